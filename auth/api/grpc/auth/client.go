@@ -51,22 +51,22 @@ func (client authGrpcClient) Authenticate(ctx context.Context, token *magistrala
 	ctx, cancel := context.WithTimeout(ctx, client.timeout)
 	defer cancel()
 
-	res, err := client.authenticate(ctx, authenticateReq{token: token.GetToken()})
+	res, err := client.authenticate(ctx, authenticateReq{token: token.GetToken(), audience: token.GetAudience(), binding: token.GetBinding()})
 	if err != nil {
 		return &magistrala.AuthNRes{}, grpcapi.DecodeError(err)
 	}
 	ir := res.(authenticateRes)
-	return &magistrala.AuthNRes{Id: ir.id, UserId: ir.userID, DomainId: ir.domainID}, nil
+	return &magistrala.AuthNRes{Id: ir.id, UserId: ir.userID, DomainId: ir.domainID, Claims: ir.claims}, nil
 }
 
 func encodeIdentifyRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(authenticateReq)
-	return &magistrala.AuthNReq{Token: req.token}, nil
+	return &magistrala.AuthNReq{Token: req.token, Audience: req.audience, Binding: req.binding}, nil
 }
 
 func decodeIdentifyResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(*magistrala.AuthNRes)
-	return authenticateRes{id: res.GetId(), userID: res.GetUserId(), domainID: res.GetDomainId()}, nil
+	return authenticateRes{id: res.GetId(), userID: res.GetUserId(), domainID: res.GetDomainId(), claims: res.GetClaims()}, nil
 }
 
 func (client authGrpcClient) Authorize(ctx context.Context, req *magistrala.AuthZReq, _ ...grpc.CallOption) (r *magistrala.AuthZRes, err error) {
@@ -82,6 +82,7 @@ func (client authGrpcClient) Authorize(ctx context.Context, req *magistrala.Auth
 		Permission:  req.GetPermission(),
 		ObjectType:  req.GetObjectType(),
 		Object:      req.GetObject(),
+		Claims:      req.GetClaims(),
 	})
 	if err != nil {
 		return &magistrala.AuthZRes{}, grpcapi.DecodeError(err)
@@ -107,5 +108,6 @@ func encodeAuthorizeRequest(_ context.Context, grpcReq interface{}) (interface{}
 		Permission:  req.Permission,
 		ObjectType:  req.ObjectType,
 		Object:      req.Object,
+		Claims:      req.Claims,
 	}, nil
 }
@@ -7,6 +7,9 @@ import (
 	"context"
 
 	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/policies"
 	"github.com/go-kit/kit/endpoint"
 )
@@ -22,8 +25,14 @@ func authenticateEndpoint(svc auth.Service) endpoint.Endpoint {
 		if err != nil {
 			return authenticateRes{}, err
 		}
+		if !key.IntendedFor(req.audience) {
+			return authenticateRes{}, errors.Wrap(svcerr.ErrAuthentication, auth.ErrInvalidAudience)
+		}
+		if !key.BoundTo(req.binding) {
+			return authenticateRes{}, errors.Wrap(svcerr.ErrAuthentication, auth.ErrInvalidBinding)
+		}
 
-		return authenticateRes{id: key.Subject, userID: key.User, domainID: key.Domain}, nil
+		return authenticateRes{id: key.Subject, userID: key.User, domainID: key.Domain, claims: key.Claims}, nil
 	}
 }
 
@@ -34,6 +43,8 @@ func authorizeEndpoint(svc auth.Service) endpoint.Endpoint {
 		if err := req.validate(); err != nil {
 			return authorizeRes{}, err
 		}
+		// The claims of the token subjects are taken from the token.
+		ctx = authn.WithClaims(ctx, req.Claims)
 		err := svc.Authorize(ctx, policies.Policy{
 			Domain:      req.Domain,
 			SubjectType: req.SubjectType,
@@ -42,6 +42,7 @@ const (
 	validToken      = "valid"
 	inValidToken    = "invalid"
 	validPolicy     = "valid"
+	validBinding    = "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I"
 )
 
 var (
@@ -67,11 +68,15 @@ func TestIdentify(t *testing.T) {
 	grpcClient := grpcapi.NewAuthClient(conn, time.Second)
 
 	cases := []struct {
-		desc   string
-		token  string
-		idt    *magistrala.AuthNRes
-		svcErr error
-		err    error
+		desc        string
+		token       string
+		audience    string
+		keyAudience string
+		binding     string
+		keyBinding  string
+		idt         *magistrala.AuthNRes
+		svcErr      error
+		err         error
 	}{
 		{
 			desc:  "authenticate user with valid user token",
@@ -92,11 +97,64 @@ func TestIdentify(t *testing.T) {
 			idt:   &magistrala.AuthNRes{},
 			err:   apiutil.ErrBearerToken,
 		},
+		{
+			desc:        "authenticate user with token issued for matching audience",
+			token:       validToken,
+			audience:    usersType,
+			keyAudience: usersType,
+			idt:         &magistrala.AuthNRes{Id: id, UserId: email, DomainId: domainID},
+			err:         nil,
+		},
+		{
+			desc:     "authenticate user with token issued without audience",
+			token:    validToken,
+			audience: usersType,
+			idt:      &magistrala.AuthNRes{Id: id, UserId: email, DomainId: domainID},
+			err:      nil,
+		},
+		{
+			desc:        "authenticate user with token issued for mismatched audience",
+			token:       validToken,
+			audience:    usersType,
+			keyAudience: thingsType,
+			idt:         &magistrala.AuthNRes{},
+			err:         svcerr.ErrAuthentication,
+		},
+		{
+			desc:       "authenticate user with bound token and matching binding",
+			token:      validToken,
+			binding:    validBinding,
+			keyBinding: validBinding,
+			idt:        &magistrala.AuthNRes{Id: id, UserId: email, DomainId: domainID},
+			err:        nil,
+		},
+		{
+			desc:       "authenticate user with bound token without binding",
+			token:      validToken,
+			keyBinding: validBinding,
+			idt:        &magistrala.AuthNRes{},
+			err:        svcerr.ErrAuthentication,
+		},
+		{
+			desc:       "authenticate user with bound token and mismatched binding",
+			token:      validToken,
+			binding:    "invalid",
+			keyBinding: validBinding,
+			idt:        &magistrala.AuthNRes{},
+			err:        svcerr.ErrAuthentication,
+		},
+		{
+			desc:    "authenticate user with unbound token and binding",
+			token:   validToken,
+			binding: validBinding,
+			idt:     &magistrala.AuthNRes{Id: id, UserId: email, DomainId: domainID},
+			err:     nil,
+		},
 	}
 
 	for _, tc := range cases {
-		svcCall := svc.On("Identify", mock.Anything, mock.Anything, mock.Anything).Return(auth.Key{Subject: id, User: email, Domain: domainID}, tc.svcErr)
-		idt, err := grpcClient.Authenticate(context.Background(), &magistrala.AuthNReq{Token: tc.token})
+		svcCall := svc.On("Identify", mock.Anything, mock.Anything, mock.Anything).Return(auth.Key{Subject: id, User: email, Domain: domainID, Audience: tc.keyAudience, Binding: tc.keyBinding}, tc.svcErr)
+		idt, err := grpcClient.Authenticate(context.Background(), &magistrala.AuthNReq{Token: tc.token, Audience: tc.audience, Binding: tc.binding})
 		if idt != nil {
 			assert.Equal(t, tc.idt, idt, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.idt, idt))
 		}
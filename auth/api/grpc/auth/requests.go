@@ -8,7 +8,9 @@ import (
 )
 
 type authenticateReq struct {
-	token string
+	token    string
+	audience string
+	binding  string
 }
 
 func (req authenticateReq) validate() error {
@@ -32,6 +34,7 @@ type authReq struct {
 	Permission  string
 	ObjectType  string
 	Object      string
+	Claims      map[string]string
 }
 
 func (req authReq) validate() error {
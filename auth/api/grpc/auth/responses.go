@@ -7,6 +7,7 @@ type authenticateRes struct {
 	id       string
 	userID   string
 	domainID string
+	claims   map[string]string
 }
 
 type authorizeRes struct {
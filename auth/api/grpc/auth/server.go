@@ -55,12 +55,12 @@ func (s *authGrpcServer) Authorize(ctx context.Context, req *magistrala.AuthZReq
 
 func decodeAuthenticateRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*magistrala.AuthNReq)
-	return authenticateReq{token: req.GetToken()}, nil
+	return authenticateReq{token: req.GetToken(), audience: req.GetAudience(), binding: req.GetBinding()}, nil
 }
 
 func encodeAuthenticateResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(authenticateRes)
-	return &magistrala.AuthNRes{Id: res.id, UserId: res.userID, DomainId: res.domainID}, nil
+	return &magistrala.AuthNRes{Id: res.id, UserId: res.userID, DomainId: res.domainID, Claims: res.claims}, nil
 }
 
 func decodeAuthorizeRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
@@ -74,6 +74,7 @@ func decodeAuthorizeRequest(_ context.Context, grpcReq interface{}) (interface{}
 		Permission:  req.GetPermission(),
 		ObjectType:  req.GetObjectType(),
 		Object:      req.GetObject(),
+		Claims:      req.GetClaims(),
 	}, nil
 }
 
@@ -53,8 +53,11 @@ func (client tokenGrpcClient) Issue(ctx context.Context, req *magistrala.IssueRe
 	defer cancel()
 
 	res, err := client.issue(ctx, issueReq{
-		userID:  req.GetUserId(),
-		keyType: auth.KeyType(req.GetType()),
+		userID:   req.GetUserId(),
+		keyType:  auth.KeyType(req.GetType()),
+		audience: req.GetAudience(),
+		binding:  req.GetBinding(),
+		claims:   req.GetClaims(),
 	})
 	if err != nil {
 		return &magistrala.Token{}, grpcapi.DecodeError(err)
@@ -65,8 +68,11 @@ func (client tokenGrpcClient) Issue(ctx context.Context, req *magistrala.IssueRe
 func encodeIssueRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(issueReq)
 	return &magistrala.IssueReq{
-		UserId: req.userID,
-		Type:   uint32(req.keyType),
+		UserId:   req.userID,
+		Type:     uint32(req.keyType),
+		Audience: req.audience,
+		Binding:  req.binding,
+		Claims:   req.claims,
 	}, nil
 }
 
@@ -18,8 +18,11 @@ func issueEndpoint(svc auth.Service) endpoint.Endpoint {
 		}
 
 		key := auth.Key{
-			Type: req.keyType,
-			User: req.userID,
+			Type:     req.keyType,
+			User:     req.userID,
+			Audience: req.audience,
+			Binding:  req.binding,
+			Claims:   req.claims,
 		}
 		tkn, err := svc.Issue(ctx, "", key)
 		if err != nil {
@@ -9,8 +9,11 @@ import (
 )
 
 type issueReq struct {
-	userID  string
-	keyType auth.KeyType
+	userID   string
+	keyType  auth.KeyType
+	audience string
+	binding  string
+	claims   map[string]string
 }
 
 func (req issueReq) validate() error {
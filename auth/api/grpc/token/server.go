@@ -55,8 +55,11 @@ func (s *tokenGrpcServer) Refresh(ctx context.Context, req *magistrala.RefreshRe
 func decodeIssueRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*magistrala.IssueReq)
 	return issueReq{
-		userID:  req.GetUserId(),
-		keyType: auth.KeyType(req.GetType()),
+		userID:   req.GetUserId(),
+		keyType:  auth.KeyType(req.GetType()),
+		audience: req.GetAudience(),
+		binding:  req.GetBinding(),
+		claims:   req.GetClaims(),
 	}, nil
 }
 
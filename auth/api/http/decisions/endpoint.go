@@ -0,0 +1,37 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package decisions
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func explainEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(explainReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		pr := policies.Policy{
+			Domain:          req.domain,
+			Subject:         req.subject,
+			SubjectType:     req.subjectType,
+			SubjectRelation: req.subjectRelation,
+			Permission:      req.permission,
+			Object:          req.object,
+			ObjectType:      req.objectType,
+		}
+		decision, err := svc.ExplainPolicy(ctx, req.token, pr)
+		if err != nil {
+			return nil, err
+		}
+
+		return explainRes{decision}, nil
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package decisions_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	httpapi "github.com/absmach/magistrala/auth/api/http/decisions"
+	"github.com/absmach/magistrala/auth/mocks"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	validToken = "token"
+	userID     = "d4ebb847-5d0e-4e46-bdd9-b6aceaaa3a22"
+	groupID    = "e4ebb847-5d0e-4e46-bdd9-b6aceaaa3a22"
+	domainID   = "f4ebb847-5d0e-4e46-bdd9-b6aceaaa3a22"
+)
+
+func newDecisionsServer() (*httptest.Server, *mocks.Service) {
+	mux := chi.NewRouter()
+	svc := new(mocks.Service)
+	httpapi.MakeHandler(svc, mux, mglog.NewMock())
+	return httptest.NewServer(mux), svc
+}
+
+func TestExplain(t *testing.T) {
+	ds, svc := newDecisionsServer()
+	defer ds.Close()
+
+	pr := policies.Policy{
+		Domain:      domainID,
+		Subject:     userID,
+		SubjectType: policies.UserType,
+		Permission:  policies.ViewPermission,
+		Object:      groupID,
+		ObjectType:  policies.GroupType,
+	}
+	query := url.Values{
+		"domain":       []string{pr.Domain},
+		"subject":      []string{pr.Subject},
+		"subject_type": []string{pr.SubjectType},
+		"permission":   []string{pr.Permission},
+		"object":       []string{pr.Object},
+		"object_type":  []string{pr.ObjectType},
+	}
+	withoutKey := func(key string) string {
+		q := url.Values{}
+		for k, v := range query {
+			if k != key {
+				q[k] = v
+			}
+		}
+		return q.Encode()
+	}
+
+	cases := []struct {
+		desc     string
+		token    string
+		query    string
+		decision policies.Decision
+		svcErr   error
+		status   int
+	}{
+		{
+			desc:  "explain allowed decision",
+			token: validToken,
+			query: query.Encode(),
+			decision: policies.Decision{
+				Allowed: true,
+				Path:    []string{"group:" + groupID + "#view", "group:" + groupID + "#administrator"},
+				Reason:  "direct policy matches",
+			},
+			status: http.StatusOK,
+		},
+		{
+			desc:     "explain denied decision",
+			token:    validToken,
+			query:    query.Encode(),
+			decision: policies.Decision{Reason: "no direct or inherited policy matches"},
+			status:   http.StatusOK,
+		},
+		{
+			desc:   "explain decision as non platform admin",
+			token:  validToken,
+			query:  query.Encode(),
+			svcErr: svcerr.ErrAuthorization,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "explain decision with empty token",
+			query:  query.Encode(),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "explain decision without subject",
+			token:  validToken,
+			query:  withoutKey("subject"),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "explain decision without object type",
+			token:  validToken,
+			query:  withoutKey("object_type"),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "explain decision without permission",
+			token:  validToken,
+			query:  withoutKey("permission"),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "explain decision with duplicate query param",
+			token:  validToken,
+			query:  query.Encode() + "&subject=" + groupID,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/decisions?%s", ds.URL, tc.query), nil)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			if tc.token != "" {
+				req.Header.Set("Authorization", apiutil.BearerPrefix+tc.token)
+			}
+
+			svcCall := svc.On("ExplainPolicy", mock.Anything, tc.token, pr).Return(tc.decision, tc.svcErr)
+			res, err := ds.Client().Do(req)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if res.StatusCode == http.StatusOK {
+				var d policies.Decision
+				err = json.NewDecoder(res.Body).Decode(&d)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.decision, d, fmt.Sprintf("%s: expected decision %v got %v", tc.desc, tc.decision, d))
+			}
+			svcCall.Unset()
+		})
+	}
+}
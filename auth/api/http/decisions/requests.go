@@ -0,0 +1,40 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package decisions
+
+import (
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+type explainReq struct {
+	token           string
+	domain          string
+	subject         string
+	subjectType     string
+	subjectRelation string
+	permission      string
+	object          string
+	objectType      string
+}
+
+func (req explainReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+	if req.subject == "" {
+		return errors.Wrap(apiutil.ErrMalformedPolicy, apiutil.ErrMissingPolicySub)
+	}
+	if req.object == "" {
+		return errors.Wrap(apiutil.ErrMalformedPolicy, apiutil.ErrMissingPolicyObj)
+	}
+	if req.subjectType == "" || req.objectType == "" {
+		return errors.Wrap(apiutil.ErrMalformedPolicy, apiutil.ErrMissingPolicyEntityType)
+	}
+	if req.permission == "" {
+		return errors.Wrap(apiutil.ErrMalformedPolicy, apiutil.ErrMalformedPolicyPer)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package decisions
+
+import (
+	"net/http"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+var _ magistrala.Response = (*explainRes)(nil)
+
+type explainRes struct {
+	policies.Decision
+}
+
+func (res explainRes) Code() int {
+	return http.StatusOK
+}
+
+func (res explainRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res explainRes) Empty() bool {
+	return false
+}
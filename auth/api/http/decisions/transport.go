@@ -0,0 +1,66 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package decisions
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/go-chi/chi/v5"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	domainKey          = "domain"
+	subjectKey         = "subject"
+	subjectTypeKey     = "subject_type"
+	subjectRelationKey = "subject_relation"
+	permissionKey      = "permission"
+	objectKey          = "object"
+	objectTypeKey      = "object_type"
+)
+
+// MakeHandler returns a HTTP handler for the authorization decisions API.
+func MakeHandler(svc auth.Service, mux *chi.Mux, logger *slog.Logger) *chi.Mux {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, api.EncodeError)),
+	}
+
+	mux.Get("/decisions", otelhttp.NewHandler(kithttp.NewServer(
+		explainEndpoint(svc),
+		decodeExplainRequest,
+		api.EncodeResponse,
+		opts...,
+	), "explain_decision").ServeHTTP)
+
+	return mux
+}
+
+func decodeExplainRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := explainReq{token: apiutil.ExtractBearerToken(r)}
+
+	for key, val := range map[string]*string{
+		domainKey:          &req.domain,
+		subjectKey:         &req.subject,
+		subjectTypeKey:     &req.subjectType,
+		subjectRelationKey: &req.subjectRelation,
+		permissionKey:      &req.permission,
+		objectKey:          &req.object,
+		objectTypeKey:      &req.objectType,
+	} {
+		v, err := apiutil.ReadStringQuery(r, key, "")
+		if err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+		*val = v
+	}
+
+	return req, nil
+}
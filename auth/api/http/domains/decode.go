@@ -100,6 +100,22 @@ func decodeFreezeDomainRequest(_ context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeSuspendDomainRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := suspendDomainReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+	}
+	return req, nil
+}
+
+func decodeResumeDomainRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := resumeDomainReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+	}
+	return req, nil
+}
+
 func decodeAssignUsersRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -116,6 +132,47 @@ func decodeAssignUsersRequest(_ context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeTransferOwnershipRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := transferOwnershipReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+func decodeExportPoliciesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	req := exportPoliciesReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+	}
+
+	return req, nil
+}
+
+func decodeImportPoliciesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := importPoliciesReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
 func decodeUnassignUserRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -145,6 +202,18 @@ func decodeListUserDomainsRequest(ctx context.Context, r *http.Request) (interfa
 	return req, nil
 }
 
+func decodeListProfileDomainsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	page, err := decodePageRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	req := listProfileDomainsReq{
+		token: apiutil.ExtractBearerToken(r),
+		page:  page,
+	}
+	return req, nil
+}
+
 func decodePageRequest(_ context.Context, r *http.Request) (page, error) {
 	s, err := apiutil.ReadStringQuery(r, api.StatusKey, api.DefClientStatus)
 	if err != nil {
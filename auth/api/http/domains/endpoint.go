@@ -21,10 +21,11 @@ func createDomainEndpoint(svc auth.Service) endpoint.Endpoint {
 		}
 
 		d := auth.Domain{
-			Name:     req.Name,
-			Metadata: req.Metadata,
-			Tags:     req.Tags,
-			Alias:    req.Alias,
+			Name:             req.Name,
+			Metadata:         req.Metadata,
+			Tags:             req.Tags,
+			Alias:            req.Alias,
+			SelfRegistration: req.SelfRegistration,
 		}
 		domain, err := svc.CreateDomain(ctx, req.token, d)
 		if err != nil {
@@ -77,10 +78,11 @@ func updateDomainEndpoint(svc auth.Service) endpoint.Endpoint {
 			metadata = *req.Metadata
 		}
 		d := auth.DomainReq{
-			Name:     req.Name,
-			Metadata: &metadata,
-			Tags:     req.Tags,
-			Alias:    req.Alias,
+			Name:             req.Name,
+			Metadata:         &metadata,
+			Tags:             req.Tags,
+			Alias:            req.Alias,
+			SelfRegistration: req.SelfRegistration,
 		}
 		domain, err := svc.UpdateDomain(ctx, req.token, req.domainID, d)
 		if err != nil {
@@ -171,6 +173,87 @@ func freezeDomainEndpoint(svc auth.Service) endpoint.Endpoint {
 	}
 }
 
+func suspendDomainEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(suspendDomainReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if _, err := svc.SuspendDomain(ctx, req.token, req.domainID); err != nil {
+			return nil, err
+		}
+		return suspendDomainRes{}, nil
+	}
+}
+
+func resumeDomainEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(resumeDomainReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if _, err := svc.ResumeDomain(ctx, req.token, req.domainID); err != nil {
+			return nil, err
+		}
+		return resumeDomainRes{}, nil
+	}
+}
+
+func transferOwnershipEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(transferOwnershipReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		report, err := svc.TransferOwnership(ctx, req.token, req.domainID, auth.TransferReq{
+			FromUserID:  req.FromUserID,
+			ToUserID:    req.ToUserID,
+			EntityTypes: req.EntityTypes,
+			DryRun:      req.DryRun,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return transferOwnershipRes{report}, nil
+	}
+}
+
+func exportPoliciesEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(exportPoliciesReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		export, err := svc.ExportPolicies(ctx, req.token, req.domainID)
+		if err != nil {
+			return nil, err
+		}
+		return exportPoliciesRes{export}, nil
+	}
+}
+
+func importPoliciesEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(importPoliciesReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		report, err := svc.ImportPolicies(ctx, req.token, req.domainID, auth.ImportReq{
+			Policies: req.Policies,
+			DryRun:   req.DryRun,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return importPoliciesRes{report}, nil
+	}
+}
+
 func assignDomainUsersEndpoint(svc auth.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(assignUsersReq)
@@ -178,7 +261,7 @@ func assignDomainUsersEndpoint(svc auth.Service) endpoint.Endpoint {
 			return nil, err
 		}
 
-		if err := svc.AssignUsers(ctx, req.token, req.domainID, req.UserIDs, req.Relation); err != nil {
+		if err := svc.AssignUsers(ctx, req.token, req.domainID, req.UserIDs, req.Relation, req.Conditions); err != nil {
 			return nil, err
 		}
 		return assignUsersRes{}, nil
@@ -224,3 +307,30 @@ func listUserDomainsEndpoint(svc auth.Service) endpoint.Endpoint {
 		return listUserDomainsRes{dp}, nil
 	}
 }
+
+func listProfileDomainsEndpoint(svc auth.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listProfileDomainsReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page := auth.Page{
+			Offset:     req.offset,
+			Limit:      req.limit,
+			Name:       req.name,
+			Metadata:   req.metadata,
+			Order:      req.order,
+			Dir:        req.dir,
+			Tag:        req.tag,
+			Permission: req.permission,
+			Status:     req.status,
+		}
+		// The empty user ID lists the domains of the authenticated user.
+		dp, err := svc.ListUserDomains(ctx, req.token, "", page)
+		if err != nil {
+			return nil, err
+		}
+		return listUserDomainsRes{dp}, nil
+	}
+}
@@ -979,6 +979,162 @@ func TestFreezeDomain(t *testing.T) {
 	}
 }
 
+func TestSuspendDomain(t *testing.T) {
+	ds, svc := newDomainsServer()
+	defer ds.Close()
+
+	cases := []struct {
+		desc     string
+		domain   auth.Domain
+		response auth.Domain
+		token    string
+		status   int
+		svcErr   error
+		err      error
+	}{
+		{
+			desc:   "suspend domain with valid token",
+			domain: domain,
+			response: auth.Domain{
+				ID:     domain.ID,
+				Status: auth.SuspendedStatus,
+			},
+			token:  validToken,
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:   "suspend domain with invalid token",
+			domain: domain,
+			token:  inValidToken,
+			status: http.StatusUnauthorized,
+			svcErr: svcerr.ErrAuthentication,
+			err:    svcerr.ErrAuthentication,
+		},
+		{
+			desc:   "suspend domain with empty token",
+			domain: domain,
+			token:  "",
+			status: http.StatusUnauthorized,
+			err:    apiutil.ErrBearerToken,
+		},
+		{
+			desc: "suspend domain with empty id",
+			domain: auth.Domain{
+				ID: "",
+			},
+			token:  validToken,
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrMissingID,
+		},
+		{
+			desc: "suspend domain with invalid id",
+			domain: auth.Domain{
+				ID: "invalid",
+			},
+			token:  validToken,
+			status: http.StatusForbidden,
+			svcErr: svcerr.ErrAuthorization,
+			err:    svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		data := toJSON(tc.domain)
+		req := testRequest{
+			client:      ds.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/domains/%s/suspend", ds.URL, tc.domain.ID),
+			contentType: contentType,
+			token:       tc.token,
+			body:        strings.NewReader(data),
+		}
+		svcCall := svc.On("SuspendDomain", mock.Anything, tc.token, tc.domain.ID).Return(tc.response, tc.svcErr)
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		svcCall.Unset()
+	}
+}
+
+func TestResumeDomain(t *testing.T) {
+	ds, svc := newDomainsServer()
+	defer ds.Close()
+
+	cases := []struct {
+		desc     string
+		domain   auth.Domain
+		response auth.Domain
+		token    string
+		status   int
+		svcErr   error
+		err      error
+	}{
+		{
+			desc:   "resume domain with valid token",
+			domain: domain,
+			response: auth.Domain{
+				ID:     domain.ID,
+				Status: auth.EnabledStatus,
+			},
+			token:  validToken,
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:   "resume domain with invalid token",
+			domain: domain,
+			token:  inValidToken,
+			status: http.StatusUnauthorized,
+			svcErr: svcerr.ErrAuthentication,
+			err:    svcerr.ErrAuthentication,
+		},
+		{
+			desc:   "resume domain with empty token",
+			domain: domain,
+			token:  "",
+			status: http.StatusUnauthorized,
+			err:    apiutil.ErrBearerToken,
+		},
+		{
+			desc: "resume domain with empty id",
+			domain: auth.Domain{
+				ID: "",
+			},
+			token:  validToken,
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrMissingID,
+		},
+		{
+			desc: "resume domain with invalid id",
+			domain: auth.Domain{
+				ID: "invalid",
+			},
+			token:  validToken,
+			status: http.StatusForbidden,
+			svcErr: svcerr.ErrAuthorization,
+			err:    svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		data := toJSON(tc.domain)
+		req := testRequest{
+			client:      ds.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/domains/%s/resume", ds.URL, tc.domain.ID),
+			contentType: contentType,
+			token:       tc.token,
+			body:        strings.NewReader(data),
+		}
+		svcCall := svc.On("ResumeDomain", mock.Anything, tc.token, tc.domain.ID).Return(tc.response, tc.svcErr)
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		svcCall.Unset()
+	}
+}
+
 func TestAssignDomainUsers(t *testing.T) {
 	ds, svc := newDomainsServer()
 	defer ds.Close()
@@ -1085,7 +1241,7 @@ func TestAssignDomainUsers(t *testing.T) {
 			body:        strings.NewReader(tc.data),
 		}
 
-		svcCall := svc.On("AssignUsers", mock.Anything, tc.token, tc.domainID, mock.Anything, mock.Anything).Return(tc.err)
+		svcCall := svc.On("AssignUsers", mock.Anything, tc.token, tc.domainID, mock.Anything, mock.Anything, mock.Anything).Return(tc.err)
 		res, err := req.make()
 		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
@@ -1198,6 +1354,338 @@ func TestUnassignDomainUser(t *testing.T) {
 	}
 }
 
+func TestTransferOwnership(t *testing.T) {
+	ds, svc := newDomainsServer()
+	defer ds.Close()
+
+	fromUserID := testsutil.GenerateUUID(t)
+	toUserID := testsutil.GenerateUUID(t)
+	thingID := testsutil.GenerateUUID(t)
+	validData := fmt.Sprintf(`{"from_user_id": "%s", "to_user_id": "%s", "entity_types": ["%s"]}`, fromUserID, toUserID, policies.ThingType)
+	report := auth.TransferReport{Resources: map[string][]string{policies.ThingType: {thingID}}}
+
+	cases := []struct {
+		desc        string
+		data        string
+		domainID    string
+		contentType string
+		token       string
+		svcRes      auth.TransferReport
+		svcErr      error
+		status      int
+		err         error
+	}{
+		{
+			desc:        "transfer ownership with valid token",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			svcRes:      report,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "transfer ownership in dry run mode",
+			data:        fmt.Sprintf(`{"from_user_id": "%s", "to_user_id": "%s", "entity_types": ["%s", "%s"], "dry_run": true}`, fromUserID, toUserID, policies.ThingType, policies.GroupType),
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			svcRes:      auth.TransferReport{Resources: report.Resources, DryRun: true},
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "transfer ownership with invalid token",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       inValidToken,
+			svcErr:      svcerr.ErrAuthentication,
+			status:      http.StatusUnauthorized,
+			err:         svcerr.ErrAuthentication,
+		},
+		{
+			desc:        "transfer ownership with empty token",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       "",
+			status:      http.StatusUnauthorized,
+			err:         apiutil.ErrBearerToken,
+		},
+		{
+			desc:        "transfer ownership to user without domain access",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			svcErr:      svcerr.ErrAuthorization,
+			status:      http.StatusForbidden,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:        "transfer ownership with empty target user id",
+			data:        fmt.Sprintf(`{"from_user_id": "%s", "entity_types": ["%s"]}`, fromUserID, policies.ThingType),
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrMissingID,
+		},
+		{
+			desc:        "transfer ownership with empty entity types",
+			data:        fmt.Sprintf(`{"from_user_id": "%s", "to_user_id": "%s"}`, fromUserID, toUserID),
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrMissingEntityType,
+		},
+		{
+			desc:        "transfer ownership with invalid entity type",
+			data:        fmt.Sprintf(`{"from_user_id": "%s", "to_user_id": "%s", "entity_types": ["%s"]}`, fromUserID, toUserID, policies.DomainType),
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrInvalidEntityType,
+		},
+		{
+			desc:        "transfer ownership with malformed data",
+			data:        fmt.Sprintf(`{"from_user_id": "%s", to_user_id: "%s"}`, fromUserID, toUserID),
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "transfer ownership with invalid content type",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: "application/xml",
+			token:       validToken,
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrUnsupportedContentType,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ds.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/domains/%s/transfer", ds.URL, tc.domainID),
+			contentType: tc.contentType,
+			token:       tc.token,
+			body:        strings.NewReader(tc.data),
+		}
+
+		svcCall := svc.On("TransferOwnership", mock.Anything, tc.token, tc.domainID, mock.Anything).Return(tc.svcRes, tc.svcErr)
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.err == nil {
+			var body auth.TransferReport
+			err = json.NewDecoder(res.Body).Decode(&body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			assert.Equal(t, tc.svcRes, body, fmt.Sprintf("%s: expected report %v got %v", tc.desc, tc.svcRes, body))
+		}
+		svcCall.Unset()
+	}
+}
+
+func TestExportPolicies(t *testing.T) {
+	ds, svc := newDomainsServer()
+	defer ds.Close()
+
+	userID := testsutil.GenerateUUID(t)
+	export := auth.PolicyExport{
+		DomainID: domain.ID,
+		Policies: []auth.PortablePolicy{
+			{SubjectType: policies.UserType, Subject: userID, Relation: policies.AdministratorRelation, ObjectType: policies.DomainType, Object: domain.ID},
+		},
+	}
+
+	cases := []struct {
+		desc     string
+		token    string
+		domainID string
+		svcRes   auth.PolicyExport
+		svcErr   error
+		status   int
+		err      error
+	}{
+		{
+			desc:     "export policies with valid token",
+			token:    validToken,
+			domainID: domain.ID,
+			svcRes:   export,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "export policies with empty token",
+			token:    "",
+			domainID: domain.ID,
+			status:   http.StatusUnauthorized,
+			err:      apiutil.ErrBearerToken,
+		},
+		{
+			desc:     "export policies with invalid token",
+			token:    inValidToken,
+			domainID: domain.ID,
+			svcErr:   svcerr.ErrAuthentication,
+			status:   http.StatusUnauthorized,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:     "export policies without domain admin permission",
+			token:    validToken,
+			domainID: domain.ID,
+			svcErr:   svcerr.ErrAuthorization,
+			status:   http.StatusForbidden,
+			err:      svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ds.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/domains/%s/policies/export", ds.URL, tc.domainID),
+			token:  tc.token,
+		}
+
+		svcCall := svc.On("ExportPolicies", mock.Anything, tc.token, tc.domainID).Return(tc.svcRes, tc.svcErr)
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.err == nil {
+			var body auth.PolicyExport
+			err = json.NewDecoder(res.Body).Decode(&body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			assert.Equal(t, tc.svcRes, body, fmt.Sprintf("%s: expected export %v got %v", tc.desc, tc.svcRes, body))
+		}
+		svcCall.Unset()
+	}
+}
+
+func TestImportPolicies(t *testing.T) {
+	ds, svc := newDomainsServer()
+	defer ds.Close()
+
+	userID := testsutil.GenerateUUID(t)
+	policy := auth.PortablePolicy{SubjectType: policies.UserType, Subject: userID, Relation: policies.AdministratorRelation, ObjectType: policies.DomainType, Object: domain.ID}
+	validData := toJSON(auth.PolicyExport{DomainID: domain.ID, Policies: []auth.PortablePolicy{policy}})
+
+	cases := []struct {
+		desc        string
+		data        string
+		domainID    string
+		contentType string
+		token       string
+		svcRes      auth.ImportReport
+		svcErr      error
+		status      int
+		err         error
+	}{
+		{
+			desc:        "import policies with valid token",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			svcRes:      auth.ImportReport{Added: []auth.PortablePolicy{policy}, Unchanged: []auth.PortablePolicy{}, Applied: true},
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "import policies in dry run mode",
+			data:        fmt.Sprintf(`{"policies": %s, "dry_run": true}`, toJSON([]auth.PortablePolicy{policy})),
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			svcRes:      auth.ImportReport{Added: []auth.PortablePolicy{policy}, Unchanged: []auth.PortablePolicy{}, DryRun: true},
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "import invalid policies",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			svcRes:      auth.ImportReport{Added: []auth.PortablePolicy{}, Unchanged: []auth.PortablePolicy{}, Invalid: []auth.InvalidPolicy{{Policy: policy, Reason: "duplicate policy"}}},
+			status:      http.StatusUnprocessableEntity,
+		},
+		{
+			desc:        "import policies with invalid token",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       inValidToken,
+			svcErr:      svcerr.ErrAuthentication,
+			status:      http.StatusUnauthorized,
+			err:         svcerr.ErrAuthentication,
+		},
+		{
+			desc:        "import policies with empty token",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       "",
+			status:      http.StatusUnauthorized,
+			err:         apiutil.ErrBearerToken,
+		},
+		{
+			desc:        "import empty policies",
+			data:        `{"policies": []}`,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrEmptyList,
+		},
+		{
+			desc:        "import policies with malformed data",
+			data:        `{"policies": [}`,
+			domainID:    domain.ID,
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "import policies with invalid content type",
+			data:        validData,
+			domainID:    domain.ID,
+			contentType: "application/xml",
+			token:       validToken,
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrUnsupportedContentType,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ds.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/domains/%s/policies/import", ds.URL, tc.domainID),
+			contentType: tc.contentType,
+			token:       tc.token,
+			body:        strings.NewReader(tc.data),
+		}
+
+		svcCall := svc.On("ImportPolicies", mock.Anything, tc.token, tc.domainID, mock.Anything).Return(tc.svcRes, tc.svcErr)
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.err == nil {
+			var body auth.ImportReport
+			err = json.NewDecoder(res.Body).Decode(&body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			assert.Equal(t, tc.svcRes, body, fmt.Sprintf("%s: expected report %v got %v", tc.desc, tc.svcRes, body))
+		}
+		svcCall.Unset()
+	}
+}
+
 func TestListDomainsByUserID(t *testing.T) {
 	ds, svc := newDomainsServer()
 	defer ds.Close()
@@ -1300,6 +1788,92 @@ func TestListDomainsByUserID(t *testing.T) {
 	}
 }
 
+func TestListProfileDomains(t *testing.T) {
+	ds, svc := newDomainsServer()
+	defer ds.Close()
+
+	adminDomain := domain
+	adminDomain.Permission = policies.AdministratorRelation
+	memberDomain := domain
+	memberDomain.ID = testsutil.GenerateUUID(t)
+	memberDomain.Name = "memberdomain"
+	memberDomain.Permission = policies.MemberRelation
+	guestDomain := domain
+	guestDomain.ID = testsutil.GenerateUUID(t)
+	guestDomain.Name = "guestdomain"
+	guestDomain.Permission = policies.GuestRelation
+	userDomains := []auth.Domain{adminDomain, memberDomain, guestDomain}
+
+	cases := []struct {
+		desc    string
+		token   string
+		query   string
+		page    auth.Page
+		domains auth.DomainsPage
+		svcErr  error
+		status  int
+	}{
+		{
+			desc:    "list profile domains of user in several domains",
+			token:   validToken,
+			page:    auth.Page{Limit: 10, Status: auth.EnabledStatus, Order: "updated_at", Dir: "asc"},
+			domains: auth.DomainsPage{Total: 3, Limit: 10, Domains: userDomains},
+			status:  http.StatusOK,
+		},
+		{
+			desc:    "list profile domains with offset and limit",
+			token:   validToken,
+			query:   "offset=1&limit=1",
+			page:    auth.Page{Offset: 1, Limit: 1, Status: auth.EnabledStatus, Order: "updated_at", Dir: "asc"},
+			domains: auth.DomainsPage{Total: 3, Offset: 1, Limit: 1, Domains: userDomains[1:2]},
+			status:  http.StatusOK,
+		},
+		{
+			desc:   "list profile domains with invalid limit",
+			token:  validToken,
+			query:  "limit=invalid",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "list profile domains with empty token",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "list profile domains with invalid token",
+			token:  inValidToken,
+			page:   auth.Page{Limit: 10, Status: auth.EnabledStatus, Order: "updated_at", Dir: "asc"},
+			svcErr: svcerr.ErrAuthentication,
+			status: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: ds.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/users/profile/domains?%s", ds.URL, tc.query),
+				token:  tc.token,
+			}
+			svcCall := svc.On("ListUserDomains", mock.Anything, tc.token, "", tc.page).Return(tc.domains, tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusOK {
+				var page auth.DomainsPage
+				err := json.NewDecoder(res.Body).Decode(&page)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+				assert.Equal(t, tc.domains.Total, page.Total)
+				assert.Equal(t, len(tc.domains.Domains), len(page.Domains))
+				for i, d := range page.Domains {
+					assert.Equal(t, tc.domains.Domains[i].ID, d.ID, fmt.Sprintf("%s: unexpected domain", tc.desc))
+					assert.Equal(t, tc.domains.Domains[i].Permission, d.Permission, fmt.Sprintf("%s: unexpected role in domain %s", tc.desc, d.ID))
+				}
+			}
+			svcCall.Unset()
+		})
+	}
+}
+
 type respBody struct {
 	Err         string           `json:"error"`
 	Message     string           `json:"message"`
@@ -6,6 +6,7 @@ package domains
 import (
 	"github.com/absmach/magistrala/auth"
 	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/policies"
 )
 
 type page struct {
@@ -21,11 +22,12 @@ type page struct {
 }
 
 type createDomainReq struct {
-	token    string
-	Name     string                 `json:"name"`
-	Metadata map[string]interface{} `json:"metadata,omitempty"`
-	Tags     []string               `json:"tags,omitempty"`
-	Alias    string                 `json:"alias"`
+	token            string
+	Name             string                 `json:"name"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+	Alias            string                 `json:"alias"`
+	SelfRegistration bool                   `json:"self_registration,omitempty"`
 }
 
 func (req createDomainReq) validate() error {
@@ -79,12 +81,13 @@ func (req retrieveDomainPermissionsRequest) validate() error {
 }
 
 type updateDomainReq struct {
-	token    string
-	domainID string
-	Name     *string                 `json:"name,omitempty"`
-	Metadata *map[string]interface{} `json:"metadata,omitempty"`
-	Tags     *[]string               `json:"tags,omitempty"`
-	Alias    *string                 `json:"alias,omitempty"`
+	token            string
+	domainID         string
+	Name             *string                 `json:"name,omitempty"`
+	Metadata         *map[string]interface{} `json:"metadata,omitempty"`
+	Tags             *[]string               `json:"tags,omitempty"`
+	Alias            *string                 `json:"alias,omitempty"`
+	SelfRegistration *bool                   `json:"self_registration,omitempty"`
 }
 
 func (req updateDomainReq) validate() error {
@@ -163,11 +166,46 @@ func (req freezeDomainReq) validate() error {
 	return nil
 }
 
-type assignUsersReq struct {
+type suspendDomainReq struct {
 	token    string
 	domainID string
-	UserIDs  []string `json:"user_ids"`
-	Relation string   `json:"relation"`
+}
+
+func (req suspendDomainReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.domainID == "" {
+		return apiutil.ErrMissingID
+	}
+
+	return nil
+}
+
+type resumeDomainReq struct {
+	token    string
+	domainID string
+}
+
+func (req resumeDomainReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.domainID == "" {
+		return apiutil.ErrMissingID
+	}
+
+	return nil
+}
+
+type assignUsersReq struct {
+	token      string
+	domainID   string
+	UserIDs    []string `json:"user_ids"`
+	Relation   string   `json:"relation"`
+	Conditions []string `json:"conditions,omitempty"`
 }
 
 func (req assignUsersReq) validate() error {
@@ -229,3 +267,89 @@ func (req listUserDomainsReq) validate() error {
 
 	return nil
 }
+
+type listProfileDomainsReq struct {
+	token string
+	page
+}
+
+func (req listProfileDomainsReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	return nil
+}
+
+type transferOwnershipReq struct {
+	token       string
+	domainID    string
+	FromUserID  string   `json:"from_user_id"`
+	ToUserID    string   `json:"to_user_id"`
+	EntityTypes []string `json:"entity_types"`
+	DryRun      bool     `json:"dry_run"`
+}
+
+func (req transferOwnershipReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.domainID == "" || req.FromUserID == "" || req.ToUserID == "" {
+		return apiutil.ErrMissingID
+	}
+
+	if len(req.EntityTypes) == 0 {
+		return apiutil.ErrMissingEntityType
+	}
+
+	for _, entityType := range req.EntityTypes {
+		if entityType != policies.ThingType && entityType != policies.GroupType {
+			return apiutil.ErrInvalidEntityType
+		}
+	}
+
+	return nil
+}
+
+type exportPoliciesReq struct {
+	token    string
+	domainID string
+}
+
+func (req exportPoliciesReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.domainID == "" {
+		return apiutil.ErrMissingID
+	}
+
+	return nil
+}
+
+// importPoliciesReq accepts the exported policies as they are, so the
+// domain ID of the export is ignored.
+type importPoliciesReq struct {
+	token    string
+	domainID string
+	Policies []auth.PortablePolicy `json:"policies"`
+	DryRun   bool                  `json:"dry_run"`
+}
+
+func (req importPoliciesReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.domainID == "" {
+		return apiutil.ErrMissingID
+	}
+
+	if len(req.Policies) == 0 {
+		return apiutil.ErrEmptyList
+	}
+
+	return nil
+}
@@ -16,6 +16,9 @@ var (
 	_ magistrala.Response = (*assignUsersRes)(nil)
 	_ magistrala.Response = (*unassignUsersRes)(nil)
 	_ magistrala.Response = (*listDomainsRes)(nil)
+	_ magistrala.Response = (*transferOwnershipRes)(nil)
+	_ magistrala.Response = (*exportPoliciesRes)(nil)
+	_ magistrala.Response = (*importPoliciesRes)(nil)
 )
 
 type createDomainRes struct {
@@ -140,6 +143,34 @@ func (res freezeDomainRes) Empty() bool {
 	return true
 }
 
+type suspendDomainRes struct{}
+
+func (res suspendDomainRes) Code() int {
+	return http.StatusOK
+}
+
+func (res suspendDomainRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res suspendDomainRes) Empty() bool {
+	return true
+}
+
+type resumeDomainRes struct{}
+
+func (res resumeDomainRes) Code() int {
+	return http.StatusOK
+}
+
+func (res resumeDomainRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res resumeDomainRes) Empty() bool {
+	return true
+}
+
 type assignUsersRes struct{}
 
 func (res assignUsersRes) Code() int {
@@ -183,3 +214,57 @@ func (res listUserDomainsRes) Headers() map[string]string {
 func (res listUserDomainsRes) Empty() bool {
 	return false
 }
+
+type transferOwnershipRes struct {
+	auth.TransferReport
+}
+
+func (res transferOwnershipRes) Code() int {
+	return http.StatusOK
+}
+
+func (res transferOwnershipRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res transferOwnershipRes) Empty() bool {
+	return false
+}
+
+type exportPoliciesRes struct {
+	auth.PolicyExport
+}
+
+func (res exportPoliciesRes) Code() int {
+	return http.StatusOK
+}
+
+func (res exportPoliciesRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res exportPoliciesRes) Empty() bool {
+	return false
+}
+
+type importPoliciesRes struct {
+	auth.ImportReport
+}
+
+// Code reports the invalid policies as unprocessable, since none of the
+// policies is added then.
+func (res importPoliciesRes) Code() int {
+	if len(res.Invalid) > 0 {
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusOK
+}
+
+func (res importPoliciesRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res importPoliciesRes) Empty() bool {
+	return false
+}
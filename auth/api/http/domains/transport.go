@@ -77,6 +77,43 @@ func MakeHandler(svc auth.Service, mux *chi.Mux, logger *slog.Logger) *chi.Mux {
 				opts...,
 			), "freeze_domain").ServeHTTP)
 
+			r.Post("/suspend", otelhttp.NewHandler(kithttp.NewServer(
+				suspendDomainEndpoint(svc),
+				decodeSuspendDomainRequest,
+				api.EncodeResponse,
+				opts...,
+			), "suspend_domain").ServeHTTP)
+
+			r.Post("/resume", otelhttp.NewHandler(kithttp.NewServer(
+				resumeDomainEndpoint(svc),
+				decodeResumeDomainRequest,
+				api.EncodeResponse,
+				opts...,
+			), "resume_domain").ServeHTTP)
+
+			r.Post("/transfer", otelhttp.NewHandler(kithttp.NewServer(
+				transferOwnershipEndpoint(svc),
+				decodeTransferOwnershipRequest,
+				api.EncodeResponse,
+				opts...,
+			), "transfer_ownership").ServeHTTP)
+
+			r.Route("/policies", func(r chi.Router) {
+				r.Get("/export", otelhttp.NewHandler(kithttp.NewServer(
+					exportPoliciesEndpoint(svc),
+					decodeExportPoliciesRequest,
+					api.EncodeResponse,
+					opts...,
+				), "export_policies").ServeHTTP)
+
+				r.Post("/import", otelhttp.NewHandler(kithttp.NewServer(
+					importPoliciesEndpoint(svc),
+					decodeImportPoliciesRequest,
+					api.EncodeResponse,
+					opts...,
+				), "import_policies").ServeHTTP)
+			})
+
 			r.Route("/users", func(r chi.Router) {
 				r.Post("/assign", otelhttp.NewHandler(kithttp.NewServer(
 					assignDomainUsersEndpoint(svc),
@@ -94,6 +131,12 @@ func MakeHandler(svc auth.Service, mux *chi.Mux, logger *slog.Logger) *chi.Mux {
 			})
 		})
 	})
+	mux.Get("/users/profile/domains", otelhttp.NewHandler(kithttp.NewServer(
+		listProfileDomainsEndpoint(svc),
+		decodeListProfileDomainsRequest,
+		api.EncodeResponse,
+		opts...,
+	), "list_profile_domains").ServeHTTP)
 	mux.Get("/users/{userID}/domains", otelhttp.NewHandler(kithttp.NewServer(
 		listUserDomainsEndpoint(svc),
 		decodeListUserDomainsRequest,
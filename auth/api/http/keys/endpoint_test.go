@@ -5,6 +5,9 @@ package keys_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,8 +26,11 @@ import (
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
 	"github.com/absmach/magistrala/pkg/uuid"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -76,11 +82,11 @@ func newService() (auth.Service, *mocks.KeyRepository) {
 
 	t := jwt.New([]byte(secret))
 
-	return auth.New(krepo, drepo, idProvider, t, pEvaluator, pService, loginDuration, refreshDuration, invalidDuration), krepo
+	return auth.New(krepo, drepo, idProvider, t, pEvaluator, pService, loginDuration, refreshDuration, invalidDuration, 0, nil), krepo
 }
 
 func newServer(svc auth.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(svc, mglog.NewMock(), "")
+	mux := httpapi.MakeHandler(svc, mglog.NewMock(), "", nil)
 	return httptest.NewServer(mux)
 }
 
@@ -336,3 +342,58 @@ func TestRevoke(t *testing.T) {
 		repocall.Unset()
 	}
 }
+
+func TestJWKS(t *testing.T) {
+	svc, _ := newService()
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generating signing key expected to succeed: %s", err))
+	signer, err := jwk.FromRaw(raw)
+	require.Nil(t, err, fmt.Sprintf("creating signing key expected to succeed: %s", err))
+	require.Nil(t, signer.Set(jwk.KeyIDKey, "region-a"))
+	require.Nil(t, signer.Set(jwk.AlgorithmKey, jwa.ES256))
+	jwks, err := jwt.PublicKeys(signer)
+	require.Nil(t, err, fmt.Sprintf("creating JWKS expected to succeed: %s", err))
+
+	cases := []struct {
+		desc string
+		jwks jwk.Set
+		kids []string
+	}{
+		{
+			desc: "get JWKS with signing key",
+			jwks: jwks,
+			kids: []string{"region-a"},
+		},
+		{
+			desc: "get JWKS without signing key",
+			jwks: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(httpapi.MakeHandler(svc, mglog.NewMock(), "", tc.jwks))
+			defer ts.Close()
+
+			res, err := http.Get(ts.URL + "/.well-known/jwks.json")
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			defer res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusOK, res.StatusCode))
+			assert.Equal(t, "application/jwk-set+json", res.Header.Get("Content-Type"), fmt.Sprintf("%s: unexpected content type", tc.desc))
+
+			body, err := io.ReadAll(res.Body)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			set, err := jwk.Parse(body)
+			require.Nil(t, err, fmt.Sprintf("%s: parsing JWKS expected to succeed: %s", tc.desc, err))
+			var kids []string
+			for i := 0; i < set.Len(); i++ {
+				key, _ := set.Key(i)
+				private, err := jwk.IsPrivateKey(key)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.False(t, private, fmt.Sprintf("%s: expected only public keys", tc.desc))
+				kids = append(kids, key.KeyID())
+			}
+			assert.Equal(t, tc.kids, kids, fmt.Sprintf("%s: expected key IDs %v got %v", tc.desc, tc.kids, kids))
+		})
+	}
+}
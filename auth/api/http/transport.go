@@ -3,26 +3,48 @@
 package http
 
 import (
+	"encoding/json"
 	"log/slog"
 	"net/http"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/auth/api/http/decisions"
 	"github.com/absmach/magistrala/auth/api/http/domains"
 	"github.com/absmach/magistrala/auth/api/http/keys"
 	"github.com/go-chi/chi/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc auth.Service, logger *slog.Logger, instanceID string) http.Handler {
+const jwksContentType = "application/jwk-set+json"
+
+// MakeHandler returns a HTTP handler for API endpoints. The public keys
+// signing the tokens are served as the JSON Web Key Set the peer regions
+// validate the tokens with.
+func MakeHandler(svc auth.Service, logger *slog.Logger, instanceID string, jwks jwk.Set) http.Handler {
 	mux := chi.NewRouter()
 
 	mux = keys.MakeHandler(svc, mux, logger)
 	mux = domains.MakeHandler(svc, mux, logger)
+	mux = decisions.MakeHandler(svc, mux, logger)
 
+	mux.Get("/.well-known/jwks.json", jwksHandler(jwks, logger))
 	mux.Get("/health", magistrala.Health("auth", instanceID))
 	mux.Handle("/metrics", promhttp.Handler())
 
 	return mux
 }
+
+func jwksHandler(jwks jwk.Set, logger *slog.Logger) http.HandlerFunc {
+	if jwks == nil {
+		jwks = jwk.NewSet()
+	}
+
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", jwksContentType)
+		if err := json.NewEncoder(w).Encode(jwks); err != nil {
+			logger.Warn("failed to encode JWKS", slog.Any("error", err))
+		}
+	}
+}
@@ -125,6 +125,31 @@ func (lm *loggingMiddleware) Authorize(ctx context.Context, pr policies.Policy)
 	return lm.svc.Authorize(ctx, pr)
 }
 
+func (lm *loggingMiddleware) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (d policies.Decision, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("object",
+				slog.String("id", pr.Object),
+				slog.String("type", pr.ObjectType),
+			),
+			slog.Group("subject",
+				slog.String("id", pr.Subject),
+				slog.String("type", pr.SubjectType),
+			),
+			slog.String("permission", pr.Permission),
+			slog.Bool("allowed", d.Allowed),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Explain policy failed", args...)
+			return
+		}
+		lm.logger.Info("Explain policy completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ExplainPolicy(ctx, token, pr)
+}
+
 func (lm *loggingMiddleware) CreateDomain(ctx context.Context, token string, d auth.Domain) (do auth.Domain, err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -215,6 +240,45 @@ func (lm *loggingMiddleware) ChangeDomainStatus(ctx context.Context, token, id s
 	return lm.svc.ChangeDomainStatus(ctx, token, id, d)
 }
 
+func (lm *loggingMiddleware) SuspendDomain(ctx context.Context, token, id string) (do auth.Domain, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("domain",
+				slog.String("id", id),
+				slog.String("name", do.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Suspend domain failed", args...)
+			return
+		}
+		lm.logger.Info("Suspend domain completed successfully", args...)
+	}(time.Now())
+	return lm.svc.SuspendDomain(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) ResumeDomain(ctx context.Context, token, id string) (do auth.Domain, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("domain",
+				slog.String("id", id),
+				slog.String("name", do.Name),
+				slog.Any("status", do.Status),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Resume domain failed", args...)
+			return
+		}
+		lm.logger.Info("Resume domain completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ResumeDomain(ctx, token, id)
+}
+
 func (lm *loggingMiddleware) ListDomains(ctx context.Context, token string, page auth.Page) (do auth.DomainsPage, err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -235,7 +299,7 @@ func (lm *loggingMiddleware) ListDomains(ctx context.Context, token string, page
 	return lm.svc.ListDomains(ctx, token, page)
 }
 
-func (lm *loggingMiddleware) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string) (err error) {
+func (lm *loggingMiddleware) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string, conditions []string) (err error) {
 	defer func(begin time.Time) {
 		args := []any{
 			slog.String("duration", time.Since(begin).String()),
@@ -243,6 +307,9 @@ func (lm *loggingMiddleware) AssignUsers(ctx context.Context, token, id string,
 			slog.String("relation", relation),
 			slog.Any("user_ids", userIds),
 		}
+		if len(conditions) > 0 {
+			args = append(args, slog.Any("conditions", conditions))
+		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
 			lm.logger.Warn("Assign users to domain failed", args...)
@@ -250,7 +317,7 @@ func (lm *loggingMiddleware) AssignUsers(ctx context.Context, token, id string,
 		}
 		lm.logger.Info("Assign users to domain completed successfully", args...)
 	}(time.Now())
-	return lm.svc.AssignUsers(ctx, token, id, userIds, relation)
+	return lm.svc.AssignUsers(ctx, token, id, userIds, relation, conditions)
 }
 
 func (lm *loggingMiddleware) UnassignUser(ctx context.Context, token, id, userID string) (err error) {
@@ -286,6 +353,70 @@ func (lm *loggingMiddleware) ListUserDomains(ctx context.Context, token, userID
 	return lm.svc.ListUserDomains(ctx, token, userID, page)
 }
 
+func (lm *loggingMiddleware) TransferOwnership(ctx context.Context, token, id string, req auth.TransferReq) (tr auth.TransferReport, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("domain_id", id),
+			slog.Group("transfer",
+				slog.String("from_user_id", req.FromUserID),
+				slog.String("to_user_id", req.ToUserID),
+				slog.Any("entity_types", req.EntityTypes),
+				slog.Bool("dry_run", req.DryRun),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Transfer ownership failed", args...)
+			return
+		}
+		lm.logger.Info("Transfer ownership completed successfully", args...)
+	}(time.Now())
+	return lm.svc.TransferOwnership(ctx, token, id, req)
+}
+
+func (lm *loggingMiddleware) ExportPolicies(ctx context.Context, token, id string) (pe auth.PolicyExport, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("domain_id", id),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Export policies failed", args...)
+			return
+		}
+		args = append(args, slog.Int("policies", len(pe.Policies)))
+		lm.logger.Info("Export policies completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ExportPolicies(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) ImportPolicies(ctx context.Context, token, id string, req auth.ImportReq) (ir auth.ImportReport, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("domain_id", id),
+			slog.Group("import",
+				slog.Int("policies", len(req.Policies)),
+				slog.Bool("dry_run", req.DryRun),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Import policies failed", args...)
+			return
+		}
+		args = append(args,
+			slog.Int("added", len(ir.Added)),
+			slog.Int("invalid", len(ir.Invalid)),
+			slog.Bool("applied", ir.Applied),
+		)
+		lm.logger.Info("Import policies completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ImportPolicies(ctx, token, id, req)
+}
+
 func (lm *loggingMiddleware) DeleteUserFromDomains(ctx context.Context, id string) (err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -75,6 +75,14 @@ func (ms *metricsMiddleware) Authorize(ctx context.Context, pr policies.Policy)
 	return ms.svc.Authorize(ctx, pr)
 }
 
+func (ms *metricsMiddleware) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "explain_policy").Add(1)
+		ms.latency.With("method", "explain_policy").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ExplainPolicy(ctx, token, pr)
+}
+
 func (ms *metricsMiddleware) CreateDomain(ctx context.Context, token string, d auth.Domain) (auth.Domain, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "create_domain").Add(1)
@@ -115,6 +123,22 @@ func (ms *metricsMiddleware) ChangeDomainStatus(ctx context.Context, token, id s
 	return ms.svc.ChangeDomainStatus(ctx, token, id, d)
 }
 
+func (ms *metricsMiddleware) SuspendDomain(ctx context.Context, token, id string) (auth.Domain, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "suspend_domain").Add(1)
+		ms.latency.With("method", "suspend_domain").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.SuspendDomain(ctx, token, id)
+}
+
+func (ms *metricsMiddleware) ResumeDomain(ctx context.Context, token, id string) (auth.Domain, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "resume_domain").Add(1)
+		ms.latency.With("method", "resume_domain").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ResumeDomain(ctx, token, id)
+}
+
 func (ms *metricsMiddleware) ListDomains(ctx context.Context, token string, page auth.Page) (auth.DomainsPage, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_domains").Add(1)
@@ -123,12 +147,12 @@ func (ms *metricsMiddleware) ListDomains(ctx context.Context, token string, page
 	return ms.svc.ListDomains(ctx, token, page)
 }
 
-func (ms *metricsMiddleware) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string) error {
+func (ms *metricsMiddleware) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string, conditions []string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "assign_users").Add(1)
 		ms.latency.With("method", "assign_users").Observe(time.Since(begin).Seconds())
 	}(time.Now())
-	return ms.svc.AssignUsers(ctx, token, id, userIds, relation)
+	return ms.svc.AssignUsers(ctx, token, id, userIds, relation, conditions)
 }
 
 func (ms *metricsMiddleware) UnassignUser(ctx context.Context, token, id, userID string) error {
@@ -147,6 +171,30 @@ func (ms *metricsMiddleware) ListUserDomains(ctx context.Context, token, userID
 	return ms.svc.ListUserDomains(ctx, token, userID, page)
 }
 
+func (ms *metricsMiddleware) TransferOwnership(ctx context.Context, token, id string, req auth.TransferReq) (auth.TransferReport, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "transfer_ownership").Add(1)
+		ms.latency.With("method", "transfer_ownership").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.TransferOwnership(ctx, token, id, req)
+}
+
+func (ms *metricsMiddleware) ExportPolicies(ctx context.Context, token, id string) (auth.PolicyExport, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "export_policies").Add(1)
+		ms.latency.With("method", "export_policies").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ExportPolicies(ctx, token, id)
+}
+
+func (ms *metricsMiddleware) ImportPolicies(ctx context.Context, token, id string, req auth.ImportReq) (auth.ImportReport, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "import_policies").Add(1)
+		ms.latency.With("method", "import_policies").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ImportPolicies(ctx, token, id, req)
+}
+
 func (ms *metricsMiddleware) DeleteUserFromDomains(ctx context.Context, id string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "delete_user_from_domains").Add(1)
@@ -25,21 +25,24 @@ const (
 	DisabledStatus
 	// FreezeStatus represents domain is in freezed state.
 	FreezeStatus
+	// SuspendedStatus represents domain suspended by the platform administrator.
+	SuspendedStatus
 
 	// AllStatus is used for querying purposes to list Domains irrespective
-	// of their status - enabled, disabled, freezed, deleting. It is never stored in the
-	// database as the actual domain status and should always be the larger than freeze status
+	// of their status - enabled, disabled, freezed, suspended. It is never stored in the
+	// database as the actual domain status and should always be the larger than suspended status
 	// value in this enumeration.
 	AllStatus
 )
 
 // String representation of the possible status values.
 const (
-	Disabled = "disabled"
-	Enabled  = "enabled"
-	Freezed  = "freezed"
-	All      = "all"
-	Unknown  = "unknown"
+	Disabled  = "disabled"
+	Enabled   = "enabled"
+	Freezed   = "freezed"
+	Suspended = "suspended"
+	All       = "all"
+	Unknown   = "unknown"
 )
 
 // String converts client/group status to string literal.
@@ -53,6 +56,8 @@ func (s Status) String() string {
 		return All
 	case FreezeStatus:
 		return Freezed
+	case SuspendedStatus:
+		return Suspended
 	default:
 		return Unknown
 	}
@@ -67,6 +72,8 @@ func ToStatus(status string) (Status, error) {
 		return DisabledStatus, nil
 	case Freezed:
 		return FreezeStatus, nil
+	case Suspended:
+		return SuspendedStatus, nil
 	case All:
 		return AllStatus, nil
 	}
@@ -87,24 +94,26 @@ func (s *Status) UnmarshalJSON(data []byte) error {
 }
 
 type DomainReq struct {
-	Name     *string           `json:"name,omitempty"`
-	Metadata *clients.Metadata `json:"metadata,omitempty"`
-	Tags     *[]string         `json:"tags,omitempty"`
-	Alias    *string           `json:"alias,omitempty"`
-	Status   *Status           `json:"status,omitempty"`
+	Name             *string           `json:"name,omitempty"`
+	Metadata         *clients.Metadata `json:"metadata,omitempty"`
+	Tags             *[]string         `json:"tags,omitempty"`
+	Alias            *string           `json:"alias,omitempty"`
+	Status           *Status           `json:"status,omitempty"`
+	SelfRegistration *bool             `json:"self_registration,omitempty"`
 }
 type Domain struct {
-	ID         string           `json:"id"`
-	Name       string           `json:"name"`
-	Metadata   clients.Metadata `json:"metadata,omitempty"`
-	Tags       []string         `json:"tags,omitempty"`
-	Alias      string           `json:"alias,omitempty"`
-	Status     Status           `json:"status"`
-	Permission string           `json:"permission,omitempty"`
-	CreatedBy  string           `json:"created_by,omitempty"`
-	CreatedAt  time.Time        `json:"created_at"`
-	UpdatedBy  string           `json:"updated_by,omitempty"`
-	UpdatedAt  time.Time        `json:"updated_at,omitempty"`
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	Metadata         clients.Metadata `json:"metadata,omitempty"`
+	Tags             []string         `json:"tags,omitempty"`
+	Alias            string           `json:"alias,omitempty"`
+	Status           Status           `json:"status"`
+	SelfRegistration bool             `json:"self_registration"`
+	Permission       string           `json:"permission,omitempty"`
+	CreatedBy        string           `json:"created_by,omitempty"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedBy        string           `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time        `json:"updated_at,omitempty"`
 }
 
 type Page struct {
@@ -155,16 +164,39 @@ type Policy struct {
 	ObjectID        string `json:"object_id,omitempty"`
 }
 
+// TransferReq represents the request to transfer the ownership of the domain
+// resources from one domain user to another. Channels are groups in the
+// authorization model, so the group entity type covers both.
+type TransferReq struct {
+	FromUserID  string
+	ToUserID    string
+	EntityTypes []string
+	DryRun      bool
+}
+
+// TransferReport contains the IDs of the transferred resources by their
+// entity type. In dry run mode, it contains the resources which would be
+// transferred.
+type TransferReport struct {
+	Resources map[string][]string `json:"resources"`
+	DryRun    bool                `json:"dry_run"`
+}
+
 type Domains interface {
 	CreateDomain(ctx context.Context, token string, d Domain) (Domain, error)
 	RetrieveDomain(ctx context.Context, token string, id string) (Domain, error)
 	RetrieveDomainPermissions(ctx context.Context, token string, id string) (policies.Permissions, error)
 	UpdateDomain(ctx context.Context, token string, id string, d DomainReq) (Domain, error)
 	ChangeDomainStatus(ctx context.Context, token string, id string, d DomainReq) (Domain, error)
+	SuspendDomain(ctx context.Context, token string, id string) (Domain, error)
+	ResumeDomain(ctx context.Context, token string, id string) (Domain, error)
 	ListDomains(ctx context.Context, token string, page Page) (DomainsPage, error)
-	AssignUsers(ctx context.Context, token string, id string, userIds []string, relation string) error
+	AssignUsers(ctx context.Context, token string, id string, userIds []string, relation string, conditions []string) error
 	UnassignUser(ctx context.Context, token string, id string, userID string) error
 	ListUserDomains(ctx context.Context, token string, userID string, page Page) (DomainsPage, error)
+	TransferOwnership(ctx context.Context, token string, id string, req TransferReq) (TransferReport, error)
+	ExportPolicies(ctx context.Context, token string, id string) (PolicyExport, error)
+	ImportPolicies(ctx context.Context, token string, id string, req ImportReq) (ImportReport, error)
 	DeleteUserFromDomains(ctx context.Context, id string) error
 }
 
@@ -187,6 +219,16 @@ type DomainsRepository interface {
 	// Update updates the client name and metadata.
 	Update(ctx context.Context, id string, userID string, d DomainReq) (Domain, error)
 
+	// RetrieveStatus retrieves the status of the Domain.
+	RetrieveStatus(ctx context.Context, id string) (Status, error)
+
+	// Suspend stores the current domain status as the prior status and
+	// marks the domain as suspended.
+	Suspend(ctx context.Context, id, userID string) (Domain, error)
+
+	// Resume restores the status the domain had before it was suspended.
+	Resume(ctx context.Context, id, userID string) (Domain, error)
+
 	// Delete
 	Delete(ctx context.Context, id string) error
 
@@ -0,0 +1,86 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"context"
+	"time"
+
+	"github.com/absmach/magistrala/auth"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/events"
+)
+
+const (
+	userPrefix          = "user."
+	userRemove          = userPrefix + "remove"
+	userDisableInactive = userPrefix + "disable_inactive"
+	userDelete          = userPrefix + "delete"
+)
+
+type eventHandler struct {
+	keys auth.KeyRepository
+}
+
+// NewEventHandler returns new event store handler, which revokes the keys
+// of the users that are disabled or deleted.
+func NewEventHandler(keys auth.KeyRepository) events.EventHandler {
+	return &eventHandler{
+		keys: keys,
+	}
+}
+
+func (es *eventHandler) Handle(ctx context.Context, event events.Event) error {
+	msg, err := event.Encode()
+	if err != nil {
+		return err
+	}
+
+	switch msg["operation"] {
+	case userRemove, userDisableInactive:
+		// Enabling the user publishes the same event, and it mustn't revoke
+		// the keys issued after the user was disabled.
+		switch events.Read(msg, "status", "") {
+		case mgclients.Disabled:
+			return es.revoke(ctx, msg, true)
+		case mgclients.Deleted:
+			return es.revoke(ctx, msg, false)
+		}
+	case userDelete:
+		return es.revoke(ctx, msg, false)
+	}
+
+	return nil
+}
+
+// revoke revokes the keys of the user issued before the user was disabled,
+// or updated otherwise. The current time is used if the event doesn't carry
+// the time.
+func (es *eventHandler) revoke(ctx context.Context, msg map[string]interface{}, disabled bool) error {
+	id := events.Read(msg, "id", "")
+	if id == "" {
+		return svcerr.ErrMalformedEntity
+	}
+	revokedAt := eventTime(msg, "updated_at")
+	if disabled {
+		if disabledAt := eventTime(msg, "disabled_at"); !disabledAt.IsZero() {
+			revokedAt = disabledAt
+		}
+	}
+	if revokedAt.IsZero() {
+		revokedAt = time.Now()
+	}
+
+	return es.keys.Revoke(ctx, auth.Revocation{Subject: id, RevokedAt: revokedAt, Disabled: disabled})
+}
+
+func eventTime(msg map[string]interface{}, key string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, events.Read(msg, key, ""))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
@@ -22,6 +22,8 @@ const (
 	domainAssign              = domainPrefix + "assign"
 	domainUnassign            = domainPrefix + "unassign"
 	domainUserList            = domainPrefix + "user_list"
+	domainTransferOwnership   = domainPrefix + "transfer_ownership"
+	domainImportPolicies      = domainPrefix + "import_policies"
 )
 
 var (
@@ -34,6 +36,8 @@ var (
 	_ events.Event = (*assignUsersEvent)(nil)
 	_ events.Event = (*unassignUsersEvent)(nil)
 	_ events.Event = (*listUserDomainsEvent)(nil)
+	_ events.Event = (*transferOwnershipEvent)(nil)
+	_ events.Event = (*importPoliciesEvent)(nil)
 )
 
 type createDomainEvent struct {
@@ -213,9 +217,10 @@ func (lde listDomainsEvent) Encode() (map[string]interface{}, error) {
 }
 
 type assignUsersEvent struct {
-	userIDs  []string
-	domainID string
-	relation string
+	userIDs    []string
+	domainID   string
+	relation   string
+	conditions []string
 }
 
 func (ase assignUsersEvent) Encode() (map[string]interface{}, error) {
@@ -225,6 +230,9 @@ func (ase assignUsersEvent) Encode() (map[string]interface{}, error) {
 		"domain_id": ase.domainID,
 		"relation":  ase.relation,
 	}
+	if len(ase.conditions) > 0 {
+		val["conditions"] = ase.conditions
+	}
 
 	return val, nil
 }
@@ -244,6 +252,40 @@ func (use unassignUsersEvent) Encode() (map[string]interface{}, error) {
 	return val, nil
 }
 
+type transferOwnershipEvent struct {
+	domainID   string
+	fromUserID string
+	toUserID   string
+	resources  map[string][]string
+}
+
+func (toe transferOwnershipEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation":    domainTransferOwnership,
+		"domain_id":    toe.domainID,
+		"from_user_id": toe.fromUserID,
+		"to_user_id":   toe.toUserID,
+		"resources":    toe.resources,
+	}
+
+	return val, nil
+}
+
+type importPoliciesEvent struct {
+	domainID string
+	added    int
+}
+
+func (ipe importPoliciesEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation": domainImportPolicies,
+		"domain_id": ipe.domainID,
+		"added":     ipe.added,
+	}
+
+	return val, nil
+}
+
 type listUserDomainsEvent struct {
 	auth.Page
 	userID string
@@ -124,6 +124,46 @@ func (es *eventStore) ChangeDomainStatus(ctx context.Context, token, id string,
 	return domain, nil
 }
 
+func (es *eventStore) SuspendDomain(ctx context.Context, token, id string) (auth.Domain, error) {
+	domain, err := es.svc.SuspendDomain(ctx, token, id)
+	if err != nil {
+		return domain, err
+	}
+
+	event := changeDomainStatusEvent{
+		domainID:  id,
+		status:    domain.Status,
+		updatedAt: domain.UpdatedAt,
+		updatedBy: domain.UpdatedBy,
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return domain, err
+	}
+
+	return domain, nil
+}
+
+func (es *eventStore) ResumeDomain(ctx context.Context, token, id string) (auth.Domain, error) {
+	domain, err := es.svc.ResumeDomain(ctx, token, id)
+	if err != nil {
+		return domain, err
+	}
+
+	event := changeDomainStatusEvent{
+		domainID:  id,
+		status:    domain.Status,
+		updatedAt: domain.UpdatedAt,
+		updatedBy: domain.UpdatedBy,
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return domain, err
+	}
+
+	return domain, nil
+}
+
 func (es *eventStore) ListDomains(ctx context.Context, token string, p auth.Page) (auth.DomainsPage, error) {
 	dp, err := es.svc.ListDomains(ctx, token, p)
 	if err != nil {
@@ -141,16 +181,17 @@ func (es *eventStore) ListDomains(ctx context.Context, token string, p auth.Page
 	return dp, nil
 }
 
-func (es *eventStore) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string) error {
-	err := es.svc.AssignUsers(ctx, token, id, userIds, relation)
+func (es *eventStore) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string, conditions []string) error {
+	err := es.svc.AssignUsers(ctx, token, id, userIds, relation, conditions)
 	if err != nil {
 		return err
 	}
 
 	event := assignUsersEvent{
-		domainID: id,
-		userIDs:  userIds,
-		relation: relation,
+		domainID:   id,
+		userIDs:    userIds,
+		relation:   relation,
+		conditions: conditions,
 	}
 
 	if err := es.Publish(ctx, event); err != nil {
@@ -196,6 +237,48 @@ func (es *eventStore) ListUserDomains(ctx context.Context, token, userID string,
 	return dp, nil
 }
 
+func (es *eventStore) TransferOwnership(ctx context.Context, token, id string, req auth.TransferReq) (auth.TransferReport, error) {
+	report, err := es.svc.TransferOwnership(ctx, token, id, req)
+	if err != nil || req.DryRun {
+		return report, err
+	}
+
+	event := transferOwnershipEvent{
+		domainID:   id,
+		fromUserID: req.FromUserID,
+		toUserID:   req.ToUserID,
+		resources:  report.Resources,
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func (es *eventStore) ExportPolicies(ctx context.Context, token, id string) (auth.PolicyExport, error) {
+	return es.svc.ExportPolicies(ctx, token, id)
+}
+
+func (es *eventStore) ImportPolicies(ctx context.Context, token, id string, req auth.ImportReq) (auth.ImportReport, error) {
+	report, err := es.svc.ImportPolicies(ctx, token, id, req)
+	if err != nil || !report.Applied {
+		return report, err
+	}
+
+	event := importPoliciesEvent{
+		domainID: id,
+		added:    len(report.Added),
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
 func (es *eventStore) Issue(ctx context.Context, token string, key auth.Key) (auth.Token, error) {
 	return es.svc.Issue(ctx, token, key)
 }
@@ -216,6 +299,10 @@ func (es *eventStore) Authorize(ctx context.Context, pr policies.Policy) error {
 	return es.svc.Authorize(ctx, pr)
 }
 
+func (es *eventStore) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error) {
+	return es.svc.ExplainPolicy(ctx, token, pr)
+}
+
 func (es *eventStore) DeleteUserFromDomains(ctx context.Context, id string) error {
 	return es.svc.DeleteUserFromDomains(ctx, id)
 }
@@ -0,0 +1,97 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"os"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+var (
+	// ErrSigningKey indicates an invalid token signing key.
+	ErrSigningKey = errors.New("invalid token signing key")
+
+	errUnsupportedKey = errors.New("unsupported signing key type")
+)
+
+// LoadSigningKey reads the PEM encoded RSA, ECDSA or Ed25519 private key
+// signing the tokens. The key ID defaults to the key thumbprint, so the
+// regions sharing their keys must not use the same ID for different keys.
+func LoadSigningKey(path, kid string) (jwk.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+	key, err := jwk.ParseKey(data, jwk.WithPEM(true))
+	if err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+	if private, err := jwk.IsPrivateKey(key); err != nil || !private {
+		return nil, errors.Wrap(ErrSigningKey, errors.New("not a private key"))
+	}
+	alg, err := algorithmOf(key)
+	if err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+	if err := key.Set(jwk.AlgorithmKey, alg); err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+	if kid == "" {
+		err = jwk.AssignKeyID(key)
+	} else {
+		err = key.Set(jwk.KeyIDKey, kid)
+	}
+	if err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+
+	return key, nil
+}
+
+// PublicKeys returns the JSON Web Key Set with the public part of the signing
+// key, which the other regions fetch to validate the tokens issued by this
+// one. The set is empty if the tokens are signed with the shared secret.
+func PublicKeys(signer jwk.Key) (jwk.Set, error) {
+	set := jwk.NewSet()
+	if signer == nil {
+		return set, nil
+	}
+	pub, err := signer.PublicKey()
+	if err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+	if err := pub.Set(jwk.KeyUsageKey, jwk.ForSignature); err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+	if err := set.AddKey(pub); err != nil {
+		return nil, errors.Wrap(ErrSigningKey, err)
+	}
+
+	return set, nil
+}
+
+func algorithmOf(key jwk.Key) (jwa.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case jwk.RSAPrivateKey:
+		return jwa.RS256, nil
+	case jwk.ECDSAPrivateKey:
+		switch k.Crv() {
+		case jwa.P256:
+			return jwa.ES256, nil
+		case jwa.P384:
+			return jwa.ES384, nil
+		case jwa.P521:
+			return jwa.ES512, nil
+		}
+	case jwk.OKPPrivateKey:
+		if k.Crv() == jwa.Ed25519 {
+			return jwa.EdDSA, nil
+		}
+	}
+
+	return "", errUnsupportedKey
+}
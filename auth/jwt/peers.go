@@ -0,0 +1,160 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+const (
+	fetchTimeout = 10 * time.Second
+	maxJWKSSize  = 1 << 20
+)
+
+var errFetchJWKS = errors.New("failed to fetch peer JWKS")
+
+// PeerKeys holds the public keys of the peer regions, fetched from their JWKS
+// endpoints, so the tokens issued by the peers are accepted. The keys are
+// refreshed periodically. If a peer can't be reached, its last fetched keys
+// are kept, and the tokens signed by the local keys are never affected.
+type PeerKeys struct {
+	urls     []string
+	interval time.Duration
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu   sync.RWMutex
+	sets map[string]jwk.Set
+}
+
+// NewPeerKeys returns the keys of the peers with the given JWKS endpoints,
+// refreshed every interval once running.
+func NewPeerKeys(urls []string, interval time.Duration, logger *slog.Logger) *PeerKeys {
+	return &PeerKeys{
+		urls:     urls,
+		interval: interval,
+		client:   &http.Client{Timeout: fetchTimeout},
+		logger:   logger,
+		sets:     make(map[string]jwk.Set),
+	}
+}
+
+// Run fetches the peer keys, then refreshes them periodically until the
+// context is canceled.
+func (pk *PeerKeys) Run(ctx context.Context) {
+	if len(pk.urls) == 0 {
+		return
+	}
+	if err := pk.Refresh(ctx); err != nil {
+		pk.logger.Warn("failed to fetch peer keys", slog.Any("error", err))
+	}
+	if pk.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(pk.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pk.Refresh(ctx); err != nil {
+				pk.logger.Warn("failed to refresh peer keys", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Refresh fetches the keys of all the peers. The keys of the peers which
+// can't be fetched are left as they are, and the error of the last failed
+// fetch is returned.
+func (pk *PeerKeys) Refresh(ctx context.Context) error {
+	var err error
+	for _, url := range pk.urls {
+		set, ferr := pk.fetch(ctx, url)
+		if ferr != nil {
+			err = errors.Wrap(errFetchJWKS, errors.Wrap(fmt.Errorf("peer %s", url), ferr))
+			continue
+		}
+		pk.mu.Lock()
+		pk.sets[url] = set
+		pk.mu.Unlock()
+	}
+
+	return err
+}
+
+// LookupKeyID returns the peer key with the given ID.
+func (pk *PeerKeys) LookupKeyID(kid string) (jwk.Key, bool) {
+	if pk == nil || kid == "" {
+		return nil, false
+	}
+	pk.mu.RLock()
+	defer pk.mu.RUnlock()
+
+	for _, url := range pk.urls {
+		set, ok := pk.sets[url]
+		if !ok {
+			continue
+		}
+		if key, ok := set.LookupKeyID(kid); ok {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+func (pk *PeerKeys) fetch(ctx context.Context, url string) (jwk.Set, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := pk.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxJWKSSize))
+	if err != nil {
+		return nil, err
+	}
+	set, err := jwk.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	// Only the public keys with the algorithm set can validate the tokens,
+	// so a peer can't make the shared secret or a private key trusted.
+	public := jwk.NewSet()
+	for i := 0; i < set.Len(); i++ {
+		key, _ := set.Key(i)
+		if key.KeyID() == "" || key.Algorithm().String() == "" {
+			continue
+		}
+		if private, err := jwk.IsPrivateKey(key); err != nil || private {
+			continue
+		}
+		if err := public.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return public, nil
+}
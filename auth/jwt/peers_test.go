@@ -0,0 +1,299 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package jwt_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/absmach/magistrala/auth"
+	authjwt "github.com/absmach/magistrala/auth/jwt"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSigningKey(t *testing.T, raw interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(raw)
+	require.Nil(t, err, fmt.Sprintf("marshaling signing key expected to succeed: %s", err))
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	require.Nil(t, err, fmt.Sprintf("writing signing key expected to succeed: %s", err))
+
+	return path
+}
+
+func newSigningKey(t *testing.T, kid string) jwk.Key {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generating signing key expected to succeed: %s", err))
+	key, err := authjwt.LoadSigningKey(writeSigningKey(t, raw), kid)
+	require.Nil(t, err, fmt.Sprintf("loading signing key expected to succeed: %s", err))
+
+	return key
+}
+
+// jwksServer serves the JWKS of the signing key, or fails while failing is set.
+func jwksServer(t *testing.T, signer jwk.Key, failing *atomic.Bool) *httptest.Server {
+	jwks, err := authjwt.PublicKeys(signer)
+	require.Nil(t, err, fmt.Sprintf("creating JWKS expected to succeed: %s", err))
+	data, err := json.Marshal(jwks)
+	require.Nil(t, err, fmt.Sprintf("encoding JWKS expected to succeed: %s", err))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generating EC key expected to succeed: %s", err))
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generating Ed25519 key expected to succeed: %s", err))
+	public := filepath.Join(t.TempDir(), "public.pem")
+	der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	require.Nil(t, err, fmt.Sprintf("marshaling public key expected to succeed: %s", err))
+	err = os.WriteFile(public, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)
+	require.Nil(t, err, fmt.Sprintf("writing public key expected to succeed: %s", err))
+
+	cases := []struct {
+		desc string
+		path string
+		kid  string
+		alg  string
+		err  error
+	}{
+		{
+			desc: "load EC key with key ID",
+			path: writeSigningKey(t, ecKey),
+			kid:  "region-a",
+			alg:  "ES384",
+		},
+		{
+			desc: "load Ed25519 key without key ID",
+			path: writeSigningKey(t, edKey),
+			alg:  "EdDSA",
+		},
+		{
+			desc: "load public key",
+			path: public,
+			err:  authjwt.ErrSigningKey,
+		},
+		{
+			desc: "load missing key",
+			path: filepath.Join(t.TempDir(), "missing.pem"),
+			err:  authjwt.ErrSigningKey,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			key, err := authjwt.LoadSigningKey(tc.path, tc.kid)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.alg, key.Algorithm().String(), fmt.Sprintf("%s: expected algorithm %s got %s", tc.desc, tc.alg, key.Algorithm()))
+			if tc.kid != "" {
+				assert.Equal(t, tc.kid, key.KeyID(), fmt.Sprintf("%s: expected key ID %s got %s", tc.desc, tc.kid, key.KeyID()))
+			}
+			assert.NotEmpty(t, key.KeyID(), fmt.Sprintf("%s: expected key ID to be set", tc.desc))
+		})
+	}
+}
+
+func TestParseForeignRegionToken(t *testing.T) {
+	signerA := newSigningKey(t, "region-a")
+	regionA, err := authjwt.NewWithKeys([]byte("secret-a"), signerA, nil)
+	require.Nil(t, err, fmt.Sprintf("creating region A tokenizer expected to succeed: %s", err))
+
+	ts := jwksServer(t, signerA, new(atomic.Bool))
+	peers := authjwt.NewPeerKeys([]string{ts.URL}, 0, mglog.NewMock())
+	err = peers.Refresh(context.Background())
+	require.Nil(t, err, fmt.Sprintf("fetching region A keys expected to succeed: %s", err))
+	regionB, err := authjwt.NewWithKeys([]byte("secret-b"), newSigningKey(t, "region-b"), peers)
+	require.Nil(t, err, fmt.Sprintf("creating region B tokenizer expected to succeed: %s", err))
+
+	foreignToken, err := regionA.Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing region A token expected to succeed: %s", err))
+	localToken, err := regionB.Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing region B token expected to succeed: %s", err))
+	secretToken, err := authjwt.New([]byte("secret-b")).Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing region B secret token expected to succeed: %s", err))
+	unknownToken, err := authjwt.New([]byte("secret-c")).Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing unknown secret token expected to succeed: %s", err))
+	unknownRegion, err := authjwt.NewWithKeys([]byte("secret-c"), newSigningKey(t, "region-c"), nil)
+	require.Nil(t, err, fmt.Sprintf("creating region C tokenizer expected to succeed: %s", err))
+	unknownRegionToken, err := unknownRegion.Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing region C token expected to succeed: %s", err))
+	// The token signed by an unknown key with the ID of a trusted key.
+	spoofed, err := authjwt.NewWithKeys([]byte("secret-c"), newSigningKey(t, "region-a"), nil)
+	require.Nil(t, err, fmt.Sprintf("creating spoofing tokenizer expected to succeed: %s", err))
+	spoofedToken, err := spoofed.Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing spoofed token expected to succeed: %s", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		err   error
+	}{
+		{
+			desc:  "parse token issued by peer region",
+			token: foreignToken,
+		},
+		{
+			desc:  "parse token issued by local region",
+			token: localToken,
+		},
+		{
+			desc:  "parse token signed with local secret",
+			token: secretToken,
+		},
+		{
+			desc:  "parse token signed with unknown secret",
+			token: unknownToken,
+			err:   svcerr.ErrAuthentication,
+		},
+		{
+			desc:  "parse token issued by unknown region",
+			token: unknownRegionToken,
+			err:   authjwt.ErrUnknownSigningKey,
+		},
+		{
+			desc:  "parse token signed with spoofed key ID",
+			token: spoofedToken,
+			err:   svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			parsed, err := regionB.Parse(tc.token)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err != nil {
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected error got none", tc.desc))
+				return
+			}
+			assert.Equal(t, key(), parsed, fmt.Sprintf("%s: expected %v got %v", tc.desc, key(), parsed))
+		})
+	}
+
+	// The region A tokenizer doesn't trust region B.
+	_, err = regionA.Parse(localToken)
+	assert.True(t, errors.Contains(err, authjwt.ErrUnknownSigningKey), fmt.Sprintf("parse region B token in region A: expected %s got %s", authjwt.ErrUnknownSigningKey, err))
+}
+
+func TestPeerKeysFetchFailure(t *testing.T) {
+	signerA := newSigningKey(t, "region-a")
+	regionA, err := authjwt.NewWithKeys([]byte("secret-a"), signerA, nil)
+	require.Nil(t, err, fmt.Sprintf("creating region A tokenizer expected to succeed: %s", err))
+	foreignToken, err := regionA.Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing region A token expected to succeed: %s", err))
+
+	failing := new(atomic.Bool)
+	failing.Store(true)
+	ts := jwksServer(t, signerA, failing)
+	peers := authjwt.NewPeerKeys([]string{ts.URL, "http://127.0.0.1:0/jwks.json"}, 0, mglog.NewMock())
+	regionB, err := authjwt.NewWithKeys([]byte("secret-b"), newSigningKey(t, "region-b"), peers)
+	require.Nil(t, err, fmt.Sprintf("creating region B tokenizer expected to succeed: %s", err))
+	localToken, err := regionB.Issue(key())
+	require.Nil(t, err, fmt.Sprintf("issuing region B token expected to succeed: %s", err))
+
+	cases := []struct {
+		desc       string
+		failing    bool
+		refreshErr bool
+		token      string
+		err        error
+	}{
+		{
+			desc:       "parse local token with peers unreachable",
+			failing:    true,
+			refreshErr: true,
+			token:      localToken,
+		},
+		{
+			desc:       "parse peer token with peers unreachable",
+			failing:    true,
+			refreshErr: true,
+			token:      foreignToken,
+			err:        authjwt.ErrUnknownSigningKey,
+		},
+		{
+			desc:       "parse peer token once fetched",
+			failing:    false,
+			refreshErr: true,
+			token:      foreignToken,
+		},
+		{
+			desc:       "parse peer token with cached keys after peer failure",
+			failing:    true,
+			refreshErr: true,
+			token:      foreignToken,
+		},
+		{
+			desc:       "parse local token after peer failure",
+			failing:    true,
+			refreshErr: true,
+			token:      localToken,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			failing.Store(tc.failing)
+			err := peers.Refresh(context.Background())
+			assert.Equal(t, tc.refreshErr, err != nil, fmt.Sprintf("%s: expected refresh error %t got %s", tc.desc, tc.refreshErr, err))
+
+			parsed, err := regionB.Parse(tc.token)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.Equal(t, auth.AccessKey, parsed.Type, fmt.Sprintf("%s: expected %s got %s", tc.desc, auth.AccessKey, parsed.Type))
+			}
+		})
+	}
+}
+
+func TestPeerKeysIgnoreSecretKeys(t *testing.T) {
+	secretKey, err := jwk.FromRaw([]byte("secret-a"))
+	require.Nil(t, err, fmt.Sprintf("creating secret key expected to succeed: %s", err))
+	require.Nil(t, secretKey.Set(jwk.KeyIDKey, "region-a"))
+	require.Nil(t, secretKey.Set(jwk.AlgorithmKey, "HS512"))
+	set := jwk.NewSet()
+	require.Nil(t, set.AddKey(secretKey))
+	data, err := json.Marshal(set)
+	require.Nil(t, err, fmt.Sprintf("encoding JWKS expected to succeed: %s", err))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer ts.Close()
+
+	peers := authjwt.NewPeerKeys([]string{ts.URL}, 0, mglog.NewMock())
+	err = peers.Refresh(context.Background())
+	require.Nil(t, err, fmt.Sprintf("fetching peer keys expected to succeed: %s", err))
+	_, ok := peers.LookupKeyID("region-a")
+	assert.False(t, ok, "expected the secret peer key to be ignored")
+}
@@ -162,6 +162,21 @@ func TestParse(t *testing.T) {
 	emptyToken, err := tokenizer.Issue(emptyKey)
 	require.Nil(t, err, fmt.Sprintf("issuing user key expected to succeed: %s", err))
 
+	audienceKey := key()
+	audienceKey.Audience = "users"
+	audienceToken, err := tokenizer.Issue(audienceKey)
+	require.Nil(t, err, fmt.Sprintf("issuing key with audience expected to succeed: %s", err))
+
+	boundKey := key()
+	boundKey.Binding = "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I"
+	boundToken, err := tokenizer.Issue(boundKey)
+	require.Nil(t, err, fmt.Sprintf("issuing bound key expected to succeed: %s", err))
+
+	claimsKey := key()
+	claimsKey.Claims = map[string]string{"region": "eu", "tier": "gold"}
+	claimsToken, err := tokenizer.Issue(claimsKey)
+	require.Nil(t, err, fmt.Sprintf("issuing key with claims expected to succeed: %s", err))
+
 	inValidToken := newToken("invalid", key())
 
 	cases := []struct {
@@ -224,6 +239,24 @@ func TestParse(t *testing.T) {
 			token: emptyToken,
 			err:   nil,
 		},
+		{
+			desc:  "parse token with audience",
+			key:   audienceKey,
+			token: audienceToken,
+			err:   nil,
+		},
+		{
+			desc:  "parse bound token",
+			key:   boundKey,
+			token: boundToken,
+			err:   nil,
+		},
+		{
+			desc:  "parse token with claims",
+			key:   claimsKey,
+			token: claimsToken,
+			err:   nil,
+		},
 	}
 
 	for _, tc := range cases {
@@ -13,6 +13,8 @@ import (
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
@@ -26,6 +28,9 @@ var (
 	ErrValidateJWTToken = errors.New("failed to validate jwt token")
 	// ErrJSONHandle indicates an error in handling JSON.
 	ErrJSONHandle = errors.New("failed to perform operation JSON")
+	// ErrUnknownSigningKey indicates that the token is signed by a key
+	// which is neither local nor fetched from a peer.
+	ErrUnknownSigningKey = errors.New("unknown token signing key")
 )
 
 const (
@@ -35,10 +40,17 @@ const (
 	oauthProviderField     = "oauth_provider"
 	oauthAccessTokenField  = "access_token"
 	oauthRefreshTokenField = "refresh_token"
+	// confirmationField carries the JWK thumbprint the token is bound to, as in RFC 9449.
+	confirmationField = "cnf"
+	thumbprintField   = "jkt"
+	claimsField       = "claims"
 )
 
 type tokenizer struct {
 	secret []byte
+	signer jwk.Key
+	public jwk.Key
+	peers  *PeerKeys
 }
 
 var _ auth.Tokenizer = (*tokenizer)(nil)
@@ -50,6 +62,27 @@ func New(secret []byte) auth.Tokenizer {
 	}
 }
 
+// NewWithKeys instantiates the tokenizer signing the tokens with the private
+// key, if set, instead of the secret. Besides the tokens signed with the
+// secret or the key, the tokens signed with the peer keys are accepted, so
+// the tokens issued in one region are valid in the others.
+func NewWithKeys(secret []byte, signer jwk.Key, peers *PeerKeys) (auth.Tokenizer, error) {
+	tok := &tokenizer{
+		secret: secret,
+		peers:  peers,
+	}
+	if signer != nil {
+		public, err := signer.PublicKey()
+		if err != nil {
+			return nil, errors.Wrap(ErrSigningKey, err)
+		}
+		tok.signer = signer
+		tok.public = public
+	}
+
+	return tok, nil
+}
+
 func (tok *tokenizer) Issue(key auth.Key) (string, error) {
 	builder := jwt.NewBuilder()
 	builder.
@@ -58,6 +91,15 @@ func (tok *tokenizer) Issue(key auth.Key) (string, error) {
 		Claim(tokenType, key.Type).
 		Expiration(key.ExpiresAt)
 	builder.Claim(userField, key.User)
+	if key.Audience != "" {
+		builder.Audience([]string{key.Audience})
+	}
+	if key.Binding != "" {
+		builder.Claim(confirmationField, map[string]string{thumbprintField: key.Binding})
+	}
+	if len(key.Claims) > 0 {
+		builder.Claim(claimsField, key.Claims)
+	}
 	if key.Subject != "" {
 		builder.Subject(key.Subject)
 	}
@@ -68,7 +110,11 @@ func (tok *tokenizer) Issue(key auth.Key) (string, error) {
 	if err != nil {
 		return "", errors.Wrap(svcerr.ErrAuthentication, err)
 	}
-	signedTkn, err := jwt.Sign(tkn, jwt.WithKey(jwa.HS512, tok.secret))
+	signKey := jwt.WithKey(jwa.HS512, tok.secret)
+	if tok.signer != nil {
+		signKey = jwt.WithKey(tok.signer.Algorithm(), tok.signer)
+	}
+	signedTkn, err := jwt.Sign(tkn, signKey)
 	if err != nil {
 		return "", errors.Wrap(ErrSignJWT, err)
 	}
@@ -90,10 +136,14 @@ func (tok *tokenizer) Parse(token string) (auth.Key, error) {
 }
 
 func (tok *tokenizer) validateToken(token string) (jwt.Token, error) {
+	verifyKey, err := tok.verificationKey(token)
+	if err != nil {
+		return nil, err
+	}
 	tkn, err := jwt.Parse(
 		[]byte(token),
 		jwt.WithValidate(true),
-		jwt.WithKey(jwa.HS512, tok.secret),
+		verifyKey,
 	)
 	if err != nil {
 		if errors.Contains(err, errJWTExpiryKey) {
@@ -115,6 +165,34 @@ func (tok *tokenizer) validateToken(token string) (jwt.Token, error) {
 	return tkn, nil
 }
 
+// verificationKey returns the key verifying the token signature. The token
+// signed with the secret is verified with the secret, and any other token
+// with the local or the peer key its header refers to. The algorithm of the
+// key must match the algorithm of the token.
+func (tok *tokenizer) verificationKey(token string) (jwt.ParseOption, error) {
+	msg, err := jws.ParseString(token)
+	if err != nil {
+		return nil, err
+	}
+	sigs := msg.Signatures()
+	if len(sigs) != 1 {
+		return nil, ErrValidateJWTToken
+	}
+	headers := sigs[0].ProtectedHeaders()
+	if headers.Algorithm() == jwa.HS512 {
+		return jwt.WithKey(jwa.HS512, tok.secret), nil
+	}
+	kid := headers.KeyID()
+	if tok.public != nil && kid == tok.public.KeyID() {
+		return jwt.WithKey(tok.public.Algorithm(), tok.public), nil
+	}
+	if key, ok := tok.peers.LookupKeyID(kid); ok {
+		return jwt.WithKey(key.Algorithm(), key), nil
+	}
+
+	return nil, ErrUnknownSigningKey
+}
+
 func toKey(tkn jwt.Token) (auth.Key, error) {
 	data, err := json.Marshal(tkn.PrivateClaims())
 	if err != nil {
@@ -124,6 +202,14 @@ func toKey(tkn jwt.Token) (auth.Key, error) {
 	if err := json.Unmarshal(data, &key); err != nil {
 		return auth.Key{}, errors.Wrap(ErrJSONHandle, err)
 	}
+	var cnf struct {
+		Confirmation struct {
+			Thumbprint string `json:"jkt"`
+		} `json:"cnf"`
+	}
+	if err := json.Unmarshal(data, &cnf); err != nil {
+		return auth.Key{}, errors.Wrap(ErrJSONHandle, err)
+	}
 
 	tType, ok := tkn.Get(tokenType)
 	if !ok {
@@ -138,6 +224,10 @@ func toKey(tkn jwt.Token) (auth.Key, error) {
 	key.Type = auth.KeyType(ktype)
 	key.Issuer = tkn.Issuer()
 	key.Subject = tkn.Subject()
+	if aud := tkn.Audience(); len(aud) > 0 {
+		key.Audience = aud[0]
+	}
+	key.Binding = cnf.Confirmation.Thumbprint
 	key.IssuedAt = tkn.IssuedAt()
 	key.ExpiresAt = tkn.Expiration()
 
@@ -10,8 +10,19 @@ import (
 	"time"
 )
 
-// ErrKeyExpired indicates that the Key is expired.
-var ErrKeyExpired = errors.New("use of expired key")
+var (
+	// ErrKeyExpired indicates that the Key is expired.
+	ErrKeyExpired = errors.New("use of expired key")
+
+	// ErrInvalidAudience indicates that the Key is not issued for the audience.
+	ErrInvalidAudience = errors.New("invalid key audience")
+
+	// ErrInvalidBinding indicates that the Key is bound to a different client.
+	ErrInvalidBinding = errors.New("invalid key binding")
+
+	// ErrKeyRevoked indicates that the Key is revoked.
+	ErrKeyRevoked = errors.New("use of revoked key")
+)
 
 type Token struct {
 	AccessToken  string // AccessToken contains the security credentials for a login session and identifies the client.
@@ -51,14 +62,17 @@ func (kt KeyType) String() string {
 
 // Key represents API key.
 type Key struct {
-	ID        string    `json:"id,omitempty"`
-	Type      KeyType   `json:"type,omitempty"`
-	Issuer    string    `json:"issuer,omitempty"`
-	Subject   string    `json:"subject,omitempty"` // user ID
-	User      string    `json:"user,omitempty"`
-	Domain    string    `json:"domain,omitempty"` // domain user ID
-	IssuedAt  time.Time `json:"issued_at,omitempty"`
-	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	ID        string            `json:"id,omitempty"`
+	Type      KeyType           `json:"type,omitempty"`
+	Issuer    string            `json:"issuer,omitempty"`
+	Subject   string            `json:"subject,omitempty"` // user ID
+	User      string            `json:"user,omitempty"`
+	Domain    string            `json:"domain,omitempty"` // domain user ID
+	Audience  string            `json:"audience,omitempty"`
+	Binding   string            `json:"binding,omitempty"` // fingerprint of the client the key is bound to
+	Claims    map[string]string `json:"claims,omitempty"`  // custom claims the conditions of the policies are evaluated against
+	IssuedAt  time.Time         `json:"issued_at,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at,omitempty"`
 }
 
 func (key Key) String() string {
@@ -69,9 +83,11 @@ func (key Key) String() string {
 	subject: %s,
 	user: %s,
 	domain: %s,
+	audience: %s,
+	binding: %s,
 	iat: %v,
 	eat: %v
-}`, key.ID, key.Type, key.Issuer, key.Subject, key.User, key.Domain, key.IssuedAt, key.ExpiresAt)
+}`, key.ID, key.Type, key.Issuer, key.Subject, key.User, key.Domain, key.Audience, key.Binding, key.IssuedAt, key.ExpiresAt)
 }
 
 // Expired verifies if the key is expired.
@@ -82,6 +98,20 @@ func (key Key) Expired() bool {
 	return key.ExpiresAt.UTC().Before(time.Now().UTC())
 }
 
+// IntendedFor verifies if the key may be used by the audience. Keys issued
+// without an audience may be used by any audience, and any key may be used
+// if the audience is not specified.
+func (key Key) IntendedFor(audience string) bool {
+	return audience == "" || key.Audience == "" || key.Audience == audience
+}
+
+// BoundTo verifies if the key may be presented by the client with the given
+// fingerprint. Keys issued without a binding may be presented by any client,
+// while bound keys may be presented only by the client they are bound to.
+func (key Key) BoundTo(binding string) bool {
+	return key.Binding == "" || key.Binding == binding
+}
+
 // KeyRepository specifies Key persistence API.
 //
 //go:generate mockery --name KeyRepository --output=./mocks --filename keys.go --quiet --note "Copyright (c) Abstract Machines"
@@ -95,4 +125,24 @@ type KeyRepository interface {
 
 	// Remove removes Key with provided ID.
 	Remove(ctx context.Context, issuer string, id string) error
+
+	// Revoke revokes all keys of the subject issued up to the revocation
+	// time. Earlier revocations of the subject are kept if they are more
+	// recent.
+	Revoke(ctx context.Context, rev Revocation) error
+
+	// RetrieveRevocation retrieves the latest revocation of the subject
+	// keys. The revocation with the zero time is returned if the subject
+	// keys aren't revoked.
+	RetrieveRevocation(ctx context.Context, subject string) (Revocation, error)
+}
+
+// Revocation revokes the keys of the subject issued up to RevokedAt.
+type Revocation struct {
+	Subject   string
+	RevokedAt time.Time
+
+	// Disabled marks the revocation of the keys of the disabled user,
+	// which are still accepted during the disabled grace period.
+	Disabled bool
 }
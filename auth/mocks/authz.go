@@ -34,6 +34,34 @@ func (_m *Authz) Authorize(ctx context.Context, pr policies.Policy) error {
 	return r0
 }
 
+// ExplainPolicy provides a mock function with given fields: ctx, token, pr
+func (_m *Authz) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error) {
+	ret := _m.Called(ctx, token, pr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExplainPolicy")
+	}
+
+	var r0 policies.Decision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, policies.Policy) (policies.Decision, error)); ok {
+		return rf(ctx, token, pr)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, policies.Policy) policies.Decision); ok {
+		r0 = rf(ctx, token, pr)
+	} else {
+		r0 = ret.Get(0).(policies.Decision)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, policies.Policy) error); ok {
+		r1 = rf(ctx, token, pr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAuthz creates a new instance of Authz. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAuthz(t interface {
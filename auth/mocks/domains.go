@@ -124,6 +124,34 @@ func (_m *DomainsRepository) ListDomains(ctx context.Context, pm auth.Page) (aut
 	return r0, r1
 }
 
+// Resume provides a mock function with given fields: ctx, id, userID
+func (_m *DomainsRepository) Resume(ctx context.Context, id string, userID string) (auth.Domain, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 auth.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (auth.Domain, error)); ok {
+		return rf(ctx, id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) auth.Domain); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		r0 = ret.Get(0).(auth.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RetrieveAllByIDs provides a mock function with given fields: ctx, pm
 func (_m *DomainsRepository) RetrieveAllByIDs(ctx context.Context, pm auth.Page) (auth.DomainsPage, error) {
 	ret := _m.Called(ctx, pm)
@@ -210,6 +238,34 @@ func (_m *DomainsRepository) RetrievePermissions(ctx context.Context, subject st
 	return r0, r1
 }
 
+// RetrieveStatus provides a mock function with given fields: ctx, id
+func (_m *DomainsRepository) RetrieveStatus(ctx context.Context, id string) (auth.Status, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveStatus")
+	}
+
+	var r0 auth.Status
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (auth.Status, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) auth.Status); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(auth.Status)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, d
 func (_m *DomainsRepository) Save(ctx context.Context, d auth.Domain) (auth.Domain, error) {
 	ret := _m.Called(ctx, d)
@@ -263,6 +319,34 @@ func (_m *DomainsRepository) SavePolicies(ctx context.Context, pcs ...auth.Polic
 	return r0
 }
 
+// Suspend provides a mock function with given fields: ctx, id, userID
+func (_m *DomainsRepository) Suspend(ctx context.Context, id string, userID string) (auth.Domain, error) {
+	ret := _m.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Suspend")
+	}
+
+	var r0 auth.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (auth.Domain, error)); ok {
+		return rf(ctx, id, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) auth.Domain); ok {
+		r0 = rf(ctx, id, userID)
+	} else {
+		r0 = ret.Get(0).(auth.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, id, userID, d
 func (_m *DomainsRepository) Update(ctx context.Context, id string, userID string, d auth.DomainReq) (auth.Domain, error) {
 	ret := _m.Called(ctx, id, userID, d)
@@ -63,6 +63,52 @@ func (_m *KeyRepository) Retrieve(ctx context.Context, issuer string, id string)
 	return r0, r1
 }
 
+// RetrieveRevocation provides a mock function with given fields: ctx, subject
+func (_m *KeyRepository) RetrieveRevocation(ctx context.Context, subject string) (auth.Revocation, error) {
+	ret := _m.Called(ctx, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveRevocation")
+	}
+
+	var r0 auth.Revocation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (auth.Revocation, error)); ok {
+		return rf(ctx, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) auth.Revocation); ok {
+		r0 = rf(ctx, subject)
+	} else {
+		r0 = ret.Get(0).(auth.Revocation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: ctx, rev
+func (_m *KeyRepository) Revoke(ctx context.Context, rev auth.Revocation) error {
+	ret := _m.Called(ctx, rev)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, auth.Revocation) error); ok {
+		r0 = rf(ctx, rev)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: ctx, key
 func (_m *KeyRepository) Save(ctx context.Context, key auth.Key) (string, error) {
 	ret := _m.Called(ctx, key)
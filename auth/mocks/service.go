@@ -19,17 +19,17 @@ type Service struct {
 	mock.Mock
 }
 
-// AssignUsers provides a mock function with given fields: ctx, token, id, userIds, relation
-func (_m *Service) AssignUsers(ctx context.Context, token string, id string, userIds []string, relation string) error {
-	ret := _m.Called(ctx, token, id, userIds, relation)
+// AssignUsers provides a mock function with given fields: ctx, token, id, userIds, relation, conditions
+func (_m *Service) AssignUsers(ctx context.Context, token string, id string, userIds []string, relation string, conditions []string) error {
+	ret := _m.Called(ctx, token, id, userIds, relation, conditions)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AssignUsers")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string, string) error); ok {
-		r0 = rf(ctx, token, id, userIds, relation)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string, string, []string) error); ok {
+		r0 = rf(ctx, token, id, userIds, relation, conditions)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -129,6 +129,62 @@ func (_m *Service) DeleteUserFromDomains(ctx context.Context, id string) error {
 	return r0
 }
 
+// ExportPolicies provides a mock function with given fields: ctx, token, id
+func (_m *Service) ExportPolicies(ctx context.Context, token string, id string) (auth.PolicyExport, error) {
+	ret := _m.Called(ctx, token, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportPolicies")
+	}
+
+	var r0 auth.PolicyExport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (auth.PolicyExport, error)); ok {
+		return rf(ctx, token, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) auth.PolicyExport); ok {
+		r0 = rf(ctx, token, id)
+	} else {
+		r0 = ret.Get(0).(auth.PolicyExport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, token, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExplainPolicy provides a mock function with given fields: ctx, token, pr
+func (_m *Service) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error) {
+	ret := _m.Called(ctx, token, pr)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExplainPolicy")
+	}
+
+	var r0 policies.Decision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, policies.Policy) (policies.Decision, error)); ok {
+		return rf(ctx, token, pr)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, policies.Policy) policies.Decision); ok {
+		r0 = rf(ctx, token, pr)
+	} else {
+		r0 = ret.Get(0).(policies.Decision)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, policies.Policy) error); ok {
+		r1 = rf(ctx, token, pr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Identify provides a mock function with given fields: ctx, token
 func (_m *Service) Identify(ctx context.Context, token string) (auth.Key, error) {
 	ret := _m.Called(ctx, token)
@@ -157,6 +213,34 @@ func (_m *Service) Identify(ctx context.Context, token string) (auth.Key, error)
 	return r0, r1
 }
 
+// ImportPolicies provides a mock function with given fields: ctx, token, id, req
+func (_m *Service) ImportPolicies(ctx context.Context, token string, id string, req auth.ImportReq) (auth.ImportReport, error) {
+	ret := _m.Called(ctx, token, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportPolicies")
+	}
+
+	var r0 auth.ImportReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, auth.ImportReq) (auth.ImportReport, error)); ok {
+		return rf(ctx, token, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, auth.ImportReq) auth.ImportReport); ok {
+		r0 = rf(ctx, token, id, req)
+	} else {
+		r0 = ret.Get(0).(auth.ImportReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, auth.ImportReq) error); ok {
+		r1 = rf(ctx, token, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Issue provides a mock function with given fields: ctx, token, key
 func (_m *Service) Issue(ctx context.Context, token string, key auth.Key) (auth.Token, error) {
 	ret := _m.Called(ctx, token, key)
@@ -241,6 +325,34 @@ func (_m *Service) ListUserDomains(ctx context.Context, token string, userID str
 	return r0, r1
 }
 
+// ResumeDomain provides a mock function with given fields: ctx, token, id
+func (_m *Service) ResumeDomain(ctx context.Context, token string, id string) (auth.Domain, error) {
+	ret := _m.Called(ctx, token, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeDomain")
+	}
+
+	var r0 auth.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (auth.Domain, error)); ok {
+		return rf(ctx, token, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) auth.Domain); ok {
+		r0 = rf(ctx, token, id)
+	} else {
+		r0 = ret.Get(0).(auth.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, token, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RetrieveDomain provides a mock function with given fields: ctx, token, id
 func (_m *Service) RetrieveDomain(ctx context.Context, token string, id string) (auth.Domain, error) {
 	ret := _m.Called(ctx, token, id)
@@ -345,6 +457,62 @@ func (_m *Service) Revoke(ctx context.Context, token string, id string) error {
 	return r0
 }
 
+// SuspendDomain provides a mock function with given fields: ctx, token, id
+func (_m *Service) SuspendDomain(ctx context.Context, token string, id string) (auth.Domain, error) {
+	ret := _m.Called(ctx, token, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SuspendDomain")
+	}
+
+	var r0 auth.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (auth.Domain, error)); ok {
+		return rf(ctx, token, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) auth.Domain); ok {
+		r0 = rf(ctx, token, id)
+	} else {
+		r0 = ret.Get(0).(auth.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, token, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TransferOwnership provides a mock function with given fields: ctx, token, id, req
+func (_m *Service) TransferOwnership(ctx context.Context, token string, id string, req auth.TransferReq) (auth.TransferReport, error) {
+	ret := _m.Called(ctx, token, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TransferOwnership")
+	}
+
+	var r0 auth.TransferReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, auth.TransferReq) (auth.TransferReport, error)); ok {
+		return rf(ctx, token, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, auth.TransferReq) auth.TransferReport); ok {
+		r0 = rf(ctx, token, id, req)
+	} else {
+		r0 = ret.Get(0).(auth.TransferReport)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, auth.TransferReq) error); ok {
+		r1 = rf(ctx, token, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UnassignUser provides a mock function with given fields: ctx, token, id, userID
 func (_m *Service) UnassignUser(ctx context.Context, token string, id string, userID string) error {
 	ret := _m.Called(ctx, token, id, userID)
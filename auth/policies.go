@@ -0,0 +1,425 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+var errImportPolicies = errors.New("failed to import policies")
+
+// PortablePolicy is a policy of a domain in the portable form. The users are
+// identified by their user IDs rather than by their IDs in the domain, so
+// the policies can be reviewed and imported.
+type PortablePolicy struct {
+	SubjectType     string              `json:"subject_type"`
+	Subject         string              `json:"subject"`
+	SubjectRelation string              `json:"subject_relation,omitempty"`
+	Relation        string              `json:"relation"`
+	ObjectType      string              `json:"object_type"`
+	Object          string              `json:"object"`
+	Conditions      policies.Conditions `json:"conditions,omitempty"`
+}
+
+// key identifies the relationship of the policy, regardless of its
+// conditions.
+func (pp PortablePolicy) key() string {
+	return strings.Join([]string{pp.SubjectType, pp.Subject, pp.SubjectRelation, pp.Relation, pp.ObjectType, pp.Object}, "|")
+}
+
+// PolicyExport contains the authorization policies of a domain: the roles
+// of the users in the domain, the entities of the domain, and the relations
+// of the users and the groups with these entities.
+type PolicyExport struct {
+	DomainID string           `json:"domain_id"`
+	Policies []PortablePolicy `json:"policies"`
+}
+
+// ImportReq represents the request to import the policies into a domain.
+type ImportReq struct {
+	Policies []PortablePolicy
+	DryRun   bool
+}
+
+// InvalidPolicy is an imported policy which can not be applied, with the
+// reason why.
+type InvalidPolicy struct {
+	Policy PortablePolicy `json:"policy"`
+	Reason string         `json:"reason"`
+}
+
+// ImportReport contains the difference between the imported policies and
+// the policies of the domain. The policies are added only if none of them is
+// invalid, and in dry run mode they are never added.
+type ImportReport struct {
+	Added     []PortablePolicy `json:"added"`
+	Unchanged []PortablePolicy `json:"unchanged"`
+	Invalid   []InvalidPolicy  `json:"invalid,omitempty"`
+	DryRun    bool             `json:"dry_run"`
+	Applied   bool             `json:"applied"`
+}
+
+// portableRelations maps the subject and the object type of the policies to
+// the relations the domain policies may have, as defined by the schema.
+var portableRelations = map[[2]string][]string{
+	{policies.UserType, policies.DomainType}:  {policies.AdministratorRelation, policies.EditorRelation, policies.ContributorRelation, policies.MemberRelation, policies.GuestRelation},
+	{policies.UserType, policies.GroupType}:   {policies.AdministratorRelation, policies.EditorRelation, policies.ContributorRelation, policies.MemberRelation, policies.GuestRelation},
+	{policies.UserType, policies.ThingType}:   {policies.AdministratorRelation},
+	{policies.DomainType, policies.GroupType}: {policies.DomainRelation},
+	{policies.DomainType, policies.ThingType}: {policies.DomainRelation},
+	{policies.GroupType, policies.GroupType}:  {policies.ParentGroupRelation},
+	{policies.GroupType, policies.ThingType}:  {policies.GroupRelation},
+}
+
+func (svc service) ExportPolicies(ctx context.Context, token, id string) (PolicyExport, error) {
+	if err := svc.authorizeDomainAdmin(ctx, token, id); err != nil {
+		return PolicyExport{}, err
+	}
+	prs, err := svc.domainPolicies(ctx, id)
+	if err != nil {
+		return PolicyExport{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	return PolicyExport{DomainID: id, Policies: prs}, nil
+}
+
+func (svc service) ImportPolicies(ctx context.Context, token, id string, req ImportReq) (ImportReport, error) {
+	if err := svc.authorizeDomainAdmin(ctx, token, id); err != nil {
+		return ImportReport{}, err
+	}
+	existing, err := svc.domainPolicies(ctx, id)
+	if err != nil {
+		return ImportReport{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	report := diffPolicies(id, existing, req.Policies)
+	report.DryRun = req.DryRun
+	if req.DryRun || len(report.Invalid) > 0 || len(report.Added) == 0 {
+		return report, nil
+	}
+	if err := svc.addPortablePolicies(ctx, id, report.Added); err != nil {
+		return ImportReport{}, err
+	}
+	report.Applied = true
+
+	return report, nil
+}
+
+func (svc service) authorizeDomainAdmin(ctx context.Context, token, id string) error {
+	ctx, res, err := svc.identify(ctx, token)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+
+	return svc.Authorize(ctx, policies.Policy{
+		Subject:     res.User,
+		SubjectType: policies.UserType,
+		SubjectKind: policies.UsersKind,
+		Object:      id,
+		ObjectType:  policies.DomainType,
+		Permission:  policies.AdminPermission,
+	})
+}
+
+// domainPolicies returns the policies of the domain in the portable form,
+// sorted by their object, so the exports of the same policies are equal.
+// The platform and the suspension relations of the domain are managed with
+// the domain itself, so they are not included.
+func (svc service) domainPolicies(ctx context.Context, id string) ([]PortablePolicy, error) {
+	prs, err := svc.listPolicies(ctx, policies.Policy{
+		SubjectType: policies.UserType,
+		ObjectType:  policies.DomainType,
+		Object:      id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entityType := range []string{policies.GroupType, policies.ThingType} {
+		entities, err := svc.listPolicies(ctx, policies.Policy{
+			SubjectType: policies.DomainType,
+			Subject:     id,
+			Relation:    policies.DomainRelation,
+			ObjectType:  entityType,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(entities) == 0 {
+			continue
+		}
+		owned := make(map[string]bool, len(entities))
+		for _, entity := range entities {
+			owned[entity.Object] = true
+		}
+		// The policies of all the entities of the type are read page by
+		// page, rather than entity by entity, keeping those of the domain.
+		if err := svc.eachPolicyPage(ctx, policies.Policy{ObjectType: entityType}, func(page []policies.Policy) {
+			for _, pr := range page {
+				if owned[pr.Object] {
+					prs = append(prs, pr)
+				}
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool, len(prs))
+	ret := make([]PortablePolicy, 0, len(prs))
+	for _, pr := range prs {
+		pp := PortablePolicy{
+			SubjectType:     pr.SubjectType,
+			Subject:         pr.Subject,
+			SubjectRelation: pr.SubjectRelation,
+			Relation:        pr.Relation,
+			ObjectType:      pr.ObjectType,
+			Object:          pr.Object,
+			Conditions:      pr.Conditions,
+		}
+		if pp.SubjectType == policies.UserType {
+			if domainID, userID := DecodeDomainUserID(pp.Subject); domainID == id && userID != "" {
+				pp.Subject = userID
+			}
+		}
+		if seen[pp.key()] {
+			continue
+		}
+		seen[pp.key()] = true
+		ret = append(ret, pp)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].ObjectType != ret[j].ObjectType {
+			return ret[i].ObjectType < ret[j].ObjectType
+		}
+		if ret[i].Object != ret[j].Object {
+			return ret[i].Object < ret[j].Object
+		}
+		return ret[i].key() < ret[j].key()
+	})
+
+	return ret, nil
+}
+
+// diffPolicies validates the imported policies against the policies of the
+// domain, and returns the policies to add and the ones already present.
+func diffPolicies(id string, existing, imported []PortablePolicy) ImportReport {
+	report := ImportReport{Added: []PortablePolicy{}, Unchanged: []PortablePolicy{}}
+	invalid := func(pp PortablePolicy, format string, args ...interface{}) {
+		report.Invalid = append(report.Invalid, InvalidPolicy{Policy: pp, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	current := make(map[string]PortablePolicy, len(existing))
+	entities := make(map[string]bool)
+	parents := make(map[string]string)
+	for _, pp := range existing {
+		current[pp.key()] = pp
+		switch {
+		case pp.Relation == policies.DomainRelation && pp.Subject == id:
+			entities[pp.ObjectType+":"+pp.Object] = true
+		case pp.Relation == policies.ParentGroupRelation:
+			parents[pp.Object] = pp.Subject
+		}
+	}
+
+	seen := make(map[string]bool, len(imported))
+	var added []PortablePolicy
+	for _, pp := range imported {
+		if reason := validatePortable(id, pp, entities); reason != "" {
+			invalid(pp, "%s", reason)
+			continue
+		}
+		if seen[pp.key()] {
+			invalid(pp, "duplicate policy")
+			continue
+		}
+		seen[pp.key()] = true
+		if cur, ok := current[pp.key()]; ok {
+			if !equalConditions(cur.Conditions, pp.Conditions) {
+				invalid(pp, "policy exists with different conditions")
+				continue
+			}
+			report.Unchanged = append(report.Unchanged, pp)
+			continue
+		}
+		if pp.Relation == policies.ParentGroupRelation {
+			if parent, ok := parents[pp.Object]; ok {
+				invalid(pp, "group %s already has parent group %s", pp.Object, parent)
+				continue
+			}
+			parents[pp.Object] = pp.Subject
+		}
+		added = append(added, pp)
+	}
+
+	for _, pp := range added {
+		if pp.Relation == policies.ParentGroupRelation && circular(parents, pp.Object) {
+			invalid(pp, "parent group %s of group %s is circular", pp.Subject, pp.Object)
+			continue
+		}
+		report.Added = append(report.Added, pp)
+	}
+
+	return report
+}
+
+// validatePortable returns the reason why the policy can not be imported into
+// the domain, or an empty string if it can.
+func validatePortable(id string, pp PortablePolicy, entities map[string]bool) string {
+	relations, ok := portableRelations[[2]string{pp.SubjectType, pp.ObjectType}]
+	switch {
+	case pp.Subject == "" || pp.Object == "":
+		return "missing subject or object"
+	case !ok || !contains(relations, pp.Relation):
+		return fmt.Sprintf("relation %q of %s to %s is not supported", pp.Relation, pp.SubjectType, pp.ObjectType)
+	case pp.SubjectRelation != "":
+		return "subject relations are not supported"
+	case pp.SubjectType == policies.UserType && strings.Contains(pp.Subject, "_"):
+		return "user subject must be a user ID"
+	case (pp.SubjectType == policies.DomainType && pp.Subject != id) || (pp.ObjectType == policies.DomainType && pp.Object != id):
+		return "policy refers to another domain"
+	case pp.Relation == policies.DomainRelation && !entities[pp.ObjectType+":"+pp.Object]:
+		// The entities are added to the domain by the services owning
+		// them, so the import may not move them between the domains.
+		return fmt.Sprintf("%s %s is not owned by the domain", pp.ObjectType, pp.Object)
+	case pp.SubjectType == policies.GroupType && !entities[policies.GroupType+":"+pp.Subject]:
+		return fmt.Sprintf("group %s is not in the domain", pp.Subject)
+	case pp.ObjectType != policies.DomainType && !entities[pp.ObjectType+":"+pp.Object]:
+		return fmt.Sprintf("%s %s is not in the domain", pp.ObjectType, pp.Object)
+	case len(pp.Conditions) > 0 && pp.ObjectType != policies.DomainType:
+		return "conditions are supported only on the domain roles"
+	}
+	if _, err := policies.ParseConditions(conditionExprs(pp.Conditions)); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+// circular reports whether the chain of the parent groups of the group
+// returns to it.
+func circular(parents map[string]string, group string) bool {
+	visited := map[string]bool{group: true}
+	for g := parents[group]; g != ""; g = parents[g] {
+		if visited[g] {
+			return true
+		}
+		visited[g] = true
+	}
+
+	return false
+}
+
+// addPortablePolicies adds the policies to the domain. The policy engine
+// requires the users to be members of the domain and the entities to be in
+// the domain before they are related, so the policies are added in that
+// order, and the added ones are removed if a later step fails.
+func (svc service) addPortablePolicies(ctx context.Context, id string, pps []PortablePolicy) (err error) {
+	var steps [3][]policies.Policy
+	var pcs []Policy
+	for _, pp := range pps {
+		pr := policies.Policy{
+			Domain:          id,
+			SubjectType:     pp.SubjectType,
+			Subject:         pp.Subject,
+			SubjectRelation: pp.SubjectRelation,
+			Relation:        pp.Relation,
+			ObjectType:      pp.ObjectType,
+			Object:          pp.Object,
+			Conditions:      pp.Conditions,
+		}
+		step := 1
+		switch {
+		case pp.SubjectType == policies.DomainType:
+			step = 0
+		case pp.SubjectType == policies.UserType && pp.ObjectType == policies.DomainType:
+			step = 0
+			pcs = append(pcs, Policy{
+				SubjectType: policies.UserType,
+				SubjectID:   pp.Subject,
+				Relation:    pp.Relation,
+				ObjectType:  policies.DomainType,
+				ObjectID:    id,
+			})
+		case pp.SubjectType == policies.UserType:
+			step = 2
+		}
+		if pp.SubjectType == policies.UserType {
+			pr.SubjectKind = policies.UsersKind
+			pr.Subject = EncodeDomainUserID(id, pp.Subject)
+		}
+		steps[step] = append(steps[step], pr)
+	}
+
+	var added []policies.Policy
+	defer func() {
+		if err != nil && len(added) > 0 {
+			if errRollback := svc.policysvc.DeletePolicies(ctx, added); errRollback != nil {
+				err = errors.Wrap(err, errors.Wrap(errRollbackPolicy, errRollback))
+			}
+		}
+	}()
+	for _, prs := range steps {
+		if len(prs) == 0 {
+			continue
+		}
+		if err := svc.policysvc.AddPolicies(ctx, prs); err != nil {
+			return errors.Wrap(errImportPolicies, err)
+		}
+		added = append(added, prs...)
+	}
+	if len(pcs) > 0 {
+		if err := svc.domains.SavePolicies(ctx, pcs...); err != nil {
+			return errors.Wrap(errImportPolicies, err)
+		}
+	}
+
+	return nil
+}
+
+func equalConditions(a, b policies.Conditions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for claim, values := range a {
+		other, ok := b[claim]
+		if !ok || len(values) != len(other) {
+			return false
+		}
+		for _, v := range values {
+			if !contains(other, v) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// conditionExprs returns the conditions as the expressions parsed by
+// policies.ParseConditions.
+func conditionExprs(conds policies.Conditions) []string {
+	exprs := make([]string, 0, len(conds))
+	for claim, values := range conds {
+		exprs = append(exprs, claim+"="+strings.Join(values, ","))
+	}
+
+	return exprs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
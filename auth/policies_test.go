@@ -0,0 +1,408 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// tupleStore stores the policies the way the policy engine does, including
+// the requirement that the users are members of the domain before they are
+// related to its entities.
+type tupleStore map[string]policies.Policy
+
+func tupleKey(pr policies.Policy) string {
+	return strings.Join([]string{pr.SubjectType, pr.Subject, pr.Relation, pr.ObjectType, pr.Object}, "|")
+}
+
+func (ts tupleStore) list(_ context.Context, filter policies.Policy, _ string, _ uint64) (policies.PoliciesPage, error) {
+	page := policies.PoliciesPage{}
+	for _, pr := range ts {
+		if pr.ObjectType != filter.ObjectType ||
+			(filter.Object != "" && pr.Object != filter.Object) ||
+			(filter.Relation != "" && pr.Relation != filter.Relation) ||
+			(filter.SubjectType != "" && pr.SubjectType != filter.SubjectType) ||
+			(filter.Subject != "" && pr.Subject != filter.Subject) {
+			continue
+		}
+		page.Policies = append(page.Policies, pr)
+	}
+
+	return page, nil
+}
+
+func (ts tupleStore) add(_ context.Context, prs []policies.Policy) error {
+	for _, pr := range prs {
+		if pr.SubjectType == policies.UserType && pr.ObjectType != policies.DomainType {
+			page, _ := ts.list(context.Background(), policies.Policy{SubjectType: policies.UserType, Subject: pr.Subject, ObjectType: policies.DomainType, Object: pr.Domain}, "", 0)
+			if len(page.Policies) == 0 {
+				return fmt.Errorf("user %s is not a member of domain %s", pr.Subject, pr.Domain)
+			}
+		}
+	}
+	for _, pr := range prs {
+		ts[tupleKey(pr)] = policies.Policy{
+			SubjectType: pr.SubjectType,
+			Subject:     pr.Subject,
+			Relation:    pr.Relation,
+			ObjectType:  pr.ObjectType,
+			Object:      pr.Object,
+			Conditions:  pr.Conditions,
+		}
+	}
+
+	return nil
+}
+
+// access returns the entities the user may access: the domain, the groups
+// the user is related to and their descendants, and the things the user
+// administers or which are connected to these groups.
+func (ts tupleStore) access(domainID, userID string) []string {
+	user := auth.EncodeDomainUserID(domainID, userID)
+	groups := map[string]bool{}
+	var ret []string
+	for _, pr := range ts {
+		if pr.SubjectType != policies.UserType || pr.Subject != user {
+			continue
+		}
+		switch pr.ObjectType {
+		case policies.GroupType:
+			groups[pr.Object] = true
+		default:
+			ret = append(ret, pr.ObjectType+":"+pr.Object)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, pr := range ts {
+			if pr.Relation == policies.ParentGroupRelation && groups[pr.Subject] && !groups[pr.Object] {
+				groups[pr.Object] = true
+				changed = true
+			}
+		}
+	}
+	for group := range groups {
+		ret = append(ret, policies.GroupType+":"+group)
+	}
+	for _, pr := range ts {
+		if pr.Relation == policies.GroupRelation && groups[pr.Subject] {
+			ret = append(ret, policies.ThingType+":"+pr.Object)
+		}
+	}
+
+	return ret
+}
+
+func domainPolicy(userID, relation string, conds policies.Conditions) policies.Policy {
+	return policies.Policy{
+		SubjectType: policies.UserType,
+		Subject:     auth.EncodeDomainUserID(validID, userID),
+		Relation:    relation,
+		ObjectType:  policies.DomainType,
+		Object:      validID,
+		Conditions:  conds,
+	}
+}
+
+func entityPolicy(subjectType, subject, relation, objectType, object string) policies.Policy {
+	return policies.Policy{
+		SubjectType: subjectType,
+		Subject:     subject,
+		Relation:    relation,
+		ObjectType:  objectType,
+		Object:      object,
+	}
+}
+
+// newPolicyStoreService returns the service storing the policies in the
+// store, and the access token of the domain admin.
+func newPolicyStoreService(store tupleStore) (auth.Service, string) {
+	svc, accessToken := newService()
+	pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(nil)
+	drepo.On("RetrieveByID", mock.Anything, validID).Return(auth.Domain{ID: validID, Status: auth.EnabledStatus}, nil)
+	drepo.On("SavePolicies", mock.Anything, mock.Anything).Return(nil)
+	drepo.On("SavePolicies", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	pService.On("ListPolicies", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(store.list)
+	pService.On("AddPolicies", mock.Anything, mock.Anything).Return(store.add)
+	pService.On("DeletePolicies", mock.Anything, mock.Anything).Return(nil)
+
+	return svc, accessToken
+}
+
+func TestExportImportPolicies(t *testing.T) {
+	admin := testsutil.GenerateUUID(t)
+	member := testsutil.GenerateUUID(t)
+	parent := testsutil.GenerateUUID(t)
+	child := testsutil.GenerateUUID(t)
+	channel := testsutil.GenerateUUID(t)
+	thing := testsutil.GenerateUUID(t)
+	otherDomain := testsutil.GenerateUUID(t)
+	otherThing := testsutil.GenerateUUID(t)
+
+	source := tupleStore{}
+	_ = source.add(context.Background(), []policies.Policy{
+		domainPolicy(admin, policies.AdministratorRelation, nil),
+		domainPolicy(member, policies.MemberRelation, policies.Conditions{"region": {"eu", "us"}}),
+		entityPolicy(policies.DomainType, validID, policies.DomainRelation, policies.GroupType, parent),
+		entityPolicy(policies.DomainType, validID, policies.DomainRelation, policies.GroupType, child),
+		entityPolicy(policies.DomainType, validID, policies.DomainRelation, policies.GroupType, channel),
+		entityPolicy(policies.DomainType, validID, policies.DomainRelation, policies.ThingType, thing),
+		entityPolicy(policies.GroupType, parent, policies.ParentGroupRelation, policies.GroupType, child),
+		entityPolicy(policies.GroupType, channel, policies.GroupRelation, policies.ThingType, thing),
+		entityPolicy(policies.DomainType, otherDomain, policies.DomainRelation, policies.ThingType, otherThing),
+	})
+	for _, pr := range []policies.Policy{
+		entityPolicy(policies.UserType, auth.EncodeDomainUserID(validID, member), policies.EditorRelation, policies.GroupType, parent),
+		entityPolicy(policies.UserType, auth.EncodeDomainUserID(validID, admin), policies.AdministratorRelation, policies.GroupType, channel),
+		entityPolicy(policies.UserType, auth.EncodeDomainUserID(validID, admin), policies.AdministratorRelation, policies.ThingType, thing),
+		entityPolicy(policies.UserType, auth.EncodeDomainUserID(otherDomain, admin), policies.AdministratorRelation, policies.ThingType, otherThing),
+	} {
+		source[tupleKey(pr)] = pr
+	}
+
+	svc, accessToken := newPolicyStoreService(source)
+	export, err := svc.ExportPolicies(context.Background(), accessToken, validID)
+	assert.Nil(t, err, fmt.Sprintf("export policies: unexpected error %s", err))
+	assert.Equal(t, validID, export.DomainID)
+	assert.Len(t, export.Policies, 11, "expected the policies of the domain only")
+	// The members of the domain and, per entity type, the entities of the
+	// domain and then the policies of all the entities are listed.
+	listed := 0
+	for _, call := range pService.Calls {
+		if call.Method == "ListPolicies" {
+			listed++
+		}
+	}
+	assert.Equal(t, 5, listed, "expected the policies to be listed regardless of the number of the entities")
+	for _, pp := range export.Policies {
+		assert.NotEqual(t, otherThing, pp.Object, "expected the policies of the other domain not to be exported")
+		if pp.SubjectType == policies.UserType {
+			assert.Contains(t, []string{admin, member}, pp.Subject, "expected the users to be exported by their user IDs")
+		}
+	}
+
+	// The entities are added to the domain by the services owning them,
+	// before their policies are imported.
+	withEntities := func() tupleStore {
+		store := tupleStore{}
+		for _, pr := range source {
+			if pr.Relation == policies.DomainRelation && pr.Subject == validID {
+				store[tupleKey(pr)] = pr
+			}
+		}
+		return store
+	}
+
+	cases := []struct {
+		desc      string
+		dryRun    bool
+		target    tupleStore
+		added     int
+		unchanged int
+		applied   bool
+	}{
+		{
+			desc:      "import policies into domain with entities in dry run mode",
+			dryRun:    true,
+			target:    withEntities(),
+			added:     7,
+			unchanged: 4,
+			applied:   false,
+		},
+		{
+			desc:      "import policies into domain with entities",
+			target:    withEntities(),
+			added:     7,
+			unchanged: 4,
+			applied:   true,
+		},
+		{
+			desc:      "import policies into domain having them",
+			target:    source,
+			unchanged: 11,
+			applied:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			before := len(tc.target)
+			svc, accessToken := newPolicyStoreService(tc.target)
+
+			report, err := svc.ImportPolicies(context.Background(), accessToken, validID, auth.ImportReq{Policies: export.Policies, DryRun: tc.dryRun})
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Empty(t, report.Invalid, fmt.Sprintf("%s: unexpected invalid policies %v", tc.desc, report.Invalid))
+			assert.Len(t, report.Added, tc.added, fmt.Sprintf("%s: unexpected added policies", tc.desc))
+			assert.Len(t, report.Unchanged, tc.unchanged, fmt.Sprintf("%s: unexpected unchanged policies", tc.desc))
+			assert.Equal(t, tc.dryRun, report.DryRun, fmt.Sprintf("%s: unexpected dry run", tc.desc))
+			assert.Equal(t, tc.applied, report.Applied, fmt.Sprintf("%s: unexpected applied", tc.desc))
+			if !tc.applied {
+				assert.Len(t, tc.target, before, fmt.Sprintf("%s: expected the policies not to change", tc.desc))
+				return
+			}
+
+			reexport, err := svc.ExportPolicies(context.Background(), accessToken, validID)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, export, reexport, fmt.Sprintf("%s: expected the imported policies to be exported as they were", tc.desc))
+			for _, userID := range []string{admin, member} {
+				assert.ElementsMatch(t, source.access(validID, userID), tc.target.access(validID, userID), fmt.Sprintf("%s: expected the access of user %s to be preserved", tc.desc, userID))
+			}
+		})
+	}
+}
+
+func TestImportInvalidPolicies(t *testing.T) {
+	userID := testsutil.GenerateUUID(t)
+	group := testsutil.GenerateUUID(t)
+	other := testsutil.GenerateUUID(t)
+	thing := testsutil.GenerateUUID(t)
+
+	inDomain := func(objectType, object string) auth.PortablePolicy {
+		return auth.PortablePolicy{SubjectType: policies.DomainType, Subject: validID, Relation: policies.DomainRelation, ObjectType: objectType, Object: object}
+	}
+	owned := func(objectType, object string) policies.Policy {
+		return entityPolicy(policies.DomainType, validID, policies.DomainRelation, objectType, object)
+	}
+	parentOf := func(parent, child string) auth.PortablePolicy {
+		return auth.PortablePolicy{SubjectType: policies.GroupType, Subject: parent, Relation: policies.ParentGroupRelation, ObjectType: policies.GroupType, Object: child}
+	}
+	member := auth.PortablePolicy{SubjectType: policies.UserType, Subject: userID, Relation: policies.MemberRelation, ObjectType: policies.DomainType, Object: validID}
+
+	cases := []struct {
+		desc     string
+		token    bool
+		existing []policies.Policy
+		policies []auth.PortablePolicy
+		invalid  []string
+		err      error
+	}{
+		{
+			desc:     "import circular parent groups",
+			token:    true,
+			existing: []policies.Policy{owned(policies.GroupType, group), owned(policies.GroupType, other)},
+			policies: []auth.PortablePolicy{parentOf(group, other), parentOf(other, group)},
+			invalid:  []string{"circular", "circular"},
+		},
+		{
+			desc:     "import group being its own parent",
+			token:    true,
+			existing: []policies.Policy{owned(policies.GroupType, group)},
+			policies: []auth.PortablePolicy{parentOf(group, group)},
+			invalid:  []string{"circular"},
+		},
+		{
+			desc:     "import second parent group",
+			token:    true,
+			existing: []policies.Policy{owned(policies.GroupType, group), entityPolicy(policies.GroupType, other, policies.ParentGroupRelation, policies.GroupType, group), owned(policies.GroupType, other), owned(policies.GroupType, thing)},
+			policies: []auth.PortablePolicy{parentOf(thing, group)},
+			invalid:  []string{"already has parent group"},
+		},
+		{
+			desc:     "import unsupported relation",
+			token:    true,
+			existing: []policies.Policy{owned(policies.ThingType, thing)},
+			policies: []auth.PortablePolicy{member, {SubjectType: policies.UserType, Subject: userID, Relation: policies.EditorRelation, ObjectType: policies.ThingType, Object: thing}},
+			invalid:  []string{"not supported"},
+		},
+		{
+			desc:     "import policy of another domain",
+			token:    true,
+			policies: []auth.PortablePolicy{{SubjectType: policies.UserType, Subject: userID, Relation: policies.MemberRelation, ObjectType: policies.DomainType, Object: other}},
+			invalid:  []string{"another domain"},
+		},
+		{
+			desc:     "import policy of entity outside the domain",
+			token:    true,
+			policies: []auth.PortablePolicy{member, {SubjectType: policies.UserType, Subject: userID, Relation: policies.AdministratorRelation, ObjectType: policies.ThingType, Object: thing}},
+			invalid:  []string{"is not in the domain"},
+		},
+		{
+			desc:     "import domain relation of entity the domain doesn't own",
+			token:    true,
+			policies: []auth.PortablePolicy{inDomain(policies.ThingType, thing)},
+			invalid:  []string{"is not owned by the domain"},
+		},
+		{
+			desc:     "import domain relation of entity of another domain",
+			token:    true,
+			existing: []policies.Policy{entityPolicy(policies.DomainType, other, policies.DomainRelation, policies.GroupType, group)},
+			policies: []auth.PortablePolicy{inDomain(policies.GroupType, group)},
+			invalid:  []string{"is not owned by the domain"},
+		},
+		{
+			desc:     "import user subject encoded with domain",
+			token:    true,
+			policies: []auth.PortablePolicy{{SubjectType: policies.UserType, Subject: auth.EncodeDomainUserID(validID, userID), Relation: policies.MemberRelation, ObjectType: policies.DomainType, Object: validID}},
+			invalid:  []string{"user ID"},
+		},
+		{
+			desc:     "import invalid conditions",
+			token:    true,
+			policies: []auth.PortablePolicy{{SubjectType: policies.UserType, Subject: userID, Relation: policies.MemberRelation, ObjectType: policies.DomainType, Object: validID, Conditions: policies.Conditions{"1region": {"eu"}}}},
+			invalid:  []string{"invalid policy condition"},
+		},
+		{
+			desc:     "import conditions of group relation",
+			token:    true,
+			existing: []policies.Policy{owned(policies.GroupType, group)},
+			policies: []auth.PortablePolicy{member, {SubjectType: policies.UserType, Subject: userID, Relation: policies.EditorRelation, ObjectType: policies.GroupType, Object: group, Conditions: policies.Conditions{"region": {"eu"}}}},
+			invalid:  []string{"only on the domain roles"},
+		},
+		{
+			desc:     "import duplicate policy",
+			token:    true,
+			policies: []auth.PortablePolicy{member, member},
+			invalid:  []string{"duplicate"},
+		},
+		{
+			desc:     "import policy existing with different conditions",
+			token:    true,
+			existing: []policies.Policy{domainPolicy(userID, policies.MemberRelation, policies.Conditions{"region": {"eu"}})},
+			policies: []auth.PortablePolicy{member},
+			invalid:  []string{"different conditions"},
+		},
+		{
+			desc:     "import with invalid token",
+			token:    false,
+			policies: []auth.PortablePolicy{member},
+			err:      svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			store := tupleStore{}
+			for _, pr := range tc.existing {
+				store[tupleKey(pr)] = pr
+			}
+			before := len(store)
+			svc, accessToken := newPolicyStoreService(store)
+			if !tc.token {
+				accessToken = inValidToken
+			}
+
+			report, err := svc.ImportPolicies(context.Background(), accessToken, validID, auth.ImportReq{Policies: tc.policies})
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			assert.False(t, report.Applied, fmt.Sprintf("%s: expected the policies not to be applied", tc.desc))
+			assert.Len(t, store, before, fmt.Sprintf("%s: expected the policies not to change", tc.desc))
+			assert.Len(t, report.Invalid, len(tc.invalid), fmt.Sprintf("%s: unexpected invalid policies %v", tc.desc, report.Invalid))
+			for i, reason := range tc.invalid {
+				if i < len(report.Invalid) {
+					assert.Contains(t, report.Invalid[i].Reason, reason, fmt.Sprintf("%s: unexpected reason", tc.desc))
+				}
+			}
+		})
+	}
+}
@@ -36,9 +36,9 @@ func NewDomainRepository(db postgres.Database) auth.DomainsRepository {
 }
 
 func (repo domainRepo) Save(ctx context.Context, d auth.Domain) (ad auth.Domain, err error) {
-	q := `INSERT INTO domains (id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status)
-	VALUES (:id, :name, :tags, :alias, :metadata, :created_at, :updated_at, :updated_by, :created_by, :status)
-	RETURNING id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status;`
+	q := `INSERT INTO domains (id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status, self_registration)
+	VALUES (:id, :name, :tags, :alias, :metadata, :created_at, :updated_at, :updated_by, :created_by, :status, :self_registration)
+	RETURNING id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status, self_registration;`
 
 	dbd, err := toDBDomain(d)
 	if err != nil {
@@ -67,7 +67,7 @@ func (repo domainRepo) Save(ctx context.Context, d auth.Domain) (ad auth.Domain,
 
 // RetrieveByID retrieves Domain by its unique ID.
 func (repo domainRepo) RetrieveByID(ctx context.Context, id string) (auth.Domain, error) {
-	q := `SELECT d.id as id, d.name as name, d.tags as tags,  d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status
+	q := `SELECT d.id as id, d.name as name, d.tags as tags,  d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status, d.self_registration as self_registration
         FROM domains d WHERE d.id = :id`
 
 	dbdp := dbDomainsPage{
@@ -136,7 +136,7 @@ func (repo domainRepo) RetrieveAllByIDs(ctx context.Context, pm auth.Page) (auth
 		return auth.DomainsPage{}, errors.Wrap(repoerr.ErrFailedOpDB, err)
 	}
 
-	q = `SELECT d.id as id, d.name as name, d.tags as tags, d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status
+	q = `SELECT d.id as id, d.name as name, d.tags as tags, d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status, d.self_registration as self_registration
 	FROM domains d`
 	q = fmt.Sprintf("%s %s  LIMIT %d OFFSET %d;", q, query, pm.Limit, pm.Offset)
 
@@ -182,7 +182,7 @@ func (repo domainRepo) ListDomains(ctx context.Context, pm auth.Page) (auth.Doma
 		return auth.DomainsPage{}, errors.Wrap(repoerr.ErrFailedOpDB, err)
 	}
 
-	q = `SELECT d.id as id, d.name as name, d.tags as tags, d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status, pc.relation as relation
+	q = `SELECT d.id as id, d.name as name, d.tags as tags, d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status, d.self_registration as self_registration, pc.relation as relation
 	FROM domains as d
 	JOIN policies pc
 	ON pc.object_id = d.id`
@@ -192,7 +192,7 @@ func (repo domainRepo) ListDomains(ctx context.Context, pm auth.Page) (auth.Doma
 	// If the user making the request is a super admin, the service will assign an empty value to the pagemeta subject field.
 	// In the repository, when the pagemeta subject is empty, the query should be constructed without applying the policies filter.
 	if pm.SubjectID == "" {
-		q = `SELECT d.id as id, d.name as name, d.tags as tags, d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status
+		q = `SELECT d.id as id, d.name as name, d.tags as tags, d.alias as alias, d.metadata as metadata, d.created_at as created_at, d.updated_at as updated_at, d.updated_by as updated_by, d.created_by as created_by, d.status as status, d.self_registration as self_registration
 		FROM domains as d`
 	}
 
@@ -262,6 +262,10 @@ func (repo domainRepo) Update(ctx context.Context, id, userID string, dr auth.Do
 		query = append(query, "alias = :alias, ")
 		d.Alias = *dr.Alias
 	}
+	if dr.SelfRegistration != nil {
+		query = append(query, "self_registration = :self_registration, ")
+		d.SelfRegistration = *dr.SelfRegistration
+	}
 	d.UpdatedAt = time.Now()
 	d.UpdatedBy = userID
 	if len(query) > 0 {
@@ -269,7 +273,7 @@ func (repo domainRepo) Update(ctx context.Context, id, userID string, dr auth.Do
 	}
 	q := fmt.Sprintf(`UPDATE domains SET %s  updated_at = :updated_at, updated_by = :updated_by
         WHERE id = :id %s
-        RETURNING id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status;`,
+        RETURNING id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status, self_registration;`,
 		upq, ws)
 
 	dbd, err := toDBDomain(d)
@@ -296,6 +300,72 @@ func (repo domainRepo) Update(ctx context.Context, id, userID string, dr auth.Do
 	return domain, nil
 }
 
+// RetrieveStatus retrieves the status of the domain.
+func (repo domainRepo) RetrieveStatus(ctx context.Context, id string) (auth.Status, error) {
+	q := "SELECT status FROM domains WHERE id = $1;"
+
+	var status auth.Status
+	if err := repo.db.QueryRowxContext(ctx, q, id).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return auth.Status(0), repoerr.ErrNotFound
+		}
+		return auth.Status(0), postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+
+	return status, nil
+}
+
+// Suspend keeps the current status of the domain as prior status and marks
+// the domain as suspended in a single statement.
+func (repo domainRepo) Suspend(ctx context.Context, id, userID string) (auth.Domain, error) {
+	q := `UPDATE domains SET prior_status = status, status = :status, updated_at = :updated_at, updated_by = :updated_by
+        WHERE id = :id AND status <> :status
+        RETURNING id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status, self_registration;`
+
+	return repo.updateSuspension(ctx, q, id, userID)
+}
+
+// Resume restores the status the domain had before it was suspended.
+func (repo domainRepo) Resume(ctx context.Context, id, userID string) (auth.Domain, error) {
+	q := `UPDATE domains SET status = prior_status, prior_status = NULL, updated_at = :updated_at, updated_by = :updated_by
+        WHERE id = :id AND status = :status AND prior_status IS NOT NULL
+        RETURNING id, name, tags, alias, metadata, created_at, updated_at, updated_by, created_by, status, self_registration;`
+
+	return repo.updateSuspension(ctx, q, id, userID)
+}
+
+func (repo domainRepo) updateSuspension(ctx context.Context, q, id, userID string) (auth.Domain, error) {
+	dbd, err := toDBDomain(auth.Domain{
+		ID:        id,
+		Status:    auth.SuspendedStatus,
+		UpdatedAt: time.Now(),
+		UpdatedBy: userID,
+	})
+	if err != nil {
+		return auth.Domain{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+	row, err := repo.db.NamedQueryContext(ctx, q, dbd)
+	if err != nil {
+		return auth.Domain{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	defer row.Close()
+
+	if !row.Next() {
+		return auth.Domain{}, repoerr.ErrNotFound
+	}
+	dbd = dbDomain{}
+	if err := row.StructScan(&dbd); err != nil {
+		return auth.Domain{}, errors.Wrap(repoerr.ErrFailedOpDB, err)
+	}
+
+	domain, err := toDomain(dbd)
+	if err != nil {
+		return auth.Domain{}, errors.Wrap(repoerr.ErrFailedOpDB, err)
+	}
+
+	return domain, nil
+}
+
 // Delete delete domain from database.
 func (repo domainRepo) Delete(ctx context.Context, id string) error {
 	q := "DELETE FROM domains WHERE id = $1;"
@@ -417,17 +487,18 @@ func (repo domainRepo) processRows(rows *sqlx.Rows) ([]auth.Domain, error) {
 }
 
 type dbDomain struct {
-	ID         string           `db:"id"`
-	Name       string           `db:"name"`
-	Metadata   []byte           `db:"metadata,omitempty"`
-	Tags       pgtype.TextArray `db:"tags,omitempty"`
-	Alias      *string          `db:"alias,omitempty"`
-	Status     auth.Status      `db:"status"`
-	Permission string           `db:"relation"`
-	CreatedBy  string           `db:"created_by"`
-	CreatedAt  time.Time        `db:"created_at"`
-	UpdatedBy  *string          `db:"updated_by,omitempty"`
-	UpdatedAt  sql.NullTime     `db:"updated_at,omitempty"`
+	ID               string           `db:"id"`
+	Name             string           `db:"name"`
+	Metadata         []byte           `db:"metadata,omitempty"`
+	Tags             pgtype.TextArray `db:"tags,omitempty"`
+	Alias            *string          `db:"alias,omitempty"`
+	Status           auth.Status      `db:"status"`
+	SelfRegistration bool             `db:"self_registration"`
+	Permission       string           `db:"relation"`
+	CreatedBy        string           `db:"created_by"`
+	CreatedAt        time.Time        `db:"created_at"`
+	UpdatedBy        *string          `db:"updated_by,omitempty"`
+	UpdatedAt        sql.NullTime     `db:"updated_at,omitempty"`
 }
 
 func toDBDomain(d auth.Domain) (dbDomain, error) {
@@ -458,17 +529,18 @@ func toDBDomain(d auth.Domain) (dbDomain, error) {
 	}
 
 	return dbDomain{
-		ID:         d.ID,
-		Name:       d.Name,
-		Metadata:   data,
-		Tags:       tags,
-		Alias:      alias,
-		Status:     d.Status,
-		Permission: d.Permission,
-		CreatedBy:  d.CreatedBy,
-		CreatedAt:  d.CreatedAt,
-		UpdatedBy:  updatedBy,
-		UpdatedAt:  updatedAt,
+		ID:               d.ID,
+		Name:             d.Name,
+		Metadata:         data,
+		Tags:             tags,
+		Alias:            alias,
+		Status:           d.Status,
+		SelfRegistration: d.SelfRegistration,
+		Permission:       d.Permission,
+		CreatedBy:        d.CreatedBy,
+		CreatedAt:        d.CreatedAt,
+		UpdatedBy:        updatedBy,
+		UpdatedAt:        updatedAt,
 	}, nil
 }
 
@@ -497,17 +569,18 @@ func toDomain(d dbDomain) (auth.Domain, error) {
 	}
 
 	return auth.Domain{
-		ID:         d.ID,
-		Name:       d.Name,
-		Metadata:   metadata,
-		Tags:       tags,
-		Alias:      alias,
-		Permission: d.Permission,
-		Status:     d.Status,
-		CreatedBy:  d.CreatedBy,
-		CreatedAt:  d.CreatedAt,
-		UpdatedBy:  updatedBy,
-		UpdatedAt:  updatedAt,
+		ID:               d.ID,
+		Name:             d.Name,
+		Metadata:         metadata,
+		Tags:             tags,
+		Alias:            alias,
+		Permission:       d.Permission,
+		Status:           d.Status,
+		SelfRegistration: d.SelfRegistration,
+		CreatedBy:        d.CreatedBy,
+		CreatedAt:        d.CreatedAt,
+		UpdatedBy:        updatedBy,
+		UpdatedAt:        updatedAt,
 	}, nil
 }
 
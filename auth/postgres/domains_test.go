@@ -932,6 +932,82 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestSuspendResume(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM domains")
+		require.Nil(t, err, fmt.Sprintf("clean domains unexpected error: %s", err))
+	})
+
+	repo := postgres.NewDomainRepository(database)
+
+	domain := auth.Domain{
+		ID:        domainID,
+		Name:      "test",
+		Alias:     "test",
+		CreatedBy: userID,
+		UpdatedBy: userID,
+		Status:    auth.DisabledStatus,
+	}
+
+	_, err := repo.Save(context.Background(), domain)
+	require.Nil(t, err, fmt.Sprintf("failed to save domain %s", domain.ID))
+
+	cases := []struct {
+		desc     string
+		domainID string
+		suspend  bool
+		status   auth.Status
+		err      error
+	}{
+		{
+			desc:     "suspend domain",
+			domainID: domain.ID,
+			suspend:  true,
+			status:   auth.SuspendedStatus,
+			err:      nil,
+		},
+		{
+			desc:     "suspend already suspended domain",
+			domainID: domain.ID,
+			suspend:  true,
+			err:      repoerr.ErrNotFound,
+		},
+		{
+			desc:     "resume domain restores prior status",
+			domainID: domain.ID,
+			status:   auth.DisabledStatus,
+			err:      nil,
+		},
+		{
+			desc:     "resume domain that is not suspended",
+			domainID: domain.ID,
+			err:      repoerr.ErrNotFound,
+		},
+		{
+			desc:     "suspend non-existing domain",
+			domainID: testsutil.GenerateUUID(t),
+			suspend:  true,
+			err:      repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		op := repo.Resume
+		if tc.suspend {
+			op = repo.Suspend
+		}
+		d, err := op(context.Background(), tc.domainID, userID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tc.status, d.Status, fmt.Sprintf("%s: expected status %s got %s\n", tc.desc, tc.status, d.Status))
+		status, err := repo.RetrieveStatus(context.Background(), tc.domainID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.status, status, fmt.Sprintf("%s: expected stored status %s got %s\n", tc.desc, tc.status, status))
+	}
+}
+
 func TestDelete(t *testing.T) {
 	t.Cleanup(func() {
 		_, err := db.Exec("DELETE FROM domains")
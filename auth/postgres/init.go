@@ -57,6 +57,45 @@ func Migration() *migrate.MemoryMigrationSource {
 					`ALTER TABLE domains ALTER COLUMN alias SET NOT NULL`,
 				},
 			},
+			{
+				Id: "auth_3",
+				Up: []string{
+					`ALTER TABLE domains ADD COLUMN IF NOT EXISTS prior_status SMALLINT CHECK (prior_status >= 0)`,
+				},
+				Down: []string{
+					`ALTER TABLE domains DROP COLUMN IF EXISTS prior_status`,
+				},
+			},
+			{
+				Id: "auth_4",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS revocations (
+						subject     VARCHAR(254) PRIMARY KEY,
+						revoked_at  TIMESTAMP NOT NULL
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS revocations`,
+				},
+			},
+			{
+				Id: "auth_5",
+				Up: []string{
+					`ALTER TABLE domains ADD COLUMN IF NOT EXISTS self_registration BOOLEAN NOT NULL DEFAULT FALSE`,
+				},
+				Down: []string{
+					`ALTER TABLE domains DROP COLUMN IF EXISTS self_registration`,
+				},
+			},
+			{
+				Id: "auth_6",
+				Up: []string{
+					`ALTER TABLE revocations ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE`,
+				},
+				Down: []string{
+					`ALTER TABLE revocations DROP COLUMN IF EXISTS disabled`,
+				},
+			},
 		},
 	}
 }
@@ -18,6 +18,7 @@ var (
 	errSave     = errors.New("failed to save key in database")
 	errRetrieve = errors.New("failed to retrieve key from database")
 	errDelete   = errors.New("failed to delete key from database")
+	errRevoke   = errors.New("failed to revoke keys in database")
 )
 var _ auth.KeyRepository = (*repo)(nil)
 
@@ -71,6 +72,34 @@ func (kr *repo) Remove(ctx context.Context, issuerID, id string) error {
 	return nil
 }
 
+func (kr *repo) Revoke(ctx context.Context, rev auth.Revocation) error {
+	// The revocation marks the keys of the disabled user only if it's the
+	// latest one, so the user deleted after being disabled gets no grace.
+	q := `INSERT INTO revocations (subject, revoked_at, disabled) VALUES ($1, $2, $3)
+	      ON CONFLICT (subject) DO UPDATE SET
+	          disabled = CASE WHEN EXCLUDED.revoked_at >= revocations.revoked_at THEN EXCLUDED.disabled ELSE revocations.disabled END,
+	          revoked_at = GREATEST(revocations.revoked_at, EXCLUDED.revoked_at)`
+	if _, err := kr.db.ExecContext(ctx, q, rev.Subject, rev.RevokedAt.UTC(), rev.Disabled); err != nil {
+		return postgres.HandleError(errRevoke, err)
+	}
+
+	return nil
+}
+
+func (kr *repo) RetrieveRevocation(ctx context.Context, subject string) (auth.Revocation, error) {
+	q := `SELECT revoked_at, disabled FROM revocations WHERE subject = $1`
+	rev := auth.Revocation{Subject: subject}
+	if err := kr.db.QueryRowxContext(ctx, q, subject).Scan(&rev.RevokedAt, &rev.Disabled); err != nil {
+		if err == sql.ErrNoRows {
+			return auth.Revocation{Subject: subject}, nil
+		}
+
+		return auth.Revocation{}, postgres.HandleError(errRetrieve, err)
+	}
+
+	return rev, nil
+}
+
 type dbKey struct {
 	ID        string       `db:"id"`
 	Type      uint32       `db:"type"`
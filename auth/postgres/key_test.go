@@ -269,3 +269,48 @@ func TestKeyRemove(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 	}
 }
+
+func TestRevoke(t *testing.T) {
+	repo := postgres.New(database)
+
+	subject := generateID(t)
+	disabledAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+	deletedAt := disabledAt.Add(time.Minute)
+
+	cases := []struct {
+		desc string
+		rev  auth.Revocation
+		res  auth.Revocation
+	}{
+		{
+			desc: "revoke keys of disabled user",
+			rev:  auth.Revocation{Subject: subject, RevokedAt: disabledAt, Disabled: true},
+			res:  auth.Revocation{Subject: subject, RevokedAt: disabledAt, Disabled: true},
+		},
+		{
+			desc: "revoke keys of deleted user",
+			rev:  auth.Revocation{Subject: subject, RevokedAt: deletedAt},
+			res:  auth.Revocation{Subject: subject, RevokedAt: deletedAt},
+		},
+		{
+			desc: "revoke keys with earlier revocation",
+			rev:  auth.Revocation{Subject: subject, RevokedAt: disabledAt, Disabled: true},
+			res:  auth.Revocation{Subject: subject, RevokedAt: deletedAt},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := repo.Revoke(context.Background(), tc.rev)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			rev, err := repo.RetrieveRevocation(context.Background(), subject)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			assert.Equal(t, tc.res.Disabled, rev.Disabled, fmt.Sprintf("%s: expected disabled %t got %t", tc.desc, tc.res.Disabled, rev.Disabled))
+			assert.True(t, tc.res.RevokedAt.Equal(rev.RevokedAt), fmt.Sprintf("%s: expected revocation at %s got %s", tc.desc, tc.res.RevokedAt, rev.RevokedAt))
+		})
+	}
+
+	rev, err := repo.RetrieveRevocation(context.Background(), generateID(t))
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.True(t, rev.RevokedAt.IsZero(), fmt.Sprintf("expected no revocation got %s", rev.RevokedAt))
+}
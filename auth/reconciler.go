@@ -0,0 +1,32 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+// ExistingDomains returns the lookup of the domains referred to by policies,
+// used to reconcile orphaned policies.
+func ExistingDomains(repo DomainsRepository) policies.ExistingFunc {
+	return func(ctx context.Context, ids []string) ([]string, error) {
+		page, err := repo.RetrieveAllByIDs(ctx, Page{
+			IDs:    ids,
+			Limit:  uint64(len(ids)),
+			Status: AllStatus,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		existing := []string{}
+		for _, d := range page.Domains {
+			existing = append(existing, d.ID)
+		}
+
+		return existing, nil
+	}
+}
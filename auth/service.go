@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/authn"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/policies"
@@ -34,8 +35,12 @@ var (
 	errAddPolicies        = errors.New("failed to add policies")
 	errRemovePolicies     = errors.New("failed to remove the policies")
 	errRollbackPolicy     = errors.New("failed to rollback policy")
+	errReplacePolicies    = errors.New("failed to replace policies")
 	errRemoveLocalPolicy  = errors.New("failed to remove from local policy copy")
 	errRemovePolicyEngine = errors.New("failed to remove from policy engine")
+	errDomainSuspended    = errors.New("domain is suspended")
+	errTransferTarget     = errors.New("transfer target is not a member of the domain")
+	errTransferEntityType = errors.New("invalid transfer entity type")
 )
 
 // Authz represents a authorization service. It exposes
@@ -49,6 +54,11 @@ type Authz interface {
 	// no relation on the object (which simply means the operation is
 	// denied).
 	Authorize(ctx context.Context, pr policies.Policy) error
+
+	// ExplainPolicy returns the authorization decision for the policy
+	// alongside the policies which produced it. It is meant for debugging
+	// denied requests and is only allowed to platform administrators.
+	ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error)
 }
 
 // Authn specifies an API that must be fulfilled by the domain service
@@ -97,10 +107,18 @@ type service struct {
 	loginDuration      time.Duration
 	refreshDuration    time.Duration
 	invitationDuration time.Duration
+	disabledGrace      time.Duration
+	audiences          map[string]bool
 }
 
-// New instantiates the auth service implementation.
-func New(keys KeyRepository, domains DomainsRepository, idp magistrala.IDProvider, tokenizer Tokenizer, policyEvaluator policies.Evaluator, policyService policies.Service, loginDuration, refreshDuration, invitationDuration time.Duration) Service {
+// New instantiates the auth service implementation. The keys of the disabled
+// users are accepted during the disabled grace period since the users were
+// disabled, while zero grace period rejects them immediately.
+func New(keys KeyRepository, domains DomainsRepository, idp magistrala.IDProvider, tokenizer Tokenizer, policyEvaluator policies.Evaluator, policyService policies.Service, loginDuration, refreshDuration, invitationDuration, disabledGrace time.Duration, audiences []string) Service {
+	auds := make(map[string]bool, len(audiences))
+	for _, aud := range audiences {
+		auds[aud] = true
+	}
 	return &service{
 		tokenizer:          tokenizer,
 		domains:            domains,
@@ -111,10 +129,15 @@ func New(keys KeyRepository, domains DomainsRepository, idp magistrala.IDProvide
 		loginDuration:      loginDuration,
 		refreshDuration:    refreshDuration,
 		invitationDuration: invitationDuration,
+		disabledGrace:      disabledGrace,
+		audiences:          auds,
 	}
 }
 
 func (svc service) Issue(ctx context.Context, token string, key Key) (Token, error) {
+	if key.Audience != "" && !svc.audiences[key.Audience] {
+		return Token{}, errors.Wrap(svcerr.ErrMalformedEntity, ErrInvalidAudience)
+	}
 	key.IssuedAt = time.Now().UTC()
 	switch key.Type {
 	case APIKey:
@@ -131,23 +154,23 @@ func (svc service) Issue(ctx context.Context, token string, key Key) (Token, err
 }
 
 func (svc service) Revoke(ctx context.Context, token, id string) error {
-	issuerID, _, err := svc.authenticate(token)
+	issuer, err := svc.authenticate(token)
 	if err != nil {
 		return errors.Wrap(errRevoke, err)
 	}
-	if err := svc.keys.Remove(ctx, issuerID, id); err != nil {
+	if err := svc.keys.Remove(ctx, issuer.Issuer, id); err != nil {
 		return errors.Wrap(errRevoke, err)
 	}
 	return nil
 }
 
 func (svc service) RetrieveKey(ctx context.Context, token, id string) (Key, error) {
-	issuerID, _, err := svc.authenticate(token)
+	issuer, err := svc.authenticate(token)
 	if err != nil {
 		return Key{}, errors.Wrap(errRetrieve, err)
 	}
 
-	key, err := svc.keys.Retrieve(ctx, issuerID, id)
+	key, err := svc.keys.Retrieve(ctx, issuer.Issuer, id)
 	if err != nil {
 		return Key{}, errors.Wrap(svcerr.ErrViewEntity, err)
 	}
@@ -163,6 +186,12 @@ func (svc service) Identify(ctx context.Context, token string) (Key, error) {
 	if err != nil {
 		return Key{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(errIdentify, err))
 	}
+	if err := svc.checkSuspended(ctx, key.Domain); err != nil {
+		return Key{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if err := svc.checkRevoked(ctx, key); err != nil {
+		return Key{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
 
 	switch key.Type {
 	case RecoveryKey, AccessKey, InvitationKey, RefreshKey:
@@ -178,6 +207,18 @@ func (svc service) Identify(ctx context.Context, token string) (Key, error) {
 	}
 }
 
+// identify identifies the token and returns the context carrying the custom
+// claims of the token, against which the conditions of the policies are
+// evaluated.
+func (svc service) identify(ctx context.Context, token string) (context.Context, Key, error) {
+	key, err := svc.Identify(ctx, token)
+	if err != nil {
+		return ctx, Key{}, err
+	}
+
+	return authn.WithClaims(ctx, key.Claims), key, nil
+}
+
 func (svc service) Authorize(ctx context.Context, pr policies.Policy) error {
 	if err := svc.PolicyValidation(pr); err != nil {
 		return errors.Wrap(svcerr.ErrMalformedEntity, err)
@@ -187,6 +228,10 @@ func (svc service) Authorize(ctx context.Context, pr policies.Policy) error {
 		if err != nil {
 			return errors.Wrap(svcerr.ErrAuthentication, err)
 		}
+		// Authorization requests don't carry the client fingerprint.
+		if !key.BoundTo("") {
+			return errors.Wrap(svcerr.ErrAuthentication, ErrInvalidBinding)
+		}
 		if key.Subject == "" {
 			if pr.ObjectType == policies.GroupType || pr.ObjectType == policies.ThingType || pr.ObjectType == policies.DomainType {
 				return svcerr.ErrDomainAuthorization
@@ -195,6 +240,7 @@ func (svc service) Authorize(ctx context.Context, pr policies.Policy) error {
 		}
 		pr.Subject = key.Subject
 		pr.Domain = key.Domain
+		ctx = authn.WithClaims(ctx, key.Claims)
 	}
 	if err := svc.checkPolicy(ctx, pr); err != nil {
 		return err
@@ -222,6 +268,43 @@ func (svc service) checkPolicy(ctx context.Context, pr policies.Policy) error {
 	return nil
 }
 
+func (svc service) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error) {
+	key, err := svc.Identify(ctx, token)
+	if err != nil {
+		return policies.Decision{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if err := svc.authorizePlatformAdmin(ctx, key.User); err != nil {
+		return policies.Decision{}, err
+	}
+	if err := svc.PolicyValidation(pr); err != nil {
+		return policies.Decision{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+
+	// Same as checkPolicy, the domain membership and status precede the policies.
+	if pr.SubjectType == policies.UserType && (pr.ObjectType == policies.GroupType || pr.ObjectType == policies.ThingType || pr.ObjectType == policies.DomainType) {
+		domainID := pr.Domain
+		if domainID == "" && pr.ObjectType == policies.DomainType {
+			domainID = pr.Object
+		}
+		if domainID == "" {
+			return policies.Decision{Reason: "missing domain of the object"}, nil
+		}
+		switch err := svc.checkDomain(ctx, pr.SubjectType, pr.Subject, domainID); {
+		case errors.Contains(err, svcerr.ErrDomainAuthorization):
+			return policies.Decision{Reason: "subject is not a member of the domain or the domain is not enabled"}, nil
+		case err != nil:
+			return policies.Decision{}, err
+		}
+	}
+
+	decision, err := svc.evaluator.ExplainPolicy(ctx, pr)
+	if err != nil {
+		return policies.Decision{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	return decision, nil
+}
+
 func (svc service) checkDomain(ctx context.Context, subjectType, subject, domainID string) error {
 	if err := svc.evaluator.CheckPolicy(ctx, policies.Policy{
 		Subject:     subject,
@@ -267,6 +350,47 @@ func (svc service) checkDomain(ctx context.Context, subjectType, subject, domain
 	return nil
 }
 
+// checkSuspended rejects tokens scoped to a suspended domain. The status is
+// read on every request, so already issued tokens are rejected as soon as
+// the domain is suspended rather than when they expire.
+func (svc service) checkSuspended(ctx context.Context, domainID string) error {
+	if domainID == "" {
+		return nil
+	}
+	status, err := svc.domains.RetrieveStatus(ctx, domainID)
+	if err != nil {
+		return errors.Wrap(errIdentify, err)
+	}
+	if status == SuspendedStatus {
+		return errDomainSuspended
+	}
+
+	return nil
+}
+
+// checkRevoked rejects the keys issued to the subject before its keys were
+// revoked, e.g. because the user was deleted or disabled. Token issue times
+// have a one second precision, so the keys issued in the same second as the
+// revocation are rejected too. The keys of a disabled user are accepted
+// until the grace period since the user was disabled elapses.
+func (svc service) checkRevoked(ctx context.Context, key Key) error {
+	if key.Subject == "" {
+		return nil
+	}
+	rev, err := svc.keys.RetrieveRevocation(ctx, key.Subject)
+	if err != nil {
+		return errors.Wrap(errIdentify, err)
+	}
+	if rev.RevokedAt.IsZero() || key.IssuedAt.After(rev.RevokedAt.Truncate(time.Second)) {
+		return nil
+	}
+	if rev.Disabled && time.Since(rev.RevokedAt) < svc.disabledGrace {
+		return nil
+	}
+
+	return ErrKeyRevoked
+}
+
 func (svc service) PolicyValidation(pr policies.Policy) error {
 	if pr.ObjectType == policies.PlatformType && pr.Object != policies.MagistralaObject {
 		return errPlatform
@@ -289,7 +413,7 @@ func (svc service) accessKey(ctx context.Context, key Key) (Token, error) {
 	key.Type = AccessKey
 	key.ExpiresAt = time.Now().Add(svc.loginDuration)
 
-	key.Subject, err = svc.checkUserDomain(ctx, key)
+	key.Subject, err = svc.checkUserDomain(authn.WithClaims(ctx, key.Claims), key)
 	if err != nil {
 		return Token{}, errors.Wrap(svcerr.ErrAuthorization, err)
 	}
@@ -339,10 +463,17 @@ func (svc service) refreshKey(ctx context.Context, token string, key Key) (Token
 	if key.Domain == "" {
 		key.Domain = k.Domain
 	}
+	if key.Audience == "" {
+		key.Audience = k.Audience
+	}
+	// The binding and the claims can't be changed, so the stolen refresh
+	// token can't be used to get unbound tokens or other claims.
+	key.Binding = k.Binding
+	key.Claims = k.Claims
 	key.User = k.User
 	key.Type = AccessKey
 
-	key.Subject, err = svc.checkUserDomain(ctx, key)
+	key.Subject, err = svc.checkUserDomain(authn.WithClaims(ctx, key.Claims), key)
 	if err != nil {
 		return Token{}, errors.Wrap(svcerr.ErrAuthorization, err)
 	}
@@ -392,15 +523,18 @@ func (svc service) checkUserDomain(ctx context.Context, key Key) (subject string
 }
 
 func (svc service) userKey(ctx context.Context, token string, key Key) (Token, error) {
-	id, sub, err := svc.authenticate(token)
+	issuer, err := svc.authenticate(token)
 	if err != nil {
 		return Token{}, errors.Wrap(errIssueUser, err)
 	}
 
-	key.Issuer = id
+	key.Issuer = issuer.Issuer
 	if key.Subject == "" {
-		key.Subject = sub
+		key.Subject = issuer.Subject
 	}
+	// API keys act on behalf of the user, so they carry the claims of the
+	// user rather than the claims of the request.
+	key.Claims = issuer.Claims
 
 	keyID, err := svc.idProvider.ID()
 	if err != nil {
@@ -420,17 +554,17 @@ func (svc service) userKey(ctx context.Context, token string, key Key) (Token, e
 	return Token{AccessToken: tkn}, nil
 }
 
-func (svc service) authenticate(token string) (string, string, error) {
+func (svc service) authenticate(token string) (Key, error) {
 	key, err := svc.tokenizer.Parse(token)
 	if err != nil {
-		return "", "", errors.Wrap(svcerr.ErrAuthentication, err)
+		return Key{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
 	// Only login key token is valid for login.
 	if key.Type != AccessKey || key.Issuer == "" {
-		return "", "", svcerr.ErrAuthentication
+		return Key{}, svcerr.ErrAuthentication
 	}
 
-	return key.Issuer, key.Subject, nil
+	return key, nil
 }
 
 // Switch the relative permission for the relation.
@@ -452,7 +586,7 @@ func SwitchToPermission(relation string) string {
 }
 
 func (svc service) CreateDomain(ctx context.Context, token string, d Domain) (do Domain, err error) {
-	key, err := svc.Identify(ctx, token)
+	ctx, key, err := svc.identify(ctx, token)
 	if err != nil {
 		return Domain{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
@@ -489,7 +623,7 @@ func (svc service) CreateDomain(ctx context.Context, token string, d Domain) (do
 }
 
 func (svc service) RetrieveDomain(ctx context.Context, token, id string) (Domain, error) {
-	res, err := svc.Identify(ctx, token)
+	ctx, res, err := svc.identify(ctx, token)
 	if err != nil {
 		return Domain{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
@@ -511,7 +645,7 @@ func (svc service) RetrieveDomain(ctx context.Context, token, id string) (Domain
 }
 
 func (svc service) RetrieveDomainPermissions(ctx context.Context, token, id string) (policies.Permissions, error) {
-	res, err := svc.Identify(ctx, token)
+	ctx, res, err := svc.identify(ctx, token)
 	if err != nil {
 		return []string{}, err
 	}
@@ -540,17 +674,23 @@ func (svc service) RetrieveDomainPermissions(ctx context.Context, token, id stri
 }
 
 func (svc service) UpdateDomain(ctx context.Context, token, id string, d DomainReq) (Domain, error) {
-	key, err := svc.Identify(ctx, token)
+	ctx, key, err := svc.identify(ctx, token)
 	if err != nil {
 		return Domain{}, err
 	}
+	// The self-registration is managed by the domain admins, since it
+	// admits the members of the domain.
+	permission := policies.EditPermission
+	if d.SelfRegistration != nil {
+		permission = policies.AdminPermission
+	}
 	if err := svc.Authorize(ctx, policies.Policy{
 		Subject:     key.User,
 		SubjectType: policies.UserType,
 		SubjectKind: policies.UsersKind,
 		Object:      id,
 		ObjectType:  policies.DomainType,
-		Permission:  policies.EditPermission,
+		Permission:  permission,
 	}); err != nil {
 		return Domain{}, err
 	}
@@ -563,7 +703,7 @@ func (svc service) UpdateDomain(ctx context.Context, token, id string, d DomainR
 }
 
 func (svc service) ChangeDomainStatus(ctx context.Context, token, id string, d DomainReq) (Domain, error) {
-	key, err := svc.Identify(ctx, token)
+	ctx, key, err := svc.identify(ctx, token)
 	if err != nil {
 		return Domain{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
@@ -577,6 +717,9 @@ func (svc service) ChangeDomainStatus(ctx context.Context, token, id string, d D
 	}); err != nil {
 		return Domain{}, err
 	}
+	if d.Status != nil && *d.Status == SuspendedStatus {
+		return Domain{}, svcerr.ErrInvalidStatus
+	}
 
 	dom, err := svc.domains.Update(ctx, id, key.User, d)
 	if err != nil {
@@ -585,8 +728,84 @@ func (svc service) ChangeDomainStatus(ctx context.Context, token, id string, d D
 	return dom, nil
 }
 
+func (svc service) SuspendDomain(ctx context.Context, token, id string) (do Domain, err error) {
+	ctx, key, err := svc.identify(ctx, token)
+	if err != nil {
+		return Domain{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if err := svc.authorizePlatformAdmin(ctx, key.User); err != nil {
+		return Domain{}, err
+	}
+
+	dom, err := svc.domains.Suspend(ctx, id, key.User)
+	if err != nil {
+		return Domain{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	defer func() {
+		if err != nil {
+			if _, errRollBack := svc.domains.Resume(ctx, id, key.User); errRollBack != nil {
+				err = errors.Wrap(err, errors.Wrap(errRollbackPolicy, errRollBack))
+			}
+		}
+	}()
+	if err := svc.policysvc.AddPolicy(ctx, suspendedDomainPolicy(id)); err != nil {
+		return Domain{}, errors.Wrap(errAddPolicies, err)
+	}
+
+	return dom, nil
+}
+
+func (svc service) ResumeDomain(ctx context.Context, token, id string) (do Domain, err error) {
+	ctx, key, err := svc.identify(ctx, token)
+	if err != nil {
+		return Domain{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if err := svc.authorizePlatformAdmin(ctx, key.User); err != nil {
+		return Domain{}, err
+	}
+
+	dom, err := svc.domains.Resume(ctx, id, key.User)
+	if err != nil {
+		return Domain{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	defer func() {
+		if err != nil {
+			if _, errRollBack := svc.domains.Suspend(ctx, id, key.User); errRollBack != nil {
+				err = errors.Wrap(err, errors.Wrap(errRollbackPolicy, errRollBack))
+			}
+		}
+	}()
+	if err := svc.policysvc.DeletePolicies(ctx, []policies.Policy{suspendedDomainPolicy(id)}); err != nil {
+		return Domain{}, errors.Wrap(errRemovePolicies, err)
+	}
+
+	return dom, nil
+}
+
+func (svc service) authorizePlatformAdmin(ctx context.Context, userID string) error {
+	return svc.Authorize(ctx, policies.Policy{
+		Subject:     userID,
+		SubjectType: policies.UserType,
+		Permission:  policies.AdminPermission,
+		ObjectType:  policies.PlatformType,
+		Object:      policies.MagistralaObject,
+	})
+}
+
+// suspendedDomainPolicy marks all channels as suspended for the domain, which
+// removes publish and subscribe permissions from the domain things.
+func suspendedDomainPolicy(domainID string) policies.Policy {
+	return policies.Policy{
+		Subject:     "*",
+		SubjectType: policies.GroupType,
+		Relation:    policies.SuspendedRelation,
+		Object:      domainID,
+		ObjectType:  policies.DomainType,
+	}
+}
+
 func (svc service) ListDomains(ctx context.Context, token string, p Page) (DomainsPage, error) {
-	key, err := svc.Identify(ctx, token)
+	ctx, key, err := svc.identify(ctx, token)
 	if err != nil {
 		return DomainsPage{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
@@ -612,11 +831,15 @@ func (svc service) ListDomains(ctx context.Context, token string, p Page) (Domai
 	return dp, nil
 }
 
-func (svc service) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string) error {
-	res, err := svc.Identify(ctx, token)
+func (svc service) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string, conditions []string) error {
+	ctx, res, err := svc.identify(ctx, token)
 	if err != nil {
 		return errors.Wrap(svcerr.ErrAuthentication, err)
 	}
+	conds, err := policies.ParseConditions(conditions)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
 
 	if err := svc.Authorize(ctx, policies.Policy{
 		Subject:     res.User,
@@ -626,7 +849,16 @@ func (svc service) AssignUsers(ctx context.Context, token, id string, userIds []
 		ObjectType:  policies.DomainType,
 		Permission:  policies.SharePermission,
 	}); err != nil {
-		return err
+		// The users may join the domain allowing self-registration
+		// themselves, as its members.
+		if len(userIds) != 1 || userIds[0] != res.User || relation != policies.MemberRelation || len(conds) > 0 {
+			return err
+		}
+		if err := svc.checkSelfRegistration(ctx, id); err != nil {
+			return err
+		}
+
+		return svc.addDomainPolicies(ctx, id, relation, conds, res.User)
 	}
 
 	if err := svc.Authorize(ctx, policies.Policy{
@@ -652,11 +884,25 @@ func (svc service) AssignUsers(ctx context.Context, token, id string, userIds []
 		}
 	}
 
-	return svc.addDomainPolicies(ctx, id, relation, userIds...)
+	return svc.addDomainPolicies(ctx, id, relation, conds, userIds...)
+}
+
+// checkSelfRegistration checks that the users may join the enabled domain
+// themselves.
+func (svc service) checkSelfRegistration(ctx context.Context, id string) error {
+	dom, err := svc.domains.RetrieveByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	if !dom.SelfRegistration || dom.Status != EnabledStatus {
+		return errors.Wrap(svcerr.ErrAuthorization, svcerr.ErrSelfRegistrationDisabled)
+	}
+
+	return nil
 }
 
 func (svc service) UnassignUser(ctx context.Context, token, id, userID string) error {
-	res, err := svc.Identify(ctx, token)
+	ctx, res, err := svc.identify(ctx, token)
 	if err != nil {
 		return errors.Wrap(svcerr.ErrAuthentication, err)
 	}
@@ -707,23 +953,24 @@ func (svc service) UnassignUser(ctx context.Context, token, id, userID string) e
 
 // IMPROVEMENT NOTE: Take decision: Only Patform admin or both Patform and domain admins can see others users domain.
 func (svc service) ListUserDomains(ctx context.Context, token, userID string, p Page) (DomainsPage, error) {
-	res, err := svc.Identify(ctx, token)
+	ctx, res, err := svc.identify(ctx, token)
 	if err != nil {
 		return DomainsPage{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
-	if err := svc.Authorize(ctx, policies.Policy{
-		Subject:     res.User,
-		SubjectType: policies.UserType,
-		Permission:  policies.AdminPermission,
-		Object:      policies.MagistralaObject,
-		ObjectType:  policies.PlatformType,
-	}); err != nil {
-		return DomainsPage{}, errors.Wrap(svcerr.ErrAuthorization, err)
-	}
+	// Users can always list their own domains, while listing the domains of
+	// other users is reserved to the platform administrators.
+	p.SubjectID = res.User
 	if userID != "" && res.User != userID {
+		if err := svc.Authorize(ctx, policies.Policy{
+			Subject:     res.User,
+			SubjectType: policies.UserType,
+			Permission:  policies.AdminPermission,
+			Object:      policies.MagistralaObject,
+			ObjectType:  policies.PlatformType,
+		}); err != nil {
+			return DomainsPage{}, errors.Wrap(svcerr.ErrAuthorization, err)
+		}
 		p.SubjectID = userID
-	} else {
-		p.SubjectID = res.User
 	}
 	dp, err := svc.domains.ListDomains(ctx, p)
 	if err != nil {
@@ -732,7 +979,137 @@ func (svc service) ListUserDomains(ctx context.Context, token, userID string, p
 	return dp, nil
 }
 
-func (svc service) addDomainPolicies(ctx context.Context, domainID, relation string, userIDs ...string) (err error) {
+// TransferOwnership moves the administrator relations of the source domain
+// user on the domain things and groups to the target domain user. Other
+// relations of the target user on the transferred resources are superseded
+// by the administrator relation and removed.
+func (svc service) TransferOwnership(ctx context.Context, token, id string, req TransferReq) (report TransferReport, err error) {
+	ctx, res, err := svc.identify(ctx, token)
+	if err != nil {
+		return TransferReport{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if err := svc.Authorize(ctx, policies.Policy{
+		Subject:     res.User,
+		SubjectType: policies.UserType,
+		SubjectKind: policies.UsersKind,
+		Object:      id,
+		ObjectType:  policies.DomainType,
+		Permission:  policies.AdminPermission,
+	}); err != nil {
+		return TransferReport{}, err
+	}
+	if req.FromUserID == "" || req.ToUserID == "" || req.FromUserID == req.ToUserID {
+		return TransferReport{}, svcerr.ErrMalformedEntity
+	}
+	// The claims of the request are not the claims of the target user, so
+	// the target user must be a member without conditions.
+	if err := svc.evaluator.CheckPolicy(authn.WithClaims(ctx, nil), policies.Policy{
+		Subject:     req.ToUserID,
+		SubjectType: policies.UserType,
+		Permission:  policies.MembershipPermission,
+		Object:      id,
+		ObjectType:  policies.DomainType,
+	}); err != nil {
+		return TransferReport{}, errors.Wrap(svcerr.ErrAuthorization, errTransferTarget)
+	}
+
+	from := EncodeDomainUserID(id, req.FromUserID)
+	to := EncodeDomainUserID(id, req.ToUserID)
+	report = TransferReport{Resources: map[string][]string{}, DryRun: req.DryRun}
+	var removed, added []policies.Policy
+	for _, entityType := range req.EntityTypes {
+		if entityType != policies.ThingType && entityType != policies.GroupType {
+			return TransferReport{}, errors.Wrap(svcerr.ErrMalformedEntity, errTransferEntityType)
+		}
+		owned, err := svc.listPolicies(ctx, policies.Policy{
+			SubjectType: policies.UserType,
+			Subject:     from,
+			Relation:    policies.AdministratorRelation,
+			ObjectType:  entityType,
+		})
+		if err != nil {
+			return TransferReport{}, errors.Wrap(svcerr.ErrViewEntity, err)
+		}
+		if len(owned) == 0 {
+			continue
+		}
+		existing, err := svc.listPolicies(ctx, policies.Policy{
+			SubjectType: policies.UserType,
+			Subject:     to,
+			ObjectType:  entityType,
+		})
+		if err != nil {
+			return TransferReport{}, errors.Wrap(svcerr.ErrViewEntity, err)
+		}
+		targetRelations := make(map[string][]policies.Policy)
+		for _, pr := range existing {
+			targetRelations[pr.Object] = append(targetRelations[pr.Object], pr)
+		}
+
+		for _, pr := range owned {
+			report.Resources[entityType] = append(report.Resources[entityType], pr.Object)
+			removed = append(removed, pr)
+			isAdmin := false
+			for _, tr := range targetRelations[pr.Object] {
+				if tr.Relation == policies.AdministratorRelation {
+					isAdmin = true
+					continue
+				}
+				removed = append(removed, tr)
+			}
+			if !isAdmin {
+				added = append(added, policies.Policy{
+					Domain:      id,
+					SubjectType: policies.UserType,
+					Subject:     to,
+					Relation:    policies.AdministratorRelation,
+					ObjectType:  entityType,
+					Object:      pr.Object,
+				})
+			}
+		}
+	}
+	if req.DryRun || len(removed) == 0 {
+		return report, nil
+	}
+
+	// The superseded relations are removed and the administrator relations
+	// added in a single write, so a failed transfer changes nothing.
+	if err := svc.policysvc.ReplacePolicies(ctx, removed, added); err != nil {
+		return TransferReport{}, errors.Wrap(errReplacePolicies, err)
+	}
+
+	return report, nil
+}
+
+// listPolicies lists all the stored policies matching the filter.
+func (svc service) listPolicies(ctx context.Context, filter policies.Policy) ([]policies.Policy, error) {
+	var prs []policies.Policy
+	err := svc.eachPolicyPage(ctx, filter, func(page []policies.Policy) {
+		prs = append(prs, page...)
+	})
+
+	return prs, err
+}
+
+// eachPolicyPage reads the policies matching the filter page by page, and
+// passes each page to fn.
+func (svc service) eachPolicyPage(ctx context.Context, filter policies.Policy, fn func([]policies.Policy)) error {
+	nextPageToken := ""
+	for {
+		page, err := svc.policysvc.ListPolicies(ctx, filter, nextPageToken, defLimit)
+		if err != nil {
+			return err
+		}
+		fn(page.Policies)
+		if page.NextPageToken == "" || len(page.Policies) == 0 {
+			return nil
+		}
+		nextPageToken = page.NextPageToken
+	}
+}
+
+func (svc service) addDomainPolicies(ctx context.Context, domainID, relation string, conds policies.Conditions, userIDs ...string) (err error) {
 	var prs []policies.Policy
 	var pcs []Policy
 
@@ -744,6 +1121,7 @@ func (svc service) addDomainPolicies(ctx context.Context, domainID, relation str
 			Relation:    relation,
 			Object:      domainID,
 			ObjectType:  policies.DomainType,
+			Conditions:  conds,
 		})
 		pcs = append(pcs, Policy{
 			SubjectType: policies.UserType,
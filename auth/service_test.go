@@ -12,6 +12,8 @@ import (
 	"github.com/absmach/magistrala/auth"
 	"github.com/absmach/magistrala/auth/jwt"
 	"github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/authn"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
@@ -33,7 +35,9 @@ const (
 	loginDuration   = 30 * time.Minute
 	refreshDuration = 24 * time.Hour
 	invalidDuration = 7 * 24 * time.Hour
+	disabledGrace   = time.Hour
 	validID         = "d4ebb847-5d0e-4e46-bdd9-b6aceaaa3a22"
+	audience        = "users"
 )
 
 var (
@@ -59,10 +63,12 @@ var (
 )
 
 var (
-	krepo      *mocks.KeyRepository
-	drepo      *mocks.DomainsRepository
-	pService   *policymocks.Service
-	pEvaluator *policymocks.Evaluator
+	krepo       *mocks.KeyRepository
+	drepo       *mocks.DomainsRepository
+	pService    *policymocks.Service
+	pEvaluator  *policymocks.Evaluator
+	statusCall  *mock.Call
+	revokedCall *mock.Call
 )
 
 func newService() (auth.Service, string) {
@@ -71,6 +77,8 @@ func newService() (auth.Service, string) {
 	pService = new(policymocks.Service)
 	pEvaluator = new(policymocks.Evaluator)
 	idProvider := uuid.NewMock()
+	statusCall = drepo.On("RetrieveStatus", mock.Anything, mock.Anything).Return(auth.EnabledStatus, nil)
+	revokedCall = krepo.On("RetrieveRevocation", mock.Anything, mock.Anything).Return(auth.Revocation{}, nil)
 
 	t := jwt.New([]byte(secret))
 	key := auth.Key{
@@ -83,7 +91,7 @@ func newService() (auth.Service, string) {
 	}
 	token, _ := t.Issue(key)
 
-	return auth.New(krepo, drepo, idProvider, t, pEvaluator, pService, loginDuration, refreshDuration, invalidDuration), token
+	return auth.New(krepo, drepo, idProvider, t, pEvaluator, pService, loginDuration, refreshDuration, invalidDuration, disabledGrace, []string{audience}), token
 }
 
 func TestIssue(t *testing.T) {
@@ -128,6 +136,26 @@ func TestIssue(t *testing.T) {
 			token: "",
 			err:   nil,
 		},
+		{
+			desc: "issue recovery key with allowed audience",
+			key: auth.Key{
+				Type:     auth.RecoveryKey,
+				IssuedAt: time.Now(),
+				Audience: audience,
+			},
+			token: "",
+			err:   nil,
+		},
+		{
+			desc: "issue recovery key with invalid audience",
+			key: auth.Key{
+				Type:     auth.RecoveryKey,
+				IssuedAt: time.Now(),
+				Audience: "invalid",
+			},
+			token: "",
+			err:   auth.ErrInvalidAudience,
+		},
 	}
 
 	for _, tc := range cases {
@@ -511,6 +539,59 @@ func TestIssue(t *testing.T) {
 	}
 }
 
+func TestIssueBound(t *testing.T) {
+	svc, _ := newService()
+
+	n := jwt.New([]byte(secret))
+	binding := "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I"
+
+	refreshToken, err := n.Issue(auth.Key{
+		IssuedAt:  time.Now(),
+		ExpiresAt: time.Now().Add(refreshDuration),
+		Subject:   id,
+		Type:      auth.RefreshKey,
+		User:      email,
+		Binding:   binding,
+	})
+	assert.Nil(t, err, fmt.Sprintf("Issuing bound refresh key expected to succeed: %s", err))
+
+	cases := []struct {
+		desc string
+		key  auth.Key
+	}{
+		{
+			desc: "refresh bound key",
+			key:  auth.Key{Type: auth.RefreshKey, IssuedAt: time.Now()},
+		},
+		{
+			desc: "refresh bound key with different binding",
+			key:  auth.Key{Type: auth.RefreshKey, IssuedAt: time.Now(), Binding: "other"},
+		},
+	}
+
+	for _, tc := range cases {
+		repoCall := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(nil)
+		tkn, err := svc.Issue(context.Background(), refreshToken, tc.key)
+		assert.Nil(t, err, fmt.Sprintf("%s expected to succeed: %s", tc.desc, err))
+		for _, token := range []string{tkn.AccessToken, tkn.RefreshToken} {
+			key, err := n.Parse(token)
+			assert.Nil(t, err, fmt.Sprintf("%s: parsing issued token expected to succeed: %s", tc.desc, err))
+			assert.Equal(t, binding, key.Binding, fmt.Sprintf("%s: expected binding %s got %s", tc.desc, binding, key.Binding))
+		}
+
+		err = svc.Authorize(context.Background(), policies.Policy{
+			Subject:     tkn.AccessToken,
+			SubjectType: policies.UserType,
+			SubjectKind: policies.TokenKind,
+			Object:      policies.MagistralaObject,
+			ObjectType:  policies.PlatformType,
+			Permission:  policies.AdminPermission,
+		})
+		assert.True(t, errors.Contains(err, auth.ErrInvalidBinding), fmt.Sprintf("%s: authorizing bound token expected %s got %s", tc.desc, auth.ErrInvalidBinding, err))
+		repoCall.Unset()
+	}
+}
+
 func TestRevoke(t *testing.T) {
 	svc, _ := newService()
 	repocall := krepo.On("Save", mock.Anything, mock.Anything).Return(mock.Anything, errIssueUser)
@@ -745,6 +826,67 @@ func TestIdentify(t *testing.T) {
 	}
 }
 
+func TestIdentifyRevoked(t *testing.T) {
+	svc, _ := newService()
+
+	issuedAt := time.Now().Add(-3 * disabledGrace)
+	te := jwt.New([]byte(secret))
+	token, err := te.Issue(auth.Key{
+		IssuedAt:  issuedAt,
+		ExpiresAt: time.Now().Add(refreshDuration),
+		Subject:   id,
+		Type:      auth.AccessKey,
+		User:      email,
+		Domain:    groupName,
+	})
+	assert.Nil(t, err, fmt.Sprintf("issuing key expected to succeed: %s", err))
+
+	cases := []struct {
+		desc    string
+		rev     auth.Revocation
+		repoErr error
+		err     error
+	}{
+		{
+			desc: "identify key issued after revocation",
+			rev:  auth.Revocation{Subject: id, RevokedAt: issuedAt.Add(-time.Minute)},
+		},
+		{
+			desc: "identify key issued before revocation",
+			rev:  auth.Revocation{Subject: id, RevokedAt: issuedAt.Add(time.Minute)},
+			err:  auth.ErrKeyRevoked,
+		},
+		{
+			desc: "identify key of disabled user within grace period",
+			rev:  auth.Revocation{Subject: id, RevokedAt: time.Now().Add(-time.Minute), Disabled: true},
+		},
+		{
+			desc: "identify key of disabled user after grace period",
+			rev:  auth.Revocation{Subject: id, RevokedAt: issuedAt.Add(time.Minute), Disabled: true},
+			err:  auth.ErrKeyRevoked,
+		},
+		{
+			desc: "identify key of deleted user within grace period",
+			rev:  auth.Revocation{Subject: id, RevokedAt: time.Now().Add(-time.Minute)},
+			err:  auth.ErrKeyRevoked,
+		},
+		{
+			desc:    "identify key with failed revocation retrieval",
+			repoErr: repoerr.ErrViewEntity,
+			err:     svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			revokedCall.Unset()
+			revokedCall = krepo.On("RetrieveRevocation", mock.Anything, id).Return(tc.rev, tc.repoErr)
+			_, err := svc.Identify(context.Background(), token)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+		})
+	}
+}
+
 func TestAuthorize(t *testing.T) {
 	svc, accessToken := newService()
 
@@ -1213,6 +1355,183 @@ func TestAuthorize(t *testing.T) {
 	}
 }
 
+func TestAuthorizeClaims(t *testing.T) {
+	svc, _ := newService()
+
+	// The evaluator grants the policy only to the sessions from the region,
+	// as the policy condition region=eu would.
+	inRegion := func(ctx context.Context) bool {
+		return authn.Claims(ctx)["region"] == "eu"
+	}
+	pEvaluator.On("CheckPolicy", mock.MatchedBy(inRegion), mock.Anything).Return(nil)
+	pEvaluator.On("CheckPolicy", mock.MatchedBy(func(ctx context.Context) bool { return !inRegion(ctx) }), mock.Anything).Return(svcerr.ErrAuthorization)
+
+	tokenizer := jwt.New([]byte(secret))
+	issue := func(claims map[string]string) string {
+		token, err := tokenizer.Issue(auth.Key{
+			IssuedAt:  time.Now(),
+			ExpiresAt: time.Now().Add(refreshDuration),
+			Subject:   id,
+			Type:      auth.AccessKey,
+			User:      email,
+			Claims:    claims,
+		})
+		assert.Nil(t, err, fmt.Sprintf("Issuing access key expected to succeed: %s", err))
+		return token
+	}
+
+	cases := []struct {
+		desc string
+		ctx  context.Context
+		pr   policies.Policy
+		err  error
+	}{
+		{
+			desc: "authorize token with matching claims",
+			ctx:  context.Background(),
+			pr:   policies.Policy{Subject: issue(map[string]string{"region": "eu"}), SubjectKind: policies.TokenKind},
+			err:  nil,
+		},
+		{
+			desc: "authorize token with mismatching claims",
+			ctx:  context.Background(),
+			pr:   policies.Policy{Subject: issue(map[string]string{"region": "us"}), SubjectKind: policies.TokenKind},
+			err:  svcerr.ErrAuthorization,
+		},
+		{
+			desc: "authorize token without claims",
+			ctx:  context.Background(),
+			pr:   policies.Policy{Subject: issue(nil), SubjectKind: policies.TokenKind},
+			err:  svcerr.ErrAuthorization,
+		},
+		{
+			desc: "authorize token ignoring the claims of the context",
+			ctx:  authn.WithClaims(context.Background(), map[string]string{"region": "eu"}),
+			pr:   policies.Policy{Subject: issue(map[string]string{"region": "us"}), SubjectKind: policies.TokenKind},
+			err:  svcerr.ErrAuthorization,
+		},
+		{
+			desc: "authorize id with matching claims of the context",
+			ctx:  authn.WithClaims(context.Background(), map[string]string{"region": "eu"}),
+			pr:   policies.Policy{Subject: id, SubjectKind: policies.UsersKind},
+			err:  nil,
+		},
+		{
+			desc: "authorize id without claims",
+			ctx:  context.Background(),
+			pr:   policies.Policy{Subject: id, SubjectKind: policies.UsersKind},
+			err:  svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.pr.SubjectType = policies.UserType
+			tc.pr.Object = policies.MagistralaObject
+			tc.pr.ObjectType = policies.PlatformType
+			tc.pr.Permission = policies.AdminPermission
+			err := svc.Authorize(tc.ctx, tc.pr)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		})
+	}
+}
+
+func TestExplainPolicy(t *testing.T) {
+	adminPolicy := policies.Policy{
+		Subject:     email,
+		SubjectType: policies.UserType,
+		Permission:  policies.AdminPermission,
+		ObjectType:  policies.PlatformType,
+		Object:      policies.MagistralaObject,
+	}
+	memberPolicy := policies.Policy{
+		Subject:     id,
+		SubjectType: policies.UserType,
+		Permission:  policies.MembershipPermission,
+		ObjectType:  policies.DomainType,
+		Object:      validID,
+	}
+	pr := policies.Policy{
+		Domain:      validID,
+		Subject:     id,
+		SubjectType: policies.UserType,
+		Permission:  policies.ViewPermission,
+		ObjectType:  policies.GroupType,
+		Object:      groupName,
+	}
+
+	cases := []struct {
+		desc      string
+		token     string
+		adminErr  error
+		memberErr error
+		decision  policies.Decision
+		explain   bool
+		explErr   error
+		err       error
+	}{
+		{
+			desc: "explain allowed decision",
+			decision: policies.Decision{
+				Allowed: true,
+				Path:    []string{"group:" + groupName + "#view", "group:parent#admin", "group:parent#administrator"},
+				Reason:  "inherited policy matches",
+			},
+			explain: true,
+		},
+		{
+			desc:     "explain denied decision",
+			decision: policies.Decision{Reason: "no direct or inherited policy matches"},
+			explain:  true,
+		},
+		{
+			desc:      "explain decision of subject outside the domain",
+			memberErr: svcerr.ErrAuthorization,
+			decision:  policies.Decision{Reason: "subject is not a member of the domain or the domain is not enabled"},
+		},
+		{
+			desc:    "explain decision with failed evaluation",
+			explain: true,
+			explErr: svcerr.ErrNotFound,
+			err:     svcerr.ErrViewEntity,
+		},
+		{
+			desc:     "explain decision as non platform admin",
+			adminErr: svcerr.ErrAuthorization,
+			err:      svcerr.ErrAuthorization,
+		},
+		{
+			desc:  "explain decision with invalid token",
+			token: inValidToken,
+			err:   svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, accessToken := newService()
+			if tc.token == "" {
+				tc.token = accessToken
+			}
+			pEvaluator.On("CheckPolicy", mock.Anything, adminPolicy).Return(tc.adminErr)
+			pEvaluator.On("CheckPolicy", mock.Anything, memberPolicy).Return(tc.memberErr)
+			pEvaluator.On("ExplainPolicy", mock.Anything, pr).Return(tc.decision, tc.explErr)
+			drepo.On("RetrieveByID", mock.Anything, validID).Return(auth.Domain{ID: validID, Status: auth.EnabledStatus}, nil)
+
+			d, err := svc.ExplainPolicy(context.Background(), tc.token, pr)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, tc.decision, d, fmt.Sprintf("%s expected decision %v got %v\n", tc.desc, tc.decision, d))
+			}
+			if tc.explain {
+				pEvaluator.AssertCalled(t, "ExplainPolicy", mock.Anything, pr)
+			} else {
+				pEvaluator.AssertNotCalled(t, "ExplainPolicy", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestSwitchToPermission(t *testing.T) {
 	cases := []struct {
 		desc     string
@@ -1511,12 +1830,23 @@ func TestRetrieveDomainPermissions(t *testing.T) {
 func TestUpdateDomain(t *testing.T) {
 	svc, accessToken := newService()
 
+	selfRegistration := true
+	adminPolicy := policies.Policy{
+		Subject:     email,
+		SubjectType: policies.UserType,
+		SubjectKind: policies.UsersKind,
+		Object:      validID,
+		ObjectType:  policies.DomainType,
+		Permission:  policies.AdminPermission,
+	}
+
 	cases := []struct {
 		desc            string
 		token           string
 		domainID        string
 		domReq          auth.DomainReq
 		checkPolicyErr  error
+		checkAdminErr   error
 		retrieveByIDErr error
 		updateErr       error
 		err             error
@@ -1574,10 +1904,31 @@ func TestUpdateDomain(t *testing.T) {
 			updateErr: errors.ErrMalformedEntity,
 			err:       errors.ErrMalformedEntity,
 		},
+		{
+			desc:     "update domain self-registration as admin",
+			token:    accessToken,
+			domainID: validID,
+			domReq: auth.DomainReq{
+				SelfRegistration: &selfRegistration,
+			},
+			err: nil,
+		},
+		{
+			desc:     "update domain self-registration as editor",
+			token:    accessToken,
+			domainID: validID,
+			domReq: auth.DomainReq{
+				SelfRegistration: &selfRegistration,
+			},
+			checkAdminErr: svcerr.ErrAuthorization,
+			err:           svcerr.ErrAuthorization,
+		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
+			// Unsetting the catch-all policy check also removes the admin one.
+			pEvaluator.On("CheckPolicy", mock.Anything, adminPolicy).Return(tc.checkAdminErr)
 			repoCall := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(tc.checkPolicyErr)
 			repoCall1 := drepo.On("RetrieveByID", mock.Anything, mock.Anything).Return(auth.Domain{}, tc.retrieveByIDErr)
 			repoCall2 := drepo.On("Update", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(auth.Domain{}, tc.updateErr)
@@ -1594,6 +1945,7 @@ func TestChangeDomainStatus(t *testing.T) {
 	svc, accessToken := newService()
 
 	disabledStatus := auth.DisabledStatus
+	suspendedStatus := auth.SuspendedStatus
 
 	cases := []struct {
 		desc             string
@@ -1653,6 +2005,15 @@ func TestChangeDomainStatus(t *testing.T) {
 			updateErr: errors.ErrMalformedEntity,
 			err:       errors.ErrMalformedEntity,
 		},
+		{
+			desc:     "change domain status to suspended",
+			token:    accessToken,
+			domainID: validID,
+			domainReq: auth.DomainReq{
+				Status: &suspendedStatus,
+			},
+			err: svcerr.ErrInvalidStatus,
+		},
 	}
 
 	for _, tc := range cases {
@@ -1669,6 +2030,392 @@ func TestChangeDomainStatus(t *testing.T) {
 	}
 }
 
+func TestSuspendDomain(t *testing.T) {
+	svc, accessToken := newService()
+
+	cases := []struct {
+		desc           string
+		token          string
+		domainID       string
+		checkPolicyErr error
+		suspendErr     error
+		addPolicyErr   error
+		err            error
+	}{
+		{
+			desc:     "suspend domain successfully",
+			token:    accessToken,
+			domainID: validID,
+			err:      nil,
+		},
+		{
+			desc:     "suspend domain with invalid token",
+			token:    inValidToken,
+			domainID: validID,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:           "suspend domain as non platform admin",
+			token:          accessToken,
+			domainID:       validID,
+			checkPolicyErr: svcerr.ErrAuthorization,
+			err:            svcerr.ErrAuthorization,
+		},
+		{
+			desc:       "suspend already suspended domain",
+			token:      accessToken,
+			domainID:   validID,
+			suspendErr: repoerr.ErrNotFound,
+			err:        svcerr.ErrUpdateEntity,
+		},
+		{
+			desc:         "suspend domain with failed to add policy",
+			token:        accessToken,
+			domainID:     validID,
+			addPolicyErr: errAddPolicies,
+			err:          errAddPolicies,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(tc.checkPolicyErr)
+			repoCall1 := drepo.On("Suspend", mock.Anything, tc.domainID, mock.Anything).Return(auth.Domain{ID: tc.domainID, Status: auth.SuspendedStatus}, tc.suspendErr)
+			repoCall2 := pService.On("AddPolicy", mock.Anything, mock.Anything).Return(tc.addPolicyErr)
+			repoCall3 := drepo.On("Resume", mock.Anything, tc.domainID, mock.Anything).Return(auth.Domain{ID: tc.domainID}, nil)
+			d, err := svc.SuspendDomain(context.Background(), tc.token, tc.domainID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, auth.SuspendedStatus, d.Status, fmt.Sprintf("%s expected status %s got %s\n", tc.desc, auth.SuspendedStatus, d.Status))
+			}
+			if tc.addPolicyErr != nil {
+				drepo.AssertCalled(t, "Resume", mock.Anything, tc.domainID, mock.Anything)
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			repoCall3.Unset()
+		})
+	}
+}
+
+// policyStore is an in-memory policy storage backing the policy service mock
+// in the ownership transfer tests, keyed by the policy string.
+type policyStore map[string]policies.Policy
+
+func ownerPolicy(subject, relation, objectType, object string) policies.Policy {
+	return policies.Policy{
+		SubjectType: policies.UserType,
+		Subject:     subject,
+		Relation:    relation,
+		ObjectType:  objectType,
+		Object:      object,
+	}
+}
+
+func (ps policyStore) list(_ context.Context, filter policies.Policy, _ string, _ uint64) (policies.PoliciesPage, error) {
+	page := policies.PoliciesPage{}
+	for _, pr := range ps {
+		if pr.ObjectType == filter.ObjectType && pr.Subject == filter.Subject && (filter.Relation == "" || pr.Relation == filter.Relation) {
+			page.Policies = append(page.Policies, pr)
+		}
+	}
+
+	return page, nil
+}
+
+func (ps policyStore) apply(prs []policies.Policy, add bool) {
+	for _, pr := range prs {
+		key := ownerPolicy(pr.Subject, pr.Relation, pr.ObjectType, pr.Object)
+		if add {
+			ps[key.String()] = key
+			continue
+		}
+		delete(ps, key.String())
+	}
+}
+
+func (ps policyStore) has(pr policies.Policy) bool {
+	_, ok := ps[pr.String()]
+	return ok
+}
+
+func TestTransferOwnership(t *testing.T) {
+	svc, accessToken := newService()
+
+	fromUserID := testsutil.GenerateUUID(t)
+	toUserID := testsutil.GenerateUUID(t)
+	from := auth.EncodeDomainUserID(validID, fromUserID)
+	to := auth.EncodeDomainUserID(validID, toUserID)
+	things := []string{testsutil.GenerateUUID(t), testsutil.GenerateUUID(t)}
+	group := testsutil.GenerateUUID(t)
+
+	cases := []struct {
+		desc      string
+		token     string
+		req       auth.TransferReq
+		adminErr  error
+		targetErr error
+		listErr   error
+		writeErr  error
+		resources map[string][]string
+		owners    map[string]string
+		err       error
+	}{
+		{
+			desc:      "transfer things successfully",
+			token:     accessToken,
+			req:       auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}},
+			resources: map[string][]string{policies.ThingType: things},
+			owners:    map[string]string{things[0]: to, things[1]: to, group: from},
+		},
+		{
+			desc:      "transfer things and groups successfully",
+			token:     accessToken,
+			req:       auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType, policies.GroupType}},
+			resources: map[string][]string{policies.ThingType: things, policies.GroupType: {group}},
+			owners:    map[string]string{things[0]: to, things[1]: to, group: to},
+		},
+		{
+			desc:      "transfer things in dry run mode",
+			token:     accessToken,
+			req:       auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}, DryRun: true},
+			resources: map[string][]string{policies.ThingType: things},
+			owners:    map[string]string{things[0]: from, things[1]: from, group: from},
+		},
+		{
+			desc:  "transfer with invalid token",
+			token: inValidToken,
+			req:   auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}},
+			err:   svcerr.ErrAuthentication,
+		},
+		{
+			desc:     "transfer as non domain admin",
+			token:    accessToken,
+			req:      auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}},
+			adminErr: svcerr.ErrAuthorization,
+			owners:   map[string]string{things[0]: from, things[1]: from, group: from},
+			err:      svcerr.ErrDomainAuthorization,
+		},
+		{
+			desc:      "transfer to user without domain access",
+			token:     accessToken,
+			req:       auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}},
+			targetErr: svcerr.ErrAuthorization,
+			owners:    map[string]string{things[0]: from, things[1]: from, group: from},
+			err:       svcerr.ErrAuthorization,
+		},
+		{
+			desc:   "transfer to the same user",
+			token:  accessToken,
+			req:    auth.TransferReq{FromUserID: fromUserID, ToUserID: fromUserID, EntityTypes: []string{policies.ThingType}},
+			owners: map[string]string{things[0]: from, things[1]: from, group: from},
+			err:    svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:   "transfer invalid entity type",
+			token:  accessToken,
+			req:    auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.DomainType}},
+			owners: map[string]string{things[0]: from, things[1]: from, group: from},
+			err:    svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:    "transfer with failed to list policies",
+			token:   accessToken,
+			req:     auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}},
+			listErr: svcerr.ErrViewEntity,
+			owners:  map[string]string{things[0]: from, things[1]: from, group: from},
+			err:     svcerr.ErrViewEntity,
+		},
+		{
+			desc:     "transfer with failed to write policies",
+			token:    accessToken,
+			req:      auth.TransferReq{FromUserID: fromUserID, ToUserID: toUserID, EntityTypes: []string{policies.ThingType}},
+			writeErr: svcerr.ErrUpdateEntity,
+			owners:   map[string]string{things[0]: from, things[1]: from, group: from},
+			err:      svcerr.ErrUpdateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			// The target user is an editor of one of the transferred things.
+			store := policyStore{}
+			store.apply([]policies.Policy{
+				ownerPolicy(from, policies.AdministratorRelation, policies.ThingType, things[0]),
+				ownerPolicy(from, policies.AdministratorRelation, policies.ThingType, things[1]),
+				ownerPolicy(from, policies.AdministratorRelation, policies.GroupType, group),
+				ownerPolicy(to, policies.EditorRelation, policies.ThingType, things[1]),
+			}, true)
+			list := store.list
+			if tc.listErr != nil {
+				list = func(context.Context, policies.Policy, string, uint64) (policies.PoliciesPage, error) {
+					return policies.PoliciesPage{}, tc.listErr
+				}
+			}
+			repoCall := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(func(_ context.Context, pr policies.Policy) error {
+				if pr.Subject == toUserID {
+					return tc.targetErr
+				}
+				return tc.adminErr
+			})
+			repoCall1 := drepo.On("RetrieveByID", mock.Anything, validID).Return(auth.Domain{ID: validID, Status: auth.EnabledStatus}, nil)
+			repoCall2 := pService.On("ListPolicies", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(list)
+			repoCall3 := pService.On("ReplacePolicies", mock.Anything, mock.Anything, mock.Anything).Return(tc.writeErr).Run(func(args mock.Arguments) {
+				if tc.writeErr == nil {
+					store.apply(args.Get(1).([]policies.Policy), false)
+					store.apply(args.Get(2).([]policies.Policy), true)
+				}
+			})
+			report, err := svc.TransferOwnership(context.Background(), tc.token, validID, tc.req)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, tc.req.DryRun, report.DryRun, fmt.Sprintf("%s: expected dry run %t got %t\n", tc.desc, tc.req.DryRun, report.DryRun))
+				for entityType, ids := range tc.resources {
+					assert.ElementsMatch(t, ids, report.Resources[entityType], fmt.Sprintf("%s: unexpected %s resources\n", tc.desc, entityType))
+				}
+			}
+			for object, owner := range tc.owners {
+				objectType := policies.ThingType
+				if object == group {
+					objectType = policies.GroupType
+				}
+				previous := from
+				if owner == from {
+					previous = to
+				}
+				assert.True(t, store.has(ownerPolicy(owner, policies.AdministratorRelation, objectType, object)), fmt.Sprintf("%s: expected %s to administer %s\n", tc.desc, owner, object))
+				assert.False(t, store.has(ownerPolicy(previous, policies.AdministratorRelation, objectType, object)), fmt.Sprintf("%s: expected %s not to administer %s\n", tc.desc, previous, object))
+			}
+			if tc.owners[things[1]] == to {
+				assert.False(t, store.has(ownerPolicy(to, policies.EditorRelation, policies.ThingType, things[1])), fmt.Sprintf("%s: expected superseded editor relation to be removed\n", tc.desc))
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			repoCall3.Unset()
+		})
+	}
+}
+
+func TestResumeDomain(t *testing.T) {
+	svc, accessToken := newService()
+
+	cases := []struct {
+		desc            string
+		token           string
+		domainID        string
+		checkPolicyErr  error
+		resumeErr       error
+		deletePolicyErr error
+		err             error
+	}{
+		{
+			desc:     "resume domain successfully",
+			token:    accessToken,
+			domainID: validID,
+			err:      nil,
+		},
+		{
+			desc:     "resume domain with invalid token",
+			token:    inValidToken,
+			domainID: validID,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:           "resume domain as non platform admin",
+			token:          accessToken,
+			domainID:       validID,
+			checkPolicyErr: svcerr.ErrAuthorization,
+			err:            svcerr.ErrAuthorization,
+		},
+		{
+			desc:      "resume domain that is not suspended",
+			token:     accessToken,
+			domainID:  validID,
+			resumeErr: repoerr.ErrNotFound,
+			err:       svcerr.ErrUpdateEntity,
+		},
+		{
+			desc:            "resume domain with failed to delete policy",
+			token:           accessToken,
+			domainID:        validID,
+			deletePolicyErr: svcerr.ErrRemoveEntity,
+			err:             svcerr.ErrRemoveEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(tc.checkPolicyErr)
+			repoCall1 := drepo.On("Resume", mock.Anything, tc.domainID, mock.Anything).Return(auth.Domain{ID: tc.domainID, Status: auth.DisabledStatus}, tc.resumeErr)
+			repoCall2 := pService.On("DeletePolicies", mock.Anything, mock.Anything).Return(tc.deletePolicyErr)
+			repoCall3 := drepo.On("Suspend", mock.Anything, tc.domainID, mock.Anything).Return(auth.Domain{ID: tc.domainID, Status: auth.SuspendedStatus}, nil)
+			d, err := svc.ResumeDomain(context.Background(), tc.token, tc.domainID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, auth.DisabledStatus, d.Status, fmt.Sprintf("%s expected prior status %s got %s\n", tc.desc, auth.DisabledStatus, d.Status))
+			}
+			if tc.deletePolicyErr != nil {
+				drepo.AssertCalled(t, "Suspend", mock.Anything, tc.domainID, mock.Anything)
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			repoCall3.Unset()
+		})
+	}
+}
+
+func TestSuspendedDomainAccess(t *testing.T) {
+	svc, adminToken := newService()
+
+	// Tokens aren't scoped to a domain, so the suspension is enforced when
+	// the domain is authorized on.
+	status := auth.EnabledStatus
+	repoCall := drepo.On("RetrieveByID", mock.Anything, validID).Return(func(context.Context, string) (auth.Domain, error) {
+		return auth.Domain{ID: validID, Status: status}, nil
+	})
+	defer repoCall.Unset()
+	repoCall1 := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(nil)
+	defer repoCall1.Unset()
+
+	domainPolicy := policies.Policy{
+		Domain:      validID,
+		Subject:     auth.EncodeDomainUserID(validID, id),
+		SubjectType: policies.UserType,
+		Permission:  policies.ViewPermission,
+		Object:      validID,
+		ObjectType:  policies.DomainType,
+	}
+	err := svc.Authorize(context.Background(), domainPolicy)
+	assert.Nil(t, err, fmt.Sprintf("authorize on enabled domain expected to succeed: %s", err))
+
+	repoCall2 := drepo.On("Suspend", mock.Anything, validID, mock.Anything).Return(auth.Domain{ID: validID, Status: auth.SuspendedStatus}, nil).Run(func(mock.Arguments) {
+		status = auth.SuspendedStatus
+	})
+	repoCall3 := pService.On("AddPolicy", mock.Anything, mock.Anything).Return(nil)
+	_, err = svc.SuspendDomain(context.Background(), adminToken, validID)
+	assert.Nil(t, err, fmt.Sprintf("suspend domain expected to succeed: %s", err))
+	repoCall2.Unset()
+	repoCall3.Unset()
+
+	err = svc.Authorize(context.Background(), domainPolicy)
+	assert.True(t, errors.Contains(err, svcerr.ErrDomainAuthorization), fmt.Sprintf("authorize on suspended domain expected %s got %s", svcerr.ErrDomainAuthorization, err))
+
+	repoCall4 := drepo.On("Resume", mock.Anything, validID, mock.Anything).Return(auth.Domain{ID: validID, Status: auth.EnabledStatus}, nil).Run(func(mock.Arguments) {
+		status = auth.EnabledStatus
+	})
+	repoCall5 := pService.On("DeletePolicies", mock.Anything, mock.Anything).Return(nil)
+	_, err = svc.ResumeDomain(context.Background(), adminToken, validID)
+	assert.Nil(t, err, fmt.Sprintf("resume domain expected to succeed: %s", err))
+	repoCall4.Unset()
+	repoCall5.Unset()
+
+	err = svc.Authorize(context.Background(), domainPolicy)
+	assert.Nil(t, err, fmt.Sprintf("authorize on resumed domain expected to succeed: %s", err))
+}
+
 func TestListDomains(t *testing.T) {
 	svc, accessToken := newService()
 
@@ -1746,6 +2493,8 @@ func TestAssignUsers(t *testing.T) {
 		domainID             string
 		userIDs              []string
 		relation             string
+		conditions           []string
+		conds                policies.Conditions
 		checkPolicyReq3      policies.Policy
 		checkAdminPolicyReq  policies.Policy
 		checkDomainPolicyReq policies.Policy
@@ -1796,6 +2545,85 @@ func TestAssignUsers(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			desc:       "assign users with conditions successfully",
+			token:      accessToken,
+			domainID:   validID,
+			userIDs:    []string{validID},
+			relation:   policies.ContributorRelation,
+			conditions: []string{"region=eu", "tier=gold, silver"},
+			conds:      policies.Conditions{"region": {"eu"}, "tier": {"gold", "silver"}},
+			checkPolicyReq3: policies.Policy{
+				Subject:     email,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Object:      validID,
+				ObjectType:  policies.DomainType,
+				Permission:  policies.SharePermission,
+			},
+			checkAdminPolicyReq: policies.Policy{
+				Subject:     email,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Object:      validID,
+				ObjectType:  policies.DomainType,
+				Permission:  policies.ViewPermission,
+			},
+			checkDomainPolicyReq: policies.Policy{
+				Subject:     validID,
+				SubjectType: policies.UserType,
+				Object:      policies.MagistralaObject,
+				ObjectType:  policies.PlatformType,
+				Permission:  policies.MembershipPermission,
+			},
+			checkPolicyReq33: policies.Policy{
+				Subject:     email,
+				SubjectType: policies.UserType,
+				Object:      validID,
+				ObjectType:  policies.DomainType,
+				Permission:  policies.MembershipPermission,
+			},
+			err: nil,
+		},
+		{
+			desc:       "assign users with invalid conditions",
+			token:      accessToken,
+			domainID:   validID,
+			userIDs:    []string{validID},
+			relation:   policies.ContributorRelation,
+			conditions: []string{"region"},
+			checkPolicyReq3: policies.Policy{
+				Subject:     email,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Object:      validID,
+				ObjectType:  policies.DomainType,
+				Permission:  policies.SharePermission,
+			},
+			checkAdminPolicyReq: policies.Policy{
+				Subject:     email,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Object:      validID,
+				ObjectType:  policies.DomainType,
+				Permission:  policies.ViewPermission,
+			},
+			checkDomainPolicyReq: policies.Policy{
+				Subject:     validID,
+				SubjectType: policies.UserType,
+				Object:      policies.MagistralaObject,
+				ObjectType:  policies.PlatformType,
+				Permission:  policies.MembershipPermission,
+			},
+			checkPolicyReq33: policies.Policy{
+				Subject:     email,
+				SubjectType: policies.UserType,
+				Object:      validID,
+				ObjectType:  policies.DomainType,
+				Permission:  policies.MembershipPermission,
+			},
+			err: svcerr.ErrMalformedEntity,
+		},
 		{
 			desc:     "assign users with invalid token",
 			token:    inValidToken,
@@ -2025,10 +2853,14 @@ func TestAssignUsers(t *testing.T) {
 			repoCall2 := pEvaluator.On("CheckPolicy", mock.Anything, tc.checkAdminPolicyReq).Return(tc.checkPolicyErr1)
 			repoCall3 := pEvaluator.On("CheckPolicy", mock.Anything, tc.checkDomainPolicyReq).Return(tc.checkPolicyErr2)
 			repoCall4 := pEvaluator.On("CheckPolicy", mock.Anything, tc.checkPolicyReq33).Return(tc.checkPolicyErr2)
-			repoCall5 := pService.On("AddPolicies", mock.Anything, mock.Anything).Return(tc.addPoliciesErr)
+			repoCall5 := pService.On("AddPolicies", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				for _, pr := range args.Get(1).([]policies.Policy) {
+					assert.Equal(t, tc.conds, pr.Conditions, fmt.Sprintf("%s: expected conditions %v got %v\n", tc.desc, tc.conds, pr.Conditions))
+				}
+			}).Return(tc.addPoliciesErr)
 			repoCall6 := drepo.On("SavePolicies", mock.Anything, mock.Anything, mock.Anything).Return(tc.savePoliciesErr)
 			repoCall7 := pService.On("DeletePolicies", mock.Anything, mock.Anything).Return(tc.deletePoliciesErr)
-			err := svc.AssignUsers(context.Background(), tc.token, tc.domainID, tc.userIDs, tc.relation)
+			err := svc.AssignUsers(context.Background(), tc.token, tc.domainID, tc.userIDs, tc.relation, tc.conditions)
 			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
 			repoCall.Unset()
 			repoCall1.Unset()
@@ -2042,6 +2874,81 @@ func TestAssignUsers(t *testing.T) {
 	}
 }
 
+func TestAssignUsersSelfRegistration(t *testing.T) {
+	svc, accessToken := newService()
+
+	cases := []struct {
+		desc        string
+		userIDs     []string
+		relation    string
+		domain      auth.Domain
+		retrieveErr error
+		err         error
+	}{
+		{
+			desc:     "join domain allowing self-registration",
+			userIDs:  []string{email},
+			relation: policies.MemberRelation,
+			domain:   auth.Domain{ID: validID, SelfRegistration: true, Status: auth.EnabledStatus},
+		},
+		{
+			desc:     "join domain disallowing self-registration",
+			userIDs:  []string{email},
+			relation: policies.MemberRelation,
+			domain:   auth.Domain{ID: validID, Status: auth.EnabledStatus},
+			err:      svcerr.ErrSelfRegistrationDisabled,
+		},
+		{
+			desc:     "join disabled domain allowing self-registration",
+			userIDs:  []string{email},
+			relation: policies.MemberRelation,
+			domain:   auth.Domain{ID: validID, SelfRegistration: true, Status: auth.DisabledStatus},
+			err:      svcerr.ErrSelfRegistrationDisabled,
+		},
+		{
+			desc:        "join non-existing domain",
+			userIDs:     []string{email},
+			relation:    policies.MemberRelation,
+			retrieveErr: repoerr.ErrNotFound,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:     "join domain allowing self-registration as admin",
+			userIDs:  []string{email},
+			relation: policies.AdministratorRelation,
+			domain:   auth.Domain{ID: validID, SelfRegistration: true, Status: auth.EnabledStatus},
+			err:      svcerr.ErrDomainAuthorization,
+		},
+		{
+			desc:     "assign other user to domain allowing self-registration",
+			userIDs:  []string{validID},
+			relation: policies.MemberRelation,
+			domain:   auth.Domain{ID: validID, SelfRegistration: true, Status: auth.EnabledStatus},
+			err:      svcerr.ErrDomainAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := drepo.On("RetrieveByID", mock.Anything, validID).Return(tc.domain, tc.retrieveErr)
+			// The user isn't allowed to share the domain.
+			repoCall1 := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(svcerr.ErrAuthorization)
+			repoCall2 := pService.On("AddPolicies", mock.Anything, mock.Anything).Return(nil)
+			repoCall3 := drepo.On("SavePolicies", mock.Anything, mock.Anything).Return(nil)
+			err := svc.AssignUsers(context.Background(), accessToken, validID, tc.userIDs, tc.relation, nil)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err == nil {
+				ok := repoCall2.Parent.AssertCalled(t, "AddPolicies", mock.Anything, mock.Anything)
+				assert.True(t, ok, fmt.Sprintf("%s: expected the user to join the domain", tc.desc))
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			repoCall3.Unset()
+		})
+	}
+}
+
 func TestUnassignUser(t *testing.T) {
 	svc, accessToken := newService()
 
@@ -2270,6 +3177,7 @@ func TestListUsersDomains(t *testing.T) {
 		retreiveByIDErr error
 		checkPolicyErr  error
 		listDomainErr   error
+		subjectID       string
 		err             error
 	}{
 		{
@@ -2305,6 +3213,40 @@ func TestListUsersDomains(t *testing.T) {
 			},
 			err: svcerr.ErrAuthentication,
 		},
+		{
+			desc:  "list own domains as non admin",
+			token: accessToken,
+			page: auth.Page{
+				Offset: 0,
+				Limit:  10,
+			},
+			checkPolicyErr: svcerr.ErrAuthorization,
+			subjectID:      email,
+			err:            nil,
+		},
+		{
+			desc:   "list own domains by user id as non admin",
+			token:  accessToken,
+			userID: email,
+			page: auth.Page{
+				Offset: 0,
+				Limit:  10,
+			},
+			checkPolicyErr: svcerr.ErrAuthorization,
+			subjectID:      email,
+			err:            nil,
+		},
+		{
+			desc:   "list other user domains as admin",
+			token:  accessToken,
+			userID: validID,
+			page: auth.Page{
+				Offset: 0,
+				Limit:  10,
+			},
+			subjectID: validID,
+			err:       nil,
+		},
 		{
 			desc:   "list users domains with invalid domainID",
 			token:  accessToken,
@@ -2334,9 +3276,15 @@ func TestListUsersDomains(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			repoCall := pEvaluator.On("CheckPolicy", mock.Anything, mock.Anything).Return(tc.checkPolicyErr)
+			drepo.Calls = nil
 			repoCall1 := drepo.On("ListDomains", mock.Anything, mock.Anything).Return(auth.DomainsPage{}, tc.listDomainErr)
 			_, err := svc.ListUserDomains(context.Background(), tc.token, tc.userID, tc.page)
 			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.subjectID != "" {
+				drepo.AssertCalled(t, "ListDomains", mock.Anything, mock.MatchedBy(func(p auth.Page) bool {
+					return p.SubjectID == tc.subjectID
+				}))
+			}
 			repoCall.Unset()
 			repoCall1.Unset()
 		})
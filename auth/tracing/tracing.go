@@ -75,6 +75,20 @@ func (tm *tracingMiddleware) Authorize(ctx context.Context, pr policies.Policy)
 	return tm.svc.Authorize(ctx, pr)
 }
 
+func (tm *tracingMiddleware) ExplainPolicy(ctx context.Context, token string, pr policies.Policy) (policies.Decision, error) {
+	ctx, span := tm.tracer.Start(ctx, "explain_policy", trace.WithAttributes(
+		attribute.String("subject", pr.Subject),
+		attribute.String("subject_type", pr.SubjectType),
+		attribute.String("subject_relation", pr.SubjectRelation),
+		attribute.String("object", pr.Object),
+		attribute.String("object_type", pr.ObjectType),
+		attribute.String("permission", pr.Permission),
+	))
+	defer span.End()
+
+	return tm.svc.ExplainPolicy(ctx, token, pr)
+}
+
 func (tm *tracingMiddleware) CreateDomain(ctx context.Context, token string, d auth.Domain) (auth.Domain, error) {
 	ctx, span := tm.tracer.Start(ctx, "create_domain", trace.WithAttributes(
 		attribute.String("name", d.Name),
@@ -115,20 +129,37 @@ func (tm *tracingMiddleware) ChangeDomainStatus(ctx context.Context, token, id s
 	return tm.svc.ChangeDomainStatus(ctx, token, id, d)
 }
 
+func (tm *tracingMiddleware) SuspendDomain(ctx context.Context, token, id string) (auth.Domain, error) {
+	ctx, span := tm.tracer.Start(ctx, "suspend_domain", trace.WithAttributes(
+		attribute.String("id", id),
+	))
+	defer span.End()
+	return tm.svc.SuspendDomain(ctx, token, id)
+}
+
+func (tm *tracingMiddleware) ResumeDomain(ctx context.Context, token, id string) (auth.Domain, error) {
+	ctx, span := tm.tracer.Start(ctx, "resume_domain", trace.WithAttributes(
+		attribute.String("id", id),
+	))
+	defer span.End()
+	return tm.svc.ResumeDomain(ctx, token, id)
+}
+
 func (tm *tracingMiddleware) ListDomains(ctx context.Context, token string, p auth.Page) (auth.DomainsPage, error) {
 	ctx, span := tm.tracer.Start(ctx, "list_domains")
 	defer span.End()
 	return tm.svc.ListDomains(ctx, token, p)
 }
 
-func (tm *tracingMiddleware) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string) error {
+func (tm *tracingMiddleware) AssignUsers(ctx context.Context, token, id string, userIds []string, relation string, conditions []string) error {
 	ctx, span := tm.tracer.Start(ctx, "assign_users", trace.WithAttributes(
 		attribute.String("id", id),
 		attribute.StringSlice("user_ids", userIds),
 		attribute.String("relation", relation),
+		attribute.StringSlice("conditions", conditions),
 	))
 	defer span.End()
-	return tm.svc.AssignUsers(ctx, token, id, userIds, relation)
+	return tm.svc.AssignUsers(ctx, token, id, userIds, relation, conditions)
 }
 
 func (tm *tracingMiddleware) UnassignUser(ctx context.Context, token, id, userID string) error {
@@ -148,6 +179,36 @@ func (tm *tracingMiddleware) ListUserDomains(ctx context.Context, token, userID
 	return tm.svc.ListUserDomains(ctx, token, userID, p)
 }
 
+func (tm *tracingMiddleware) TransferOwnership(ctx context.Context, token, id string, req auth.TransferReq) (auth.TransferReport, error) {
+	ctx, span := tm.tracer.Start(ctx, "transfer_ownership", trace.WithAttributes(
+		attribute.String("id", id),
+		attribute.String("from_user_id", req.FromUserID),
+		attribute.String("to_user_id", req.ToUserID),
+		attribute.StringSlice("entity_types", req.EntityTypes),
+		attribute.Bool("dry_run", req.DryRun),
+	))
+	defer span.End()
+	return tm.svc.TransferOwnership(ctx, token, id, req)
+}
+
+func (tm *tracingMiddleware) ExportPolicies(ctx context.Context, token, id string) (auth.PolicyExport, error) {
+	ctx, span := tm.tracer.Start(ctx, "export_policies", trace.WithAttributes(
+		attribute.String("id", id),
+	))
+	defer span.End()
+	return tm.svc.ExportPolicies(ctx, token, id)
+}
+
+func (tm *tracingMiddleware) ImportPolicies(ctx context.Context, token, id string, req auth.ImportReq) (auth.ImportReport, error) {
+	ctx, span := tm.tracer.Start(ctx, "import_policies", trace.WithAttributes(
+		attribute.String("id", id),
+		attribute.Int("policies", len(req.Policies)),
+		attribute.Bool("dry_run", req.DryRun),
+	))
+	defer span.End()
+	return tm.svc.ImportPolicies(ctx, token, id, req)
+}
+
 func (tm *tracingMiddleware) DeleteUserFromDomains(ctx context.Context, id string) error {
 	ctx, span := tm.tracer.Start(ctx, "delete_user_from_domains", trace.WithAttributes(
 		attribute.String("id", id),
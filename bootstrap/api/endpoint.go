@@ -27,21 +27,15 @@ func addEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 			return nil, svcerr.ErrAuthorization
 		}
 
-		channels := []bootstrap.Channel{}
-		for _, c := range req.Channels {
-			channels = append(channels, bootstrap.Channel{ID: c})
-		}
-
-		config := bootstrap.Config{
-			ThingID:     req.ThingID,
-			ExternalID:  req.ExternalID,
-			ExternalKey: req.ExternalKey,
-			Channels:    channels,
-			Name:        req.Name,
-			ClientCert:  req.ClientCert,
-			ClientKey:   req.ClientKey,
-			CACert:      req.CACert,
-			Content:     req.Content,
+		config := req.config()
+		if req.validateConfig {
+			problems, err := svc.Validate(ctx, session, req.token, config)
+			if err != nil {
+				return nil, err
+			}
+			if len(problems) > 0 {
+				return validateRes{Problems: problems, rejected: true}, nil
+			}
 		}
 
 		saved, err := svc.Add(ctx, session, req.token, config)
@@ -58,6 +52,27 @@ func addEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	}
 }
 
+func validateEndpoint(svc bootstrap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(validateReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		problems, err := svc.Validate(ctx, session, req.token, req.config())
+		if err != nil {
+			return nil, err
+		}
+
+		return validateRes{Valid: len(problems) == 0, Problems: problems}, nil
+	}
+}
+
 func updateCertEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(updateCertReq)
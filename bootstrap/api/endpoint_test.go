@@ -348,6 +348,180 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	bs, svc, auth := newBootstrapServer()
+	defer bs.Close()
+
+	data := toJSON(addReq)
+	missingChannel := []bootstrap.Problem{
+		{Field: "channels[0]", Message: "channel 1 doesn't exist or isn't accessible"},
+	}
+
+	cases := []struct {
+		desc            string
+		req             string
+		token           string
+		session         mgauthn.Session
+		contentType     string
+		validate        bool
+		problems        []bootstrap.Problem
+		status          int
+		authenticateErr error
+		svcErr          error
+	}{
+		{
+			desc:        "validate a valid config",
+			req:         data,
+			token:       validToken,
+			contentType: contentType,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "validate a config referencing a missing channel",
+			req:         data,
+			token:       validToken,
+			contentType: contentType,
+			problems:    missingChannel,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "validate an empty config",
+			req:         "{}",
+			token:       validToken,
+			contentType: contentType,
+			problems:    []bootstrap.Problem{{Field: "channels", Message: "missing channels"}},
+			status:      http.StatusOK,
+		},
+		{
+			desc:            "validate a config with invalid token",
+			req:             data,
+			token:           invalidToken,
+			contentType:     contentType,
+			status:          http.StatusUnauthorized,
+			authenticateErr: svcerr.ErrAuthentication,
+		},
+		{
+			desc:        "validate a config with wrong content type",
+			req:         data,
+			token:       validToken,
+			contentType: "",
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:        "validate a config with invalid request format",
+			req:         "}",
+			token:       validToken,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "validate a config with failed authorization",
+			req:         data,
+			token:       validToken,
+			contentType: contentType,
+			status:      http.StatusForbidden,
+			svcErr:      svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.token == validToken {
+				tc.session = mgauthn.Session{DomainUserID: domainID + "_" + validID, UserID: validID, DomainID: domainID}
+			}
+			authCall := auth.On("Authenticate", mock.Anything, tc.token).Return(tc.session, tc.authenticateErr)
+			svcCall := svc.On("Validate", mock.Anything, tc.session, tc.token, mock.Anything).Return(tc.problems, tc.svcErr)
+			req := testRequest{
+				client:      bs.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/%s/things/configs/validate", bs.URL, domainID),
+				contentType: tc.contentType,
+				token:       tc.token,
+				body:        strings.NewReader(tc.req),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusOK {
+				var body validateRes
+				err := json.NewDecoder(res.Body).Decode(&body)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error decoding response %s", tc.desc, err))
+				assert.Equal(t, len(tc.problems) == 0, body.Valid, fmt.Sprintf("%s: expected valid %t got %t", tc.desc, len(tc.problems) == 0, body.Valid))
+				assert.Equal(t, tc.problems, body.Problems, fmt.Sprintf("%s: expected problems %v got %v", tc.desc, tc.problems, body.Problems))
+			}
+			svcCall.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
+func TestAddValidate(t *testing.T) {
+	bs, svc, auth := newBootstrapServer()
+	defer bs.Close()
+	c := newConfig()
+
+	data := toJSON(addReq)
+
+	cases := []struct {
+		desc     string
+		query    string
+		problems []bootstrap.Problem
+		status   int
+	}{
+		{
+			desc:   "add a valid config with validation",
+			query:  "?validate=true",
+			status: http.StatusCreated,
+		},
+		{
+			desc:     "add a config referencing a missing channel with validation",
+			query:    "?validate=true",
+			problems: []bootstrap.Problem{{Field: "channels[0]", Message: "channel 1 doesn't exist or isn't accessible"}},
+			status:   http.StatusUnprocessableEntity,
+		},
+		{
+			desc:     "add a config referencing a missing channel without validation",
+			query:    "?validate=false",
+			problems: []bootstrap.Problem{{Field: "channels[0]", Message: "channel 1 doesn't exist or isn't accessible"}},
+			status:   http.StatusCreated,
+		},
+		{
+			desc:   "add a config with invalid validate query",
+			query:  "?validate=maybe",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			session := mgauthn.Session{DomainUserID: domainID + "_" + validID, UserID: validID, DomainID: domainID}
+			authCall := auth.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := svc.On("Validate", mock.Anything, session, validToken, mock.Anything).Return(tc.problems, nil)
+			svcCall1 := svc.On("Add", mock.Anything, session, validToken, mock.Anything).Return(c, nil)
+			req := testRequest{
+				client:      bs.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/%s/things/configs%s", bs.URL, domainID, tc.query),
+				contentType: contentType,
+				token:       validToken,
+				body:        strings.NewReader(data),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusUnprocessableEntity {
+				var body validateRes
+				err := json.NewDecoder(res.Body).Decode(&body)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error decoding response %s", tc.desc, err))
+				assert.Equal(t, tc.problems, body.Problems, fmt.Sprintf("%s: expected problems %v got %v", tc.desc, tc.problems, body.Problems))
+			}
+			svcCall.Unset()
+			svcCall1.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
 func TestView(t *testing.T) {
 	bs, svc, auth := newBootstrapServer()
 	defer bs.Close()
@@ -1393,6 +1567,11 @@ func TestChangeState(t *testing.T) {
 	}
 }
 
+type validateRes struct {
+	Valid    bool                `json:"valid"`
+	Problems []bootstrap.Problem `json:"problems"`
+}
+
 type channel struct {
 	ID       string      `json:"id"`
 	Name     string      `json:"name,omitempty"`
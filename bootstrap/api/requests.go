@@ -11,16 +11,17 @@ import (
 const maxLimitSize = 100
 
 type addReq struct {
-	token       string
-	ThingID     string   `json:"thing_id"`
-	ExternalID  string   `json:"external_id"`
-	ExternalKey string   `json:"external_key"`
-	Channels    []string `json:"channels"`
-	Name        string   `json:"name"`
-	Content     string   `json:"content"`
-	ClientCert  string   `json:"client_cert"`
-	ClientKey   string   `json:"client_key"`
-	CACert      string   `json:"ca_cert"`
+	token          string
+	validateConfig bool
+	ThingID        string   `json:"thing_id"`
+	ExternalID     string   `json:"external_id"`
+	ExternalKey    string   `json:"external_key"`
+	Channels       []string `json:"channels"`
+	Name           string   `json:"name"`
+	Content        string   `json:"content"`
+	ClientCert     string   `json:"client_cert"`
+	ClientKey      string   `json:"client_key"`
+	CACert         string   `json:"ca_cert"`
 }
 
 func (req addReq) validate() error {
@@ -49,6 +50,39 @@ func (req addReq) validate() error {
 	return nil
 }
 
+func (req addReq) config() bootstrap.Config {
+	channels := []bootstrap.Channel{}
+	for _, c := range req.Channels {
+		channels = append(channels, bootstrap.Channel{ID: c})
+	}
+
+	return bootstrap.Config{
+		ThingID:     req.ThingID,
+		ExternalID:  req.ExternalID,
+		ExternalKey: req.ExternalKey,
+		Channels:    channels,
+		Name:        req.Name,
+		ClientCert:  req.ClientCert,
+		ClientKey:   req.ClientKey,
+		CACert:      req.CACert,
+		Content:     req.Content,
+	}
+}
+
+// validateReq is the proposed config. Only the token is required, since the
+// missing fields are reported as the problems of the config.
+type validateReq struct {
+	addReq
+}
+
+func (req validateReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	return nil
+}
+
 type entityReq struct {
 	id string
 }
@@ -17,6 +17,7 @@ var (
 	_ magistrala.Response = (*stateRes)(nil)
 	_ magistrala.Response = (*viewRes)(nil)
 	_ magistrala.Response = (*listRes)(nil)
+	_ magistrala.Response = (*validateRes)(nil)
 )
 
 type removeRes struct{}
@@ -142,3 +143,27 @@ func (res updateConfigRes) Headers() map[string]string {
 func (res updateConfigRes) Empty() bool {
 	return false
 }
+
+// validateRes lists the problems of the config. The config saved with the
+// validation is rejected if it has problems.
+type validateRes struct {
+	Valid    bool                `json:"valid"`
+	Problems []bootstrap.Problem `json:"problems"`
+	rejected bool
+}
+
+func (res validateRes) Code() int {
+	if res.rejected {
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusOK
+}
+
+func (res validateRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res validateRes) Empty() bool {
+	return false
+}
@@ -28,6 +28,7 @@ const (
 	byteContentType = "application/octet-stream"
 	offsetKey       = "offset"
 	limitKey        = "limit"
+	validateKey     = "validate"
 	defOffset       = 0
 	defLimit        = 10
 )
@@ -58,6 +59,12 @@ func MakeHandler(svc bootstrap.Service, authn mgauthn.Authentication, reader boo
 					api.EncodeResponse,
 					opts...), "add").ServeHTTP)
 
+				r.Post("/validate", otelhttp.NewHandler(kithttp.NewServer(
+					validateEndpoint(svc),
+					decodeValidateRequest,
+					api.EncodeResponse,
+					opts...), "validate").ServeHTTP)
+
 				r.Get("/", otelhttp.NewHandler(kithttp.NewServer(
 					listEndpoint(svc),
 					decodeListRequest,
@@ -132,8 +139,31 @@ func decodeAddRequest(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
 	}
 
+	v, err := apiutil.ReadBoolQuery(r, validateKey, false)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
 	req := addReq{
-		token: apiutil.ExtractBearerToken(r),
+		token:          apiutil.ExtractBearerToken(r),
+		validateConfig: v,
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+func decodeValidateRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := validateReq{
+		addReq: addReq{
+			token: apiutil.ExtractBearerToken(r),
+		},
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
@@ -44,6 +44,10 @@ func (es *eventStore) Add(ctx context.Context, session mgauthn.Session, token st
 	return saved, err
 }
 
+func (es *eventStore) Validate(ctx context.Context, session mgauthn.Session, token string, cfg bootstrap.Config) ([]bootstrap.Problem, error) {
+	return es.svc.Validate(ctx, session, token, cfg)
+}
+
 func (es *eventStore) View(ctx context.Context, session mgauthn.Session, id string) (bootstrap.Config, error) {
 	cfg, err := es.svc.View(ctx, session, id)
 	if err != nil {
@@ -36,6 +36,14 @@ func (am *authorizationMiddleware) Add(ctx context.Context, session mgauthn.Sess
 	return am.svc.Add(ctx, session, token, cfg)
 }
 
+func (am *authorizationMiddleware) Validate(ctx context.Context, session mgauthn.Session, token string, cfg bootstrap.Config) ([]bootstrap.Problem, error) {
+	if err := am.authorize(ctx, "", policies.UserType, policies.UsersKind, session.DomainUserID, policies.MembershipPermission, policies.DomainType, session.DomainID); err != nil {
+		return nil, err
+	}
+
+	return am.svc.Validate(ctx, session, token, cfg)
+}
+
 func (am *authorizationMiddleware) View(ctx context.Context, session mgauthn.Session, id string) (bootstrap.Config, error) {
 	if err := am.authorize(ctx, session.DomainID, policies.UserType, policies.UsersKind, session.DomainUserID, policies.ViewPermission, policies.ThingType, id); err != nil {
 		return bootstrap.Config{}, err
@@ -45,6 +45,26 @@ func (lm *loggingMiddleware) Add(ctx context.Context, session mgauthn.Session, t
 	return lm.svc.Add(ctx, session, token, cfg)
 }
 
+// Validate logs the validate request. It logs the external ID, the number of problems
+// found and the time it took to complete the request. If the request fails, it logs the error.
+func (lm *loggingMiddleware) Validate(ctx context.Context, session mgauthn.Session, token string, cfg bootstrap.Config) (problems []bootstrap.Problem, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("external_id", cfg.ExternalID),
+			slog.Int("problems", len(problems)),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Validate bootstrap config failed", args...)
+			return
+		}
+		lm.logger.Info("Validate bootstrap config completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.Validate(ctx, session, token, cfg)
+}
+
 // View logs the view request. It logs the thing ID and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) View(ctx context.Context, session mgauthn.Session, id string) (saved bootstrap.Config, err error) {
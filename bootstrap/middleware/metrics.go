@@ -41,6 +41,16 @@ func (mm *metricsMiddleware) Add(ctx context.Context, session mgauthn.Session, t
 	return mm.svc.Add(ctx, session, token, cfg)
 }
 
+// Validate instruments Validate method with metrics.
+func (mm *metricsMiddleware) Validate(ctx context.Context, session mgauthn.Session, token string, cfg bootstrap.Config) (problems []bootstrap.Problem, err error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "validate").Add(1)
+		mm.latency.With("method", "validate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Validate(ctx, session, token, cfg)
+}
+
 // View instruments View method with metrics.
 func (mm *metricsMiddleware) View(ctx context.Context, session mgauthn.Session, id string) (saved bootstrap.Config, err error) {
 	defer func(begin time.Time) {
@@ -292,6 +292,36 @@ func (_m *Service) UpdateConnections(ctx context.Context, session authn.Session,
 	return r0
 }
 
+// Validate provides a mock function with given fields: ctx, session, token, cfg
+func (_m *Service) Validate(ctx context.Context, session authn.Session, token string, cfg bootstrap.Config) ([]bootstrap.Problem, error) {
+	ret := _m.Called(ctx, session, token, cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Validate")
+	}
+
+	var r0 []bootstrap.Problem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, bootstrap.Config) ([]bootstrap.Problem, error)); ok {
+		return rf(ctx, session, token, cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, bootstrap.Config) []bootstrap.Problem); ok {
+		r0 = rf(ctx, session, token, cfg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bootstrap.Problem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, string, bootstrap.Config) error); ok {
+		r1 = rf(ctx, session, token, cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // View provides a mock function with given fields: ctx, session, id
 func (_m *Service) View(ctx context.Context, session authn.Session, id string) (bootstrap.Config, error) {
 	ret := _m.Called(ctx, session, id)
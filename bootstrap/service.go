@@ -11,6 +11,7 @@ import (
 
 	"github.com/absmach/magistrala"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
@@ -51,6 +52,10 @@ var (
 	errConnectionChannels = errors.New("failed to check channels connections")
 	errThingNotFound      = errors.New("failed to find thing")
 	errUpdateCert         = errors.New("failed to update cert")
+
+	// ErrSecretNotRevealed indicates that Things doesn't reveal the secret of
+	// an existing thing.
+	ErrSecretNotRevealed = errors.New("thing secret is not revealed by Things service")
 )
 
 var _ Service = (*bootstrapService)(nil)
@@ -69,6 +74,13 @@ type Service interface {
 	// Update updates editable fields of the provided Config.
 	Update(ctx context.Context, session mgauthn.Session, cfg Config) error
 
+	// Validate returns the problems of the Config which would make the Thing
+	// fail to bootstrap, without saving it. The referenced Thing and Channels
+	// must exist and be accessible with the given token, the content must be
+	// valid JSON and the certificates must parse and match. A non-nil error is
+	// returned only if the Config can't be checked.
+	Validate(ctx context.Context, session mgauthn.Session, token string, cfg Config) ([]Problem, error)
+
 	// UpdateCert updates an existing Config certificate and token.
 	// A non-nil error is returned to indicate operation failure.
 	UpdateCert(ctx context.Context, session mgauthn.Session, thingID, clientCert, clientKey, caCert string) (Config, error)
@@ -157,6 +169,10 @@ func (bs bootstrapService) Add(ctx context.Context, session mgauthn.Session, tok
 		return Config{}, errors.Wrap(errThingNotFound, err)
 	}
 
+	if mgThing.Credentials.Secret == mgclients.MaskedSecret {
+		return Config{}, errors.Wrap(svcerr.ErrCreateEntity, ErrSecretNotRevealed)
+	}
+
 	for _, channel := range cfg.Channels {
 		if channel.DomainID != mgThing.DomainID {
 			return Config{}, errors.Wrap(svcerr.ErrMalformedEntity, errNotInSameDomain)
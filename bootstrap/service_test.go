@@ -11,6 +11,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"sort"
 	"testing"
 
@@ -18,7 +19,9 @@ import (
 	"github.com/absmach/magistrala/bootstrap/mocks"
 	"github.com/absmach/magistrala/internal/testsutil"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	policysvc "github.com/absmach/magistrala/pkg/policies"
 	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
@@ -94,6 +97,9 @@ func TestAdd(t *testing.T) {
 	neID := config
 	neID.ThingID = "non-existent"
 
+	masked := config
+	masked.ThingKey = mgclients.MaskedSecret
+
 	wrongChannels := config
 	ch := channel
 	ch.ID = "invalid"
@@ -131,6 +137,14 @@ func TestAdd(t *testing.T) {
 			thingErr: errors.NewSDKError(svcerr.ErrNotFound),
 			err:      svcerr.ErrNotFound,
 		},
+		{
+			desc:     "add a config of a thing with masked secret",
+			config:   masked,
+			token:    validToken,
+			userID:   validID,
+			domainID: domainID,
+			err:      bootstrap.ErrSecretNotRevealed,
+		},
 		{
 			desc:            "add a config with invalid list of channels",
 			config:          wrongChannels,
@@ -168,6 +182,92 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	svc := newService()
+
+	missingID := testsutil.GenerateUUID(t)
+	valid := config
+	valid.Content = `{"server": "mqtt://localhost:1883"}`
+
+	missingChannel := valid
+	missingChannel.Channels = []bootstrap.Channel{channel, {ID: missingID}}
+
+	// The content is passed to the thing as is, in any format.
+	textContent := valid
+	textContent.Content = "server=localhost"
+
+	keyWithoutCert := valid
+	keyWithoutCert.ClientKey = "key"
+
+	cases := []struct {
+		desc        string
+		config      bootstrap.Config
+		retrieveErr error
+		channelErr  errors.SDKError
+		problems    []bootstrap.Problem
+		err         error
+	}{
+		{
+			desc:        "validate a valid config",
+			config:      valid,
+			retrieveErr: repoerr.ErrNotFound,
+		},
+		{
+			desc:        "validate a config referencing a missing channel",
+			config:      missingChannel,
+			retrieveErr: repoerr.ErrNotFound,
+			channelErr:  errors.NewSDKErrorWithStatus(svcerr.ErrNotFound, http.StatusNotFound),
+			problems: []bootstrap.Problem{
+				{Field: "channels[1]", Message: fmt.Sprintf("channel %s doesn't exist or isn't accessible", missingID)},
+			},
+		},
+		{
+			desc:        "validate a config with non JSON content",
+			config:      textContent,
+			retrieveErr: repoerr.ErrNotFound,
+		},
+		{
+			desc:        "validate a config with client key without certificate",
+			config:      keyWithoutCert,
+			retrieveErr: repoerr.ErrNotFound,
+			problems: []bootstrap.Problem{
+				{Field: "client_key", Message: "client key without client certificate"},
+			},
+		},
+		{
+			desc:   "validate a config with external ID in use",
+			config: valid,
+			problems: []bootstrap.Problem{
+				{Field: "external_id", Message: fmt.Sprintf("external ID %s is used by another configuration", valid.ExternalID)},
+			},
+		},
+		{
+			desc:        "validate a config with unavailable things service",
+			config:      missingChannel,
+			retrieveErr: repoerr.ErrNotFound,
+			channelErr:  errors.NewSDKError(svcerr.ErrNotFound),
+			err:         bootstrap.ErrThings,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			session := mgauthn.Session{UserID: validID, DomainID: domainID, DomainUserID: validID}
+			repoCall := boot.On("RetrieveByExternalID", context.Background(), tc.config.ExternalID).Return(bootstrap.Config{}, tc.retrieveErr)
+			repoCall1 := sdk.On("Thing", tc.config.ThingID, domainID, validToken).Return(mgsdk.Thing{ID: tc.config.ThingID, DomainID: domainID}, nil)
+			repoCall2 := sdk.On("Channel", channel.ID, domainID, validToken).Return(mgsdk.Channel{ID: channel.ID, DomainID: domainID}, nil)
+			repoCall3 := sdk.On("Channel", missingID, domainID, validToken).Return(mgsdk.Channel{}, tc.channelErr)
+			problems, err := svc.Validate(context.Background(), session, validToken, tc.config)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.problems, problems, fmt.Sprintf("%s: expected problems %v got %v\n", tc.desc, tc.problems, problems))
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			repoCall3.Unset()
+		})
+	}
+}
+
 func TestView(t *testing.T) {
 	svc := newService()
 
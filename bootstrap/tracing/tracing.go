@@ -39,6 +39,17 @@ func (tm *tracingMiddleware) Add(ctx context.Context, session mgauthn.Session, t
 	return tm.svc.Add(ctx, session, token, cfg)
 }
 
+// Validate traces the "Validate" operation of the wrapped bootstrap.Service.
+func (tm *tracingMiddleware) Validate(ctx context.Context, session mgauthn.Session, token string, cfg bootstrap.Config) ([]bootstrap.Problem, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_validate_config", trace.WithAttributes(
+		attribute.String("thing_id", cfg.ThingID),
+		attribute.String("external_id", cfg.ExternalID),
+	))
+	defer span.End()
+
+	return tm.svc.Validate(ctx, session, token, cfg)
+}
+
 // View traces the "View" operation of the wrapped bootstrap.Service.
 func (tm *tracingMiddleware) View(ctx context.Context, session mgauthn.Session, id string) (bootstrap.Config, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_view_client", trace.WithAttributes(
@@ -0,0 +1,148 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package bootstrap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+)
+
+var errValidateConfig = errors.New("failed to validate bootstrap configuration")
+
+// Problem is a problem found in a bootstrap configuration, which would make
+// the thing fail to bootstrap with it.
+type Problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (bs bootstrapService) Validate(ctx context.Context, session mgauthn.Session, token string, cfg Config) ([]Problem, error) {
+	var problems []Problem
+	problem := func(field, format string, args ...interface{}) {
+		problems = append(problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case cfg.ExternalID == "":
+		problem("external_id", "missing external ID")
+	default:
+		_, err := bs.configs.RetrieveByExternalID(ctx, cfg.ExternalID)
+		switch {
+		case err == nil:
+			problem("external_id", "external ID %s is used by another configuration", cfg.ExternalID)
+		case !errors.Contains(err, repoerr.ErrNotFound):
+			return nil, errors.Wrap(errValidateConfig, err)
+		}
+	}
+	if cfg.ExternalKey == "" {
+		problem("external_key", "missing external key")
+	}
+
+	thingDomain := session.DomainID
+	if cfg.ThingID != "" {
+		thing, sdkErr := bs.sdk.Thing(cfg.ThingID, session.DomainID, token)
+		switch {
+		case sdkErr == nil:
+			thingDomain = thing.DomainID
+		case unavailable(sdkErr.StatusCode()):
+			return nil, errors.Wrap(ErrThings, sdkErr)
+		default:
+			problem("thing_id", "thing %s doesn't exist or isn't accessible", cfg.ThingID)
+		}
+	}
+
+	if len(cfg.Channels) == 0 {
+		problem("channels", "missing channels")
+	}
+	seen := make(map[string]bool, len(cfg.Channels))
+	for i, c := range cfg.Channels {
+		field := fmt.Sprintf("channels[%d]", i)
+		switch {
+		case c.ID == "":
+			problem(field, "missing channel ID")
+			continue
+		case seen[c.ID]:
+			problem(field, "channel %s is listed more than once", c.ID)
+			continue
+		}
+		seen[c.ID] = true
+
+		ch, sdkErr := bs.sdk.Channel(c.ID, session.DomainID, token)
+		switch {
+		case sdkErr == nil:
+			if ch.DomainID != thingDomain {
+				problem(field, "channel %s is not in the domain of the thing", c.ID)
+			}
+		case unavailable(sdkErr.StatusCode()):
+			return nil, errors.Wrap(ErrThings, sdkErr)
+		default:
+			problem(field, "channel %s doesn't exist or isn't accessible", c.ID)
+		}
+	}
+
+	problems = append(problems, certProblems(cfg)...)
+
+	return problems, nil
+}
+
+// certProblems returns the problems of the certificates of the configuration.
+// The client certificate must match the client key, and be issued by the CA
+// certificate if both are set.
+func certProblems(cfg Config) []Problem {
+	var problems []Problem
+	parse := func(field, data string) *x509.Certificate {
+		block, _ := pem.Decode([]byte(data))
+		if block == nil {
+			problems = append(problems, Problem{Field: field, Message: "no PEM encoded certificate"})
+			return nil
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			problems = append(problems, Problem{Field: field, Message: fmt.Sprintf("invalid certificate: %s", err)})
+			return nil
+		}
+		return cert
+	}
+
+	var clientCert, caCert *x509.Certificate
+	if cfg.ClientCert != "" {
+		clientCert = parse("client_cert", cfg.ClientCert)
+	}
+	if cfg.CACert != "" {
+		caCert = parse("ca_cert", cfg.CACert)
+	}
+
+	switch {
+	case cfg.ClientKey != "" && cfg.ClientCert == "":
+		problems = append(problems, Problem{Field: "client_key", Message: "client key without client certificate"})
+	case cfg.ClientKey == "" && cfg.ClientCert != "":
+		problems = append(problems, Problem{Field: "client_key", Message: "missing key of the client certificate"})
+	case clientCert != nil:
+		if _, err := tls.X509KeyPair([]byte(cfg.ClientCert), []byte(cfg.ClientKey)); err != nil {
+			problems = append(problems, Problem{Field: "client_key", Message: fmt.Sprintf("client key doesn't match the client certificate: %s", err)})
+		}
+	}
+
+	if clientCert != nil && caCert != nil {
+		if err := clientCert.CheckSignatureFrom(caCert); err != nil {
+			problems = append(problems, Problem{Field: "client_cert", Message: fmt.Sprintf("client certificate is not issued by the CA certificate: %s", err)})
+		}
+	}
+
+	return problems
+}
+
+// unavailable reports whether the request failed because the service
+// couldn't be reached or failed, rather than because of the request.
+func unavailable(statusCode int) bool {
+	return statusCode == 0 || statusCode >= http.StatusInternalServerError
+}
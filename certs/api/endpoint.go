@@ -67,6 +67,47 @@ func listSerials(svc certs.Service) endpoint.Endpoint {
 	}
 }
 
+func listThings(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listThingsReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		page, err := svc.ListThings(ctx, req.domainID, req.token, req.pm)
+		if err != nil {
+			return nil, err
+		}
+		res := thingsPageRes{
+			pageRes: pageRes{
+				Total:  page.Total,
+				Offset: page.Offset,
+				Limit:  page.Limit,
+			},
+			Things: []thingCertsRes{},
+		}
+
+		for _, th := range page.Things {
+			tr := thingCertsRes{
+				ThingID:   th.ThingID,
+				ThingName: th.ThingName,
+				Certs:     []certsRes{},
+			}
+			for _, cert := range th.Certificates {
+				tr.Certs = append(tr.Certs, certsRes{
+					SerialNumber: cert.SerialNumber,
+					ExpiryTime:   cert.ExpiryTime,
+					Revoked:      cert.Revoked,
+					ThingID:      cert.ThingID,
+					Issuer:       cert.Issuer,
+				})
+			}
+			res.Things = append(res.Things, tr)
+		}
+		return res, nil
+	}
+}
+
 func viewCert(svc certs.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(viewReq)
@@ -666,6 +666,140 @@ func TestListSerials(t *testing.T) {
 	}
 }
 
+func TestListThings(t *testing.T) {
+	cs, svc, auth := newCertServer()
+	defer cs.Close()
+	revoked := "false"
+	before := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	after := before.Add(-30 * 24 * time.Hour)
+	page := certs.ThingsPage{
+		Total:  1,
+		Limit:  10,
+		Things: []certs.ThingCerts{{ThingID: thingID, Certificates: []certs.Cert{cert}}},
+	}
+
+	cases := []struct {
+		desc            string
+		token           string
+		domainID        string
+		session         mgauthn.Session
+		query           string
+		pm              certs.PageMetadata
+		status          int
+		authenticateErr error
+		svcRes          certs.ThingsPage
+		svcErr          error
+		err             error
+	}{
+		{
+			desc:     "list things successfully",
+			domainID: valid,
+			token:    valid,
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 10},
+			status:   http.StatusOK,
+			svcRes:   page,
+		},
+		{
+			desc:     "list things with certs expiring before date",
+			domainID: valid,
+			token:    valid,
+			query:    "?expires_before=" + before.Format(time.RFC3339),
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 10, ExpiresBefore: before},
+			status:   http.StatusOK,
+			svcRes:   page,
+		},
+		{
+			desc:     "list things with certs expiring in range",
+			domainID: valid,
+			token:    valid,
+			query:    fmt.Sprintf("?expires_after=%s&expires_before=%s", after.Format(time.RFC3339), before.Format(time.RFC3339)),
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 10, ExpiresBefore: before, ExpiresAfter: after},
+			status:   http.StatusOK,
+			svcRes:   page,
+		},
+		{
+			desc:     "list things by issuer and serial",
+			domainID: valid,
+			token:    valid,
+			query:    fmt.Sprintf("?issuer=Magistrala&serial=%s", serial),
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 10, Issuer: "Magistrala", SerialNumber: serial},
+			status:   http.StatusOK,
+			svcRes:   page,
+		},
+		{
+			desc:     "list things with invalid expiry time",
+			domainID: valid,
+			token:    valid,
+			query:    "?expires_before=invalid",
+			status:   http.StatusBadRequest,
+			err:      apiutil.ErrValidation,
+		},
+		{
+			desc:     "list things with empty expiry range",
+			domainID: valid,
+			token:    valid,
+			query:    fmt.Sprintf("?expires_after=%s&expires_before=%s", before.Format(time.RFC3339), after.Format(time.RFC3339)),
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 10, ExpiresBefore: after, ExpiresAfter: before},
+			status:   http.StatusBadRequest,
+			err:      apiutil.ErrInvalidQueryParams,
+		},
+		{
+			desc:     "list things with limit exceeding max limit",
+			domainID: valid,
+			token:    valid,
+			query:    "?limit=1000",
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 1000},
+			status:   http.StatusBadRequest,
+			err:      apiutil.ErrLimitSize,
+		},
+		{
+			desc:            "list things with invalid token",
+			domainID:        valid,
+			token:           invalid,
+			pm:              certs.PageMetadata{Revoked: revoked, Limit: 10},
+			status:          http.StatusUnauthorized,
+			authenticateErr: svcerr.ErrAuthentication,
+			err:             svcerr.ErrAuthentication,
+		},
+		{
+			desc:     "list things with failed service",
+			domainID: valid,
+			token:    valid,
+			pm:       certs.PageMetadata{Revoked: revoked, Limit: 10},
+			status:   http.StatusBadRequest,
+			svcErr:   svcerr.ErrViewEntity,
+			err:      svcerr.ErrViewEntity,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: cs.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/%s/things", cs.URL, tc.domainID) + tc.query,
+				token:  tc.token,
+			}
+			if tc.token == valid {
+				tc.session = mgauthn.Session{DomainUserID: validID, UserID: validID, DomainID: validID}
+			}
+			authCall := auth.On("Authenticate", mock.Anything, tc.token).Return(tc.session, tc.authenticateErr)
+			svcCall := svc.On("ListThings", mock.Anything, tc.domainID, tc.token, tc.pm).Return(tc.svcRes, tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var errRes respBody
+			err = json.NewDecoder(res.Body).Decode(&errRes)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if errRes.Err != "" || errRes.Message != "" {
+				err = errors.Wrap(errors.New(errRes.Err), errors.New(errRes.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n ", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
 type respBody struct {
 	Err     string `json:"error"`
 	Message string `json:"message"`
@@ -93,6 +93,42 @@ func (lm *loggingMiddleware) ListSerials(ctx context.Context, thingID string, pm
 	return lm.svc.ListSerials(ctx, thingID, pm)
 }
 
+// ListThings logs the list_things request. It logs the filters and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ListThings(ctx context.Context, domainID, token string, pm certs.PageMetadata) (tp certs.ThingsPage, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("domain_id", domainID),
+			slog.Group("page",
+				slog.Uint64("offset", tp.Offset),
+				slog.Uint64("limit", tp.Limit),
+				slog.Uint64("total", tp.Total),
+			),
+		}
+		if !pm.ExpiresBefore.IsZero() {
+			args = append(args, slog.Time("expires_before", pm.ExpiresBefore))
+		}
+		if !pm.ExpiresAfter.IsZero() {
+			args = append(args, slog.Time("expires_after", pm.ExpiresAfter))
+		}
+		if pm.Issuer != "" {
+			args = append(args, slog.String("issuer", pm.Issuer))
+		}
+		if pm.SerialNumber != "" {
+			args = append(args, slog.String("serial_number", pm.SerialNumber))
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("List things by certificates failed", args...)
+			return
+		}
+		lm.logger.Info("List things by certificates completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.ListThings(ctx, domainID, token, pm)
+}
+
 // ViewCert logs the view_cert request. It logs the serial ID and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) ViewCert(ctx context.Context, serialID string) (c certs.Cert, err error) {
@@ -60,6 +60,16 @@ func (ms *metricsMiddleware) ListSerials(ctx context.Context, thingID string, pm
 	return ms.svc.ListSerials(ctx, thingID, pm)
 }
 
+// ListThings instruments ListThings method with metrics.
+func (ms *metricsMiddleware) ListThings(ctx context.Context, domainID, token string, pm certs.PageMetadata) (certs.ThingsPage, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_things").Add(1)
+		ms.latency.With("method", "list_things").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListThings(ctx, domainID, token, pm)
+}
+
 // ViewCert instruments ViewCert method with metrics.
 func (ms *metricsMiddleware) ViewCert(ctx context.Context, serialID string) (certs.Cert, error) {
 	defer func(begin time.Time) {
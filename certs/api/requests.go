@@ -56,6 +56,32 @@ func (req *listReq) validate() error {
 	return nil
 }
 
+type listThingsReq struct {
+	token    string
+	domainID string
+	pm       certs.PageMetadata
+}
+
+func (req *listThingsReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.domainID == "" {
+		return apiutil.ErrMissingDomainID
+	}
+
+	if req.pm.Limit > maxLimitSize {
+		return apiutil.ErrLimitSize
+	}
+
+	if !req.pm.ExpiresBefore.IsZero() && !req.pm.ExpiresAfter.IsZero() && !req.pm.ExpiresAfter.Before(req.pm.ExpiresBefore) {
+		return apiutil.ErrInvalidQueryParams
+	}
+
+	return nil
+}
+
 type viewReq struct {
 	serialID string
 }
@@ -19,6 +19,17 @@ type certsPageRes struct {
 	Certs []certsRes `json:"certs"`
 }
 
+type thingsPageRes struct {
+	pageRes
+	Things []thingCertsRes `json:"things"`
+}
+
+type thingCertsRes struct {
+	ThingID   string     `json:"thing_id"`
+	ThingName string     `json:"thing_name,omitempty"`
+	Certs     []certsRes `json:"certs"`
+}
+
 type certsRes struct {
 	ThingID      string    `json:"thing_id"`
 	Certificate  string    `json:"certificate,omitempty"`
@@ -26,6 +37,7 @@ type certsRes struct {
 	SerialNumber string    `json:"serial_number"`
 	ExpiryTime   time.Time `json:"expiry_time"`
 	Revoked      bool      `json:"revoked"`
+	Issuer       string    `json:"issuer,omitempty"`
 	issued       bool
 }
 
@@ -45,6 +57,18 @@ func (res certsPageRes) Empty() bool {
 	return false
 }
 
+func (res thingsPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res thingsPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res thingsPageRes) Empty() bool {
+	return false
+}
+
 func (res certsRes) Code() int {
 	if res.issued {
 		return http.StatusCreated
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/certs"
@@ -26,6 +27,10 @@ const (
 	offsetKey   = "offset"
 	limitKey    = "limit"
 	revokeKey   = "revoked"
+	beforeKey   = "expires_before"
+	afterKey    = "expires_after"
+	issuerKey   = "issuer"
+	serialKey   = "serial"
 	defRevoke   = "false"
 	defOffset   = 0
 	defLimit    = 10
@@ -68,6 +73,12 @@ func MakeHandler(svc certs.Service, authn mgauthn.Authentication, logger *slog.L
 				api.EncodeResponse,
 				opts...,
 			), "list_serials").ServeHTTP)
+			r.Get("/things", otelhttp.NewHandler(kithttp.NewServer(
+				listThings(svc),
+				decodeListThings,
+				api.EncodeResponse,
+				opts...,
+			), "list_things").ServeHTTP)
 		})
 	})
 	r.Handle("/metrics", promhttp.Handler())
@@ -101,6 +112,66 @@ func decodeListCerts(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
+func decodeListThings(_ context.Context, r *http.Request) (interface{}, error) {
+	l, err := apiutil.ReadNumQuery[uint64](r, limitKey, defLimit)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	o, err := apiutil.ReadNumQuery[uint64](r, offsetKey, defOffset)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	rv, err := apiutil.ReadStringQuery(r, revokeKey, defRevoke)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	before, err := readTimeQuery(r, beforeKey)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	after, err := readTimeQuery(r, afterKey)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	issuer, err := apiutil.ReadStringQuery(r, issuerKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	serial, err := apiutil.ReadStringQuery(r, serialKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	req := listThingsReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+		pm: certs.PageMetadata{
+			Offset:        o,
+			Limit:         l,
+			Revoked:       rv,
+			ExpiresBefore: before,
+			ExpiresAfter:  after,
+			Issuer:        issuer,
+			SerialNumber:  serial,
+		},
+	}
+	return req, nil
+}
+
+// readTimeQuery reads the RFC 3339 time of the query parameter.
+func readTimeQuery(r *http.Request, key string) (time.Time, error) {
+	s, err := apiutil.ReadStringQuery(r, key, "")
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.Wrap(apiutil.ErrInvalidQueryParams, err)
+	}
+
+	return t, nil
+}
+
 func decodeViewCert(_ context.Context, r *http.Request) (interface{}, error) {
 	req := viewReq{
 		serialID: chi.URLParam(r, "certID"),
@@ -20,6 +20,7 @@ type Cert struct {
 	Revoked      bool      `json:"revoked"`
 	ExpiryTime   time.Time `json:"expiry_time"`
 	ThingID      string    `json:"entity_id"`
+	Issuer       string    `json:"issuer,omitempty"`
 }
 
 type CertPage struct {
@@ -37,6 +38,28 @@ type PageMetadata struct {
 	Token      string `json:"token,omitempty"`
 	CommonName string `json:"common_name,omitempty"`
 	Revoked    string `json:"revoked,omitempty"`
+	// ExpiresBefore and ExpiresAfter limit the certificates to the ones
+	// expiring in the range. The zero values leave the range open.
+	ExpiresBefore time.Time `json:"expires_before,omitempty"`
+	ExpiresAfter  time.Time `json:"expires_after,omitempty"`
+	// Issuer matches the common name or the distinguished name of the
+	// certificate issuer.
+	Issuer       string `json:"issuer,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+}
+
+// ThingCerts is a thing with its certificates matching the filters.
+type ThingCerts struct {
+	ThingID      string `json:"thing_id"`
+	ThingName    string `json:"thing_name,omitempty"`
+	Certificates []Cert `json:"certificates"`
+}
+
+type ThingsPage struct {
+	Total  uint64       `json:"total"`
+	Offset uint64       `json:"offset"`
+	Limit  uint64       `json:"limit"`
+	Things []ThingCerts `json:"things"`
 }
 
 var ErrMissingCerts = errors.New("CA path or CA key path not set")
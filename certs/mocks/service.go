@@ -101,6 +101,34 @@ func (_m *Service) ListSerials(ctx context.Context, thingID string, pm certs.Pag
 	return r0, r1
 }
 
+// ListThings provides a mock function with given fields: ctx, domainID, token, pm
+func (_m *Service) ListThings(ctx context.Context, domainID string, token string, pm certs.PageMetadata) (certs.ThingsPage, error) {
+	ret := _m.Called(ctx, domainID, token, pm)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListThings")
+	}
+
+	var r0 certs.ThingsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, certs.PageMetadata) (certs.ThingsPage, error)); ok {
+		return rf(ctx, domainID, token, pm)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, certs.PageMetadata) certs.ThingsPage); ok {
+		r0 = rf(ctx, domainID, token, pm)
+	} else {
+		r0 = ret.Get(0).(certs.ThingsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, certs.PageMetadata) error); ok {
+		r1 = rf(ctx, domainID, token, pm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RevokeCert provides a mock function with given fields: ctx, domainID, token, thingID
 func (_m *Service) RevokeCert(ctx context.Context, domainID string, token string, thingID string) (certs.Revoke, error) {
 	ret := _m.Called(ctx, domainID, token, thingID)
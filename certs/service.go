@@ -42,6 +42,11 @@ type Service interface {
 	// ListSerials lists certificate serial IDs issued for a given thing ID
 	ListSerials(ctx context.Context, thingID string, pm PageMetadata) (CertPage, error)
 
+	// ListThings lists the things of the domain whose certificates match the
+	// expiry range, issuer and serial filters, together with the matching
+	// certificates.
+	ListThings(ctx context.Context, domainID, token string, pm PageMetadata) (ThingsPage, error)
+
 	// ViewCert retrieves the certificate issued for a given serial ID
 	ViewCert(ctx context.Context, serialID string) (Cert, error)
 
@@ -5,10 +5,19 @@ package certs_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"net/http"
 	"testing"
 	"time"
 
+	certsdk "github.com/absmach/certs/sdk"
 	"github.com/absmach/magistrala/certs"
 	"github.com/absmach/magistrala/certs/mocks"
 	mgcrt "github.com/absmach/magistrala/certs/pki/amcerts"
@@ -343,3 +352,145 @@ func TestViewCert(t *testing.T) {
 		})
 	}
 }
+
+func TestListThings(t *testing.T) {
+	svc, agent, sdk := newService(t)
+
+	now := time.Now()
+	rotated := newPEM(t, "Old CA")
+	current := newPEM(t, "Magistrala")
+	thing1 := mgcrt.Cert{ThingID: "thing1", SerialNumber: "1", ExpiryTime: now.Add(time.Hour), Certificate: rotated}
+	thing2 := mgcrt.Cert{ThingID: "thing2", SerialNumber: "2", ExpiryTime: now.Add(48 * time.Hour), Certificate: current}
+	thing3 := mgcrt.Cert{ThingID: "thing3", SerialNumber: "3", ExpiryTime: now.Add(2 * time.Hour), Certificate: current}
+	revoked := mgcrt.Cert{ThingID: "thing2", SerialNumber: "4", ExpiryTime: now.Add(time.Hour), Revoked: true, Certificate: rotated}
+	other := mgcrt.Cert{ThingID: "other", SerialNumber: "5", ExpiryTime: now.Add(time.Hour), Certificate: rotated}
+	page := mgcrt.CertPage{Certificates: []mgcrt.Cert{thing1, thing2, thing3, revoked, other}}
+	// The other thing is not visible to the user.
+	visibleThings := mgsdk.ThingsPage{Things: []mgsdk.Thing{{ID: "thing1"}, {ID: "thing2"}, {ID: "thing3"}}}
+
+	cases := []struct {
+		desc    string
+		pm      certs.PageMetadata
+		listErr error
+		things  []string
+		serials []string
+		err     error
+	}{
+		{
+			desc:    "list things with certs expiring before date",
+			pm:      certs.PageMetadata{Revoked: "false", Limit: 10, ExpiresBefore: now.Add(3 * time.Hour)},
+			things:  []string{"thing1", "thing3"},
+			serials: []string{"1", "3"},
+		},
+		{
+			desc:    "list things with certs expiring in range",
+			pm:      certs.PageMetadata{Revoked: "false", Limit: 10, ExpiresAfter: now.Add(90 * time.Minute), ExpiresBefore: now.Add(3 * time.Hour)},
+			things:  []string{"thing3"},
+			serials: []string{"3"},
+		},
+		{
+			desc:    "list things by issuer common name",
+			pm:      certs.PageMetadata{Revoked: "false", Limit: 10, Issuer: "Old CA"},
+			things:  []string{"thing1"},
+			serials: []string{"1"},
+		},
+		{
+			desc:    "list things by issuer including revoked certs",
+			pm:      certs.PageMetadata{Revoked: "all", Limit: 10, Issuer: "CN=Old CA"},
+			things:  []string{"thing1", "thing2"},
+			serials: []string{"1", "4"},
+		},
+		{
+			desc:    "list things by serial",
+			pm:      certs.PageMetadata{Revoked: "false", Limit: 10, SerialNumber: "2"},
+			things:  []string{"thing2"},
+			serials: []string{"2"},
+		},
+		{
+			desc:    "list things with offset",
+			pm:      certs.PageMetadata{Revoked: "false", Offset: 1, Limit: 1},
+			things:  []string{"thing2"},
+			serials: []string{"2"},
+		},
+		{
+			desc:    "list things with failed pki",
+			pm:      certs.PageMetadata{Revoked: "false", Limit: 10},
+			listErr: svcerr.ErrViewEntity,
+			err:     svcerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			agentCall := agent.On("ListCerts", mock.Anything).Return(page, tc.listErr)
+			viewCall := agent.On("View", tc.pm.SerialNumber).Return(func(serial string) (mgcrt.Cert, error) {
+				for _, c := range page.Certificates {
+					if c.SerialNumber == serial {
+						return c, nil
+					}
+				}
+				return mgcrt.Cert{}, errors.NewSDKErrorWithStatus(svcerr.ErrNotFound, http.StatusNotFound)
+			})
+			sdkCall := sdk.On("Things", mock.Anything, domain, token).Return(visibleThings, nil)
+			tp, err := svc.ListThings(context.Background(), domain, token, tc.pm)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			var things, serials []string
+			for _, th := range tp.Things {
+				things = append(things, th.ThingID)
+				for _, c := range th.Certificates {
+					serials = append(serials, c.SerialNumber)
+				}
+			}
+			assert.Equal(t, tc.things, things, fmt.Sprintf("%s: expected things %v got %v\n", tc.desc, tc.things, things))
+			assert.Equal(t, tc.serials, serials, fmt.Sprintf("%s: expected certs %v got %v\n", tc.desc, tc.serials, serials))
+			agentCall.Unset()
+			viewCall.Unset()
+			sdkCall.Unset()
+		})
+	}
+}
+
+func TestListThingsPaging(t *testing.T) {
+	svc, agent, sdk := newService(t)
+
+	// The certificates span several PKI pages and thing batches.
+	const total = 250
+	var all []mgcrt.Cert
+	for i := 0; i < total; i++ {
+		all = append(all, mgcrt.Cert{ThingID: fmt.Sprintf("thing%d", i), SerialNumber: fmt.Sprint(i), ExpiryTime: time.Now().Add(time.Hour)})
+	}
+	agent.On("ListCerts", mock.Anything).Return(func(pm certsdk.PageMetadata) (mgcrt.CertPage, error) {
+		end := min(pm.Offset+pm.Limit, total)
+		return mgcrt.CertPage{Total: total, Offset: pm.Offset, Limit: pm.Limit, Certificates: all[pm.Offset:end]}, nil
+	})
+	sdk.On("Things", mock.Anything, domain, token).Return(func(pm mgsdk.PageMetadata, _, _ string) (mgsdk.ThingsPage, errors.SDKError) {
+		tp := mgsdk.ThingsPage{}
+		for _, id := range pm.IDs {
+			tp.Things = append(tp.Things, mgsdk.Thing{ID: id, Name: id})
+		}
+		return tp, nil
+	})
+
+	tp, err := svc.ListThings(context.Background(), domain, token, certs.PageMetadata{Revoked: "false", Offset: 240, Limit: 20})
+	assert.Nil(t, err, fmt.Sprintf("list things: unexpected error %s", err))
+	assert.Equal(t, uint64(total), tp.Total, fmt.Sprintf("list things: expected total %d got %d", total, tp.Total))
+	assert.Len(t, tp.Things, 10, fmt.Sprintf("list things: expected 10 things got %d", len(tp.Things)))
+	agent.AssertNumberOfCalls(t, "ListCerts", 3)
+	sdk.AssertNumberOfCalls(t, "Things", 3)
+}
+
+// newPEM returns the PEM of a self-signed certificate issued by the issuer.
+func newPEM(t *testing.T, issuer string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err, fmt.Sprintf("generating key: unexpected error %s", err))
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: issuer},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err, fmt.Sprintf("creating certificate: unexpected error %s", err))
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -0,0 +1,168 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package certs
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/absmach/certs/sdk"
+	pki "github.com/absmach/magistrala/certs/pki/amcerts"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	mgsdk "github.com/absmach/magistrala/pkg/sdk/go"
+)
+
+const (
+	// pkiPageSize is the number of certificates read from the PKI per
+	// request.
+	pkiPageSize = 100
+	// thingsBatchSize is the number of things looked up per request.
+	thingsBatchSize = 100
+	allStatus       = "all"
+)
+
+func (cs *certsService) ListThings(ctx context.Context, domainID, token string, pm PageMetadata) (ThingsPage, error) {
+	var ids []string
+	matched := map[string][]Cert{}
+	err := cs.listCerts(pm, func(c pki.Cert) error {
+		crt, ok, err := cs.match(c, pm)
+		if err != nil {
+			return errors.Wrap(ErrFailedReadFromPKI, err)
+		}
+		if !ok {
+			return nil
+		}
+		if _, ok := matched[c.ThingID]; !ok {
+			ids = append(ids, c.ThingID)
+		}
+		matched[c.ThingID] = append(matched[c.ThingID], crt)
+		return nil
+	})
+	if err != nil {
+		return ThingsPage{}, err
+	}
+
+	// The certificates are joined with the things of the domain visible to
+	// the user, so the certificates of the other things are left out. The
+	// things are looked up in batches.
+	things := []ThingCerts{}
+	for start := 0; start < len(ids); start += thingsBatchSize {
+		batch := ids[start:min(start+thingsBatchSize, len(ids))]
+		tp, sdkErr := cs.sdk.Things(mgsdk.PageMetadata{Limit: uint64(len(batch)), IDs: batch, Status: allStatus}, domainID, token)
+		if sdkErr != nil {
+			return ThingsPage{}, errors.Wrap(svcerr.ErrViewEntity, sdkErr)
+		}
+		names := make(map[string]string, len(tp.Things))
+		for _, th := range tp.Things {
+			names[th.ID] = th.Name
+		}
+		for _, id := range batch {
+			name, ok := names[id]
+			if !ok {
+				continue
+			}
+			things = append(things, ThingCerts{
+				ThingID:      id,
+				ThingName:    name,
+				Certificates: matched[id],
+			})
+		}
+	}
+
+	page := ThingsPage{
+		Total:  uint64(len(things)),
+		Offset: pm.Offset,
+		Limit:  pm.Limit,
+		Things: []ThingCerts{},
+	}
+	if pm.Offset < page.Total {
+		end := page.Total
+		if pm.Limit > 0 && pm.Offset+pm.Limit < end {
+			end = pm.Offset + pm.Limit
+		}
+		page.Things = things[pm.Offset:end]
+	}
+
+	return page, nil
+}
+
+// listCerts calls f with the certificates of the PKI. The filters supported
+// by the PKI are applied by its query: the certificate is read directly if
+// the serial number is filtered, and the certificates of the thing are
+// listed if the thing is filtered. The certificates are read page by page.
+func (cs *certsService) listCerts(pm PageMetadata, f func(pki.Cert) error) error {
+	if pm.SerialNumber != "" {
+		c, err := cs.pki.View(pm.SerialNumber)
+		if err != nil {
+			if se, ok := err.(interface{ StatusCode() int }); ok && se.StatusCode() == http.StatusNotFound {
+				return nil
+			}
+			return errors.Wrap(svcerr.ErrViewEntity, err)
+		}
+		if pm.ThingID != "" && c.ThingID != pm.ThingID {
+			return nil
+		}
+		return f(c)
+	}
+
+	spm := sdk.PageMetadata{Offset: 0, Limit: pkiPageSize, EntityID: pm.ThingID}
+	for {
+		cp, err := cs.pki.ListCerts(spm)
+		if err != nil {
+			return errors.Wrap(svcerr.ErrViewEntity, err)
+		}
+		for _, c := range cp.Certificates {
+			if err := f(c); err != nil {
+				return err
+			}
+		}
+		spm.Offset += uint64(len(cp.Certificates))
+		if len(cp.Certificates) < pkiPageSize || spm.Offset >= cp.Total {
+			return nil
+		}
+	}
+}
+
+// match returns the certificate if it matches the filters. The certificate
+// is read from the PKI if the issuer is filtered and the listed certificate
+// is missing.
+func (cs *certsService) match(c pki.Cert, pm PageMetadata) (Cert, bool, error) {
+	switch {
+	case pm.SerialNumber != "" && c.SerialNumber != pm.SerialNumber,
+		!pm.ExpiresBefore.IsZero() && !c.ExpiryTime.Before(pm.ExpiresBefore),
+		!pm.ExpiresAfter.IsZero() && !c.ExpiryTime.After(pm.ExpiresAfter),
+		(pm.Revoked == "true" && !c.Revoked) || (pm.Revoked == "false" && c.Revoked):
+		return Cert{}, false, nil
+	}
+
+	crt := Cert{
+		SerialNumber: c.SerialNumber,
+		Revoked:      c.Revoked,
+		ExpiryTime:   c.ExpiryTime,
+		ThingID:      c.ThingID,
+	}
+	if pm.Issuer == "" {
+		return crt, true, nil
+	}
+
+	pem := c.Certificate
+	if pem == "" {
+		vc, err := cs.pki.View(c.SerialNumber)
+		if err != nil {
+			return Cert{}, false, err
+		}
+		pem = vc.Certificate
+	}
+	x509Cert, err := ReadCert([]byte(pem))
+	if err != nil {
+		return Cert{}, false, err
+	}
+	if x509Cert.Issuer.CommonName != pm.Issuer && x509Cert.Issuer.String() != pm.Issuer {
+		return Cert{}, false, nil
+	}
+	crt.Issuer = x509Cert.Issuer.String()
+
+	return crt, true, nil
+}
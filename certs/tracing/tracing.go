@@ -58,6 +58,20 @@ func (tm *tracingMiddleware) ListSerials(ctx context.Context, thingID string, pm
 	return tm.svc.ListSerials(ctx, thingID, pm)
 }
 
+// ListThings traces the "ListThings" operation of the wrapped certs.Service.
+func (tm *tracingMiddleware) ListThings(ctx context.Context, domainID, token string, pm certs.PageMetadata) (certs.ThingsPage, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_list_things", trace.WithAttributes(
+		attribute.String("domain_id", domainID),
+		attribute.String("issuer", pm.Issuer),
+		attribute.String("serial_number", pm.SerialNumber),
+		attribute.Int64("offset", int64(pm.Offset)),
+		attribute.Int64("limit", int64(pm.Limit)),
+	))
+	defer span.End()
+
+	return tm.svc.ListThings(ctx, domainID, token, pm)
+}
+
 // ViewCert traces the "ViewCert" operation of the wrapped certs.Service.
 func (tm *tracingMiddleware) ViewCert(ctx context.Context, serialID string) (certs.Cert, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_view_cert", trace.WithAttributes(
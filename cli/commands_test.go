@@ -70,3 +70,8 @@ const (
 	acceptCmd = "accept"
 	rejectCmd = "reject"
 )
+
+// Provision commands
+const (
+	testCmd = "test"
+)
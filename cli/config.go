@@ -162,6 +162,10 @@ func ParseConfig(sdkConf mgxsdk.Config) (mgxsdk.Config, error) {
 		Topic = config.Filter.Topic
 	}
 
+	if config.UserToken != "" && UserToken == "" {
+		UserToken = config.UserToken
+	}
+
 	if config.RawOutput != "" {
 		rawOutput, err := strconv.ParseBool(config.RawOutput)
 		if err != nil {
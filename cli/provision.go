@@ -24,9 +24,20 @@ const (
 	csvExt  = ".csv"
 )
 
+const (
+	testMsgName      = "provision-test"
+	testReadRetries  = 5
+	testReadInterval = time.Second
+)
+
 var (
-	msgFormat      = `[{"bn":"provision:", "bu":"V", "t": %d, "bver":5, "n":"voltage", "u":"V", "v":%d}]`
+	testMsgFormat  = `[{"bn":"%s:", "bu":"V", "t": %d, "bver":5, "n":"%s", "u":"V", "v":%d}]`
 	namesgenerator = namegenerator.NewGenerator()
+
+	errMissingToken     = errors.New("missing user token, provide it as argument or set user_token in config")
+	errMessageNotFound  = errors.New("published message not found")
+	errProvisionTest    = errors.New("provision test failed")
+	errProvisionCleanup = errors.New("provision test cleanup failed")
 )
 
 var cmdProvision = []cobra.Command{
@@ -116,135 +127,33 @@ var cmdProvision = []cobra.Command{
 		},
 	},
 	{
-		Use:   "test",
-		Short: "test",
-		Long: `Provisions test setup: one test user, two things and two channels. \
-						Connect both things to one of the channels, \
-						and only on thing to other channel.`,
+		Use:   "test <domain_id> [user_token]",
+		Short: "Test device provisioning end to end",
+		Long: "Creates a throwaway thing and channel, connects them, publishes a sample message,\n" +
+			"reads it back through the configured reader and removes the created entities.\n" +
+			"The user token from the config file is used if it's not provided.\n" +
+			"Usage:\n" +
+			"\tmagistrala-cli provision test <domain_id> <user_token>\n",
 		Run: func(cmd *cobra.Command, args []string) {
-			numThings := 2
-			numChan := 2
-			things := []mgxsdk.Thing{}
-			channels := []mgxsdk.Channel{}
-
-			if len(args) != 0 {
+			if len(args) < 1 || len(args) > 2 {
 				logUsageCmd(*cmd, cmd.Use)
 				return
 			}
-
-			// Create test user
-			name := namesgenerator.Generate()
-			user := mgxsdk.User{
-				Name: name,
-				Credentials: mgxsdk.Credentials{
-					Identity: fmt.Sprintf("%s@email.com", name),
-					Secret:   "12345678",
-				},
-				Status: mgxsdk.EnabledStatus,
-			}
-			user, err := sdk.CreateUser(user, "")
-			if err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			user.Credentials.Secret = "12345678"
-			ut, err := sdk.CreateToken(mgxsdk.Login{Identity: user.Credentials.Identity, Secret: user.Credentials.Secret})
-			if err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			// create domain
-			domain := mgxsdk.Domain{
-				Name:   fmt.Sprintf("%s-domain", name),
-				Status: mgxsdk.EnabledStatus,
+			token := UserToken
+			if len(args) == 2 {
+				token = args[1]
 			}
-			domain, err = sdk.CreateDomain(domain, ut.AccessToken)
-			if err != nil {
-				logErrorCmd(*cmd, err)
+			if token == "" {
+				logErrorCmd(*cmd, errMissingToken)
 				return
 			}
 
-			ut, err = sdk.CreateToken(mgxsdk.Login{Identity: user.Credentials.Identity, Secret: user.Credentials.Secret})
-			if err != nil {
+			if err := provisionTest(*cmd, args[0], token); err != nil {
 				logErrorCmd(*cmd, err)
 				return
 			}
 
-			// Create things
-			for i := 0; i < numThings; i++ {
-				t := mgxsdk.Thing{
-					Name:   fmt.Sprintf("%s-thing-%d", name, i),
-					Status: mgxsdk.EnabledStatus,
-				}
-
-				things = append(things, t)
-			}
-			things, err = sdk.CreateThings(things, domain.ID, ut.AccessToken)
-			if err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			// Create channels
-			for i := 0; i < numChan; i++ {
-				c := mgxsdk.Channel{
-					Name:   fmt.Sprintf("%s-channel-%d", name, i),
-					Status: mgxsdk.EnabledStatus,
-				}
-				c, err = sdk.CreateChannel(c, domain.ID, ut.AccessToken)
-				if err != nil {
-					logErrorCmd(*cmd, err)
-					return
-				}
-
-				channels = append(channels, c)
-			}
-
-			// Connect things to channels - first thing to both channels, second only to first
-			conIDs := mgxsdk.Connection{
-				ChannelID: channels[0].ID,
-				ThingID:   things[0].ID,
-			}
-			if err := sdk.Connect(conIDs, domain.ID, ut.AccessToken); err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			conIDs = mgxsdk.Connection{
-				ChannelID: channels[1].ID,
-				ThingID:   things[0].ID,
-			}
-			if err := sdk.Connect(conIDs, domain.ID, ut.AccessToken); err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			conIDs = mgxsdk.Connection{
-				ChannelID: channels[0].ID,
-				ThingID:   things[1].ID,
-			}
-			if err := sdk.Connect(conIDs, domain.ID, ut.AccessToken); err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			// send message to test connectivity
-			if err := sdk.SendMessage(channels[0].ID, fmt.Sprintf(msgFormat, time.Now().Unix(), rand.Int()), things[0].Credentials.Secret); err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-			if err := sdk.SendMessage(channels[0].ID, fmt.Sprintf(msgFormat, time.Now().Unix(), rand.Int()), things[1].Credentials.Secret); err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-			if err := sdk.SendMessage(channels[1].ID, fmt.Sprintf(msgFormat, time.Now().Unix(), rand.Int()), things[0].Credentials.Secret); err != nil {
-				logErrorCmd(*cmd, err)
-				return
-			}
-
-			logJSONCmd(*cmd, user, ut, things, channels)
+			logOKCmd(*cmd)
 		},
 	},
 }
@@ -264,6 +173,95 @@ func NewProvisionCmd() *cobra.Command {
 	return &cmd
 }
 
+// provisionTest runs the provisioning stages one by one, stopping at the first
+// failed stage. Created entities are always removed, even if a stage fails.
+func provisionTest(cmd cobra.Command, domainID, token string) (err error) {
+	name := namesgenerator.Generate()
+	var thing mgxsdk.Thing
+	var channel mgxsdk.Channel
+	var connected bool
+
+	defer func() {
+		cleanupErr := provisionTestCleanup(cmd, thing, channel, connected, domainID, token)
+		if err == nil && cleanupErr != nil {
+			err = cleanupErr
+		}
+	}()
+
+	th, err := sdk.CreateThing(mgxsdk.Thing{Name: fmt.Sprintf("%s-thing", name), Status: mgxsdk.EnabledStatus}, domainID, token)
+	if !logStageCmd(cmd, "create thing", err) {
+		return errProvisionTest
+	}
+	thing = th
+
+	ch, err := sdk.CreateChannel(mgxsdk.Channel{Name: fmt.Sprintf("%s-channel", name), Status: mgxsdk.EnabledStatus}, domainID, token)
+	if !logStageCmd(cmd, "create channel", err) {
+		return errProvisionTest
+	}
+	channel = ch
+
+	err = sdk.Connect(mgxsdk.Connection{ThingID: thing.ID, ChannelID: channel.ID}, domainID, token)
+	if !logStageCmd(cmd, "connect thing to channel", err) {
+		return errProvisionTest
+	}
+	connected = true
+
+	msg := fmt.Sprintf(testMsgFormat, name, time.Now().Unix(), testMsgName, rand.Intn(100))
+	err = sdk.SendMessage(channel.ID, msg, thing.Credentials.Secret)
+	if !logStageCmd(cmd, "publish message", err) {
+		return errProvisionTest
+	}
+
+	err = readTestMessage(channel.ID, thing.ID, fmt.Sprintf("%s:%s", name, testMsgName), token)
+	if !logStageCmd(cmd, "read message", err) {
+		return errProvisionTest
+	}
+
+	return nil
+}
+
+// readTestMessage polls the reader until the published message is stored.
+func readTestMessage(channelID, thingID, msgName, token string) error {
+	pm := mgxsdk.MessagePageMetadata{
+		PageMetadata: mgxsdk.PageMetadata{Offset: 0, Limit: Limit},
+		Publisher:    thingID,
+	}
+	for i := 0; i < testReadRetries; i++ {
+		if i > 0 {
+			time.Sleep(testReadInterval)
+		}
+		page, err := sdk.ReadMessages(pm, channelID, token)
+		if err != nil {
+			return err
+		}
+		for _, m := range page.Messages {
+			if m.Name == msgName {
+				return nil
+			}
+		}
+	}
+
+	return errMessageNotFound
+}
+
+func provisionTestCleanup(cmd cobra.Command, thing mgxsdk.Thing, channel mgxsdk.Channel, connected bool, domainID, token string) error {
+	ok := true
+	if connected {
+		ok = logStageCmd(cmd, "disconnect thing from channel", sdk.Disconnect(mgxsdk.Connection{ThingID: thing.ID, ChannelID: channel.ID}, domainID, token)) && ok
+	}
+	if channel.ID != "" {
+		ok = logStageCmd(cmd, "delete channel", sdk.DeleteChannel(channel.ID, domainID, token)) && ok
+	}
+	if thing.ID != "" {
+		ok = logStageCmd(cmd, "delete thing", sdk.DeleteThing(thing.ID, domainID, token)) && ok
+	}
+	if !ok {
+		return errProvisionCleanup
+	}
+
+	return nil
+}
+
 func thingsFromFile(path string) ([]mgxsdk.Thing, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return []mgxsdk.Thing{}, err
@@ -0,0 +1,174 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cli_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/cli"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	mgsdk "github.com/absmach/magistrala/pkg/sdk/go"
+	sdkmocks "github.com/absmach/magistrala/pkg/sdk/mocks"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProvisionTestCmd(t *testing.T) {
+	sdkErr := errors.NewSDKErrorWithStatus(svcerr.ErrAuthorization, http.StatusForbidden)
+
+	cases := []struct {
+		desc          string
+		args          []string
+		logType       outputLog
+		createThing   errors.SDKError
+		createChannel errors.SDKError
+		connect       errors.SDKError
+		sendMessage   errors.SDKError
+		readMessage   errors.SDKError
+		deleteThing   errors.SDKError
+		passed        []string
+		failed        []string
+		cleanup       []string
+	}{
+		{
+			desc:    "provision test successfully",
+			args:    []string{domainID, token},
+			logType: okLog,
+			passed:  []string{"create thing", "create channel", "connect thing to channel", "publish message", "read message", "disconnect thing from channel", "delete channel", "delete thing"},
+			cleanup: []string{"Disconnect", "DeleteChannel", "DeleteThing"},
+		},
+		{
+			desc:        "provision test with failed thing creation",
+			args:        []string{domainID, token},
+			logType:     errLog,
+			createThing: sdkErr,
+			failed:      []string{"create thing"},
+		},
+		{
+			desc:          "provision test with failed channel creation",
+			args:          []string{domainID, token},
+			logType:       errLog,
+			createChannel: sdkErr,
+			passed:        []string{"create thing", "delete thing"},
+			failed:        []string{"create channel"},
+			cleanup:       []string{"DeleteThing"},
+		},
+		{
+			desc:        "provision test with failed publish",
+			args:        []string{domainID, token},
+			logType:     errLog,
+			sendMessage: sdkErr,
+			passed:      []string{"create thing", "create channel", "connect thing to channel", "disconnect thing from channel", "delete channel", "delete thing"},
+			failed:      []string{"publish message"},
+			cleanup:     []string{"Disconnect", "DeleteChannel", "DeleteThing"},
+		},
+		{
+			desc:        "provision test with failed read",
+			args:        []string{domainID, token},
+			logType:     errLog,
+			readMessage: sdkErr,
+			passed:      []string{"publish message", "disconnect thing from channel", "delete channel", "delete thing"},
+			failed:      []string{"read message"},
+			cleanup:     []string{"Disconnect", "DeleteChannel", "DeleteThing"},
+		},
+		{
+			desc:        "provision test with failed cleanup",
+			args:        []string{domainID, token},
+			logType:     errLog,
+			deleteThing: sdkErr,
+			passed:      []string{"read message", "disconnect thing from channel", "delete channel"},
+			failed:      []string{"delete thing"},
+			cleanup:     []string{"Disconnect", "DeleteChannel", "DeleteThing"},
+		},
+		{
+			desc:    "provision test with invalid args",
+			args:    []string{domainID, token, extraArg},
+			logType: usageLog,
+		},
+		{
+			desc:    "provision test without token",
+			args:    []string{domainID},
+			logType: errLog,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sdkMock := new(sdkmocks.SDK)
+			cli.SetSDK(sdkMock)
+			rootCmd := setFlags(cli.NewProvisionCmd())
+
+			var sent string
+			sdkMock.On("CreateThing", mock.Anything, domainID, token).Return(thing, tc.createThing)
+			sdkMock.On("CreateChannel", mock.Anything, domainID, token).Return(channel, tc.createChannel)
+			sdkMock.On("Connect", mgsdk.Connection{ThingID: thing.ID, ChannelID: channel.ID}, domainID, token).Return(tc.connect)
+			sdkMock.On("SendMessage", channel.ID, mock.Anything, thing.Credentials.Secret).Return(tc.sendMessage).Run(func(args mock.Arguments) {
+				sent = args.String(1)
+			})
+			sdkMock.On("ReadMessages", mock.Anything, channel.ID, token).Return(func(mgsdk.MessagePageMetadata, string, string) (mgsdk.MessagesPage, errors.SDKError) {
+				return sentPage(t, sent), tc.readMessage
+			})
+			sdkMock.On("Disconnect", mgsdk.Connection{ThingID: thing.ID, ChannelID: channel.ID}, domainID, token).Return(nil)
+			sdkMock.On("DeleteChannel", channel.ID, domainID, token).Return(nil)
+			sdkMock.On("DeleteThing", thing.ID, domainID, token).Return(tc.deleteThing)
+
+			out := executeCommand(t, rootCmd, append([]string{testCmd}, tc.args...)...)
+
+			switch tc.logType {
+			case okLog:
+				assert.True(t, strings.Contains(out, "ok"), fmt.Sprintf("%s unexpected response: expected success message, got: %v", tc.desc, out))
+			case errLog:
+				assert.True(t, strings.Contains(out, "error"), fmt.Sprintf("%s unexpected response: expected error message, got: %v", tc.desc, out))
+			case usageLog:
+				assert.False(t, strings.Contains(out, rootCmd.Use), fmt.Sprintf("%s invalid usage: %s", tc.desc, out))
+			}
+			for _, stage := range tc.passed {
+				assert.Contains(t, out, fmt.Sprintf("[PASS] %s\n", stage), fmt.Sprintf("%s: expected stage %s to pass", tc.desc, stage))
+			}
+			for _, stage := range tc.failed {
+				assert.Contains(t, out, fmt.Sprintf("[FAIL] %s: ", stage), fmt.Sprintf("%s: expected stage %s to fail", tc.desc, stage))
+			}
+			for _, method := range []string{"Disconnect", "DeleteChannel", "DeleteThing"} {
+				called := false
+				for _, c := range tc.cleanup {
+					called = called || c == method
+				}
+				if called {
+					sdkMock.AssertCalled(t, method, mock.Anything, mock.Anything, mock.Anything)
+					continue
+				}
+				sdkMock.AssertNotCalled(t, method, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// sentPage returns the messages page the reader would return for the sent SenML message.
+func sentPage(t *testing.T, sent string) mgsdk.MessagesPage {
+	var records []struct {
+		BaseName string  `json:"bn"`
+		Name     string  `json:"n"`
+		Value    float64 `json:"v"`
+	}
+	err := json.Unmarshal([]byte(sent), &records)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error parsing sent message: %s", err))
+
+	page := mgsdk.MessagesPage{}
+	for _, r := range records {
+		page.Messages = append(page.Messages, senml.Message{
+			Channel:   channel.ID,
+			Publisher: thing.ID,
+			Name:      r.BaseName + r.Name,
+			Value:     &r.Value,
+		})
+	}
+
+	return page
+}
@@ -91,13 +91,15 @@ var cmdUsers = []cobra.Command{
 		},
 	},
 	{
-		Use:   "token <username> <password>",
+		Use:   "token <username> <password> [mfa_code]",
 		Short: "Get token",
 		Long: "Generate new token from username and password\n" +
+			"Users with multi-factor authentication enrolled also pass the current code\n" +
 			"For example:\n" +
-			"\tmagistrala-cli users token user@example.com 12345678\n",
+			"\tmagistrala-cli users token user@example.com 12345678\n" +
+			"\tmagistrala-cli users token user@example.com 12345678 123456\n",
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if len(args) < 2 || len(args) > 3 {
 				logUsageCmd(*cmd, cmd.Use)
 				return
 			}
@@ -106,6 +108,9 @@ var cmdUsers = []cobra.Command{
 				Identity: args[0],
 				Secret:   args[1],
 			}
+			if len(args) == 3 {
+				lg.MFACode = args[2]
+			}
 
 			token, err := sdk.CreateToken(lg)
 			if err != nil {
@@ -304,6 +304,17 @@ func TestIssueTokenCmd(t *testing.T) {
 			logType: entityLog,
 			token:   token,
 		},
+		{
+			desc: "issue token with MFA code",
+			args: []string{
+				user.Credentials.Identity,
+				user.Credentials.Secret,
+				"123456",
+			},
+			sdkerr:  nil,
+			logType: entityLog,
+			token:   token,
+		},
 		{
 			desc: "issue token with failed authentication",
 			args: []string{
@@ -320,6 +331,7 @@ func TestIssueTokenCmd(t *testing.T) {
 			args: []string{
 				user.Credentials.Identity,
 				user.Credentials.Secret,
+				"123456",
 				extraArg,
 			},
 			logType: usageLog,
@@ -332,6 +344,9 @@ func TestIssueTokenCmd(t *testing.T) {
 				Identity: tc.args[0],
 				Secret:   tc.args[1],
 			}
+			if len(tc.args) == 3 {
+				lg.MFACode = tc.args[2]
+			}
 			sdkCall := sdkMock.On("CreateToken", lg).Return(tc.token, tc.sdkerr)
 
 			out := executeCommand(t, rootCmd, append([]string{tokCmd}, tc.args...)...)
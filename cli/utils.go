@@ -36,6 +36,8 @@ var (
 	Contact string = ""
 	// RawOutput raw output mode.
 	RawOutput bool = false
+	// UserToken user token from the config file.
+	UserToken string = ""
 )
 
 func logJSONCmd(cmd cobra.Command, iList ...interface{}) {
@@ -71,6 +73,16 @@ func logOKCmd(cmd cobra.Command) {
 	fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n\n", color.BlueString("ok"))
 }
 
+func logStageCmd(cmd cobra.Command, stage string, err error) bool {
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s: %s\n", color.RedString("[FAIL]"), stage, err)
+		return false
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", color.GreenString("[PASS]"), stage)
+
+	return true
+}
+
 func logCreatedCmd(cmd cobra.Command, e string) {
 	if RawOutput {
 		fmt.Fprintln(cmd.OutOrStdout(), e)
@@ -21,11 +21,16 @@ import (
 	tokengrpcapi "github.com/absmach/magistrala/auth/api/grpc/token"
 	httpapi "github.com/absmach/magistrala/auth/api/http"
 	"github.com/absmach/magistrala/auth/events"
+	"github.com/absmach/magistrala/auth/events/consumer"
 	"github.com/absmach/magistrala/auth/jwt"
 	apostgres "github.com/absmach/magistrala/auth/postgres"
 	"github.com/absmach/magistrala/auth/tracing"
 	mglog "github.com/absmach/magistrala/logger"
+	mgevents "github.com/absmach/magistrala/pkg/events"
+	"github.com/absmach/magistrala/pkg/events/store"
 	"github.com/absmach/magistrala/pkg/jaeger"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/policies/opa"
 	"github.com/absmach/magistrala/pkg/policies/spicedb"
 	"github.com/absmach/magistrala/pkg/postgres"
 	pgclient "github.com/absmach/magistrala/pkg/postgres"
@@ -39,6 +44,7 @@ import (
 	"github.com/authzed/grpcutil"
 	"github.com/caarlos0/env/v11"
 	"github.com/jmoiron/sqlx"
+	"github.com/lestrrat-go/jwx/v2/jwk"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -47,17 +53,21 @@ import (
 )
 
 const (
-	svcName        = "auth"
-	envPrefixHTTP  = "MG_AUTH_HTTP_"
-	envPrefixGrpc  = "MG_AUTH_GRPC_"
-	envPrefixDB    = "MG_AUTH_DB_"
-	defDB          = "auth"
-	defSvcHTTPPort = "8189"
-	defSvcGRPCPort = "8181"
+	svcName         = "auth"
+	envPrefixHTTP   = "MG_AUTH_HTTP_"
+	envPrefixGrpc   = "MG_AUTH_GRPC_"
+	envPrefixDB     = "MG_AUTH_DB_"
+	envPrefixOPA    = "MG_AUTH_OPA_"
+	envPrefixPolicy = "MG_AUTH_POLICY_RECONCILER_"
+	defDB           = "auth"
+	defSvcHTTPPort  = "8189"
+	defSvcGRPCPort  = "8181"
+	usersStream     = "events.magistrala.users"
 )
 
 type config struct {
 	LogLevel            string        `env:"MG_AUTH_LOG_LEVEL"               envDefault:"info"`
+	LogFormat           string        `env:"MG_LOG_FORMAT"                   envDefault:"json"`
 	SecretKey           string        `env:"MG_AUTH_SECRET_KEY"              envDefault:"secret"`
 	JaegerURL           url.URL       `env:"MG_JAEGER_URL"                   envDefault:"http://localhost:4318/v1/traces"`
 	SendTelemetry       bool          `env:"MG_SEND_TELEMETRY"               envDefault:"true"`
@@ -65,12 +75,18 @@ type config struct {
 	AccessDuration      time.Duration `env:"MG_AUTH_ACCESS_TOKEN_DURATION"   envDefault:"1h"`
 	RefreshDuration     time.Duration `env:"MG_AUTH_REFRESH_TOKEN_DURATION"  envDefault:"24h"`
 	InvitationDuration  time.Duration `env:"MG_AUTH_INVITATION_DURATION"     envDefault:"168h"`
+	DisabledGrace       time.Duration `env:"MG_AUTH_DISABLED_GRACE"          envDefault:"0s"`
+	Audiences           []string      `env:"MG_AUTH_AUDIENCES"               envDefault:""`
 	SpicedbHost         string        `env:"MG_SPICEDB_HOST"                 envDefault:"localhost"`
 	SpicedbPort         string        `env:"MG_SPICEDB_PORT"                 envDefault:"50051"`
 	SpicedbSchemaFile   string        `env:"MG_SPICEDB_SCHEMA_FILE"          envDefault:"./docker/spicedb/schema.zed"`
 	SpicedbPreSharedKey string        `env:"MG_SPICEDB_PRE_SHARED_KEY"       envDefault:"12345678"`
 	TraceRatio          float64       `env:"MG_JAEGER_TRACE_RATIO"           envDefault:"1.0"`
 	ESURL               string        `env:"MG_ES_URL"                       envDefault:"nats://localhost:4222"`
+	SigningKeyFile      string        `env:"MG_AUTH_SIGNING_KEY_FILE"        envDefault:""`
+	SigningKeyID        string        `env:"MG_AUTH_SIGNING_KEY_ID"          envDefault:""`
+	PeerJWKSURLs        []string      `env:"MG_AUTH_PEER_JWKS_URLS"          envDefault:""`
+	PeerJWKSRefresh     time.Duration `env:"MG_AUTH_PEER_JWKS_REFRESH"       envDefault:"15m"`
 }
 
 func main() {
@@ -82,7 +98,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err.Error())
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -103,6 +119,13 @@ func main() {
 		logger.Error(err.Error())
 	}
 
+	rcConfig := policies.ReconcilerConfig{}
+	if err := env.ParseWithOptions(&rcConfig, env.Options{Prefix: envPrefixPolicy}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s policy reconciler configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	db, err := pgclient.Setup(dbConfig, *apostgres.Migration())
 	if err != nil {
 		logger.Error(err.Error())
@@ -131,7 +154,44 @@ func main() {
 		return
 	}
 
-	svc := newService(ctx, db, tracer, cfg, dbConfig, logger, spicedbclient)
+	opaConfig := opa.Config{}
+	if err := env.ParseWithOptions(&opaConfig, env.Options{Prefix: envPrefixOPA}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s external authorizer configuration : %s", svcName, err.Error()))
+		exitCode = 1
+		return
+	}
+
+	var signer jwk.Key
+	if cfg.SigningKeyFile != "" {
+		if signer, err = jwt.LoadSigningKey(cfg.SigningKeyFile, cfg.SigningKeyID); err != nil {
+			logger.Error(fmt.Sprintf("failed to load %s token signing key : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+	}
+	jwks, err := jwt.PublicKeys(signer)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s JWKS : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	peers := jwt.NewPeerKeys(cfg.PeerJWKSURLs, cfg.PeerJWKSRefresh, logger)
+	go peers.Run(ctx)
+	tokenizer, err := jwt.NewWithKeys([]byte(cfg.SecretKey), signer, peers)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s tokenizer : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	svc := newService(ctx, db, tracer, cfg, dbConfig, opaConfig, rcConfig, logger, spicedbclient, tokenizer)
+
+	keysRepo := apostgres.New(postgres.NewDatabase(db, dbConfig, tracer))
+	if err := subscribeToUsersES(ctx, keysRepo, cfg, logger); err != nil {
+		logger.Error(fmt.Sprintf("failed to subscribe to users event store: %s", err))
+		exitCode = 1
+		return
+	}
 
 	httpServerConfig := server.Config{Port: defSvcHTTPPort}
 	if err := env.ParseWithOptions(&httpServerConfig, env.Options{Prefix: envPrefixHTTP}); err != nil {
@@ -139,7 +199,7 @@ func main() {
 		exitCode = 1
 		return
 	}
-	hs := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, httpapi.MakeHandler(svc, logger, cfg.InstanceID), logger)
+	hs := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, httpapi.MakeHandler(svc, logger, cfg.InstanceID, jwks), logger)
 
 	grpcServerConfig := server.Config{Port: defSvcGRPCPort}
 	if err := env.ParseWithOptions(&grpcServerConfig, env.Options{Prefix: envPrefixGrpc}); err != nil {
@@ -177,6 +237,21 @@ func main() {
 	}
 }
 
+// subscribeToUsersES revokes the keys of the disabled and deleted users.
+func subscribeToUsersES(ctx context.Context, keys auth.KeyRepository, cfg config, logger *slog.Logger) error {
+	subscriber, err := store.NewSubscriber(ctx, cfg.ESURL, logger)
+	if err != nil {
+		return err
+	}
+
+	subConfig := mgevents.SubscriberConfig{
+		Stream:   usersStream,
+		Consumer: svcName,
+		Handler:  consumer.NewEventHandler(keys),
+	}
+	return subscriber.Subscribe(ctx, subConfig)
+}
+
 func initSpiceDB(ctx context.Context, cfg config) (*authzed.ClientWithExperimental, error) {
 	client, err := authzed.NewClientWithExperimentalAPIs(
 		fmt.Sprintf("%s:%s", cfg.SpicedbHost, cfg.SpicedbPort),
@@ -207,18 +282,20 @@ func initSchema(ctx context.Context, client *authzed.ClientWithExperimental, sch
 	return nil
 }
 
-func newService(ctx context.Context, db *sqlx.DB, tracer trace.Tracer, cfg config, dbConfig pgclient.Config, logger *slog.Logger, spicedbClient *authzed.ClientWithExperimental) auth.Service {
+func newService(ctx context.Context, db *sqlx.DB, tracer trace.Tracer, cfg config, dbConfig pgclient.Config, opaConfig opa.Config, rc policies.ReconcilerConfig, logger *slog.Logger, spicedbClient *authzed.ClientWithExperimental, t auth.Tokenizer) auth.Service {
 	database := postgres.NewDatabase(db, dbConfig, tracer)
 	keysRepo := apostgres.New(database)
 	domainsRepo := apostgres.NewDomainRepository(database)
 	idProvider := uuid.New()
 
 	pEvaluator := spicedb.NewPolicyEvaluator(spicedbClient, logger)
+	if opaConfig.URL != "" {
+		pEvaluator = opa.NewEvaluator(opaConfig, pEvaluator, logger)
+		logger.Info("Authorization decisions are deferred to external authorizer at " + opaConfig.URL)
+	}
 	pService := spicedb.NewPolicyService(spicedbClient, logger)
 
-	t := jwt.New([]byte(cfg.SecretKey))
-
-	svc := auth.New(keysRepo, domainsRepo, idProvider, t, pEvaluator, pService, cfg.AccessDuration, cfg.RefreshDuration, cfg.InvitationDuration)
+	svc := auth.New(keysRepo, domainsRepo, idProvider, t, pEvaluator, pService, cfg.AccessDuration, cfg.RefreshDuration, cfg.InvitationDuration, cfg.DisabledGrace, cfg.Audiences)
 	svc, err := events.NewEventStoreMiddleware(ctx, svc, cfg.ESURL)
 	if err != nil {
 		logger.Error(fmt.Sprintf("failed to init event store middleware : %s", err))
@@ -229,5 +306,12 @@ func newService(ctx context.Context, db *sqlx.DB, tracer trace.Tracer, cfg confi
 	svc = api.MetricsMiddleware(svc, counter, latency)
 	svc = tracing.New(svc, tracer)
 
+	if rc.Interval > 0 {
+		found, removed := prometheus.MakeReconcilerMetrics(svcName)
+		subjectOf := []string{policies.GroupType, policies.ThingType}
+		reconciler := policies.NewReconciler(pService, policies.DomainType, subjectOf, auth.ExistingDomains(domainsRepo), rc, found, removed, logger)
+		go reconciler.Run(ctx)
+	}
+
 	return svc
 }
@@ -25,7 +25,6 @@ import (
 	authsvcAuthn "github.com/absmach/magistrala/pkg/authn/authsvc"
 	mgauthz "github.com/absmach/magistrala/pkg/authz"
 	authsvcAuthz "github.com/absmach/magistrala/pkg/authz/authsvc"
-	"github.com/absmach/magistrala/pkg/events"
 	"github.com/absmach/magistrala/pkg/events/store"
 	"github.com/absmach/magistrala/pkg/grpcclient"
 	"github.com/absmach/magistrala/pkg/jaeger"
@@ -55,12 +54,12 @@ const (
 	defDB          = "bootstrap"
 	defSvcHTTPPort = "9013"
 
-	thingsStream = "events.magistrala.things"
-	streamID     = "magistrala.bootstrap"
+	streamID = "magistrala.bootstrap"
 )
 
 type config struct {
 	LogLevel            string  `env:"MG_BOOTSTRAP_LOG_LEVEL"        envDefault:"info"`
+	LogFormat           string  `env:"MG_LOG_FORMAT"                 envDefault:"json"`
 	EncKey              string  `env:"MG_BOOTSTRAP_ENCRYPT_KEY"      envDefault:"12345678910111213141516171819202"`
 	ESConsumerName      string  `env:"MG_BOOTSTRAP_EVENT_CONSUMER"   envDefault:"bootstrap"`
 	ThingsURL           string  `env:"MG_THINGS_URL"                 envDefault:"http://localhost:9000"`
@@ -72,6 +71,7 @@ type config struct {
 	SpicedbHost         string  `env:"MG_SPICEDB_HOST"               envDefault:"localhost"`
 	SpicedbPort         string  `env:"MG_SPICEDB_PORT"               envDefault:"50051"`
 	SpicedbPreSharedKey string  `env:"MG_SPICEDB_PRE_SHARED_KEY"     envDefault:"12345678"`
+	RevealSecretOnce    bool    `env:"MG_THINGS_REVEAL_SECRET_ONCE"  envDefault:"false"`
 }
 
 func main() {
@@ -83,7 +83,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -91,6 +91,14 @@ func main() {
 	var exitCode int
 	defer mglog.ExitWithError(&exitCode)
 
+	// Bootstrap configurations carry the thing secrets, which can't be read
+	// back from the Things service once they are revealed only on creation.
+	if cfg.RevealSecretOnce {
+		logger.Error("bootstrap service can't run with MG_THINGS_REVEAL_SECRET_ONCE enabled")
+		exitCode = 1
+		return
+	}
+
 	if cfg.InstanceID == "" {
 		if cfg.InstanceID, err = uuid.New().ID(); err != nil {
 			logger.Error(fmt.Sprintf("failed to generate instanceID: %s", err))
@@ -139,7 +147,7 @@ func main() {
 		exitCode = 1
 		return
 	}
-	authn, authnClient, err := authsvcAuthn.NewAuthentication(ctx, grpcCfg)
+	authn, authnClient, err := authsvcAuthn.NewAuthentication(ctx, grpcCfg, "")
 	if err != nil {
 		logger.Error(err.Error())
 		exitCode = 1
@@ -165,7 +173,7 @@ func main() {
 		return
 	}
 
-	if err = subscribeToThingsES(ctx, svc, cfg, logger); err != nil {
+	if err = store.SubscribeToThings(ctx, cfg.ESURL, cfg.ESConsumerName, consumer.NewEventHandler(svc), logger); err != nil {
 		logger.Error(fmt.Sprintf("failed to subscribe to things event store: %s", err))
 		exitCode = 1
 		return
@@ -228,20 +236,6 @@ func newService(ctx context.Context, authz mgauthz.Authorization, policySvc poli
 	return svc, nil
 }
 
-func subscribeToThingsES(ctx context.Context, svc bootstrap.Service, cfg config, logger *slog.Logger) error {
-	subscriber, err := store.NewSubscriber(ctx, cfg.ESURL, logger)
-	if err != nil {
-		return err
-	}
-
-	subConfig := events.SubscriberConfig{
-		Stream:   thingsStream,
-		Consumer: cfg.ESConsumerName,
-		Handler:  consumer.NewEventHandler(svc),
-	}
-	return subscriber.Subscribe(ctx, subConfig)
-}
-
 func newPolicyService(cfg config, logger *slog.Logger) (policies.Service, error) {
 	client, err := authzed.NewClientWithExperimentalAPIs(
 		fmt.Sprintf("%s:%s", cfg.SpicedbHost, cfg.SpicedbPort),
@@ -43,6 +43,7 @@ const (
 
 type config struct {
 	LogLevel      string  `env:"MG_CERTS_LOG_LEVEL"        envDefault:"info"`
+	LogFormat     string  `env:"MG_LOG_FORMAT"             envDefault:"json"`
 	ThingsURL     string  `env:"MG_THINGS_URL"             envDefault:"http://localhost:9000"`
 	JaegerURL     url.URL `env:"MG_JAEGER_URL"             envDefault:"http://localhost:4318/v1/traces"`
 	SendTelemetry bool    `env:"MG_SEND_TELEMETRY"         envDefault:"true"`
@@ -68,7 +69,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -103,7 +104,7 @@ func main() {
 		exitCode = 1
 		return
 	}
-	authn, authnClient, err := authsvcAuthn.NewAuthentication(ctx, grpcCfg)
+	authn, authnClient, err := authsvcAuthn.NewAuthentication(ctx, grpcCfg, "")
 	if err != nil {
 		logger.Error(err.Error())
 		exitCode = 1
@@ -16,31 +16,49 @@ import (
 	"github.com/absmach/magistrala/coap"
 	"github.com/absmach/magistrala/coap/api"
 	"github.com/absmach/magistrala/coap/tracing"
+	redisclient "github.com/absmach/magistrala/internal/clients/redis"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/grpcclient"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
+	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/messaging/brokers"
 	brokerstracing "github.com/absmach/magistrala/pkg/messaging/brokers/tracing"
+	msgmask "github.com/absmach/magistrala/pkg/messaging/mask"
+	msgmetrics "github.com/absmach/magistrala/pkg/messaging/metrics"
+	"github.com/absmach/magistrala/pkg/messaging/timewindow"
 	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 	"github.com/absmach/magistrala/pkg/server"
 	coapserver "github.com/absmach/magistrala/pkg/server/coap"
 	httpserver "github.com/absmach/magistrala/pkg/server/http"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/caarlos0/env/v11"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	svcName         = "coap_adapter"
-	envPrefix       = "MG_COAP_ADAPTER_"
-	envPrefixHTTP   = "MG_COAP_ADAPTER_HTTP_"
-	envPrefixThings = "MG_THINGS_AUTH_GRPC_"
-	defSvcHTTPPort  = "5683"
-	defSvcCoAPPort  = "5683"
+	svcName                 = "coap_adapter"
+	envPrefix               = "MG_COAP_ADAPTER_"
+	envPrefixHTTP           = "MG_COAP_ADAPTER_HTTP_"
+	envPrefixThings         = "MG_THINGS_AUTH_GRPC_"
+	envPrefixSubtopic       = "MG_MESSAGE_SUBTOPIC_"
+	envPrefixTopic          = "MG_MESSAGE_TOPIC_"
+	envPrefixChannelMetrics = "MG_MESSAGE_CHANNEL_METRICS_"
+	envPrefixMask           = "MG_MESSAGE_MASK_"
+	envPrefixTime           = "MG_MESSAGE_TIME_"
+	envPrefixSigning        = "MG_MESSAGE_SIGNING_"
+	envPrefixIPFilter       = "MG_COAP_ADAPTER_IP_FILTER_"
+	envPrefixRateLimit      = "MG_COAP_ADAPTER_RATE_LIMIT_"
+	defSvcHTTPPort          = "5683"
+	defSvcCoAPPort          = "5683"
 )
 
 type config struct {
 	LogLevel      string  `env:"MG_COAP_ADAPTER_LOG_LEVEL"   envDefault:"info"`
+	LogFormat     string  `env:"MG_LOG_FORMAT"               envDefault:"json"`
 	BrokerURL     string  `env:"MG_MESSAGE_BROKER_URL"       envDefault:"nats://localhost:4222"`
 	JaegerURL     url.URL `env:"MG_JAEGER_URL"               envDefault:"http://localhost:4318/v1/traces"`
 	SendTelemetry bool    `env:"MG_SEND_TELEMETRY"           envDefault:"true"`
@@ -57,7 +75,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -80,6 +98,12 @@ func main() {
 		return
 	}
 
+	if _, err := httpServerConfig.TLSConfig(); err != nil {
+		logger.Error(fmt.Sprintf("invalid %s HTTP server TLS configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	coapServerConfig := server.Config{Port: defSvcCoAPPort}
 	if err := env.ParseWithOptions(&coapServerConfig, env.Options{Prefix: envPrefix}); err != nil {
 		logger.Error(fmt.Sprintf("failed to load %s CoAP server configuration : %s", svcName, err))
@@ -94,6 +118,45 @@ func main() {
 		return
 	}
 
+	subtopicConfig := messaging.SubtopicConfig{}
+	if err := env.ParseWithOptions(&subtopicConfig, env.Options{Prefix: envPrefixSubtopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s subtopic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	subtopics, err := messaging.NewSubtopicRules(subtopicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s subtopic rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	topicConfig := messaging.TopicConfig{}
+	if err := env.ParseWithOptions(&topicConfig, env.Options{Prefix: envPrefixTopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s topic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	topics, err := messaging.NewTopicScheme(topicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s topic scheme : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	signingConfig := messaging.SigningConfig{}
+	if err := env.ParseWithOptions(&signingConfig, env.Options{Prefix: envPrefixSigning}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s signing configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	signing, err := messaging.NewSigningRules(signingConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s signing rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	thingsClient, thingsHandler, err := grpcclient.SetupThingsClient(ctx, thingsClientCfg)
 	if err != nil {
 		logger.Error(err.Error())
@@ -126,7 +189,100 @@ func main() {
 	defer nps.Close()
 	nps = brokerstracing.NewPubSub(coapServerConfig, tracer, nps)
 
-	svc := coap.New(thingsClient, nps)
+	channelMetricsConfig := msgmetrics.Config{}
+	if err := env.ParseWithOptions(&channelMetricsConfig, env.Options{Prefix: envPrefixChannelMetrics}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s channel metrics configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if channelMetricsConfig.Enabled() {
+		messages, bytes := prometheus.MakeChannelMetrics(svcName)
+		nps = msgmetrics.NewPubSub(channelMetricsConfig, nps, messages, bytes)
+	}
+
+	maskConfig := msgmask.Config{}
+	if err := env.ParseWithOptions(&maskConfig, env.Options{Prefix: envPrefixMask}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message mask configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if maskConfig.Enabled() {
+		masks, err := maskConfig.Parse()
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message mask configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		nps = msgmask.NewPubSub(masks, maskConfig.ContentType, nps)
+	}
+
+	timeConfig := timewindow.Config{}
+	if err := env.ParseWithOptions(&timeConfig, env.Options{Prefix: envPrefixTime}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message time configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if timeConfig.Enabled() {
+		if err := timeConfig.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message time configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		nps = timewindow.NewPubSub(timeConfig, nps)
+	}
+
+	ipFilterConfig := ipfilter.Config{}
+	if err := env.ParseWithOptions(&ipFilterConfig, env.Options{Prefix: envPrefixIPFilter}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s IP filter configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	ipFilter, err := ipfilter.New(ctx, ipFilterConfig, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s IP filter : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if ipFilter != nil {
+		rejected := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rejected_ips",
+			Help:      "Number of requests rejected due to the IP filter rules.",
+		}, []string{})
+		ipFilter = ipfilter.NewMetricsFilter(ipFilter, rejected)
+	}
+
+	rateLimitConfig := ratelimit.Config{}
+	if err := env.ParseWithOptions(&rateLimitConfig, env.Options{Prefix: envPrefixRateLimit}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s rate limit configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	var limiter ratelimit.Limiter
+	if rateLimitConfig.URL != "" {
+		limiterClient, err := redisclient.Connect(rateLimitConfig.URL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to connect to rate limit cache: %s", err))
+			exitCode = 1
+			return
+		}
+		defer limiterClient.Close()
+		if limiter, err = ratelimit.New(limiterClient, rateLimitConfig, logger); err != nil {
+			logger.Error(fmt.Sprintf("failed to create %s rate limiter : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		limited := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rate_limited_messages",
+			Help:      "Number of messages rejected or shed due to the publish rate of things.",
+		}, []string{})
+		limiter = ratelimit.NewMetricsLimiter(limiter, limited)
+	}
+
+	svc := coap.New(thingsClient, nps, topics, ipFilter, limiter, signing)
 
 	svc = tracing.New(tracer, svc)
 
@@ -137,7 +293,7 @@ func main() {
 
 	hs := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, api.MakeHandler(cfg.InstanceID), logger)
 
-	cs := coapserver.NewServer(ctx, cancel, svcName, coapServerConfig, api.MakeCoAPHandler(svc, logger), logger)
+	cs := coapserver.NewServer(ctx, cancel, svcName, coapServerConfig, api.MakeCoAPHandler(svc, subtopics, logger), logger)
 
 	if cfg.SendTelemetry {
 		chc := chclient.New(svcName, magistrala.Version, logger, cancel)
@@ -13,46 +13,76 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	chclient "github.com/absmach/callhome/pkg/client"
 	"github.com/absmach/magistrala"
 	adapter "github.com/absmach/magistrala/http"
 	"github.com/absmach/magistrala/http/api"
+	"github.com/absmach/magistrala/http/cache"
+	redisclient "github.com/absmach/magistrala/internal/clients/redis"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/grpcclient"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/messaging/brokers"
 	brokerstracing "github.com/absmach/magistrala/pkg/messaging/brokers/tracing"
+	msggrpc "github.com/absmach/magistrala/pkg/messaging/grpc"
 	"github.com/absmach/magistrala/pkg/messaging/handler"
+	msgmask "github.com/absmach/magistrala/pkg/messaging/mask"
+	msgmetrics "github.com/absmach/magistrala/pkg/messaging/metrics"
+	"github.com/absmach/magistrala/pkg/messaging/timewindow"
 	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 	"github.com/absmach/magistrala/pkg/server"
+	grpcserver "github.com/absmach/magistrala/pkg/server/grpc"
 	httpserver "github.com/absmach/magistrala/pkg/server/http"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/absmach/mproxy"
 	mproxyhttp "github.com/absmach/mproxy/pkg/http"
 	"github.com/absmach/mproxy/pkg/session"
 	"github.com/caarlos0/env/v11"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 const (
-	svcName         = "http_adapter"
-	envPrefix       = "MG_HTTP_ADAPTER_"
-	envPrefixThings = "MG_THINGS_AUTH_GRPC_"
-	defSvcHTTPPort  = "80"
-	targetHTTPPort  = "81"
-	targetHTTPHost  = "http://localhost"
+	svcName                 = "http_adapter"
+	envPrefix               = "MG_HTTP_ADAPTER_"
+	envPrefixGRPC           = "MG_HTTP_ADAPTER_GRPC_"
+	envPrefixThings         = "MG_THINGS_AUTH_GRPC_"
+	envPrefixSubtopic       = "MG_MESSAGE_SUBTOPIC_"
+	envPrefixTopic          = "MG_MESSAGE_TOPIC_"
+	envPrefixChannelMetrics = "MG_MESSAGE_CHANNEL_METRICS_"
+	envPrefixMask           = "MG_MESSAGE_MASK_"
+	envPrefixTime           = "MG_MESSAGE_TIME_"
+	envPrefixSigning        = "MG_MESSAGE_SIGNING_"
+	envPrefixIPFilter       = "MG_HTTP_ADAPTER_IP_FILTER_"
+	envPrefixRateLimit      = "MG_HTTP_ADAPTER_RATE_LIMIT_"
+	envPrefixPriority       = "MG_MESSAGE_PRIORITY_"
+	defSvcHTTPPort          = "80"
+	defSvcGRPCPort          = "7008"
+	targetHTTPPort          = "81"
+	targetHTTPHost          = "http://localhost"
 )
 
 type config struct {
-	LogLevel      string  `env:"MG_HTTP_ADAPTER_LOG_LEVEL"   envDefault:"info"`
-	BrokerURL     string  `env:"MG_MESSAGE_BROKER_URL"       envDefault:"nats://localhost:4222"`
-	JaegerURL     url.URL `env:"MG_JAEGER_URL"               envDefault:"http://localhost:4318/v1/traces"`
-	SendTelemetry bool    `env:"MG_SEND_TELEMETRY"           envDefault:"true"`
-	InstanceID    string  `env:"MG_HTTP_ADAPTER_INSTANCE_ID" envDefault:""`
-	TraceRatio    float64 `env:"MG_JAEGER_TRACE_RATIO"       envDefault:"1.0"`
+	LogLevel            string        `env:"MG_HTTP_ADAPTER_LOG_LEVEL"             envDefault:"info"`
+	LogFormat           string        `env:"MG_LOG_FORMAT"                         envDefault:"json"`
+	BrokerURL           string        `env:"MG_MESSAGE_BROKER_URL"                 envDefault:"nats://localhost:4222"`
+	JaegerURL           url.URL       `env:"MG_JAEGER_URL"                         envDefault:"http://localhost:4318/v1/traces"`
+	SendTelemetry       bool          `env:"MG_SEND_TELEMETRY"                     envDefault:"true"`
+	InstanceID          string        `env:"MG_HTTP_ADAPTER_INSTANCE_ID"           envDefault:""`
+	TraceRatio          float64       `env:"MG_JAEGER_TRACE_RATIO"                 envDefault:"1.0"`
+	IdempotencyWindow   time.Duration `env:"MG_HTTP_ADAPTER_IDEMPOTENCY_WINDOW"    envDefault:"0s"`
+	IdempotencyCacheURL string        `env:"MG_HTTP_ADAPTER_IDEMPOTENCY_CACHE_URL" envDefault:"redis://localhost:6379/0"`
+	AckTimeout          time.Duration `env:"MG_HTTP_ADAPTER_ACK_TIMEOUT"           envDefault:"5s"`
+	DrainTimeout        time.Duration `env:"MG_HTTP_ADAPTER_DRAIN_TIMEOUT"         envDefault:"5s"`
 }
 
 func main() {
@@ -64,7 +94,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -87,6 +117,12 @@ func main() {
 		return
 	}
 
+	if _, err := httpServerConfig.TLSConfig(); err != nil {
+		logger.Error(fmt.Sprintf("invalid %s HTTP server TLS configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	thingsClientCfg := grpcclient.Config{}
 	if err := env.ParseWithOptions(&thingsClientCfg, env.Options{Prefix: envPrefixThings}); err != nil {
 		logger.Error(fmt.Sprintf("failed to load %s auth configuration : %s", svcName, err))
@@ -94,6 +130,45 @@ func main() {
 		return
 	}
 
+	subtopicConfig := messaging.SubtopicConfig{}
+	if err := env.ParseWithOptions(&subtopicConfig, env.Options{Prefix: envPrefixSubtopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s subtopic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	subtopics, err := messaging.NewSubtopicRules(subtopicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s subtopic rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	topicConfig := messaging.TopicConfig{}
+	if err := env.ParseWithOptions(&topicConfig, env.Options{Prefix: envPrefixTopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s topic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	topics, err := messaging.NewTopicScheme(topicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s topic scheme : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	signingConfig := messaging.SigningConfig{}
+	if err := env.ParseWithOptions(&signingConfig, env.Options{Prefix: envPrefixSigning}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s signing configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	signing, err := messaging.NewSigningRules(signingConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s signing rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	thingsClient, thingsHandler, err := grpcclient.SetupThingsClient(ctx, thingsClientCfg)
 	if err != nil {
 		logger.Error(err.Error())
@@ -126,11 +201,163 @@ func main() {
 	defer pub.Close()
 	pub = brokerstracing.NewPublisher(httpServerConfig, tracer, pub)
 
-	svc := newService(pub, thingsClient, logger, tracer)
+	channelMetricsConfig := msgmetrics.Config{}
+	if err := env.ParseWithOptions(&channelMetricsConfig, env.Options{Prefix: envPrefixChannelMetrics}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s channel metrics configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if channelMetricsConfig.Enabled() {
+		messages, bytes := prometheus.MakeChannelMetrics(svcName)
+		pub = msgmetrics.NewPublisher(channelMetricsConfig, pub, messages, bytes)
+	}
+
+	maskConfig := msgmask.Config{}
+	if err := env.ParseWithOptions(&maskConfig, env.Options{Prefix: envPrefixMask}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message mask configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if maskConfig.Enabled() {
+		masks, err := maskConfig.Parse()
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message mask configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		pub = msgmask.NewPublisher(masks, maskConfig.ContentType, pub)
+	}
+
+	timeConfig := timewindow.Config{}
+	if err := env.ParseWithOptions(&timeConfig, env.Options{Prefix: envPrefixTime}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message time configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if timeConfig.Enabled() {
+		if err := timeConfig.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message time configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		pub = timewindow.NewPublisher(timeConfig, pub)
+	}
+
+	priorityConfig := messaging.PriorityConfig{}
+	if err := env.ParseWithOptions(&priorityConfig, env.Options{Prefix: envPrefixPriority}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message priority configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if priorityConfig.Enabled() {
+		pub = messaging.NewPriorityPublisher(pub, priorityConfig, logger)
+		// Publishes the queued messages on shutdown.
+		defer pub.Close()
+	}
+
+	var idempotency adapter.IdempotencyCache
+	if cfg.IdempotencyWindow > 0 {
+		cacheClient, err := redisclient.Connect(cfg.IdempotencyCacheURL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to connect to idempotency cache: %s", err))
+			exitCode = 1
+			return
+		}
+		defer cacheClient.Close()
+		idempotency = cache.NewIdempotencyCache(cacheClient, cfg.IdempotencyWindow)
+	}
+
+	ipFilterConfig := ipfilter.Config{}
+	if err := env.ParseWithOptions(&ipFilterConfig, env.Options{Prefix: envPrefixIPFilter}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s IP filter configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	ipFilter, err := ipfilter.New(ctx, ipFilterConfig, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s IP filter : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if ipFilter != nil {
+		rejected := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rejected_ips",
+			Help:      "Number of requests rejected due to the IP filter rules.",
+		}, []string{})
+		ipFilter = ipfilter.NewMetricsFilter(ipFilter, rejected)
+	}
+
+	rateLimitConfig := ratelimit.Config{}
+	if err := env.ParseWithOptions(&rateLimitConfig, env.Options{Prefix: envPrefixRateLimit}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s rate limit configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	var limiter ratelimit.Limiter
+	if rateLimitConfig.URL != "" {
+		limiterClient, err := redisclient.Connect(rateLimitConfig.URL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to connect to rate limit cache: %s", err))
+			exitCode = 1
+			return
+		}
+		defer limiterClient.Close()
+		if limiter, err = ratelimit.New(limiterClient, rateLimitConfig, logger); err != nil {
+			logger.Error(fmt.Sprintf("failed to create %s rate limiter : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		limited := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rate_limited_messages",
+			Help:      "Number of messages rejected or shed due to the publish rate of things.",
+		}, []string{})
+		limiter = ratelimit.NewMetricsLimiter(limiter, limited)
+	}
+
+	svc := newService(pub, thingsClient, subtopics, topics, idempotency, ipFilter, limiter, signing, cfg.AckTimeout, logger, tracer)
+	drain := handler.NewDrain(svc)
 	targetServerCfg := server.Config{Port: targetHTTPPort}
 
 	hs := httpserver.NewServer(ctx, cancel, svcName, targetServerCfg, api.MakeHandler(logger, cfg.InstanceID), logger)
 
+	grpcServerConfig := server.Config{Port: defSvcGRPCPort}
+	if err := env.ParseWithOptions(&grpcServerConfig, env.Options{Prefix: envPrefixGRPC}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s gRPC server configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	streamConfig := msggrpc.StreamConfig{}
+	if err := env.ParseWithOptions(&streamConfig, env.Options{Prefix: envPrefixGRPC}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s gRPC stream configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if err := streamConfig.Validate(); err != nil {
+		logger.Error(fmt.Sprintf("invalid %s gRPC stream configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	sub, err := brokers.NewPubSub(ctx, cfg.BrokerURL, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to connect to message broker: %s", err))
+		exitCode = 1
+		return
+	}
+	defer sub.Close()
+	sub = brokerstracing.NewPubSub(grpcServerConfig, tracer, sub)
+
+	registerMessagingServers := func(srv *grpc.Server) {
+		reflection.Register(srv)
+		messaging.RegisterPublisherServiceServer(srv, msggrpc.NewServer(drain))
+		messaging.RegisterSubscriberServiceServer(srv, msggrpc.NewSubscriberServer(sub, thingsClient, subtopics, topics, uuid.New(), streamConfig))
+	}
+	gs := grpcserver.NewServer(ctx, cancel, svcName, grpcServerConfig, registerMessagingServers, logger)
+
 	if cfg.SendTelemetry {
 		chc := chclient.New(svcName, magistrala.Version, logger, cancel)
 		go chc.CallHome(ctx)
@@ -141,11 +368,15 @@ func main() {
 	})
 
 	g.Go(func() error {
-		return proxyHTTP(ctx, httpServerConfig, logger, svc)
+		return gs.Start()
+	})
+
+	g.Go(func() error {
+		return proxyHTTP(ctx, httpServerConfig, logger, drain)
 	})
 
 	g.Go(func() error {
-		return server.StopSignalHandler(ctx, cancel, logger, svcName, hs)
+		return server.StopSignalHandler(ctx, cancel, logger, svcName, drain.Stopper(cfg.DrainTimeout), hs, gs)
 	})
 
 	if err := g.Wait(); err != nil {
@@ -153,8 +384,8 @@ func main() {
 	}
 }
 
-func newService(pub messaging.Publisher, tc magistrala.ThingsServiceClient, logger *slog.Logger, tracer trace.Tracer) session.Handler {
-	svc := adapter.NewHandler(pub, logger, tc)
+func newService(pub messaging.Publisher, tc magistrala.ThingsServiceClient, subtopics messaging.SubtopicRules, topics messaging.TopicScheme, idempotency adapter.IdempotencyCache, ipFilter ipfilter.Filter, limiter ratelimit.Limiter, signing messaging.SigningRules, ackTimeout time.Duration, logger *slog.Logger, tracer trace.Tracer) session.Handler {
+	svc := adapter.NewHandler(pub, logger, tc, subtopics, topics, idempotency, ipFilter, limiter, signing, ackTimeout)
 	svc = handler.NewTracing(tracer, svc)
 	svc = handler.LoggingMiddleware(svc, logger)
 	counter, latency := prometheus.MakeMetrics(svcName, "api")
@@ -173,15 +404,17 @@ func proxyHTTP(ctx context.Context, cfg server.Config, logger *slog.Logger, sess
 		if err != nil {
 			return err
 		}
-		config.TLSConfig = &tls.Config{
-			Certificates: []tls.Certificate{tlsCert},
+		config.TLSConfig, err = cfg.TLSPolicy.TLSConfig()
+		if err != nil {
+			return err
 		}
+		config.TLSConfig.Certificates = []tls.Certificate{tlsCert}
 	}
 	mp, err := mproxyhttp.NewProxy(config, sessionHandler, logger)
 	if err != nil {
 		return err
 	}
-	http.HandleFunc("/", mp.ServeHTTP)
+	http.Handle("/", ipfilter.RemoteIPMiddleware(adapter.IdempotencyKeyMiddleware(adapter.PriorityMiddleware(adapter.ContentTypeMiddleware(adapter.SignatureMiddleware(adapter.PublishAckMiddleware(http.HandlerFunc(mp.ServeHTTP))))))))
 
 	errCh := make(chan error)
 	switch {
@@ -48,6 +48,7 @@ const (
 
 type config struct {
 	LogLevel      string  `env:"MG_INVITATIONS_LOG_LEVEL"      envDefault:"info"`
+	LogFormat     string  `env:"MG_LOG_FORMAT"                 envDefault:"json"`
 	UsersURL      string  `env:"MG_USERS_URL"                  envDefault:"http://localhost:9002"`
 	DomainsURL    string  `env:"MG_DOMAINS_URL"                envDefault:"http://localhost:8189"`
 	InstanceID    string  `env:"MG_INVITATIONS_INSTANCE_ID"    envDefault:""`
@@ -65,7 +66,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -110,7 +111,7 @@ func main() {
 	defer tokenHandler.Close()
 	logger.Info("Token service client successfully connected to auth gRPC server " + tokenHandler.Secure())
 
-	authn, authnHandler, err := authsvcAuthn.NewAuthentication(ctx, authClientCfg)
+	authn, authnHandler, err := authsvcAuthn.NewAuthentication(ctx, authClientCfg, "")
 	if err != nil {
 		logger.Error(err.Error())
 		exitCode = 1
@@ -11,6 +11,7 @@ import (
 	"log/slog"
 	"net/url"
 	"os"
+	"time"
 
 	chclient "github.com/absmach/callhome/pkg/client"
 	"github.com/absmach/magistrala"
@@ -34,7 +35,6 @@ import (
 	"github.com/absmach/magistrala/pkg/server/http"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/caarlos0/env/v11"
-	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
@@ -44,17 +44,20 @@ const (
 	envPrefixDB    = "MG_JOURNAL_DB_"
 	envPrefixHTTP  = "MG_JOURNAL_HTTP_"
 	envPrefixAuth  = "MG_AUTH_GRPC_"
+	envPrefixRet   = "MG_JOURNAL_"
 	defDB          = "journal"
 	defSvcHTTPPort = "9021"
 )
 
 type config struct {
-	LogLevel      string  `env:"MG_JOURNAL_LOG_LEVEL"   envDefault:"info"`
-	ESURL         string  `env:"MG_ES_URL"              envDefault:"nats://localhost:4222"`
-	JaegerURL     url.URL `env:"MG_JAEGER_URL"          envDefault:"http://localhost:4318/v1/traces"`
-	SendTelemetry bool    `env:"MG_SEND_TELEMETRY"      envDefault:"true"`
-	InstanceID    string  `env:"MG_JOURNAL_INSTANCE_ID" envDefault:""`
-	TraceRatio    float64 `env:"MG_JAEGER_TRACE_RATIO"  envDefault:"1.0"`
+	LogLevel      string        `env:"MG_JOURNAL_LOG_LEVEL"   envDefault:"info"`
+	LogFormat     string        `env:"MG_LOG_FORMAT"          envDefault:"json"`
+	ESURL         string        `env:"MG_ES_URL"              envDefault:"nats://localhost:4222"`
+	JaegerURL     url.URL       `env:"MG_JAEGER_URL"          envDefault:"http://localhost:4318/v1/traces"`
+	SendTelemetry bool          `env:"MG_SEND_TELEMETRY"      envDefault:"true"`
+	InstanceID    string        `env:"MG_JOURNAL_INSTANCE_ID" envDefault:""`
+	TraceRatio    float64       `env:"MG_JAEGER_TRACE_RATIO"  envDefault:"1.0"`
+	ConnsTTL      time.Duration `env:"MG_JOURNAL_CONNS_TTL"   envDefault:"90s"`
 }
 
 func main() {
@@ -66,7 +69,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err)
 	}
@@ -103,7 +106,7 @@ func main() {
 		return
 	}
 
-	authn, authnHandler, err := authsvcAuthn.NewAuthentication(ctx, authClientCfg)
+	authn, authnHandler, err := authsvcAuthn.NewAuthentication(ctx, authClientCfg, "")
 	if err != nil {
 		logger.Error(err.Error())
 		exitCode = 1
@@ -134,7 +137,17 @@ func main() {
 	}()
 	tracer := tp.Tracer(svcName)
 
-	svc := newService(db, dbConfig, authn, authz, logger, tracer)
+	database := postgres.NewDatabase(db, dbConfig, tracer)
+	repo := journalpg.NewRepository(database)
+	svc := newService(repo, authn, authz, cfg.ConnsTTL, logger, tracer)
+
+	retConfig := journal.RetentionConfig{}
+	if err := env.ParseWithOptions(&retConfig, env.Options{Prefix: envPrefixRet}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s retention configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	go journal.NewPurger(repo, retConfig, logger).Run(ctx)
 
 	subscriber, err := store.NewSubscriber(ctx, cfg.ESURL, logger)
 	if err != nil {
@@ -178,12 +191,10 @@ func main() {
 	}
 }
 
-func newService(db *sqlx.DB, dbConfig pgclient.Config, authn mgauthn.Authentication, authz mgauthz.Authorization, logger *slog.Logger, tracer trace.Tracer) journal.Service {
-	database := postgres.NewDatabase(db, dbConfig, tracer)
-	repo := journalpg.NewRepository(database)
+func newService(repo journal.Repository, authn mgauthn.Authentication, authz mgauthz.Authorization, connsTTL time.Duration, logger *slog.Logger, tracer trace.Tracer) journal.Service {
 	idp := uuid.New()
 
-	svc := journal.NewService(authn, authz, idp, repo)
+	svc := journal.NewService(authn, authz, idp, repo, connsTTL)
 	svc = middleware.LoggingMiddleware(svc, logger)
 	counter, latency := prometheus.MakeMetrics("journal", "journal_writer")
 	svc = middleware.MetricsMiddleware(svc, counter, latency)
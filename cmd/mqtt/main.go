@@ -13,42 +13,67 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	chclient "github.com/absmach/callhome/pkg/client"
 	"github.com/absmach/magistrala"
+	redisclient "github.com/absmach/magistrala/internal/clients/redis"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/magistrala/mqtt/cache"
 	"github.com/absmach/magistrala/mqtt/events"
+	"github.com/absmach/magistrala/mqtt/events/consumer"
 	mqtttracing "github.com/absmach/magistrala/mqtt/tracing"
 	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/events/store"
 	"github.com/absmach/magistrala/pkg/grpcclient"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
+	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/messaging/brokers"
 	brokerstracing "github.com/absmach/magistrala/pkg/messaging/brokers/tracing"
 	"github.com/absmach/magistrala/pkg/messaging/handler"
+	msgmask "github.com/absmach/magistrala/pkg/messaging/mask"
+	msgmetrics "github.com/absmach/magistrala/pkg/messaging/metrics"
 	mqttpub "github.com/absmach/magistrala/pkg/messaging/mqtt"
+	"github.com/absmach/magistrala/pkg/messaging/timewindow"
+	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 	"github.com/absmach/magistrala/pkg/server"
+	httpserver "github.com/absmach/magistrala/pkg/server/http"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/absmach/mproxy"
-	mproxymqtt "github.com/absmach/mproxy/pkg/mqtt"
-	"github.com/absmach/mproxy/pkg/mqtt/websocket"
 	"github.com/absmach/mproxy/pkg/session"
 	"github.com/caarlos0/env/v11"
 	"github.com/cenkalti/backoff/v4"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	svcName         = "mqtt"
-	envPrefixThings = "MG_THINGS_AUTH_GRPC_"
-	wsPathPrefix    = "/mqtt"
+	svcName                 = "mqtt"
+	envPrefixThings         = "MG_THINGS_AUTH_GRPC_"
+	envPrefixSubtopic       = "MG_MESSAGE_SUBTOPIC_"
+	envPrefixTopic          = "MG_MESSAGE_TOPIC_"
+	envPrefixChannelMetrics = "MG_MESSAGE_CHANNEL_METRICS_"
+	envPrefixMask           = "MG_MESSAGE_MASK_"
+	envPrefixTime           = "MG_MESSAGE_TIME_"
+	envPrefixSigning        = "MG_MESSAGE_SIGNING_"
+	envPrefixHTTP           = "MG_MQTT_ADAPTER_HTTP_"
+	envPrefixIPFilter       = "MG_MQTT_ADAPTER_IP_FILTER_"
+	envPrefixBatch          = "MG_MQTT_ADAPTER_BATCH_"
+	envPrefixRateLimit      = "MG_MQTT_ADAPTER_RATE_LIMIT_"
+	envPrefixKeepalive      = "MG_MQTT_ADAPTER_KEEPALIVE_"
+	envPrefixPriority       = "MG_MESSAGE_PRIORITY_"
+	defSvcHTTPPort          = "9015"
+	wsPathPrefix            = "/mqtt"
 )
 
 type config struct {
 	LogLevel              string        `env:"MG_MQTT_ADAPTER_LOG_LEVEL"                    envDefault:"info"`
+	LogFormat             string        `env:"MG_LOG_FORMAT"                                envDefault:"json"`
 	MQTTPort              string        `env:"MG_MQTT_ADAPTER_MQTT_PORT"                    envDefault:"1883"`
 	MQTTTargetHost        string        `env:"MG_MQTT_ADAPTER_MQTT_TARGET_HOST"             envDefault:"localhost"`
 	MQTTTargetPort        string        `env:"MG_MQTT_ADAPTER_MQTT_TARGET_PORT"             envDefault:"1883"`
@@ -66,6 +91,11 @@ type config struct {
 	InstanceID            string        `env:"MG_MQTT_ADAPTER_INSTANCE_ID"                  envDefault:""`
 	ESURL                 string        `env:"MG_ES_URL"                                    envDefault:"nats://localhost:4222"`
 	TraceRatio            float64       `env:"MG_JAEGER_TRACE_RATIO"                        envDefault:"1.0"`
+	MaxConnsPerThing      int           `env:"MG_MQTT_ADAPTER_MAX_CONNS_PER_THING"          envDefault:"0"`
+	ConnsCacheURL         string        `env:"MG_MQTT_ADAPTER_CONNS_CACHE_URL"              envDefault:"redis://localhost:6379/0"`
+	ConnsTTL              time.Duration `env:"MG_MQTT_ADAPTER_CONNS_TTL"                    envDefault:"1m"`
+	DrainTimeout          time.Duration `env:"MG_MQTT_ADAPTER_DRAIN_TIMEOUT"                envDefault:"5s"`
+	HeartbeatInterval     time.Duration `env:"MG_MQTT_ADAPTER_HEARTBEAT_INTERVAL"           envDefault:"30s"`
 }
 
 func main() {
@@ -77,7 +107,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -158,12 +188,96 @@ func main() {
 	defer np.Close()
 	np = brokerstracing.NewPublisher(serverConfig, tracer, np)
 
+	channelMetricsConfig := msgmetrics.Config{}
+	if err := env.ParseWithOptions(&channelMetricsConfig, env.Options{Prefix: envPrefixChannelMetrics}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s channel metrics configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if channelMetricsConfig.Enabled() {
+		messages, bytes := prometheus.MakeChannelMetrics(svcName)
+		np = msgmetrics.NewPublisher(channelMetricsConfig, np, messages, bytes)
+	}
+
+	maskConfig := msgmask.Config{}
+	if err := env.ParseWithOptions(&maskConfig, env.Options{Prefix: envPrefixMask}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message mask configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if maskConfig.Enabled() {
+		masks, err := maskConfig.Parse()
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message mask configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		np = msgmask.NewPublisher(masks, maskConfig.ContentType, np)
+	}
+
+	timeConfig := timewindow.Config{}
+	if err := env.ParseWithOptions(&timeConfig, env.Options{Prefix: envPrefixTime}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message time configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if timeConfig.Enabled() {
+		if err := timeConfig.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message time configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		np = timewindow.NewPublisher(timeConfig, np)
+	}
+
+	priorityConfig := messaging.PriorityConfig{}
+	if err := env.ParseWithOptions(&priorityConfig, env.Options{Prefix: envPrefixPriority}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message priority configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if priorityConfig.Enabled() {
+		np = messaging.NewPriorityPublisher(np, priorityConfig, logger)
+		// Publishes the queued messages on shutdown.
+		defer np.Close()
+	}
+
+	batchConfig := mqtt.BatchConfig{}
+	if err := env.ParseWithOptions(&batchConfig, env.Options{Prefix: envPrefixBatch}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s batch configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if batchConfig.Enabled() {
+		np = mqtt.NewBatchPublisher(np, batchConfig, logger)
+		// Flushes the pending batches on shutdown.
+		defer np.Close()
+	}
+
+	keepaliveConfig := mqtt.KeepaliveConfig{}
+	if err := env.ParseWithOptions(&keepaliveConfig, env.Options{Prefix: envPrefixKeepalive}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s keepalive configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	// The connections are registered per instance, so the instances sharing
+	// the event store must not share the instance name.
+	if cfg.Instance == "" {
+		cfg.Instance = cfg.InstanceID
+	}
 	es, err := events.NewEventStore(ctx, cfg.ESURL, cfg.Instance)
 	if err != nil {
 		logger.Error(fmt.Sprintf("failed to create %s event store : %s", svcName, err))
 		exitCode = 1
 		return
 	}
+	if cfg.HeartbeatInterval <= 0 {
+		logger.Error(fmt.Sprintf("invalid %s heartbeat interval %s, expected positive interval", svcName, cfg.HeartbeatInterval))
+		exitCode = 1
+		return
+	}
+	go events.RunHeartbeat(ctx, es, cfg.HeartbeatInterval, logger)
 
 	thingsClientCfg := grpcclient.Config{}
 	if err := env.ParseWithOptions(&thingsClientCfg, env.Options{Prefix: envPrefixThings}); err != nil {
@@ -172,6 +286,45 @@ func main() {
 		return
 	}
 
+	subtopicConfig := messaging.SubtopicConfig{}
+	if err := env.ParseWithOptions(&subtopicConfig, env.Options{Prefix: envPrefixSubtopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s subtopic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	subtopics, err := messaging.NewSubtopicRules(subtopicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s subtopic rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	topicConfig := messaging.TopicConfig{}
+	if err := env.ParseWithOptions(&topicConfig, env.Options{Prefix: envPrefixTopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s topic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	topics, err := messaging.NewTopicScheme(topicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s topic scheme : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	signingConfig := messaging.SigningConfig{}
+	if err := env.ParseWithOptions(&signingConfig, env.Options{Prefix: envPrefixSigning}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s signing configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	signing, err := messaging.NewSigningRules(signingConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s signing rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	thingsClient, thingsHandler, err := grpcclient.SetupThingsClient(ctx, thingsClientCfg)
 	if err != nil {
 		logger.Error(err.Error())
@@ -182,27 +335,133 @@ func main() {
 
 	logger.Info("Things service gRPC client successfully connected to things gRPC server " + thingsHandler.Secure())
 
-	h := mqtt.NewHandler(np, es, logger, thingsClient)
+	var limiter mqtt.ConnLimiter
+	if cfg.MaxConnsPerThing > 0 {
+		cacheClient, err := redisclient.Connect(cfg.ConnsCacheURL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to connect to connections cache: %s", err))
+			exitCode = 1
+			return
+		}
+		defer cacheClient.Close()
+
+		rejected := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rejected_connections",
+			Help:      "Number of connections rejected due to the per thing connection limit.",
+		}, []string{})
+		limiter, err = cache.NewConnLimiter(ctx, cacheClient, cfg.MaxConnsPerThing, cfg.ConnsTTL, logger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to create connection limiter: %s", err))
+			exitCode = 1
+			return
+		}
+		limiter = mqtt.NewMetricsLimiter(limiter, rejected)
+	}
+
+	ipFilterConfig := ipfilter.Config{}
+	if err := env.ParseWithOptions(&ipFilterConfig, env.Options{Prefix: envPrefixIPFilter}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s IP filter configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	ipFilter, err := ipfilter.New(ctx, ipFilterConfig, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s IP filter : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if ipFilter != nil {
+		rejected := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rejected_ips",
+			Help:      "Number of connections rejected due to the IP filter rules.",
+		}, []string{})
+		ipFilter = ipfilter.NewMetricsFilter(ipFilter, rejected)
+	}
+
+	rateLimitConfig := ratelimit.Config{}
+	if err := env.ParseWithOptions(&rateLimitConfig, env.Options{Prefix: envPrefixRateLimit}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s rate limit configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	var rates ratelimit.Limiter
+	if rateLimitConfig.URL != "" {
+		ratesClient, err := redisclient.Connect(rateLimitConfig.URL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to connect to rate limit cache: %s", err))
+			exitCode = 1
+			return
+		}
+		defer ratesClient.Close()
+		if rates, err = ratelimit.New(ratesClient, rateLimitConfig, logger); err != nil {
+			logger.Error(fmt.Sprintf("failed to create %s rate limiter : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		limited := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: svcName,
+			Subsystem: "adapter",
+			Name:      "rate_limited_messages",
+			Help:      "Number of messages rejected or shed due to the publish rate of things.",
+		}, []string{})
+		rates = ratelimit.NewMetricsLimiter(rates, limited)
+	}
+
+	// The sessions of the things whose key is rotated are closed. Each
+	// instance closes its own sessions, so the consumer is per instance.
+	sessions := mqtt.NewSessions()
+	consumerName := fmt.Sprintf("%s-%s", svcName, cfg.Instance)
+	if err := store.SubscribeToThings(ctx, cfg.ESURL, consumerName, consumer.NewEventHandler(sessions, logger), logger); err != nil {
+		logger.Error(fmt.Sprintf("failed to subscribe to things event store: %s", err))
+		exitCode = 1
+		return
+	}
+
+	h := mqtt.NewHandler(np, es, logger, thingsClient, subtopics, topics, limiter, ipFilter, rates, signing, sessions)
+	// The handler intercepts the packets to map the QoS of the messages to
+	// their priority.
+	interceptor := h.(session.Interceptor)
 	h = handler.NewTracing(tracer, h)
+	drain := handler.NewDrain(h)
 
 	if cfg.SendTelemetry {
 		chc := chclient.New(svcName, magistrala.Version, logger, cancel)
 		go chc.CallHome(ctx)
 	}
 
-	var interceptor session.Interceptor
 	logger.Info(fmt.Sprintf("Starting MQTT proxy on port %s", cfg.MQTTPort))
 	g.Go(func() error {
-		return proxyMQTT(ctx, cfg, logger, h, interceptor)
+		return proxyMQTT(ctx, cfg, keepaliveConfig, logger, drain, interceptor)
 	})
 
 	logger.Info(fmt.Sprintf("Starting MQTT over WS  proxy on port %s", cfg.HTTPPort))
 	g.Go(func() error {
-		return proxyWS(ctx, cfg, logger, h, interceptor)
+		return proxyWS(ctx, cfg, keepaliveConfig, logger, drain, interceptor)
+	})
+
+	httpServerConfig := server.Config{Port: defSvcHTTPPort}
+	if err := env.ParseWithOptions(&httpServerConfig, env.Options{Prefix: envPrefixHTTP}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s HTTP server configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	if _, err := httpServerConfig.TLSConfig(); err != nil {
+		logger.Error(fmt.Sprintf("invalid %s HTTP server TLS configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	hs := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, makeHandler(cfg.InstanceID), logger)
+	g.Go(func() error {
+		return hs.Start()
 	})
 
 	g.Go(func() error {
-		return stopSignalHandler(ctx, cancel, logger)
+		return server.StopSignalHandler(ctx, cancel, logger, svcName, drain.Stopper(cfg.DrainTimeout), hs)
 	})
 
 	if err := g.Wait(); err != nil {
@@ -210,16 +469,16 @@ func main() {
 	}
 }
 
-func proxyMQTT(ctx context.Context, cfg config, logger *slog.Logger, sessionHandler session.Handler, interceptor session.Interceptor) error {
+func proxyMQTT(ctx context.Context, cfg config, keepaliveConfig mqtt.KeepaliveConfig, logger *slog.Logger, sessionHandler session.Handler, interceptor session.Interceptor) error {
 	config := mproxy.Config{
 		Address: fmt.Sprintf(":%s", cfg.MQTTPort),
 		Target:  fmt.Sprintf("%s:%s", cfg.MQTTTargetHost, cfg.MQTTTargetPort),
 	}
-	mproxy := mproxymqtt.New(config, sessionHandler, interceptor, logger)
+	mp := mqtt.NewProxy(config, sessionHandler, interceptor, keepaliveConfig, logger)
 
 	errCh := make(chan error)
 	go func() {
-		errCh <- mproxy.Listen(ctx)
+		errCh <- mp.Listen(ctx)
 	}()
 
 	select {
@@ -231,15 +490,14 @@ func proxyMQTT(ctx context.Context, cfg config, logger *slog.Logger, sessionHand
 	}
 }
 
-func proxyWS(ctx context.Context, cfg config, logger *slog.Logger, sessionHandler session.Handler, interceptor session.Interceptor) error {
+func proxyWS(ctx context.Context, cfg config, keepaliveConfig mqtt.KeepaliveConfig, logger *slog.Logger, sessionHandler session.Handler, interceptor session.Interceptor) error {
 	config := mproxy.Config{
 		Address:    fmt.Sprintf("%s:%s", "", cfg.HTTPPort),
 		Target:     fmt.Sprintf("ws://%s:%s%s", cfg.HTTPTargetHost, cfg.HTTPTargetPort, wsPathPrefix),
 		PathPrefix: wsPathPrefix,
 	}
 
-	wp := websocket.New(config, sessionHandler, interceptor, logger)
-	http.HandleFunc(wsPathPrefix, wp.ServeHTTP)
+	wp := mqtt.NewWSProxy(config, sessionHandler, interceptor, keepaliveConfig, logger)
 
 	errCh := make(chan error)
 
@@ -274,15 +532,10 @@ func healthcheck(cfg config) func() error {
 	}
 }
 
-func stopSignalHandler(ctx context.Context, cancel context.CancelFunc, logger *slog.Logger) error {
-	c := make(chan os.Signal, 2)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGABRT)
-	select {
-	case sig := <-c:
-		defer cancel()
-		logger.Info(fmt.Sprintf("%s service shutdown by signal: %s", svcName, sig))
-		return nil
-	case <-ctx.Done():
-		return nil
-	}
+func makeHandler(instanceID string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/health", magistrala.Health(svcName, instanceID))
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return mux
 }
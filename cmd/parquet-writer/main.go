@@ -0,0 +1,159 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package main contains parquet-writer main function to start the parquet-writer service.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/url"
+	"os"
+	"time"
+
+	chclient "github.com/absmach/callhome/pkg/client"
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/consumers"
+	consumertracing "github.com/absmach/magistrala/consumers/tracing"
+	"github.com/absmach/magistrala/consumers/writers/api"
+	"github.com/absmach/magistrala/consumers/writers/parquet"
+	mglog "github.com/absmach/magistrala/logger"
+	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
+	"github.com/absmach/magistrala/pkg/messaging/brokers"
+	brokerstracing "github.com/absmach/magistrala/pkg/messaging/brokers/tracing"
+	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/pkg/server"
+	httpserver "github.com/absmach/magistrala/pkg/server/http"
+	"github.com/absmach/magistrala/pkg/uuid"
+	"github.com/caarlos0/env/v11"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	svcName        = "parquet-writer"
+	envPrefixHTTP  = "MG_PARQUET_WRITER_HTTP_"
+	defSvcHTTPPort = "9014"
+)
+
+type config struct {
+	LogLevel      string        `env:"MG_PARQUET_WRITER_LOG_LEVEL"       envDefault:"info"`
+	LogFormat     string        `env:"MG_LOG_FORMAT"                     envDefault:"json"`
+	ConfigPath    string        `env:"MG_PARQUET_WRITER_CONFIG_PATH"     envDefault:"/config.toml"`
+	StoragePath   string        `env:"MG_PARQUET_WRITER_STORAGE_PATH"    envDefault:"/data"`
+	FilePrefix    string        `env:"MG_PARQUET_WRITER_FILE_PREFIX"     envDefault:"messages"`
+	MaxRows       int           `env:"MG_PARQUET_WRITER_MAX_ROWS"        envDefault:"100000"`
+	MaxBytes      int64         `env:"MG_PARQUET_WRITER_MAX_BYTES"       envDefault:"67108864"`
+	FlushInterval time.Duration `env:"MG_PARQUET_WRITER_FLUSH_INTERVAL"  envDefault:"1m"`
+	BrokerURL     string        `env:"MG_MESSAGE_BROKER_URL"             envDefault:"nats://localhost:4222"`
+	JaegerURL     url.URL       `env:"MG_JAEGER_URL"                     envDefault:"http://localhost:4318/v1/traces"`
+	SendTelemetry bool          `env:"MG_SEND_TELEMETRY"                 envDefault:"true"`
+	InstanceID    string        `env:"MG_PARQUET_WRITER_INSTANCE_ID"     envDefault:""`
+	TraceRatio    float64       `env:"MG_JAEGER_TRACE_RATIO"             envDefault:"1.0"`
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+
+	cfg := config{}
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("failed to load %s service configuration : %s", svcName, err)
+	}
+
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatalf("failed to init logger: %s", err.Error())
+	}
+
+	var exitCode int
+	defer mglog.ExitWithError(&exitCode)
+
+	if cfg.InstanceID == "" {
+		if cfg.InstanceID, err = uuid.New().ID(); err != nil {
+			logger.Error(fmt.Sprintf("failed to generate instanceID: %s", err))
+			exitCode = 1
+			return
+		}
+	}
+
+	httpServerConfig := server.Config{Port: defSvcHTTPPort}
+	if err := env.ParseWithOptions(&httpServerConfig, env.Options{Prefix: envPrefixHTTP}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s HTTP server configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	tp, err := jaegerclient.NewProvider(ctx, svcName, cfg.JaegerURL, cfg.InstanceID, cfg.TraceRatio)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to init Jaeger: %s", err))
+		exitCode = 1
+		return
+	}
+	defer func() {
+		if err := tp.Shutdown(ctx); err != nil {
+			logger.Error(fmt.Sprintf("Error shutting down tracer provider: %v", err))
+		}
+	}()
+	tracer := tp.Tracer(svcName)
+
+	writerConfig := parquet.Config{
+		Prefix:        cfg.FilePrefix,
+		MaxRows:       cfg.MaxRows,
+		MaxBytes:      cfg.MaxBytes,
+		FlushInterval: cfg.FlushInterval,
+	}
+	writer := parquet.New(parquet.NewFSStorage(cfg.StoragePath), writerConfig, logger)
+	// Registered before the broker connection is closed, so it runs last
+	// and flushes every message received before shutdown.
+	defer func() {
+		if err := writer.Close(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("failed to flush remaining messages: %s", err))
+		}
+	}()
+
+	repo := newService(writer, logger)
+	repo = consumertracing.NewBlocking(tracer, repo, httpServerConfig)
+
+	pubSub, err := brokers.NewPubSub(ctx, cfg.BrokerURL, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to connect to message broker: %s", err))
+		exitCode = 1
+		return
+	}
+	defer pubSub.Close()
+	pubSub = brokerstracing.NewPubSub(httpServerConfig, tracer, pubSub)
+
+	if err = consumers.Start(ctx, svcName, pubSub, repo, cfg.ConfigPath, logger); err != nil {
+		logger.Error(fmt.Sprintf("failed to create Parquet writer: %s", err))
+		exitCode = 1
+		return
+	}
+
+	hs := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, api.MakeHandler(svcName, cfg.InstanceID), logger)
+
+	if cfg.SendTelemetry {
+		chc := chclient.New(svcName, magistrala.Version, logger, cancel)
+		go chc.CallHome(ctx)
+	}
+
+	g.Go(func() error {
+		return hs.Start()
+	})
+
+	g.Go(func() error {
+		return server.StopSignalHandler(ctx, cancel, logger, svcName, hs)
+	})
+
+	if err := g.Wait(); err != nil {
+		logger.Error(fmt.Sprintf("Parquet writer service terminated: %s", err))
+	}
+}
+
+func newService(writer consumers.BlockingConsumer, logger *slog.Logger) consumers.BlockingConsumer {
+	svc := api.LoggingMiddleware(writer, logger)
+	counter, latency := prometheus.MakeMetrics("parquet", "message_writer")
+	svc = api.MetricsMiddleware(svc, counter, latency)
+	return svc
+}
@@ -18,7 +18,9 @@ import (
 	consumertracing "github.com/absmach/magistrala/consumers/tracing"
 	"github.com/absmach/magistrala/consumers/writers/api"
 	writerpg "github.com/absmach/magistrala/consumers/writers/postgres"
+	"github.com/absmach/magistrala/consumers/writers/retention"
 	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/events/store"
 	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
 	"github.com/absmach/magistrala/pkg/messaging/brokers"
 	brokerstracing "github.com/absmach/magistrala/pkg/messaging/brokers/tracing"
@@ -33,21 +35,25 @@ import (
 )
 
 const (
-	svcName        = "postgres-writer"
-	envPrefixDB    = "MG_POSTGRES_"
-	envPrefixHTTP  = "MG_POSTGRES_WRITER_HTTP_"
-	defDB          = "messages"
-	defSvcHTTPPort = "9010"
+	svcName            = "postgres-writer"
+	envPrefixDB        = "MG_POSTGRES_"
+	envPrefixHTTP      = "MG_POSTGRES_WRITER_HTTP_"
+	envPrefixRetention = "MG_POSTGRES_WRITER_RETENTION_"
+	defDB              = "messages"
+	defSvcHTTPPort     = "9010"
 )
 
 type config struct {
-	LogLevel      string  `env:"MG_POSTGRES_WRITER_LOG_LEVEL"     envDefault:"info"`
-	ConfigPath    string  `env:"MG_POSTGRES_WRITER_CONFIG_PATH"   envDefault:"/config.toml"`
-	BrokerURL     string  `env:"MG_MESSAGE_BROKER_URL"            envDefault:"nats://localhost:4222"`
-	JaegerURL     url.URL `env:"MG_JAEGER_URL"                    envDefault:"http://localhost:4318/v1/traces"`
-	SendTelemetry bool    `env:"MG_SEND_TELEMETRY"                envDefault:"true"`
-	InstanceID    string  `env:"MG_POSTGRES_WRITER_INSTANCE_ID"   envDefault:""`
-	TraceRatio    float64 `env:"MG_JAEGER_TRACE_RATIO"            envDefault:"1.0"`
+	LogLevel       string  `env:"MG_POSTGRES_WRITER_LOG_LEVEL"       envDefault:"info"`
+	LogFormat      string  `env:"MG_LOG_FORMAT"                      envDefault:"json"`
+	ConfigPath     string  `env:"MG_POSTGRES_WRITER_CONFIG_PATH"     envDefault:"/config.toml"`
+	BrokerURL      string  `env:"MG_MESSAGE_BROKER_URL"              envDefault:"nats://localhost:4222"`
+	JaegerURL      url.URL `env:"MG_JAEGER_URL"                      envDefault:"http://localhost:4318/v1/traces"`
+	SendTelemetry  bool    `env:"MG_SEND_TELEMETRY"                  envDefault:"true"`
+	InstanceID     string  `env:"MG_POSTGRES_WRITER_INSTANCE_ID"     envDefault:""`
+	TraceRatio     float64 `env:"MG_JAEGER_TRACE_RATIO"              envDefault:"1.0"`
+	ESURL          string  `env:"MG_ES_URL"                          envDefault:"nats://localhost:4222"`
+	ESConsumerName string  `env:"MG_POSTGRES_WRITER_EVENT_CONSUMER"  envDefault:"postgres-writer"`
 }
 
 func main() {
@@ -59,7 +65,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -88,6 +94,14 @@ func main() {
 		exitCode = 1
 		return
 	}
+
+	rtConfig := retention.Config{}
+	if err := env.ParseWithOptions(&rtConfig, env.Options{Prefix: envPrefixRetention}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s retention configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	db, err := pgclient.Setup(dbConfig, *writerpg.Migration())
 	if err != nil {
 		logger.Error(err.Error())
@@ -125,6 +139,16 @@ func main() {
 		return
 	}
 
+	if rtConfig.Interval > 0 {
+		rtRepo := writerpg.NewRetentionRepository(db)
+		if err := store.SubscribeToThings(ctx, cfg.ESURL, cfg.ESConsumerName, retention.NewEventHandler(rtRepo, rtConfig.MetadataKey), logger); err != nil {
+			logger.Error(fmt.Sprintf("failed to subscribe to things event store: %s", err))
+			exitCode = 1
+			return
+		}
+		go retention.NewPurger(rtRepo, rtConfig, logger).Run(ctx)
+	}
+
 	hs := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, api.MakeHandler(svcName, cfg.InstanceID), logger)
 
 	if cfg.SendTelemetry {
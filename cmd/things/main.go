@@ -15,6 +15,7 @@ import (
 
 	chclient "github.com/absmach/callhome/pkg/client"
 	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/internal/api"
 	redisclient "github.com/absmach/magistrala/internal/clients/redis"
 	mggroups "github.com/absmach/magistrala/internal/groups"
 	gevents "github.com/absmach/magistrala/internal/groups/events"
@@ -28,11 +29,16 @@ import (
 	"github.com/absmach/magistrala/pkg/groups"
 	"github.com/absmach/magistrala/pkg/grpcclient"
 	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
+	"github.com/absmach/magistrala/pkg/jobs"
+	jpostgres "github.com/absmach/magistrala/pkg/jobs/postgres"
+	"github.com/absmach/magistrala/pkg/messaging/brokers"
 	"github.com/absmach/magistrala/pkg/policies"
 	"github.com/absmach/magistrala/pkg/policies/spicedb"
 	"github.com/absmach/magistrala/pkg/postgres"
 	pgclient "github.com/absmach/magistrala/pkg/postgres"
 	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/pkg/quotas"
+	qpostgres "github.com/absmach/magistrala/pkg/quotas/postgres"
 	"github.com/absmach/magistrala/pkg/server"
 	grpcserver "github.com/absmach/magistrala/pkg/server/grpc"
 	httpserver "github.com/absmach/magistrala/pkg/server/http"
@@ -64,6 +70,9 @@ const (
 	envPrefixHTTP      = "MG_THINGS_HTTP_"
 	envPrefixGRPC      = "MG_THINGS_AUTH_GRPC_"
 	envPrefixAuth      = "MG_AUTH_GRPC_"
+	envPrefixPolicy    = "MG_THINGS_POLICY_RECONCILER_"
+	envPrefixJobs      = "MG_THINGS_JOBS_"
+	envPrefixQuota     = "MG_THINGS_QUOTA_"
 	defDB              = "things"
 	defSvcHTTPPort     = "9000"
 	defSvcAuthGRPCPort = "7000"
@@ -72,19 +81,30 @@ const (
 )
 
 type config struct {
-	LogLevel            string        `env:"MG_THINGS_LOG_LEVEL"           envDefault:"info"`
-	StandaloneID        string        `env:"MG_THINGS_STANDALONE_ID"       envDefault:""`
-	StandaloneToken     string        `env:"MG_THINGS_STANDALONE_TOKEN"    envDefault:""`
-	JaegerURL           url.URL       `env:"MG_JAEGER_URL"                 envDefault:"http://localhost:4318/v1/traces"`
-	CacheKeyDuration    time.Duration `env:"MG_THINGS_CACHE_KEY_DURATION"  envDefault:"10m"`
-	SendTelemetry       bool          `env:"MG_SEND_TELEMETRY"             envDefault:"true"`
-	InstanceID          string        `env:"MG_THINGS_INSTANCE_ID"         envDefault:""`
-	ESURL               string        `env:"MG_ES_URL"                     envDefault:"nats://localhost:4222"`
-	CacheURL            string        `env:"MG_THINGS_CACHE_URL"           envDefault:"redis://localhost:6379/0"`
-	TraceRatio          float64       `env:"MG_JAEGER_TRACE_RATIO"         envDefault:"1.0"`
-	SpicedbHost         string        `env:"MG_SPICEDB_HOST"               envDefault:"localhost"`
-	SpicedbPort         string        `env:"MG_SPICEDB_PORT"               envDefault:"50051"`
-	SpicedbPreSharedKey string        `env:"MG_SPICEDB_PRE_SHARED_KEY"     envDefault:"12345678"`
+	LogLevel            string            `env:"MG_THINGS_LOG_LEVEL"           envDefault:"info"`
+	LogFormat           string            `env:"MG_LOG_FORMAT"                 envDefault:"json"`
+	StandaloneID        string            `env:"MG_THINGS_STANDALONE_ID"       envDefault:""`
+	StandaloneToken     string            `env:"MG_THINGS_STANDALONE_TOKEN"    envDefault:""`
+	JaegerURL           url.URL           `env:"MG_JAEGER_URL"                 envDefault:"http://localhost:4318/v1/traces"`
+	CacheKeyDuration    time.Duration     `env:"MG_THINGS_CACHE_KEY_DURATION"  envDefault:"10m"`
+	SendTelemetry       bool              `env:"MG_SEND_TELEMETRY"             envDefault:"true"`
+	InstanceID          string            `env:"MG_THINGS_INSTANCE_ID"         envDefault:""`
+	ESURL               string            `env:"MG_ES_URL"                     envDefault:"nats://localhost:4222"`
+	CacheURL            string            `env:"MG_THINGS_CACHE_URL"           envDefault:"redis://localhost:6379/0"`
+	TraceRatio          float64           `env:"MG_JAEGER_TRACE_RATIO"         envDefault:"1.0"`
+	SpicedbHost         string            `env:"MG_SPICEDB_HOST"               envDefault:"localhost"`
+	SpicedbPort         string            `env:"MG_SPICEDB_PORT"               envDefault:"50051"`
+	SpicedbPreSharedKey string            `env:"MG_SPICEDB_PRE_SHARED_KEY"     envDefault:"12345678"`
+	HealthAuth          bool              `env:"MG_THINGS_HEALTH_AUTH"         envDefault:"false"`
+	DefaultPageSize     uint64            `env:"MG_THINGS_DEFAULT_PAGE_SIZE"  envDefault:"10"`
+	MaxPageSize         uint64            `env:"MG_THINGS_MAX_PAGE_SIZE"      envDefault:"100"`
+	CompressMinSize     int               `env:"MG_THINGS_COMPRESS_MIN_SIZE"  envDefault:"1024"`
+	ReadTimeout         time.Duration     `env:"MG_THINGS_READ_TIMEOUT"       envDefault:"30s"`
+	WriteTimeout        time.Duration     `env:"MG_THINGS_WRITE_TIMEOUT"      envDefault:"60s"`
+	MaxMetadataSize     int               `env:"MG_THINGS_MAX_METADATA_SIZE"  envDefault:"65536"`
+	DefaultChannels     map[string]string `env:"MG_THINGS_DEFAULT_CHANNELS"   envDefault:""`
+	RevealSecretOnce    bool              `env:"MG_THINGS_REVEAL_SECRET_ONCE" envDefault:"false"`
+	BulkBatchSize       int               `env:"MG_THINGS_BULK_BATCH_SIZE"    envDefault:"100"`
 }
 
 func main() {
@@ -98,7 +118,7 @@ func main() {
 	}
 
 	var logger *slog.Logger
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -121,9 +141,26 @@ func main() {
 		exitCode = 1
 		return
 	}
+	rcConfig := policies.ReconcilerConfig{}
+	if err := env.ParseWithOptions(&rcConfig, env.Options{Prefix: envPrefixPolicy}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s policy reconciler configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	quotaConfig := quotas.Config{}
+	if err := env.ParseWithOptions(&quotaConfig, env.Options{Prefix: envPrefixQuota}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s quota configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	tm := thingspg.Migration()
 	gm := gpostgres.Migration()
+	jm := jpostgres.Migration()
+	qm := qpostgres.Migration()
 	tm.Migrations = append(tm.Migrations, gm.Migrations...)
+	tm.Migrations = append(tm.Migrations, jm.Migrations...)
+	tm.Migrations = append(tm.Migrations, qm.Migrations...)
 	db, err := pgclient.Setup(dbConfig, *tm)
 	if err != nil {
 		logger.Error(err.Error())
@@ -168,7 +205,7 @@ func main() {
 		exitCode = 1
 		return
 	}
-	authn, authnClient, err := authsvcAuthn.NewAuthentication(ctx, grpcCfg)
+	authn, authnClient, err := authsvcAuthn.NewAuthentication(ctx, grpcCfg, "")
 	if err != nil {
 		logger.Error(err.Error())
 		exitCode = 1
@@ -186,21 +223,45 @@ func main() {
 	defer authzClient.Close()
 	logger.Info("AuthZ  successfully connected to auth gRPC server " + authnClient.Secure())
 
-	csvc, gsvc, err := newService(ctx, db, dbConfig, authz, policyEvaluator, policyService, cacheclient, cfg.CacheKeyDuration, cfg.ESURL, tracer, logger)
+	svcConfig := things.Config{
+		MaxMetadataSize:  cfg.MaxMetadataSize,
+		DefaultChannels:  cfg.DefaultChannels,
+		RevealSecretOnce: cfg.RevealSecretOnce,
+	}
+	csvc, gsvc, qsvc, err := newService(ctx, db, dbConfig, authz, policyEvaluator, policyService, cacheclient, cfg.CacheKeyDuration, cfg.ESURL, rcConfig, quotaConfig, svcConfig, tracer, logger)
 	if err != nil {
 		logger.Error(fmt.Sprintf("failed to create services: %s", err))
 		exitCode = 1
 		return
 	}
 
+	jobsConfig := jobs.Config{}
+	if err := env.ParseWithOptions(&jobsConfig, env.Options{Prefix: envPrefixJobs}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s jobs configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	jobRunner := newJobRunner(db, dbConfig, csvc, jobsConfig, cfg.BulkBatchSize, tracer, logger)
+
 	httpServerConfig := server.Config{Port: defSvcHTTPPort}
 	if err := env.ParseWithOptions(&httpServerConfig, env.Options{Prefix: envPrefixHTTP}); err != nil {
 		logger.Error(fmt.Sprintf("failed to load %s HTTP server configuration : %s", svcName, err))
 		exitCode = 1
 		return
 	}
+	healthOpts := []magistrala.HealthOption{
+		magistrala.WithSchemaVersion(func() (string, error) { return pgclient.SchemaVersion(db) }),
+		magistrala.WithBroker(brokers.Type),
+		magistrala.WithErrorEncoder(api.EncodeError),
+	}
+	if cfg.HealthAuth {
+		healthOpts = append(healthOpts, magistrala.WithAuthorization(api.AuthorizeHealth(authn)))
+	}
+
+	pageLimits := api.NewPageLimits(cfg.DefaultPageSize, cfg.MaxPageSize)
 	mux := chi.NewRouter()
-	httpSvc := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, httpapi.MakeHandler(csvc, gsvc, authn, mux, logger, cfg.InstanceID), logger)
+	handler := httpapi.MakeHandler(csvc, gsvc, jobRunner, qsvc, authn, mux, logger, cfg.InstanceID, pageLimits, healthOpts...)
+	httpSvc := httpserver.NewServer(ctx, cancel, svcName, httpServerConfig, api.CompressionMiddleware(cfg.CompressMinSize)(api.TimeoutMiddleware(cfg.ReadTimeout, cfg.WriteTimeout)(handler)), logger)
 
 	grpcServerConfig := server.Config{Port: defSvcAuthGRPCPort}
 	if err := env.ParseWithOptions(&grpcServerConfig, env.Options{Prefix: envPrefixGRPC}); err != nil {
@@ -228,6 +289,11 @@ func main() {
 		return gs.Start()
 	})
 
+	g.Go(func() error {
+		jobRunner.Run(ctx)
+		return nil
+	})
+
 	g.Go(func() error {
 		return server.StopSignalHandler(ctx, cancel, logger, svcName, httpSvc)
 	})
@@ -237,7 +303,7 @@ func main() {
 	}
 }
 
-func newService(ctx context.Context, db *sqlx.DB, dbConfig pgclient.Config, authz mgauthz.Authorization, pe policies.Evaluator, ps policies.Service, cacheClient *redis.Client, keyDuration time.Duration, esURL string, tracer trace.Tracer, logger *slog.Logger) (things.Service, groups.Service, error) {
+func newService(ctx context.Context, db *sqlx.DB, dbConfig pgclient.Config, authz mgauthz.Authorization, pe policies.Evaluator, ps policies.Service, cacheClient *redis.Client, keyDuration time.Duration, esURL string, rc policies.ReconcilerConfig, qc quotas.Config, svcConfig things.Config, tracer trace.Tracer, logger *slog.Logger) (things.Service, groups.Service, quotas.Service, error) {
 	database := postgres.NewDatabase(db, dbConfig, tracer)
 	cRepo := thingspg.NewRepository(database)
 	gRepo := gpostgres.New(database)
@@ -246,17 +312,26 @@ func newService(ctx context.Context, db *sqlx.DB, dbConfig pgclient.Config, auth
 
 	thingCache := thcache.NewCache(cacheClient, keyDuration)
 
-	csvc := things.NewService(pe, ps, cRepo, gRepo, thingCache, idp)
+	counters := map[string]quotas.Counter{
+		quotas.ThingsKind:   things.CountClients(cRepo),
+		quotas.ChannelsKind: mggroups.CountGroups(gRepo),
+	}
+	qsvc := quotas.NewService(qpostgres.New(database), authz, qc, counters, prometheus.MakeQuotaMetrics(svcName))
+
+	csvc := things.NewService(pe, ps, cRepo, gRepo, thingCache, idp, svcConfig)
 	gsvc := mggroups.NewService(gRepo, idp, ps)
 
+	csvc = tmiddleware.QuotasMiddleware(csvc, qsvc)
+	gsvc = gmiddleware.QuotasMiddleware(gsvc, qsvc)
+
 	csvc, err := thevents.NewEventStoreMiddleware(ctx, csvc, esURL)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	gsvc, err = gevents.NewEventStoreMiddleware(ctx, gsvc, esURL, streamID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	csvc = tmiddleware.AuthorizationMiddleware(csvc, authz)
@@ -272,7 +347,23 @@ func newService(ctx context.Context, db *sqlx.DB, dbConfig pgclient.Config, auth
 	counter, latency = prometheus.MakeMetrics(fmt.Sprintf("%s_groups", svcName), "api")
 	gsvc = gmiddleware.MetricsMiddleware(gsvc, counter, latency)
 
-	return csvc, gsvc, err
+	if rc.Interval > 0 {
+		found, removed := prometheus.MakeReconcilerMetrics(svcName)
+		reconciler := policies.NewReconciler(ps, policies.ThingType, nil, things.ExistingClients(cRepo), rc, found, removed, logger)
+		go reconciler.Run(ctx)
+	}
+
+	return csvc, gsvc, qsvc, err
+}
+
+// newJobRunner returns the runner of the jobs of the things bulk operations.
+func newJobRunner(db *sqlx.DB, dbConfig pgclient.Config, csvc things.Service, cfg jobs.Config, batchSize int, tracer trace.Tracer, logger *slog.Logger) *jobs.Runner {
+	database := postgres.NewDatabase(db, dbConfig, tracer)
+	handlers := map[string]jobs.Handler{
+		things.CreateThingsJob: things.NewCreateThingsHandler(csvc, batchSize),
+	}
+
+	return jobs.NewRunner(jpostgres.New(database), uuid.New(), handlers, cfg, logger)
 }
 
 func newSpiceDBPolicyServiceEvaluator(cfg config, logger *slog.Logger) (policies.Evaluator, policies.Service, error) {
@@ -20,6 +20,7 @@ import (
 	"github.com/absmach/magistrala/pkg/prometheus"
 	"github.com/absmach/magistrala/pkg/server"
 	httpserver "github.com/absmach/magistrala/pkg/server/http"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/absmach/magistrala/readers"
 	"github.com/absmach/magistrala/readers/api"
@@ -41,8 +42,10 @@ const (
 
 type config struct {
 	LogLevel      string `env:"MG_TIMESCALE_READER_LOG_LEVEL"    envDefault:"info"`
+	LogFormat     string `env:"MG_LOG_FORMAT"                    envDefault:"json"`
 	SendTelemetry bool   `env:"MG_SEND_TELEMETRY"                envDefault:"true"`
 	InstanceID    string `env:"MG_TIMESCALE_READER_INSTANCE_ID"  envDefault:""`
+	Units         string `env:"MG_TIMESCALE_READER_UNITS"        envDefault:""`
 }
 
 func main() {
@@ -54,7 +57,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -62,6 +65,13 @@ func main() {
 	var exitCode int
 	defer mglog.ExitWithError(&exitCode)
 
+	units, err := senml.ParseUnits(cfg.Units)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to load unit conversions: %s", err))
+		exitCode = 1
+		return
+	}
+
 	if cfg.InstanceID == "" {
 		if cfg.InstanceID, err = uuid.New().ID(); err != nil {
 			logger.Error(fmt.Sprintf("failed to generate instanceID: %s", err))
@@ -82,7 +92,7 @@ func main() {
 	}
 	defer db.Close()
 
-	repo := newService(db, logger)
+	repo := newService(db, logger, units)
 
 	authzCfg := grpcclient.Config{}
 	if err := env.ParseWithOptions(&authzCfg, env.Options{Prefix: envPrefixAuth}); err != nil {
@@ -143,8 +153,9 @@ func main() {
 	}
 }
 
-func newService(db *sqlx.DB, logger *slog.Logger) readers.MessageRepository {
+func newService(db *sqlx.DB, logger *slog.Logger, units senml.Units) readers.MessageRepository {
 	svc := timescale.New(db)
+	svc = api.UnitsMiddleware(svc, units)
 	svc = api.LoggingMiddleware(svc, logger)
 	counter, latency := prometheus.MakeMetrics("timescale", "message_reader")
 	svc = api.MetricsMiddleware(svc, counter, latency)
@@ -9,47 +9,70 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	chclient "github.com/absmach/callhome/pkg/client"
 	"github.com/absmach/magistrala"
+	redisclient "github.com/absmach/magistrala/internal/clients/redis"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/grpcclient"
 	jaegerclient "github.com/absmach/magistrala/pkg/jaeger"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/messaging/brokers"
 	brokerstracing "github.com/absmach/magistrala/pkg/messaging/brokers/tracing"
+	"github.com/absmach/magistrala/pkg/messaging/handler"
+	msgmask "github.com/absmach/magistrala/pkg/messaging/mask"
+	msgmetrics "github.com/absmach/magistrala/pkg/messaging/metrics"
+	"github.com/absmach/magistrala/pkg/messaging/timewindow"
 	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 	"github.com/absmach/magistrala/pkg/server"
 	httpserver "github.com/absmach/magistrala/pkg/server/http"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/absmach/magistrala/ws"
 	"github.com/absmach/magistrala/ws/api"
+	"github.com/absmach/magistrala/ws/events"
 	"github.com/absmach/magistrala/ws/tracing"
 	"github.com/absmach/mproxy/pkg/session"
 	"github.com/absmach/mproxy/pkg/websockets"
 	"github.com/caarlos0/env/v11"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	svcName         = "ws-adapter"
-	envPrefixHTTP   = "MG_WS_ADAPTER_HTTP_"
-	envPrefixThings = "MG_THINGS_AUTH_GRPC_"
-	defSvcHTTPPort  = "8190"
-	targetWSPort    = "8191"
-	targetWSHost    = "localhost"
+	svcName                 = "ws-adapter"
+	envPrefixHTTP           = "MG_WS_ADAPTER_HTTP_"
+	envPrefixAdapter        = "MG_WS_ADAPTER_"
+	envPrefixThings         = "MG_THINGS_AUTH_GRPC_"
+	envPrefixSubtopic       = "MG_MESSAGE_SUBTOPIC_"
+	envPrefixTopic          = "MG_MESSAGE_TOPIC_"
+	envPrefixChannelMetrics = "MG_MESSAGE_CHANNEL_METRICS_"
+	envPrefixMask           = "MG_MESSAGE_MASK_"
+	envPrefixTime           = "MG_MESSAGE_TIME_"
+	envPrefixSigning        = "MG_MESSAGE_SIGNING_"
+	envPrefixRateLimit      = "MG_WS_ADAPTER_RATE_LIMIT_"
+	defSvcHTTPPort          = "8190"
+	targetWSPort            = "8191"
+	targetWSHost            = "localhost"
 )
 
 type config struct {
-	LogLevel      string  `env:"MG_WS_ADAPTER_LOG_LEVEL"    envDefault:"info"`
-	BrokerURL     string  `env:"MG_MESSAGE_BROKER_URL"      envDefault:"nats://localhost:4222"`
-	JaegerURL     url.URL `env:"MG_JAEGER_URL"              envDefault:"http://localhost:4318/v1/traces"`
-	SendTelemetry bool    `env:"MG_SEND_TELEMETRY"          envDefault:"true"`
-	InstanceID    string  `env:"MG_WS_ADAPTER_INSTANCE_ID"  envDefault:""`
-	TraceRatio    float64 `env:"MG_JAEGER_TRACE_RATIO"      envDefault:"1.0"`
+	LogLevel          string        `env:"MG_WS_ADAPTER_LOG_LEVEL"          envDefault:"info"`
+	LogFormat         string        `env:"MG_LOG_FORMAT"                    envDefault:"json"`
+	BrokerURL         string        `env:"MG_MESSAGE_BROKER_URL"            envDefault:"nats://localhost:4222"`
+	JaegerURL         url.URL       `env:"MG_JAEGER_URL"                    envDefault:"http://localhost:4318/v1/traces"`
+	SendTelemetry     bool          `env:"MG_SEND_TELEMETRY"                envDefault:"true"`
+	InstanceID        string        `env:"MG_WS_ADAPTER_INSTANCE_ID"        envDefault:""`
+	TraceRatio        float64       `env:"MG_JAEGER_TRACE_RATIO"            envDefault:"1.0"`
+	DrainTimeout      time.Duration `env:"MG_WS_ADAPTER_DRAIN_TIMEOUT"      envDefault:"5s"`
+	ESURL             string        `env:"MG_ES_URL"                        envDefault:"nats://localhost:4222"`
+	HeartbeatInterval time.Duration `env:"MG_WS_ADAPTER_HEARTBEAT_INTERVAL" envDefault:"30s"`
 }
 
 func main() {
@@ -61,7 +84,7 @@ func main() {
 		log.Fatalf("failed to load %s configuration : %s", svcName, err)
 	}
 
-	logger, err := mglog.New(os.Stdout, cfg.LogLevel)
+	logger, err := mglog.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatalf("failed to init logger: %s", err.Error())
 	}
@@ -84,6 +107,12 @@ func main() {
 		return
 	}
 
+	if _, err := httpServerConfig.TLSConfig(); err != nil {
+		logger.Error(fmt.Sprintf("invalid %s HTTP server TLS configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	targetServerConfig := server.Config{
 		Port: targetWSPort,
 		Host: targetWSHost,
@@ -96,6 +125,57 @@ func main() {
 		return
 	}
 
+	subtopicConfig := messaging.SubtopicConfig{}
+	if err := env.ParseWithOptions(&subtopicConfig, env.Options{Prefix: envPrefixSubtopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s subtopic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	subtopics, err := messaging.NewSubtopicRules(subtopicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s subtopic rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	topicConfig := messaging.TopicConfig{}
+	if err := env.ParseWithOptions(&topicConfig, env.Options{Prefix: envPrefixTopic}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s topic configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	topics, err := messaging.NewTopicScheme(topicConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s topic scheme : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	wsConfig := ws.Config{}
+	if err := env.ParseWithOptions(&wsConfig, env.Options{Prefix: envPrefixAdapter}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s connection limits configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if err := wsConfig.Validate(); err != nil {
+		logger.Error(fmt.Sprintf("invalid %s connection limits configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
+	signingConfig := messaging.SigningConfig{}
+	if err := env.ParseWithOptions(&signingConfig, env.Options{Prefix: envPrefixSigning}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s signing configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	signing, err := messaging.NewSigningRules(signingConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s signing rules : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+
 	thingsClient, thingsHandler, err := grpcclient.SetupThingsClient(ctx, thingsClientCfg)
 	if err != nil {
 		logger.Error(err.Error())
@@ -128,25 +208,110 @@ func main() {
 	defer nps.Close()
 	nps = brokerstracing.NewPubSub(targetServerConfig, tracer, nps)
 
-	svc := newService(thingsClient, nps, logger, tracer)
+	channelMetricsConfig := msgmetrics.Config{}
+	if err := env.ParseWithOptions(&channelMetricsConfig, env.Options{Prefix: envPrefixChannelMetrics}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s channel metrics configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if channelMetricsConfig.Enabled() {
+		messages, bytes := prometheus.MakeChannelMetrics("ws_adapter")
+		nps = msgmetrics.NewPubSub(channelMetricsConfig, nps, messages, bytes)
+	}
+
+	maskConfig := msgmask.Config{}
+	if err := env.ParseWithOptions(&maskConfig, env.Options{Prefix: envPrefixMask}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message mask configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if maskConfig.Enabled() {
+		masks, err := maskConfig.Parse()
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message mask configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		nps = msgmask.NewPubSub(masks, maskConfig.ContentType, nps)
+	}
+
+	timeConfig := timewindow.Config{}
+	if err := env.ParseWithOptions(&timeConfig, env.Options{Prefix: envPrefixTime}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s message time configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if timeConfig.Enabled() {
+		if err := timeConfig.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("invalid %s message time configuration : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		nps = timewindow.NewPubSub(timeConfig, nps)
+	}
+
+	rateLimitConfig := ratelimit.Config{}
+	if err := env.ParseWithOptions(&rateLimitConfig, env.Options{Prefix: envPrefixRateLimit}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s rate limit configuration : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	var limiter ratelimit.Limiter
+	if rateLimitConfig.URL != "" {
+		limiterClient, err := redisclient.Connect(rateLimitConfig.URL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to connect to rate limit cache: %s", err))
+			exitCode = 1
+			return
+		}
+		defer limiterClient.Close()
+		if limiter, err = ratelimit.New(limiterClient, rateLimitConfig, logger); err != nil {
+			logger.Error(fmt.Sprintf("failed to create %s rate limiter : %s", svcName, err))
+			exitCode = 1
+			return
+		}
+		limited := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "ws",
+			Subsystem: "adapter",
+			Name:      "rate_limited_messages",
+			Help:      "Number of messages rejected or shed due to the publish rate of things.",
+		}, []string{})
+		limiter = ratelimit.NewMetricsLimiter(limiter, limited)
+	}
+
+	// The connections are registered per instance, by the instance ID.
+	es, err := events.NewEventStore(ctx, cfg.ESURL, cfg.InstanceID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to create %s event store : %s", svcName, err))
+		exitCode = 1
+		return
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		logger.Error(fmt.Sprintf("invalid %s heartbeat interval %s, expected positive interval", svcName, cfg.HeartbeatInterval))
+		exitCode = 1
+		return
+	}
+	go events.RunHeartbeat(ctx, es, cfg.HeartbeatInterval, logger)
+
+	svc := newService(thingsClient, nps, topics, wsConfig, logger, tracer)
 
-	hs := httpserver.NewServer(ctx, cancel, svcName, targetServerConfig, api.MakeHandler(ctx, svc, logger, cfg.InstanceID), logger)
+	hs := httpserver.NewServer(ctx, cancel, svcName, targetServerConfig, api.MakeHandler(ctx, svc, subtopics, logger, cfg.InstanceID), logger)
 
 	if cfg.SendTelemetry {
 		chc := chclient.New(svcName, magistrala.Version, logger, cancel)
 		go chc.CallHome(ctx)
 	}
 
+	drain := handler.NewDrain(ws.NewHandler(nps, es, logger, thingsClient, subtopics, topics, limiter, signing))
 	g.Go(func() error {
 		g.Go(func() error {
 			return hs.Start()
 		})
-		handler := ws.NewHandler(nps, logger, thingsClient)
-		return proxyWS(ctx, httpServerConfig, targetServerConfig, logger, handler)
+		return proxyWS(ctx, httpServerConfig, targetServerConfig, logger, drain)
 	})
 
 	g.Go(func() error {
-		return server.StopSignalHandler(ctx, cancel, logger, svcName, hs)
+		return server.StopSignalHandler(ctx, cancel, logger, svcName, drain.Stopper(cfg.DrainTimeout), hs)
 	})
 
 	if err := g.Wait(); err != nil {
@@ -154,8 +319,8 @@ func main() {
 	}
 }
 
-func newService(thingsClient magistrala.ThingsServiceClient, nps messaging.PubSub, logger *slog.Logger, tracer trace.Tracer) ws.Service {
-	svc := ws.New(thingsClient, nps)
+func newService(thingsClient magistrala.ThingsServiceClient, nps messaging.PubSub, topics messaging.TopicScheme, wsConfig ws.Config, logger *slog.Logger, tracer trace.Tracer) ws.Service {
+	svc := ws.New(thingsClient, nps, topics, wsConfig)
 	svc = tracing.New(tracer, svc)
 	svc = api.LoggingMiddleware(svc, logger)
 	counter, latency := prometheus.MakeMetrics("ws_adapter", "api")
@@ -175,8 +340,14 @@ func proxyWS(ctx context.Context, hostConfig, targetConfig server.Config, logger
 
 	go func() {
 		if hostConfig.CertFile != "" && hostConfig.KeyFile != "" {
+			tlsConfig, err := hostConfig.TLSPolicy.TLSConfig()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			srv := &http.Server{Addr: address, Handler: http.HandlerFunc(wp.Handler), TLSConfig: tlsConfig}
 			logger.Info(fmt.Sprintf("ws-adapter service http server listening at %s:%s with TLS", hostConfig.Host, hostConfig.Port))
-			errCh <- wp.ListenTLS(hostConfig.CertFile, hostConfig.KeyFile)
+			errCh <- srv.ListenAndServeTLS(hostConfig.CertFile, hostConfig.KeyFile)
 		} else {
 			logger.Info(fmt.Sprintf("ws-adapter service http server listening at %s:%s without TLS", hostConfig.Host, hostConfig.Port))
 			errCh <- wp.Listen()
@@ -13,8 +13,10 @@ import (
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 )
 
 const chansPrefix = "channels"
@@ -37,25 +39,43 @@ var _ Service = (*adapterService)(nil)
 
 // Observers is a map of maps,.
 type adapterService struct {
-	things magistrala.ThingsServiceClient
-	pubsub messaging.PubSub
+	things   magistrala.ThingsServiceClient
+	pubsub   messaging.PubSub
+	topics   messaging.TopicScheme
+	ipFilter ipfilter.Filter
+	limiter  ratelimit.Limiter
+	signing  messaging.SigningRules
 }
 
-// New instantiates the CoAP adapter implementation.
-func New(thingsClient magistrala.ThingsServiceClient, pubsub messaging.PubSub) Service {
+// New instantiates the CoAP adapter implementation. If the IP filter is not
+// nil, things can't publish or subscribe from IP addresses it rejects. If the
+// rate limiter is not nil, publishes of things over their rate are rejected or
+// shed. The payloads published to the channels requiring signatures are
+// verified with the signing secret of the thing. Messages are published to
+// and observed from the topics of the topic scheme.
+func New(thingsClient magistrala.ThingsServiceClient, pubsub messaging.PubSub, topics messaging.TopicScheme, ipFilter ipfilter.Filter, limiter ratelimit.Limiter, signing messaging.SigningRules) Service {
 	as := &adapterService{
-		things: thingsClient,
-		pubsub: pubsub,
+		things:   thingsClient,
+		pubsub:   pubsub,
+		topics:   topics,
+		ipFilter: ipFilter,
+		limiter:  limiter,
+		signing:  signing,
 	}
 
 	return as
 }
 
 func (svc *adapterService) Publish(ctx context.Context, key string, msg *messaging.Message) error {
+	if err := svc.checkIP(ctx, ""); err != nil {
+		return err
+	}
 	ar := &magistrala.ThingsAuthzReq{
-		Permission: policies.PublishPermission,
-		ThingKey:   key,
-		ChannelID:  msg.GetChannel(),
+		Permission:  policies.PublishPermission,
+		ThingKey:    key,
+		ChannelID:   msg.GetChannel(),
+		ContentType: messaging.ContentTypeFrom(ctx, ""),
+		Payload:     msg.GetPayload(),
 	}
 	res, err := svc.things.Authorize(ctx, ar)
 	if err != nil {
@@ -65,11 +85,24 @@ func (svc *adapterService) Publish(ctx context.Context, key string, msg *messagi
 		return svcerr.ErrAuthorization
 	}
 	msg.Publisher = res.GetId()
+	if err := svc.checkIP(ctx, msg.Publisher); err != nil {
+		return err
+	}
+	allowed, err := messaging.CheckRate(ctx, svc.limiter, res)
+	if err != nil || !allowed {
+		return err
+	}
+	if err := svc.signing.Check(msg, res.GetSigningSecret(), messaging.SignatureFrom(ctx, "")); err != nil {
+		return err
+	}
 
-	return svc.pubsub.Publish(ctx, msg.GetChannel(), msg)
+	return svc.pubsub.Publish(ctx, svc.topics.Topic(res.GetDomainId(), msg.GetChannel()), msg)
 }
 
 func (svc *adapterService) Subscribe(ctx context.Context, key, chanID, subtopic string, c Client) error {
+	if err := svc.checkIP(ctx, ""); err != nil {
+		return err
+	}
 	ar := &magistrala.ThingsAuthzReq{
 		Permission: policies.SubscribePermission,
 		ThingKey:   key,
@@ -82,13 +115,12 @@ func (svc *adapterService) Subscribe(ctx context.Context, key, chanID, subtopic
 	if !res.GetAuthorized() {
 		return svcerr.ErrAuthorization
 	}
-	subject := fmt.Sprintf("%s.%s", chansPrefix, chanID)
-	if subtopic != "" {
-		subject = fmt.Sprintf("%s.%s", subject, subtopic)
+	if err := svc.checkIP(ctx, res.GetId()); err != nil {
+		return err
 	}
 	subCfg := messaging.SubscriberConfig{
 		ID:      c.Token(),
-		Topic:   subject,
+		Topic:   channelSubject(svc.topics.Topic(res.GetDomainId(), chanID), subtopic),
 		Handler: c,
 	}
 	return svc.pubsub.Subscribe(ctx, subCfg)
@@ -107,10 +139,30 @@ func (svc *adapterService) Unsubscribe(ctx context.Context, key, chanID, subtopi
 	if !res.GetAuthorized() {
 		return svcerr.ErrAuthorization
 	}
-	subject := fmt.Sprintf("%s.%s", chansPrefix, chanID)
+	subject := channelSubject(svc.topics.Topic(res.GetDomainId(), chanID), subtopic)
+
+	return svc.pubsub.Unsubscribe(ctx, token, subject)
+}
+
+func channelSubject(topic, subtopic string) string {
+	subject := fmt.Sprintf("%s.%s", chansPrefix, topic)
 	if subtopic != "" {
 		subject = fmt.Sprintf("%s.%s", subject, subtopic)
 	}
 
-	return svc.pubsub.Unsubscribe(ctx, token, subject)
+	return subject
+}
+
+// checkIP checks the remote IP address carried by the context. Empty thing
+// ID checks only the default rules, so denied addresses can be rejected
+// before authorization.
+func (svc *adapterService) checkIP(ctx context.Context, thingID string) error {
+	if svc.ipFilter == nil {
+		return nil
+	}
+	if err := svc.ipFilter.Check(ipfilter.RemoteIP(ctx), thingID); err != nil {
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+
+	return nil
 }
@@ -18,7 +18,10 @@ import (
 	"github.com/absmach/magistrala/coap"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
 	"github.com/go-chi/chi/v5"
 	"github.com/plgd-dev/go-coap/v3/message"
 	"github.com/plgd-dev/go-coap/v3/message/codes"
@@ -28,9 +31,10 @@ import (
 )
 
 const (
-	protocol     = "coap"
-	authQuery    = "auth"
-	startObserve = 0 // observe option value that indicates start of observation
+	protocol       = "coap"
+	authQuery      = "auth"
+	signatureQuery = "signature"
+	startObserve   = 0 // observe option value that indicates start of observation
 )
 
 var channelPartRegExp = regexp.MustCompile(`^/channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
@@ -47,8 +51,9 @@ var (
 )
 
 var (
-	logger  *slog.Logger
-	service coap.Service
+	logger    *slog.Logger
+	service   coap.Service
+	subtopics messaging.SubtopicRules
 )
 
 // MakeHandler returns a HTTP handler for API endpoints.
@@ -61,9 +66,10 @@ func MakeHandler(instanceID string) http.Handler {
 }
 
 // MakeCoAPHandler creates handler for CoAP messages.
-func MakeCoAPHandler(svc coap.Service, l *slog.Logger) mux.HandlerFunc {
+func MakeCoAPHandler(svc coap.Service, rules messaging.SubtopicRules, l *slog.Logger) mux.HandlerFunc {
 	logger = l
 	service = svc
+	subtopics = rules
 
 	return handler
 }
@@ -95,13 +101,30 @@ func handler(w mux.ResponseWriter, m *mux.Message) {
 		return
 	}
 
+	// Observed subtopics are normalized like the published ones, so the
+	// observers keep matching the normalized messages.
+	if msg.Subtopic, err = subtopics.Apply(msg.GetSubtopic()); err != nil {
+		logger.Warn(fmt.Sprintf("Error applying subtopic rules: %s", err))
+		resp.SetCode(codes.BadRequest)
+		return
+	}
+
+	ip := ipfilter.AddrIP(w.Conn().RemoteAddr().String())
+
 	switch m.Code() {
 	case codes.GET:
 		resp.SetCode(codes.Content)
-		err = handleGet(m, w, msg, key)
+		err = handleGet(m, w, msg, key, ip)
 	case codes.POST:
 		resp.SetCode(codes.Created)
-		err = service.Publish(m.Context(), key, msg)
+		ctx := ipfilter.WithRemoteIP(m.Context(), ip)
+		if cf, err := m.Options().ContentFormat(); err == nil {
+			ctx = messaging.WithContentType(ctx, cf.String())
+		}
+		if sig := parseSignature(m); sig != "" {
+			ctx = messaging.WithSignature(ctx, sig)
+		}
+		err = service.Publish(ctx, key, msg)
 	default:
 		err = errMethodNotAllowed
 	}
@@ -116,13 +139,19 @@ func handler(w mux.ResponseWriter, m *mux.Message) {
 			resp.SetCode(codes.Forbidden)
 		case errors.Contains(err, svcerr.ErrAuthentication):
 			resp.SetCode(codes.Unauthorized)
+		case errors.Contains(err, ratelimit.ErrRateLimited):
+			resp.SetCode(codes.TooManyRequests)
+		case errors.Contains(err, senml.ErrTimeOutOfRange),
+			errors.Contains(err, messaging.ErrMissingSignature),
+			errors.Contains(err, messaging.ErrInvalidSignature):
+			resp.SetCode(codes.BadRequest)
 		default:
 			resp.SetCode(codes.InternalServerError)
 		}
 	}
 }
 
-func handleGet(m *mux.Message, w mux.ResponseWriter, msg *messaging.Message, key string) error {
+func handleGet(m *mux.Message, w mux.ResponseWriter, msg *messaging.Message, key, ip string) error {
 	var obs uint32
 	obs, err := m.Options().Observe()
 	if err != nil {
@@ -145,7 +174,7 @@ func handleGet(m *mux.Message, w mux.ResponseWriter, msg *messaging.Message, key
 			}
 			logger.Warn("Unsubscribe idle client completed successfully", args...)
 		})
-		return service.Subscribe(w.Conn().Context(), key, msg.GetChannel(), msg.GetSubtopic(), c)
+		return service.Subscribe(ipfilter.WithRemoteIP(w.Conn().Context(), ip), key, msg.GetChannel(), msg.GetSubtopic(), c)
 	}
 	return service.Unsubscribe(w.Conn().Context(), key, msg.GetChannel(), msg.GetSubtopic(), m.Token().String())
 }
@@ -197,6 +226,22 @@ func parseKey(msg *mux.Message) (string, error) {
 	return vars[1], nil
 }
 
+// parseSignature returns the payload signature set in the signature URI
+// query option of the request.
+func parseSignature(msg *mux.Message) string {
+	queries, err := msg.Options().Queries()
+	if err != nil {
+		return ""
+	}
+	for _, q := range queries {
+		if sig, ok := strings.CutPrefix(q, signatureQuery+"="); ok {
+			return sig
+		}
+	}
+
+	return ""
+}
+
 func parseSubtopic(subtopic string) (string, error) {
 	if subtopic == "" {
 		return subtopic, nil
@@ -0,0 +1,202 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	defAlertWindow    = time.Minute
+	defAlertErrorRate = 0.5
+	defAlertMinWrites = 1
+	alertTimeout      = 10 * time.Second
+
+	// AlertFiring is the status of the alert sent once the writes start failing.
+	AlertFiring = "firing"
+	// AlertResolved is the status of the alert sent once the writes recover.
+	AlertResolved = "resolved"
+)
+
+var (
+	errInvalidAlertConfig = errors.New("invalid alert configuration")
+	errSendAlert          = errors.New("failed to send alert")
+)
+
+var _ BlockingConsumer = (*alerter)(nil)
+
+// Alert is the payload of the alert posted to the webhook.
+type Alert struct {
+	Status    string    `json:"status"`
+	Consumer  string    `json:"consumer"`
+	ErrorRate float64   `json:"error_rate"`
+	Failed    int       `json:"failed"`
+	Total     int       `json:"total"`
+	Window    string    `json:"window"`
+	LastError string    `json:"last_error,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+type alertConfig struct {
+	URL       string        `toml:"url"`
+	Window    time.Duration `toml:"window"`
+	ErrorRate float64       `toml:"error_rate"`
+	MinWrites int           `toml:"min_writes"`
+}
+
+func (ac *alertConfig) validate() error {
+	if ac.Window <= 0 {
+		ac.Window = defAlertWindow
+	}
+	if ac.ErrorRate == 0 {
+		ac.ErrorRate = defAlertErrorRate
+	}
+	if ac.MinWrites <= 0 {
+		ac.MinWrites = defAlertMinWrites
+	}
+	if ac.ErrorRate < 0 || ac.ErrorRate > 1 {
+		return errors.Wrap(errInvalidAlertConfig, fmt.Errorf("error rate %v out of [0, 1]", ac.ErrorRate))
+	}
+
+	return nil
+}
+
+// alerter counts the failed writes of the consumer, and alerts once the
+// error rate over a window reaches the threshold. The alert is sent only
+// when the state changes, so a sustained failure sends a single alert, and
+// a single resolve once the writes recover. Windows without writes don't
+// change the state.
+type alerter struct {
+	consumer BlockingConsumer
+	id       string
+	cfg      alertConfig
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	total   int
+	failed  int
+	lastErr error
+	firing  bool
+}
+
+func newAlerter(ctx context.Context, id string, consumer BlockingConsumer, cfg alertConfig, logger *slog.Logger) *alerter {
+	a := &alerter{
+		consumer: consumer,
+		id:       id,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: alertTimeout},
+		logger:   logger,
+	}
+	go a.watch(ctx)
+
+	return a
+}
+
+func (a *alerter) ConsumeBlocking(ctx context.Context, messages interface{}) error {
+	err := a.consumer.ConsumeBlocking(ctx, messages)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total++
+	if err != nil {
+		a.failed++
+		a.lastErr = err
+	}
+
+	return err
+}
+
+func (a *alerter) watch(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			alert, ok := a.evaluate()
+			if !ok {
+				continue
+			}
+			if err := a.send(ctx, alert); err != nil {
+				a.logger.Warn(fmt.Sprintf("Failed to send %s alert: %s", alert.Status, err))
+				continue
+			}
+			// The state changes only once the alert is sent, so the alert
+			// is sent again after the next window if sending failed.
+			a.mu.Lock()
+			a.firing = alert.Status == AlertFiring
+			a.mu.Unlock()
+			a.logger.Info(fmt.Sprintf("Sent %s alert of %d failed out of %d writes", alert.Status, alert.Failed, alert.Total))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// evaluate closes the window, returning the alert if the state changed.
+func (a *alerter) evaluate() (Alert, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	total, failed, lastErr := a.total, a.failed, a.lastErr
+	a.total, a.failed, a.lastErr = 0, 0, nil
+	if total == 0 {
+		return Alert{}, false
+	}
+
+	rate := float64(failed) / float64(total)
+	firing := failed > 0 && total >= a.cfg.MinWrites && rate >= a.cfg.ErrorRate
+	if firing == a.firing {
+		return Alert{}, false
+	}
+
+	alert := Alert{
+		Status:    AlertResolved,
+		Consumer:  a.id,
+		ErrorRate: rate,
+		Failed:    failed,
+		Total:     total,
+		Window:    a.cfg.Window.String(),
+		Time:      time.Now(),
+	}
+	if firing {
+		alert.Status = AlertFiring
+		alert.LastError = lastErr.Error()
+	}
+
+	return alert, true
+}
+
+func (a *alerter) send(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(errSendAlert, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(errSendAlert, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return errors.Wrap(errSendAlert, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Wrap(errSendAlert, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/transformers"
+	"github.com/absmach/magistrala/pkg/transformers/json"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+)
+
+var (
+	errUnsupportedBatch = errors.New("unsupported message type for batching")
+	errBatchFull        = errors.New("batch is full of messages which failed to be consumed")
+)
+
+var (
+	_ flusher              = (*batchHandler)(nil)
+	_ messaging.AckHandler = (*batchHandler)(nil)
+)
+
+// flusher is a message handler which holds the handled messages until
+// they are flushed.
+type flusher interface {
+	messaging.MessageHandler
+
+	// Flush consumes the held messages.
+	Flush(ctx context.Context) error
+}
+
+// batchEntry is a transformed message held in the batch.
+type batchEntry struct {
+	senml []senml.Message
+	json  json.Messages
+	count int
+	done  func(err error)
+}
+
+// batchHandler transforms the messages and consumes them in batches of
+// up to size messages. It is not safe for concurrent use, so each worker
+// uses its own batch handler.
+type batchHandler struct {
+	ctx         context.Context
+	transformer transformers.Transformer
+	consumer    BlockingConsumer
+	size        int
+	count       int
+	entries     []batchEntry
+}
+
+func newBatchHandler(ctx context.Context, t transformers.Transformer, bc BlockingConsumer, size int) *batchHandler {
+	return &batchHandler{
+		ctx:         ctx,
+		transformer: t,
+		consumer:    bc,
+		size:        size,
+	}
+}
+
+// Handle adds the transformed message to the batch, and consumes the batch
+// once it is full.
+func (bh *batchHandler) Handle(msg *messaging.Message) error {
+	e, err := bh.transform(msg)
+	if err != nil {
+		return err
+	}
+	if bh.count >= bh.size {
+		return errBatchFull
+	}
+	bh.add(e)
+	if bh.count < bh.size {
+		return nil
+	}
+
+	return bh.Flush(bh.ctx)
+}
+
+// HandleAck adds the transformed message to the batch, and consumes the
+// batch once it is full. The message is acknowledged only once the batch
+// holding it is consumed.
+func (bh *batchHandler) HandleAck(msg *messaging.Message, done func(err error)) {
+	e, err := bh.transform(msg)
+	if err != nil {
+		done(err)
+		return
+	}
+	if bh.count >= bh.size {
+		done(errBatchFull)
+		return
+	}
+	e.done = done
+	bh.add(e)
+	if bh.count < bh.size {
+		return
+	}
+	// The result is reported to the held messages.
+	_ = bh.Flush(bh.ctx)
+}
+
+func (bh *batchHandler) Cancel() error {
+	return nil
+}
+
+// Flush consumes the batch and acknowledges its messages. If consuming
+// fails, the messages of the subscribers with acknowledgments are returned
+// to the broker for redelivery, and the other messages are kept in the
+// batch, so they are consumed by the next flush. The batch doesn't grow
+// beyond its size meanwhile, the messages are rejected instead.
+func (bh *batchHandler) Flush(ctx context.Context) error {
+	if len(bh.entries) == 0 {
+		return nil
+	}
+	var sm []senml.Message
+	jm := make(map[string][]json.Message)
+	for _, e := range bh.entries {
+		sm = append(sm, e.senml...)
+		if len(e.json.Data) > 0 {
+			jm[e.json.Format] = append(jm[e.json.Format], e.json.Data...)
+		}
+	}
+
+	var err error
+	if len(sm) > 0 {
+		if e := bh.consumer.ConsumeBlocking(ctx, sm); e != nil {
+			err = errors.Wrap(e, err)
+		}
+	}
+	for format, data := range jm {
+		if e := bh.consumer.ConsumeBlocking(ctx, json.Messages{Data: data, Format: format}); e != nil {
+			err = errors.Wrap(e, err)
+		}
+	}
+
+	kept := bh.entries[:0]
+	bh.count = 0
+	for _, e := range bh.entries {
+		switch {
+		case e.done != nil:
+			e.done(err)
+		case err != nil:
+			kept = append(kept, e)
+			bh.count += e.count
+		}
+	}
+	clear(bh.entries[len(kept):])
+	bh.entries = kept
+
+	return err
+}
+
+func (bh *batchHandler) transform(msg *messaging.Message) (batchEntry, error) {
+	m, err := bh.transformer.Transform(msg)
+	if err != nil {
+		return batchEntry{}, errors.Wrap(errTransform, err)
+	}
+	switch m := m.(type) {
+	case []senml.Message:
+		return batchEntry{senml: m, count: len(m)}, nil
+	case json.Messages:
+		return batchEntry{json: m, count: len(m.Data)}, nil
+	default:
+		return batchEntry{}, errors.Wrap(errUnsupportedBatch, fmt.Errorf("%T", m))
+	}
+}
+
+func (bh *batchHandler) add(e batchEntry) {
+	bh.entries = append(bh.entries, e)
+	bh.count += e.count
+}
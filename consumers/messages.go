@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/absmach/magistrala/pkg/apiutil"
 	"github.com/absmach/magistrala/pkg/errors"
@@ -21,13 +22,15 @@ import (
 )
 
 const (
-	defContentType = "application/senml+json"
-	defFormat      = "senml"
+	defContentType   = "application/senml+json"
+	defFormat        = "senml"
+	defFlushInterval = time.Second
 )
 
 var (
 	errOpenConfFile  = errors.New("unable to open configuration file")
 	errParseConfFile = errors.New("unable to parse configuration file")
+	errTransform     = errors.New("unable to transform message")
 )
 
 // Start method starts consuming messages received from Message broker.
@@ -40,6 +43,18 @@ func Start(ctx context.Context, id string, sub messaging.Subscriber, consumer in
 	}
 
 	transformer := makeTransformer(cfg.TransformerCfg, logger)
+	batched := cfg.SubscriberCfg.BatchSize > 1 && transformer != nil
+	if batched && cfg.SubscriberCfg.FlushInterval <= 0 {
+		cfg.SubscriberCfg.FlushInterval = defFlushInterval
+	}
+	if bc, ok := consumer.(BlockingConsumer); ok && cfg.AlertCfg.URL != "" {
+		if _, async := consumer.(AsyncConsumer); !async {
+			if err := cfg.AlertCfg.validate(); err != nil {
+				return err
+			}
+			consumer = newAlerter(ctx, id, bc, cfg.AlertCfg, logger)
+		}
+	}
 
 	for _, subject := range cfg.SubscriberCfg.Subjects {
 		subCfg := messaging.SubscriberConfig{
@@ -47,20 +62,30 @@ func Start(ctx context.Context, id string, sub messaging.Subscriber, consumer in
 			Topic:          subject,
 			DeliveryPolicy: messaging.DeliverAllPolicy,
 		}
+		var newHandler func() messaging.MessageHandler
 		switch c := consumer.(type) {
 		case AsyncConsumer:
-			subCfg.Handler = handleAsync(ctx, transformer, c)
-			if err := sub.Subscribe(ctx, subCfg); err != nil {
-				return err
-			}
+			newHandler = func() messaging.MessageHandler { return handleAsync(ctx, transformer, c) }
 		case BlockingConsumer:
-			subCfg.Handler = handleSync(ctx, transformer, c)
-			if err := sub.Subscribe(ctx, subCfg); err != nil {
-				return err
+			newHandler = func() messaging.MessageHandler { return handleSync(ctx, transformer, c) }
+			if batched {
+				newHandler = func() messaging.MessageHandler {
+					return newBatchHandler(ctx, transformer, c, cfg.SubscriberCfg.BatchSize)
+				}
 			}
 		default:
 			return apiutil.ErrInvalidQueryParams
 		}
+		switch {
+		case cfg.SubscriberCfg.Workers > 1, batched:
+			workers := max(cfg.SubscriberCfg.Workers, 1)
+			subCfg.Handler = newWorkerPool(ctx, newHandler, workers, cfg.SubscriberCfg.Ordered, cfg.SubscriberCfg.FlushInterval, logger)
+		default:
+			subCfg.Handler = newHandler()
+		}
+		if err := sub.Subscribe(ctx, subCfg); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -72,7 +97,7 @@ func handleSync(ctx context.Context, t transformers.Transformer, sc BlockingCons
 		if t != nil {
 			m, err = t.Transform(msg)
 			if err != nil {
-				return err
+				return errors.Wrap(errTransform, err)
 			}
 		}
 		return sc.ConsumeBlocking(ctx, m)
@@ -86,7 +111,7 @@ func handleAsync(ctx context.Context, t transformers.Transformer, ac AsyncConsum
 		if t != nil {
 			m, err = t.Transform(msg)
 			if err != nil {
-				return err
+				return errors.Wrap(errTransform, err)
 			}
 		}
 
@@ -106,18 +131,28 @@ func (h handleFunc) Cancel() error {
 }
 
 type subscriberConfig struct {
-	Subjects []string `toml:"subjects"`
+	Subjects      []string      `toml:"subjects"`
+	Workers       int           `toml:"workers"`
+	Ordered       bool          `toml:"ordered"`
+	BatchSize     int           `toml:"batch_size"`
+	FlushInterval time.Duration `toml:"flush_interval"`
 }
 
 type transformerConfig struct {
 	Format      string           `toml:"format"`
 	ContentType string           `toml:"content_type"`
 	TimeFields  []json.TimeField `toml:"time_fields"`
+	Time        senml.TimeConfig `toml:"time"`
+	Units       senml.Units      `toml:"units"`
+	Geo         senml.GeoConfig  `toml:"geo"`
+	Computed    senml.Computed   `toml:"computed"`
+	Mask        senml.Masks      `toml:"mask"`
 }
 
 type config struct {
 	SubscriberCfg  subscriberConfig  `toml:"subscriber"`
 	TransformerCfg transformerConfig `toml:"transformer"`
+	AlertCfg       alertConfig       `toml:"alert"`
 }
 
 func loadConfig(configPath string) (config, error) {
@@ -147,7 +182,27 @@ func makeTransformer(cfg transformerConfig, logger *slog.Logger) transformers.Tr
 	switch strings.ToUpper(cfg.Format) {
 	case "SENML":
 		logger.Info("Using SenML transformer")
-		return senml.New(cfg.ContentType)
+		if err := cfg.Time.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("Can't create transformer: %s", err))
+			os.Exit(1)
+			return nil
+		}
+		if err := cfg.Units.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("Can't create transformer: %s", err))
+			os.Exit(1)
+			return nil
+		}
+		if err := cfg.Computed.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("Can't create transformer: %s", err))
+			os.Exit(1)
+			return nil
+		}
+		if err := cfg.Mask.Validate(); err != nil {
+			logger.Error(fmt.Sprintf("Can't create transformer: %s", err))
+			os.Exit(1)
+			return nil
+		}
+		return senml.New(cfg.ContentType, cfg.Time, cfg.Units, cfg.Geo, cfg.Computed, cfg.Mask)
 	case "JSON":
 		logger.Info("Using JSON transformer")
 		return json.New(cfg.TimeFields)
@@ -0,0 +1,564 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/consumers"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const configTemplate = `
+[subscriber]
+subjects = ["channels.>"]
+workers = %d
+ordered = %t
+batch_size = %d
+flush_interval = "%s"
+
+[transformer]
+format = "senml"
+content_type = "application/senml+json"
+`
+
+var _ consumers.BlockingConsumer = (*recorder)(nil)
+
+// recorder stores received values by publisher with random latency.
+type recorder struct {
+	mu     sync.Mutex
+	values map[string][]float64
+	count  int
+	total  int
+	done   chan struct{}
+}
+
+func newRecorder(total int) *recorder {
+	return &recorder{
+		values: make(map[string][]float64),
+		total:  total,
+		done:   make(chan struct{}),
+	}
+}
+
+func (r *recorder) ConsumeBlocking(_ context.Context, messages interface{}) error {
+	time.Sleep(time.Duration(rand.Intn(100)) * time.Microsecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range messages.([]senml.Message) {
+		r.values[msg.Publisher] = append(r.values[msg.Publisher], *msg.Value)
+		r.count++
+	}
+	if r.count == r.total {
+		close(r.done)
+	}
+
+	return nil
+}
+
+func TestStartOrdering(t *testing.T) {
+	publishers := []string{"publisher-1", "publisher-2", "publisher-3"}
+	numMsgs := 500
+
+	cases := []struct {
+		desc      string
+		workers   int
+		ordered   bool
+		batchSize int
+	}{
+		{
+			desc:    "consume messages with a single worker",
+			workers: 1,
+			ordered: false,
+		},
+		{
+			desc:    "consume messages with workers ordered by publisher",
+			workers: 4,
+			ordered: true,
+		},
+		{
+			desc:      "consume message batches with workers ordered by publisher",
+			workers:   4,
+			ordered:   true,
+			batchSize: 7,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rec := newRecorder(numMsgs * len(publishers))
+			handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, tc.workers, tc.ordered, tc.batchSize, time.Millisecond), rec)
+
+			for i := 0; i < numMsgs; i++ {
+				for _, pub := range publishers {
+					msg := messaging.Message{
+						Channel:   "channel",
+						Publisher: pub,
+						Protocol:  "mqtt",
+						Payload:   []byte(fmt.Sprintf(`[{"n":"seq","v":%d}]`, i)),
+						Created:   time.Now().UnixNano(),
+					}
+					err := handler.Handle(&msg)
+					assert.Nil(t, err, fmt.Sprintf("handling message expected to succeed: %s", err))
+				}
+			}
+
+			select {
+			case <-rec.done:
+			case <-time.After(10 * time.Second):
+				t.Fatalf("%s: timed out waiting for messages to be consumed", tc.desc)
+			}
+
+			expected := make([]float64, numMsgs)
+			for i := range expected {
+				expected[i] = float64(i)
+			}
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			for _, pub := range publishers {
+				assert.Equal(t, expected, rec.values[pub], fmt.Sprintf("%s: expected messages of %s to be stored in publish order", tc.desc, pub))
+			}
+		})
+	}
+}
+
+func startConsumer(ctx context.Context, t *testing.T, config string, consumer interface{}) messaging.MessageHandler {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte(config), 0o600)
+	require.Nil(t, err, fmt.Sprintf("writing config file expected to succeed: %s", err))
+
+	var handler messaging.MessageHandler
+	pubsub := new(mocks.PubSub)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		handler = args.Get(1).(messaging.SubscriberConfig).Handler
+	}).Return(nil)
+
+	err = consumers.Start(ctx, "consumer", pubsub, consumer, path, mglog.NewMock())
+	require.Nil(t, err, fmt.Sprintf("starting consumer expected to succeed: %s", err))
+	require.NotNil(t, handler, "subscription handler expected to be set")
+
+	return handler
+}
+
+func senmlMessage(publisher string, value int) *messaging.Message {
+	return &messaging.Message{
+		Channel:   "channel",
+		Publisher: publisher,
+		Protocol:  "mqtt",
+		Payload:   []byte(fmt.Sprintf(`[{"n":"seq","v":%d}]`, value)),
+		Created:   time.Now().UnixNano(),
+	}
+}
+
+var _ consumers.BlockingConsumer = (*batchRecorder)(nil)
+
+// batchRecorder records the consumed batches and the maximum number of
+// concurrent writes. The writes fail while failing is set.
+type batchRecorder struct {
+	failing  atomic.Bool
+	mu       sync.Mutex
+	batches  [][]senml.Message
+	count    int
+	active   int
+	maxSlots int
+	delay    time.Duration
+}
+
+func (r *batchRecorder) ConsumeBlocking(_ context.Context, messages interface{}) error {
+	if r.failing.Load() {
+		return errWrite
+	}
+
+	r.mu.Lock()
+	r.active++
+	r.maxSlots = max(r.maxSlots, r.active)
+	r.mu.Unlock()
+
+	time.Sleep(r.delay)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active--
+	msgs := messages.([]senml.Message)
+	r.batches = append(r.batches, msgs)
+	r.count += len(msgs)
+
+	return nil
+}
+
+func (r *batchRecorder) consumed() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.count, len(r.batches)
+}
+
+func TestStartConcurrencyLimit(t *testing.T) {
+	numMsgs := 300
+
+	cases := []struct {
+		desc      string
+		workers   int
+		ordered   bool
+		batchSize int
+	}{
+		{
+			desc:    "flood of messages with workers",
+			workers: 3,
+		},
+		{
+			desc:    "flood of messages with ordered workers",
+			workers: 3,
+			ordered: true,
+		},
+		{
+			desc:      "flood of messages with batching workers",
+			workers:   3,
+			batchSize: 10,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rec := &batchRecorder{delay: time.Millisecond}
+			handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, tc.workers, tc.ordered, tc.batchSize, time.Millisecond), rec)
+
+			var wg sync.WaitGroup
+			for i := 0; i < numMsgs; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					err := handler.Handle(senmlMessage(fmt.Sprintf("publisher-%d", i%10), i))
+					assert.Nil(t, err, fmt.Sprintf("handling message expected to succeed: %s", err))
+				}(i)
+			}
+			wg.Wait()
+
+			assert.Eventually(t, func() bool {
+				count, _ := rec.consumed()
+				return count == numMsgs
+			}, 10*time.Second, time.Millisecond, fmt.Sprintf("%s: expected all messages to be consumed", tc.desc))
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			assert.LessOrEqual(t, rec.maxSlots, tc.workers, fmt.Sprintf("%s: expected at most %d concurrent writes got %d", tc.desc, tc.workers, rec.maxSlots))
+		})
+	}
+}
+
+func TestStartBatchFlush(t *testing.T) {
+	cases := []struct {
+		desc          string
+		batchSize     int
+		flushInterval time.Duration
+		messages      int
+		count         int
+		batches       int
+	}{
+		{
+			desc:          "flush full batches",
+			batchSize:     10,
+			flushInterval: time.Hour,
+			messages:      25,
+			count:         20,
+			batches:       2,
+		},
+		{
+			desc:          "flush incomplete batch on interval",
+			batchSize:     100,
+			flushInterval: 50 * time.Millisecond,
+			messages:      25,
+			count:         25,
+			batches:       1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rec := &batchRecorder{}
+			handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, 1, false, tc.batchSize, tc.flushInterval), rec)
+
+			for i := 0; i < tc.messages; i++ {
+				err := handler.Handle(senmlMessage("publisher", i))
+				assert.Nil(t, err, fmt.Sprintf("handling message expected to succeed: %s", err))
+			}
+
+			assert.Eventually(t, func() bool {
+				count, _ := rec.consumed()
+				return count == tc.count
+			}, 5*time.Second, time.Millisecond, fmt.Sprintf("%s: expected %d messages to be consumed", tc.desc, tc.count))
+			// Incomplete batches must not be flushed before the interval.
+			time.Sleep(100 * time.Millisecond)
+			count, batches := rec.consumed()
+			assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d consumed messages got %d", tc.desc, tc.count, count))
+			assert.Equal(t, tc.batches, batches, fmt.Sprintf("%s: expected %d batches got %d", tc.desc, tc.batches, batches))
+		})
+	}
+}
+
+func TestStartBatchAcknowledgment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &batchRecorder{}
+	handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, 1, false, 2, time.Hour), rec)
+	ah, ok := handler.(messaging.AckHandler)
+	require.True(t, ok, "worker pool expected to acknowledge messages")
+
+	done := make(chan error, 2)
+	ack := func(err error) { done <- err }
+
+	// The held message is acknowledged only once its batch is consumed.
+	ah.HandleAck(senmlMessage("publisher", 1), ack)
+	select {
+	case err := <-done:
+		t.Fatalf("expected the held message not to be acknowledged got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	ah.HandleAck(senmlMessage("publisher", 2), ack)
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			assert.Nil(t, err, fmt.Sprintf("expected the consumed message to be acknowledged got %s", err))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the message to be acknowledged")
+		}
+	}
+
+	// The messages of a failed batch are returned for redelivery.
+	rec.failing.Store(true)
+	ah.HandleAck(senmlMessage("publisher", 3), ack)
+	ah.HandleAck(senmlMessage("publisher", 4), ack)
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-done:
+			assert.True(t, errors.Contains(err, errWrite), fmt.Sprintf("expected the message of the failed batch to be rejected with %s got %s", errWrite, err))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the message to be rejected")
+		}
+	}
+}
+
+func TestStartBatchRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &batchRecorder{}
+	rec.failing.Store(true)
+	handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, 1, false, 2, 50*time.Millisecond), rec)
+
+	for i := 0; i < 2; i++ {
+		err := handler.Handle(senmlMessage("publisher", i))
+		assert.Nil(t, err, fmt.Sprintf("handling message expected to succeed: %s", err))
+	}
+	time.Sleep(100 * time.Millisecond)
+	count, _ := rec.consumed()
+	assert.Equal(t, 0, count, fmt.Sprintf("expected no consumed messages while the writes fail got %d", count))
+
+	// The batch is kept and consumed once the writes succeed.
+	rec.failing.Store(false)
+	assert.Eventually(t, func() bool {
+		count, _ := rec.consumed()
+		return count == 2
+	}, 5*time.Second, time.Millisecond, "expected the kept batch to be consumed")
+}
+
+func TestStartBatchDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rec := &batchRecorder{delay: 10 * time.Millisecond}
+	handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, 1, false, 100, time.Hour), rec)
+
+	numMsgs := 50
+	for i := 0; i < numMsgs; i++ {
+		err := handler.Handle(senmlMessage("publisher", i))
+		assert.Nil(t, err, fmt.Sprintf("handling message expected to succeed: %s", err))
+	}
+	cancel()
+
+	// The queued and the held messages are consumed on shutdown.
+	assert.Eventually(t, func() bool {
+		count, _ := rec.consumed()
+		return count == numMsgs
+	}, 5*time.Second, time.Millisecond, fmt.Sprintf("expected %d messages to be consumed on shutdown", numMsgs))
+}
+
+const alertConfigTemplate = `
+[subscriber]
+subjects = ["channels.>"]
+
+[transformer]
+format = "senml"
+content_type = "application/senml+json"
+
+[alert]
+url = "%s"
+window = "%s"
+error_rate = 0.5
+`
+
+var errWrite = errors.New("write failed")
+
+var _ consumers.BlockingConsumer = (*failingConsumer)(nil)
+
+// failingConsumer fails the writes while failing is set.
+type failingConsumer struct {
+	failing atomic.Bool
+}
+
+func (c *failingConsumer) ConsumeBlocking(_ context.Context, _ interface{}) error {
+	if c.failing.Load() {
+		return errWrite
+	}
+
+	return nil
+}
+
+// alertRecorder records the alerts posted to the webhook.
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []consumers.Alert
+}
+
+func (r *alertRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var alert consumers.Alert
+	if err := json.NewDecoder(req.Body).Decode(&alert); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.alerts = append(r.alerts, alert)
+	r.mu.Unlock()
+}
+
+func (r *alertRecorder) statuses() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var statuses []string
+	for _, alert := range r.alerts {
+		statuses = append(statuses, alert.Status)
+	}
+
+	return statuses
+}
+
+func TestStartAcknowledgment(t *testing.T) {
+	cases := []struct {
+		desc    string
+		failing bool
+		payload string
+		err     error
+	}{
+		{
+			desc:    "acknowledge written message",
+			payload: `[{"n":"temperature","v":1}]`,
+		},
+		{
+			desc:    "reject message on failed write",
+			failing: true,
+			payload: `[{"n":"temperature","v":1}]`,
+			err:     errWrite,
+		},
+		{
+			desc:    "acknowledge message which can't be transformed",
+			failing: true,
+			payload: `invalid`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			c := &failingConsumer{}
+			c.failing.Store(tc.failing)
+			handler := startConsumer(ctx, t, fmt.Sprintf(configTemplate, 2, true, 0, time.Millisecond), c)
+			ah, ok := handler.(messaging.AckHandler)
+			require.True(t, ok, "worker pool expected to acknowledge messages")
+
+			done := make(chan error, 1)
+			ah.HandleAck(&messaging.Message{Channel: "channel", Publisher: "publisher", Payload: []byte(tc.payload)}, func(err error) {
+				done <- err
+			})
+			select {
+			case err := <-done:
+				assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			case <-time.After(5 * time.Second):
+				t.Fatalf("%s: timed out waiting for the message to be acknowledged", tc.desc)
+			}
+		})
+	}
+}
+
+func TestStartAlerts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rec := &alertRecorder{}
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	window := 20 * time.Millisecond
+	c := &failingConsumer{}
+	handler := startConsumer(ctx, t, fmt.Sprintf(alertConfigTemplate, ts.URL, window), c)
+
+	// write handles messages for the duration, one every millisecond.
+	write := func(d time.Duration) {
+		for end := time.Now().Add(d); time.Now().Before(end); {
+			_ = handler.Handle(senmlMessage("publisher", 1))
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	write(5 * window)
+	assert.Empty(t, rec.statuses(), "successful writes: expected no alerts")
+
+	c.failing.Store(true)
+	write(10 * window)
+	assert.Equal(t, []string{consumers.AlertFiring}, rec.statuses(), "sustained write failures: expected a single alert")
+
+	c.failing.Store(false)
+	write(10 * window)
+	assert.Equal(t, []string{consumers.AlertFiring, consumers.AlertResolved}, rec.statuses(), "recovered writes: expected a single resolve")
+
+	// Windows without writes keep the state.
+	time.Sleep(5 * window)
+	assert.Equal(t, []string{consumers.AlertFiring, consumers.AlertResolved}, rec.statuses(), "no writes: expected no more alerts")
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	alert := rec.alerts[0]
+	assert.Equal(t, "consumer", alert.Consumer, fmt.Sprintf("firing alert: expected consumer %s got %s", "consumer", alert.Consumer))
+	assert.Equal(t, errWrite.Error(), alert.LastError, fmt.Sprintf("firing alert: expected last error %s got %s", errWrite, alert.LastError))
+	assert.GreaterOrEqual(t, alert.ErrorRate, 0.5, fmt.Sprintf("firing alert: expected error rate over threshold got %f", alert.ErrorRate))
+}
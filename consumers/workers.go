@@ -0,0 +1,193 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+)
+
+const (
+	queueSize = 100
+
+	// drainTimeout bounds the consumption of the held messages once the
+	// consumer stops.
+	drainTimeout = 10 * time.Second
+)
+
+var _ messaging.AckHandler = (*workerPool)(nil)
+
+// job is a message queued for a worker. The done callback reports the
+// result of handling the message to the subscriber, which acknowledges the
+// message only then.
+type job struct {
+	msg  *messaging.Message
+	done func(err error)
+}
+
+// workerPool handles messages concurrently. If ordered, messages are
+// dispatched by publisher, so all the messages of a single publisher are
+// handled, in order, by the same worker. Otherwise, any idle worker picks
+// up the next message.
+//
+// Each worker handles messages with its own handler, one at a time, so the
+// number of workers bounds the number of concurrent writes. The queues are
+// bounded as well: once they are full, Handle blocks, which slows down the
+// consumption from the broker instead of dropping messages. The messages
+// are acknowledged only once they are written, so the messages of a failed
+// write are redelivered. When the consumer stops, the workers handle the
+// queued messages and flush their handlers.
+type workerPool struct {
+	ctx           context.Context
+	handlers      []messaging.MessageHandler
+	flushInterval time.Duration
+	queues        []chan job
+	ordered       bool
+	logger        *slog.Logger
+}
+
+// newWorkerPool starts the workers. Handlers which hold messages, such as
+// batch handlers, are flushed by their worker every flush interval.
+func newWorkerPool(ctx context.Context, newHandler func() messaging.MessageHandler, workers int, ordered bool, flushInterval time.Duration, logger *slog.Logger) messaging.MessageHandler {
+	wp := &workerPool{
+		ctx:           ctx,
+		flushInterval: flushInterval,
+		ordered:       ordered,
+		logger:        logger,
+	}
+
+	switch ordered {
+	case true:
+		for i := 0; i < workers; i++ {
+			q := make(chan job, queueSize)
+			wp.queues = append(wp.queues, q)
+			go wp.work(q, wp.addHandler(newHandler()))
+		}
+	default:
+		q := make(chan job, queueSize*workers)
+		wp.queues = append(wp.queues, q)
+		for i := 0; i < workers; i++ {
+			go wp.work(q, wp.addHandler(newHandler()))
+		}
+	}
+
+	return wp
+}
+
+// Handle queues the message of a subscriber without acknowledgments.
+func (wp *workerPool) Handle(msg *messaging.Message) error {
+	return wp.queue(job{msg: msg})
+}
+
+// HandleAck queues the message, which is acknowledged once its worker
+// handles it.
+func (wp *workerPool) HandleAck(msg *messaging.Message, done func(err error)) {
+	if err := wp.queue(job{msg: msg, done: done}); err != nil {
+		done(err)
+	}
+}
+
+func (wp *workerPool) queue(j job) error {
+	q := wp.queues[0]
+	if wp.ordered {
+		h := fnv.New32a()
+		h.Write([]byte(j.msg.GetPublisher()))
+		q = wp.queues[h.Sum32()%uint32(len(wp.queues))]
+	}
+
+	select {
+	case q <- j:
+		return nil
+	case <-wp.ctx.Done():
+		return wp.ctx.Err()
+	}
+}
+
+func (wp *workerPool) Cancel() error {
+	var err error
+	for _, h := range wp.handlers {
+		if e := h.Cancel(); e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+func (wp *workerPool) addHandler(h messaging.MessageHandler) messaging.MessageHandler {
+	wp.handlers = append(wp.handlers, h)
+	return h
+}
+
+func (wp *workerPool) work(q <-chan job, h messaging.MessageHandler) {
+	f, batched := h.(flusher)
+	var flush <-chan time.Time
+	if batched && wp.flushInterval > 0 {
+		ticker := time.NewTicker(wp.flushInterval)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+
+	for {
+		select {
+		case j := <-q:
+			wp.handle(h, j)
+		case <-flush:
+			if err := f.Flush(wp.ctx); err != nil {
+				wp.logger.Warn(fmt.Sprintf("Failed to flush messages: %s", err))
+			}
+		case <-wp.ctx.Done():
+			wp.drain(q, h)
+			if batched {
+				// The held messages are consumed even though the consumer
+				// is stopping, but not for longer than the drain timeout.
+				ctx, cancel := context.WithTimeout(context.WithoutCancel(wp.ctx), drainTimeout)
+				if err := f.Flush(ctx); err != nil {
+					wp.logger.Warn(fmt.Sprintf("Failed to flush messages: %s", err))
+				}
+				cancel()
+			}
+			return
+		}
+	}
+}
+
+// drain handles the messages which were queued before the consumer stopped.
+func (wp *workerPool) drain(q <-chan job, h messaging.MessageHandler) {
+	for {
+		select {
+		case j := <-q:
+			wp.handle(h, j)
+		default:
+			return
+		}
+	}
+}
+
+// handle handles the message and reports the result to the subscriber. The
+// messages which can't be transformed are acknowledged, since redelivering
+// them can't succeed. Handlers which hold messages report the result once
+// the messages are consumed.
+func (wp *workerPool) handle(h messaging.MessageHandler, j job) {
+	done := func(err error) {
+		if err != nil && (j.done == nil || errors.Contains(err, errTransform)) {
+			wp.logger.Warn(fmt.Sprintf("Failed to handle message: %s", err))
+			err = nil
+		}
+		if j.done != nil {
+			j.done(err)
+		}
+	}
+	if ah, ok := h.(messaging.AckHandler); ok && j.done != nil {
+		ah.HandleAck(j.msg, done)
+		return
+	}
+	done(h.Handle(j.msg))
+}
@@ -0,0 +1,251 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/consumers"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	// rowOverhead approximates the encoded size of the fixed width columns.
+	rowOverhead = 48
+	fileExt     = ".parquet"
+)
+
+var (
+	errSaveMessage       = errors.New("failed to save message to parquet storage")
+	errUnsupportedFormat = errors.New("unsupported message format, only SenML messages are supported")
+	errClosed            = errors.New("parquet writer is closed")
+)
+
+// Config contains Parquet writer batching options.
+type Config struct {
+	// Prefix is prepended to the keys of written files.
+	Prefix string
+	// MaxRows is the number of buffered messages which triggers a flush.
+	MaxRows int
+	// MaxBytes is the approximate size of buffered messages which triggers a flush.
+	MaxBytes int64
+	// FlushInterval is the interval incomplete batches are flushed at.
+	FlushInterval time.Duration
+}
+
+// Writer is a blocking consumer which buffers messages and writes them
+// as Parquet files once the batch is full or the flush interval elapses.
+type Writer interface {
+	consumers.BlockingConsumer
+
+	// Flush writes buffered messages, if any, to the storage.
+	Flush(ctx context.Context) error
+
+	// Close stops the flush timer and flushes the remaining messages.
+	Close(ctx context.Context) error
+}
+
+var _ Writer = (*writer)(nil)
+
+type writer struct {
+	storage Storage
+	cfg     Config
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	rows   []row
+	size   int64
+	seq    uint64
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New returns new Parquet writer. Incomplete batches are flushed every
+// FlushInterval until the writer is closed.
+func New(storage Storage, cfg Config, logger *slog.Logger) Writer {
+	w := &writer{
+		storage: storage,
+		cfg:     cfg,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+	if cfg.FlushInterval > 0 {
+		w.wg.Add(1)
+		go w.flushPeriodically()
+	}
+
+	return w
+}
+
+func (w *writer) ConsumeBlocking(ctx context.Context, messages interface{}) error {
+	msgs, ok := messages.([]senml.Message)
+	if !ok {
+		return errors.Wrap(errSaveMessage, errUnsupportedFormat)
+	}
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.Wrap(errSaveMessage, errClosed)
+	}
+	for _, msg := range msgs {
+		r := toRow(msg)
+		w.rows = append(w.rows, r)
+		w.size += r.size()
+	}
+	full := w.full()
+	w.mu.Unlock()
+
+	if full {
+		return w.Flush(ctx)
+	}
+
+	return nil
+}
+
+func (w *writer) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	rows, size := w.rows, w.size
+	w.rows, w.size = nil, 0
+	w.seq++
+	seq := w.seq
+	w.mu.Unlock()
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := w.write(ctx, rows, seq); err != nil {
+		// Put the rows back so they are retried on the next flush.
+		w.mu.Lock()
+		w.rows = append(rows, w.rows...)
+		w.size += size
+		w.mu.Unlock()
+		return errors.Wrap(errSaveMessage, err)
+	}
+
+	return nil
+}
+
+func (w *writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	w.wg.Wait()
+
+	return w.Flush(ctx)
+}
+
+func (w *writer) flushPeriodically() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if err := w.Flush(context.Background()); err != nil {
+				w.logger.Warn(fmt.Sprintf("Failed to flush parquet batch: %s", err))
+			}
+		}
+	}
+}
+
+func (w *writer) full() bool {
+	if w.cfg.MaxRows > 0 && len(w.rows) >= w.cfg.MaxRows {
+		return true
+	}
+
+	return w.cfg.MaxBytes > 0 && w.size >= w.cfg.MaxBytes
+}
+
+func (w *writer) write(ctx context.Context, rows []row, seq uint64) error {
+	var buf bytes.Buffer
+	if err := parquet.Write(&buf, rows); err != nil {
+		return err
+	}
+
+	return w.storage.Put(ctx, w.key(time.Now().UTC(), seq), buf.Bytes())
+}
+
+// key returns file key partitioned by date, e.g. messages/2024/05/17/1715950800000000000-1.parquet.
+func (w *writer) key(t time.Time, seq uint64) string {
+	name := fmt.Sprintf("%s/%d-%d%s", t.Format("2006/01/02"), t.UnixNano(), seq, fileExt)
+	if w.cfg.Prefix == "" {
+		return name
+	}
+
+	return w.cfg.Prefix + "/" + name
+}
+
+// row represents a single SenML message as stored in Parquet files.
+type row struct {
+	Channel      string   `parquet:"channel"`
+	Subtopic     string   `parquet:"subtopic"`
+	Publisher    string   `parquet:"publisher"`
+	Protocol     string   `parquet:"protocol"`
+	Name         string   `parquet:"name"`
+	Unit         string   `parquet:"unit"`
+	Time         float64  `parquet:"time"`
+	UpdateTime   float64  `parquet:"update_time"`
+	OriginalTime float64  `parquet:"original_time,optional"`
+	Value        *float64 `parquet:"value,optional"`
+	StringValue  *string  `parquet:"string_value,optional"`
+	DataValue    *string  `parquet:"data_value,optional"`
+	BoolValue    *bool    `parquet:"bool_value,optional"`
+	Sum          *float64 `parquet:"sum,optional"`
+	Latitude     *float64 `parquet:"latitude,optional"`
+	Longitude    *float64 `parquet:"longitude,optional"`
+}
+
+func toRow(msg senml.Message) row {
+	return row{
+		Channel:      msg.Channel,
+		Subtopic:     msg.Subtopic,
+		Publisher:    msg.Publisher,
+		Protocol:     msg.Protocol,
+		Name:         msg.Name,
+		Unit:         msg.Unit,
+		Time:         msg.Time,
+		UpdateTime:   msg.UpdateTime,
+		OriginalTime: msg.OriginalTime,
+		Value:        msg.Value,
+		StringValue:  msg.StringValue,
+		DataValue:    msg.DataValue,
+		BoolValue:    msg.BoolValue,
+		Sum:          msg.Sum,
+		Latitude:     msg.Latitude,
+		Longitude:    msg.Longitude,
+	}
+}
+
+func (r row) size() int64 {
+	n := len(r.Channel) + len(r.Subtopic) + len(r.Publisher) + len(r.Protocol) + len(r.Name) + len(r.Unit)
+	if r.StringValue != nil {
+		n += len(*r.StringValue)
+	}
+	if r.DataValue != nil {
+		n += len(*r.DataValue)
+	}
+
+	return int64(n + rowOverhead)
+}
@@ -0,0 +1,334 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	mgparquet "github.com/absmach/magistrala/consumers/writers/parquet"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/transformers/json"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	msgsNum     = 42
+	valueFields = 5
+	subtopic    = "topic"
+	prefix      = "messages"
+)
+
+var (
+	v       float64 = 5
+	stringV         = "value"
+	boolV           = true
+	dataV           = "base64"
+	sum     float64 = 42
+
+	errStorage = errors.New("storage unavailable")
+)
+
+// record mirrors the schema the writer produces.
+type record struct {
+	Channel     string   `parquet:"channel"`
+	Subtopic    string   `parquet:"subtopic"`
+	Publisher   string   `parquet:"publisher"`
+	Protocol    string   `parquet:"protocol"`
+	Name        string   `parquet:"name"`
+	Unit        string   `parquet:"unit"`
+	Time        float64  `parquet:"time"`
+	UpdateTime  float64  `parquet:"update_time"`
+	Value       *float64 `parquet:"value,optional"`
+	StringValue *string  `parquet:"string_value,optional"`
+	DataValue   *string  `parquet:"data_value,optional"`
+	BoolValue   *bool    `parquet:"bool_value,optional"`
+	Sum         *float64 `parquet:"sum,optional"`
+}
+
+type memStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	err   error
+}
+
+func (ms *memStorage) Put(_ context.Context, key string, data []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.err != nil {
+		return ms.err
+	}
+	ms.files[key] = data
+
+	return nil
+}
+
+func (ms *memStorage) keys() []string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var keys []string
+	for k := range ms.files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (ms *memStorage) records(t *testing.T) []record {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	var recs []record
+	for _, data := range ms.files {
+		rs, err := parquet.Read[record](bytes.NewReader(data), int64(len(data)))
+		require.Nil(t, err, fmt.Sprintf("got unexpected error reading parquet file: %s", err))
+		recs = append(recs, rs...)
+	}
+
+	return recs
+}
+
+func (ms *memStorage) setErr(err error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.err = err
+}
+
+func newStorage() *memStorage {
+	return &memStorage{files: make(map[string][]byte)}
+}
+
+func messages(n int) []senml.Message {
+	now := float64(time.Now().Unix())
+	var msgs []senml.Message
+	for i := 0; i < n; i++ {
+		msg := senml.Message{
+			Channel:    "channel",
+			Publisher:  fmt.Sprintf("publisher-%d", i%3),
+			Protocol:   "mqtt",
+			Name:       fmt.Sprintf("sensor-%d", i),
+			Unit:       "C",
+			Time:       now + float64(i),
+			UpdateTime: now,
+		}
+		// Mix possible values as well as value sum.
+		switch i % valueFields {
+		case 0:
+			msg.Subtopic = subtopic
+			msg.Value = &v
+		case 1:
+			msg.BoolValue = &boolV
+		case 2:
+			msg.StringValue = &stringV
+		case 3:
+			msg.DataValue = &dataV
+		case 4:
+			msg.Sum = &sum
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs
+}
+
+func toMessages(recs []record) []senml.Message {
+	msgs := make([]senml.Message, len(recs))
+	for i, r := range recs {
+		msgs[i] = senml.Message{
+			Channel:     r.Channel,
+			Subtopic:    r.Subtopic,
+			Publisher:   r.Publisher,
+			Protocol:    r.Protocol,
+			Name:        r.Name,
+			Unit:        r.Unit,
+			Time:        r.Time,
+			UpdateTime:  r.UpdateTime,
+			Value:       r.Value,
+			StringValue: r.StringValue,
+			DataValue:   r.DataValue,
+			BoolValue:   r.BoolValue,
+			Sum:         r.Sum,
+		}
+	}
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].Time < msgs[j].Time })
+
+	return msgs
+}
+
+func TestConsumeBlocking(t *testing.T) {
+	cases := []struct {
+		desc     string
+		cfg      mgparquet.Config
+		msgs     interface{}
+		files    int
+		buffered int
+		err      error
+	}{
+		{
+			desc:  "consume full batch",
+			cfg:   mgparquet.Config{Prefix: prefix, MaxRows: msgsNum},
+			msgs:  messages(msgsNum),
+			files: 1,
+		},
+		{
+			desc:     "consume incomplete batch",
+			cfg:      mgparquet.Config{Prefix: prefix, MaxRows: msgsNum + 1},
+			msgs:     messages(msgsNum),
+			buffered: msgsNum,
+		},
+		{
+			desc:  "consume batch exceeding max bytes",
+			cfg:   mgparquet.Config{Prefix: prefix, MaxBytes: 1},
+			msgs:  messages(msgsNum),
+			files: 1,
+		},
+		{
+			desc: "consume JSON messages",
+			cfg:  mgparquet.Config{Prefix: prefix, MaxRows: 1},
+			msgs: json.Messages{Data: []json.Message{{Channel: "channel"}}},
+			err:  errors.New("unsupported message format, only SenML messages are supported"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			storage := newStorage()
+			w := mgparquet.New(storage, tc.cfg, mglog.NewMock())
+
+			err := w.ConsumeBlocking(context.Background(), tc.msgs)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+			assert.Len(t, storage.keys(), tc.files, fmt.Sprintf("%s: expected %d files, got %d", tc.desc, tc.files, len(storage.keys())))
+			if tc.files > 0 {
+				assert.Equal(t, tc.msgs, toMessages(storage.records(t)), fmt.Sprintf("%s: read messages don't match written", tc.desc))
+			}
+
+			err = w.Close(context.Background())
+			assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error on close: %s", tc.desc, err))
+			assert.Len(t, storage.records(t), tc.files*msgsNum+tc.buffered, fmt.Sprintf("%s: unexpected number of stored messages after close", tc.desc))
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	storage := newStorage()
+	w := mgparquet.New(storage, mgparquet.Config{MaxRows: 1}, mglog.NewMock())
+	err := w.ConsumeBlocking(context.Background(), messages(1))
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	keys := storage.keys()
+	require.Len(t, keys, 1)
+	data := storage.files[keys[0]]
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.Nil(t, err, fmt.Sprintf("got unexpected error opening parquet file: %s", err))
+
+	expected := map[string]bool{
+		"channel":       false,
+		"subtopic":      false,
+		"publisher":     false,
+		"protocol":      false,
+		"name":          false,
+		"unit":          false,
+		"time":          false,
+		"update_time":   false,
+		"original_time": true,
+		"value":         true,
+		"string_value":  true,
+		"data_value":    true,
+		"bool_value":    true,
+		"sum":           true,
+		"latitude":      true,
+		"longitude":     true,
+	}
+	fields := f.Schema().Fields()
+	assert.Len(t, fields, len(expected))
+	for _, field := range fields {
+		optional, ok := expected[field.Name()]
+		assert.True(t, ok, fmt.Sprintf("unexpected column %s", field.Name()))
+		assert.Equal(t, optional, field.Optional(), fmt.Sprintf("column %s: expected optional %t", field.Name(), optional))
+	}
+}
+
+func TestFlushInterval(t *testing.T) {
+	storage := newStorage()
+	w := mgparquet.New(storage, mgparquet.Config{MaxRows: msgsNum * 2, FlushInterval: 10 * time.Millisecond}, mglog.NewMock())
+	defer w.Close(context.Background())
+
+	msgs := messages(msgsNum)
+	err := w.ConsumeBlocking(context.Background(), msgs)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	assert.Eventually(t, func() bool {
+		return len(storage.keys()) == 1
+	}, time.Second, 5*time.Millisecond, "expected incomplete batch to be flushed on interval")
+	assert.Equal(t, msgs, toMessages(storage.records(t)))
+}
+
+func TestClose(t *testing.T) {
+	storage := newStorage()
+	w := mgparquet.New(storage, mgparquet.Config{MaxRows: msgsNum * 2, FlushInterval: time.Hour}, mglog.NewMock())
+
+	msgs := messages(msgsNum)
+	err := w.ConsumeBlocking(context.Background(), msgs)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Empty(t, storage.keys())
+
+	err = w.Close(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error on close: %s", err))
+	assert.Equal(t, msgs, toMessages(storage.records(t)))
+
+	err = w.ConsumeBlocking(context.Background(), msgs)
+	assert.NotNil(t, err, "expected error consuming after close")
+
+	err = w.Close(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error on repeated close: %s", err))
+}
+
+func TestFlushRetry(t *testing.T) {
+	storage := newStorage()
+	storage.setErr(errStorage)
+	w := mgparquet.New(storage, mgparquet.Config{MaxRows: msgsNum}, mglog.NewMock())
+
+	msgs := messages(msgsNum)
+	err := w.ConsumeBlocking(context.Background(), msgs)
+	assert.True(t, errors.Contains(err, errStorage), fmt.Sprintf("expected error %s, got %s", errStorage, err))
+
+	storage.setErr(nil)
+	err = w.Flush(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, msgs, toMessages(storage.records(t)), "failed batch should be retried on next flush")
+}
+
+func TestFSStorage(t *testing.T) {
+	root := t.TempDir()
+	storage := mgparquet.NewFSStorage(root)
+	w := mgparquet.New(storage, mgparquet.Config{Prefix: prefix, MaxRows: msgsNum}, mglog.NewMock())
+
+	msgs := messages(msgsNum)
+	err := w.ConsumeBlocking(context.Background(), msgs)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	files, err := filepath.Glob(filepath.Join(root, prefix, "*", "*", "*", "*.parquet"))
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	recs, err := parquet.Read[record](bytes.NewReader(data), int64(len(data)))
+	require.Nil(t, err, fmt.Sprintf("got unexpected error reading parquet file: %s", err))
+	assert.Equal(t, msgs, toMessages(recs))
+
+	err = storage.Put(context.Background(), "../escape.parquet", data)
+	assert.NotNil(t, err, "expected error storing outside of root")
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package parquet contains a message writer which batches SenML messages
+// and stores them as Parquet files using a pluggable blob storage.
+package parquet
@@ -0,0 +1,51 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package parquet
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+var errStorePath = errors.New("invalid storage path")
+
+// Storage specifies the blob storage Parquet files are written to.
+type Storage interface {
+	// Put stores the data under the given key. Keys are slash separated
+	// paths relative to the storage root.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+var _ Storage = (*fsStorage)(nil)
+
+type fsStorage struct {
+	root string
+}
+
+// NewFSStorage returns new Storage which stores files under the root directory.
+func NewFSStorage(root string) Storage {
+	return &fsStorage{root: root}
+}
+
+func (fs *fsStorage) Put(_ context.Context, key string, data []byte) error {
+	path := filepath.Join(fs.root, filepath.FromSlash(key))
+	if rel, err := filepath.Rel(fs.root, path); err != nil || strings.HasPrefix(rel, "..") {
+		return errStorePath
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never observe partial files.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
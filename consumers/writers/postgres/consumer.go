@@ -52,10 +52,10 @@ func (pr postgresRepo) saveSenml(ctx context.Context, messages interface{}) (err
 	}
 	q := `INSERT INTO messages (id, channel, subtopic, publisher, protocol,
           name, unit, value, string_value, bool_value, data_value, sum,
-          time, update_time)
+          time, update_time, original_time, latitude, longitude)
           VALUES (:id, :channel, :subtopic, :publisher, :protocol, :name, :unit,
           :value, :string_value, :bool_value, :data_value, :sum,
-          :time, :update_time);`
+          :time, :update_time, :original_time, :latitude, :longitude);`
 
 	tx, err := pr.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -41,6 +41,38 @@ func Migration() *migrate.MemoryMigrationSource {
 					`ALTER TABLE messages ADD PRIMARY KEY (time, publisher, subtopic, name)`,
 				},
 			},
+			{
+				Id: "messages_3",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS channel_ttls (
+                        channel UUID PRIMARY KEY,
+                        ttl     BIGINT NOT NULL
+                    )`,
+				},
+				Down: []string{
+					"DROP TABLE channel_ttls",
+				},
+			},
+			{
+				Id: "messages_4",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS latitude FLOAT, ADD COLUMN IF NOT EXISTS longitude FLOAT`,
+					`CREATE INDEX IF NOT EXISTS messages_location_idx ON messages (latitude, longitude) WHERE latitude IS NOT NULL`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS messages_location_idx`,
+					`ALTER TABLE messages DROP COLUMN IF EXISTS latitude, DROP COLUMN IF EXISTS longitude`,
+				},
+			},
+			{
+				Id: "messages_5",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS original_time FLOAT`,
+				},
+				Down: []string{
+					`ALTER TABLE messages DROP COLUMN IF EXISTS original_time`,
+				},
+			},
 		},
 	}
 }
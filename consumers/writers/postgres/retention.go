@@ -0,0 +1,92 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/absmach/magistrala/consumers/writers/retention"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	errSaveTTL   = errors.New("failed to save channel TTL to postgres database")
+	errRemoveTTL = errors.New("failed to remove channel TTL from postgres database")
+	errPurge     = errors.New("failed to purge messages from postgres database")
+)
+
+var _ retention.Repository = (*retentionRepo)(nil)
+
+type retentionRepo struct {
+	db *sqlx.DB
+}
+
+// NewRetentionRepository returns new PostgreSQL retention repository.
+// Only the SenML messages are subject to the retention.
+func NewRetentionRepository(db *sqlx.DB) retention.Repository {
+	return &retentionRepo{db: db}
+}
+
+func (rr *retentionRepo) SaveTTL(ctx context.Context, channelID string, ttl time.Duration) error {
+	q := `INSERT INTO channel_ttls (channel, ttl) VALUES ($1, $2)
+          ON CONFLICT (channel) DO UPDATE SET ttl = EXCLUDED.ttl;`
+	if _, err := rr.db.ExecContext(ctx, q, channelID, ttl.Nanoseconds()); err != nil {
+		return errors.Wrap(errSaveTTL, err)
+	}
+
+	return nil
+}
+
+func (rr *retentionRepo) RemoveTTL(ctx context.Context, channelID string) error {
+	if _, err := rr.db.ExecContext(ctx, `DELETE FROM channel_ttls WHERE channel = $1;`, channelID); err != nil {
+		return errors.Wrap(errRemoveTTL, err)
+	}
+
+	return nil
+}
+
+func (rr *retentionRepo) Purge(ctx context.Context, now time.Time, defaultTTL time.Duration, limit uint64) (uint64, error) {
+	ts := now.UnixNano()
+
+	q := `DELETE FROM messages WHERE id IN (
+            SELECT m.id FROM messages m JOIN channel_ttls c ON m.channel = c.channel
+            WHERE c.ttl > 0 AND m.time < $1 - c.ttl LIMIT $2);`
+	res, err := rr.db.ExecContext(ctx, q, ts, limit)
+	if err != nil {
+		return 0, errors.Wrap(errPurge, err)
+	}
+	removed, err := affected(res)
+	if err != nil {
+		return 0, errors.Wrap(errPurge, err)
+	}
+
+	if defaultTTL > 0 && removed < limit {
+		q := `DELETE FROM messages WHERE id IN (
+                SELECT m.id FROM messages m WHERE m.time < $1
+                AND NOT EXISTS (SELECT 1 FROM channel_ttls c WHERE c.channel = m.channel) LIMIT $2);`
+		res, err := rr.db.ExecContext(ctx, q, ts-defaultTTL.Nanoseconds(), limit-removed)
+		if err != nil {
+			return removed, errors.Wrap(errPurge, err)
+		}
+		n, err := affected(res)
+		if err != nil {
+			return removed, errors.Wrap(errPurge, err)
+		}
+		removed += n
+	}
+
+	return removed, nil
+}
+
+func affected(res sql.Result) (uint64, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(n), nil
+}
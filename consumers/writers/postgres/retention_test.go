@@ -0,0 +1,95 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/consumers/writers/postgres"
+	"github.com/absmach/magistrala/consumers/writers/retention"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurge(t *testing.T) {
+	writer := postgres.New(db)
+	repo := postgres.NewRetentionRepository(db)
+
+	now := time.Now()
+	ttlChan := newUUID(t)
+	noTTLChan := newUUID(t)
+	zeroTTLChan := newUUID(t)
+	for _, ch := range []string{ttlChan, noTTLChan, zeroTTLChan} {
+		var msgs []senml.Message
+		for _, age := range []time.Duration{3 * time.Hour, 2 * time.Hour, time.Minute} {
+			msgs = append(msgs, senml.Message{
+				Channel:   ch,
+				Publisher: newUUID(t),
+				Subtopic:  subtopic,
+				Name:      "temperature",
+				Value:     &v,
+				Time:      float64(now.Add(-age).UnixNano()),
+			})
+		}
+		err := writer.ConsumeBlocking(context.Background(), msgs)
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	}
+
+	err := repo.SaveTTL(context.Background(), ttlChan, time.Hour)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = repo.SaveTTL(context.Background(), zeroTTLChan, 0)
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	cases := []struct {
+		desc       string
+		defaultTTL time.Duration
+		removeTTL  string
+		counts     map[string]int
+	}{
+		{
+			desc:       "purge messages past the channel TTL",
+			defaultTTL: 0,
+			counts:     map[string]int{ttlChan: 1, noTTLChan: 3, zeroTTLChan: 3},
+		},
+		{
+			desc:       "purge messages past the default TTL",
+			defaultTTL: 150 * time.Minute,
+			counts:     map[string]int{ttlChan: 1, noTTLChan: 2, zeroTTLChan: 3},
+		},
+		{
+			desc:       "purge messages past the default TTL after the zero channel TTL removal",
+			defaultTTL: 90 * time.Minute,
+			removeTTL:  zeroTTLChan,
+			counts:     map[string]int{ttlChan: 1, noTTLChan: 1, zeroTTLChan: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.removeTTL != "" {
+				err := repo.RemoveTTL(context.Background(), tc.removeTTL)
+				assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+			}
+			_, err := repo.Purge(context.Background(), now, tc.defaultTTL, retention.DefBatchSize)
+			assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+			for ch, count := range tc.counts {
+				var stored int
+				err := db.Get(&stored, `SELECT COUNT(*) FROM messages WHERE channel = $1`, ch)
+				assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+				assert.Equal(t, count, stored, fmt.Sprintf("channel %s: expected %d messages got %d\n", ch, count, stored))
+			}
+		})
+	}
+}
+
+func newUUID(t *testing.T) string {
+	id, err := uuid.NewV4()
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	return id.String()
+}
@@ -0,0 +1,8 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retention contains the per-channel message retention used by the
+// message writers. Channel TTLs are read from the channel metadata through
+// the things event stream and expired messages are removed by a background
+// purger.
+package retention
@@ -0,0 +1,104 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/events"
+)
+
+const (
+	channelPrefix = "group."
+	channelCreate = channelPrefix + "create"
+	channelUpdate = channelPrefix + "update"
+	channelRemove = channelPrefix + "remove"
+)
+
+// ErrInvalidTTL indicates the channel TTL metadata value is malformed.
+var ErrInvalidTTL = errors.New("invalid channel TTL")
+
+type eventHandler struct {
+	repo        Repository
+	metadataKey string
+}
+
+// NewEventHandler returns the things event store handler which keeps the
+// channel TTLs in sync with the channel metadata.
+func NewEventHandler(repo Repository, metadataKey string) events.EventHandler {
+	return &eventHandler{
+		repo:        repo,
+		metadataKey: metadataKey,
+	}
+}
+
+func (eh *eventHandler) Handle(ctx context.Context, event events.Event) error {
+	msg, err := event.Encode()
+	if err != nil {
+		return err
+	}
+
+	id := events.Read(msg, "id", "")
+	switch msg["operation"] {
+	case channelCreate, channelUpdate:
+		// Update events without metadata leave the metadata unchanged.
+		metadata, ok := msg["metadata"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if id == "" {
+			return svcerr.ErrMalformedEntity
+		}
+		val, ok := metadata[eh.metadataKey]
+		if !ok {
+			return eh.repo.RemoveTTL(ctx, id)
+		}
+		ttl, err := ParseTTL(val)
+		if err != nil {
+			return err
+		}
+		return eh.repo.SaveTTL(ctx, id, ttl)
+	case channelRemove:
+		if id == "" {
+			return svcerr.ErrMalformedEntity
+		}
+		return eh.repo.RemoveTTL(ctx, id)
+	}
+
+	return nil
+}
+
+// ParseTTL parses the channel TTL metadata value. The value is either a
+// duration string, such as "72h", or a number of seconds. Zero TTL retains
+// the channel messages forever.
+func ParseTTL(val interface{}) (time.Duration, error) {
+	var ttl time.Duration
+	switch v := val.(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			secs, serr := strconv.ParseFloat(v, 64)
+			if serr != nil {
+				return 0, errors.Wrap(ErrInvalidTTL, err)
+			}
+			d = time.Duration(secs * float64(time.Second))
+		}
+		ttl = d
+	case float64:
+		ttl = time.Duration(v * float64(time.Second))
+	case int:
+		ttl = time.Duration(v) * time.Second
+	default:
+		return 0, ErrInvalidTTL
+	}
+	if ttl < 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	return ttl, nil
+}
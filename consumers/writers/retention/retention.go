@@ -0,0 +1,106 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// DefBatchSize is the default number of messages removed by a single delete.
+const DefBatchSize = 10000
+
+var errPurge = errors.New("failed to purge expired messages")
+
+// Config contains the message retention parameters. Zero interval disables
+// the purger and zero default TTL retains the messages of the channels
+// without TTL forever. The expired messages are removed in batches of at most
+// BatchSize messages, so that a purge never holds long locks or builds up a
+// large transaction.
+type Config struct {
+	Interval    time.Duration `env:"INTERVAL"     envDefault:"0s"`
+	DefaultTTL  time.Duration `env:"DEFAULT_TTL"  envDefault:"0s"`
+	MetadataKey string        `env:"METADATA_KEY" envDefault:"ttl"`
+	BatchSize   uint64        `env:"BATCH_SIZE"   envDefault:"10000"`
+}
+
+// Repository specifies the channel TTL persistence and the expired messages
+// removal API.
+//
+//go:generate mockery --name Repository --output=./mocks --filename repository.go --quiet --note "Copyright (c) Abstract Machines"
+type Repository interface {
+	// SaveTTL saves the TTL of the channel messages. Zero TTL retains the
+	// channel messages forever, regardless of the default TTL.
+	SaveTTL(ctx context.Context, channelID string, ttl time.Duration) error
+
+	// RemoveTTL removes the TTL of the channel, so the default TTL applies.
+	RemoveTTL(ctx context.Context, channelID string) error
+
+	// Purge removes at most limit messages older than the TTL of their
+	// channel, or the default TTL for the channels without one, and returns
+	// the number of removed messages. Zero default TTL retains the messages
+	// of the channels without TTL.
+	Purge(ctx context.Context, now time.Time, defaultTTL time.Duration, limit uint64) (uint64, error)
+}
+
+// Purger periodically removes the expired messages.
+type Purger struct {
+	repo   Repository
+	cfg    Config
+	logger *slog.Logger
+}
+
+// NewPurger returns the purger of the expired messages.
+func NewPurger(repo Repository, cfg Config, logger *slog.Logger) *Purger {
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = DefBatchSize
+	}
+
+	return &Purger{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run purges the expired messages periodically until the context is canceled.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := p.Purge(ctx)
+			if err != nil {
+				p.logger.Error("failed to purge expired messages", slog.Any("error", err))
+				continue
+			}
+			p.logger.Info("expired messages purged", slog.Uint64("removed", removed))
+		}
+	}
+}
+
+// Purge removes the messages expired at the time of the call, one batch at a
+// time, until no expired messages are left or the context is canceled.
+func (p *Purger) Purge(ctx context.Context) (uint64, error) {
+	now := time.Now()
+
+	var removed uint64
+	for {
+		n, err := p.repo.Purge(ctx, now, p.cfg.DefaultTTL, p.cfg.BatchSize)
+		removed += n
+		if err != nil {
+			return removed, errors.Wrap(errPurge, err)
+		}
+		if n < p.cfg.BatchSize || ctx.Err() != nil {
+			return removed, nil
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package retention_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/consumers/writers/retention"
+	"github.com/absmach/magistrala/consumers/writers/retention/mocks"
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const metadataKey = "ttl"
+
+var (
+	logger  = slog.New(slog.NewTextHandler(io.Discard, nil))
+	errRepo = errors.New("repository error")
+)
+
+type event map[string]interface{}
+
+func (e event) Encode() (map[string]interface{}, error) {
+	return e, nil
+}
+
+func TestHandle(t *testing.T) {
+	channelID := testsutil.GenerateUUID(t)
+
+	cases := []struct {
+		desc     string
+		event    event
+		saveTTL  time.Duration
+		saveCall bool
+		remove   bool
+		repoErr  error
+		err      error
+	}{
+		{
+			desc: "create channel with duration TTL",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{metadataKey: "72h"},
+			},
+			saveTTL:  72 * time.Hour,
+			saveCall: true,
+		},
+		{
+			desc: "create channel with TTL in seconds",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{metadataKey: float64(3600)},
+			},
+			saveTTL:  time.Hour,
+			saveCall: true,
+		},
+		{
+			desc: "create channel with zero TTL",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{metadataKey: "0"},
+			},
+			saveTTL:  0,
+			saveCall: true,
+		},
+		{
+			desc: "create channel without TTL",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{"key": "value"},
+			},
+			remove: true,
+		},
+		{
+			desc: "create channel without metadata",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+			},
+		},
+		{
+			desc: "create channel with invalid TTL",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{metadataKey: "forever"},
+			},
+			err: retention.ErrInvalidTTL,
+		},
+		{
+			desc: "create channel with negative TTL",
+			event: event{
+				"operation": "group.create",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{metadataKey: "-1h"},
+			},
+			err: retention.ErrInvalidTTL,
+		},
+		{
+			desc: "update channel TTL with repository error",
+			event: event{
+				"operation": "group.update",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{metadataKey: "1h"},
+			},
+			saveTTL:  time.Hour,
+			saveCall: true,
+			repoErr:  errRepo,
+			err:      errRepo,
+		},
+		{
+			desc: "update channel removing TTL",
+			event: event{
+				"operation": "group.update",
+				"id":        channelID,
+				"metadata":  map[string]interface{}{},
+			},
+			remove: true,
+		},
+		{
+			desc: "update channel without id",
+			event: event{
+				"operation": "group.update",
+				"metadata":  map[string]interface{}{metadataKey: "1h"},
+			},
+			err: svcerr.ErrMalformedEntity,
+		},
+		{
+			desc: "remove channel",
+			event: event{
+				"operation": "group.remove",
+				"id":        channelID,
+			},
+			remove: true,
+		},
+		{
+			desc: "unrelated event",
+			event: event{
+				"operation": "thing.remove",
+				"id":        channelID,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			if tc.saveCall {
+				repo.On("SaveTTL", mock.Anything, channelID, tc.saveTTL).Return(tc.repoErr).Once()
+			}
+			if tc.remove {
+				repo.On("RemoveTTL", mock.Anything, channelID).Return(tc.repoErr).Once()
+			}
+			eh := retention.NewEventHandler(repo, metadataKey)
+			err := eh.Handle(context.Background(), tc.event)
+			assert.True(t, errors.Contains(err, tc.err), "expected error %v, got %v", tc.err, err)
+		})
+	}
+}
+
+func TestPurge(t *testing.T) {
+	cfg := retention.Config{DefaultTTL: time.Hour, BatchSize: 10}
+
+	cases := []struct {
+		desc    string
+		batches []uint64
+		repoErr error
+		removed uint64
+		err     error
+	}{
+		{
+			desc:    "purge expired messages",
+			batches: []uint64{4},
+			removed: 4,
+		},
+		{
+			desc:    "purge expired messages in multiple batches",
+			batches: []uint64{10, 10, 3},
+			removed: 23,
+		},
+		{
+			desc:    "purge expired messages until none are left",
+			batches: []uint64{10, 0},
+			removed: 10,
+		},
+		{
+			desc:    "purge expired messages with repository error",
+			batches: []uint64{10, 0},
+			repoErr: errRepo,
+			removed: 10,
+			err:     errRepo,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			for i, n := range tc.batches {
+				var err error
+				if i == len(tc.batches)-1 {
+					err = tc.repoErr
+				}
+				repo.On("Purge", mock.Anything, mock.AnythingOfType("time.Time"), cfg.DefaultTTL, cfg.BatchSize).Return(n, err).Once()
+			}
+			p := retention.NewPurger(repo, cfg, logger)
+			removed, err := p.Purge(context.Background())
+			assert.True(t, errors.Contains(err, tc.err), "expected error %v, got %v", tc.err, err)
+			assert.Equal(t, tc.removed, removed)
+		})
+	}
+}
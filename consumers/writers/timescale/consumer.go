@@ -51,10 +51,10 @@ func (tr timescaleRepo) saveSenml(ctx context.Context, messages interface{}) (er
 	}
 	q := `INSERT INTO messages (channel, subtopic, publisher, protocol,
           name, unit, value, string_value, bool_value, data_value, sum,
-          time, update_time)
+          time, update_time, original_time, latitude, longitude)
           VALUES (:channel, :subtopic, :publisher, :protocol, :name, :unit,
           :value, :string_value, :bool_value, :data_value, :sum,
-          :time, :update_time);`
+          :time, :update_time, :original_time, :latitude, :longitude);`
 
 	tx, err := tr.db.BeginTxx(ctx, nil)
 	if err != nil {
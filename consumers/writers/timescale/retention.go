@@ -0,0 +1,107 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/absmach/magistrala/consumers/writers/retention"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	errSaveTTL   = errors.New("failed to save channel TTL to timescale database")
+	errRemoveTTL = errors.New("failed to remove channel TTL from timescale database")
+	errPurge     = errors.New("failed to purge messages from timescale database")
+)
+
+var _ retention.Repository = (*retentionRepo)(nil)
+
+type retentionRepo struct {
+	db *sqlx.DB
+}
+
+// NewRetentionRepository returns new TimescaleSQL retention repository.
+// Only the SenML messages are subject to the retention.
+func NewRetentionRepository(db *sqlx.DB) retention.Repository {
+	return &retentionRepo{db: db}
+}
+
+func (rr *retentionRepo) SaveTTL(ctx context.Context, channelID string, ttl time.Duration) error {
+	q := `INSERT INTO channel_ttls (channel, ttl) VALUES ($1, $2)
+          ON CONFLICT (channel) DO UPDATE SET ttl = EXCLUDED.ttl;`
+	if _, err := rr.db.ExecContext(ctx, q, channelID, ttl.Nanoseconds()); err != nil {
+		return errors.Wrap(errSaveTTL, err)
+	}
+
+	return nil
+}
+
+func (rr *retentionRepo) RemoveTTL(ctx context.Context, channelID string) error {
+	if _, err := rr.db.ExecContext(ctx, `DELETE FROM channel_ttls WHERE channel = $1;`, channelID); err != nil {
+		return errors.Wrap(errRemoveTTL, err)
+	}
+
+	return nil
+}
+
+// Purge first drops the whole chunks which are expired for every channel and
+// then deletes a batch of the remaining expired rows. Rows removed with the
+// dropped chunks are not counted.
+func (rr *retentionRepo) Purge(ctx context.Context, now time.Time, defaultTTL time.Duration, limit uint64) (uint64, error) {
+	ts := now.UnixNano()
+
+	if defaultTTL > 0 {
+		// Chunks older than the longest TTL are expired for every channel,
+		// unless some channel retains its messages forever.
+		q := `SELECT drop_chunks('messages', older_than => $1::BIGINT - GREATEST($2::BIGINT, COALESCE(MAX(ttl), 0)))
+              FROM channel_ttls HAVING COUNT(*) FILTER (WHERE ttl = 0) = 0;`
+		if _, err := rr.db.ExecContext(ctx, q, ts, defaultTTL.Nanoseconds()); err != nil {
+			return 0, errors.Wrap(errPurge, err)
+		}
+	}
+
+	q := `DELETE FROM messages m USING (
+            SELECT e.time, e.publisher, e.subtopic, e.name FROM messages e JOIN channel_ttls c ON e.channel = c.channel
+            WHERE c.ttl > 0 AND e.time < $1 - c.ttl LIMIT $2) x
+          WHERE (m.time, m.publisher, m.subtopic, m.name) = (x.time, x.publisher, x.subtopic, x.name);`
+	res, err := rr.db.ExecContext(ctx, q, ts, limit)
+	if err != nil {
+		return 0, errors.Wrap(errPurge, err)
+	}
+	removed, err := affected(res)
+	if err != nil {
+		return 0, errors.Wrap(errPurge, err)
+	}
+
+	if defaultTTL > 0 && removed < limit {
+		q := `DELETE FROM messages m USING (
+                SELECT e.time, e.publisher, e.subtopic, e.name FROM messages e WHERE e.time < $1
+                AND NOT EXISTS (SELECT 1 FROM channel_ttls c WHERE c.channel = e.channel) LIMIT $2) x
+              WHERE (m.time, m.publisher, m.subtopic, m.name) = (x.time, x.publisher, x.subtopic, x.name);`
+		res, err := rr.db.ExecContext(ctx, q, ts-defaultTTL.Nanoseconds(), limit-removed)
+		if err != nil {
+			return removed, errors.Wrap(errPurge, err)
+		}
+		n, err := affected(res)
+		if err != nil {
+			return removed, errors.Wrap(errPurge, err)
+		}
+		removed += n
+	}
+
+	return removed, nil
+}
+
+func affected(res sql.Result) (uint64, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(n), nil
+}
@@ -4,8 +4,13 @@
 package magistrala
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"runtime"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 )
 
 const (
@@ -49,10 +54,66 @@ type HealthInfo struct {
 
 	// InstanceID contains the ID of the current service instance
 	InstanceID string `json:"instance_id"`
+
+	// GoVersion contains the Go version the service was built with.
+	GoVersion string `json:"go_version,omitempty"`
+
+	// SchemaVersion contains the ID of the last applied database migration.
+	SchemaVersion string `json:"schema_version,omitempty"`
+
+	// Broker contains the message broker type the service was built with.
+	Broker string `json:"broker,omitempty"`
+}
+
+// HealthOption configures additional information reported by the health handler.
+type HealthOption func(*healthConfig)
+
+type healthConfig struct {
+	schemaVersion func() (string, error)
+	broker        string
+	authorize     func(r *http.Request) error
+	encodeError   func(ctx context.Context, err error, w http.ResponseWriter)
+}
+
+// WithSchemaVersion reports the database schema version returned by the
+// given function, typically backed by the migration runner.
+func WithSchemaVersion(schemaVersion func() (string, error)) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.schemaVersion = schemaVersion
+	}
+}
+
+// WithBroker reports the message broker type used by the service.
+func WithBroker(broker string) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.broker = broker
+	}
+}
+
+// WithAuthorization gates the build and dependency information behind the
+// given check. Requests failing the check receive only the basic health info.
+func WithAuthorization(authorize func(r *http.Request) error) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.authorize = authorize
+	}
+}
+
+// WithErrorEncoder encodes the errors of the health handler, such as the
+// unavailable database, with the error encoder of the service API. Without
+// it, only the status code is written.
+func WithErrorEncoder(encodeError func(ctx context.Context, err error, w http.ResponseWriter)) HealthOption {
+	return func(cfg *healthConfig) {
+		cfg.encodeError = encodeError
+	}
 }
 
 // Health exposes an HTTP handler for retrieving service health.
-func Health(service, instanceID string) http.HandlerFunc {
+func Health(service, instanceID string, opts ...HealthOption) http.HandlerFunc {
+	cfg := healthConfig{encodeError: encodeHealthError}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add(contentType, contentTypeJSON)
 		if r.Method != http.MethodGet && r.Method != http.MethodHead {
@@ -69,6 +130,19 @@ func Health(service, instanceID string) http.HandlerFunc {
 			InstanceID:  instanceID,
 		}
 
+		if cfg.authorize == nil || cfg.authorize(r) == nil {
+			res.GoVersion = runtime.Version()
+			res.Broker = cfg.broker
+			if cfg.schemaVersion != nil {
+				version, err := cfg.schemaVersion()
+				if err != nil {
+					cfg.encodeError(r.Context(), errors.Wrap(svcerr.ErrServiceUnavailable, err), w)
+					return
+				}
+				res.SchemaVersion = version
+			}
+		}
+
 		w.WriteHeader(http.StatusOK)
 
 		if err := json.NewEncoder(w).Encode(res); err != nil {
@@ -76,3 +150,9 @@ func Health(service, instanceID string) http.HandlerFunc {
 		}
 	})
 }
+
+// encodeHealthError writes only the status code of the error, which is the
+// unavailable dependency the health handler reports.
+func encodeHealthError(_ context.Context, _ error, w http.ResponseWriter) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package magistrala_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	svcName       = "test"
+	instanceID    = "5de9b29a-feb9-11ed-be56-0242ac120002"
+	schemaVersion = "clients_03"
+	broker        = "nats"
+	validToken    = "valid"
+)
+
+var errSchemaVersion = errors.New("failed to retrieve schema version")
+
+func authorize(r *http.Request) error {
+	if r.Header.Get("Authorization") != "Bearer "+validToken {
+		return errors.New("unauthorized")
+	}
+
+	return nil
+}
+
+func TestHealth(t *testing.T) {
+	cases := []struct {
+		desc     string
+		opts     []magistrala.HealthOption
+		method   string
+		token    string
+		status   int
+		message  string
+		expected magistrala.HealthInfo
+	}{
+		{
+			desc:   "health without options",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			expected: magistrala.HealthInfo{
+				GoVersion: runtime.Version(),
+			},
+		},
+		{
+			desc: "health with schema version and broker",
+			opts: []magistrala.HealthOption{
+				magistrala.WithSchemaVersion(func() (string, error) { return schemaVersion, nil }),
+				magistrala.WithBroker(broker),
+			},
+			method: http.MethodGet,
+			status: http.StatusOK,
+			expected: magistrala.HealthInfo{
+				GoVersion:     runtime.Version(),
+				SchemaVersion: schemaVersion,
+				Broker:        broker,
+			},
+		},
+		{
+			desc: "health with failed schema version retrieval",
+			opts: []magistrala.HealthOption{
+				magistrala.WithSchemaVersion(func() (string, error) { return "", errSchemaVersion }),
+			},
+			method: http.MethodGet,
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			desc: "health with failed schema version retrieval and error encoder",
+			opts: []magistrala.HealthOption{
+				magistrala.WithSchemaVersion(func() (string, error) { return "", errSchemaVersion }),
+				magistrala.WithErrorEncoder(api.EncodeError),
+			},
+			method:  http.MethodGet,
+			status:  http.StatusServiceUnavailable,
+			message: svcerr.ErrServiceUnavailable.Error(),
+		},
+		{
+			desc: "health with authorization and valid token",
+			opts: []magistrala.HealthOption{
+				magistrala.WithSchemaVersion(func() (string, error) { return schemaVersion, nil }),
+				magistrala.WithBroker(broker),
+				magistrala.WithAuthorization(authorize),
+			},
+			method: http.MethodGet,
+			token:  validToken,
+			status: http.StatusOK,
+			expected: magistrala.HealthInfo{
+				GoVersion:     runtime.Version(),
+				SchemaVersion: schemaVersion,
+				Broker:        broker,
+			},
+		},
+		{
+			desc: "health with authorization and invalid token",
+			opts: []magistrala.HealthOption{
+				magistrala.WithSchemaVersion(func() (string, error) { return schemaVersion, nil }),
+				magistrala.WithBroker(broker),
+				magistrala.WithAuthorization(authorize),
+			},
+			method:   http.MethodGet,
+			token:    "invalid",
+			status:   http.StatusOK,
+			expected: magistrala.HealthInfo{},
+		},
+		{
+			desc: "health with authorization and missing token",
+			opts: []magistrala.HealthOption{
+				magistrala.WithBroker(broker),
+				magistrala.WithAuthorization(authorize),
+			},
+			method:   http.MethodGet,
+			status:   http.StatusOK,
+			expected: magistrala.HealthInfo{},
+		},
+		{
+			desc:   "health with invalid method",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/health", nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			magistrala.Health(svcName, instanceID, tc.opts...).ServeHTTP(rec, req)
+			assert.Equal(t, tc.status, rec.Code, fmt.Sprintf("%s: expected status %d got %d", tc.desc, tc.status, rec.Code))
+			if tc.message != "" {
+				var body struct {
+					Message string `json:"message"`
+				}
+				err := json.NewDecoder(rec.Body).Decode(&body)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.message, body.Message, fmt.Sprintf("%s: expected message %s got %s", tc.desc, tc.message, body.Message))
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			var info magistrala.HealthInfo
+			err := json.NewDecoder(rec.Body).Decode(&info)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, "pass", info.Status, fmt.Sprintf("%s: expected status pass got %s", tc.desc, info.Status))
+			assert.Equal(t, magistrala.Version, info.Version, fmt.Sprintf("%s: expected version %s got %s", tc.desc, magistrala.Version, info.Version))
+			assert.Equal(t, magistrala.Commit, info.Commit, fmt.Sprintf("%s: expected commit %s got %s", tc.desc, magistrala.Commit, info.Commit))
+			assert.Equal(t, instanceID, info.InstanceID, fmt.Sprintf("%s: expected instance ID %s got %s", tc.desc, instanceID, info.InstanceID))
+			assert.Equal(t, tc.expected.GoVersion, info.GoVersion, fmt.Sprintf("%s: expected Go version %s got %s", tc.desc, tc.expected.GoVersion, info.GoVersion))
+			assert.Equal(t, tc.expected.SchemaVersion, info.SchemaVersion, fmt.Sprintf("%s: expected schema version %s got %s", tc.desc, tc.expected.SchemaVersion, info.SchemaVersion))
+			assert.Equal(t, tc.expected.Broker, info.Broker, fmt.Sprintf("%s: expected broker %s got %s", tc.desc, tc.expected.Broker, info.Broker))
+		})
+	}
+}
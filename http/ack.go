@@ -0,0 +1,62 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// PublishAckHeader is the HTTP header requesting the publish to wait for
+	// the message broker acknowledgment.
+	PublishAckHeader = "Publish-Ack"
+
+	// ReceiptHeader is the HTTP header carrying the message broker receipt
+	// from the publish handler to the HTTP API.
+	ReceiptHeader = "Message-Receipt"
+
+	// publishAckParam is the query parameter requesting the publish
+	// acknowledgment, for clients which can not set custom headers.
+	publishAckParam = "ack"
+)
+
+type ackKey struct{}
+
+// ackRequest holds the headers of the request waiting for the publish
+// acknowledgment, so the handler can pass the receipt on with the request.
+type ackRequest struct {
+	header http.Header
+}
+
+// PublishAckMiddleware marks the requests with the Publish-Ack header or the
+// ack query parameter set to true as waiting for the publish acknowledgment.
+// Receipts set by the clients are dropped, so only the handler sets them.
+func PublishAckMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(ReceiptHeader)
+		if ackRequested(r) {
+			r = r.WithContext(context.WithValue(r.Context(), ackKey{}, &ackRequest{header: r.Header}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func ackRequested(r *http.Request) bool {
+	val := r.Header.Get(PublishAckHeader)
+	if val == "" {
+		val = r.URL.Query().Get(publishAckParam)
+	}
+	ack, err := strconv.ParseBool(val)
+
+	return err == nil && ack
+}
+
+// ackRequestFrom returns the acknowledgment request of the publish, if any.
+func ackRequestFrom(ctx context.Context) (*ackRequest, bool) {
+	ar, ok := ctx.Value(ackKey{}).(*ackRequest)
+
+	return ar, ok
+}
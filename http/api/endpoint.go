@@ -18,6 +18,6 @@ func sendMessageEndpoint() endpoint.Endpoint {
 			return nil, errors.Wrap(apiutil.ErrValidation, err)
 		}
 
-		return publishMessageRes{}, nil
+		return publishMessageRes{Receipt: req.receipt}, nil
 	}
 }
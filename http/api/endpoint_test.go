@@ -4,19 +4,29 @@
 package api_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/absmach/magistrala"
 	server "github.com/absmach/magistrala/http"
 	"github.com/absmach/magistrala/http/api"
+	httpmocks "github.com/absmach/magistrala/http/mocks"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/magistrala/pkg/messaging"
 	pubsub "github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	rlmocks "github.com/absmach/magistrala/pkg/ratelimit/mocks"
+	thingssvc "github.com/absmach/magistrala/things"
 	thmocks "github.com/absmach/magistrala/things/mocks"
 	"github.com/absmach/mproxy"
 	mproxyhttp "github.com/absmach/mproxy/pkg/http"
@@ -28,11 +38,12 @@ import (
 const (
 	instanceID   = "5de9b29a-feb9-11ed-be56-0242ac120002"
 	invalidValue = "invalid"
+	ackTimeout   = time.Second
 )
 
-func newService(things magistrala.ThingsServiceClient) (session.Handler, *pubsub.PubSub) {
+func newService(things magistrala.ThingsServiceClient, idempotency server.IdempotencyCache, ipFilter ipfilter.Filter) (session.Handler, *pubsub.PubSub) {
 	pub := new(pubsub.PubSub)
-	return server.NewHandler(pub, mglog.NewMock(), things), pub
+	return server.NewHandler(pub, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, idempotency, ipFilter, nil, messaging.SigningRules{}, ackTimeout), pub
 }
 
 func newTargetHTTPServer() *httptest.Server {
@@ -49,17 +60,21 @@ func newProxyHTPPServer(svc session.Handler, targetServer *httptest.Server) (*ht
 	if err != nil {
 		return nil, err
 	}
-	return httptest.NewServer(http.HandlerFunc(mp.ServeHTTP)), nil
+	return httptest.NewServer(ipfilter.RemoteIPMiddleware(server.IdempotencyKeyMiddleware(server.PriorityMiddleware(server.ContentTypeMiddleware(server.SignatureMiddleware(server.PublishAckMiddleware(http.HandlerFunc(mp.ServeHTTP)))))))), nil
 }
 
 type testRequest struct {
-	client      *http.Client
-	method      string
-	url         string
-	contentType string
-	token       string
-	body        io.Reader
-	basicAuth   bool
+	client         *http.Client
+	method         string
+	url            string
+	contentType    string
+	token          string
+	idempotencyKey string
+	priority       string
+	ack            bool
+	signature      string
+	body           io.Reader
+	basicAuth      bool
 }
 
 func (tr testRequest) make() (*http.Response, error) {
@@ -77,6 +92,18 @@ func (tr testRequest) make() (*http.Response, error) {
 	if tr.contentType != "" {
 		req.Header.Set("Content-Type", tr.contentType)
 	}
+	if tr.idempotencyKey != "" {
+		req.Header.Set(server.IdempotencyKeyHeader, tr.idempotencyKey)
+	}
+	if tr.priority != "" {
+		req.Header.Set(server.PriorityHeader, tr.priority)
+	}
+	if tr.ack {
+		req.Header.Set(server.PublishAckHeader, "true")
+	}
+	if tr.signature != "" {
+		req.Header.Set(server.SignatureHeader, tr.signature)
+	}
 	return tr.client.Do(req)
 }
 
@@ -91,7 +118,7 @@ func TestPublish(t *testing.T) {
 	msg := `[{"n":"current","t":-1,"v":1.6}]`
 	msgJSON := `{"field1":"val1","field2":"val2"}`
 	msgCBOR := `81A3616E6763757272656E746174206176FB3FF999999999999A`
-	svc, pub := newService(things)
+	svc, pub := newService(things, nil, nil)
 	target := newTargetHTTPServer()
 	defer target.Close()
 	ts, err := newProxyHTPPServer(svc, target)
@@ -99,7 +126,9 @@ func TestPublish(t *testing.T) {
 
 	defer ts.Close()
 
-	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: thingKey, ChannelID: chanID, Permission: "publish"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: ""}, nil)
+	things.On("Authorize", mock.Anything, mock.MatchedBy(func(req *magistrala.ThingsAuthzReq) bool {
+		return req.ThingKey == thingKey && req.ChannelID == chanID && req.Permission == "publish"
+	})).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: ""}, nil)
 	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: false, Id: ""}, nil)
 
 	cases := map[string]struct {
@@ -196,3 +225,664 @@ func TestPublish(t *testing.T) {
 		})
 	}
 }
+
+func TestPublishIdempotency(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	chanID := "1"
+	thingKey := "thing_key"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+
+	stored := map[string]bool{}
+	cache := new(httpmocks.IdempotencyCache)
+	cache.On("Save", mock.Anything, mock.Anything).Return(func(_ context.Context, key string) (bool, error) {
+		if stored[key] {
+			return false, nil
+		}
+		stored[key] = true
+		return true, nil
+	})
+	cache.On("Remove", mock.Anything, mock.Anything).Return(func(_ context.Context, key string) error {
+		delete(stored, key)
+		return nil
+	})
+
+	svc, pub := newService(things, cache, nil)
+	target := newTargetHTTPServer()
+	defer target.Close()
+	ts, err := newProxyHTPPServer(svc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer ts.Close()
+
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing"}, nil)
+
+	cases := []struct {
+		desc           string
+		idempotencyKey string
+		query          string
+		pubErr         error
+		status         int
+		published      bool
+	}{
+		{
+			desc:           "publish message with idempotency key",
+			idempotencyKey: "key",
+			status:         http.StatusAccepted,
+			published:      true,
+		},
+		{
+			desc:           "publish message with the same idempotency key",
+			idempotencyKey: "key",
+			status:         http.StatusAccepted,
+			published:      false,
+		},
+		{
+			desc:           "publish message with different idempotency key",
+			idempotencyKey: "key1",
+			status:         http.StatusAccepted,
+			published:      true,
+		},
+		{
+			desc:      "publish message with the same idempotency key in query",
+			query:     "?idempotency_key=key1",
+			status:    http.StatusAccepted,
+			published: false,
+		},
+		{
+			desc:      "publish message with different idempotency key in query",
+			query:     "?idempotency_key=key2",
+			status:    http.StatusAccepted,
+			published: true,
+		},
+		{
+			desc:      "publish message without idempotency key",
+			status:    http.StatusAccepted,
+			published: true,
+		},
+		{
+			desc:      "publish message again without idempotency key",
+			status:    http.StatusAccepted,
+			published: true,
+		},
+		{
+			desc:           "publish message with idempotency key failed to publish",
+			idempotencyKey: "key3",
+			pubErr:         errors.New("failed to publish"),
+			status:         http.StatusBadRequest,
+			published:      true,
+		},
+		{
+			desc:           "retry failed publish with the same idempotency key",
+			idempotencyKey: "key3",
+			status:         http.StatusAccepted,
+			published:      true,
+		},
+		{
+			desc:           "publish message with too long idempotency key",
+			idempotencyKey: strings.Repeat("k", 257),
+			status:         http.StatusBadRequest,
+			published:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svcCall := pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(tc.pubErr)
+			req := testRequest{
+				client:         ts.Client(),
+				method:         http.MethodPost,
+				url:            fmt.Sprintf("%s/channels/%s/messages%s", ts.URL, chanID, tc.query),
+				contentType:    "application/senml+json",
+				token:          thingKey,
+				idempotencyKey: tc.idempotencyKey,
+				body:           strings.NewReader(msg),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			published := len(pub.Calls) > 0
+			assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+			svcCall.Unset()
+			pub.Calls = nil
+		})
+	}
+}
+
+func TestPublishIPFilter(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	chanID := "1"
+	allowedKey := "allowed_key"
+	deniedKey := "denied_key"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+
+	// Test requests are sent from the loopback address.
+	ipFilter, err := ipfilter.NewStatic(ipfilter.Rules{
+		Things: map[string]ipfilter.List{
+			"allowed": {Allow: []string{"127.0.0.0/8", "::1"}},
+			"denied":  {Deny: []string{"127.0.0.0/8", "::1"}},
+		},
+	})
+	assert.Nil(t, err, fmt.Sprintf("failed to create IP filter with err: %v", err))
+
+	svc, pub := newService(things, nil, ipFilter)
+	target := newTargetHTTPServer()
+	defer target.Close()
+	ts, err := newProxyHTPPServer(svc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer ts.Close()
+
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: allowedKey, ChannelID: chanID, Permission: "publish", ContentType: "application/senml+json", Payload: []byte(msg)}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "allowed"}, nil)
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: deniedKey, ChannelID: chanID, Permission: "publish", ContentType: "application/senml+json", Payload: []byte(msg)}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "denied"}, nil)
+
+	cases := []struct {
+		desc      string
+		key       string
+		status    int
+		published bool
+	}{
+		{
+			desc:      "publish message from allowed IP",
+			key:       allowedKey,
+			status:    http.StatusAccepted,
+			published: true,
+		},
+		{
+			desc:      "publish message from denied IP",
+			key:       deniedKey,
+			status:    http.StatusBadRequest,
+			published: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svcCall := pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			req := testRequest{
+				client:      ts.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+				contentType: "application/senml+json",
+				token:       tc.key,
+				body:        strings.NewReader(msg),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			published := len(pub.Calls) > 0
+			assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+			svcCall.Unset()
+			pub.Calls = nil
+		})
+	}
+}
+
+func TestPublishRateLimit(t *testing.T) {
+	chanID := "1"
+	limitedKey := "limited_key"
+	otherKey := "other_key"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+
+	for _, mode := range []string{ratelimit.Reject, ratelimit.Shed} {
+		t.Run(mode, func(t *testing.T) {
+			things := new(thmocks.ThingsServiceClient)
+			things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: limitedKey, ChannelID: chanID, Permission: "publish", ContentType: "application/senml+json", Payload: []byte(msg)}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "limited", Rate: 1, Burst: 2}, nil)
+			things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: otherKey, ChannelID: chanID, Permission: "publish", ContentType: "application/senml+json", Payload: []byte(msg)}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "other"}, nil)
+
+			// The buckets are not refilled during the test, so each thing
+			// may publish the number of messages of its burst.
+			taken := map[string]uint32{}
+			limiter := new(rlmocks.Limiter)
+			limiter.On("Mode").Return(mode)
+			limiter.On("Allow", mock.Anything, "limited", ratelimit.Limit{Rate: 1, Burst: 2}).Return(func(_ context.Context, thingID string, limit ratelimit.Limit) error {
+				if taken[thingID] == limit.Burst {
+					return ratelimit.ErrRateLimited
+				}
+				taken[thingID]++
+				return nil
+			})
+			limiter.On("Allow", mock.Anything, "other", ratelimit.Limit{}).Return(nil)
+
+			pub := new(pubsub.PubSub)
+			svc := server.NewHandler(pub, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, limiter, messaging.SigningRules{}, ackTimeout)
+			target := newTargetHTTPServer()
+			defer target.Close()
+			ts, err := newProxyHTPPServer(svc, target)
+			assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+			defer ts.Close()
+
+			overStatus := http.StatusBadRequest
+			if mode == ratelimit.Shed {
+				overStatus = http.StatusAccepted
+			}
+			cases := []struct {
+				desc      string
+				key       string
+				status    int
+				published bool
+			}{
+				{
+					desc:      "publish first message under rate",
+					key:       limitedKey,
+					status:    http.StatusAccepted,
+					published: true,
+				},
+				{
+					desc:      "publish second message under rate",
+					key:       limitedKey,
+					status:    http.StatusAccepted,
+					published: true,
+				},
+				{
+					desc:      "publish message over rate",
+					key:       limitedKey,
+					status:    overStatus,
+					published: false,
+				},
+				{
+					desc:      "publish message of other thing",
+					key:       otherKey,
+					status:    http.StatusAccepted,
+					published: true,
+				},
+				{
+					desc:      "publish message over rate again",
+					key:       limitedKey,
+					status:    overStatus,
+					published: false,
+				},
+			}
+
+			for _, tc := range cases {
+				svcCall := pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+				req := testRequest{
+					client:      ts.Client(),
+					method:      http.MethodPost,
+					url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+					contentType: "application/senml+json",
+					token:       tc.key,
+					body:        strings.NewReader(msg),
+				}
+				res, err := req.make()
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+				published := len(pub.Calls) > 0
+				assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+				svcCall.Unset()
+				pub.Calls = nil
+			}
+		})
+	}
+}
+
+func TestPublishContentType(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	chanID := "1"
+	thingKey := "thing_key"
+	ctSenmlJSON := "application/senml+json"
+
+	svc, pub := newService(things, nil, nil)
+	target := newTargetHTTPServer()
+	defer target.Close()
+	ts, err := newProxyHTPPServer(svc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer ts.Close()
+
+	// The channel accepts only SenML JSON messages.
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: thingKey, ChannelID: chanID, Permission: "publish", ContentType: ctSenmlJSON, Payload: []byte(`[{"n":"current","t":-1,"v":1.6}]`)}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing"}, nil)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{}, errors.Wrap(svcerr.ErrAuthorization, thingssvc.ErrContentTypeNotAllowed))
+
+	cases := []struct {
+		desc        string
+		contentType string
+		msg         string
+		status      int
+		published   bool
+	}{
+		{
+			desc:        "publish message with allowed content type",
+			contentType: ctSenmlJSON,
+			msg:         `[{"n":"current","t":-1,"v":1.6}]`,
+			status:      http.StatusAccepted,
+			published:   true,
+		},
+		{
+			desc:        "publish message with not allowed content type",
+			contentType: "application/json",
+			msg:         `{"field1":"val1","field2":"val2"}`,
+			status:      http.StatusBadRequest,
+			published:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svcCall := pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			req := testRequest{
+				client:      ts.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+				contentType: tc.contentType,
+				token:       thingKey,
+				body:        strings.NewReader(tc.msg),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			published := len(pub.Calls) > 0
+			assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+			svcCall.Unset()
+			pub.Calls = nil
+		})
+	}
+}
+
+func TestPublishPriority(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	chanID := "1"
+	thingKey := "thing_key"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+
+	svc, pub := newService(things, nil, nil)
+	target := newTargetHTTPServer()
+	defer target.Close()
+	ts, err := newProxyHTPPServer(svc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer ts.Close()
+
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing"}, nil)
+
+	cases := []struct {
+		desc      string
+		priority  string
+		query     string
+		status    int
+		published bool
+		expected  uint32
+	}{
+		{
+			desc:      "publish message without priority",
+			status:    http.StatusAccepted,
+			published: true,
+			expected:  0,
+		},
+		{
+			desc:      "publish message with priority name in header",
+			priority:  "critical",
+			status:    http.StatusAccepted,
+			published: true,
+			expected:  messaging.PriorityCritical,
+		},
+		{
+			desc:      "publish message with priority number in header",
+			priority:  "1",
+			status:    http.StatusAccepted,
+			published: true,
+			expected:  messaging.PriorityLow,
+		},
+		{
+			desc:      "publish message with priority in query",
+			query:     "?priority=high",
+			status:    http.StatusAccepted,
+			published: true,
+			expected:  messaging.PriorityHigh,
+		},
+		{
+			desc:      "publish message with invalid priority",
+			priority:  "urgent",
+			status:    http.StatusBadRequest,
+			published: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svcCall := pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			req := testRequest{
+				client:      ts.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/channels/%s/messages%s", ts.URL, chanID, tc.query),
+				contentType: "application/senml+json",
+				token:       thingKey,
+				priority:    tc.priority,
+				body:        strings.NewReader(msg),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			published := len(pub.Calls) > 0
+			assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+			if published {
+				priority := pub.Calls[0].Arguments.Get(2).(*messaging.Message).GetPriority()
+				assert.Equal(t, tc.expected, priority, fmt.Sprintf("%s: expected priority %d got %d", tc.desc, tc.expected, priority))
+			}
+			svcCall.Unset()
+			pub.Calls = nil
+		})
+	}
+}
+
+func TestPublishSignature(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	thingKey := "thing_key"
+	signingSecret := "signing_secret"
+	rejectChanID := "1"
+	flagChanID := "2"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	tampered := `[{"n":"current","t":-1,"v":9.6}]`
+	signature := messaging.Sign(signingSecret, []byte(msg))
+
+	signing, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: fmt.Sprintf("%s:reject,%s:flag", rejectChanID, flagChanID)})
+	assert.Nil(t, err, fmt.Sprintf("failed to create signing rules with err: %v", err))
+	pub := new(pubsub.PubSub)
+	svc := server.NewHandler(pub, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, signing, ackTimeout)
+	target := newTargetHTTPServer()
+	defer target.Close()
+	ts, err := newProxyHTPPServer(svc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer ts.Close()
+
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing", SigningSecret: signingSecret}, nil)
+
+	cases := []struct {
+		desc       string
+		chanID     string
+		query      string
+		msg        string
+		signature  string
+		status     int
+		published  bool
+		unverified bool
+	}{
+		{
+			desc:      "publish validly signed message",
+			chanID:    rejectChanID,
+			msg:       msg,
+			signature: signature,
+			status:    http.StatusAccepted,
+			published: true,
+		},
+		{
+			desc:      "publish message signed in query",
+			chanID:    rejectChanID,
+			query:     "?signature=" + signature,
+			msg:       msg,
+			status:    http.StatusAccepted,
+			published: true,
+		},
+		{
+			desc:      "publish tampered message",
+			chanID:    rejectChanID,
+			msg:       tampered,
+			signature: signature,
+			status:    http.StatusBadRequest,
+			published: false,
+		},
+		{
+			desc:      "publish message signed with thing key",
+			chanID:    rejectChanID,
+			msg:       msg,
+			signature: messaging.Sign(thingKey, []byte(msg)),
+			status:    http.StatusBadRequest,
+			published: false,
+		},
+		{
+			desc:      "publish unsigned message",
+			chanID:    rejectChanID,
+			msg:       msg,
+			status:    http.StatusBadRequest,
+			published: false,
+		},
+		{
+			desc:       "publish tampered message to channel flagging unverified messages",
+			chanID:     flagChanID,
+			msg:        tampered,
+			signature:  signature,
+			status:     http.StatusAccepted,
+			published:  true,
+			unverified: true,
+		},
+		{
+			desc:       "publish validly signed message to channel flagging unverified messages",
+			chanID:     flagChanID,
+			msg:        msg,
+			signature:  signature,
+			status:     http.StatusAccepted,
+			published:  true,
+			unverified: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svcCall := pub.On("Publish", mock.Anything, tc.chanID, mock.Anything).Return(nil)
+			req := testRequest{
+				client:      ts.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/channels/%s/messages%s", ts.URL, tc.chanID, tc.query),
+				contentType: "application/senml+json",
+				token:       thingKey,
+				signature:   tc.signature,
+				body:        strings.NewReader(tc.msg),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			published := len(pub.Calls) > 0
+			assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+			if published {
+				m := pub.Calls[0].Arguments.Get(2).(*messaging.Message)
+				assert.Equal(t, tc.unverified, m.GetUnverified(), fmt.Sprintf("%s: expected unverified %t got %t", tc.desc, tc.unverified, m.GetUnverified()))
+			}
+			svcCall.Unset()
+			pub.Calls = nil
+		})
+	}
+}
+
+func TestPublishAck(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	chanID := "1"
+	thingKey := "thing_key"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	receipt := messaging.Receipt{ID: "messages-1"}
+
+	pub := new(pubsub.AckPublisher)
+	svc := server.NewHandler(pub, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, ackTimeout)
+	target := newTargetHTTPServer()
+	defer target.Close()
+	ts, err := newProxyHTPPServer(svc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer ts.Close()
+
+	asyncSvc, asyncPub := newService(things, nil, nil)
+	asyncTS, err := newProxyHTPPServer(asyncSvc, target)
+	assert.Nil(t, err, fmt.Sprintf("failed to create proxy server with err: %v", err))
+	defer asyncTS.Close()
+
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing"}, nil)
+
+	cases := []struct {
+		desc     string
+		server   *httptest.Server
+		ack      bool
+		query    string
+		receipt  messaging.Receipt
+		ackErr   error
+		status   int
+		response string
+	}{
+		{
+			desc:     "publish message with acknowledgment",
+			server:   ts,
+			ack:      true,
+			receipt:  receipt,
+			status:   http.StatusOK,
+			response: fmt.Sprintf(`{"receipt_id":"%s"}`, receipt.ID),
+		},
+		{
+			desc:     "publish message with acknowledgment in query",
+			server:   ts,
+			query:    "?ack=true",
+			receipt:  receipt,
+			status:   http.StatusOK,
+			response: fmt.Sprintf(`{"receipt_id":"%s"}`, receipt.ID),
+		},
+		{
+			desc:   "publish message with acknowledgment rejected by the broker",
+			server: ts,
+			ack:    true,
+			ackErr: errors.New("message rejected by the broker"),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "publish message without acknowledgment",
+			server: ts,
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "publish message with acknowledgment to broker without acknowledgments",
+			server: asyncTS,
+			ack:    true,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ackCall := pub.On("PublishAck", mock.Anything, chanID, mock.Anything).Return(tc.receipt, tc.ackErr)
+			pubCall := pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			asyncCall := asyncPub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			req := testRequest{
+				client:      tc.server.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/channels/%s/messages%s", tc.server.URL, chanID, tc.query),
+				contentType: "application/senml+json",
+				token:       thingKey,
+				ack:         tc.ack,
+				body:        strings.NewReader(msg),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.response != "" {
+				body, err := io.ReadAll(res.Body)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.response, strings.TrimSpace(string(body)), fmt.Sprintf("%s: expected response %s got %s", tc.desc, tc.response, body))
+			}
+			if tc.server == ts {
+				acked := tc.ack || tc.query != ""
+				if acked {
+					pub.AssertCalled(t, "PublishAck", mock.Anything, chanID, mock.Anything)
+					pub.AssertNotCalled(t, "Publish", mock.Anything, chanID, mock.Anything)
+				} else {
+					pub.AssertCalled(t, "Publish", mock.Anything, chanID, mock.Anything)
+					pub.AssertNotCalled(t, "PublishAck", mock.Anything, chanID, mock.Anything)
+				}
+			}
+			ackCall.Unset()
+			pubCall.Unset()
+			asyncCall.Unset()
+			pub.Calls = nil
+			asyncPub.Calls = nil
+		})
+	}
+}
@@ -9,8 +9,9 @@ import (
 )
 
 type publishReq struct {
-	msg   *messaging.Message
-	token string
+	msg     *messaging.Message
+	token   string
+	receipt string
 }
 
 func (req publishReq) validate() error {
@@ -11,9 +11,17 @@ import (
 
 var _ magistrala.Response = (*publishMessageRes)(nil)
 
-type publishMessageRes struct{}
+// publishMessageRes carries the message broker receipt of the publishes
+// waiting for the acknowledgment.
+type publishMessageRes struct {
+	Receipt string `json:"receipt_id,omitempty"`
+}
 
 func (res publishMessageRes) Code() int {
+	if res.Receipt != "" {
+		return http.StatusOK
+	}
+
 	return http.StatusAccepted
 }
 
@@ -22,5 +30,5 @@ func (res publishMessageRes) Headers() map[string]string {
 }
 
 func (res publishMessageRes) Empty() bool {
-	return true
+	return res.Receipt == ""
 }
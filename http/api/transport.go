@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"github.com/absmach/magistrala"
+	adapter "github.com/absmach/magistrala/http"
 	"github.com/absmach/magistrala/internal/api"
 	"github.com/absmach/magistrala/pkg/apiutil"
 	"github.com/absmach/magistrala/pkg/errors"
@@ -74,6 +75,7 @@ func decodeRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	defer r.Body.Close()
 
 	req.msg = &messaging.Message{Payload: payload}
+	req.receipt = r.Header.Get(adapter.ReceiptHeader)
 
 	return req, nil
 }
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cache contains the Redis implementation of the HTTP adapter
+// idempotency cache, which shares idempotency keys between adapter instances.
+package cache
@@ -0,0 +1,49 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	adapter "github.com/absmach/magistrala/http"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "http_idempotency"
+
+var _ adapter.IdempotencyCache = (*idempotencyCache)(nil)
+
+type idempotencyCache struct {
+	client *redis.Client
+	window time.Duration
+}
+
+// NewIdempotencyCache returns Redis idempotency cache which keeps the keys
+// for the deduplication window. Keys expire once the window passes, so the
+// number of stored keys is bounded by the publish rate within the window.
+func NewIdempotencyCache(client *redis.Client, window time.Duration) adapter.IdempotencyCache {
+	return &idempotencyCache{
+		client: client,
+		window: window,
+	}
+}
+
+func (ic *idempotencyCache) Save(ctx context.Context, key string) (bool, error) {
+	return ic.client.SetNX(ctx, idempotencyKey(key), 1, ic.window).Result()
+}
+
+func (ic *idempotencyCache) Remove(ctx context.Context, key string) error {
+	return ic.client.Del(ctx, idempotencyKey(key)).Err()
+}
+
+// idempotencyKey hashes the key so that stored keys have a fixed size.
+func idempotencyKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return fmt.Sprintf("%s:%s", keyPrefix, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	adapter "github.com/absmach/magistrala/http"
+	"github.com/absmach/magistrala/http/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	key  = "thing:channel:key"
+	key1 = "thing:channel:key1"
+)
+
+func TestSave(t *testing.T) {
+	ctx := context.Background()
+	ic := cache.NewIdempotencyCache(redisClient, time.Minute)
+	// Second cache represents another adapter instance sharing the store.
+	ic1 := cache.NewIdempotencyCache(redisClient, time.Minute)
+
+	cases := []struct {
+		desc  string
+		cache adapter.IdempotencyCache
+		key   string
+		saved bool
+	}{
+		{
+			desc:  "save new key",
+			cache: ic,
+			key:   key,
+			saved: true,
+		},
+		{
+			desc:  "save existing key",
+			cache: ic,
+			key:   key,
+			saved: false,
+		},
+		{
+			desc:  "save existing key on another instance",
+			cache: ic1,
+			key:   key,
+			saved: false,
+		},
+		{
+			desc:  "save another key",
+			cache: ic1,
+			key:   key1,
+			saved: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			saved, err := tc.cache.Save(ctx, tc.key)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.saved, saved, fmt.Sprintf("%s: expected %t got %t", tc.desc, tc.saved, saved))
+		})
+	}
+}
+
+func TestSaveExpired(t *testing.T) {
+	ctx := context.Background()
+	ic := cache.NewIdempotencyCache(redisClient, 100*time.Millisecond)
+
+	saved, err := ic.Save(ctx, "expired")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.True(t, saved, "expected key to be saved")
+
+	time.Sleep(200 * time.Millisecond)
+	saved, err = ic.Save(ctx, "expired")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.True(t, saved, "expected expired key to be saved again")
+}
+
+func TestRemove(t *testing.T) {
+	ctx := context.Background()
+	ic := cache.NewIdempotencyCache(redisClient, time.Minute)
+
+	saved, err := ic.Save(ctx, "removed")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.True(t, saved, "expected key to be saved")
+
+	err = ic.Remove(ctx, "removed")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error on remove %s", err))
+
+	saved, err = ic.Save(ctx, "removed")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.True(t, saved, "expected removed key to be saved again")
+}
@@ -0,0 +1,23 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/absmach/magistrala/pkg/messaging"
+)
+
+// ContentTypeMiddleware stores the value of the Content-Type header in the
+// request context, so the publish handler can check that the channel accepts
+// the content type. The publishers without the header can set the content
+// type in the query of the topic instead.
+func ContentTypeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "" {
+			r = r.WithContext(messaging.WithContentType(r.Context(), ct))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/absmach/magistrala/pkg/apiutil"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 	"github.com/absmach/mproxy/pkg/session"
 )
 
@@ -27,8 +29,13 @@ const protocol = "http"
 
 // Log message formats.
 const (
-	logInfoConnected = "connected with thing_key %s"
-	logInfoPublished = "published with client_id %s to the topic %s"
+	logInfoConnected  = "connected with thing_key %s"
+	logInfoPublished  = "published with client_id %s to the topic %s"
+	logInfoDuplicate  = "skipped duplicate publish with idempotency key %s to the channel %s"
+	logWarnIPDenied   = "rejected publish from %s: %s"
+	logWarnFlagged    = "flagged unverified message of thing %s on the channel %s"
+	logWarnRateReject = "rejected message of thing %s over the publish rate: %s"
+	logWarnRateShed   = "shed message of thing %s over the publish rate"
 )
 
 // Error wrappers for MQTT errors.
@@ -46,17 +53,38 @@ var channelRegExp = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/messages(\/[^?]
 
 // Event implements events.Event interface.
 type handler struct {
-	publisher messaging.Publisher
-	things    magistrala.ThingsServiceClient
-	logger    *slog.Logger
+	publisher   messaging.Publisher
+	things      magistrala.ThingsServiceClient
+	subtopics   messaging.SubtopicRules
+	topics      messaging.TopicScheme
+	idempotency IdempotencyCache
+	ipFilter    ipfilter.Filter
+	limiter     ratelimit.Limiter
+	signing     messaging.SigningRules
+	ackTimeout  time.Duration
+	logger      *slog.Logger
 }
 
-// NewHandler creates new Handler entity.
-func NewHandler(publisher messaging.Publisher, logger *slog.Logger, thingsClient magistrala.ThingsServiceClient) session.Handler {
+// NewHandler creates new Handler entity. If the idempotency cache is not nil,
+// publishes with an idempotency key already seen by the cache are skipped.
+// If the IP filter is not nil, publishes from IP addresses it rejects are
+// denied. If the rate limiter is not nil, publishes of things over their rate
+// are rejected or shed. Payloads published to the channels requiring
+// signatures are verified with the signing secret of the thing. Publishes requesting the
+// acknowledgment wait for the message broker at most the ack timeout. The
+// messages are published to the topics of the topic scheme.
+func NewHandler(publisher messaging.Publisher, logger *slog.Logger, thingsClient magistrala.ThingsServiceClient, subtopics messaging.SubtopicRules, topics messaging.TopicScheme, idempotency IdempotencyCache, ipFilter ipfilter.Filter, limiter ratelimit.Limiter, signing messaging.SigningRules, ackTimeout time.Duration) session.Handler {
 	return &handler{
-		logger:    logger,
-		publisher: publisher,
-		things:    thingsClient,
+		logger:      logger,
+		publisher:   publisher,
+		things:      thingsClient,
+		subtopics:   subtopics,
+		topics:      topics,
+		idempotency: idempotency,
+		ipFilter:    ipFilter,
+		limiter:     limiter,
+		signing:     signing,
+		ackTimeout:  ackTimeout,
 	}
 }
 
@@ -102,6 +130,16 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	if topic == nil {
 		return errMissingTopicPub
 	}
+	idemKey, err := idempotencyKeyFrom(ctx, *topic)
+	if err != nil {
+		return errors.Wrap(errFailedPublish, err)
+	}
+	priority, err := priorityFrom(ctx, *topic)
+	if err != nil {
+		return errors.Wrap(errFailedPublish, err)
+	}
+	signature := messaging.SignatureFrom(ctx, *topic)
+	contentType := messaging.ContentTypeFrom(ctx, *topic)
 	topic = &strings.Split(*topic, "?")[0]
 	s, ok := session.FromContext(ctx)
 	if !ok {
@@ -119,17 +157,21 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	chanID := channelParts[1]
 	subtopic := channelParts[2]
 
-	subtopic, err := parseSubtopic(subtopic)
+	subtopic, err = parseSubtopic(subtopic)
 	if err != nil {
 		return errors.Wrap(errFailedParseSubtopic, err)
 	}
+	if subtopic, err = h.subtopics.Apply(subtopic); err != nil {
+		return errors.Wrap(errFailedParseSubtopic, err)
+	}
 
 	msg := messaging.Message{
-		Protocol: protocol,
+		Protocol: messaging.ProtocolFrom(ctx, protocol),
 		Channel:  chanID,
 		Subtopic: subtopic,
 		Payload:  *payload,
 		Created:  time.Now().UnixNano(),
+		Priority: priority,
 	}
 	var tok string
 	switch {
@@ -140,10 +182,17 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	default:
 		tok = string(s.Password)
 	}
+	// Default rules are checked before authorization, so denied addresses
+	// don't load the things service.
+	if err := h.checkIP(ctx, ""); err != nil {
+		return err
+	}
 	ar := &magistrala.ThingsAuthzReq{
-		ThingKey:   tok,
-		ChannelID:  msg.Channel,
-		Permission: policies.PublishPermission,
+		ThingKey:    tok,
+		ChannelID:   msg.Channel,
+		Permission:  policies.PublishPermission,
+		ContentType: contentType,
+		Payload:     msg.Payload,
 	}
 	res, err := h.things.Authorize(ctx, ar)
 	if err != nil {
@@ -153,14 +202,81 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 		return svcerr.ErrAuthorization
 	}
 	msg.Publisher = res.GetId()
+	if err := h.checkIP(ctx, msg.Publisher); err != nil {
+		return err
+	}
+	allowed, err := messaging.CheckRate(ctx, h.limiter, res)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf(logWarnRateReject, res.GetId(), err))
+		return errors.Wrap(errFailedPublish, err)
+	}
+	if !allowed {
+		h.logger.Warn(fmt.Sprintf(logWarnRateShed, res.GetId()))
+		return nil
+	}
+	if err := h.signing.Check(&msg, res.GetSigningSecret(), signature); err != nil {
+		return errors.Wrap(errFailedPublish, err)
+	}
+	if msg.GetUnverified() {
+		h.logger.Warn(fmt.Sprintf(logWarnFlagged, msg.Publisher, msg.Channel))
+	}
 
-	if err := h.publisher.Publish(ctx, msg.Channel, &msg); err != nil {
+	cacheKey := ""
+	if h.idempotency != nil && idemKey != "" {
+		key := fmt.Sprintf("%s:%s:%s", msg.Publisher, msg.Channel, idemKey)
+		saved, err := h.idempotency.Save(ctx, key)
+		switch {
+		case err != nil:
+			// The message is published if the cache is unavailable, since
+			// a duplicate is preferable to a lost message.
+			h.logger.Warn(fmt.Sprintf("failed to save idempotency key: %s", err))
+		case !saved:
+			h.logger.Info(fmt.Sprintf(logInfoDuplicate, idemKey, msg.Channel))
+			return nil
+		default:
+			cacheKey = key
+		}
+	}
+
+	if err := h.publish(ctx, h.topics.Topic(res.GetDomainId(), msg.Channel), &msg); err != nil {
+		if cacheKey != "" {
+			// Remove the key so that the retried publish is not skipped.
+			if err := h.idempotency.Remove(ctx, cacheKey); err != nil {
+				h.logger.Warn(fmt.Sprintf("failed to remove idempotency key: %s", err))
+			}
+		}
 		return errors.Wrap(errFailedPublishToMsgBroker, err)
 	}
 
 	return nil
 }
 
+// publish publishes the message, waiting for the message broker
+// acknowledgment if the request asks for it. The receipt is added to the
+// request headers, so the HTTP API returns it.
+func (h *handler) publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	ar, ok := ackRequestFrom(ctx)
+	if !ok {
+		return h.publisher.Publish(ctx, topic, msg)
+	}
+	ap, ok := h.publisher.(messaging.AckPublisher)
+	if !ok {
+		return messaging.ErrAckNotSupported
+	}
+	if h.ackTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.ackTimeout)
+		defer cancel()
+	}
+	receipt, err := ap.PublishAck(ctx, topic, msg)
+	if err != nil {
+		return err
+	}
+	ar.header.Set(ReceiptHeader, receipt.ID)
+
+	return nil
+}
+
 // Subscribe - not used for HTTP.
 func (h *handler) Subscribe(ctx context.Context, topics *[]string) error {
 	return nil
@@ -176,6 +292,19 @@ func (h *handler) Disconnect(ctx context.Context) error {
 	return nil
 }
 
+func (h *handler) checkIP(ctx context.Context, thingID string) error {
+	if h.ipFilter == nil {
+		return nil
+	}
+	ip := ipfilter.RemoteIP(ctx)
+	if err := h.ipFilter.Check(ip, thingID); err != nil {
+		h.logger.Warn(fmt.Sprintf(logWarnIPDenied, ip, err))
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+
+	return nil
+}
+
 func parseSubtopic(subtopic string) (string, error) {
 	if subtopic == "" {
 		return subtopic, nil
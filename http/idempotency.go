@@ -0,0 +1,70 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	// IdempotencyKeyHeader is the HTTP header carrying the publish idempotency key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotencyKeyParam is the query parameter carrying the publish
+	// idempotency key, for clients which can not set custom headers.
+	idempotencyKeyParam = "idempotency_key"
+
+	maxIdempotencyKeyLen = 256
+)
+
+var errInvalidIdempotencyKey = errors.New("idempotency key must not be longer than 256 characters")
+
+// IdempotencyCache stores the idempotency keys of the published messages so
+// that retried publishes are not published again.
+//
+//go:generate mockery --name IdempotencyCache --output=./mocks --filename idempotency.go --quiet --note "Copyright (c) Abstract Machines"
+type IdempotencyCache interface {
+	// Save stores the key unless it is already stored and reports whether
+	// the key was stored.
+	Save(ctx context.Context, key string) (bool, error)
+
+	// Remove removes the key, so that the message can be published again.
+	Remove(ctx context.Context, key string) error
+}
+
+type idempotencyKey struct{}
+
+// IdempotencyKeyMiddleware stores the value of the Idempotency-Key header in
+// the request context, so it is available to the publish handler.
+func IdempotencyKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+			r = r.WithContext(context.WithValue(r.Context(), idempotencyKey{}, key))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// idempotencyKeyFrom returns the idempotency key of the request, set either
+// in the header or in the query of the topic.
+func idempotencyKeyFrom(ctx context.Context, topic string) (string, error) {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	if key == "" {
+		if _, query, ok := strings.Cut(topic, "?"); ok {
+			if values, err := url.ParseQuery(query); err == nil {
+				key = values.Get(idempotencyKeyParam)
+			}
+		}
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return "", errInvalidIdempotencyKey
+	}
+
+	return key, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/messaging"
+)
+
+const (
+	// PriorityHeader is the HTTP header carrying the priority of the
+	// published message, as a name such as high or a number.
+	PriorityHeader = "Message-Priority"
+
+	// priorityParam is the query parameter carrying the priority of the
+	// published message, for clients which can not set custom headers.
+	priorityParam = "priority"
+)
+
+type priorityKey struct{}
+
+// PriorityMiddleware stores the value of the Message-Priority header in the
+// request context, so it is available to the publish handler.
+func PriorityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.Header.Get(PriorityHeader); p != "" {
+			r = r.WithContext(context.WithValue(r.Context(), priorityKey{}, p))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// priorityFrom returns the priority of the request, set either in the header
+// or in the query of the topic, or 0 if it isn't set.
+func priorityFrom(ctx context.Context, topic string) (uint32, error) {
+	p, _ := ctx.Value(priorityKey{}).(string)
+	if p == "" {
+		if _, query, ok := strings.Cut(topic, "?"); ok {
+			if values, err := url.ParseQuery(query); err == nil {
+				p = values.Get(priorityParam)
+			}
+		}
+	}
+	if p == "" {
+		return 0, nil
+	}
+
+	return messaging.ParsePriority(p)
+}
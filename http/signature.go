@@ -0,0 +1,27 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"net/http"
+
+	"github.com/absmach/magistrala/pkg/messaging"
+)
+
+// SignatureHeader is the HTTP header carrying the signature of the published
+// payload.
+const SignatureHeader = "Message-Signature"
+
+// SignatureMiddleware stores the value of the Message-Signature header in the
+// request context, so the publish handler can verify the payload. The clients
+// which can not set custom headers can set the signature in the query of the
+// topic instead.
+func SignatureMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sig := r.Header.Get(SignatureHeader); sig != "" {
+			r = r.WithContext(messaging.WithSignature(r.Context(), sig))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
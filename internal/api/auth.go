@@ -5,10 +5,14 @@ package api
 
 import (
 	"context"
+	"crypto/x509"
 	"net/http"
 
 	"github.com/absmach/magistrala/pkg/apiutil"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/authn/dpop"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -20,12 +24,25 @@ func AuthenticateMiddleware(authn mgauthn.Authentication, domainCheck bool) func
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := apiutil.ExtractBearerToken(r)
-			if token == "" {
-				EncodeError(r.Context(), apiutil.ErrBearerToken, w)
+			cert, certAuthn := clientCert(r, authn)
+
+			ctx, err := bindingContext(r, token)
+			if err != nil {
+				EncodeError(r.Context(), err, w)
 				return
 			}
 
-			resp, err := authn.Authenticate(r.Context(), token)
+			var resp mgauthn.Session
+			switch {
+			case token != "" && cert != nil:
+				err = apiutil.ErrMultipleCredentials
+			case cert != nil:
+				resp, err = certAuthn.AuthenticateCert(ctx, cert)
+			case token != "":
+				resp, err = authn.Authenticate(ctx, token)
+			default:
+				err = apiutil.ErrBearerToken
+			}
 			if err != nil {
 				EncodeError(r.Context(), err, w)
 				return
@@ -41,9 +58,68 @@ func AuthenticateMiddleware(authn mgauthn.Authentication, domainCheck bool) func
 				resp.DomainUserID = domain + "_" + resp.UserID
 			}
 
-			ctx := context.WithValue(r.Context(), SessionKey, resp)
+			ctx = mgauthn.WithClaims(ctx, resp.Claims)
+			ctx = context.WithValue(ctx, SessionKey, resp)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// TokenBindingMiddleware verifies the DPoP proof the request carries, if any,
+// so the tokens issued within the request are bound to the proof key.
+// AuthenticateMiddleware verifies the proof as well, so it's only needed by
+// the endpoints issuing tokens without authenticating the request.
+func TokenBindingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := bindingContext(r, "")
+		if err != nil {
+			EncodeError(r.Context(), err, w)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bindingContext returns the request context carrying the thumbprint of the
+// key the DPoP proof of the request is signed with. The proof of the request
+// presenting the token must carry the token hash. Requests without a proof
+// are left unbound, so the binding is opt-in for the clients.
+func bindingContext(r *http.Request, token string) (context.Context, error) {
+	proof := r.Header.Get(dpop.Header)
+	if proof == "" {
+		return r.Context(), nil
+	}
+	binding, err := dpop.Verify(proof, r.Method, r.URL.Path, token)
+	if err != nil {
+		return nil, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+
+	return mgauthn.WithBinding(r.Context(), binding), nil
+}
+
+// clientCert returns the verified client certificate of the request if
+// authentication supports client certificates.
+func clientCert(r *http.Request, authn mgauthn.Authentication) (*x509.Certificate, mgauthn.CertAuthentication) {
+	certAuthn, ok := authn.(mgauthn.CertAuthentication)
+	if !ok || r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+
+	return r.TLS.VerifiedChains[0][0], certAuthn
+}
+
+// AuthorizeHealth returns a check that accepts requests carrying a valid
+// bearer token. It is used to gate sensitive health information.
+func AuthorizeHealth(authn mgauthn.Authentication) func(r *http.Request) error {
+	return func(r *http.Request) error {
+		token := apiutil.ExtractBearerToken(r)
+		if token == "" {
+			return apiutil.ErrBearerToken
+		}
+		_, err := authn.Authenticate(r.Context(), token)
+
+		return err
+	}
+}
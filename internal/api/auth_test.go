@@ -0,0 +1,265 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/internal/testsutil"
+	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/authn/dpop"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	validToken = "valid"
+	certUser   = "cert-user"
+	tokenUser  = "token-user"
+)
+
+type certAuthn struct{}
+
+func (certAuthn) Authenticate(_ context.Context, token string) (mgauthn.Session, error) {
+	if token != validToken {
+		return mgauthn.Session{}, svcerr.ErrAuthentication
+	}
+	return mgauthn.Session{UserID: tokenUser, DomainUserID: tokenUser}, nil
+}
+
+func (certAuthn) AuthenticateCert(_ context.Context, cert *x509.Certificate) (mgauthn.Session, error) {
+	if cert.Subject.CommonName != certUser {
+		return mgauthn.Session{}, svcerr.ErrAuthentication
+	}
+	return mgauthn.Session{UserID: certUser, DomainUserID: certUser}, nil
+}
+
+type tokenAuthn struct{}
+
+func (tokenAuthn) Authenticate(ctx context.Context, token string) (mgauthn.Session, error) {
+	return certAuthn{}.Authenticate(ctx, token)
+}
+
+func TestAuthenticateMiddleware(t *testing.T) {
+	verified := func(cn string) *tls.ConnectionState {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	cases := []struct {
+		desc   string
+		authn  mgauthn.Authentication
+		token  string
+		tls    *tls.ConnectionState
+		status int
+		userID string
+	}{
+		{
+			desc:   "authenticate with valid token",
+			authn:  certAuthn{},
+			token:  validToken,
+			status: http.StatusOK,
+			userID: tokenUser,
+		},
+		{
+			desc:   "authenticate with mapped client certificate",
+			authn:  certAuthn{},
+			tls:    verified(certUser),
+			status: http.StatusOK,
+			userID: certUser,
+		},
+		{
+			desc:   "authenticate with unmapped client certificate",
+			authn:  certAuthn{},
+			tls:    verified("unknown"),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate with both token and client certificate",
+			authn:  certAuthn{},
+			token:  validToken,
+			tls:    verified(certUser),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "authenticate with unverified client certificate",
+			authn:  certAuthn{},
+			tls:    &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: certUser}}}},
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate with client certificate without certificate support",
+			authn:  tokenAuthn{},
+			tls:    verified(certUser),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate with token and client certificate without certificate support",
+			authn:  tokenAuthn{},
+			token:  validToken,
+			tls:    verified(certUser),
+			status: http.StatusOK,
+			userID: tokenUser,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var session mgauthn.Session
+			handler := api.AuthenticateMiddleware(tc.authn, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				session, _ = r.Context().Value(api.SessionKey).(mgauthn.Session)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			req.TLS = tc.tls
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.status, res.Code, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.Code))
+			assert.Equal(t, tc.userID, session.UserID, fmt.Sprintf("%s: expected user %s got %s", tc.desc, tc.userID, session.UserID))
+		})
+	}
+}
+
+// boundAuthn accepts the valid token only if it's presented with the proof of
+// the key the token is bound to.
+type boundAuthn struct {
+	binding string
+}
+
+func (a boundAuthn) Authenticate(ctx context.Context, token string) (mgauthn.Session, error) {
+	if token != validToken || mgauthn.Binding(ctx) != a.binding {
+		return mgauthn.Session{}, svcerr.ErrAuthentication
+	}
+	return mgauthn.Session{UserID: tokenUser, DomainUserID: tokenUser}, nil
+}
+
+func newProofKey(t *testing.T) (jwk.Key, string) {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generating key expected to succeed: %s", err))
+	key, err := jwk.FromRaw(raw)
+	require.Nil(t, err, fmt.Sprintf("creating JWK expected to succeed: %s", err))
+	tp, err := key.Thumbprint(crypto.SHA256)
+	require.Nil(t, err, fmt.Sprintf("computing thumbprint expected to succeed: %s", err))
+
+	return key, base64.RawURLEncoding.EncodeToString(tp)
+}
+
+func newProof(t *testing.T, key jwk.Key, method, path, token string) string {
+	pub, err := key.PublicKey()
+	require.Nil(t, err, fmt.Sprintf("retrieving public key expected to succeed: %s", err))
+	headers := jws.NewHeaders()
+	require.Nil(t, headers.Set(jws.TypeKey, dpop.ProofType))
+	require.Nil(t, headers.Set(jws.JWKKey, pub))
+	payload := fmt.Sprintf(`{"jti":"%s","htm":"%s","htu":"http://localhost%s","iat":%d,"ath":"%s"}`, testsutil.GenerateUUID(t), method, path, time.Now().Unix(), dpop.AccessTokenHash(token))
+	proof, err := jws.Sign([]byte(payload), jws.WithKey(jwa.ES256, key, jws.WithProtectedHeaders(headers)))
+	require.Nil(t, err, fmt.Sprintf("signing proof expected to succeed: %s", err))
+
+	return string(proof)
+}
+
+func TestAuthenticateMiddlewareBinding(t *testing.T) {
+	key, binding := newProofKey(t)
+	otherKey, _ := newProofKey(t)
+	proof := newProof(t, key, http.MethodGet, "/", validToken)
+
+	cases := []struct {
+		desc   string
+		authn  mgauthn.Authentication
+		proof  string
+		status int
+		userID string
+	}{
+		{
+			desc:   "authenticate bound token with its proof",
+			authn:  boundAuthn{binding: binding},
+			proof:  proof,
+			status: http.StatusOK,
+			userID: tokenUser,
+		},
+		{
+			desc:   "authenticate bound token without proof",
+			authn:  boundAuthn{binding: binding},
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate bound token with replayed proof",
+			authn:  boundAuthn{binding: binding},
+			proof:  proof,
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate bound token with proof of different key",
+			authn:  boundAuthn{binding: binding},
+			proof:  newProof(t, otherKey, http.MethodGet, "/", validToken),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate bound token with proof of different request",
+			authn:  boundAuthn{binding: binding},
+			proof:  newProof(t, key, http.MethodGet, "/users", validToken),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate bound token with proof of different token",
+			authn:  boundAuthn{binding: binding},
+			proof:  newProof(t, key, http.MethodGet, "/", "other"),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate bound token with invalid proof",
+			authn:  boundAuthn{binding: binding},
+			proof:  "invalid",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "authenticate unbound token with proof",
+			authn:  certAuthn{},
+			proof:  newProof(t, key, http.MethodGet, "/", validToken),
+			status: http.StatusOK,
+			userID: tokenUser,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var session mgauthn.Session
+			handler := api.AuthenticateMiddleware(tc.authn, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				session, _ = r.Context().Value(api.SessionKey).(mgauthn.Session)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+validToken)
+			if tc.proof != "" {
+				req.Header.Set(dpop.Header, tc.proof)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.status, res.Code, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.Code))
+			assert.Equal(t, tc.userID, session.UserID, fmt.Sprintf("%s: expected user %s got %s", tc.desc, tc.userID, session.UserID))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/absmach/magistrala/pkg/apiutil"
+)
+
+// BodyLimitMiddleware rejects requests with bodies larger than maxSize bytes
+// with 413 Request Entity Too Large. Requests declaring a larger Content-Length
+// are rejected before the body is read, while the bodies of unknown length
+// fail to read once they exceed the limit. Multipart requests are limited
+// too, so the handlers parsing them never buffer more than maxSize bytes. The
+// limit is disabled if maxSize is not positive.
+func BodyLimitMiddleware(maxSize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxSize <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxSize {
+				EncodeError(context.Background(), apiutil.ErrRequestTooLarge, w)
+				return
+			}
+			r.Body = limitedBody{http.MaxBytesReader(w, r.Body, maxSize)}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// limitedBody replaces the error of reading over the limit with the API error.
+type limitedBody struct {
+	io.ReadCloser
+}
+
+func (b limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return n, apiutil.ErrRequestTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const maxBodySize = 64
+
+// chunkedReader hides the body length, so the request is sent chunked.
+type chunkedReader struct {
+	io.Reader
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	// The handler decodes the body the way the API decoders do.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			api.EncodeError(context.Background(), errors.Wrap(apiutil.ErrValidation, errors.Wrap(errors.ErrMalformedEntity, err)), w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		desc        string
+		maxSize     int64
+		size        int
+		chunked     bool
+		contentType string
+		status      int
+	}{
+		{
+			desc:        "request with body at the limit",
+			maxSize:     maxBodySize,
+			size:        maxBodySize,
+			contentType: api.ContentType,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "request with body over the limit",
+			maxSize:     maxBodySize,
+			size:        maxBodySize + 1,
+			contentType: api.ContentType,
+			status:      http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:        "chunked request with body at the limit",
+			maxSize:     maxBodySize,
+			size:        maxBodySize,
+			chunked:     true,
+			contentType: api.ContentType,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "chunked request with body over the limit",
+			maxSize:     maxBodySize,
+			size:        maxBodySize * 4,
+			chunked:     true,
+			contentType: api.ContentType,
+			status:      http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:        "multipart request with body over the limit",
+			maxSize:     maxBodySize,
+			size:        maxBodySize + 1,
+			contentType: "multipart/form-data; boundary=boundary",
+			status:      http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:        "chunked multipart request with body over the limit",
+			maxSize:     maxBodySize,
+			size:        maxBodySize * 4,
+			chunked:     true,
+			contentType: "multipart/form-data; boundary=boundary",
+			status:      http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:        "request with body over the disabled limit",
+			maxSize:     0,
+			size:        maxBodySize + 1,
+			contentType: api.ContentType,
+			status:      http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(api.BodyLimitMiddleware(tc.maxSize)(handler))
+			defer ts.Close()
+
+			var body io.Reader = strings.NewReader(strings.Repeat("a", tc.size))
+			if tc.chunked {
+				body = chunkedReader{body}
+			}
+			req, err := http.NewRequest(http.MethodPost, ts.URL, body)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			req.Header.Set("Content-Type", tc.contentType)
+
+			res, err := ts.Client().Do(req)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			defer res.Body.Close()
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusRequestEntityTooLarge {
+				resBody, err := io.ReadAll(res.Body)
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Contains(t, string(resBody), apiutil.ErrRequestTooLarge.Error(), fmt.Sprintf("%s: expected error %s got %s", tc.desc, apiutil.ErrRequestTooLarge, resBody))
+			}
+		})
+	}
+}
@@ -11,10 +11,13 @@ import (
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/bootstrap"
 	"github.com/absmach/magistrala/certs"
+	"github.com/absmach/magistrala/internal/email"
 	"github.com/absmach/magistrala/pkg/apiutil"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/jobs"
+	"github.com/absmach/magistrala/pkg/quotas"
 	"github.com/gofrs/uuid/v5"
 )
 
@@ -42,9 +45,13 @@ const (
 	TreeKey          = "tree"
 	DirKey           = "dir"
 	ListPerms        = "list_perms"
+	TotalUnfiltered  = "total_unfiltered"
 	VisibilityKey    = "visibility"
 	SharedByKey      = "shared_by"
 	TokenKey         = "token"
+	AsyncKey         = "async"
+	OAuthProviderKey = "oauth_provider"
+	IDsKey           = "ids"
 	DefPermission    = "view"
 	DefTotal         = uint64(100)
 	DefOffset        = 0
@@ -56,6 +63,7 @@ const (
 	DefClientStatus  = mgclients.Enabled
 	DefGroupStatus   = mgclients.Enabled
 	DefListPerms     = false
+	DefUnfiltered    = false
 	SharedVisibility = "shared"
 	MyVisibility     = "mine"
 	AllVisibility    = "all"
@@ -71,6 +79,29 @@ const (
 	DescDir      = "desc"
 )
 
+// PageLimits defines the page size used when the limit is omitted from a
+// list request and the largest page size a list request may ask for.
+type PageLimits struct {
+	Default uint64
+	Max     uint64
+}
+
+// NewPageLimits returns page limits. Zero values fall back to DefLimit and
+// MaxLimitSize, and the default never exceeds the maximum.
+func NewPageLimits(defLimit, maxLimit uint64) PageLimits {
+	if maxLimit == 0 {
+		maxLimit = MaxLimitSize
+	}
+	if defLimit == 0 {
+		defLimit = DefLimit
+	}
+	if defLimit > maxLimit {
+		defLimit = maxLimit
+	}
+
+	return PageLimits{Default: defLimit, Max: maxLimit}
+}
+
 // ValidateUUID validates UUID format.
 func ValidateUUID(extID string) (err error) {
 	id, err := uuid.FromString(extID)
@@ -107,6 +138,20 @@ func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 
 	w.Header().Set("Content-Type", ContentType)
 	switch {
+	// Oversized bodies are reported as the decoding errors, so they are
+	// matched before the malformed entity errors.
+	case errors.Contains(err, apiutil.ErrRequestTooLarge):
+		err = apiutil.ErrRequestTooLarge
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+
+	case errors.Contains(err, quotas.ErrQuotaExceeded):
+		err = quotas.ErrQuotaExceeded
+		w.WriteHeader(http.StatusForbidden)
+
+	case errors.Contains(err, svcerr.ErrSelfRegistrationDisabled):
+		err = svcerr.ErrSelfRegistrationDisabled
+		w.WriteHeader(http.StatusForbidden)
+
 	case errors.Contains(err, svcerr.ErrAuthorization),
 		errors.Contains(err, svcerr.ErrDomainAuthorization),
 		errors.Contains(err, bootstrap.ErrExternalKey),
@@ -114,6 +159,12 @@ func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 		err = unwrap(err)
 		w.WriteHeader(http.StatusForbidden)
 
+	case errors.Contains(err, svcerr.ErrMFAEnrollmentRequired):
+		err = svcerr.ErrMFAEnrollmentRequired
+		w.WriteHeader(http.StatusUnauthorized)
+	case errors.Contains(err, svcerr.ErrMFACode):
+		err = svcerr.ErrMFACode
+		w.WriteHeader(http.StatusUnauthorized)
 	case errors.Contains(err, svcerr.ErrAuthentication),
 		errors.Contains(err, apiutil.ErrBearerToken),
 		errors.Contains(err, svcerr.ErrLogin):
@@ -128,20 +179,26 @@ func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 		errors.Contains(err, apiutil.ErrMissingAlias),
 		errors.Contains(err, apiutil.ErrMissingEmail),
 		errors.Contains(err, apiutil.ErrMissingHost),
+		errors.Contains(err, apiutil.ErrMissingCode),
 		errors.Contains(err, apiutil.ErrInvalidResetPass),
 		errors.Contains(err, apiutil.ErrEmptyList),
 		errors.Contains(err, apiutil.ErrMissingMemberKind),
 		errors.Contains(err, apiutil.ErrMissingMemberType),
 		errors.Contains(err, apiutil.ErrLimitSize),
 		errors.Contains(err, apiutil.ErrBearerKey),
+		errors.Contains(err, apiutil.ErrMultipleCredentials),
 		errors.Contains(err, svcerr.ErrInvalidStatus),
 		errors.Contains(err, apiutil.ErrNameSize),
+		errors.Contains(err, apiutil.ErrMaxTags),
+		errors.Contains(err, apiutil.ErrTagSize),
+		errors.Contains(err, apiutil.ErrMetadataSize),
 		errors.Contains(err, apiutil.ErrInvalidIDFormat),
 		errors.Contains(err, apiutil.ErrInvalidQueryParams),
 		errors.Contains(err, apiutil.ErrMissingRelation),
 		errors.Contains(err, apiutil.ErrValidation),
 		errors.Contains(err, apiutil.ErrMissingIdentity),
 		errors.Contains(err, apiutil.ErrMissingPass),
+		errors.Contains(err, apiutil.ErrMissingMFACode),
 		errors.Contains(err, apiutil.ErrMissingConfPass),
 		errors.Contains(err, apiutil.ErrPasswordFormat),
 		errors.Contains(err, svcerr.ErrInvalidRole),
@@ -157,6 +214,7 @@ func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 		errors.Contains(err, apiutil.ErrInvalidCertData),
 		errors.Contains(err, apiutil.ErrEmptyMessage),
 		errors.Contains(err, apiutil.ErrInvalidLevel),
+		errors.Contains(err, apiutil.ErrInvalidOrder),
 		errors.Contains(err, apiutil.ErrInvalidDirection),
 		errors.Contains(err, apiutil.ErrInvalidEntityType),
 		errors.Contains(err, apiutil.ErrMissingEntityType),
@@ -184,14 +242,34 @@ func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 	case errors.Contains(err, errors.ErrStatusAlreadyAssigned),
 		errors.Contains(err, svcerr.ErrInvitationAlreadyRejected),
 		errors.Contains(err, svcerr.ErrInvitationAlreadyAccepted),
-		errors.Contains(err, svcerr.ErrConflict):
+		errors.Contains(err, svcerr.ErrConflict),
+		errors.Contains(err, svcerr.ErrConcurrentUpdate),
+		errors.Contains(err, jobs.ErrFinished):
 		err = unwrap(err)
 		w.WriteHeader(http.StatusConflict)
 
+	case errors.Contains(err, email.ErrParseTemplate),
+		errors.Contains(err, email.ErrExecTemplate):
+		// The template error is kept, since it locates the template mistake.
+		w.WriteHeader(http.StatusUnprocessableEntity)
+
 	case errors.Contains(err, apiutil.ErrUnsupportedContentType):
 		err = unwrap(err)
 		w.WriteHeader(http.StatusUnsupportedMediaType)
 
+	case errors.Contains(err, apiutil.ErrTooManyRequests):
+		err = unwrap(err)
+		w.WriteHeader(http.StatusTooManyRequests)
+
+	case errors.Contains(err, apiutil.ErrRequestTimeout):
+		err = apiutil.ErrRequestTimeout
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+	case errors.Contains(err, svcerr.ErrServiceUnavailable):
+		// The cause is not reported, since it describes the internals.
+		err = svcerr.ErrServiceUnavailable
+		w.WriteHeader(http.StatusServiceUnavailable)
+
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
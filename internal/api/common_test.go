@@ -109,6 +109,44 @@ func TestValidateUUID(t *testing.T) {
 	}
 }
 
+func TestNewPageLimits(t *testing.T) {
+	cases := []struct {
+		desc     string
+		def      uint64
+		max      uint64
+		expected api.PageLimits
+	}{
+		{
+			desc:     "page limits with zero values",
+			expected: api.PageLimits{Default: api.DefLimit, Max: api.MaxLimitSize},
+		},
+		{
+			desc:     "page limits with lowered max",
+			def:      5,
+			max:      20,
+			expected: api.PageLimits{Default: 5, Max: 20},
+		},
+		{
+			desc:     "page limits with raised max",
+			max:      1000,
+			expected: api.PageLimits{Default: api.DefLimit, Max: 1000},
+		},
+		{
+			desc:     "page limits with default above max",
+			def:      50,
+			max:      20,
+			expected: api.PageLimits{Default: 20, Max: 20},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			pl := api.NewPageLimits(c.def, c.max)
+			assert.Equal(t, c.expected, pl)
+		})
+	}
+}
+
 func TestEncodeResponse(t *testing.T) {
 	now := time.Now()
 	validBody := []byte(`{"id":"` + validUUID + `","name":"test","created_at":"` + now.Format(time.RFC3339Nano) + `"}` + "\n" + ``)
@@ -250,6 +288,7 @@ func TestEncodeError(t *testing.T) {
 				errors.Wrap(apiutil.ErrValidation, apiutil.ErrMissingMemberKind),
 				errors.Wrap(apiutil.ErrValidation, apiutil.ErrLimitSize),
 				errors.Wrap(apiutil.ErrValidation, apiutil.ErrNameSize),
+				errors.Wrap(apiutil.ErrValidation, apiutil.ErrMissingMFACode),
 			},
 			code: http.StatusBadRequest,
 		},
@@ -259,6 +298,8 @@ func TestEncodeError(t *testing.T) {
 				svcerr.ErrAuthentication,
 				svcerr.ErrAuthentication,
 				apiutil.ErrBearerToken,
+				svcerr.ErrMFAEnrollmentRequired,
+				svcerr.ErrMFACode,
 			},
 			code: http.StatusUnauthorized,
 		},
@@ -275,6 +316,7 @@ func TestEncodeError(t *testing.T) {
 			errs: []error{
 				svcerr.ErrConflict,
 				svcerr.ErrConflict,
+				svcerr.ErrConcurrentUpdate,
 			},
 			code: http.StatusConflict,
 		},
@@ -294,6 +336,20 @@ func TestEncodeError(t *testing.T) {
 			},
 			code: http.StatusUnsupportedMediaType,
 		},
+		{
+			desc: "TooManyRequests",
+			errs: []error{
+				apiutil.ErrTooManyRequests,
+			},
+			code: http.StatusTooManyRequests,
+		},
+		{
+			desc: "RequestEntityTooLarge",
+			errs: []error{
+				apiutil.ErrRequestTooLarge,
+			},
+			code: http.StatusRequestEntityTooLarge,
+		},
 		{
 			desc: "StatusUnprocessableEntity",
 			errs: []error{
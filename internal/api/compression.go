@@ -0,0 +1,173 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const gzipEncoding = "gzip"
+
+// CompressionMiddleware gzips JSON responses of at least minSize bytes if the
+// client accepts gzip encoding. The response is buffered until minSize bytes
+// are written, so smaller responses are sent uncompressed. Flushed responses
+// are never compressed, since compression would delay the streamed data.
+// Compression is disabled if minSize is not positive.
+func CompressionMiddleware(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if minSize <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, minSize: minSize}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header value allows gzip.
+func acceptsGzip(header string) bool {
+	for _, enc := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(enc, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != gzipEncoding && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// compressWriter buffers the response until it can decide whether to
+// compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	buf     []byte
+	started bool
+	gz      *gzip.Writer
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.status == 0 {
+		cw.status = code
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	switch {
+	case cw.gz != nil:
+		return cw.gz.Write(p)
+	case cw.started:
+		return cw.ResponseWriter.Write(p)
+	case !cw.compressible():
+		if err := cw.start(false); err != nil {
+			return 0, err
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) < cw.minSize {
+		return len(p), nil
+	}
+	if err := cw.start(true); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush sends the buffered response uncompressed and disables compression.
+func (cw *compressWriter) Flush() {
+	if !cw.started && cw.status != 0 {
+		if err := cw.start(false); err != nil {
+			return
+		}
+	}
+	if cw.gz != nil {
+		if err := cw.gz.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if cw.status < http.StatusOK || cw.status == http.StatusNoContent || cw.status == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	return strings.HasPrefix(h.Get("Content-Type"), "application/json")
+}
+
+// start writes the response header and the buffered response.
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+	if compress {
+		cw.Header().Del("Content-Length")
+		cw.Header().Set("Content-Encoding", gzipEncoding)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if compress {
+		cw.gz = gzip.NewWriter(cw.ResponseWriter)
+		_, err := cw.gz.Write(buf)
+		return err
+	}
+	if len(buf) > 0 {
+		_, err := cw.ResponseWriter.Write(buf)
+		return err
+	}
+
+	return nil
+}
+
+func (cw *compressWriter) close() {
+	if !cw.started {
+		if cw.status == 0 {
+			return
+		}
+		if err := cw.start(false); err != nil {
+			return
+		}
+	}
+	if cw.gz != nil {
+		cw.gz.Close()
+	}
+}
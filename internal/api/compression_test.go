@@ -0,0 +1,168 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const minSize = 1024
+
+type listRes struct {
+	Total   int      `json:"total"`
+	Clients []string `json:"clients"`
+}
+
+func (res listRes) Code() int {
+	return http.StatusOK
+}
+
+func (res listRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res listRes) Empty() bool {
+	return false
+}
+
+func newList(t *testing.T, n int) listRes {
+	res := listRes{Total: n}
+	for i := 0; i < n; i++ {
+		res.Clients = append(res.Clients, testsutil.GenerateUUID(t))
+	}
+
+	return res
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := newList(t, 100)
+	small := newList(t, 1)
+
+	cases := []struct {
+		desc           string
+		minSize        int
+		acceptEncoding string
+		contentType    string
+		flush          bool
+		res            listRes
+		compressed     bool
+	}{
+		{
+			desc:           "large list with gzip accepted",
+			minSize:        minSize,
+			acceptEncoding: "gzip, deflate, br",
+			res:            large,
+			compressed:     true,
+		},
+		{
+			desc:       "large list without accepted encoding",
+			minSize:    minSize,
+			res:        large,
+			compressed: false,
+		},
+		{
+			desc:           "large list with gzip not accepted",
+			minSize:        minSize,
+			acceptEncoding: "gzip;q=0, deflate",
+			res:            large,
+			compressed:     false,
+		},
+		{
+			desc:           "large list with any encoding accepted",
+			minSize:        minSize,
+			acceptEncoding: "*",
+			res:            large,
+			compressed:     true,
+		},
+		{
+			desc:           "small list with gzip accepted",
+			minSize:        minSize,
+			acceptEncoding: "gzip",
+			res:            small,
+			compressed:     false,
+		},
+		{
+			desc:           "large non JSON response with gzip accepted",
+			minSize:        minSize,
+			acceptEncoding: "gzip",
+			contentType:    "application/octet-stream",
+			res:            large,
+			compressed:     false,
+		},
+		{
+			desc:           "large flushed response with gzip accepted",
+			minSize:        minSize,
+			acceptEncoding: "gzip",
+			flush:          true,
+			res:            large,
+			compressed:     false,
+		},
+		{
+			desc:           "large list with compression disabled",
+			minSize:        0,
+			acceptEncoding: "gzip",
+			res:            large,
+			compressed:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			handler := api.CompressionMiddleware(tc.minSize)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.contentType != "" {
+					w.Header().Set("Content-Type", tc.contentType)
+					w.WriteHeader(http.StatusOK)
+					if tc.flush {
+						w.(http.Flusher).Flush()
+					}
+					err := json.NewEncoder(w).Encode(tc.res)
+					assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+					return
+				}
+				if tc.flush {
+					w.Header().Set("Content-Type", api.ContentType)
+					w.WriteHeader(http.StatusOK)
+					w.(http.Flusher).Flush()
+				}
+				err := api.EncodeResponse(context.Background(), w, tc.res)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/clients", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code, fmt.Sprintf("%s: expected status %d got %d", tc.desc, http.StatusOK, rec.Code))
+			var body io.Reader = rec.Body
+			if tc.compressed {
+				assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"), fmt.Sprintf("%s: expected gzip content encoding", tc.desc))
+				gz, err := gzip.NewReader(rec.Body)
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				body = gz
+			} else {
+				assert.Empty(t, rec.Header().Get("Content-Encoding"), fmt.Sprintf("%s: expected no content encoding", tc.desc))
+			}
+
+			var res listRes
+			err := json.NewDecoder(body).Decode(&res)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, res))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/time/rate"
+)
+
+// DefLimiterKeys is the default number of keys tracked by the keyed limiter.
+const DefLimiterKeys = 10000
+
+// KeyedLimiter limits the rate of requests per key, such as the client IP
+// address, so that a single client can not exhaust the rate of the others.
+// The least recently used keys are evicted once the limiter tracks too many
+// keys, and the idle keys expire once their bucket would be full again.
+type KeyedLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters *expirable.LRU[string, *rate.Limiter]
+}
+
+// NewKeyedLimiter returns a limiter of limit requests per second with the
+// given burst per key, tracking at most size keys. Zero size tracks
+// DefLimiterKeys keys.
+func NewKeyedLimiter(limit rate.Limit, burst, size int) *KeyedLimiter {
+	if size <= 0 {
+		size = DefLimiterKeys
+	}
+	ttl := time.Minute
+	if limit > 0 {
+		// An idle bucket refills in burst/limit seconds, after which a new
+		// bucket is equivalent to the evicted one.
+		if refill := time.Duration(float64(burst) / float64(limit) * float64(time.Second)); refill > ttl {
+			ttl = refill
+		}
+	}
+
+	return &KeyedLimiter{
+		limit:    limit,
+		burst:    burst,
+		limiters: expirable.NewLRU[string, *rate.Limiter](size, nil, ttl),
+	}
+}
+
+// Allow reports whether a request of the key may happen now.
+func (kl *KeyedLimiter) Allow(key string) bool {
+	if kl == nil {
+		return true
+	}
+
+	kl.mu.Lock()
+	l, ok := kl.limiters.Get(key)
+	if !ok {
+		l = rate.NewLimiter(kl.limit, kl.burst)
+		kl.limiters.Add(key, l)
+	}
+	kl.mu.Unlock()
+
+	return l.Allow()
+}
@@ -0,0 +1,40 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func TestKeyedLimiter(t *testing.T) {
+	limiter := api.NewKeyedLimiter(rate.Every(time.Hour), 2, 2)
+
+	cases := []struct {
+		desc    string
+		key     string
+		allowed bool
+	}{
+		{desc: "first request of the first key", key: "first", allowed: true},
+		{desc: "second request of the first key", key: "first", allowed: true},
+		{desc: "request of the first key over the burst", key: "first", allowed: false},
+		{desc: "first request of the second key", key: "second", allowed: true},
+		{desc: "request of the first key after the second key", key: "first", allowed: false},
+	}
+
+	for _, tc := range cases {
+		allowed := limiter.Allow(tc.key)
+		assert.Equal(t, tc.allowed, allowed, fmt.Sprintf("%s: expected allowed %t got %t", tc.desc, tc.allowed, allowed))
+	}
+}
+
+func TestNilKeyedLimiter(t *testing.T) {
+	var limiter *api.KeyedLimiter
+	assert.True(t, limiter.Allow("key"), "nil limiter should allow requests")
+}
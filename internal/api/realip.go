@@ -0,0 +1,110 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ForwardedForHeader is the header the reverse proxies report the client
+// address in.
+const ForwardedForHeader = "X-Forwarded-For"
+
+// TrustedProxies lists the addresses of the reverse proxies trusted to report
+// the client address.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses comma-separated IP addresses and CIDR ranges.
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+
+	return proxies, nil
+}
+
+func (tp TrustedProxies) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range tp {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientIP returns the client address of the request. The X-Forwarded-For
+// header is read only if the request comes from a trusted proxy. The header
+// is read from the right, since each proxy appends the address it received
+// the request from, and the first address which isn't a trusted proxy is the
+// client address.
+func (tp TrustedProxies) clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !tp.trusted(ip) {
+		return ip
+	}
+	fwd := strings.Split(strings.Join(r.Header.Values(ForwardedForHeader), ","), ",")
+	for i := len(fwd) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(fwd[i])
+		if addr == "" {
+			continue
+		}
+		if _, err := netip.ParseAddr(addr); err != nil {
+			// Addresses left of a malformed one can't be trusted.
+			return ip
+		}
+		ip = addr
+		if !tp.trusted(addr) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// RealIPMiddleware sets the remote address of the requests forwarded by the
+// trusted proxies to the client address reported in the X-Forwarded-For
+// header. The header of the other requests is ignored, so the clients can't
+// spoof their address.
+func RealIPMiddleware(proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(proxies) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.RemoteAddr = proxies.clientIP(r)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	cases := []struct {
+		desc    string
+		proxies string
+		count   int
+		err     bool
+	}{
+		{
+			desc:    "parse empty proxies",
+			proxies: "",
+			count:   0,
+		},
+		{
+			desc:    "parse addresses and ranges",
+			proxies: "10.0.0.1, 172.16.0.0/12,::1",
+			count:   3,
+		},
+		{
+			desc:    "parse invalid address",
+			proxies: "10.0.0.256",
+			err:     true,
+		},
+		{
+			desc:    "parse invalid range",
+			proxies: "10.0.0.0/33",
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		proxies, err := api.ParseTrustedProxies(tc.proxies)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+		assert.Len(t, proxies, tc.count, fmt.Sprintf("%s: expected %d proxies got %d", tc.desc, tc.count, len(proxies)))
+	}
+}
+
+func TestRealIPMiddleware(t *testing.T) {
+	proxies, err := api.ParseTrustedProxies("10.0.0.0/8")
+	require.Nil(t, err, fmt.Sprintf("unexpected error parsing proxies: %s", err))
+
+	cases := []struct {
+		desc       string
+		proxies    api.TrustedProxies
+		remoteAddr string
+		forwarded  []string
+		ip         string
+	}{
+		{
+			desc:       "request without trusted proxies",
+			remoteAddr: "192.0.2.1:1234",
+			forwarded:  []string{"198.51.100.1"},
+			ip:         "192.0.2.1:1234",
+		},
+		{
+			desc:       "request from untrusted address",
+			proxies:    proxies,
+			remoteAddr: "192.0.2.1:1234",
+			forwarded:  []string{"198.51.100.1"},
+			ip:         "192.0.2.1",
+		},
+		{
+			desc:       "request from trusted proxy",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  []string{"198.51.100.1"},
+			ip:         "198.51.100.1",
+		},
+		{
+			desc:       "request from trusted proxy with spoofed address",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  []string{"203.0.113.1, 198.51.100.1"},
+			ip:         "198.51.100.1",
+		},
+		{
+			desc:       "request through several trusted proxies",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  []string{"198.51.100.1", "10.0.0.2"},
+			ip:         "198.51.100.1",
+		},
+		{
+			desc:       "request from trusted proxy with malformed address",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  []string{"198.51.100.1, unknown"},
+			ip:         "10.0.0.1",
+		},
+		{
+			desc:       "request from trusted proxy without header",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.1:1234",
+			ip:         "10.0.0.1",
+		},
+	}
+
+	for _, tc := range cases {
+		var ip string
+		handler := api.RealIPMiddleware(tc.proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip = r.RemoteAddr
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+		for _, fwd := range tc.forwarded {
+			req.Header.Add(api.ForwardedForHeader, fwd)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, tc.ip, ip, fmt.Sprintf("%s: expected client IP %s got %s", tc.desc, tc.ip, ip))
+	}
+}
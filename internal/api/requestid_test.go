@@ -0,0 +1,70 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	cases := []struct {
+		desc      string
+		requestID string
+		generated bool
+	}{
+		{
+			desc:      "request with request ID",
+			requestID: "7b7b2e0c-3b7e-4f55-9b1e-4a3f8e7a2c11",
+		},
+		{
+			desc:      "request without request ID",
+			requestID: "",
+			generated: true,
+		},
+		{
+			desc:      "request with request ID containing control characters",
+			requestID: "id\nforged log line",
+			generated: true,
+		},
+		{
+			desc:      "request with too long request ID",
+			requestID: strings.Repeat("a", 129),
+			generated: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var ctxID string
+			handler := api.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.requestID != "" {
+				req.Header.Set(requestid.Header, tc.requestID)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			echoed := res.Header().Get(requestid.Header)
+			assert.Equal(t, ctxID, echoed, fmt.Sprintf("%s: expected echoed request ID to match context request ID", tc.desc))
+			switch tc.generated {
+			case true:
+				assert.NotEmpty(t, echoed, fmt.Sprintf("%s: expected request ID to be generated", tc.desc))
+				assert.NotEqual(t, tc.requestID, echoed, fmt.Sprintf("%s: expected invalid request ID to be replaced", tc.desc))
+			default:
+				assert.Equal(t, tc.requestID, echoed, fmt.Sprintf("%s: expected request ID %s, got %s", tc.desc, tc.requestID, echoed))
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/apiutil"
+)
+
+// TimeoutMiddleware cancels the context of the requests which take longer
+// than the timeout of their method, so the service and the database calls
+// they make are canceled too, and responds with 503 Service Unavailable.
+// The reads (GET and HEAD requests) are limited by the read timeout, and the
+// rest of the requests by the write timeout. The response is buffered until
+// the handler returns, so the streaming handlers must not be wrapped by the
+// middleware. A timeout is disabled if it is not positive.
+func TimeoutMiddleware(read, write time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if read <= 0 && write <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := write
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				timeout = read
+			}
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r)
+				tw.finish(ctx.Err() == nil)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+			case <-ctx.Done():
+			}
+			if !tw.inTime() {
+				EncodeError(ctx, apiutil.ErrRequestTimeout, w)
+				return
+			}
+			tw.flush(w)
+		})
+	}
+}
+
+// timeoutWriter buffers the response of the handler, which is discarded if
+// the request times out.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	buf      bytes.Buffer
+	code     int
+	finished bool
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+// finish records whether the handler returned before the timeout.
+func (tw *timeoutWriter) finish(inTime bool) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.finished = inTime
+}
+
+// inTime reports whether the handler returned before the timeout. Otherwise,
+// the response is discarded, including the errors of the canceled calls.
+func (tw *timeoutWriter) inTime() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.finished {
+		tw.timedOut = true
+	}
+
+	return tw.finished
+}
+
+func (tw *timeoutWriter) flush(w http.ResponseWriter) {
+	dst := w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	_, _ = w.Write(tw.buf.Bytes())
+}
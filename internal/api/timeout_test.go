@@ -0,0 +1,143 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	readTimeout  = 50 * time.Millisecond
+	writeTimeout = 200 * time.Millisecond
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	cases := []struct {
+		desc     string
+		method   string
+		read     time.Duration
+		write    time.Duration
+		duration time.Duration
+		status   int
+		canceled bool
+	}{
+		{
+			desc:     "fast read",
+			method:   http.MethodGet,
+			read:     readTimeout,
+			write:    writeTimeout,
+			duration: 0,
+			status:   http.StatusCreated,
+		},
+		{
+			desc:     "slow read",
+			method:   http.MethodGet,
+			read:     readTimeout,
+			write:    writeTimeout,
+			duration: time.Second,
+			status:   http.StatusServiceUnavailable,
+			canceled: true,
+		},
+		{
+			desc:     "write slower than the read timeout",
+			method:   http.MethodPost,
+			read:     readTimeout,
+			write:    writeTimeout,
+			duration: 2 * readTimeout,
+			status:   http.StatusCreated,
+		},
+		{
+			desc:     "slow write",
+			method:   http.MethodDelete,
+			read:     readTimeout,
+			write:    writeTimeout,
+			duration: time.Second,
+			status:   http.StatusServiceUnavailable,
+			canceled: true,
+		},
+		{
+			desc:     "slow read with the read timeout disabled",
+			method:   http.MethodGet,
+			write:    readTimeout,
+			duration: 2 * readTimeout,
+			status:   http.StatusCreated,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			canceled := make(chan error, 1)
+			// The handler waits for the slow query the way the repositories do,
+			// giving up once the request context is canceled.
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(tc.duration):
+				case <-r.Context().Done():
+					canceled <- r.Context().Err()
+					api.EncodeError(r.Context(), r.Context().Err(), w)
+					return
+				}
+				w.Header().Set("Content-Type", api.ContentType)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":"1"}`))
+			})
+			ts := httptest.NewServer(api.TimeoutMiddleware(tc.read, tc.write)(handler))
+			defer ts.Close()
+
+			req, err := http.NewRequest(tc.method, ts.URL, http.NoBody)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error creating request %s", tc.desc, err))
+			start := time.Now()
+			res, err := ts.Client().Do(req)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			defer res.Body.Close()
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			assert.Equal(t, api.ContentType, res.Header.Get("Content-Type"), fmt.Sprintf("%s: expected content type %s got %s", tc.desc, api.ContentType, res.Header.Get("Content-Type")))
+
+			if !tc.canceled {
+				var body map[string]string
+				err = json.NewDecoder(res.Body).Decode(&body)
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error decoding response %s", tc.desc, err))
+				assert.Equal(t, "1", body["id"], fmt.Sprintf("%s: expected the handler response got %v", tc.desc, body))
+				return
+			}
+
+			assert.Less(t, time.Since(start), tc.duration, fmt.Sprintf("%s: expected the request to time out before the handler finishes", tc.desc))
+			var body struct {
+				Message string `json:"message"`
+			}
+			err = json.NewDecoder(res.Body).Decode(&body)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error decoding response %s", tc.desc, err))
+			assert.Equal(t, apiutil.ErrRequestTimeout.Error(), body.Message, fmt.Sprintf("%s: expected error %s got %s", tc.desc, apiutil.ErrRequestTimeout, body.Message))
+			select {
+			case err := <-canceled:
+				assert.Equal(t, context.DeadlineExceeded, err, fmt.Sprintf("%s: expected the handler context to be canceled got %s", tc.desc, err))
+			case <-time.After(time.Second):
+				t.Errorf("%s: expected the handler context to be canceled", tc.desc)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddlewareDisabled(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := r.Context().Deadline()
+		assert.False(t, ok, "expected the request context to have no deadline")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	api.TimeoutMiddleware(0, 0)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code, fmt.Sprintf("expected status code %d got %d", http.StatusOK, rec.Code))
+}
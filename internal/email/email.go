@@ -14,23 +14,41 @@ import (
 	"gopkg.in/gomail.v2"
 )
 
+// ContentType is the content type of the e-mail body.
+const ContentType = "text/plain"
+
 var (
+	// ErrParseTemplate indicates that the e-mail template file can't be read or parsed.
+	ErrParseTemplate = errors.New("Parse e-mail template failed")
+
+	// ErrExecTemplate indicates that the e-mail template can't be rendered,
+	// e.g. because it refers to a missing field.
+	ErrExecTemplate = errors.New("Execute e-mail template failed")
+
 	// errMissingEmailTemplate missing email template file.
 	errMissingEmailTemplate = errors.New("Missing e-mail template file")
-	errParseTemplate        = errors.New("Parse e-mail template failed")
-	errExecTemplate         = errors.New("Execute e-mail template failed")
 	errSendMail             = errors.New("Sending e-mail failed")
 )
 
 type email struct {
 	To      []string
 	From    string
+	ReplyTo string
 	Subject string
 	Header  string
 	User    string
 	Content string
 	Host    string
 	Footer  string
+	Logo    string
+}
+
+// Branding overrides the sender of an e-mail and the logo the e-mail
+// template can show. Empty values are not overridden.
+type Branding struct {
+	FromName string
+	ReplyTo  string
+	LogoURL  string
 }
 
 // Config email agent configuration.
@@ -64,7 +82,7 @@ func New(c *Config) (*Agent, error) {
 
 	tmpl, err := template.ParseFiles(c.Template)
 	if err != nil {
-		return a, errors.Wrap(errParseTemplate, err)
+		return a, errors.Wrap(ErrParseTemplate, err)
 	}
 	a.tmpl = tmpl
 	return a, nil
@@ -72,11 +90,62 @@ func New(c *Config) (*Agent, error) {
 
 // Send sends e-mail.
 func (a *Agent) Send(to []string, from, subject, header, user, content, footer string) error {
+	return a.send(newEmail(a.conf, to, from, subject, header, user, content, footer))
+}
+
+// SendBranded sends e-mail with the sender name, reply-to address and logo
+// of the branding.
+func (a *Agent) SendBranded(to []string, b Branding, subject, header, user, content, footer string) error {
+	e := newEmail(a.conf, to, "", subject, header, user, content, footer)
+	if b.FromName != "" {
+		from := mail.Address{Name: b.FromName, Address: a.conf.FromAddress}
+		e.From = from.String()
+	}
+	e.ReplyTo = b.ReplyTo
+	e.Logo = b.LogoURL
+
+	return a.send(e)
+}
+
+func (a *Agent) send(e email) error {
 	if a.tmpl == nil {
 		return errMissingEmailTemplate
 	}
 
-	buff := new(bytes.Buffer)
+	body, err := execute(a.tmpl, e)
+	if err != nil {
+		return err
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", e.From)
+	m.SetHeader("To", e.To...)
+	if e.ReplyTo != "" {
+		m.SetHeader("Reply-To", e.ReplyTo)
+	}
+	m.SetHeader("Subject", e.Subject)
+	m.SetBody(ContentType, body)
+
+	if err := a.dial.DialAndSend(m); err != nil {
+		return errors.Wrap(errSendMail, err)
+	}
+
+	return nil
+}
+
+// Render returns the body of the e-mail rendered from the configured template,
+// without sending it. The template file is parsed on every call, so changes
+// of the file are rendered without restart.
+func Render(c *Config, to []string, from, subject, header, user, content, footer string) (string, error) {
+	tmpl, err := template.ParseFiles(c.Template)
+	if err != nil {
+		return "", errors.Wrap(ErrParseTemplate, err)
+	}
+
+	return execute(tmpl, newEmail(c, to, from, subject, header, user, content, footer))
+}
+
+func newEmail(c *Config, to []string, from, subject, header, user, content, footer string) email {
 	e := email{
 		To:      to,
 		From:    from,
@@ -88,23 +157,18 @@ func (a *Agent) Send(to []string, from, subject, header, user, content, footer s
 		Footer:  footer,
 	}
 	if from == "" {
-		from := mail.Address{Name: a.conf.FromName, Address: a.conf.FromAddress}
+		from := mail.Address{Name: c.FromName, Address: c.FromAddress}
 		e.From = from.String()
 	}
 
-	if err := a.tmpl.Execute(buff, e); err != nil {
-		return errors.Wrap(errExecTemplate, err)
-	}
-
-	m := gomail.NewMessage()
-	m.SetHeader("From", e.From)
-	m.SetHeader("To", to...)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", buff.String())
+	return e
+}
 
-	if err := a.dial.DialAndSend(m); err != nil {
-		return errors.Wrap(errSendMail, err)
+func execute(tmpl *template.Template, e email) (string, error) {
+	buff := new(bytes.Buffer)
+	if err := tmpl.Execute(buff, e); err != nil {
+		return "", errors.Wrap(ErrExecTemplate, err)
 	}
 
-	return nil
+	return buff.String(), nil
 }
@@ -17,6 +17,14 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+var pageLimits = api.NewPageLimits(api.DefLimit, api.MaxLimitSize)
+
+// SetPageLimits sets the default and the maximum page size of the group
+// list endpoints. Zero values keep DefLimit and MaxLimitSize.
+func SetPageLimits(pl api.PageLimits) {
+	pageLimits = api.NewPageLimits(pl.Default, pl.Max)
+}
+
 func DecodeListGroupsRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	pm, err := decodePageMeta(r)
 	if err != nil {
@@ -253,7 +261,7 @@ func decodePageMeta(r *http.Request) (mggroups.PageMeta, error) {
 	if err != nil {
 		return mggroups.PageMeta{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	limit, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	limit, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
 	if err != nil {
 		return mggroups.PageMeta{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
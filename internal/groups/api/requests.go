@@ -58,7 +58,7 @@ func (req listGroupsReq) validate() error {
 	if req.Level > mggroups.MaxLevel {
 		return apiutil.ErrInvalidLevel
 	}
-	if req.Limit > api.MaxLimitSize || req.Limit < 1 {
+	if req.Limit > pageLimits.Max || req.Limit < 1 {
 		return apiutil.ErrLimitSize
 	}
 
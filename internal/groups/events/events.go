@@ -19,6 +19,7 @@ var (
 	groupViewPerms       = groupPrefix + "view_perms"
 	groupList            = groupPrefix + "list"
 	groupListMemberships = groupPrefix + "list_by_user"
+	groupListUserGroups  = groupPrefix + "list_user_groups"
 	groupRemove          = groupPrefix + "remove"
 	groupAssign          = groupPrefix + "assign"
 	groupUnassign        = groupPrefix + "unassign"
@@ -35,6 +36,7 @@ var (
 	_ events.Event = (*viewGroupEvent)(nil)
 	_ events.Event = (*listGroupEvent)(nil)
 	_ events.Event = (*listGroupMembershipEvent)(nil)
+	_ events.Event = (*listUserGroupsEvent)(nil)
 )
 
 type assignEvent struct {
@@ -259,6 +261,20 @@ func (lgme listGroupMembershipEvent) Encode() (map[string]interface{}, error) {
 	}, nil
 }
 
+type listUserGroupsEvent struct {
+	userID string
+	groups.PageMeta
+}
+
+func (luge listUserGroupsEvent) Encode() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"operation": groupListUserGroups,
+		"user_id":   luge.userID,
+		"offset":    luge.Offset,
+		"limit":     luge.Limit,
+	}, nil
+}
+
 type deleteGroupEvent struct {
 	id string
 }
@@ -131,6 +131,22 @@ func (es eventStore) ListMembers(ctx context.Context, session authn.Session, gro
 	return mp, nil
 }
 
+func (es eventStore) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (groups.MembershipsPage, error) {
+	mp, err := es.svc.ListUserGroups(ctx, session, userID, pm)
+	if err != nil {
+		return mp, err
+	}
+	event := listUserGroupsEvent{
+		userID, pm,
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return mp, err
+	}
+
+	return mp, nil
+}
+
 func (es eventStore) EnableGroup(ctx context.Context, session authn.Session, id string) (groups.Group, error) {
 	group, err := es.svc.EnableGroup(ctx, session, id)
 	if err != nil {
@@ -108,6 +108,23 @@ func (am *authorizationMiddleware) ListMembers(ctx context.Context, session auth
 	return am.svc.ListMembers(ctx, session, groupID, permission, memberKind)
 }
 
+func (am *authorizationMiddleware) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (groups.MembershipsPage, error) {
+	switch {
+	case session.UserID == userID:
+		if err := am.authorize(ctx, session.DomainID, policies.UserType, policies.UsersKind, session.DomainUserID, policies.MembershipPermission, policies.DomainType, session.DomainID); err != nil {
+			return groups.MembershipsPage{}, err
+		}
+	default:
+		if err := am.checkSuperAdmin(ctx, session.UserID); err != nil {
+			if err := am.authorize(ctx, session.DomainID, policies.UserType, policies.UsersKind, session.DomainUserID, policies.AdminPermission, policies.DomainType, session.DomainID); err != nil {
+				return groups.MembershipsPage{}, err
+			}
+		}
+	}
+
+	return am.svc.ListUserGroups(ctx, session, userID, pm)
+}
+
 func (am *authorizationMiddleware) EnableGroup(ctx context.Context, session authn.Session, id string) (groups.Group, error) {
 	if err := am.authorize(ctx, session.DomainID, policies.UserType, policies.UsersKind, session.DomainUserID, policies.EditPermission, policies.GroupType, id); err != nil {
 		return groups.Group{}, err
@@ -194,6 +194,29 @@ func (lm *loggingMiddleware) ListMembers(ctx context.Context, session authn.Sess
 	return lm.svc.ListMembers(ctx, session, groupID, permission, memberKind)
 }
 
+// ListUserGroups logs the list_user_groups request. It logs the user id and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (mp groups.MembershipsPage, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("user_id", userID),
+			slog.Group("page",
+				slog.Uint64("limit", pm.Limit),
+				slog.Uint64("offset", pm.Offset),
+				slog.Uint64("total", mp.Total),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("List user groups failed", args...)
+			return
+		}
+		lm.logger.Info("List user groups completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ListUserGroups(ctx, session, userID, pm)
+}
+
 func (lm *loggingMiddleware) Assign(ctx context.Context, session authn.Session, groupID, relation, memberKind string, memberIDs ...string) (err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -101,6 +101,15 @@ func (ms *metricsMiddleware) ListMembers(ctx context.Context, session authn.Sess
 	return ms.svc.ListMembers(ctx, session, groupID, permission, memberKind)
 }
 
+// ListUserGroups instruments ListUserGroups method with metrics.
+func (ms *metricsMiddleware) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (mp groups.MembershipsPage, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_user_groups").Add(1)
+		ms.latency.With("method", "list_user_groups").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ListUserGroups(ctx, session, userID, pm)
+}
+
 // Assign instruments Assign method with metrics.
 func (ms *metricsMiddleware) Assign(ctx context.Context, session authn.Session, groupID, relation, memberKind string, memberIDs ...string) (err error) {
 	defer func(begin time.Time) {
@@ -0,0 +1,86 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/quotas"
+)
+
+var _ groups.Service = (*quotasMiddleware)(nil)
+
+type quotasMiddleware struct {
+	svc    groups.Service
+	quotas quotas.Service
+}
+
+// QuotasMiddleware enforces the domain groups and channels quotas.
+func QuotasMiddleware(svc groups.Service, qsvc quotas.Service) groups.Service {
+	return &quotasMiddleware{
+		svc:    svc,
+		quotas: qsvc,
+	}
+}
+
+func (qm *quotasMiddleware) CreateGroup(ctx context.Context, session authn.Session, kind string, g groups.Group) (groups.Group, error) {
+	quotaKind := quotas.GroupsKind
+	if kind == policies.NewChannelKind {
+		quotaKind = quotas.ChannelsKind
+	}
+	release, err := qm.quotas.Reserve(ctx, session.DomainID, quotaKind, 1)
+	if err != nil {
+		return groups.Group{}, err
+	}
+	defer release()
+
+	return qm.svc.CreateGroup(ctx, session, kind, g)
+}
+
+func (qm *quotasMiddleware) UpdateGroup(ctx context.Context, session authn.Session, group groups.Group) (rGroup groups.Group, err error) {
+	return qm.svc.UpdateGroup(ctx, session, group)
+}
+
+func (qm *quotasMiddleware) ViewGroup(ctx context.Context, session authn.Session, id string) (g groups.Group, err error) {
+	return qm.svc.ViewGroup(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) ViewGroupPerms(ctx context.Context, session authn.Session, id string) (p []string, err error) {
+	return qm.svc.ViewGroupPerms(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) ListGroups(ctx context.Context, session authn.Session, memberKind, memberID string, gp groups.Page) (cg groups.Page, err error) {
+	return qm.svc.ListGroups(ctx, session, memberKind, memberID, gp)
+}
+
+func (qm *quotasMiddleware) EnableGroup(ctx context.Context, session authn.Session, id string) (g groups.Group, err error) {
+	return qm.svc.EnableGroup(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) DisableGroup(ctx context.Context, session authn.Session, id string) (g groups.Group, err error) {
+	return qm.svc.DisableGroup(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) ListMembers(ctx context.Context, session authn.Session, groupID, permission, memberKind string) (mp groups.MembersPage, err error) {
+	return qm.svc.ListMembers(ctx, session, groupID, permission, memberKind)
+}
+
+func (qm *quotasMiddleware) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (mp groups.MembershipsPage, err error) {
+	return qm.svc.ListUserGroups(ctx, session, userID, pm)
+}
+
+func (qm *quotasMiddleware) Assign(ctx context.Context, session authn.Session, groupID, relation, memberKind string, memberIDs ...string) (err error) {
+	return qm.svc.Assign(ctx, session, groupID, relation, memberKind, memberIDs...)
+}
+
+func (qm *quotasMiddleware) Unassign(ctx context.Context, session authn.Session, groupID, relation, memberKind string, memberIDs ...string) (err error) {
+	return qm.svc.Unassign(ctx, session, groupID, relation, memberKind, memberIDs...)
+}
+
+func (qm *quotasMiddleware) DeleteGroup(ctx context.Context, session authn.Session, id string) (err error) {
+	return qm.svc.DeleteGroup(ctx, session, id)
+}
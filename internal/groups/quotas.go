@@ -0,0 +1,30 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package groups
+
+import (
+	"context"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/quotas"
+)
+
+// CountGroups returns the counter of the groups of a domain, used to enforce
+// the domain groups or channels quota, depending on the service storing them.
+func CountGroups(repo groups.Repository) quotas.Counter {
+	return func(ctx context.Context, domainID string) (uint64, error) {
+		page, err := repo.RetrieveAll(ctx, groups.Page{
+			PageMeta: groups.PageMeta{
+				DomainID: domainID,
+				Status:   mgclients.AllStatus,
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		return page.Total, nil
+	}
+}
@@ -272,6 +272,47 @@ func (svc service) ListMembers(ctx context.Context, session authn.Session, group
 	}
 }
 
+func (svc service) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (groups.MembershipsPage, error) {
+	pp, err := svc.policies.ListPolicies(ctx, policies.Policy{
+		SubjectType: policies.UserType,
+		Subject:     mgauth.EncodeDomainUserID(session.DomainID, userID),
+		ObjectType:  policies.GroupType,
+	}, "", 0)
+	if err != nil {
+		return groups.MembershipsPage{}, err
+	}
+
+	// A user may be related to the group by several relations, the
+	// strongest one is reported.
+	relations := make(map[string]string)
+	for _, p := range pp.Policies {
+		if r, ok := relations[p.Object]; !ok || relationRank(p.Relation) < relationRank(r) {
+			relations[p.Object] = p.Relation
+		}
+	}
+	if len(relations) == 0 {
+		return groups.MembershipsPage{PageMeta: groups.PageMeta{Offset: pm.Offset, Limit: pm.Limit}}, nil
+	}
+	ids := make([]string, 0, len(relations))
+	for id := range relations {
+		ids = append(ids, id)
+	}
+
+	gp, err := svc.groups.RetrieveByIDs(ctx, groups.Page{PageMeta: pm}, ids...)
+	if err != nil {
+		return groups.MembershipsPage{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	page := groups.MembershipsPage{
+		PageMeta:    gp.PageMeta,
+		Memberships: make([]groups.Membership, 0, len(gp.Groups)),
+	}
+	for _, g := range gp.Groups {
+		page.Memberships = append(page.Memberships, groups.Membership{Group: g, Relation: relations[g.ID]})
+	}
+
+	return page, nil
+}
+
 func (svc service) UpdateGroup(ctx context.Context, session authn.Session, g groups.Group) (groups.Group, error) {
 	g.UpdatedAt = time.Now()
 	g.UpdatedBy = session.UserID
@@ -585,3 +626,23 @@ func (svc service) addGroupPolicy(ctx context.Context, userID, domainID, id, par
 
 	return []policies.Policy{}, nil
 }
+
+var userRelations = []string{
+	policies.AdministratorRelation,
+	policies.EditorRelation,
+	policies.ContributorRelation,
+	policies.MemberRelation,
+	policies.GuestRelation,
+}
+
+// relationRank returns the rank of the user relation to the group, lower
+// ranks granting more permissions.
+func relationRank(relation string) int {
+	for i, r := range userRelations {
+		if r == relation {
+			return i
+		}
+	}
+
+	return len(userRelations)
+}
@@ -519,6 +519,103 @@ func TestListMembers(t *testing.T) {
 	}
 }
 
+func TestListUserGroups(t *testing.T) {
+	repo := new(mocks.Repository)
+	policies := new(policymocks.Service)
+	svc := groups.NewService(repo, idProvider, policies)
+
+	session := mgauthn.Session{DomainID: testsutil.GenerateUUID(t), UserID: testsutil.GenerateUUID(t)}
+	adminGroup := validGroup
+	adminGroup.ID = testsutil.GenerateUUID(t)
+	memberGroup := validGroup
+	memberGroup.ID = testsutil.GenerateUUID(t)
+	guestGroup := validGroup
+	guestGroup.ID = testsutil.GenerateUUID(t)
+
+	cases := []struct {
+		desc         string
+		userID       string
+		pageMeta     mggroups.PageMeta
+		policiesResp policysvc.PoliciesPage
+		policiesErr  error
+		repoResp     mggroups.Page
+		repoErr      error
+		resp         mggroups.MembershipsPage
+		err          error
+	}{
+		{
+			desc:     "successfully with user in several groups",
+			userID:   session.UserID,
+			pageMeta: mggroups.PageMeta{Limit: 10},
+			policiesResp: policysvc.PoliciesPage{
+				Policies: []policysvc.Policy{
+					{Object: adminGroup.ID, Relation: policysvc.MemberRelation},
+					{Object: adminGroup.ID, Relation: policysvc.AdministratorRelation},
+					{Object: memberGroup.ID, Relation: policysvc.MemberRelation},
+					{Object: guestGroup.ID, Relation: policysvc.GuestRelation},
+				},
+			},
+			repoResp: mggroups.Page{
+				PageMeta: mggroups.PageMeta{Total: 3, Limit: 10},
+				Groups:   []mggroups.Group{adminGroup, memberGroup, guestGroup},
+			},
+			resp: mggroups.MembershipsPage{
+				PageMeta: mggroups.PageMeta{Total: 3, Limit: 10},
+				Memberships: []mggroups.Membership{
+					{Group: adminGroup, Relation: policysvc.AdministratorRelation},
+					{Group: memberGroup, Relation: policysvc.MemberRelation},
+					{Group: guestGroup, Relation: policysvc.GuestRelation},
+				},
+			},
+		},
+		{
+			desc:     "successfully with user in no groups",
+			userID:   session.UserID,
+			pageMeta: mggroups.PageMeta{Offset: 5, Limit: 10},
+			resp: mggroups.MembershipsPage{
+				PageMeta: mggroups.PageMeta{Offset: 5, Limit: 10},
+			},
+		},
+		{
+			desc:        "with failed to list policies",
+			userID:      session.UserID,
+			pageMeta:    mggroups.PageMeta{Limit: 10},
+			policiesErr: svcerr.ErrAuthorization,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:     "with failed to retrieve groups",
+			userID:   session.UserID,
+			pageMeta: mggroups.PageMeta{Limit: 10},
+			policiesResp: policysvc.PoliciesPage{
+				Policies: []policysvc.Policy{
+					{Object: memberGroup.ID, Relation: policysvc.MemberRelation},
+				},
+			},
+			repoErr: repoerr.ErrNotFound,
+			err:     svcerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			policyCall := policies.On("ListPolicies", context.Background(), policysvc.Policy{
+				SubjectType: policysvc.UserType,
+				Subject:     mgauth.EncodeDomainUserID(session.DomainID, tc.userID),
+				ObjectType:  policysvc.GroupType,
+			}, "", uint64(0)).Return(tc.policiesResp, tc.policiesErr)
+			repoCall := repo.On("RetrieveByIDs", context.Background(), mggroups.Page{PageMeta: tc.pageMeta}, mock.Anything).Return(tc.repoResp, tc.repoErr)
+			got, err := svc.ListUserGroups(context.Background(), session, tc.userID, tc.pageMeta)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("expected error %v to contain %v", err, tc.err))
+			if err == nil {
+				assert.Equal(t, tc.resp, got)
+			}
+			policyCall.Unset()
+			repoCall.Unset()
+		})
+	}
+}
+
 func TestListGroups(t *testing.T) {
 	repo := new(mocks.Repository)
 	policies := new(policymocks.Service)
@@ -64,6 +64,14 @@ func (tm *tracingMiddleware) ListMembers(ctx context.Context, session authn.Sess
 	return tm.gsvc.ListMembers(ctx, session, groupID, permission, memberKind)
 }
 
+// ListUserGroups traces the "ListUserGroups" operation of the wrapped groups.Service.
+func (tm *tracingMiddleware) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (groups.MembershipsPage, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_list_user_groups", trace.WithAttributes(attribute.String("userID", userID)))
+	defer span.End()
+
+	return tm.gsvc.ListUserGroups(ctx, session, userID, pm)
+}
+
 // UpdateGroup traces the "UpdateGroup" operation of the wrapped groups.Service.
 func (tm *tracingMiddleware) UpdateGroup(ctx context.Context, session authn.Session, g groups.Group) (groups.Group, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_update_group")
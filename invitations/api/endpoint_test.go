@@ -308,6 +308,16 @@ func TestListInvitation(t *testing.T) {
 			contentType: validContenType,
 			svcErr:      nil,
 		},
+		{
+			desc:        "with expired state",
+			domainID:    domainID,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID, DomainUserID: domainID + "_" + validID},
+			token:       validToken,
+			query:       "state=expired",
+			status:      http.StatusOK,
+			contentType: validContenType,
+			svcErr:      nil,
+		},
 		{
 			desc:        "with invalid state",
 			domainID:    domainID,
@@ -17,6 +17,7 @@ import (
 type Invitation struct {
 	InvitedBy   string    `json:"invited_by"`
 	UserID      string    `json:"user_id"`
+	Email       string    `json:"email,omitempty"`
 	DomainID    string    `json:"domain_id"`
 	Token       string    `json:"token,omitempty"`
 	Relation    string    `json:"relation,omitempty"`
@@ -24,19 +25,23 @@ type Invitation struct {
 	UpdatedAt   time.Time `json:"updated_at,omitempty"`
 	ConfirmedAt time.Time `json:"confirmed_at,omitempty"`
 	RejectedAt  time.Time `json:"rejected_at,omitempty"`
+	SentAt      time.Time `json:"sent_at,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	State       State     `json:"state,omitempty"`
 	Resend      bool      `json:"resend,omitempty"`
 }
 
 // Page is a page of invitations.
 type Page struct {
-	Offset            uint64 `json:"offset" db:"offset"`
-	Limit             uint64 `json:"limit" db:"limit"`
-	InvitedBy         string `json:"invited_by,omitempty" db:"invited_by,omitempty"`
-	UserID            string `json:"user_id,omitempty" db:"user_id,omitempty"`
-	DomainID          string `json:"domain_id,omitempty" db:"domain_id,omitempty"`
-	Relation          string `json:"relation,omitempty" db:"relation,omitempty"`
-	InvitedByOrUserID string `db:"invited_by_or_user_id,omitempty"`
-	State             State  `json:"state,omitempty"`
+	Offset            uint64    `json:"offset" db:"offset"`
+	Limit             uint64    `json:"limit" db:"limit"`
+	InvitedBy         string    `json:"invited_by,omitempty" db:"invited_by,omitempty"`
+	UserID            string    `json:"user_id,omitempty" db:"user_id,omitempty"`
+	DomainID          string    `json:"domain_id,omitempty" db:"domain_id,omitempty"`
+	Relation          string    `json:"relation,omitempty" db:"relation,omitempty"`
+	InvitedByOrUserID string    `db:"invited_by_or_user_id,omitempty"`
+	State             State     `json:"state,omitempty"`
+	Now               time.Time `json:"-" db:"now"`
 }
 
 // InvitationPage is a page of invitations.
@@ -78,7 +83,9 @@ type Service interface {
 	// - platform administrators
 	ViewInvitation(ctx context.Context, session authn.Session, userID, domainID string) (invitation Invitation, err error)
 
-	// ListInvitations returns a list of invitations.
+	// ListInvitations returns a list of invitations, with their state and
+	// expiry. Pending invitations whose join token has expired are listed
+	// as expired.
 	// People who can list invitations are:
 	// - platform administrators can list all invitations
 	// - domain administrators can list invitations for their domain
@@ -30,8 +30,16 @@ func AuthorizationMiddleware(authz authz.Authorization, svc invitations.Service)
 }
 
 func (am *authorizationMiddleware) SendInvitation(ctx context.Context, session authn.Session, invitation invitations.Invitation) (err error) {
-	if err := am.checkAdmin(ctx, session.UserID, session.DomainID); err != nil {
-		return err
+	// The users invite themselves to join the domain as its members,
+	// which the domain allows only if self-registration is enabled.
+	self := invitation.UserID == session.UserID
+	if self && invitation.Relation != policies.MemberRelation {
+		return svcerr.ErrAuthorization
+	}
+	if !self {
+		if err := am.checkAdmin(ctx, session.UserID, session.DomainID); err != nil {
+			return err
+		}
 	}
 
 	domainUserId := auth.EncodeDomainUserID(invitation.DomainID, invitation.UserID)
@@ -40,8 +48,10 @@ func (am *authorizationMiddleware) SendInvitation(ctx context.Context, session a
 		return errors.Wrap(svcerr.ErrConflict, ErrMemberExist)
 	}
 
-	if err := am.checkAdmin(ctx, session.DomainUserID, invitation.DomainID); err != nil {
-		return err
+	if !self {
+		if err := am.checkAdmin(ctx, session.DomainUserID, invitation.DomainID); err != nil {
+			return err
+		}
 	}
 
 	return am.svc.SendInvitation(ctx, session, invitation)
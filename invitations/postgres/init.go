@@ -43,6 +43,36 @@ func Migration() *migrate.MemoryMigrationSource {
 					 DROP COLUMN rejected_at`,
 				},
 			},
+			{
+				Id: "invitations_03_add_email_and_sent_at",
+				Up: []string{
+					`ALTER TABLE invitations
+					 ADD COLUMN email VARCHAR(254) NOT NULL DEFAULT '',
+					 ADD COLUMN sent_at TIMESTAMP`,
+					`UPDATE invitations SET sent_at = CASE
+						WHEN confirmed_at IS NULL AND rejected_at IS NULL THEN COALESCE(updated_at, created_at)
+						ELSE created_at
+					 END`,
+					`ALTER TABLE invitations
+					 ALTER COLUMN sent_at SET NOT NULL`,
+				},
+				Down: []string{
+					`ALTER TABLE invitations
+					 DROP COLUMN email,
+					 DROP COLUMN sent_at`,
+				},
+			},
+			{
+				Id: "invitations_04_add_expires_at",
+				Up: []string{
+					`ALTER TABLE invitations
+					 ADD COLUMN expires_at TIMESTAMP`,
+				},
+				Down: []string{
+					`ALTER TABLE invitations
+					 DROP COLUMN expires_at`,
+				},
+			},
 		},
 	}
 }
@@ -24,8 +24,8 @@ func NewRepository(db postgres.Database) invitations.Repository {
 }
 
 func (repo *repository) Create(ctx context.Context, invitation invitations.Invitation) (err error) {
-	q := `INSERT INTO invitations (invited_by, user_id, domain_id, token, relation, created_at)
-		VALUES (:invited_by, :user_id, :domain_id, :token, :relation, :created_at)`
+	q := `INSERT INTO invitations (invited_by, user_id, email, domain_id, token, relation, created_at, sent_at, expires_at)
+		VALUES (:invited_by, :user_id, :email, :domain_id, :token, :relation, :created_at, :sent_at, :expires_at)`
 
 	dbInv := toDBInvitation(invitation)
 	if _, err = repo.db.NamedExecContext(ctx, q, dbInv); err != nil {
@@ -36,7 +36,7 @@ func (repo *repository) Create(ctx context.Context, invitation invitations.Invit
 }
 
 func (repo *repository) Retrieve(ctx context.Context, userID, domainID string) (invitations.Invitation, error) {
-	q := `SELECT invited_by, user_id, domain_id, token, relation, created_at, updated_at, confirmed_at, rejected_at FROM invitations WHERE user_id = :user_id AND domain_id = :domain_id;`
+	q := `SELECT invited_by, user_id, email, domain_id, token, relation, created_at, updated_at, confirmed_at, rejected_at, sent_at, expires_at FROM invitations WHERE user_id = :user_id AND domain_id = :domain_id;`
 
 	dbinv := dbInvitation{
 		UserID:   userID,
@@ -63,7 +63,7 @@ func (repo *repository) Retrieve(ctx context.Context, userID, domainID string) (
 func (repo *repository) RetrieveAll(ctx context.Context, page invitations.Page) (invitations.InvitationPage, error) {
 	query := pageQuery(page)
 
-	q := fmt.Sprintf("SELECT invited_by, user_id, domain_id, relation, created_at, updated_at, confirmed_at, rejected_at FROM invitations %s LIMIT :limit OFFSET :offset;", query)
+	q := fmt.Sprintf("SELECT invited_by, user_id, email, domain_id, relation, created_at, updated_at, confirmed_at, rejected_at, sent_at, expires_at FROM invitations %s LIMIT :limit OFFSET :offset;", query)
 
 	rows, err := repo.db.NamedQueryContext(ctx, q, page)
 	if err != nil {
@@ -98,7 +98,7 @@ func (repo *repository) RetrieveAll(ctx context.Context, page invitations.Page)
 }
 
 func (repo *repository) UpdateToken(ctx context.Context, invitation invitations.Invitation) (err error) {
-	q := `UPDATE invitations SET token = :token, updated_at = :updated_at WHERE user_id = :user_id AND domain_id = :domain_id`
+	q := `UPDATE invitations SET token = :token, updated_at = :updated_at, sent_at = :sent_at, expires_at = :expires_at, email = COALESCE(NULLIF(:email, ''), email) WHERE user_id = :user_id AND domain_id = :domain_id`
 
 	dbinv := toDBInvitation(invitation)
 	result, err := repo.db.NamedExecContext(ctx, q, dbinv)
@@ -179,10 +179,16 @@ func pageQuery(pm invitations.Page) string {
 	}
 	if pm.State == invitations.Pending {
 		query = append(query, "confirmed_at IS NULL AND rejected_at IS NULL")
+		if !pm.Now.IsZero() {
+			query = append(query, "(expires_at IS NULL OR expires_at >= :now)")
+		}
 	}
 	if pm.State == invitations.Rejected {
 		query = append(query, "rejected_at IS NOT NULL")
 	}
+	if pm.State == invitations.Expired {
+		query = append(query, "confirmed_at IS NULL AND rejected_at IS NULL AND expires_at < :now")
+	}
 
 	if len(query) > 0 {
 		emq = fmt.Sprintf("WHERE %s", strings.Join(query, " AND "))
@@ -194,6 +200,7 @@ func pageQuery(pm invitations.Page) string {
 type dbInvitation struct {
 	InvitedBy   string       `db:"invited_by"`
 	UserID      string       `db:"user_id"`
+	Email       string       `db:"email"`
 	DomainID    string       `db:"domain_id"`
 	Token       string       `db:"token,omitempty"`
 	Relation    string       `db:"relation"`
@@ -201,10 +208,12 @@ type dbInvitation struct {
 	UpdatedAt   sql.NullTime `db:"updated_at,omitempty"`
 	ConfirmedAt sql.NullTime `db:"confirmed_at,omitempty"`
 	RejectedAt  sql.NullTime `db:"rejected_at,omitempty"`
+	SentAt      time.Time    `db:"sent_at"`
+	ExpiresAt   sql.NullTime `db:"expires_at,omitempty"`
 }
 
 func toDBInvitation(inv invitations.Invitation) dbInvitation {
-	var updatedAt, confirmedAt, rejectedAt sql.NullTime
+	var updatedAt, confirmedAt, rejectedAt, expiresAt sql.NullTime
 	if inv.UpdatedAt != (time.Time{}) {
 		updatedAt = sql.NullTime{Time: inv.UpdatedAt, Valid: true}
 	}
@@ -214,10 +223,14 @@ func toDBInvitation(inv invitations.Invitation) dbInvitation {
 	if inv.RejectedAt != (time.Time{}) {
 		rejectedAt = sql.NullTime{Time: inv.RejectedAt, Valid: true}
 	}
+	if inv.ExpiresAt != (time.Time{}) {
+		expiresAt = sql.NullTime{Time: inv.ExpiresAt, Valid: true}
+	}
 
 	return dbInvitation{
 		InvitedBy:   inv.InvitedBy,
 		UserID:      inv.UserID,
+		Email:       inv.Email,
 		DomainID:    inv.DomainID,
 		Token:       inv.Token,
 		Relation:    inv.Relation,
@@ -225,11 +238,13 @@ func toDBInvitation(inv invitations.Invitation) dbInvitation {
 		UpdatedAt:   updatedAt,
 		ConfirmedAt: confirmedAt,
 		RejectedAt:  rejectedAt,
+		SentAt:      inv.SentAt,
+		ExpiresAt:   expiresAt,
 	}
 }
 
 func toInvitation(dbinv dbInvitation) invitations.Invitation {
-	var updatedAt, confirmedAt, rejectedAt time.Time
+	var updatedAt, confirmedAt, rejectedAt, expiresAt time.Time
 	if dbinv.UpdatedAt.Valid {
 		updatedAt = dbinv.UpdatedAt.Time
 	}
@@ -239,10 +254,14 @@ func toInvitation(dbinv dbInvitation) invitations.Invitation {
 	if dbinv.RejectedAt.Valid {
 		rejectedAt = dbinv.RejectedAt.Time
 	}
+	if dbinv.ExpiresAt.Valid {
+		expiresAt = dbinv.ExpiresAt.Time
+	}
 
 	return invitations.Invitation{
 		InvitedBy:   dbinv.InvitedBy,
 		UserID:      dbinv.UserID,
+		Email:       dbinv.Email,
 		DomainID:    dbinv.DomainID,
 		Token:       dbinv.Token,
 		Relation:    dbinv.Relation,
@@ -250,5 +269,7 @@ func toInvitation(dbinv dbInvitation) invitations.Invitation {
 		UpdatedAt:   updatedAt,
 		ConfirmedAt: confirmedAt,
 		RejectedAt:  rejectedAt,
+		SentAt:      dbinv.SentAt,
+		ExpiresAt:   expiresAt,
 	}
 }
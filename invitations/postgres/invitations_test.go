@@ -195,6 +195,7 @@ func TestInvitationRetrieve(t *testing.T) {
 		Relation:  relation,
 		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
 	}
+	invitation.SentAt = invitation.CreatedAt
 	err := repo.Create(context.Background(), invitation)
 	require.Nil(t, err, fmt.Sprintf("create invitation unexpected error: %s", err))
 
@@ -281,6 +282,12 @@ func TestInvitationRetrieveAll(t *testing.T) {
 			Relation:  fmt.Sprintf("%s-%d", relation, i),
 			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
 		}
+		invitation.SentAt = invitation.CreatedAt
+		invitation.ExpiresAt = invitation.CreatedAt.Add(time.Hour)
+		// The first invitations are expired.
+		if i < 10 {
+			invitation.ExpiresAt = invitation.CreatedAt.Add(-time.Hour)
+		}
 		err := repo.Create(context.Background(), invitation)
 		require.Nil(t, err, fmt.Sprintf("create invitation unexpected error: %s", err))
 		invitation.Token = ""
@@ -634,6 +641,36 @@ func TestInvitationRetrieveAll(t *testing.T) {
 				Invitations: items[0:10],
 			},
 		},
+		{
+			desc: "retrieve invitations with pending state excluding expired",
+			page: invitations.Page{
+				State:  invitations.Pending,
+				Now:    time.Now().UTC(),
+				Offset: 0,
+				Limit:  10,
+			},
+			response: invitations.InvitationPage{
+				Total:       uint64(num - 11),
+				Offset:      0,
+				Limit:       10,
+				Invitations: items[10:20],
+			},
+		},
+		{
+			desc: "retrieve invitations with expired state",
+			page: invitations.Page{
+				State:  invitations.Expired,
+				Now:    time.Now().UTC(),
+				Offset: 0,
+				Limit:  10,
+			},
+			response: invitations.InvitationPage{
+				Total:       10,
+				Offset:      0,
+				Limit:       10,
+				Invitations: items[0:10],
+			},
+		},
 	}
 	for _, tc := range cases {
 		page, err := repo.RetrieveAll(context.Background(), tc.page)
@@ -10,10 +10,16 @@ import (
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/auth"
 	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	mgsdk "github.com/absmach/magistrala/pkg/sdk/go"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 )
 
+// errJoinTokenExpiry indicates that the expiry of the join token can't be
+// read.
+var errJoinTokenExpiry = errors.New("failed to read join token expiry")
+
 type service struct {
 	token magistrala.TokenServiceClient
 	repo  Repository
@@ -40,18 +46,57 @@ func (svc *service) SendInvitation(ctx context.Context, session authn.Session, i
 		return err
 	}
 	invitation.Token = joinToken.GetAccessToken()
+	invitation.SentAt = time.Now()
+	// The invitation expires with its join token.
+	if invitation.ExpiresAt, err = tokenExpiry(invitation.Token); err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	// The email is resolved by the users service, which reveals it only to
+	// the users allowed to view the identity of the invitee.
+	user, sdkerr := svc.sdk.User(invitation.UserID, invitation.Token)
+	if sdkerr != nil {
+		return sdkerr
+	}
+	invitation.Email = user.Credentials.Identity
+
+	// The users invite themselves to join the domains allowing
+	// self-registration, which admit them right away.
+	if invitation.UserID == session.UserID {
+		return svc.join(ctx, invitation)
+	}
 
 	if invitation.Resend {
-		invitation.UpdatedAt = time.Now()
+		invitation.UpdatedAt = invitation.SentAt
 
 		return svc.repo.UpdateToken(ctx, invitation)
 	}
 
-	invitation.CreatedAt = time.Now()
+	invitation.CreatedAt = invitation.SentAt
 
 	return svc.repo.Create(ctx, invitation)
 }
 
+// join adds the user to the domain, which checks that the domain allows
+// self-registration, and records the invitation as accepted.
+func (svc *service) join(ctx context.Context, invitation Invitation) error {
+	req := mgsdk.UsersRelationRequest{
+		Relation: invitation.Relation,
+		UserIDs:  []string{invitation.UserID},
+	}
+	if sdkerr := svc.sdk.AddUserToDomain(invitation.DomainID, req, invitation.Token); sdkerr != nil {
+		return sdkerr
+	}
+
+	invitation.CreatedAt = invitation.SentAt
+	invitation.ConfirmedAt = invitation.SentAt
+	invitation.UpdatedAt = invitation.SentAt
+	if err := svc.repo.Create(ctx, invitation); err != nil {
+		return err
+	}
+
+	return svc.repo.UpdateConfirmation(ctx, invitation)
+}
+
 func (svc *service) ViewInvitation(ctx context.Context, session authn.Session, userID, domainID string) (invitation Invitation, err error) {
 	inv, err := svc.repo.Retrieve(ctx, userID, domainID)
 	if err != nil {
@@ -59,14 +104,20 @@ func (svc *service) ViewInvitation(ctx context.Context, session authn.Session, u
 	}
 	inv.Token = ""
 
-	return inv, nil
+	return svc.withState(inv, time.Now()), nil
 }
 
 func (svc *service) ListInvitations(ctx context.Context, session authn.Session, page Page) (invitations InvitationPage, err error) {
+	now := time.Now()
+	page.Now = now
 	ip, err := svc.repo.RetrieveAll(ctx, page)
 	if err != nil {
 		return InvitationPage{}, err
 	}
+	for i, inv := range ip.Invitations {
+		ip.Invitations[i] = svc.withState(inv, now)
+	}
+
 	return ip, nil
 }
 
@@ -140,3 +191,31 @@ func (svc *service) DeleteInvitation(ctx context.Context, session authn.Session,
 
 	return svc.repo.Delete(ctx, userID, domainID)
 }
+
+// withState sets the state of the invitation at the given time.
+func (svc *service) withState(inv Invitation, now time.Time) Invitation {
+	switch {
+	case !inv.ConfirmedAt.IsZero():
+		inv.State = Accepted
+	case !inv.RejectedAt.IsZero():
+		inv.State = Rejected
+	case !inv.ExpiresAt.IsZero() && inv.ExpiresAt.Before(now):
+		inv.State = Expired
+	default:
+		inv.State = Pending
+	}
+
+	return inv
+}
+
+// tokenExpiry reads the expiry of the join token issued by the auth service.
+// The token is not verified, since it's just issued. Tokens without expiry
+// return zero time.
+func tokenExpiry(token string) (time.Time, error) {
+	tkn, err := jwt.ParseInsecure([]byte(token))
+	if err != nil {
+		return time.Time{}, errors.Wrap(errJoinTokenExpiry, err)
+	}
+
+	return tkn.Expiration(), nil
+}
@@ -5,6 +5,8 @@ package invitations_test
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
@@ -19,7 +21,10 @@ import (
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/policies"
+	mgsdk "github.com/absmach/magistrala/pkg/sdk/go"
 	sdkmocks "github.com/absmach/magistrala/pkg/sdk/mocks"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -38,86 +43,198 @@ var (
 )
 
 func TestSendInvitation(t *testing.T) {
-	repo := new(mocks.Repository)
-	token := new(authmocks.TokenServiceClient)
-	svc := invitations.NewService(token, repo, nil)
+	expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	joinToken := newJoinToken(t, expiry)
+	email := "invitee@example.com"
 
 	cases := []struct {
-		desc        string
-		token       string
-		session     authn.Session
-		tokenUserID string
-		req         invitations.Invitation
-		err         error
-		issueErr    error
-		repoErr     error
+		desc       string
+		session    authn.Session
+		req        invitations.Invitation
+		joinToken  string
+		user       mgsdk.User
+		err        error
+		issueErr   error
+		sdkErr     errors.SDKError
+		repoErr    error
+		invitation invitations.Invitation
 	}{
 		{
-			desc:        "send invitation successful",
-			token:       validToken,
-			session:     authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
-			tokenUserID: testsutil.GenerateUUID(t),
-			req:         validInvitation,
-			err:         nil,
-			issueErr:    nil,
-			repoErr:     nil,
+			desc:      "send invitation successful",
+			session:   authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
+			req:       validInvitation,
+			joinToken: joinToken,
+			user:      mgsdk.User{ID: validInvitation.UserID, Credentials: mgsdk.Credentials{Identity: email}},
+			err:       nil,
+			invitation: invitations.Invitation{
+				InvitedBy: validUserID,
+				UserID:    validInvitation.UserID,
+				Email:     email,
+				DomainID:  validInvitation.DomainID,
+				Token:     joinToken,
+				Relation:  validInvitation.Relation,
+				ExpiresAt: expiry,
+			},
 		},
 		{
-			desc:        "failed to issue token",
-			token:       invalidToken,
-			session:     authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
-			tokenUserID: testsutil.GenerateUUID(t),
-			req:         validInvitation,
-			err:         svcerr.ErrCreateEntity,
-			issueErr:    svcerr.ErrCreateEntity,
-			repoErr:     nil,
+			desc:      "send invitation without invitee identity",
+			session:   authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
+			req:       validInvitation,
+			joinToken: joinToken,
+			user:      mgsdk.User{ID: validInvitation.UserID, Name: "invitee"},
+			err:       nil,
+			invitation: invitations.Invitation{
+				InvitedBy: validUserID,
+				UserID:    validInvitation.UserID,
+				DomainID:  validInvitation.DomainID,
+				Token:     joinToken,
+				Relation:  validInvitation.Relation,
+				ExpiresAt: expiry,
+			},
 		},
 		{
-			desc:        "invalid relation",
-			token:       validToken,
-			tokenUserID: testsutil.GenerateUUID(t),
-			req:         invitations.Invitation{Relation: "invalid"},
-			err:         apiutil.ErrInvalidRelation,
-			issueErr:    nil,
-			repoErr:     nil,
+			desc:     "failed to issue token",
+			session:  authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
+			req:      validInvitation,
+			err:      svcerr.ErrCreateEntity,
+			issueErr: svcerr.ErrCreateEntity,
 		},
 		{
-			desc:        "resend invitation",
-			token:       invalidToken,
-			session:     authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
-			tokenUserID: testsutil.GenerateUUID(t),
+			desc:      "send invitation with malformed join token",
+			session:   authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
+			req:       validInvitation,
+			joinToken: invalidToken,
+			err:       svcerr.ErrCreateEntity,
+		},
+		{
+			desc:      "send invitation with failed to view invitee",
+			session:   authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
+			req:       validInvitation,
+			joinToken: joinToken,
+			sdkErr:    errors.NewSDKErrorWithStatus(svcerr.ErrNotFound, http.StatusNotFound),
+			err:       svcerr.ErrNotFound,
+		},
+		{
+			desc:    "invalid relation",
+			req:     invitations.Invitation{Relation: "invalid"},
+			err:     apiutil.ErrInvalidRelation,
+			session: authn.Session{UserID: validUserID},
+		},
+		{
+			desc:    "resend invitation",
+			session: authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
 			req: invitations.Invitation{
 				UserID:   validInvitation.UserID,
 				DomainID: validInvitation.DomainID,
 				Relation: validInvitation.Relation,
 				Resend:   true,
 			},
-			err:      nil,
-			issueErr: nil,
-			repoErr:  nil,
+			joinToken: joinToken,
+			user:      mgsdk.User{ID: validInvitation.UserID, Credentials: mgsdk.Credentials{Identity: email}},
+			err:       nil,
+			invitation: invitations.Invitation{
+				InvitedBy: validUserID,
+				UserID:    validInvitation.UserID,
+				Email:     email,
+				DomainID:  validInvitation.DomainID,
+				Token:     joinToken,
+				Relation:  validInvitation.Relation,
+				ExpiresAt: expiry,
+				Resend:    true,
+			},
 		},
 		{
-			desc:        "error during token issuance",
-			token:       validToken,
-			tokenUserID: testsutil.GenerateUUID(t),
-			req:         validInvitation,
-			err:         svcerr.ErrAuthentication,
-			issueErr:    svcerr.ErrAuthentication,
-			repoErr:     nil,
+			desc:     "error during token issuance",
+			req:      validInvitation,
+			err:      svcerr.ErrAuthentication,
+			issueErr: svcerr.ErrAuthentication,
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			repocall1 := token.On("Issue", context.Background(), mock.Anything).Return(&magistrala.Token{AccessToken: tc.req.Token}, tc.issueErr)
-			repocall2 := repo.On("Create", context.Background(), mock.Anything).Return(tc.repoErr)
-			if tc.req.Resend {
-				repocall2 = repo.On("UpdateToken", context.Background(), mock.Anything).Return(tc.repoErr)
-			}
+			repo := new(mocks.Repository)
+			token := new(authmocks.TokenServiceClient)
+			sdksvc := new(sdkmocks.SDK)
+			svc := invitations.NewService(token, repo, sdksvc)
+
+			token.On("Issue", context.Background(), mock.Anything).Return(&magistrala.Token{AccessToken: tc.joinToken}, tc.issueErr)
+			sdksvc.On("User", tc.req.UserID, tc.joinToken).Return(tc.user, tc.sdkErr)
+			saved := mock.MatchedBy(func(inv invitations.Invitation) bool {
+				inv.SentAt, inv.CreatedAt, inv.UpdatedAt = time.Time{}, time.Time{}, time.Time{}
+				return assert.ObjectsAreEqual(tc.invitation, inv)
+			})
+			repo.On("Create", context.Background(), saved).Return(tc.repoErr)
+			repo.On("UpdateToken", context.Background(), saved).Return(tc.repoErr)
 			err := svc.SendInvitation(context.Background(), tc.session, tc.req)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			switch {
+			case tc.err != nil:
+				repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				repo.AssertNotCalled(t, "UpdateToken", mock.Anything, mock.Anything)
+			case tc.req.Resend:
+				repo.AssertNumberOfCalls(t, "UpdateToken", 1)
+			default:
+				repo.AssertNumberOfCalls(t, "Create", 1)
+			}
+		})
+	}
+}
+
+func TestSendSelfInvitation(t *testing.T) {
+	joinToken := newJoinToken(t, time.Now().Add(time.Hour))
+	userID := testsutil.GenerateUUID(t)
+	session := authn.Session{UserID: userID}
+	invitation := invitations.Invitation{
+		UserID:   userID,
+		DomainID: validInvitation.DomainID,
+		Relation: policies.MemberRelation,
+	}
+
+	cases := []struct {
+		desc     string
+		sdkErr   errors.SDKError
+		repoErr  error
+		repoErr1 error
+		err      error
+	}{
+		{
+			desc: "join domain allowing self-registration",
+		},
+		{
+			desc:   "join domain disallowing self-registration",
+			sdkErr: errors.NewSDKErrorWithStatus(svcerr.ErrSelfRegistrationDisabled, http.StatusForbidden),
+			err:    errors.NewSDKErrorWithStatus(svcerr.ErrSelfRegistrationDisabled, http.StatusForbidden),
+		},
+		{
+			desc:    "join domain with failed to save invitation",
+			repoErr: repoerr.ErrCreateEntity,
+			err:     repoerr.ErrCreateEntity,
+		},
+		{
+			desc:     "join domain with failed to confirm invitation",
+			repoErr1: repoerr.ErrUpdateEntity,
+			err:      repoerr.ErrUpdateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			token := new(authmocks.TokenServiceClient)
+			sdksvc := new(sdkmocks.SDK)
+			svc := invitations.NewService(token, repo, sdksvc)
+
+			token.On("Issue", context.Background(), mock.Anything).Return(&magistrala.Token{AccessToken: joinToken}, nil)
+			sdksvc.On("User", userID, joinToken).Return(mgsdk.User{ID: userID}, nil)
+			sdksvc.On("AddUserToDomain", invitation.DomainID, mgsdk.UsersRelationRequest{Relation: policies.MemberRelation, UserIDs: []string{userID}}, joinToken).Return(tc.sdkErr)
+			repo.On("Create", context.Background(), mock.Anything).Return(tc.repoErr)
+			repo.On("UpdateConfirmation", context.Background(), mock.Anything).Return(tc.repoErr1)
+			err := svc.SendInvitation(context.Background(), session, invitation)
 			assert.Equal(t, tc.err, err, tc.desc)
-			repocall1.Unset()
-			repocall2.Unset()
+			if tc.sdkErr != nil {
+				repo.AssertNotCalled(t, "Create", context.Background(), mock.Anything)
+			}
 		})
 	}
 }
@@ -135,7 +252,11 @@ func TestViewInvitation(t *testing.T) {
 		CreatedAt:   time.Now().Add(-time.Hour),
 		UpdatedAt:   time.Now().Add(-time.Hour),
 		ConfirmedAt: time.Now().Add(-time.Hour),
+		SentAt:      time.Now().Add(-2 * time.Hour),
+		ExpiresAt:   time.Now().Add(time.Hour),
 	}
+	viewedInvitation := validInvitation
+	viewedInvitation.State = invitations.Accepted
 	cases := []struct {
 		desc        string
 		token       string
@@ -145,6 +266,7 @@ func TestViewInvitation(t *testing.T) {
 		tokenUserID string
 		req         invitations.Invitation
 		resp        invitations.Invitation
+		inv         invitations.Invitation
 		err         error
 		issueErr    error
 		repoErr     error
@@ -157,6 +279,7 @@ func TestViewInvitation(t *testing.T) {
 			domainID:    validInvitation.DomainID,
 			session:     authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
 			resp:        validInvitation,
+			inv:         viewedInvitation,
 			err:         nil,
 			repoErr:     nil,
 		},
@@ -178,6 +301,7 @@ func TestViewInvitation(t *testing.T) {
 			domainID:    validInvitation.DomainID,
 			session:     authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
 			resp:        validInvitation,
+			inv:         viewedInvitation,
 			tokenUserID: validInvitation.UserID,
 			err:         nil,
 			repoErr:     nil,
@@ -190,6 +314,7 @@ func TestViewInvitation(t *testing.T) {
 			session:     authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID},
 			tokenUserID: validInvitation.InvitedBy,
 			resp:        validInvitation,
+			inv:         viewedInvitation,
 			err:         nil,
 			repoErr:     nil,
 		},
@@ -200,7 +325,7 @@ func TestViewInvitation(t *testing.T) {
 			repocall1 := repo.On("Retrieve", context.Background(), mock.Anything, mock.Anything).Return(tc.resp, tc.repoErr)
 			inv, err := svc.ViewInvitation(context.Background(), tc.session, tc.userID, tc.domainID)
 			assert.Equal(t, tc.err, err, tc.desc)
-			assert.Equal(t, tc.resp, inv, tc.desc)
+			assert.Equal(t, tc.inv, inv, tc.desc)
 			repocall1.Unset()
 		})
 	}
@@ -270,6 +395,88 @@ func TestListInvitations(t *testing.T) {
 	}
 }
 
+func TestListInvitationsByState(t *testing.T) {
+	now := time.Now()
+	invitation := func(expiresAt time.Time) invitations.Invitation {
+		return invitations.Invitation{
+			InvitedBy: testsutil.GenerateUUID(t),
+			UserID:    testsutil.GenerateUUID(t),
+			Email:     "invitee@example.com",
+			DomainID:  validDomainID,
+			Relation:  policies.ContributorRelation,
+			CreatedAt: now.Add(-2 * time.Hour),
+			SentAt:    now.Add(-2 * time.Hour),
+			ExpiresAt: expiresAt,
+		}
+	}
+	pending := invitation(now.Add(time.Hour))
+	expired := invitation(now.Add(-time.Hour))
+	accepted := invitation(now.Add(-time.Hour))
+	accepted.ConfirmedAt = now.Add(-90 * time.Minute)
+	rejected := invitation(now.Add(time.Hour))
+	rejected.RejectedAt = now.Add(-time.Minute)
+	legacy := invitation(time.Time{})
+
+	withState := func(inv invitations.Invitation, state invitations.State) invitations.Invitation {
+		inv.State = state
+		return inv
+	}
+
+	cases := []struct {
+		desc        string
+		state       invitations.State
+		invitations []invitations.Invitation
+		resp        []invitations.Invitation
+	}{
+		{
+			desc:        "list pending invitations",
+			state:       invitations.Pending,
+			invitations: []invitations.Invitation{pending, legacy},
+			resp:        []invitations.Invitation{withState(pending, invitations.Pending), withState(legacy, invitations.Pending)},
+		},
+		{
+			desc:        "list expired invitations",
+			state:       invitations.Expired,
+			invitations: []invitations.Invitation{expired},
+			resp:        []invitations.Invitation{withState(expired, invitations.Expired)},
+		},
+		{
+			desc:        "list accepted invitations",
+			state:       invitations.Accepted,
+			invitations: []invitations.Invitation{accepted},
+			resp:        []invitations.Invitation{withState(accepted, invitations.Accepted)},
+		},
+		{
+			desc:        "list all invitations",
+			state:       invitations.All,
+			invitations: []invitations.Invitation{pending, expired, accepted, rejected},
+			resp: []invitations.Invitation{
+				withState(pending, invitations.Pending),
+				withState(expired, invitations.Expired),
+				withState(accepted, invitations.Accepted),
+				withState(rejected, invitations.Rejected),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			svc := invitations.NewService(new(authmocks.TokenServiceClient), repo, nil)
+			page := invitations.Page{DomainID: validDomainID, State: tc.state, Offset: 0, Limit: 10}
+			atNow := mock.MatchedBy(func(p invitations.Page) bool {
+				return p.State == tc.state && !p.Now.Before(now) && time.Since(p.Now) < time.Minute
+			})
+			invs := append([]invitations.Invitation(nil), tc.invitations...)
+			repo.On("RetrieveAll", context.Background(), atNow).Return(invitations.InvitationPage{Total: uint64(len(invs)), Limit: 10, Invitations: invs}, nil)
+			resp, err := svc.ListInvitations(context.Background(), authn.Session{DomainUserID: validDomainUserID, DomainID: validDomainID, UserID: validUserID}, page)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.resp, resp.Invitations, tc.desc)
+			assert.Equal(t, uint64(len(tc.resp)), resp.Total, tc.desc)
+		})
+	}
+}
+
 func TestAcceptInvitation(t *testing.T) {
 	repo := new(mocks.Repository)
 	token := new(authmocks.TokenServiceClient)
@@ -513,3 +720,13 @@ func TestRejectInvitation(t *testing.T) {
 		})
 	}
 }
+
+// newJoinToken returns the join token expiring at the given time.
+func newJoinToken(t *testing.T, expiresAt time.Time) string {
+	tkn, err := jwt.NewBuilder().Expiration(expiresAt).Build()
+	assert.Nil(t, err, fmt.Sprintf("build join token: unexpected error %s", err))
+	signed, err := jwt.Sign(tkn, jwt.WithKey(jwa.HS256, []byte("secret")))
+	assert.Nil(t, err, fmt.Sprintf("sign join token: unexpected error %s", err))
+
+	return string(signed)
+}
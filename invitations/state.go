@@ -18,6 +18,7 @@ const (
 	Pending               // Pending is the state of an invitation that has not been accepted yet.
 	Accepted              // Accepted is the state of an invitation that has been accepted.
 	Rejected              // Rejected is the state of an invitation that has been rejected.
+	Expired               // Expired is the state of a pending invitation whose join token has expired.
 )
 
 // String representation of the possible state values.
@@ -26,6 +27,7 @@ const (
 	pending  = "pending"
 	accepted = "accepted"
 	rejected = "rejected"
+	expired  = "expired"
 	unknown  = "unknown"
 )
 
@@ -40,6 +42,8 @@ func (s State) String() string {
 		return accepted
 	case Rejected:
 		return rejected
+	case Expired:
+		return expired
 	default:
 		return unknown
 	}
@@ -56,6 +60,8 @@ func ToState(status string) (State, error) {
 		return Accepted, nil
 	case rejected:
 		return Rejected, nil
+	case expired:
+		return Expired, nil
 	}
 
 	return State(0), apiutil.ErrInvitationState
@@ -20,6 +20,7 @@ func TestState_String(t *testing.T) {
 		{"Pending", invitations.Pending, "pending"},
 		{"Accepted", invitations.Accepted, "accepted"},
 		{"Rejected", invitations.Rejected, "rejected"},
+		{"Expired", invitations.Expired, "expired"},
 		{"All", invitations.All, "all"},
 		{"Unknown", invitations.State(100), "unknown"},
 	}
@@ -40,6 +41,7 @@ func TestToState(t *testing.T) {
 		{"Pending", "pending", invitations.Pending, nil},
 		{"Accepted", "accepted", invitations.Accepted, nil},
 		{"Rejected", "rejected", invitations.Rejected, nil},
+		{"Expired", "expired", invitations.Expired, nil},
 		{"All", "all", invitations.All, nil},
 		{"Unknown", "unknown", invitations.State(0), apiutil.ErrInvitationState},
 	}
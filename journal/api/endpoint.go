@@ -29,3 +29,39 @@ func retrieveJournalsEndpoint(svc journal.Service) endpoint.Endpoint {
 		}, nil
 	}
 }
+
+func retrieveDomainJournalsEndpoint(svc journal.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(retrieveDomainJournalsReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		page, err := svc.RetrieveDomainJournals(ctx, req.token, req.domainID, req.page)
+		if err != nil {
+			return nil, err
+		}
+
+		return pageRes{
+			JournalsPage: page,
+		}, nil
+	}
+}
+
+func countConnsEndpoint(svc journal.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(countConnsReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		count, err := svc.CountConns(ctx, req.token, req.domainID, req.channelID)
+		if err != nil {
+			return nil, err
+		}
+
+		return connsCountRes{
+			ConnsCount: count,
+		}, nil
+	}
+}
@@ -280,3 +280,177 @@ func TestListJournalsEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestListDomainJournalsEndpoint(t *testing.T) {
+	es, svc := newjournalServer()
+
+	domainID := "domain"
+	actorID := "actor"
+
+	cases := []struct {
+		desc   string
+		token  string
+		url    string
+		page   journal.Page
+		status int
+		svcErr error
+	}{
+		{
+			desc:   "successful",
+			token:  validToken,
+			url:    "/" + domainID + "/journal",
+			page:   journal.Page{Limit: 10, Direction: "desc"},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "with actor and operation",
+			token:  validToken,
+			url:    fmt.Sprintf("/%s/journal?actor=%s&operation=thing.update", domainID, actorID),
+			page:   journal.Page{Limit: 10, Direction: "desc", Actor: actorID, Operation: "thing.update"},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "with target and time range",
+			token:  validToken,
+			url:    fmt.Sprintf("/%s/journal?target=thing&from=10&to=20&offset=5&limit=5", domainID),
+			page:   journal.Page{Offset: 5, Limit: 5, Direction: "desc", Target: "thing", From: time.Unix(10, 0), To: time.Unix(20, 0)},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "empty token",
+			token:  "",
+			url:    "/" + domainID + "/journal",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "with service error",
+			token:  validToken,
+			url:    "/" + domainID + "/journal",
+			page:   journal.Page{Limit: 10, Direction: "desc"},
+			status: http.StatusForbidden,
+			svcErr: svcerr.ErrAuthorization,
+		},
+		{
+			desc:   "with malformed actor",
+			token:  validToken,
+			url:    fmt.Sprintf("/%s/journal?actor=%s&actor=other", domainID, actorID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "with malformed target",
+			token:  validToken,
+			url:    fmt.Sprintf("/%s/journal?target=thing&target=other", domainID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "with invalid limit",
+			token:  validToken,
+			url:    "/" + domainID + "/journal?limit=1000",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "with invalid direction",
+			token:  validToken,
+			url:    "/" + domainID + "/journal?dir=invalid",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			svcCall := svc.On("RetrieveDomainJournals", mock.Anything, c.token, domainID, c.page).Return(journal.JournalsPage{}, c.svcErr)
+			req := testRequest{
+				client: es.Client(),
+				method: http.MethodGet,
+				url:    es.URL + c.url,
+				token:  c.token,
+			}
+
+			resp, err := req.make()
+			assert.Nil(t, err, c.desc)
+			defer resp.Body.Close()
+			assert.Equal(t, c.status, resp.StatusCode, c.desc)
+			svcCall.Unset()
+		})
+	}
+}
+
+func TestCountConnsEndpoint(t *testing.T) {
+	es, svc := newjournalServer()
+
+	domainID := "domain"
+	channelID := "channel"
+	count := journal.ConnsCount{
+		DomainID: domainID,
+		Things:   2,
+		Channels: []journal.ChannelConns{{ChannelID: channelID, Things: 2}},
+	}
+
+	cases := []struct {
+		desc      string
+		token     string
+		url       string
+		channelID string
+		status    int
+		res       string
+		svcErr    error
+	}{
+		{
+			desc:   "count connections of domain",
+			token:  validToken,
+			url:    "/" + domainID + "/connections",
+			status: http.StatusOK,
+			res:    `{"domain_id":"domain","things":2,"channels":[{"channel_id":"channel","things":2}]}`,
+		},
+		{
+			desc:      "count connections of channel",
+			token:     validToken,
+			url:       fmt.Sprintf("/%s/connections?channel_id=%s", domainID, channelID),
+			channelID: channelID,
+			status:    http.StatusOK,
+			res:       `{"domain_id":"domain","things":2,"channels":[{"channel_id":"channel","things":2}]}`,
+		},
+		{
+			desc:   "count connections with empty token",
+			token:  "",
+			url:    "/" + domainID + "/connections",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "count connections with malformed channel",
+			token:  validToken,
+			url:    fmt.Sprintf("/%s/connections?channel_id=%s&channel_id=other", domainID, channelID),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "count connections with service error",
+			token:  validToken,
+			url:    "/" + domainID + "/connections",
+			status: http.StatusForbidden,
+			svcErr: svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			svcCall := svc.On("CountConns", mock.Anything, c.token, domainID, c.channelID).Return(count, c.svcErr)
+			req := testRequest{
+				client: es.Client(),
+				method: http.MethodGet,
+				url:    es.URL + c.url,
+				token:  c.token,
+			}
+
+			resp, err := req.make()
+			assert.Nil(t, err, c.desc)
+			defer resp.Body.Close()
+			assert.Equal(t, c.status, resp.StatusCode, c.desc)
+			if c.res != "" {
+				body, err := io.ReadAll(resp.Body)
+				assert.Nil(t, err, c.desc)
+				assert.JSONEq(t, c.res, string(body), c.desc)
+			}
+			svcCall.Unset()
+		})
+	}
+}
@@ -30,3 +30,43 @@ func (req retrieveJournalsReq) validate() error {
 
 	return nil
 }
+
+type retrieveDomainJournalsReq struct {
+	token    string
+	domainID string
+	page     journal.Page
+}
+
+func (req retrieveDomainJournalsReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+	if req.domainID == "" {
+		return apiutil.ErrMissingDomainID
+	}
+	if req.page.Limit > api.DefLimit {
+		return apiutil.ErrLimitSize
+	}
+	if req.page.Direction != "" && req.page.Direction != api.AscDir && req.page.Direction != api.DescDir {
+		return apiutil.ErrInvalidDirection
+	}
+
+	return nil
+}
+
+type countConnsReq struct {
+	token     string
+	domainID  string
+	channelID string
+}
+
+func (req countConnsReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+	if req.domainID == "" {
+		return apiutil.ErrMissingDomainID
+	}
+
+	return nil
+}
@@ -124,3 +124,62 @@ func TestRetrieveJournalsReqValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieveDomainJournalsReqValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		req  retrieveDomainJournalsReq
+		err  error
+	}{
+		{
+			desc: "valid",
+			req: retrieveDomainJournalsReq{
+				token:    token,
+				domainID: "domain",
+				page:     journal.Page{Limit: limit, Actor: "actor"},
+			},
+			err: nil,
+		},
+		{
+			desc: "missing token",
+			req: retrieveDomainJournalsReq{
+				domainID: "domain",
+				page:     journal.Page{Limit: limit},
+			},
+			err: apiutil.ErrBearerToken,
+		},
+		{
+			desc: "missing domain ID",
+			req: retrieveDomainJournalsReq{
+				token: token,
+				page:  journal.Page{Limit: limit},
+			},
+			err: apiutil.ErrMissingDomainID,
+		},
+		{
+			desc: "invalid limit size",
+			req: retrieveDomainJournalsReq{
+				token:    token,
+				domainID: "domain",
+				page:     journal.Page{Limit: api.DefLimit + 1},
+			},
+			err: apiutil.ErrLimitSize,
+		},
+		{
+			desc: "invalid sorting direction",
+			req: retrieveDomainJournalsReq{
+				token:    token,
+				domainID: "domain",
+				page:     journal.Page{Limit: limit, Direction: "invalid"},
+			},
+			err: apiutil.ErrInvalidDirection,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := c.req.validate()
+			assert.Equal(t, c.err, err)
+		})
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"github.com/absmach/magistrala/journal"
 )
 
-var _ magistrala.Response = (*pageRes)(nil)
+var (
+	_ magistrala.Response = (*pageRes)(nil)
+	_ magistrala.Response = (*connsCountRes)(nil)
+)
 
 type pageRes struct {
 	journal.JournalsPage `json:",inline"`
@@ -27,3 +30,19 @@ func (res pageRes) Code() int {
 func (res pageRes) Empty() bool {
 	return false
 }
+
+type connsCountRes struct {
+	journal.ConnsCount `json:",inline"`
+}
+
+func (res connsCountRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res connsCountRes) Code() int {
+	return http.StatusOK
+}
+
+func (res connsCountRes) Empty() bool {
+	return false
+}
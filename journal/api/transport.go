@@ -30,6 +30,9 @@ const (
 	metadataKey   = "with_metadata"
 	entityIDKey   = "id"
 	entityTypeKey = "entity_type"
+	actorKey      = "actor"
+	targetKey     = "target"
+	channelIDKey  = "channel_id"
 )
 
 // MakeHandler returns a HTTP API handler with health check and metrics.
@@ -47,6 +50,20 @@ func MakeHandler(svc journal.Service, logger *slog.Logger, svcName, instanceID s
 		opts...,
 	), "list_journals").ServeHTTP)
 
+	mux.Get("/{domainID}/journal", otelhttp.NewHandler(kithttp.NewServer(
+		retrieveDomainJournalsEndpoint(svc),
+		decodeRetrieveDomainJournalsReq,
+		api.EncodeResponse,
+		opts...,
+	), "list_domain_journals").ServeHTTP)
+
+	mux.Get("/{domainID}/connections", otelhttp.NewHandler(kithttp.NewServer(
+		countConnsEndpoint(svc),
+		decodeCountConnsReq,
+		api.EncodeResponse,
+		opts...,
+	), "count_connections").ServeHTTP)
+
 	mux.Get("/health", magistrala.Health(svcName, instanceID))
 	mux.Handle("/metrics", promhttp.Handler())
 
@@ -54,24 +71,84 @@ func MakeHandler(svc journal.Service, logger *slog.Logger, svcName, instanceID s
 }
 
 func decodeRetrieveJournalReq(_ context.Context, r *http.Request) (interface{}, error) {
-	offset, err := apiutil.ReadNumQuery[uint64](r, api.OffsetKey, api.DefOffset)
+	page, err := decodePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	entityType, err := journal.ToEntityType(chi.URLParam(r, "entityType"))
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	limit, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	if entityType == journal.ChannelEntity {
+		page.Operation = strings.ReplaceAll(page.Operation, "channel", "group")
+	}
+	page.EntityID = chi.URLParam(r, "entityID")
+	page.EntityType = entityType
+
+	req := retrieveJournalsReq{
+		token: apiutil.ExtractBearerToken(r),
+		page:  page,
+	}
+
+	return req, nil
+}
+
+func decodeRetrieveDomainJournalsReq(_ context.Context, r *http.Request) (interface{}, error) {
+	page, err := decodePage(r)
 	if err != nil {
+		return nil, err
+	}
+	if page.Actor, err = apiutil.ReadStringQuery(r, actorKey, ""); err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	operation, err := apiutil.ReadStringQuery(r, operationKey, "")
+	if page.Target, err = apiutil.ReadStringQuery(r, targetKey, ""); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	req := retrieveDomainJournalsReq{
+		token:    apiutil.ExtractBearerToken(r),
+		domainID: chi.URLParam(r, "domainID"),
+		page:     page,
+	}
+
+	return req, nil
+}
+
+func decodeCountConnsReq(_ context.Context, r *http.Request) (interface{}, error) {
+	channelID, err := apiutil.ReadStringQuery(r, channelIDKey, "")
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
+
+	req := countConnsReq{
+		token:     apiutil.ExtractBearerToken(r),
+		domainID:  chi.URLParam(r, "domainID"),
+		channelID: channelID,
+	}
+
+	return req, nil
+}
+
+func decodePage(r *http.Request) (journal.Page, error) {
+	offset, err := apiutil.ReadNumQuery[uint64](r, api.OffsetKey, api.DefOffset)
+	if err != nil {
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	limit, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	if err != nil {
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	operation, err := apiutil.ReadStringQuery(r, operationKey, "")
+	if err != nil {
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
+	}
 	from, err := apiutil.ReadNumQuery[int64](r, fromKey, 0)
 	if err != nil {
-		return nil, errors.Wrap(apiutil.ErrValidation, err)
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
 	if from > math.MaxInt32 {
-		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrInvalidTimeFormat)
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, apiutil.ErrInvalidTimeFormat)
 	}
 	var fromTime time.Time
 	if from != 0 {
@@ -79,10 +156,10 @@ func decodeRetrieveJournalReq(_ context.Context, r *http.Request) (interface{},
 	}
 	to, err := apiutil.ReadNumQuery[int64](r, toKey, 0)
 	if err != nil {
-		return nil, errors.Wrap(apiutil.ErrValidation, err)
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
 	if to > math.MaxInt32 {
-		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrInvalidTimeFormat)
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, apiutil.ErrInvalidTimeFormat)
 	}
 	var toTime time.Time
 	if to != 0 {
@@ -90,40 +167,25 @@ func decodeRetrieveJournalReq(_ context.Context, r *http.Request) (interface{},
 	}
 	attributes, err := apiutil.ReadBoolQuery(r, attributesKey, false)
 	if err != nil {
-		return nil, errors.Wrap(apiutil.ErrValidation, err)
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
 	metadata, err := apiutil.ReadBoolQuery(r, metadataKey, false)
 	if err != nil {
-		return nil, errors.Wrap(apiutil.ErrValidation, err)
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
 	dir, err := apiutil.ReadStringQuery(r, api.DirKey, api.DescDir)
 	if err != nil {
-		return nil, errors.Wrap(apiutil.ErrValidation, err)
-	}
-
-	entityType, err := journal.ToEntityType(chi.URLParam(r, "entityType"))
-	if err != nil {
-		return nil, errors.Wrap(apiutil.ErrValidation, err)
-	}
-	if entityType == journal.ChannelEntity {
-		operation = strings.ReplaceAll(operation, "channel", "group")
-	}
-
-	req := retrieveJournalsReq{
-		token: apiutil.ExtractBearerToken(r),
-		page: journal.Page{
-			Offset:         offset,
-			Limit:          limit,
-			Operation:      operation,
-			From:           fromTime,
-			To:             toTime,
-			WithAttributes: attributes,
-			WithMetadata:   metadata,
-			EntityID:       chi.URLParam(r, "entityID"),
-			EntityType:     entityType,
-			Direction:      dir,
-		},
+		return journal.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
 
-	return req, nil
+	return journal.Page{
+		Offset:         offset,
+		Limit:          limit,
+		Operation:      operation,
+		From:           fromTime,
+		To:             toTime,
+		WithAttributes: attributes,
+		WithMetadata:   metadata,
+		Direction:      dir,
+	}, nil
 }
@@ -0,0 +1,130 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// Operations of the connection events the protocol adapters feed the
+// connection registry with. The clients open and close the connections, and
+// the things of the connections are counted once they are authorized on the
+// channels, since the adapters authorize the things per channel.
+const (
+	ConnOpen      = "conn.open"
+	ConnChannel   = "conn.channel"
+	ConnClose     = "conn.close"
+	ConnReset     = "conn.reset"
+	ConnHeartbeat = "conn.heartbeat"
+)
+
+var errConnEvent = errors.New("malformed connection event")
+
+// Conn is the live connection of the thing to the channel through the client
+// of the protocol adapter instance.
+type Conn struct {
+	Instance    string    `db:"instance"`
+	ClientID    string    `db:"client_id"`
+	ThingID     string    `db:"thing_id"`
+	DomainID    string    `db:"domain_id"`
+	ChannelID   string    `db:"channel_id"`
+	Protocol    string    `db:"protocol"`
+	ConnectedAt time.Time `db:"connected_at"`
+}
+
+// ConnsCount is the number of the things connected to the domain, and to
+// each of its channels with connected things.
+type ConnsCount struct {
+	DomainID string         `json:"domain_id"`
+	Things   uint64         `json:"things"`
+	Channels []ChannelConns `json:"channels"`
+}
+
+// ChannelConns is the number of the things connected to the channel.
+type ChannelConns struct {
+	ChannelID string `json:"channel_id" db:"channel_id"`
+	Things    uint64 `json:"things"     db:"things"`
+}
+
+func (cc ConnsCount) MarshalJSON() ([]byte, error) {
+	type Alias ConnsCount
+	a := struct {
+		Alias
+	}{
+		Alias: Alias(cc),
+	}
+
+	if a.Channels == nil {
+		a.Channels = make([]ChannelConns, 0)
+	}
+
+	return json.Marshal(a)
+}
+
+// register updates the connection registry with the connection event. The
+// instance is alive at the time of any of its events.
+func (svc *service) register(ctx context.Context, j Journal) error {
+	instance, _ := j.Attributes["instance"].(string)
+	if instance == "" {
+		return errors.Wrap(errConnEvent, errors.New("missing instance"))
+	}
+
+	switch j.Operation {
+	case ConnOpen, ConnClose:
+		// The connection opened by the client replaces the connection
+		// the client left open, if any.
+		clientID, _ := j.Attributes["client_id"].(string)
+		if clientID == "" {
+			return errors.Wrap(errConnEvent, errors.New("missing client"))
+		}
+		if err := svc.repository.RemoveConns(ctx, instance, clientID); err != nil {
+			return err
+		}
+	case ConnChannel:
+		conn := Conn{
+			Instance:    instance,
+			ConnectedAt: j.OccurredAt,
+		}
+		conn.ClientID, _ = j.Attributes["client_id"].(string)
+		conn.ThingID, _ = j.Attributes["thing_id"].(string)
+		conn.DomainID, _ = j.Attributes["domain_id"].(string)
+		conn.ChannelID, _ = j.Attributes["channel_id"].(string)
+		conn.Protocol, _ = j.Attributes["protocol"].(string)
+		if conn.ClientID == "" || conn.ThingID == "" || conn.DomainID == "" || conn.ChannelID == "" {
+			return errors.Wrap(errConnEvent, errors.New("missing client, thing, domain or channel"))
+		}
+		if err := svc.repository.SaveConn(ctx, conn); err != nil {
+			return err
+		}
+	case ConnReset:
+		if err := svc.repository.RemoveConns(ctx, instance, ""); err != nil {
+			return err
+		}
+	}
+
+	if err := svc.repository.SaveHeartbeat(ctx, instance, j.OccurredAt); err != nil {
+		return err
+	}
+	if j.Operation != ConnHeartbeat {
+		return nil
+	}
+	// The connections of the instances which stopped without closing them
+	// are removed once they expire.
+	_, err := svc.repository.PurgeConns(ctx, j.OccurredAt.Add(-svc.connsTTL))
+
+	return err
+}
+
+func isConnEvent(operation string) bool {
+	switch operation {
+	case ConnOpen, ConnChannel, ConnClose, ConnReset, ConnHeartbeat:
+		return true
+	default:
+		return false
+	}
+}
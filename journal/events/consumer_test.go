@@ -36,6 +36,7 @@ var (
 		"status": "active",
 	}
 	idProvider = uuid.New()
+	connsTTL   = time.Minute
 )
 
 type testEvent struct {
@@ -55,7 +56,7 @@ func TestHandle(t *testing.T) {
 	repo := new(mocks.Repository)
 	authn := new(authnmocks.Authentication)
 	authz := new(authzmocks.Authorization)
-	svc := journal.NewService(authn, authz, idProvider, repo)
+	svc := journal.NewService(authn, authz, idProvider, repo, connsTTL)
 
 	cases := []struct {
 		desc      string
@@ -118,6 +118,9 @@ type Page struct {
 	EntityID       string     `json:"entity_id,omitempty" db:"entity_id,omitempty"`
 	EntityType     EntityType `json:"entity_type,omitempty" db:"entity_type,omitempty"`
 	Direction      string     `json:"direction,omitempty"`
+	Domain         string     `json:"domain,omitempty" db:"domain,omitempty"` // Domain scopes the journals to the ones of the domain.
+	Actor          string     `json:"actor,omitempty" db:"actor,omitempty"`   // Actor is the ID of the user who performed the operation.
+	Target         string     `json:"target,omitempty" db:"target,omitempty"` // Target is the ID of the entity the operation was performed on.
 }
 
 func (page JournalsPage) MarshalJSON() ([]byte, error) {
@@ -144,6 +147,16 @@ type Service interface {
 
 	// RetrieveAll retrieves all journals from the database with the given page.
 	RetrieveAll(ctx context.Context, token string, page Page) (JournalsPage, error)
+
+	// RetrieveDomainJournals retrieves the journals of the domain with the
+	// given page. Only the domain administrators may retrieve them.
+	RetrieveDomainJournals(ctx context.Context, token, domainID string, page Page) (JournalsPage, error)
+
+	// CountConns returns the number of the things live connected to the
+	// domain and its channels, or to the channel of the domain if channelID
+	// is set. The domain administrators may count the connections of the
+	// domain, and the users who may view the channel those of the channel.
+	CountConns(ctx context.Context, token, domainID, channelID string) (ConnsCount, error)
 }
 
 // Repository provides access to the journal log database.
@@ -155,4 +168,28 @@ type Repository interface {
 
 	// RetrieveAll retrieves all journals from the database with the given page.
 	RetrieveAll(ctx context.Context, page Page) (JournalsPage, error)
+
+	// Purge removes the journals which occurred before the given time and
+	// returns the number of removed journals.
+	Purge(ctx context.Context, before time.Time) (uint64, error)
+
+	// SaveConn registers the live connection.
+	SaveConn(ctx context.Context, conn Conn) error
+
+	// RemoveConns removes the connections of the client of the instance, or
+	// all the connections of the instance if the client ID is empty.
+	RemoveConns(ctx context.Context, instance, clientID string) error
+
+	// SaveHeartbeat records that the instance was alive at the given time.
+	SaveHeartbeat(ctx context.Context, instance string, at time.Time) error
+
+	// PurgeConns removes the instances which were not alive since the given
+	// time with their connections, and returns the number of removed
+	// connections.
+	PurgeConns(ctx context.Context, before time.Time) (uint64, error)
+
+	// CountConns returns the number of the things connected to the domain,
+	// and to its channels or the channel if channelID is set, through the
+	// instances alive since the given time.
+	CountConns(ctx context.Context, domainID, channelID string, aliveAfter time.Time) (ConnsCount, error)
 }
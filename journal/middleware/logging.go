@@ -68,3 +68,47 @@ func (lm *loggingMiddleware) RetrieveAll(ctx context.Context, token string, page
 
 	return lm.service.RetrieveAll(ctx, token, page)
 }
+
+func (lm *loggingMiddleware) RetrieveDomainJournals(ctx context.Context, token, domainID string, page journal.Page) (journalsPage journal.JournalsPage, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("domain_id", domainID),
+			slog.Group("page",
+				slog.String("operation", page.Operation),
+				slog.String("actor", page.Actor),
+				slog.String("target", page.Target),
+				slog.Uint64("offset", page.Offset),
+				slog.Uint64("limit", page.Limit),
+				slog.Uint64("total", journalsPage.Total),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Retrieve domain journals failed", args...)
+			return
+		}
+		lm.logger.Info("Retrieve domain journals completed successfully", args...)
+	}(time.Now())
+
+	return lm.service.RetrieveDomainJournals(ctx, token, domainID, page)
+}
+
+func (lm *loggingMiddleware) CountConns(ctx context.Context, token, domainID, channelID string) (count journal.ConnsCount, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("domain_id", domainID),
+			slog.String("channel_id", channelID),
+			slog.Uint64("things", count.Things),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Count connections failed", args...)
+			return
+		}
+		lm.logger.Info("Count connections completed successfully", args...)
+	}(time.Now())
+
+	return lm.service.CountConns(ctx, token, domainID, channelID)
+}
@@ -46,3 +46,21 @@ func (mm *metricsMiddleware) RetrieveAll(ctx context.Context, token string, page
 
 	return mm.service.RetrieveAll(ctx, token, page)
 }
+
+func (mm *metricsMiddleware) RetrieveDomainJournals(ctx context.Context, token, domainID string, page journal.Page) (journal.JournalsPage, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "retrieve_domain_journals").Add(1)
+		mm.latency.With("method", "retrieve_domain_journals").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.service.RetrieveDomainJournals(ctx, token, domainID, page)
+}
+
+func (mm *metricsMiddleware) CountConns(ctx context.Context, token, domainID, channelID string) (journal.ConnsCount, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "count_conns").Add(1)
+		mm.latency.With("method", "count_conns").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.service.CountConns(ctx, token, domainID, channelID)
+}
@@ -44,3 +44,27 @@ func (tm *tracing) RetrieveAll(ctx context.Context, token string, page journal.P
 
 	return tm.svc.RetrieveAll(ctx, token, page)
 }
+
+func (tm *tracing) RetrieveDomainJournals(ctx context.Context, token, domainID string, page journal.Page) (resp journal.JournalsPage, err error) {
+	ctx, span := tm.tracer.Start(ctx, "retrieve_domain_journals", trace.WithAttributes(
+		attribute.String("domain_id", domainID),
+		attribute.Int64("offset", int64(page.Offset)),
+		attribute.Int64("limit", int64(page.Limit)),
+		attribute.String("operation", page.Operation),
+		attribute.String("actor", page.Actor),
+		attribute.String("target", page.Target),
+	))
+	defer span.End()
+
+	return tm.svc.RetrieveDomainJournals(ctx, token, domainID, page)
+}
+
+func (tm *tracing) CountConns(ctx context.Context, token, domainID, channelID string) (journal.ConnsCount, error) {
+	ctx, span := tm.tracer.Start(ctx, "count_conns", trace.WithAttributes(
+		attribute.String("domain_id", domainID),
+		attribute.String("channel_id", channelID),
+	))
+	defer span.End()
+
+	return tm.svc.CountConns(ctx, token, domainID, channelID)
+}
@@ -9,6 +9,8 @@ import (
 
 	journal "github.com/absmach/magistrala/journal"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -16,6 +18,108 @@ type Repository struct {
 	mock.Mock
 }
 
+// CountConns provides a mock function with given fields: ctx, domainID, channelID, aliveAfter
+func (_m *Repository) CountConns(ctx context.Context, domainID string, channelID string, aliveAfter time.Time) (journal.ConnsCount, error) {
+	ret := _m.Called(ctx, domainID, channelID, aliveAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountConns")
+	}
+
+	var r0 journal.ConnsCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (journal.ConnsCount, error)); ok {
+		return rf(ctx, domainID, channelID, aliveAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) journal.ConnsCount); ok {
+		r0 = rf(ctx, domainID, channelID, aliveAfter)
+	} else {
+		r0 = ret.Get(0).(journal.ConnsCount)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, domainID, channelID, aliveAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *Repository) Purge(ctx context.Context, before time.Time) (uint64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (uint64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) uint64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeConns provides a mock function with given fields: ctx, before
+func (_m *Repository) PurgeConns(ctx context.Context, before time.Time) (uint64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeConns")
+	}
+
+	var r0 uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (uint64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) uint64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveConns provides a mock function with given fields: ctx, instance, clientID
+func (_m *Repository) RemoveConns(ctx context.Context, instance string, clientID string) error {
+	ret := _m.Called(ctx, instance, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveConns")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, instance, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RetrieveAll provides a mock function with given fields: ctx, page
 func (_m *Repository) RetrieveAll(ctx context.Context, page journal.Page) (journal.JournalsPage, error) {
 	ret := _m.Called(ctx, page)
@@ -62,6 +166,42 @@ func (_m *Repository) Save(ctx context.Context, _a1 journal.Journal) error {
 	return r0
 }
 
+// SaveConn provides a mock function with given fields: ctx, conn
+func (_m *Repository) SaveConn(ctx context.Context, conn journal.Conn) error {
+	ret := _m.Called(ctx, conn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveConn")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, journal.Conn) error); ok {
+		r0 = rf(ctx, conn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveHeartbeat provides a mock function with given fields: ctx, instance, at
+func (_m *Repository) SaveHeartbeat(ctx context.Context, instance string, at time.Time) error {
+	ret := _m.Called(ctx, instance, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveHeartbeat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, instance, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
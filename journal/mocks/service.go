@@ -16,6 +16,34 @@ type Service struct {
 	mock.Mock
 }
 
+// CountConns provides a mock function with given fields: ctx, token, domainID, channelID
+func (_m *Service) CountConns(ctx context.Context, token string, domainID string, channelID string) (journal.ConnsCount, error) {
+	ret := _m.Called(ctx, token, domainID, channelID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountConns")
+	}
+
+	var r0 journal.ConnsCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (journal.ConnsCount, error)); ok {
+		return rf(ctx, token, domainID, channelID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) journal.ConnsCount); ok {
+		r0 = rf(ctx, token, domainID, channelID)
+	} else {
+		r0 = ret.Get(0).(journal.ConnsCount)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, token, domainID, channelID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RetrieveAll provides a mock function with given fields: ctx, token, page
 func (_m *Service) RetrieveAll(ctx context.Context, token string, page journal.Page) (journal.JournalsPage, error) {
 	ret := _m.Called(ctx, token, page)
@@ -44,6 +72,34 @@ func (_m *Service) RetrieveAll(ctx context.Context, token string, page journal.P
 	return r0, r1
 }
 
+// RetrieveDomainJournals provides a mock function with given fields: ctx, token, domainID, page
+func (_m *Service) RetrieveDomainJournals(ctx context.Context, token string, domainID string, page journal.Page) (journal.JournalsPage, error) {
+	ret := _m.Called(ctx, token, domainID, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveDomainJournals")
+	}
+
+	var r0 journal.JournalsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, journal.Page) (journal.JournalsPage, error)); ok {
+		return rf(ctx, token, domainID, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, journal.Page) journal.JournalsPage); ok {
+		r0 = rf(ctx, token, domainID, page)
+	} else {
+		r0 = ret.Get(0).(journal.JournalsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, journal.Page) error); ok {
+		r1 = rf(ctx, token, domainID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, _a1
 func (_m *Service) Save(ctx context.Context, _a1 journal.Journal) error {
 	ret := _m.Called(ctx, _a1)
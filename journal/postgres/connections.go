@@ -0,0 +1,118 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/absmach/magistrala/journal"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	"github.com/absmach/magistrala/pkg/postgres"
+)
+
+func (repo *repository) SaveConn(ctx context.Context, conn journal.Conn) error {
+	q := `INSERT INTO conns (instance, client_id, thing_id, domain_id, channel_id, protocol, connected_at)
+		VALUES (:instance, :client_id, :thing_id, :domain_id, :channel_id, :protocol, :connected_at)
+		ON CONFLICT (instance, client_id, channel_id) DO UPDATE SET
+			thing_id = EXCLUDED.thing_id, domain_id = EXCLUDED.domain_id,
+			protocol = EXCLUDED.protocol, connected_at = EXCLUDED.connected_at;`
+
+	if _, err := repo.db.NamedExecContext(ctx, q, conn); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo *repository) RemoveConns(ctx context.Context, instance, clientID string) error {
+	q := `DELETE FROM conns WHERE instance = $1 AND client_id = $2;`
+	args := []interface{}{instance, clientID}
+	if clientID == "" {
+		q = `DELETE FROM conns WHERE instance = $1;`
+		args = args[:1]
+	}
+
+	if _, err := repo.db.ExecContext(ctx, q, args...); err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+func (repo *repository) SaveHeartbeat(ctx context.Context, instance string, at time.Time) error {
+	q := `INSERT INTO conn_instances (instance, seen_at) VALUES ($1, $2)
+		ON CONFLICT (instance) DO UPDATE SET seen_at = GREATEST(conn_instances.seen_at, EXCLUDED.seen_at);`
+
+	if _, err := repo.db.ExecContext(ctx, q, instance, at); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo *repository) PurgeConns(ctx context.Context, before time.Time) (uint64, error) {
+	q := `WITH expired AS (DELETE FROM conn_instances WHERE seen_at < $1 RETURNING instance)
+		DELETE FROM conns WHERE instance IN (SELECT instance FROM expired);`
+
+	res, err := repo.db.ExecContext(ctx, q, before)
+	if err != nil {
+		return 0, postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(repoerr.ErrRemoveEntity, err)
+	}
+
+	return uint64(removed), nil
+}
+
+func (repo *repository) CountConns(ctx context.Context, domainID, channelID string, aliveAfter time.Time) (journal.ConnsCount, error) {
+	query := `FROM conns c JOIN conn_instances i ON i.instance = c.instance
+		WHERE c.domain_id = :domain_id AND i.seen_at >= :alive_after`
+	if channelID != "" {
+		query += ` AND c.channel_id = :channel_id`
+	}
+	params := connsQuery{
+		DomainID:   domainID,
+		ChannelID:  channelID,
+		AliveAfter: aliveAfter,
+	}
+
+	tq := fmt.Sprintf(`SELECT COUNT(DISTINCT c.thing_id) %s;`, query)
+	total, err := postgres.Total(ctx, repo.db, tq, params)
+	if err != nil {
+		return journal.ConnsCount{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+
+	q := fmt.Sprintf(`SELECT c.channel_id, COUNT(DISTINCT c.thing_id) AS things %s GROUP BY c.channel_id ORDER BY c.channel_id;`, query)
+	rows, err := repo.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return journal.ConnsCount{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var channels []journal.ChannelConns
+	for rows.Next() {
+		var cc journal.ChannelConns
+		if err := rows.StructScan(&cc); err != nil {
+			return journal.ConnsCount{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+		}
+		channels = append(channels, cc)
+	}
+
+	return journal.ConnsCount{
+		DomainID: domainID,
+		Things:   total,
+		Channels: channels,
+	}, nil
+}
+
+type connsQuery struct {
+	DomainID   string    `db:"domain_id"`
+	ChannelID  string    `db:"channel_id"`
+	AliveAfter time.Time `db:"alive_after"`
+}
@@ -0,0 +1,170 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/journal"
+	"github.com/absmach/magistrala/journal/postgres"
+	"github.com/absmach/magistrala/pkg/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const connsTTL = time.Minute
+
+func TestConnsCount(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM conns")
+		require.Nil(t, err, fmt.Sprintf("clean conns unexpected error: %s", err))
+		_, err = db.Exec("DELETE FROM conn_instances")
+		require.Nil(t, err, fmt.Sprintf("clean conn instances unexpected error: %s", err))
+		_, err = db.Exec("DELETE FROM journal")
+		require.Nil(t, err, fmt.Sprintf("clean journal unexpected error: %s", err))
+	})
+	repo := postgres.NewRepository(database)
+	svc := journal.NewService(nil, nil, uuid.New(), repo, connsTTL)
+
+	domainID := testsutil.GenerateUUID(t)
+	channel1 := testsutil.GenerateUUID(t)
+	channel2 := testsutil.GenerateUUID(t)
+	thing1 := testsutil.GenerateUUID(t)
+	thing2 := testsutil.GenerateUUID(t)
+	instance1 := testsutil.GenerateUUID(t)
+	instance2 := testsutil.GenerateUUID(t)
+
+	event := func(operation, instance string, at time.Time, attrs ...string) journal.Journal {
+		j := journal.Journal{
+			Operation:  operation,
+			OccurredAt: at,
+			Attributes: map[string]interface{}{
+				"instance": instance,
+				"protocol": "mqtt",
+			},
+		}
+		for i := 0; i+1 < len(attrs); i += 2 {
+			j.Attributes[attrs[i]] = attrs[i+1]
+		}
+
+		return j
+	}
+	attach := func(instance, clientID, thingID, channelID string) journal.Journal {
+		return event(journal.ConnChannel, instance, time.Now(), "client_id", clientID, "thing_id", thingID, "domain_id", domainID, "channel_id", channelID)
+	}
+
+	cases := []struct {
+		desc   string
+		stale  string
+		events []journal.Journal
+		count  journal.ConnsCount
+	}{
+		{
+			desc:  "count without connections",
+			count: journal.ConnsCount{DomainID: domainID},
+		},
+		{
+			desc: "count after connections are opened",
+			events: []journal.Journal{
+				attach(instance1, "client1", thing1, channel1),
+				attach(instance1, "client1", thing1, channel2),
+				attach(instance1, "client2", thing2, channel1),
+				attach(instance2, "client3", thing1, channel1),
+			},
+			count: journal.ConnsCount{
+				DomainID: domainID,
+				Things:   2,
+				Channels: []journal.ChannelConns{
+					{ChannelID: channel1, Things: 2},
+					{ChannelID: channel2, Things: 1},
+				},
+			},
+		},
+		{
+			desc: "count after connection is closed",
+			events: []journal.Journal{
+				event(journal.ConnClose, instance1, time.Now(), "client_id", "client1"),
+			},
+			count: journal.ConnsCount{
+				DomainID: domainID,
+				Things:   2,
+				Channels: []journal.ChannelConns{{ChannelID: channel1, Things: 2}},
+			},
+		},
+		{
+			desc: "count after client reconnects",
+			events: []journal.Journal{
+				attach(instance1, "client2", thing2, channel2),
+				event(journal.ConnOpen, instance1, time.Now(), "client_id", "client2"),
+			},
+			count: journal.ConnsCount{
+				DomainID: domainID,
+				Things:   1,
+				Channels: []journal.ChannelConns{{ChannelID: channel1, Things: 1}},
+			},
+		},
+		{
+			desc: "count after instance restarts",
+			events: []journal.Journal{
+				event(journal.ConnReset, instance1, time.Now()),
+			},
+			count: journal.ConnsCount{
+				DomainID: domainID,
+				Things:   1,
+				Channels: []journal.ChannelConns{{ChannelID: channel1, Things: 1}},
+			},
+		},
+		{
+			desc:  "count after instance expires",
+			stale: instance2,
+			events: []journal.Journal{
+				attach(instance1, "client1", thing2, channel2),
+			},
+			count: journal.ConnsCount{
+				DomainID: domainID,
+				Things:   1,
+				Channels: []journal.ChannelConns{{ChannelID: channel2, Things: 1}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.stale != "" {
+				_, err := db.Exec("UPDATE conn_instances SET seen_at = $1 WHERE instance = $2", time.Now().Add(-2*connsTTL), tc.stale)
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			}
+			for _, e := range tc.events {
+				err := svc.Save(context.Background(), e)
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			}
+			count, err := repo.CountConns(context.Background(), domainID, "", time.Now().Add(-connsTTL))
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.count, count, tc.desc)
+		})
+	}
+
+	t.Run("count connections of channel", func(t *testing.T) {
+		count, err := repo.CountConns(context.Background(), domainID, channel2, time.Now().Add(-connsTTL))
+		assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+		assert.Equal(t, journal.ConnsCount{
+			DomainID: domainID,
+			Things:   1,
+			Channels: []journal.ChannelConns{{ChannelID: channel2, Things: 1}},
+		}, count)
+	})
+
+	t.Run("purge expired connections", func(t *testing.T) {
+		err := svc.Save(context.Background(), event(journal.ConnHeartbeat, instance1, time.Now()))
+		require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+		var conns int
+		err = db.Get(&conns, "SELECT COUNT(*) FROM conns WHERE instance = $1", instance2)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+		assert.Equal(t, 0, conns, "expected the connections of the expired instance to be purged")
+	})
+}
@@ -31,6 +31,41 @@ func Migration() *migrate.MemoryMigrationSource {
 					`DROP TABLE IF EXISTS journal`,
 				},
 			},
+			{
+				Id: "journal_02",
+				Up: []string{
+					`CREATE INDEX idx_journal_occurred_at ON journal(occurred_at);`,
+					`CREATE INDEX idx_journal_domain_filter ON journal((attributes->>'domain'), (attributes->>'domain_id'), occurred_at DESC);`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS idx_journal_domain_filter`,
+					`DROP INDEX IF EXISTS idx_journal_occurred_at`,
+				},
+			},
+			{
+				Id: "journal_03",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS conn_instances (
+						instance	VARCHAR PRIMARY KEY,
+						seen_at		TIMESTAMP NOT NULL
+					)`,
+					`CREATE TABLE IF NOT EXISTS conns (
+						instance		VARCHAR NOT NULL,
+						client_id		VARCHAR NOT NULL,
+						thing_id		VARCHAR(36) NOT NULL,
+						domain_id		VARCHAR(36) NOT NULL,
+						channel_id		VARCHAR(36) NOT NULL,
+						protocol		VARCHAR NOT NULL DEFAULT '',
+						connected_at	TIMESTAMP NOT NULL,
+						PRIMARY KEY (instance, client_id, channel_id)
+					)`,
+					`CREATE INDEX idx_conns_domain_channel ON conns(domain_id, channel_id);`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS conns`,
+					`DROP TABLE IF EXISTS conn_instances`,
+				},
+			},
 		},
 	}
 }
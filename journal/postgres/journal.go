@@ -91,6 +91,21 @@ func (repo *repository) RetrieveAll(ctx context.Context, page journal.Page) (jou
 	return journalsPage, nil
 }
 
+func (repo *repository) Purge(ctx context.Context, before time.Time) (uint64, error) {
+	q := `DELETE FROM journal WHERE occurred_at < $1;`
+
+	res, err := repo.db.ExecContext(ctx, q, before)
+	if err != nil {
+		return 0, postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(repoerr.ErrRemoveEntity, err)
+	}
+
+	return uint64(removed), nil
+}
+
 func pageQuery(pm journal.Page) string {
 	var query []string
 	var emq string
@@ -106,6 +121,16 @@ func pageQuery(pm journal.Page) string {
 	if pm.EntityID != "" {
 		query = append(query, pm.EntityType.Query())
 	}
+	if pm.Domain != "" {
+		query = append(query, "(attributes->>'domain' = :domain OR attributes->>'domain_id' = :domain)")
+	}
+	if pm.Actor != "" {
+		query = append(query, "(attributes->>'updated_by' = :actor OR attributes->>'created_by' = :actor)")
+	}
+	if pm.Target != "" {
+		query = append(query, "(attributes->>'id' = :target OR attributes->>'user_id' = :target OR attributes->>'group_id' = :target "+
+			"OR attributes->>'thing_id' = :target OR attributes->>'channel_id' = :target)")
+	}
 
 	if len(query) > 0 {
 		emq = fmt.Sprintf("WHERE %s", strings.Join(query, " AND "))
@@ -722,3 +722,129 @@ func extractEntities(journals []journal.Journal, entityType journal.EntityType,
 
 	return entities
 }
+
+func TestJournalRetrieveAllByActor(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM journal")
+		require.Nil(t, err, fmt.Sprintf("clean journal unexpected error: %s", err))
+	})
+	repo := postgres.NewRepository(database)
+
+	domainID := testsutil.GenerateUUID(t)
+	actorID := testsutil.GenerateUUID(t)
+	thingID := testsutil.GenerateUUID(t)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	journals := []journal.Journal{
+		{
+			Operation:  "thing.create",
+			OccurredAt: now.Add(-4 * time.Minute),
+			Attributes: map[string]interface{}{"id": thingID, "domain": domainID, "created_by": actorID},
+		},
+		{
+			Operation:  "thing.update",
+			OccurredAt: now.Add(-3 * time.Minute),
+			Attributes: map[string]interface{}{"id": thingID, "domain": domainID, "updated_by": actorID},
+		},
+		{
+			Operation:  "thing.update",
+			OccurredAt: now.Add(-2 * time.Minute),
+			Attributes: map[string]interface{}{"id": testsutil.GenerateUUID(t), "domain": domainID, "updated_by": testsutil.GenerateUUID(t)},
+		},
+		{
+			Operation:  "thing.update",
+			OccurredAt: now.Add(-time.Minute),
+			Attributes: map[string]interface{}{"id": thingID, "domain": testsutil.GenerateUUID(t), "updated_by": actorID},
+		},
+	}
+	for i, j := range journals {
+		j.ID = testsutil.GenerateUUID(t)
+		err := repo.Save(context.Background(), j)
+		require.Nil(t, err, fmt.Sprintf("create journal unexpected error: %s", err))
+		journals[i].Attributes = nil
+	}
+
+	cases := []struct {
+		desc     string
+		page     journal.Page
+		journals []journal.Journal
+	}{
+		{
+			desc:     "by domain",
+			page:     journal.Page{Limit: 10, Domain: domainID},
+			journals: journals[:3],
+		},
+		{
+			desc:     "by domain and actor",
+			page:     journal.Page{Limit: 10, Domain: domainID, Actor: actorID},
+			journals: journals[:2],
+		},
+		{
+			desc:     "by domain, actor and operation",
+			page:     journal.Page{Limit: 10, Domain: domainID, Actor: actorID, Operation: "thing.update"},
+			journals: journals[1:2],
+		},
+		{
+			desc:     "by domain and target",
+			page:     journal.Page{Limit: 10, Domain: domainID, Target: thingID},
+			journals: journals[:2],
+		},
+		{
+			desc:     "by domain and time range",
+			page:     journal.Page{Limit: 10, Domain: domainID, From: now.Add(-3 * time.Minute), To: now.Add(-2 * time.Minute)},
+			journals: journals[1:3],
+		},
+		{
+			desc:     "by domain with pagination",
+			page:     journal.Page{Offset: 1, Limit: 1, Domain: domainID},
+			journals: journals[1:2],
+		},
+		{
+			desc: "by unknown actor",
+			page: journal.Page{Limit: 10, Domain: domainID, Actor: testsutil.GenerateUUID(t)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			page, err := repo.RetrieveAll(context.Background(), tc.page)
+			require.Nil(t, err, fmt.Sprintf("retrieve journals unexpected error: %s", err))
+			assert.Equal(t, tc.journals, page.Journals, tc.desc)
+		})
+	}
+}
+
+func TestJournalPurge(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM journal")
+		require.Nil(t, err, fmt.Sprintf("clean journal unexpected error: %s", err))
+	})
+	repo := postgres.NewRepository(database)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for i := 0; i < 10; i++ {
+		j := journal.Journal{
+			ID:         testsutil.GenerateUUID(t),
+			Operation:  operation,
+			OccurredAt: now.Add(-time.Duration(i) * 24 * time.Hour),
+			Attributes: map[string]interface{}{"id": testsutil.GenerateUUID(t)},
+		}
+		err := repo.Save(context.Background(), j)
+		require.Nil(t, err, fmt.Sprintf("create journal unexpected error: %s", err))
+	}
+
+	removed, err := repo.Purge(context.Background(), now.Add(-4*24*time.Hour-time.Hour))
+	require.Nil(t, err, fmt.Sprintf("purge journals unexpected error: %s", err))
+	assert.Equal(t, uint64(5), removed, "purge journals: unexpected number of removed journals")
+
+	page, err := repo.RetrieveAll(context.Background(), journal.Page{Limit: 10})
+	require.Nil(t, err, fmt.Sprintf("retrieve journals unexpected error: %s", err))
+	assert.Equal(t, uint64(5), page.Total, "purge journals: unexpected number of remaining journals")
+	for _, j := range page.Journals {
+		assert.False(t, j.OccurredAt.Before(now.Add(-4*24*time.Hour)), "purge journals: expired journal retained")
+	}
+
+	removed, err = repo.Purge(context.Background(), now.Add(-4*24*time.Hour-time.Hour))
+	require.Nil(t, err, fmt.Sprintf("purge journals unexpected error: %s", err))
+	assert.Equal(t, uint64(0), removed, "purge journals: expired journals already removed")
+}
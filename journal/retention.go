@@ -0,0 +1,74 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package journal
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+var errPurge = errors.New("failed to purge expired journals")
+
+// RetentionConfig contains the journal retention parameters. Zero retention
+// keeps the journals forever.
+type RetentionConfig struct {
+	Retention time.Duration `env:"RETENTION"          envDefault:"0s"`
+	Interval  time.Duration `env:"RETENTION_INTERVAL" envDefault:"1h"`
+}
+
+// Purger periodically removes the journals older than the retention period.
+type Purger struct {
+	repo   Repository
+	cfg    RetentionConfig
+	logger *slog.Logger
+}
+
+// NewPurger returns the purger of the expired journals.
+func NewPurger(repo Repository, cfg RetentionConfig, logger *slog.Logger) *Purger {
+	return &Purger{
+		repo:   repo,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run purges the expired journals periodically until the context is canceled.
+// It returns immediately if the retention is disabled.
+func (p *Purger) Run(ctx context.Context) {
+	if p.cfg.Retention <= 0 || p.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := p.Purge(ctx)
+			if err != nil {
+				p.logger.Error("failed to purge expired journals", slog.Any("error", err))
+				continue
+			}
+			p.logger.Info("expired journals purged", slog.Uint64("removed", removed))
+		}
+	}
+}
+
+// Purge removes the journals expired at the time of the call.
+func (p *Purger) Purge(ctx context.Context) (uint64, error) {
+	if p.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	removed, err := p.repo.Purge(ctx, time.Now().Add(-p.cfg.Retention))
+	if err != nil {
+		return removed, errors.Wrap(errPurge, err)
+	}
+
+	return removed, nil
+}
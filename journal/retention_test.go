@@ -0,0 +1,69 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package journal_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/journal"
+	"github.com/absmach/magistrala/journal/mocks"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPurge(t *testing.T) {
+	cases := []struct {
+		desc      string
+		retention time.Duration
+		removed   uint64
+		repoErr   error
+		err       error
+		purged    bool
+	}{
+		{
+			desc:      "purge expired journals",
+			retention: 24 * time.Hour,
+			removed:   3,
+			purged:    true,
+		},
+		{
+			desc:      "purge with disabled retention",
+			retention: 0,
+		},
+		{
+			desc:      "purge with repo error",
+			retention: 24 * time.Hour,
+			repoErr:   repoerr.ErrRemoveEntity,
+			err:       repoerr.ErrRemoveEntity,
+			purged:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			purger := journal.NewPurger(repo, journal.RetentionConfig{Retention: tc.retention, Interval: time.Hour}, mglog.NewMock())
+
+			start := time.Now()
+			repoCall := repo.On("Purge", context.Background(), mock.MatchedBy(func(before time.Time) bool {
+				cutoff := start.Add(-tc.retention)
+				return !before.Before(cutoff) && before.Before(cutoff.Add(time.Minute))
+			})).Return(tc.removed, tc.repoErr)
+			removed, err := purger.Purge(context.Background())
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.removed, removed, fmt.Sprintf("%s: expected %d removed journals got %d\n", tc.desc, tc.removed, removed))
+			if tc.purged {
+				repoCall.Parent.AssertCalled(t, "Purge", context.Background(), mock.Anything)
+			} else {
+				repoCall.Parent.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
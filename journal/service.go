@@ -5,8 +5,10 @@ package journal
 
 import (
 	"context"
+	"time"
 
 	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/auth"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
 	mgauthz "github.com/absmach/magistrala/pkg/authz"
 	"github.com/absmach/magistrala/pkg/policies"
@@ -17,18 +19,33 @@ type service struct {
 	authz      mgauthz.Authorization
 	idProvider magistrala.IDProvider
 	repository Repository
+	connsTTL   time.Duration
 }
 
-func NewService(authn mgauthn.Authentication, authz mgauthz.Authorization, idp magistrala.IDProvider, repository Repository) Service {
+// NewService returns the journal service. The connections of the protocol
+// adapter instances which didn't report they are alive within connsTTL are
+// not counted.
+func NewService(authn mgauthn.Authentication, authz mgauthz.Authorization, idp magistrala.IDProvider, repository Repository, connsTTL time.Duration) Service {
 	return &service{
 		idProvider: idp,
 		authn:      authn,
 		authz:      authz,
 		repository: repository,
+		connsTTL:   connsTTL,
 	}
 }
 
 func (svc *service) Save(ctx context.Context, journal Journal) error {
+	if isConnEvent(journal.Operation) {
+		if err := svc.register(ctx, journal); err != nil {
+			return err
+		}
+		// The heartbeats only keep the connections alive.
+		if journal.Operation == ConnHeartbeat {
+			return nil
+		}
+	}
+
 	id, err := svc.idProvider.ID()
 	if err != nil {
 		return err
@@ -46,6 +63,58 @@ func (svc *service) RetrieveAll(ctx context.Context, token string, page Page) (J
 	return svc.repository.RetrieveAll(ctx, page)
 }
 
+func (svc *service) RetrieveDomainJournals(ctx context.Context, token, domainID string, page Page) (JournalsPage, error) {
+	session, err := svc.authn.Authenticate(ctx, token)
+	if err != nil {
+		return JournalsPage{}, err
+	}
+
+	req := mgauthz.PolicyReq{
+		Domain:      domainID,
+		SubjectType: policies.UserType,
+		SubjectKind: policies.UsersKind,
+		Subject:     auth.EncodeDomainUserID(domainID, session.UserID),
+		Permission:  policies.AdminPermission,
+		ObjectType:  policies.DomainType,
+		Object:      domainID,
+	}
+	if err := svc.authz.Authorize(ctx, req); err != nil {
+		return JournalsPage{}, err
+	}
+	page.Domain = domainID
+
+	return svc.repository.RetrieveAll(ctx, page)
+}
+
+func (svc *service) CountConns(ctx context.Context, token, domainID, channelID string) (ConnsCount, error) {
+	session, err := svc.authn.Authenticate(ctx, token)
+	if err != nil {
+		return ConnsCount{}, err
+	}
+
+	// The domain administrators count the connections of the whole domain,
+	// and the members the connections of the channels they may view.
+	req := mgauthz.PolicyReq{
+		Domain:      domainID,
+		SubjectType: policies.UserType,
+		SubjectKind: policies.UsersKind,
+		Subject:     auth.EncodeDomainUserID(domainID, session.UserID),
+		Permission:  policies.AdminPermission,
+		ObjectType:  policies.DomainType,
+		Object:      domainID,
+	}
+	if channelID != "" {
+		req.Permission = policies.ViewPermission
+		req.ObjectType = policies.GroupType
+		req.Object = channelID
+	}
+	if err := svc.authz.Authorize(ctx, req); err != nil {
+		return ConnsCount{}, err
+	}
+
+	return svc.repository.CountConns(ctx, domainID, channelID, time.Now().Add(-svc.connsTTL))
+}
+
 func (svc *service) authorize(ctx context.Context, token, entityID, entityType string) error {
 	session, err := svc.authn.Authenticate(ctx, token)
 	if err != nil {
@@ -39,13 +39,14 @@ var (
 		},
 	}
 	idProvider = uuid.New()
+	connsTTL   = time.Minute
 )
 
 func TestSave(t *testing.T) {
 	repo := new(mocks.Repository)
 	authn := new(authnmocks.Authentication)
 	authz := new(authzmocks.Authorization)
-	svc := journal.NewService(authn, authz, idProvider, repo)
+	svc := journal.NewService(authn, authz, idProvider, repo, connsTTL)
 
 	cases := []struct {
 		desc    string
@@ -80,7 +81,7 @@ func TestReadAll(t *testing.T) {
 	repo := new(mocks.Repository)
 	authn := new(authnmocks.Authentication)
 	authz := new(authzmocks.Authorization)
-	svc := journal.NewService(authn, authz, idProvider, repo)
+	svc := journal.NewService(authn, authz, idProvider, repo, connsTTL)
 
 	validToken := "token"
 	validPage := journal.Page{
@@ -206,3 +207,358 @@ func TestReadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieveDomainJournals(t *testing.T) {
+	validToken := "token"
+	domainID := testsutil.GenerateUUID(t)
+	userID := testsutil.GenerateUUID(t)
+	validPage := journal.Page{
+		Offset:    0,
+		Limit:     10,
+		Operation: "thing.update",
+		Actor:     userID,
+	}
+
+	cases := []struct {
+		desc        string
+		token       string
+		domainID    string
+		page        journal.Page
+		resp        journal.JournalsPage
+		identifyRes mgauthn.Session
+		identifyErr error
+		authErr     error
+		repoErr     error
+		err         error
+	}{
+		{
+			desc:     "successful",
+			token:    validToken,
+			domainID: domainID,
+			page:     validPage,
+			resp: journal.JournalsPage{
+				Total:    1,
+				Offset:   0,
+				Limit:    10,
+				Journals: []journal.Journal{validJournal},
+			},
+			identifyRes: mgauthn.Session{UserID: userID},
+		},
+		{
+			desc:        "with identify error",
+			token:       validToken,
+			domainID:    domainID,
+			page:        validPage,
+			identifyErr: svcerr.ErrAuthentication,
+			err:         svcerr.ErrAuthentication,
+		},
+		{
+			desc:        "with failed to authorize",
+			token:       validToken,
+			domainID:    domainID,
+			page:        validPage,
+			identifyRes: mgauthn.Session{UserID: userID},
+			authErr:     svcerr.ErrAuthorization,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:        "with repo error",
+			token:       validToken,
+			domainID:    domainID,
+			page:        validPage,
+			identifyRes: mgauthn.Session{UserID: userID},
+			repoErr:     repoerr.ErrViewEntity,
+			err:         repoerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			authn := new(authnmocks.Authentication)
+			authz := new(authzmocks.Authorization)
+			svc := journal.NewService(authn, authz, idProvider, repo, connsTTL)
+
+			authReq := mgauthz.PolicyReq{
+				Domain:      tc.domainID,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Subject:     tc.domainID + "_" + tc.identifyRes.UserID,
+				Permission:  policies.AdminPermission,
+				ObjectType:  policies.DomainType,
+				Object:      tc.domainID,
+			}
+			repoPage := tc.page
+			repoPage.Domain = tc.domainID
+			authn.On("Authenticate", context.Background(), tc.token).Return(tc.identifyRes, tc.identifyErr)
+			authz.On("Authorize", context.Background(), authReq).Return(tc.authErr)
+			repoCall := repo.On("RetrieveAll", context.Background(), repoPage).Return(tc.resp, tc.repoErr)
+			resp, err := svc.RetrieveDomainJournals(context.Background(), tc.token, tc.domainID, tc.page)
+			if tc.err == nil {
+				assert.Equal(t, tc.resp, resp, tc.desc)
+				ok := repoCall.Parent.AssertCalled(t, "RetrieveAll", context.Background(), repoPage)
+				assert.True(t, ok, fmt.Sprintf("%s: journals are not scoped to the domain", tc.desc))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		})
+	}
+}
+
+func TestSaveConnEvents(t *testing.T) {
+	instance := testsutil.GenerateUUID(t)
+	clientID := testsutil.GenerateUUID(t)
+	thingID := testsutil.GenerateUUID(t)
+	domainID := testsutil.GenerateUUID(t)
+	channelID := testsutil.GenerateUUID(t)
+	occurredAt := time.Now()
+
+	newEvent := func(operation string, attrs map[string]interface{}) journal.Journal {
+		return journal.Journal{
+			Operation:  operation,
+			OccurredAt: occurredAt,
+			Attributes: attrs,
+		}
+	}
+
+	cases := []struct {
+		desc     string
+		journal  journal.Journal
+		conn     journal.Conn
+		clientID string
+		remove   bool
+		purge    bool
+		repoErr  error
+		err      error
+	}{
+		{
+			desc: "save open connection event",
+			journal: newEvent(journal.ConnOpen, map[string]interface{}{
+				"instance":  instance,
+				"protocol":  "mqtt",
+				"client_id": clientID,
+			}),
+			clientID: clientID,
+			remove:   true,
+		},
+		{
+			desc: "save channel connection event",
+			journal: newEvent(journal.ConnChannel, map[string]interface{}{
+				"instance":   instance,
+				"protocol":   "mqtt",
+				"client_id":  clientID,
+				"thing_id":   thingID,
+				"domain_id":  domainID,
+				"channel_id": channelID,
+			}),
+			conn: journal.Conn{
+				Instance:    instance,
+				ClientID:    clientID,
+				ThingID:     thingID,
+				DomainID:    domainID,
+				ChannelID:   channelID,
+				Protocol:    "mqtt",
+				ConnectedAt: occurredAt,
+			},
+		},
+		{
+			desc: "save close connection event",
+			journal: newEvent(journal.ConnClose, map[string]interface{}{
+				"instance":  instance,
+				"client_id": clientID,
+			}),
+			clientID: clientID,
+			remove:   true,
+		},
+		{
+			desc:    "save reset connection event",
+			journal: newEvent(journal.ConnReset, map[string]interface{}{"instance": instance}),
+			remove:  true,
+		},
+		{
+			desc:    "save heartbeat event",
+			journal: newEvent(journal.ConnHeartbeat, map[string]interface{}{"instance": instance}),
+			purge:   true,
+		},
+		{
+			desc:    "save heartbeat event with repo error",
+			journal: newEvent(journal.ConnHeartbeat, map[string]interface{}{"instance": instance}),
+			repoErr: repoerr.ErrCreateEntity,
+			err:     repoerr.ErrCreateEntity,
+		},
+		{
+			desc:    "save connection event without instance",
+			journal: newEvent(journal.ConnHeartbeat, map[string]interface{}{}),
+			err:     errors.New("malformed connection event"),
+		},
+		{
+			desc:    "save open connection event without client",
+			journal: newEvent(journal.ConnOpen, map[string]interface{}{"instance": instance}),
+			err:     errors.New("malformed connection event"),
+		},
+		{
+			desc: "save channel connection event without channel",
+			journal: newEvent(journal.ConnChannel, map[string]interface{}{
+				"instance":  instance,
+				"client_id": clientID,
+				"thing_id":  thingID,
+				"domain_id": domainID,
+			}),
+			err: errors.New("malformed connection event"),
+		},
+		{
+			desc:    "save close connection event without client",
+			journal: newEvent(journal.ConnClose, map[string]interface{}{"instance": instance}),
+			err:     errors.New("malformed connection event"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			svc := journal.NewService(nil, nil, idProvider, repo, connsTTL)
+			repo.On("SaveConn", context.Background(), tc.conn).Return(nil)
+			repo.On("RemoveConns", context.Background(), instance, tc.clientID).Return(nil)
+			repo.On("SaveHeartbeat", context.Background(), instance, occurredAt).Return(tc.repoErr)
+			repo.On("PurgeConns", context.Background(), occurredAt.Add(-connsTTL)).Return(uint64(0), nil)
+			repo.On("Save", context.Background(), mock.Anything).Return(nil)
+
+			err := svc.Save(context.Background(), tc.journal)
+			assert.Equal(t, tc.err == nil, err == nil, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.err, err))
+			if tc.err != nil {
+				assert.Contains(t, err.Error(), tc.err.Error(), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+				return
+			}
+			switch tc.conn.Instance {
+			case "":
+				repo.AssertNotCalled(t, "SaveConn", mock.Anything, mock.Anything)
+			default:
+				repo.AssertCalled(t, "SaveConn", context.Background(), tc.conn)
+			}
+			switch tc.remove {
+			case true:
+				repo.AssertCalled(t, "RemoveConns", context.Background(), instance, tc.clientID)
+			default:
+				repo.AssertNotCalled(t, "RemoveConns", mock.Anything, mock.Anything, mock.Anything)
+			}
+			switch tc.purge {
+			case true:
+				repo.AssertCalled(t, "PurgeConns", context.Background(), occurredAt.Add(-connsTTL))
+				repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			default:
+				repo.AssertNotCalled(t, "PurgeConns", mock.Anything, mock.Anything)
+				repo.AssertCalled(t, "Save", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCountConns(t *testing.T) {
+	domainID := testsutil.GenerateUUID(t)
+	channelID := testsutil.GenerateUUID(t)
+	userID := testsutil.GenerateUUID(t)
+	validToken := "token"
+	count := journal.ConnsCount{
+		DomainID: domainID,
+		Things:   1,
+		Channels: []journal.ChannelConns{{ChannelID: channelID, Things: 1}},
+	}
+
+	cases := []struct {
+		desc        string
+		token       string
+		channelID   string
+		authReq     mgauthz.PolicyReq
+		identifyErr error
+		authErr     error
+		repoErr     error
+		resp        journal.ConnsCount
+		err         error
+	}{
+		{
+			desc:  "count connections of domain",
+			token: validToken,
+			authReq: mgauthz.PolicyReq{
+				Domain:      domainID,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Subject:     domainID + "_" + userID,
+				Permission:  policies.AdminPermission,
+				ObjectType:  policies.DomainType,
+				Object:      domainID,
+			},
+			resp: count,
+		},
+		{
+			desc:      "count connections of channel",
+			token:     validToken,
+			channelID: channelID,
+			authReq: mgauthz.PolicyReq{
+				Domain:      domainID,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Subject:     domainID + "_" + userID,
+				Permission:  policies.ViewPermission,
+				ObjectType:  policies.GroupType,
+				Object:      channelID,
+			},
+			resp: count,
+		},
+		{
+			desc:        "count connections with invalid token",
+			token:       "invalid",
+			identifyErr: svcerr.ErrAuthentication,
+			err:         svcerr.ErrAuthentication,
+		},
+		{
+			desc:  "count connections of domain without admin permission",
+			token: validToken,
+			authReq: mgauthz.PolicyReq{
+				Domain:      domainID,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Subject:     domainID + "_" + userID,
+				Permission:  policies.AdminPermission,
+				ObjectType:  policies.DomainType,
+				Object:      domainID,
+			},
+			authErr: svcerr.ErrAuthorization,
+			err:     svcerr.ErrAuthorization,
+		},
+		{
+			desc:  "count connections with repo error",
+			token: validToken,
+			authReq: mgauthz.PolicyReq{
+				Domain:      domainID,
+				SubjectType: policies.UserType,
+				SubjectKind: policies.UsersKind,
+				Subject:     domainID + "_" + userID,
+				Permission:  policies.AdminPermission,
+				ObjectType:  policies.DomainType,
+				Object:      domainID,
+			},
+			repoErr: repoerr.ErrViewEntity,
+			err:     repoerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repo := new(mocks.Repository)
+			authn := new(authnmocks.Authentication)
+			authz := new(authzmocks.Authorization)
+			svc := journal.NewService(authn, authz, idProvider, repo, connsTTL)
+
+			authn.On("Authenticate", context.Background(), tc.token).Return(mgauthn.Session{UserID: userID}, tc.identifyErr)
+			authz.On("Authorize", context.Background(), tc.authReq).Return(tc.authErr)
+			// Only the connections of the instances alive within the TTL are counted.
+			aliveAfter := mock.MatchedBy(func(t time.Time) bool {
+				return time.Since(t) >= connsTTL && time.Since(t) < connsTTL+time.Minute
+			})
+			repo.On("CountConns", context.Background(), domainID, tc.channelID, aliveAfter).Return(tc.resp, tc.repoErr)
+
+			resp, err := svc.CountConns(context.Background(), tc.token, domainID, tc.channelID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.resp, resp, tc.desc)
+		})
+	}
+}
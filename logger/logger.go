@@ -10,16 +10,34 @@ import (
 	"time"
 )
 
-// New returns wrapped slog logger.
-func New(w io.Writer, levelText string) (*slog.Logger, error) {
+const (
+	// JSONFormat renders each log line as a JSON object.
+	JSONFormat = "json"
+	// LogfmtFormat renders each log line as space separated key=value pairs.
+	LogfmtFormat = "logfmt"
+)
+
+// New returns wrapped slog logger writing in the given format. Empty format
+// defaults to JSON.
+func New(w io.Writer, levelText, format string) (*slog.Logger, error) {
 	var level slog.Level
 	if err := level.UnmarshalText([]byte(levelText)); err != nil {
 		return &slog.Logger{}, fmt.Errorf(`{"level":"error","message":"%s: %s","ts":"%s"}`, err, levelText, time.RFC3339Nano)
 	}
 
-	logHandler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+	opts := &slog.HandlerOptions{
 		Level: level,
-	})
+	}
+
+	var logHandler slog.Handler
+	switch format {
+	case JSONFormat, "":
+		logHandler = slog.NewJSONHandler(w, opts)
+	case LogfmtFormat:
+		logHandler = slog.NewTextHandler(w, opts)
+	default:
+		return &slog.Logger{}, fmt.Errorf(`{"level":"error","message":"invalid log format: %s","ts":"%s"}`, format, time.RFC3339Nano)
+	}
 
-	return slog.New(logHandler), nil
+	return slog.New(contextHandler{logHandler}), nil
 }
@@ -4,10 +4,16 @@
 package logger_test
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"regexp"
 	"testing"
+	"time"
 
 	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/requestid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,7 +56,7 @@ func TestLoggerInitialization(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			writer := &mockWriter{}
-			logger, err := mglog.New(writer, tc.level)
+			logger, err := mglog.New(writer, tc.level, mglog.JSONFormat)
 			if tc.level == "invalid" {
 				assert.NotNil(t, err, "expected error during logger initialization")
 				assert.NotNil(t, logger, "logger should not be nil when an error occurs")
@@ -61,3 +67,108 @@ func TestLoggerInitialization(t *testing.T) {
 		})
 	}
 }
+
+func TestLoggerFormat(t *testing.T) {
+	cases := []struct {
+		desc   string
+		format string
+		err    bool
+	}{
+		{
+			desc:   "default format",
+			format: "",
+		},
+		{
+			desc:   "json format",
+			format: mglog.JSONFormat,
+		},
+		{
+			desc:   "logfmt format",
+			format: mglog.LogfmtFormat,
+		},
+		{
+			desc:   "invalid format",
+			format: "xml",
+			err:    true,
+		},
+	}
+
+	logfmtLine := regexp.MustCompile(`^time=(\S+) level=WARN msg="disk almost full" service=test path="/var/lib/data dir" usage=0\.93\n$`)
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			writer := &mockWriter{}
+			logger, err := mglog.New(writer, slog.LevelInfo.String(), tc.format)
+			if tc.err {
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected error during logger initialization", tc.desc))
+				return
+			}
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error during logger initialization: %s", tc.desc, err))
+
+			logger.With(slog.String("service", "test")).Warn("disk almost full", slog.String("path", "/var/lib/data dir"), slog.Float64("usage", 0.93))
+
+			var ts string
+			switch tc.format {
+			case mglog.LogfmtFormat:
+				match := logfmtLine.FindStringSubmatch(string(writer.value))
+				assert.NotNil(t, match, fmt.Sprintf("%s: unexpected log line %q", tc.desc, writer.value))
+				if match == nil {
+					return
+				}
+				ts = match[1]
+			default:
+				entry := map[string]any{}
+				err = json.Unmarshal(writer.value, &entry)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error parsing log entry: %s", tc.desc, err))
+				assert.Equal(t, "WARN", entry["level"], fmt.Sprintf("%s: unexpected level", tc.desc))
+				assert.Equal(t, "disk almost full", entry["msg"], fmt.Sprintf("%s: unexpected message", tc.desc))
+				assert.Equal(t, "test", entry["service"], fmt.Sprintf("%s: unexpected logger attribute", tc.desc))
+				assert.Equal(t, "/var/lib/data dir", entry["path"], fmt.Sprintf("%s: unexpected string attribute", tc.desc))
+				assert.Equal(t, 0.93, entry["usage"], fmt.Sprintf("%s: unexpected float attribute", tc.desc))
+				ts, _ = entry["time"].(string)
+			}
+			_, err = time.Parse(time.RFC3339Nano, ts)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error parsing log timestamp %q: %s", tc.desc, ts, err))
+		})
+	}
+}
+
+func TestLoggerRequestID(t *testing.T) {
+	cases := []struct {
+		desc      string
+		ctx       context.Context
+		requestID string
+	}{
+		{
+			desc:      "log with request ID in context",
+			ctx:       requestid.WithID(context.Background(), "request-id"),
+			requestID: "request-id",
+		},
+		{
+			desc: "log without request ID in context",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			writer := &mockWriter{}
+			logger, err := mglog.New(writer, slog.LevelInfo.String(), mglog.JSONFormat)
+			assert.Nil(t, err, "unexpected error during logger initialization")
+
+			logger.With(slog.String("service", "test")).InfoContext(tc.ctx, "message")
+
+			entry := map[string]any{}
+			err = json.Unmarshal(writer.value, &entry)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error parsing log entry: %s", tc.desc, err))
+			id, ok := entry[requestid.LogKey]
+			switch tc.requestID {
+			case "":
+				assert.False(t, ok, fmt.Sprintf("%s: unexpected request ID in log entry", tc.desc))
+			default:
+				assert.Equal(t, tc.requestID, id, fmt.Sprintf("%s: expected request ID %s, got %v", tc.desc, tc.requestID, id))
+			}
+			assert.Equal(t, "test", entry["service"], fmt.Sprintf("%s: expected logger attributes to be kept", tc.desc))
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+)
+
+// ErrBatcherClosed indicates that the message was published after the
+// batching publisher has been closed.
+var ErrBatcherClosed = errors.New("batching publisher is closed")
+
+// BatchConfig defines how the published messages are batched.
+type BatchConfig struct {
+	// Size is the maximum number of messages in a batch. Batching is
+	// disabled if it is less than two.
+	Size int `env:"SIZE" envDefault:"0"`
+
+	// Linger is the time a batch waits for more messages before it is
+	// flushed.
+	Linger time.Duration `env:"LINGER" envDefault:"10ms"`
+}
+
+// Enabled reports whether the messages are batched.
+func (cfg BatchConfig) Enabled() bool {
+	return cfg.Size > 1
+}
+
+type batch struct {
+	topic string
+	msgs  []*messaging.Message
+	timer *time.Timer
+	// done is closed once the batch is published, and err holds the error
+	// of publishing it.
+	done chan struct{}
+	err  error
+	// acked is true if the batch holds acknowledged messages, so the
+	// wrapped publisher publishes it before returning too.
+	acked bool
+}
+
+var _ messaging.Publisher = (*batcher)(nil)
+
+type batcher struct {
+	publisher messaging.Publisher
+	cfg       BatchConfig
+	logger    *slog.Logger
+
+	mu      sync.Mutex
+	closed  bool
+	batches map[string]*batch
+	flushes chan *batch
+	done    chan struct{}
+}
+
+// NewBatchPublisher returns publisher which coalesces the messages published
+// to the same topic into batches. A batch is flushed once it reaches the
+// configured size or once the linger time since its first message passes.
+//
+// Batches are flushed one after another in the order they were completed,
+// so the messages published to a topic keep their order. Publish returns
+// once the message is queued, and the errors of flushing the batches are
+// logged. The acknowledged messages, see messaging.WithQoS, are held until
+// their batch is published, and Publish returns the error of publishing the
+// batch, so the client isn't acknowledged a message which is lost. If the
+// wrapped publisher implements messaging.BatchPublisher, a
+// batch is published at once, otherwise its messages are published one by
+// one.
+func NewBatchPublisher(publisher messaging.Publisher, cfg BatchConfig, logger *slog.Logger) messaging.Publisher {
+	b := &batcher{
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+		batches:   make(map[string]*batch),
+		flushes:   make(chan *batch, cfg.Size),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+
+	return b
+}
+
+func (b *batcher) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatcherClosed
+	}
+	bt, ok := b.batches[topic]
+	if !ok {
+		bt = &batch{
+			topic: topic,
+			msgs:  make([]*messaging.Message, 0, b.cfg.Size),
+			done:  make(chan struct{}),
+		}
+		bt.timer = time.AfterFunc(b.cfg.Linger, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			// The batch may have been flushed because it filled up.
+			if b.batches[topic] == bt {
+				b.flush(bt)
+			}
+		})
+		b.batches[topic] = bt
+	}
+	bt.msgs = append(bt.msgs, msg)
+	acked := messaging.Acknowledged(ctx)
+	bt.acked = bt.acked || acked
+	if len(bt.msgs) >= b.cfg.Size {
+		bt.timer.Stop()
+		b.flush(bt)
+	}
+	b.mu.Unlock()
+
+	if !acked {
+		return nil
+	}
+	select {
+	case <-bt.done:
+		return bt.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes the pending batches, waits for them to be published and
+// closes the wrapped publisher.
+func (b *batcher) Close() error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, bt := range b.batches {
+			bt.timer.Stop()
+			b.flush(bt)
+		}
+		close(b.flushes)
+	}
+	b.mu.Unlock()
+	<-b.done
+
+	return b.publisher.Close()
+}
+
+// flush queues the batch for publishing. The caller must hold the lock, so
+// the batches are queued in the order they were completed.
+func (b *batcher) flush(bt *batch) {
+	delete(b.batches, bt.topic)
+	b.flushes <- bt
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+	for bt := range b.flushes {
+		if bt.err = b.publish(bt); bt.err != nil {
+			b.logger.Error(fmt.Sprintf("failed to publish batch of %d messages to topic %s: %s", len(bt.msgs), bt.topic, bt.err))
+		}
+		close(bt.done)
+	}
+}
+
+func (b *batcher) publish(bt *batch) error {
+	ctx := context.Background()
+	if bt.acked {
+		ctx = messaging.WithQoS(ctx, 1)
+	}
+	if bp, ok := b.publisher.(messaging.BatchPublisher); ok {
+		err := bp.PublishBatch(ctx, bt.topic, bt.msgs)
+		if !errors.Contains(err, messaging.ErrBatchNotSupported) {
+			return err
+		}
+	}
+	for _, msg := range bt.msgs {
+		if err := b.publisher.Publish(ctx, bt.topic, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	pubsub "github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	chanID1 = "123e4567-e89b-12d3-a456-000000000002"
+	waitFor = time.Second
+	tick    = 5 * time.Millisecond
+)
+
+var errPublish = errors.New("failed to publish batch")
+
+// published collects the messages passed to the broker per topic.
+type published struct {
+	mu    sync.Mutex
+	calls int
+	msgs  map[string][]*messaging.Message
+}
+
+func (p *published) add(topic string, msgs ...*messaging.Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	p.msgs[topic] = append(p.msgs[topic], msgs...)
+}
+
+func (p *published) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, msgs := range p.msgs {
+		n += len(msgs)
+	}
+
+	return n
+}
+
+func newBatchPublisher(cfg mqtt.BatchConfig) (messaging.Publisher, *published, *pubsub.BatchPublisher) {
+	pub := new(pubsub.BatchPublisher)
+	p := &published{msgs: make(map[string][]*messaging.Message)}
+	pub.On("PublishBatch", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		p.add(args.String(1), args.Get(2).([]*messaging.Message)...)
+	}).Return(nil)
+	pub.On("Close").Return(nil)
+
+	return mqtt.NewBatchPublisher(pub, cfg, mglog.NewMock()), p, pub
+}
+
+func message(channel string, i int) *messaging.Message {
+	return &messaging.Message{
+		Channel:   channel,
+		Publisher: thingID,
+		Payload:   []byte(fmt.Sprintf("%d", i)),
+	}
+}
+
+func TestBatchPublisherSize(t *testing.T) {
+	bp, p, _ := newBatchPublisher(mqtt.BatchConfig{Size: 10, Linger: time.Hour})
+
+	var want []*messaging.Message
+	for i := 0; i < 100; i++ {
+		msg := message(chanID, i)
+		want = append(want, msg)
+		err := bp.Publish(context.Background(), chanID, msg)
+		assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+	}
+
+	assert.Eventually(t, func() bool { return p.count() == len(want) }, waitFor, tick, "all messages expected to be published")
+	assert.Equal(t, 10, p.calls, "full batches expected to be published without waiting for the linger time")
+	assert.Equal(t, want, p.msgs[chanID], "messages expected to be published in order")
+}
+
+func TestBatchPublisherLinger(t *testing.T) {
+	bp, p, _ := newBatchPublisher(mqtt.BatchConfig{Size: 100, Linger: 20 * time.Millisecond})
+
+	var want []*messaging.Message
+	for i := 0; i < 5; i++ {
+		msg := message(chanID, i)
+		want = append(want, msg)
+		err := bp.Publish(context.Background(), chanID, msg)
+		assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+	}
+
+	assert.Eventually(t, func() bool { return p.count() == len(want) }, waitFor, tick, "batch expected to be flushed after the linger time")
+	assert.Equal(t, 1, p.calls, "messages expected to be published in a single batch")
+	assert.Equal(t, want, p.msgs[chanID], "messages expected to be published in order")
+}
+
+func TestBatchPublisherTopics(t *testing.T) {
+	bp, p, _ := newBatchPublisher(mqtt.BatchConfig{Size: 3, Linger: time.Hour})
+
+	want := make(map[string][]*messaging.Message)
+	for i := 0; i < 6; i++ {
+		for _, ch := range []string{chanID, chanID1} {
+			msg := message(ch, i)
+			want[ch] = append(want[ch], msg)
+			err := bp.Publish(context.Background(), ch, msg)
+			assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+		}
+	}
+
+	assert.Eventually(t, func() bool { return p.count() == 12 }, waitFor, tick, "all messages expected to be published")
+	assert.Equal(t, 4, p.calls, "messages expected to be batched per topic")
+	assert.Equal(t, want, p.msgs, "messages expected to be published in order per topic")
+}
+
+func TestBatchPublisherClose(t *testing.T) {
+	bp, p, pub := newBatchPublisher(mqtt.BatchConfig{Size: 100, Linger: time.Hour})
+
+	for i := 0; i < 5; i++ {
+		err := bp.Publish(context.Background(), chanID, message(chanID, i))
+		assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+	}
+	err := bp.Close()
+	assert.Nil(t, err, fmt.Sprintf("closing publisher expected to succeed: %s", err))
+	assert.Equal(t, 5, p.count(), "pending batch expected to be flushed on close")
+	pub.AssertCalled(t, "Close")
+
+	err = bp.Publish(context.Background(), chanID, message(chanID, 5))
+	assert.Equal(t, mqtt.ErrBatcherClosed, err, fmt.Sprintf("expected %s, got %s", mqtt.ErrBatcherClosed, err))
+}
+
+func TestBatchPublisherFallback(t *testing.T) {
+	pub := new(pubsub.PubSub)
+	var (
+		mu   sync.Mutex
+		msgs []*messaging.Message
+	)
+	pub.On("Publish", mock.Anything, chanID, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		msgs = append(msgs, args.Get(2).(*messaging.Message))
+	}).Return(nil)
+	pub.On("Close").Return(nil)
+	bp := mqtt.NewBatchPublisher(pub, mqtt.BatchConfig{Size: 4, Linger: time.Hour}, mglog.NewMock())
+
+	var want []*messaging.Message
+	for i := 0; i < 8; i++ {
+		msg := message(chanID, i)
+		want = append(want, msg)
+		err := bp.Publish(context.Background(), chanID, msg)
+		assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+	}
+	err := bp.Close()
+	assert.Nil(t, err, fmt.Sprintf("closing publisher expected to succeed: %s", err))
+	assert.Equal(t, want, msgs, "messages expected to be published one by one in order")
+}
+
+func TestBatchPublisherAcknowledged(t *testing.T) {
+	bp, p, _ := newBatchPublisher(mqtt.BatchConfig{Size: 100, Linger: 20 * time.Millisecond})
+
+	err := bp.Publish(context.Background(), chanID, message(chanID, 0))
+	assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+	err = bp.Publish(messaging.WithQoS(context.Background(), 1), chanID, message(chanID, 1))
+	assert.Nil(t, err, fmt.Sprintf("publishing acknowledged message expected to succeed: %s", err))
+	assert.Equal(t, 2, p.count(), "acknowledged message expected to be held until its batch is published")
+
+	pub := new(pubsub.BatchPublisher)
+	pub.On("PublishBatch", mock.Anything, chanID, mock.Anything).Return(errPublish)
+	bp = mqtt.NewBatchPublisher(pub, mqtt.BatchConfig{Size: 2, Linger: time.Hour}, mglog.NewMock())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- bp.Publish(messaging.WithQoS(context.Background(), 2), chanID, message(chanID, 0))
+	}()
+	err = bp.Publish(context.Background(), chanID, message(chanID, 1))
+	assert.Nil(t, err, fmt.Sprintf("publishing message expected to succeed: %s", err))
+	select {
+	case err = <-errs:
+		assert.Equal(t, errPublish, err, fmt.Sprintf("expected %s, got %s", errPublish, err))
+	case <-time.After(waitFor):
+		t.Error("acknowledged message expected to return the batch error")
+	}
+}
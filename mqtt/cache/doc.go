@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cache contains the Redis implementation of the MQTT adapter
+// connection limiter, which shares connection counts between adapter instances.
+package cache
@@ -0,0 +1,166 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/gofrs/uuid/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "mqtt_conns"
+
+var (
+	// ErrInvalidTTL indicates that the connections TTL is not positive.
+	ErrInvalidTTL = errors.New("connections TTL must be positive")
+
+	errRefresh = errors.New("failed to refresh connections")
+)
+
+// acquireScript removes expired connections of the thing and adds the new
+// one unless the limit is reached. Connections are scored by the time they
+// were last refreshed, so connections of crashed instances expire after TTL.
+var acquireScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local ttl = tonumber(ARGV[1])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - ttl)
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], now, ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ttl)
+return 1
+`)
+
+// refreshScript updates the score of the live connection, if it's still present.
+var refreshScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+if redis.call("ZSCORE", KEYS[1], ARGV[2]) then
+	redis.call("ZADD", KEYS[1], now, ARGV[2])
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+var _ mqtt.ConnLimiter = (*limiter)(nil)
+
+type limiter struct {
+	client *redis.Client
+	max    int
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mu sync.Mutex
+	// conns maps IDs of the connections held by this instance to their keys.
+	conns map[string]string
+}
+
+// NewConnLimiter returns Redis connection limiter which allows at most max
+// concurrent connections per thing across all adapter instances sharing the
+// Redis database. Live connections are refreshed every third of TTL until
+// the context is canceled. Connections which are not refreshed within TTL,
+// e.g. those of crashed instances, are no longer counted. TTL must be positive.
+func NewConnLimiter(ctx context.Context, client *redis.Client, max int, ttl time.Duration, logger *slog.Logger) (mqtt.ConnLimiter, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+	l := &limiter{
+		client: client,
+		max:    max,
+		ttl:    ttl,
+		logger: logger,
+		conns:  make(map[string]string),
+	}
+	go l.refreshPeriodically(ctx)
+
+	return l, nil
+}
+
+func (l *limiter) Acquire(ctx context.Context, thingKey string) (string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+	connID := id.String()
+	key := connsKey(thingKey)
+
+	ok, err := acquireScript.Run(ctx, l.client, []string{key}, l.ttl.Milliseconds(), l.max, connID).Bool()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", mqtt.ErrConnLimitExceeded
+	}
+
+	l.mu.Lock()
+	l.conns[connID] = key
+	l.mu.Unlock()
+
+	return connID, nil
+}
+
+func (l *limiter) Release(ctx context.Context, thingKey, connID string) error {
+	l.mu.Lock()
+	delete(l.conns, connID)
+	l.mu.Unlock()
+
+	return l.client.ZRem(ctx, connsKey(thingKey), connID).Err()
+}
+
+func (l *limiter) refreshPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.refresh(ctx); err != nil {
+				l.logger.Warn(err.Error())
+			}
+		}
+	}
+}
+
+func (l *limiter) refresh(ctx context.Context) error {
+	l.mu.Lock()
+	conns := make(map[string]string, len(l.conns))
+	for id, key := range l.conns {
+		conns[id] = key
+	}
+	l.mu.Unlock()
+
+	if len(conns) == 0 {
+		return nil
+	}
+
+	pipe := l.client.Pipeline()
+	for id, key := range conns {
+		refreshScript.Eval(ctx, pipe, []string{key}, l.ttl.Milliseconds(), id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(errRefresh, err)
+	}
+
+	return nil
+}
+
+// connsKey hashes the thing key so that secrets are not stored in Redis.
+func connsKey(thingKey string) string {
+	sum := sha256.Sum256([]byte(thingKey))
+
+	return fmt.Sprintf("%s:%s", keyPrefix, hex.EncodeToString(sum[:]))
+}
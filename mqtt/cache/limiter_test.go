@@ -0,0 +1,122 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/magistrala/mqtt/cache"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	thingKey  = "thing-key"
+	thingKey1 = "thing-key-1"
+	maxConns  = 3
+)
+
+func TestNewConnLimiter(t *testing.T) {
+	cases := []struct {
+		desc string
+		ttl  time.Duration
+		err  error
+	}{
+		{
+			desc: "create limiter with positive TTL",
+			ttl:  time.Minute,
+		},
+		{
+			desc: "create limiter with zero TTL",
+			ttl:  0,
+			err:  cache.ErrInvalidTTL,
+		},
+		{
+			desc: "create limiter with negative TTL",
+			ttl:  -time.Minute,
+			err:  cache.ErrInvalidTTL,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			limiter, err := cache.NewConnLimiter(ctx, redisClient, maxConns, tc.ttl, mglog.NewMock())
+			assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.NotNil(t, limiter, fmt.Sprintf("%s: expected limiter", tc.desc))
+			}
+		})
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	limiter, err := cache.NewConnLimiter(ctx, redisClient, maxConns, time.Minute, mglog.NewMock())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating limiter %s", err))
+	// Second limiter represents another adapter instance sharing the store.
+	limiter1, err := cache.NewConnLimiter(ctx, redisClient, maxConns, time.Minute, mglog.NewMock())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating limiter %s", err))
+
+	var ids []string
+	for i := 0; i < maxConns+2; i++ {
+		l := limiter
+		if i%2 == 1 {
+			l = limiter1
+		}
+		id, err := l.Acquire(ctx, thingKey)
+		switch {
+		case i < maxConns:
+			assert.Nil(t, err, fmt.Sprintf("connection %d: unexpected error %s", i, err))
+			assert.NotEmpty(t, id, fmt.Sprintf("connection %d: expected connection ID", i))
+			ids = append(ids, id)
+		default:
+			assert.True(t, errors.Contains(err, mqtt.ErrConnLimitExceeded), fmt.Sprintf("connection %d: expected %s got %s", i, mqtt.ErrConnLimitExceeded, err))
+		}
+	}
+
+	_, err = limiter.Acquire(ctx, thingKey1)
+	assert.Nil(t, err, fmt.Sprintf("other thing: unexpected error %s", err))
+
+	err = limiter.Release(ctx, thingKey, ids[0])
+	assert.Nil(t, err, fmt.Sprintf("unexpected error on release %s", err))
+	_, err = limiter1.Acquire(ctx, thingKey)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error after release %s", err))
+	_, err = limiter1.Acquire(ctx, thingKey)
+	assert.True(t, errors.Contains(err, mqtt.ErrConnLimitExceeded), fmt.Sprintf("expected %s got %s", mqtt.ErrConnLimitExceeded, err))
+}
+
+func TestAcquireExpired(t *testing.T) {
+	ttl := 300 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	// Stopped limiter simulates crashed instance which no longer refreshes its connections.
+	crashed, err := cache.NewConnLimiter(ctx, redisClient, 1, ttl, mglog.NewMock())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating limiter %s", err))
+	cancel()
+	_, err = crashed.Acquire(context.Background(), t.Name())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	limiter, err := cache.NewConnLimiter(ctx, redisClient, 1, ttl, mglog.NewMock())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating limiter %s", err))
+	_, err = limiter.Acquire(ctx, t.Name())
+	assert.True(t, errors.Contains(err, mqtt.ErrConnLimitExceeded), fmt.Sprintf("expected %s got %s", mqtt.ErrConnLimitExceeded, err))
+
+	time.Sleep(2 * ttl)
+	_, err = limiter.Acquire(ctx, t.Name())
+	assert.Nil(t, err, fmt.Sprintf("expected expired connection to be removed, got %s", err))
+
+	// Live connections are refreshed and don't expire.
+	time.Sleep(2 * ttl)
+	_, err = limiter.Acquire(ctx, t.Name())
+	assert.True(t, errors.Contains(err, mqtt.ErrConnLimitExceeded), fmt.Sprintf("expected %s got %s", mqtt.ErrConnLimitExceeded, err))
+}
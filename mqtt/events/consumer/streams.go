@@ -0,0 +1,51 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/absmach/magistrala/mqtt"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/events"
+)
+
+const thingRotateKey = "thing.rotate_key"
+
+type eventHandler struct {
+	sessions *mqtt.Sessions
+	logger   *slog.Logger
+}
+
+// NewEventHandler returns new event store handler, which closes the sessions
+// of the things whose key is rotated, so the clients have to reconnect with
+// the new key.
+func NewEventHandler(sessions *mqtt.Sessions, logger *slog.Logger) events.EventHandler {
+	return &eventHandler{
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+func (es *eventHandler) Handle(ctx context.Context, event events.Event) error {
+	msg, err := event.Encode()
+	if err != nil {
+		return err
+	}
+
+	switch msg["operation"] {
+	case thingRotateKey:
+		id, _ := msg["id"].(string)
+		if id == "" {
+			return svcerr.ErrMalformedEntity
+		}
+		if n := es.sessions.Close(id); n > 0 {
+			es.logger.Info(fmt.Sprintf("closed %d sessions of thing %s after key rotation", n, id))
+		}
+	}
+
+	return nil
+}
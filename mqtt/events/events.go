@@ -5,7 +5,20 @@ package events
 
 import "github.com/absmach/magistrala/pkg/events"
 
-var _ events.Event = (*mqttEvent)(nil)
+const (
+	protocol      = "mqtt"
+	connPrefix    = "conn."
+	connOpen      = connPrefix + "open"
+	connChannel   = connPrefix + "channel"
+	connClose     = connPrefix + "close"
+	connReset     = connPrefix + "reset"
+	connHeartbeat = connPrefix + "heartbeat"
+)
+
+var (
+	_ events.Event = (*mqttEvent)(nil)
+	_ events.Event = (*connEvent)(nil)
+)
 
 type mqttEvent struct {
 	clientID  string
@@ -20,3 +33,36 @@ func (me mqttEvent) Encode() (map[string]interface{}, error) {
 		"instance":  me.instance,
 	}, nil
 }
+
+// connEvent feeds the registry of the live connections. The connections are
+// identified by the adapter instance and the MQTT client ID.
+type connEvent struct {
+	operation string
+	instance  string
+	clientID  string
+	thingID   string
+	domainID  string
+	channelID string
+}
+
+func (ce connEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation": ce.operation,
+		"instance":  ce.instance,
+		"protocol":  protocol,
+	}
+	if ce.clientID != "" {
+		val["client_id"] = ce.clientID
+	}
+	if ce.thingID != "" {
+		val["thing_id"] = ce.thingID
+	}
+	if ce.domainID != "" {
+		val["domain_id"] = ce.domainID
+	}
+	if ce.channelID != "" {
+		val["channel_id"] = ce.channelID
+	}
+
+	return val, nil
+}
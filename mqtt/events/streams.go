@@ -5,6 +5,8 @@ package events
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/absmach/magistrala/pkg/events"
 	"github.com/absmach/magistrala/pkg/events/store"
@@ -16,6 +18,22 @@ const streamID = "magistrala.mqtt"
 type EventStore interface {
 	Connect(ctx context.Context, clientID string) error
 	Disconnect(ctx context.Context, clientID string) error
+
+	// OpenConn issues event once the client connects.
+	OpenConn(ctx context.Context, clientID string) error
+
+	// AttachConn issues event once the thing of the client is authorized on
+	// the channel.
+	AttachConn(ctx context.Context, clientID, thingID, domainID, channelID string) error
+
+	// CloseConn issues event once the client disconnects.
+	CloseConn(ctx context.Context, clientID string) error
+
+	// ResetConns issues event closing all the connections of the instance.
+	ResetConns(ctx context.Context) error
+
+	// Heartbeat issues event reporting that the instance is alive.
+	Heartbeat(ctx context.Context) error
 }
 
 // EventStore is a struct used to store event streams in Redis.
@@ -59,3 +77,76 @@ func (es *eventStore) Disconnect(ctx context.Context, clientID string) error {
 
 	return es.Publish(ctx, ev)
 }
+
+// OpenConn issues event once the client connects.
+func (es *eventStore) OpenConn(ctx context.Context, clientID string) error {
+	ev := connEvent{
+		operation: connOpen,
+		instance:  es.instance,
+		clientID:  clientID,
+	}
+
+	return es.Publish(ctx, ev)
+}
+
+// AttachConn issues event once the thing of the client is authorized on the
+// channel.
+func (es *eventStore) AttachConn(ctx context.Context, clientID, thingID, domainID, channelID string) error {
+	ev := connEvent{
+		operation: connChannel,
+		instance:  es.instance,
+		clientID:  clientID,
+		thingID:   thingID,
+		domainID:  domainID,
+		channelID: channelID,
+	}
+
+	return es.Publish(ctx, ev)
+}
+
+// CloseConn issues event once the client disconnects.
+func (es *eventStore) CloseConn(ctx context.Context, clientID string) error {
+	ev := connEvent{
+		operation: connClose,
+		instance:  es.instance,
+		clientID:  clientID,
+	}
+
+	return es.Publish(ctx, ev)
+}
+
+// ResetConns issues event closing all the connections of the instance.
+func (es *eventStore) ResetConns(ctx context.Context) error {
+	return es.Publish(ctx, connEvent{operation: connReset, instance: es.instance})
+}
+
+// Heartbeat issues event reporting that the instance is alive.
+func (es *eventStore) Heartbeat(ctx context.Context) error {
+	return es.Publish(ctx, connEvent{operation: connHeartbeat, instance: es.instance})
+}
+
+// RunHeartbeat closes the connections left by the previous run of the
+// instance, and then reports that the instance is alive every interval until
+// the context is canceled, so the registry expires the connections of the
+// instances which stopped without closing them.
+func RunHeartbeat(ctx context.Context, es EventStore, interval time.Duration, logger *slog.Logger) {
+	if err := es.ResetConns(ctx); err != nil {
+		logger.Error("failed to publish connections reset event", slog.Any("error", err))
+	}
+	if err := es.Heartbeat(ctx); err != nil {
+		logger.Error("failed to publish heartbeat event", slog.Any("error", err))
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := es.Heartbeat(ctx); err != nil {
+				logger.Error("failed to publish heartbeat event", slog.Any("error", err))
+			}
+		}
+	}
+}
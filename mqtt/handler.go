@@ -10,21 +10,36 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/mqtt/events"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/ratelimit"
 	"github.com/absmach/mproxy/pkg/session"
+	"github.com/eclipse/paho.mqtt.golang/packets"
 )
 
-var _ session.Handler = (*handler)(nil)
+var (
+	_ session.Handler     = (*handler)(nil)
+	_ session.Interceptor = (*handler)(nil)
+)
 
 const protocol = "mqtt"
 
+const (
+	messagesPath    = "/messages"
+	mqttWildcardOne = "+"
+	mqttWildcardAll = "#"
+	wildcardOne     = "*"
+	wildcardAll     = ">"
+)
+
 // Log message formats.
 const (
 	LogInfoSubscribed   = "subscribed with client_id %s to topics %s"
@@ -32,6 +47,10 @@ const (
 	LogInfoConnected    = "connected with client_id %s"
 	LogInfoDisconnected = "disconnected client_id %s and username %s"
 	LogInfoPublished    = "published with client_id %s to the topic %s"
+	LogWarnIPDenied     = "rejected connection from %s: %s"
+	LogWarnRateReject   = "rejected message of thing %s over the publish rate: %s"
+	LogWarnRateShed     = "shed message of thing %s over the publish rate"
+	LogWarnFlagged      = "flagged unverified message of thing %s on the channel %s"
 )
 
 // Error wrappers for MQTT errors.
@@ -50,7 +69,9 @@ var (
 	ErrFailedPublishDisconnectEvent = errors.New("failed to publish disconnect event")
 	ErrFailedParseSubtopic          = errors.New("failed to parse subtopic")
 	ErrFailedPublishConnectEvent    = errors.New("failed to publish connect event")
+	ErrFailedPublishConnEvent       = errors.New("failed to publish connection event")
 	ErrFailedPublishToMsgBroker     = errors.New("failed to publish to magistrala message broker")
+	ErrFailedReleaseConn            = errors.New("failed to release connection")
 )
 
 var channelRegExp = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/messages(\/[^?]*)?(\?.*)?$`)
@@ -59,17 +80,68 @@ var channelRegExp = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/messages(\/[^?]
 type handler struct {
 	publisher messaging.Publisher
 	things    magistrala.ThingsServiceClient
+	subtopics messaging.SubtopicRules
+	topics    messaging.TopicScheme
 	logger    *slog.Logger
 	es        events.EventStore
+	limiter   ConnLimiter
+	ipFilter  ipfilter.Filter
+	rates     ratelimit.Limiter
+	signing   messaging.SigningRules
+	sessions  *Sessions
+	// conns maps sessions to connections acquired from the limiter.
+	conns sync.Map
+	// shed holds the sessions whose last message is over the publish rate
+	// and is not published to the message broker.
+	shed sync.Map
+	// unverified holds the sessions whose last message has no valid
+	// signature, but is published marked as unverified.
+	unverified sync.Map
+	// domains maps sessions to the domains of their things, which scope the
+	// topics the messages are published to.
+	domains sync.Map
+	// qos maps sessions to the QoS of their last published message, which
+	// sets the priority of the message.
+	qos sync.Map
+	// batches maps sessions to the authorized records of their last
+	// published batch.
+	batches sync.Map
+	// channels maps sessions to the channels their things are authorized
+	// on, whose connections are reported to the connection registry.
+	channels sync.Map
+}
+
+type conn struct {
+	thingKey string
+	id       string
 }
 
-// NewHandler creates new Handler entity.
-func NewHandler(publisher messaging.Publisher, es events.EventStore, logger *slog.Logger, thingsClient magistrala.ThingsServiceClient) session.Handler {
+// NewHandler creates new Handler entity. If limiter is nil, the number
+// of concurrent connections per thing is not limited. If the IP filter is
+// not nil, connections from IP addresses it rejects are denied. The remote
+// IP address is read from the context, see ipfilter.RemoteIP. If the rate
+// limiter is not nil, publishes of things over their rate are rejected by
+// disconnecting the client, or shed by not publishing them to the message
+// broker. The payloads published to the channels requiring signatures are
+// verified with the signing secret of the thing, the signature being set in
+// the signature query parameter of the topic. The messages are published to
+// the topics of the topic scheme. If the sessions registry is not nil, the
+// client connections of the authorized things are registered, so they can
+// be closed once the thing key is rotated. The handler is also the session.Interceptor which maps the QoS of the
+// published messages to their priority, see messaging.QoSPriority.
+func NewHandler(publisher messaging.Publisher, es events.EventStore, logger *slog.Logger, thingsClient magistrala.ThingsServiceClient, subtopics messaging.SubtopicRules, topics messaging.TopicScheme, limiter ConnLimiter, ipFilter ipfilter.Filter, rates ratelimit.Limiter, signing messaging.SigningRules, sessions *Sessions) session.Handler {
 	return &handler{
 		es:        es,
 		logger:    logger,
 		publisher: publisher,
 		things:    thingsClient,
+		subtopics: subtopics,
+		topics:    topics,
+		limiter:   limiter,
+		ipFilter:  ipFilter,
+		rates:     rates,
+		signing:   signing,
+		sessions:  sessions,
 	}
 }
 
@@ -85,11 +157,25 @@ func (h *handler) AuthConnect(ctx context.Context) error {
 		return ErrMissingClientID
 	}
 
+	if err := h.checkIP(ctx, ""); err != nil {
+		return err
+	}
+
 	pwd := string(s.Password)
 
+	if err := h.acquire(ctx, s, pwd); err != nil {
+		return err
+	}
+
 	if err := h.es.Connect(ctx, pwd); err != nil {
 		h.logger.Error(errors.Wrap(ErrFailedPublishConnectEvent, err).Error())
 	}
+	// A client may send CONNECT more than once on the same session, which
+	// opens the connection anew.
+	h.channels.Delete(s)
+	if err := h.es.OpenConn(ctx, s.ID); err != nil {
+		h.logger.Error(errors.Wrap(ErrFailedPublishConnEvent, err).Error())
+	}
 
 	return nil
 }
@@ -105,7 +191,30 @@ func (h *handler) AuthPublish(ctx context.Context, topic *string, payload *[]byt
 		return ErrClientNotInitialized
 	}
 
-	return h.authAccess(ctx, string(s.Password), *topic, policies.PublishPermission)
+	if parts := batchRegExp.FindStringSubmatch(*topic); len(parts) > 1 {
+		return h.authBatch(ctx, s, topic, payload, parts[1])
+	}
+
+	var data []byte
+	if payload != nil {
+		data = *payload
+	}
+	normalized, err := h.normalizeTopic(*topic)
+	if err != nil {
+		return err
+	}
+	*topic = normalized
+	res, chanID, err := h.authAccess(ctx, string(s.Password), *topic, policies.PublishPermission, data)
+	if err != nil {
+		return err
+	}
+	h.domains.Store(s, res.GetDomainId())
+	h.attachConn(ctx, s, chanID, res)
+	if err := h.checkSignature(ctx, s, chanID, *topic, data, res); err != nil {
+		return err
+	}
+
+	return h.checkRate(ctx, s, res)
 }
 
 // AuthSubscribe is called on device subscribe,
@@ -119,10 +228,32 @@ func (h *handler) AuthSubscribe(ctx context.Context, topics *[]string) error {
 		return ErrMissingTopicSub
 	}
 
-	for _, v := range *topics {
-		if err := h.authAccess(ctx, string(s.Password), v, policies.SubscribePermission); err != nil {
+	for i, v := range *topics {
+		// Gateways subscribe to the reports of their batches.
+		if parts := batchResponseRegExp.FindStringSubmatch(v); len(parts) > 1 {
+			res, err := h.authorize(ctx, &magistrala.ThingsAuthzReq{
+				Permission: policies.SubscribePermission,
+				ThingKey:   string(s.Password),
+				ChannelID:  parts[1],
+			})
+			if err != nil {
+				return err
+			}
+			h.attachConn(ctx, s, parts[1], res)
+			continue
+		}
+		// Subscriptions are normalized with the same rules as the published
+		// topics, so the subscribers keep matching the normalized messages.
+		normalized, err := h.normalizeTopic(v)
+		if err != nil {
 			return err
 		}
+		(*topics)[i] = normalized
+		res, chanID, err := h.authAccess(ctx, string(s.Password), normalized, policies.SubscribePermission, nil)
+		if err != nil {
+			return err
+		}
+		h.attachConn(ctx, s, chanID, res)
 	}
 
 	return nil
@@ -144,7 +275,15 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	if !ok {
 		return errors.Wrap(ErrFailedPublish, ErrClientNotInitialized)
 	}
+	b, isBatch := h.batches.LoadAndDelete(s)
+	_, unverified := h.unverified.LoadAndDelete(s)
+	if _, ok := h.shed.LoadAndDelete(s); ok {
+		return nil
+	}
 	h.logger.Info(fmt.Sprintf(LogInfoPublished, s.ID, *topic))
+	if isBatch {
+		return h.publishBatch(ctx, s, b.(authorizedBatch), unverified)
+	}
 	// Topics are in the format:
 	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
 
@@ -160,17 +299,29 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	if err != nil {
 		return errors.Wrap(ErrFailedParseSubtopic, err)
 	}
+	if subtopic, err = h.subtopics.Apply(subtopic); err != nil {
+		return errors.Wrap(ErrFailedParseSubtopic, err)
+	}
 
 	msg := messaging.Message{
-		Protocol:  protocol,
-		Channel:   chanID,
-		Subtopic:  subtopic,
-		Publisher: s.Username,
-		Payload:   *payload,
-		Created:   time.Now().UnixNano(),
+		Protocol:   protocol,
+		Channel:    chanID,
+		Subtopic:   subtopic,
+		Publisher:  s.Username,
+		Payload:    *payload,
+		Created:    time.Now().UnixNano(),
+		Unverified: unverified,
+	}
+	if qos, ok := h.qos.Load(s); ok {
+		msg.Priority = messaging.QoSPriority(qos.(byte))
+		ctx = messaging.WithQoS(ctx, qos.(byte))
 	}
 
-	if err := h.publisher.Publish(ctx, msg.GetChannel(), &msg); err != nil {
+	var domainID string
+	if d, ok := h.domains.Load(s); ok {
+		domainID = d.(string)
+	}
+	if err := h.publisher.Publish(ctx, h.topics.Topic(domainID, msg.GetChannel()), &msg); err != nil {
 		return errors.Wrap(ErrFailedPublishToMsgBroker, err)
 	}
 
@@ -204,42 +355,224 @@ func (h *handler) Disconnect(ctx context.Context) error {
 		return errors.Wrap(ErrFailedDisconnect, ErrClientNotInitialized)
 	}
 	h.logger.Error(fmt.Sprintf(LogInfoDisconnected, s.ID, s.Password))
+	if c, ok := h.conns.LoadAndDelete(s); ok {
+		h.release(ctx, c.(conn))
+	}
+	h.shed.Delete(s)
+	h.unverified.Delete(s)
+	h.domains.Delete(s)
+	h.qos.Delete(s)
+	h.batches.Delete(s)
+	h.sessions.remove(s)
+	h.channels.Delete(s)
+	if err := h.es.CloseConn(ctx, s.ID); err != nil {
+		h.logger.Error(errors.Wrap(ErrFailedPublishConnEvent, err).Error())
+	}
 	if err := h.es.Disconnect(ctx, string(s.Password)); err != nil {
 		return errors.Wrap(ErrFailedPublishDisconnectEvent, err)
 	}
 	return nil
 }
 
-func (h *handler) authAccess(ctx context.Context, password, topic, action string) error {
+// Intercept records the QoS of the messages published by the client. It's
+// called after AuthPublish and before Publish, so Publish sets the priority
+// of the message from its QoS.
+func (h *handler) Intercept(ctx context.Context, pkt packets.ControlPacket, dir session.Direction) (packets.ControlPacket, error) {
+	p, ok := pkt.(*packets.PublishPacket)
+	if !ok || dir != session.Up {
+		return pkt, nil
+	}
+	if s, ok := session.FromContext(ctx); ok {
+		h.qos.Store(s, p.Qos)
+	}
+
+	return pkt, nil
+}
+
+// acquire registers the connection with the limiter. Connections are
+// rejected only if the limit is exceeded, limiter failures are logged
+// so that the unavailable store doesn't prevent things from connecting.
+func (h *handler) acquire(ctx context.Context, s *session.Session, thingKey string) error {
+	if h.limiter == nil {
+		return nil
+	}
+
+	id, err := h.limiter.Acquire(ctx, thingKey)
+	switch {
+	case errors.Contains(err, ErrConnLimitExceeded):
+		h.logger.Warn(fmt.Sprintf("rejected connection with client_id %s: %s", s.ID, err))
+		return errors.Wrap(ErrFailedConnect, err)
+	case err != nil:
+		h.logger.Error(fmt.Sprintf("failed to acquire connection for client_id %s: %s", s.ID, err))
+		return nil
+	}
+
+	// A client may send CONNECT more than once on the same session.
+	if prev, ok := h.conns.Swap(s, conn{thingKey: thingKey, id: id}); ok {
+		h.release(ctx, prev.(conn))
+	}
+
+	return nil
+}
+
+func (h *handler) release(ctx context.Context, c conn) {
+	if err := h.limiter.Release(ctx, c.thingKey, c.id); err != nil {
+		h.logger.Error(errors.Wrap(ErrFailedReleaseConn, err).Error())
+	}
+}
+
+func (h *handler) authAccess(ctx context.Context, password, topic, action string, payload []byte) (*magistrala.ThingsAuthzRes, string, error) {
 	// Topics are in the format:
 	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
 	if !channelRegExp.MatchString(topic) {
-		return ErrMalformedTopic
+		return nil, "", ErrMalformedTopic
 	}
 
 	channelParts := channelRegExp.FindStringSubmatch(topic)
 	if len(channelParts) < 1 {
-		return ErrMalformedTopic
+		return nil, "", ErrMalformedTopic
 	}
 
 	chanID := channelParts[1]
 
-	ar := &magistrala.ThingsAuthzReq{
-		Permission: action,
-		ThingKey:   password,
-		ChannelID:  chanID,
+	res, err := h.authorize(ctx, &magistrala.ThingsAuthzReq{
+		Permission:  action,
+		ThingKey:    password,
+		ChannelID:   chanID,
+		ContentType: messaging.ContentTypeFrom(ctx, topic),
+		Payload:     payload,
+	})
+	if err != nil {
+		return nil, "", err
 	}
+
+	return res, chanID, nil
+}
+
+// attachConn reports the connection of the thing of the session to the
+// channel the first time the thing is authorized on the channel in the
+// session.
+func (h *handler) attachConn(ctx context.Context, s *session.Session, chanID string, res *magistrala.ThingsAuthzRes) {
+	h.sessions.add(ctx, s, res.GetId())
+	chans, _ := h.channels.LoadOrStore(s, &sync.Map{})
+	if _, ok := chans.(*sync.Map).LoadOrStore(chanID, struct{}{}); ok {
+		return
+	}
+	if err := h.es.AttachConn(ctx, s.ID, res.GetId(), res.GetDomainId(), chanID); err != nil {
+		h.logger.Error(errors.Wrap(ErrFailedPublishConnEvent, err).Error())
+	}
+}
+
+func (h *handler) authorize(ctx context.Context, ar *magistrala.ThingsAuthzReq) (*magistrala.ThingsAuthzRes, error) {
 	res, err := h.things.Authorize(ctx, ar)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !res.GetAuthorized() {
-		return svcerr.ErrAuthorization
+		return nil, svcerr.ErrAuthorization
+	}
+	if err := h.checkIP(ctx, res.GetId()); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// checkSignature verifies the signature of the payload published by the
+// authorized thing to the channel. Messages without a valid signature are
+// rejected, which disconnects the client, or marked as unverified so that
+// Publish flags them.
+func (h *handler) checkSignature(ctx context.Context, s *session.Session, chanID, topic string, payload []byte, res *magistrala.ThingsAuthzRes) error {
+	msg := messaging.Message{Channel: chanID, Payload: payload}
+	if err := h.signing.Check(&msg, res.GetSigningSecret(), messaging.SignatureFrom(ctx, topic)); err != nil {
+		return errors.Wrap(ErrFailedPublish, err)
+	}
+	if msg.GetUnverified() {
+		h.logger.Warn(fmt.Sprintf(LogWarnFlagged, res.GetId(), chanID))
+		h.unverified.Store(s, struct{}{})
+	}
+
+	return nil
+}
+
+// checkRate takes a token from the bucket of the authorized thing. Messages
+// over the rate are rejected, which disconnects the client, or marked as shed
+// so that Publish drops them.
+func (h *handler) checkRate(ctx context.Context, s *session.Session, res *magistrala.ThingsAuthzRes) error {
+	allowed, err := messaging.CheckRate(ctx, h.rates, res)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf(LogWarnRateReject, res.GetId(), err))
+		return errors.Wrap(ErrFailedPublish, err)
+	}
+	if !allowed {
+		h.logger.Warn(fmt.Sprintf(LogWarnRateShed, res.GetId()))
+		h.shed.Store(s, struct{}{})
 	}
 
 	return nil
 }
 
+// checkIP checks the remote IP address carried by the context. Empty thing
+// ID checks only the default rules, which is used on connect since the
+// thing is not known before authorization.
+func (h *handler) checkIP(ctx context.Context, thingID string) error {
+	if h.ipFilter == nil {
+		return nil
+	}
+	ip := ipfilter.RemoteIP(ctx)
+	if err := h.ipFilter.Check(ip, thingID); err != nil {
+		h.logger.Warn(fmt.Sprintf(LogWarnIPDenied, ip, err))
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+
+	return nil
+}
+
+// normalizeTopic applies the subtopic rules to the subtopic of the topic,
+// with the MQTT wildcard levels kept as they are. The topic is rebuilt only
+// if the rules changed the subtopic, so the topics are forwarded unchanged
+// unless the rules normalize them.
+func (h *handler) normalizeTopic(topic string) (string, error) {
+	channelParts := channelRegExp.FindStringSubmatch(topic)
+	if len(channelParts) < 2 {
+		return "", ErrMalformedTopic
+	}
+
+	levels := strings.Split(channelParts[2], "/")
+	for i, level := range levels {
+		switch level {
+		case mqttWildcardOne:
+			levels[i] = wildcardOne
+		case mqttWildcardAll:
+			levels[i] = wildcardAll
+		}
+	}
+	subtopic, err := parseSubtopic(strings.Join(levels, "/"))
+	if err != nil {
+		return "", errors.Wrap(ErrFailedParseSubtopic, err)
+	}
+	normalized, err := h.subtopics.Apply(subtopic)
+	if err != nil {
+		return "", errors.Wrap(ErrFailedParseSubtopic, err)
+	}
+	if normalized == subtopic {
+		return topic, nil
+	}
+
+	prefix := topic[:strings.Index(topic, messagesPath)+len(messagesPath)]
+	levels = strings.Split(normalized, ".")
+	for i, level := range levels {
+		switch level {
+		case wildcardOne:
+			levels[i] = mqttWildcardOne
+		case wildcardAll:
+			levels[i] = mqttWildcardAll
+		}
+	}
+
+	return prefix + "/" + strings.Join(levels, "/") + channelParts[3], nil
+}
+
 func parseSubtopic(subtopic string) (string, error) {
 	if subtopic == "" {
 		return subtopic, nil
@@ -6,8 +6,10 @@ package mqtt_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"testing"
 
 	"github.com/absmach/magistrala"
@@ -17,10 +19,17 @@ import (
 	"github.com/absmach/magistrala/mqtt/mocks"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/magistrala/pkg/messaging"
+	pubsub "github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	rlmocks "github.com/absmach/magistrala/pkg/ratelimit/mocks"
 	thmocks "github.com/absmach/magistrala/things/mocks"
 	"github.com/absmach/mproxy/pkg/session"
+	"github.com/eclipse/paho.mqtt.golang/packets"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -120,6 +129,124 @@ func TestAuthConnect(t *testing.T) {
 	}
 }
 
+func TestAuthConnectLimit(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating logger: %s", err))
+	eventStore := newEventStore()
+	eventStore.On("Connect", mock.Anything, password).Return(nil)
+	eventStore.On("Disconnect", mock.Anything, password).Return(nil)
+
+	// Limiter allowing maxConns concurrent connections.
+	maxConns := 3
+	conns := map[string]bool{}
+	limiter := new(mocks.ConnLimiter)
+	limiter.On("Acquire", mock.Anything, password).Return(func(_ context.Context, _ string) (string, error) {
+		if len(conns) >= maxConns {
+			return "", mqtt.ErrConnLimitExceeded
+		}
+		id := fmt.Sprintf("conn-%d", len(conns))
+		conns[id] = true
+		return id, nil
+	})
+	limiter.On("Release", mock.Anything, password, mock.Anything).Return(func(_ context.Context, _, id string) error {
+		delete(conns, id)
+		return nil
+	})
+	handler := mqtt.NewHandler(mocks.NewPublisher(), eventStore, logger, new(thmocks.ThingsServiceClient), messaging.SubtopicRules{}, messaging.FlatTopics, limiter, nil, nil, messaging.SigningRules{}, nil)
+
+	var ctxs []context.Context
+	for i := 0; i < maxConns+2; i++ {
+		ctx := session.NewContext(context.TODO(), &session.Session{
+			ID:       fmt.Sprintf("%s-%d", clientID, i),
+			Username: thingID,
+			Password: []byte(password),
+		})
+		err := handler.AuthConnect(ctx)
+		switch {
+		case i < maxConns:
+			assert.Nil(t, err, fmt.Sprintf("connection %d: unexpected error %s", i, err))
+			ctxs = append(ctxs, ctx)
+		default:
+			assert.True(t, errors.Contains(err, mqtt.ErrConnLimitExceeded), fmt.Sprintf("connection %d: expected %s got %s", i, mqtt.ErrConnLimitExceeded, err))
+			// Rejected connections are not released on disconnect.
+			err = handler.Disconnect(ctx)
+			assert.Nil(t, err, fmt.Sprintf("connection %d: unexpected error on disconnect %s", i, err))
+		}
+	}
+	assert.Len(t, conns, maxConns)
+	limiter.AssertNumberOfCalls(t, "Release", 0)
+
+	err = handler.Disconnect(ctxs[0])
+	assert.Nil(t, err, fmt.Sprintf("unexpected error on disconnect %s", err))
+	assert.Len(t, conns, maxConns-1)
+
+	ctx := session.NewContext(context.TODO(), &session.Session{ID: clientID, Username: thingID, Password: []byte(password)})
+	err = handler.AuthConnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error after releasing connection %s", err))
+
+	limiter = new(mocks.ConnLimiter)
+	limiter.On("Acquire", mock.Anything, password).Return("", errors.New("limiter unavailable"))
+	handler = mqtt.NewHandler(mocks.NewPublisher(), eventStore, logger, new(thmocks.ThingsServiceClient), messaging.SubtopicRules{}, messaging.FlatTopics, limiter, nil, nil, messaging.SigningRules{}, nil)
+	err = handler.AuthConnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("expected connection to be allowed when limiter fails, got %s", err))
+}
+
+func TestAuthIPFilter(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating logger: %s", err))
+	eventStore := newEventStore()
+	eventStore.On("Connect", mock.Anything, password).Return(nil)
+	things := new(thmocks.ThingsServiceClient)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID}, nil)
+
+	ipFilter, err := ipfilter.NewStatic(ipfilter.Rules{
+		Default: ipfilter.List{Deny: []string{"203.0.113.0/24"}},
+		Things:  map[string]ipfilter.List{thingID: {Allow: []string{"192.0.2.0/24", "2001:db8::/32"}}},
+	})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating IP filter: %s", err))
+	handler := mqtt.NewHandler(mocks.NewPublisher(), eventStore, logger, things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, ipFilter, nil, messaging.SigningRules{}, nil)
+
+	cases := []struct {
+		desc       string
+		ip         string
+		connectErr error
+		publishErr error
+	}{
+		{
+			desc: "connect and publish from allowed IPv4",
+			ip:   "192.0.2.10",
+		},
+		{
+			desc: "connect and publish from allowed IPv6",
+			ip:   "2001:db8::10",
+		},
+		{
+			desc:       "connect from IP denied by default rules",
+			ip:         "203.0.113.10",
+			connectErr: ipfilter.ErrDenied,
+			publishErr: ipfilter.ErrDenied,
+		},
+		{
+			desc:       "publish from IP not allowed by thing rules",
+			ip:         "198.51.100.10",
+			publishErr: ipfilter.ErrDenied,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := session.NewContext(ipfilter.WithRemoteIP(context.TODO(), tc.ip), &sessionClient)
+			err := handler.AuthConnect(ctx)
+			assert.True(t, errors.Contains(err, tc.connectErr), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.connectErr, err))
+			err = handler.AuthPublish(ctx, &topic, &payload)
+			assert.True(t, errors.Contains(err, tc.publishErr), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.publishErr, err))
+			if tc.publishErr != nil {
+				assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, svcerr.ErrAuthorization, err))
+			}
+		})
+	}
+}
+
 func TestAuthPublish(t *testing.T) {
 	handler, things, _ := newHandler()
 
@@ -172,6 +299,20 @@ func TestAuthPublish(t *testing.T) {
 	}
 }
 
+func TestAuthPublishContentType(t *testing.T) {
+	handler, things, _ := newHandler()
+
+	ctTopic := topic + "?content_type=application/senml%2Bjson"
+	things.On("Authorize", mock.Anything, mock.MatchedBy(func(req *magistrala.ThingsAuthzReq) bool {
+		return req.GetContentType() == "application/senml+json"
+	})).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: testsutil.GenerateUUID(t)}, nil)
+
+	ctx := session.NewContext(context.TODO(), &sessionClient)
+	err := handler.AuthPublish(ctx, &ctTopic, &payload)
+	assert.Nil(t, err, fmt.Sprintf("publish with content type: unexpected error %s", err))
+	things.AssertNumberOfCalls(t, "Authorize", 1)
+}
+
 func TestAuthSubscribe(t *testing.T) {
 	handler, things, _ := newHandler()
 
@@ -211,6 +352,18 @@ func TestAuthSubscribe(t *testing.T) {
 			err:     nil,
 			topic:   &topics,
 		},
+		{
+			desc:    "subscribe to batch response topic",
+			session: &sessionClientSub,
+			err:     nil,
+			topic:   &[]string{fmt.Sprintf("channels/%s/batch/response", chanID)},
+		},
+		{
+			desc:    "subscribe to batch response topic without permission",
+			session: &sessionClient,
+			err:     svcerr.ErrAuthorization,
+			topic:   &[]string{fmt.Sprintf("channels/%s/batch/response", invalidValue)},
+		},
 	}
 
 	for _, tc := range cases {
@@ -338,6 +491,445 @@ func TestPublish(t *testing.T) {
 	}
 }
 
+func TestPublishSubtopicRules(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("failed to create logger: %s", err))
+	rules, err := messaging.NewSubtopicRules(messaging.SubtopicConfig{Lowercase: true, MaxDepth: 3, Pattern: `^[a-z0-9_-]+$`})
+	assert.Nil(t, err, fmt.Sprintf("failed to create subtopic rules: %s", err))
+
+	cases := []struct {
+		desc     string
+		topic    string
+		subtopic string
+		err      error
+	}{
+		{
+			desc:     "publish with mixed case subtopic",
+			topic:    topic + "/Temp",
+			subtopic: "temp",
+		},
+		{
+			desc:     "publish with empty subtopic levels",
+			topic:    topic + "/Temp//Sensor/",
+			subtopic: "temp.sensor",
+		},
+		{
+			desc:     "publish with subtopic at max depth",
+			topic:    topic + "/building/floor/room",
+			subtopic: "building.floor.room",
+		},
+		{
+			desc:  "publish with too deep subtopic",
+			topic: topic + "/building/floor/room/sensor",
+			err:   messaging.ErrSubtopicTooDeep,
+		},
+		{
+			desc:  "publish with subtopic not matching pattern",
+			topic: topic + "/temp/sensor$1",
+			err:   messaging.ErrSubtopicPattern,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pub := new(pubsub.PubSub)
+			handler := mqtt.NewHandler(pub, newEventStore(), logger, new(thmocks.ThingsServiceClient), rules, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, nil)
+			ctx := session.NewContext(context.TODO(), &sessionClient)
+			pub.On("Publish", mock.Anything, chanID, mock.MatchedBy(func(msg *messaging.Message) bool {
+				return msg.GetSubtopic() == tc.subtopic
+			})).Return(nil)
+			err := handler.Publish(ctx, &tc.topic, &payload)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				pub.AssertNumberOfCalls(t, "Publish", 1)
+			} else {
+				assert.True(t, errors.Contains(err, mqtt.ErrFailedParseSubtopic), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, mqtt.ErrFailedParseSubtopic, err))
+				pub.AssertNumberOfCalls(t, "Publish", 0)
+			}
+		})
+	}
+}
+
+func TestAuthSubtopicRules(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("failed to create logger: %s", err))
+	rules, err := messaging.NewSubtopicRules(messaging.SubtopicConfig{Lowercase: true, MaxDepth: 3})
+	assert.Nil(t, err, fmt.Sprintf("failed to create subtopic rules: %s", err))
+
+	cases := []struct {
+		desc     string
+		topic    string
+		expected string
+		err      error
+	}{
+		{
+			desc:     "mixed case subtopic",
+			topic:    topic + "/Temp/Sensor",
+			expected: topic + "/temp/sensor",
+		},
+		{
+			desc:     "mixed case subtopic with wildcards",
+			topic:    topic + "/Temp/+/#",
+			expected: topic + "/temp/+/#",
+		},
+		{
+			desc:     "normalized subtopic",
+			topic:    topic + "/temp",
+			expected: topic + "/temp",
+		},
+		{
+			desc:     "topic without subtopic",
+			topic:    topic,
+			expected: topic,
+		},
+		{
+			desc:  "too deep subtopic",
+			topic: topic + "/building/floor/room/sensor",
+			err:   messaging.ErrSubtopicTooDeep,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			things := new(thmocks.ThingsServiceClient)
+			things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID}, nil)
+			handler := mqtt.NewHandler(mocks.NewPublisher(), newEventStore(), logger, things, rules, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, nil)
+			ctx := session.NewContext(context.TODO(), &sessionClient)
+
+			subs := []string{tc.topic}
+			err := handler.AuthSubscribe(ctx, &subs)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.Equal(t, tc.expected, subs[0], fmt.Sprintf("%s: expected subscription %s, got %s", tc.desc, tc.expected, subs[0]))
+			}
+
+			if strings.ContainsAny(tc.topic, "+#") {
+				return
+			}
+			pub := tc.topic
+			err = handler.AuthPublish(ctx, &pub, &payload)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.Equal(t, tc.expected, pub, fmt.Sprintf("%s: expected topic %s, got %s", tc.desc, tc.expected, pub))
+			}
+		})
+	}
+}
+
+func TestPublishPriority(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("failed to create logger: %s", err))
+
+	cases := []struct {
+		desc      string
+		intercept bool
+		qos       byte
+		priority  uint32
+	}{
+		{
+			desc:      "publish with QoS 0",
+			intercept: true,
+			qos:       0,
+			priority:  messaging.PriorityLow,
+		},
+		{
+			desc:      "publish with QoS 1",
+			intercept: true,
+			qos:       1,
+			priority:  messaging.PriorityNormal,
+		},
+		{
+			desc:      "publish with QoS 2",
+			intercept: true,
+			qos:       2,
+			priority:  messaging.PriorityHigh,
+		},
+		{
+			desc:     "publish without intercepted QoS",
+			priority: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pub := new(pubsub.PubSub)
+			handler := mqtt.NewHandler(pub, newEventStore(), logger, new(thmocks.ThingsServiceClient), messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, nil)
+			ctx := session.NewContext(context.TODO(), &session.Session{ID: clientID, Username: thingID})
+			if tc.intercept {
+				pkt := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+				pkt.TopicName = topic
+				pkt.Qos = tc.qos
+				_, err := handler.(session.Interceptor).Intercept(ctx, pkt, session.Up)
+				assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error %s", tc.desc, err))
+			}
+			pub.On("Publish", mock.Anything, chanID, mock.MatchedBy(func(msg *messaging.Message) bool {
+				return msg.GetPriority() == tc.priority
+			})).Return(nil)
+			tpc := topic
+			err := handler.Publish(ctx, &tpc, &payload)
+			assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error %s", tc.desc, err))
+			pub.AssertNumberOfCalls(t, "Publish", 1)
+		})
+	}
+}
+
+func TestPublishBatch(t *testing.T) {
+	var logs bytes.Buffer
+	logger, err := mglog.New(&logs, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("failed to create logger: %s", err))
+
+	gatewayID := testsutil.GenerateUUID(t)
+	batchTopic := fmt.Sprintf("channels/%s/batch", chanID)
+	gateway := session.Session{ID: clientID, Username: gatewayID, Password: []byte(password)}
+	// The gateway represents thingID but not thingID1, and the first record
+	// doesn't identify its thing.
+	batch := []byte(fmt.Sprintf(`[{"n":"orphan","v":1},{"bn":"%s:","n":"temp","u":"Cel","v":21},{"n":"hum","v":40},{"bn":"%s:","n":"temp","v":22}]`, thingID, thingID1))
+
+	// The things service fails to check the thing failingID.
+	errUnavailable := errors.New("things service unavailable")
+	failingID := testsutil.GenerateUUID(t)
+	failing := []byte(fmt.Sprintf(`[{"bn":"%s:","n":"temp","v":21},{"bn":"%s:","n":"temp","v":22}]`, thingID, failingID))
+
+	things := new(thmocks.ThingsServiceClient)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(func(_ context.Context, req *magistrala.ThingsAuthzReq, _ ...grpc.CallOption) (*magistrala.ThingsAuthzRes, error) {
+		switch {
+		case req.GetThingKey() != password || req.GetChannelID() != chanID:
+			return &magistrala.ThingsAuthzRes{Authorized: false}, nil
+		case req.GetThingID() == "":
+			return &magistrala.ThingsAuthzRes{Authorized: true, Id: gatewayID}, nil
+		case req.GetThingID() == thingID:
+			return &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID}, nil
+		case req.GetThingID() == failingID:
+			return nil, errUnavailable
+		default:
+			return nil, svcerr.ErrAuthorization
+		}
+	})
+	pub := new(pubsub.PubSub)
+	pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+	handler := mqtt.NewHandler(pub, newEventStore(), logger, things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, nil)
+
+	ctx := session.NewContext(context.TODO(), &gateway)
+	tpc := batchTopic
+	data := batch
+	err = handler.AuthPublish(ctx, &tpc, &data)
+	assert.Nil(t, err, fmt.Sprintf("authorize batch: got unexpected error %s", err))
+	// The report is forwarded to the MQTT broker in place of the batch.
+	assert.Equal(t, fmt.Sprintf("channels/%s/batch/response", chanID), tpc, fmt.Sprintf("expected the response topic got %s", tpc))
+	var report mqtt.BatchReport
+	err = json.Unmarshal(data, &report)
+	assert.Nil(t, err, fmt.Sprintf("decode batch report: got unexpected error %s", err))
+	assert.Equal(t, mqtt.BatchReport{Total: 4, Published: 2, Dropped: 2}, report)
+	err = handler.Publish(ctx, &tpc, &data)
+	assert.Nil(t, err, fmt.Sprintf("publish batch: got unexpected error %s", err))
+
+	pub.AssertNumberOfCalls(t, "Publish", 1)
+	msg := pub.Calls[0].Arguments.Get(2).(*messaging.Message)
+	assert.Equal(t, thingID, msg.GetPublisher(), fmt.Sprintf("expected records published by %s got %s", thingID, msg.GetPublisher()))
+	assert.Equal(t, chanID, msg.GetChannel(), fmt.Sprintf("expected records published to channel %s got %s", chanID, msg.GetChannel()))
+	var records []map[string]interface{}
+	err = json.Unmarshal(msg.GetPayload(), &records)
+	assert.Nil(t, err, fmt.Sprintf("decode published records: got unexpected error %s", err))
+	var names []string
+	for _, r := range records {
+		names = append(names, r["n"].(string))
+	}
+	assert.ElementsMatch(t, []string{"temp", "hum"}, names, fmt.Sprintf("expected the records of %s without the thing ID got %v", thingID, names))
+	assert.Contains(t, logs.String(), fmt.Sprintf(mqtt.LogWarnBatchDropped, 2, 4, clientID), "expected the dropped records to be reported")
+
+	// The batch is published once.
+	err = handler.Publish(ctx, &tpc, &payload)
+	assert.NotNil(t, err, "publish to batch topic without authorized batch: expected error")
+	pub.AssertNumberOfCalls(t, "Publish", 1)
+
+	cases := []struct {
+		desc    string
+		session session.Session
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "authorize malformed batch",
+			session: gateway,
+			payload: []byte(`{"n":"temp"}`),
+			err:     mqtt.ErrMalformedBatch,
+		},
+		{
+			desc:    "authorize batch of unauthorized gateway",
+			session: session.Session{ID: clientID1, Username: thingID1, Password: []byte(password1)},
+			payload: batch,
+			err:     svcerr.ErrAuthorization,
+		},
+		{
+			desc:    "authorize batch with failing thing check",
+			session: gateway,
+			payload: failing,
+			err:     errUnavailable,
+		},
+	}
+	for _, tc := range cases {
+		ctx := session.NewContext(context.TODO(), &tc.session)
+		tpc := batchTopic
+		err := handler.AuthPublish(ctx, &tpc, &tc.payload)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, batchTopic, tpc, fmt.Sprintf("%s: expected the batch topic unchanged got %s", tc.desc, tpc))
+	}
+	pub.AssertNumberOfCalls(t, "Publish", 1)
+}
+
+func TestPublishRateLimit(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("failed to create logger: %s", err))
+	limited := session.Session{ID: clientID, Username: thingID, Password: []byte(password)}
+	other := session.Session{ID: clientID1, Username: thingID1, Password: []byte(password1)}
+
+	for _, mode := range []string{ratelimit.Reject, ratelimit.Shed} {
+		t.Run(mode, func(t *testing.T) {
+			things := new(thmocks.ThingsServiceClient)
+			things.On("Authorize", mock.Anything, mock.MatchedBy(func(req *magistrala.ThingsAuthzReq) bool {
+				return req.GetThingKey() == password
+			})).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, Rate: 1, Burst: 2}, nil)
+			things.On("Authorize", mock.Anything, mock.MatchedBy(func(req *magistrala.ThingsAuthzReq) bool {
+				return req.GetThingKey() == password1
+			})).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID1}, nil)
+
+			// The buckets are not refilled during the test, so each thing
+			// may publish the number of messages of its burst.
+			taken := map[string]uint32{}
+			limiter := new(rlmocks.Limiter)
+			limiter.On("Mode").Return(mode)
+			limiter.On("Allow", mock.Anything, thingID, ratelimit.Limit{Rate: 1, Burst: 2}).Return(func(_ context.Context, id string, limit ratelimit.Limit) error {
+				if taken[id] == limit.Burst {
+					return ratelimit.ErrRateLimited
+				}
+				taken[id]++
+				return nil
+			})
+			limiter.On("Allow", mock.Anything, thingID1, ratelimit.Limit{}).Return(nil)
+
+			pub := new(pubsub.PubSub)
+			pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			handler := mqtt.NewHandler(pub, newEventStore(), logger, things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, limiter, messaging.SigningRules{}, nil)
+
+			var overErr error
+			if mode == ratelimit.Reject {
+				overErr = ratelimit.ErrRateLimited
+			}
+			cases := []struct {
+				desc      string
+				session   *session.Session
+				err       error
+				published bool
+			}{
+				{
+					desc:      "publish first message under rate",
+					session:   &limited,
+					published: true,
+				},
+				{
+					desc:      "publish second message under rate",
+					session:   &limited,
+					published: true,
+				},
+				{
+					desc:    "publish message over rate",
+					session: &limited,
+					err:     overErr,
+				},
+				{
+					desc:      "publish message of other thing",
+					session:   &other,
+					published: true,
+				},
+				{
+					desc:    "publish message over rate again",
+					session: &limited,
+					err:     overErr,
+				},
+			}
+
+			for _, tc := range cases {
+				ctx := session.NewContext(context.TODO(), tc.session)
+				err := handler.AuthPublish(ctx, &topic, &payload)
+				assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+				if err == nil {
+					err = handler.Publish(ctx, &topic, &payload)
+					assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+				}
+				published := len(pub.Calls) > 0
+				assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t\n", tc.desc, tc.published, published))
+				pub.Calls = nil
+			}
+		})
+	}
+}
+
+func TestPublishSignature(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("failed to create logger: %s", err))
+	const signingSecret = "signing_secret"
+	sess := session.Session{ID: clientID, Username: thingID, Password: []byte(password)}
+	signed := fmt.Sprintf("%s?signature=%s", topic, messaging.Sign(signingSecret, payload))
+	signedWithKey := fmt.Sprintf("%s?signature=%s", topic, messaging.Sign(password, payload))
+
+	for _, mode := range []string{messaging.SigningReject, messaging.SigningFlag} {
+		t.Run(mode, func(t *testing.T) {
+			things := new(thmocks.ThingsServiceClient)
+			things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, SigningSecret: signingSecret}, nil)
+			signing, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: fmt.Sprintf("%s:%s", chanID, mode)})
+			assert.Nil(t, err, fmt.Sprintf("failed to create signing rules: %s", err))
+			pub := new(pubsub.PubSub)
+			pub.On("Publish", mock.Anything, chanID, mock.Anything).Return(nil)
+			handler := mqtt.NewHandler(pub, newEventStore(), logger, things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, signing, nil)
+
+			var invalidErr, missingErr error
+			if mode == messaging.SigningReject {
+				invalidErr = messaging.ErrInvalidSignature
+				missingErr = messaging.ErrMissingSignature
+			}
+			cases := []struct {
+				desc       string
+				topic      string
+				err        error
+				unverified bool
+			}{
+				{
+					desc:  "publish validly signed message",
+					topic: signed,
+				},
+				{
+					desc:       "publish message signed with thing key",
+					topic:      signedWithKey,
+					err:        invalidErr,
+					unverified: true,
+				},
+				{
+					desc:       "publish unsigned message",
+					topic:      topic,
+					err:        missingErr,
+					unverified: true,
+				},
+			}
+
+			for _, tc := range cases {
+				ctx := session.NewContext(context.TODO(), &sess)
+				tpc := tc.topic
+				err := handler.AuthPublish(ctx, &tpc, &payload)
+				assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+				if err != nil {
+					continue
+				}
+				err = handler.Publish(ctx, &tpc, &payload)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+				pub.AssertNumberOfCalls(t, "Publish", 1)
+				msg := pub.Calls[0].Arguments.Get(2).(*messaging.Message)
+				assert.Equal(t, tc.unverified, msg.GetUnverified(), fmt.Sprintf("%s: expected unverified %t got %t\n", tc.desc, tc.unverified, msg.GetUnverified()))
+				pub.Calls = nil
+			}
+		})
+	}
+}
+
 func TestSubscribe(t *testing.T) {
 	handler, _, _ := newHandler()
 	logBuffer.Reset()
@@ -450,12 +1042,82 @@ func TestDisconnect(t *testing.T) {
 	}
 }
 
+func TestConnEvents(t *testing.T) {
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating logger: %s", err))
+	otherChanID := "123e4567-e89b-12d3-a456-000000000002"
+	domainID := "123e4567-e89b-12d3-a456-000000000003"
+	things := new(thmocks.ThingsServiceClient)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, DomainId: domainID}, nil)
+	eventStore := new(mocks.EventStore)
+	eventStore.On("Connect", mock.Anything, password).Return(nil)
+	eventStore.On("OpenConn", mock.Anything, clientID).Return(nil)
+	eventStore.On("AttachConn", mock.Anything, clientID, thingID, domainID, mock.Anything).Return(nil)
+	eventStore.On("CloseConn", mock.Anything, clientID).Return(nil)
+	eventStore.On("Disconnect", mock.Anything, password).Return(nil)
+	handler := mqtt.NewHandler(mocks.NewPublisher(), eventStore, logger, things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, nil)
+
+	s := sessionClient
+	ctx := session.NewContext(context.TODO(), &s)
+	err = handler.AuthConnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("authorize connect: got unexpected error %s", err))
+	eventStore.AssertNumberOfCalls(t, "OpenConn", 1)
+	eventStore.AssertNotCalled(t, "AttachConn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	for i := 0; i < 3; i++ {
+		tpc := topic
+		err := handler.AuthPublish(ctx, &tpc, &payload)
+		assert.Nil(t, err, fmt.Sprintf("authorize publish: got unexpected error %s", err))
+	}
+	subs := []string{topic, fmt.Sprintf(topicMsg, otherChanID)}
+	err = handler.AuthSubscribe(ctx, &subs)
+	assert.Nil(t, err, fmt.Sprintf("authorize subscribe: got unexpected error %s", err))
+
+	// The connection to each channel is reported once per session.
+	eventStore.AssertNumberOfCalls(t, "AttachConn", 2)
+	eventStore.AssertCalled(t, "AttachConn", mock.Anything, clientID, thingID, domainID, chanID)
+	eventStore.AssertCalled(t, "AttachConn", mock.Anything, clientID, thingID, domainID, otherChanID)
+
+	// The connection opened anew is reported to the channels anew.
+	err = handler.AuthConnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("authorize connect: got unexpected error %s", err))
+	tpc := topic
+	err = handler.AuthPublish(ctx, &tpc, &payload)
+	assert.Nil(t, err, fmt.Sprintf("authorize publish: got unexpected error %s", err))
+	eventStore.AssertNumberOfCalls(t, "OpenConn", 2)
+	eventStore.AssertNumberOfCalls(t, "AttachConn", 3)
+
+	err = handler.Disconnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("disconnect: got unexpected error %s", err))
+	eventStore.AssertNumberOfCalls(t, "CloseConn", 1)
+
+	// The clients which never were authorized on a channel are closed too.
+	s1 := sessionClientSub
+	ctx = session.NewContext(context.TODO(), &s1)
+	eventStore.On("CloseConn", mock.Anything, s1.ID).Return(nil)
+	eventStore.On("Disconnect", mock.Anything, password1).Return(nil)
+	err = handler.Disconnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("disconnect: got unexpected error %s", err))
+	eventStore.AssertCalled(t, "CloseConn", mock.Anything, s1.ID)
+}
+
 func newHandler() (session.Handler, *thmocks.ThingsServiceClient, *mocks.EventStore) {
-	logger, err := mglog.New(&logBuffer, "debug")
+	logger, err := mglog.New(&logBuffer, "debug", mglog.JSONFormat)
 	if err != nil {
 		log.Fatalf("failed to create logger: %s", err)
 	}
 	things := new(thmocks.ThingsServiceClient)
+	eventStore := newEventStore()
+	return mqtt.NewHandler(mocks.NewPublisher(), eventStore, logger, things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, nil), things, eventStore
+}
+
+// newEventStore returns the event store accepting the connection events of
+// the connection registry.
+func newEventStore() *mocks.EventStore {
 	eventStore := new(mocks.EventStore)
-	return mqtt.NewHandler(mocks.NewPublisher(), eventStore, logger, things), things, eventStore
+	eventStore.On("OpenConn", mock.Anything, mock.Anything).Return(nil)
+	eventStore.On("AttachConn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	eventStore.On("CloseConn", mock.Anything, mock.Anything).Return(nil)
+
+	return eventStore
 }
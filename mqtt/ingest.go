@@ -0,0 +1,219 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/mproxy/pkg/session"
+	"github.com/absmach/senml"
+)
+
+// LogWarnBatchDropped is the log message format of the batch records which
+// are dropped because the gateway may not publish them.
+const LogWarnBatchDropped = "dropped %d of %d records of the batch published with client_id %s"
+
+// batchContentType is the content type of the batches and of the messages
+// published from their records.
+const batchContentType = "application/senml+json"
+
+// ErrMalformedBatch indicates that the batch is not a SenML JSON pack.
+var ErrMalformedBatch = errors.New("malformed batch")
+
+// Batches are published to the channels/<channel_id>/batch topic, with the
+// signature of the batch optionally set in the query.
+var batchRegExp = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/batch(\?.*)?$`)
+
+// The reports of the batches are forwarded to the MQTT broker on the
+// channels/<channel_id>/batch/response topic in place of the batches.
+var batchResponseRegExp = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/batch\/response$`)
+
+// BatchReport reports the records of the batch to the gateway.
+type BatchReport struct {
+	Total     int `json:"total"`
+	Published int `json:"published"`
+	Dropped   int `json:"dropped"`
+}
+
+// batchRecords are the records of a batch produced by a single thing.
+type batchRecords struct {
+	thingID string
+	count   int
+	payload []byte
+}
+
+// authorizedBatch holds the authorized records of the batch published to the channel.
+type authorizedBatch struct {
+	chanID  string
+	records []batchRecords
+}
+
+// authBatch authorizes the gateway to publish the batch to the channel, and
+// each thing of the batch to publish its records. The gateway publishes on
+// behalf of the things which name it as their gateway, and the records of the
+// things which aren't allowed to publish them are dropped. Failing to check a
+// thing fails the whole batch, so the gateway may publish it again. The batch
+// is signed by the gateway, so it's verified with the signing secret of the
+// gateway. The raw batch is never forwarded to the MQTT broker: the topic and
+// the payload are replaced with the BatchReport on the response topic of the
+// channel. Publish publishes the authorized records.
+func (h *handler) authBatch(ctx context.Context, s *session.Session, topic *string, payload *[]byte, chanID string) error {
+	var data []byte
+	if payload != nil {
+		data = *payload
+	}
+	res, err := h.authorize(ctx, &magistrala.ThingsAuthzReq{
+		Permission:  policies.PublishPermission,
+		ThingKey:    string(s.Password),
+		ChannelID:   chanID,
+		ContentType: batchContentType,
+	})
+	if err != nil {
+		return err
+	}
+	h.domains.Store(s, res.GetDomainId())
+	h.attachConn(ctx, s, chanID, res)
+	if err := h.checkSignature(ctx, s, chanID, *topic, data, res); err != nil {
+		return err
+	}
+	if err := h.checkRate(ctx, s, res); err != nil {
+		return err
+	}
+
+	recs, total, err := splitBatch(data)
+	if err != nil {
+		return errors.Wrap(ErrMalformedBatch, err)
+	}
+	report := BatchReport{Total: total, Dropped: total}
+	var authorized []batchRecords
+	if _, shed := h.shed.Load(s); !shed {
+		for _, r := range recs {
+			_, err := h.authorize(ctx, &magistrala.ThingsAuthzReq{
+				Permission:  policies.PublishPermission,
+				ThingKey:    string(s.Password),
+				ThingID:     r.thingID,
+				ChannelID:   chanID,
+				ContentType: batchContentType,
+				Payload:     r.payload,
+			})
+			switch {
+			case err == nil:
+				authorized = append(authorized, r)
+				report.Published += r.count
+				report.Dropped -= r.count
+			case !recordsDenied(err):
+				return errors.Wrap(ErrFailedPublish, err)
+			}
+		}
+		h.batches.Store(s, authorizedBatch{chanID: chanID, records: authorized})
+	}
+	if report.Dropped > 0 {
+		h.logger.Warn(fmt.Sprintf(LogWarnBatchDropped, report.Dropped, report.Total, s.ID))
+	}
+
+	resp, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(ErrFailedPublish, err)
+	}
+	*topic = "channels/" + chanID + "/batch/response"
+	if payload != nil {
+		*payload = resp
+	}
+
+	return nil
+}
+
+// recordsDenied reports whether the error denies the thing to publish its
+// records, as opposed to the failure to check the thing.
+func recordsDenied(err error) bool {
+	return errors.Contains(err, svcerr.ErrAuthorization) ||
+		errors.Contains(err, svcerr.ErrNotFound) ||
+		errors.Contains(err, errors.ErrMalformedEntity)
+}
+
+// publishBatch publishes the authorized records of the batch as the messages
+// of the things which produced them, marked as unverified if the batch has no
+// valid signature.
+func (h *handler) publishBatch(ctx context.Context, s *session.Session, b authorizedBatch, unverified bool) error {
+	var domainID string
+	if d, ok := h.domains.Load(s); ok {
+		domainID = d.(string)
+	}
+	var priority uint32
+	if qos, ok := h.qos.Load(s); ok {
+		priority = messaging.QoSPriority(qos.(byte))
+		ctx = messaging.WithQoS(ctx, qos.(byte))
+	}
+
+	for _, r := range b.records {
+		msg := messaging.Message{
+			Protocol:   protocol,
+			Channel:    b.chanID,
+			Publisher:  r.thingID,
+			Payload:    r.payload,
+			Created:    time.Now().UnixNano(),
+			Priority:   priority,
+			Unverified: unverified,
+		}
+		if err := h.publisher.Publish(ctx, h.topics.Topic(domainID, msg.GetChannel()), &msg); err != nil {
+			return errors.Wrap(ErrFailedPublishToMsgBroker, err)
+		}
+	}
+
+	return nil
+}
+
+// splitBatch splits the SenML JSON batch into the records of the things which
+// produced them, in the order of their first records. A record is produced
+// by the thing whose ID prefixes its resolved name, separated by a colon,
+// e.g. using the base name "<thing_id>:". The thing ID is removed from the
+// record names. The number of all the records is returned too, including the
+// records without the thing ID.
+func splitBatch(payload []byte) ([]batchRecords, int, error) {
+	pack, err := senml.Decode(payload, senml.JSON)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pack, err = senml.Normalize(pack); err != nil {
+		return nil, 0, err
+	}
+
+	var ids []string
+	packs := make(map[string]*senml.Pack)
+	for _, r := range pack.Records {
+		thingID, name, ok := strings.Cut(r.Name, ":")
+		if !ok || thingID == "" || name == "" {
+			continue
+		}
+		r.Name = name
+		p, ok := packs[thingID]
+		if !ok {
+			p = &senml.Pack{}
+			packs[thingID] = p
+			ids = append(ids, thingID)
+		}
+		p.Records = append(p.Records, r)
+	}
+
+	ret := make([]batchRecords, 0, len(ids))
+	for _, id := range ids {
+		data, err := senml.Encode(*packs[id], senml.JSON)
+		if err != nil {
+			return nil, 0, err
+		}
+		ret = append(ret, batchRecords{thingID: id, count: len(packs[id].Records), payload: data})
+	}
+
+	return ret, len(pack.Records), nil
+}
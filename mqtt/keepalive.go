@@ -0,0 +1,103 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/mproxy/pkg/session"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// LogWarnKeepalive is the log message format of the clients disconnected
+// because of their keepalive.
+const LogWarnKeepalive = "disconnected client_id %s: %s"
+
+var (
+	// ErrKeepaliveTimeout indicates that the client didn't send a control
+	// packet within one and a half times its keepalive.
+	ErrKeepaliveTimeout = errors.New("keepalive timeout")
+
+	// ErrKeepaliveTooLarge indicates that the client advertised keepalive
+	// larger than the maximum, or no keepalive.
+	ErrKeepaliveTooLarge = errors.New("keepalive exceeds the maximum")
+)
+
+// KeepaliveConfig defines how the keepalive of the clients is enforced.
+type KeepaliveConfig struct {
+	// Enforce reports whether the clients which don't send a control packet
+	// within one and a half times their keepalive are disconnected.
+	Enforce bool `env:"ENFORCE" envDefault:"false"`
+
+	// Max is the maximum keepalive the clients may advertise. Clients
+	// advertising larger keepalive, or no keepalive, are refused. Zero
+	// accepts any keepalive.
+	Max time.Duration `env:"MAX" envDefault:"0"`
+}
+
+// Enabled reports whether the keepalive of the clients is checked.
+func (cfg KeepaliveConfig) Enabled() bool {
+	return cfg.Enforce || cfg.Max > 0
+}
+
+type keepaliveConnKey struct{}
+
+// keepaliveConn is the client connection whose reads time out after one and
+// a half times the keepalive of the client, once it is known.
+type keepaliveConn struct {
+	net.Conn
+	timeout  atomic.Int64
+	clientID atomic.Value
+	expired  atomic.Bool
+}
+
+func (c *keepaliveConn) Read(b []byte) (int, error) {
+	if timeout := c.timeout.Load(); timeout > 0 {
+		if err := c.SetReadDeadline(time.Now().Add(time.Duration(timeout))); err != nil {
+			return 0, err
+		}
+	}
+	n, err := c.Conn.Read(b)
+	if os.IsTimeout(err) {
+		c.expired.Store(true)
+		return n, ErrKeepaliveTimeout
+	}
+
+	return n, err
+}
+
+type keepaliveInterceptor struct {
+	interceptor session.Interceptor
+	cfg         KeepaliveConfig
+	logger      *slog.Logger
+}
+
+// Intercept checks the keepalive of the connecting client, and sets the read
+// timeout of its connection from the keepalive.
+func (ki keepaliveInterceptor) Intercept(ctx context.Context, pkt packets.ControlPacket, dir session.Direction) (packets.ControlPacket, error) {
+	if p, ok := pkt.(*packets.ConnectPacket); ok && dir == session.Up {
+		keepalive := time.Duration(p.Keepalive) * time.Second
+		if ki.cfg.Max > 0 && (keepalive == 0 || keepalive > ki.cfg.Max) {
+			err := errors.Wrap(ErrKeepaliveTooLarge, fmt.Errorf("keepalive %s, maximum %s", keepalive, ki.cfg.Max))
+			ki.logger.Warn(fmt.Sprintf(LogWarnKeepalive, p.ClientIdentifier, err))
+			return nil, err
+		}
+		if c, ok := ctx.Value(keepaliveConnKey{}).(*keepaliveConn); ok && ki.cfg.Enforce {
+			c.clientID.Store(p.ClientIdentifier)
+			c.timeout.Store(int64(keepalive * 3 / 2))
+		}
+	}
+	if ki.interceptor == nil {
+		return pkt, nil
+	}
+
+	return ki.interceptor.Intercept(ctx, pkt, dir)
+}
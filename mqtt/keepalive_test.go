@@ -0,0 +1,288 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/mproxy"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// sessionHandler accepts all the clients and records their disconnects.
+type sessionHandler struct {
+	disconnected chan struct{}
+}
+
+func (sh sessionHandler) AuthConnect(context.Context) error                   { return nil }
+func (sh sessionHandler) AuthPublish(context.Context, *string, *[]byte) error { return nil }
+func (sh sessionHandler) AuthSubscribe(context.Context, *[]string) error      { return nil }
+func (sh sessionHandler) Connect(context.Context) error                       { return nil }
+func (sh sessionHandler) Publish(context.Context, *string, *[]byte) error     { return nil }
+func (sh sessionHandler) Subscribe(context.Context, *[]string) error          { return nil }
+func (sh sessionHandler) Unsubscribe(context.Context, *[]string) error        { return nil }
+
+func (sh sessionHandler) Disconnect(context.Context) error {
+	sh.disconnected <- struct{}{}
+	return nil
+}
+
+// startBroker starts the broker which acknowledges the connects and the
+// pings, and returns its address.
+func startBroker(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err, fmt.Sprintf("start broker: unexpected error %s", err))
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				for {
+					pkt, err := packets.ReadPacket(conn)
+					if err != nil {
+						return
+					}
+					switch pkt.(type) {
+					case *packets.ConnectPacket:
+						err = packets.NewControlPacket(packets.Connack).Write(conn)
+					case *packets.PingreqPacket:
+						err = packets.NewControlPacket(packets.Pingresp).Write(conn)
+					}
+					if err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+// clientConn is the client connection to the proxy.
+type clientConn interface {
+	io.ReadWriter
+	SetReadDeadline(t time.Time) error
+}
+
+// wsClientConn is the MQTT over WebSocket client connection, with the MQTT
+// packets carried in binary messages.
+type wsClientConn struct {
+	*websocket.Conn
+	r io.Reader
+}
+
+func (c *wsClientConn) Read(p []byte) (int, error) {
+	for {
+		if c.r == nil {
+			var err error
+			if _, c.r, err = c.NextReader(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := c.r.Read(p)
+		if err == io.EOF {
+			c.r = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
+
+func (c *wsClientConn) Write(p []byte) (int, error) {
+	if err := c.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// transport starts the proxy to the broker and dials it.
+type transport struct {
+	name  string
+	start func(t *testing.T, cfg mqtt.KeepaliveConfig, sh sessionHandler) string
+	dial  func(t *testing.T, addr string) clientConn
+}
+
+var transports = []transport{
+	{
+		name: "mqtt",
+		start: func(t *testing.T, cfg mqtt.KeepaliveConfig, sh sessionHandler) string {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.Nil(t, err, fmt.Sprintf("start proxy: unexpected error %s", err))
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			proxy := mqtt.NewProxy(mproxy.Config{Target: startBroker(t)}, sh, nil, cfg, mglog.NewMock())
+			go proxy.Serve(ctx, l)
+
+			return l.Addr().String()
+		},
+		dial: func(t *testing.T, addr string) clientConn {
+			conn, err := net.Dial("tcp", addr)
+			assert.Nil(t, err, fmt.Sprintf("dial proxy: unexpected error %s", err))
+			t.Cleanup(func() { conn.Close() })
+
+			return conn
+		},
+	},
+	{
+		name: "websocket",
+		start: func(t *testing.T, cfg mqtt.KeepaliveConfig, sh sessionHandler) string {
+			proxy := mqtt.NewWSProxy(mproxy.Config{Target: startWSBroker(t), PathPrefix: "/mqtt"}, sh, nil, cfg, mglog.NewMock())
+			srv := httptest.NewServer(proxy)
+			t.Cleanup(srv.Close)
+
+			return "ws" + strings.TrimPrefix(srv.URL, "http") + "/mqtt"
+		},
+		dial: func(t *testing.T, addr string) clientConn {
+			dialer := websocket.Dialer{Subprotocols: []string{"mqtt"}}
+			conn, _, err := dialer.Dial(addr, nil)
+			assert.Nil(t, err, fmt.Sprintf("dial proxy: unexpected error %s", err))
+			t.Cleanup(func() { conn.Close() })
+
+			return &wsClientConn{Conn: conn}
+		},
+	},
+}
+
+// connect connects the client with the keepalive in seconds and returns the
+// connection and the error of reading the CONNACK.
+func connect(t *testing.T, tr transport, addr string, keepalive uint16) (clientConn, error) {
+	conn := tr.dial(t, addr)
+	pkt := packets.NewControlPacket(packets.Connect).(*packets.ConnectPacket)
+	pkt.ProtocolName = "MQTT"
+	pkt.ProtocolVersion = 4
+	pkt.ClientIdentifier = clientID
+	pkt.Keepalive = keepalive
+	err := pkt.Write(conn)
+	assert.Nil(t, err, fmt.Sprintf("send connect: unexpected error %s", err))
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = packets.ReadPacket(conn)
+
+	return conn, err
+}
+
+func TestKeepaliveSilentClient(t *testing.T) {
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			sh := sessionHandler{disconnected: make(chan struct{}, 1)}
+			addr := tr.start(t, mqtt.KeepaliveConfig{Enforce: true}, sh)
+
+			conn, err := connect(t, tr, addr, 1)
+			assert.Nil(t, err, fmt.Sprintf("connect: unexpected error %s", err))
+			start := time.Now()
+
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			_, err = packets.ReadPacket(conn)
+			elapsed := time.Since(start)
+			assert.NotNil(t, err, "expected the silent client to be disconnected")
+			assert.False(t, isTimeout(err), "expected the silent client to be disconnected by the proxy")
+			assert.GreaterOrEqual(t, elapsed, 1400*time.Millisecond, fmt.Sprintf("expected the client to be disconnected after 1.5 times the keepalive, got %s", elapsed))
+			assert.Less(t, elapsed, 3*time.Second, fmt.Sprintf("expected the client to be disconnected after 1.5 times the keepalive, got %s", elapsed))
+
+			select {
+			case <-sh.disconnected:
+			case <-time.After(time.Second):
+				t.Fatal("expected the session of the silent client to be disconnected")
+			}
+		})
+	}
+}
+
+func TestKeepaliveActiveClient(t *testing.T) {
+	for _, tr := range transports {
+		t.Run(tr.name, func(t *testing.T) {
+			sh := sessionHandler{disconnected: make(chan struct{}, 1)}
+			addr := tr.start(t, mqtt.KeepaliveConfig{Enforce: true}, sh)
+
+			conn, err := connect(t, tr, addr, 1)
+			assert.Nil(t, err, fmt.Sprintf("connect: unexpected error %s", err))
+
+			// The client pings within its keepalive for longer than the
+			// keepalive window, so it stays connected.
+			for i := 0; i < 4; i++ {
+				time.Sleep(500 * time.Millisecond)
+				err := packets.NewControlPacket(packets.Pingreq).Write(conn)
+				assert.Nil(t, err, fmt.Sprintf("ping %d: unexpected error %s", i, err))
+				_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+				pkt, err := packets.ReadPacket(conn)
+				assert.Nil(t, err, fmt.Sprintf("ping %d: expected the active client to stay connected, got %s", i, err))
+				assert.IsType(t, &packets.PingrespPacket{}, pkt, fmt.Sprintf("ping %d: expected ping response", i))
+			}
+
+			select {
+			case <-sh.disconnected:
+				t.Fatal("expected the session of the active client not to be disconnected")
+			default:
+			}
+		})
+	}
+}
+
+func TestKeepaliveMax(t *testing.T) {
+	cases := []struct {
+		desc      string
+		keepalive uint16
+		refused   bool
+	}{
+		{
+			desc:      "connect with keepalive under the maximum",
+			keepalive: 30,
+		},
+		{
+			desc:      "connect with keepalive equal to the maximum",
+			keepalive: 60,
+		},
+		{
+			desc:      "connect with keepalive over the maximum",
+			keepalive: 120,
+			refused:   true,
+		},
+		{
+			desc:      "connect without keepalive",
+			keepalive: 0,
+			refused:   true,
+		},
+	}
+
+	for _, tr := range transports {
+		for _, tc := range cases {
+			t.Run(tr.name+" "+tc.desc, func(t *testing.T) {
+				sh := sessionHandler{disconnected: make(chan struct{}, 1)}
+				addr := tr.start(t, mqtt.KeepaliveConfig{Max: time.Minute}, sh)
+
+				_, err := connect(t, tr, addr, tc.keepalive)
+				assert.Equal(t, tc.refused, err != nil, fmt.Sprintf("%s: expected refused %t got error %v", tc.desc, tc.refused, err))
+				if tc.refused {
+					assert.False(t, isTimeout(err), fmt.Sprintf("%s: expected the client to be disconnected by the proxy", tc.desc))
+				}
+			})
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/go-kit/kit/metrics"
+)
+
+// ErrConnLimitExceeded indicates that the thing reached the maximum number
+// of concurrent connections. It corresponds to the MQTT "Quota exceeded" reason.
+var ErrConnLimitExceeded = errors.New("quota exceeded: maximum number of concurrent connections reached")
+
+// ConnLimiter tracks concurrent connections per thing.
+//
+//go:generate mockery --name ConnLimiter --output=./mocks --filename limiter.go --quiet --note "Copyright (c) Abstract Machines"
+type ConnLimiter interface {
+	// Acquire registers a new connection of the thing identified by the key
+	// and returns the connection ID. If the thing reached the maximum number
+	// of connections, ErrConnLimitExceeded is returned.
+	Acquire(ctx context.Context, thingKey string) (string, error)
+
+	// Release removes the connection of the thing identified by the key.
+	Release(ctx context.Context, thingKey, connID string) error
+}
+
+var _ ConnLimiter = (*metricsLimiter)(nil)
+
+type metricsLimiter struct {
+	limiter  ConnLimiter
+	rejected metrics.Counter
+}
+
+// NewMetricsLimiter returns connection limiter which counts rejected connections.
+func NewMetricsLimiter(limiter ConnLimiter, rejected metrics.Counter) ConnLimiter {
+	return &metricsLimiter{
+		limiter:  limiter,
+		rejected: rejected,
+	}
+}
+
+func (ml *metricsLimiter) Acquire(ctx context.Context, thingKey string) (string, error) {
+	connID, err := ml.limiter.Acquire(ctx, thingKey)
+	if errors.Contains(err, ErrConnLimitExceeded) {
+		ml.rejected.Add(1)
+	}
+
+	return connID, err
+}
+
+func (ml *metricsLimiter) Release(ctx context.Context, thingKey, connID string) error {
+	return ml.limiter.Release(ctx, thingKey, connID)
+}
@@ -15,6 +15,42 @@ type EventStore struct {
 	mock.Mock
 }
 
+// AttachConn provides a mock function with given fields: ctx, clientID, thingID, domainID, channelID
+func (_m *EventStore) AttachConn(ctx context.Context, clientID string, thingID string, domainID string, channelID string) error {
+	ret := _m.Called(ctx, clientID, thingID, domainID, channelID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AttachConn")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, clientID, thingID, domainID, channelID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CloseConn provides a mock function with given fields: ctx, clientID
+func (_m *EventStore) CloseConn(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloseConn")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Connect provides a mock function with given fields: ctx, clientID
 func (_m *EventStore) Connect(ctx context.Context, clientID string) error {
 	ret := _m.Called(ctx, clientID)
@@ -51,6 +87,60 @@ func (_m *EventStore) Disconnect(ctx context.Context, clientID string) error {
 	return r0
 }
 
+// Heartbeat provides a mock function with given fields: ctx
+func (_m *EventStore) Heartbeat(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Heartbeat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OpenConn provides a mock function with given fields: ctx, clientID
+func (_m *EventStore) OpenConn(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenConn")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetConns provides a mock function with given fields: ctx
+func (_m *EventStore) ResetConns(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetConns")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewEventStore creates a new instance of EventStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewEventStore(t interface {
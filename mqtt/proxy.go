@@ -0,0 +1,109 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/mproxy"
+	"github.com/absmach/mproxy/pkg/session"
+	mptls "github.com/absmach/mproxy/pkg/tls"
+)
+
+// Proxy is the MQTT proxy which passes the remote IP address of the clients
+// to the session handler, see ipfilter.RemoteIP, and enforces the keepalive
+// of the clients. Otherwise it proxies the clients to the broker as the
+// mproxy MQTT proxy, which doesn't expose the client connections. The clients
+// are disconnected by closing their connections, as MQTT 3.1.1 defines no
+// reason codes.
+type Proxy struct {
+	config      mproxy.Config
+	handler     session.Handler
+	interceptor session.Interceptor
+	logger      *slog.Logger
+}
+
+// NewProxy returns the MQTT proxy of the clients to the configured target.
+func NewProxy(config mproxy.Config, handler session.Handler, interceptor session.Interceptor, cfg KeepaliveConfig, logger *slog.Logger) *Proxy {
+	return &Proxy{
+		config:      config,
+		handler:     handler,
+		interceptor: keepaliveInterceptor{interceptor: interceptor, cfg: cfg, logger: logger},
+		logger:      logger,
+	}
+}
+
+// Listen listens on the configured address and serves the clients until the
+// context is canceled.
+func (p *Proxy) Listen(ctx context.Context) error {
+	l, err := net.Listen("tcp", p.config.Address)
+	if err != nil {
+		return err
+	}
+	if p.config.TLSConfig != nil {
+		l = tls.NewListener(l, p.config.TLSConfig)
+	}
+
+	return p.Serve(ctx, l)
+}
+
+// Serve serves the clients accepted by the listener until the context is
+// canceled, and closes the listener.
+func (p *Proxy) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	p.logger.Info(fmt.Sprintf("MQTT proxy server started at %s with %s", l.Addr(), mptls.SecurityStatus(p.config.TLSConfig)))
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			p.logger.Warn("Accept error " + err.Error())
+			continue
+		}
+		go p.handle(ctx, &keepaliveConn{Conn: conn})
+	}
+}
+
+func (p *Proxy) handle(ctx context.Context, inbound *keepaliveConn) {
+	defer p.close(inbound)
+	outbound, err := net.Dial("tcp", p.config.Target)
+	if err != nil {
+		p.logger.Error("Cannot connect to remote broker " + p.config.Target + " due to: " + err.Error())
+		return
+	}
+	defer p.close(outbound)
+
+	clientCert, err := mptls.ClientCert(inbound.Conn)
+	if err != nil {
+		p.logger.Error("Failed to get client certificate: " + err.Error())
+		return
+	}
+
+	ctx = context.WithValue(ctx, keepaliveConnKey{}, inbound)
+	ctx = ipfilter.WithRemoteIP(ctx, ipfilter.AddrIP(inbound.RemoteAddr().String()))
+	ctx = withClientConn(ctx, inbound)
+	err = session.Stream(ctx, inbound, outbound, p.handler, p.interceptor, clientCert)
+	switch {
+	case inbound.expired.Load():
+		p.logger.Warn(fmt.Sprintf(LogWarnKeepalive, inbound.clientID.Load(), ErrKeepaliveTimeout))
+	case err != io.EOF:
+		p.logger.Warn(err.Error())
+	}
+}
+
+func (p *Proxy) close(conn net.Conn) {
+	if err := conn.Close(); err != nil {
+		p.logger.Warn(fmt.Sprintf("Error closing connection %s", err.Error()))
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/mproxy"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ipHandler records the remote IP address the client connects from.
+type ipHandler struct {
+	sessionHandler
+	ips chan string
+}
+
+func (ih ipHandler) AuthConnect(ctx context.Context) error {
+	ih.ips <- ipfilter.RemoteIP(ctx)
+	return nil
+}
+
+// startWSBroker starts the MQTT over WebSocket broker which acknowledges the
+// connects and the pings, and returns its URL.
+func startWSBroker(t *testing.T) string {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"mqtt"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			pkt, err := packets.ReadPacket(bytes.NewReader(data))
+			if err != nil {
+				return
+			}
+			var resp packets.ControlPacket
+			switch pkt.(type) {
+			case *packets.ConnectPacket:
+				resp = packets.NewControlPacket(packets.Connack)
+			case *packets.PingreqPacket:
+				resp = packets.NewControlPacket(packets.Pingresp)
+			default:
+				continue
+			}
+			w, err := conn.NextWriter(websocket.BinaryMessage)
+			if err != nil {
+				return
+			}
+			if err := resp.Write(w); err != nil {
+				return
+			}
+			w.Close()
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/mqtt"
+}
+
+func connectPacket() *packets.ConnectPacket {
+	pkt := packets.NewControlPacket(packets.Connect).(*packets.ConnectPacket)
+	pkt.ProtocolName = "MQTT"
+	pkt.ProtocolVersion = 4
+	pkt.ClientIdentifier = clientID
+	pkt.Keepalive = 30
+
+	return pkt
+}
+
+func TestProxyRemoteIP(t *testing.T) {
+	ih := ipHandler{sessionHandler: sessionHandler{disconnected: make(chan struct{}, 1)}, ips: make(chan string, 1)}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, fmt.Sprintf("start proxy: unexpected error %s", err))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	proxy := mqtt.NewProxy(mproxy.Config{Target: startBroker(t)}, ih, nil, mqtt.KeepaliveConfig{}, mglog.NewMock())
+	go proxy.Serve(ctx, l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.Nil(t, err, fmt.Sprintf("dial proxy: unexpected error %s", err))
+	defer conn.Close()
+	err = connectPacket().Write(conn)
+	assert.Nil(t, err, fmt.Sprintf("send connect: unexpected error %s", err))
+
+	select {
+	case ip := <-ih.ips:
+		assert.Equal(t, "127.0.0.1", ip, fmt.Sprintf("expected remote IP 127.0.0.1 got %s", ip))
+	case <-time.After(time.Second):
+		t.Fatal("expected the client to be authorized")
+	}
+}
+
+func TestWSProxyRemoteIP(t *testing.T) {
+	ih := ipHandler{sessionHandler: sessionHandler{disconnected: make(chan struct{}, 1)}, ips: make(chan string, 1)}
+	proxy := mqtt.NewWSProxy(mproxy.Config{Target: startWSBroker(t), PathPrefix: "/mqtt"}, ih, nil, mqtt.KeepaliveConfig{}, mglog.NewMock())
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"mqtt"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/mqtt", nil)
+	require.Nil(t, err, fmt.Sprintf("dial proxy: unexpected error %s", err))
+	defer conn.Close()
+	w, err := conn.NextWriter(websocket.BinaryMessage)
+	require.Nil(t, err, fmt.Sprintf("send connect: unexpected error %s", err))
+	err = connectPacket().Write(w)
+	assert.Nil(t, err, fmt.Sprintf("send connect: unexpected error %s", err))
+	w.Close()
+
+	select {
+	case ip := <-ih.ips:
+		assert.Equal(t, "127.0.0.1", ip, fmt.Sprintf("expected remote IP 127.0.0.1 got %s", ip))
+	case <-time.After(time.Second):
+		t.Fatal("expected the client to be authorized")
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := conn.ReadMessage()
+	assert.Nil(t, err, fmt.Sprintf("read connack: unexpected error %s", err))
+	pkt, err := packets.ReadPacket(bytes.NewReader(data))
+	assert.Nil(t, err, fmt.Sprintf("read connack: unexpected error %s", err))
+	assert.IsType(t, &packets.ConnackPacket{}, pkt, "expected the broker connack to be proxied")
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/absmach/mproxy/pkg/session"
+)
+
+type clientConnKey struct{}
+
+// withClientConn returns a copy of the context carrying the client
+// connection of the session, so the handler can register it once the thing
+// of the session is authorized.
+func withClientConn(ctx context.Context, conn io.Closer) context.Context {
+	return context.WithValue(ctx, clientConnKey{}, conn)
+}
+
+// Sessions tracks the client connections of the authorized things, so they
+// can be closed once the key the things connected with is rotated. The nil
+// value tracks no connections.
+type Sessions struct {
+	mu     sync.Mutex
+	things map[string]map[*session.Session]io.Closer
+	owners map[*session.Session]string
+}
+
+// NewSessions returns the empty sessions registry.
+func NewSessions() *Sessions {
+	return &Sessions{
+		things: make(map[string]map[*session.Session]io.Closer),
+		owners: make(map[*session.Session]string),
+	}
+}
+
+// add registers the client connection carried by the context as the
+// connection of the thing.
+func (s *Sessions) add(ctx context.Context, sess *session.Session, thingID string) {
+	if s == nil {
+		return
+	}
+	conn, ok := ctx.Value(clientConnKey{}).(io.Closer)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.owners[sess]; ok {
+		return
+	}
+	if s.things[thingID] == nil {
+		s.things[thingID] = make(map[*session.Session]io.Closer)
+	}
+	s.things[thingID][sess] = conn
+	s.owners[sess] = thingID
+}
+
+// remove removes the connection of the session.
+func (s *Sessions) remove(sess *session.Session) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thingID, ok := s.owners[sess]
+	if !ok {
+		return
+	}
+	delete(s.owners, sess)
+	delete(s.things[thingID], sess)
+	if len(s.things[thingID]) == 0 {
+		delete(s.things, thingID)
+	}
+}
+
+// Close closes the client connections of the thing and returns their number.
+// The sessions are removed once the proxy disconnects them.
+func (s *Sessions) Close(thingID string) int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	conns := make([]io.Closer, 0, len(s.things[thingID]))
+	for _, conn := range s.things[thingID] {
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+
+	return len(conns)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/mqtt"
+	"github.com/absmach/magistrala/mqtt/mocks"
+	"github.com/absmach/magistrala/pkg/messaging"
+	thmocks "github.com/absmach/magistrala/things/mocks"
+	"github.com/absmach/mproxy"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionsClose(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID}, nil)
+	eventStore := newEventStore()
+	eventStore.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	eventStore.On("Disconnect", mock.Anything, mock.Anything).Return(nil)
+	sessions := mqtt.NewSessions()
+	h := mqtt.NewHandler(mocks.NewPublisher(), eventStore, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, sessions)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, fmt.Sprintf("start proxy: unexpected error %s", err))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	proxy := mqtt.NewProxy(mproxy.Config{Target: startBroker(t)}, h, nil, mqtt.KeepaliveConfig{}, mglog.NewMock())
+	go proxy.Serve(ctx, l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.Nil(t, err, fmt.Sprintf("dial proxy: unexpected error %s", err))
+	defer conn.Close()
+	pkt := connectPacket()
+	pkt.UsernameFlag, pkt.Username = true, thingID
+	pkt.PasswordFlag, pkt.Password = true, []byte(password)
+	err = pkt.Write(conn)
+	require.Nil(t, err, fmt.Sprintf("send connect: unexpected error %s", err))
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = packets.ReadPacket(conn)
+	require.Nil(t, err, fmt.Sprintf("read connack: unexpected error %s", err))
+
+	// The session is not registered before the thing is authorized on a
+	// channel.
+	assert.Equal(t, 0, sessions.Close(thingID), "expected no sessions of the connected thing")
+
+	sub := packets.NewControlPacket(packets.Subscribe).(*packets.SubscribePacket)
+	sub.MessageID = 1
+	sub.Topics = []string{fmt.Sprintf("channels/%s/messages", chanID)}
+	sub.Qoss = []byte{0}
+	err = sub.Write(conn)
+	require.Nil(t, err, fmt.Sprintf("send subscribe: unexpected error %s", err))
+
+	closed := 0
+	for deadline := time.Now().Add(time.Second); closed == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		closed = sessions.Close(thingID)
+	}
+	assert.Equal(t, 1, closed, "expected the session of the authorized thing to be closed")
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = packets.ReadPacket(conn)
+	assert.NotNil(t, err, "expected the client to be disconnected")
+	assert.False(t, isTimeout(err), "expected the client to be disconnected by the proxy")
+
+	deadline := time.Now().Add(time.Second)
+	for sessions.Close(thingID) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, sessions.Close(thingID), "expected the closed session to be removed")
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/mproxy"
+	"github.com/absmach/mproxy/pkg/session"
+	mptls "github.com/absmach/mproxy/pkg/tls"
+	"github.com/gorilla/websocket"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	HandshakeTimeout: 10 * time.Second,
+	// Paho JS client expects the mqtt subprotocol in the upgrade response.
+	Subprotocols: []string{"mqttv3.1", "mqtt"},
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// WSProxy is the MQTT over WebSocket proxy which passes the remote IP address
+// of the clients to the session handler, see ipfilter.RemoteIP, and enforces
+// the keepalive of the clients as the Proxy. Otherwise it proxies the clients
+// to the broker as the mproxy MQTT over WebSocket proxy, which doesn't expose
+// the client connections.
+type WSProxy struct {
+	config      mproxy.Config
+	handler     session.Handler
+	interceptor session.Interceptor
+	logger      *slog.Logger
+}
+
+// NewWSProxy returns the MQTT over WebSocket proxy of the clients to the
+// configured target.
+func NewWSProxy(config mproxy.Config, handler session.Handler, interceptor session.Interceptor, cfg KeepaliveConfig, logger *slog.Logger) *WSProxy {
+	return &WSProxy{
+		config:      config,
+		handler:     handler,
+		interceptor: keepaliveInterceptor{interceptor: interceptor, cfg: cfg, logger: logger},
+		logger:      logger,
+	}
+}
+
+func (p *WSProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.config.PathPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		p.logger.Error("Error upgrading connection", slog.Any("error", err))
+		return
+	}
+
+	go p.pass(conn, ipfilter.AddrIP(r.RemoteAddr))
+}
+
+func (p *WSProxy) pass(in *websocket.Conn, ip string) {
+	defer in.Close()
+	// The context of the request is done once the connection is upgraded.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dialer := &websocket.Dialer{Subprotocols: []string{"mqtt"}}
+	out, _, err := dialer.Dial(p.config.Target, nil)
+	if err != nil {
+		p.logger.Error("Unable to connect to broker", slog.Any("error", err))
+		return
+	}
+	defer out.Close()
+
+	clientCert, err := mptls.ClientCert(in.UnderlyingConn())
+	if err != nil {
+		p.logger.Error("Failed to get client certificate", slog.Any("error", err))
+		return
+	}
+
+	inbound := &keepaliveConn{Conn: newWSConn(in)}
+	ctx = context.WithValue(ctx, keepaliveConnKey{}, inbound)
+	ctx = ipfilter.WithRemoteIP(ctx, ip)
+	ctx = withClientConn(ctx, in)
+	err = session.Stream(ctx, inbound, newWSConn(out), p.handler, p.interceptor, clientCert)
+	switch {
+	case inbound.expired.Load():
+		p.logger.Warn(fmt.Sprintf(LogWarnKeepalive, inbound.clientID.Load(), ErrKeepaliveTimeout))
+	case err != io.EOF:
+		p.logger.Warn("Broken connection for client", slog.Any("error", err))
+	}
+}
+
+// Listen listens on the configured address and serves the clients until the
+// context is canceled.
+func (p *WSProxy) Listen(ctx context.Context) error {
+	l, err := net.Listen("tcp", p.config.Address)
+	if err != nil {
+		return err
+	}
+	if p.config.TLSConfig != nil {
+		l = tls.NewListener(l, p.config.TLSConfig)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(p.config.PathPrefix, p)
+	server := http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	p.logger.Info(fmt.Sprintf("MQTT websocket proxy server started at %s%s with %s", p.config.Address, p.config.PathPrefix, mptls.SecurityStatus(p.config.TLSConfig)))
+	if err := server.Serve(l); err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+// wsConn is the WebSocket connection read and written as a stream of bytes,
+// with the MQTT packets carried in binary messages.
+type wsConn struct {
+	*websocket.Conn
+	r   io.Reader
+	rmu sync.Mutex
+	wmu sync.Mutex
+}
+
+func newWSConn(ws *websocket.Conn) net.Conn {
+	return &wsConn{Conn: ws}
+}
+
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+
+	return c.SetWriteDeadline(t)
+}
+
+func (c *wsConn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if err := c.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (c *wsConn) Read(p []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	for {
+		if c.r == nil {
+			var err error
+			if _, c.r, err = c.NextReader(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := c.r.Read(p)
+		if err == io.EOF {
+			// The message is read, continue with the next one.
+			c.r = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+
+		return n, err
+	}
+}
@@ -18,6 +18,9 @@ var (
 	// ErrBearerKey indicates missing or invalid bearer entity key.
 	ErrBearerKey = errors.New("missing or invalid bearer entity key")
 
+	// ErrMultipleCredentials indicates that both bearer token and client certificate are provided.
+	ErrMultipleCredentials = errors.New("bearer token and client certificate are mutually exclusive")
+
 	// ErrMissingID indicates missing entity ID.
 	ErrMissingID = errors.New("missing entity id")
 
@@ -30,6 +33,15 @@ var (
 	// ErrNameSize indicates that name size exceeds the max.
 	ErrNameSize = errors.New("invalid name size")
 
+	// ErrMaxTags indicates that the number of tags exceeds the max.
+	ErrMaxTags = errors.New("number of tags exceeds the maximum allowed")
+
+	// ErrTagSize indicates that tag size exceeds the max.
+	ErrTagSize = errors.New("tag length exceeds the maximum allowed")
+
+	// ErrMetadataSize indicates that metadata size exceeds the max.
+	ErrMetadataSize = errors.New("metadata size exceeds the maximum allowed")
+
 	// ErrEmailSize indicates that email size exceeds the max.
 	ErrEmailSize = errors.New("invalid email size")
 
@@ -90,9 +102,15 @@ var (
 	// ErrMissingHost indicates missing host.
 	ErrMissingHost = errors.New("missing host")
 
+	// ErrMissingCode indicates missing verification code.
+	ErrMissingCode = errors.New("missing verification code")
+
 	// ErrMissingPass indicates missing password.
 	ErrMissingPass = errors.New("missing password")
 
+	// ErrMissingMFACode indicates missing multi-factor authentication code.
+	ErrMissingMFACode = errors.New("missing multi-factor authentication code")
+
 	// ErrMissingConfPass indicates missing conf password.
 	ErrMissingConfPass = errors.New("missing conf password")
 
@@ -102,6 +120,9 @@ var (
 	// ErrInvalidComparator indicates an invalid comparator.
 	ErrInvalidComparator = errors.New("invalid comparator")
 
+	// ErrInvalidGeolocation indicates an invalid bounding box or radius.
+	ErrInvalidGeolocation = errors.New("invalid geolocation filter")
+
 	// ErrMissingMemberType indicates missing group member type.
 	ErrMissingMemberType = errors.New("missing group member type")
 
@@ -153,6 +174,9 @@ var (
 	// ErrUnsupportedContentType indicates unacceptable or lack of Content-Type.
 	ErrUnsupportedContentType = errors.New("unsupported content type")
 
+	// ErrNotAcceptable indicates that none of the types in the Accept header is supported.
+	ErrNotAcceptable = errors.New("none of the accepted content types is supported")
+
 	// ErrRollbackTx indicates failed to rollback transaction.
 	ErrRollbackTx = errors.New("failed to rollback transaction")
 
@@ -188,4 +212,13 @@ var (
 
 	// ErrMissingDomainID indicates missing domainID.
 	ErrMissingDomainID = errors.New("missing domainID")
+
+	// ErrTooManyRequests indicates that the request rate limit is exceeded.
+	ErrTooManyRequests = errors.New("too many requests")
+
+	// ErrRequestTooLarge indicates that the request body exceeds the size limit.
+	ErrRequestTooLarge = errors.New("request body too large")
+
+	// ErrRequestTimeout indicates that the request took longer than its timeout.
+	ErrRequestTimeout = errors.New("request timed out")
 )
@@ -5,6 +5,7 @@ package authn
 
 import (
 	"context"
+	"crypto/x509"
 )
 
 type Session struct {
@@ -12,6 +13,7 @@ type Session struct {
 	UserID       string
 	DomainID     string
 	SuperAdmin   bool
+	Claims       map[string]string // custom claims of the token
 }
 
 // Authn is magistrala authentication library.
@@ -20,3 +22,10 @@ type Session struct {
 type Authentication interface {
 	Authenticate(ctx context.Context, token string) (Session, error)
 }
+
+// CertAuthentication authenticates requests presenting a verified TLS client
+// certificate. Authentication implementations supporting client certificates
+// implement it as well.
+type CertAuthentication interface {
+	AuthenticateCert(ctx context.Context, cert *x509.Certificate) (Session, error)
+}
@@ -16,12 +16,18 @@ import (
 
 type authentication struct {
 	authSvcClient magistrala.AuthServiceClient
+	audience      string
 }
 
 var _ authn.Authentication = (*authentication)(nil)
 
-func NewAuthentication(ctx context.Context, cfg grpcclient.Config) (authn.Authentication, grpcclient.Handler, error) {
-	client, err := grpcclient.NewHandler(cfg)
+// NewAuthentication returns authentication backed by the auth service. If the
+// audience is not empty, tokens issued for other audiences are rejected.
+// Tokens bound to a client are accepted only if the context carries the
+// fingerprint of the client, as set by authn.WithBinding.
+func NewAuthentication(ctx context.Context, cfg grpcclient.Config, audience string, opts ...grpcclient.Option) (authn.Authentication, grpcclient.Handler, error) {
+	opts = append(opts, grpcclient.WithIdempotentMethods(magistrala.AuthService_Authenticate_FullMethodName))
+	client, err := grpcclient.NewHandler(cfg, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -34,13 +40,13 @@ func NewAuthentication(ctx context.Context, cfg grpcclient.Config) (authn.Authen
 		return nil, nil, grpcclient.ErrSvcNotServing
 	}
 	authSvcClient := auth.NewAuthClient(client.Connection(), cfg.Timeout)
-	return authentication{authSvcClient}, client, nil
+	return authentication{authSvcClient: authSvcClient, audience: audience}, client, nil
 }
 
 func (a authentication) Authenticate(ctx context.Context, token string) (authn.Session, error) {
-	res, err := a.authSvcClient.Authenticate(ctx, &magistrala.AuthNReq{Token: token})
+	res, err := a.authSvcClient.Authenticate(ctx, &magistrala.AuthNReq{Token: token, Audience: a.audience, Binding: authn.Binding(ctx)})
 	if err != nil {
 		return authn.Session{}, errors.Wrap(errors.ErrAuthentication, err)
 	}
-	return authn.Session{DomainUserID: res.GetId(), UserID: res.GetUserId(), DomainID: res.GetDomainId()}, nil
+	return authn.Session{DomainUserID: res.GetId(), UserID: res.GetUserId(), DomainID: res.GetDomainId(), Claims: res.GetClaims()}, nil
 }
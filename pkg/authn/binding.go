@@ -0,0 +1,22 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package authn
+
+import "context"
+
+type bindingKey struct{}
+
+// WithBinding returns a copy of the context carrying the verified fingerprint
+// of the client presenting the request. Tokens issued within the context are
+// bound to the client, and bound tokens are accepted only within a context
+// carrying the same fingerprint.
+func WithBinding(ctx context.Context, binding string) context.Context {
+	return context.WithValue(ctx, bindingKey{}, binding)
+}
+
+// Binding returns the client fingerprint carried by the context.
+func Binding(ctx context.Context) string {
+	binding, _ := ctx.Value(bindingKey{}).(string)
+	return binding
+}
@@ -0,0 +1,22 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package authn
+
+import "context"
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of the context carrying the custom claims of the
+// session. The conditions of the policies are evaluated against the claims
+// of the context, so the policies with conditions don't hold if the context
+// carries no claims.
+func WithClaims(ctx context.Context, claims map[string]string) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// Claims returns the custom claims of the session carried by the context.
+func Claims(ctx context.Context) map[string]string {
+	claims, _ := ctx.Value(claimsKey{}).(map[string]string)
+	return claims
+}
@@ -0,0 +1,8 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dpop verifies DPoP-style proofs of possession. Clients holding an
+// asymmetric key pair prove possession of the private key by signing a short
+// lived proof of each request, and tokens are bound to the thumbprint of the
+// public key, so a stolen token is useless without the private key.
+package dpop
@@ -0,0 +1,164 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package dpop
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+)
+
+const (
+	// Header is the HTTP header carrying the proof.
+	Header = "DPoP"
+
+	// ProofType is the type header value of the proof.
+	ProofType = "dpop+jwt"
+
+	// MaxAge is the maximum difference between the time the proof was issued
+	// at and the time it is verified at. It limits the period in which a
+	// proof intercepted along with the token can be replayed.
+	MaxAge = time.Minute
+)
+
+var (
+	// ErrInvalidProof indicates a malformed or wrongly signed proof.
+	ErrInvalidProof = errors.New("invalid DPoP proof")
+
+	// ErrProofMismatch indicates a proof issued for a different request.
+	ErrProofMismatch = errors.New("DPoP proof does not match the request")
+
+	// ErrProofExpired indicates a proof issued too long ago or in the future.
+	ErrProofExpired = errors.New("DPoP proof is expired")
+
+	// ErrProofReplayed indicates a proof which was already presented.
+	ErrProofReplayed = errors.New("DPoP proof is already used")
+)
+
+type claims struct {
+	ID              string `json:"jti"`
+	Method          string `json:"htm"`
+	URI             string `json:"htu"`
+	IssuedAt        int64  `json:"iat"`
+	AccessTokenHash string `json:"ath"`
+}
+
+// replays holds the proofs verified within the last MaxAge, so each proof is
+// accepted only once. Older proofs are rejected as expired anyway.
+var replays = &replayCache{seen: make(map[string]time.Time)}
+
+// Verify verifies that the proof is signed by the public key it carries and
+// is issued for the request with the given method and path. If the request
+// presents the access token, the proof must carry the hash of the token. The
+// same proof is accepted only once. It returns the base64url encoded SHA-256
+// thumbprint of the public key.
+//
+// Only the path of the proof URI is compared, since the services run behind
+// a reverse proxy and don't know the scheme and host the client used.
+func Verify(proof, method, path, accessToken string) (string, error) {
+	msg, err := jws.Parse([]byte(proof))
+	if err != nil {
+		return "", errors.Wrap(ErrInvalidProof, err)
+	}
+	if len(msg.Signatures()) != 1 {
+		return "", ErrInvalidProof
+	}
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	if headers.Type() != ProofType {
+		return "", ErrInvalidProof
+	}
+	key := headers.JWK()
+	if key == nil || key.KeyType() == jwa.OctetSeq {
+		return "", ErrInvalidProof
+	}
+	if private, err := jwk.IsPrivateKey(key); err != nil || private {
+		return "", ErrInvalidProof
+	}
+	payload, err := jws.Verify([]byte(proof), jws.WithKey(headers.Algorithm(), key))
+	if err != nil {
+		return "", errors.Wrap(ErrInvalidProof, err)
+	}
+
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return "", errors.Wrap(ErrInvalidProof, err)
+	}
+	if c.ID == "" {
+		return "", ErrInvalidProof
+	}
+	uri, err := url.Parse(c.URI)
+	if err != nil {
+		return "", errors.Wrap(ErrInvalidProof, err)
+	}
+	if c.Method != method || uri.Path != path {
+		return "", ErrProofMismatch
+	}
+	if accessToken != "" && c.AccessTokenHash != AccessTokenHash(accessToken) {
+		return "", ErrProofMismatch
+	}
+	iat := time.Unix(c.IssuedAt, 0)
+	age := time.Since(iat)
+	if age > MaxAge || age < -MaxAge {
+		return "", ErrProofExpired
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errors.Wrap(ErrInvalidProof, err)
+	}
+	tp := base64.RawURLEncoding.EncodeToString(thumbprint)
+	if !replays.add(tp+":"+c.ID, iat.Add(MaxAge)) {
+		return "", ErrProofReplayed
+	}
+
+	return tp, nil
+}
+
+// AccessTokenHash returns the base64url encoded SHA-256 hash of the access
+// token, which the proofs presented with the token carry as the ath claim.
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// replayCache holds the IDs of the used proofs until they expire. The cache
+// is local to the service instance, so a proof may be replayed against
+// another instance within its MaxAge.
+type replayCache struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+// add records the proof ID until the expiration. It returns false if the ID
+// is already recorded.
+func (rc *replayCache) add(id string, expiresAt time.Time) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(rc.pruned) > MaxAge {
+		for k, exp := range rc.seen {
+			if now.After(exp) {
+				delete(rc.seen, k)
+			}
+		}
+		rc.pruned = now
+	}
+	if exp, ok := rc.seen[id]; ok && !now.After(exp) {
+		return false
+	}
+	rc.seen[id] = expiresAt
+
+	return true
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package dpop_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn/dpop"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	path  = "/users/tokens/issue"
+	uri   = "https://localhost" + path
+	token = "token"
+)
+
+type proofClaims struct {
+	ID              string `json:"jti"`
+	Method          string `json:"htm"`
+	URI             string `json:"htu"`
+	IssuedAt        int64  `json:"iat"`
+	AccessTokenHash string `json:"ath,omitempty"`
+}
+
+func newKey(t *testing.T) jwk.Key {
+	raw, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generating key expected to succeed: %s", err))
+	key, err := jwk.FromRaw(raw)
+	require.Nil(t, err, fmt.Sprintf("creating JWK expected to succeed: %s", err))
+
+	return key
+}
+
+func thumbprint(t *testing.T, key jwk.Key) string {
+	tp, err := key.Thumbprint(crypto.SHA256)
+	require.Nil(t, err, fmt.Sprintf("computing thumbprint expected to succeed: %s", err))
+
+	return base64.RawURLEncoding.EncodeToString(tp)
+}
+
+func sign(t *testing.T, key, header jwk.Key, typ string, claims proofClaims) string {
+	payload, err := json.Marshal(claims)
+	require.Nil(t, err, fmt.Sprintf("marshaling claims expected to succeed: %s", err))
+	headers := jws.NewHeaders()
+	require.Nil(t, headers.Set(jws.TypeKey, typ))
+	if header != nil {
+		require.Nil(t, headers.Set(jws.JWKKey, header))
+	}
+	proof, err := jws.Sign(payload, jws.WithKey(jwa.ES256, key, jws.WithProtectedHeaders(headers)))
+	require.Nil(t, err, fmt.Sprintf("signing proof expected to succeed: %s", err))
+
+	return string(proof)
+}
+
+func TestVerify(t *testing.T) {
+	key := newKey(t)
+	pub, err := key.PublicKey()
+	require.Nil(t, err, fmt.Sprintf("retrieving public key expected to succeed: %s", err))
+	other, err := newKey(t).PublicKey()
+	require.Nil(t, err, fmt.Sprintf("retrieving public key expected to succeed: %s", err))
+
+	var n int
+	claims := func(method, uri string, iat time.Time) proofClaims {
+		n++
+		return proofClaims{ID: fmt.Sprintf("id-%d", n), Method: method, URI: uri, IssuedAt: iat.Unix()}
+	}
+	withToken := func(c proofClaims, token string) proofClaims {
+		c.AccessTokenHash = dpop.AccessTokenHash(token)
+		return c
+	}
+	replayed := sign(t, key, pub, dpop.ProofType, claims(http.MethodPost, uri, time.Now()))
+	_, err = dpop.Verify(replayed, http.MethodPost, path, "")
+	require.Nil(t, err, fmt.Sprintf("verifying proof expected to succeed: %s", err))
+
+	cases := []struct {
+		desc       string
+		proof      string
+		token      string
+		thumbprint string
+		err        error
+	}{
+		{
+			desc:       "verify valid proof",
+			proof:      sign(t, key, pub, dpop.ProofType, claims(http.MethodPost, uri, time.Now())),
+			thumbprint: thumbprint(t, pub),
+		},
+		{
+			desc:       "verify valid proof with access token hash",
+			proof:      sign(t, key, pub, dpop.ProofType, withToken(claims(http.MethodPost, uri, time.Now()), token)),
+			token:      token,
+			thumbprint: thumbprint(t, pub),
+		},
+		{
+			desc:  "verify proof without access token hash",
+			proof: sign(t, key, pub, dpop.ProofType, claims(http.MethodPost, uri, time.Now())),
+			token: token,
+			err:   dpop.ErrProofMismatch,
+		},
+		{
+			desc:  "verify proof with hash of different access token",
+			proof: sign(t, key, pub, dpop.ProofType, withToken(claims(http.MethodPost, uri, time.Now()), "other")),
+			token: token,
+			err:   dpop.ErrProofMismatch,
+		},
+		{
+			desc:  "verify replayed proof",
+			proof: replayed,
+			err:   dpop.ErrProofReplayed,
+		},
+		{
+			desc:  "verify malformed proof",
+			proof: "invalid",
+			err:   dpop.ErrInvalidProof,
+		},
+		{
+			desc:  "verify proof with invalid type",
+			proof: sign(t, key, pub, "JWT", claims(http.MethodPost, uri, time.Now())),
+			err:   dpop.ErrInvalidProof,
+		},
+		{
+			desc:  "verify proof without key",
+			proof: sign(t, key, nil, dpop.ProofType, claims(http.MethodPost, uri, time.Now())),
+			err:   dpop.ErrInvalidProof,
+		},
+		{
+			desc:  "verify proof with private key",
+			proof: sign(t, key, key, dpop.ProofType, claims(http.MethodPost, uri, time.Now())),
+			err:   dpop.ErrInvalidProof,
+		},
+		{
+			desc:  "verify proof signed with different key",
+			proof: sign(t, key, other, dpop.ProofType, claims(http.MethodPost, uri, time.Now())),
+			err:   dpop.ErrInvalidProof,
+		},
+		{
+			desc:  "verify proof without ID",
+			proof: sign(t, key, pub, dpop.ProofType, proofClaims{Method: http.MethodPost, URI: uri, IssuedAt: time.Now().Unix()}),
+			err:   dpop.ErrInvalidProof,
+		},
+		{
+			desc:  "verify proof for different method",
+			proof: sign(t, key, pub, dpop.ProofType, claims(http.MethodGet, uri, time.Now())),
+			err:   dpop.ErrProofMismatch,
+		},
+		{
+			desc:  "verify proof for different path",
+			proof: sign(t, key, pub, dpop.ProofType, claims(http.MethodPost, "https://localhost/users", time.Now())),
+			err:   dpop.ErrProofMismatch,
+		},
+		{
+			desc:  "verify expired proof",
+			proof: sign(t, key, pub, dpop.ProofType, claims(http.MethodPost, uri, time.Now().Add(-2*dpop.MaxAge))),
+			err:   dpop.ErrProofExpired,
+		},
+		{
+			desc:  "verify proof issued in the future",
+			proof: sign(t, key, pub, dpop.ProofType, claims(http.MethodPost, uri, time.Now().Add(2*dpop.MaxAge))),
+			err:   dpop.ErrProofExpired,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tp, err := dpop.Verify(tc.proof, http.MethodPost, path, tc.token)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			assert.Equal(t, tc.thumbprint, tp, fmt.Sprintf("%s: expected thumbprint %s got %s", tc.desc, tc.thumbprint, tp))
+		})
+	}
+}
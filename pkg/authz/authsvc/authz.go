@@ -8,6 +8,7 @@ import (
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/auth/api/grpc/auth"
+	"github.com/absmach/magistrala/pkg/authn"
 	"github.com/absmach/magistrala/pkg/authz"
 	"github.com/absmach/magistrala/pkg/errors"
 	"github.com/absmach/magistrala/pkg/grpcclient"
@@ -20,8 +21,9 @@ type authorization struct {
 
 var _ authz.Authorization = (*authorization)(nil)
 
-func NewAuthorization(ctx context.Context, cfg grpcclient.Config) (authz.Authorization, grpcclient.Handler, error) {
-	client, err := grpcclient.NewHandler(cfg)
+func NewAuthorization(ctx context.Context, cfg grpcclient.Config, opts ...grpcclient.Option) (authz.Authorization, grpcclient.Handler, error) {
+	opts = append(opts, grpcclient.WithIdempotentMethods(magistrala.AuthService_Authorize_FullMethodName))
+	client, err := grpcclient.NewHandler(cfg, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -37,6 +39,8 @@ func NewAuthorization(ctx context.Context, cfg grpcclient.Config) (authz.Authori
 	return authorization{authSvcClient}, client, nil
 }
 
+// Authorize sends the claims of the session, as set by authn.WithClaims, so
+// the conditions of the policies are evaluated against them.
 func (a authorization) Authorize(ctx context.Context, pr authz.PolicyReq) error {
 	req := magistrala.AuthZReq{
 		Domain:          pr.Domain,
@@ -48,6 +52,7 @@ func (a authorization) Authorize(ctx context.Context, pr authz.PolicyReq) error
 		Permission:      pr.Permission,
 		Object:          pr.Object,
 		ObjectType:      pr.ObjectType,
+		Claims:          authn.Claims(ctx),
 	}
 	res, err := a.authSvcClient.Authorize(ctx, &req)
 	if err != nil {
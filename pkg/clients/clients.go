@@ -23,6 +23,9 @@ const (
 	dotSeparator = "."
 )
 
+// MaskedSecret replaces the client secrets which are not revealed.
+const MaskedSecret = "********"
+
 var (
 	userRegexp    = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+/=?^_`{|}~.-]+$")
 	hostRegexp    = regexp.MustCompile(`^[^\s]+\.[^\s]+$`)
@@ -39,18 +42,20 @@ type Credentials struct {
 
 // Client represents generic Client.
 type Client struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name,omitempty"`
-	Tags        []string    `json:"tags,omitempty"`
-	Domain      string      `json:"domain_id,omitempty"`
-	Credentials Credentials `json:"credentials,omitempty"`
-	Metadata    Metadata    `json:"metadata,omitempty"`
-	CreatedAt   time.Time   `json:"created_at,omitempty"`
-	UpdatedAt   time.Time   `json:"updated_at,omitempty"`
-	UpdatedBy   string      `json:"updated_by,omitempty"`
-	Status      Status      `json:"status,omitempty"` // 1 for enabled, 0 for disabled
-	Role        Role        `json:"role,omitempty"`   // 1 for admin, 0 for normal user
-	Permissions []string    `json:"permissions,omitempty"`
+	ID              string      `json:"id"`
+	Name            string      `json:"name,omitempty"`
+	Tags            []string    `json:"tags,omitempty"`
+	Domain          string      `json:"domain_id,omitempty"`
+	Credentials     Credentials `json:"credentials,omitempty"`
+	Metadata        Metadata    `json:"metadata,omitempty"`
+	CreatedAt       time.Time   `json:"created_at,omitempty"`
+	UpdatedAt       time.Time   `json:"updated_at,omitempty"`
+	UpdatedBy       string      `json:"updated_by,omitempty"`
+	Status          Status      `json:"status,omitempty"` // 1 for enabled, 0 for disabled
+	Role            Role        `json:"role,omitempty"`   // 1 for admin, 0 for normal user
+	Permissions     []string    `json:"permissions,omitempty"`
+	LinkedProviders []string    `json:"linked_providers,omitempty" toml:",omitempty"` // OAuth2 providers the user signed in with
+	DisabledAt      *time.Time  `json:"disabled_at,omitempty" toml:",omitempty"`      // Time the user was disabled, if disabled
 }
 
 // ClientsPage contains page related metadata as well as list
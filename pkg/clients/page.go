@@ -3,22 +3,87 @@
 
 package clients
 
+import (
+	"regexp"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// MetadataOrderPrefix prefixes the page order by a metadata field, such as
+// metadata.department for the department key or metadata.org.unit for the
+// nested unit key.
+const MetadataOrderPrefix = "metadata."
+
+var (
+	// ErrInvalidOrder indicates the order is neither a client field nor a
+	// metadata field.
+	ErrInvalidOrder = errors.New("invalid page order")
+
+	// ErrInvalidMetadataOrder indicates an invalid metadata field order.
+	ErrInvalidMetadataOrder = errors.New("invalid metadata order path")
+
+	metadataKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// orderFields are the client fields the pages can be ordered by.
+	orderFields = map[string]bool{
+		"name":       true,
+		"identity":   true,
+		"created_at": true,
+		"updated_at": true,
+	}
+)
+
 // Page contains page metadata that helps navigation.
 type Page struct {
-	Total      uint64   `json:"total"`
-	Offset     uint64   `json:"offset"`
-	Limit      uint64   `json:"limit"`
-	Name       string   `json:"name,omitempty"`
-	Id         string   `json:"id,omitempty"`
-	Order      string   `json:"order,omitempty"`
-	Dir        string   `json:"dir,omitempty"`
-	Metadata   Metadata `json:"metadata,omitempty"`
-	Domain     string   `json:"domain,omitempty"`
-	Tag        string   `json:"tag,omitempty"`
-	Permission string   `json:"permission,omitempty"`
-	Status     Status   `json:"status,omitempty"`
-	IDs        []string `json:"ids,omitempty"`
-	Identity   string   `json:"identity,omitempty"`
-	Role       Role     `json:"-"`
-	ListPerms  bool     `json:"-"`
+	Total           uint64   `json:"total"`
+	TotalUnfiltered *uint64  `json:"total_unfiltered,omitempty"`
+	Offset          uint64   `json:"offset"`
+	Limit           uint64   `json:"limit"`
+	Name            string   `json:"name,omitempty"`
+	Id              string   `json:"id,omitempty"`
+	Order           string   `json:"order,omitempty"`
+	Dir             string   `json:"dir,omitempty"`
+	Metadata        Metadata `json:"metadata,omitempty"`
+	Domain          string   `json:"domain,omitempty"`
+	Tag             string   `json:"tag,omitempty"`
+	Permission      string   `json:"permission,omitempty"`
+	Status          Status   `json:"status,omitempty"`
+	IDs             []string `json:"ids,omitempty"`
+	Identity        string   `json:"identity,omitempty"`
+	OAuthProvider   string   `json:"oauth_provider,omitempty"`
+	Role            Role     `json:"-"`
+	ListPerms       bool     `json:"-"`
+	CountUnfiltered bool     `json:"-"`
+}
+
+// MetadataOrderPath returns the keys path of the metadata field the order
+// refers to, or nil if the order doesn't refer to a metadata field.
+func MetadataOrderPath(order string) ([]string, error) {
+	path, ok := strings.CutPrefix(order, MetadataOrderPrefix)
+	if !ok {
+		return nil, nil
+	}
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if !metadataKeyRegexp.MatchString(key) {
+			return nil, ErrInvalidMetadataOrder
+		}
+	}
+
+	return keys, nil
+}
+
+// ValidateOrder returns an error if the pages can't be ordered by the order.
+// The empty order stands for the default order.
+func ValidateOrder(order string) error {
+	path, err := MetadataOrderPath(order)
+	if err != nil {
+		return err
+	}
+	if path != nil || order == "" || orderFields[order] {
+		return nil
+	}
+
+	return ErrInvalidOrder
 }
@@ -0,0 +1,97 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package clients_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/clients"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataOrderPath(t *testing.T) {
+	cases := []struct {
+		desc  string
+		order string
+		path  []string
+		err   error
+	}{
+		{
+			desc:  "order by column",
+			order: "name",
+		},
+		{
+			desc:  "order by metadata key",
+			order: "metadata.department",
+			path:  []string{"department"},
+		},
+		{
+			desc:  "order by nested metadata key",
+			order: "metadata.org.department",
+			path:  []string{"org", "department"},
+		},
+		{
+			desc:  "order by empty metadata path",
+			order: "metadata.",
+			err:   clients.ErrInvalidMetadataOrder,
+		},
+		{
+			desc:  "order by metadata path with empty key",
+			order: "metadata.org..department",
+			err:   clients.ErrInvalidMetadataOrder,
+		},
+		{
+			desc:  "order by metadata path with invalid characters",
+			order: "metadata.department'}",
+			err:   clients.ErrInvalidMetadataOrder,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path, err := clients.MetadataOrderPath(tc.order)
+			assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %v got %v", tc.desc, tc.err, err))
+			assert.Equal(t, tc.path, path, fmt.Sprintf("%s: expected path %v got %v", tc.desc, tc.path, path))
+		})
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	cases := []struct {
+		desc  string
+		order string
+		err   error
+	}{
+		{
+			desc:  "validate default order",
+			order: "",
+		},
+		{
+			desc:  "validate order by column",
+			order: "updated_at",
+		},
+		{
+			desc:  "validate order by metadata key",
+			order: "metadata.org.department",
+		},
+		{
+			desc:  "validate order by unknown column",
+			order: "secret",
+			err:   clients.ErrInvalidOrder,
+		},
+		{
+			desc:  "validate order by invalid metadata path",
+			order: "metadata.org..department",
+			err:   clients.ErrInvalidMetadataOrder,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := clients.ValidateOrder(tc.order)
+			assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %v got %v", tc.desc, tc.err, err))
+		})
+	}
+}
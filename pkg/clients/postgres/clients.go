@@ -33,6 +33,9 @@ func (repo *Repository) Update(ctx context.Context, client clients.Client) (clie
 	if client.Metadata != nil {
 		query = append(query, "metadata = :metadata,")
 	}
+	if client.Tags != nil {
+		query = append(query, "tags = :tags,")
+	}
 	if len(query) > 0 {
 		upq = strings.Join(query, " ")
 	}
@@ -188,6 +191,14 @@ func (repo *Repository) RetrieveAll(ctx context.Context, pm clients.Page) (clien
 		},
 	}
 
+	if pm.CountUnfiltered {
+		total, err := repo.CountUnfiltered(ctx, pm)
+		if err != nil {
+			return clients.ClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		page.TotalUnfiltered = &total
+	}
+
 	return page, nil
 }
 
@@ -243,6 +254,14 @@ func (repo *Repository) SearchClients(ctx context.Context, pm clients.Page) (cli
 		},
 	}
 
+	if pm.CountUnfiltered {
+		total, err := repo.CountUnfiltered(ctx, pm)
+		if err != nil {
+			return clients.ClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		page.TotalUnfiltered = &total
+	}
+
 	return page, nil
 }
 
@@ -301,6 +320,14 @@ func (repo *Repository) RetrieveAllByIDs(ctx context.Context, pm clients.Page) (
 		},
 	}
 
+	if pm.CountUnfiltered {
+		total, err := repo.CountUnfiltered(ctx, pm)
+		if err != nil {
+			return clients.ClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		page.TotalUnfiltered = &total
+	}
+
 	return page, nil
 }
 
@@ -343,19 +370,20 @@ func (repo *Repository) Delete(ctx context.Context, id string) error {
 }
 
 type DBClient struct {
-	ID        string           `db:"id"`
-	Name      string           `db:"name,omitempty"`
-	Tags      pgtype.TextArray `db:"tags,omitempty"`
-	Identity  string           `db:"identity"`
-	Domain    string           `db:"domain_id"`
-	Secret    string           `db:"secret"`
-	Metadata  []byte           `db:"metadata,omitempty"`
-	CreatedAt time.Time        `db:"created_at,omitempty"`
-	UpdatedAt sql.NullTime     `db:"updated_at,omitempty"`
-	UpdatedBy *string          `db:"updated_by,omitempty"`
-	Groups    []groups.Group   `db:"groups,omitempty"`
-	Status    clients.Status   `db:"status,omitempty"`
-	Role      *clients.Role    `db:"role,omitempty"`
+	ID         string           `db:"id"`
+	Name       string           `db:"name,omitempty"`
+	Tags       pgtype.TextArray `db:"tags,omitempty"`
+	Identity   string           `db:"identity"`
+	Domain     string           `db:"domain_id"`
+	Secret     string           `db:"secret"`
+	Metadata   []byte           `db:"metadata,omitempty"`
+	CreatedAt  time.Time        `db:"created_at,omitempty"`
+	UpdatedAt  sql.NullTime     `db:"updated_at,omitempty"`
+	UpdatedBy  *string          `db:"updated_by,omitempty"`
+	Groups     []groups.Group   `db:"groups,omitempty"`
+	Status     clients.Status   `db:"status,omitempty"`
+	Role       *clients.Role    `db:"role,omitempty"`
+	DisabledAt sql.NullTime     `db:"disabled_at,omitempty"`
 }
 
 func ToDBClient(c clients.Client) (DBClient, error) {
@@ -379,20 +407,25 @@ func ToDBClient(c clients.Client) (DBClient, error) {
 	if c.UpdatedAt != (time.Time{}) {
 		updatedAt = sql.NullTime{Time: c.UpdatedAt, Valid: true}
 	}
+	var disabledAt sql.NullTime
+	if c.DisabledAt != nil {
+		disabledAt = sql.NullTime{Time: *c.DisabledAt, Valid: true}
+	}
 
 	return DBClient{
-		ID:        c.ID,
-		Name:      c.Name,
-		Tags:      tags,
-		Domain:    c.Domain,
-		Identity:  c.Credentials.Identity,
-		Secret:    c.Credentials.Secret,
-		Metadata:  data,
-		CreatedAt: c.CreatedAt,
-		UpdatedAt: updatedAt,
-		UpdatedBy: updatedBy,
-		Status:    c.Status,
-		Role:      &c.Role,
+		ID:         c.ID,
+		Name:       c.Name,
+		Tags:       tags,
+		Domain:     c.Domain,
+		Identity:   c.Credentials.Identity,
+		Secret:     c.Credentials.Secret,
+		Metadata:   data,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  updatedAt,
+		UpdatedBy:  updatedBy,
+		Status:     c.Status,
+		Role:       &c.Role,
+		DisabledAt: disabledAt,
 	}, nil
 }
 
@@ -434,6 +467,9 @@ func ToClient(c DBClient) (clients.Client, error) {
 	if c.Role != nil {
 		cli.Role = *c.Role
 	}
+	if c.DisabledAt.Valid {
+		cli.DisabledAt = &c.DisabledAt.Time
+	}
 	return cli, nil
 }
 
@@ -443,17 +479,18 @@ func ToDBClientsPage(pm clients.Page) (dbClientsPage, error) {
 		return dbClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
 	}
 	return dbClientsPage{
-		Name:     pm.Name,
-		Identity: pm.Identity,
-		Id:       pm.Id,
-		Metadata: data,
-		Domain:   pm.Domain,
-		Total:    pm.Total,
-		Offset:   pm.Offset,
-		Limit:    pm.Limit,
-		Status:   pm.Status,
-		Tag:      pm.Tag,
-		Role:     pm.Role,
+		Name:          pm.Name,
+		Identity:      pm.Identity,
+		Id:            pm.Id,
+		Metadata:      data,
+		Domain:        pm.Domain,
+		Total:         pm.Total,
+		Offset:        pm.Offset,
+		Limit:         pm.Limit,
+		Status:        pm.Status,
+		Tag:           pm.Tag,
+		Role:          pm.Role,
+		OAuthProvider: pm.OAuthProvider,
 	}, nil
 }
 
@@ -470,6 +507,31 @@ type dbClientsPage struct {
 	Status   clients.Status `db:"status"`
 	GroupID  string         `db:"group_id"`
 	Role     clients.Role   `db:"role"`
+	// OAuthProvider is used by the users repository only.
+	OAuthProvider string `db:"oauth_provider"`
+}
+
+// CountUnfiltered returns the number of clients within the page scope,
+// i.e. the domain, the IDs and the role, ignoring the name, identity, ID,
+// tag, metadata and status filters.
+func (repo *Repository) CountUnfiltered(ctx context.Context, pm clients.Page) (uint64, error) {
+	scope := clients.Page{
+		IDs:    pm.IDs,
+		Domain: pm.Domain,
+		Role:   pm.Role,
+		Status: clients.AllStatus,
+	}
+	query, err := PageQuery(scope)
+	if err != nil {
+		return 0, err
+	}
+	dbPage, err := ToDBClientsPage(scope)
+	if err != nil {
+		return 0, err
+	}
+	cq := fmt.Sprintf(`SELECT COUNT(*) FROM clients c %s;`, query)
+
+	return postgres.Total(ctx, repo.DB, cq, dbPage)
 }
 
 func PageQuery(pm clients.Page) (string, error) {
@@ -530,3 +592,30 @@ func applyOrdering(emq string, pm clients.Page) string {
 	}
 	return emq
 }
+
+// OrderQuery returns the ORDER BY clause of the page. Pages ordered by a
+// metadata field are ordered by the JSONB value at the metadata path, with
+// clients lacking the field last. Other pages are ordered by the column
+// named by the order, or by the creation time if there is no order.
+func OrderQuery(pm clients.Page) (string, error) {
+	if err := clients.ValidateOrder(pm.Order); err != nil {
+		return "", err
+	}
+	var dir string
+	if pm.Dir == api.AscDir || pm.Dir == api.DescDir {
+		dir = " " + pm.Dir
+	}
+
+	path, err := clients.MetadataOrderPath(pm.Order)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case path != nil:
+		return fmt.Sprintf("ORDER BY c.metadata #> '{%s}'%s NULLS LAST", strings.Join(path, ","), dir), nil
+	case pm.Order == "":
+		return "ORDER BY c.created_at", nil
+	default:
+		return fmt.Sprintf("ORDER BY c.%s%s", pm.Order, dir), nil
+	}
+}
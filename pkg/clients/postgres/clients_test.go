@@ -663,6 +663,166 @@ func TestRetrieveAll(t *testing.T) {
 	}
 }
 
+func TestRetrieveAllTotalUnfiltered(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM clients")
+		require.Nil(t, err, fmt.Sprintf("clean clients unexpected error: %s", err))
+	})
+	repo := &postgres.Repository{database}
+
+	domainID := testsutil.GenerateUUID(t)
+	otherDomainID := testsutil.GenerateUUID(t)
+	nClients := 20
+	nOtherClients := 10
+
+	var ids []string
+	for i := 0; i < nClients+nOtherClients; i++ {
+		client := mgclients.Client{
+			ID:     testsutil.GenerateUUID(t),
+			Domain: domainID,
+			Name:   namegen.Generate(),
+			Credentials: mgclients.Credentials{
+				Identity: namegen.Generate() + emailSuffix,
+				Secret:   password,
+			},
+			Metadata:  mgclients.Metadata{},
+			Status:    mgclients.EnabledStatus,
+			CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+			Role:      mgclients.UserRole,
+		}
+		if i >= nClients {
+			client.Domain = otherDomainID
+		}
+		if i%5 == 0 {
+			client.Status = mgclients.DisabledStatus
+		}
+		if i < 3 {
+			client.Name = "unfiltered-" + client.Name
+		}
+		client, err := save(context.Background(), repo, client)
+		require.Nil(t, err, fmt.Sprintf("add new client: expected nil got %s\n", err))
+		if client.Domain == domainID && len(ids) < 10 {
+			ids = append(ids, client.ID)
+		}
+	}
+
+	cases := []struct {
+		desc            string
+		pm              mgclients.Page
+		total           uint64
+		totalUnfiltered uint64
+	}{
+		{
+			desc: "without counting unfiltered clients",
+			pm: mgclients.Page{
+				Limit:  10,
+				Domain: domainID,
+				Status: mgclients.EnabledStatus,
+				Role:   mgclients.AllRole,
+			},
+			total:           16,
+			totalUnfiltered: 0,
+		},
+		{
+			desc: "without filters",
+			pm: mgclients.Page{
+				Limit:           10,
+				Domain:          domainID,
+				Status:          mgclients.AllStatus,
+				Role:            mgclients.AllRole,
+				CountUnfiltered: true,
+			},
+			total:           uint64(nClients),
+			totalUnfiltered: uint64(nClients),
+		},
+		{
+			desc: "with status filter",
+			pm: mgclients.Page{
+				Limit:           10,
+				Domain:          domainID,
+				Status:          mgclients.EnabledStatus,
+				Role:            mgclients.AllRole,
+				CountUnfiltered: true,
+			},
+			total:           16,
+			totalUnfiltered: uint64(nClients),
+		},
+		{
+			desc: "with name filter",
+			pm: mgclients.Page{
+				Limit:           10,
+				Name:            "unfiltered-",
+				Domain:          domainID,
+				Status:          mgclients.AllStatus,
+				Role:            mgclients.AllRole,
+				CountUnfiltered: true,
+			},
+			total:           3,
+			totalUnfiltered: uint64(nClients),
+		},
+		{
+			desc: "with status filter in other domain",
+			pm: mgclients.Page{
+				Limit:           10,
+				Domain:          otherDomainID,
+				Status:          mgclients.EnabledStatus,
+				Role:            mgclients.AllRole,
+				CountUnfiltered: true,
+			},
+			total:           8,
+			totalUnfiltered: uint64(nOtherClients),
+		},
+		{
+			desc: "with status filter within ids",
+			pm: mgclients.Page{
+				Limit:           10,
+				IDs:             ids,
+				Status:          mgclients.EnabledStatus,
+				Role:            mgclients.AllRole,
+				CountUnfiltered: true,
+			},
+			total:           8,
+			totalUnfiltered: uint64(len(ids)),
+		},
+		{
+			desc: "in domain without clients",
+			pm: mgclients.Page{
+				Limit:           10,
+				Domain:          testsutil.GenerateUUID(t),
+				Status:          mgclients.AllStatus,
+				Role:            mgclients.AllRole,
+				CountUnfiltered: true,
+			},
+			total:           0,
+			totalUnfiltered: 0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			page, err := repo.RetrieveAll(context.Background(), c.pm)
+			assert.Nil(t, err, fmt.Sprintf("%s: expected nil got %s\n", c.desc, err))
+			assert.Equal(t, c.total, page.Total)
+			assertTotalUnfiltered(t, c.pm.CountUnfiltered, c.totalUnfiltered, page.TotalUnfiltered)
+
+			page, err = repo.SearchClients(context.Background(), c.pm)
+			assert.Nil(t, err, fmt.Sprintf("%s: expected nil got %s\n", c.desc, err))
+			assert.Equal(t, c.total, page.Total)
+			assertTotalUnfiltered(t, c.pm.CountUnfiltered, c.totalUnfiltered, page.TotalUnfiltered)
+		})
+	}
+}
+
+// assertTotalUnfiltered asserts that the unfiltered total is set, even if
+// zero, only when it's requested.
+func assertTotalUnfiltered(t *testing.T, requested bool, expected uint64, total *uint64) {
+	if !requested {
+		assert.Nil(t, total, fmt.Sprintf("expected no total unfiltered got %v", total))
+		return
+	}
+	require.NotNil(t, total, "expected total unfiltered got none")
+	assert.Equal(t, expected, *total)
+}
+
 func TestRetrieveByIDs(t *testing.T) {
 	t.Cleanup(func() {
 		_, err := db.Exec("DELETE FROM clients")
@@ -3,5 +3,32 @@
 
 package clients
 
+import (
+	"encoding/json"
+
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// DefMaxMetadataSize is the default maximum size of the JSON-encoded metadata in bytes.
+const DefMaxMetadataSize = 64 * 1024
+
 // Metadata represents arbitrary JSON.
 type Metadata map[string]interface{}
+
+// ValidateSize returns an error if the JSON-encoded metadata is larger than
+// maxSize bytes. Non-positive maxSize disables the check.
+func (m Metadata) ValidateSize(maxSize int) error {
+	if maxSize <= 0 || len(m) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return errors.Wrap(apiutil.ErrValidation, errors.Wrap(errors.ErrMalformedEntity, err))
+	}
+	if len(data) > maxSize {
+		return errors.Wrap(apiutil.ErrValidation, apiutil.ErrMetadataSize)
+	}
+
+	return nil
+}
@@ -16,6 +16,9 @@ var (
 	// ErrConflict indicates that entity already exists.
 	ErrConflict = errors.New("entity already exists")
 
+	// ErrConcurrentUpdate indicates that entity was changed since it was read.
+	ErrConcurrentUpdate = errors.New("entity was updated concurrently")
+
 	// ErrCreateEntity indicates error in creating entity or entities.
 	ErrCreateEntity = errors.New("failed to create entity in the db")
 
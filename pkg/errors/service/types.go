@@ -19,15 +19,29 @@ var (
 	// ErrLogin indicates wrong login credentials.
 	ErrLogin = errors.New("invalid user id or secret")
 
+	// ErrMFAEnrollmentRequired indicates that the user must enroll multi-factor
+	// authentication before a token can be issued.
+	ErrMFAEnrollmentRequired = errors.New("multi-factor authentication is required for this account, enroll it before logging in")
+
+	// ErrMFACode indicates a missing or invalid multi-factor authentication code.
+	ErrMFACode = errors.New("missing or invalid multi-factor authentication code")
+
 	// ErrMalformedEntity indicates a malformed entity specification.
 	ErrMalformedEntity = errors.New("malformed entity specification")
 
+	// ErrServiceUnavailable indicates that a dependency of the service, such
+	// as the database, is unavailable.
+	ErrServiceUnavailable = errors.New("service is unavailable")
+
 	// ErrNotFound indicates a non-existent entity request.
 	ErrNotFound = errors.New("entity not found")
 
 	// ErrConflict indicates that entity already exists.
 	ErrConflict = errors.New("entity already exists")
 
+	// ErrConcurrentUpdate indicates that entity was changed by another request in the meantime.
+	ErrConcurrentUpdate = errors.New("entity was updated concurrently, retry the request")
+
 	// ErrCreateEntity indicates error in creating entity or entities.
 	ErrCreateEntity = errors.New("failed to create entity")
 
@@ -72,4 +86,7 @@ var (
 
 	// ErrParentGroupAuthorization indicates failure occurred while authorizing the parent group.
 	ErrParentGroupAuthorization = errors.New("failed to authorize parent group")
+
+	// ErrSelfRegistrationDisabled indicates that the users may not join the domain themselves.
+	ErrSelfRegistrationDisabled = errors.New("self-registration is disabled in the domain")
 )
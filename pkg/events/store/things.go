@@ -0,0 +1,30 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package store
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/absmach/magistrala/pkg/events"
+)
+
+// ThingsStream represents the stream of the things service events.
+const ThingsStream = "events.magistrala.things"
+
+// SubscribeToThings subscribes the handler to the things service events of
+// the event store at the URL, as the named consumer.
+func SubscribeToThings(ctx context.Context, url, consumer string, handler events.EventHandler, logger *slog.Logger) error {
+	subscriber, err := NewSubscriber(ctx, url, logger)
+	if err != nil {
+		return err
+	}
+
+	subConfig := events.SubscriberConfig{
+		Stream:   ThingsStream,
+		Consumer: consumer,
+		Handler:  handler,
+	}
+	return subscriber.Subscribe(ctx, subConfig)
+}
@@ -49,6 +49,20 @@ type MembersPage struct {
 	Members []Member `json:"members"`
 }
 
+// Membership represents a group the user belongs to along with the
+// relation of the user to the group.
+type Membership struct {
+	Group
+	Relation string `json:"relation"`
+}
+
+// MembershipsPage contains page related metadata as well as list of
+// groups the user belongs to that belong to the page.
+type MembershipsPage struct {
+	PageMeta
+	Memberships []Membership
+}
+
 // Page contains page related metadata as well as list
 // of Groups that belong to the page.
 type Page struct {
@@ -114,6 +128,10 @@ type Service interface {
 	// ListMembers retrieves everything that is assigned to a group identified by groupID.
 	ListMembers(ctx context.Context, session authn.Session, groupID, permission, memberKind string) (MembersPage, error)
 
+	// ListUserGroups retrieves the groups of the session domain the user
+	// identified by userID belongs to, along with the user relation.
+	ListUserGroups(ctx context.Context, session authn.Session, userID string, pm PageMeta) (MembershipsPage, error)
+
 	// EnableGroup logically enables the group identified with the provided ID.
 	EnableGroup(ctx context.Context, session authn.Session, id string) (Group, error)
 
@@ -195,6 +195,34 @@ func (_m *Service) ListMembers(ctx context.Context, session authn.Session, group
 	return r0, r1
 }
 
+// ListUserGroups provides a mock function with given fields: ctx, session, userID, pm
+func (_m *Service) ListUserGroups(ctx context.Context, session authn.Session, userID string, pm groups.PageMeta) (groups.MembershipsPage, error) {
+	ret := _m.Called(ctx, session, userID, pm)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUserGroups")
+	}
+
+	var r0 groups.MembershipsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, groups.PageMeta) (groups.MembershipsPage, error)); ok {
+		return rf(ctx, session, userID, pm)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, groups.PageMeta) groups.MembershipsPage); ok {
+		r0 = rf(ctx, session, userID, pm)
+	} else {
+		r0 = ret.Get(0).(groups.MembershipsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, string, groups.PageMeta) error); ok {
+		r1 = rf(ctx, session, userID, pm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Unassign provides a mock function with given fields: ctx, session, groupID, relation, memberKind, memberIDs
 func (_m *Service) Unassign(ctx context.Context, session authn.Session, groupID string, relation string, memberKind string, memberIDs ...string) error {
 	ret := _m.Called(ctx, session, groupID, relation, memberKind, memberIDs)
@@ -0,0 +1,195 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-kit/kit/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerState is the state of the circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets all calls through.
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen lets a single probe call through after the cooldown.
+	BreakerHalfOpen
+	// BreakerOpen rejects all calls until the cooldown expires.
+	BreakerOpen
+)
+
+const retryJitter = 0.5
+
+// ErrCircuitOpen indicates that the call was rejected because the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// String returns the breaker state as a string.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// Option configures the gRPC client handler.
+type Option func(*options)
+
+type options struct {
+	idempotent map[string]bool
+	state      metrics.Gauge
+}
+
+// WithIdempotentMethods marks the full gRPC method names which are safe to retry.
+// Other methods are never retried.
+func WithIdempotentMethods(methods ...string) Option {
+	return func(o *options) {
+		for _, m := range methods {
+			o.idempotent[m] = true
+		}
+	}
+}
+
+// WithBreakerMetrics reports the circuit breaker state to the gauge.
+func WithBreakerMetrics(state metrics.Gauge) Option {
+	return func(o *options) {
+		o.state = state
+	}
+}
+
+type breaker struct {
+	mu        sync.Mutex
+	threshold uint32
+	cooldown  time.Duration
+	failures  uint32
+	state     BreakerState
+	openedAt  time.Time
+	probing   bool
+	gauge     metrics.Gauge
+	now       func() time.Time
+}
+
+func newBreaker(threshold uint32, cooldown time.Duration, gauge metrics.Gauge) *breaker {
+	b := &breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		gauge:     gauge,
+		now:       time.Now,
+	}
+	b.setState(BreakerClosed)
+
+	return b
+}
+
+// allow returns ErrCircuitOpen if the call must be rejected.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// done records the result of the allowed call.
+func (b *breaker) done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		b.probing = false
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.probing = false
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+func (b *breaker) setState(state BreakerState) {
+	b.state = state
+	if b.gauge != nil {
+		b.gauge.Set(float64(state))
+	}
+}
+
+// unavailable reports whether the error indicates that the server is
+// unavailable or overloaded, as opposed to rejecting the request.
+func unavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// unaryInterceptor fails fast while the breaker is open and retries
+// idempotent calls rejected by an unavailable server with jittered backoff.
+func unaryInterceptor(cfg Config, b *breaker, idempotent map[string]bool) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		call := func() error {
+			if b != nil {
+				if err := b.allow(); err != nil {
+					return backoff.Permanent(status.Error(codes.Unavailable, err.Error()))
+				}
+			}
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if b != nil {
+				b.done(unavailable(err))
+			}
+			if err != nil && status.Code(err) != codes.Unavailable {
+				return backoff.Permanent(err)
+			}
+
+			return err
+		}
+
+		if cfg.MaxRetries == 0 || !idempotent[method] {
+			err := call()
+			if perr, ok := err.(*backoff.PermanentError); ok {
+				return perr.Err
+			}
+			return err
+		}
+
+		bo := backoff.NewExponentialBackOff()
+		bo.InitialInterval = cfg.RetryInterval
+		bo.RandomizationFactor = retryJitter
+		bo.MaxElapsedTime = 0
+
+		return backoff.Retry(call, backoff.WithContext(backoff.WithMaxRetries(bo, cfg.MaxRetries), ctx))
+	}
+}
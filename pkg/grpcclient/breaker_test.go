@@ -0,0 +1,261 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	idempotentMethod = "/magistrala.AuthService/Authenticate"
+	otherMethod      = "/magistrala.TokenService/Issue"
+)
+
+var (
+	errUnavailable = status.Error(codes.Unavailable, "auth service unavailable")
+	errDeadline    = status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+	errUnauth      = status.Error(codes.Unauthenticated, "invalid token")
+)
+
+type fakeGauge struct {
+	value float64
+}
+
+func (g *fakeGauge) With(labelValues ...string) metrics.Gauge {
+	return g
+}
+
+func (g *fakeGauge) Set(value float64) {
+	g.value = value
+}
+
+func (g *fakeGauge) Add(delta float64) {
+	g.value += delta
+}
+
+type fakeInvoker struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeInvoker) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+	f.calls++
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+
+	return err
+}
+
+func TestBreaker(t *testing.T) {
+	gauge := &fakeGauge{}
+	now := time.Now()
+	b := newBreaker(3, time.Minute, gauge)
+	b.now = func() time.Time { return now }
+	interceptor := unaryInterceptor(Config{}, b, map[string]bool{})
+	inv := &fakeInvoker{}
+
+	steps := []struct {
+		desc    string
+		elapsed time.Duration
+		invErr  error
+		err     error
+		calls   int
+		state   BreakerState
+	}{
+		{
+			desc:   "call with breaker closed",
+			invErr: nil,
+			err:    nil,
+			calls:  1,
+			state:  BreakerClosed,
+		},
+		{
+			desc:   "call failed with rejected request",
+			invErr: errUnauth,
+			err:    errUnauth,
+			calls:  2,
+			state:  BreakerClosed,
+		},
+		{
+			desc:   "first failed call",
+			invErr: errUnavailable,
+			err:    errUnavailable,
+			calls:  3,
+			state:  BreakerClosed,
+		},
+		{
+			desc:   "second failed call",
+			invErr: errDeadline,
+			err:    errDeadline,
+			calls:  4,
+			state:  BreakerClosed,
+		},
+		{
+			desc:   "third failed call trips the breaker",
+			invErr: errUnavailable,
+			err:    errUnavailable,
+			calls:  5,
+			state:  BreakerOpen,
+		},
+		{
+			desc:   "call with breaker open fails fast",
+			invErr: nil,
+			err:    status.Error(codes.Unavailable, ErrCircuitOpen.Error()),
+			calls:  5,
+			state:  BreakerOpen,
+		},
+		{
+			desc:    "call before cooldown fails fast",
+			elapsed: 30 * time.Second,
+			invErr:  nil,
+			err:     status.Error(codes.Unavailable, ErrCircuitOpen.Error()),
+			calls:   5,
+			state:   BreakerOpen,
+		},
+		{
+			desc:    "failed probe after cooldown opens the breaker",
+			elapsed: 31 * time.Second,
+			invErr:  errUnavailable,
+			err:     errUnavailable,
+			calls:   6,
+			state:   BreakerOpen,
+		},
+		{
+			desc:    "call after failed probe fails fast",
+			elapsed: 30 * time.Second,
+			invErr:  nil,
+			err:     status.Error(codes.Unavailable, ErrCircuitOpen.Error()),
+			calls:   6,
+			state:   BreakerOpen,
+		},
+		{
+			desc:    "successful probe after cooldown closes the breaker",
+			elapsed: 31 * time.Second,
+			invErr:  nil,
+			err:     nil,
+			calls:   7,
+			state:   BreakerClosed,
+		},
+		{
+			desc:   "call after recovery",
+			invErr: errUnavailable,
+			err:    errUnavailable,
+			calls:  8,
+			state:  BreakerClosed,
+		},
+	}
+
+	for _, s := range steps {
+		now = now.Add(s.elapsed)
+		inv.errs = []error{s.invErr}
+		err := interceptor(context.Background(), otherMethod, nil, nil, nil, inv.invoke)
+		assert.Equal(t, s.err, err, fmt.Sprintf("%s: expected error %s got %s", s.desc, s.err, err))
+		assert.Equal(t, s.calls, inv.calls, fmt.Sprintf("%s: expected %d calls got %d", s.desc, s.calls, inv.calls))
+		assert.Equal(t, s.state, b.state, fmt.Sprintf("%s: expected state %s got %s", s.desc, s.state, b.state))
+		assert.Equal(t, float64(s.state), gauge.value, fmt.Sprintf("%s: expected gauge %d got %f", s.desc, s.state, gauge.value))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	cfg := Config{
+		MaxRetries:    2,
+		RetryInterval: time.Millisecond,
+	}
+	idempotent := map[string]bool{idempotentMethod: true}
+
+	cases := []struct {
+		desc    string
+		cfg     Config
+		method  string
+		invErrs []error
+		err     error
+		calls   int
+	}{
+		{
+			desc:    "retry idempotent call until success",
+			cfg:     cfg,
+			method:  idempotentMethod,
+			invErrs: []error{errUnavailable, errUnavailable},
+			err:     nil,
+			calls:   3,
+		},
+		{
+			desc:    "retry idempotent call until retries are exhausted",
+			cfg:     cfg,
+			method:  idempotentMethod,
+			invErrs: []error{errUnavailable, errUnavailable, errUnavailable, errUnavailable},
+			err:     errUnavailable,
+			calls:   3,
+		},
+		{
+			desc:    "do not retry idempotent call rejected by the server",
+			cfg:     cfg,
+			method:  idempotentMethod,
+			invErrs: []error{errUnauth},
+			err:     errUnauth,
+			calls:   1,
+		},
+		{
+			desc:    "do not retry idempotent call with deadline exceeded",
+			cfg:     cfg,
+			method:  idempotentMethod,
+			invErrs: []error{errDeadline},
+			err:     errDeadline,
+			calls:   1,
+		},
+		{
+			desc:    "do not retry non idempotent call",
+			cfg:     cfg,
+			method:  otherMethod,
+			invErrs: []error{errUnavailable},
+			err:     errUnavailable,
+			calls:   1,
+		},
+		{
+			desc:    "do not retry with retries disabled",
+			cfg:     Config{},
+			method:  idempotentMethod,
+			invErrs: []error{errUnavailable},
+			err:     errUnavailable,
+			calls:   1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			inv := &fakeInvoker{errs: tc.invErrs}
+			interceptor := unaryInterceptor(tc.cfg, nil, idempotent)
+			err := interceptor(context.Background(), tc.method, nil, nil, nil, inv.invoke)
+			assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			assert.Equal(t, tc.calls, inv.calls, fmt.Sprintf("%s: expected %d calls got %d", tc.desc, tc.calls, inv.calls))
+		})
+	}
+}
+
+func TestRetryBreaker(t *testing.T) {
+	b := newBreaker(2, time.Minute, nil)
+	cfg := Config{
+		MaxRetries:    5,
+		RetryInterval: time.Millisecond,
+	}
+	interceptor := unaryInterceptor(cfg, b, map[string]bool{idempotentMethod: true})
+	inv := &fakeInvoker{errs: []error{errUnavailable, errUnavailable, errUnavailable}}
+
+	err := interceptor(context.Background(), idempotentMethod, nil, nil, nil, inv.invoke)
+	assert.Equal(t, status.Error(codes.Unavailable, ErrCircuitOpen.Error()), err, fmt.Sprintf("expected circuit open error got %s", err))
+	assert.Equal(t, 2, inv.calls, fmt.Sprintf("expected retries to stop after the breaker opens, got %d calls", inv.calls))
+	assert.Equal(t, BreakerOpen, b.state, fmt.Sprintf("expected state %s got %s", BreakerOpen, b.state))
+}
@@ -18,8 +18,8 @@ import (
 // For example:
 //
 // tokenClient, tokenHandler, err := grpcclient.SetupTokenClient(ctx, grpcclient.Config{}).
-func SetupTokenClient(ctx context.Context, cfg Config) (magistrala.TokenServiceClient, Handler, error) {
-	client, err := NewHandler(cfg)
+func SetupTokenClient(ctx context.Context, cfg Config, opts ...Option) (magistrala.TokenServiceClient, Handler, error) {
+	client, err := NewHandler(cfg, opts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/requestid"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -33,11 +34,15 @@ var (
 )
 
 type Config struct {
-	URL          string        `env:"URL"              envDefault:""`
-	Timeout      time.Duration `env:"TIMEOUT"          envDefault:"1s"`
-	ClientCert   string        `env:"CLIENT_CERT"      envDefault:""`
-	ClientKey    string        `env:"CLIENT_KEY"       envDefault:""`
-	ServerCAFile string        `env:"SERVER_CA_CERTS"  envDefault:""`
+	URL              string        `env:"URL"                envDefault:""`
+	Timeout          time.Duration `env:"TIMEOUT"            envDefault:"1s"`
+	ClientCert       string        `env:"CLIENT_CERT"        envDefault:""`
+	ClientKey        string        `env:"CLIENT_KEY"         envDefault:""`
+	ServerCAFile     string        `env:"SERVER_CA_CERTS"    envDefault:""`
+	MaxRetries       uint64        `env:"MAX_RETRIES"        envDefault:"0"`
+	RetryInterval    time.Duration `env:"RETRY_INTERVAL"     envDefault:"100ms"`
+	BreakerThreshold uint32        `env:"BREAKER_THRESHOLD"  envDefault:"0"`
+	BreakerCooldown  time.Duration `env:"BREAKER_COOLDOWN"   envDefault:"10s"`
 }
 
 // Handler is used to handle gRPC connection.
@@ -60,8 +65,11 @@ type client struct {
 
 var _ Handler = (*client)(nil)
 
-func NewHandler(cfg Config) (Handler, error) {
-	conn, secure, err := connect(cfg)
+// NewHandler creates a new gRPC connection handler. Calls are retried only
+// for methods marked idempotent and only if MaxRetries is set. The circuit
+// breaker is enabled if BreakerThreshold is set.
+func NewHandler(cfg Config, opts ...Option) (Handler, error) {
+	conn, secure, err := connect(cfg, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -100,9 +108,20 @@ func (c *client) Secure() string {
 }
 
 // connect creates new gRPC client and connect to gRPC server.
-func connect(cfg Config) (*grpc.ClientConn, security, error) {
-	opts := []grpc.DialOption{
+func connect(cfg Config, opts ...Option) (*grpc.ClientConn, security, error) {
+	o := &options{idempotent: map[string]bool{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	var b *breaker
+	if cfg.BreakerThreshold > 0 {
+		b = newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, o.state)
+	}
+
+	dialOpts := []grpc.DialOption{
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(requestid.UnaryClientInterceptor(), unaryInterceptor(cfg, b, o.idempotent)),
+		grpc.WithChainStreamInterceptor(requestid.StreamClientInterceptor()),
 	}
 	secure := withoutTLS
 	tc := insecure.NewCredentials()
@@ -137,14 +156,14 @@ func connect(cfg Config) (*grpc.ClientConn, security, error) {
 		tc = credentials.NewTLS(tlsConfig)
 	}
 
-	opts = append(
-		opts, grpc.WithTransportCredentials(tc),
+	dialOpts = append(
+		dialOpts, grpc.WithTransportCredentials(tc),
 		grpc.WithReadBufferSize(buffSize),
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(buffSize/10), grpc.MaxCallSendMsgSize(buffSize/10)),
 		grpc.WithWriteBufferSize(buffSize),
 	)
 
-	conn, err := grpc.NewClient(cfg.URL, opts...)
+	conn, err := grpc.NewClient(cfg.URL, dialOpts...)
 	if err != nil {
 		return nil, secure, errors.Wrap(errGrpcConnect, err)
 	}
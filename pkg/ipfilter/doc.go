@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ipfilter contains the IP address allowlist and denylist protocol
+// adapters use to restrict the source addresses things connect from.
+package ipfilter
@@ -0,0 +1,322 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package ipfilter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/go-kit/kit/metrics"
+)
+
+var (
+	// ErrDenied indicates that connections from the IP address are not allowed.
+	ErrDenied = errors.New("connection from the IP address is not allowed")
+
+	errInvalidRules = errors.New("invalid IP filter rules")
+)
+
+// Config defines the IP filter options.
+type Config struct {
+	// File is the path of the JSON rules file. An empty path disables the filter.
+	File string `env:"FILE"            envDefault:""`
+
+	// ReloadInterval defines how often the rules file is checked for
+	// changes. Zero interval disables reloading.
+	ReloadInterval time.Duration `env:"RELOAD_INTERVAL" envDefault:"10s"`
+}
+
+// List contains the IP addresses and CIDR ranges, IPv4 or IPv6, connections
+// are allowed or denied from.
+type List struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// Rules contains the default rules applied to all things and the rules of
+// the individual things, mapped by the thing ID. The connection must pass
+// both the default and the thing rules.
+type Rules struct {
+	Default List            `json:"default"`
+	Things  map[string]List `json:"things,omitempty"`
+}
+
+// Filter checks whether things may connect from an IP address.
+type Filter interface {
+	// Check returns ErrDenied if the IP address is denied, or if it is not
+	// allowed while the allowlist is not empty. Empty thing ID checks only
+	// the default rules. Empty IP address, which is unknown, is denied if
+	// any of the checked rules are set.
+	Check(ip, thingID string) error
+}
+
+type list struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+type rules struct {
+	def    list
+	things map[string]list
+}
+
+var _ Filter = (*filter)(nil)
+
+type filter struct {
+	cfg     Config
+	rules   atomic.Pointer[rules]
+	modTime time.Time
+	logger  *slog.Logger
+}
+
+// New returns the filter with the rules read from the configured file. The
+// file is checked for changes every reload interval until the context is
+// canceled, so the rules can be changed without restart. If the changed file
+// is invalid, the previous rules are kept. If the file is not configured, nil
+// filter is returned.
+func New(ctx context.Context, cfg Config, logger *slog.Logger) (Filter, error) {
+	if cfg.File == "" {
+		return nil, nil
+	}
+
+	f := &filter{cfg: cfg, logger: logger}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	if cfg.ReloadInterval > 0 {
+		go f.watch(ctx)
+	}
+
+	return f, nil
+}
+
+// NewStatic returns the filter with the given rules.
+func NewStatic(r Rules) (Filter, error) {
+	parsed, err := parseRules(r)
+	if err != nil {
+		return nil, err
+	}
+	f := &filter{}
+	f.rules.Store(parsed)
+
+	return f, nil
+}
+
+func (f *filter) Check(ip, thingID string) error {
+	r := f.rules.Load()
+	if ip == "" {
+		// The address is unknown, so it can pass only if there are no rules
+		// it would have to pass.
+		if !r.def.empty() || (thingID != "" && !r.things[thingID].empty()) {
+			return errors.Wrap(ErrDenied, errors.New("unknown IP address"))
+		}
+		return nil
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return errors.Wrap(ErrDenied, fmt.Errorf("invalid IP address %q", ip))
+	}
+	addr = addr.Unmap()
+
+	if err := r.def.check(addr); err != nil {
+		return errors.Wrap(ErrDenied, fmt.Errorf("%s by default rules", err))
+	}
+	if l, ok := r.things[thingID]; ok && thingID != "" {
+		if err := l.check(addr); err != nil {
+			return errors.Wrap(ErrDenied, fmt.Errorf("%s by rules of thing %s", err, thingID))
+		}
+	}
+
+	return nil
+}
+
+func (l list) empty() bool {
+	return len(l.allow) == 0 && len(l.deny) == 0
+}
+
+func (l list) check(addr netip.Addr) error {
+	for _, p := range l.deny {
+		if p.Contains(addr) {
+			return fmt.Errorf("%s is denied by %s", addr, p)
+		}
+	}
+	if len(l.allow) == 0 {
+		return nil
+	}
+	for _, p := range l.allow {
+		if p.Contains(addr) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not allowed", addr)
+}
+
+func (f *filter) watch(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(f.cfg.File)
+			if err != nil {
+				f.logger.Error(fmt.Sprintf("failed to check IP filter rules file %s: %s", f.cfg.File, err))
+				continue
+			}
+			if info.ModTime().Equal(f.modTime) {
+				continue
+			}
+			if err := f.load(); err != nil {
+				f.logger.Error(fmt.Sprintf("failed to reload IP filter rules, keeping previous rules: %s", err))
+				continue
+			}
+			f.logger.Info(fmt.Sprintf("reloaded IP filter rules from %s", f.cfg.File))
+		}
+	}
+}
+
+func (f *filter) load() error {
+	info, err := os.Stat(f.cfg.File)
+	if err != nil {
+		return errors.Wrap(errInvalidRules, err)
+	}
+	data, err := os.ReadFile(f.cfg.File)
+	if err != nil {
+		return errors.Wrap(errInvalidRules, err)
+	}
+	var r Rules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return errors.Wrap(errInvalidRules, err)
+	}
+	parsed, err := parseRules(r)
+	if err != nil {
+		return err
+	}
+	f.rules.Store(parsed)
+	// A failed load is retried until the file changes again.
+	f.modTime = info.ModTime()
+
+	return nil
+}
+
+func parseRules(r Rules) (*rules, error) {
+	def, err := parseList(r.Default)
+	if err != nil {
+		return nil, errors.Wrap(errInvalidRules, fmt.Errorf("default rules: %w", err))
+	}
+	parsed := &rules{def: def, things: make(map[string]list, len(r.Things))}
+	for id, l := range r.Things {
+		if parsed.things[id], err = parseList(l); err != nil {
+			return nil, errors.Wrap(errInvalidRules, fmt.Errorf("rules of thing %s: %w", id, err))
+		}
+	}
+
+	return parsed, nil
+}
+
+func parseList(l List) (list, error) {
+	allow, err := parsePrefixes(l.Allow)
+	if err != nil {
+		return list{}, err
+	}
+	deny, err := parsePrefixes(l.Deny)
+	if err != nil {
+		return list{}, err
+	}
+
+	return list{allow: allow, deny: deny}, nil
+}
+
+// parsePrefixes parses CIDR ranges and single IP addresses, which are
+// treated as ranges containing only that address.
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		if strings.Contains(v, "/") {
+			p, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, err
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+var _ Filter = (*metricsFilter)(nil)
+
+type metricsFilter struct {
+	filter   Filter
+	rejected metrics.Counter
+}
+
+// NewMetricsFilter returns filter which counts rejected connections.
+func NewMetricsFilter(f Filter, rejected metrics.Counter) Filter {
+	return &metricsFilter{
+		filter:   f,
+		rejected: rejected,
+	}
+}
+
+func (mf *metricsFilter) Check(ip, thingID string) error {
+	err := mf.filter.Check(ip, thingID)
+	if errors.Contains(err, ErrDenied) {
+		mf.rejected.Add(1)
+	}
+
+	return err
+}
+
+type remoteIPKey struct{}
+
+// WithRemoteIP returns a copy of the context carrying the remote IP address.
+func WithRemoteIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, remoteIPKey{}, ip)
+}
+
+// RemoteIP returns the remote IP address carried by the context.
+func RemoteIP(ctx context.Context) string {
+	ip, _ := ctx.Value(remoteIPKey{}).(string)
+	return ip
+}
+
+// AddrIP returns the IP address of the network address.
+func AddrIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// RemoteIPMiddleware stores the IP address of the request remote address in
+// the request context, so it is available to the adapter handler.
+func RemoteIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithRemoteIP(r.Context(), AddrIP(r.RemoteAddr)))
+		next.ServeHTTP(w, r)
+	})
+}
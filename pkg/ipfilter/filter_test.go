@@ -0,0 +1,134 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package ipfilter_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/stretchr/testify/assert"
+)
+
+const thingID = "thing"
+
+func TestCheck(t *testing.T) {
+	f, err := ipfilter.NewStatic(ipfilter.Rules{
+		Default: ipfilter.List{Deny: []string{"203.0.113.0/24", "2001:db8:bad::/48"}},
+		Things: map[string]ipfilter.List{
+			thingID: {Allow: []string{"10.0.0.0/8", "192.0.2.7", "2001:db8::/32"}, Deny: []string{"10.1.0.0/16"}},
+		},
+	})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating filter: %s", err))
+
+	cases := []struct {
+		desc    string
+		ip      string
+		thingID string
+		err     error
+	}{
+		{desc: "check allowed IPv4 range", ip: "10.2.3.4", thingID: thingID},
+		{desc: "check allowed single IPv4", ip: "192.0.2.7", thingID: thingID},
+		{desc: "check allowed IPv6 range", ip: "2001:db8::1", thingID: thingID},
+		{desc: "check IPv4 mapped IPv6 address", ip: "::ffff:10.2.3.4", thingID: thingID},
+		{desc: "check IP not in thing allowlist", ip: "192.0.2.8", thingID: thingID, err: ipfilter.ErrDenied},
+		{desc: "check IP denied by thing denylist", ip: "10.1.2.3", thingID: thingID, err: ipfilter.ErrDenied},
+		{desc: "check IP denied by default denylist", ip: "2001:db8:bad::1", thingID: thingID, err: ipfilter.ErrDenied},
+		{desc: "check IP of thing without rules", ip: "192.0.2.8", thingID: "other"},
+		{desc: "check default rules only", ip: "203.0.113.5", err: ipfilter.ErrDenied},
+		{desc: "check invalid IP", ip: "invalid", thingID: thingID, err: ipfilter.ErrDenied},
+		{desc: "check empty IP", thingID: thingID, err: ipfilter.ErrDenied},
+		{desc: "check empty IP with default rules only", err: ipfilter.ErrDenied},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := f.Check(tc.ip, tc.thingID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		})
+	}
+}
+
+func TestCheckUnknownIP(t *testing.T) {
+	f, err := ipfilter.NewStatic(ipfilter.Rules{
+		Things: map[string]ipfilter.List{
+			thingID: {Allow: []string{"10.0.0.0/8"}},
+		},
+	})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating filter: %s", err))
+
+	cases := []struct {
+		desc    string
+		thingID string
+		err     error
+	}{
+		{desc: "check unknown IP of thing with rules", thingID: thingID, err: ipfilter.ErrDenied},
+		{desc: "check unknown IP of thing without rules", thingID: "other"},
+		{desc: "check unknown IP without default rules"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := f.Check("", tc.thingID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		})
+	}
+}
+
+func TestNewStaticInvalid(t *testing.T) {
+	cases := []struct {
+		desc  string
+		rules ipfilter.Rules
+	}{
+		{desc: "invalid default CIDR", rules: ipfilter.Rules{Default: ipfilter.List{Allow: []string{"10.0.0.0/33"}}}},
+		{desc: "invalid thing IP", rules: ipfilter.Rules{Things: map[string]ipfilter.List{thingID: {Deny: []string{"10.0.0"}}}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ipfilter.NewStatic(tc.rules)
+			assert.NotNil(t, err, fmt.Sprintf("%s: expected error", tc.desc))
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.json")
+	write := func(content string, mod time.Time) {
+		err := os.WriteFile(file, []byte(content), 0o600)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error writing rules: %s", err))
+		err = os.Chtimes(file, mod, mod)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error setting rules time: %s", err))
+	}
+	start := time.Now().Add(-time.Hour)
+	write(`{"default": {"deny": ["192.0.2.1"]}}`, start)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f, err := ipfilter.New(ctx, ipfilter.Config{File: file, ReloadInterval: 10 * time.Millisecond}, mglog.NewMock())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating filter: %s", err))
+	assert.True(t, errors.Contains(f.Check("192.0.2.1", thingID), ipfilter.ErrDenied), "expected IP to be denied")
+	assert.Nil(t, f.Check("192.0.2.2", thingID), "expected IP to be allowed")
+
+	write(`{"default": {"deny": ["192.0.2.2"]}}`, start.Add(time.Minute))
+	assert.Eventually(t, func() bool {
+		return f.Check("192.0.2.1", thingID) == nil && f.Check("192.0.2.2", thingID) != nil
+	}, time.Second, 10*time.Millisecond, "expected reloaded rules")
+
+	write(`{"default": {"deny": ["invalid"]}}`, start.Add(2*time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, errors.Contains(f.Check("192.0.2.2", thingID), ipfilter.ErrDenied), "expected previous rules to be kept")
+}
+
+func TestNewWithoutFile(t *testing.T) {
+	f, err := ipfilter.New(context.Background(), ipfilter.Config{}, mglog.NewMock())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating filter: %s", err))
+	assert.Nil(t, f, "expected disabled filter")
+}
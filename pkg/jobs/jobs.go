@@ -0,0 +1,112 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+var (
+	// ErrFinished indicates that the job has already finished.
+	ErrFinished = errors.New("job has already finished")
+
+	// ErrUnknownKind indicates that there is no handler for the job kind.
+	ErrUnknownKind = errors.New("unknown job kind")
+
+	// ErrCanceled indicates that the job has been canceled.
+	ErrCanceled = errors.New("job has been canceled")
+)
+
+// Status represents the job status.
+type Status string
+
+const (
+	// PendingStatus is the status of a job waiting to be run.
+	PendingStatus Status = "pending"
+	// RunningStatus is the status of a job being run.
+	RunningStatus Status = "running"
+	// CompletedStatus is the status of a successfully finished job.
+	CompletedStatus Status = "completed"
+	// FailedStatus is the status of a job which finished with an error.
+	FailedStatus Status = "failed"
+	// CanceledStatus is the status of a canceled job.
+	CanceledStatus Status = "canceled"
+)
+
+// Finished returns true if the job with the status will not be run anymore.
+func (s Status) Finished() bool {
+	return s == CompletedStatus || s == FailedStatus || s == CanceledStatus
+}
+
+// Job represents a long-running operation processing Total items.
+type Job struct {
+	ID        string            `json:"id"`
+	Kind      string            `json:"kind"`
+	DomainID  string            `json:"domain_id,omitempty"`
+	UserID    string            `json:"user_id"`
+	Status    Status            `json:"status"`
+	Total     uint64            `json:"total"`
+	Processed uint64            `json:"processed"`
+	Payload   json.RawMessage   `json:"-"`
+	Results   []json.RawMessage `json:"results,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ProgressFunc saves the number of processed items and appends the results
+// of the items processed since the last call to the job results. It returns
+// ErrCanceled if the job has been canceled in the meantime.
+type ProgressFunc func(processed uint64, results ...interface{}) error
+
+// Handler runs the jobs of a kind. The job is passed as last saved, so the
+// handler of a resumed job continues from job.Processed. The handler should
+// report progress after each batch of items, and stop once the context is
+// canceled.
+type Handler func(ctx context.Context, job Job, progress ProgressFunc) error
+
+// Service specifies an API for submitting, viewing and canceling jobs.
+//
+//go:generate mockery --name Service --output=./mocks --filename service.go --quiet --note "Copyright (c) Abstract Machines"
+type Service interface {
+	// Submit saves a pending job of the given kind processing total items
+	// and returns it. The job is run in the background.
+	Submit(ctx context.Context, session authn.Session, kind string, total uint64, payload interface{}) (Job, error)
+
+	// View retrieves the job with the given ID.
+	View(ctx context.Context, session authn.Session, id string) (Job, error)
+
+	// Cancel cancels the pending or running job with the given ID.
+	Cancel(ctx context.Context, session authn.Session, id string) (Job, error)
+}
+
+// Repository specifies a jobs persistence API. Since the jobs are persisted,
+// the jobs interrupted by a restart are resumed.
+type Repository interface {
+	// Save persists the job.
+	Save(ctx context.Context, job Job) error
+
+	// RetrieveByID retrieves the job with the given ID.
+	RetrieveByID(ctx context.Context, id string) (Job, error)
+
+	// Claim marks the oldest pending job, or the oldest running job which
+	// has not been updated since the given time, as running and returns it.
+	// It returns repository ErrNotFound if there is no such job.
+	Claim(ctx context.Context, staleBefore time.Time) (Job, error)
+
+	// Update saves the status, progress, results and error of the job,
+	// unless the saved job has already finished.
+	// It returns repository ErrNotFound if the saved job has finished.
+	Update(ctx context.Context, job Job) error
+
+	// Cancel marks the pending or running job with the given ID as canceled
+	// and returns it.
+	// It returns repository ErrNotFound if there is no such job.
+	Cancel(ctx context.Context, id string) (Job, error)
+}
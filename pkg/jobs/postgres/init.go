@@ -0,0 +1,40 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	_ "github.com/jackc/pgx/v5/stdlib" // required for SQL access
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+func Migration() *migrate.MemoryMigrationSource {
+	return &migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{
+			{
+				Id: "jobs_01",
+				// RESULTS holds the JSON array of the results of the processed items.
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS jobs (
+						id			VARCHAR(36) PRIMARY KEY,
+						kind		VARCHAR(254) NOT NULL,
+						domain_id	VARCHAR(36),
+						user_id		VARCHAR(36) NOT NULL,
+						status		VARCHAR(16) NOT NULL,
+						total		BIGINT NOT NULL DEFAULT 0,
+						processed	BIGINT NOT NULL DEFAULT 0,
+						payload		JSONB,
+						results		JSONB,
+						error		TEXT,
+						created_at	TIMESTAMP NOT NULL,
+						updated_at	TIMESTAMP NOT NULL
+					)`,
+					`CREATE INDEX IF NOT EXISTS jobs_status_created_at_idx ON jobs (status, created_at)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS jobs`,
+				},
+			},
+		},
+	}
+}
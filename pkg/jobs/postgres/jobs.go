@@ -0,0 +1,193 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	"github.com/absmach/magistrala/pkg/jobs"
+	"github.com/absmach/magistrala/pkg/postgres"
+)
+
+const columns = `id, kind, COALESCE(domain_id, '') AS domain_id, user_id, status, total, processed,
+	payload, results, COALESCE(error, '') AS error, created_at, updated_at`
+
+var _ jobs.Repository = (*jobRepository)(nil)
+
+type jobRepository struct {
+	db postgres.Database
+}
+
+// New instantiates a PostgreSQL implementation of jobs repository.
+func New(db postgres.Database) jobs.Repository {
+	return &jobRepository{
+		db: db,
+	}
+}
+
+func (repo jobRepository) Save(ctx context.Context, job jobs.Job) error {
+	q := `INSERT INTO jobs (id, kind, domain_id, user_id, status, total, processed, payload, results, error, created_at, updated_at)
+		VALUES (:id, :kind, :domain_id, :user_id, :status, :total, :processed, :payload, :results, :error, :created_at, :updated_at)`
+
+	dbj, err := toDBJob(job)
+	if err != nil {
+		return errors.Wrap(repoerr.ErrCreateEntity, err)
+	}
+	if _, err := repo.db.NamedExecContext(ctx, q, dbj); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo jobRepository) RetrieveByID(ctx context.Context, id string) (jobs.Job, error) {
+	q := `SELECT ` + columns + ` FROM jobs WHERE id = :id`
+
+	return repo.retrieve(ctx, q, dbJob{ID: id}, repoerr.ErrViewEntity)
+}
+
+func (repo jobRepository) Claim(ctx context.Context, staleBefore time.Time) (jobs.Job, error) {
+	q := `UPDATE jobs SET status = :status, updated_at = :updated_at
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'pending' OR (status = 'running' AND updated_at < :stale_before)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + columns
+
+	params := map[string]interface{}{
+		"status":       string(jobs.RunningStatus),
+		"updated_at":   time.Now().UTC(),
+		"stale_before": staleBefore,
+	}
+
+	return repo.retrieve(ctx, q, params, repoerr.ErrUpdateEntity)
+}
+
+func (repo jobRepository) Update(ctx context.Context, job jobs.Job) error {
+	q := `UPDATE jobs SET status = :status, processed = :processed, results = :results, error = :error, updated_at = :updated_at
+		WHERE id = :id AND status IN ('pending', 'running')`
+
+	dbj, err := toDBJob(job)
+	if err != nil {
+		return errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+	result, err := repo.db.NamedExecContext(ctx, q, dbj)
+	if err != nil {
+		return postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repoerr.ErrNotFound
+	}
+
+	return nil
+}
+
+func (repo jobRepository) Cancel(ctx context.Context, id string) (jobs.Job, error) {
+	q := `UPDATE jobs SET status = :status, updated_at = :updated_at
+		WHERE id = :id AND status IN ('pending', 'running')
+		RETURNING ` + columns
+
+	dbj := dbJob{
+		ID:        id,
+		Status:    string(jobs.CanceledStatus),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	return repo.retrieve(ctx, q, dbj, repoerr.ErrUpdateEntity)
+}
+
+// retrieve returns the job returned by the query, or repository ErrNotFound
+// if the query returns no job.
+func (repo jobRepository) retrieve(ctx context.Context, q string, params interface{}, wrapper error) (jobs.Job, error) {
+	rows, err := repo.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return jobs.Job{}, postgres.HandleError(wrapper, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return jobs.Job{}, errors.Wrap(wrapper, err)
+		}
+		return jobs.Job{}, repoerr.ErrNotFound
+	}
+	dbj := dbJob{}
+	if err := rows.StructScan(&dbj); err != nil {
+		return jobs.Job{}, errors.Wrap(wrapper, err)
+	}
+
+	return toJob(dbj)
+}
+
+type dbJob struct {
+	ID        string    `db:"id"`
+	Kind      string    `db:"kind"`
+	DomainID  string    `db:"domain_id"`
+	UserID    string    `db:"user_id"`
+	Status    string    `db:"status"`
+	Total     uint64    `db:"total"`
+	Processed uint64    `db:"processed"`
+	Payload   []byte    `db:"payload"`
+	Results   []byte    `db:"results"`
+	Error     string    `db:"error"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func toDBJob(job jobs.Job) (dbJob, error) {
+	var results []byte
+	if len(job.Results) > 0 {
+		b, err := json.Marshal(job.Results)
+		if err != nil {
+			return dbJob{}, errors.Wrap(errors.ErrMalformedEntity, err)
+		}
+		results = b
+	}
+
+	return dbJob{
+		ID:        job.ID,
+		Kind:      job.Kind,
+		DomainID:  job.DomainID,
+		UserID:    job.UserID,
+		Status:    string(job.Status),
+		Total:     job.Total,
+		Processed: job.Processed,
+		Payload:   job.Payload,
+		Results:   results,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}, nil
+}
+
+func toJob(dbj dbJob) (jobs.Job, error) {
+	var results []json.RawMessage
+	if len(dbj.Results) > 0 {
+		if err := json.Unmarshal(dbj.Results, &results); err != nil {
+			return jobs.Job{}, errors.Wrap(errors.ErrMalformedEntity, err)
+		}
+	}
+
+	return jobs.Job{
+		ID:        dbj.ID,
+		Kind:      dbj.Kind,
+		DomainID:  dbj.DomainID,
+		UserID:    dbj.UserID,
+		Status:    jobs.Status(dbj.Status),
+		Total:     dbj.Total,
+		Processed: dbj.Processed,
+		Payload:   dbj.Payload,
+		Results:   results,
+		Error:     dbj.Error,
+		CreatedAt: dbj.CreatedAt.UTC(),
+		UpdatedAt: dbj.UpdatedAt.UTC(),
+	}, nil
+}
@@ -0,0 +1,258 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+// requeueTimeout bounds the time spent returning the running jobs to the
+// queue once the runner stops.
+const requeueTimeout = 5 * time.Second
+
+var _ Service = (*Runner)(nil)
+
+// Config contains the job runner parameters.
+type Config struct {
+	Workers      int           `env:"WORKERS"       envDefault:"2"`
+	PollInterval time.Duration `env:"POLL_INTERVAL" envDefault:"5s"`
+	StaleAfter   time.Duration `env:"STALE_AFTER"   envDefault:"5m"`
+}
+
+// Runner runs the persisted jobs in the background.
+//
+// The jobs are queued in the repository, so any runner sharing the repository
+// may run them. A job running on a stopped runner is returned to the queue,
+// and a job whose runner crashed is run again once it has not reported
+// progress for cfg.StaleAfter. Either way, the job is resumed from the last
+// reported progress.
+type Runner struct {
+	repo     Repository
+	idp      magistrala.IDProvider
+	handlers map[string]Handler
+	cfg      Config
+	logger   *slog.Logger
+	wake     chan struct{}
+	mu       sync.Mutex
+	cancels  map[string]context.CancelFunc
+}
+
+// NewRunner returns the runner of the jobs of the kinds handled by the
+// handlers.
+func NewRunner(repo Repository, idp magistrala.IDProvider, handlers map[string]Handler, cfg Config, logger *slog.Logger) *Runner {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+
+	return &Runner{
+		repo:     repo,
+		idp:      idp,
+		handlers: handlers,
+		cfg:      cfg,
+		logger:   logger,
+		wake:     make(chan struct{}, 1),
+		cancels:  make(map[string]context.CancelFunc),
+	}
+}
+
+func (r *Runner) Submit(ctx context.Context, session authn.Session, kind string, total uint64, payload interface{}) (Job, error) {
+	if _, ok := r.handlers[kind]; !ok {
+		return Job{}, errors.Wrap(svcerr.ErrMalformedEntity, ErrUnknownKind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	id, err := r.idp.ID()
+	if err != nil {
+		return Job{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+
+	now := time.Now().UTC()
+	job := Job{
+		ID:        id,
+		Kind:      kind,
+		DomainID:  session.DomainID,
+		UserID:    session.UserID,
+		Status:    PendingStatus,
+		Total:     total,
+		Payload:   data,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := r.repo.Save(ctx, job); err != nil {
+		return Job{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	r.notify()
+
+	return job, nil
+}
+
+func (r *Runner) View(ctx context.Context, session authn.Session, id string) (Job, error) {
+	job, err := r.repo.RetrieveByID(ctx, id)
+	if err != nil {
+		if errors.Contains(err, repoerr.ErrNotFound) {
+			return Job{}, svcerr.ErrNotFound
+		}
+		return Job{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	if job.UserID != session.UserID && !session.SuperAdmin {
+		return Job{}, svcerr.ErrAuthorization
+	}
+
+	return job, nil
+}
+
+func (r *Runner) Cancel(ctx context.Context, session authn.Session, id string) (Job, error) {
+	job, err := r.View(ctx, session, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Status.Finished() {
+		return Job{}, ErrFinished
+	}
+	job, err = r.repo.Cancel(ctx, id)
+	if err != nil {
+		if errors.Contains(err, repoerr.ErrNotFound) {
+			return Job{}, ErrFinished
+		}
+		return Job{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	// A job running on another runner stops once it reports progress.
+	r.mu.Lock()
+	if cancel, ok := r.cancels[id]; ok {
+		cancel()
+	}
+	r.mu.Unlock()
+
+	return job, nil
+}
+
+// Run runs the queued jobs with cfg.Workers workers until the context is
+// canceled.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < r.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *Runner) work(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for r.runNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify wakes up an idle worker.
+func (r *Runner) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runNext claims and runs the next job. It returns false if there is no job
+// to run.
+func (r *Runner) runNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	job, err := r.repo.Claim(ctx, time.Now().UTC().Add(-r.cfg.StaleAfter))
+	if err != nil {
+		if !errors.Contains(err, repoerr.ErrNotFound) {
+			r.logger.Error("failed to claim job", slog.Any("error", err))
+		}
+		return false
+	}
+	r.run(ctx, job)
+
+	return true
+}
+
+func (r *Runner) run(ctx context.Context, job Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	r.mu.Lock()
+	r.cancels[job.ID] = cancel
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.cancels, job.ID)
+		r.mu.Unlock()
+		cancel()
+	}()
+
+	var err error = ErrUnknownKind
+	if handler, ok := r.handlers[job.Kind]; ok {
+		err = handler(jobCtx, job, r.progress(ctx, &job))
+	}
+
+	switch {
+	case err == nil:
+		job.Status = CompletedStatus
+	case ctx.Err() != nil:
+		// The runner is stopping, so the job is resumed by the next runner.
+		job.Status = PendingStatus
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeout(context.Background(), requeueTimeout)
+		defer stop()
+	case errors.Contains(err, ErrCanceled), jobCtx.Err() != nil:
+		// The canceled status has already been saved.
+		return
+	default:
+		job.Status = FailedStatus
+		job.Error = err.Error()
+	}
+	job.UpdatedAt = time.Now().UTC()
+	if err := r.repo.Update(ctx, job); err != nil && !errors.Contains(err, repoerr.ErrNotFound) {
+		r.logger.Error("failed to save job", slog.String("id", job.ID), slog.String("status", string(job.Status)), slog.Any("error", err))
+	}
+}
+
+func (r *Runner) progress(ctx context.Context, job *Job) ProgressFunc {
+	return func(processed uint64, results ...interface{}) error {
+		for _, res := range results {
+			data, err := json.Marshal(res)
+			if err != nil {
+				return err
+			}
+			job.Results = append(job.Results, data)
+		}
+		job.Processed = processed
+		job.UpdatedAt = time.Now().UTC()
+		if err := r.repo.Update(ctx, *job); err != nil {
+			if errors.Contains(err, repoerr.ErrNotFound) {
+				return ErrCanceled
+			}
+			return err
+		}
+
+		return nil
+	}
+}
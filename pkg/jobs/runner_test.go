@@ -0,0 +1,408 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/jobs"
+	"github.com/absmach/magistrala/pkg/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	countKind = "count"
+	blockKind = "block"
+	waitFor   = 5 * time.Second
+	tick      = 10 * time.Millisecond
+)
+
+var (
+	logger  = slog.New(slog.NewTextHandler(io.Discard, nil))
+	session = authn.Session{UserID: "user", DomainID: "domain"}
+	cfg     = jobs.Config{Workers: 2, PollInterval: 50 * time.Millisecond, StaleAfter: time.Minute}
+)
+
+// memRepository is an in-memory jobs repository.
+type memRepository struct {
+	mu   sync.Mutex
+	jobs map[string]jobs.Job
+}
+
+func newRepository() *memRepository {
+	return &memRepository{jobs: make(map[string]jobs.Job)}
+}
+
+func (repo *memRepository) Save(_ context.Context, job jobs.Job) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.jobs[job.ID] = job
+
+	return nil
+}
+
+func (repo *memRepository) RetrieveByID(_ context.Context, id string) (jobs.Job, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	job, ok := repo.jobs[id]
+	if !ok {
+		return jobs.Job{}, repoerr.ErrNotFound
+	}
+
+	return job, nil
+}
+
+func (repo *memRepository) Claim(_ context.Context, staleBefore time.Time) (jobs.Job, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	var claimed jobs.Job
+	for _, job := range repo.jobs {
+		stale := job.Status == jobs.RunningStatus && job.UpdatedAt.Before(staleBefore)
+		if job.Status != jobs.PendingStatus && !stale {
+			continue
+		}
+		if claimed.ID == "" || job.CreatedAt.Before(claimed.CreatedAt) {
+			claimed = job
+		}
+	}
+	if claimed.ID == "" {
+		return jobs.Job{}, repoerr.ErrNotFound
+	}
+	claimed.Status = jobs.RunningStatus
+	claimed.UpdatedAt = time.Now().UTC()
+	repo.jobs[claimed.ID] = claimed
+
+	return claimed, nil
+}
+
+func (repo *memRepository) Update(_ context.Context, job jobs.Job) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	saved, ok := repo.jobs[job.ID]
+	if !ok || saved.Status.Finished() {
+		return repoerr.ErrNotFound
+	}
+	saved.Status = job.Status
+	saved.Processed = job.Processed
+	saved.Results = job.Results
+	saved.Error = job.Error
+	saved.UpdatedAt = job.UpdatedAt
+	repo.jobs[job.ID] = saved
+
+	return nil
+}
+
+func (repo *memRepository) Cancel(_ context.Context, id string) (jobs.Job, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	job, ok := repo.jobs[id]
+	if !ok || job.Status.Finished() {
+		return jobs.Job{}, repoerr.ErrNotFound
+	}
+	job.Status = jobs.CanceledStatus
+	job.UpdatedAt = time.Now().UTC()
+	repo.jobs[id] = job
+
+	return job, nil
+}
+
+// count reports each item as processed, with the item index as its result.
+func count(ctx context.Context, job jobs.Job, progress jobs.ProgressFunc) error {
+	for i := job.Processed; i < job.Total; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := progress(i+1, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blocker processes an item once released, until the context is canceled.
+type blocker struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlocker() *blocker {
+	return &blocker{
+		started: make(chan string, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blocker) handle(ctx context.Context, job jobs.Job, progress jobs.ProgressFunc) error {
+	b.started <- job.ID
+	for i := job.Processed; i < job.Total; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.release:
+		}
+		if err := progress(i + 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func startRunner(t *testing.T, repo jobs.Repository, handlers map[string]jobs.Handler) (*jobs.Runner, context.CancelFunc) {
+	runner := jobs.NewRunner(repo, uuid.New(), handlers, cfg, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+
+	return runner, stop
+}
+
+func waitStatus(t *testing.T, runner *jobs.Runner, id string, status jobs.Status) jobs.Job {
+	var job jobs.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = runner.View(context.Background(), session, id)
+		return err == nil && job.Status == status
+	}, waitFor, tick, fmt.Sprintf("expected job status %s, got %s", status, job.Status))
+
+	return job
+}
+
+func TestSubmit(t *testing.T) {
+	runner, _ := startRunner(t, newRepository(), map[string]jobs.Handler{countKind: count})
+
+	cases := []struct {
+		desc    string
+		kind    string
+		total   uint64
+		payload interface{}
+		err     error
+	}{
+		{
+			desc:    "submit job",
+			kind:    countKind,
+			total:   5,
+			payload: map[string]string{"key": "value"},
+		},
+		{
+			desc:  "submit job of unknown kind",
+			kind:  "unknown",
+			total: 5,
+			err:   jobs.ErrUnknownKind,
+		},
+		{
+			desc:    "submit job with invalid payload",
+			kind:    countKind,
+			total:   5,
+			payload: make(chan int),
+			err:     svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			job, err := runner.Submit(context.Background(), session, tc.kind, tc.total, tc.payload)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if err != nil {
+				return
+			}
+			assert.Equal(t, jobs.PendingStatus, job.Status, fmt.Sprintf("%s: expected pending job", tc.desc))
+
+			job = waitStatus(t, runner, job.ID, jobs.CompletedStatus)
+			assert.Equal(t, tc.total, job.Processed, fmt.Sprintf("%s: expected %d processed items got %d", tc.desc, tc.total, job.Processed))
+			var results []uint64
+			for _, res := range job.Results {
+				var i uint64
+				require.Nil(t, json.Unmarshal(res, &i), fmt.Sprintf("%s: unexpected result %s", tc.desc, res))
+				results = append(results, i)
+			}
+			assert.Equal(t, []uint64{0, 1, 2, 3, 4}, results, fmt.Sprintf("%s: unexpected results", tc.desc))
+		})
+	}
+}
+
+func TestFailedJob(t *testing.T) {
+	errProcess := errors.New("failed to process item")
+	fail := func(_ context.Context, _ jobs.Job, progress jobs.ProgressFunc) error {
+		if err := progress(1); err != nil {
+			return err
+		}
+		return errProcess
+	}
+	runner, _ := startRunner(t, newRepository(), map[string]jobs.Handler{countKind: fail})
+
+	job, err := runner.Submit(context.Background(), session, countKind, 3, nil)
+	require.Nil(t, err, fmt.Sprintf("submitting job expected to succeed: %s", err))
+
+	job = waitStatus(t, runner, job.ID, jobs.FailedStatus)
+	assert.Equal(t, uint64(1), job.Processed, fmt.Sprintf("expected 1 processed item got %d", job.Processed))
+	assert.Equal(t, errProcess.Error(), job.Error, fmt.Sprintf("expected error %s got %s", errProcess, job.Error))
+}
+
+func TestView(t *testing.T) {
+	runner, _ := startRunner(t, newRepository(), map[string]jobs.Handler{countKind: count})
+
+	job, err := runner.Submit(context.Background(), session, countKind, 1, nil)
+	require.Nil(t, err, fmt.Sprintf("submitting job expected to succeed: %s", err))
+
+	cases := []struct {
+		desc    string
+		session authn.Session
+		id      string
+		err     error
+	}{
+		{
+			desc:    "view job",
+			session: session,
+			id:      job.ID,
+		},
+		{
+			desc:    "view job as super admin",
+			session: authn.Session{UserID: "admin", SuperAdmin: true},
+			id:      job.ID,
+		},
+		{
+			desc:    "view job of another user",
+			session: authn.Session{UserID: "other"},
+			id:      job.ID,
+			err:     svcerr.ErrAuthorization,
+		},
+		{
+			desc:    "view non-existing job",
+			session: session,
+			id:      "unknown",
+			err:     svcerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := runner.View(context.Background(), tc.session, tc.id)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, job.ID, res.ID, fmt.Sprintf("%s: expected job %s got %s", tc.desc, job.ID, res.ID))
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	b := newBlocker()
+	runner, _ := startRunner(t, newRepository(), map[string]jobs.Handler{blockKind: b.handle})
+
+	job, err := runner.Submit(context.Background(), session, blockKind, 3, nil)
+	require.Nil(t, err, fmt.Sprintf("submitting job expected to succeed: %s", err))
+	assert.Equal(t, job.ID, <-b.started, "expected submitted job to start")
+	b.release <- struct{}{}
+	waitProcessed(t, runner, job.ID, 1)
+
+	_, err = runner.Cancel(context.Background(), authn.Session{UserID: "other"}, job.ID)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("expected %s got %s", svcerr.ErrAuthorization, err))
+
+	canceled, err := runner.Cancel(context.Background(), session, job.ID)
+	require.Nil(t, err, fmt.Sprintf("canceling running job expected to succeed: %s", err))
+	assert.Equal(t, jobs.CanceledStatus, canceled.Status, fmt.Sprintf("expected canceled job got %s", canceled.Status))
+
+	// The canceled job is not run anymore, and keeps its progress.
+	time.Sleep(3 * cfg.PollInterval)
+	job, err = runner.View(context.Background(), session, job.ID)
+	require.Nil(t, err, fmt.Sprintf("viewing job expected to succeed: %s", err))
+	assert.Equal(t, jobs.CanceledStatus, job.Status, fmt.Sprintf("expected canceled job got %s", job.Status))
+	assert.Equal(t, uint64(1), job.Processed, fmt.Sprintf("expected 1 processed item got %d", job.Processed))
+
+	_, err = runner.Cancel(context.Background(), session, job.ID)
+	assert.True(t, errors.Contains(err, jobs.ErrFinished), fmt.Sprintf("expected %s got %s", jobs.ErrFinished, err))
+}
+
+func TestCancelPending(t *testing.T) {
+	repo := newRepository()
+	runner := jobs.NewRunner(repo, uuid.New(), map[string]jobs.Handler{countKind: count}, cfg, logger)
+
+	job, err := runner.Submit(context.Background(), session, countKind, 3, nil)
+	require.Nil(t, err, fmt.Sprintf("submitting job expected to succeed: %s", err))
+	_, err = runner.Cancel(context.Background(), session, job.ID)
+	require.Nil(t, err, fmt.Sprintf("canceling pending job expected to succeed: %s", err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*cfg.PollInterval)
+	defer cancel()
+	runner.Run(ctx)
+
+	job, err = runner.View(context.Background(), session, job.ID)
+	require.Nil(t, err, fmt.Sprintf("viewing job expected to succeed: %s", err))
+	assert.Equal(t, jobs.CanceledStatus, job.Status, fmt.Sprintf("expected canceled job got %s", job.Status))
+	assert.Equal(t, uint64(0), job.Processed, fmt.Sprintf("expected no processed items got %d", job.Processed))
+}
+
+func TestResume(t *testing.T) {
+	repo := newRepository()
+	b := newBlocker()
+	runner, stop := startRunner(t, repo, map[string]jobs.Handler{blockKind: b.handle})
+
+	job, err := runner.Submit(context.Background(), session, blockKind, 3, nil)
+	require.Nil(t, err, fmt.Sprintf("submitting job expected to succeed: %s", err))
+	<-b.started
+	b.release <- struct{}{}
+	waitProcessed(t, runner, job.ID, 1)
+
+	// The job of the stopped runner is resumed by the next runner.
+	stop()
+	job, err = repo.RetrieveByID(context.Background(), job.ID)
+	require.Nil(t, err, fmt.Sprintf("retrieving job expected to succeed: %s", err))
+	assert.Equal(t, jobs.PendingStatus, job.Status, fmt.Sprintf("expected pending job got %s", job.Status))
+
+	runner, _ = startRunner(t, repo, map[string]jobs.Handler{blockKind: b.handle})
+	<-b.started
+	b.release <- struct{}{}
+	b.release <- struct{}{}
+	job = waitStatus(t, runner, job.ID, jobs.CompletedStatus)
+	assert.Equal(t, uint64(3), job.Processed, fmt.Sprintf("expected 3 processed items got %d", job.Processed))
+}
+
+func TestResumeStale(t *testing.T) {
+	repo := newRepository()
+	stale := time.Now().UTC().Add(-2 * cfg.StaleAfter)
+	job := jobs.Job{
+		ID:        "stale",
+		Kind:      countKind,
+		UserID:    session.UserID,
+		Status:    jobs.RunningStatus,
+		Total:     3,
+		Processed: 2,
+		CreatedAt: stale,
+		UpdatedAt: stale,
+	}
+	require.Nil(t, repo.Save(context.Background(), job), "saving job expected to succeed")
+
+	runner, _ := startRunner(t, repo, map[string]jobs.Handler{countKind: count})
+	job = waitStatus(t, runner, job.ID, jobs.CompletedStatus)
+	assert.Equal(t, 1, len(job.Results), fmt.Sprintf("expected only the remaining item to be processed, got %d results", len(job.Results)))
+}
+
+func waitProcessed(t *testing.T, runner *jobs.Runner, id string, processed uint64) {
+	require.Eventually(t, func() bool {
+		job, err := runner.View(context.Background(), session, id)
+		return err == nil && job.Processed == processed
+	}, waitFor, tick, fmt.Sprintf("expected %d processed items", processed))
+}
@@ -18,6 +18,9 @@ import (
 // SubjectAllChannels represents subject to subscribe for all the channels.
 const SubjectAllChannels = "channels.>"
 
+// Type represents the message broker the binary was built with.
+const Type = "nats"
+
 func init() {
 	log.Println("The binary was build using Nats as the message broker")
 }
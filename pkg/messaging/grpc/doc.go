@@ -0,0 +1,7 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grpc contains implementation of the messaging Publisher and
+// Subscriber services gRPC API used by internal services to publish messages
+// to channels and to stream the messages of channels.
+package grpc
@@ -0,0 +1,88 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/mproxy/pkg/session"
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	protocol    = "grpc"
+	chansPrefix = "channels"
+)
+
+var (
+	errMalformedSubtopic     = errors.New("malformed subtopic")
+	errInvalidIdempotencyKey = errors.New("idempotency key must not be longer than 256 characters")
+	errFailedSubscribe       = errors.New("failed to subscribe to magistrala message broker")
+)
+
+// publishEndpoint publishes the messages through the session handler of the
+// protocol adapter, so they go through the same IP filter, authorization,
+// content type, rate limit, signing and idempotency checks as the messages
+// published over the adapter.
+func publishEndpoint(handler session.Handler) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(publishReq)
+		if err := req.validate(); err != nil {
+			return publishRes{}, errors.Wrap(errors.ErrMalformedEntity, err)
+		}
+
+		ctx = session.NewContext(ctx, &session.Session{Password: []byte(req.thingKey)})
+		ctx = messaging.WithProtocol(ctx, protocol)
+		if p, ok := peer.FromContext(ctx); ok {
+			ctx = ipfilter.WithRemoteIP(ctx, ipfilter.AddrIP(p.Addr.String()))
+		}
+		topic, payload := req.topic(), req.payload
+		if err := handler.Publish(ctx, &topic, &payload); err != nil {
+			return publishRes{}, err
+		}
+
+		return publishRes{published: true}, nil
+	}
+}
+
+func subscribeEndpoint(things magistrala.ThingsServiceClient, subtopics messaging.SubtopicRules, topics messaging.TopicScheme) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(subscribeReq)
+		if err := req.validate(); err != nil {
+			return subscribeRes{}, errors.Wrap(errors.ErrMalformedEntity, err)
+		}
+
+		subtopic, err := subtopics.Apply(req.subtopic)
+		if err != nil {
+			return subscribeRes{}, errors.Wrap(errors.ErrMalformedEntity, err)
+		}
+
+		res, err := things.Authorize(ctx, &magistrala.ThingsAuthzReq{
+			ThingKey:   req.thingKey,
+			ChannelID:  req.channel,
+			Permission: policies.SubscribePermission,
+		})
+		if err != nil {
+			return subscribeRes{}, err
+		}
+		if !res.GetAuthorized() {
+			return subscribeRes{}, svcerr.ErrAuthorization
+		}
+
+		topic := fmt.Sprintf("%s.%s", chansPrefix, topics.Topic(res.GetDomainId(), req.channel))
+		if subtopic != "" {
+			topic = fmt.Sprintf("%s.%s", topic, subtopic)
+		}
+
+		return subscribeRes{thingID: res.GetId(), topic: topic}, nil
+	}
+}
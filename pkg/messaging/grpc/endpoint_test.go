@@ -0,0 +1,612 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	adapter "github.com/absmach/magistrala/http"
+	httpmocks "github.com/absmach/magistrala/http/mocks"
+	mglog "github.com/absmach/magistrala/logger"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/ipfilter"
+	"github.com/absmach/magistrala/pkg/messaging"
+	grpcapi "github.com/absmach/magistrala/pkg/messaging/grpc"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	rlmocks "github.com/absmach/magistrala/pkg/ratelimit/mocks"
+	"github.com/absmach/magistrala/pkg/uuid"
+	thmocks "github.com/absmach/magistrala/things/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const port = 7019
+
+var (
+	thingID         = "testID"
+	deniedThingID   = "deniedID"
+	thingKey        = "testKey"
+	signingSecret   = "signingSecret"
+	channelID       = "chanID"
+	signedChannelID = "signedChanID"
+	invalid         = "invalid"
+	payload         = []byte(`[{"n":"current","t":-5,"v":1.2}]`)
+)
+
+// pubsub is an in-memory PubSub delivering published messages to the
+// handlers subscribed to the message channel.
+type pubsub struct {
+	mu       sync.Mutex
+	handlers map[string]map[string]messaging.MessageHandler
+	// durable counts the subscriptions which are not ephemeral.
+	durable int
+}
+
+func newPubSub() *pubsub {
+	return &pubsub{handlers: make(map[string]map[string]messaging.MessageHandler)}
+}
+
+func (ps *pubsub) Publish(_ context.Context, topic string, msg *messaging.Message) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, h := range ps.handlers[topic] {
+		if err := h.Handle(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ps *pubsub) Subscribe(_ context.Context, cfg messaging.SubscriberConfig) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.handlers[cfg.Topic] == nil {
+		ps.handlers[cfg.Topic] = make(map[string]messaging.MessageHandler)
+	}
+	ps.handlers[cfg.Topic][cfg.ID] = cfg.Handler
+	if !cfg.Ephemeral {
+		ps.durable++
+	}
+	return nil
+}
+
+func (ps *pubsub) Unsubscribe(_ context.Context, id, topic string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if h, ok := ps.handlers[topic][id]; ok {
+		delete(ps.handlers[topic], id)
+		return h.Cancel()
+	}
+	return nil
+}
+
+// subscriptions returns the number of the subscriptions to the topic.
+func (ps *pubsub) subscriptions(topic string) int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return len(ps.handlers[topic])
+}
+
+func (ps *pubsub) Close() error {
+	return nil
+}
+
+type handler struct {
+	msgs []*messaging.Message
+}
+
+func (h *handler) Handle(msg *messaging.Message) error {
+	h.msgs = append(h.msgs, msg)
+	return nil
+}
+
+func (h *handler) Cancel() error {
+	return nil
+}
+
+func startGRPCServer(pub messaging.Publisher, things magistrala.ThingsServiceClient, idempotency adapter.IdempotencyCache, limiter ratelimit.Limiter, port int) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		panic(fmt.Sprintf("failed to obtain port: %s", err))
+	}
+	ipFilter, err := ipfilter.NewStatic(ipfilter.Rules{Things: map[string]ipfilter.List{deniedThingID: {Deny: []string{"127.0.0.0/8", "::1/128"}}}})
+	if err != nil {
+		panic(fmt.Sprintf("failed to create IP filter: %s", err))
+	}
+	signing, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: signedChannelID})
+	if err != nil {
+		panic(fmt.Sprintf("failed to create signing rules: %s", err))
+	}
+	handler := adapter.NewHandler(pub, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, idempotency, ipFilter, limiter, signing, 0)
+	server := grpc.NewServer()
+	messaging.RegisterPublisherServiceServer(server, grpcapi.NewServer(handler))
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			panic(fmt.Sprintf("failed to serve: %s", err))
+		}
+	}()
+}
+
+func TestPublish(t *testing.T) {
+	ps := newPubSub()
+	things := new(thmocks.ThingsServiceClient)
+	idempotency := new(httpmocks.IdempotencyCache)
+	limiter := new(rlmocks.Limiter)
+	limiter.On("Mode").Return(ratelimit.Reject)
+	startGRPCServer(ps, things, idempotency, limiter, port)
+	conn, err := grpc.NewClient(fmt.Sprintf("localhost:%d", port), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating client: %s", err))
+	client := messaging.NewPublisherServiceClient(conn)
+
+	sub := &handler{}
+	err = ps.Subscribe(context.Background(), messaging.SubscriberConfig{ID: "subscriber", Topic: channelID, Handler: sub})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error subscribing: %s", err))
+	signedSub := &handler{}
+	err = ps.Subscribe(context.Background(), messaging.SubscriberConfig{ID: "subscriber", Topic: signedChannelID, Handler: signedSub})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error subscribing: %s", err))
+
+	cases := []struct {
+		desc         string
+		req          *messaging.PublishReq
+		authorizeRes *magistrala.ThingsAuthzRes
+		authorizeErr error
+		rateErr      error
+		saved        bool
+		delivered    bool
+		code         codes.Code
+	}{
+		{
+			desc: "publish with authorized thing",
+			req: &messaging.PublishReq{
+				ThingKey: thingKey,
+				Channel:  channelID,
+				Subtopic: "sensors.temperature",
+				Payload:  payload,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			delivered:    true,
+			code:         codes.OK,
+		},
+		{
+			desc: "publish with content type",
+			req: &messaging.PublishReq{
+				ThingKey:    thingKey,
+				Channel:     channelID,
+				Payload:     payload,
+				ContentType: "application/senml+json",
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			delivered:    true,
+			code:         codes.OK,
+		},
+		{
+			desc: "publish with unauthorized thing",
+			req: &messaging.PublishReq{
+				ThingKey: invalid,
+				Channel:  channelID,
+				Payload:  payload,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{},
+			authorizeErr: svcerr.ErrAuthorization,
+			code:         codes.PermissionDenied,
+		},
+		{
+			desc: "publish with unauthenticated thing",
+			req: &messaging.PublishReq{
+				ThingKey: invalid,
+				Channel:  channelID,
+				Payload:  payload,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{},
+			authorizeErr: svcerr.ErrAuthentication,
+			code:         codes.Unauthenticated,
+		},
+		{
+			desc: "publish from denied IP address",
+			req: &messaging.PublishReq{
+				ThingKey: thingKey,
+				Channel:  channelID,
+				Payload:  payload,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: deniedThingID},
+			code:         codes.PermissionDenied,
+		},
+		{
+			desc: "publish over the rate limit",
+			req: &messaging.PublishReq{
+				ThingKey: thingKey,
+				Channel:  channelID,
+				Payload:  payload,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			rateErr:      ratelimit.ErrRateLimited,
+			code:         codes.ResourceExhausted,
+		},
+		{
+			desc: "publish signed message to signed channel",
+			req: &messaging.PublishReq{
+				ThingKey:  thingKey,
+				Channel:   signedChannelID,
+				Payload:   payload,
+				Signature: messaging.Sign(signingSecret, payload),
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, SigningSecret: signingSecret},
+			delivered:    true,
+			code:         codes.OK,
+		},
+		{
+			desc: "publish message signed with thing key to signed channel",
+			req: &messaging.PublishReq{
+				ThingKey:  thingKey,
+				Channel:   signedChannelID,
+				Payload:   payload,
+				Signature: messaging.Sign(thingKey, payload),
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, SigningSecret: signingSecret},
+			code:         codes.Unauthenticated,
+		},
+		{
+			desc: "publish unsigned message to signed channel",
+			req: &messaging.PublishReq{
+				ThingKey: thingKey,
+				Channel:  signedChannelID,
+				Payload:  payload,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			code:         codes.Unauthenticated,
+		},
+		{
+			desc: "publish with new idempotency key",
+			req: &messaging.PublishReq{
+				ThingKey:       thingKey,
+				Channel:        channelID,
+				Payload:        payload,
+				IdempotencyKey: "key",
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			saved:        true,
+			delivered:    true,
+			code:         codes.OK,
+		},
+		{
+			desc: "publish with duplicate idempotency key",
+			req: &messaging.PublishReq{
+				ThingKey:       thingKey,
+				Channel:        channelID,
+				Payload:        payload,
+				IdempotencyKey: "key",
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			saved:        false,
+			code:         codes.OK,
+		},
+		{
+			desc: "publish with too long idempotency key",
+			req: &messaging.PublishReq{
+				ThingKey:       thingKey,
+				Channel:        channelID,
+				Payload:        payload,
+				IdempotencyKey: strings.Repeat("k", 257),
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "publish without thing key",
+			req: &messaging.PublishReq{
+				Channel: channelID,
+				Payload: payload,
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "publish without channel",
+			req: &messaging.PublishReq{
+				ThingKey: thingKey,
+				Payload:  payload,
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "publish with wildcard subtopic",
+			req: &messaging.PublishReq{
+				ThingKey: thingKey,
+				Channel:  channelID,
+				Subtopic: "sensors.>",
+				Payload:  payload,
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		sub.msgs = nil
+		signedSub.msgs = nil
+		authzReq := &magistrala.ThingsAuthzReq{
+			ThingKey:    tc.req.GetThingKey(),
+			ChannelID:   tc.req.GetChannel(),
+			Permission:  policies.PublishPermission,
+			ContentType: tc.req.GetContentType(),
+			Payload:     tc.req.GetPayload(),
+		}
+		authCall := things.On("Authorize", mock.Anything, authzReq).Return(tc.authorizeRes, tc.authorizeErr)
+		rateCall := limiter.On("Allow", mock.Anything, tc.authorizeRes.GetId(), mock.Anything).Return(tc.rateErr)
+		saveCall := idempotency.On("Save", mock.Anything, fmt.Sprintf("%s:%s:%s", thingID, channelID, tc.req.GetIdempotencyKey())).Return(tc.saved, nil)
+		res, err := client.Publish(context.Background(), tc.req)
+		assert.Equal(t, tc.code, status.Code(err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.code, status.Code(err)))
+		msgs := append(sub.msgs, signedSub.msgs...)
+		switch tc.delivered {
+		case true:
+			assert.True(t, res.GetPublished(), fmt.Sprintf("%s: expected message to be published", tc.desc))
+			if assert.Len(t, msgs, 1, fmt.Sprintf("%s: expected message to reach the subscriber", tc.desc)) {
+				msg := msgs[0]
+				assert.Equal(t, tc.req.GetChannel(), msg.GetChannel(), fmt.Sprintf("%s: expected channel %s got %s", tc.desc, tc.req.GetChannel(), msg.GetChannel()))
+				assert.Equal(t, tc.req.GetSubtopic(), msg.GetSubtopic(), fmt.Sprintf("%s: expected subtopic %s got %s", tc.desc, tc.req.GetSubtopic(), msg.GetSubtopic()))
+				assert.Equal(t, thingID, msg.GetPublisher(), fmt.Sprintf("%s: expected publisher %s got %s", tc.desc, thingID, msg.GetPublisher()))
+				assert.Equal(t, "grpc", msg.GetProtocol(), fmt.Sprintf("%s: expected protocol grpc got %s", tc.desc, msg.GetProtocol()))
+				assert.Equal(t, payload, msg.GetPayload(), fmt.Sprintf("%s: expected payload %s got %s", tc.desc, payload, msg.GetPayload()))
+			}
+		default:
+			assert.Empty(t, msgs, fmt.Sprintf("%s: expected message not to reach the subscriber", tc.desc))
+		}
+		authCall.Unset()
+		rateCall.Unset()
+		saveCall.Unset()
+	}
+}
+
+func startSubscriberServer(t *testing.T, sub messaging.Subscriber, things magistrala.ThingsServiceClient, cfg grpcapi.StreamConfig) messaging.SubscriberServiceClient {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err, fmt.Sprintf("failed to obtain port: %s", err))
+	server := grpc.NewServer()
+	messaging.RegisterSubscriberServiceServer(server, grpcapi.NewSubscriberServer(sub, things, messaging.SubtopicRules{}, messaging.FlatTopics, uuid.New(), cfg))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating client: %s", err))
+	t.Cleanup(func() { conn.Close() })
+
+	return messaging.NewSubscriberServiceClient(conn)
+}
+
+func TestSubscribe(t *testing.T) {
+	cases := []struct {
+		desc         string
+		req          *messaging.SubscribeReq
+		topic        string
+		authorizeRes *magistrala.ThingsAuthzRes
+		authorizeErr error
+		code         codes.Code
+	}{
+		{
+			desc: "subscribe with authorized thing",
+			req: &messaging.SubscribeReq{
+				ThingKey: thingKey,
+				Channel:  channelID,
+			},
+			topic:        "channels." + channelID,
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			code:         codes.OK,
+		},
+		{
+			desc: "subscribe to subtopic with authorized thing",
+			req: &messaging.SubscribeReq{
+				ThingKey: thingKey,
+				Channel:  channelID,
+				Subtopic: "sensors.>",
+			},
+			topic:        "channels." + channelID + ".sensors.>",
+			authorizeRes: &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			code:         codes.OK,
+		},
+		{
+			desc: "subscribe with unauthorized thing",
+			req: &messaging.SubscribeReq{
+				ThingKey: invalid,
+				Channel:  channelID,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{},
+			authorizeErr: svcerr.ErrAuthorization,
+			code:         codes.PermissionDenied,
+		},
+		{
+			desc: "subscribe with unauthenticated thing",
+			req: &messaging.SubscribeReq{
+				ThingKey: invalid,
+				Channel:  channelID,
+			},
+			authorizeRes: &magistrala.ThingsAuthzRes{},
+			authorizeErr: svcerr.ErrAuthentication,
+			code:         codes.Unauthenticated,
+		},
+		{
+			desc: "subscribe without thing key",
+			req: &messaging.SubscribeReq{
+				Channel: channelID,
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			desc: "subscribe without channel",
+			req: &messaging.SubscribeReq{
+				ThingKey: thingKey,
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ps := newPubSub()
+			things := new(thmocks.ThingsServiceClient)
+			client := startSubscriberServer(t, ps, things, grpcapi.StreamConfig{SendBuffer: 16, SlowConsumer: grpcapi.SlowConsumerDrop})
+			things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{
+				ThingKey:   tc.req.GetThingKey(),
+				ChannelID:  tc.req.GetChannel(),
+				Permission: policies.SubscribePermission,
+			}).Return(tc.authorizeRes, tc.authorizeErr)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream, err := client.Subscribe(ctx, tc.req)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+			if tc.code != codes.OK {
+				_, err := stream.Recv()
+				assert.Equal(t, tc.code, status.Code(err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.code, status.Code(err)))
+				return
+			}
+
+			require.Eventually(t, func() bool { return ps.subscriptions(tc.topic) == 1 }, time.Second, 10*time.Millisecond, fmt.Sprintf("%s: expected the stream to subscribe to %s", tc.desc, tc.topic))
+			assert.Zero(t, ps.durable, fmt.Sprintf("%s: expected the subscription to be ephemeral", tc.desc))
+			for i := 0; i < 3; i++ {
+				msg := &messaging.Message{Channel: channelID, Publisher: thingID, Payload: payload, Created: int64(i)}
+				err := ps.Publish(context.Background(), tc.topic, msg)
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			}
+			for i := 0; i < 3; i++ {
+				msg, err := stream.Recv()
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, int64(i), msg.GetCreated(), fmt.Sprintf("%s: expected the messages in order", tc.desc))
+				assert.Equal(t, payload, msg.GetPayload(), fmt.Sprintf("%s: expected payload %s got %s", tc.desc, payload, msg.GetPayload()))
+			}
+
+			// Cancelling the stream removes the broker subscription.
+			cancel()
+			assert.Eventually(t, func() bool { return ps.subscriptions(tc.topic) == 0 }, time.Second, 10*time.Millisecond, fmt.Sprintf("%s: expected the subscription to be removed on cancel", tc.desc))
+		})
+	}
+}
+
+func TestSubscribeSlowConsumer(t *testing.T) {
+	const sendTimeout = 200 * time.Millisecond
+	topic := "channels." + channelID
+	// The payloads are large enough to fill the gRPC flow control window of
+	// the stream which isn't read.
+	large := make([]byte, 64*1024)
+
+	cases := []struct {
+		desc   string
+		policy string
+		code   codes.Code
+	}{
+		{
+			desc:   "slow consumer with block policy",
+			policy: grpcapi.SlowConsumerBlock,
+			code:   codes.ResourceExhausted,
+		},
+		{
+			desc: "slow consumer with default policy",
+			code: codes.ResourceExhausted,
+		},
+		{
+			desc:   "slow consumer with drop policy",
+			policy: grpcapi.SlowConsumerDrop,
+			code:   codes.OK,
+		},
+		{
+			desc:   "slow consumer with disconnect policy",
+			policy: grpcapi.SlowConsumerDisconnect,
+			code:   codes.ResourceExhausted,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ps := newPubSub()
+			things := new(thmocks.ThingsServiceClient)
+			client := startSubscriberServer(t, ps, things, grpcapi.StreamConfig{SendBuffer: 4, SlowConsumer: tc.policy, SendTimeout: sendTimeout})
+			things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID}, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream, err := client.Subscribe(ctx, &messaging.SubscribeReq{ThingKey: thingKey, Channel: channelID})
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			require.Eventually(t, func() bool { return ps.subscriptions(topic) == 1 }, time.Second, 10*time.Millisecond, fmt.Sprintf("%s: expected the stream to subscribe", tc.desc))
+
+			// The stream holds back its messages, instead of the broker, until
+			// its send buffer is full.
+			err = nil
+			var blocked time.Duration
+			for i := 0; i < 1000 && err == nil; i++ {
+				start := time.Now()
+				err = ps.Publish(context.Background(), topic, &messaging.Message{Channel: channelID, Payload: large})
+				blocked = time.Since(start)
+			}
+			require.ErrorIs(t, err, grpcapi.ErrSlowConsumer, fmt.Sprintf("%s: expected %s got %s", tc.desc, grpcapi.ErrSlowConsumer, err))
+			// The block policy holds back the broker for the send timeout
+			// before giving up on the stream.
+			switch tc.policy {
+			case grpcapi.SlowConsumerDrop, grpcapi.SlowConsumerDisconnect:
+				assert.Less(t, blocked, sendTimeout, fmt.Sprintf("%s: expected the broker not to be held back", tc.desc))
+			default:
+				assert.GreaterOrEqual(t, blocked, sendTimeout, fmt.Sprintf("%s: expected the broker to be held back for %s got %s", tc.desc, sendTimeout, blocked))
+			}
+
+			switch tc.code {
+			case codes.OK:
+				_, err := stream.Recv()
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, 1, ps.subscriptions(topic), fmt.Sprintf("%s: expected the subscription to stay", tc.desc))
+			default:
+				// The buffered messages are sent before the stream ends.
+				for err = nil; err == nil; {
+					_, err = stream.Recv()
+				}
+				assert.Equal(t, tc.code, status.Code(err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.code, status.Code(err)))
+				assert.Eventually(t, func() bool { return ps.subscriptions(topic) == 0 }, time.Second, 10*time.Millisecond, fmt.Sprintf("%s: expected the subscription to be removed", tc.desc))
+			}
+		})
+	}
+}
+
+func TestSubscribeBackpressure(t *testing.T) {
+	topic := "channels." + channelID
+	ps := newPubSub()
+	things := new(thmocks.ThingsServiceClient)
+	client := startSubscriberServer(t, ps, things, grpcapi.StreamConfig{SendBuffer: 2, SlowConsumer: grpcapi.SlowConsumerBlock, SendTimeout: 5 * time.Second})
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: thingID}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Subscribe(ctx, &messaging.SubscribeReq{ThingKey: thingKey, Channel: channelID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	require.Eventually(t, func() bool { return ps.subscriptions(topic) == 1 }, time.Second, 10*time.Millisecond, "expected the stream to subscribe")
+
+	// The broker is held back by the consumer reading slower than the
+	// messages are published, instead of dropping the messages.
+	const count = 50
+	errs := make(chan error, 1)
+	go func() {
+		for i := 0; i < count; i++ {
+			if err := ps.Publish(context.Background(), topic, &messaging.Message{Channel: channelID, Payload: payload, Created: int64(i)}); err != nil {
+				errs <- err
+				return
+			}
+		}
+		errs <- nil
+	}()
+	for i := 0; i < count; i++ {
+		msg, err := stream.Recv()
+		require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+		assert.Equal(t, int64(i), msg.GetCreated(), "expected the messages in order without drops")
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, <-errs, "expected the messages to be published")
+}
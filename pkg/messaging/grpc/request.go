@@ -0,0 +1,82 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/apiutil"
+)
+
+const maxIdempotencyKeyLen = 256
+
+type publishReq struct {
+	thingKey       string
+	channel        string
+	subtopic       string
+	payload        []byte
+	contentType    string
+	signature      string
+	idempotencyKey string
+}
+
+func (req publishReq) validate() error {
+	if req.thingKey == "" {
+		return apiutil.ErrBearerKey
+	}
+	if req.channel == "" {
+		return apiutil.ErrMissingID
+	}
+	if strings.ContainsAny(req.subtopic, "*>") {
+		return errMalformedSubtopic
+	}
+	if len(req.idempotencyKey) > maxIdempotencyKeyLen {
+		return errInvalidIdempotencyKey
+	}
+
+	return nil
+}
+
+// topic returns the topic of the request in the format of the topics
+// published over the adapter, with the publish options in the query.
+func (req publishReq) topic() string {
+	topic := fmt.Sprintf("%s/%s/messages", chansPrefix, url.PathEscape(req.channel))
+	if req.subtopic != "" {
+		topic = fmt.Sprintf("%s/%s", topic, url.QueryEscape(req.subtopic))
+	}
+	query := url.Values{}
+	if req.contentType != "" {
+		query.Set("content_type", req.contentType)
+	}
+	if req.signature != "" {
+		query.Set("signature", req.signature)
+	}
+	if req.idempotencyKey != "" {
+		query.Set("idempotency_key", req.idempotencyKey)
+	}
+	if len(query) > 0 {
+		topic = fmt.Sprintf("%s?%s", topic, query.Encode())
+	}
+
+	return topic
+}
+
+type subscribeReq struct {
+	thingKey string
+	channel  string
+	subtopic string
+}
+
+func (req subscribeReq) validate() error {
+	if req.thingKey == "" {
+		return apiutil.ErrBearerKey
+	}
+	if req.channel == "" {
+		return apiutil.ErrMissingID
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+type publishRes struct {
+	published bool
+}
+
+type subscribeRes struct {
+	thingID string
+	topic   string
+}
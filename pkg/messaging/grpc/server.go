@@ -0,0 +1,161 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/absmach/mproxy/pkg/session"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ messaging.PublisherServiceServer = (*grpcServer)(nil)
+
+type grpcServer struct {
+	messaging.UnimplementedPublisherServiceServer
+	publish kitgrpc.Handler
+}
+
+// NewServer returns new PublisherServiceServer instance. Published messages
+// are handed to the session handler of the protocol adapter, so they go
+// through the same checks, subtopic rules and topic scheme as the messages
+// published over the adapter.
+func NewServer(handler session.Handler) messaging.PublisherServiceServer {
+	return &grpcServer{
+		publish: kitgrpc.NewServer(
+			publishEndpoint(handler),
+			decodePublishRequest,
+			encodePublishResponse,
+		),
+	}
+}
+
+func (s *grpcServer) Publish(ctx context.Context, req *messaging.PublishReq) (*messaging.PublishRes, error) {
+	_, res, err := s.publish.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return res.(*messaging.PublishRes), nil
+}
+
+var _ messaging.SubscriberServiceServer = (*subscriberServer)(nil)
+
+type subscriberServer struct {
+	messaging.UnimplementedSubscriberServiceServer
+	subscribe kitgrpc.Handler
+	sub       messaging.Subscriber
+	idp       magistrala.IDProvider
+	config    StreamConfig
+}
+
+// NewSubscriberServer returns new SubscriberServiceServer instance. Each
+// subscription stream is authorized like the subscriptions over the protocol
+// adapters and holds its own broker subscription, which is removed once the
+// stream ends. The messages are sent from a buffer of the stream, so a slow
+// consumer holds back only its own stream until the buffer is full, and then
+// the slow consumer policy of the config applies.
+func NewSubscriberServer(sub messaging.Subscriber, things magistrala.ThingsServiceClient, subtopics messaging.SubtopicRules, topics messaging.TopicScheme, idp magistrala.IDProvider, config StreamConfig) messaging.SubscriberServiceServer {
+	return &subscriberServer{
+		subscribe: kitgrpc.NewServer(
+			subscribeEndpoint(things, subtopics, topics),
+			decodeSubscribeRequest,
+			encodeSubscribeResponse,
+		),
+		sub:    sub,
+		idp:    idp,
+		config: config,
+	}
+}
+
+func (s *subscriberServer) Subscribe(req *messaging.SubscribeReq, srv messaging.SubscriberService_SubscribeServer) error {
+	ctx := srv.Context()
+	_, res, err := s.subscribe.ServeGRPC(ctx, req)
+	if err != nil {
+		return encodeError(err)
+	}
+	topic := res.(subscribeRes).topic
+
+	// Every stream is a distinct subscriber, so the streams of the same
+	// thing to the same topic don't share the broker subscription.
+	id, err := s.idp.ID()
+	if err != nil {
+		return encodeError(err)
+	}
+	st := newStream(s.config)
+	// The subscription is ephemeral, so the broker removes it if the server
+	// stops without unsubscribing.
+	if err := s.sub.Subscribe(ctx, messaging.SubscriberConfig{ID: id, Topic: topic, Handler: st, Ephemeral: true}); err != nil {
+		return encodeError(errors.Wrap(errFailedSubscribe, err))
+	}
+
+	err = st.serve(ctx, srv.Send)
+	// The context of the stream is done by now.
+	if uerr := s.sub.Unsubscribe(context.Background(), id, topic); uerr != nil {
+		err = errors.Wrap(uerr, err)
+	}
+
+	return encodeError(err)
+}
+
+func decodePublishRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*messaging.PublishReq)
+	return publishReq{
+		thingKey:       req.GetThingKey(),
+		channel:        req.GetChannel(),
+		subtopic:       req.GetSubtopic(),
+		payload:        req.GetPayload(),
+		contentType:    req.GetContentType(),
+		signature:      req.GetSignature(),
+		idempotencyKey: req.GetIdempotencyKey(),
+	}, nil
+}
+
+func encodePublishResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
+	res := grpcRes.(publishRes)
+	return &messaging.PublishRes{Published: res.published}, nil
+}
+
+func decodeSubscribeRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*messaging.SubscribeReq)
+	return subscribeReq{
+		thingKey: req.GetThingKey(),
+		channel:  req.GetChannel(),
+		subtopic: req.GetSubtopic(),
+	}, nil
+}
+
+func encodeSubscribeResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
+	return grpcRes.(subscribeRes), nil
+}
+
+func encodeError(err error) error {
+	switch {
+	case errors.Contains(err, nil):
+		return nil
+	case errors.Contains(err, errors.ErrMalformedEntity),
+		errors.Contains(err, senml.ErrTimeOutOfRange),
+		errors.Contains(err, messaging.ErrSubtopicTooDeep),
+		errors.Contains(err, messaging.ErrSubtopicPattern):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Contains(err, svcerr.ErrAuthentication),
+		errors.Contains(err, messaging.ErrMissingSignature),
+		errors.Contains(err, messaging.ErrInvalidSignature):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Contains(err, svcerr.ErrAuthorization):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Contains(err, ErrSlowConsumer),
+		errors.Contains(err, ratelimit.ErrRateLimited):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
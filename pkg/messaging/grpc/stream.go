@@ -0,0 +1,164 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+)
+
+const (
+	// SlowConsumerBlock holds back the broker subscription of a slow
+	// subscription stream whose send buffer is full, at most the send
+	// timeout, and then ends the stream.
+	SlowConsumerBlock = "block"
+
+	// SlowConsumerDrop drops the messages which don't fit in the send buffer
+	// of a slow subscription stream.
+	SlowConsumerDrop = "drop"
+
+	// SlowConsumerDisconnect ends the subscription stream whose send buffer
+	// is full.
+	SlowConsumerDisconnect = "disconnect"
+)
+
+var (
+	// ErrSlowConsumer indicates that the message is not sent, since the send
+	// buffer of the subscription stream is full, or stayed full for the send
+	// timeout with the block policy.
+	ErrSlowConsumer = errors.New("send buffer of slow consumer is full")
+
+	errStreamClosed = errors.New("subscription stream is closed")
+)
+
+// StreamConfig defines the send buffering of the subscription streams.
+type StreamConfig struct {
+	// SendBuffer is the number of messages buffered per subscription stream.
+	SendBuffer int `env:"SEND_BUFFER" envDefault:"256"`
+
+	// SlowConsumer is the policy applied once the send buffer is full,
+	// either block, drop or disconnect.
+	SlowConsumer string `env:"SLOW_CONSUMER" envDefault:"block"`
+
+	// SendTimeout is the maximum time the block policy waits for the send
+	// buffer before ending the stream.
+	SendTimeout time.Duration `env:"SEND_TIMEOUT" envDefault:"5s"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg StreamConfig) Validate() error {
+	if cfg.SendBuffer <= 0 {
+		return fmt.Errorf("send buffer must be positive")
+	}
+	switch cfg.SlowConsumer {
+	case "", SlowConsumerBlock:
+		if cfg.SendTimeout <= 0 {
+			return fmt.Errorf("send timeout must be positive")
+		}
+		return nil
+	case SlowConsumerDrop, SlowConsumerDisconnect:
+		return nil
+	default:
+		return fmt.Errorf("invalid slow consumer policy %q", cfg.SlowConsumer)
+	}
+}
+
+var _ messaging.MessageHandler = (*stream)(nil)
+
+// stream buffers the messages of the broker subscription, so the gRPC flow
+// control of a slow consumer holds back its own stream, and the broker only
+// once the buffer is full with the block policy.
+type stream struct {
+	send    chan *messaging.Message
+	policy  string
+	timeout time.Duration
+
+	done     chan struct{}
+	doneOnce sync.Once
+	slow     chan struct{}
+	slowOnce sync.Once
+}
+
+func newStream(cfg StreamConfig) *stream {
+	policy := cfg.SlowConsumer
+	if policy == "" {
+		policy = SlowConsumerBlock
+	}
+
+	return &stream{
+		send:    make(chan *messaging.Message, cfg.SendBuffer),
+		policy:  policy,
+		timeout: cfg.SendTimeout,
+		done:    make(chan struct{}),
+		slow:    make(chan struct{}),
+	}
+}
+
+// Handle buffers the message of the broker. Once the buffer is full, the
+// block policy waits for the consumer at most the send timeout, while the
+// other policies don't wait.
+func (s *stream) Handle(msg *messaging.Message) error {
+	select {
+	case <-s.done:
+		return errStreamClosed
+	default:
+	}
+
+	select {
+	case s.send <- msg:
+		return nil
+	default:
+	}
+
+	switch s.policy {
+	case SlowConsumerDrop:
+		return ErrSlowConsumer
+	case SlowConsumerBlock:
+		timer := time.NewTimer(s.timeout)
+		defer timer.Stop()
+		select {
+		case s.send <- msg:
+			return nil
+		case <-s.done:
+			return errStreamClosed
+		case <-timer.C:
+		}
+	}
+	s.slowOnce.Do(func() { close(s.slow) })
+
+	return ErrSlowConsumer
+}
+
+// Cancel is called once the broker subscription is removed.
+func (s *stream) Cancel() error {
+	s.doneOnce.Do(func() { close(s.done) })
+
+	return nil
+}
+
+// serve sends the buffered messages until the context is done or the
+// consumer falls behind with the block or disconnect policy.
+func (s *stream) serve(ctx context.Context, send func(*messaging.Message) error) error {
+	defer s.Cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.done:
+			return nil
+		case <-s.slow:
+			return ErrSlowConsumer
+		case msg := <-s.send:
+			if err := send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/server"
+	"github.com/absmach/mproxy/pkg/session"
+)
+
+// ErrShuttingDown indicates that the adapter is shutting down, so it doesn't
+// accept new connections.
+var ErrShuttingDown = errors.New("adapter is shutting down")
+
+var _ session.Handler = (*Drain)(nil)
+
+// Drain is the session handler tracking the in-flight publishes, so the
+// adapter completes them before it shuts down.
+type Drain struct {
+	handler session.Handler
+
+	mu       sync.Mutex
+	draining bool
+	inflight int
+	idle     chan struct{}
+}
+
+// NewDrain returns the session handler draining the handler on shutdown.
+func NewDrain(handler session.Handler) *Drain {
+	return &Drain{
+		handler: handler,
+		idle:    make(chan struct{}, 1),
+	}
+}
+
+// Shutdown refuses the new connections and waits for the in-flight publishes
+// to complete, or for the context to be done. The publishes of the already
+// connected clients are still passed to the handler, so the messages the
+// clients sent are not lost.
+func (d *Drain) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	for {
+		d.mu.Lock()
+		n := d.inflight
+		d.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-d.idle:
+		case <-ctx.Done():
+			return errors.Wrap(ErrShuttingDown, ctx.Err())
+		}
+	}
+}
+
+// Stopper returns the server draining the in-flight publishes for at most
+// the timeout when stopped. It's stopped before the servers and the proxies
+// of the adapter, since stopping them closes the connections of the clients.
+func (d *Drain) Stopper(timeout time.Duration) server.StopFunc {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		return d.Shutdown(ctx)
+	}
+}
+
+// AuthConnect refuses the connection once the shutdown starts.
+func (d *Drain) AuthConnect(ctx context.Context) error {
+	d.mu.Lock()
+	draining := d.draining
+	d.mu.Unlock()
+	if draining {
+		return ErrShuttingDown
+	}
+
+	return d.handler.AuthConnect(ctx)
+}
+
+// AuthPublish implements session.Handler.
+func (d *Drain) AuthPublish(ctx context.Context, topic *string, payload *[]byte) error {
+	return d.handler.AuthPublish(ctx, topic, payload)
+}
+
+// AuthSubscribe implements session.Handler.
+func (d *Drain) AuthSubscribe(ctx context.Context, topics *[]string) error {
+	return d.handler.AuthSubscribe(ctx, topics)
+}
+
+// Connect implements session.Handler.
+func (d *Drain) Connect(ctx context.Context) error {
+	return d.handler.Connect(ctx)
+}
+
+// Publish tracks the publish until the handler completes it.
+func (d *Drain) Publish(ctx context.Context, topic *string, payload *[]byte) error {
+	d.mu.Lock()
+	d.inflight++
+	d.mu.Unlock()
+	defer d.done()
+
+	return d.handler.Publish(ctx, topic, payload)
+}
+
+// Subscribe implements session.Handler.
+func (d *Drain) Subscribe(ctx context.Context, topics *[]string) error {
+	return d.handler.Subscribe(ctx, topics)
+}
+
+// Unsubscribe implements session.Handler.
+func (d *Drain) Unsubscribe(ctx context.Context, topics *[]string) error {
+	return d.handler.Unsubscribe(ctx, topics)
+}
+
+// Disconnect implements session.Handler.
+func (d *Drain) Disconnect(ctx context.Context) error {
+	return d.handler.Disconnect(ctx)
+}
+
+func (d *Drain) done() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inflight--
+	if d.draining && d.inflight == 0 {
+		select {
+		case d.idle <- struct{}{}:
+		default:
+		}
+	}
+}
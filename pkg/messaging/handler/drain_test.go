@@ -0,0 +1,139 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging/handler"
+	"github.com/absmach/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ session.Handler = (*blockingHandler)(nil)
+
+// blockingHandler completes the publishes once they are released.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu        sync.Mutex
+	published []string
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockingHandler) AuthConnect(ctx context.Context) error { return nil }
+
+func (h *blockingHandler) AuthPublish(ctx context.Context, topic *string, payload *[]byte) error {
+	return nil
+}
+
+func (h *blockingHandler) AuthSubscribe(ctx context.Context, topics *[]string) error { return nil }
+
+func (h *blockingHandler) Connect(ctx context.Context) error { return nil }
+
+func (h *blockingHandler) Publish(ctx context.Context, topic *string, payload *[]byte) error {
+	h.started <- struct{}{}
+	<-h.release
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.published = append(h.published, string(*payload))
+
+	return nil
+}
+
+func (h *blockingHandler) Subscribe(ctx context.Context, topics *[]string) error { return nil }
+
+func (h *blockingHandler) Unsubscribe(ctx context.Context, topics *[]string) error { return nil }
+
+func (h *blockingHandler) Disconnect(ctx context.Context) error { return nil }
+
+func TestDrainShutdown(t *testing.T) {
+	h := newBlockingHandler()
+	d := handler.NewDrain(h)
+
+	topic, payload := "channels/1/messages", []byte("in-flight")
+	pubErr := make(chan error)
+	go func() {
+		pubErr <- d.Publish(context.Background(), &topic, &payload)
+	}()
+	<-h.started
+
+	shutdownErr := make(chan error)
+	go func() {
+		shutdownErr <- d.Shutdown(context.Background())
+	}()
+
+	// The shutdown starts asynchronously, so the connections are refused
+	// once it does.
+	assert.Eventually(t, func() bool {
+		return errors.Contains(d.AuthConnect(context.Background()), handler.ErrShuttingDown)
+	}, time.Second, time.Millisecond, "connect during shutdown: expected connection to be refused")
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("shutdown completed with in-flight publish: %s", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(h.release)
+	err := <-pubErr
+	assert.Nil(t, err, fmt.Sprintf("in-flight publish: expected nil got %s", err))
+	err = <-shutdownErr
+	assert.Nil(t, err, fmt.Sprintf("shutdown: expected nil got %s", err))
+	assert.Equal(t, []string{string(payload)}, h.published, "shutdown: expected in-flight message to be published")
+}
+
+func TestDrainShutdownTimeout(t *testing.T) {
+	h := newBlockingHandler()
+	defer close(h.release)
+	d := handler.NewDrain(h)
+
+	topic, payload := "channels/1/messages", []byte("stuck")
+	go func() {
+		_ = d.Publish(context.Background(), &topic, &payload)
+	}()
+	<-h.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := d.Shutdown(ctx)
+	assert.True(t, errors.Contains(err, handler.ErrShuttingDown), fmt.Sprintf("shutdown with stuck publish: expected %s got %s", handler.ErrShuttingDown, err))
+}
+
+func TestDrainShutdownIdle(t *testing.T) {
+	d := handler.NewDrain(newBlockingHandler())
+
+	err := d.Shutdown(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("shutdown without publishes: expected nil got %s", err))
+	err = d.AuthConnect(context.Background())
+	assert.True(t, errors.Contains(err, handler.ErrShuttingDown), fmt.Sprintf("connect after shutdown: expected %s got %s", handler.ErrShuttingDown, err))
+}
+
+func TestDrainStopper(t *testing.T) {
+	h := newBlockingHandler()
+	defer close(h.release)
+	d := handler.NewDrain(h)
+
+	topic, payload := "channels/1/messages", []byte("stuck")
+	go func() {
+		_ = d.Publish(context.Background(), &topic, &payload)
+	}()
+	<-h.started
+
+	err := d.Stopper(10 * time.Millisecond).Stop()
+	assert.True(t, errors.Contains(err, handler.ErrShuttingDown), fmt.Sprintf("stop with stuck publish: expected %s got %s", handler.ErrShuttingDown, err))
+}
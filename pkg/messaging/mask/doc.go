@@ -0,0 +1,10 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mask provides the masking of the SenML records of the published
+// messages.
+//
+// The writers mask the records only before storage, so the live subscribers
+// still receive them. Masking the records on publish redacts them for the
+// subscribers and the writers alike.
+package mask
@@ -0,0 +1,168 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mask
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+)
+
+var (
+	// ErrMask indicates the published message of the masked channel which
+	// can't be masked, and is rejected instead of being published unmasked.
+	ErrMask = errors.New("failed to mask the published message")
+
+	errContentType = errors.New("unsupported masked content type")
+)
+
+// Config defines which records of the published messages are masked.
+type Config struct {
+	// Masks is the JSON encoded list of the SenML field masks, such as
+	// [{"channel":"<channel_id>","name":"patient-name","action":"drop"}].
+	Masks string `env:"RULES" envDefault:""`
+
+	// ContentType is the SenML content type of the published payloads.
+	ContentType string `env:"CONTENT_TYPE" envDefault:"application/senml+json"`
+}
+
+// Enabled reports whether any record is masked.
+func (cfg Config) Enabled() bool {
+	return cfg.Masks != ""
+}
+
+// Parse returns the field masks, or the error if the masks or the content
+// type are invalid.
+func (cfg Config) Parse() (senml.Masks, error) {
+	if cfg.ContentType != senml.JSON && cfg.ContentType != senml.CBOR {
+		return nil, errors.Wrap(errContentType, fmt.Errorf("%q, expected %q or %q", cfg.ContentType, senml.JSON, senml.CBOR))
+	}
+
+	return senml.ParseMasks(cfg.Masks)
+}
+
+var (
+	_ messaging.AckPublisher   = (*publisherMiddleware)(nil)
+	_ messaging.BatchPublisher = (*publisherMiddleware)(nil)
+)
+
+type publisherMiddleware struct {
+	publisher   messaging.Publisher
+	masks       senml.Masks
+	contentType string
+}
+
+// NewPublisher returns publisher which drops or hashes the masked SenML
+// records of the published messages. The messages of the channels without
+// masks are published unchanged, while the messages of the masked channels
+// which are not SenML in the content type are rejected with ErrMask.
+func NewPublisher(masks senml.Masks, contentType string, publisher messaging.Publisher) messaging.Publisher {
+	return &publisherMiddleware{
+		publisher:   publisher,
+		masks:       masks,
+		contentType: contentType,
+	}
+}
+
+func (pm *publisherMiddleware) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	masked, err := pm.mask(msg)
+	if err != nil {
+		return err
+	}
+
+	return pm.publisher.Publish(ctx, topic, masked)
+}
+
+// PublishAck masks the acknowledged messages. It returns messaging
+// ErrAckNotSupported if the wrapped publisher can't wait for the
+// acknowledgment.
+func (pm *publisherMiddleware) PublishAck(ctx context.Context, topic string, msg *messaging.Message) (messaging.Receipt, error) {
+	ap, ok := pm.publisher.(messaging.AckPublisher)
+	if !ok {
+		return messaging.Receipt{}, messaging.ErrAckNotSupported
+	}
+
+	masked, err := pm.mask(msg)
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+
+	return ap.PublishAck(ctx, topic, masked)
+}
+
+// PublishBatch masks the messages of the published batch. It returns
+// messaging ErrBatchNotSupported if the wrapped publisher can't publish
+// batches, and rejects the whole batch if any message can't be masked.
+func (pm *publisherMiddleware) PublishBatch(ctx context.Context, topic string, msgs []*messaging.Message) error {
+	bp, ok := pm.publisher.(messaging.BatchPublisher)
+	if !ok {
+		return messaging.ErrBatchNotSupported
+	}
+	masked := make([]*messaging.Message, len(msgs))
+	for i, msg := range msgs {
+		m, err := pm.mask(msg)
+		if err != nil {
+			return err
+		}
+		masked[i] = m
+	}
+
+	return bp.PublishBatch(ctx, topic, masked)
+}
+
+func (pm *publisherMiddleware) Close() error {
+	return pm.publisher.Close()
+}
+
+// mask returns the message with the masked records, leaving the message of
+// the caller unchanged, or ErrMask if the message can't be masked.
+func (pm *publisherMiddleware) mask(msg *messaging.Message) (*messaging.Message, error) {
+	payload, masked, err := pm.masks.MaskPayload(pm.contentType, msg.GetChannel(), msg.GetPayload())
+	if err != nil {
+		return nil, errors.Wrap(ErrMask, err)
+	}
+	if !masked {
+		return msg, nil
+	}
+
+	return &messaging.Message{
+		Channel:    msg.GetChannel(),
+		Subtopic:   msg.GetSubtopic(),
+		Publisher:  msg.GetPublisher(),
+		Protocol:   msg.GetProtocol(),
+		Payload:    payload,
+		Created:    msg.GetCreated(),
+		Priority:   msg.GetPriority(),
+		Unverified: msg.GetUnverified(),
+	}, nil
+}
+
+type pubsubMiddleware struct {
+	publisherMiddleware
+	pubsub messaging.PubSub
+}
+
+// NewPubSub returns pubsub which drops or hashes the masked SenML records of
+// the published messages.
+func NewPubSub(masks senml.Masks, contentType string, pubsub messaging.PubSub) messaging.PubSub {
+	return &pubsubMiddleware{
+		publisherMiddleware: publisherMiddleware{
+			publisher:   pubsub,
+			masks:       masks,
+			contentType: contentType,
+		},
+		pubsub: pubsub,
+	}
+}
+
+func (pm *pubsubMiddleware) Subscribe(ctx context.Context, cfg messaging.SubscriberConfig) error {
+	return pm.pubsub.Subscribe(ctx, cfg)
+}
+
+func (pm *pubsubMiddleware) Unsubscribe(ctx context.Context, id, topic string) error {
+	return pm.pubsub.Unsubscribe(ctx, id, topic)
+}
@@ -0,0 +1,260 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package mask_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/messaging/mask"
+	"github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	mgsenml "github.com/absmach/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const channel = "channel"
+
+var (
+	payload    = []byte(`[{"bn":"patient-","bt":1700000000,"n":"name","vs":"John Doe"},{"n":"pulse","u":"beat/min","v":72}]`)
+	errPublish = errors.New("failed to publish")
+)
+
+func TestPublish(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("John Doe"))
+	nameHash := hex.EncodeToString(mac.Sum(nil))
+	dropName := senml.Masks{{Name: "patient-name", Action: senml.MaskDrop}}
+	hashName := senml.Masks{{Name: "patient-name", Action: senml.MaskHash, Key: "secret"}}
+
+	cases := []struct {
+		desc        string
+		publishMask senml.Masks
+		storageMask senml.Masks
+		payload     []byte
+		// subscribed and stored are the values of the patient-name record
+		// received by the subscribers and stored by the writer, empty if
+		// the record is absent.
+		subscribed string
+		stored     string
+		pubErr     error
+	}{
+		{
+			desc:       "publish without masks",
+			payload:    payload,
+			subscribed: "John Doe",
+			stored:     "John Doe",
+		},
+		{
+			desc:        "publish with record dropped before storage",
+			storageMask: dropName,
+			payload:     payload,
+			subscribed:  "John Doe",
+		},
+		{
+			desc:        "publish with record hashed before storage",
+			storageMask: hashName,
+			payload:     payload,
+			subscribed:  "John Doe",
+			stored:      nameHash,
+		},
+		{
+			desc:        "publish with record dropped on publish",
+			publishMask: dropName,
+			payload:     payload,
+		},
+		{
+			desc:        "publish with record hashed on publish",
+			publishMask: hashName,
+			payload:     payload,
+			subscribed:  nameHash,
+			stored:      nameHash,
+		},
+		{
+			desc:        "publish with record masked on publish in other channel",
+			publishMask: senml.Masks{{Channel: "other", Name: "patient-name", Action: senml.MaskDrop}},
+			payload:     payload,
+			subscribed:  "John Doe",
+			stored:      "John Doe",
+		},
+		{
+			desc:        "publish with record masked on publish and failed publish",
+			publishMask: dropName,
+			payload:     payload,
+			pubErr:      errPublish,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var published *messaging.Message
+			pub := new(mocks.PubSub)
+			pub.On("Publish", context.Background(), channel, mock.Anything).Run(func(args mock.Arguments) {
+				published = args.Get(2).(*messaging.Message)
+			}).Return(tc.pubErr)
+			mp := mask.NewPublisher(tc.publishMask, senml.JSON, pub)
+
+			msg := &messaging.Message{Channel: channel, Publisher: "publisher", Payload: tc.payload}
+			err := mp.Publish(context.Background(), channel, msg)
+			assert.True(t, errors.Contains(err, tc.pubErr), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.pubErr, err))
+			assert.Equal(t, tc.payload, msg.GetPayload(), fmt.Sprintf("%s: expected the published message to be unchanged", tc.desc))
+			if tc.pubErr != nil {
+				return
+			}
+			require.NotNil(t, published, fmt.Sprintf("%s: expected the message to be published", tc.desc))
+
+			pack, err := mgsenml.Decode(published.GetPayload(), mgsenml.JSON)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			pack, err = mgsenml.Normalize(pack)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			subscribed := ""
+			for _, r := range pack.Records {
+				if r.Name == "patient-name" && r.StringValue != nil {
+					subscribed = *r.StringValue
+				}
+			}
+			assert.Equal(t, tc.subscribed, subscribed, fmt.Sprintf("%s: expected subscribers to receive %q got %q", tc.desc, tc.subscribed, subscribed))
+
+			tr := senml.New(senml.JSON, senml.TimeConfig{}, nil, senml.GeoConfig{}, nil, tc.storageMask)
+			res, err := tr.Transform(published)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			stored := ""
+			for _, m := range res.([]senml.Message) {
+				if m.Name == "patient-name" && m.StringValue != nil {
+					stored = *m.StringValue
+				}
+			}
+			assert.Equal(t, tc.stored, stored, fmt.Sprintf("%s: expected %q to be stored got %q", tc.desc, tc.stored, stored))
+		})
+	}
+}
+
+func TestPublishUnmasked(t *testing.T) {
+	cases := []struct {
+		desc    string
+		masks   senml.Masks
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "publish message without masked records",
+			masks:   senml.Masks{{Name: "patient-name", Action: senml.MaskDrop}},
+			payload: []byte(`[{"bn":"patient-","n":"pulse","u":"beat/min","v":72}]`),
+		},
+		{
+			desc:    "publish message which is not SenML in channel without masks",
+			masks:   senml.Masks{{Channel: "other", Name: "patient-name", Action: senml.MaskDrop}},
+			payload: []byte(`{"patient-name":"John Doe"}`),
+		},
+		{
+			desc:    "publish message which is not SenML in masked channel",
+			masks:   senml.Masks{{Name: "patient-name", Action: senml.MaskDrop}},
+			payload: []byte(`{"patient-name":"John Doe"}`),
+			err:     mask.ErrMask,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			msg := &messaging.Message{Channel: channel, Payload: tc.payload}
+			pub := new(mocks.PubSub)
+			pub.On("Publish", context.Background(), channel, msg).Return(nil)
+			mp := mask.NewPublisher(tc.masks, senml.JSON, pub)
+
+			err := mp.Publish(context.Background(), channel, msg)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			switch tc.err {
+			case nil:
+				pub.AssertCalled(t, "Publish", context.Background(), channel, msg)
+			default:
+				pub.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPublishBatch(t *testing.T) {
+	msgs := []*messaging.Message{
+		{Channel: channel, Payload: payload},
+		{Channel: channel, Payload: payload},
+	}
+	var published []*messaging.Message
+	pub := new(mocks.BatchPublisher)
+	pub.On("PublishBatch", context.Background(), channel, mock.Anything).Run(func(args mock.Arguments) {
+		published = args.Get(2).([]*messaging.Message)
+	}).Return(nil)
+	mp := mask.NewPublisher(senml.Masks{{Name: "patient-name", Action: senml.MaskDrop}}, senml.JSON, pub)
+
+	bp, ok := mp.(messaging.BatchPublisher)
+	require.True(t, ok, "expected publisher to support batches")
+	err := bp.PublishBatch(context.Background(), channel, msgs)
+	assert.Nil(t, err, fmt.Sprintf("publish batch: unexpected error %s", err))
+	require.Len(t, published, len(msgs), fmt.Sprintf("expected %d published messages got %d", len(msgs), len(published)))
+	for _, msg := range published {
+		assert.NotContains(t, string(msg.GetPayload()), "John Doe", "expected the masked record not to be published")
+		assert.Contains(t, string(msg.GetPayload()), "pulse", "expected the other records to be published")
+	}
+
+	rejected := []*messaging.Message{
+		{Channel: channel, Payload: payload},
+		{Channel: channel, Payload: []byte(`{"patient-name":"John Doe"}`)},
+	}
+	pub = new(mocks.BatchPublisher)
+	mp = mask.NewPublisher(senml.Masks{{Name: "patient-name", Action: senml.MaskDrop}}, senml.JSON, pub)
+	bp = mp.(messaging.BatchPublisher)
+	err = bp.PublishBatch(context.Background(), channel, rejected)
+	assert.True(t, errors.Contains(err, mask.ErrMask), fmt.Sprintf("publish batch with message which is not SenML: expected %s got %s", mask.ErrMask, err))
+	pub.AssertNotCalled(t, "PublishBatch", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestConfig(t *testing.T) {
+	cases := []struct {
+		desc  string
+		cfg   mask.Config
+		valid bool
+	}{
+		{
+			desc:  "parse disabled config",
+			cfg:   mask.Config{ContentType: senml.JSON},
+			valid: true,
+		},
+		{
+			desc:  "parse config",
+			cfg:   mask.Config{Masks: `[{"name":"patient-name","action":"hash","key":"secret"}]`, ContentType: senml.CBOR},
+			valid: true,
+		},
+		{
+			desc: "parse config with hash mask without key",
+			cfg:  mask.Config{Masks: `[{"name":"patient-name","action":"hash"}]`, ContentType: senml.JSON},
+		},
+		{
+			desc: "parse config with invalid mask",
+			cfg:  mask.Config{Masks: `[{"name":"patient-name","action":"redact"}]`, ContentType: senml.JSON},
+		},
+		{
+			desc: "parse config with unsupported content type",
+			cfg:  mask.Config{Masks: `[{"name":"patient-name","action":"drop"}]`, ContentType: "application/json"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := tc.cfg.Parse()
+			switch tc.valid {
+			case true:
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			default:
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected error", tc.desc))
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics provides the per channel metrics of the published messages.
+//
+// Counting every channel separately would create a time series per channel,
+// so only the configured channels are labeled with their own IDs, while the
+// others are labeled with a hash bucket or counted together.
+package metrics
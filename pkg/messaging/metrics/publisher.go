@@ -0,0 +1,170 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/go-kit/kit/metrics"
+)
+
+// OtherChannels is the label of the channels which are neither tracked nor
+// bucketed.
+const OtherChannels = "other"
+
+// Config defines how the channels are labeled.
+type Config struct {
+	// Channels are the IDs of the channels counted under their own label.
+	Channels []string `env:"CHANNELS" envDefault:"" envSeparator:","`
+
+	// Buckets is the number of the hash buckets the other channels are
+	// counted in. If zero, the other channels are counted together.
+	Buckets uint32 `env:"BUCKETS" envDefault:"0"`
+}
+
+// Enabled reports whether any channel is counted.
+func (cfg Config) Enabled() bool {
+	return len(cfg.Channels) > 0 || cfg.Buckets > 0
+}
+
+type labeler struct {
+	channels map[string]struct{}
+	buckets  uint32
+}
+
+func newLabeler(cfg Config) labeler {
+	l := labeler{
+		channels: make(map[string]struct{}, len(cfg.Channels)),
+		buckets:  cfg.Buckets,
+	}
+	for _, ch := range cfg.Channels {
+		if ch != "" {
+			l.channels[ch] = struct{}{}
+		}
+	}
+
+	return l
+}
+
+// label returns the label of the channel, so the number of the labels never
+// exceeds the number of the tracked channels and buckets, plus one.
+func (l labeler) label(channel string) string {
+	if _, ok := l.channels[channel]; ok {
+		return channel
+	}
+	if l.buckets == 0 {
+		return OtherChannels
+	}
+	h := fnv.New32a()
+	h.Write([]byte(channel))
+
+	return fmt.Sprintf("bucket_%d", h.Sum32()%l.buckets)
+}
+
+var (
+	_ messaging.AckPublisher   = (*publisherMiddleware)(nil)
+	_ messaging.BatchPublisher = (*publisherMiddleware)(nil)
+)
+
+type publisherMiddleware struct {
+	publisher messaging.Publisher
+	labeler   labeler
+	messages  metrics.Counter
+	bytes     metrics.Counter
+}
+
+// NewPublisher returns publisher which counts the published messages and
+// their payload bytes per channel.
+func NewPublisher(cfg Config, publisher messaging.Publisher, messages, bytes metrics.Counter) messaging.Publisher {
+	return &publisherMiddleware{
+		publisher: publisher,
+		labeler:   newLabeler(cfg),
+		messages:  messages,
+		bytes:     bytes,
+	}
+}
+
+func (pm *publisherMiddleware) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	if err := pm.publisher.Publish(ctx, topic, msg); err != nil {
+		return err
+	}
+	pm.count(msg)
+
+	return nil
+}
+
+// PublishAck counts the acknowledged messages. It returns messaging
+// ErrAckNotSupported if the wrapped publisher can't wait for the
+// acknowledgment.
+func (pm *publisherMiddleware) PublishAck(ctx context.Context, topic string, msg *messaging.Message) (messaging.Receipt, error) {
+	ap, ok := pm.publisher.(messaging.AckPublisher)
+	if !ok {
+		return messaging.Receipt{}, messaging.ErrAckNotSupported
+	}
+	receipt, err := ap.PublishAck(ctx, topic, msg)
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+	pm.count(msg)
+
+	return receipt, nil
+}
+
+// PublishBatch counts the messages of the published batch. It returns
+// messaging ErrBatchNotSupported if the wrapped publisher can't publish
+// batches.
+func (pm *publisherMiddleware) PublishBatch(ctx context.Context, topic string, msgs []*messaging.Message) error {
+	bp, ok := pm.publisher.(messaging.BatchPublisher)
+	if !ok {
+		return messaging.ErrBatchNotSupported
+	}
+	if err := bp.PublishBatch(ctx, topic, msgs); err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		pm.count(msg)
+	}
+
+	return nil
+}
+
+func (pm *publisherMiddleware) Close() error {
+	return pm.publisher.Close()
+}
+
+func (pm *publisherMiddleware) count(msg *messaging.Message) {
+	label := pm.labeler.label(msg.GetChannel())
+	pm.messages.With("channel", label).Add(1)
+	pm.bytes.With("channel", label).Add(float64(len(msg.GetPayload())))
+}
+
+type pubsubMiddleware struct {
+	publisherMiddleware
+	pubsub messaging.PubSub
+}
+
+// NewPubSub returns pubsub which counts the published messages and their
+// payload bytes per channel.
+func NewPubSub(cfg Config, pubsub messaging.PubSub, messages, bytes metrics.Counter) messaging.PubSub {
+	return &pubsubMiddleware{
+		publisherMiddleware: publisherMiddleware{
+			publisher: pubsub,
+			labeler:   newLabeler(cfg),
+			messages:  messages,
+			bytes:     bytes,
+		},
+		pubsub: pubsub,
+	}
+}
+
+func (pm *pubsubMiddleware) Subscribe(ctx context.Context, cfg messaging.SubscriberConfig) error {
+	return pm.pubsub.Subscribe(ctx, cfg)
+}
+
+func (pm *pubsubMiddleware) Unsubscribe(ctx context.Context, id, topic string) error {
+	return pm.pubsub.Unsubscribe(ctx, id, topic)
+}
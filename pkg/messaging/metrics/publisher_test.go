@@ -0,0 +1,248 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/messaging/metrics"
+	"github.com/absmach/magistrala/pkg/messaging/mocks"
+	kitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	tracked   = "tracked"
+	untracked = "untracked"
+)
+
+var errPublish = errors.New("failed to publish")
+
+// counter records the values added per channel label.
+type counter struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounter() *counter {
+	return &counter{values: map[string]float64{}}
+}
+
+func (c *counter) With(labelValues ...string) kitmetrics.Counter {
+	return &labeledCounter{counter: c, label: labelValues[1]}
+}
+
+func (c *counter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[""] += delta
+}
+
+func (c *counter) value(label string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[label]
+}
+
+func (c *counter) labels() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	labels := []string{}
+	for l := range c.values {
+		labels = append(labels, l)
+	}
+	return labels
+}
+
+type labeledCounter struct {
+	*counter
+	label string
+}
+
+func (lc *labeledCounter) Add(delta float64) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.values[lc.label] += delta
+}
+
+func TestPublish(t *testing.T) {
+	payload := []byte(`[{"n":"current","t":-1,"v":1.6}]`)
+
+	cases := []struct {
+		desc     string
+		cfg      metrics.Config
+		channel  string
+		pubErr   error
+		label    string
+		messages float64
+	}{
+		{
+			desc:     "publish to tracked channel",
+			cfg:      metrics.Config{Channels: []string{tracked}},
+			channel:  tracked,
+			label:    tracked,
+			messages: 3,
+		},
+		{
+			desc:     "publish to untracked channel",
+			cfg:      metrics.Config{Channels: []string{tracked}},
+			channel:  untracked,
+			label:    metrics.OtherChannels,
+			messages: 3,
+		},
+		{
+			desc:     "publish to tracked channel with buckets",
+			cfg:      metrics.Config{Channels: []string{tracked}, Buckets: 4},
+			channel:  tracked,
+			label:    tracked,
+			messages: 3,
+		},
+		{
+			desc:    "publish to tracked channel with failed publish",
+			cfg:     metrics.Config{Channels: []string{tracked}},
+			channel: tracked,
+			pubErr:  errPublish,
+			label:   tracked,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pub := new(mocks.PubSub)
+			pub.On("Publish", context.Background(), tc.channel, mock.Anything).Return(tc.pubErr)
+			messages, bytes := newCounter(), newCounter()
+			mp := metrics.NewPublisher(tc.cfg, pub, messages, bytes)
+
+			for i := 0; i < 3; i++ {
+				err := mp.Publish(context.Background(), tc.channel, &messaging.Message{Channel: tc.channel, Payload: payload})
+				assert.True(t, errors.Contains(err, tc.pubErr), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.pubErr, err))
+			}
+			assert.Equal(t, tc.messages, messages.value(tc.label), fmt.Sprintf("%s: expected %v messages got %v", tc.desc, tc.messages, messages.value(tc.label)))
+			assert.Equal(t, tc.messages*float64(len(payload)), bytes.value(tc.label), fmt.Sprintf("%s: expected %v bytes got %v", tc.desc, tc.messages*float64(len(payload)), bytes.value(tc.label)))
+		})
+	}
+}
+
+func TestPublishBuckets(t *testing.T) {
+	buckets := uint32(4)
+	pub := new(mocks.PubSub)
+	pub.On("Publish", context.Background(), mock.Anything, mock.Anything).Return(nil)
+	messages, bytes := newCounter(), newCounter()
+	mp := metrics.NewPublisher(metrics.Config{Buckets: buckets}, pub, messages, bytes)
+
+	channels := 100
+	for i := 0; i < channels; i++ {
+		channel := fmt.Sprintf("channel-%d", i)
+		err := mp.Publish(context.Background(), channel, &messaging.Message{Channel: channel, Payload: []byte("payload")})
+		assert.Nil(t, err, fmt.Sprintf("publish expected to succeed: %s", err))
+	}
+
+	labels := messages.labels()
+	assert.LessOrEqual(t, len(labels), int(buckets), fmt.Sprintf("expected at most %d labels got %d", buckets, len(labels)))
+	var total float64
+	for _, l := range labels {
+		assert.True(t, strings.HasPrefix(l, "bucket_"), fmt.Sprintf("expected bucket label got %s", l))
+		total += messages.value(l)
+	}
+	assert.Equal(t, float64(channels), total, fmt.Sprintf("expected %d messages got %v", channels, total))
+
+	// The same channel is always counted in the same bucket.
+	channel := "channel-0"
+	before := map[string]float64{}
+	for _, l := range labels {
+		before[l] = messages.value(l)
+	}
+	err := mp.Publish(context.Background(), channel, &messaging.Message{Channel: channel, Payload: []byte("payload")})
+	assert.Nil(t, err, fmt.Sprintf("publish expected to succeed: %s", err))
+	changed := 0
+	for _, l := range messages.labels() {
+		if messages.value(l) != before[l] {
+			changed++
+		}
+	}
+	assert.Equal(t, 1, changed, fmt.Sprintf("expected one bucket to change got %d", changed))
+}
+
+func TestPublishAck(t *testing.T) {
+	payload := []byte("payload")
+	receipt := messaging.Receipt{ID: "messages-1"}
+
+	cases := []struct {
+		desc     string
+		ackErr   error
+		messages float64
+	}{
+		{
+			desc:     "publish acknowledged message to tracked channel",
+			messages: 1,
+		},
+		{
+			desc:   "publish rejected message to tracked channel",
+			ackErr: errPublish,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pub := new(mocks.AckPublisher)
+			pub.On("PublishAck", context.Background(), tracked, mock.Anything).Return(receipt, tc.ackErr)
+			messages, bytes := newCounter(), newCounter()
+			mp := metrics.NewPublisher(metrics.Config{Channels: []string{tracked}}, pub, messages, bytes)
+
+			ap, ok := mp.(messaging.AckPublisher)
+			assert.True(t, ok, "expected publisher to support acknowledgments")
+			_, err := ap.PublishAck(context.Background(), tracked, &messaging.Message{Channel: tracked, Payload: payload})
+			assert.True(t, errors.Contains(err, tc.ackErr), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.ackErr, err))
+			assert.Equal(t, tc.messages, messages.value(tracked), fmt.Sprintf("%s: expected %v messages got %v", tc.desc, tc.messages, messages.value(tracked)))
+			assert.Equal(t, tc.messages*float64(len(payload)), bytes.value(tracked), fmt.Sprintf("%s: expected %v bytes got %v", tc.desc, tc.messages*float64(len(payload)), bytes.value(tracked)))
+		})
+	}
+}
+
+func TestPublishBatch(t *testing.T) {
+	payload := []byte("payload")
+	msgs := []*messaging.Message{
+		{Channel: tracked, Payload: payload},
+		{Channel: tracked, Payload: payload},
+		{Channel: tracked, Payload: payload},
+	}
+
+	cases := []struct {
+		desc     string
+		batchErr error
+		messages float64
+	}{
+		{
+			desc:     "publish batch to tracked channel",
+			messages: 3,
+		},
+		{
+			desc:     "publish rejected batch to tracked channel",
+			batchErr: errPublish,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			pub := new(mocks.BatchPublisher)
+			pub.On("PublishBatch", context.Background(), tracked, msgs).Return(tc.batchErr)
+			messages, bytes := newCounter(), newCounter()
+			mp := metrics.NewPublisher(metrics.Config{Channels: []string{tracked}}, pub, messages, bytes)
+
+			bp, ok := mp.(messaging.BatchPublisher)
+			assert.True(t, ok, "expected publisher to support batches")
+			err := bp.PublishBatch(context.Background(), tracked, msgs)
+			assert.True(t, errors.Contains(err, tc.batchErr), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.batchErr, err))
+			assert.Equal(t, tc.messages, messages.value(tracked), fmt.Sprintf("%s: expected %v messages got %v", tc.desc, tc.messages, messages.value(tracked)))
+			assert.Equal(t, tc.messages*float64(len(payload)), bytes.value(tracked), fmt.Sprintf("%s: expected %v bytes got %v", tc.desc, tc.messages*float64(len(payload)), bytes.value(tracked)))
+		})
+	}
+}
@@ -25,7 +25,10 @@ const (
 	reconnectBufSize = events.MaxUnpublishedEvents * (1024 * 1024)
 )
 
-var _ messaging.Publisher = (*publisher)(nil)
+var (
+	_ messaging.AckPublisher   = (*publisher)(nil)
+	_ messaging.BatchPublisher = (*publisher)(nil)
+)
 
 type publisher struct {
 	js     jetstream.JetStream
@@ -63,13 +66,69 @@ func NewPublisher(ctx context.Context, url string, opts ...messaging.Option) (me
 }
 
 func (pub *publisher) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	_, err := pub.publish(ctx, topic, msg)
+
+	return err
+}
+
+// PublishAck returns the receipt identifying the message by the stream and
+// the sequence number JetStream stored the message with.
+func (pub *publisher) PublishAck(ctx context.Context, topic string, msg *messaging.Message) (messaging.Receipt, error) {
+	ack, err := pub.publish(ctx, topic, msg)
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+
+	return messaging.Receipt{ID: fmt.Sprintf("%s-%d", ack.Stream, ack.Sequence)}, nil
+}
+
+// PublishBatch publishes the messages asynchronously and then waits for
+// all the acknowledgments, so the batch takes a single round trip to the
+// server instead of one per message.
+func (pub *publisher) PublishBatch(ctx context.Context, topic string, msgs []*messaging.Message) error {
+	futures := make([]jetstream.PubAckFuture, 0, len(msgs))
+	for _, msg := range msgs {
+		subject, data, err := pub.encode(topic, msg)
+		if err != nil {
+			return err
+		}
+		future, err := pub.js.PublishAsync(subject, data)
+		if err != nil {
+			return err
+		}
+		futures = append(futures, future)
+	}
+
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+func (pub *publisher) publish(ctx context.Context, topic string, msg *messaging.Message) (*jetstream.PubAck, error) {
+	subject, data, err := pub.encode(topic, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return pub.js.Publish(ctx, subject, data)
+}
+
+func (pub *publisher) encode(topic string, msg *messaging.Message) (string, []byte, error) {
 	if topic == "" {
-		return ErrEmptyTopic
+		return "", nil, ErrEmptyTopic
 	}
 
 	data, err := proto.Marshal(msg)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
 	subject := fmt.Sprintf("%s.%s", pub.prefix, topic)
@@ -77,9 +136,7 @@ func (pub *publisher) Publish(ctx context.Context, topic string, msg *messaging.
 		subject = fmt.Sprintf("%s.%s", subject, msg.GetSubtopic())
 	}
 
-	_, err = pub.js.Publish(ctx, subject, data)
-
-	return err
+	return subject, data, nil
 }
 
 func (pub *publisher) Close() error {
@@ -17,7 +17,14 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-const chansPrefix = "channels"
+const (
+	chansPrefix = "channels"
+
+	// ephemeralInactiveThreshold is the time after which the consumers of
+	// the ephemeral subscriptions which are not consumed are removed. It's
+	// well above the expiry of the pull requests of the consumers.
+	ephemeralInactiveThreshold = time.Minute
+)
 
 // Publisher and Subscriber errors.
 var (
@@ -104,6 +111,11 @@ func (ps *pubsub) Subscribe(ctx context.Context, cfg messaging.SubscriberConfig)
 		FilterSubject: cfg.Topic,
 	}
 
+	if cfg.Ephemeral {
+		consumerConfig.Durable = ""
+		consumerConfig.InactiveThreshold = ephemeralInactiveThreshold
+	}
+
 	switch cfg.DeliveryPolicy {
 	case messaging.DeliverNewPolicy:
 		consumerConfig.DeliverPolicy = jetstream.DeliverNewPolicy
@@ -149,6 +161,22 @@ func (ps *pubsub) natsHandler(h messaging.MessageHandler) func(m jetstream.Msg)
 			return
 		}
 
+		if ah, ok := h.(messaging.AckHandler); ok {
+			ah.HandleAck(&msg, func(err error) {
+				if err != nil {
+					ps.logger.Warn(fmt.Sprintf("Failed to handle Magistrala message: %s", err))
+					if err := m.Nak(); err != nil {
+						ps.logger.Warn(fmt.Sprintf("Failed to nak message: %s", err))
+					}
+					return
+				}
+				if err := m.Ack(); err != nil {
+					ps.logger.Warn(fmt.Sprintf("Failed to ack message: %s", err))
+				}
+			})
+			return
+		}
+
 		if err := h.Handle(&msg); err != nil {
 			ps.logger.Warn(fmt.Sprintf("Failed to handle Magistrala message: %s", err))
 		}
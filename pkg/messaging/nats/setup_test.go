@@ -47,7 +47,7 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
 
-	logger, err := mglog.New(os.Stdout, "error")
+	logger, err := mglog.New(os.Stdout, "error", mglog.JSONFormat)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
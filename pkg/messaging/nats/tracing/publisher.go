@@ -13,7 +13,10 @@ import (
 )
 
 // Traced operations.
-const publishOP = "publish"
+const (
+	publishOP      = "publish"
+	publishBatchOP = "publish_batch"
+)
 
 var defaultAttributes = []attribute.KeyValue{
 	attribute.String("messaging.system", "nats"),
@@ -21,7 +24,10 @@ var defaultAttributes = []attribute.KeyValue{
 	attribute.String("network.protocol.version", "2.2.4"),
 }
 
-var _ messaging.Publisher = (*publisherMiddleware)(nil)
+var (
+	_ messaging.AckPublisher   = (*publisherMiddleware)(nil)
+	_ messaging.BatchPublisher = (*publisherMiddleware)(nil)
+)
 
 type publisherMiddleware struct {
 	publisher messaging.Publisher
@@ -47,6 +53,44 @@ func (pm *publisherMiddleware) Publish(ctx context.Context, topic string, msg *m
 	return pm.publisher.Publish(ctx, topic, msg)
 }
 
+// PublishAck traces the publish waiting for the broker acknowledgment. It
+// returns messaging ErrAckNotSupported if the wrapped publisher can't wait
+// for the acknowledgment.
+func (pm *publisherMiddleware) PublishAck(ctx context.Context, topic string, msg *messaging.Message) (messaging.Receipt, error) {
+	ap, ok := pm.publisher.(messaging.AckPublisher)
+	if !ok {
+		return messaging.Receipt{}, messaging.ErrAckNotSupported
+	}
+	ctx, span := tracing.CreateSpan(ctx, publishOP, msg.GetPublisher(), topic, msg.GetSubtopic(), len(msg.GetPayload()), pm.host, trace.SpanKindClient, pm.tracer)
+	defer span.End()
+	span.SetAttributes(defaultAttributes...)
+
+	return ap.PublishAck(ctx, topic, msg)
+}
+
+// PublishBatch traces the publish of the batch as a single operation. It
+// returns messaging ErrBatchNotSupported if the wrapped publisher can't
+// publish batches.
+func (pm *publisherMiddleware) PublishBatch(ctx context.Context, topic string, msgs []*messaging.Message) error {
+	bp, ok := pm.publisher.(messaging.BatchPublisher)
+	if !ok {
+		return messaging.ErrBatchNotSupported
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	size := 0
+	for _, msg := range msgs {
+		size += len(msg.GetPayload())
+	}
+	ctx, span := tracing.CreateSpan(ctx, publishBatchOP, msgs[0].GetPublisher(), topic, "", size, pm.host, trace.SpanKindClient, pm.tracer)
+	defer span.End()
+	span.SetAttributes(defaultAttributes...)
+	span.SetAttributes(attribute.Int("messaging.batch.message_count", len(msgs)))
+
+	return bp.PublishBatch(ctx, topic, msgs)
+}
+
 func (pm *publisherMiddleware) Close() error {
 	return pm.publisher.Close()
 }
@@ -48,7 +48,7 @@ func (pm *pubsubMiddleware) Subscribe(ctx context.Context, cfg messaging.Subscri
 
 	span.SetAttributes(defaultAttributes...)
 
-	cfg.Handler = &traceHandler{
+	th := &traceHandler{
 		ctx:      ctx,
 		handler:  cfg.Handler,
 		tracer:   pm.tracer,
@@ -56,6 +56,10 @@ func (pm *pubsubMiddleware) Subscribe(ctx context.Context, cfg messaging.Subscri
 		topic:    cfg.Topic,
 		clientID: cfg.ID,
 	}
+	cfg.Handler = th
+	if ah, ok := th.handler.(messaging.AckHandler); ok {
+		cfg.Handler = &traceAckHandler{traceHandler: th, handler: ah}
+	}
 
 	return pm.pubsub.Subscribe(ctx, cfg)
 }
@@ -94,3 +98,22 @@ func (h *traceHandler) Handle(msg *messaging.Message) error {
 func (h *traceHandler) Cancel() error {
 	return h.handler.Cancel()
 }
+
+// traceAckHandler traces the handling of the messages acknowledged by the
+// handler once they are processed.
+type traceAckHandler struct {
+	*traceHandler
+	handler messaging.AckHandler
+}
+
+// HandleAck instruments the message handling operation until the message is
+// processed.
+func (h *traceAckHandler) HandleAck(msg *messaging.Message, done func(err error)) {
+	_, span := tracing.CreateSpan(h.ctx, processOp, h.clientID, h.topic, msg.GetSubtopic(), len(msg.GetPayload()), h.host, trace.SpanKindConsumer, h.tracer)
+	span.SetAttributes(defaultAttributes...)
+
+	h.handler.HandleAck(msg, func(err error) {
+		span.End()
+		done(err)
+	})
+}
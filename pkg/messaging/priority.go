@@ -0,0 +1,281 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// The priorities of the messages, from the lowest to the highest. The
+// messages without a priority are delivered as normal.
+const (
+	PriorityLow uint32 = iota + 1
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical
+)
+
+var (
+	// ErrInvalidPriority indicates an unknown message priority.
+	ErrInvalidPriority = errors.New("invalid message priority")
+
+	// ErrPriorityQueueFull indicates that the message was shed, since the
+	// queue is full of the messages of the same or higher priority.
+	ErrPriorityQueueFull = errors.New("priority queue is full")
+
+	// ErrPriorityQueueClosed indicates that the message was published after
+	// the priority publisher has been closed.
+	ErrPriorityQueueClosed = errors.New("priority queue is closed")
+)
+
+var priorityNames = map[string]uint32{
+	"low":      PriorityLow,
+	"normal":   PriorityNormal,
+	"high":     PriorityHigh,
+	"critical": PriorityCritical,
+}
+
+// ParsePriority parses the priority from its name, such as high, or from its
+// number, such as 3.
+func ParsePriority(s string) (uint32, error) {
+	if p, ok := priorityNames[strings.ToLower(s)]; ok {
+		return p, nil
+	}
+	p, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || p < uint64(PriorityLow) || p > uint64(PriorityCritical) {
+		return 0, errors.Wrap(ErrInvalidPriority, fmt.Errorf("%q", s))
+	}
+
+	return uint32(p), nil
+}
+
+// QoSPriority maps the MQTT QoS of the message to its priority. The messages
+// published at most once are routine telemetry which is shed first, and the
+// messages published exactly once are delivered ahead of the others.
+func QoSPriority(qos byte) uint32 {
+	switch qos {
+	case 0:
+		return PriorityLow
+	case 1:
+		return PriorityNormal
+	default:
+		return PriorityHigh
+	}
+}
+
+// priorityOf returns the priority of the message, normal if it is not set.
+func priorityOf(msg *Message) uint32 {
+	switch p := msg.GetPriority(); {
+	case p == 0:
+		return PriorityNormal
+	case p > PriorityCritical:
+		return PriorityCritical
+	default:
+		return p
+	}
+}
+
+// PriorityConfig defines how the published messages are queued by priority.
+type PriorityConfig struct {
+	// QueueSize is the maximum number of the queued messages. Queuing by
+	// priority is disabled if it is 0.
+	QueueSize int `env:"QUEUE_SIZE" envDefault:"0"`
+
+	// Workers is the number of the messages published at the same time.
+	Workers int `env:"WORKERS"    envDefault:"1"`
+}
+
+// Enabled reports whether the messages are queued by priority.
+func (cfg PriorityConfig) Enabled() bool {
+	return cfg.QueueSize > 0
+}
+
+type queuedMessage struct {
+	ctx   context.Context
+	topic string
+	msg   *Message
+	// done receives the error of publishing the message, or
+	// ErrPriorityQueueFull if the message is shed.
+	done chan error
+}
+
+var _ AckPublisher = (*priorityPublisher)(nil)
+
+type priorityPublisher struct {
+	publisher Publisher
+	cfg       PriorityConfig
+	logger    *slog.Logger
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queues [PriorityCritical + 1][]*queuedMessage
+	size   int
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewPriorityPublisher returns publisher which queues the published messages
+// and publishes the queued messages of the highest priority first, so the
+// critical messages are delivered ahead of the routine ones while the broker
+// is slow to accept them. Publish waits for the message to be published with
+// the context of the caller, and returns the error of publishing it.
+//
+// Once the queue is full, publishing a message sheds the oldest queued
+// message of the lowest priority below the priority of the message. If there
+// is none, the message itself is shed. Publishing the shed message returns
+// ErrPriorityQueueFull. The acknowledged messages, see Acknowledged, are
+// published right away, so they are never queued or shed.
+func NewPriorityPublisher(publisher Publisher, cfg PriorityConfig, logger *slog.Logger) AckPublisher {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	pp := &priorityPublisher{
+		publisher: publisher,
+		cfg:       cfg,
+		logger:    logger,
+	}
+	pp.cond = sync.NewCond(&pp.mu)
+	pp.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go pp.run()
+	}
+
+	return pp
+}
+
+func (pp *priorityPublisher) Publish(ctx context.Context, topic string, msg *Message) error {
+	if Acknowledged(ctx) {
+		return pp.publisher.Publish(ctx, topic, msg)
+	}
+	p := priorityOf(msg)
+	qm := &queuedMessage{ctx: ctx, topic: topic, msg: msg, done: make(chan error, 1)}
+
+	pp.mu.Lock()
+	if pp.closed {
+		pp.mu.Unlock()
+		return ErrPriorityQueueClosed
+	}
+	if pp.size >= pp.cfg.QueueSize && !pp.shed(p) {
+		pp.mu.Unlock()
+		pp.logger.Warn(fmt.Sprintf("shed message of priority %d from %s to topic %s", p, msg.GetPublisher(), topic))
+		return ErrPriorityQueueFull
+	}
+	pp.queues[p] = append(pp.queues[p], qm)
+	pp.size++
+	pp.cond.Signal()
+	pp.mu.Unlock()
+
+	select {
+	case err := <-qm.done:
+		return err
+	case <-ctx.Done():
+		if pp.remove(p, qm) {
+			return ctx.Err()
+		}
+		// The message is being published with the canceled context.
+		return <-qm.done
+	}
+}
+
+func (pp *priorityPublisher) PublishAck(ctx context.Context, topic string, msg *Message) (Receipt, error) {
+	ap, ok := pp.publisher.(AckPublisher)
+	if !ok {
+		return Receipt{}, ErrAckNotSupported
+	}
+
+	return ap.PublishAck(ctx, topic, msg)
+}
+
+// Close publishes the queued messages and closes the wrapped publisher.
+func (pp *priorityPublisher) Close() error {
+	pp.mu.Lock()
+	pp.closed = true
+	pp.cond.Broadcast()
+	pp.mu.Unlock()
+	pp.wg.Wait()
+
+	return pp.publisher.Close()
+}
+
+// shed drops the oldest queued message of the lowest priority below the
+// given one, and reports whether a message was dropped. The caller must hold
+// the lock.
+func (pp *priorityPublisher) shed(priority uint32) bool {
+	for p := PriorityLow; p < priority; p++ {
+		if len(pp.queues[p]) == 0 {
+			continue
+		}
+		qm := pp.queues[p][0]
+		pp.queues[p][0] = nil
+		pp.queues[p] = pp.queues[p][1:]
+		pp.size--
+		pp.logger.Warn(fmt.Sprintf("shed message of priority %d from %s to topic %s", p, qm.msg.GetPublisher(), qm.topic))
+		qm.done <- ErrPriorityQueueFull
+		return true
+	}
+
+	return false
+}
+
+// remove removes the message from the queue of the priority, and reports
+// whether it was still queued.
+func (pp *priorityPublisher) remove(priority uint32, qm *queuedMessage) bool {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	for i, m := range pp.queues[priority] {
+		if m == qm {
+			pp.queues[priority] = append(pp.queues[priority][:i], pp.queues[priority][i+1:]...)
+			pp.size--
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pp *priorityPublisher) run() {
+	defer pp.wg.Done()
+	for {
+		qm, ok := pp.next()
+		if !ok {
+			return
+		}
+		qm.done <- pp.publisher.Publish(qm.ctx, qm.topic, qm.msg)
+	}
+}
+
+// next waits for a queued message and returns the oldest message of the
+// highest priority. It returns false once the publisher is closed and the
+// queue is empty.
+func (pp *priorityPublisher) next() (*queuedMessage, bool) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	for pp.size == 0 {
+		if pp.closed {
+			return nil, false
+		}
+		pp.cond.Wait()
+	}
+	for p := PriorityCritical; p >= PriorityLow; p-- {
+		if len(pp.queues[p]) == 0 {
+			continue
+		}
+		qm := pp.queues[p][0]
+		pp.queues[p][0] = nil
+		pp.queues[p] = pp.queues[p][1:]
+		pp.size--
+		return qm, true
+	}
+
+	return nil, false
+}
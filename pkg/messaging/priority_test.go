@@ -0,0 +1,289 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedPublisher blocks publishing until the gate is opened, as a broker
+// which is slow to accept the messages, and records the published messages.
+type gatedPublisher struct {
+	gate    chan struct{}
+	started chan struct{}
+
+	err       error
+	mu        sync.Mutex
+	published []string
+}
+
+func newGatedPublisher() *gatedPublisher {
+	return &gatedPublisher{
+		gate:    make(chan struct{}),
+		started: make(chan struct{}, 100),
+	}
+}
+
+func (gp *gatedPublisher) Publish(_ context.Context, _ string, msg *messaging.Message) error {
+	gp.started <- struct{}{}
+	<-gp.gate
+
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+	gp.published = append(gp.published, string(msg.GetPayload()))
+
+	return gp.err
+}
+
+func (gp *gatedPublisher) Close() error {
+	return nil
+}
+
+func message(payload string, priority uint32) *messaging.Message {
+	return &messaging.Message{Channel: "1", Payload: []byte(payload), Priority: priority}
+}
+
+// publish publishes the message in the background, since publishing waits
+// for the message to be published, and returns the channel of the error of
+// publishing it. It gives the message a moment to be queued, so the messages
+// are queued in the order they are published.
+func publish(ctx context.Context, pub messaging.Publisher, msg *messaging.Message) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- pub.Publish(ctx, "1", msg)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	return errCh
+}
+
+func wait(t *testing.T, errCh <-chan error) error {
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("expected publish to return")
+		return nil
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	cases := []struct {
+		desc     string
+		priority string
+		result   uint32
+		err      error
+	}{
+		{
+			desc:     "parse priority name",
+			priority: "high",
+			result:   messaging.PriorityHigh,
+		},
+		{
+			desc:     "parse upper case priority name",
+			priority: "CRITICAL",
+			result:   messaging.PriorityCritical,
+		},
+		{
+			desc:     "parse priority number",
+			priority: "1",
+			result:   messaging.PriorityLow,
+		},
+		{
+			desc:     "parse priority number out of range",
+			priority: "5",
+			err:      messaging.ErrInvalidPriority,
+		},
+		{
+			desc:     "parse unknown priority name",
+			priority: "urgent",
+			err:      messaging.ErrInvalidPriority,
+		},
+	}
+
+	for _, tc := range cases {
+		p, err := messaging.ParsePriority(tc.priority)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.result, p, fmt.Sprintf("%s: expected priority %d got %d", tc.desc, tc.result, p))
+	}
+}
+
+func TestQoSPriority(t *testing.T) {
+	assert.Equal(t, messaging.PriorityLow, messaging.QoSPriority(0), "QoS 0 should map to low priority")
+	assert.Equal(t, messaging.PriorityNormal, messaging.QoSPriority(1), "QoS 1 should map to normal priority")
+	assert.Equal(t, messaging.PriorityHigh, messaging.QoSPriority(2), "QoS 2 should map to high priority")
+}
+
+func TestPriorityPublisherOrder(t *testing.T) {
+	gp := newGatedPublisher()
+	pub := messaging.NewPriorityPublisher(gp, messaging.PriorityConfig{QueueSize: 10, Workers: 1}, mglog.NewMock())
+
+	// The first message is taken by the worker, which then waits for the
+	// broker, so the next messages are queued.
+	errChs := []<-chan error{publish(context.Background(), pub, message("low-1", messaging.PriorityLow))}
+	<-gp.started
+
+	for _, msg := range []*messaging.Message{
+		message("low-2", messaging.PriorityLow),
+		message("normal-1", 0),
+		message("low-3", messaging.PriorityLow),
+		message("critical-1", messaging.PriorityCritical),
+		message("high-1", messaging.PriorityHigh),
+		message("critical-2", messaging.PriorityCritical),
+	} {
+		errChs = append(errChs, publish(context.Background(), pub, msg))
+	}
+
+	close(gp.gate)
+	for _, errCh := range errChs {
+		err := wait(t, errCh)
+		assert.Nil(t, err, fmt.Sprintf("publish: got unexpected error %s", err))
+	}
+	err := pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: got unexpected error %s", err))
+
+	expected := []string{"low-1", "critical-1", "critical-2", "high-1", "normal-1", "low-2", "low-3"}
+	assert.Equal(t, expected, gp.published, fmt.Sprintf("expected messages published in order %v got %v", expected, gp.published))
+}
+
+func TestPriorityPublisherShed(t *testing.T) {
+	gp := newGatedPublisher()
+	pub := messaging.NewPriorityPublisher(gp, messaging.PriorityConfig{QueueSize: 2, Workers: 1}, mglog.NewMock())
+
+	inFlight := publish(context.Background(), pub, message("in-flight", messaging.PriorityLow))
+	<-gp.started
+
+	low1 := publish(context.Background(), pub, message("low-1", messaging.PriorityLow))
+	normal1 := publish(context.Background(), pub, message("normal-1", messaging.PriorityNormal))
+
+	high1 := publish(context.Background(), pub, message("high-1", messaging.PriorityHigh))
+	err := wait(t, low1)
+	assert.Equal(t, messaging.ErrPriorityQueueFull, err, fmt.Sprintf("shed low priority message from full queue: expected error %s got %s", messaging.ErrPriorityQueueFull, err))
+
+	err = wait(t, publish(context.Background(), pub, message("low-2", messaging.PriorityLow)))
+	assert.Equal(t, messaging.ErrPriorityQueueFull, err, fmt.Sprintf("shed low priority message published to full queue: expected error %s got %s", messaging.ErrPriorityQueueFull, err))
+
+	critical1 := publish(context.Background(), pub, message("critical-1", messaging.PriorityCritical))
+	err = wait(t, normal1)
+	assert.Equal(t, messaging.ErrPriorityQueueFull, err, fmt.Sprintf("shed normal priority message from full queue: expected error %s got %s", messaging.ErrPriorityQueueFull, err))
+
+	err = wait(t, publish(context.Background(), pub, message("high-2", messaging.PriorityHigh)))
+	assert.Equal(t, messaging.ErrPriorityQueueFull, err, fmt.Sprintf("shed high priority message published to queue full of higher and same priority: expected error %s got %s", messaging.ErrPriorityQueueFull, err))
+
+	close(gp.gate)
+	for _, errCh := range []<-chan error{inFlight, high1, critical1} {
+		err := wait(t, errCh)
+		assert.Nil(t, err, fmt.Sprintf("publish: got unexpected error %s", err))
+	}
+	err = pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: got unexpected error %s", err))
+
+	expected := []string{"in-flight", "critical-1", "high-1"}
+	assert.Equal(t, expected, gp.published, fmt.Sprintf("expected messages published %v got %v", expected, gp.published))
+
+	err = pub.Publish(context.Background(), "1", message("closed", messaging.PriorityCritical))
+	assert.Equal(t, messaging.ErrPriorityQueueClosed, err, fmt.Sprintf("publish after close: expected error %s got %s", messaging.ErrPriorityQueueClosed, err))
+}
+
+func TestPriorityPublisherAcknowledged(t *testing.T) {
+	gp := newGatedPublisher()
+	pub := messaging.NewPriorityPublisher(gp, messaging.PriorityConfig{QueueSize: 1, Workers: 1}, mglog.NewMock())
+
+	// Fill the worker and the queue with the critical messages.
+	inFlight := publish(context.Background(), pub, message("in-flight", messaging.PriorityCritical))
+	<-gp.started
+	queued := publish(context.Background(), pub, message("queued", messaging.PriorityCritical))
+
+	// The acknowledged message is published right away, so it is neither
+	// queued behind nor shed by the critical messages.
+	acked := publish(messaging.WithQoS(context.Background(), 1), pub, message("acked", messaging.PriorityLow))
+	select {
+	case <-gp.started:
+	case <-time.After(time.Second):
+		t.Fatal("expected acknowledged message to be published right away")
+	}
+
+	close(gp.gate)
+	for _, errCh := range []<-chan error{inFlight, queued, acked} {
+		err := wait(t, errCh)
+		assert.Nil(t, err, fmt.Sprintf("publish: got unexpected error %s", err))
+	}
+	err := pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: got unexpected error %s", err))
+	assert.ElementsMatch(t, []string{"in-flight", "queued", "acked"}, gp.published, fmt.Sprintf("expected all messages published got %v", gp.published))
+}
+
+func TestPriorityPublisherError(t *testing.T) {
+	gp := newGatedPublisher()
+	gp.err = errors.New("broker error")
+	close(gp.gate)
+	pub := messaging.NewPriorityPublisher(gp, messaging.PriorityConfig{QueueSize: 10, Workers: 1}, mglog.NewMock())
+
+	err := pub.Publish(context.Background(), "1", message("queued", messaging.PriorityHigh))
+	assert.Equal(t, gp.err, err, fmt.Sprintf("publish queued message: expected error %s got %s", gp.err, err))
+
+	err = pub.Publish(messaging.WithQoS(context.Background(), 2), "1", message("acked", messaging.PriorityHigh))
+	assert.Equal(t, gp.err, err, fmt.Sprintf("publish acknowledged message: expected error %s got %s", gp.err, err))
+
+	err = pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: got unexpected error %s", err))
+}
+
+func TestPriorityPublisherCancel(t *testing.T) {
+	gp := newGatedPublisher()
+	pub := messaging.NewPriorityPublisher(gp, messaging.PriorityConfig{QueueSize: 10, Workers: 1}, mglog.NewMock())
+
+	inFlight := publish(context.Background(), pub, message("in-flight", messaging.PriorityLow))
+	<-gp.started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := publish(ctx, pub, message("canceled", messaging.PriorityHigh))
+	cancel()
+	err := wait(t, canceled)
+	assert.Equal(t, context.Canceled, err, fmt.Sprintf("publish canceled message: expected error %s got %s", context.Canceled, err))
+
+	close(gp.gate)
+	err = wait(t, inFlight)
+	assert.Nil(t, err, fmt.Sprintf("publish: got unexpected error %s", err))
+	err = pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: got unexpected error %s", err))
+
+	expected := []string{"in-flight"}
+	assert.Equal(t, expected, gp.published, fmt.Sprintf("expected canceled message not published got %v", gp.published))
+}
+
+func TestPriorityPublisherWorkers(t *testing.T) {
+	gp := newGatedPublisher()
+	pub := messaging.NewPriorityPublisher(gp, messaging.PriorityConfig{QueueSize: 10, Workers: 3}, mglog.NewMock())
+
+	var errChs []<-chan error
+	for i := 0; i < 3; i++ {
+		errChs = append(errChs, publish(context.Background(), pub, message(fmt.Sprintf("msg-%d", i), 0)))
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-gp.started:
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d messages published at the same time, got %d", 3, i)
+		}
+	}
+
+	close(gp.gate)
+	for _, errCh := range errChs {
+		err := wait(t, errCh)
+		assert.Nil(t, err, fmt.Sprintf("publish: got unexpected error %s", err))
+	}
+	err := pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: got unexpected error %s", err))
+	assert.Len(t, gp.published, 3, fmt.Sprintf("expected 3 messages published got %d", len(gp.published)))
+}
@@ -0,0 +1,24 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import "context"
+
+type protocolKey struct{}
+
+// WithProtocol returns a copy of the context carrying the protocol the message
+// was published over, for the handlers shared by several protocols.
+func WithProtocol(ctx context.Context, protocol string) context.Context {
+	return context.WithValue(ctx, protocolKey{}, protocol)
+}
+
+// ProtocolFrom returns the protocol carried by the context, or def if the
+// context doesn't carry one.
+func ProtocolFrom(ctx context.Context, def string) string {
+	if p, _ := ctx.Value(protocolKey{}).(string); p != "" {
+		return p
+	}
+
+	return def
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+)
+
+const (
+	// ContentTypeParam is the topic query parameter carrying the content type
+	// of the published message, which is checked against the content types
+	// the channel accepts.
+	ContentTypeParam = "content_type"
+
+	// SignatureParam is the topic query parameter carrying the signature of
+	// the published payload, which is verified on the channels requiring
+	// signatures.
+	SignatureParam = "signature"
+)
+
+type (
+	contentTypeKey struct{}
+	signatureKey   struct{}
+)
+
+// WithContentType returns the context carrying the content type of the
+// published message, for the protocols setting it outside of the topic, such
+// as the HTTP Content-Type header or the CoAP Content-Format option.
+func WithContentType(ctx context.Context, contentType string) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, contentType)
+}
+
+// WithSignature returns the context carrying the signature of the published
+// payload, for the protocols setting it outside of the topic, such as the
+// HTTP Message-Signature header.
+func WithSignature(ctx context.Context, signature string) context.Context {
+	return context.WithValue(ctx, signatureKey{}, signature)
+}
+
+// ContentTypeFrom returns the content type of the published message, set
+// either in the context or in the query of the topic.
+func ContentTypeFrom(ctx context.Context, topic string) string {
+	if ct, _ := ctx.Value(contentTypeKey{}).(string); ct != "" {
+		return ct
+	}
+
+	return topicParam(topic, ContentTypeParam)
+}
+
+// SignatureFrom returns the signature of the published payload, set either in
+// the context or in the query of the topic.
+func SignatureFrom(ctx context.Context, topic string) string {
+	if sig, _ := ctx.Value(signatureKey{}).(string); sig != "" {
+		return sig
+	}
+
+	return topicParam(topic, SignatureParam)
+}
+
+// CheckRate takes a token from the bucket of the authorized thing. It reports
+// whether the message may be published. Messages over the rate are rejected
+// with the limiter error, or shed without an error if the limiter sheds them.
+// Nil limiter doesn't limit the things.
+func CheckRate(ctx context.Context, limiter ratelimit.Limiter, res *magistrala.ThingsAuthzRes) (bool, error) {
+	if limiter == nil {
+		return true, nil
+	}
+	limit := ratelimit.Limit{Rate: res.GetRate(), Burst: res.GetBurst()}
+	if err := limiter.Allow(ctx, res.GetId(), limit); err != nil {
+		if limiter.Mode() == ratelimit.Shed {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func topicParam(topic, param string) string {
+	if _, query, ok := strings.Cut(topic, "?"); ok {
+		if values, err := url.ParseQuery(query); err == nil {
+			return values.Get(param)
+		}
+	}
+
+	return ""
+}
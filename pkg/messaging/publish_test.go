@@ -0,0 +1,143 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	"github.com/absmach/magistrala/pkg/ratelimit/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentTypeFrom(t *testing.T) {
+	cases := []struct {
+		desc        string
+		ctx         context.Context
+		topic       string
+		contentType string
+	}{
+		{
+			desc:        "content type from context",
+			ctx:         messaging.WithContentType(context.Background(), "application/json"),
+			topic:       "channels/1/messages?content_type=text/plain",
+			contentType: "application/json",
+		},
+		{
+			desc:        "content type from topic",
+			ctx:         context.Background(),
+			topic:       "channels/1/messages?content_type=text%2Fplain",
+			contentType: "text/plain",
+		},
+		{
+			desc:  "content type from topic without query",
+			ctx:   context.Background(),
+			topic: "channels/1/messages",
+		},
+		{
+			desc:  "content type from topic with invalid query",
+			ctx:   context.Background(),
+			topic: "channels/1/messages?content_type=%zz",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ct := messaging.ContentTypeFrom(tc.ctx, tc.topic)
+			assert.Equal(t, tc.contentType, ct, fmt.Sprintf("%s: expected content type %q got %q", tc.desc, tc.contentType, ct))
+		})
+	}
+}
+
+func TestSignatureFrom(t *testing.T) {
+	cases := []struct {
+		desc      string
+		ctx       context.Context
+		topic     string
+		signature string
+	}{
+		{
+			desc:      "signature from context",
+			ctx:       messaging.WithSignature(context.Background(), "header"),
+			topic:     "channels/1/messages?signature=query",
+			signature: "header",
+		},
+		{
+			desc:      "signature from topic",
+			ctx:       context.Background(),
+			topic:     "channels/1/messages?signature=query",
+			signature: "query",
+		},
+		{
+			desc:  "signature from topic without query",
+			ctx:   context.Background(),
+			topic: "channels/1/messages",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			sig := messaging.SignatureFrom(tc.ctx, tc.topic)
+			assert.Equal(t, tc.signature, sig, fmt.Sprintf("%s: expected signature %q got %q", tc.desc, tc.signature, sig))
+		})
+	}
+}
+
+func TestCheckRate(t *testing.T) {
+	res := &magistrala.ThingsAuthzRes{Id: "thing", Rate: 1, Burst: 2}
+	limit := ratelimit.Limit{Rate: 1, Burst: 2}
+
+	cases := []struct {
+		desc     string
+		limiter  bool
+		mode     string
+		allowErr error
+		allowed  bool
+		err      error
+	}{
+		{
+			desc:    "check rate without limiter",
+			allowed: true,
+		},
+		{
+			desc:    "check rate within the limit",
+			limiter: true,
+			mode:    ratelimit.Reject,
+			allowed: true,
+		},
+		{
+			desc:     "check rate over the limit with reject mode",
+			limiter:  true,
+			mode:     ratelimit.Reject,
+			allowErr: ratelimit.ErrRateLimited,
+			err:      ratelimit.ErrRateLimited,
+		},
+		{
+			desc:     "check rate over the limit with shed mode",
+			limiter:  true,
+			mode:     ratelimit.Shed,
+			allowErr: ratelimit.ErrRateLimited,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var limiter ratelimit.Limiter
+			if tc.limiter {
+				l := new(mocks.Limiter)
+				l.On("Allow", context.Background(), res.GetId(), limit).Return(tc.allowErr)
+				l.On("Mode").Return(tc.mode)
+				limiter = l
+			}
+			allowed, err := messaging.CheckRate(context.Background(), limiter, res)
+			assert.Equal(t, tc.allowed, allowed, fmt.Sprintf("%s: expected allowed %t got %t", tc.desc, tc.allowed, allowed))
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		})
+	}
+}
@@ -3,7 +3,19 @@
 
 package messaging
 
-import "context"
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// ErrAckNotSupported indicates that the publisher can't wait for the broker
+// acknowledgment.
+var ErrAckNotSupported = errors.New("publisher does not support acknowledgments")
+
+// ErrBatchNotSupported indicates that the publisher can't publish several
+// messages at once.
+var ErrBatchNotSupported = errors.New("publisher does not support batches")
 
 type DeliveryPolicy uint8
 
@@ -25,6 +37,37 @@ type Publisher interface {
 	Close() error
 }
 
+// Receipt is the broker acknowledgment of a published message.
+type Receipt struct {
+	// ID identifies the message accepted by the broker.
+	ID string `json:"id"`
+}
+
+// AckPublisher specifies a publisher which can wait for the broker to
+// acknowledge the published message.
+//
+//go:generate mockery --name AckPublisher --filename ack_publisher.go --quiet --note "Copyright (c) Abstract Machines"
+type AckPublisher interface {
+	Publisher
+
+	// PublishAck publishes the message and returns the receipt once the
+	// broker has accepted it, or an error if the broker rejects it.
+	PublishAck(ctx context.Context, topic string, msg *Message) (Receipt, error)
+}
+
+// BatchPublisher specifies a publisher which can publish several messages
+// to the same topic without waiting for the broker after each of them.
+//
+//go:generate mockery --name BatchPublisher --filename batch_publisher.go --quiet --note "Copyright (c) Abstract Machines"
+type BatchPublisher interface {
+	Publisher
+
+	// PublishBatch publishes the messages in the given order and returns
+	// once the broker has accepted all of them, or an error if the broker
+	// rejects any of them.
+	PublishBatch(ctx context.Context, topic string, msgs []*Message) error
+}
+
 // MessageHandler represents Message handler for Subscriber.
 type MessageHandler interface {
 	// Handle handles messages passed by underlying implementation.
@@ -34,11 +77,28 @@ type MessageHandler interface {
 	Cancel() error
 }
 
+// AckHandler is a MessageHandler which may finish handling a message after
+// HandleAck returns. The subscribers of the brokers with acknowledgments
+// acknowledge the message only once done is called, and return it to the
+// broker for redelivery if done is called with an error. The other
+// subscribers handle the messages with Handle.
+type AckHandler interface {
+	MessageHandler
+
+	// HandleAck handles the message and calls done exactly once, when the
+	// message is processed or fails to be processed.
+	HandleAck(msg *Message, done func(err error))
+}
+
 type SubscriberConfig struct {
 	ID             string
 	Topic          string
 	Handler        MessageHandler
 	DeliveryPolicy DeliveryPolicy
+	// Ephemeral subscriptions are removed by the broker once they are not
+	// consumed anymore, so the subscriptions of the subscribers which stop
+	// without unsubscribing don't outlive them.
+	Ephemeral bool
 }
 
 // Subscriber specifies message subscription API.
@@ -0,0 +1,24 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import "context"
+
+type qosKey struct{}
+
+// WithQoS returns the context of publishing a message with the MQTT QoS.
+// The messages published with QoS 1 or 2 are acknowledged to the client
+// once Publish returns, so the publishers must not drop them or queue them
+// to be published later.
+func WithQoS(ctx context.Context, qos byte) context.Context {
+	return context.WithValue(ctx, qosKey{}, qos)
+}
+
+// Acknowledged reports whether the message published with the context is
+// acknowledged to the client, see WithQoS.
+func Acknowledged(ctx context.Context) bool {
+	qos, ok := ctx.Value(qosKey{}).(byte)
+
+	return ok && qos > 0
+}
@@ -5,21 +5,36 @@ package rabbitmq
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/absmach/magistrala/pkg/messaging"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"google.golang.org/protobuf/proto"
 )
 
-var _ messaging.Publisher = (*publisher)(nil)
+// ErrNacked indicates that the broker has rejected the published message.
+var ErrNacked = errors.New("message rejected by the broker")
+
+var (
+	_ messaging.AckPublisher   = (*publisher)(nil)
+	_ messaging.BatchPublisher = (*publisher)(nil)
+)
 
 type publisher struct {
 	conn     *amqp.Connection
 	channel  *amqp.Channel
 	prefix   string
 	exchange string
+
+	// confirms is the channel in confirm mode, opened on the first publish
+	// waiting for the broker acknowledgment.
+	confirms  *amqp.Channel
+	confirmMu sync.Mutex
 }
 
 // NewPublisher returns RabbitMQ message Publisher.
@@ -53,37 +68,104 @@ func NewPublisher(url string, opts ...messaging.Option) (messaging.Publisher, er
 }
 
 func (pub *publisher) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	subject, publishing, err := pub.publishing(topic, msg)
+	if err != nil {
+		return err
+	}
+
+	err = pub.channel.PublishWithContext(ctx, pub.exchange, subject, false, false, publishing)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PublishBatch publishes the messages one after another. Publishing doesn't
+// wait for the broker, so the messages are sent without a round trip each.
+func (pub *publisher) PublishBatch(ctx context.Context, topic string, msgs []*messaging.Message) error {
+	for _, msg := range msgs {
+		if err := pub.Publish(ctx, topic, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PublishAck publishes the message with a random message ID using publisher
+// confirms, and returns the message ID as the receipt once confirmed.
+func (pub *publisher) PublishAck(ctx context.Context, topic string, msg *messaging.Message) (messaging.Receipt, error) {
+	subject, publishing, err := pub.publishing(topic, msg)
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return messaging.Receipt{}, err
+	}
+	publishing.MessageId = hex.EncodeToString(id)
+
+	ch, err := pub.confirmChannel()
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, pub.exchange, subject, false, false, publishing)
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return messaging.Receipt{}, err
+	}
+	if !acked {
+		return messaging.Receipt{}, ErrNacked
+	}
+
+	return messaging.Receipt{ID: publishing.MessageId}, nil
+}
+
+func (pub *publisher) publishing(topic string, msg *messaging.Message) (string, amqp.Publishing, error) {
 	if topic == "" {
-		return ErrEmptyTopic
+		return "", amqp.Publishing{}, ErrEmptyTopic
 	}
 	data, err := proto.Marshal(msg)
 	if err != nil {
-		return err
+		return "", amqp.Publishing{}, err
 	}
 
 	subject := fmt.Sprintf("%s.%s", pub.prefix, topic)
 	if msg.GetSubtopic() != "" {
 		subject = fmt.Sprintf("%s.%s", subject, msg.GetSubtopic())
 	}
-	subject = formatTopic(subject)
-
-	err = pub.channel.PublishWithContext(
-		ctx,
-		pub.exchange,
-		subject,
-		false,
-		false,
-		amqp.Publishing{
-			Headers:     amqp.Table{},
-			ContentType: "application/octet-stream",
-			AppId:       "magistrala-publisher",
-			Body:        data,
-		})
+
+	return formatTopic(subject), amqp.Publishing{
+		Headers:     amqp.Table{},
+		ContentType: "application/octet-stream",
+		AppId:       "magistrala-publisher",
+		Body:        data,
+	}, nil
+}
+
+// confirmChannel returns the channel in confirm mode, reopening it if it has
+// been closed, since the broker closes the channel on errors.
+func (pub *publisher) confirmChannel() (*amqp.Channel, error) {
+	pub.confirmMu.Lock()
+	defer pub.confirmMu.Unlock()
+
+	if pub.confirms != nil && !pub.confirms.IsClosed() {
+		return pub.confirms, nil
+	}
+	ch, err := pub.conn.Channel()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if err := ch.Confirm(false); err != nil {
+		return nil, err
+	}
+	pub.confirms = ch
 
-	return nil
+	return ch, nil
 }
 
 func (pub *publisher) Close() error {
@@ -113,7 +113,9 @@ func (ps *pubsub) Subscribe(ctx context.Context, cfg messaging.SubscriberConfig)
 
 	clientID := fmt.Sprintf("%s-%s", cfg.Topic, cfg.ID)
 
-	queue, err := ps.channel.QueueDeclare(clientID, true, false, false, false, nil)
+	// The queues of the ephemeral subscriptions are deleted once their
+	// consumer is canceled or its connection is lost.
+	queue, err := ps.channel.QueueDeclare(clientID, !cfg.Ephemeral, cfg.Ephemeral, false, false, nil)
 	if err != nil {
 		return err
 	}
@@ -122,7 +124,10 @@ func (ps *pubsub) Subscribe(ctx context.Context, cfg messaging.SubscriberConfig)
 		return err
 	}
 
-	msgs, err := ps.channel.Consume(queue.Name, clientID, true, false, false, false, nil)
+	// The handlers acknowledging the messages themselves consume them
+	// without the automatic acknowledgment.
+	_, manualAck := cfg.Handler.(messaging.AckHandler)
+	msgs, err := ps.channel.Consume(queue.Name, clientID, !manualAck, false, false, false, nil)
 	if err != nil {
 		return err
 	}
@@ -183,6 +188,21 @@ func (ps *pubsub) handle(deliveries <-chan amqp.Delivery, h messaging.MessageHan
 			ps.logger.Warn(fmt.Sprintf("Failed to unmarshal received message: %s", err))
 			return
 		}
+		if ah, ok := h.(messaging.AckHandler); ok {
+			ah.HandleAck(&msg, func(err error) {
+				if err != nil {
+					ps.logger.Warn(fmt.Sprintf("Failed to handle Magistrala message: %s", err))
+					if err := d.Nack(false, true); err != nil {
+						ps.logger.Warn(fmt.Sprintf("Failed to nack message: %s", err))
+					}
+					return
+				}
+				if err := d.Ack(false); err != nil {
+					ps.logger.Warn(fmt.Sprintf("Failed to ack message: %s", err))
+				}
+			})
+			continue
+		}
 		if err := h.Handle(&msg); err != nil {
 			ps.logger.Warn(fmt.Sprintf("Failed to handle Magistrala message: %s", err))
 			return
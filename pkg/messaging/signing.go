@@ -0,0 +1,131 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	// SigningReject rejects the messages without a valid signature.
+	SigningReject = "reject"
+
+	// SigningFlag publishes the messages without a valid signature, marked
+	// as unverified so the consumers can tell them apart.
+	SigningFlag = "flag"
+)
+
+var (
+	// ErrMissingSignature indicates that the message published to a channel
+	// requiring signatures is not signed.
+	ErrMissingSignature = errors.New("missing message signature")
+
+	// ErrInvalidSignature indicates that the message signature doesn't match
+	// the payload, so the message has been tampered with or signed with
+	// another secret.
+	ErrInvalidSignature = errors.New("invalid message signature")
+)
+
+var errMissingSecret = errors.New("publisher has no signing secret")
+
+// SigningConfig lists the channels requiring signed messages.
+type SigningConfig struct {
+	// Channels contains comma-separated channel IDs, each optionally
+	// followed by a colon and the mode, such as "<channel_id>:flag". The
+	// mode defaults to reject.
+	Channels string `env:"CHANNELS" envDefault:""`
+}
+
+// SigningRules verifies the signatures of the messages published to the
+// channels requiring them. The zero value requires no signatures.
+type SigningRules struct {
+	modes map[string]string
+}
+
+// NewSigningRules returns signing rules built from the given config.
+func NewSigningRules(cfg SigningConfig) (SigningRules, error) {
+	rules := SigningRules{modes: make(map[string]string)}
+	for _, entry := range strings.Split(cfg.Channels, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		channel, mode, ok := strings.Cut(entry, ":")
+		if !ok {
+			mode = SigningReject
+		}
+		if channel == "" {
+			return SigningRules{}, fmt.Errorf("invalid signing channel %q: missing channel ID", entry)
+		}
+		if mode != SigningReject && mode != SigningFlag {
+			return SigningRules{}, fmt.Errorf("invalid signing mode %q of channel %s", mode, channel)
+		}
+		rules.modes[channel] = mode
+	}
+
+	return rules, nil
+}
+
+// Mode returns the signing mode of the channel, or an empty string if the
+// channel doesn't require signatures.
+func (sr SigningRules) Mode(channel string) string {
+	return sr.modes[channel]
+}
+
+// Verify verifies the signature of the payload published to the channel by
+// the thing holding the signing secret. Messages published to the channels
+// which don't require signatures are not verified, and the messages of the
+// things without a signing secret are never valid.
+func (sr SigningRules) Verify(channel, secret string, payload []byte, signature string) error {
+	if sr.Mode(channel) == "" {
+		return nil
+	}
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	if secret == "" {
+		return errors.Wrap(ErrInvalidSignature, errMissingSecret)
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.Wrap(ErrInvalidSignature, err)
+	}
+	if !hmac.Equal(sig, mac(secret, payload)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Check verifies the signature of the message like Verify. The messages
+// published to the channels in the flag mode are not rejected, but marked
+// as unverified instead, in which case Check returns nil.
+func (sr SigningRules) Check(msg *Message, secret, signature string) error {
+	err := sr.Verify(msg.GetChannel(), secret, msg.GetPayload(), signature)
+	if err != nil && sr.Mode(msg.GetChannel()) == SigningFlag {
+		msg.Unverified = true
+		return nil
+	}
+
+	return err
+}
+
+// Sign returns the signature of the payload, which is the hex encoded
+// HMAC-SHA256 of the payload keyed with the signing secret of the thing.
+func Sign(secret string, payload []byte) string {
+	return hex.EncodeToString(mac(secret, payload))
+}
+
+func mac(secret string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(payload)
+
+	return h.Sum(nil)
+}
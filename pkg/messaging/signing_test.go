@@ -0,0 +1,178 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSigningRules(t *testing.T) {
+	cases := []struct {
+		desc     string
+		channels string
+		modes    map[string]string
+		err      bool
+	}{
+		{
+			desc:     "parse channels with default and explicit modes",
+			channels: "chan1, chan2:flag,chan3:reject",
+			modes:    map[string]string{"chan1": messaging.SigningReject, "chan2": messaging.SigningFlag, "chan3": messaging.SigningReject, "chan4": ""},
+		},
+		{
+			desc:     "parse channel with unknown mode",
+			channels: "chan1:drop",
+			err:      true,
+		},
+		{
+			desc:     "parse channel without ID",
+			channels: ":flag",
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rules, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: tc.channels})
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			for channel, mode := range tc.modes {
+				assert.Equal(t, mode, rules.Mode(channel), fmt.Sprintf("%s: unexpected mode of %s", tc.desc, channel))
+			}
+		})
+	}
+}
+
+func TestSigningRulesVerify(t *testing.T) {
+	const secret = "signing_secret"
+	payload := []byte(`[{"n":"current","t":-1,"v":1.6}]`)
+	rules, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: "signed"})
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cases := []struct {
+		desc      string
+		channel   string
+		secret    string
+		payload   []byte
+		signature string
+		err       error
+	}{
+		{
+			desc:      "verify validly signed message",
+			channel:   "signed",
+			secret:    secret,
+			payload:   payload,
+			signature: messaging.Sign(secret, payload),
+		},
+		{
+			desc:      "verify tampered message",
+			channel:   "signed",
+			secret:    secret,
+			payload:   []byte(`[{"n":"current","t":-1,"v":9.6}]`),
+			signature: messaging.Sign(secret, payload),
+			err:       messaging.ErrInvalidSignature,
+		},
+		{
+			desc:      "verify message signed with another secret",
+			channel:   "signed",
+			secret:    secret,
+			payload:   payload,
+			signature: messaging.Sign("other_secret", payload),
+			err:       messaging.ErrInvalidSignature,
+		},
+		{
+			desc:      "verify message with malformed signature",
+			channel:   "signed",
+			secret:    secret,
+			payload:   payload,
+			signature: "not-hex",
+			err:       messaging.ErrInvalidSignature,
+		},
+		{
+			desc:      "verify message of thing without signing secret",
+			channel:   "signed",
+			payload:   payload,
+			signature: messaging.Sign("", payload),
+			err:       messaging.ErrInvalidSignature,
+		},
+		{
+			desc:    "verify unsigned message",
+			channel: "signed",
+			secret:  secret,
+			payload: payload,
+			err:     messaging.ErrMissingSignature,
+		},
+		{
+			desc:    "verify unsigned message on channel without signing",
+			channel: "unsigned",
+			secret:  secret,
+			payload: payload,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := rules.Verify(tc.channel, tc.secret, tc.payload, tc.signature)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		})
+	}
+}
+
+func TestSigningRulesCheck(t *testing.T) {
+	const secret = "signing_secret"
+	payload := []byte(`[{"n":"current","t":-1,"v":1.6}]`)
+	rules, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: "rejected,flagged:flag"})
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cases := []struct {
+		desc       string
+		channel    string
+		signature  string
+		unverified bool
+		err        error
+	}{
+		{
+			desc:      "check validly signed message on rejecting channel",
+			channel:   "rejected",
+			signature: messaging.Sign(secret, payload),
+		},
+		{
+			desc:    "check unsigned message on rejecting channel",
+			channel: "rejected",
+			err:     messaging.ErrMissingSignature,
+		},
+		{
+			desc:      "check validly signed message on flagging channel",
+			channel:   "flagged",
+			signature: messaging.Sign(secret, payload),
+		},
+		{
+			desc:       "check message with invalid signature on flagging channel",
+			channel:    "flagged",
+			signature:  messaging.Sign("other_secret", payload),
+			unverified: true,
+		},
+		{
+			desc:       "check unsigned message on flagging channel",
+			channel:    "flagged",
+			unverified: true,
+		},
+		{
+			desc:    "check unsigned message on channel without signing",
+			channel: "unsigned",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			msg := messaging.Message{Channel: tc.channel, Payload: payload}
+			err := rules.Check(&msg, secret, tc.signature)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			assert.Equal(t, tc.unverified, msg.GetUnverified(), fmt.Sprintf("%s: expected unverified %t got %t", tc.desc, tc.unverified, msg.GetUnverified()))
+		})
+	}
+}
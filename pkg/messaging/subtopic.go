@@ -0,0 +1,94 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	subtopicSeparator = "."
+	wildcardOne       = "*"
+	wildcardAll       = ">"
+)
+
+var (
+	// ErrSubtopicTooDeep indicates subtopic has more levels than allowed.
+	ErrSubtopicTooDeep = errors.New("subtopic exceeds maximum number of levels")
+
+	// ErrSubtopicPattern indicates subtopic level doesn't match the configured pattern.
+	ErrSubtopicPattern = errors.New("subtopic level does not match allowed pattern")
+)
+
+// SubtopicConfig contains subtopic normalization and validation rules
+// applied to published messages and subscriptions. The defaults leave the
+// subtopics unchanged.
+type SubtopicConfig struct {
+	Lowercase bool   `env:"LOWERCASE"  envDefault:"false"`
+	MaxDepth  int    `env:"MAX_DEPTH"  envDefault:"0"`
+	Pattern   string `env:"PATTERN"    envDefault:""`
+}
+
+// SubtopicRules normalizes and validates subtopics of published messages and
+// subscriptions.
+// The zero value leaves subtopics unchanged.
+type SubtopicRules struct {
+	lowercase bool
+	maxDepth  int
+	pattern   *regexp.Regexp
+}
+
+// NewSubtopicRules returns subtopic rules built from the given config.
+func NewSubtopicRules(cfg SubtopicConfig) (SubtopicRules, error) {
+	rules := SubtopicRules{
+		lowercase: cfg.Lowercase,
+		maxDepth:  cfg.MaxDepth,
+	}
+	if cfg.Pattern != "" {
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return SubtopicRules{}, fmt.Errorf("invalid subtopic pattern: %w", err)
+		}
+		rules.pattern = re
+	}
+
+	return rules, nil
+}
+
+// Apply normalizes parsed subtopic, with levels separated by dots, and
+// validates it against the rules. Empty levels are dropped and levels
+// are never split or merged, so the subtopic keeps its topic levels.
+func (sr SubtopicRules) Apply(subtopic string) (string, error) {
+	if subtopic == "" {
+		return subtopic, nil
+	}
+
+	levels := []string{}
+	for _, level := range strings.Split(subtopic, subtopicSeparator) {
+		if level == "" {
+			continue
+		}
+		if level == wildcardOne || level == wildcardAll {
+			levels = append(levels, level)
+			continue
+		}
+		if sr.lowercase {
+			level = strings.ToLower(level)
+		}
+		if sr.pattern != nil && !sr.pattern.MatchString(level) {
+			return "", errors.Wrap(ErrSubtopicPattern, fmt.Errorf("level %q does not match %s", level, sr.pattern))
+		}
+		levels = append(levels, level)
+	}
+
+	if sr.maxDepth > 0 && len(levels) > sr.maxDepth {
+		return "", errors.Wrap(ErrSubtopicTooDeep, fmt.Errorf("%d levels exceed maximum of %d", len(levels), sr.maxDepth))
+	}
+
+	return strings.Join(levels, subtopicSeparator), nil
+}
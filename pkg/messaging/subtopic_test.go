@@ -0,0 +1,95 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSubtopicRules(t *testing.T) {
+	_, err := messaging.NewSubtopicRules(messaging.SubtopicConfig{Pattern: `^[a-z`})
+	assert.NotNil(t, err, "expected error for invalid pattern")
+
+	_, err = messaging.NewSubtopicRules(messaging.SubtopicConfig{Pattern: `^[a-z]+$`, MaxDepth: 4})
+	assert.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+}
+
+func TestSubtopicRulesApply(t *testing.T) {
+	cases := []struct {
+		desc     string
+		cfg      messaging.SubtopicConfig
+		subtopic string
+		result   string
+		err      error
+	}{
+		{
+			desc:     "apply zero rules to subtopic",
+			subtopic: "Temp.Sensor",
+			result:   "Temp.Sensor",
+		},
+		{
+			desc:     "apply rules to empty subtopic",
+			cfg:      messaging.SubtopicConfig{Lowercase: true, MaxDepth: 1},
+			subtopic: "",
+			result:   "",
+		},
+		{
+			desc:     "case-fold subtopic",
+			cfg:      messaging.SubtopicConfig{Lowercase: true},
+			subtopic: "Temp",
+			result:   "temp",
+		},
+		{
+			desc:     "collapse empty levels",
+			cfg:      messaging.SubtopicConfig{Lowercase: true},
+			subtopic: ".Temp..Sensor.",
+			result:   "temp.sensor",
+		},
+		{
+			desc:     "keep wildcard levels",
+			cfg:      messaging.SubtopicConfig{Lowercase: true, Pattern: `^[a-z]+$`},
+			subtopic: "Temp.*.>",
+			result:   "temp.*.>",
+		},
+		{
+			desc:     "subtopic at max depth",
+			cfg:      messaging.SubtopicConfig{MaxDepth: 3},
+			subtopic: "a.b..c",
+			result:   "a.b.c",
+		},
+		{
+			desc:     "subtopic exceeding max depth",
+			cfg:      messaging.SubtopicConfig{MaxDepth: 3},
+			subtopic: "a.b.c.d",
+			err:      messaging.ErrSubtopicTooDeep,
+		},
+		{
+			desc:     "subtopic matching pattern after case-folding",
+			cfg:      messaging.SubtopicConfig{Lowercase: true, Pattern: `^[a-z0-9_-]+$`},
+			subtopic: "Floor_1.Room-2",
+			result:   "floor_1.room-2",
+		},
+		{
+			desc:     "subtopic not matching pattern",
+			cfg:      messaging.SubtopicConfig{Pattern: `^[a-z0-9_-]+$`},
+			subtopic: "temp.Sensor",
+			err:      messaging.ErrSubtopicPattern,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rules, err := messaging.NewSubtopicRules(tc.cfg)
+			assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+			result, err := rules.Apply(tc.subtopic)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+			assert.Equal(t, tc.result, result, fmt.Sprintf("%s: expected subtopic %s, got %s", tc.desc, tc.result, result))
+		})
+	}
+}
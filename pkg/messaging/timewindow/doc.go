@@ -0,0 +1,10 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package timewindow provides the rejection of the published messages with
+// SenML records timestamped outside of the allowed window.
+//
+// The writers can only drop or clamp such records once the message is
+// accepted, so the publisher is never told about it. Rejecting the messages
+// on publish returns the error to the publisher instead.
+package timewindow
@@ -0,0 +1,143 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package timewindow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+)
+
+var errContentType = errors.New("unsupported content type")
+
+// Config defines the window of the record times of the published messages.
+type Config struct {
+	// MaxPast is the maximum age of the record time, 0 for unlimited.
+	MaxPast time.Duration `env:"MAX_PAST" envDefault:"0s"`
+
+	// MaxFuture is the maximum amount the record time can be ahead of the
+	// server time, 0 for unlimited.
+	MaxFuture time.Duration `env:"MAX_FUTURE" envDefault:"0s"`
+
+	// ContentType is the SenML content type of the published payloads.
+	ContentType string `env:"CONTENT_TYPE" envDefault:"application/senml+json"`
+}
+
+// Enabled reports whether the record times are limited.
+func (cfg Config) Enabled() bool {
+	return cfg.MaxPast > 0 || cfg.MaxFuture > 0
+}
+
+// Validate returns an error if the content type is invalid.
+func (cfg Config) Validate() error {
+	if cfg.ContentType != senml.JSON && cfg.ContentType != senml.CBOR {
+		return errors.Wrap(errContentType, fmt.Errorf("%q, expected %q or %q", cfg.ContentType, senml.JSON, senml.CBOR))
+	}
+
+	return nil
+}
+
+func (cfg Config) times() senml.TimeConfig {
+	return senml.TimeConfig{Mode: senml.TimeModeReject, MaxPast: cfg.MaxPast, MaxFuture: cfg.MaxFuture}
+}
+
+var (
+	_ messaging.AckPublisher   = (*publisherMiddleware)(nil)
+	_ messaging.BatchPublisher = (*publisherMiddleware)(nil)
+)
+
+type publisherMiddleware struct {
+	publisher   messaging.Publisher
+	times       senml.TimeConfig
+	contentType string
+}
+
+// NewPublisher returns publisher which rejects the messages with SenML
+// records timestamped outside of the window with senml ErrTimeOutOfRange.
+// The payloads which are not SenML in the content type are published.
+func NewPublisher(cfg Config, publisher messaging.Publisher) messaging.Publisher {
+	return &publisherMiddleware{
+		publisher:   publisher,
+		times:       cfg.times(),
+		contentType: cfg.ContentType,
+	}
+}
+
+func (pm *publisherMiddleware) Publish(ctx context.Context, topic string, msg *messaging.Message) error {
+	if err := pm.check(msg); err != nil {
+		return err
+	}
+
+	return pm.publisher.Publish(ctx, topic, msg)
+}
+
+// PublishAck checks the acknowledged messages. It returns messaging
+// ErrAckNotSupported if the wrapped publisher can't wait for the
+// acknowledgment.
+func (pm *publisherMiddleware) PublishAck(ctx context.Context, topic string, msg *messaging.Message) (messaging.Receipt, error) {
+	ap, ok := pm.publisher.(messaging.AckPublisher)
+	if !ok {
+		return messaging.Receipt{}, messaging.ErrAckNotSupported
+	}
+	if err := pm.check(msg); err != nil {
+		return messaging.Receipt{}, err
+	}
+
+	return ap.PublishAck(ctx, topic, msg)
+}
+
+// PublishBatch rejects the whole batch if any of its messages is rejected.
+// It returns messaging ErrBatchNotSupported if the wrapped publisher can't
+// publish batches.
+func (pm *publisherMiddleware) PublishBatch(ctx context.Context, topic string, msgs []*messaging.Message) error {
+	bp, ok := pm.publisher.(messaging.BatchPublisher)
+	if !ok {
+		return messaging.ErrBatchNotSupported
+	}
+	for _, msg := range msgs {
+		if err := pm.check(msg); err != nil {
+			return err
+		}
+	}
+
+	return bp.PublishBatch(ctx, topic, msgs)
+}
+
+func (pm *publisherMiddleware) Close() error {
+	return pm.publisher.Close()
+}
+
+func (pm *publisherMiddleware) check(msg *messaging.Message) error {
+	return pm.times.CheckPayload(pm.contentType, msg.GetPayload(), time.Now())
+}
+
+type pubsubMiddleware struct {
+	publisherMiddleware
+	pubsub messaging.PubSub
+}
+
+// NewPubSub returns pubsub which rejects the published messages with SenML
+// records timestamped outside of the window.
+func NewPubSub(cfg Config, pubsub messaging.PubSub) messaging.PubSub {
+	return &pubsubMiddleware{
+		publisherMiddleware: publisherMiddleware{
+			publisher:   pubsub,
+			times:       cfg.times(),
+			contentType: cfg.ContentType,
+		},
+		pubsub: pubsub,
+	}
+}
+
+func (pm *pubsubMiddleware) Subscribe(ctx context.Context, cfg messaging.SubscriberConfig) error {
+	return pm.pubsub.Subscribe(ctx, cfg)
+}
+
+func (pm *pubsubMiddleware) Unsubscribe(ctx context.Context, id, topic string) error {
+	return pm.pubsub.Unsubscribe(ctx, id, topic)
+}
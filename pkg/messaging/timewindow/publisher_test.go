@@ -0,0 +1,122 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package timewindow_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/absmach/magistrala/pkg/messaging/timewindow"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const channel = "channel"
+
+var cfg = timewindow.Config{MaxPast: 24 * time.Hour, MaxFuture: time.Minute, ContentType: senml.JSON}
+
+func payload(bt time.Time) []byte {
+	return []byte(fmt.Sprintf(`[{"bn":"sensor-","bt":%d,"n":"temp","v":21}]`, bt.Unix()))
+}
+
+func TestPublish(t *testing.T) {
+	cases := []struct {
+		desc    string
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "publish message in window",
+			payload: payload(time.Now().Add(-time.Hour)),
+		},
+		{
+			desc:    "publish message without record time",
+			payload: []byte(`[{"n":"temp","v":21}]`),
+		},
+		{
+			desc:    "publish message which is not SenML",
+			payload: []byte(`{"temp":21}`),
+		},
+		{
+			desc:    "publish past message",
+			payload: payload(time.Now().AddDate(-3, 0, 0)),
+			err:     senml.ErrTimeOutOfRange,
+		},
+		{
+			desc:    "publish future message",
+			payload: payload(time.Now().AddDate(3, 0, 0)),
+			err:     senml.ErrTimeOutOfRange,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			msg := &messaging.Message{Channel: channel, Payload: tc.payload}
+			pub := new(mocks.PubSub)
+			pub.On("Publish", context.Background(), channel, msg).Return(nil)
+			tp := timewindow.NewPublisher(cfg, pub)
+
+			err := tp.Publish(context.Background(), channel, msg)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err != nil {
+				pub.AssertNotCalled(t, "Publish", context.Background(), channel, msg)
+				return
+			}
+			pub.AssertCalled(t, "Publish", context.Background(), channel, msg)
+		})
+	}
+}
+
+func TestPublishBatch(t *testing.T) {
+	msgs := []*messaging.Message{
+		{Channel: channel, Payload: payload(time.Now())},
+		{Channel: channel, Payload: payload(time.Now().AddDate(3, 0, 0))},
+	}
+	pub := new(mocks.BatchPublisher)
+	tp := timewindow.NewPublisher(cfg, pub)
+
+	bp, ok := tp.(messaging.BatchPublisher)
+	require.True(t, ok, "expected publisher to support batches")
+	err := bp.PublishBatch(context.Background(), channel, msgs)
+	assert.True(t, errors.Contains(err, senml.ErrTimeOutOfRange), fmt.Sprintf("publish batch: expected %s got %s", senml.ErrTimeOutOfRange, err))
+	pub.AssertNotCalled(t, "PublishBatch")
+}
+
+func TestConfig(t *testing.T) {
+	cases := []struct {
+		desc    string
+		cfg     timewindow.Config
+		enabled bool
+		valid   bool
+	}{
+		{
+			desc:  "disabled config",
+			cfg:   timewindow.Config{ContentType: senml.JSON},
+			valid: true,
+		},
+		{
+			desc:    "config with past limit",
+			cfg:     timewindow.Config{MaxPast: time.Hour, ContentType: senml.CBOR},
+			enabled: true,
+			valid:   true,
+		},
+		{
+			desc:    "config with invalid content type",
+			cfg:     timewindow.Config{MaxFuture: time.Minute, ContentType: "application/json"},
+			enabled: true,
+		},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.enabled, tc.cfg.Enabled(), fmt.Sprintf("%s: expected enabled %t", tc.desc, tc.enabled))
+		err := tc.cfg.Validate()
+		assert.Equal(t, tc.valid, err == nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import (
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// ErrTopicScheme indicates an unknown topic scheme.
+var ErrTopicScheme = errors.New("unknown topic scheme")
+
+// TopicScheme lays out the message broker topics of the channels.
+type TopicScheme string
+
+const (
+	// FlatTopics lays out the topics of the channels of all the domains in
+	// the same namespace, as channels.<channel_id>.<subtopic>.
+	FlatTopics TopicScheme = "flat"
+
+	// DomainTopics namespaces the topics of the channels by their domain, as
+	// channels.<domain_id>.<channel_id>.<subtopic>, so the subscriptions in
+	// a domain never match the messages of the other domains.
+	DomainTopics TopicScheme = "domain"
+)
+
+// TopicConfig contains the layout of the message broker topics.
+type TopicConfig struct {
+	Scheme string `env:"SCHEME" envDefault:"flat"`
+}
+
+// NewTopicScheme returns the topic scheme of the given config.
+func NewTopicScheme(cfg TopicConfig) (TopicScheme, error) {
+	switch ts := TopicScheme(cfg.Scheme); ts {
+	case "":
+		return FlatTopics, nil
+	case FlatTopics, DomainTopics:
+		return ts, nil
+	default:
+		return "", errors.Wrap(ErrTopicScheme, fmt.Errorf("%q", cfg.Scheme))
+	}
+}
+
+// Topic returns the topic of the channel in the domain, which the messages
+// are published to and subscribed from. The topics of the things without a
+// domain are never namespaced.
+func (ts TopicScheme) Topic(domainID, chanID string) string {
+	if ts != DomainTopics || domainID == "" {
+		return chanID
+	}
+
+	return domainID + subtopicSeparator + chanID
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	domainA = "domainA"
+	domainB = "domainB"
+	chanA   = "chanA"
+	chanB   = "chanB"
+)
+
+func TestNewTopicScheme(t *testing.T) {
+	cases := []struct {
+		desc   string
+		scheme string
+		result messaging.TopicScheme
+		err    error
+	}{
+		{
+			desc:   "empty scheme",
+			scheme: "",
+			result: messaging.FlatTopics,
+		},
+		{
+			desc:   "flat scheme",
+			scheme: "flat",
+			result: messaging.FlatTopics,
+		},
+		{
+			desc:   "domain scheme",
+			scheme: "domain",
+			result: messaging.DomainTopics,
+		},
+		{
+			desc:   "unknown scheme",
+			scheme: "tenant",
+			err:    messaging.ErrTopicScheme,
+		},
+	}
+
+	for _, tc := range cases {
+		ts, err := messaging.NewTopicScheme(messaging.TopicConfig{Scheme: tc.scheme})
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.result, ts, fmt.Sprintf("%s: expected scheme %s got %s", tc.desc, tc.result, ts))
+	}
+}
+
+func TestTopicSchemeTopic(t *testing.T) {
+	cases := []struct {
+		desc     string
+		scheme   messaging.TopicScheme
+		domainID string
+		chanID   string
+		topic    string
+	}{
+		{
+			desc:     "flat topic",
+			scheme:   messaging.FlatTopics,
+			domainID: domainA,
+			chanID:   chanA,
+			topic:    chanA,
+		},
+		{
+			desc:     "domain topic",
+			scheme:   messaging.DomainTopics,
+			domainID: domainA,
+			chanID:   chanA,
+			topic:    domainA + "." + chanA,
+		},
+		{
+			desc:     "domain topic without domain",
+			scheme:   messaging.DomainTopics,
+			domainID: "",
+			chanID:   chanA,
+			topic:    chanA,
+		},
+	}
+
+	for _, tc := range cases {
+		topic := tc.scheme.Topic(tc.domainID, tc.chanID)
+		assert.Equal(t, tc.topic, topic, fmt.Sprintf("%s: expected topic %s got %s", tc.desc, tc.topic, topic))
+	}
+}
+
+// TestTopicSchemeIsolation publishes a message to a channel of each domain,
+// and checks which of them the subscriber of domain A receives. The subjects
+// are laid out and matched the way the brokers do.
+func TestTopicSchemeIsolation(t *testing.T) {
+	cases := []struct {
+		desc     string
+		scheme   messaging.TopicScheme
+		subject  string
+		received []string
+	}{
+		{
+			desc:     "subscribe to all the channels of domain A",
+			scheme:   messaging.DomainTopics,
+			subject:  "channels." + messaging.DomainTopics.Topic(domainA, "*") + ".>",
+			received: []string{domainA},
+		},
+		{
+			desc:     "subscribe to the channel of domain A",
+			scheme:   messaging.DomainTopics,
+			subject:  "channels." + messaging.DomainTopics.Topic(domainA, chanA) + ".>",
+			received: []string{domainA},
+		},
+		{
+			desc:     "subscribe to the channel of domain A with the ID of the channel of domain B",
+			scheme:   messaging.DomainTopics,
+			subject:  "channels." + messaging.DomainTopics.Topic(domainA, chanB) + ".>",
+			received: []string{},
+		},
+		{
+			desc:     "subscribe to all the channels with flat topics",
+			scheme:   messaging.FlatTopics,
+			subject:  "channels.*.>",
+			received: []string{domainA, domainB},
+		},
+	}
+
+	published := map[string]string{domainA: chanA, domainB: chanB}
+	for _, tc := range cases {
+		received := []string{}
+		for _, domainID := range []string{domainA, domainB} {
+			subject := "channels." + tc.scheme.Topic(domainID, published[domainID]) + ".temp"
+			if matchSubject(tc.subject, subject) {
+				received = append(received, domainID)
+			}
+		}
+		assert.Equal(t, tc.received, received, fmt.Sprintf("%s: expected messages of %v got %v", tc.desc, tc.received, received))
+	}
+}
+
+// matchSubject matches the subject against the subscription, where * matches
+// a single token and > matches one or more trailing tokens.
+func matchSubject(subscription, subject string) bool {
+	subs, tokens := strings.Split(subscription, "."), strings.Split(subject, ".")
+	for i, s := range subs {
+		if s == ">" {
+			return len(tokens) > i
+		}
+		if i >= len(tokens) || (s != "*" && s != tokens[i]) {
+			return false
+		}
+	}
+
+	return len(subs) == len(tokens)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package passwords
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxScore is the score of a very strong password.
+const MaxScore = 4
+
+// Rules a password may fail.
+const (
+	// PolicyRule indicates that the password doesn't match the password policy.
+	PolicyRule = "password_policy"
+	// MinScoreRule indicates that the password score is below the minimal score.
+	MinScoreRule = "min_score"
+	// CommonRule indicates that the password contains a commonly used password.
+	CommonRule = "common_password"
+	// RepeatedRule indicates that the password contains repeated characters.
+	RepeatedRule = "repeated_characters"
+	// SequentialRule indicates that the password contains character sequences.
+	SequentialRule = "sequential_characters"
+	// VarietyRule indicates that the password uses a single character class.
+	VarietyRule = "character_variety"
+)
+
+// Entropy thresholds, in bits, for scores 1 to 4.
+var thresholds = [MaxScore]float64{28, 40, 60, 80}
+
+var commonPasswords = []string{
+	"password", "passw0rd", "qwerty", "azerty", "letmein", "welcome",
+	"admin", "dragon", "monkey", "iloveyou", "football", "baseball",
+	"master", "login", "abc123", "123456", "trustno1", "sunshine",
+	"princess", "secret", "magistrala",
+}
+
+// Strength represents the result of a password evaluation.
+type Strength struct {
+	Score  int      `json:"score"`
+	Valid  bool     `json:"valid"`
+	Failed []string `json:"failed_rules,omitempty"`
+}
+
+// Evaluator evaluates password strength.
+type Evaluator struct {
+	policy   *regexp.Regexp
+	minScore int
+}
+
+// NewEvaluator returns a password strength evaluator. Password is valid if
+// it matches the policy and its score is not below the minimal score. Zero
+// minimal score disables the score requirement.
+func NewEvaluator(policy *regexp.Regexp, minScore int) Evaluator {
+	if minScore > MaxScore {
+		minScore = MaxScore
+	}
+
+	return Evaluator{
+		policy:   policy,
+		minScore: minScore,
+	}
+}
+
+// Evaluate evaluates the password. The password is never stored.
+func (e Evaluator) Evaluate(password string) Strength {
+	var failed []string
+	if e.policy != nil && !e.policy.MatchString(password) {
+		failed = append(failed, PolicyRule)
+	}
+
+	score, rules := Score(password)
+	failed = append(failed, rules...)
+	if score < e.minScore {
+		failed = append(failed, MinScoreRule)
+	}
+
+	return Strength{
+		Score:  score,
+		Valid:  !contains(failed, PolicyRule) && !contains(failed, MinScoreRule),
+		Failed: failed,
+	}
+}
+
+// Score estimates password strength and returns score from 0 to MaxScore
+// together with the weaknesses found in the password.
+func Score(password string) (int, []string) {
+	var rules []string
+	runes := []rune(password)
+
+	// Characters that repeat or continue a sequence of the previous two
+	// characters add no entropy.
+	var repeated, sequential bool
+	effective := 0
+	for i, r := range runes {
+		if i >= 2 {
+			d1, d2 := r-runes[i-1], runes[i-1]-runes[i-2]
+			switch {
+			case d1 == 0 && d2 == 0:
+				repeated = true
+				continue
+			case d1 == d2 && (d1 == 1 || d1 == -1):
+				sequential = true
+				continue
+			}
+		}
+		effective++
+	}
+	if repeated {
+		rules = append(rules, RepeatedRule)
+	}
+	if sequential {
+		rules = append(rules, SequentialRule)
+	}
+
+	charset, classes := charsetSize(runes)
+	if len(runes) > 0 && classes < 2 {
+		rules = append(rules, VarietyRule)
+	}
+
+	bits := float64(effective) * math.Log2(float64(charset))
+	score := 0
+	for _, t := range thresholds {
+		if bits >= t {
+			score++
+		}
+	}
+
+	lower := strings.ToLower(password)
+	for _, cp := range commonPasswords {
+		if strings.Contains(lower, cp) {
+			rules = append(rules, CommonRule)
+			score = min(score, 1)
+			break
+		}
+	}
+
+	return score, rules
+}
+
+func charsetSize(runes []rune) (int, int) {
+	var lower, upper, digit, other bool
+	for _, r := range runes {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	size, classes := 0, 0
+	for _, c := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {other, 33}} {
+		if c.present {
+			size += c.size
+			classes++
+		}
+	}
+	if size == 0 {
+		size = 1
+	}
+
+	return size, classes
+}
+
+func contains(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
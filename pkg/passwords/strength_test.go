@@ -0,0 +1,79 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package passwords_test
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/passwords"
+	"github.com/stretchr/testify/assert"
+)
+
+var policy = regexp.MustCompile("^.{8,}$")
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		desc     string
+		minScore int
+		password string
+		maxScore int
+		minValue int
+		valid    bool
+		failed   []string
+	}{
+		{
+			desc:     "evaluate common password",
+			minScore: 2,
+			password: "password1",
+			maxScore: 1,
+			valid:    false,
+			failed:   []string{passwords.CommonRule, passwords.MinScoreRule},
+		},
+		{
+			desc:     "evaluate short password with repeated characters",
+			minScore: 2,
+			password: "aaaaaaa",
+			maxScore: 0,
+			valid:    false,
+			failed:   []string{passwords.PolicyRule, passwords.RepeatedRule, passwords.VarietyRule, passwords.MinScoreRule},
+		},
+		{
+			desc:     "evaluate password with sequential characters",
+			minScore: 2,
+			password: "abcdefgh",
+			maxScore: 0,
+			valid:    false,
+			failed:   []string{passwords.SequentialRule, passwords.VarietyRule, passwords.MinScoreRule},
+		},
+		{
+			desc:     "evaluate weak password without minimal score",
+			minScore: 0,
+			password: "abcdefgh",
+			maxScore: 0,
+			valid:    true,
+			failed:   []string{passwords.SequentialRule, passwords.VarietyRule},
+		},
+		{
+			desc:     "evaluate strong password",
+			minScore: 3,
+			password: "correct-Horse7-battery-Staple",
+			maxScore: passwords.MaxScore,
+			minValue: passwords.MaxScore,
+			valid:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			e := passwords.NewEvaluator(policy, tc.minScore)
+			s := e.Evaluate(tc.password)
+			assert.LessOrEqual(t, s.Score, tc.maxScore, fmt.Sprintf("%s: expected score at most %d got %d", tc.desc, tc.maxScore, s.Score))
+			assert.GreaterOrEqual(t, s.Score, tc.minValue, fmt.Sprintf("%s: expected score at least %d got %d", tc.desc, tc.minValue, s.Score))
+			assert.Equal(t, tc.valid, s.Valid, fmt.Sprintf("%s: expected valid %t got %t", tc.desc, tc.valid, s.Valid))
+			assert.ElementsMatch(t, tc.failed, s.Failed, fmt.Sprintf("%s: expected failed rules %v got %v", tc.desc, tc.failed, s.Failed))
+		})
+	}
+}
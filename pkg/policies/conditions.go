@@ -0,0 +1,69 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package policies
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	maxConditions      = 16
+	maxConditionValues = 16
+)
+
+var (
+	// ErrInvalidCondition indicates a malformed policy condition.
+	ErrInvalidCondition = errors.New("invalid policy condition")
+
+	claimPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]{0,63}$`)
+)
+
+// Conditions are the attribute conditions of the policy, mapping the claim
+// names to the values the claim may have. The policy holds only for the
+// sessions whose claims satisfy all the conditions, and a claim missing from
+// the session fails its condition.
+type Conditions map[string][]string
+
+// ParseConditions parses the condition expressions. An expression is either
+// `claim=value`, satisfied if the claim has the value, or
+// `claim=value1,value2`, satisfied if the claim has any of the values.
+// Each claim may be used in a single expression.
+func ParseConditions(exprs []string) (Conditions, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	if len(exprs) > maxConditions {
+		return nil, errors.Wrap(ErrInvalidCondition, fmt.Errorf("at most %d conditions are allowed", maxConditions))
+	}
+
+	conds := make(Conditions, len(exprs))
+	for _, expr := range exprs {
+		claim, vals, ok := strings.Cut(expr, "=")
+		claim = strings.TrimSpace(claim)
+		if !ok || !claimPattern.MatchString(claim) {
+			return nil, errors.Wrap(ErrInvalidCondition, fmt.Errorf("expression %q must have the claim=value form", expr))
+		}
+		if _, ok := conds[claim]; ok {
+			return nil, errors.Wrap(ErrInvalidCondition, fmt.Errorf("claim %s is used more than once", claim))
+		}
+		values := strings.Split(vals, ",")
+		if len(values) > maxConditionValues {
+			return nil, errors.Wrap(ErrInvalidCondition, fmt.Errorf("claim %s may have at most %d values", claim, maxConditionValues))
+		}
+		for i, val := range values {
+			val = strings.TrimSpace(val)
+			if val == "" {
+				return nil, errors.Wrap(ErrInvalidCondition, fmt.Errorf("expression %q has an empty value", expr))
+			}
+			values[i] = val
+		}
+		conds[claim] = values
+	}
+
+	return conds, nil
+}
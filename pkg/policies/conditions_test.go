@@ -0,0 +1,86 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package policies_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConditions(t *testing.T) {
+	cases := []struct {
+		desc  string
+		exprs []string
+		conds policies.Conditions
+		err   error
+	}{
+		{
+			desc:  "parse empty conditions",
+			exprs: nil,
+			conds: nil,
+			err:   nil,
+		},
+		{
+			desc:  "parse single value condition",
+			exprs: []string{"region=eu"},
+			conds: policies.Conditions{"region": {"eu"}},
+			err:   nil,
+		},
+		{
+			desc:  "parse multiple conditions",
+			exprs: []string{"region = eu", "tier=gold, silver"},
+			conds: policies.Conditions{"region": {"eu"}, "tier": {"gold", "silver"}},
+			err:   nil,
+		},
+		{
+			desc:  "parse condition without value",
+			exprs: []string{"region"},
+			err:   policies.ErrInvalidCondition,
+		},
+		{
+			desc:  "parse condition with empty value",
+			exprs: []string{"region=eu,"},
+			err:   policies.ErrInvalidCondition,
+		},
+		{
+			desc:  "parse condition with invalid claim",
+			exprs: []string{"1region=eu"},
+			err:   policies.ErrInvalidCondition,
+		},
+		{
+			desc:  "parse conditions with duplicate claim",
+			exprs: []string{"region=eu", "region=us"},
+			err:   policies.ErrInvalidCondition,
+		},
+		{
+			desc:  "parse condition with too many values",
+			exprs: []string{"region=" + strings.Repeat("eu,", 16) + "us"},
+			err:   policies.ErrInvalidCondition,
+		},
+		{
+			desc: "parse too many conditions",
+			exprs: func() []string {
+				var exprs []string
+				for i := 0; i < 17; i++ {
+					exprs = append(exprs, fmt.Sprintf("claim%d=value", i))
+				}
+				return exprs
+			}(),
+			err: policies.ErrInvalidCondition,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			conds, err := policies.ParseConditions(tc.exprs)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.conds, conds, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.conds, conds))
+		})
+	}
+}
@@ -39,6 +39,7 @@ const (
 	GroupRelation         = "group"
 	PlatformRelation      = "platform"
 	GuestRelation         = "guest"
+	SuspendedRelation     = "suspended"
 )
 
 const (
@@ -61,4 +62,24 @@ type Evaluator interface {
 	// It returns a non-nil error if the subject has no relation on
 	// the object (which simply means the operation is denied).
 	CheckPolicy(ctx context.Context, pr Policy) error
+
+	// ExplainPolicy checks if the subject has a relation on the object
+	// and returns the decision alongside the policies that produced it.
+	// Unlike CheckPolicy, a denied decision is not an error.
+	ExplainPolicy(ctx context.Context, pr Policy) (Decision, error)
+}
+
+// Decision describes the outcome of a policy check.
+type Decision struct {
+	// Allowed is true if the subject has the relation on the object.
+	Allowed bool `json:"allowed"`
+
+	// Path lists the policies through which the subject has the relation,
+	// starting from the checked object, in the object_type:object#relation
+	// form. The last policy is the one held directly by the subject. The
+	// path is empty if the decision is denied.
+	Path []string `json:"path,omitempty"`
+
+	// Reason describes how the decision was reached.
+	Reason string `json:"reason"`
 }
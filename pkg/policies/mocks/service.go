@@ -258,6 +258,34 @@ func (_m *Service) ListPermissions(ctx context.Context, pr policies.Policy, perm
 	return r0, r1
 }
 
+// ListPolicies provides a mock function with given fields: ctx, pr, nextPageToken, limit
+func (_m *Service) ListPolicies(ctx context.Context, pr policies.Policy, nextPageToken string, limit uint64) (policies.PoliciesPage, error) {
+	ret := _m.Called(ctx, pr, nextPageToken, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPolicies")
+	}
+
+	var r0 policies.PoliciesPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, policies.Policy, string, uint64) (policies.PoliciesPage, error)); ok {
+		return rf(ctx, pr, nextPageToken, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, policies.Policy, string, uint64) policies.PoliciesPage); ok {
+		r0 = rf(ctx, pr, nextPageToken, limit)
+	} else {
+		r0 = ret.Get(0).(policies.PoliciesPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, policies.Policy, string, uint64) error); ok {
+		r1 = rf(ctx, pr, nextPageToken, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListSubjects provides a mock function with given fields: ctx, pr, nextPageToken, limit
 func (_m *Service) ListSubjects(ctx context.Context, pr policies.Policy, nextPageToken string, limit uint64) (policies.PolicyPage, error) {
 	ret := _m.Called(ctx, pr, nextPageToken, limit)
@@ -286,6 +314,24 @@ func (_m *Service) ListSubjects(ctx context.Context, pr policies.Policy, nextPag
 	return r0, r1
 }
 
+// ReplacePolicies provides a mock function with given fields: ctx, removed, added
+func (_m *Service) ReplacePolicies(ctx context.Context, removed []policies.Policy, added []policies.Policy) error {
+	ret := _m.Called(ctx, removed, added)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplacePolicies")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []policies.Policy, []policies.Policy) error); ok {
+		r0 = rf(ctx, removed, added)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewService(t interface {
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package opa contains the policy evaluator that defers authorization
+// decisions to an external policy engine such as Open Policy Agent.
+package opa
@@ -0,0 +1,203 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package opa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+const (
+	defTimeout      = 500 * time.Millisecond
+	maxCacheEntries = 10000
+)
+
+var (
+	errDecision       = errors.New("failed to retrieve decision from external authorizer")
+	errUnexpectedCode = errors.New("unexpected status code from external authorizer")
+	errDenied         = errors.New("denied by external authorizer")
+)
+
+// Config defines the options that are used when connecting to the external authorizer.
+type Config struct {
+	// URL is the decision endpoint, e.g. http://opa:8181/v1/data/magistrala/allow.
+	// An empty URL disables the external authorizer.
+	URL string `env:"URL"            envDefault:""`
+
+	// Timeout bounds a single call to the external authorizer.
+	Timeout time.Duration `env:"TIMEOUT"        envDefault:"500ms"`
+
+	// CacheDuration defines how long decisions are cached.
+	CacheDuration time.Duration `env:"CACHE_DURATION" envDefault:"5s"`
+
+	// Fallback enables local policy evaluation when the external
+	// authorizer fails to respond in time.
+	Fallback bool `env:"FALLBACK"       envDefault:"true"`
+}
+
+type decisionReq struct {
+	Input decisionInput `json:"input"`
+}
+
+// decisionInput is the policy extended with the custom claims of the
+// session, so the external authorizer may use them in its decisions.
+type decisionInput struct {
+	policies.Policy
+	Claims map[string]string `json:"claims,omitempty"`
+}
+
+type decisionRes struct {
+	Result bool `json:"result"`
+}
+
+var _ policies.Evaluator = (*evaluator)(nil)
+
+type evaluator struct {
+	config   Config
+	client   *http.Client
+	fallback policies.Evaluator
+	logger   *slog.Logger
+	// cache maps the decision inputs to the decisions. The least recently
+	// used decisions are evicted once it is full.
+	cache *expirable.LRU[string, bool]
+}
+
+// NewEvaluator returns a policy evaluator which sends the policy as input to
+// the external authorizer and uses its allow or deny result. Fallback is used
+// when the external authorizer is unavailable and fallback is enabled.
+func NewEvaluator(cfg Config, fallback policies.Evaluator, logger *slog.Logger) policies.Evaluator {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defTimeout
+	}
+
+	e := &evaluator{
+		config:   cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		fallback: fallback,
+		logger:   logger,
+	}
+	if cfg.CacheDuration > 0 {
+		e.cache = expirable.NewLRU[string, bool](maxCacheEntries, nil, cfg.CacheDuration)
+	}
+
+	return e
+}
+
+func (e *evaluator) CheckPolicy(ctx context.Context, pr policies.Policy) error {
+	input, err := decisionData(ctx, pr)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	key := string(input)
+	if allowed, ok := e.cached(key); ok {
+		return result(allowed)
+	}
+
+	allowed, err := e.decide(ctx, input)
+	if err != nil {
+		if e.config.Fallback && e.fallback != nil {
+			e.logger.Warn(fmt.Sprintf("external authorizer unavailable, falling back to local evaluation: %s", err))
+			return e.fallback.CheckPolicy(ctx, pr)
+		}
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	e.store(key, allowed)
+
+	return result(allowed)
+}
+
+// ExplainPolicy bypasses the cache. The external authorizer only returns the
+// result, so the decision has no path.
+func (e *evaluator) ExplainPolicy(ctx context.Context, pr policies.Policy) (policies.Decision, error) {
+	input, err := decisionData(ctx, pr)
+	if err != nil {
+		return policies.Decision{}, errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	allowed, err := e.decide(ctx, input)
+	if err != nil {
+		if e.config.Fallback && e.fallback != nil {
+			e.logger.Warn(fmt.Sprintf("external authorizer unavailable, falling back to local evaluation: %s", err))
+			return e.fallback.ExplainPolicy(ctx, pr)
+		}
+		return policies.Decision{}, errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	if !allowed {
+		return policies.Decision{Reason: errDenied.Error()}, nil
+	}
+
+	return policies.Decision{Allowed: true, Reason: "allowed by external authorizer"}, nil
+}
+
+// decisionData returns the request of the decision of the policy with the
+// claims of the session. The request contains every field the decision
+// may depend on, so it's also the cache key of the decision.
+func decisionData(ctx context.Context, pr policies.Policy) ([]byte, error) {
+	data, err := json.Marshal(decisionReq{Input: decisionInput{Policy: pr, Claims: authn.Claims(ctx)}})
+	if err != nil {
+		return nil, errors.Wrap(errDecision, err)
+	}
+
+	return data, nil
+}
+
+func (e *evaluator) decide(ctx context.Context, data []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return false, errors.Wrap(errDecision, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return false, errors.Wrap(errDecision, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, errors.Wrap(errDecision, errUnexpectedCode)
+	}
+	var res decisionRes
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return false, errors.Wrap(errDecision, err)
+	}
+
+	return res.Result, nil
+}
+
+func (e *evaluator) cached(key string) (bool, bool) {
+	if e.cache == nil {
+		return false, false
+	}
+
+	return e.cache.Get(key)
+}
+
+func (e *evaluator) store(key string, allowed bool) {
+	if e.cache == nil {
+		return
+	}
+	e.cache.Add(key, allowed)
+}
+
+func result(allowed bool) error {
+	if !allowed {
+		return errors.Wrap(svcerr.ErrAuthorization, errDenied)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package opa_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/pkg/policies/opa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var policy = policies.Policy{
+	SubjectType: policies.UserType,
+	Subject:     "user",
+	Permission:  policies.ViewPermission,
+	ObjectType:  policies.ThingType,
+	Object:      "thing",
+}
+
+type fakeOPA struct {
+	allow bool
+	// decide, if set, decides the input instead of allow.
+	decide func(policies.Policy) bool
+	delay  time.Duration
+	calls  atomic.Int32
+	mu     sync.Mutex
+	input  policies.Policy
+	claims map[string]string
+}
+
+func (f *fakeOPA) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.calls.Add(1)
+	var req struct {
+		Input struct {
+			policies.Policy
+			Claims map[string]string `json:"claims"`
+		} `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.input = req.Input.Policy
+	f.claims = req.Input.Claims
+	f.mu.Unlock()
+	time.Sleep(f.delay)
+	allow := f.allow
+	if f.decide != nil {
+		allow = f.decide(req.Input.Policy)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"result": allow})
+}
+
+func TestCheckPolicy(t *testing.T) {
+	cases := []struct {
+		desc        string
+		allow       bool
+		delay       time.Duration
+		fallback    bool
+		fallbackErr error
+		err         error
+	}{
+		{
+			desc:  "check policy allowed by external authorizer",
+			allow: true,
+			err:   nil,
+		},
+		{
+			desc:  "check policy denied by external authorizer",
+			allow: false,
+			err:   svcerr.ErrAuthorization,
+		},
+		{
+			desc:        "check policy with timeout and fallback allowing",
+			allow:       false,
+			delay:       200 * time.Millisecond,
+			fallback:    true,
+			fallbackErr: nil,
+			err:         nil,
+		},
+		{
+			desc:        "check policy with timeout and fallback denying",
+			allow:       true,
+			delay:       200 * time.Millisecond,
+			fallback:    true,
+			fallbackErr: svcerr.ErrAuthorization,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:  "check policy with timeout and fallback disabled",
+			allow: true,
+			delay: 200 * time.Millisecond,
+			err:   svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			fake := &fakeOPA{allow: tc.allow, delay: tc.delay}
+			ts := httptest.NewServer(fake)
+			defer ts.Close()
+
+			local := new(mocks.Evaluator)
+			localCall := local.On("CheckPolicy", mock.Anything, policy).Return(tc.fallbackErr)
+			defer localCall.Unset()
+
+			cfg := opa.Config{
+				URL:           ts.URL,
+				Timeout:       50 * time.Millisecond,
+				CacheDuration: time.Minute,
+				Fallback:      tc.fallback,
+			}
+			evaluator := opa.NewEvaluator(cfg, local, mglog.NewMock())
+			err := evaluator.CheckPolicy(context.Background(), policy)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.delay == 0 {
+				fake.mu.Lock()
+				assert.Equal(t, policy, fake.input, fmt.Sprintf("%s: expected input %v got %v\n", tc.desc, policy, fake.input))
+				fake.mu.Unlock()
+				local.AssertNotCalled(t, "CheckPolicy", mock.Anything, mock.Anything)
+			}
+			if tc.fallback {
+				local.AssertCalled(t, "CheckPolicy", mock.Anything, policy)
+			}
+		})
+	}
+}
+
+func TestCheckPolicyCache(t *testing.T) {
+	fake := &fakeOPA{allow: true}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	cfg := opa.Config{
+		URL:           ts.URL,
+		Timeout:       time.Second,
+		CacheDuration: 100 * time.Millisecond,
+	}
+	evaluator := opa.NewEvaluator(cfg, nil, mglog.NewMock())
+
+	for i := 0; i < 3; i++ {
+		err := evaluator.CheckPolicy(context.Background(), policy)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+	assert.Equal(t, int32(1), fake.calls.Load(), "expected cached decision to be used")
+
+	time.Sleep(150 * time.Millisecond)
+	err := evaluator.CheckPolicy(context.Background(), policy)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, int32(2), fake.calls.Load(), "expected expired decision to be refreshed")
+}
+
+func TestCheckPolicyCacheKey(t *testing.T) {
+	// The external authorizer allows only the policies of the users kind on
+	// the things kind, so the policies differing only in the kinds get
+	// different decisions.
+	fake := &fakeOPA{decide: func(pr policies.Policy) bool {
+		return pr.SubjectKind == policies.UsersKind && pr.ObjectKind == policies.ThingsKind
+	}}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	cfg := opa.Config{
+		URL:           ts.URL,
+		Timeout:       time.Second,
+		CacheDuration: time.Minute,
+	}
+	evaluator := opa.NewEvaluator(cfg, nil, mglog.NewMock())
+
+	allowed := policy
+	allowed.SubjectKind = policies.UsersKind
+	allowed.ObjectKind = policies.ThingsKind
+	otherSubject := allowed
+	otherSubject.SubjectKind = policies.TokenKind
+	otherObject := allowed
+	otherObject.ObjectKind = policies.ChannelsKind
+	conditioned := allowed
+	conditioned.Conditions = policies.Conditions{"region": {"eu"}}
+
+	cases := []struct {
+		desc   string
+		policy policies.Policy
+		err    error
+	}{
+		{
+			desc:   "check allowed policy",
+			policy: allowed,
+		},
+		{
+			desc:   "check policy with different subject kind",
+			policy: otherSubject,
+			err:    svcerr.ErrAuthorization,
+		},
+		{
+			desc:   "check policy with different object kind",
+			policy: otherObject,
+			err:    svcerr.ErrAuthorization,
+		},
+		{
+			desc:   "check allowed policy again",
+			policy: allowed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := evaluator.CheckPolicy(context.Background(), tc.policy)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		})
+	}
+	assert.Equal(t, int32(3), fake.calls.Load(), "expected decisions of different policies not to be shared")
+
+	err := evaluator.CheckPolicy(context.Background(), conditioned)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, int32(4), fake.calls.Load(), "expected decision of policy with different conditions not to be cached")
+}
+
+func TestCheckPolicyClaims(t *testing.T) {
+	fake := &fakeOPA{allow: true}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	cfg := opa.Config{
+		URL:           ts.URL,
+		Timeout:       time.Second,
+		CacheDuration: time.Minute,
+	}
+	evaluator := opa.NewEvaluator(cfg, nil, mglog.NewMock())
+
+	claims := map[string]string{"region": "eu"}
+	err := evaluator.CheckPolicy(authn.WithClaims(context.Background(), claims), policy)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	fake.mu.Lock()
+	assert.Equal(t, claims, fake.claims, fmt.Sprintf("expected claims %v got %v\n", claims, fake.claims))
+	fake.mu.Unlock()
+
+	err = evaluator.CheckPolicy(context.Background(), policy)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, int32(2), fake.calls.Load(), "expected decision with different claims not to be cached")
+	fake.mu.Lock()
+	assert.Nil(t, fake.claims, fmt.Sprintf("expected no claims got %v\n", fake.claims))
+	fake.mu.Unlock()
+}
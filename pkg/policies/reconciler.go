@@ -0,0 +1,231 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package policies
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/time/rate"
+)
+
+const wildcard = "*"
+
+var errReconcile = errors.New("failed to reconcile policies")
+
+// ExistingFunc returns the subset of the given entity IDs which still exist.
+type ExistingFunc func(ctx context.Context, ids []string) ([]string, error)
+
+// ReconcilerConfig contains the orphaned policies reconciler parameters.
+type ReconcilerConfig struct {
+	Interval  time.Duration `env:"INTERVAL"   envDefault:"24h"`
+	DryRun    bool          `env:"DRY_RUN"    envDefault:"true"`
+	BatchSize uint64        `env:"BATCH_SIZE" envDefault:"100"`
+	Rate      float64       `env:"RATE"       envDefault:"10"`
+}
+
+// ReconcileReport contains the result of a single reconciliation.
+type ReconcileReport struct {
+	Scanned uint64
+	Orphans []Policy
+	Removed []Policy
+}
+
+// Reconciler removes policies whose subject or object refers to an entity
+// which no longer exists.
+//
+// Entities are created and deleted alongside their policies, so a policy may
+// briefly refer to an entity which is not stored yet. To run safely alongside
+// live traffic, the orphaned policy is removed only if it is found orphaned
+// by two consecutive reconciliations. Policies are removed one by one, so
+// policies added in the meantime are never removed.
+type Reconciler struct {
+	policies   Service
+	entityType string
+	subjectOf  []string
+	existing   ExistingFunc
+	cfg        ReconcilerConfig
+	limiter    *rate.Limiter
+	found      metrics.Counter
+	removed    metrics.Counter
+	logger     *slog.Logger
+	candidates map[string]bool
+}
+
+// NewReconciler returns the reconciler of the policies which refer to the
+// entities of the given type. The entity is looked up as the object of the
+// policies and as the subject of the policies on the subjectOf object types.
+// Reads and removals are limited to cfg.Rate requests per second.
+func NewReconciler(policyService Service, entityType string, subjectOf []string, existing ExistingFunc, cfg ReconcilerConfig, found, removed metrics.Counter, logger *slog.Logger) *Reconciler {
+	limit := rate.Inf
+	if cfg.Rate > 0 {
+		limit = rate.Limit(cfg.Rate)
+	}
+
+	return &Reconciler{
+		policies:   policyService,
+		entityType: entityType,
+		subjectOf:  subjectOf,
+		existing:   existing,
+		cfg:        cfg,
+		limiter:    rate.NewLimiter(limit, 1),
+		found:      found,
+		removed:    removed,
+		logger:     logger,
+		candidates: make(map[string]bool),
+	}
+}
+
+// Run reconciles the policies periodically until the context is canceled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := r.Reconcile(ctx)
+			if err != nil {
+				r.logger.Error("failed to reconcile policies", slog.String("entity_type", r.entityType), slog.Any("error", err))
+				continue
+			}
+			r.logger.Info("policies reconciled",
+				slog.String("entity_type", r.entityType),
+				slog.Bool("dry_run", r.cfg.DryRun),
+				slog.Uint64("scanned", report.Scanned),
+				slog.Int("orphans", len(report.Orphans)),
+				slog.Int("removed", len(report.Removed)),
+			)
+		}
+	}
+}
+
+// Reconcile scans the policies once and removes the orphaned ones found by
+// the previous reconciliation as well. In dry run mode, orphaned policies are
+// only reported.
+func (r *Reconciler) Reconcile(ctx context.Context) (ReconcileReport, error) {
+	filters := []Policy{{ObjectType: r.entityType}}
+	for _, objectType := range r.subjectOf {
+		filters = append(filters, Policy{ObjectType: objectType, SubjectType: r.entityType})
+	}
+
+	report := ReconcileReport{}
+	candidates := make(map[string]bool)
+	var confirmed []Policy
+	for _, filter := range filters {
+		nextPageToken := ""
+		for {
+			if err := r.limiter.Wait(ctx); err != nil {
+				return report, errors.Wrap(errReconcile, err)
+			}
+			page, err := r.policies.ListPolicies(ctx, filter, nextPageToken, r.cfg.BatchSize)
+			if err != nil {
+				return report, errors.Wrap(errReconcile, err)
+			}
+			report.Scanned += uint64(len(page.Policies))
+
+			orphans, err := r.orphans(ctx, page.Policies)
+			if err != nil {
+				return report, errors.Wrap(errReconcile, err)
+			}
+			for _, pr := range orphans {
+				key := pr.String()
+				candidates[key] = true
+				if r.candidates[key] {
+					confirmed = append(confirmed, pr)
+				}
+				r.logger.Info("orphaned policy found", slog.Bool("dry_run", r.cfg.DryRun), slog.String("policy", key))
+			}
+			report.Orphans = append(report.Orphans, orphans...)
+			r.add(r.found, len(orphans))
+
+			if page.NextPageToken == "" || len(page.Policies) == 0 {
+				break
+			}
+			nextPageToken = page.NextPageToken
+		}
+	}
+	r.candidates = candidates
+
+	if r.cfg.DryRun {
+		return report, nil
+	}
+	// Policies are removed once the scan is done, so that the removals do
+	// not shift the pages being read.
+	for _, pr := range confirmed {
+		if err := r.limiter.Wait(ctx); err != nil {
+			return report, errors.Wrap(errReconcile, err)
+		}
+		if err := r.policies.DeletePolicies(ctx, []Policy{pr}); err != nil {
+			return report, errors.Wrap(errReconcile, err)
+		}
+		delete(r.candidates, pr.String())
+		report.Removed = append(report.Removed, pr)
+		r.add(r.removed, 1)
+	}
+
+	return report, nil
+}
+
+// orphans returns the policies which refer to entities that no longer exist.
+func (r *Reconciler) orphans(ctx context.Context, prs []Policy) ([]Policy, error) {
+	ids := []string{}
+	seen := make(map[string]bool)
+	for _, pr := range prs {
+		for _, id := range r.entityIDs(pr) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	existing, err := r.existing(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		exists[id] = true
+	}
+
+	var orphans []Policy
+	for _, pr := range prs {
+		for _, id := range r.entityIDs(pr) {
+			if !exists[id] {
+				orphans = append(orphans, pr)
+				break
+			}
+		}
+	}
+
+	return orphans, nil
+}
+
+func (r *Reconciler) entityIDs(pr Policy) []string {
+	var ids []string
+	if pr.ObjectType == r.entityType && pr.Object != "" && pr.Object != wildcard {
+		ids = append(ids, pr.Object)
+	}
+	if pr.SubjectType == r.entityType && pr.Subject != "" && pr.Subject != wildcard {
+		ids = append(ids, pr.Subject)
+	}
+
+	return ids
+}
+
+func (r *Reconciler) add(counter metrics.Counter, n int) {
+	if counter == nil || n == 0 {
+		return
+	}
+	counter.With("entity_type", r.entityType).Add(float64(n))
+}
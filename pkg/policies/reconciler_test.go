@@ -0,0 +1,239 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package policies_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const batchSize = 2
+
+var logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type fakeCounter struct {
+	value float64
+}
+
+func (c *fakeCounter) With(labelValues ...string) metrics.Counter {
+	return c
+}
+
+func (c *fakeCounter) Add(delta float64) {
+	c.value += delta
+}
+
+// seed returns the stored policies and the orphaned ones among them.
+func seed(t *testing.T, users []string, deleted []string) ([]policies.Policy, []policies.Policy) {
+	domainID := testsutil.GenerateUUID(t)
+	var stored, orphans []policies.Policy
+	for i, id := range append(users, deleted...) {
+		prs := []policies.Policy{
+			{
+				Subject:     domainID + "_" + id,
+				SubjectType: policies.UserType,
+				Relation:    policies.AdministratorRelation,
+				Object:      testsutil.GenerateUUID(t),
+				ObjectType:  policies.ThingType,
+			},
+			{
+				Subject:     id,
+				SubjectType: policies.UserType,
+				Relation:    policies.MemberRelation,
+				Object:      domainID,
+				ObjectType:  policies.DomainType,
+			},
+		}
+		stored = append(stored, prs...)
+		if i >= len(users) {
+			orphans = append(orphans, prs...)
+		}
+	}
+
+	return stored, orphans
+}
+
+// listPolicies pages the stored policies matching the filter object and
+// subject types.
+func listPolicies(stored []policies.Policy, err error) func(context.Context, policies.Policy, string, uint64) (policies.PoliciesPage, error) {
+	return func(_ context.Context, pr policies.Policy, nextPageToken string, limit uint64) (policies.PoliciesPage, error) {
+		if err != nil {
+			return policies.PoliciesPage{}, err
+		}
+		var prs []policies.Policy
+		for _, s := range stored {
+			if s.ObjectType == pr.ObjectType && (pr.SubjectType == "" || s.SubjectType == pr.SubjectType) {
+				prs = append(prs, s)
+			}
+		}
+		offset, _ := strconv.Atoi(nextPageToken)
+		if offset >= len(prs) {
+			return policies.PoliciesPage{}, nil
+		}
+		end := offset + int(limit)
+		page := policies.PoliciesPage{}
+		if end < len(prs) {
+			page.NextPageToken = strconv.Itoa(end)
+		} else {
+			end = len(prs)
+		}
+		page.Policies = prs[offset:end]
+
+		return page, nil
+	}
+}
+
+func existing(ids []string, err error) policies.ExistingFunc {
+	return func(_ context.Context, refs []string) ([]string, error) {
+		if err != nil {
+			return nil, err
+		}
+		var res []string
+		for _, ref := range refs {
+			for _, id := range ids {
+				if ref == id || len(ref) > len(id) && ref[len(ref)-len(id)-1:] == "_"+id {
+					res = append(res, ref)
+				}
+			}
+		}
+
+		return res, nil
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	users := []string{testsutil.GenerateUUID(t), testsutil.GenerateUUID(t)}
+	deleted := []string{testsutil.GenerateUUID(t), testsutil.GenerateUUID(t)}
+	stored, orphans := seed(t, users, deleted)
+	subjectOf := []string{policies.DomainType, policies.ThingType}
+
+	cases := []struct {
+		desc        string
+		dryRun      bool
+		runs        int
+		existing    []string
+		lastExisted []string
+		listErr     error
+		existingErr error
+		deleteErr   error
+		orphans     []policies.Policy
+		removed     []policies.Policy
+		found       int
+		err         error
+	}{
+		{
+			desc:     "reconcile orphaned policies",
+			runs:     2,
+			existing: users,
+			orphans:  orphans,
+			removed:  orphans,
+			found:    2 * len(orphans),
+		},
+		{
+			desc:     "reconcile orphaned policies once",
+			runs:     1,
+			existing: users,
+			orphans:  orphans,
+			removed:  nil,
+			found:    len(orphans),
+		},
+		{
+			desc:     "reconcile orphaned policies in dry run",
+			dryRun:   true,
+			runs:     2,
+			existing: users,
+			orphans:  orphans,
+			removed:  nil,
+			found:    2 * len(orphans),
+		},
+		{
+			desc:     "reconcile without orphaned policies",
+			runs:     2,
+			existing: append(users, deleted...),
+			orphans:  nil,
+			removed:  nil,
+		},
+		{
+			desc:        "reconcile policies of entities created since the last run",
+			runs:        2,
+			existing:    append(users, deleted...),
+			lastExisted: users,
+			orphans:     nil,
+			removed:     nil,
+			found:       len(orphans),
+		},
+		{
+			desc:     "reconcile with failed to list policies",
+			runs:     1,
+			existing: users,
+			listErr:  svcerr.ErrViewEntity,
+			err:      svcerr.ErrViewEntity,
+		},
+		{
+			desc:        "reconcile with failed to look up entities",
+			runs:        1,
+			existing:    users,
+			existingErr: svcerr.ErrViewEntity,
+			err:         svcerr.ErrViewEntity,
+		},
+		{
+			desc:      "reconcile with failed to remove policies",
+			runs:      2,
+			existing:  users,
+			deleteErr: svcerr.ErrRemoveEntity,
+			orphans:   orphans,
+			err:       svcerr.ErrRemoveEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := new(mocks.Service)
+			found, removed := &fakeCounter{}, &fakeCounter{}
+			svc.On("ListPolicies", mock.Anything, mock.Anything, mock.Anything, uint64(batchSize)).Return(listPolicies(stored, tc.listErr))
+			svc.On("DeletePolicies", mock.Anything, mock.Anything).Return(tc.deleteErr)
+
+			lookup := existing(tc.existing, tc.existingErr)
+			if tc.lastExisted != nil {
+				lookup = existing(tc.lastExisted, nil)
+			}
+			cfg := policies.ReconcilerConfig{DryRun: tc.dryRun, BatchSize: batchSize}
+			r := policies.NewReconciler(svc, policies.UserType, subjectOf, func(ctx context.Context, ids []string) ([]string, error) {
+				return lookup(ctx, ids)
+			}, cfg, found, removed, logger)
+
+			var report policies.ReconcileReport
+			var err error
+			for i := 0; i < tc.runs; i++ {
+				report, err = r.Reconcile(context.Background())
+				lookup = existing(tc.existing, tc.existingErr)
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err != nil {
+				return
+			}
+			assert.ElementsMatch(t, tc.orphans, report.Orphans, fmt.Sprintf("%s: expected orphans %v got %v", tc.desc, tc.orphans, report.Orphans))
+			assert.ElementsMatch(t, tc.removed, report.Removed, fmt.Sprintf("%s: expected removed %v got %v", tc.desc, tc.removed, report.Removed))
+			assert.Equal(t, float64(tc.found), found.value, fmt.Sprintf("%s: expected %d found got %f", tc.desc, tc.found, found.value))
+			assert.Equal(t, float64(len(tc.removed)), removed.value, fmt.Sprintf("%s: expected %d removed got %f", tc.desc, len(tc.removed), removed.value))
+			for _, pr := range tc.removed {
+				svc.AssertCalled(t, "DeletePolicies", mock.Anything, []policies.Policy{pr})
+			}
+			svc.AssertNumberOfCalls(t, "DeletePolicies", len(tc.removed))
+		})
+	}
+}
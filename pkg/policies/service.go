@@ -43,6 +43,10 @@ type Policy struct {
 	// Permission contains the permission. Supported permissions are admin, delete, edit, share, view,
 	// membership, create, admin_only, edit_only, view_only, membership_only, ext_admin, ext_edit, ext_view.
 	Permission string `json:"permission,omitempty"`
+
+	// Conditions contains the attribute conditions of the policy, evaluated
+	// against the custom claims of the session, as set by authn.WithClaims.
+	Conditions Conditions `json:"conditions,omitempty"`
 }
 
 func (pr Policy) String() string {
@@ -58,6 +62,11 @@ type PolicyPage struct {
 	NextPageToken string
 }
 
+type PoliciesPage struct {
+	Policies      []Policy
+	NextPageToken string
+}
+
 type Permissions []string
 
 // PolicyService facilitates the communication to authorization
@@ -81,6 +90,11 @@ type Service interface {
 	// only allowed to use as an admin.
 	DeletePolicies(ctx context.Context, prs []Policy) error
 
+	// ReplacePolicies deletes the removed policies and adds the added ones
+	// in a single atomic write, so either all of the changes are applied or
+	// none. The write fails if any of the removed policies no longer exists.
+	ReplacePolicies(ctx context.Context, removed, added []Policy) error
+
 	// ListObjects lists policies based on the given Policy structure.
 	ListObjects(ctx context.Context, pr Policy, nextPageToken string, limit uint64) (PolicyPage, error)
 
@@ -101,4 +115,8 @@ type Service interface {
 
 	// ListPermissions lists permission betweeen given subject and object .
 	ListPermissions(ctx context.Context, pr Policy, permissionsFilter []string) (Permissions, error)
+
+	// ListPolicies lists stored policies matching the given Policy structure.
+	// The object type is required, all the other fields are optional filters.
+	ListPolicies(ctx context.Context, pr Policy, nextPageToken string, limit uint64) (PoliciesPage, error)
 }
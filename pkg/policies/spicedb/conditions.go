@@ -0,0 +1,78 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package spicedb
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/policies"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The conditions of the policies are stored as the context of the
+// claims_match caveat of the relationships, and the claims of the session are
+// sent as the context of the checks. The caveat is defined in the schema.
+const (
+	claimsCaveat    = "claims_match"
+	conditionsParam = "conditions"
+	claimsParam     = "claims"
+)
+
+// caveat returns the caveat of the relationship holding only if the claims
+// satisfy the conditions, or nil if there are no conditions.
+func caveat(conds policies.Conditions) (*v1.ContextualizedCaveat, error) {
+	if len(conds) == 0 {
+		return nil, nil
+	}
+	vals := make(map[string]interface{}, len(conds))
+	for claim, values := range conds {
+		list := make([]interface{}, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		vals[claim] = list
+	}
+	caveatCtx, err := structpb.NewStruct(map[string]interface{}{conditionsParam: vals})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ContextualizedCaveat{CaveatName: claimsCaveat, Context: caveatCtx}, nil
+}
+
+// claimsContext returns the context of the checks carrying the claims of the
+// session. The claims are always sent, so the caveats of the relationships
+// are fully evaluated and a missing claim denies the check rather than
+// leaving it conditional.
+func claimsContext(ctx context.Context) *structpb.Struct {
+	vals := map[string]*structpb.Value{}
+	for claim, val := range authn.Claims(ctx) {
+		vals[claim] = structpb.NewStringValue(val)
+	}
+
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		claimsParam: structpb.NewStructValue(&structpb.Struct{Fields: vals}),
+	}}
+}
+
+// conditions returns the conditions stored in the caveat of the relationship.
+func conditions(c *v1.ContextualizedCaveat) policies.Conditions {
+	if c.GetCaveatName() != claimsCaveat {
+		return nil
+	}
+	fields := c.GetContext().GetFields()[conditionsParam].GetStructValue().GetFields()
+	if len(fields) == 0 {
+		return nil
+	}
+	conds := make(policies.Conditions, len(fields))
+	for claim, val := range fields {
+		for _, v := range val.GetListValue().GetValues() {
+			conds[claim] = append(conds[claim], v.GetStringValue())
+		}
+	}
+
+	return conds
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package spicedb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/policies"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaveat(t *testing.T) {
+	cases := []struct {
+		desc  string
+		conds policies.Conditions
+	}{
+		{
+			desc:  "caveat without conditions",
+			conds: nil,
+		},
+		{
+			desc:  "caveat with conditions",
+			conds: policies.Conditions{"region": {"eu"}, "tier": {"gold", "silver"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cav, err := caveat(tc.conds)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			if len(tc.conds) == 0 {
+				assert.Nil(t, cav, fmt.Sprintf("%s: expected no caveat got %v", tc.desc, cav))
+				return
+			}
+			assert.Equal(t, claimsCaveat, cav.GetCaveatName(), fmt.Sprintf("%s: expected caveat %s got %s", tc.desc, claimsCaveat, cav.GetCaveatName()))
+			conds := conditions(cav)
+			assert.Equal(t, tc.conds, conds, fmt.Sprintf("%s: expected conditions %v got %v", tc.desc, tc.conds, conds))
+		})
+	}
+}
+
+func TestConditionsOtherCaveat(t *testing.T) {
+	conds := conditions(&v1.ContextualizedCaveat{CaveatName: "other"})
+	assert.Nil(t, conds, fmt.Sprintf("expected no conditions got %v", conds))
+}
+
+func TestClaimsContext(t *testing.T) {
+	cases := []struct {
+		desc   string
+		claims map[string]string
+	}{
+		{
+			desc:   "claims context without claims",
+			claims: nil,
+		},
+		{
+			desc:   "claims context with claims",
+			claims: map[string]string{"region": "eu", "tier": "gold"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			caveatCtx := claimsContext(authn.WithClaims(context.Background(), tc.claims))
+			claims, ok := caveatCtx.GetFields()[claimsParam]
+			assert.True(t, ok, fmt.Sprintf("%s: expected claims in the context", tc.desc))
+			fields := claims.GetStructValue().GetFields()
+			assert.Len(t, fields, len(tc.claims), fmt.Sprintf("%s: expected %d claims got %d", tc.desc, len(tc.claims), len(fields)))
+			for name, val := range tc.claims {
+				assert.Equal(t, val, fields[name].GetStringValue(), fmt.Sprintf("%s: expected claim %s to be %s", tc.desc, name, val))
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ package spicedb
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/absmach/magistrala/pkg/errors"
@@ -29,7 +30,33 @@ func NewPolicyEvaluator(client *authzed.ClientWithExperimental, logger *slog.Log
 }
 
 func (pe *policyEvaluator) CheckPolicy(ctx context.Context, pr policies.Policy) error {
-	checkReq := v1.CheckPermissionRequest{
+	resp, err := pe.permissionClient.CheckPermission(ctx, checkRequest(ctx, pr))
+	if err != nil {
+		return handleSpicedbError(err)
+	}
+	if resp.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
+		return nil
+	}
+	if reason, ok := v1.CheckPermissionResponse_Permissionship_name[int32(resp.Permissionship)]; ok {
+		return errors.Wrap(svcerr.ErrAuthorization, errors.New(reason))
+	}
+	return svcerr.ErrAuthorization
+}
+
+func (pe *policyEvaluator) ExplainPolicy(ctx context.Context, pr policies.Policy) (policies.Decision, error) {
+	checkReq := checkRequest(ctx, pr)
+	checkReq.WithTracing = true
+
+	resp, err := pe.permissionClient.CheckPermission(ctx, checkReq)
+	if err != nil {
+		return policies.Decision{}, handleSpicedbError(err)
+	}
+
+	return explain(resp), nil
+}
+
+func checkRequest(ctx context.Context, pr policies.Policy) *v1.CheckPermissionRequest {
+	return &v1.CheckPermissionRequest{
 		// FullyConsistent means little caching will be available, which means performance will suffer.
 		// Only use if a ZedToken is not available or absolutely latest information is required.
 		// If we want to avoid FullyConsistent and to improve the performance of  spicedb, then we need to cache the ZEDTOKEN whenever RELATIONS is created or updated.
@@ -48,17 +75,54 @@ func (pe *policyEvaluator) CheckPolicy(ctx context.Context, pr policies.Policy)
 		Resource:   &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
 		Permission: pr.Permission,
 		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+		Context:    claimsContext(ctx),
 	}
+}
 
-	resp, err := pe.permissionClient.CheckPermission(ctx, &checkReq)
-	if err != nil {
-		return handleSpicedbError(err)
+// explain builds the decision from the check debug trace by following the
+// sub-problems which granted the permission down to the relation held by
+// the subject.
+func explain(resp *v1.CheckPermissionResponse) policies.Decision {
+	switch resp.GetPermissionship() {
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		return policies.Decision{Reason: "matching policy depends on missing caveat context"}
+	default:
+		return policies.Decision{Reason: "no direct or inherited policy matches"}
 	}
-	if resp.Permissionship == v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION {
-		return nil
+
+	var path []string
+	inherited := false
+	root := resp.GetDebugTrace().GetCheck()
+	for trace := root; trace != nil; trace = grantingTrace(trace) {
+		res := trace.GetResource()
+		path = append(path, fmt.Sprintf("%s:%s#%s", res.GetObjectType(), res.GetObjectId(), trace.GetPermission()))
+		if res.GetObjectType() != root.GetResource().GetObjectType() || res.GetObjectId() != root.GetResource().GetObjectId() {
+			inherited = true
+		}
 	}
-	if reason, ok := v1.CheckPermissionResponse_Permissionship_name[int32(resp.Permissionship)]; ok {
-		return errors.Wrap(svcerr.ErrAuthorization, errors.New(reason))
+
+	d := policies.Decision{Allowed: true, Path: path}
+	switch {
+	case len(path) == 0:
+		d.Reason = "permission granted, no trace available"
+	case inherited:
+		d.Reason = "inherited policy matches"
+	default:
+		d.Reason = "direct policy matches"
 	}
-	return svcerr.ErrAuthorization
+
+	return d
+}
+
+// grantingTrace returns the first sub-problem of the trace which granted the
+// permission, or nil if there is none.
+func grantingTrace(trace *v1.CheckDebugTrace) *v1.CheckDebugTrace {
+	for _, t := range trace.GetSubProblems().GetTraces() {
+		if t.GetResult() == v1.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION {
+			return t
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,87 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package spicedb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/policies"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func trace(objectType, id, permission string, result v1.CheckDebugTrace_Permissionship, sub ...*v1.CheckDebugTrace) *v1.CheckDebugTrace {
+	t := &v1.CheckDebugTrace{
+		Resource:   &v1.ObjectReference{ObjectType: objectType, ObjectId: id},
+		Permission: permission,
+		Result:     result,
+	}
+	if len(sub) > 0 {
+		t.Resolution = &v1.CheckDebugTrace_SubProblems_{SubProblems: &v1.CheckDebugTrace_SubProblems{Traces: sub}}
+	}
+
+	return t
+}
+
+func TestExplain(t *testing.T) {
+	has := v1.CheckDebugTrace_PERMISSIONSHIP_HAS_PERMISSION
+	none := v1.CheckDebugTrace_PERMISSIONSHIP_NO_PERMISSION
+
+	cases := []struct {
+		desc     string
+		resp     *v1.CheckPermissionResponse
+		decision policies.Decision
+	}{
+		{
+			desc: "explain direct policy",
+			resp: &v1.CheckPermissionResponse{
+				Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+				DebugTrace: &v1.DebugInformation{Check: trace("group", "g1", "view", has,
+					trace("group", "g1", "editor", none),
+					trace("group", "g1", "administrator", has),
+				)},
+			},
+			decision: policies.Decision{
+				Allowed: true,
+				Path:    []string{"group:g1#view", "group:g1#administrator"},
+				Reason:  "direct policy matches",
+			},
+		},
+		{
+			desc: "explain inherited policy",
+			resp: &v1.CheckPermissionResponse{
+				Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+				DebugTrace: &v1.DebugInformation{Check: trace("thing", "t1", "view", has,
+					trace("thing", "t1", "administrator", none),
+					trace("domain", "d1", "admin", has,
+						trace("domain", "d1", "administrator", has),
+					),
+				)},
+			},
+			decision: policies.Decision{
+				Allowed: true,
+				Path:    []string{"thing:t1#view", "domain:d1#admin", "domain:d1#administrator"},
+				Reason:  "inherited policy matches",
+			},
+		},
+		{
+			desc: "explain denied decision",
+			resp: &v1.CheckPermissionResponse{
+				Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION,
+				DebugTrace: &v1.DebugInformation{Check: trace("group", "g1", "view", none,
+					trace("group", "g1", "administrator", none),
+				)},
+			},
+			decision: policies.Decision{Reason: "no direct or inherited policy matches"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			d := explain(tc.resp)
+			assert.Equal(t, tc.decision, d, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.decision, d))
+		})
+	}
+}
@@ -27,6 +27,7 @@ var (
 	errAddPolicies      = errors.New("failed to add policies")
 	errRetrievePolicies = errors.New("failed to retrieve policies")
 	errRemovePolicies   = errors.New("failed to remove the policies")
+	errReplacePolicies  = errors.New("failed to replace the policies")
 	errNoPolicies       = errors.New("no policies provided")
 	errInternal         = errors.New("spicedb internal error")
 	errPlatform         = errors.New("invalid platform id")
@@ -88,14 +89,19 @@ func (ps *policyService) AddPolicy(ctx context.Context, pr policies.Policy) erro
 	if err != nil {
 		return err
 	}
+	cav, err := caveat(pr.Conditions)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrInvalidPolicy, err)
+	}
 
 	updates := []*v1.RelationshipUpdate{
 		{
 			Operation: v1.RelationshipUpdate_OPERATION_CREATE,
 			Relationship: &v1.Relationship{
-				Resource: &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
-				Relation: pr.Relation,
-				Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+				Resource:       &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
+				Relation:       pr.Relation,
+				Subject:        &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+				OptionalCaveat: cav,
 			},
 		},
 	}
@@ -119,12 +125,17 @@ func (ps *policyService) AddPolicies(ctx context.Context, prs []policies.Policy)
 			return err
 		}
 		preconds = append(preconds, precond...)
+		cav, err := caveat(pr.Conditions)
+		if err != nil {
+			return errors.Wrap(svcerr.ErrInvalidPolicy, err)
+		}
 		updates = append(updates, &v1.RelationshipUpdate{
 			Operation: v1.RelationshipUpdate_OPERATION_CREATE,
 			Relationship: &v1.Relationship{
-				Resource: &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
-				Relation: pr.Relation,
-				Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+				Resource:       &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
+				Relation:       pr.Relation,
+				Subject:        &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+				OptionalCaveat: cav,
 			},
 		})
 	}
@@ -198,6 +209,69 @@ func (ps *policyService) DeletePolicies(ctx context.Context, prs []policies.Poli
 	return nil
 }
 
+func (ps *policyService) ReplacePolicies(ctx context.Context, removed, added []policies.Policy) error {
+	updates := []*v1.RelationshipUpdate{}
+	var preconds []*v1.Precondition
+	for _, pr := range removed {
+		if err := ps.policyValidation(pr); err != nil {
+			return errors.Wrap(svcerr.ErrInvalidPolicy, err)
+		}
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation: v1.RelationshipUpdate_OPERATION_DELETE,
+			Relationship: &v1.Relationship{
+				Resource: &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
+				Relation: pr.Relation,
+				Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+			},
+		})
+		// The removed policies are read before the write, so the write is
+		// rejected if any of them was changed in the meantime.
+		filter := &v1.RelationshipFilter{
+			ResourceType:       pr.ObjectType,
+			OptionalResourceId: pr.Object,
+			OptionalRelation:   pr.Relation,
+			OptionalSubjectFilter: &v1.SubjectFilter{
+				SubjectType:       pr.SubjectType,
+				OptionalSubjectId: pr.Subject,
+			},
+		}
+		if pr.SubjectRelation != "" {
+			filter.OptionalSubjectFilter.OptionalRelation = &v1.SubjectFilter_RelationFilter{Relation: pr.SubjectRelation}
+		}
+		preconds = append(preconds, &v1.Precondition{
+			Operation: v1.Precondition_OPERATION_MUST_MATCH,
+			Filter:    filter,
+		})
+	}
+	for _, pr := range added {
+		if err := ps.policyValidation(pr); err != nil {
+			return errors.Wrap(svcerr.ErrInvalidPolicy, err)
+		}
+		cav, err := caveat(pr.Conditions)
+		if err != nil {
+			return errors.Wrap(svcerr.ErrInvalidPolicy, err)
+		}
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: &v1.Relationship{
+				Resource:       &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
+				Relation:       pr.Relation,
+				Subject:        &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+				OptionalCaveat: cav,
+			},
+		})
+	}
+	if len(updates) == 0 {
+		return errors.Wrap(errors.ErrMalformedEntity, errNoPolicies)
+	}
+	_, err := ps.permissionClient.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates, OptionalPreconditions: preconds})
+	if err != nil {
+		return errors.Wrap(errReplacePolicies, handleSpicedbError(err))
+	}
+
+	return nil
+}
+
 func (ps *policyService) ListObjects(ctx context.Context, pr policies.Policy, nextPageToken string, limit uint64) (policies.PolicyPage, error) {
 	if limit <= 0 {
 		limit = 100
@@ -317,6 +391,51 @@ func (ps *policyService) ListPermissions(ctx context.Context, pr policies.Policy
 	return pers, nil
 }
 
+func (ps *policyService) ListPolicies(ctx context.Context, pr policies.Policy, nextPageToken string, limit uint64) (policies.PoliciesPage, error) {
+	req := &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_MinimizeLatency{
+				MinimizeLatency: true,
+			},
+		},
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:       pr.ObjectType,
+			OptionalResourceId: pr.Object,
+			OptionalRelation:   pr.Relation,
+		},
+		OptionalLimit: uint32(limit),
+	}
+	if pr.SubjectType != "" {
+		req.RelationshipFilter.OptionalSubjectFilter = &v1.SubjectFilter{
+			SubjectType:       pr.SubjectType,
+			OptionalSubjectId: pr.Subject,
+		}
+	}
+	if nextPageToken != "" {
+		req.OptionalCursor = &v1.Cursor{Token: nextPageToken}
+	}
+
+	stream, err := ps.permissionClient.ReadRelationships(ctx, req)
+	if err != nil {
+		return policies.PoliciesPage{}, errors.Wrap(errRetrievePolicies, handleSpicedbError(err))
+	}
+	page := policies.PoliciesPage{}
+	for {
+		resp, err := stream.Recv()
+		switch {
+		case errors.Contains(err, io.EOF):
+			return page, nil
+		case err != nil:
+			return policies.PoliciesPage{}, errors.Wrap(errRetrievePolicies, handleSpicedbError(err))
+		default:
+			page.Policies = append(page.Policies, relationshipToPolicy(resp.GetRelationship()))
+			if resp.GetAfterResultCursor() != nil && uint64(len(page.Policies)) == limit {
+				page.NextPageToken = resp.GetAfterResultCursor().GetToken()
+			}
+		}
+	}
+}
+
 func (ps *policyService) policyValidation(pr policies.Policy) error {
 	if pr.ObjectType == policies.PlatformType && pr.Object != policies.MagistralaObject {
 		return errPlatform
@@ -591,6 +710,7 @@ func (ps *policyService) checkPolicy(ctx context.Context, pr policies.Policy) er
 		Resource:   &v1.ObjectReference{ObjectType: pr.ObjectType, ObjectId: pr.Object},
 		Permission: pr.Permission,
 		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+		Context:    claimsContext(ctx),
 	}
 
 	resp, err := ps.permissionClient.CheckPermission(ctx, &checkReq)
@@ -617,6 +737,7 @@ func (ps *policyService) retrieveObjects(ctx context.Context, pr policies.Policy
 		Permission:         pr.Permission,
 		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
 		OptionalLimit:      uint32(limit),
+		Context:            claimsContext(ctx),
 	}
 	if nextPageToken != "" {
 		resourceReq.OptionalCursor = &v1.Cursor{Token: nextPageToken}
@@ -656,6 +777,7 @@ func (ps *policyService) retrieveAllObjects(ctx context.Context, pr policies.Pol
 		ResourceObjectType: pr.ObjectType,
 		Permission:         pr.Permission,
 		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: pr.SubjectType, ObjectId: pr.Subject}, OptionalRelation: pr.SubjectRelation},
+		Context:            claimsContext(ctx),
 	}
 	stream, err := ps.permissionClient.LookupResources(ctx, resourceReq)
 	if err != nil {
@@ -669,7 +791,7 @@ func (ps *policyService) retrieveAllObjects(ctx context.Context, pr policies.Pol
 			return tuples, nil
 		case err != nil:
 			return tuples, errors.Wrap(errRetrievePolicies, handleSpicedbError(err))
-		default:
+		case resp.GetPermissionship() == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION:
 			tuples = append(tuples, policies.Policy{Object: resp.ResourceObjectId})
 		}
 	}
@@ -751,6 +873,7 @@ func (ps *policyService) retrievePermissions(ctx context.Context, pr policies.Po
 				},
 				OptionalRelation: pr.SubjectRelation,
 			},
+			Context: claimsContext(ctx),
 		})
 	}
 	resp, err := ps.client.PermissionsServiceClient.CheckBulkPermissions(ctx, &v1.CheckBulkPermissionsRequest{
@@ -894,6 +1017,10 @@ func channelThingPreCondition(pr policies.Policy) ([]*v1.Precondition, error) {
 func objectsToAuthPolicies(objects []*v1.LookupResourcesResponse) []policies.Policy {
 	var policyList []policies.Policy
 	for _, obj := range objects {
+		// Objects depending on the conditions the claims don't satisfy are not listed.
+		if obj.GetPermissionship() != v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION {
+			continue
+		}
 		policyList = append(policyList, policies.Policy{
 			Object: obj.GetResourceObjectId(),
 		})
@@ -911,6 +1038,18 @@ func subjectsToAuthPolicies(subjects []*v1.LookupSubjectsResponse) []policies.Po
 	return policyList
 }
 
+func relationshipToPolicy(rel *v1.Relationship) policies.Policy {
+	return policies.Policy{
+		Subject:         rel.GetSubject().GetObject().GetObjectId(),
+		SubjectType:     rel.GetSubject().GetObject().GetObjectType(),
+		SubjectRelation: rel.GetSubject().GetOptionalRelation(),
+		Object:          rel.GetResource().GetObjectId(),
+		ObjectType:      rel.GetResource().GetObjectType(),
+		Relation:        rel.GetRelation(),
+		Conditions:      conditions(rel.GetOptionalCaveat()),
+	}
+}
+
 func handleSpicedbError(err error) error {
 	if st, ok := status.FromError(err); ok {
 		return convertGRPCStatusToError(st)
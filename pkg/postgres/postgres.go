@@ -13,8 +13,9 @@ import (
 )
 
 var (
-	errConnect   = errors.New("failed to connect to postgresql server")
-	errMigration = errors.New("failed to apply migrations")
+	errConnect       = errors.New("failed to connect to postgresql server")
+	errMigration     = errors.New("failed to apply migrations")
+	errSchemaVersion = errors.New("failed to retrieve schema version")
 )
 
 type Config struct {
@@ -63,3 +64,28 @@ func Connect(cfg Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// SchemaVersion returns the ID of the most recently applied migration as
+// recorded by the migration runner.
+//
+// For example:
+//
+//	version, err := postgres.SchemaVersion(db)
+func SchemaVersion(db *sqlx.DB) (string, error) {
+	records, err := migrate.GetMigrationRecords(db.DB, "postgres")
+	if err != nil {
+		return "", errors.Wrap(errSchemaVersion, err)
+	}
+
+	var latest *migrate.MigrationRecord
+	for _, record := range records {
+		if latest == nil || !record.AppliedAt.Before(latest.AppliedAt) {
+			latest = record
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+
+	return latest.Id, nil
+}
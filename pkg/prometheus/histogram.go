@@ -0,0 +1,57 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheus
+
+import (
+	"context"
+
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDLabel is the exemplar label holding the ID of the trace.
+const TraceIDLabel = "trace_id"
+
+// Histogram is a Prometheus histogram whose observations carry the ID of the
+// trace they are made in as an exemplar, linking the buckets to the traces.
+// Exemplars are exposed in the OpenMetrics format only.
+type Histogram struct {
+	hv  *stdprometheus.HistogramVec
+	lvs stdprometheus.Labels
+}
+
+// NewHistogramFrom returns the histogram registered with the default
+// registerer, with the label names given.
+func NewHistogramFrom(opts stdprometheus.HistogramOpts, labelNames []string) *Histogram {
+	hv := stdprometheus.NewHistogramVec(opts, labelNames)
+	stdprometheus.MustRegister(hv)
+
+	return &Histogram{hv: hv, lvs: stdprometheus.Labels{}}
+}
+
+// With returns the histogram with the label name and value pairs added.
+func (h *Histogram) With(labelValues ...string) *Histogram {
+	lvs := make(stdprometheus.Labels, len(h.lvs)+len(labelValues)/2)
+	for k, v := range h.lvs {
+		lvs[k] = v
+	}
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		lvs[labelValues[i]] = labelValues[i+1]
+	}
+
+	return &Histogram{hv: h.hv, lvs: lvs}
+}
+
+// Observe adds the value to the histogram. If the context carries a sampled
+// trace, its ID is attached to the observation as an exemplar, since only the
+// sampled traces can be looked up.
+func (h *Histogram) Observe(ctx context.Context, value float64) {
+	obs := h.hv.With(h.lvs)
+	sc := trace.SpanContextFromContext(ctx)
+	if eo, ok := obs.(stdprometheus.ExemplarObserver); ok && sc.IsValid() && sc.IsSampled() {
+		eo.ObserveWithExemplar(value, stdprometheus.Labels{TraceIDLabel: sc.TraceID().String()})
+		return
+	}
+	obs.Observe(value)
+}
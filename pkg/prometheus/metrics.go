@@ -29,3 +29,82 @@ func MakeMetrics(namespace, subsystem string) (*kitprometheus.Counter, *kitprome
 
 	return counter, latency
 }
+
+// MakeReconcilerMetrics returns the counters of the orphaned policies found
+// and removed by the policy reconciler.
+//
+//	found, removed := metrics.MakeReconcilerMetrics("demo-service")
+func MakeReconcilerMetrics(namespace string) (*kitprometheus.Counter, *kitprometheus.Counter) {
+	found := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "policy_reconciler",
+		Name:      "orphans_found",
+		Help:      "Number of orphaned policies found.",
+	}, []string{"entity_type"})
+	removed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "policy_reconciler",
+		Name:      "orphans_removed",
+		Help:      "Number of orphaned policies removed.",
+	}, []string{"entity_type"})
+
+	return found, removed
+}
+
+// MakeChannelMetrics returns the counters of the messages and the payload
+// bytes published per channel.
+//
+//	messages, bytes := metrics.MakeChannelMetrics("demo-service")
+func MakeChannelMetrics(namespace string) (*kitprometheus.Counter, *kitprometheus.Counter) {
+	messages := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "channel",
+		Name:      "published_messages",
+		Help:      "Number of messages published per channel.",
+	}, []string{"channel"})
+	bytes := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "channel",
+		Name:      "published_bytes",
+		Help:      "Number of payload bytes published per channel.",
+	}, []string{"channel"})
+
+	return messages, bytes
+}
+
+// MakeQuotaMetrics returns the counter of the operations rejected for
+// exceeding the domain quota of an entity kind.
+//
+//	exceeded := metrics.MakeQuotaMetrics("demo-service")
+func MakeQuotaMetrics(namespace string) *kitprometheus.Counter {
+	return kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "quota",
+		Name:      "exceeded",
+		Help:      "Number of operations rejected for exceeding the domain quota.",
+	}, []string{"kind"})
+}
+
+// MakeTracedMetrics returns the request counter and the request latency
+// histogram, whose buckets carry the IDs of the sampled traces of the requests
+// as exemplars. Unlike the summary of MakeMetrics, the latency is a histogram,
+// since only the histograms carry exemplars.
+//
+//	counter, latency := metrics.MakeTracedMetrics("demo-service", "api")
+func MakeTracedMetrics(namespace, subsystem string) (*kitprometheus.Counter, *Histogram) {
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "request_count",
+		Help:      "Number of requests received.",
+	}, []string{"method"})
+	latency := NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "request_latency_microseconds",
+		Help:      "Total duration of requests in microseconds.",
+		Buckets:   stdprometheus.DefBuckets,
+	}, []string{"method"})
+
+	return counter, latency
+}
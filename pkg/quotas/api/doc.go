@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package api contains the HTTP API of the domain quotas, served by the
+// services owning the quota entities.
+package api
@@ -0,0 +1,55 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/quotas"
+	"github.com/go-kit/kit/endpoint"
+)
+
+func viewQuotaEndpoint(svc quotas.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewQuotaReq)
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		q, err := svc.View(ctx, session, req.kind)
+		if err != nil {
+			return nil, err
+		}
+
+		return quotaRes{Quota: q}, nil
+	}
+}
+
+func updateQuotaEndpoint(svc quotas.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateQuotaReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		q, err := svc.Update(ctx, session, req.kind, *req.Limit)
+		if err != nil {
+			return nil, err
+		}
+
+		return quotaRes{Quota: q}, nil
+	}
+}
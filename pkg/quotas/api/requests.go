@@ -0,0 +1,35 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"math"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+var (
+	errMissingLimit = errors.New("missing quota limit")
+	errLimitRange   = errors.New("quota limit is out of range")
+)
+
+type viewQuotaReq struct {
+	kind string
+}
+
+type updateQuotaReq struct {
+	kind  string
+	Limit *uint64 `json:"limit"`
+}
+
+func (req updateQuotaReq) validate() error {
+	if req.Limit == nil {
+		return errors.Wrap(errors.ErrMalformedEntity, errMissingLimit)
+	}
+	if *req.Limit > math.MaxInt64 {
+		return errors.Wrap(errors.ErrMalformedEntity, errLimitRange)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/quotas"
+)
+
+var _ magistrala.Response = (*quotaRes)(nil)
+
+type quotaRes struct {
+	quotas.Quota
+}
+
+func (res quotaRes) Code() int {
+	return http.StatusOK
+}
+
+func (res quotaRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res quotaRes) Empty() bool {
+	return false
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/quotas"
+	"github.com/go-chi/chi/v5"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// MakeHandler returns a HTTP handler for the domain quotas API endpoints.
+func MakeHandler(svc quotas.Service, authn mgauthn.Authentication, mux *chi.Mux, logger *slog.Logger) *chi.Mux {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, api.EncodeError)),
+	}
+
+	mux.Group(func(r chi.Router) {
+		r.Use(api.AuthenticateMiddleware(authn, true))
+
+		r.Get("/{domainID}/quotas/{kind}", otelhttp.NewHandler(kithttp.NewServer(
+			viewQuotaEndpoint(svc),
+			decodeViewQuota,
+			api.EncodeResponse,
+			opts...,
+		), "view_quota").ServeHTTP)
+
+		r.Put("/{domainID}/quotas/{kind}", otelhttp.NewHandler(kithttp.NewServer(
+			updateQuotaEndpoint(svc),
+			decodeUpdateQuota,
+			api.EncodeResponse,
+			opts...,
+		), "update_quota").ServeHTTP)
+	})
+
+	return mux
+}
+
+func decodeViewQuota(_ context.Context, r *http.Request) (interface{}, error) {
+	req := viewQuotaReq{
+		kind: chi.URLParam(r, "kind"),
+	}
+
+	return req, nil
+}
+
+func decodeUpdateQuota(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := updateQuotaReq{
+		kind: chi.URLParam(r, "kind"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(errors.ErrMalformedEntity, err))
+	}
+
+	return req, nil
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package quotas contains the domain quotas service, limiting the number of
+// groups, things and channels a domain may own.
+package quotas
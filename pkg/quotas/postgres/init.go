@@ -0,0 +1,34 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	_ "github.com/jackc/pgx/v5/stdlib" // required for SQL access
+	migrate "github.com/rubenv/sql-migrate"
+)
+
+func Migration() *migrate.MemoryMigrationSource {
+	return &migrate.MemoryMigrationSource{
+		Migrations: []*migrate.Migration{
+			{
+				Id: "quotas_01",
+				// QUOTA_LIMIT holds the quota overrides only, the domains
+				// without an override use the default quotas.
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS domain_quotas (
+						domain_id	VARCHAR(36) NOT NULL,
+						kind		VARCHAR(36) NOT NULL,
+						quota_limit	BIGINT NOT NULL,
+						updated_at	TIMESTAMP NOT NULL,
+						updated_by	VARCHAR(254) NOT NULL,
+						PRIMARY KEY (domain_id, kind)
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS domain_quotas`,
+				},
+			},
+		},
+	}
+}
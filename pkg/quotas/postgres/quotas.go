@@ -0,0 +1,110 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	"github.com/absmach/magistrala/pkg/postgres"
+	"github.com/absmach/magistrala/pkg/quotas"
+)
+
+var _ quotas.Repository = (*quotaRepository)(nil)
+
+type quotaRepository struct {
+	db postgres.Database
+}
+
+// New instantiates a PostgreSQL implementation of quotas repository.
+func New(db postgres.Database) quotas.Repository {
+	return &quotaRepository{
+		db: db,
+	}
+}
+
+func (repo quotaRepository) Save(ctx context.Context, q quotas.Quota) error {
+	query := `INSERT INTO domain_quotas (domain_id, kind, quota_limit, updated_at, updated_by)
+		VALUES (:domain_id, :kind, :quota_limit, :updated_at, :updated_by)
+		ON CONFLICT (domain_id, kind) DO UPDATE SET
+			quota_limit = EXCLUDED.quota_limit, updated_at = EXCLUDED.updated_at, updated_by = EXCLUDED.updated_by`
+
+	if _, err := repo.db.NamedExecContext(ctx, query, toDBQuota(q)); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo quotaRepository) Retrieve(ctx context.Context, domainID, kind string) (quotas.Quota, error) {
+	query := `SELECT domain_id, kind, quota_limit, updated_at, updated_by FROM domain_quotas
+		WHERE domain_id = :domain_id AND kind = :kind`
+
+	rows, err := repo.db.NamedQueryContext(ctx, query, dbQuota{DomainID: domainID, Kind: kind})
+	if err != nil {
+		return quotas.Quota{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return quotas.Quota{}, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		return quotas.Quota{}, repoerr.ErrNotFound
+	}
+	dbq := dbQuota{}
+	if err := rows.StructScan(&dbq); err != nil {
+		return quotas.Quota{}, errors.Wrap(repoerr.ErrViewEntity, err)
+	}
+
+	return toQuota(dbq), nil
+}
+
+// Lock takes a transaction-level advisory lock on the domain quota, which is
+// released when the transaction ends. The transaction is rolled back if the
+// context is canceled before unlocking.
+func (repo quotaRepository) Lock(ctx context.Context, domainID, kind string) (func() error, error) {
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(repoerr.ErrViewEntity, err)
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, domainID+":"+kind); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			err = errors.Wrap(err, errRollback)
+		}
+		return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+
+	return tx.Commit, nil
+}
+
+type dbQuota struct {
+	DomainID  string    `db:"domain_id"`
+	Kind      string    `db:"kind"`
+	Limit     int64     `db:"quota_limit"`
+	UpdatedAt time.Time `db:"updated_at"`
+	UpdatedBy string    `db:"updated_by"`
+}
+
+func toDBQuota(q quotas.Quota) dbQuota {
+	return dbQuota{
+		DomainID:  q.DomainID,
+		Kind:      q.Kind,
+		Limit:     int64(q.Limit),
+		UpdatedAt: q.UpdatedAt.UTC(),
+		UpdatedBy: q.UpdatedBy,
+	}
+}
+
+func toQuota(dbq dbQuota) quotas.Quota {
+	return quotas.Quota{
+		DomainID:  dbq.DomainID,
+		Kind:      dbq.Kind,
+		Limit:     uint64(dbq.Limit),
+		UpdatedAt: dbq.UpdatedAt,
+		UpdatedBy: dbq.UpdatedBy,
+	}
+}
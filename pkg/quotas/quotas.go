@@ -0,0 +1,105 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package quotas
+
+import (
+	"context"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// Entity kinds limited by the quotas.
+const (
+	GroupsKind   = "groups"
+	ThingsKind   = "things"
+	ChannelsKind = "channels"
+)
+
+var (
+	// ErrQuotaExceeded indicates that the domain reached the quota of the entity kind.
+	ErrQuotaExceeded = errors.New("domain quota exceeded")
+
+	// ErrUnknownKind indicates that there is no quota for the entity kind.
+	ErrUnknownKind = errors.New("unknown quota kind")
+)
+
+// Config defines the default quotas of the domains. Zero quota means the
+// number of entities is not limited.
+type Config struct {
+	Groups   uint64 `env:"GROUPS"   envDefault:"0"`
+	Things   uint64 `env:"THINGS"   envDefault:"0"`
+	Channels uint64 `env:"CHANNELS" envDefault:"0"`
+}
+
+// Default returns the default quota of the entity kind.
+func (cfg Config) Default(kind string) uint64 {
+	switch kind {
+	case GroupsKind:
+		return cfg.Groups
+	case ThingsKind:
+		return cfg.Things
+	case ChannelsKind:
+		return cfg.Channels
+	default:
+		return 0
+	}
+}
+
+// Quota represents the number of entities of a kind a domain may own.
+type Quota struct {
+	DomainID string `json:"domain_id"`
+	Kind     string `json:"kind"`
+
+	// Limit is the maximum number of entities. Zero means unlimited.
+	Limit uint64 `json:"limit"`
+
+	// Used is the number of entities the domain owns.
+	Used uint64 `json:"used"`
+
+	// Override is true if the quota overrides the default quota.
+	Override bool `json:"override"`
+
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+}
+
+// Counter returns the number of entities of a kind the domain owns.
+type Counter func(ctx context.Context, domainID string) (uint64, error)
+
+// Service specifies an API for enforcing, viewing and updating domain quotas.
+//
+//go:generate mockery --name Service --output=./mocks --filename service.go --quiet --note "Copyright (c) Abstract Machines"
+type Service interface {
+	// Reserve returns ErrQuotaExceeded if creating n entities of the kind
+	// would exceed the quota of the domain. Otherwise, the quota stays
+	// locked until the returned release function is called, so the
+	// concurrent creations wait for the entities to be created.
+	Reserve(ctx context.Context, domainID, kind string, n uint64) (release func(), err error)
+
+	// View retrieves the quota of the entity kind of the session domain.
+	// Only domain and platform administrators can view the quota.
+	View(ctx context.Context, session authn.Session, kind string) (Quota, error)
+
+	// Update overrides the quota of the entity kind of the session domain.
+	// Only platform administrators can update the quota.
+	Update(ctx context.Context, session authn.Session, kind string, limit uint64) (Quota, error)
+}
+
+// Repository specifies a quota overrides persistence API.
+//
+//go:generate mockery --name Repository --output=./mocks --filename repository.go --quiet --note "Copyright (c) Abstract Machines"
+type Repository interface {
+	// Save creates or replaces the quota override of the domain.
+	Save(ctx context.Context, q Quota) error
+
+	// Retrieve retrieves the quota override of the entity kind of the domain.
+	// It returns repository ErrNotFound if the domain has no override.
+	Retrieve(ctx context.Context, domainID, kind string) (Quota, error)
+
+	// Lock locks the quota of the entity kind of the domain until the
+	// returned unlock function is called. Locking a locked quota waits.
+	Lock(ctx context.Context, domainID, kind string) (unlock func() error, err error)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package quotas
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgauthz "github.com/absmach/magistrala/pkg/authz"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/go-kit/kit/metrics"
+)
+
+var _ Service = (*service)(nil)
+
+type service struct {
+	repo     Repository
+	authz    mgauthz.Authorization
+	config   Config
+	counters map[string]Counter
+	exceeded metrics.Counter
+}
+
+// NewService returns the quotas service enforcing the quotas of the entity
+// kinds counted by the given counters. Rejected creations are counted by
+// the exceeded counter, labeled by the entity kind.
+func NewService(repo Repository, authz mgauthz.Authorization, cfg Config, counters map[string]Counter, exceeded metrics.Counter) Service {
+	return &service{
+		repo:     repo,
+		authz:    authz,
+		config:   cfg,
+		counters: counters,
+		exceeded: exceeded,
+	}
+}
+
+// Reserve locks the quota before counting the entities, and the quota is
+// unlocked once the entities are created, so the concurrent creations can't
+// exceed it. The unlimited quotas aren't locked.
+func (svc *service) Reserve(ctx context.Context, domainID, kind string, n uint64) (func(), error) {
+	q, err := svc.limit(ctx, domainID, kind)
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit == 0 {
+		return func() {}, nil
+	}
+	unlock, err := svc.repo.Lock(ctx, domainID, kind)
+	if err != nil {
+		return nil, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	release := func() {
+		// The lock is released even if the unlock fails, when the
+		// transaction holding it ends.
+		_ = unlock()
+	}
+	if q.Used, err = svc.counters[kind](ctx, domainID); err != nil {
+		release()
+		return nil, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	if q.Used+n > q.Limit {
+		release()
+		svc.exceeded.With("kind", kind).Add(1)
+		return nil, errors.Wrap(ErrQuotaExceeded, fmt.Errorf("%d of %d %s used, %d requested", q.Used, q.Limit, kind, n))
+	}
+
+	return release, nil
+}
+
+func (svc *service) View(ctx context.Context, session authn.Session, kind string) (Quota, error) {
+	if err := svc.authorize(ctx, session.UserID, policies.PlatformType, policies.MagistralaObject); err != nil {
+		if err := svc.authorize(ctx, session.DomainUserID, policies.DomainType, session.DomainID); err != nil {
+			return Quota{}, err
+		}
+	}
+
+	return svc.quota(ctx, session.DomainID, kind)
+}
+
+func (svc *service) Update(ctx context.Context, session authn.Session, kind string, limit uint64) (Quota, error) {
+	if err := svc.authorize(ctx, session.UserID, policies.PlatformType, policies.MagistralaObject); err != nil {
+		return Quota{}, err
+	}
+	if _, ok := svc.counters[kind]; !ok {
+		return Quota{}, errors.Wrap(svcerr.ErrMalformedEntity, ErrUnknownKind)
+	}
+
+	q := Quota{
+		DomainID:  session.DomainID,
+		Kind:      kind,
+		Limit:     limit,
+		Override:  true,
+		UpdatedAt: time.Now(),
+		UpdatedBy: session.UserID,
+	}
+	if err := svc.repo.Save(ctx, q); err != nil {
+		return Quota{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	return svc.quota(ctx, session.DomainID, kind)
+}
+
+// quota returns the quota of the domain alongside the number of used entities.
+func (svc *service) quota(ctx context.Context, domainID, kind string) (Quota, error) {
+	q, err := svc.limit(ctx, domainID, kind)
+	if err != nil {
+		return Quota{}, err
+	}
+	if q.Used, err = svc.counters[kind](ctx, domainID); err != nil {
+		return Quota{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	return q, nil
+}
+
+// limit returns the quota of the domain, falling back to the default quota
+// if the domain has no override.
+func (svc *service) limit(ctx context.Context, domainID, kind string) (Quota, error) {
+	if _, ok := svc.counters[kind]; !ok {
+		return Quota{}, errors.Wrap(svcerr.ErrMalformedEntity, ErrUnknownKind)
+	}
+
+	q, err := svc.repo.Retrieve(ctx, domainID, kind)
+	switch {
+	case err == nil:
+		q.Override = true
+	case errors.Contains(err, repoerr.ErrNotFound):
+		q = Quota{
+			DomainID: domainID,
+			Kind:     kind,
+			Limit:    svc.config.Default(kind),
+		}
+	default:
+		return Quota{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	return q, nil
+}
+
+func (svc *service) authorize(ctx context.Context, subject, objectType, object string) error {
+	return svc.authz.Authorize(ctx, mgauthz.PolicyReq{
+		SubjectType: policies.UserType,
+		SubjectKind: policies.UsersKind,
+		Subject:     subject,
+		Permission:  policies.AdminPermission,
+		ObjectType:  objectType,
+		Object:      object,
+	})
+}
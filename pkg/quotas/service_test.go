@@ -0,0 +1,269 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package quotas_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgauthz "github.com/absmach/magistrala/pkg/authz"
+	authzmocks "github.com/absmach/magistrala/pkg/authz/mocks"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/quotas"
+	"github.com/absmach/magistrala/pkg/quotas/mocks"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const domainID = "domain"
+
+var (
+	session = authn.Session{UserID: "user", DomainID: domainID, DomainUserID: domainID + "_user"}
+	cfg     = quotas.Config{Things: 2}
+)
+
+// exceededCounter counts the rejected creations per entity kind.
+type exceededCounter struct {
+	counts map[string]float64
+	kind   string
+}
+
+func (c *exceededCounter) With(labelValues ...string) metrics.Counter {
+	return &exceededCounter{counts: c.counts, kind: labelValues[1]}
+}
+
+func (c *exceededCounter) Add(delta float64) {
+	c.counts[c.kind] += delta
+}
+
+func newService(t *testing.T, used uint64) (quotas.Service, *mocks.Repository, *authzmocks.Authorization, *exceededCounter) {
+	repo := mocks.NewRepository(t)
+	authz := new(authzmocks.Authorization)
+	exceeded := &exceededCounter{counts: make(map[string]float64)}
+	counters := map[string]quotas.Counter{
+		quotas.ThingsKind: func(_ context.Context, _ string) (uint64, error) {
+			return used, nil
+		},
+	}
+
+	return quotas.NewService(repo, authz, cfg, counters, exceeded), repo, authz, exceeded
+}
+
+func TestReserve(t *testing.T) {
+	cases := []struct {
+		desc     string
+		kind     string
+		used     uint64
+		n        uint64
+		override quotas.Quota
+		repoErr  error
+		lockErr  error
+		locked   bool
+		err      error
+	}{
+		{
+			desc:    "create within default quota",
+			kind:    quotas.ThingsKind,
+			used:    1,
+			n:       1,
+			repoErr: repoerr.ErrNotFound,
+			locked:  true,
+		},
+		{
+			desc:    "create past default quota",
+			kind:    quotas.ThingsKind,
+			used:    2,
+			n:       1,
+			repoErr: repoerr.ErrNotFound,
+			locked:  true,
+			err:     quotas.ErrQuotaExceeded,
+		},
+		{
+			desc:    "create batch past default quota",
+			kind:    quotas.ThingsKind,
+			used:    1,
+			n:       2,
+			repoErr: repoerr.ErrNotFound,
+			locked:  true,
+			err:     quotas.ErrQuotaExceeded,
+		},
+		{
+			desc:     "create within raised quota",
+			kind:     quotas.ThingsKind,
+			used:     2,
+			n:        1,
+			override: quotas.Quota{DomainID: domainID, Kind: quotas.ThingsKind, Limit: 3},
+			locked:   true,
+		},
+		{
+			desc:     "create with failed quota lock",
+			kind:     quotas.ThingsKind,
+			used:     2,
+			n:        1,
+			override: quotas.Quota{DomainID: domainID, Kind: quotas.ThingsKind, Limit: 3},
+			lockErr:  repoerr.ErrViewEntity,
+			err:      svcerr.ErrViewEntity,
+		},
+		{
+			desc:     "create with unlimited quota",
+			kind:     quotas.ThingsKind,
+			used:     100,
+			n:        1,
+			override: quotas.Quota{DomainID: domainID, Kind: quotas.ThingsKind, Limit: 0},
+		},
+		{
+			desc: "create unknown kind",
+			kind: quotas.GroupsKind,
+			err:  quotas.ErrUnknownKind,
+		},
+		{
+			desc:    "create with failed override retrieval",
+			kind:    quotas.ThingsKind,
+			repoErr: repoerr.ErrViewEntity,
+			err:     svcerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, repo, _, exceeded := newService(t, tc.used)
+			if tc.kind == quotas.ThingsKind {
+				repo.On("Retrieve", context.Background(), domainID, tc.kind).Return(tc.override, tc.repoErr)
+			}
+			unlocked := false
+			if tc.locked || tc.lockErr != nil {
+				unlock := func() error {
+					unlocked = true
+					return nil
+				}
+				repo.On("Lock", context.Background(), domainID, tc.kind).Return(unlock, tc.lockErr)
+			}
+			release, err := svc.Reserve(context.Background(), domainID, tc.kind, tc.n)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("expected error %s, got %s", tc.err, err))
+			if err == nil {
+				assert.False(t, unlocked, "expected the quota to stay locked until released")
+				release()
+			}
+			assert.Equal(t, tc.locked, unlocked, fmt.Sprintf("expected the quota unlocked %t, got %t", tc.locked, unlocked))
+			want := 0.0
+			if errors.Contains(err, quotas.ErrQuotaExceeded) {
+				want = 1
+			}
+			assert.Equal(t, want, exceeded.counts[tc.kind])
+		})
+	}
+}
+
+func TestView(t *testing.T) {
+	cases := []struct {
+		desc          string
+		platformAdmin bool
+		domainAdmin   bool
+		quota         quotas.Quota
+		err           error
+	}{
+		{
+			desc:        "view as domain admin",
+			domainAdmin: true,
+			quota:       quotas.Quota{DomainID: domainID, Kind: quotas.ThingsKind, Limit: 2, Used: 1},
+		},
+		{
+			desc:          "view as platform admin",
+			platformAdmin: true,
+			quota:         quotas.Quota{DomainID: domainID, Kind: quotas.ThingsKind, Limit: 2, Used: 1},
+		},
+		{
+			desc: "view as domain member",
+			err:  svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, repo, authz, _ := newService(t, 1)
+			authz.On("Authorize", context.Background(), mock.MatchedBy(func(pr mgauthz.PolicyReq) bool {
+				return pr.ObjectType == policies.PlatformType
+			})).Return(authzErr(tc.platformAdmin))
+			authz.On("Authorize", context.Background(), mock.MatchedBy(func(pr mgauthz.PolicyReq) bool {
+				return pr.ObjectType == policies.DomainType && pr.Object == domainID
+			})).Return(authzErr(tc.domainAdmin))
+			if tc.err == nil {
+				repo.On("Retrieve", context.Background(), domainID, quotas.ThingsKind).Return(quotas.Quota{}, repoerr.ErrNotFound)
+			}
+			q, err := svc.View(context.Background(), session, quotas.ThingsKind)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("expected error %s, got %s", tc.err, err))
+			assert.Equal(t, tc.quota, q)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cases := []struct {
+		desc          string
+		platformAdmin bool
+		kind          string
+		limit         uint64
+		err           error
+	}{
+		{
+			desc:          "raise quota as platform admin",
+			platformAdmin: true,
+			kind:          quotas.ThingsKind,
+			limit:         10,
+		},
+		{
+			desc:  "raise quota as domain admin",
+			kind:  quotas.ThingsKind,
+			limit: 10,
+			err:   svcerr.ErrAuthorization,
+		},
+		{
+			desc:          "raise quota of unknown kind",
+			platformAdmin: true,
+			kind:          quotas.GroupsKind,
+			limit:         10,
+			err:           quotas.ErrUnknownKind,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, repo, authz, _ := newService(t, 1)
+			authz.On("Authorize", context.Background(), mock.MatchedBy(func(pr mgauthz.PolicyReq) bool {
+				return pr.ObjectType == policies.PlatformType
+			})).Return(authzErr(tc.platformAdmin))
+			var saved quotas.Quota
+			if tc.err == nil {
+				repo.On("Save", context.Background(), mock.Anything).Run(func(args mock.Arguments) {
+					saved = args.Get(1).(quotas.Quota)
+				}).Return(nil)
+				repo.On("Retrieve", context.Background(), domainID, tc.kind).Return(func(context.Context, string, string) quotas.Quota {
+					return saved
+				}, nil)
+			}
+			q, err := svc.Update(context.Background(), session, tc.kind, tc.limit)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("expected error %s, got %s", tc.err, err))
+			if tc.err == nil {
+				assert.Equal(t, tc.limit, q.Limit)
+				assert.Equal(t, uint64(1), q.Used)
+				assert.True(t, q.Override)
+				assert.Equal(t, session.UserID, q.UpdatedBy)
+			}
+		})
+	}
+}
+
+func authzErr(allowed bool) error {
+	if allowed {
+		return nil
+	}
+
+	return svcerr.ErrAuthorization
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ratelimit contains the per-thing publish rate limiter protocol
+// adapters use to throttle things publishing faster than their rate.
+package ratelimit
@@ -0,0 +1,181 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/go-kit/kit/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Reject rejects the messages over the rate with ErrRateLimited.
+	Reject = "reject"
+
+	// Shed drops the messages over the rate without an error, so the
+	// publishers are not disconnected.
+	Shed = "shed"
+
+	keyPrefix = "rate_limit"
+)
+
+var (
+	// ErrRateLimited indicates that the thing publishes faster than its rate.
+	ErrRateLimited = errors.New("publish rate limit exceeded")
+
+	errInvalidMode = errors.New("invalid rate limit mode")
+)
+
+// Config defines the rate limiter options.
+type Config struct {
+	// URL is the Redis URL of the token buckets shared by the adapter
+	// instances. An empty URL disables the limiter.
+	URL string `env:"URL"   envDefault:""`
+
+	// Rate is the default publish rate of the things in messages per second.
+	// Zero rate doesn't limit the things without their own rate.
+	Rate float64 `env:"RATE"  envDefault:"0"`
+
+	// Burst is the default number of messages a thing may publish at once.
+	// Zero burst equals the rate rounded up.
+	Burst uint32 `env:"BURST" envDefault:"0"`
+
+	// Mode is either reject or shed.
+	Mode string `env:"MODE"  envDefault:"reject"`
+}
+
+// Limit is the publish rate limit of a thing.
+type Limit struct {
+	// Rate is the number of messages per second.
+	Rate float64
+	// Burst is the number of messages published at once. Zero burst equals
+	// the rate rounded up.
+	Burst uint32
+}
+
+// Limiter limits the publish rate of things.
+//
+//go:generate mockery --name Limiter --output=./mocks --filename limiter.go --quiet --note "Copyright (c) Abstract Machines"
+type Limiter interface {
+	// Allow takes a token from the bucket of the thing, refilled at the rate
+	// of the limit, or of the default limit if the limit rate is zero. If the
+	// bucket is empty, ErrRateLimited is returned.
+	Allow(ctx context.Context, thingID string, limit Limit) error
+
+	// Mode returns how the messages over the rate are handled, either
+	// Reject or Shed.
+	Mode() string
+}
+
+var _ Limiter = (*limiter)(nil)
+
+type limiter struct {
+	client *redis.Client
+	def    Limit
+	mode   string
+	logger *slog.Logger
+}
+
+// allowScript refills the token bucket of the thing for the time passed since
+// the last refill and takes a token, if there is one. Time is read from Redis,
+// so the adapter instances share the clock. The bucket expires once it would
+// be full again.
+var allowScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = t[1] * 1000000 + t[2]
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + (now - ts) * rate / 1000000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+return allowed
+`)
+
+// New returns Redis rate limiter, which limits the things across all adapter
+// instances sharing the Redis database. The things without their own limit
+// are limited by the default limit of the config. If Redis is unavailable,
+// the messages are allowed, since throttling is preferable to losing them.
+func New(client *redis.Client, cfg Config, logger *slog.Logger) (Limiter, error) {
+	if cfg.Mode != Reject && cfg.Mode != Shed {
+		return nil, errors.Wrap(errInvalidMode, fmt.Errorf("unknown mode %q", cfg.Mode))
+	}
+
+	return &limiter{
+		client: client,
+		def:    Limit{Rate: cfg.Rate, Burst: cfg.Burst},
+		mode:   cfg.Mode,
+		logger: logger,
+	}, nil
+}
+
+func (l *limiter) Allow(ctx context.Context, thingID string, limit Limit) error {
+	if limit.Rate == 0 {
+		limit = l.def
+	}
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := limit.Burst
+	if burst == 0 {
+		burst = uint32(math.Ceil(limit.Rate))
+	}
+
+	key := fmt.Sprintf("%s:%s", keyPrefix, thingID)
+	allowed, err := allowScript.Run(ctx, l.client, []string{key}, limit.Rate, burst).Int()
+	if err != nil {
+		l.logger.Warn(fmt.Sprintf("failed to check publish rate of thing %s: %s", thingID, err))
+		return nil
+	}
+	if allowed == 0 {
+		return errors.Wrap(ErrRateLimited, fmt.Errorf("thing %s exceeded %g messages per second with burst %d", thingID, limit.Rate, burst))
+	}
+
+	return nil
+}
+
+func (l *limiter) Mode() string {
+	return l.mode
+}
+
+var _ Limiter = (*metricsLimiter)(nil)
+
+type metricsLimiter struct {
+	limiter Limiter
+	limited metrics.Counter
+}
+
+// NewMetricsLimiter returns rate limiter which counts the messages over the
+// rate.
+func NewMetricsLimiter(l Limiter, limited metrics.Counter) Limiter {
+	return &metricsLimiter{
+		limiter: l,
+		limited: limited,
+	}
+}
+
+func (ml *metricsLimiter) Allow(ctx context.Context, thingID string, limit Limit) error {
+	err := ml.limiter.Allow(ctx, thingID, limit)
+	if errors.Contains(err, ErrRateLimited) {
+		ml.limited.Add(1)
+	}
+
+	return err
+}
+
+func (ml *metricsLimiter) Mode() string {
+	return ml.limiter.Mode()
+}
@@ -0,0 +1,124 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package ratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	"github.com/absmach/magistrala/pkg/ratelimit/mocks"
+	"github.com/go-kit/kit/metrics/generic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLimiter(t *testing.T, cfg ratelimit.Config) ratelimit.Limiter {
+	l, err := ratelimit.New(redisClient, cfg, mglog.NewMock())
+	require.Nil(t, err, fmt.Sprintf("create limiter: unexpected error %s", err))
+
+	return l
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	cfg := ratelimit.Config{Rate: 1, Burst: 3, Mode: ratelimit.Reject}
+	limiter := newLimiter(t, cfg)
+	// Second limiter represents another adapter instance sharing the buckets.
+	limiter1 := newLimiter(t, cfg)
+
+	cases := []struct {
+		desc    string
+		thingID string
+		limit   ratelimit.Limit
+		allowed int
+	}{
+		{
+			desc:    "thing with default limit",
+			thingID: "thing-default",
+			allowed: 3,
+		},
+		{
+			desc:    "thing with its own limit",
+			thingID: "thing-own",
+			limit:   ratelimit.Limit{Rate: 1, Burst: 5},
+			allowed: 5,
+		},
+		{
+			desc:    "thing with its own limit without burst",
+			thingID: "thing-own-rate",
+			limit:   ratelimit.Limit{Rate: 2},
+			allowed: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			for i := 0; i < tc.allowed; i++ {
+				l := limiter
+				if i%2 == 1 {
+					l = limiter1
+				}
+				err := l.Allow(ctx, tc.thingID, tc.limit)
+				assert.Nil(t, err, fmt.Sprintf("message %d under rate: unexpected error %s", i, err))
+			}
+			err := limiter1.Allow(ctx, tc.thingID, tc.limit)
+			assert.True(t, errors.Contains(err, ratelimit.ErrRateLimited), fmt.Sprintf("message over rate: expected %s got %s", ratelimit.ErrRateLimited, err))
+			err = limiter.Allow(ctx, tc.thingID, tc.limit)
+			assert.True(t, errors.Contains(err, ratelimit.ErrRateLimited), fmt.Sprintf("message over rate on other instance: expected %s got %s", ratelimit.ErrRateLimited, err))
+		})
+	}
+
+	// Throttling of the things above doesn't affect the other things.
+	err := limiter.Allow(ctx, "thing-other", ratelimit.Limit{})
+	assert.Nil(t, err, fmt.Sprintf("other thing: unexpected error %s", err))
+}
+
+func TestAllowRefill(t *testing.T) {
+	ctx := context.Background()
+	limiter := newLimiter(t, ratelimit.Config{Mode: ratelimit.Reject})
+	limit := ratelimit.Limit{Rate: 10, Burst: 1}
+
+	err := limiter.Allow(ctx, "thing-refill", limit)
+	assert.Nil(t, err, fmt.Sprintf("first message: unexpected error %s", err))
+	err = limiter.Allow(ctx, "thing-refill", limit)
+	assert.True(t, errors.Contains(err, ratelimit.ErrRateLimited), fmt.Sprintf("message over rate: expected %s got %s", ratelimit.ErrRateLimited, err))
+
+	time.Sleep(150 * time.Millisecond)
+	err = limiter.Allow(ctx, "thing-refill", limit)
+	assert.Nil(t, err, fmt.Sprintf("message after refill: unexpected error %s", err))
+}
+
+func TestAllowUnlimited(t *testing.T) {
+	limiter := newLimiter(t, ratelimit.Config{Mode: ratelimit.Shed})
+
+	for i := 0; i < 100; i++ {
+		err := limiter.Allow(context.Background(), "thing-unlimited", ratelimit.Limit{})
+		require.Nil(t, err, fmt.Sprintf("message %d without limit: unexpected error %s", i, err))
+	}
+	assert.Equal(t, ratelimit.Shed, limiter.Mode(), "expected shed mode")
+}
+
+func TestNew(t *testing.T) {
+	_, err := ratelimit.New(redisClient, ratelimit.Config{Rate: 1, Mode: "drop"}, mglog.NewMock())
+	assert.NotNil(t, err, "create limiter with unknown mode: expected error")
+}
+
+func TestMetricsLimiter(t *testing.T) {
+	l := new(mocks.Limiter)
+	l.On("Allow", context.Background(), "thing-under", ratelimit.Limit{}).Return(nil)
+	l.On("Allow", context.Background(), "thing-over", ratelimit.Limit{}).Return(ratelimit.ErrRateLimited)
+	limited := generic.NewCounter("limited")
+	limiter := ratelimit.NewMetricsLimiter(l, limited)
+
+	err := limiter.Allow(context.Background(), "thing-under", ratelimit.Limit{})
+	assert.Nil(t, err, fmt.Sprintf("thing under rate: unexpected error %s", err))
+	err = limiter.Allow(context.Background(), "thing-over", ratelimit.Limit{})
+	assert.True(t, errors.Contains(err, ratelimit.ErrRateLimited), fmt.Sprintf("thing over rate: expected %s got %s", ratelimit.ErrRateLimited, err))
+	assert.Equal(t, 1.0, limited.Value(), "expected one limited message to be counted")
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package requestid contains helpers for propagating request correlation
+// IDs through the request context, HTTP headers and gRPC metadata.
+package requestid
@@ -0,0 +1,91 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package requestid_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/requestid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const id = "7b7b2e0c-3b7e-4f55-9b1e-4a3f8e7a2c11"
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	cases := []struct {
+		desc string
+		ctx  context.Context
+		md   []string
+	}{
+		{
+			desc: "call with request ID in context",
+			ctx:  requestid.WithID(context.Background(), id),
+			md:   []string{id},
+		},
+		{
+			desc: "call without request ID in context",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var md metadata.MD
+			invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				md, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+			err := requestid.UnaryClientInterceptor()(tc.ctx, "/method", nil, nil, nil, invoker)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			assert.Equal(t, tc.md, md.Get(requestid.MetadataKey), fmt.Sprintf("%s: unexpected request ID metadata", tc.desc))
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	cases := []struct {
+		desc      string
+		ctx       context.Context
+		requestID string
+		generated bool
+	}{
+		{
+			desc:      "handle call with request ID",
+			ctx:       metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, id)),
+			requestID: id,
+		},
+		{
+			desc:      "handle call without request ID",
+			ctx:       context.Background(),
+			generated: true,
+		},
+		{
+			desc:      "handle call with invalid request ID",
+			ctx:       metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "invalid id")),
+			generated: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var ctxID string
+			handler := func(ctx context.Context, req any) (any, error) {
+				ctxID = requestid.FromContext(ctx)
+				return nil, nil
+			}
+			_, err := requestid.UnaryServerInterceptor()(tc.ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			switch tc.generated {
+			case true:
+				assert.True(t, requestid.Valid(ctxID), fmt.Sprintf("%s: expected request ID to be generated, got %s", tc.desc, ctxID))
+			default:
+				assert.Equal(t, tc.requestID, ctxID, fmt.Sprintf("%s: expected request ID %s, got %s", tc.desc, tc.requestID, ctxID))
+			}
+		})
+	}
+}
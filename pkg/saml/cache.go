@@ -0,0 +1,50 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package saml
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayCache records the IDs of the consumed assertions, so that a captured
+// assertion can not be used to log in again while it is still valid.
+type ReplayCache interface {
+	// Consume records the assertion ID until it expires and reports whether
+	// the ID was not consumed before.
+	Consume(id string, expiry time.Time) bool
+}
+
+var _ ReplayCache = (*memoryCache)(nil)
+
+type memoryCache struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+// NewReplayCache returns an in-memory replay cache. The cache is local to the
+// service instance, so the assertion consumer service requests must be routed
+// to a single instance, or the instances must share a cache.
+func NewReplayCache() ReplayCache {
+	return &memoryCache{ids: map[string]time.Time{}}
+}
+
+func (c *memoryCache) Consume(id string, expiry time.Time) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, exp := range c.ids {
+		if !now.Before(exp) {
+			delete(c.ids, k)
+		}
+	}
+	if _, ok := c.ids[id]; ok {
+		return false
+	}
+	c.ids[id] = expiry
+
+	return true
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package saml contains the SAML 2.0 service provider used for the federated
+// login of the Magistrala users.
+package saml
@@ -0,0 +1,526 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"net/url"
+	"os"
+	"time"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+)
+
+// ProviderName is the name of the SAML provider set in the metadata of the
+// users who logged in with it.
+const ProviderName = "saml"
+
+const (
+	protocolNamespace = "urn:oasis:names:tc:SAML:2.0:protocol"
+	assertionNS       = "urn:oasis:names:tc:SAML:2.0:assertion"
+	statusSuccess     = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bearerMethod      = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	postBinding       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	unspecifiedFormat = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+	emailFormat       = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	timeLayout        = "2006-01-02T15:04:05Z"
+	idAttr            = "ID"
+)
+
+var (
+	// ErrInvalidResponse indicates a malformed SAML response.
+	ErrInvalidResponse = errors.New("invalid SAML response")
+
+	// ErrMissingSignature indicates that neither the SAML response nor the
+	// assertion is signed.
+	ErrMissingSignature = errors.New("SAML assertion is not signed")
+
+	// ErrInvalidSignature indicates that the signature of the SAML response
+	// or the assertion is not valid.
+	ErrInvalidSignature = errors.New("invalid SAML signature")
+
+	// ErrInvalidAudience indicates that the assertion is not intended for
+	// this service provider.
+	ErrInvalidAudience = errors.New("invalid SAML assertion audience")
+
+	// ErrExpiredAssertion indicates that the assertion is used outside of
+	// its validity period.
+	ErrExpiredAssertion = errors.New("SAML assertion is expired or not yet valid")
+
+	// ErrReplayedAssertion indicates that the assertion was already used to
+	// log in.
+	ErrReplayedAssertion = errors.New("SAML assertion is already consumed")
+
+	// ErrUnexpectedResponse indicates that the response does not answer the
+	// authentication request started by the user.
+	ErrUnexpectedResponse = errors.New("SAML response does not match the authentication request")
+)
+
+// Config is the configuration for the SAML service provider.
+type Config struct {
+	EntityID       string        `env:"ENTITY_ID"       envDefault:""`
+	ACSURL         string        `env:"ACS_URL"         envDefault:""`
+	IdPEntityID    string        `env:"IDP_ENTITY_ID"   envDefault:""`
+	IdPSSOURL      string        `env:"IDP_SSO_URL"     envDefault:""`
+	IdPCertFile    string        `env:"IDP_CERT_FILE"   envDefault:""`
+	EmailAttribute string        `env:"EMAIL_ATTRIBUTE" envDefault:"email"`
+	NameAttribute  string        `env:"NAME_ATTRIBUTE"  envDefault:"name"`
+	ClockSkew      time.Duration `env:"CLOCK_SKEW"      envDefault:"1m"`
+	// AllowIdPInitiated accepts the unsolicited responses of the logins
+	// started at the IdP, which can't be bound to the user's browser.
+	AllowIdPInitiated bool `env:"ALLOW_IDP_INITIATED" envDefault:"false"`
+	// JIT provisions the unknown users on their first login. Otherwise,
+	// the users must be registered before they log in.
+	JIT bool `env:"JIT" envDefault:"true"`
+}
+
+// ServiceProvider is the SAML 2.0 service provider which accepts the
+// assertions issued by a single identity provider.
+type ServiceProvider interface {
+	// Metadata returns the service provider metadata document.
+	Metadata() ([]byte, error)
+
+	// LoginURL returns the IdP URL which starts the SP-initiated login
+	// using the HTTP-Redirect binding, and the ID of the authentication
+	// request the response must answer.
+	LoginURL(relayState string) (string, string, error)
+
+	// ParseResponse validates the base64 encoded SAML response received on
+	// the assertion consumer service and returns the authenticated user.
+	// The request ID is the ID returned by LoginURL to the same browser,
+	// or empty for the IdP-initiated login.
+	ParseResponse(samlResponse, requestID string) (mgclients.Client, error)
+
+	// RedirectURL returns the URL to redirect the user to after the login.
+	RedirectURL() string
+
+	// ErrorURL returns the URL to redirect the user to if the login fails.
+	ErrorURL() string
+}
+
+var _ ServiceProvider = (*provider)(nil)
+
+type provider struct {
+	cfg           Config
+	validator     *dsig.ValidationContext
+	cache         ReplayCache
+	uiRedirectURL string
+	errorURL      string
+}
+
+// NewServiceProvider returns a new SAML service provider which trusts the
+// assertions signed by the given IdP certificate. The IDs of the consumed
+// assertions are recorded in the cache.
+func NewServiceProvider(cfg Config, cert *x509.Certificate, cache ReplayCache, uiRedirectURL, errorURL string) (ServiceProvider, error) {
+	if cfg.EntityID == "" || cfg.ACSURL == "" || cert == nil || cache == nil {
+		return nil, errors.Wrap(svcerr.ErrMalformedEntity, errors.New("SAML entity ID, ACS URL, IdP certificate and replay cache are required"))
+	}
+
+	validator := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{cert},
+	})
+	validator.IdAttribute = idAttr
+
+	return &provider{
+		cfg:           cfg,
+		validator:     validator,
+		cache:         cache,
+		uiRedirectURL: uiRedirectURL,
+		errorURL:      errorURL,
+	}, nil
+}
+
+// LoadCertificate reads the PEM encoded certificate from the file.
+func LoadCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode PEM certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func (p *provider) RedirectURL() string {
+	return p.uiRedirectURL
+}
+
+func (p *provider) ErrorURL() string {
+	return p.errorURL
+}
+
+func (p *provider) Metadata() ([]byte, error) {
+	type nameIDFormat struct {
+		Value string `xml:",chardata"`
+	}
+	type acs struct {
+		Binding  string `xml:"Binding,attr"`
+		Location string `xml:"Location,attr"`
+		Index    int    `xml:"index,attr"`
+	}
+	type spDescriptor struct {
+		AuthnRequestsSigned        bool           `xml:"AuthnRequestsSigned,attr"`
+		WantAssertionsSigned       bool           `xml:"WantAssertionsSigned,attr"`
+		ProtocolSupportEnumeration string         `xml:"protocolSupportEnumeration,attr"`
+		NameIDFormats              []nameIDFormat `xml:"NameIDFormat"`
+		AssertionConsumerService   acs            `xml:"AssertionConsumerService"`
+	}
+	md := struct {
+		XMLName      xml.Name     `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+		EntityID     string       `xml:"entityID,attr"`
+		SPDescriptor spDescriptor `xml:"SPSSODescriptor"`
+	}{
+		EntityID: p.cfg.EntityID,
+		SPDescriptor: spDescriptor{
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: protocolNamespace,
+			NameIDFormats:              []nameIDFormat{{emailFormat}, {unspecifiedFormat}},
+			AssertionConsumerService: acs{
+				Binding:  postBinding,
+				Location: p.cfg.ACSURL,
+			},
+		},
+	}
+
+	data, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (p *provider) LoginURL(relayState string) (string, string, error) {
+	if p.cfg.IdPSSOURL == "" {
+		return "", "", errors.Wrap(svcerr.ErrMalformedEntity, errors.New("SAML IdP SSO URL is not configured"))
+	}
+
+	id := make([]byte, 20)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	req := struct {
+		XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+		ID                          string   `xml:"ID,attr"`
+		Version                     string   `xml:"Version,attr"`
+		IssueInstant                string   `xml:"IssueInstant,attr"`
+		Destination                 string   `xml:"Destination,attr"`
+		ProtocolBinding             string   `xml:"ProtocolBinding,attr"`
+		AssertionConsumerServiceURL string   `xml:"AssertionConsumerServiceURL,attr"`
+		Issuer                      struct {
+			XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+			Value   string   `xml:",chardata"`
+		}
+	}{
+		ID:                          "_" + hex.EncodeToString(id),
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(timeLayout),
+		Destination:                 p.cfg.IdPSSOURL,
+		ProtocolBinding:             postBinding,
+		AssertionConsumerServiceURL: p.cfg.ACSURL,
+	}
+	req.Issuer.Value = p.cfg.EntityID
+	data, err := xml.Marshal(req)
+	if err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(p.cfg.IdPSSOURL)
+	if err != nil {
+		return "", "", errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	q := u.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		q.Set("RelayState", relayState)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), req.ID, nil
+}
+
+func (p *provider) ParseResponse(samlResponse, requestID string) (mgclients.Client, error) {
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(ErrInvalidResponse, err))
+	}
+	resp, assertion, err := p.verifiedAssertion(data)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+
+	expiry, err := p.validateAssertion(resp, assertion, requestID, time.Now())
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	id := attr(assertion, idAttr)
+	if id == "" {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(ErrInvalidResponse, errors.New("missing assertion ID")))
+	}
+	if !p.cache.Consume(id, expiry.Add(p.cfg.ClockSkew)) {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, ErrReplayedAssertion)
+	}
+
+	return p.client(assertion)
+}
+
+// verifiedAssertion parses the response and returns it with its assertion
+// once the signature of the response or of the assertion is verified. The
+// returned elements are the signed ones, as read by the signature validation,
+// so the content which is not covered by a signature is never used.
+func (p *provider) verifiedAssertion(data []byte) (*etree.Element, *etree.Element, error) {
+	resp, err := parse(data)
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, err)
+	}
+	if !is(resp, protocolNamespace, "Response") {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, errors.New("expected a Response element"))
+	}
+
+	respSigned, err := signed(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	if respSigned {
+		if resp, err = p.validate(resp); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if dest := attr(resp, "Destination"); dest != "" && dest != p.cfg.ACSURL {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, errors.New("invalid destination"))
+	}
+	status := child(resp, protocolNamespace, "Status")
+	if status == nil {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, errors.New("missing status"))
+	}
+	if code := child(status, protocolNamespace, "StatusCode"); code == nil || attr(code, "Value") != statusSuccess {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, errors.New("authentication failed at the identity provider"))
+	}
+	if child(resp, assertionNS, "EncryptedAssertion") != nil {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, errors.New("encrypted assertions are not supported"))
+	}
+	assertions := children(resp, assertionNS, "Assertion")
+	if len(assertions) != 1 {
+		return nil, nil, errors.Wrap(ErrInvalidResponse, errors.New("expected exactly one assertion"))
+	}
+	assertion := assertions[0]
+
+	assertionSigned, err := signed(assertion)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !respSigned && !assertionSigned {
+		return nil, nil, ErrMissingSignature
+	}
+	if assertionSigned {
+		if assertion, err = p.validate(assertion); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return resp, assertion, nil
+}
+
+// validate verifies the enveloped signature of the element and returns the
+// signed element. The element is detached from its document along with the
+// namespaces declared by its ancestors, so it is read as it was signed.
+func (p *provider) validate(el *etree.Element) (*etree.Element, error) {
+	nsCtx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidResponse, err)
+	}
+	if el, err = etreeutils.NSDetatch(nsCtx, el); err != nil {
+		return nil, errors.Wrap(ErrInvalidResponse, err)
+	}
+	if el, err = p.validator.Validate(el); err != nil {
+		return nil, errors.Wrap(ErrInvalidSignature, err)
+	}
+
+	return el, nil
+}
+
+// signed reports whether the element has an enveloped signature.
+func signed(el *etree.Element) (bool, error) {
+	switch len(children(el, dsig.Namespace, dsig.SignatureTag)) {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	default:
+		return false, errors.Wrap(ErrInvalidSignature, errors.New("multiple signatures"))
+	}
+}
+
+// validateAssertion validates the assertion conditions and its bearer
+// subject confirmation, and returns the time the assertion expires at.
+func (p *provider) validateAssertion(resp, assertion *etree.Element, requestID string, now time.Time) (time.Time, error) {
+	if p.cfg.IdPEntityID != "" {
+		issuer := child(assertion, assertionNS, "Issuer")
+		if issuer == nil || text(issuer) != p.cfg.IdPEntityID {
+			return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("invalid issuer"))
+		}
+	}
+
+	conditions := child(assertion, assertionNS, "Conditions")
+	if conditions == nil {
+		return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("missing conditions"))
+	}
+	if _, err := p.validateTime(attr(conditions, "NotBefore"), attr(conditions, "NotOnOrAfter"), now); err != nil {
+		return time.Time{}, err
+	}
+	restrictions := children(conditions, assertionNS, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return time.Time{}, ErrInvalidAudience
+	}
+	// Every audience restriction must be satisfied.
+	for _, r := range restrictions {
+		found := false
+		for _, a := range children(r, assertionNS, "Audience") {
+			if text(a) == p.cfg.EntityID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return time.Time{}, ErrInvalidAudience
+		}
+	}
+
+	subject := child(assertion, assertionNS, "Subject")
+	if subject == nil {
+		return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("missing subject"))
+	}
+	for _, sc := range children(subject, assertionNS, "SubjectConfirmation") {
+		if attr(sc, "Method") != bearerMethod {
+			continue
+		}
+		data := child(sc, assertionNS, "SubjectConfirmationData")
+		if data == nil {
+			return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("missing bearer subject confirmation data"))
+		}
+		if attr(data, "Recipient") != p.cfg.ACSURL {
+			return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("invalid recipient"))
+		}
+		if attr(data, "NotOnOrAfter") == "" {
+			return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("missing bearer subject confirmation expiry"))
+		}
+		if err := p.validateRequest(attr(resp, "InResponseTo"), attr(data, "InResponseTo"), requestID); err != nil {
+			return time.Time{}, err
+		}
+		return p.validateTime(attr(data, "NotBefore"), attr(data, "NotOnOrAfter"), now)
+	}
+
+	return time.Time{}, errors.Wrap(ErrInvalidResponse, errors.New("missing bearer subject confirmation"))
+}
+
+// validateRequest checks that the response answers the authentication
+// request started by the same browser. Unsolicited responses are accepted
+// only if the IdP-initiated login is allowed.
+func (p *provider) validateRequest(respInResponseTo, inResponseTo, requestID string) error {
+	if respInResponseTo != "" && respInResponseTo != inResponseTo {
+		return ErrUnexpectedResponse
+	}
+	switch {
+	case inResponseTo == "" && requestID == "":
+		if !p.cfg.AllowIdPInitiated {
+			return ErrUnexpectedResponse
+		}
+		return nil
+	case inResponseTo != requestID:
+		return ErrUnexpectedResponse
+	default:
+		return nil
+	}
+}
+
+func (p *provider) validateTime(notBefore, notOnOrAfter string, now time.Time) (time.Time, error) {
+	if notBefore != "" {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return time.Time{}, errors.Wrap(ErrInvalidResponse, err)
+		}
+		if now.Add(p.cfg.ClockSkew).Before(t) {
+			return time.Time{}, ErrExpiredAssertion
+		}
+	}
+	if notOnOrAfter == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, notOnOrAfter)
+	if err != nil {
+		return time.Time{}, errors.Wrap(ErrInvalidResponse, err)
+	}
+	if !now.Add(-p.cfg.ClockSkew).Before(t) {
+		return time.Time{}, ErrExpiredAssertion
+	}
+
+	return t, nil
+}
+
+// client maps the assertion subject and attributes to the user. The email
+// attribute is used as the identity, falling back to the NameID.
+func (p *provider) client(assertion *etree.Element) (mgclients.Client, error) {
+	nameID := child(child(assertion, assertionNS, "Subject"), assertionNS, "NameID")
+	if nameID == nil || text(nameID) == "" {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(ErrInvalidResponse, errors.New("missing name ID")))
+	}
+
+	attrs := map[string]string{}
+	for _, st := range children(assertion, assertionNS, "AttributeStatement") {
+		for _, a := range children(st, assertionNS, "Attribute") {
+			if v := child(a, assertionNS, "AttributeValue"); v != nil {
+				attrs[attr(a, "Name")] = text(v)
+			}
+		}
+	}
+
+	identity := attrs[p.cfg.EmailAttribute]
+	if identity == "" {
+		identity = text(nameID)
+	}
+	name := attrs[p.cfg.NameAttribute]
+	if name == "" {
+		name = identity
+	}
+
+	return mgclients.Client{
+		Name: name,
+		Credentials: mgclients.Credentials{
+			Identity: identity,
+		},
+		Metadata: map[string]interface{}{
+			"oauth_provider": ProviderName,
+			"saml_name_id":   text(nameID),
+		},
+		Status: mgclients.EnabledStatus,
+	}, nil
+}
@@ -0,0 +1,424 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package saml_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	entityID    = "https://magistrala.example.com/saml/metadata"
+	acsURL      = "https://magistrala.example.com/saml/acs"
+	idpEntityID = "https://idp.example.com"
+	idpSSOURL   = "https://idp.example.com/sso"
+	email       = "user@example.com"
+	nameID      = "user-name-id"
+	assertionID = "_assertion"
+	responseID  = "_response"
+	requestID   = "_request"
+	assertionNS = "urn:oasis:names:tc:SAML:2.0:assertion"
+	protocolNS  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	dsigNS      = "http://www.w3.org/2000/09/xmldsig#"
+	timeLayout  = "2006-01-02T15:04:05Z"
+)
+
+var cfg = saml.Config{
+	EntityID:       entityID,
+	ACSURL:         acsURL,
+	IdPEntityID:    idpEntityID,
+	IdPSSOURL:      idpSSOURL,
+	EmailAttribute: "email",
+	NameAttribute:  "name",
+	ClockSkew:      time.Minute,
+}
+
+type assertionOpts struct {
+	audience     string
+	recipient    string
+	inResponseTo string
+	notOnOrAfter time.Time
+	noExpiry     bool
+}
+
+func validOpts() assertionOpts {
+	return assertionOpts{
+		audience:     entityID,
+		recipient:    acsURL,
+		inResponseTo: requestID,
+		notOnOrAfter: time.Now().Add(5 * time.Minute),
+	}
+}
+
+func newCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err, fmt.Sprintf("unexpected error generating key: %s", err))
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating certificate: %s", err))
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err, fmt.Sprintf("unexpected error parsing certificate: %s", err))
+
+	return key, cert
+}
+
+// assertion returns the assertion in its exclusive canonical form, so the
+// test signatures are computed independently of the verified implementation.
+func assertion(opts assertionOpts) string {
+	now := time.Now().UTC()
+	notBefore := now.Add(-time.Minute).Format(timeLayout)
+	notOnOrAfter := opts.notOnOrAfter.UTC().Format(timeLayout)
+	confirmation := ``
+	if opts.inResponseTo != "" {
+		confirmation += ` InResponseTo="` + opts.inResponseTo + `"`
+	}
+	if !opts.noExpiry {
+		confirmation += ` NotOnOrAfter="` + notOnOrAfter + `"`
+	}
+
+	return `<saml:Assertion xmlns:saml="` + assertionNS + `" ID="` + assertionID + `" IssueInstant="` + now.Format(timeLayout) + `" Version="2.0">` +
+		`<saml:Issuer>` + idpEntityID + `</saml:Issuer>` +
+		`<saml:Subject><saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified">` + nameID + `</saml:NameID>` +
+		`<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData` + confirmation + ` Recipient="` + opts.recipient + `"></saml:SubjectConfirmationData>` +
+		`</saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotBefore="` + notBefore + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+		`<saml:AudienceRestriction><saml:Audience>` + opts.audience + `</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+		`<saml:AttributeStatement><saml:Attribute Name="email"><saml:AttributeValue>` + email + `</saml:AttributeValue></saml:Attribute></saml:AttributeStatement>` +
+		`</saml:Assertion>`
+}
+
+// response returns the canonical response wrapping the assertion.
+func response(assertion string) string {
+	return `<samlp:Response xmlns:samlp="` + protocolNS + `" Destination="` + acsURL + `" ID="` + responseID + `" Version="2.0">` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"></samlp:StatusCode></samlp:Status>` +
+		assertion +
+		`</samlp:Response>`
+}
+
+// signature returns the enveloped signature of the canonical element.
+func signature(t *testing.T, key *rsa.PrivateKey, canonical, id string) string {
+	digest := sha256.Sum256([]byte(canonical))
+	signedInfo := `<ds:SignedInfo xmlns:ds="` + dsigNS + `">` +
+		`<ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#` + id + `"><ds:Transforms>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"></ds:Transform>` +
+		`<ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"></ds:Transform>` +
+		`</ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue></ds:Reference></ds:SignedInfo>`
+	hashed := sha256.Sum256([]byte(signedInfo))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	require.Nil(t, err, fmt.Sprintf("unexpected error signing: %s", err))
+
+	return `<ds:Signature xmlns:ds="` + dsigNS + `">` + signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(sig) + `</ds:SignatureValue></ds:Signature>`
+}
+
+// enveloped inserts the signature after the issuer or the first child.
+func enveloped(element, after, sig string) string {
+	return strings.Replace(element, after, after+sig, 1)
+}
+
+func encode(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func TestParseResponse(t *testing.T) {
+	key, cert := newCertificate(t)
+	otherKey, _ := newCertificate(t)
+
+	signedAssertion := func(opts assertionOpts) string {
+		a := assertion(opts)
+		return enveloped(a, "</saml:Issuer>", signature(t, key, a, assertionID))
+	}
+
+	valid := assertion(validOpts())
+	signed := enveloped(valid, "</saml:Issuer>", signature(t, key, valid, assertionID))
+
+	// The same signed assertion serialized differently: the namespace is
+	// declared on the response and the empty elements are self-closing.
+	reserialized := strings.Replace(signed, ` xmlns:saml="`+assertionNS+`"`, "", 1)
+	reserialized = strings.Replace(reserialized, `"></saml:SubjectConfirmationData>`, `"/>`, 1)
+	reserialized = strings.Replace(response(reserialized), `<samlp:Response `, `<samlp:Response xmlns:saml="`+assertionNS+`" `, 1)
+
+	signedResponse := response(valid)
+	signedResponse = enveloped(signedResponse, `Version="2.0">`, signature(t, key, signedResponse, responseID))
+
+	client := mgclients.Client{
+		Name: email,
+		Credentials: mgclients.Credentials{
+			Identity: email,
+		},
+		Metadata: map[string]interface{}{
+			"oauth_provider": "saml",
+			"saml_name_id":   nameID,
+		},
+		Status: mgclients.EnabledStatus,
+	}
+
+	unsolicitedOpts := validOpts()
+	unsolicitedOpts.inResponseTo = ""
+	unsolicited := signedAssertion(unsolicitedOpts)
+
+	noExpiryOpts := validOpts()
+	noExpiryOpts.noExpiry = true
+
+	idpInitiated := cfg
+	idpInitiated.AllowIdPInitiated = true
+
+	cases := []struct {
+		desc      string
+		cfg       saml.Config
+		response  string
+		requestID string
+		replay    bool
+		client    mgclients.Client
+		err       error
+	}{
+		{
+			desc:      "parse response with signed assertion",
+			response:  encode(response(signed)),
+			requestID: requestID,
+			client:    client,
+		},
+		{
+			desc:      "parse signed response",
+			response:  encode(signedResponse),
+			requestID: requestID,
+			client:    client,
+		},
+		{
+			desc:      "parse response with reserialized signed assertion",
+			response:  encode(reserialized),
+			requestID: requestID,
+			client:    client,
+		},
+		{
+			desc:      "parse response with unsigned assertion",
+			response:  encode(response(valid)),
+			requestID: requestID,
+			err:       saml.ErrMissingSignature,
+		},
+		{
+			desc:      "parse response with tampered assertion",
+			response:  encode(response(strings.Replace(signed, email, "admin@example.com", 1))),
+			requestID: requestID,
+			err:       saml.ErrInvalidSignature,
+		},
+		{
+			desc:      "parse tampered signed response",
+			response:  encode(strings.Replace(signedResponse, nameID, "admin", 1)),
+			requestID: requestID,
+			err:       saml.ErrInvalidSignature,
+		},
+		{
+			desc:      "parse response with assertion signed by another key",
+			response:  encode(response(enveloped(valid, "</saml:Issuer>", signature(t, otherKey, valid, assertionID)))),
+			requestID: requestID,
+			err:       saml.ErrInvalidSignature,
+		},
+		{
+			desc:      "parse response with signature referencing another element",
+			response:  encode(response(enveloped(valid, "</saml:Issuer>", signature(t, key, valid, "_other")))),
+			requestID: requestID,
+			err:       saml.ErrInvalidSignature,
+		},
+		{
+			desc: "parse response with invalid audience",
+			response: encode(response(signedAssertion(assertionOpts{
+				audience:     "https://other.example.com",
+				recipient:    acsURL,
+				inResponseTo: requestID,
+				notOnOrAfter: time.Now().Add(5 * time.Minute),
+			}))),
+			err: saml.ErrInvalidAudience,
+		},
+		{
+			desc: "parse response with expired assertion",
+			response: encode(response(signedAssertion(assertionOpts{
+				audience:     entityID,
+				recipient:    acsURL,
+				inResponseTo: requestID,
+				notOnOrAfter: time.Now().Add(-5 * time.Minute),
+			}))),
+			err: saml.ErrExpiredAssertion,
+		},
+		{
+			desc: "parse response with invalid recipient",
+			response: encode(response(signedAssertion(assertionOpts{
+				audience:     entityID,
+				recipient:    "https://other.example.com/saml/acs",
+				inResponseTo: requestID,
+				notOnOrAfter: time.Now().Add(5 * time.Minute),
+			}))),
+			err: saml.ErrInvalidResponse,
+		},
+		{
+			desc:      "parse replayed response",
+			response:  encode(response(signed)),
+			requestID: requestID,
+			replay:    true,
+			err:       saml.ErrReplayedAssertion,
+		},
+		{
+			desc:      "parse response without the request ID",
+			response:  encode(response(signed)),
+			requestID: "",
+			err:       saml.ErrUnexpectedResponse,
+		},
+		{
+			desc:      "parse response to another request",
+			response:  encode(response(signed)),
+			requestID: "_other",
+			err:       saml.ErrUnexpectedResponse,
+		},
+		{
+			desc:      "parse unsolicited response",
+			response:  encode(response(unsolicited)),
+			requestID: "",
+			err:       saml.ErrUnexpectedResponse,
+		},
+		{
+			desc:      "parse unsolicited response with IdP-initiated login allowed",
+			cfg:       idpInitiated,
+			response:  encode(response(unsolicited)),
+			requestID: "",
+			client:    client,
+		},
+		{
+			desc:      "parse response without subject confirmation expiry",
+			response:  encode(response(signedAssertion(noExpiryOpts))),
+			requestID: requestID,
+			err:       saml.ErrInvalidResponse,
+		},
+		{
+			desc:      "parse response with document type declaration",
+			response:  encode(`<!DOCTYPE foo [<!ENTITY xxe "xxe">]>` + response(signed)),
+			requestID: requestID,
+			err:       saml.ErrInvalidResponse,
+		},
+		{
+			desc:      "parse invalid base64 response",
+			response:  "invalid",
+			requestID: requestID,
+			err:       saml.ErrInvalidResponse,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.cfg.EntityID == "" {
+				tc.cfg = cfg
+			}
+			sp, err := saml.NewServiceProvider(tc.cfg, cert, saml.NewReplayCache(), "", "")
+			require.Nil(t, err, fmt.Sprintf("unexpected error creating service provider: %s", err))
+			if tc.replay {
+				_, err := sp.ParseResponse(tc.response, tc.requestID)
+				require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+			}
+			client, err := sp.ParseResponse(tc.response, tc.requestID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err != nil {
+				assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("%s: expected %s got %s", tc.desc, svcerr.ErrAuthentication, err))
+				return
+			}
+			assert.Equal(t, tc.client, client, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.client, client))
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	_, cert := newCertificate(t)
+	sp, err := saml.NewServiceProvider(cfg, cert, saml.NewReplayCache(), "", "")
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating service provider: %s", err))
+
+	md, err := sp.Metadata()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Contains(t, string(md), `entityID="`+entityID+`"`)
+	assert.Contains(t, string(md), `Location="`+acsURL+`"`)
+	assert.Contains(t, string(md), `WantAssertionsSigned="true"`)
+}
+
+func TestLoginURL(t *testing.T) {
+	_, cert := newCertificate(t)
+	sp, err := saml.NewServiceProvider(cfg, cert, saml.NewReplayCache(), "", "")
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating service provider: %s", err))
+
+	loginURL, id, err := sp.LoginURL("state")
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	u, err := url.Parse(loginURL)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.True(t, strings.HasPrefix(loginURL, idpSSOURL+"?"), fmt.Sprintf("expected login URL prefix %s got %s", idpSSOURL, loginURL))
+	assert.Equal(t, "state", u.Query().Get("RelayState"))
+
+	data, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	req, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Contains(t, string(req), `AssertionConsumerServiceURL="`+acsURL+`"`)
+	assert.Contains(t, string(req), entityID)
+	assert.Contains(t, string(req), `ID="`+id+`"`)
+}
+
+func TestNewServiceProvider(t *testing.T) {
+	_, cert := newCertificate(t)
+
+	cases := []struct {
+		desc string
+		cfg  saml.Config
+		cert *x509.Certificate
+		err  error
+	}{
+		{
+			desc: "create service provider",
+			cfg:  cfg,
+			cert: cert,
+		},
+		{
+			desc: "create service provider without entity ID",
+			cfg:  saml.Config{ACSURL: acsURL},
+			cert: cert,
+			err:  svcerr.ErrMalformedEntity,
+		},
+		{
+			desc: "create service provider without IdP certificate",
+			cfg:  cfg,
+			err:  svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := saml.NewServiceProvider(tc.cfg, tc.cert, saml.NewReplayCache(), "", "")
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		})
+	}
+}
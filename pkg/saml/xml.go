@@ -0,0 +1,82 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package saml
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/beevik/etree"
+)
+
+var errDoctype = errors.New("document type declarations are not allowed")
+
+// parse parses the XML document and returns its root element. Document type
+// declarations are rejected, so that no entities are ever expanded.
+func parse(data []byte) (*etree.Element, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+	for _, tok := range doc.Child {
+		if _, ok := tok.(*etree.Directive); ok {
+			return nil, errDoctype
+		}
+	}
+	root := doc.Root()
+	if root == nil {
+		return nil, errors.New("incomplete document")
+	}
+
+	return root, nil
+}
+
+// is reports whether the element has the namespace and the local name.
+func is(el *etree.Element, space, local string) bool {
+	return el.Tag == local && el.NamespaceURI() == space
+}
+
+// child returns the first child element with the namespace and the local
+// name, if any.
+func child(el *etree.Element, space, local string) *etree.Element {
+	if el == nil {
+		return nil
+	}
+	for _, c := range el.ChildElements() {
+		if is(c, space, local) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// children returns the child elements with the namespace and the local name.
+func children(el *etree.Element, space, local string) []*etree.Element {
+	var ret []*etree.Element
+	for _, c := range el.ChildElements() {
+		if is(c, space, local) {
+			ret = append(ret, c)
+		}
+	}
+
+	return ret
+}
+
+// attr returns the value of the unqualified attribute.
+func attr(el *etree.Element, key string) string {
+	return el.SelectAttrValue(key, "")
+}
+
+// text returns the character data of the element.
+func text(el *etree.Element) string {
+	return strings.TrimSpace(el.Text())
+}
+
+// decodeBase64 decodes the base64 value, ignoring the line breaks which are
+// commonly inserted by the IdPs.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	authmocks "github.com/absmach/magistrala/auth/mocks"
+	internalapi "github.com/absmach/magistrala/internal/api"
 	"github.com/absmach/magistrala/internal/testsutil"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/apiutil"
@@ -22,8 +23,11 @@ import (
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/groups"
 	gmocks "github.com/absmach/magistrala/pkg/groups/mocks"
+	jobsmocks "github.com/absmach/magistrala/pkg/jobs/mocks"
 	oauth2mocks "github.com/absmach/magistrala/pkg/oauth2/mocks"
+	"github.com/absmach/magistrala/pkg/passwords"
 	policies "github.com/absmach/magistrala/pkg/policies"
+	qmocks "github.com/absmach/magistrala/pkg/quotas/mocks"
 	sdk "github.com/absmach/magistrala/pkg/sdk/go"
 	thapi "github.com/absmach/magistrala/things/api/http"
 	thmocks "github.com/absmach/magistrala/things/mocks"
@@ -53,8 +57,10 @@ func setupChannels() (*httptest.Server, *gmocks.Service, *authnmocks.Authenticat
 
 	mux := chi.NewRouter()
 
-	thapi.MakeHandler(tsvc, gsvc, authn, mux, logger, "")
-	usapi.MakeHandler(usvc, authn, token, true, gsvc, mux, logger, "", passRegex, provider)
+	// The users handler registers middlewares, so it must be made before
+	// any routes are added to the shared mux.
+	usapi.MakeHandler(usvc, authn, token, true, gsvc, new(qmocks.Service), mux, logger, "", passRegex, internalapi.PageLimits{}, passwords.NewEvaluator(passRegex, 0), nil, nil, nil, nil, provider)
+	thapi.MakeHandler(tsvc, gsvc, new(jobsmocks.Service), new(qmocks.Service), authn, mux, logger, "", internalapi.PageLimits{})
 	return httptest.NewServer(mux), gsvc, authn
 }
 
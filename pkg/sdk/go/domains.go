@@ -17,18 +17,19 @@ const domainsEndpoint = "domains"
 
 // Domain represents magistrala domain.
 type Domain struct {
-	ID          string    `json:"id,omitempty"`
-	Name        string    `json:"name,omitempty"`
-	Metadata    Metadata  `json:"metadata,omitempty"`
-	Tags        []string  `json:"tags,omitempty"`
-	Alias       string    `json:"alias,omitempty"`
-	Status      string    `json:"status,omitempty"`
-	Permission  string    `json:"permission,omitempty"`
-	CreatedBy   string    `json:"created_by,omitempty"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
-	UpdatedBy   string    `json:"updated_by,omitempty"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty"`
-	Permissions []string  `json:"permissions,omitempty"`
+	ID               string    `json:"id,omitempty"`
+	Name             string    `json:"name,omitempty"`
+	Metadata         Metadata  `json:"metadata,omitempty"`
+	Tags             []string  `json:"tags,omitempty"`
+	Alias            string    `json:"alias,omitempty"`
+	Status           string    `json:"status,omitempty"`
+	SelfRegistration *bool     `json:"self_registration,omitempty"`
+	Permission       string    `json:"permission,omitempty"`
+	CreatedBy        string    `json:"created_by,omitempty"`
+	CreatedAt        time.Time `json:"created_at,omitempty"`
+	UpdatedBy        string    `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+	Permissions      []string  `json:"permissions,omitempty"`
 }
 
 func (sdk mgSDK) CreateDomain(domain Domain, token string) (Domain, errors.SDKError) {
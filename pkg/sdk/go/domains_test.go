@@ -1017,11 +1017,11 @@ func TestAddUserToDomain(t *testing.T) {
 	}
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
-			svcCall := svc.On("AssignUsers", mock.Anything, tc.token, tc.domainID, tc.addUserDomainReq.UserIDs, tc.addUserDomainReq.Relation).Return(tc.svcErr)
+			svcCall := svc.On("AssignUsers", mock.Anything, tc.token, tc.domainID, tc.addUserDomainReq.UserIDs, tc.addUserDomainReq.Relation, tc.addUserDomainReq.Conditions).Return(tc.svcErr)
 			err := mgsdk.AddUserToDomain(tc.domainID, tc.addUserDomainReq, tc.token)
 			assert.Equal(t, tc.err, err)
 			if tc.err == nil {
-				ok := svcCall.Parent.AssertCalled(t, "AssignUsers", mock.Anything, tc.token, tc.domainID, tc.addUserDomainReq.UserIDs, tc.addUserDomainReq.Relation)
+				ok := svcCall.Parent.AssertCalled(t, "AssignUsers", mock.Anything, tc.token, tc.domainID, tc.addUserDomainReq.UserIDs, tc.addUserDomainReq.Relation, tc.addUserDomainReq.Conditions)
 				assert.True(t, ok)
 			}
 			svcCall.Unset()
@@ -1122,16 +1122,17 @@ func generateTestDomain(t *testing.T) (auth.Domain, sdk.Domain) {
 	}
 
 	sd := sdk.Domain{
-		ID:        ad.ID,
-		Name:      ad.Name,
-		Metadata:  validMetadata,
-		Tags:      ad.Tags,
-		Alias:     ad.Alias,
-		Status:    ad.Status.String(),
-		CreatedBy: ad.CreatedBy,
-		CreatedAt: ad.CreatedAt,
-		UpdatedBy: ad.UpdatedBy,
-		UpdatedAt: ad.UpdatedAt,
+		ID:               ad.ID,
+		Name:             ad.Name,
+		Metadata:         validMetadata,
+		Tags:             ad.Tags,
+		Alias:            ad.Alias,
+		Status:           ad.Status.String(),
+		SelfRegistration: &ad.SelfRegistration,
+		CreatedBy:        ad.CreatedBy,
+		CreatedAt:        ad.CreatedAt,
+		UpdatedBy:        ad.UpdatedBy,
+		UpdatedAt:        ad.UpdatedAt,
 	}
 	return ad, sd
 }
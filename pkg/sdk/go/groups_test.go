@@ -12,6 +12,7 @@ import (
 	"time"
 
 	authmocks "github.com/absmach/magistrala/auth/mocks"
+	internalapi "github.com/absmach/magistrala/internal/api"
 	"github.com/absmach/magistrala/internal/testsutil"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/apiutil"
@@ -23,7 +24,9 @@ import (
 	"github.com/absmach/magistrala/pkg/groups"
 	"github.com/absmach/magistrala/pkg/groups/mocks"
 	oauth2mocks "github.com/absmach/magistrala/pkg/oauth2/mocks"
+	"github.com/absmach/magistrala/pkg/passwords"
 	policies "github.com/absmach/magistrala/pkg/policies"
+	qmocks "github.com/absmach/magistrala/pkg/quotas/mocks"
 	sdk "github.com/absmach/magistrala/pkg/sdk/go"
 	"github.com/absmach/magistrala/users/api"
 	umocks "github.com/absmach/magistrala/users/mocks"
@@ -49,7 +52,7 @@ func setupGroups() (*httptest.Server, *mocks.Service, *authnmocks.Authentication
 	provider.On("Name").Return("test")
 	authn := new(authnmocks.Authentication)
 	token := new(authmocks.TokenServiceClient)
-	api.MakeHandler(usvc, authn, token, true, gsvc, mux, logger, "", passRegex, provider)
+	api.MakeHandler(usvc, authn, token, true, gsvc, new(qmocks.Service), mux, logger, "", passRegex, internalapi.PageLimits{}, passwords.NewEvaluator(passRegex, 0), nil, nil, nil, nil, provider)
 
 	return httptest.NewServer(mux), gsvc, authn
 }
@@ -21,6 +21,7 @@ const (
 type Invitation struct {
 	InvitedBy   string    `json:"invited_by"`
 	UserID      string    `json:"user_id"`
+	Email       string    `json:"email,omitempty"`
 	DomainID    string    `json:"domain_id"`
 	Token       string    `json:"token,omitempty"`
 	Relation    string    `json:"relation,omitempty"`
@@ -28,6 +29,9 @@ type Invitation struct {
 	UpdatedAt   time.Time `json:"updated_at,omitempty"`
 	ConfirmedAt time.Time `json:"confirmed_at,omitempty"`
 	RejectedAt  time.Time `json:"rejected_at,omitempty"`
+	SentAt      time.Time `json:"sent_at,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	State       string    `json:"state,omitempty"`
 	Resend      bool      `json:"resend,omitempty"`
 }
 
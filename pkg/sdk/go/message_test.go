@@ -17,6 +17,7 @@ import (
 	authzmocks "github.com/absmach/magistrala/pkg/authz/mocks"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/messaging"
 	pubsub "github.com/absmach/magistrala/pkg/messaging/mocks"
 	sdk "github.com/absmach/magistrala/pkg/sdk/go"
 	"github.com/absmach/magistrala/pkg/transformers/senml"
@@ -33,7 +34,7 @@ import (
 func setupMessages() (*httptest.Server, *thmocks.ThingsServiceClient, *pubsub.PubSub) {
 	things := new(thmocks.ThingsServiceClient)
 	pub := new(pubsub.PubSub)
-	handler := adapter.NewHandler(pub, mglog.NewMock(), things)
+	handler := adapter.NewHandler(pub, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, nil, nil, messaging.SigningRules{}, 0)
 
 	mux := api.MakeHandler(mglog.NewMock(), "")
 	target := httptest.NewServer(mux)
@@ -46,6 +46,9 @@ type Connection struct {
 type UsersRelationRequest struct {
 	Relation string   `json:"relation"`
 	UserIDs  []string `json:"user_ids"`
+	// Conditions are the claim conditions of the domain user assignments,
+	// such as `region=eu` or `tier=gold,silver`.
+	Conditions []string `json:"conditions,omitempty"`
 }
 
 type UserGroupsRequest struct {
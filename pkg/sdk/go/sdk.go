@@ -6,6 +6,7 @@ package sdk
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -73,18 +74,21 @@ var (
 
 type MessagePageMetadata struct {
 	PageMetadata
-	Subtopic    string  `json:"subtopic,omitempty"`
-	Publisher   string  `json:"publisher,omitempty"`
-	Comparator  string  `json:"comparator,omitempty"`
-	BoolValue   *bool   `json:"vb,omitempty"`
-	StringValue string  `json:"vs,omitempty"`
-	DataValue   string  `json:"vd,omitempty"`
-	From        float64 `json:"from,omitempty"`
-	To          float64 `json:"to,omitempty"`
-	Aggregation string  `json:"aggregation,omitempty"`
-	Interval    string  `json:"interval,omitempty"`
-	Value       float64 `json:"value,omitempty"`
-	Protocol    string  `json:"protocol,omitempty"`
+	Subtopic    string   `json:"subtopic,omitempty"`
+	Publisher   string   `json:"publisher,omitempty"`
+	Comparator  string   `json:"comparator,omitempty"`
+	BoolValue   *bool    `json:"vb,omitempty"`
+	StringValue string   `json:"vs,omitempty"`
+	DataValue   string   `json:"vd,omitempty"`
+	From        float64  `json:"from,omitempty"`
+	To          float64  `json:"to,omitempty"`
+	Aggregation string   `json:"aggregation,omitempty"`
+	Interval    string   `json:"interval,omitempty"`
+	Value       float64  `json:"value,omitempty"`
+	ValueGT     *float64 `json:"value_gt,omitempty"`
+	ValueLT     *float64 `json:"value_lt,omitempty"`
+	ValueEQ     *float64 `json:"value_eq,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"`
 }
 
 type PageMetadata struct {
@@ -122,6 +126,8 @@ type PageMetadata struct {
 	WithMetadata    bool     `json:"with_metadata,omitempty"`
 	WithAttributes  bool     `json:"with_attributes,omitempty"`
 	ID              string   `json:"id,omitempty"`
+	IDs             []string `json:"ids,omitempty"`
+	OAuthProvider   string   `json:"oauth_provider,omitempty"`
 }
 
 // Credentials represent client credentials: it contains
@@ -1201,6 +1207,7 @@ type mgSDK struct {
 	msgContentType ContentType
 	client         *http.Client
 	curlFlag       bool
+	session        *session
 }
 
 // Config contains sdk configuration parameters.
@@ -1218,6 +1225,7 @@ type Config struct {
 
 	MsgContentType  ContentType
 	TLSVerification bool
+	CACert          []byte
 	CurlFlag        bool
 }
 
@@ -1238,9 +1246,7 @@ func NewSDK(conf Config) SDK {
 		msgContentType: conf.MsgContentType,
 		client: &http.Client{
 			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: !conf.TLSVerification,
-				},
+				TLSClientConfig: tlsConfig(conf),
 			},
 		},
 		curlFlag: conf.CurlFlag,
@@ -1249,6 +1255,21 @@ func NewSDK(conf Config) SDK {
 
 // processRequest creates and send a new HTTP request, and checks for errors in the HTTP response.
 // It then returns the response headers, the response body, and the associated error(s) (if any).
+// tlsConfig verifies the server certificates against the CA certificate if
+// set, or the system CAs otherwise.
+func tlsConfig(conf Config) *tls.Config {
+	cfg := &tls.Config{
+		InsecureSkipVerify: !conf.TLSVerification,
+	}
+	if len(conf.CACert) > 0 {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(conf.CACert)
+		cfg.RootCAs = pool
+	}
+
+	return cfg
+}
+
 func (sdk mgSDK) processRequest(method, reqUrl, token string, data []byte, headers map[string]string, expectedRespCodes ...int) (http.Header, []byte, errors.SDKError) {
 	req, err := http.NewRequest(method, reqUrl, bytes.NewReader(data))
 	if err != nil {
@@ -1263,6 +1284,14 @@ func (sdk mgSDK) processRequest(method, reqUrl, token string, data []byte, heade
 		req.Header.Add(key, value)
 	}
 
+	if token == "" && sdk.session != nil {
+		t, sdkerr := sdk.session.accessToken()
+		if sdkerr != nil {
+			return make(http.Header), []byte{}, sdkerr
+		}
+		token = t
+	}
+
 	if token != "" {
 		if !strings.Contains(token, ThingPrefix) {
 			token = BearerPrefix + token
@@ -1335,6 +1364,9 @@ func (pm PageMetadata) query() (string, error) {
 	if pm.ID != "" {
 		q.Add("id", pm.ID)
 	}
+	if len(pm.IDs) > 0 {
+		q.Add("ids", strings.Join(pm.IDs, ","))
+	}
 	if pm.Type != "" {
 		q.Add("type", pm.Type)
 	}
@@ -1405,6 +1437,9 @@ func (pm PageMetadata) query() (string, error) {
 	if pm.To != 0 {
 		q.Add("to", strconv.FormatInt(pm.To, 10))
 	}
+	if pm.OAuthProvider != "" {
+		q.Add("oauth_provider", pm.OAuthProvider)
+	}
 	q.Add("with_attributes", strconv.FormatBool(pm.WithAttributes))
 	q.Add("with_metadata", strconv.FormatBool(pm.WithMetadata))
 
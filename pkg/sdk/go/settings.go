@@ -0,0 +1,287 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/pelletier/go-toml"
+)
+
+// Environment variables of the connection settings.
+const (
+	EnvConfigFile      = "MG_SDK_CONFIG_FILE"
+	EnvHostURL         = "MG_SDK_HOST_URL"
+	EnvUsersURL        = "MG_SDK_USERS_URL"
+	EnvThingsURL       = "MG_SDK_THINGS_URL"
+	EnvDomainsURL      = "MG_SDK_DOMAINS_URL"
+	EnvReaderURL       = "MG_SDK_READER_URL"
+	EnvHTTPAdapterURL  = "MG_SDK_HTTP_ADAPTER_URL"
+	EnvBootstrapURL    = "MG_SDK_BOOTSTRAP_URL"
+	EnvCertsURL        = "MG_SDK_CERTS_URL"
+	EnvInvitationsURL  = "MG_SDK_INVITATIONS_URL"
+	EnvJournalURL      = "MG_SDK_JOURNAL_URL"
+	EnvCACert          = "MG_SDK_CA_CERT"
+	EnvTLSVerification = "MG_SDK_TLS_VERIFICATION"
+	EnvToken           = "MG_SDK_TOKEN"
+	EnvRefreshToken    = "MG_SDK_REFRESH_TOKEN"
+	EnvIdentity        = "MG_SDK_IDENTITY"
+	EnvSecret          = "MG_SDK_SECRET"
+)
+
+// refreshMargin is the time before the access token expires at which it is
+// refreshed, so it doesn't expire in flight.
+const refreshMargin = 10 * time.Second
+
+var (
+	errReadSettings  = errors.New("failed to read SDK settings")
+	errInvalidCACert = errors.New("invalid CA certificate")
+)
+
+// Settings are the connection settings of the SDK. The service URLs which
+// are not set default to the host URL, as when the services are behind the
+// same proxy.
+type Settings struct {
+	HostURL        string `toml:"host_url"`
+	UsersURL       string `toml:"users_url"`
+	ThingsURL      string `toml:"things_url"`
+	DomainsURL     string `toml:"domains_url"`
+	ReaderURL      string `toml:"reader_url"`
+	HTTPAdapterURL string `toml:"http_adapter_url"`
+	BootstrapURL   string `toml:"bootstrap_url"`
+	CertsURL       string `toml:"certs_url"`
+	InvitationsURL string `toml:"invitations_url"`
+	JournalURL     string `toml:"journal_url"`
+
+	// CACert is the path of the PEM encoded CA certificate the server
+	// certificates are verified against.
+	CACert string `toml:"ca_cert"`
+	// TLSVerification disables the verification of the server certificates
+	// if set to false. The certificates are verified if it's not set.
+	TLSVerification *bool `toml:"tls_verification"`
+
+	// Token and RefreshToken authenticate the user. Once the access token
+	// expires, it is refreshed with the refresh token, or issued again from
+	// the Identity and Secret credentials if the refresh fails.
+	Token        string `toml:"token"`
+	RefreshToken string `toml:"refresh_token"`
+	Identity     string `toml:"identity"`
+	Secret       string `toml:"secret"`
+}
+
+// LoadSettings resolves the connection settings. The settings set in args
+// take precedence over the environment variables, which take precedence over
+// the TOML config file. The file is read from the path, or from the path in
+// the MG_SDK_CONFIG_FILE environment variable if the path is empty. No file
+// is read if neither is set.
+func LoadSettings(path string, args Settings) (Settings, error) {
+	var file Settings
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Settings{}, errors.Wrap(errReadSettings, err)
+		}
+		if err := toml.Unmarshal(data, &file); err != nil {
+			return Settings{}, errors.Wrap(errReadSettings, err)
+		}
+	}
+
+	env, err := envSettings()
+	if err != nil {
+		return Settings{}, err
+	}
+
+	return args.merge(env).merge(file), nil
+}
+
+// Load returns the SDK connected with the resolved settings. If the
+// settings authenticate the user, the requests made with the empty token
+// use the access token of the user, refreshed once it expires.
+func Load(path string, args Settings) (SDK, error) {
+	s, err := LoadSettings(path, args)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := s.config()
+	if s.CACert != "" {
+		ca, err := os.ReadFile(s.CACert)
+		if err != nil {
+			return nil, errors.Wrap(errInvalidCACert, err)
+		}
+		if block, _ := pem.Decode(ca); block == nil {
+			return nil, errors.Wrap(errInvalidCACert, fmt.Errorf("no PEM data in %s", s.CACert))
+		}
+		conf.CACert = ca
+	}
+
+	sdk := NewSDK(conf).(*mgSDK)
+	if s.Token != "" || s.RefreshToken != "" || (s.Identity != "" && s.Secret != "") {
+		sdk.session = &session{
+			sdk:   NewSDK(conf),
+			login: Login{Identity: s.Identity, Secret: s.Secret},
+			token: Token{AccessToken: s.Token, RefreshToken: s.RefreshToken},
+		}
+	}
+
+	return sdk, nil
+}
+
+// merge sets the settings which are not set from the fallback.
+func (s Settings) merge(fallback Settings) Settings {
+	for _, f := range []struct{ val, def *string }{
+		{&s.HostURL, &fallback.HostURL},
+		{&s.UsersURL, &fallback.UsersURL},
+		{&s.ThingsURL, &fallback.ThingsURL},
+		{&s.DomainsURL, &fallback.DomainsURL},
+		{&s.ReaderURL, &fallback.ReaderURL},
+		{&s.HTTPAdapterURL, &fallback.HTTPAdapterURL},
+		{&s.BootstrapURL, &fallback.BootstrapURL},
+		{&s.CertsURL, &fallback.CertsURL},
+		{&s.InvitationsURL, &fallback.InvitationsURL},
+		{&s.JournalURL, &fallback.JournalURL},
+		{&s.CACert, &fallback.CACert},
+		{&s.Token, &fallback.Token},
+		{&s.RefreshToken, &fallback.RefreshToken},
+		{&s.Identity, &fallback.Identity},
+		{&s.Secret, &fallback.Secret},
+	} {
+		if *f.val == "" {
+			*f.val = *f.def
+		}
+	}
+	if s.TLSVerification == nil {
+		s.TLSVerification = fallback.TLSVerification
+	}
+
+	return s
+}
+
+func (s Settings) config() Config {
+	url := func(u string) string {
+		if u == "" {
+			return s.HostURL
+		}
+		return u
+	}
+
+	// The server certificates are verified unless explicitly disabled.
+	return Config{
+		HostURL:         s.HostURL,
+		UsersURL:        url(s.UsersURL),
+		ThingsURL:       url(s.ThingsURL),
+		DomainsURL:      url(s.DomainsURL),
+		ReaderURL:       url(s.ReaderURL),
+		HTTPAdapterURL:  url(s.HTTPAdapterURL),
+		BootstrapURL:    url(s.BootstrapURL),
+		CertsURL:        url(s.CertsURL),
+		InvitationsURL:  url(s.InvitationsURL),
+		JournalURL:      url(s.JournalURL),
+		MsgContentType:  CTJSONSenML,
+		TLSVerification: s.TLSVerification == nil || *s.TLSVerification,
+	}
+}
+
+func envSettings() (Settings, error) {
+	s := Settings{
+		HostURL:        os.Getenv(EnvHostURL),
+		UsersURL:       os.Getenv(EnvUsersURL),
+		ThingsURL:      os.Getenv(EnvThingsURL),
+		DomainsURL:     os.Getenv(EnvDomainsURL),
+		ReaderURL:      os.Getenv(EnvReaderURL),
+		HTTPAdapterURL: os.Getenv(EnvHTTPAdapterURL),
+		BootstrapURL:   os.Getenv(EnvBootstrapURL),
+		CertsURL:       os.Getenv(EnvCertsURL),
+		InvitationsURL: os.Getenv(EnvInvitationsURL),
+		JournalURL:     os.Getenv(EnvJournalURL),
+		CACert:         os.Getenv(EnvCACert),
+		Token:          os.Getenv(EnvToken),
+		RefreshToken:   os.Getenv(EnvRefreshToken),
+		Identity:       os.Getenv(EnvIdentity),
+		Secret:         os.Getenv(EnvSecret),
+	}
+	if v, ok := os.LookupEnv(EnvTLSVerification); ok && v != "" {
+		verify, err := strconv.ParseBool(v)
+		if err != nil {
+			return Settings{}, errors.Wrap(errReadSettings, fmt.Errorf("%s: %w", EnvTLSVerification, err))
+		}
+		s.TLSVerification = &verify
+	}
+
+	return s, nil
+}
+
+// session keeps the access token of the user valid.
+type session struct {
+	sdk   SDK
+	login Login
+
+	mu    sync.Mutex
+	token Token
+}
+
+// accessToken returns the access token, refreshing it if it expires within
+// the refresh margin. Tokens which are not JWTs are used as they are.
+func (s *session) accessToken() (string, errors.SDKError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken != "" && !expired(s.token.AccessToken) {
+		return s.token.AccessToken, nil
+	}
+
+	var sdkerr errors.SDKError
+	if s.token.RefreshToken != "" && !expired(s.token.RefreshToken) {
+		var token Token
+		if token, sdkerr = s.sdk.RefreshToken(s.token.RefreshToken); sdkerr == nil {
+			s.token = token
+			return token.AccessToken, nil
+		}
+	}
+	if s.login.Identity != "" && s.login.Secret != "" {
+		var token Token
+		if token, sdkerr = s.sdk.CreateToken(s.login); sdkerr == nil {
+			s.token = token
+			return token.AccessToken, nil
+		}
+	}
+	if sdkerr != nil {
+		return "", sdkerr
+	}
+
+	// Nothing to refresh the token with, so the server rejects it.
+	return s.token.AccessToken, nil
+}
+
+// expired reports whether the JWT expires within the refresh margin.
+func expired(token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return false
+	}
+
+	return time.Until(time.Unix(claims.Exp, 0)) < refreshMargin
+}
@@ -0,0 +1,257 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/absmach/magistrala/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const settingsFile = `
+host_url = "https://file.example.com"
+users_url = "https://users.file.example.com"
+things_url = "https://things.file.example.com"
+tls_verification = true
+token = "file-token"
+refresh_token = "file-refresh-token"
+`
+
+func TestLoadSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdk.toml")
+	err := os.WriteFile(path, []byte(settingsFile), 0o600)
+	require.Nil(t, err, fmt.Sprintf("writing settings file expected to succeed: %s", err))
+
+	verify, noVerify := true, false
+
+	cases := []struct {
+		desc     string
+		path     string
+		env      map[string]string
+		args     sdk.Settings
+		settings sdk.Settings
+		err      bool
+	}{
+		{
+			desc: "load settings from file",
+			path: path,
+			settings: sdk.Settings{
+				HostURL:         "https://file.example.com",
+				UsersURL:        "https://users.file.example.com",
+				ThingsURL:       "https://things.file.example.com",
+				TLSVerification: &verify,
+				Token:           "file-token",
+				RefreshToken:    "file-refresh-token",
+			},
+		},
+		{
+			desc: "load settings from file in environment",
+			env:  map[string]string{sdk.EnvConfigFile: path},
+			settings: sdk.Settings{
+				HostURL:         "https://file.example.com",
+				UsersURL:        "https://users.file.example.com",
+				ThingsURL:       "https://things.file.example.com",
+				TLSVerification: &verify,
+				Token:           "file-token",
+				RefreshToken:    "file-refresh-token",
+			},
+		},
+		{
+			desc: "load settings from environment over file",
+			path: path,
+			env: map[string]string{
+				sdk.EnvUsersURL:        "https://users.env.example.com",
+				sdk.EnvTLSVerification: "false",
+				sdk.EnvToken:           "env-token",
+			},
+			settings: sdk.Settings{
+				HostURL:         "https://file.example.com",
+				UsersURL:        "https://users.env.example.com",
+				ThingsURL:       "https://things.file.example.com",
+				TLSVerification: &noVerify,
+				Token:           "env-token",
+				RefreshToken:    "file-refresh-token",
+			},
+		},
+		{
+			desc: "load settings from args over environment and file",
+			path: path,
+			env: map[string]string{
+				sdk.EnvUsersURL: "https://users.env.example.com",
+				sdk.EnvToken:    "env-token",
+			},
+			args: sdk.Settings{
+				UsersURL:        "https://users.args.example.com",
+				TLSVerification: &noVerify,
+			},
+			settings: sdk.Settings{
+				HostURL:         "https://file.example.com",
+				UsersURL:        "https://users.args.example.com",
+				ThingsURL:       "https://things.file.example.com",
+				TLSVerification: &noVerify,
+				Token:           "env-token",
+				RefreshToken:    "file-refresh-token",
+			},
+		},
+		{
+			desc:     "load settings without file",
+			args:     sdk.Settings{HostURL: "https://args.example.com"},
+			settings: sdk.Settings{HostURL: "https://args.example.com"},
+		},
+		{
+			desc: "load settings from missing file",
+			path: filepath.Join(t.TempDir(), "missing.toml"),
+			err:  true,
+		},
+		{
+			desc: "load settings with invalid TLS verification",
+			env:  map[string]string{sdk.EnvTLSVerification: "maybe"},
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			for key, val := range tc.env {
+				t.Setenv(key, val)
+			}
+			settings, err := sdk.LoadSettings(tc.path, tc.args)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %v", tc.desc, tc.err, err))
+			assert.Equal(t, tc.settings, settings, fmt.Sprintf("%s: expected settings %+v got %+v", tc.desc, tc.settings, settings))
+		})
+	}
+}
+
+// jwt returns the unsigned JWT expiring at exp.
+func jwt(t *testing.T, sub string, exp time.Time) string {
+	claims, err := json.Marshal(map[string]interface{}{"sub": sub, "exp": exp.Unix()})
+	require.Nil(t, err, fmt.Sprintf("encoding claims expected to succeed: %s", err))
+	enc := base64.RawURLEncoding
+
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString(claims) + "." + enc.EncodeToString([]byte("signature"))
+}
+
+// tokenServer refreshes the tokens and records the tokens of the requests.
+type tokenServer struct {
+	access  string
+	refresh string
+
+	mu        sync.Mutex
+	refreshes int
+	tokens    []string
+}
+
+func (ts *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/users/tokens/refresh":
+		if r.Header.Get("Authorization") != sdk.BearerPrefix+ts.refresh {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ts.refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(sdk.Token{AccessToken: ts.access, RefreshToken: ts.refresh})
+	case "/users/profile":
+		ts.tokens = append(ts.tokens, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sdk.User{ID: "user"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestLoadRefreshToken(t *testing.T) {
+	expiredToken := jwt(t, "user", time.Now().Add(-time.Minute))
+	validToken := jwt(t, "user", time.Now().Add(time.Hour))
+	refreshToken := jwt(t, "user", time.Now().Add(24*time.Hour))
+
+	cases := []struct {
+		desc      string
+		token     string
+		refreshes int
+	}{
+		{
+			desc:      "request with valid token",
+			token:     validToken,
+			refreshes: 0,
+		},
+		{
+			desc:      "request with expired token",
+			token:     expiredToken,
+			refreshes: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			srv := &tokenServer{access: validToken, refresh: refreshToken}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			mgsdk, err := sdk.Load("", sdk.Settings{HostURL: ts.URL, Token: tc.token, RefreshToken: refreshToken})
+			require.Nil(t, err, fmt.Sprintf("loading SDK expected to succeed: %s", err))
+
+			for i := 0; i < 2; i++ {
+				_, sdkerr := mgsdk.UserProfile("")
+				assert.Nil(t, sdkerr, fmt.Sprintf("%s: unexpected error %s", tc.desc, sdkerr))
+			}
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			assert.Equal(t, tc.refreshes, srv.refreshes, fmt.Sprintf("%s: expected %d refreshes got %d", tc.desc, tc.refreshes, srv.refreshes))
+			for _, token := range srv.tokens {
+				assert.Equal(t, sdk.BearerPrefix+validToken, token, fmt.Sprintf("%s: expected request with valid token", tc.desc))
+			}
+		})
+	}
+}
+
+func TestLoadTLSVerification(t *testing.T) {
+	noVerify := false
+
+	cases := []struct {
+		desc     string
+		settings sdk.Settings
+		err      bool
+	}{
+		{
+			desc:     "request to server with self-signed certificate by default",
+			settings: sdk.Settings{},
+			err:      true,
+		},
+		{
+			desc:     "request to server with self-signed certificate without verification",
+			settings: sdk.Settings{TLSVerification: &noVerify},
+			err:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewTLSServer(&tokenServer{})
+			defer ts.Close()
+
+			tc.settings.HostURL = ts.URL
+			mgsdk, err := sdk.Load("", tc.settings)
+			require.Nil(t, err, fmt.Sprintf("loading SDK expected to succeed: %s", err))
+			_, sdkerr := mgsdk.UserProfile("token")
+			assert.Equal(t, tc.err, sdkerr != nil, fmt.Sprintf("%s: expected error %t got %v", tc.desc, tc.err, sdkerr))
+		})
+	}
+}
@@ -205,6 +205,7 @@ func convertInvitation(i sdk.Invitation) invitations.Invitation {
 	return invitations.Invitation{
 		InvitedBy:   i.InvitedBy,
 		UserID:      i.UserID,
+		Email:       i.Email,
 		DomainID:    i.DomainID,
 		Token:       i.Token,
 		Relation:    i.Relation,
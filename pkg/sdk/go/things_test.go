@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	internalapi "github.com/absmach/magistrala/internal/api"
 	"github.com/absmach/magistrala/internal/testsutil"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/apiutil"
@@ -20,7 +21,9 @@ import (
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	gmocks "github.com/absmach/magistrala/pkg/groups/mocks"
+	jobsmocks "github.com/absmach/magistrala/pkg/jobs/mocks"
 	policies "github.com/absmach/magistrala/pkg/policies"
+	qmocks "github.com/absmach/magistrala/pkg/quotas/mocks"
 	sdk "github.com/absmach/magistrala/pkg/sdk/go"
 	api "github.com/absmach/magistrala/things/api/http"
 	"github.com/absmach/magistrala/things/mocks"
@@ -36,7 +39,7 @@ func setupThings() (*httptest.Server, *mocks.Service, *authnmocks.Authentication
 	logger := mglog.NewMock()
 	mux := chi.NewRouter()
 	authn := new(authnmocks.Authentication)
-	api.MakeHandler(tsvc, gsvc, authn, mux, logger, "")
+	api.MakeHandler(tsvc, gsvc, new(jobsmocks.Service), new(qmocks.Service), authn, mux, logger, "", internalapi.PageLimits{})
 
 	return httptest.NewServer(mux), tsvc, authn
 }
@@ -22,6 +22,8 @@ type Token struct {
 type Login struct {
 	Identity string `json:"identity"`
 	Secret   string `json:"secret"`
+	MFACode  string `json:"mfa_code,omitempty"`
+	Audience string `json:"audience,omitempty"`
 }
 
 func (sdk mgSDK) CreateToken(lt Login) (Token, errors.SDKError) {
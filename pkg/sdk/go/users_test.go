@@ -24,7 +24,9 @@ import (
 	"github.com/absmach/magistrala/pkg/groups"
 	gmocks "github.com/absmach/magistrala/pkg/groups/mocks"
 	oauth2mocks "github.com/absmach/magistrala/pkg/oauth2/mocks"
+	"github.com/absmach/magistrala/pkg/passwords"
 	policies "github.com/absmach/magistrala/pkg/policies"
+	qmocks "github.com/absmach/magistrala/pkg/quotas/mocks"
 	sdk "github.com/absmach/magistrala/pkg/sdk/go"
 	"github.com/absmach/magistrala/users/api"
 	umocks "github.com/absmach/magistrala/users/mocks"
@@ -47,7 +49,7 @@ func setupUsers() (*httptest.Server, *umocks.Service, *authnmocks.Authentication
 	provider.On("Name").Return("test")
 	authn := new(authnmocks.Authentication)
 	token := new(authmocks.TokenServiceClient)
-	api.MakeHandler(usvc, authn, token, true, gsvc, mux, logger, "", passRegex, provider)
+	api.MakeHandler(usvc, authn, token, true, gsvc, new(qmocks.Service), mux, logger, "", passRegex, internalapi.PageLimits{}, passwords.NewEvaluator(passRegex, 0), nil, nil, nil, nil, provider)
 
 	return httptest.NewServer(mux), usvc, authn
 }
@@ -13,6 +13,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/absmach/magistrala/pkg/requestid"
 	"github.com/absmach/magistrala/pkg/server"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
@@ -46,6 +47,7 @@ func (s *grpcServer) Start() error {
 	errCh := make(chan error)
 	grpcServerOptions := []grpc.ServerOption{
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(requestid.UnaryServerInterceptor()),
 	}
 
 	listener, err := net.Listen("tcp", s.Address)
@@ -60,10 +62,12 @@ func (s *grpcServer) Start() error {
 		if err != nil {
 			return fmt.Errorf("failed to load auth gRPC client certificates: %w", err)
 		}
-		tlsConfig := &tls.Config{
-			ClientAuth:   tls.RequireAndVerifyClientCert,
-			Certificates: []tls.Certificate{certificate},
+		tlsConfig, err := s.Config.TLSPolicy.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
 		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		tlsConfig.Certificates = []tls.Certificate{certificate}
 
 		var mtlsCA string
 		// Loading Server CA file
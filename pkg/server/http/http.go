@@ -5,9 +5,12 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/absmach/magistrala/pkg/server"
 )
@@ -40,7 +43,29 @@ func (s *httpServer) Start() error {
 	switch {
 	case s.Config.CertFile != "" || s.Config.KeyFile != "":
 		s.Protocol = httpsProtocol
-		s.Logger.Info(fmt.Sprintf("%s service %s server listening at %s with TLS cert %s and key %s", s.Name, s.Protocol, s.Address, s.Config.CertFile, s.Config.KeyFile))
+		tlsConfig, err := s.Config.TLSPolicy.TLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		s.server.TLSConfig = tlsConfig
+		clientCA, err := loadCertFile(s.Config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client ca file: %w", err)
+		}
+		switch {
+		case len(clientCA) > 0:
+			// Client certificates are optional so that requests can still
+			// be authenticated by bearer tokens.
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM(clientCA) {
+				return fmt.Errorf("failed to append client ca to tls.Config")
+			}
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			tlsConfig.ClientCAs = clientCAs
+			s.Logger.Info(fmt.Sprintf("%s service %s server listening at %s with TLS/mTLS cert %s, key %s and client ca %s", s.Name, s.Protocol, s.Address, s.Config.CertFile, s.Config.KeyFile, s.Config.ClientCAFile))
+		default:
+			s.Logger.Info(fmt.Sprintf("%s service %s server listening at %s with TLS cert %s and key %s", s.Name, s.Protocol, s.Address, s.Config.CertFile, s.Config.KeyFile))
+		}
 		go func() {
 			errCh <- s.server.ListenAndServeTLS(s.Config.CertFile, s.Config.KeyFile)
 		}()
@@ -69,3 +94,10 @@ func (s *httpServer) Stop() error {
 	s.Logger.Info(fmt.Sprintf("%s %s service shutdown of http at %s", s.Name, s.Protocol, s.Address))
 	return nil
 }
+
+func loadCertFile(certFile string) ([]byte, error) {
+	if certFile != "" {
+		return os.ReadFile(certFile)
+	}
+	return []byte{}, nil
+}
@@ -30,6 +30,24 @@ type Config struct {
 	KeyFile      string `env:"SERVER_KEY"      envDefault:""`
 	ServerCAFile string `env:"SERVER_CA_CERTS" envDefault:""`
 	ClientCAFile string `env:"CLIENT_CA_CERTS" envDefault:""`
+	TLSPolicy
+}
+
+// StopFunc adapts the function to the Server stopped along with the other
+// servers, such as the draining of the in-flight work before the servers
+// stop. Starting it is a no-op.
+type StopFunc func() error
+
+var _ Server = (StopFunc)(nil)
+
+// Start implements Server.
+func (f StopFunc) Start() error {
+	return nil
+}
+
+// Stop calls the function.
+func (f StopFunc) Stop() error {
+	return f()
 }
 
 type BaseServer struct {
@@ -70,11 +88,12 @@ func stopAllServer(servers ...Server) error {
 	return err
 }
 
-// StopSignalHandler stops the server when a signal is received.
+// StopSignalHandler stops the servers, in the given order, when a signal is
+// received.
 func StopSignalHandler(ctx context.Context, cancel context.CancelFunc, logger *slog.Logger, svcName string, servers ...Server) error {
 	var err error
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGABRT)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGABRT)
 	select {
 	case sig := <-c:
 		defer cancel()
@@ -0,0 +1,104 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrTLSVersion indicates the unsupported TLS minimum version.
+	ErrTLSVersion = errors.New("unsupported TLS minimum version")
+
+	// ErrTLSCipherSuite indicates the unknown or insecure TLS cipher suite.
+	ErrTLSCipherSuite = errors.New("unknown or insecure TLS cipher suite")
+
+	// ErrTLSPolicy indicates the invalid combination of the TLS minimum
+	// version and cipher suites.
+	ErrTLSPolicy = errors.New("invalid TLS policy")
+)
+
+const defTLSMinVersion = "1.2"
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// defCipherSuites are the TLS 1.2 cipher suites accepted by default: the
+// ECDHE key exchanges with AEAD encryption.
+var defCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// TLSPolicy is the TLS versions and cipher suites the servers accept. The
+// cipher suites are the Go names of the TLS 1.2 suites, TLS 1.3 suites are
+// not configurable.
+type TLSPolicy struct {
+	MinVersion   string   `env:"TLS_MIN_VERSION"   envDefault:"1.2"`
+	CipherSuites []string `env:"TLS_CIPHER_SUITES" envDefault:""`
+}
+
+// TLSConfig returns the TLS configuration of the servers accepting the
+// policy, or the error if the policy is invalid. The empty minimum version
+// is TLS 1.2.
+func (p TLSPolicy) TLSConfig() (*tls.Config, error) {
+	if p.MinVersion == "" {
+		p.MinVersion = defTLSMinVersion
+	}
+	version, ok := tlsVersions[p.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of 1.0, 1.1, 1.2 or 1.3", ErrTLSVersion, p.MinVersion)
+	}
+	cfg := &tls.Config{MinVersion: version}
+	if len(p.CipherSuites) == 0 {
+		// The older versions keep the Go default suites, since the
+		// default ones are only supported by TLS 1.2.
+		if version == tls.VersionTLS12 {
+			cfg.CipherSuites = defCipherSuites
+		}
+		return cfg, nil
+	}
+	if version == tls.VersionTLS13 {
+		return nil, fmt.Errorf("%w: cipher suites are not configurable with TLS minimum version 1.3", ErrTLSPolicy)
+	}
+
+	supported := false
+	for _, name := range p.CipherSuites {
+		suite := cipherSuite(name)
+		if suite == nil || !slices.Contains(suite.SupportedVersions, tls.VersionTLS12) {
+			return nil, fmt.Errorf("%w %q", ErrTLSCipherSuite, name)
+		}
+		if slices.Contains(suite.SupportedVersions, version) {
+			supported = true
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, suite.ID)
+	}
+	if !supported {
+		return nil, fmt.Errorf("%w: none of the cipher suites supports TLS minimum version %s", ErrTLSPolicy, p.MinVersion)
+	}
+
+	return cfg, nil
+}
+
+// cipherSuite returns the secure cipher suite with the name, or nil.
+func cipherSuite(name string) *tls.CipherSuite {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/server"
+	"github.com/caarlos0/env/v11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSPolicyDefault(t *testing.T) {
+	cfg := server.Config{}
+	err := env.ParseWithOptions(&cfg, env.Options{Prefix: "MG_TEST_", Environment: map[string]string{}})
+	require.Nil(t, err, fmt.Sprintf("parse config: unexpected error %s", err))
+
+	tlsConfig, err := cfg.TLSConfig()
+	require.Nil(t, err, fmt.Sprintf("default policy: unexpected error %s", err))
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion, "expected TLS 1.2 minimum version by default")
+	assert.NotEmpty(t, tlsConfig.CipherSuites, "expected the modern cipher suites by default")
+}
+
+func TestTLSPolicyInvalid(t *testing.T) {
+	cases := []struct {
+		desc   string
+		policy server.TLSPolicy
+		err    error
+	}{
+		{
+			desc:   "unsupported minimum version",
+			policy: server.TLSPolicy{MinVersion: "1.4"},
+			err:    server.ErrTLSVersion,
+		},
+		{
+			desc:   "malformed minimum version",
+			policy: server.TLSPolicy{MinVersion: "TLSv1.2"},
+			err:    server.ErrTLSVersion,
+		},
+		{
+			desc:   "unknown cipher suite",
+			policy: server.TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_UNKNOWN"}},
+			err:    server.ErrTLSCipherSuite,
+		},
+		{
+			desc:   "insecure cipher suite",
+			policy: server.TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			err:    server.ErrTLSCipherSuite,
+		},
+		{
+			desc:   "TLS 1.3 cipher suite",
+			policy: server.TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_AES_128_GCM_SHA256"}},
+			err:    server.ErrTLSCipherSuite,
+		},
+		{
+			desc:   "cipher suites with minimum version 1.3",
+			policy: server.TLSPolicy{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}},
+			err:    server.ErrTLSPolicy,
+		},
+		{
+			desc:   "cipher suites unsupported by the minimum version",
+			policy: server.TLSPolicy{MinVersion: "1.0", CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}},
+			err:    server.ErrTLSPolicy,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := tc.policy.TLSConfig()
+			assert.True(t, errors.Is(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		})
+	}
+}
+
+func TestTLSPolicyHandshake(t *testing.T) {
+	cert := newCertificate(t)
+
+	cases := []struct {
+		desc    string
+		policy  server.TLSPolicy
+		version uint16
+		suites  []uint16
+		refused bool
+	}{
+		{
+			desc:    "handshake with TLS 1.1",
+			policy:  server.TLSPolicy{MinVersion: "1.2"},
+			version: tls.VersionTLS11,
+			refused: true,
+		},
+		{
+			desc:    "handshake with TLS 1.2 and a modern cipher suite",
+			policy:  server.TLSPolicy{MinVersion: "1.2"},
+			version: tls.VersionTLS12,
+			suites:  []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		},
+		{
+			desc:    "handshake with TLS 1.2 and a legacy cipher suite",
+			policy:  server.TLSPolicy{MinVersion: "1.2"},
+			version: tls.VersionTLS12,
+			suites:  []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA},
+			refused: true,
+		},
+		{
+			desc:    "handshake with TLS 1.3",
+			policy:  server.TLSPolicy{MinVersion: "1.2"},
+			version: tls.VersionTLS13,
+		},
+		{
+			desc:    "handshake with TLS 1.2 and the configured cipher suite",
+			policy:  server.TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}},
+			version: tls.VersionTLS12,
+			suites:  []uint16{tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256},
+		},
+		{
+			desc:    "handshake with TLS 1.2 and not configured cipher suite",
+			policy:  server.TLSPolicy{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"}},
+			version: tls.VersionTLS12,
+			suites:  []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			refused: true,
+		},
+		{
+			desc:    "handshake with TLS 1.2 and minimum version 1.3",
+			policy:  server.TLSPolicy{MinVersion: "1.3"},
+			version: tls.VersionTLS12,
+			refused: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tlsConfig, err := tc.policy.TLSConfig()
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			tlsConfig.Certificates = []tls.Certificate{cert}
+			l, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			defer l.Close()
+			go func() {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+			}()
+
+			conn, err := tls.Dial("tcp", l.Addr().String(), &tls.Config{
+				InsecureSkipVerify: true,
+				MinVersion:         tc.version,
+				MaxVersion:         tc.version,
+				CipherSuites:       tc.suites,
+			})
+			switch tc.refused {
+			case true:
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected the handshake to be refused", tc.desc))
+			default:
+				require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.version, conn.ConnectionState().Version, fmt.Sprintf("%s: unexpected version", tc.desc))
+				conn.Close()
+			}
+		})
+	}
+}
+
+func newCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err, fmt.Sprintf("generate key: unexpected error %s", err))
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.Nil(t, err, fmt.Sprintf("create certificate: unexpected error %s", err))
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
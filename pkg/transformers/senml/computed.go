@@ -0,0 +1,133 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// ComputedErrorKey is the metadata key of the reason the computed record has
+// no value, such as a missing input or a division by zero.
+const ComputedErrorKey = "computed_error"
+
+var errInvalidComputed = errors.New("invalid computed field")
+
+// ComputedField computes the record Name in the Unit from the Expression over
+// the values of the records of the message taken at the same time. If
+// Channel is set, only the messages of the channel are computed.
+type ComputedField struct {
+	Channel    string `toml:"channel"`
+	Name       string `toml:"name"`
+	Unit       string `toml:"unit"`
+	Expression string `toml:"expression"`
+}
+
+// Computed is the list of the computed fields. The empty list computes
+// nothing.
+type Computed []ComputedField
+
+// Validate returns an error if a field is unnamed or its expression is invalid.
+func (c Computed) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+// compiledField is the computed field with the parsed expression.
+type compiledField struct {
+	ComputedField
+	expr   expression
+	inputs map[string]struct{}
+}
+
+func (c Computed) compile() ([]compiledField, error) {
+	fields := make([]compiledField, 0, len(c))
+	for _, cf := range c {
+		if cf.Name == "" {
+			return nil, errors.Wrap(errInvalidComputed, fmt.Errorf("missing name of the field computed as %q", cf.Expression))
+		}
+		expr, inputs, err := parseExpression(cf.Expression)
+		if err != nil {
+			return nil, errors.Wrap(errInvalidComputed, fmt.Errorf("field %s: %w", cf.Name, err))
+		}
+		in := make(map[string]struct{}, len(inputs))
+		for _, name := range inputs {
+			in[name] = struct{}{}
+		}
+		fields = append(fields, compiledField{ComputedField: cf, expr: expr, inputs: in})
+	}
+
+	return fields, nil
+}
+
+// compute returns the computed records of the message. A field is computed
+// at each time any of its inputs is taken. If an input is missing at that
+// time, or the expression fails, the computed record has no value and the
+// reason is kept in its metadata.
+func compute(fields []compiledField, msgs []Message) []Message {
+	if len(fields) == 0 || len(msgs) == 0 {
+		return nil
+	}
+
+	var times []float64
+	values := map[float64]map[string]float64{}
+	for _, msg := range msgs {
+		if _, ok := values[msg.Time]; !ok {
+			times = append(times, msg.Time)
+			values[msg.Time] = map[string]float64{}
+		}
+		if msg.Value != nil {
+			values[msg.Time][msg.Name] = *msg.Value
+		}
+	}
+
+	var computed []Message
+	for _, f := range fields {
+		if f.Channel != "" && f.Channel != msgs[0].Channel {
+			continue
+		}
+		for _, t := range times {
+			if !f.takes(msgs, t) {
+				continue
+			}
+			rec := Message{
+				Channel:   msgs[0].Channel,
+				Subtopic:  msgs[0].Subtopic,
+				Publisher: msgs[0].Publisher,
+				Protocol:  msgs[0].Protocol,
+				Name:      f.Name,
+				Unit:      f.Unit,
+				Time:      t,
+			}
+			v, err := f.expr.eval(values[t])
+			if err == nil && (math.IsNaN(v) || math.IsInf(v, 0)) {
+				err = errNotFinite
+			}
+			if err != nil {
+				rec.Metadata = map[string]interface{}{ComputedErrorKey: err.Error()}
+			} else {
+				rec.Value = &v
+			}
+			computed = append(computed, rec)
+		}
+	}
+
+	return computed
+}
+
+// takes reports whether any input of the field is taken at the time.
+func (f compiledField) takes(msgs []Message, t float64) bool {
+	for _, msg := range msgs {
+		if msg.Time != t {
+			continue
+		}
+		if _, ok := f.inputs[msg.Name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
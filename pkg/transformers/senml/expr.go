@@ -0,0 +1,368 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	// maxExpressionLen and maxExpressionDepth bound the size of the parsed
+	// expressions, so a configured expression can't exhaust the consumer.
+	maxExpressionLen   = 1024
+	maxExpressionDepth = 32
+)
+
+var (
+	errParseExpression = errors.New("failed to parse expression")
+	errMissingInput    = errors.New("missing input")
+	errDivisionByZero  = errors.New("division by zero")
+	errNotFinite       = errors.New("result is not a finite number")
+)
+
+// The expressions are arithmetic over the values of the records, referenced
+// by their names. Names which are not identifiers are quoted, as in
+// "urn:dev:ow:10e2073a01080063:temp". The expressions support the +, -, *, /,
+// % and ^ operators, the parentheses and the functions below. There are no
+// variables, loops or calls other than the functions, so the evaluation of
+// an expression is bounded by its size.
+var functions = map[string]struct {
+	arity int
+	fn    func(args []float64) (float64, error)
+}{
+	"abs":   {1, func(a []float64) (float64, error) { return math.Abs(a[0]), nil }},
+	"ceil":  {1, func(a []float64) (float64, error) { return math.Ceil(a[0]), nil }},
+	"exp":   {1, func(a []float64) (float64, error) { return math.Exp(a[0]), nil }},
+	"floor": {1, func(a []float64) (float64, error) { return math.Floor(a[0]), nil }},
+	"log":   {1, func(a []float64) (float64, error) { return math.Log(a[0]), nil }},
+	"max":   {2, func(a []float64) (float64, error) { return math.Max(a[0], a[1]), nil }},
+	"min":   {2, func(a []float64) (float64, error) { return math.Min(a[0], a[1]), nil }},
+	"pow":   {2, func(a []float64) (float64, error) { return math.Pow(a[0], a[1]), nil }},
+	"round": {1, func(a []float64) (float64, error) { return math.Round(a[0]), nil }},
+	"sqrt":  {1, func(a []float64) (float64, error) { return math.Sqrt(a[0]), nil }},
+}
+
+// expression is the parsed expression evaluated over the record values.
+type expression interface {
+	eval(values map[string]float64) (float64, error)
+}
+
+type number float64
+
+func (n number) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type input string
+
+func (in input) eval(values map[string]float64) (float64, error) {
+	v, ok := values[string(in)]
+	if !ok {
+		return 0, errors.Wrap(errMissingInput, fmt.Errorf("%s", string(in)))
+	}
+
+	return v, nil
+}
+
+type negation struct {
+	operand expression
+}
+
+func (n negation) eval(values map[string]float64) (float64, error) {
+	v, err := n.operand.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	return -v, nil
+}
+
+type binary struct {
+	op          byte
+	left, right expression
+}
+
+func (b binary) eval(values map[string]float64) (float64, error) {
+	l, err := b.left.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	r, err := b.right.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		if r == 0 {
+			return 0, errDivisionByZero
+		}
+		return l / r, nil
+	case '%':
+		if r == 0 {
+			return 0, errDivisionByZero
+		}
+		return math.Mod(l, r), nil
+	default:
+		return math.Pow(l, r), nil
+	}
+}
+
+type call struct {
+	name string
+	args []expression
+}
+
+func (c call) eval(values map[string]float64) (float64, error) {
+	args := make([]float64, len(c.args))
+	for i, arg := range c.args {
+		v, err := arg.eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+
+	return functions[c.name].fn(args)
+}
+
+// parseExpression parses the expression, returning the names of the records
+// it takes as inputs.
+func parseExpression(s string) (expression, []string, error) {
+	if len(s) > maxExpressionLen {
+		return nil, nil, errors.Wrap(errParseExpression, fmt.Errorf("expression longer than %d characters", maxExpressionLen))
+	}
+	p := &parser{src: s, inputs: map[string]struct{}{}}
+	expr, err := p.parseSum()
+	if err == nil {
+		p.skipSpace()
+		if p.pos < len(p.src) {
+			err = fmt.Errorf("unexpected %q at %d", p.src[p.pos], p.pos)
+		}
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(errParseExpression, err)
+	}
+	inputs := make([]string, 0, len(p.inputs))
+	for name := range p.inputs {
+		inputs = append(inputs, name)
+	}
+
+	return expr, inputs, nil
+}
+
+// parser is the recursive descent parser of the expressions.
+type parser struct {
+	src    string
+	pos    int
+	depth  int
+	inputs map[string]struct{}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// next returns the next character after the spaces, or 0 at the end.
+func (p *parser) next() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+
+	return p.src[p.pos]
+}
+
+func (p *parser) enter() error {
+	if p.depth++; p.depth > maxExpressionDepth {
+		return fmt.Errorf("expression nested deeper than %d", maxExpressionDepth)
+	}
+
+	return nil
+}
+
+func (p *parser) parseSum() (expression, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.next()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseProduct() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.next()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	if p.next() == '-' {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negation{operand: operand}, nil
+	}
+
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.next() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exp, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return binary{op: '^', left: base, right: exp}, nil
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	c := p.next()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		expr, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ')' {
+			return nil, fmt.Errorf("missing ) at %d", p.pos)
+		}
+		p.pos++
+		return expr, nil
+	case c == '"':
+		end := strings.IndexByte(p.src[p.pos+1:], '"')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated name at %d", p.pos)
+		}
+		name := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		p.inputs[name] = struct{}{}
+		return input(name), nil
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || (p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+			p.pos++
+		}
+		// The exponent of the numbers, as in 1e-3.
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+				p.pos++
+			}
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return number(v), nil
+	case isIdentStart(c):
+		start := p.pos
+		for p.pos < len(p.src) && isIdentPart(p.src[p.pos]) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if p.next() != '(' {
+			p.inputs[name] = struct{}{}
+			return input(name), nil
+		}
+		return p.parseCall(name)
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", c, p.pos)
+	}
+}
+
+func (p *parser) parseCall(name string) (expression, error) {
+	f, ok := functions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	p.pos++
+	var args []expression
+	for p.next() != ')' {
+		if len(args) > 0 {
+			if p.next() != ',' {
+				return nil, fmt.Errorf("missing , at %d", p.pos)
+			}
+			p.pos++
+		}
+		arg, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.pos++
+	if len(args) != f.arity {
+		return nil, fmt.Errorf("function %s takes %d arguments, got %d", name, f.arity, len(args))
+	}
+
+	return call{name: name, args: args}, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+const (
+	// LatitudeUnit is the SenML unit of the records holding the latitude in degrees.
+	LatitudeUnit = "lat"
+	// LongitudeUnit is the SenML unit of the records holding the longitude in degrees.
+	LongitudeUnit = "lon"
+)
+
+// GeoConfig configures the extraction of the record geolocation.
+// The zero value disables the extraction.
+type GeoConfig struct {
+	// Enabled sets the latitude and longitude of the records from the
+	// records in the LatitudeUnit and LongitudeUnit units of the same message
+	// taken at the same time.
+	Enabled bool `toml:"enabled"`
+}
+
+// locate sets the geolocation of the records. Records are located only if
+// both the latitude and the longitude taken at their time are valid.
+func (gc GeoConfig) locate(msgs []Message) {
+	if !gc.Enabled {
+		return
+	}
+
+	lats := map[float64]float64{}
+	lons := map[float64]float64{}
+	for _, msg := range msgs {
+		if msg.Value == nil {
+			continue
+		}
+		switch msg.Unit {
+		case LatitudeUnit:
+			if *msg.Value >= -90 && *msg.Value <= 90 {
+				lats[msg.Time] = *msg.Value
+			}
+		case LongitudeUnit:
+			if *msg.Value >= -180 && *msg.Value <= 180 {
+				lons[msg.Time] = *msg.Value
+			}
+		}
+	}
+
+	for i := range msgs {
+		lat, ok := lats[msgs[i].Time]
+		if !ok {
+			continue
+		}
+		lon, ok := lons[msgs[i].Time]
+		if !ok {
+			continue
+		}
+		msgs[i].Latitude = &lat
+		msgs[i].Longitude = &lon
+	}
+}
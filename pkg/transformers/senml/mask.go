@@ -0,0 +1,186 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/senml"
+)
+
+const (
+	// MaskDrop drops the masked records.
+	MaskDrop = "drop"
+	// MaskHash replaces the value of the masked records with its keyed hash.
+	MaskHash = "hash"
+)
+
+var (
+	errInvalidMask = errors.New("invalid field mask")
+	errParseMasks  = errors.New("failed to parse field masks")
+)
+
+// Mask redacts the records with the Name, which is the record name resolved
+// against the base name, of the messages of the Channel, or of all the
+// channels if Channel is empty. The drop action removes the records. The hash
+// action replaces the value of the records with the hex encoded HMAC-SHA256
+// of the value with the Key as the string value, so the records stay
+// comparable without exposing the value. The Key is required by the hash
+// action, since the plain hash of the low entropy values is easily reversed.
+type Mask struct {
+	Channel string `toml:"channel" json:"channel,omitempty"`
+	Name    string `toml:"name"    json:"name"`
+	Action  string `toml:"action"  json:"action"`
+	Key     string `toml:"key"     json:"key,omitempty"`
+}
+
+// Masks is the list of the field masks. The first mask matching a record is
+// applied. The empty list masks nothing.
+type Masks []Mask
+
+// ParseMasks parses the JSON encoded field masks. The empty string is the
+// empty list.
+func ParseMasks(data string) (Masks, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var masks Masks
+	if err := json.Unmarshal([]byte(data), &masks); err != nil {
+		return nil, errors.Wrap(errParseMasks, err)
+	}
+	if err := masks.Validate(); err != nil {
+		return nil, err
+	}
+
+	return masks, nil
+}
+
+// Validate returns an error if a mask is unnamed, its action is unknown, or
+// the hash action is missing the key.
+func (m Masks) Validate() error {
+	for _, mask := range m {
+		if mask.Name == "" {
+			return errors.Wrap(errInvalidMask, fmt.Errorf("missing name of the record masked with %q", mask.Action))
+		}
+		if mask.Action != MaskDrop && mask.Action != MaskHash {
+			return errors.Wrap(errInvalidMask, fmt.Errorf("record %s: unknown action %q, expected %q or %q", mask.Name, mask.Action, MaskDrop, MaskHash))
+		}
+		if mask.Action == MaskHash && mask.Key == "" {
+			return errors.Wrap(errInvalidMask, fmt.Errorf("record %s: missing key of the %q action", mask.Name, MaskHash))
+		}
+	}
+
+	return nil
+}
+
+// Apply returns the normalized records of the message of the channel with
+// the masked records dropped or hashed, and whether any record was masked.
+func (m Masks) Apply(channel string, records []senml.Record) ([]senml.Record, bool) {
+	if len(m) == 0 {
+		return records, false
+	}
+	masked := false
+	ret := make([]senml.Record, 0, len(records))
+	for _, r := range records {
+		mask, ok := m.match(channel, r.Name)
+		if !ok {
+			ret = append(ret, r)
+			continue
+		}
+		masked = true
+		if mask.Action == MaskHash {
+			ret = append(ret, mask.hash(r))
+		}
+	}
+
+	return ret, masked
+}
+
+// MaskPayload returns the SenML payload in the content type of the message
+// of the channel with the masked records dropped or hashed, and whether any
+// record was masked. The payload is returned unchanged if no record is
+// masked, and re-encoded normalized otherwise. The payload of the channel
+// without masks is returned unchanged without being decoded, while the error
+// is returned if the payload of the masked channel can't be decoded.
+func (m Masks) MaskPayload(contentType, channel string, payload []byte) ([]byte, bool, error) {
+	if !m.covers(channel) {
+		return payload, false, nil
+	}
+	format, ok := formats[contentType]
+	if !ok {
+		format = formats[JSON]
+	}
+	raw, err := senml.Decode(payload, format)
+	if err != nil {
+		return nil, false, errors.Wrap(errDecode, err)
+	}
+	normalized, err := senml.Normalize(raw)
+	if err != nil {
+		return nil, false, errors.Wrap(errNormalize, err)
+	}
+	records, masked := m.Apply(channel, normalized.Records)
+	if !masked {
+		return payload, false, nil
+	}
+	payload, err = senml.Encode(senml.Pack{Records: records}, format)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return payload, true, nil
+}
+
+// covers reports whether any mask applies to the messages of the channel.
+func (m Masks) covers(channel string) bool {
+	for _, mask := range m {
+		if mask.Channel == "" || mask.Channel == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m Masks) match(channel, name string) (Mask, bool) {
+	for _, mask := range m {
+		if mask.Name == name && (mask.Channel == "" || mask.Channel == channel) {
+			return mask, true
+		}
+	}
+
+	return Mask{}, false
+}
+
+// hash returns the record with its value replaced by the keyed hash of the
+// value.
+func (mask Mask) hash(r senml.Record) senml.Record {
+	var value string
+	switch {
+	case r.Value != nil:
+		value = strconv.FormatFloat(*r.Value, 'g', -1, 64)
+	case r.StringValue != nil:
+		value = *r.StringValue
+	case r.DataValue != nil:
+		value = *r.DataValue
+	case r.BoolValue != nil:
+		value = strconv.FormatBool(*r.BoolValue)
+	case r.Sum != nil:
+		value = strconv.FormatFloat(*r.Sum, 'g', -1, 64)
+	}
+
+	h := hmac.New(sha256.New, []byte(mask.Key))
+	h.Write([]byte(value))
+	hashed := hex.EncodeToString(h.Sum(nil))
+
+	r.Value, r.DataValue, r.BoolValue, r.Sum = nil, nil, nil, nil
+	r.StringValue = &hashed
+
+	return r
+}
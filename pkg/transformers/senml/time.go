@@ -0,0 +1,107 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/transformers"
+	"github.com/absmach/senml"
+)
+
+const (
+	// TimeModeReject rejects messages with records timestamped outside of the allowed window.
+	TimeModeReject = "reject"
+	// TimeModeClamp moves timestamps outside of the allowed window to the closest window bound.
+	TimeModeClamp = "clamp"
+)
+
+var (
+	// ErrTimeOutOfRange indicates that the record time is outside of the allowed window.
+	ErrTimeOutOfRange = errors.New("record time is outside of the allowed window")
+
+	errInvalidTimeMode = errors.New("invalid time validation mode")
+)
+
+// TimeConfig configures validation of the record time against the server time.
+// The zero value disables the validation.
+type TimeConfig struct {
+	// Mode is either TimeModeReject or TimeModeClamp. Empty mode disables the validation.
+	Mode string `toml:"mode"`
+	// MaxPast is the maximum age of the record time, 0 for unlimited.
+	MaxPast time.Duration `toml:"max_past"`
+	// MaxFuture is the maximum amount the record time can be ahead of the server time, 0 for unlimited.
+	MaxFuture time.Duration `toml:"max_future"`
+}
+
+// Validate returns an error if the time validation mode is not supported.
+func (tc TimeConfig) Validate() error {
+	switch tc.Mode {
+	case "", TimeModeReject, TimeModeClamp:
+		return nil
+	default:
+		return errors.Wrap(errInvalidTimeMode, fmt.Errorf("mode %q", tc.Mode))
+	}
+}
+
+// check validates the record time in nanoseconds against the server time.
+// It returns the time to store, which differs from t only if it's clamped.
+func (tc TimeConfig) check(t float64, now time.Time) (float64, error) {
+	// Times below 2**28 are relative to the current time.
+	if tc.Mode == "" || t < maxRelativeTime {
+		return t, nil
+	}
+
+	var bound float64
+	switch {
+	case tc.MaxPast > 0 && t < float64(now.Add(-tc.MaxPast).UnixNano()):
+		bound = float64(now.Add(-tc.MaxPast).UnixNano())
+	case tc.MaxFuture > 0 && t > float64(now.Add(tc.MaxFuture).UnixNano()):
+		bound = float64(now.Add(tc.MaxFuture).UnixNano())
+	default:
+		return t, nil
+	}
+
+	if tc.Mode == TimeModeReject {
+		return 0, ErrTimeOutOfRange
+	}
+
+	return bound, nil
+}
+
+// CheckPayload returns ErrTimeOutOfRange if the time of any record of the
+// SenML payload in the content type is outside of the allowed window. It's
+// used to reject the messages on publish, so only the reject mode fails.
+// Records without time, which are timestamped on reception, and payloads
+// which are not SenML are accepted.
+func (tc TimeConfig) CheckPayload(contentType string, payload []byte, now time.Time) error {
+	if tc.Mode != TimeModeReject {
+		return nil
+	}
+	format, ok := formats[contentType]
+	if !ok {
+		format = formats[JSON]
+	}
+	raw, err := senml.Decode(payload, format)
+	if err != nil {
+		return nil
+	}
+	normalized, err := senml.Normalize(raw)
+	if err != nil {
+		return nil
+	}
+	for _, r := range normalized.Records {
+		tm := r.Time
+		if tm >= maxRelativeTime {
+			tm = transformers.ToUnixNano(tm)
+		}
+		if _, err := tc.check(tm, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
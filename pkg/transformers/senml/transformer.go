@@ -4,6 +4,8 @@
 package senml
 
 import (
+	"time"
+
 	"github.com/absmach/magistrala/pkg/errors"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/transformers"
@@ -30,18 +32,37 @@ var formats = map[string]senml.Format{
 }
 
 type transformer struct {
-	format senml.Format
+	format   senml.Format
+	time     TimeConfig
+	units    Units
+	geo      GeoConfig
+	computed []compiledField
+	masks    Masks
 }
 
 // New returns transformer service implementation for SenML messages.
-func New(contentFormat string) transformers.Transformer {
+// Record times are validated against the server time as configured by timeCfg,
+// the masked records are dropped or hashed, record values are converted to
+// the units of the units table, the computed records are appended, and
+// records are located as configured by geoCfg. The computed fields with
+// invalid expressions are ignored, so they should be validated first.
+func New(contentFormat string, timeCfg TimeConfig, units Units, geoCfg GeoConfig, computed Computed, masks Masks) transformers.Transformer {
 	format, ok := formats[contentFormat]
 	if !ok {
 		format = formats[JSON]
 	}
+	fields, err := computed.compile()
+	if err != nil {
+		fields = nil
+	}
 
 	return transformer{
-		format: format,
+		format:   format,
+		time:     timeCfg,
+		units:    units,
+		geo:      geoCfg,
+		computed: fields,
+		masks:    masks,
 	}
 }
 
@@ -56,23 +77,33 @@ func (t transformer) Transform(msg *messaging.Message) (interface{}, error) {
 		return nil, errors.Wrap(errNormalize, err)
 	}
 
-	msgs := make([]Message, len(normalized.Records))
-	for i, v := range normalized.Records {
+	// Masked records are redacted before anything is derived from them, so
+	// their values don't leak into the computed or located records.
+	records, _ := t.masks.Apply(msg.GetChannel(), normalized.Records)
+
+	now := time.Now()
+	msgs := make([]Message, len(records))
+	for i, v := range records {
 		// Use reception timestamp if SenML messsage Time is missing
-		t := v.Time
-		if t == 0 {
-			t = float64(msg.GetCreated())
+		tm := v.Time
+		if tm == 0 {
+			tm = float64(msg.GetCreated())
 		}
 
 		// If time is below 2**28 it is relative to the current time
 		// https://datatracker.ietf.org/doc/html/rfc8428#section-4.5.3
-		if t >= maxRelativeTime {
-			t = transformers.ToUnixNano(t)
+		if tm >= maxRelativeTime {
+			tm = transformers.ToUnixNano(tm)
 		}
 		if v.UpdateTime >= maxRelativeTime {
 			v.UpdateTime = transformers.ToUnixNano(v.UpdateTime)
 		}
 
+		checked, err := t.time.check(tm, now)
+		if err != nil {
+			return nil, err
+		}
+
 		msgs[i] = Message{
 			Channel:     msg.GetChannel(),
 			Subtopic:    msg.GetSubtopic(),
@@ -80,7 +111,7 @@ func (t transformer) Transform(msg *messaging.Message) (interface{}, error) {
 			Protocol:    msg.GetProtocol(),
 			Name:        v.Name,
 			Unit:        v.Unit,
-			Time:        t,
+			Time:        tm,
 			UpdateTime:  v.UpdateTime,
 			Value:       v.Value,
 			BoolValue:   v.BoolValue,
@@ -88,7 +119,14 @@ func (t transformer) Transform(msg *messaging.Message) (interface{}, error) {
 			StringValue: v.StringValue,
 			Sum:         v.Sum,
 		}
+		if checked != tm {
+			msgs[i].Time = checked
+			msgs[i].OriginalTime = tm
+		}
+		t.units.Convert(&msgs[i])
 	}
+	msgs = append(msgs, compute(t.computed, msgs)...)
+	t.geo.locate(msgs)
 
 	return msgs, nil
 }
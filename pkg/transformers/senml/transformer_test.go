@@ -4,9 +4,13 @@
 package senml_test
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/absmach/magistrala/pkg/errors"
 	"github.com/absmach/magistrala/pkg/messaging"
@@ -22,7 +26,7 @@ func TestTransformJSON(t *testing.T) {
 	jsonBytes, err := hex.DecodeString("5b7b22626e223a22626173652d6e616d65222c226274223a3130302c226275223a22626173652d756e6974222c2262766572223a31302c226276223a31302c226273223a3130302c226e223a226e616d65222c2275223a22756e6974222c2274223a3330302c227574223a3135302c2276223a34322c2273223a31307d5d")
 	assert.Nil(t, err, "Decoding JSON expected to succeed")
 
-	tr := senml.New(senml.JSON)
+	tr := senml.New(senml.JSON, senml.TimeConfig{}, nil, senml.GeoConfig{}, nil, nil)
 	msg := &messaging.Message{
 		Channel:   "channel",
 		Subtopic:  "subtopic",
@@ -88,7 +92,7 @@ func TestTransformCBOR(t *testing.T) {
 	tooManyBytes, err := hex.DecodeString("82AD2169626173652D6E616D6522F956402369626173652D756E6974200A24F9490025F9564000646E616D650164756E697406F95CB0036331323307F958B002F9514005F94900AA2169626173652D6E616D6522F956402369626173652D756E6974200A24F9490025F9564000646E616D6506F95CB007F958B005F94900")
 	assert.Nil(t, err, "Decoding CBOR expected to succeed")
 
-	tr := senml.New(senml.CBOR)
+	tr := senml.New(senml.CBOR, senml.TimeConfig{}, nil, senml.GeoConfig{}, nil, nil)
 
 	cborPld := &messaging.Message{
 		Channel:   "channel",
@@ -149,3 +153,660 @@ func TestTransformCBOR(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+func TestTransformTime(t *testing.T) {
+	maxPast := 24 * time.Hour
+	maxFuture := time.Minute
+	now := time.Now()
+	// Base time is in seconds, record time is relative to the base time.
+	payload := func(bt time.Time) []byte {
+		return []byte(fmt.Sprintf(`[{"bn":"base-name","bt":%d,"n":"name","t":1,"v":42}]`, bt.Unix()))
+	}
+	recTime := func(bt time.Time) float64 {
+		return float64((bt.Unix() + 1) * int64(time.Second))
+	}
+	inWindow := now.Add(-time.Hour)
+	past := now.AddDate(-3, 0, 0)
+	future := now.AddDate(3, 0, 0)
+
+	cases := []struct {
+		desc     string
+		cfg      senml.TimeConfig
+		bt       time.Time
+		time     float64
+		min      float64
+		max      float64
+		original bool
+		err      error
+	}{
+		{
+			desc: "transform time in window",
+			cfg:  senml.TimeConfig{Mode: senml.TimeModeReject, MaxPast: maxPast, MaxFuture: maxFuture},
+			bt:   inWindow,
+			time: recTime(inWindow),
+		},
+		{
+			desc: "transform past time with validation disabled",
+			cfg:  senml.TimeConfig{MaxPast: maxPast, MaxFuture: maxFuture},
+			bt:   past,
+			time: recTime(past),
+		},
+		{
+			desc: "transform past time without past limit",
+			cfg:  senml.TimeConfig{Mode: senml.TimeModeReject, MaxFuture: maxFuture},
+			bt:   past,
+			time: recTime(past),
+		},
+		{
+			desc: "reject past time",
+			cfg:  senml.TimeConfig{Mode: senml.TimeModeReject, MaxPast: maxPast, MaxFuture: maxFuture},
+			bt:   past,
+			err:  senml.ErrTimeOutOfRange,
+		},
+		{
+			desc: "reject future time",
+			cfg:  senml.TimeConfig{Mode: senml.TimeModeReject, MaxPast: maxPast, MaxFuture: maxFuture},
+			bt:   future,
+			err:  senml.ErrTimeOutOfRange,
+		},
+		{
+			desc:     "clamp past time",
+			cfg:      senml.TimeConfig{Mode: senml.TimeModeClamp, MaxPast: maxPast, MaxFuture: maxFuture},
+			bt:       past,
+			time:     recTime(past),
+			min:      float64(now.Add(-maxPast).UnixNano()),
+			max:      float64(time.Now().Add(time.Minute - maxPast).UnixNano()),
+			original: true,
+		},
+		{
+			desc:     "clamp future time",
+			cfg:      senml.TimeConfig{Mode: senml.TimeModeClamp, MaxPast: maxPast, MaxFuture: maxFuture},
+			bt:       future,
+			time:     recTime(future),
+			min:      float64(now.Add(maxFuture).UnixNano()),
+			max:      float64(time.Now().Add(time.Minute + maxFuture).UnixNano()),
+			original: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr := senml.New(senml.JSON, tc.cfg, nil, senml.GeoConfig{}, nil, nil)
+			res, err := tr.Transform(&messaging.Message{Channel: "channel", Payload: payload(tc.bt)})
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s, got %s", tc.desc, tc.err, err))
+			if tc.err != nil {
+				return
+			}
+			msgs := res.([]senml.Message)
+			assert.Len(t, msgs, 1)
+			switch tc.original {
+			case true:
+				assert.GreaterOrEqual(t, msgs[0].Time, tc.min, fmt.Sprintf("%s: expected time to be clamped to window bound", tc.desc))
+				assert.LessOrEqual(t, msgs[0].Time, tc.max, fmt.Sprintf("%s: expected time to be clamped to window bound", tc.desc))
+				assert.Equal(t, tc.time, msgs[0].OriginalTime, fmt.Sprintf("%s: expected original time %f, got %f", tc.desc, tc.time, msgs[0].OriginalTime))
+			default:
+				assert.Equal(t, tc.time, msgs[0].Time, fmt.Sprintf("%s: expected time %f, got %f", tc.desc, tc.time, msgs[0].Time))
+				assert.Zero(t, msgs[0].OriginalTime, fmt.Sprintf("%s: unexpected original time %f", tc.desc, msgs[0].OriginalTime))
+			}
+		})
+	}
+}
+
+func TestCheckPayload(t *testing.T) {
+	now := time.Now()
+	reject := senml.TimeConfig{Mode: senml.TimeModeReject, MaxPast: 24 * time.Hour, MaxFuture: time.Minute}
+	payload := func(bt time.Time) []byte {
+		return []byte(fmt.Sprintf(`[{"bn":"base-name","bt":%d,"n":"name","v":42},{"n":"other","t":1,"v":24}]`, bt.Unix()))
+	}
+
+	cases := []struct {
+		desc    string
+		cfg     senml.TimeConfig
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "check payload in window",
+			cfg:     reject,
+			payload: payload(now.Add(-time.Hour)),
+		},
+		{
+			desc:    "check payload without record time",
+			cfg:     reject,
+			payload: []byte(`[{"n":"name","v":42}]`),
+		},
+		{
+			desc:    "check past payload",
+			cfg:     reject,
+			payload: payload(now.AddDate(-3, 0, 0)),
+			err:     senml.ErrTimeOutOfRange,
+		},
+		{
+			desc:    "check future payload",
+			cfg:     reject,
+			payload: payload(now.AddDate(3, 0, 0)),
+			err:     senml.ErrTimeOutOfRange,
+		},
+		{
+			desc:    "check past payload in clamp mode",
+			cfg:     senml.TimeConfig{Mode: senml.TimeModeClamp, MaxPast: reject.MaxPast},
+			payload: payload(now.AddDate(-3, 0, 0)),
+		},
+		{
+			desc:    "check payload which is not SenML",
+			cfg:     reject,
+			payload: []byte(`{"temperature":42}`),
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.cfg.CheckPayload(senml.JSON, tc.payload, now)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s, got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestTimeConfigValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		mode string
+		err  bool
+	}{
+		{desc: "validate disabled mode", mode: ""},
+		{desc: "validate reject mode", mode: senml.TimeModeReject},
+		{desc: "validate clamp mode", mode: senml.TimeModeClamp},
+		{desc: "validate invalid mode", mode: "drop", err: true},
+	}
+
+	for _, tc := range cases {
+		err := senml.TimeConfig{Mode: tc.mode}.Validate()
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+	}
+}
+
+func TestTransformUnits(t *testing.T) {
+	units := senml.Units{
+		{Name: "room-pressure", From: "Pa", To: "hPa"},
+		{From: "Cel", To: "degF"},
+		{Name: "room-level", From: "m", To: "cm", Scale: 100},
+	}
+	payload := `[{"bn":"room-","n":"%s","u":"%s","v":%v}]`
+
+	cases := []struct {
+		desc     string
+		name     string
+		unit     string
+		value    float64
+		expUnit  string
+		expValue float64
+		metadata map[string]interface{}
+	}{
+		{
+			desc:     "convert Celsius to Fahrenheit",
+			name:     "temperature",
+			unit:     "Cel",
+			value:    25,
+			expUnit:  "degF",
+			expValue: 77,
+			metadata: map[string]interface{}{senml.OriginalValueKey: 25.0, senml.OriginalUnitKey: "Cel"},
+		},
+		{
+			desc:     "convert named record",
+			name:     "pressure",
+			unit:     "Pa",
+			value:    101325,
+			expUnit:  "hPa",
+			expValue: 1013.25,
+			metadata: map[string]interface{}{senml.OriginalValueKey: 101325.0, senml.OriginalUnitKey: "Pa"},
+		},
+		{
+			desc:     "convert with custom scale",
+			name:     "level",
+			unit:     "m",
+			value:    1.5,
+			expUnit:  "cm",
+			expValue: 150,
+			metadata: map[string]interface{}{senml.OriginalValueKey: 1.5, senml.OriginalUnitKey: "m"},
+		},
+		{
+			desc:     "leave unrelated unit untouched",
+			name:     "humidity",
+			unit:     "%RH",
+			value:    40,
+			expUnit:  "%RH",
+			expValue: 40,
+		},
+		{
+			desc:     "leave unit of other name untouched",
+			name:     "height",
+			unit:     "m",
+			value:    1.5,
+			expUnit:  "m",
+			expValue: 1.5,
+		},
+	}
+
+	tr := senml.New(senml.JSON, senml.TimeConfig{}, units, senml.GeoConfig{}, nil, nil)
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := tr.Transform(&messaging.Message{Channel: "channel", Payload: []byte(fmt.Sprintf(payload, tc.name, tc.unit, tc.value))})
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			msgs := res.([]senml.Message)
+			assert.Len(t, msgs, 1)
+			assert.Equal(t, tc.expUnit, msgs[0].Unit, fmt.Sprintf("%s: expected unit %s, got %s", tc.desc, tc.expUnit, msgs[0].Unit))
+			assert.InDelta(t, tc.expValue, *msgs[0].Value, 1e-9, fmt.Sprintf("%s: expected value %f, got %f", tc.desc, tc.expValue, *msgs[0].Value))
+			assert.Equal(t, tc.metadata, msgs[0].Metadata, fmt.Sprintf("%s: expected metadata %v, got %v", tc.desc, tc.metadata, msgs[0].Metadata))
+		})
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	cases := []struct {
+		desc  string
+		data  string
+		units senml.Units
+		err   bool
+	}{
+		{desc: "parse empty table", data: ""},
+		{
+			desc:  "parse known conversion",
+			data:  `[{"name":"temperature","from":"Cel","to":"degF"}]`,
+			units: senml.Units{{Name: "temperature", From: "Cel", To: "degF"}},
+		},
+		{
+			desc:  "parse custom conversion",
+			data:  `[{"from":"m","to":"cm","scale":100}]`,
+			units: senml.Units{{From: "m", To: "cm", Scale: 100}},
+		},
+		{desc: "parse unknown conversion", data: `[{"from":"Cel","to":"m"}]`, err: true},
+		{desc: "parse negative scale", data: `[{"from":"m","to":"cm","scale":-100}]`, err: true},
+		{desc: "parse malformed table", data: `{"from":"Cel"}`, err: true},
+	}
+
+	for _, tc := range cases {
+		units, err := senml.ParseUnits(tc.data)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t, got %s", tc.desc, tc.err, err))
+		if !tc.err {
+			assert.Equal(t, tc.units, units, fmt.Sprintf("%s: expected %v, got %v", tc.desc, tc.units, units))
+		}
+	}
+}
+
+func TestRevertUnits(t *testing.T) {
+	cases := []struct {
+		desc  string
+		units senml.Units
+		name  string
+		value float64
+		exp   float64
+		ok    bool
+	}{
+		{
+			desc:  "revert named conversion",
+			units: senml.Units{{Name: "pressure", From: "Pa", To: "hPa"}, {Name: "temperature", From: "Cel", To: "degF"}},
+			name:  "temperature",
+			value: 77,
+			exp:   25,
+			ok:    true,
+		},
+		{
+			desc:  "revert only unnamed conversion",
+			units: senml.Units{{From: "Cel", To: "degF"}},
+			value: 32,
+			exp:   0,
+			ok:    true,
+		},
+		{
+			desc:  "keep value of ambiguous conversions",
+			units: senml.Units{{From: "Cel", To: "degF"}, {From: "Pa", To: "hPa"}},
+			name:  "temperature",
+			value: 77,
+			exp:   77,
+		},
+		{
+			desc:  "keep value of other name",
+			units: senml.Units{{Name: "pressure", From: "Pa", To: "hPa"}, {Name: "level", From: "m", To: "cm", Scale: 100}},
+			name:  "temperature",
+			value: 77,
+			exp:   77,
+		},
+	}
+
+	for _, tc := range cases {
+		v, ok := tc.units.Revert(tc.name, tc.value)
+		assert.Equal(t, tc.ok, ok, fmt.Sprintf("%s: expected found %t, got %t", tc.desc, tc.ok, ok))
+		assert.InDelta(t, tc.exp, v, 1e-9, fmt.Sprintf("%s: expected value %f, got %f", tc.desc, tc.exp, v))
+	}
+}
+
+func TestConvertScale(t *testing.T) {
+	units := senml.Units{{From: "Cel", To: "degF"}}
+	value := 50.0
+	msg := senml.Message{Name: "temperature", Unit: "Cel", Value: &value}
+
+	assert.True(t, units.ConvertScale(&msg), "expected message to be converted")
+	assert.Equal(t, "degF", msg.Unit, fmt.Sprintf("expected unit degF, got %s", msg.Unit))
+	assert.InDelta(t, 90.0, *msg.Value, 1e-9, fmt.Sprintf("expected value 90, got %f", *msg.Value))
+}
+
+func TestTransformGeo(t *testing.T) {
+	cases := []struct {
+		desc    string
+		cfg     senml.GeoConfig
+		payload string
+		lat     []float64
+		lon     []float64
+	}{
+		{
+			desc:    "locate records taken with the geolocation",
+			cfg:     senml.GeoConfig{Enabled: true},
+			payload: `[{"bn":"truck-","bt":1700000000,"n":"temperature","u":"Cel","v":21},{"n":"latitude","u":"lat","v":44.8},{"n":"longitude","u":"lon","v":20.4}]`,
+			lat:     []float64{44.8, 44.8, 44.8},
+			lon:     []float64{20.4, 20.4, 20.4},
+		},
+		{
+			desc:    "leave records taken at other times unlocated",
+			cfg:     senml.GeoConfig{Enabled: true},
+			payload: `[{"bn":"truck-","bt":1700000000,"n":"temperature","u":"Cel","v":21,"t":-5},{"n":"latitude","u":"lat","v":44.8},{"n":"longitude","u":"lon","v":20.4}]`,
+			lat:     []float64{0, 44.8, 44.8},
+			lon:     []float64{0, 20.4, 20.4},
+		},
+		{
+			desc:    "leave records with invalid geolocation unlocated",
+			cfg:     senml.GeoConfig{Enabled: true},
+			payload: `[{"bn":"truck-","bt":1700000000,"n":"temperature","u":"Cel","v":21},{"n":"latitude","u":"lat","v":95},{"n":"longitude","u":"lon","v":20.4}]`,
+			lat:     []float64{0, 0, 0},
+			lon:     []float64{0, 0, 0},
+		},
+		{
+			desc:    "leave records unlocated with extraction disabled",
+			payload: `[{"bn":"truck-","bt":1700000000,"n":"temperature","u":"Cel","v":21},{"n":"latitude","u":"lat","v":44.8},{"n":"longitude","u":"lon","v":20.4}]`,
+			lat:     []float64{0, 0, 0},
+			lon:     []float64{0, 0, 0},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr := senml.New(senml.JSON, senml.TimeConfig{}, nil, tc.cfg, nil, nil)
+			res, err := tr.Transform(&messaging.Message{Channel: "channel", Payload: []byte(tc.payload)})
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			msgs := res.([]senml.Message)
+			assert.Len(t, msgs, len(tc.lat))
+			for i, msg := range msgs {
+				switch tc.lat[i] {
+				case 0:
+					assert.Nil(t, msg.Latitude, fmt.Sprintf("%s: expected record %d to be unlocated", tc.desc, i))
+					assert.Nil(t, msg.Longitude, fmt.Sprintf("%s: expected record %d to be unlocated", tc.desc, i))
+				default:
+					assert.Equal(t, tc.lat[i], *msg.Latitude, fmt.Sprintf("%s: expected latitude %f got %f", tc.desc, tc.lat[i], *msg.Latitude))
+					assert.Equal(t, tc.lon[i], *msg.Longitude, fmt.Sprintf("%s: expected longitude %f got %f", tc.desc, tc.lon[i], *msg.Longitude))
+				}
+			}
+		})
+	}
+}
+
+func TestTransformComputed(t *testing.T) {
+	heatIndex := senml.ComputedField{
+		Name:       "heat_index",
+		Unit:       "Cel",
+		Expression: `-8.78469475556 + 1.61139411 * "room-temperature" + 2.33854883889 * "room-humidity" - 0.14611605 * "room-temperature" * "room-humidity"`,
+	}
+	temp, hum := 30.0, 60.0
+	expected := -8.78469475556 + 1.61139411*temp + 2.33854883889*hum - 0.14611605*temp*hum
+
+	cases := []struct {
+		desc     string
+		computed senml.Computed
+		payload  string
+		value    *float64
+		err      string
+		count    int
+	}{
+		{
+			desc:     "compute derived value",
+			computed: senml.Computed{heatIndex},
+			payload:  `[{"bn":"room-","bt":1700000000,"n":"temperature","u":"Cel","v":30},{"n":"humidity","u":"%RH","v":60}]`,
+			value:    &expected,
+			count:    3,
+		},
+		{
+			desc:     "compute derived value with missing input",
+			computed: senml.Computed{heatIndex},
+			payload:  `[{"bn":"room-","bt":1700000000,"n":"temperature","u":"Cel","v":30}]`,
+			err:      "missing input",
+			count:    2,
+		},
+		{
+			desc:     "compute derived value with division by zero",
+			computed: senml.Computed{{Name: "ratio", Expression: `"room-temperature" / "room-humidity"`}},
+			payload:  `[{"bn":"room-","bt":1700000000,"n":"temperature","u":"Cel","v":30},{"n":"humidity","u":"%RH","v":0}]`,
+			err:      "division by zero",
+			count:    3,
+		},
+		{
+			desc:     "compute derived value with non finite result",
+			computed: senml.Computed{{Name: "log", Expression: `log("room-humidity")`}},
+			payload:  `[{"bn":"room-","bt":1700000000,"n":"humidity","u":"%RH","v":0}]`,
+			err:      "not a finite number",
+			count:    2,
+		},
+		{
+			desc:     "compute derived value of other channel",
+			computed: senml.Computed{{Channel: "other", Name: "heat_index", Expression: heatIndex.Expression}},
+			payload:  `[{"bn":"room-","bt":1700000000,"n":"temperature","u":"Cel","v":30},{"n":"humidity","u":"%RH","v":60}]`,
+			count:    2,
+		},
+		{
+			desc:     "compute derived value without inputs",
+			computed: senml.Computed{heatIndex},
+			payload:  `[{"bn":"door-","bt":1700000000,"n":"open","vb":true}]`,
+			count:    1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.computed.Validate()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected validation error %s", tc.desc, err))
+			tr := senml.New(senml.JSON, senml.TimeConfig{}, nil, senml.GeoConfig{}, tc.computed, nil)
+			res, err := tr.Transform(&messaging.Message{Channel: "channel", Publisher: "publisher", Payload: []byte(tc.payload)})
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			msgs := res.([]senml.Message)
+			assert.Len(t, msgs, tc.count, fmt.Sprintf("%s: expected %d records got %d", tc.desc, tc.count, len(msgs)))
+			if tc.value == nil && tc.err == "" {
+				return
+			}
+			rec := msgs[len(msgs)-1]
+			assert.Equal(t, tc.computed[0].Name, rec.Name, fmt.Sprintf("%s: expected computed record %s got %s", tc.desc, tc.computed[0].Name, rec.Name))
+			assert.Equal(t, "channel", rec.Channel, fmt.Sprintf("%s: expected computed record of the message channel", tc.desc))
+			assert.Equal(t, msgs[0].Time, rec.Time, fmt.Sprintf("%s: expected computed record at the time of its inputs", tc.desc))
+			switch tc.value {
+			case nil:
+				assert.Nil(t, rec.Value, fmt.Sprintf("%s: expected computed record without value", tc.desc))
+				assert.Contains(t, rec.Metadata[senml.ComputedErrorKey], tc.err, fmt.Sprintf("%s: expected computed error %s got %v", tc.desc, tc.err, rec.Metadata))
+			default:
+				assert.InDelta(t, *tc.value, *rec.Value, 1e-9, fmt.Sprintf("%s: expected value %f got %f", tc.desc, *tc.value, *rec.Value))
+			}
+		})
+	}
+}
+
+func TestComputedValidate(t *testing.T) {
+	cases := []struct {
+		desc     string
+		computed senml.Computed
+		valid    bool
+	}{
+		{
+			desc:     "validate arithmetic expression",
+			computed: senml.Computed{{Name: "dew_point", Expression: "temperature - (100 - humidity) / 5"}},
+			valid:    true,
+		},
+		{
+			desc:     "validate expression with functions",
+			computed: senml.Computed{{Name: "magnitude", Expression: "sqrt(pow(x, 2) + y ^ 2 + max(z, 0) % 3) * 1e-3"}},
+			valid:    true,
+		},
+		{
+			desc:     "validate expression with unknown function",
+			computed: senml.Computed{{Name: "f", Expression: "system(x)"}},
+		},
+		{
+			desc:     "validate expression with wrong arity",
+			computed: senml.Computed{{Name: "f", Expression: "min(x)"}},
+		},
+		{
+			desc:     "validate malformed expression",
+			computed: senml.Computed{{Name: "f", Expression: "(x + 1"}},
+		},
+		{
+			desc:     "validate empty expression",
+			computed: senml.Computed{{Name: "f"}},
+		},
+		{
+			desc:     "validate unnamed field",
+			computed: senml.Computed{{Expression: "x + 1"}},
+		},
+		{
+			desc:     "validate deeply nested expression",
+			computed: senml.Computed{{Name: "f", Expression: strings.Repeat("(", 100) + "x" + strings.Repeat(")", 100)}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.computed.Validate()
+			assert.Equal(t, tc.valid, err == nil, fmt.Sprintf("%s: expected valid %t got error %v", tc.desc, tc.valid, err))
+		})
+	}
+}
+
+func TestTransformMask(t *testing.T) {
+	payload := `[{"bn":"patient-","bt":1700000000,"n":"name","vs":"John Doe"},{"n":"pulse","u":"beat/min","v":72},{"n":"temperature","u":"Cel","v":36.6}]`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("72"))
+	pulseHash := hex.EncodeToString(mac.Sum(nil))
+	mac = hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("John Doe"))
+	nameHMAC := hex.EncodeToString(mac.Sum(nil))
+
+	cases := []struct {
+		desc     string
+		masks    senml.Masks
+		computed senml.Computed
+		expected map[string]string
+	}{
+		{
+			desc:     "transform without masks",
+			expected: map[string]string{"patient-name": "John Doe", "patient-pulse": "", "patient-temperature": ""},
+		},
+		{
+			desc:     "transform with dropped record",
+			masks:    senml.Masks{{Name: "patient-name", Action: senml.MaskDrop}},
+			expected: map[string]string{"patient-pulse": "", "patient-temperature": ""},
+		},
+		{
+			desc:     "transform with hashed record",
+			masks:    senml.Masks{{Name: "patient-pulse", Action: senml.MaskHash, Key: "secret"}},
+			expected: map[string]string{"patient-name": "John Doe", "patient-pulse": pulseHash, "patient-temperature": ""},
+		},
+		{
+			desc:     "transform with string record hashed",
+			masks:    senml.Masks{{Name: "patient-name", Action: senml.MaskHash, Key: "secret"}},
+			expected: map[string]string{"patient-name": nameHMAC, "patient-pulse": "", "patient-temperature": ""},
+		},
+		{
+			desc:     "transform with record masked in the channel",
+			masks:    senml.Masks{{Channel: "channel", Name: "patient-name", Action: senml.MaskDrop}},
+			expected: map[string]string{"patient-pulse": "", "patient-temperature": ""},
+		},
+		{
+			desc:     "transform with record masked in other channel",
+			masks:    senml.Masks{{Channel: "other", Name: "patient-name", Action: senml.MaskDrop}},
+			expected: map[string]string{"patient-name": "John Doe", "patient-pulse": "", "patient-temperature": ""},
+		},
+		{
+			desc:     "transform with masked record input of computed record",
+			masks:    senml.Masks{{Name: "patient-pulse", Action: senml.MaskHash, Key: "secret"}},
+			computed: senml.Computed{{Name: "double_pulse", Expression: `"patient-pulse" * 2`}},
+			expected: map[string]string{"patient-name": "John Doe", "patient-pulse": pulseHash, "patient-temperature": "", "double_pulse": ""},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tr := senml.New(senml.JSON, senml.TimeConfig{}, nil, senml.GeoConfig{}, tc.computed, tc.masks)
+			res, err := tr.Transform(&messaging.Message{Channel: "channel", Publisher: "publisher", Payload: []byte(payload)})
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			msgs := res.([]senml.Message)
+			assert.Len(t, msgs, len(tc.expected), fmt.Sprintf("%s: expected %d records got %d", tc.desc, len(tc.expected), len(msgs)))
+			for _, msg := range msgs {
+				value, ok := tc.expected[msg.Name]
+				assert.True(t, ok, fmt.Sprintf("%s: unexpected record %s", tc.desc, msg.Name))
+				if value == "" {
+					continue
+				}
+				assert.NotNil(t, msg.StringValue, fmt.Sprintf("%s: expected string value of record %s", tc.desc, msg.Name))
+				assert.Nil(t, msg.Value, fmt.Sprintf("%s: expected no value of record %s", tc.desc, msg.Name))
+				if msg.StringValue != nil {
+					assert.Equal(t, value, *msg.StringValue, fmt.Sprintf("%s: expected value %s of record %s got %s", tc.desc, value, msg.Name, *msg.StringValue))
+				}
+			}
+			if tc.computed != nil {
+				rec := msgs[len(msgs)-1]
+				assert.Nil(t, rec.Value, fmt.Sprintf("%s: expected computed record without value", tc.desc))
+				assert.Contains(t, rec.Metadata[senml.ComputedErrorKey], "missing input", fmt.Sprintf("%s: expected computed error got %v", tc.desc, rec.Metadata))
+			}
+		})
+	}
+}
+
+func TestParseMasks(t *testing.T) {
+	cases := []struct {
+		desc  string
+		data  string
+		masks senml.Masks
+		valid bool
+	}{
+		{
+			desc:  "parse empty masks",
+			valid: true,
+		},
+		{
+			desc:  "parse masks",
+			data:  `[{"channel":"channel","name":"patient-name","action":"drop"},{"name":"patient-pulse","action":"hash","key":"secret"}]`,
+			masks: senml.Masks{{Channel: "channel", Name: "patient-name", Action: senml.MaskDrop}, {Name: "patient-pulse", Action: senml.MaskHash, Key: "secret"}},
+			valid: true,
+		},
+		{
+			desc: "parse malformed masks",
+			data: `[{"name":"patient-name"`,
+		},
+		{
+			desc: "parse unnamed mask",
+			data: `[{"action":"drop"}]`,
+		},
+		{
+			desc: "parse mask with unknown action",
+			data: `[{"name":"patient-name","action":"redact"}]`,
+		},
+		{
+			desc: "parse hash mask without key",
+			data: `[{"name":"patient-name","action":"hash"}]`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			masks, err := senml.ParseMasks(tc.data)
+			switch tc.valid {
+			case true:
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.masks, masks, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.masks, masks))
+			default:
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected error", tc.desc))
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	// OriginalValueKey is the metadata key of the record value before the unit conversion.
+	OriginalValueKey = "original_value"
+	// OriginalUnitKey is the metadata key of the record unit before the unit conversion.
+	OriginalUnitKey = "original_unit"
+)
+
+var (
+	errUnknownConversion = errors.New("unknown unit conversion")
+	errParseUnits        = errors.New("failed to parse unit conversions")
+	errNegativeScale     = errors.New("unit conversion scale must be positive")
+)
+
+// linear converts a value to another unit as value*scale + offset.
+type linear struct {
+	scale  float64
+	offset float64
+}
+
+// conversions holds the known conversions between the SenML units.
+var conversions = map[[2]string]linear{
+	{"Cel", "degF"}: {scale: 1.8, offset: 32},
+	{"degF", "Cel"}: {scale: 5.0 / 9, offset: -160.0 / 9},
+	{"Cel", "K"}:    {scale: 1, offset: 273.15},
+	{"K", "Cel"}:    {scale: 1, offset: -273.15},
+	{"K", "degF"}:   {scale: 1.8, offset: -459.67},
+	{"degF", "K"}:   {scale: 5.0 / 9, offset: 459.67 * 5 / 9},
+	{"m", "km"}:     {scale: 0.001},
+	{"km", "m"}:     {scale: 1000},
+	{"m/s", "km/h"}: {scale: 3.6},
+	{"km/h", "m/s"}: {scale: 1 / 3.6},
+	{"Pa", "hPa"}:   {scale: 0.01},
+	{"hPa", "Pa"}:   {scale: 100},
+	{"Pa", "kPa"}:   {scale: 0.001},
+	{"kPa", "Pa"}:   {scale: 1000},
+	{"W", "kW"}:     {scale: 0.001},
+	{"kW", "W"}:     {scale: 1000},
+	{"Wh", "kWh"}:   {scale: 0.001},
+	{"kWh", "Wh"}:   {scale: 1000},
+	{"s", "ms"}:     {scale: 1000},
+	{"ms", "s"}:     {scale: 0.001},
+}
+
+// UnitConversion converts the values of the records in the unit From to the
+// unit To. If Name is set, only the records with that name are converted.
+// The known conversions between the SenML units are used, unless Scale is
+// set, in which case the values are converted as value*Scale + Offset.
+type UnitConversion struct {
+	Name   string  `toml:"name"   json:"name,omitempty"`
+	From   string  `toml:"from"   json:"from"`
+	To     string  `toml:"to"     json:"to"`
+	Scale  float64 `toml:"scale"  json:"scale,omitempty"`
+	Offset float64 `toml:"offset" json:"offset,omitempty"`
+}
+
+// Units is the unit conversion table. The first conversion matching a record
+// is applied, so the conversions of the named records should precede the
+// conversions of their units. The empty table converts nothing.
+type Units []UnitConversion
+
+// ParseUnits parses the JSON encoded unit conversion table. The empty string
+// is the empty table.
+func ParseUnits(data string) (Units, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var units Units
+	if err := json.Unmarshal([]byte(data), &units); err != nil {
+		return nil, errors.Wrap(errParseUnits, err)
+	}
+
+	return units, units.Validate()
+}
+
+// Validate returns an error if a conversion is neither known nor defined by
+// its scale, or if its scale is negative, which would reverse the value order.
+func (u Units) Validate() error {
+	for _, uc := range u {
+		if uc.Scale < 0 {
+			return errors.Wrap(errNegativeScale, fmt.Errorf("from %q to %q", uc.From, uc.To))
+		}
+		if _, err := uc.linear(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Convert converts the value of the record with the first matching conversion,
+// keeping the original value and unit in the metadata. It reports whether the
+// record is converted. Records in the units without a conversion pass
+// through unchanged, as do the sums which can't be converted with an offset.
+func (u Units) Convert(msg *Message) bool {
+	return u.convert(msg, false)
+}
+
+// ConvertScale converts the record like Convert, applying only the scale of
+// the conversion. It converts the sums of the values, since their offsets
+// don't add up to the offset of the conversion.
+func (u Units) ConvertScale(msg *Message) bool {
+	return u.convert(msg, true)
+}
+
+// Revert converts the value v of the records named name from the unit they are
+// converted to back to their stored unit, as needed to filter the stored
+// records by the converted values. The first conversion of the name is used,
+// or the only conversion of the table if it isn't named. It reports whether a
+// conversion is found.
+func (u Units) Revert(name string, v float64) (float64, bool) {
+	for _, uc := range u {
+		if name == "" || uc.Name != name {
+			continue
+		}
+		l, err := uc.linear()
+		if err != nil {
+			return v, false
+		}
+		return (v - l.offset) / l.scale, true
+	}
+	if len(u) != 1 || u[0].Name != "" {
+		return v, false
+	}
+	l, err := u[0].linear()
+	if err != nil {
+		return v, false
+	}
+
+	return (v - l.offset) / l.scale, true
+}
+
+func (u Units) convert(msg *Message, scaleOnly bool) bool {
+	if msg.Value == nil && msg.Sum == nil {
+		return false
+	}
+	for _, uc := range u {
+		if uc.From != msg.Unit || (uc.Name != "" && uc.Name != msg.Name) {
+			continue
+		}
+		l, err := uc.linear()
+		if err != nil {
+			return false
+		}
+		if scaleOnly {
+			l.offset = 0
+		}
+		if msg.Sum != nil && l.offset != 0 {
+			return false
+		}
+
+		if msg.Metadata == nil {
+			msg.Metadata = map[string]interface{}{}
+		}
+		msg.Metadata[OriginalUnitKey] = msg.Unit
+		msg.Unit = uc.To
+		if msg.Value != nil {
+			msg.Metadata[OriginalValueKey] = *msg.Value
+			v := *msg.Value*l.scale + l.offset
+			msg.Value = &v
+		}
+		if msg.Sum != nil {
+			s := *msg.Sum * l.scale
+			msg.Sum = &s
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (uc UnitConversion) linear() (linear, error) {
+	if uc.Scale != 0 {
+		return linear{scale: uc.Scale, offset: uc.Offset}, nil
+	}
+	l, ok := conversions[[2]string{uc.From, uc.To}]
+	if !ok {
+		return linear{}, errors.Wrap(errUnknownConversion, fmt.Errorf("from %q to %q", uc.From, uc.To))
+	}
+
+	return l, nil
+}
@@ -19,6 +19,7 @@ var errFailedToReadConfig = errors.New("failed to read config file")
 type ServiceConf struct {
 	Port       string `toml:"port"          env:"MG_PROVISION_HTTP_PORT"            envDefault:"9016"`
 	LogLevel   string `toml:"log_level"     env:"MG_PROVISION_LOG_LEVEL"            envDefault:"info"`
+	LogFormat  string `toml:"log_format"    env:"MG_LOG_FORMAT"                     envDefault:"json"`
 	TLS        bool   `toml:"tls"           env:"MG_PROVISION_ENV_CLIENTS_TLS"      envDefault:"false"`
 	ServerCert string `toml:"server_cert"   env:"MG_PROVISION_SERVER_CERT"          envDefault:""`
 	ServerKey  string `toml:"server_key"    env:"MG_PROVISION_SERVER_KEY"           envDefault:""`
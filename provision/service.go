@@ -148,12 +148,15 @@ func (ps *provisionService) Provision(domainID, token, name, externalID, externa
 			return res, errors.Wrap(ErrFailedThingCreation, err)
 		}
 
-		// Get newly created thing (in order to get the key).
+		// Get newly created thing, keeping the key from the creation
+		// response since Things may reveal it only on creation.
+		secret := th.Credentials.Secret
 		th, err = ps.sdk.Thing(th.ID, domainID, token)
 		if err != nil {
 			e := errors.Wrap(err, fmt.Errorf("thing id: %s", th.ID))
 			return res, errors.Wrap(ErrFailedThingRetrieval, e)
 		}
+		th.Credentials.Secret = secret
 		things = append(things, th)
 	}
 
@@ -60,6 +60,48 @@ func TestMapping(t *testing.T) {
 	}
 }
 
+func TestProvision(t *testing.T) {
+	conf := validConfig
+	conf.Bootstrap = provision.Bootstrap{}
+
+	cases := []struct {
+		desc   string
+		secret string
+		viewed string
+	}{
+		{
+			desc:   "provision with revealed secret",
+			secret: "secret",
+			viewed: "secret",
+		},
+		{
+			desc:   "provision with secret revealed only on creation",
+			secret: "secret",
+			viewed: "********",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			mgsdk := new(sdkmocks.SDK)
+			svc := provision.New(conf, mgsdk, mglog.NewMock())
+
+			thingID := testsutil.GenerateUUID(t)
+			domainID := testsutil.GenerateUUID(t)
+			mgsdk.On("CreateThing", mock.Anything, domainID, validToken).Return(sdk.Thing{ID: thingID, Credentials: sdk.Credentials{Secret: c.secret}}, nil)
+			mgsdk.On("Thing", thingID, domainID, validToken).Return(sdk.Thing{ID: thingID, Credentials: sdk.Credentials{Secret: c.viewed}}, nil)
+			mgsdk.On("CreateChannel", mock.Anything, domainID, validToken).Return(sdk.Channel{ID: thingID}, nil)
+			mgsdk.On("Channel", thingID, domainID, validToken).Return(sdk.Channel{ID: thingID}, nil)
+			mgsdk.On("Thing", mock.Anything, domainID, validToken).Return(sdk.Thing{}, nil)
+			mgsdk.On("UpdateThing", mock.Anything, domainID, validToken).Return(sdk.Thing{}, nil)
+			res, err := svc.Provision(domainID, validToken, "test", "", "")
+			assert.Nil(t, err, fmt.Sprintf("expected nil error, got %v", err))
+			assert.Len(t, res.Things, 1)
+			assert.Equal(t, c.secret, res.Things[0].Credentials.Secret)
+		})
+	}
+}
+
 func TestCert(t *testing.T) {
 	cases := []struct {
 		desc        string
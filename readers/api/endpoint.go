@@ -35,6 +35,31 @@ func listMessagesEndpoint(svc readers.MessageRepository, authz mgauthz.Authoriza
 			PageMetadata: page.PageMetadata,
 			Total:        page.Total,
 			Messages:     page.Messages,
+			contentType:  req.accept,
+		}, nil
+	}
+}
+
+func exportMessagesEndpoint(svc readers.MessageRepository, authz mgauthz.Authorization, thingsClient magistrala.ThingsServiceClient) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(exportMessagesReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		if err := authorize(ctx, listMessagesReq{chanID: req.chanID, token: req.token, key: req.key}, authz, thingsClient); err != nil {
+			return nil, errors.Wrap(svcerr.ErrAuthorization, err)
+		}
+
+		return exportRes{
+			output: req.output,
+			format: req.pageMeta.Format,
+			cursor: req.cursor,
+			rng:    req.rng,
+			key:    req.exportKey(),
+			export: func(cursor string, handler readers.ExportHandler) error {
+				return svc.Export(ctx, req.chanID, req.pageMeta, cursor, handler)
+			},
 		}, nil
 	}
 }
@@ -4,10 +4,15 @@
 package api_test
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -60,6 +65,7 @@ type testRequest struct {
 	url    string
 	token  string
 	key    string
+	rng    string
 }
 
 func (tr testRequest) make() (*http.Response, error) {
@@ -73,6 +79,9 @@ func (tr testRequest) make() (*http.Response, error) {
 	if tr.key != "" {
 		req.Header.Set("Authorization", apiutil.ThingPrefix+tr.key)
 	}
+	if tr.rng != "" {
+		req.Header.Set("Range", tr.rng)
+	}
 
 	return tr.client.Do(req)
 }
@@ -133,6 +142,8 @@ func TestReadAll(t *testing.T) {
 	ts := newServer(repo, authz, things)
 	defer ts.Close()
 
+	lower, higher, noBool := v-1, v+1, false
+
 	cases := []struct {
 		desc         string
 		req          string
@@ -408,6 +419,48 @@ func TestReadAll(t *testing.T) {
 			authResponse: true,
 			status:       http.StatusBadRequest,
 		},
+		{
+			desc:         "read page with value greater than as thing",
+			url:          fmt.Sprintf("%s/channels/%s/messages?value_gt=%f", ts.URL, chanID, lower),
+			key:          thingToken,
+			authResponse: true,
+			status:       http.StatusOK,
+			res: pageRes{
+				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", ValueGT: &lower},
+				Total:        uint64(len(valueMsgs)),
+				Messages:     valueMsgs[0:10],
+			},
+		},
+		{
+			desc:         "read page with value range and name as thing",
+			url:          fmt.Sprintf("%s/channels/%s/messages?value_gt=%f&value_lt=%f&name=name", ts.URL, chanID, lower, higher),
+			key:          thingToken,
+			authResponse: true,
+			status:       http.StatusOK,
+			res: pageRes{
+				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", Name: "name", ValueGT: &lower, ValueLT: &higher},
+				Total:        uint64(len(valueMsgs)),
+				Messages:     valueMsgs[0:10],
+			},
+		},
+		{
+			desc:         "read page with value equal to zero as thing",
+			url:          fmt.Sprintf("%s/channels/%s/messages?value_eq=0", ts.URL, chanID),
+			key:          thingToken,
+			authResponse: true,
+			status:       http.StatusOK,
+			res: pageRes{
+				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", ValueEQ: new(float64)},
+				Messages:     []senml.Message{},
+			},
+		},
+		{
+			desc:         "read page with non-float value comparison as thing",
+			url:          fmt.Sprintf("%s/channels/%s/messages?value_lt=ab01", ts.URL, chanID),
+			key:          thingToken,
+			authResponse: true,
+			status:       http.StatusBadRequest,
+		},
 		{
 			desc:         "read page with value and wrong comparator as thing",
 			url:          fmt.Sprintf("%s/channels/%s/messages?v=%f&comparator=wrong", ts.URL, chanID, v-1),
@@ -422,11 +475,22 @@ func TestReadAll(t *testing.T) {
 			authResponse: true,
 			status:       http.StatusOK,
 			res: pageRes{
-				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", BoolValue: true},
+				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", BoolValue: &vb},
 				Total:        uint64(len(boolMsgs)),
 				Messages:     boolMsgs[0:10],
 			},
 		},
+		{
+			desc:         "read page with false boolean value as thing",
+			url:          fmt.Sprintf("%s/channels/%s/messages?vb=false", ts.URL, chanID),
+			key:          thingToken,
+			authResponse: true,
+			status:       http.StatusOK,
+			res: pageRes{
+				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", BoolValue: &noBool},
+				Messages:     []senml.Message{},
+			},
+		},
 		{
 			desc:         "read page with non-boolean value as thing",
 			url:          fmt.Sprintf("%s/channels/%s/messages?vb=yes", ts.URL, chanID),
@@ -830,7 +894,7 @@ func TestReadAll(t *testing.T) {
 			authResponse: true,
 			status:       http.StatusOK,
 			res: pageRes{
-				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", BoolValue: true},
+				PageMetadata: readers.PageMetadata{Limit: 10, Format: "messages", BoolValue: &vb},
 				Total:        uint64(len(boolMsgs)),
 				Messages:     boolMsgs[0:10],
 			},
@@ -995,6 +1059,481 @@ func TestReadAll(t *testing.T) {
 	}
 }
 
+func TestReadAllUnsupportedFilter(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	repo := new(mocks.MessageRepository)
+	authz := new(authzmocks.Authorization)
+	things := new(thmocks.ThingsServiceClient)
+	ts := newServer(repo, authz, things)
+	defer ts.Close()
+
+	gt := v
+	pm := readers.PageMetadata{Limit: 10, Format: "json", ValueGT: &gt}
+	repo.On("ReadAll", chanID, pm).Return(readers.MessagesPage{}, readers.ErrUnsupportedFilter)
+	authz.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+
+	req := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/channels/%s/messages?format=json&value_gt=%f", ts.URL, chanID, gt),
+		token:  userToken,
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode, "value filters on JSON messages expected to be rejected")
+}
+
+func TestReadAllGeo(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	repo := new(mocks.MessageRepository)
+	authz := new(authzmocks.Authorization)
+	things := new(thmocks.ThingsServiceClient)
+	ts := newServer(repo, authz, things)
+	defer ts.Close()
+
+	authz.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+
+	cases := []struct {
+		desc   string
+		query  string
+		pm     readers.PageMetadata
+		repErr error
+		status int
+	}{
+		{
+			desc:   "read messages within bounding box",
+			query:  "bbox=20.1,44.5,20.9,45.1",
+			pm:     readers.PageMetadata{Limit: 10, Format: "messages", BBox: &readers.BoundingBox{West: 20.1, South: 44.5, East: 20.9, North: 45.1}},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "read messages within bounding box crossing antimeridian",
+			query:  "bbox=170,-20,-170,20",
+			pm:     readers.PageMetadata{Limit: 10, Format: "messages", BBox: &readers.BoundingBox{West: 170, South: -20, East: -170, North: 20}},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "read messages within radius",
+			query:  "lat=44.8&lon=20.4&radius=1500",
+			pm:     readers.PageMetadata{Limit: 10, Format: "messages", Near: &readers.GeoRadius{Latitude: 44.8, Longitude: 20.4, Radius: 1500}},
+			status: http.StatusOK,
+		},
+		{
+			desc:   "read messages with incomplete bounding box",
+			query:  "bbox=20.1,44.5,20.9",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages with malformed bounding box",
+			query:  "bbox=20.1,south,20.9,45.1",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages with inverted bounding box",
+			query:  "bbox=20.1,45.1,20.9,44.5",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages with bounding box out of range",
+			query:  "bbox=20.1,44.5,200,45.1",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages with radius without center",
+			query:  "lat=44.8&radius=1500",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read messages with negative radius",
+			query:  "lat=44.8&lon=20.4&radius=-1",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read JSON messages within bounding box",
+			query:  "format=json&bbox=20.1,44.5,20.9,45.1",
+			pm:     readers.PageMetadata{Limit: 10, Format: "json", BBox: &readers.BoundingBox{West: 20.1, South: 44.5, East: 20.9, North: 45.1}},
+			repErr: readers.ErrUnsupportedGeoFilter,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := repo.On("ReadAll", chanID, tc.pm).Return(readers.MessagesPage{PageMetadata: tc.pm}, tc.repErr)
+			req := testRequest{
+				client: ts.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/channels/%s/messages?%s", ts.URL, chanID, tc.query),
+				token:  userToken,
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusOK {
+				repo.AssertCalled(t, "ReadAll", chanID, tc.pm)
+			}
+			repoCall.Unset()
+		})
+	}
+}
+
+func TestReadAllContentType(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	pubID := testsutil.GenerateUUID(t)
+	now := time.Now().Unix()
+
+	var messages []senml.Message
+	for i := 0; i < 10; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      float64(now - int64(i)),
+		}
+		if i%2 == 0 {
+			msg.Value = &v
+		} else {
+			msg.StringValue = &vs
+		}
+		messages = append(messages, msg)
+	}
+
+	repo := new(mocks.MessageRepository)
+	authz := new(authzmocks.Authorization)
+	things := new(thmocks.ThingsServiceClient)
+	ts := newServer(repo, authz, things)
+	defer ts.Close()
+
+	pm := readers.PageMetadata{Limit: 10, Format: "messages"}
+	repoCall := repo.On("ReadAll", chanID, pm).Return(readers.MessagesPage{PageMetadata: pm, Total: uint64(len(messages)), Messages: fromSenml(messages)}, nil)
+	authCall := authz.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	defer repoCall.Unset()
+	defer authCall.Unset()
+
+	cases := []struct {
+		desc   string
+		url    string
+		accept string
+		status int
+		ctype  string
+	}{
+		{
+			desc:   "read page without accept header",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			status: http.StatusOK,
+			ctype:  "application/json",
+		},
+		{
+			desc:   "read page as json",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "application/json",
+			status: http.StatusOK,
+			ctype:  "application/json",
+		},
+		{
+			desc:   "read page accepting any type",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "*/*",
+			status: http.StatusOK,
+			ctype:  "application/json",
+		},
+		{
+			desc:   "read page as senml",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "application/senml+json",
+			status: http.StatusOK,
+			ctype:  "application/senml+json",
+		},
+		{
+			desc:   "read page as csv",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "text/csv",
+			status: http.StatusOK,
+			ctype:  "text/csv",
+		},
+		{
+			desc:   "read page with preferred senml",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "text/csv;q=0.5, application/senml+json",
+			status: http.StatusOK,
+			ctype:  "application/senml+json",
+		},
+		{
+			desc:   "read page with specific type preferred over wildcard",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "*/*, text/csv",
+			status: http.StatusOK,
+			ctype:  "text/csv",
+		},
+		{
+			desc:   "read page with unsupported type",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "application/xml",
+			status: http.StatusNotAcceptable,
+		},
+		{
+			desc:   "read page with rejected json",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			accept: "application/json;q=0",
+			status: http.StatusNotAcceptable,
+		},
+		{
+			desc:   "read json messages as senml",
+			url:    fmt.Sprintf("%s/channels/%s/messages?format=json", ts.URL, chanID),
+			accept: "application/senml+json",
+			status: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tc.url, http.NoBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			req.Header.Set("Authorization", apiutil.BearerPrefix+userToken)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			res, err := ts.Client().Do(req)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			defer res.Body.Close()
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tc.ctype, res.Header.Get("Content-Type"))
+
+			switch tc.ctype {
+			case "application/senml+json":
+				var records []map[string]interface{}
+				err := json.NewDecoder(res.Body).Decode(&records)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding records: %s", tc.desc, err))
+				assert.Equal(t, len(messages), len(records), fmt.Sprintf("%s: expected %d records got %d", tc.desc, len(messages), len(records)))
+				for i, r := range records {
+					expected := map[string]interface{}{"n": msgName, "t": messages[i].Time}
+					if messages[i].Value != nil {
+						expected["v"] = v
+					} else {
+						expected["vs"] = vs
+					}
+					assert.Equal(t, expected, r, fmt.Sprintf("%s: got incorrect record %d", tc.desc, i))
+				}
+			case "text/csv":
+				records, err := csv.NewReader(res.Body).ReadAll()
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while parsing csv: %s", tc.desc, err))
+				assert.Equal(t, len(messages)+1, len(records), fmt.Sprintf("%s: expected %d records got %d", tc.desc, len(messages)+1, len(records)))
+				assert.Equal(t, []string{"channel", "publisher", "protocol", "name", "time", "value", "string_value"}, records[0])
+				for i, r := range records[1:] {
+					value, stringValue := "", vs
+					if messages[i].Value != nil {
+						value, stringValue = strconv.FormatFloat(v, 'f', -1, 64), ""
+					}
+					expected := []string{chanID, pubID, mqttProt, msgName, strconv.FormatInt(now-int64(i), 10), value, stringValue}
+					assert.Equal(t, expected, r, fmt.Sprintf("%s: got incorrect row %d", tc.desc, i))
+				}
+			default:
+				var page pageRes
+				err := json.NewDecoder(res.Body).Decode(&page)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+				assert.Equal(t, uint64(len(messages)), page.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, len(messages), page.Total))
+				assert.Equal(t, messages, page.Messages, fmt.Sprintf("%s: got incorrect body from response", tc.desc))
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	pubID := testsutil.GenerateUUID(t)
+	now := time.Now().Unix()
+
+	var messages []senml.Message
+	for i := 0; i < 25; i++ {
+		messages = append(messages, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      float64(now + int64(i)),
+			Value:     &v,
+		})
+	}
+
+	// export emulates the repository by streaming the messages after the
+	// one with the given cursor, which is the message index.
+	export := func(failAfter int, err error) func(context.Context, string, readers.PageMetadata, string, readers.ExportHandler) error {
+		return func(_ context.Context, _ string, _ readers.PageMetadata, cursor string, handler readers.ExportHandler) error {
+			start := 0
+			if cursor != "" {
+				i, err := strconv.Atoi(cursor)
+				if err != nil {
+					return readers.ErrInvalidCursor
+				}
+				start = i + 1
+			}
+			for i := start; i < len(messages); i++ {
+				if failAfter >= 0 && i-start == failAfter {
+					return err
+				}
+				if err := handler(messages[i], strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	repo := new(mocks.MessageRepository)
+	authz := new(authzmocks.Authorization)
+	things := new(thmocks.ThingsServiceClient)
+	ts := newServer(repo, authz, things)
+	defer ts.Close()
+
+	cases := []struct {
+		desc     string
+		url      string
+		token    string
+		key      string
+		authzErr error
+		export   func(context.Context, string, readers.PageMetadata, string, readers.ExportHandler) error
+		status   int
+		ctype    string
+		first    int
+		count    int
+		aborted  bool
+	}{
+		{
+			desc:   "export all messages as ndjson",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:  userToken,
+			export: export(-1, nil),
+			status: http.StatusOK,
+			ctype:  "application/x-ndjson",
+			first:  0,
+			count:  len(messages),
+		},
+		{
+			desc:   "resume export from cursor",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export?cursor=9", ts.URL, chanID),
+			token:  userToken,
+			export: export(-1, nil),
+			status: http.StatusOK,
+			ctype:  "application/x-ndjson",
+			first:  10,
+			count:  len(messages) - 10,
+		},
+		{
+			desc:   "export all messages as csv with thing key",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export?output=csv", ts.URL, chanID),
+			key:    thingToken,
+			export: export(-1, nil),
+			status: http.StatusOK,
+			ctype:  "text/csv",
+			first:  0,
+			count:  len(messages),
+		},
+		{
+			desc:   "export with invalid output",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export?output=xml", ts.URL, chanID),
+			token:  userToken,
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "export with invalid cursor",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export?cursor=invalid", ts.URL, chanID),
+			token:  userToken,
+			export: export(-1, nil),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "export without credentials",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:     "export without channel permission",
+			url:      fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:    userToken,
+			authzErr: svcerr.ErrAuthorization,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			desc:   "export failing before the first message",
+			url:    fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:  userToken,
+			export: export(0, readers.ErrReadMessages),
+			status: http.StatusInternalServerError,
+		},
+		{
+			desc:    "export failing after the first message",
+			url:     fmt.Sprintf("%s/channels/%s/messages/export", ts.URL, chanID),
+			token:   userToken,
+			export:  export(5, readers.ErrReadMessages),
+			status:  http.StatusOK,
+			aborted: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			authCall := authz.On("Authorize", mock.Anything, mock.Anything).Return(tc.authzErr)
+			thingsCall := things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true}, tc.authzErr)
+			repoCall := repo.On("Export", mock.Anything, chanID, mock.Anything, mock.Anything, mock.Anything).Return(tc.export)
+			req := testRequest{
+				client: ts.Client(),
+				method: http.MethodGet,
+				url:    tc.url,
+				token:  tc.token,
+				key:    tc.key,
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+			body, err := io.ReadAll(res.Body)
+			res.Body.Close()
+			if tc.aborted {
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected aborted response", tc.desc))
+			}
+			if tc.status != http.StatusOK || tc.aborted {
+				authCall.Unset()
+				thingsCall.Unset()
+				repoCall.Unset()
+				return
+			}
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while reading response body: %s", tc.desc, err))
+			assert.Equal(t, tc.ctype, res.Header.Get("Content-Type"))
+
+			lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+			switch tc.ctype {
+			case "text/csv":
+				records, err := csv.NewReader(strings.NewReader(string(body))).ReadAll()
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while parsing csv: %s", tc.desc, err))
+				assert.Equal(t, tc.count+1, len(records), fmt.Sprintf("%s: expected %d records got %d", tc.desc, tc.count+1, len(records)))
+				assert.Equal(t, "cursor", records[0][0])
+				for i, r := range records[1:] {
+					assert.Equal(t, strconv.Itoa(tc.first+i), r[0])
+					assert.Equal(t, chanID, r[1])
+				}
+			default:
+				assert.Equal(t, tc.count, len(lines), fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.count, len(lines)))
+				for i, l := range lines {
+					var m struct {
+						Cursor  string        `json:"cursor"`
+						Message senml.Message `json:"message"`
+					}
+					err := json.Unmarshal([]byte(l), &m)
+					assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding message: %s", tc.desc, err))
+					assert.Equal(t, strconv.Itoa(tc.first+i), m.Cursor)
+					assert.Equal(t, messages[tc.first+i], m.Message)
+				}
+			}
+			authCall.Unset()
+			thingsCall.Unset()
+			repoCall.Unset()
+		})
+	}
+}
+
 type pageRes struct {
 	readers.PageMetadata
 	Total    uint64          `json:"total"`
@@ -1008,3 +1547,159 @@ func fromSenml(in []senml.Message) []readers.Message {
 	}
 	return ret
 }
+
+func TestExportRange(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	pubID := testsutil.GenerateUUID(t)
+	now := time.Now().Unix()
+
+	var messages []senml.Message
+	for i := 0; i < 2500; i++ {
+		messages = append(messages, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      float64(now + int64(i)),
+			Value:     &v,
+		})
+	}
+
+	// export emulates the repository by streaming the messages after the
+	// one with the given cursor, and records the cursors it is called with.
+	var cursors []string
+	export := func(_ context.Context, _ string, _ readers.PageMetadata, cursor string, handler readers.ExportHandler) error {
+		cursors = append(cursors, cursor)
+		start := 0
+		if cursor != "" {
+			i, err := strconv.Atoi(cursor)
+			if err != nil {
+				return readers.ErrInvalidCursor
+			}
+			start = i + 1
+		}
+		for i := start; i < len(messages); i++ {
+			if err := handler(messages[i], strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	repo := new(mocks.MessageRepository)
+	authz := new(authzmocks.Authorization)
+	things := new(thmocks.ThingsServiceClient)
+	authz.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Export", mock.Anything, chanID, mock.Anything, mock.Anything, mock.Anything).Return(export)
+
+	get := func(ts *httptest.Server, url, rng string) (*http.Response, []byte) {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    url,
+			token:  userToken,
+			rng:    rng,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Nil(t, err, fmt.Sprintf("unexpected error while reading response body: %s", err))
+		return res, body
+	}
+
+	for _, output := range []string{"ndjson", "csv"} {
+		url := fmt.Sprintf("/channels/%s/messages/export?output=%s", chanID, output)
+
+		ts := newServer(repo, authz, things)
+		res, full := get(ts, ts.URL+url, "")
+		ts.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: export expected %d got %d", output, http.StatusOK, res.StatusCode))
+		assert.Equal(t, "bytes", res.Header.Get("Accept-Ranges"), fmt.Sprintf("%s: export must accept byte ranges", output))
+		size := int64(len(full))
+		// Offsets after the first message, right before a resume point and
+		// past it, all in the middle of a message.
+		offsets := []int64{100, size/2 - 7, size - 150}
+
+		cases := []struct {
+			desc    string
+			indexed bool
+			rng     string
+			status  int
+			body    []byte
+			crange  string
+			cursor  string
+		}{
+			{
+				desc:   "resume export from offset",
+				rng:    fmt.Sprintf("bytes=%d-", offsets[1]),
+				status: http.StatusPartialContent,
+				body:   full[offsets[1]:],
+				crange: fmt.Sprintf("bytes %d-%d/%d", offsets[1], size-1, size),
+			},
+			{
+				desc:    "resume indexed export from offset before the first resume point",
+				indexed: true,
+				rng:     fmt.Sprintf("bytes=%d-", offsets[0]),
+				status:  http.StatusPartialContent,
+				body:    full[offsets[0]:],
+				crange:  fmt.Sprintf("bytes %d-%d/%d", offsets[0], size-1, size),
+			},
+			{
+				desc:    "resume indexed export from offset past the resume points",
+				indexed: true,
+				rng:     fmt.Sprintf("bytes=%d-", offsets[2]),
+				status:  http.StatusPartialContent,
+				body:    full[offsets[2]:],
+				crange:  fmt.Sprintf("bytes %d-%d/%d", offsets[2], size-1, size),
+				cursor:  "1999",
+			},
+			{
+				desc:    "export bounded range",
+				indexed: true,
+				rng:     fmt.Sprintf("bytes=%d-%d", offsets[1], offsets[1]+99),
+				status:  http.StatusPartialContent,
+				body:    full[offsets[1] : offsets[1]+100],
+				crange:  fmt.Sprintf("bytes %d-%d/*", offsets[1], offsets[1]+99),
+				cursor:  "999",
+			},
+			{
+				desc:   "export range past the end",
+				rng:    fmt.Sprintf("bytes=%d-", size),
+				status: http.StatusRequestedRangeNotSatisfiable,
+				crange: fmt.Sprintf("bytes */%d", size),
+			},
+			{
+				desc:   "export with unsupported suffix range",
+				rng:    "bytes=-100",
+				status: http.StatusOK,
+				body:   full,
+			},
+			{
+				desc:   "export with multiple ranges",
+				rng:    "bytes=0-10,20-30",
+				status: http.StatusOK,
+				body:   full,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(fmt.Sprintf("%s %s", output, tc.desc), func(t *testing.T) {
+				ts := newServer(repo, authz, things)
+				defer ts.Close()
+				if tc.indexed {
+					get(ts, ts.URL+url, "")
+				}
+				cursors = nil
+
+				res, body := get(ts, ts.URL+url, tc.rng)
+				assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+				assert.Equal(t, tc.crange, res.Header.Get("Content-Range"), fmt.Sprintf("%s: unexpected content range", tc.desc))
+				if tc.status != http.StatusRequestedRangeNotSatisfiable {
+					assert.Equal(t, string(tc.body), string(body), fmt.Sprintf("%s: resumed export is not continuous", tc.desc))
+					assert.Equal(t, []string{tc.cursor}, cursors, fmt.Sprintf("%s: unexpected export cursor", tc.desc))
+				}
+			})
+		}
+	}
+}
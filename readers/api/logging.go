@@ -6,9 +6,11 @@
 package api
 
 import (
+	"context"
 	"log/slog"
 	"time"
 
+	"github.com/absmach/magistrala/pkg/errors"
 	"github.com/absmach/magistrala/readers"
 )
 
@@ -54,3 +56,31 @@ func (lm *loggingMiddleware) ReadAll(chanID string, rpm readers.PageMetadata) (p
 
 	return lm.svc.ReadAll(chanID, rpm)
 }
+
+func (lm *loggingMiddleware) Export(ctx context.Context, chanID string, rpm readers.PageMetadata, cursor string, handler readers.ExportHandler) (err error) {
+	var count uint64
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("channel_id", chanID),
+			slog.Uint64("exported", count),
+		}
+		if rpm.Subtopic != "" {
+			args = append(args, slog.String("subtopic", rpm.Subtopic))
+		}
+		if rpm.Publisher != "" {
+			args = append(args, slog.String("publisher", rpm.Publisher))
+		}
+		if err != nil && !errors.Contains(err, readers.ErrStopExport) {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Export failed", args...)
+			return
+		}
+		lm.logger.Info("Export completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.Export(ctx, chanID, rpm, cursor, func(msg readers.Message, cursor string) error {
+		count++
+		return handler(msg, cursor)
+	})
+}
@@ -6,6 +6,7 @@
 package api
 
 import (
+	"context"
 	"time"
 
 	"github.com/absmach/magistrala/readers"
@@ -37,3 +38,12 @@ func (mm *metricsMiddleware) ReadAll(chanID string, rpm readers.PageMetadata) (r
 
 	return mm.svc.ReadAll(chanID, rpm)
 }
+
+func (mm *metricsMiddleware) Export(ctx context.Context, chanID string, rpm readers.PageMetadata, cursor string, handler readers.ExportHandler) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "export").Add(1)
+		mm.latency.With("method", "export").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Export(ctx, chanID, rpm, cursor, handler)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	rangeHeader        = "Range"
+	acceptRangesHeader = "Accept-Ranges"
+	contentRangeHeader = "Content-Range"
+	bytesUnit          = "bytes"
+
+	// checkpointInterval is the number of exported messages between two
+	// resume points recorded in the export index.
+	checkpointInterval = 1000
+
+	// maxRangeSize is the maximum number of bytes sent in a response to a
+	// range request. The client requests the rest of the range with another
+	// request, as announced by the Content-Range header.
+	maxRangeSize = 8 << 20
+
+	// maxIndexedExports is the number of exports the export index keeps the
+	// resume points of.
+	maxIndexedExports = 256
+)
+
+// byteRange is a single range of bytes. Negative end means the range extends
+// to the end of the export.
+type byteRange struct {
+	start int64
+	end   int64
+}
+
+// parseRange parses the Range header. Only a single range with a known first
+// byte is supported, since the length of the export is not known ahead. Any
+// other range is ignored and the whole export is sent.
+func parseRange(header string) (byteRange, bool) {
+	spec, ok := strings.CutPrefix(header, bytesUnit+"=")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, false
+	}
+	first, last, ok := strings.Cut(spec, "-")
+	if !ok {
+		return byteRange{}, false
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false
+	}
+	end := int64(-1)
+	if last = strings.TrimSpace(last); last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return byteRange{}, false
+		}
+	}
+
+	return byteRange{start: start, end: end}, true
+}
+
+// checkpoint is the cursor resuming the export at the byte offset.
+type checkpoint struct {
+	offset int64
+	cursor string
+}
+
+// exportIndex keeps the resume points of the recent exports, so a range
+// request continues the export from the cursor closest to the first byte of
+// the range instead of reading it from the start.
+type exportIndex struct {
+	mu     sync.Mutex
+	size   int
+	keys   []string
+	points map[string][]checkpoint
+}
+
+func newExportIndex(size int) *exportIndex {
+	return &exportIndex{
+		size:   size,
+		points: make(map[string][]checkpoint),
+	}
+}
+
+// record saves the resume point of the export. The points of the oldest
+// export are dropped once the index is full.
+func (idx *exportIndex) record(key string, cp checkpoint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	points, ok := idx.points[key]
+	if !ok {
+		if len(idx.keys) >= idx.size {
+			delete(idx.points, idx.keys[0])
+			idx.keys = idx.keys[1:]
+		}
+		idx.keys = append(idx.keys, key)
+	}
+	if n := len(points); n > 0 && points[n-1].offset >= cp.offset {
+		return
+	}
+	idx.points[key] = append(points, cp)
+}
+
+// resume returns the last resume point of the export at or before the offset.
+func (idx *exportIndex) resume(key string, offset int64) (checkpoint, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	points := idx.points[key]
+	i := sort.Search(len(points), func(i int) bool {
+		return points[i].offset > offset
+	})
+	if i == 0 {
+		return checkpoint{}, false
+	}
+
+	return points[i-1], true
+}
+
+// exportWriter counts the bytes of the export. Serving a range, it keeps the
+// bytes within the range instead of writing them through.
+type exportWriter struct {
+	w      io.Writer
+	offset int64
+
+	ranged bool
+	first  int64
+	last   int64
+	buf    bytes.Buffer
+}
+
+func (ew *exportWriter) Write(p []byte) (int, error) {
+	start := ew.offset
+	ew.offset += int64(len(p))
+	if !ew.ranged {
+		return ew.w.Write(p)
+	}
+
+	lo := max(ew.first-start, 0)
+	hi := min(ew.last+1-start, int64(len(p)))
+	if lo < hi {
+		ew.buf.Write(p[lo:hi])
+	}
+
+	return len(p), nil
+}
+
+// done reports whether all the bytes of the range are written.
+func (ew *exportWriter) done() bool {
+	return ew.ranged && ew.offset > ew.last
+}
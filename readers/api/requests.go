@@ -4,6 +4,7 @@
 package api
 
 import (
+	"encoding/json"
 	"slices"
 	"strings"
 	"time"
@@ -20,6 +21,7 @@ type listMessagesReq struct {
 	chanID   string
 	token    string
 	key      string
+	accept   string
 	pageMeta readers.PageMetadata
 }
 
@@ -45,6 +47,10 @@ func (req listMessagesReq) validate() error {
 		return apiutil.ErrInvalidComparator
 	}
 
+	if err := validateGeo(req.pageMeta); err != nil {
+		return err
+	}
+
 	if req.pageMeta.Aggregation != "" {
 		if req.pageMeta.From == 0 {
 			return apiutil.ErrMissingFrom
@@ -65,3 +71,62 @@ func (req listMessagesReq) validate() error {
 
 	return nil
 }
+
+// validateGeo checks that the bounding box and the radius lie on the Earth.
+func validateGeo(pm readers.PageMetadata) error {
+	if box := pm.BBox; box != nil {
+		if !validLatitude(box.South) || !validLatitude(box.North) || box.South > box.North ||
+			!validLongitude(box.West) || !validLongitude(box.East) {
+			return apiutil.ErrInvalidGeolocation
+		}
+	}
+	if near := pm.Near; near != nil {
+		if !validLatitude(near.Latitude) || !validLongitude(near.Longitude) || near.Radius <= 0 {
+			return apiutil.ErrInvalidGeolocation
+		}
+	}
+
+	return nil
+}
+
+func validLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+func validLongitude(lon float64) bool {
+	return lon >= -180 && lon <= 180
+}
+
+type exportMessagesReq struct {
+	chanID   string
+	token    string
+	key      string
+	output   string
+	cursor   string
+	rng      string
+	pageMeta readers.PageMetadata
+}
+
+func (req exportMessagesReq) validate() error {
+	if req.token == "" && req.key == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	if req.chanID == "" {
+		return apiutil.ErrMissingID
+	}
+
+	if req.output != ndjsonOutput && req.output != csvOutput {
+		return apiutil.ErrInvalidQueryParams
+	}
+
+	return nil
+}
+
+// exportKey identifies the export, so the range requests resuming the export
+// find its resume points.
+func (req exportMessagesReq) exportKey() string {
+	pm, _ := json.Marshal(req.pageMeta)
+
+	return strings.Join([]string{req.chanID, req.output, req.cursor, string(pm)}, "/")
+}
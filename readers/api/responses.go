@@ -16,6 +16,8 @@ type pageRes struct {
 	readers.PageMetadata
 	Total    uint64            `json:"total"`
 	Messages []readers.Message `json:"messages,omitempty"`
+
+	contentType string
 }
 
 func (res pageRes) Headers() map[string]string {
@@ -29,3 +31,14 @@ func (res pageRes) Code() int {
 func (res pageRes) Empty() bool {
 	return false
 }
+
+type exportRes struct {
+	output string
+	format string
+	cursor string
+	// rng is the Range header of the request.
+	rng string
+	// key identifies the export in the export index.
+	key    string
+	export func(cursor string, handler readers.ExportHandler) error
+}
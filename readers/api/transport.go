@@ -4,15 +4,21 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/pkg/apiutil"
 	mgauthz "github.com/absmach/magistrala/pkg/authz"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
 	"github.com/absmach/magistrala/readers"
 	"github.com/go-chi/chi/v5"
 	kithttp "github.com/go-kit/kit/transport/http"
@@ -35,15 +41,30 @@ const (
 	dataValueKey   = "vd"
 	boolValueKey   = "vb"
 	comparatorKey  = "comparator"
+	valueGTKey     = "value_gt"
+	valueLTKey     = "value_lt"
+	valueEQKey     = "value_eq"
 	fromKey        = "from"
 	toKey          = "to"
 	aggregationKey = "aggregation"
 	intervalKey    = "interval"
+	bboxKey        = "bbox"
+	latKey         = "lat"
+	lonKey         = "lon"
+	radiusKey      = "radius"
+	cursorKey      = "cursor"
+	outputKey      = "output"
 	defInterval    = "1s"
 	defLimit       = 10
 	defOffset      = 0
 	defFormat      = "messages"
 
+	ndjsonOutput      = "ndjson"
+	csvOutput         = "csv"
+	ndjsonContentType = "application/x-ndjson"
+	csvContentType    = "text/csv"
+	senmlContentType  = "application/senml+json"
+
 	tokenKind           = "token"
 	thingType           = "thing"
 	userType            = "user"
@@ -52,7 +73,12 @@ const (
 	groupType           = "group"
 )
 
-var errUserAccess = errors.New("user has no permission")
+var (
+	errUserAccess = errors.New("user has no permission")
+
+	senmlColumns = []string{"channel", "subtopic", "publisher", "protocol", "name", "unit", "time", "update_time", "original_time", "value", "string_value", "bool_value", "data_value", "sum", "latitude", "longitude"}
+	jsonColumns  = []string{"id", "channel", "created", "subtopic", "publisher", "protocol", "payload"}
+)
 
 // MakeHandler returns a HTTP handler for API endpoints.
 func MakeHandler(svc readers.MessageRepository, authz mgauthz.Authorization, things magistrala.ThingsServiceClient, svcName, instanceID string) http.Handler {
@@ -64,7 +90,14 @@ func MakeHandler(svc readers.MessageRepository, authz mgauthz.Authorization, thi
 	mux.Get("/channels/{chanID}/messages", kithttp.NewServer(
 		listMessagesEndpoint(svc, authz, things),
 		decodeList,
-		encodeResponse,
+		encodeList,
+		opts...,
+	).ServeHTTP)
+
+	mux.Get("/channels/{chanID}/messages/export", kithttp.NewServer(
+		exportMessagesEndpoint(svc, authz, things),
+		decodeExport,
+		encodeExport(newExportIndex(maxIndexedExports)),
 		opts...,
 	).ServeHTTP)
 
@@ -75,6 +108,11 @@ func MakeHandler(svc readers.MessageRepository, authz mgauthz.Authorization, thi
 }
 
 func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
+	accept, err := negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		return nil, err
+	}
+
 	offset, err := apiutil.ReadNumQuery[uint64](r, offsetKey, defOffset)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
@@ -130,9 +168,28 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
 
-	vb, err := apiutil.ReadBoolQuery(r, boolValueKey, false)
-	if err != nil && err != apiutil.ErrNotFoundParam {
-		return nil, err
+	var vb *bool
+	if r.URL.Query().Has(boolValueKey) {
+		b, err := apiutil.ReadBoolQuery(r, boolValueKey, false)
+		if err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+		vb = &b
+	}
+
+	valueGT, err := readValueQuery(r, valueGTKey)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	valueLT, err := readValueQuery(r, valueLTKey)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	valueEQ, err := readValueQuery(r, valueEQKey)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
 
 	from, err := apiutil.ReadNumQuery[float64](r, fromKey, 0)
@@ -158,10 +215,26 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 		}
 	}
 
+	bbox, err := readBBoxQuery(r)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	near, err := readNearQuery(r)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	// Only SenML messages can be represented as SenML records.
+	if accept == senmlContentType && format != defFormat {
+		return nil, errors.Wrap(apiutil.ErrNotAcceptable, fmt.Errorf("format %s is not SenML", format))
+	}
+
 	req := listMessagesReq{
 		chanID: chi.URLParam(r, "chanID"),
 		token:  apiutil.ExtractBearerToken(r),
 		key:    apiutil.ExtractThingKey(r),
+		accept: accept,
 		pageMeta: readers.PageMetadata{
 			Offset:      offset,
 			Limit:       limit,
@@ -172,6 +245,9 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 			Name:        name,
 			Value:       v,
 			Comparator:  comparator,
+			ValueGT:     valueGT,
+			ValueLT:     valueLT,
+			ValueEQ:     valueEQ,
 			StringValue: vs,
 			DataValue:   vd,
 			BoolValue:   vb,
@@ -179,6 +255,126 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 			To:          to,
 			Aggregation: aggregation,
 			Interval:    interval,
+			BBox:        bbox,
+			Near:        near,
+		},
+	}
+	return req, nil
+}
+
+// readValueQuery reads the optional value comparison, so that comparing
+// with zero is distinguished from not comparing at all.
+func readValueQuery(r *http.Request, key string) (*float64, error) {
+	if !r.URL.Query().Has(key) {
+		return nil, nil
+	}
+	v, err := apiutil.ReadNumQuery[float64](r, key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// readBBoxQuery reads the optional bounding box, given as
+// west,south,east,north in degrees like the GeoJSON bounding boxes.
+func readBBoxQuery(r *http.Request) (*readers.BoundingBox, error) {
+	val, err := apiutil.ReadStringQuery(r, bboxKey, "")
+	if err != nil {
+		return nil, err
+	}
+	if val == "" {
+		return nil, nil
+	}
+	parts := strings.Split(val, ",")
+	if len(parts) != 4 {
+		return nil, apiutil.ErrInvalidGeolocation
+	}
+	var bounds [4]float64
+	for i, p := range parts {
+		if bounds[i], err = strconv.ParseFloat(strings.TrimSpace(p), 64); err != nil {
+			return nil, errors.Wrap(apiutil.ErrInvalidGeolocation, err)
+		}
+	}
+
+	return &readers.BoundingBox{West: bounds[0], South: bounds[1], East: bounds[2], North: bounds[3]}, nil
+}
+
+// readNearQuery reads the optional radius in meters around the latitude and
+// longitude. The center and the radius are given together.
+func readNearQuery(r *http.Request) (*readers.GeoRadius, error) {
+	q := r.URL.Query()
+	if !q.Has(latKey) && !q.Has(lonKey) && !q.Has(radiusKey) {
+		return nil, nil
+	}
+	if !q.Has(latKey) || !q.Has(lonKey) || !q.Has(radiusKey) {
+		return nil, apiutil.ErrInvalidGeolocation
+	}
+	lat, err := apiutil.ReadNumQuery[float64](r, latKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	lon, err := apiutil.ReadNumQuery[float64](r, lonKey, 0)
+	if err != nil {
+		return nil, err
+	}
+	radius, err := apiutil.ReadNumQuery[float64](r, radiusKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &readers.GeoRadius{Latitude: lat, Longitude: lon, Radius: radius}, nil
+}
+
+func decodeExport(_ context.Context, r *http.Request) (interface{}, error) {
+	format, err := apiutil.ReadStringQuery(r, formatKey, defFormat)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	output, err := apiutil.ReadStringQuery(r, outputKey, ndjsonOutput)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	cursor, err := apiutil.ReadStringQuery(r, cursorKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	subtopic, err := apiutil.ReadStringQuery(r, subtopicKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	publisher, err := apiutil.ReadStringQuery(r, publisherKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	from, err := apiutil.ReadNumQuery[float64](r, fromKey, 0)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	to, err := apiutil.ReadNumQuery[float64](r, toKey, 0)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	req := exportMessagesReq{
+		chanID: chi.URLParam(r, "chanID"),
+		token:  apiutil.ExtractBearerToken(r),
+		key:    apiutil.ExtractThingKey(r),
+		output: output,
+		cursor: cursor,
+		rng:    r.Header.Get(rangeHeader),
+		pageMeta: readers.PageMetadata{
+			Format:    format,
+			Subtopic:  subtopic,
+			Publisher: publisher,
+			From:      from,
+			To:        to,
 		},
 	}
 	return req, nil
@@ -202,6 +398,408 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 	return json.NewEncoder(w).Encode(response)
 }
 
+// negotiate returns the supported content type most preferred by the Accept
+// header. Wildcards match JSON, unless a more specific supported type is
+// accepted with the same quality. Missing header accepts JSON.
+func negotiate(accept string) (string, error) {
+	if strings.TrimSpace(accept) == "" {
+		return contentType, nil
+	}
+
+	best, bestQ, bestExact := "", 0.0, false
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		q := 1.0
+		for _, p := range params[1:] {
+			k, v, ok := strings.Cut(p, "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+			var err error
+			if q, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+				q = 0
+			}
+		}
+
+		var ct string
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		switch mediaType {
+		case contentType, "application/*", "*/*":
+			ct = contentType
+		case senmlContentType:
+			ct = senmlContentType
+		case csvContentType, "text/*":
+			ct = csvContentType
+		default:
+			continue
+		}
+		exact := !strings.HasSuffix(mediaType, "*")
+		if q > bestQ || (q == bestQ && q > 0 && exact && !bestExact) {
+			best, bestQ, bestExact = ct, q, exact
+		}
+	}
+	if best == "" {
+		return "", errors.Wrap(apiutil.ErrNotAcceptable, fmt.Errorf("accept %q", accept))
+	}
+
+	return best, nil
+}
+
+// encodeList encodes the page in the negotiated content type. CSV and SenML
+// contain only the messages, without the page metadata.
+func encodeList(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(pageRes)
+	switch res.contentType {
+	case senmlContentType:
+		records, err := senmlRecords(res.Messages)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", senmlContentType)
+		w.WriteHeader(res.Code())
+		return json.NewEncoder(w).Encode(records)
+	case csvContentType:
+		data, err := csvPage(res.Messages, res.Format)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", csvContentType)
+		w.WriteHeader(res.Code())
+		_, err = w.Write(data)
+		return err
+	default:
+		return encodeResponse(ctx, w, response)
+	}
+}
+
+// senmlRecord is the SenML representation of the stored message, with the
+// resolved name and time.
+type senmlRecord struct {
+	Name        string   `json:"n,omitempty"`
+	Unit        string   `json:"u,omitempty"`
+	Time        float64  `json:"t,omitempty"`
+	UpdateTime  float64  `json:"ut,omitempty"`
+	Value       *float64 `json:"v,omitempty"`
+	StringValue *string  `json:"vs,omitempty"`
+	DataValue   *string  `json:"vd,omitempty"`
+	BoolValue   *bool    `json:"vb,omitempty"`
+	Sum         *float64 `json:"s,omitempty"`
+}
+
+func senmlRecords(msgs []readers.Message) ([]senmlRecord, error) {
+	records := make([]senmlRecord, 0, len(msgs))
+	for _, msg := range msgs {
+		m, ok := msg.(senml.Message)
+		if !ok {
+			return nil, errors.Wrap(apiutil.ErrNotAcceptable, fmt.Errorf("message of type %T is not SenML", msg))
+		}
+		records = append(records, senmlRecord{
+			Name:        m.Name,
+			Unit:        m.Unit,
+			Time:        m.Time,
+			UpdateTime:  m.UpdateTime,
+			Value:       m.Value,
+			StringValue: m.StringValue,
+			DataValue:   m.DataValue,
+			BoolValue:   m.BoolValue,
+			Sum:         m.Sum,
+		})
+	}
+
+	return records, nil
+}
+
+// csvPage returns the messages as CSV. The header contains only the columns
+// present in at least one message, in the order of the message fields.
+func csvPage(msgs []readers.Message, format string) ([]byte, error) {
+	candidates := senmlColumns
+	if format != "" && format != defFormat {
+		candidates = jsonColumns
+	}
+
+	fields := make([]map[string]interface{}, len(msgs))
+	present := make(map[string]bool)
+	for i, msg := range msgs {
+		f, err := messageFields(msg)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range f {
+			present[k] = present[k] || v != nil
+		}
+		fields[i] = f
+	}
+	var columns []string
+	for _, c := range candidates {
+		if present[c] {
+			columns = append(columns, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(columns); err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		record, err := fieldsRecord(f, columns)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+// encodeExport streams the exported messages as they are read. Once the
+// first message is written the status can't be changed anymore, so a
+// failure aborts the response and the client resumes from the cursor of
+// the last message it received, or from the byte offset it received using
+// a range request.
+func encodeExport(idx *exportIndex) kithttp.EncodeResponseFunc {
+	return func(_ context.Context, w http.ResponseWriter, response interface{}) error {
+		res := response.(exportRes)
+		w.Header().Set(acceptRangesHeader, bytesUnit)
+		if rng, ok := parseRange(res.rng); ok {
+			return encodeExportRange(w, res, rng, idx)
+		}
+
+		ew := &exportWriter{w: w}
+		enc := newExportEncoder(ew, res, idx)
+		enc.onStart = func() {
+			w.Header().Set("Content-Type", enc.contentType())
+			w.WriteHeader(http.StatusOK)
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+		}
+
+		if err := res.export(res.cursor, enc.encode); err != nil {
+			if enc.started {
+				panic(http.ErrAbortHandler)
+			}
+			return err
+		}
+
+		return enc.close()
+	}
+}
+
+// encodeExportRange sends the bytes of the export within the range. The
+// range is translated to the cursor of the closest preceding resume point,
+// and the export bytes between the resume point and the range are skipped.
+// Responses are limited to maxRangeSize bytes, and the length of the export
+// is reported only if the export ends within the response.
+func encodeExportRange(w http.ResponseWriter, res exportRes, rng byteRange, idx *exportIndex) error {
+	last := rng.start + maxRangeSize - 1
+	if rng.end >= 0 && rng.end < last {
+		last = rng.end
+	}
+	ew := &exportWriter{ranged: true, first: rng.start, last: last}
+	enc := newExportEncoder(ew, res, idx)
+	cursor := res.cursor
+	if cp, ok := idx.resume(res.key, rng.start); ok {
+		cursor, ew.offset, enc.resumed = cp.cursor, cp.offset, true
+	}
+
+	err := res.export(cursor, func(msg readers.Message, cursor string) error {
+		if err := enc.encode(msg, cursor); err != nil {
+			return err
+		}
+		if ew.done() {
+			return readers.ErrStopExport
+		}
+		return nil
+	})
+	complete := err == nil
+	if err != nil && !errors.Contains(err, readers.ErrStopExport) {
+		return err
+	}
+	if err := enc.close(); err != nil {
+		return err
+	}
+
+	length := "*"
+	if complete {
+		length = strconv.FormatInt(ew.offset, 10)
+	}
+	if ew.buf.Len() == 0 {
+		w.Header().Set(contentRangeHeader, fmt.Sprintf("%s */%s", bytesUnit, length))
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return nil
+	}
+
+	end := rng.start + int64(ew.buf.Len()) - 1
+	w.Header().Set("Content-Type", enc.contentType())
+	w.Header().Set("Content-Length", strconv.Itoa(ew.buf.Len()))
+	w.Header().Set(contentRangeHeader, fmt.Sprintf("%s %d-%d/%s", bytesUnit, rng.start, end, length))
+	w.WriteHeader(http.StatusPartialContent)
+	_, err = w.Write(ew.buf.Bytes())
+
+	return err
+}
+
+type exportEncoder struct {
+	w       *exportWriter
+	output  string
+	columns []string
+	csv     *csv.Writer
+	started bool
+	// resumed is set if the export continues from a resume point past the
+	// CSV header.
+	resumed bool
+	// onStart is called before the first byte of the export is written.
+	onStart func()
+	key     string
+	idx     *exportIndex
+	count   int
+}
+
+func newExportEncoder(w *exportWriter, res exportRes, idx *exportIndex) *exportEncoder {
+	enc := &exportEncoder{w: w, output: res.output, columns: senmlColumns, key: res.key, idx: idx}
+	if res.format != "" && res.format != defFormat {
+		enc.columns = jsonColumns
+	}
+
+	return enc
+}
+
+func (enc *exportEncoder) contentType() string {
+	if enc.output == csvOutput {
+		return csvContentType
+	}
+
+	return ndjsonContentType
+}
+
+func (enc *exportEncoder) start() error {
+	enc.started = true
+	if enc.onStart != nil {
+		enc.onStart()
+	}
+	if enc.output == csvOutput {
+		enc.csv = csv.NewWriter(enc.w)
+		if !enc.resumed {
+			return enc.csv.Write(append([]string{cursorKey}, enc.columns...))
+		}
+	}
+
+	return nil
+}
+
+func (enc *exportEncoder) encode(msg readers.Message, cursor string) error {
+	if !enc.started {
+		if err := enc.start(); err != nil {
+			return err
+		}
+	}
+	if err := enc.write(msg, cursor); err != nil {
+		return err
+	}
+
+	enc.count++
+	if enc.count%checkpointInterval != 0 {
+		return nil
+	}
+	// The resume point must be at the message boundary, so the buffered
+	// CSV records are written out first.
+	if enc.csv != nil {
+		enc.csv.Flush()
+		if err := enc.csv.Error(); err != nil {
+			return err
+		}
+	}
+	enc.idx.record(enc.key, checkpoint{offset: enc.w.offset, cursor: cursor})
+
+	return nil
+}
+
+func (enc *exportEncoder) write(msg readers.Message, cursor string) error {
+	if enc.output != csvOutput {
+		return json.NewEncoder(enc.w).Encode(exportedMessage{Cursor: cursor, Message: msg})
+	}
+
+	record, err := csvRecord(msg, enc.columns)
+	if err != nil {
+		return err
+	}
+
+	return enc.csv.Write(append([]string{cursor}, record...))
+}
+
+func (enc *exportEncoder) close() error {
+	if !enc.started {
+		if err := enc.start(); err != nil {
+			return err
+		}
+	}
+	if enc.csv != nil {
+		enc.csv.Flush()
+		return enc.csv.Error()
+	}
+
+	return nil
+}
+
+type exportedMessage struct {
+	Cursor  string          `json:"cursor"`
+	Message readers.Message `json:"message"`
+}
+
+// csvRecord returns the message fields in the columns order.
+func csvRecord(msg readers.Message, columns []string) ([]string, error) {
+	fields, err := messageFields(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldsRecord(fields, columns)
+}
+
+// messageFields returns the message fields by their JSON names. Numbers are
+// kept as they are decoded, so nanosecond timestamps don't lose precision.
+func messageFields(msg readers.Message) (map[string]interface{}, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	fields := map[string]interface{}{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func fieldsRecord(fields map[string]interface{}, columns []string) ([]string, error) {
+	record := make([]string, len(columns))
+	for i, c := range columns {
+		switch v := fields[c].(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case json.Number:
+			record[i] = v.String()
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		default:
+			val, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			record[i] = string(val)
+		}
+	}
+
+	return record, nil
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	var wrapper error
 	if errors.Contains(err, apiutil.ErrValidation) {
@@ -216,15 +814,21 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		errors.Contains(err, apiutil.ErrLimitSize),
 		errors.Contains(err, apiutil.ErrOffsetSize),
 		errors.Contains(err, apiutil.ErrInvalidComparator),
+		errors.Contains(err, apiutil.ErrInvalidGeolocation),
 		errors.Contains(err, apiutil.ErrInvalidAggregation),
 		errors.Contains(err, apiutil.ErrInvalidInterval),
 		errors.Contains(err, apiutil.ErrMissingFrom),
-		errors.Contains(err, apiutil.ErrMissingTo):
+		errors.Contains(err, apiutil.ErrMissingTo),
+		errors.Contains(err, readers.ErrInvalidCursor),
+		errors.Contains(err, readers.ErrUnsupportedFilter),
+		errors.Contains(err, readers.ErrUnsupportedGeoFilter):
 		w.WriteHeader(http.StatusBadRequest)
 	case errors.Contains(err, svcerr.ErrAuthentication),
 		errors.Contains(err, svcerr.ErrAuthorization),
 		errors.Contains(err, apiutil.ErrBearerToken):
 		w.WriteHeader(http.StatusUnauthorized)
+	case errors.Contains(err, apiutil.ErrNotAcceptable):
+		w.WriteHeader(http.StatusNotAcceptable)
 	case errors.Contains(err, readers.ErrReadMessages):
 		w.WriteHeader(http.StatusInternalServerError)
 	default:
@@ -0,0 +1,103 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/absmach/magistrala/readers"
+)
+
+const (
+	countAggregation = "COUNT"
+	sumAggregation   = "SUM"
+)
+
+var _ readers.MessageRepository = (*unitsMiddleware)(nil)
+
+type unitsMiddleware struct {
+	units senml.Units
+	svc   readers.MessageRepository
+}
+
+// UnitsMiddleware converts the values of the read SenML messages to the units
+// of the units table. The original value and unit of the converted messages
+// are kept in their metadata. The stored messages are never modified. The
+// value filters are given in the converted unit, and are converted back to
+// the stored unit of the filtered name before the messages are read.
+func UnitsMiddleware(svc readers.MessageRepository, units senml.Units) readers.MessageRepository {
+	if len(units) == 0 {
+		return svc
+	}
+
+	return &unitsMiddleware{
+		units: units,
+		svc:   svc,
+	}
+}
+
+func (um *unitsMiddleware) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	page, err := um.svc.ReadAll(chanID, um.revert(rpm))
+	if err != nil {
+		return page, err
+	}
+	// The page metadata holds the filters as they are requested.
+	page.PageMetadata = rpm
+	for i, msg := range page.Messages {
+		page.Messages[i] = um.convert(msg, rpm.Aggregation)
+	}
+
+	return page, nil
+}
+
+func (um *unitsMiddleware) Export(ctx context.Context, chanID string, rpm readers.PageMetadata, cursor string, handler readers.ExportHandler) error {
+	return um.svc.Export(ctx, chanID, um.revert(rpm), cursor, func(msg readers.Message, cursor string) error {
+		return handler(um.convert(msg, rpm.Aggregation), cursor)
+	})
+}
+
+// revert converts the value filters to the stored unit. The conversions keep
+// the value order, so the comparators are left as they are.
+func (um *unitsMiddleware) revert(rpm readers.PageMetadata) readers.PageMetadata {
+	if rpm.Value != 0 {
+		rpm.Value, _ = um.units.Revert(rpm.Name, rpm.Value)
+	}
+	for _, v := range []**float64{&rpm.ValueGT, &rpm.ValueLT, &rpm.ValueEQ} {
+		if *v == nil {
+			continue
+		}
+		r, _ := um.units.Revert(rpm.Name, **v)
+		*v = &r
+	}
+
+	return rpm
+}
+
+func (um *unitsMiddleware) convert(msg readers.Message, aggregation string) readers.Message {
+	m, ok := msg.(senml.Message)
+	if !ok {
+		return msg
+	}
+	// The counts have no unit.
+	if strings.EqualFold(aggregation, countAggregation) {
+		return msg
+	}
+	// The metadata is copied, since it may be shared with the stored message.
+	if m.Metadata != nil {
+		md := make(map[string]interface{}, len(m.Metadata)+2)
+		for k, v := range m.Metadata {
+			md[k] = v
+		}
+		m.Metadata = md
+	}
+	if strings.EqualFold(aggregation, sumAggregation) {
+		um.units.ConvertScale(&m)
+		return m
+	}
+	um.units.Convert(&m)
+
+	return m
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/absmach/magistrala/readers"
+	"github.com/absmach/magistrala/readers/api"
+	"github.com/absmach/magistrala/readers/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitsMiddleware(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	celsius, humidity := 25.0, 40.0
+	stored := []readers.Message{
+		senml.Message{Channel: chanID, Name: "temperature", Unit: "Cel", Value: &celsius, Metadata: map[string]interface{}{senml.ComputedErrorKey: "division by zero"}},
+		senml.Message{Channel: chanID, Name: "humidity", Unit: "%RH", Value: &humidity},
+		map[string]interface{}{"channel": chanID, "unit": "Cel"},
+	}
+	fahrenheit := 77.0
+	expected := []readers.Message{
+		senml.Message{
+			Channel:  chanID,
+			Name:     "temperature",
+			Unit:     "degF",
+			Value:    &fahrenheit,
+			Metadata: map[string]interface{}{senml.ComputedErrorKey: "division by zero", senml.OriginalValueKey: celsius, senml.OriginalUnitKey: "Cel"},
+		},
+		senml.Message{Channel: chanID, Name: "humidity", Unit: "%RH", Value: &humidity},
+		map[string]interface{}{"channel": chanID, "unit": "Cel"},
+	}
+	units := senml.Units{{From: "Cel", To: "degF"}}
+
+	repo := new(mocks.MessageRepository)
+	repo.On("ReadAll", chanID, mock.Anything).Return(func(string, readers.PageMetadata) (readers.MessagesPage, error) {
+		msgs := make([]readers.Message, len(stored))
+		copy(msgs, stored)
+		return readers.MessagesPage{Total: uint64(len(msgs)), Messages: msgs}, nil
+	})
+	repo.On("Export", mock.Anything, chanID, mock.Anything, "", mock.Anything).Return(func(_ context.Context, _ string, _ readers.PageMetadata, _ string, handler readers.ExportHandler) error {
+		for _, msg := range stored {
+			if err := handler(msg, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	svc := api.UnitsMiddleware(repo, units)
+
+	page, err := svc.ReadAll(chanID, readers.PageMetadata{})
+	require.Nil(t, err, fmt.Sprintf("read messages: unexpected error %s", err))
+	assert.Equal(t, expected, page.Messages, fmt.Sprintf("read messages: expected %v got %v", expected, page.Messages))
+
+	var exported []readers.Message
+	err = svc.Export(context.Background(), chanID, readers.PageMetadata{}, "", func(msg readers.Message, _ string) error {
+		exported = append(exported, msg)
+		return nil
+	})
+	require.Nil(t, err, fmt.Sprintf("export messages: unexpected error %s", err))
+	assert.Equal(t, expected, exported, fmt.Sprintf("export messages: expected %v got %v", expected, exported))
+
+	// The stored messages are left as they are.
+	assert.Equal(t, map[string]interface{}{senml.ComputedErrorKey: "division by zero"}, stored[0].(senml.Message).Metadata, "expected stored metadata to be unchanged")
+	assert.Equal(t, 25.0, celsius, "expected stored value to be unchanged")
+}
+
+func TestUnitsMiddlewareAggregation(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	units := senml.Units{{From: "Cel", To: "degF"}}
+
+	cases := []struct {
+		desc        string
+		aggregation string
+		value       float64
+		expUnit     string
+		expValue    float64
+	}{
+		{desc: "convert average", aggregation: "AVG", value: 25, expUnit: "degF", expValue: 77},
+		{desc: "scale sum", aggregation: "sum", value: 50, expUnit: "degF", expValue: 90},
+		{desc: "leave count untouched", aggregation: "COUNT", value: 3, expUnit: "Cel", expValue: 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			value := tc.value
+			repo := new(mocks.MessageRepository)
+			repo.On("ReadAll", chanID, mock.Anything).Return(readers.MessagesPage{
+				Total:    1,
+				Messages: []readers.Message{senml.Message{Channel: chanID, Name: "temperature", Unit: "Cel", Value: &value}},
+			}, nil)
+			svc := api.UnitsMiddleware(repo, units)
+
+			page, err := svc.ReadAll(chanID, readers.PageMetadata{Aggregation: tc.aggregation, Interval: "1h"})
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			msg := page.Messages[0].(senml.Message)
+			assert.Equal(t, tc.expUnit, msg.Unit, fmt.Sprintf("%s: expected unit %s got %s", tc.desc, tc.expUnit, msg.Unit))
+			assert.InDelta(t, tc.expValue, *msg.Value, 1e-9, fmt.Sprintf("%s: expected value %f got %f", tc.desc, tc.expValue, *msg.Value))
+		})
+	}
+}
+
+func TestUnitsMiddlewareFilters(t *testing.T) {
+	chanID := testsutil.GenerateUUID(t)
+	units := senml.Units{{Name: "temperature", From: "Cel", To: "degF"}}
+	gt, eq := 77.0, 95.0
+
+	repo := new(mocks.MessageRepository)
+	repo.On("ReadAll", chanID, mock.Anything).Return(readers.MessagesPage{}, nil)
+	svc := api.UnitsMiddleware(repo, units)
+
+	rpm := readers.PageMetadata{Name: "temperature", Value: 212, Comparator: readers.GreaterThanEqualKey, ValueGT: &gt, ValueEQ: &eq}
+	page, err := svc.ReadAll(chanID, rpm)
+	require.Nil(t, err, fmt.Sprintf("read messages: unexpected error %s", err))
+	assert.Equal(t, rpm, page.PageMetadata, "expected requested page metadata")
+
+	stored := repo.Calls[0].Arguments.Get(1).(readers.PageMetadata)
+	assert.InDelta(t, 100.0, stored.Value, 1e-9, fmt.Sprintf("expected value 100 got %f", stored.Value))
+	assert.Equal(t, readers.GreaterThanEqualKey, stored.Comparator, "expected comparator to be unchanged")
+	assert.InDelta(t, 25.0, *stored.ValueGT, 1e-9, fmt.Sprintf("expected value_gt 25 got %f", *stored.ValueGT))
+	assert.InDelta(t, 35.0, *stored.ValueEQ, 1e-9, fmt.Sprintf("expected value_eq 35 got %f", *stored.ValueEQ))
+	assert.Nil(t, stored.ValueLT, "expected value_lt to be unset")
+	assert.Equal(t, 77.0, gt, "expected requested filter to be unchanged")
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package readers
+
+import "math"
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// BoundingBox selects the messages located within the box, with the bounds
+// in degrees. A box whose west bound is greater than its east bound crosses
+// the antimeridian.
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+// GeoRadius selects the messages located within the radius in meters around
+// the center in degrees.
+type GeoRadius struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Radius    float64 `json:"radius"`
+}
+
+// Bounds returns the smallest bounding box containing the circle, so the
+// stores can narrow the messages down using the location index before
+// computing the distances.
+func (gr GeoRadius) Bounds() BoundingBox {
+	dist := gr.Radius / EarthRadius
+	lat := gr.Latitude * math.Pi / 180
+	south := (lat - dist) * 180 / math.Pi
+	north := (lat + dist) * 180 / math.Pi
+	// The circle containing a pole contains all the longitudes.
+	if south <= -90 || north >= 90 {
+		return BoundingBox{South: math.Max(south, -90), West: -180, North: math.Min(north, 90), East: 180}
+	}
+
+	dLon := math.Asin(math.Sin(dist)/math.Cos(lat)) * 180 / math.Pi
+	west, east := gr.Longitude-dLon, gr.Longitude+dLon
+	if west < -180 {
+		west += 360
+	}
+	if east > 180 {
+		east -= 360
+	}
+
+	return BoundingBox{South: south, West: west, North: north, East: east}
+}
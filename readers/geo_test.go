@@ -0,0 +1,50 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package readers_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/readers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoRadiusBounds(t *testing.T) {
+	cases := []struct {
+		desc   string
+		radius readers.GeoRadius
+		bounds readers.BoundingBox
+	}{
+		{
+			desc:   "bounds at equator",
+			radius: readers.GeoRadius{Latitude: 0, Longitude: 0, Radius: 111195},
+			bounds: readers.BoundingBox{South: -1, West: -1, North: 1, East: 1},
+		},
+		{
+			desc:   "bounds crossing antimeridian",
+			radius: readers.GeoRadius{Latitude: 0, Longitude: 179.5, Radius: 111195},
+			bounds: readers.BoundingBox{South: -1, West: 178.5, North: 1, East: -179.5},
+		},
+		{
+			desc:   "bounds containing pole",
+			radius: readers.GeoRadius{Latitude: 89.5, Longitude: 20, Radius: 111195},
+			bounds: readers.BoundingBox{South: 88.5, West: -180, North: 90, East: 180},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			b := tc.radius.Bounds()
+			assert.InDelta(t, tc.bounds.South, b.South, 1e-3, fmt.Sprintf("%s: expected south %f got %f", tc.desc, tc.bounds.South, b.South))
+			assert.InDelta(t, tc.bounds.West, b.West, 1e-3, fmt.Sprintf("%s: expected west %f got %f", tc.desc, tc.bounds.West, b.West))
+			assert.InDelta(t, tc.bounds.North, b.North, 1e-3, fmt.Sprintf("%s: expected north %f got %f", tc.desc, tc.bounds.North, b.North))
+			assert.InDelta(t, tc.bounds.East, b.East, 1e-3, fmt.Sprintf("%s: expected east %f got %f", tc.desc, tc.bounds.East, b.East))
+		})
+	}
+
+	// Away from the equator the longitudes of the same distance are wider.
+	b := readers.GeoRadius{Latitude: 60, Longitude: 0, Radius: 111195}.Bounds()
+	assert.Greater(t, b.East, 1.9, fmt.Sprintf("expected east bound over 1.9 got %f", b.East))
+}
@@ -3,7 +3,10 @@
 
 package readers
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 const (
 	// EqualKey represents the equal comparison operator key.
@@ -18,8 +21,25 @@ const (
 	GreaterThanEqualKey = "ge"
 )
 
-// ErrReadMessages indicates failure occurred while reading messages from database.
-var ErrReadMessages = errors.New("failed to read messages from database")
+var (
+	// ErrReadMessages indicates failure occurred while reading messages from database.
+	ErrReadMessages = errors.New("failed to read messages from database")
+
+	// ErrInvalidCursor indicates malformed export continuation token.
+	ErrInvalidCursor = errors.New("invalid export cursor")
+
+	// ErrStopExport is returned by the export handler to stop the export
+	// once it received all the messages it needs.
+	ErrStopExport = errors.New("export stopped")
+
+	// ErrUnsupportedFilter indicates that the message store can't filter the
+	// messages of the requested format by value.
+	ErrUnsupportedFilter = errors.New("value filters are not supported for the message format")
+
+	// ErrUnsupportedGeoFilter indicates that the message store can't filter
+	// the messages of the requested format by geolocation.
+	ErrUnsupportedGeoFilter = errors.New("geolocation filters are not supported for the message format")
+)
 
 // MessageRepository specifies message reader API.
 //
@@ -28,8 +48,19 @@ type MessageRepository interface {
 	// ReadAll skips given number of messages for given channel and returns next
 	// limited number of messages.
 	ReadAll(chanID string, pm PageMetadata) (MessagesPage, error)
+
+	// Export passes all messages of the given channel matching the format,
+	// subtopic, publisher and time range of the page metadata to the handler,
+	// oldest first, without loading them in memory. Each message is passed
+	// together with the cursor that resumes the export right after it. An
+	// empty cursor starts the export from the first message. Export stops
+	// on the first handler error or when the context is canceled.
+	Export(ctx context.Context, chanID string, pm PageMetadata, cursor string, handler ExportHandler) error
 }
 
+// ExportHandler handles a single exported message and its continuation cursor.
+type ExportHandler func(msg Message, cursor string) error
+
 // Message represents any message format.
 type Message interface{}
 
@@ -43,22 +74,39 @@ type MessagesPage struct {
 
 // PageMetadata represents the parameters used to create database queries.
 type PageMetadata struct {
-	Offset      uint64  `json:"offset"`
-	Limit       uint64  `json:"limit"`
-	Subtopic    string  `json:"subtopic,omitempty"`
-	Publisher   string  `json:"publisher,omitempty"`
-	Protocol    string  `json:"protocol,omitempty"`
-	Name        string  `json:"name,omitempty"`
-	Value       float64 `json:"v,omitempty"`
-	Comparator  string  `json:"comparator,omitempty"`
-	BoolValue   bool    `json:"vb,omitempty"`
-	StringValue string  `json:"vs,omitempty"`
-	DataValue   string  `json:"vd,omitempty"`
-	From        float64 `json:"from,omitempty"`
-	To          float64 `json:"to,omitempty"`
-	Format      string  `json:"format,omitempty"`
-	Aggregation string  `json:"aggregation,omitempty"`
-	Interval    string  `json:"interval,omitempty"`
+	Offset      uint64       `json:"offset"`
+	Limit       uint64       `json:"limit"`
+	Subtopic    string       `json:"subtopic,omitempty"`
+	Publisher   string       `json:"publisher,omitempty"`
+	Protocol    string       `json:"protocol,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Value       float64      `json:"v,omitempty"`
+	Comparator  string       `json:"comparator,omitempty"`
+	ValueGT     *float64     `json:"value_gt,omitempty"`
+	ValueLT     *float64     `json:"value_lt,omitempty"`
+	ValueEQ     *float64     `json:"value_eq,omitempty"`
+	BoolValue   *bool        `json:"vb,omitempty"`
+	StringValue string       `json:"vs,omitempty"`
+	DataValue   string       `json:"vd,omitempty"`
+	From        float64      `json:"from,omitempty"`
+	To          float64      `json:"to,omitempty"`
+	Format      string       `json:"format,omitempty"`
+	Aggregation string       `json:"aggregation,omitempty"`
+	Interval    string       `json:"interval,omitempty"`
+	BBox        *BoundingBox `json:"bbox,omitempty"`
+	Near        *GeoRadius   `json:"near,omitempty"`
+}
+
+// ValueFilters reports whether the messages are filtered by the value
+// comparisons, which apply only to the numeric SenML values.
+func (pm PageMetadata) ValueFilters() bool {
+	return pm.ValueGT != nil || pm.ValueLT != nil || pm.ValueEQ != nil
+}
+
+// GeoFilters reports whether the messages are filtered by the geolocation,
+// which applies only to the located SenML messages.
+func (pm PageMetadata) GeoFilters() bool {
+	return pm.BBox != nil || pm.Near != nil
 }
 
 // ParseValueComparator convert comparison operator keys into mathematic anotation.
@@ -5,6 +5,8 @@
 package mocks
 
 import (
+	context "context"
+
 	readers "github.com/absmach/magistrala/readers"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -14,6 +16,24 @@ type MessageRepository struct {
 	mock.Mock
 }
 
+// Export provides a mock function with given fields: ctx, chanID, pm, cursor, handler
+func (_m *MessageRepository) Export(ctx context.Context, chanID string, pm readers.PageMetadata, cursor string, handler readers.ExportHandler) error {
+	ret := _m.Called(ctx, chanID, pm, cursor, handler)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, readers.PageMetadata, string, readers.ExportHandler) error); ok {
+		r0 = rf(ctx, chanID, pm, cursor, handler)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReadAll provides a mock function with given fields: chanID, pm
 func (_m *MessageRepository) ReadAll(chanID string, pm readers.PageMetadata) (readers.MessagesPage, error) {
 	ret := _m.Called(chanID, pm)
@@ -0,0 +1,184 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/absmach/magistrala/readers"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// exportBatchSize is the number of rows fetched from the export cursor at once.
+const exportBatchSize = 1000
+
+var (
+	senmlKey = []string{"time", "publisher", "subtopic", "name"}
+	jsonKey  = []string{"created", "publisher", "subtopic"}
+)
+
+func (tr timescaleRepository) Export(ctx context.Context, chanID string, rpm readers.PageMetadata, cursor string, handler readers.ExportHandler) error {
+	format, timeCol, key := defTable, "time", senmlKey
+	if rpm.Format != "" && rpm.Format != defTable {
+		format, timeCol, key = rpm.Format, "created", jsonKey
+	}
+
+	params := map[string]interface{}{
+		"channel":   chanID,
+		"subtopic":  rpm.Subtopic,
+		"publisher": rpm.Publisher,
+		"from":      rpm.From,
+		"to":        rpm.To,
+	}
+	cond := fmtExportCondition(rpm, timeCol)
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return err
+		}
+		params["cursor"] = after
+		cond = fmt.Sprintf(`%s AND (%s) > (SELECT %s FROM jsonb_populate_record(CAST(NULL AS %s), CAST(:cursor AS JSONB)) AS c)`,
+			cond, strings.Join(key, ", "), prefixed("c.", key), format)
+	}
+
+	tx, err := tr.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return errors.Wrap(readers.ErrReadMessages, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	q := fmt.Sprintf(`DECLARE export_cursor NO SCROLL CURSOR FOR
+	SELECT *, CAST(jsonb_build_object(%s) AS TEXT) AS export_cursor FROM %s
+	WHERE %s ORDER BY %s ASC;`, jsonbPairs(key), format, cond, strings.Join(key, ", "))
+	if _, err := tx.NamedExecContext(ctx, q, params); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok {
+			if pgErr.Code == pgerrcode.UndefinedTable {
+				return nil
+			}
+		}
+		return errors.Wrap(readers.ErrReadMessages, err)
+	}
+
+	for {
+		n, err := fetchExportBatch(ctx, tx, format, handler)
+		if err != nil {
+			return err
+		}
+		if n < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func fetchExportBatch(ctx context.Context, tx *sqlx.Tx, format string, handler readers.ExportHandler) (int, error) {
+	rows, err := tx.QueryxContext(ctx, fmt.Sprintf(`FETCH %d FROM export_cursor;`, exportBatchSize))
+	if err != nil {
+		return 0, errors.Wrap(readers.ErrReadMessages, err)
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		n++
+		var msg readers.Message
+		var cursor string
+		switch format {
+		case defTable:
+			m := exportSenMLMessage{senmlMessage: senmlMessage{Message: senml.Message{}}}
+			if err := rows.StructScan(&m); err != nil {
+				return n, errors.Wrap(readers.ErrReadMessages, err)
+			}
+			msg, cursor = m.Message, m.Cursor
+		default:
+			m := exportJSONMessage{}
+			if err := rows.StructScan(&m); err != nil {
+				return n, errors.Wrap(readers.ErrReadMessages, err)
+			}
+			if msg, err = m.toMap(); err != nil {
+				return n, errors.Wrap(readers.ErrReadMessages, err)
+			}
+			cursor = m.Cursor
+		}
+		if err := handler(msg, encodeCursor(cursor)); err != nil {
+			return n, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, errors.Wrap(readers.ErrReadMessages, err)
+	}
+
+	return n, nil
+}
+
+func fmtExportCondition(rpm readers.PageMetadata, timeCol string) string {
+	condition := `channel = :channel`
+	if rpm.Subtopic != "" {
+		condition = fmt.Sprintf(`%s AND subtopic = :subtopic`, condition)
+	}
+	if rpm.Publisher != "" {
+		condition = fmt.Sprintf(`%s AND publisher = :publisher`, condition)
+	}
+	if rpm.From != 0 {
+		condition = fmt.Sprintf(`%s AND %s >= :from`, condition, timeCol)
+	}
+	if rpm.To != 0 {
+		condition = fmt.Sprintf(`%s AND %s < :to`, condition, timeCol)
+	}
+
+	return condition
+}
+
+func jsonbPairs(cols []string) string {
+	pairs := make([]string, len(cols))
+	for i, c := range cols {
+		pairs[i] = fmt.Sprintf("'%s', %s", c, c)
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+func prefixed(prefix string, cols []string) string {
+	ret := make([]string, len(cols))
+	for i, c := range cols {
+		ret[i] = prefix + c
+	}
+
+	return strings.Join(ret, ", ")
+}
+
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.Wrap(readers.ErrInvalidCursor, err)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(key, &obj); err != nil {
+		return "", errors.Wrap(readers.ErrInvalidCursor, err)
+	}
+
+	return string(key), nil
+}
+
+type exportSenMLMessage struct {
+	senmlMessage
+	Cursor string `db:"export_cursor"`
+}
+
+type exportJSONMessage struct {
+	jsonMessage
+	Cursor string `db:"export_cursor"`
+}
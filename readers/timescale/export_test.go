@@ -0,0 +1,123 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package timescale_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	twriter "github.com/absmach/magistrala/consumers/writers/timescale"
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/transformers/senml"
+	"github.com/absmach/magistrala/readers"
+	treader "github.com/absmach/magistrala/readers/timescale"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportMsgsNum exceeds a single export batch, so the export has to fetch
+// from the cursor more than once.
+const exportMsgsNum = 2500
+
+var errStopExport = errors.New("stop export")
+
+func TestExport(t *testing.T) {
+	writer := twriter.New(db)
+
+	chanID := testsutil.GenerateUUID(t)
+	pubID := testsutil.GenerateUUID(t)
+
+	messages := []senml.Message{}
+	now := float64(time.Now().Unix())
+	for i := 0; i < exportMsgsNum; i++ {
+		messages = append(messages, senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &v,
+		})
+	}
+	err := writer.ConsumeBlocking(context.TODO(), messages)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	other := senml.Message{Channel: testsutil.GenerateUUID(t), Publisher: pubID, Protocol: mqttProt, Name: msgName, Time: now, Value: &v}
+	err = writer.ConsumeBlocking(context.TODO(), []senml.Message{other})
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := treader.New(db)
+
+	t.Run("export all messages", func(t *testing.T) {
+		var times []float64
+		err := reader.Export(context.Background(), chanID, readers.PageMetadata{}, "", func(msg readers.Message, _ string) error {
+			times = append(times, msg.(senml.Message).Time)
+			return nil
+		})
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s", err))
+		assert.Equal(t, exportMsgsNum, len(times))
+		for i := range times {
+			assert.Equal(t, now-float64(exportMsgsNum-1-i), times[i], "expected messages ordered from the oldest")
+		}
+	})
+
+	t.Run("export messages in time range", func(t *testing.T) {
+		pm := readers.PageMetadata{From: now - 99, To: now + 1}
+		count := 0
+		err := reader.Export(context.Background(), chanID, pm, "", func(_ readers.Message, _ string) error {
+			count++
+			return nil
+		})
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s", err))
+		assert.Equal(t, 100, count)
+	})
+
+	t.Run("resume interrupted export", func(t *testing.T) {
+		seen := map[float64]bool{}
+		var cursor string
+		err := reader.Export(context.Background(), chanID, readers.PageMetadata{}, "", func(msg readers.Message, c string) error {
+			if len(seen) == 1200 {
+				return errStopExport
+			}
+			seen[msg.(senml.Message).Time] = true
+			cursor = c
+			return nil
+		})
+		assert.True(t, errors.Contains(err, errStopExport), fmt.Sprintf("expected %s got %s", errStopExport, err))
+
+		err = reader.Export(context.Background(), chanID, readers.PageMetadata{}, cursor, func(msg readers.Message, _ string) error {
+			tm := msg.(senml.Message).Time
+			assert.False(t, seen[tm], fmt.Sprintf("message %f exported twice", tm))
+			seen[tm] = true
+			return nil
+		})
+		assert.Nil(t, err, fmt.Sprintf("expected no error got %s", err))
+		assert.Equal(t, exportMsgsNum, len(seen))
+	})
+
+	t.Run("cancel export", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		count := 0
+		err := reader.Export(ctx, chanID, readers.PageMetadata{}, "", func(_ readers.Message, _ string) error {
+			count++
+			if count == 10 {
+				cancel()
+			}
+			return nil
+		})
+		assert.NotNil(t, err, "expected canceled export to fail")
+		assert.Less(t, count, exportMsgsNum)
+	})
+
+	t.Run("export with invalid cursor", func(t *testing.T) {
+		err := reader.Export(context.Background(), chanID, readers.PageMetadata{}, "invalid", func(_ readers.Message, _ string) error {
+			return nil
+		})
+		assert.True(t, errors.Contains(err, readers.ErrInvalidCursor), fmt.Sprintf("expected %s got %s", readers.ErrInvalidCursor, err))
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package timescale
+
+import (
+	"fmt"
+
+	"github.com/absmach/magistrala/readers"
+)
+
+// boxCondition returns the condition selecting the messages within the box
+// of the named parameters prefixed with the prefix. The conditions compare
+// the columns directly, so they use the location index.
+func boxCondition(box readers.BoundingBox, prefix string) string {
+	cond := fmt.Sprintf(`latitude BETWEEN :%[1]s_south AND :%[1]s_north`, prefix)
+	switch {
+	case box.West == -180 && box.East == 180:
+		return cond
+	case box.West > box.East:
+		return fmt.Sprintf(`%[1]s AND (longitude >= :%[2]s_west OR longitude <= :%[2]s_east)`, cond, prefix)
+	default:
+		return fmt.Sprintf(`%[1]s AND longitude BETWEEN :%[2]s_west AND :%[2]s_east`, cond, prefix)
+	}
+}
+
+// nearCondition returns the condition selecting the messages within the
+// radius, narrowed down to the bounding box of the circle first.
+func nearCondition(near readers.GeoRadius) string {
+	dist := fmt.Sprintf(`2 * %f * ASIN(SQRT(POWER(SIN(RADIANS(latitude - :near_lat) / 2), 2) + COS(RADIANS(:near_lat)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - :near_lon) / 2), 2)))`, readers.EarthRadius)
+
+	return fmt.Sprintf(`%s AND %s <= :near_radius`, boxCondition(near.Bounds(), "near"), dist)
+}
+
+// geoParams adds the parameters of the geolocation conditions.
+func geoParams(rpm readers.PageMetadata, params map[string]interface{}) {
+	if rpm.BBox != nil {
+		boxParams(*rpm.BBox, "bbox", params)
+	}
+	if rpm.Near != nil {
+		boxParams(rpm.Near.Bounds(), "near", params)
+		params["near_lat"] = rpm.Near.Latitude
+		params["near_lon"] = rpm.Near.Longitude
+		params["near_radius"] = rpm.Near.Radius
+	}
+}
+
+func boxParams(box readers.BoundingBox, prefix string, params map[string]interface{}) {
+	params[prefix+"_south"] = box.South
+	params[prefix+"_west"] = box.West
+	params[prefix+"_north"] = box.North
+	params[prefix+"_east"] = box.East
+}
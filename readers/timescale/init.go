@@ -72,6 +72,30 @@ func migrateDB(db *sqlx.DB) error {
 					"DROP TABLE messages",
 				},
 			},
+			{
+				// Same as the writer migration, so the message columns match
+				// whichever of the reader and the writer migrates first.
+				Id: "messages_3",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS latitude FLOAT, ADD COLUMN IF NOT EXISTS longitude FLOAT`,
+					`CREATE INDEX IF NOT EXISTS messages_location_idx ON messages (latitude, longitude) WHERE latitude IS NOT NULL`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS messages_location_idx`,
+					`ALTER TABLE messages DROP COLUMN IF EXISTS latitude, DROP COLUMN IF EXISTS longitude`,
+				},
+			},
+			{
+				// Same as the writer migration, so the message columns match
+				// whichever of the reader and the writer migrates first.
+				Id: "messages_4",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS original_time FLOAT`,
+				},
+				Down: []string{
+					`ALTER TABLE messages DROP COLUMN IF EXISTS original_time`,
+				},
+			},
 		},
 	}
 
@@ -33,6 +33,14 @@ func (tr timescaleRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 	format := defTable
 
 	if rpm.Format != "" && rpm.Format != defTable {
+		// JSON messages have no value column to compare.
+		if rpm.ValueFilters() {
+			return readers.MessagesPage{}, readers.ErrUnsupportedFilter
+		}
+		// JSON messages are not located.
+		if rpm.GeoFilters() {
+			return readers.MessagesPage{}, readers.ErrUnsupportedGeoFilter
+		}
 		order = "created"
 		format = rpm.Format
 	}
@@ -58,12 +66,16 @@ func (tr timescaleRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 		"name":         rpm.Name,
 		"protocol":     rpm.Protocol,
 		"value":        rpm.Value,
+		"value_gt":     rpm.ValueGT,
+		"value_lt":     rpm.ValueLT,
+		"value_eq":     rpm.ValueEQ,
 		"bool_value":   rpm.BoolValue,
 		"string_value": rpm.StringValue,
 		"data_value":   rpm.DataValue,
 		"from":         rpm.From,
 		"to":           rpm.To,
 	}
+	geoParams(rpm, params)
 
 	rows, err := tr.db.NamedQuery(q, params)
 	if err != nil {
@@ -144,6 +156,12 @@ func fmtCondition(rpm readers.PageMetadata) string {
 		case "v":
 			comparator := readers.ParseValueComparator(query)
 			condition = fmt.Sprintf(`%s AND value %s :value`, condition, comparator)
+		case "value_gt":
+			condition = fmt.Sprintf(`%s AND value > :value_gt`, condition)
+		case "value_lt":
+			condition = fmt.Sprintf(`%s AND value < :value_lt`, condition)
+		case "value_eq":
+			condition = fmt.Sprintf(`%s AND value = :value_eq`, condition)
 		case "vb":
 			condition = fmt.Sprintf(`%s AND bool_value = :bool_value`, condition)
 		case "vs":
@@ -167,6 +185,10 @@ func fmtCondition(rpm readers.PageMetadata) string {
 			condition = fmt.Sprintf(`%s AND time >= :from`, condition)
 		case "to":
 			condition = fmt.Sprintf(`%s AND time < :to`, condition)
+		case "bbox":
+			condition = fmt.Sprintf(`%s AND %s`, condition, boxCondition(*rpm.BBox, "bbox"))
+		case "near":
+			condition = fmt.Sprintf(`%s AND %s`, condition, nearCondition(*rpm.Near))
 		}
 	}
 	return condition
@@ -309,7 +309,7 @@ func TestReadSenml(t *testing.T) {
 			pageMeta: readers.PageMetadata{
 				Offset:    0,
 				Limit:     limit,
-				BoolValue: vb,
+				BoolValue: &vb,
 			},
 			page: readers.MessagesPage{
 				Total:    uint64(len(boolMsgs)),
@@ -644,6 +644,218 @@ func TestReadMessagesWithAggregation(t *testing.T) {
 	}
 }
 
+func TestReadSenmlValueFilters(t *testing.T) {
+	writer := twriter.New(db)
+
+	chanID := testsutil.GenerateUUID(t)
+	pubID := testsutil.GenerateUUID(t)
+
+	// Even values are temperatures, odd values are humidities.
+	var valueMsgs, trueMsgs, falseMsgs, stringMsgs []senml.Message
+	now := float64(time.Now().Unix())
+	for i := 0; i < 20; i++ {
+		value := float64(i)
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      msgName,
+			Time:      now - float64(i),
+			Value:     &value,
+		}
+		if i%2 == 1 {
+			msg.Name = "humidity"
+		}
+		valueMsgs = append(valueMsgs, msg)
+	}
+	vbFalse, vsOther := false, "otherValue"
+	for i := 0; i < 6; i++ {
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      "state",
+			Time:      now - float64(100+i),
+		}
+		switch i % 3 {
+		case 0:
+			msg.BoolValue = &vb
+			trueMsgs = append(trueMsgs, msg)
+		case 1:
+			msg.BoolValue = &vbFalse
+			falseMsgs = append(falseMsgs, msg)
+		case 2:
+			msg.StringValue = &vsOther
+			stringMsgs = append(stringMsgs, msg)
+		}
+	}
+	messages := append(append(append(append([]senml.Message{}, valueMsgs...), trueMsgs...), falseMsgs...), stringMsgs...)
+
+	err := writer.ConsumeBlocking(context.TODO(), messages)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := treader.New(db)
+
+	lower, upper, zero := 9.0, 15.0, 0.0
+	cases := []struct {
+		desc     string
+		pageMeta readers.PageMetadata
+		msgs     []senml.Message
+		err      error
+	}{
+		{
+			desc:     "read messages with value greater than",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, ValueGT: &upper},
+			msgs:     valueMsgs[16:],
+		},
+		{
+			desc:     "read messages with value lower than",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, ValueLT: &lower},
+			msgs:     valueMsgs[:9],
+		},
+		{
+			desc:     "read messages with value equal to zero",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, ValueEQ: &zero},
+			msgs:     valueMsgs[:1],
+		},
+		{
+			desc:     "read messages with value range and name",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, Name: msgName, ValueGT: &lower, ValueLT: &upper},
+			msgs:     []senml.Message{valueMsgs[10], valueMsgs[12], valueMsgs[14]},
+		},
+		{
+			desc:     "read messages with false boolean value",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, BoolValue: &vbFalse},
+			msgs:     falseMsgs,
+		},
+		{
+			desc:     "read messages with true boolean value",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, BoolValue: &vb},
+			msgs:     trueMsgs,
+		},
+		{
+			desc:     "read messages with string value and name",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, Name: "state", StringValue: vsOther},
+			msgs:     stringMsgs,
+		},
+		{
+			desc:     "read JSON messages with value comparison",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, Format: format1, ValueGT: &lower},
+			err:      readers.ErrUnsupportedFilter,
+		},
+	}
+
+	for _, tc := range cases {
+		result, err := reader.ReadAll(chanID, tc.pageMeta)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		assert.ElementsMatch(t, fromSenml(tc.msgs), result.Messages, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.msgs, result.Messages))
+		assert.Equal(t, uint64(len(tc.msgs)), result.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, len(tc.msgs), result.Total))
+	}
+}
+
+func TestReadSenmlGeo(t *testing.T) {
+	writer := twriter.New(db)
+
+	chanID := testsutil.GenerateUUID(t)
+	pubID := testsutil.GenerateUUID(t)
+
+	locations := []struct {
+		name string
+		lat  float64
+		lon  float64
+	}{
+		{name: "belgrade", lat: 44.8125, lon: 20.4612},
+		{name: "novi_sad", lat: 45.2671, lon: 19.8335},
+		{name: "london", lat: 51.5072, lon: -0.1276},
+		{name: "suva", lat: -18.1248, lon: 178.4501},
+		{name: "taveuni", lat: -16.8, lon: -179.9},
+	}
+	now := float64(time.Now().Unix())
+	located := map[string]senml.Message{}
+	var messages []senml.Message
+	for i, l := range locations {
+		value := float64(i)
+		lat, lon := l.lat, l.lon
+		msg := senml.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  mqttProt,
+			Name:      l.name,
+			Time:      now - float64(i),
+			Value:     &value,
+			Latitude:  &lat,
+			Longitude: &lon,
+		}
+		located[l.name] = msg
+		messages = append(messages, msg)
+	}
+	value := 42.0
+	messages = append(messages, senml.Message{
+		Channel:   chanID,
+		Publisher: pubID,
+		Protocol:  mqttProt,
+		Name:      "unlocated",
+		Time:      now - 100,
+		Value:     &value,
+	})
+
+	err := writer.ConsumeBlocking(context.TODO(), messages)
+	require.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	reader := treader.New(db)
+
+	cases := []struct {
+		desc     string
+		pageMeta readers.PageMetadata
+		msgs     []senml.Message
+		err      error
+	}{
+		{
+			desc:     "read messages within bounding box",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, BBox: &readers.BoundingBox{West: 19, South: 44, East: 21, North: 46}},
+			msgs:     []senml.Message{located["belgrade"], located["novi_sad"]},
+		},
+		{
+			desc:     "read messages within bounding box crossing antimeridian",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, BBox: &readers.BoundingBox{West: 178, South: -19, East: -179, North: -16}},
+			msgs:     []senml.Message{located["suva"], located["taveuni"]},
+		},
+		{
+			desc:     "read messages within empty bounding box",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, BBox: &readers.BoundingBox{West: 0, South: 0, East: 1, North: 1}},
+			msgs:     []senml.Message{},
+		},
+		{
+			desc:     "read messages within small radius",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, Near: &readers.GeoRadius{Latitude: 44.8125, Longitude: 20.4612, Radius: 10000}},
+			msgs:     []senml.Message{located["belgrade"]},
+		},
+		{
+			desc:     "read messages within large radius",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, Near: &readers.GeoRadius{Latitude: 44.8125, Longitude: 20.4612, Radius: 100000}},
+			msgs:     []senml.Message{located["belgrade"], located["novi_sad"]},
+		},
+		{
+			desc:     "read JSON messages within bounding box",
+			pageMeta: readers.PageMetadata{Limit: msgsNum, Format: format1, BBox: &readers.BoundingBox{West: 19, South: 44, East: 21, North: 46}},
+			err:      readers.ErrUnsupportedGeoFilter,
+		},
+	}
+
+	for _, tc := range cases {
+		result, err := reader.ReadAll(chanID, tc.pageMeta)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		assert.ElementsMatch(t, fromSenml(tc.msgs), result.Messages, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.msgs, result.Messages))
+		assert.Equal(t, uint64(len(tc.msgs)), result.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, len(tc.msgs), result.Total))
+	}
+}
+
 func TestReadJSON(t *testing.T) {
 	writer := twriter.New(db)
 
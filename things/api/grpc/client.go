@@ -49,31 +49,35 @@ func (client grpcClient) Authorize(ctx context.Context, req *magistrala.ThingsAu
 	defer cancel()
 
 	res, err := client.authorize(ctx, things.AuthzReq{
-		ThingID:    req.GetThingID(),
-		ThingKey:   req.GetThingKey(),
-		ChannelID:  req.GetChannelID(),
-		Permission: req.GetPermission(),
+		ThingID:     req.GetThingID(),
+		ThingKey:    req.GetThingKey(),
+		ChannelID:   req.GetChannelID(),
+		Permission:  req.GetPermission(),
+		ContentType: req.GetContentType(),
+		Payload:     req.GetPayload(),
 	})
 	if err != nil {
 		return &magistrala.ThingsAuthzRes{}, decodeError(err)
 	}
 
 	ar := res.(authorizeRes)
-	return &magistrala.ThingsAuthzRes{Authorized: ar.authorized, Id: ar.id}, nil
+	return &magistrala.ThingsAuthzRes{Authorized: ar.authorized, Id: ar.id, Rate: ar.rate, Burst: ar.burst, DomainId: ar.domainID, SigningSecret: ar.signingSecret}, nil
 }
 
 func decodeAuthorizeResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(*magistrala.ThingsAuthzRes)
-	return authorizeRes{authorized: res.Authorized, id: res.Id}, nil
+	return authorizeRes{authorized: res.Authorized, id: res.Id, rate: res.Rate, burst: res.Burst, domainID: res.DomainId, signingSecret: res.SigningSecret}, nil
 }
 
 func encodeAuthorizeRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(things.AuthzReq)
 	return &magistrala.ThingsAuthzReq{
-		ChannelID:  req.ChannelID,
-		ThingID:    req.ThingID,
-		ThingKey:   req.ThingKey,
-		Permission: req.Permission,
+		ChannelID:   req.ChannelID,
+		ThingID:     req.ThingID,
+		ThingKey:    req.ThingKey,
+		Permission:  req.Permission,
+		ContentType: req.ContentType,
+		Payload:     req.Payload,
 	}, nil
 }
 
@@ -14,18 +14,24 @@ func authorizeEndpoint(svc things.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(authorizeReq)
 
-		thingID, err := svc.Authorize(ctx, things.AuthzReq{
-			ChannelID:  req.ChannelID,
-			ThingID:    req.ThingID,
-			ThingKey:   req.ThingKey,
-			Permission: req.Permission,
+		res, err := svc.Authorize(ctx, things.AuthzReq{
+			ChannelID:   req.ChannelID,
+			ThingID:     req.ThingID,
+			ThingKey:    req.ThingKey,
+			Permission:  req.Permission,
+			ContentType: req.ContentType,
+			Payload:     req.Payload,
 		})
 		if err != nil {
 			return authorizeRes{}, err
 		}
 		return authorizeRes{
-			authorized: true,
-			id:         thingID,
+			authorized:    true,
+			id:            res.ThingID,
+			rate:          res.RateLimit.Rate,
+			burst:         res.RateLimit.Burst,
+			domainID:      res.DomainID,
+			signingSecret: res.SigningSecret,
 		}, err
 	}
 }
@@ -30,6 +30,7 @@ var (
 	thingID   = "testID"
 	thingKey  = "testKey"
 	channelID = "testID"
+	domainID  = "testDomainID"
 	invalid   = "invalid"
 )
 
@@ -61,7 +62,7 @@ func TestAuthorize(t *testing.T) {
 		thingID      string
 		identifyKey  string
 		authorizeReq things.AuthzReq
-		authorizeRes string
+		authorizeRes things.AuthzRes
 		authorizeErr error
 		identifyErr  error
 		err          error
@@ -80,9 +81,27 @@ func TestAuthorize(t *testing.T) {
 				ChannelID:  channelID,
 				Permission: policies.PublishPermission,
 			},
-			authorizeRes: thingID,
+			authorizeRes: things.AuthzRes{ThingID: thingID, DomainID: domainID},
 			identifyKey:  thingKey,
-			res:          &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID},
+			res:          &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, DomainId: domainID},
+			err:          nil,
+		},
+		{
+			desc:    "authorize successfully thing with rate limit",
+			thingID: thingID,
+			req: &magistrala.ThingsAuthzReq{
+				ThingKey:   thingKey,
+				ChannelID:  channelID,
+				Permission: policies.PublishPermission,
+			},
+			authorizeReq: things.AuthzReq{
+				ThingKey:   thingKey,
+				ChannelID:  channelID,
+				Permission: policies.PublishPermission,
+			},
+			authorizeRes: things.AuthzRes{ThingID: thingID, RateLimit: things.RateLimit{Rate: 0.5, Burst: 5}},
+			identifyKey:  thingKey,
+			res:          &magistrala.ThingsAuthzRes{Authorized: true, Id: thingID, Rate: 0.5, Burst: 5},
 			err:          nil,
 		},
 		{
@@ -198,7 +217,7 @@ func TestAuthorize(t *testing.T) {
 
 	for _, tc := range cases {
 		svcCall1 := svc.On("Identify", mock.Anything, tc.identifyKey).Return(tc.thingID, tc.identifyErr)
-		svcCall2 := svc.On("Authorize", mock.Anything, tc.authorizeReq).Return(tc.thingID, tc.authorizeErr)
+		svcCall2 := svc.On("Authorize", mock.Anything, tc.authorizeReq).Return(tc.authorizeRes, tc.authorizeErr)
 		res, err := client.Authorize(context.Background(), tc.req)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
 		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.res, res))
@@ -4,8 +4,10 @@
 package grpc
 
 type authorizeReq struct {
-	ThingID    string
-	ThingKey   string
-	ChannelID  string
-	Permission string
+	ThingID     string
+	ThingKey    string
+	ChannelID   string
+	Permission  string
+	ContentType string
+	Payload     []byte
 }
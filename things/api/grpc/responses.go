@@ -4,6 +4,10 @@
 package grpc
 
 type authorizeRes struct {
-	id         string
-	authorized bool
+	id            string
+	authorized    bool
+	rate          float64
+	burst         uint32
+	domainID      string
+	signingSecret string
 }
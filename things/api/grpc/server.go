@@ -46,16 +46,18 @@ func (s *grpcServer) Authorize(ctx context.Context, req *magistrala.ThingsAuthzR
 func decodeAuthorizeRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*magistrala.ThingsAuthzReq)
 	return authorizeReq{
-		ThingID:    req.GetThingID(),
-		ThingKey:   req.GetThingKey(),
-		ChannelID:  req.GetChannelID(),
-		Permission: req.GetPermission(),
+		ThingID:     req.GetThingID(),
+		ThingKey:    req.GetThingKey(),
+		ChannelID:   req.GetChannelID(),
+		Permission:  req.GetPermission(),
+		ContentType: req.GetContentType(),
+		Payload:     req.GetPayload(),
 	}, nil
 }
 
 func encodeAuthorizeResponse(_ context.Context, grpcRes interface{}) (interface{}, error) {
 	res := grpcRes.(authorizeRes)
-	return &magistrala.ThingsAuthzRes{Authorized: res.authorized, Id: res.id}, nil
+	return &magistrala.ThingsAuthzRes{Authorized: res.authorized, Id: res.id, Rate: res.rate, Burst: res.burst, DomainId: res.domainID, SigningSecret: res.signingSecret}, nil
 }
 
 func encodeError(err error) error {
@@ -15,13 +15,16 @@ import (
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/jobs"
 	"github.com/absmach/magistrala/things"
 	"github.com/go-chi/chi/v5"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-func clientsHandler(svc things.Service, r *chi.Mux, authn mgauthn.Authentication, logger *slog.Logger) http.Handler {
+func clientsHandler(svc things.Service, jsvc jobs.Service, r *chi.Mux, authn mgauthn.Authentication, logger *slog.Logger, pl api.PageLimits) http.Handler {
+	pageLimits = api.NewPageLimits(pl.Default, pl.Max)
+
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, api.EncodeError)),
 	}
@@ -44,7 +47,7 @@ func clientsHandler(svc things.Service, r *chi.Mux, authn mgauthn.Authentication
 			), "list_things").ServeHTTP)
 
 			r.Post("/bulk", otelhttp.NewHandler(kithttp.NewServer(
-				createClientsEndpoint(svc),
+				createClientsEndpoint(svc, jsvc),
 				decodeCreateClientsReq,
 				api.EncodeResponse,
 				opts...,
@@ -85,6 +88,13 @@ func clientsHandler(svc things.Service, r *chi.Mux, authn mgauthn.Authentication
 				opts...,
 			), "update_thing_credentials").ServeHTTP)
 
+			r.Post("/{thingID}/key/rotate", otelhttp.NewHandler(kithttp.NewServer(
+				rotateKeyEndpoint(svc),
+				decodeRotateKey,
+				api.EncodeResponse,
+				opts...,
+			), "rotate_thing_key").ServeHTTP)
+
 			r.Post("/{thingID}/enable", otelhttp.NewHandler(kithttp.NewServer(
 				enableClientEndpoint(svc),
 				decodeChangeClientStatus,
@@ -168,7 +178,7 @@ func decodeListClients(_ context.Context, r *http.Request) (interface{}, error)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
@@ -188,6 +198,10 @@ func decodeListClients(_ context.Context, r *http.Request) (interface{}, error)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
+	ids, err := apiutil.ReadStringQuery(r, api.IDsKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
 	p, err := apiutil.ReadStringQuery(r, api.PermissionKey, api.DefPermission)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
@@ -197,6 +211,10 @@ func decodeListClients(_ context.Context, r *http.Request) (interface{}, error)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
+	tu, err := apiutil.ReadBoolQuery(r, api.TotalUnfiltered, api.DefUnfiltered)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
 	st, err := mgclients.ToStatus(s)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
@@ -212,6 +230,10 @@ func decodeListClients(_ context.Context, r *http.Request) (interface{}, error)
 		listPerms:  lp,
 		userID:     chi.URLParam(r, "userID"),
 		id:         id,
+		unfiltered: tu,
+	}
+	if ids != "" {
+		req.ids = strings.Split(ids, ",")
 	}
 	return req, nil
 }
@@ -282,7 +304,12 @@ func decodeCreateClientsReq(_ context.Context, r *http.Request) (interface{}, er
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
 	}
 
-	c := createClientsReq{}
+	async, err := apiutil.ReadBoolQuery(r, api.AsyncKey, false)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	c := createClientsReq{async: async}
 	if err := json.NewDecoder(r.Body).Decode(&c.Clients); err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(errors.ErrMalformedEntity, err))
 	}
@@ -290,6 +317,14 @@ func decodeCreateClientsReq(_ context.Context, r *http.Request) (interface{}, er
 	return c, nil
 }
 
+func decodeRotateKey(_ context.Context, r *http.Request) (interface{}, error) {
+	req := rotateKeyReq{
+		id: chi.URLParam(r, "thingID"),
+	}
+
+	return req, nil
+}
+
 func decodeChangeClientStatus(_ context.Context, r *http.Request) (interface{}, error) {
 	req := changeClientStatusReq{
 		id: chi.URLParam(r, "thingID"),
@@ -307,7 +342,7 @@ func decodeListMembersRequest(_ context.Context, r *http.Request) (interface{},
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
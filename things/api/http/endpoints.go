@@ -13,6 +13,7 @@ import (
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/jobs"
 	"github.com/absmach/magistrala/pkg/policies"
 	"github.com/absmach/magistrala/things"
 	"github.com/go-kit/kit/endpoint"
@@ -42,7 +43,7 @@ func createClientEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
-func createClientsEndpoint(svc things.Service) endpoint.Endpoint {
+func createClientsEndpoint(svc things.Service, jsvc jobs.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(createClientsReq)
 		if err := req.validate(); err != nil {
@@ -54,6 +55,16 @@ func createClientsEndpoint(svc things.Service) endpoint.Endpoint {
 			return nil, svcerr.ErrAuthorization
 		}
 
+		if req.async {
+			payload := things.CreateThingsPayload{Things: req.Clients}
+			job, err := jsvc.Submit(ctx, session, things.CreateThingsJob, uint64(len(req.Clients)), payload)
+			if err != nil {
+				return nil, err
+			}
+
+			return jobRes{Job: job, submitted: true}, nil
+		}
+
 		page, err := svc.CreateThings(ctx, session, req.Clients...)
 		if err != nil {
 			return nil, err
@@ -128,16 +139,18 @@ func listClientsEndpoint(svc things.Service) endpoint.Endpoint {
 		}
 
 		pm := mgclients.Page{
-			Status:     req.status,
-			Offset:     req.offset,
-			Limit:      req.limit,
-			Name:       req.name,
-			Tag:        req.tag,
-			Permission: req.permission,
-			Metadata:   req.metadata,
-			ListPerms:  req.listPerms,
-			Role:       mgclients.AllRole, // retrieve all things since things don't have roles
-			Id:         req.id,
+			Status:          req.status,
+			Offset:          req.offset,
+			Limit:           req.limit,
+			Name:            req.name,
+			Tag:             req.tag,
+			Permission:      req.permission,
+			Metadata:        req.metadata,
+			ListPerms:       req.listPerms,
+			Role:            mgclients.AllRole, // retrieve all things since things don't have roles
+			Id:              req.id,
+			IDs:             req.ids,
+			CountUnfiltered: req.unfiltered,
 		}
 		page, err := svc.ListClients(ctx, session, req.userID, pm)
 		if err != nil {
@@ -146,9 +159,10 @@ func listClientsEndpoint(svc things.Service) endpoint.Endpoint {
 
 		res := clientsPageRes{
 			pageRes: pageRes{
-				Total:  page.Total,
-				Offset: page.Offset,
-				Limit:  page.Limit,
+				Total:           page.Total,
+				TotalUnfiltered: page.TotalUnfiltered,
+				Offset:          page.Offset,
+				Limit:           page.Limit,
 			},
 			Clients: []viewClientRes{},
 		}
@@ -254,6 +268,27 @@ func updateClientSecretEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
+func rotateKeyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(rotateKeyReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		client, err := svc.RotateKey(ctx, session, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return updateClientRes{Client: client}, nil
+	}
+}
+
 func enableClientEndpoint(svc things.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(changeClientStatusReq)
@@ -531,3 +566,45 @@ func deleteClientEndpoint(svc things.Service) endpoint.Endpoint {
 		return deleteClientRes{}, nil
 	}
 }
+
+func viewJobEndpoint(jsvc jobs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(jobReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		job, err := jsvc.View(ctx, session, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return jobRes{Job: job}, nil
+	}
+}
+
+func cancelJobEndpoint(jsvc jobs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(jobReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		job, err := jsvc.Cancel(ctx, session, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return jobRes{Job: job}, nil
+	}
+}
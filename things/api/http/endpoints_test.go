@@ -23,6 +23,10 @@ import (
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	gmocks "github.com/absmach/magistrala/pkg/groups/mocks"
+	"github.com/absmach/magistrala/pkg/jobs"
+	jobsmocks "github.com/absmach/magistrala/pkg/jobs/mocks"
+	qmocks "github.com/absmach/magistrala/pkg/quotas/mocks"
+	"github.com/absmach/magistrala/things"
 	httpapi "github.com/absmach/magistrala/things/api/http"
 	"github.com/absmach/magistrala/things/mocks"
 	"github.com/go-chi/chi/v5"
@@ -95,11 +99,22 @@ func newThingsServer() (*httptest.Server, *mocks.Service, *gmocks.Service, *auth
 
 	logger := mglog.NewMock()
 	mux := chi.NewRouter()
-	httpapi.MakeHandler(svc, gsvc, authn, mux, logger, "")
+	httpapi.MakeHandler(svc, gsvc, new(jobsmocks.Service), new(qmocks.Service), authn, mux, logger, "", api.PageLimits{})
 
 	return httptest.NewServer(mux), svc, gsvc, authn
 }
 
+func newJobsServer() (*httptest.Server, *jobsmocks.Service, *authnmocks.Authentication) {
+	jsvc := new(jobsmocks.Service)
+	authn := new(authnmocks.Authentication)
+
+	logger := mglog.NewMock()
+	mux := chi.NewRouter()
+	httpapi.MakeHandler(new(mocks.Service), new(gmocks.Service), jsvc, new(qmocks.Service), authn, mux, logger, "", api.PageLimits{})
+
+	return httptest.NewServer(mux), jsvc, authn
+}
+
 func TestCreateThing(t *testing.T) {
 	ts, svc, _, authn := newThingsServer()
 	defer ts.Close()
@@ -416,6 +431,185 @@ func TestCreateThings(t *testing.T) {
 	}
 }
 
+func TestCreateThingsAsync(t *testing.T) {
+	ts, jsvc, authn := newJobsServer()
+	defer ts.Close()
+
+	items := []mgclients.Client{{Name: namesgen.Generate()}, {Name: namesgen.Generate()}}
+	session := mgauthn.Session{DomainUserID: domainID + "_" + validID, UserID: validID, DomainID: domainID}
+	job := jobs.Job{ID: testsutil.GenerateUUID(t), Kind: things.CreateThingsJob, UserID: validID, DomainID: domainID, Status: jobs.PendingStatus, Total: 2}
+
+	cases := []struct {
+		desc   string
+		query  string
+		job    jobs.Job
+		status int
+		err    error
+	}{
+		{
+			desc:   "submit create things job",
+			query:  "async=true",
+			job:    job,
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "submit create things job with invalid async",
+			query:  "async=invalid",
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrValidation,
+		},
+		{
+			desc:   "submit create things job with service error",
+			query:  "async=true",
+			status: http.StatusUnprocessableEntity,
+			err:    svcerr.ErrCreateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      ts.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/%s/things/bulk?%s", ts.URL, domainID, tc.query),
+				contentType: contentType,
+				token:       validToken,
+				body:        strings.NewReader(toJSON(items)),
+			}
+
+			authCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := jsvc.On("Submit", mock.Anything, session, things.CreateThingsJob, uint64(len(items)), mock.Anything).Return(tc.job, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.err == nil {
+				var resJob jobs.Job
+				err = json.NewDecoder(res.Body).Decode(&resJob)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+				assert.Equal(t, tc.job.ID, resJob.ID, fmt.Sprintf("%s: expected job %s got %s", tc.desc, tc.job.ID, resJob.ID))
+				assert.Equal(t, fmt.Sprintf("/jobs/%s", tc.job.ID), res.Header.Get("Location"), fmt.Sprintf("%s: unexpected location", tc.desc))
+			}
+			svcCall.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
+func TestViewJob(t *testing.T) {
+	ts, jsvc, authn := newJobsServer()
+	defer ts.Close()
+
+	session := mgauthn.Session{UserID: validID}
+	job := jobs.Job{ID: testsutil.GenerateUUID(t), Kind: things.CreateThingsJob, UserID: validID, Status: jobs.RunningStatus, Total: 10, Processed: 5}
+
+	cases := []struct {
+		desc   string
+		id     string
+		job    jobs.Job
+		status int
+		err    error
+	}{
+		{
+			desc:   "view job",
+			id:     job.ID,
+			job:    job,
+			status: http.StatusOK,
+		},
+		{
+			desc:   "view non-existing job",
+			id:     validID,
+			status: http.StatusNotFound,
+			err:    svcerr.ErrNotFound,
+		},
+		{
+			desc:   "view job of another user",
+			id:     job.ID,
+			status: http.StatusForbidden,
+			err:    svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: ts.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/jobs/%s", ts.URL, tc.id),
+				token:  validToken,
+			}
+
+			authCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := jsvc.On("View", mock.Anything, session, tc.id).Return(tc.job, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.err == nil {
+				var resJob jobs.Job
+				err = json.NewDecoder(res.Body).Decode(&resJob)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+				assert.Equal(t, tc.job.Processed, resJob.Processed, fmt.Sprintf("%s: expected processed %d got %d", tc.desc, tc.job.Processed, resJob.Processed))
+				assert.Equal(t, tc.job.Status, resJob.Status, fmt.Sprintf("%s: expected status %s got %s", tc.desc, tc.job.Status, resJob.Status))
+			}
+			svcCall.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	ts, jsvc, authn := newJobsServer()
+	defer ts.Close()
+
+	session := mgauthn.Session{UserID: validID}
+	job := jobs.Job{ID: testsutil.GenerateUUID(t), Kind: things.CreateThingsJob, UserID: validID, Status: jobs.CanceledStatus, Total: 10, Processed: 5}
+
+	cases := []struct {
+		desc   string
+		id     string
+		job    jobs.Job
+		status int
+		err    error
+	}{
+		{
+			desc:   "cancel running job",
+			id:     job.ID,
+			job:    job,
+			status: http.StatusOK,
+		},
+		{
+			desc:   "cancel finished job",
+			id:     job.ID,
+			status: http.StatusConflict,
+			err:    jobs.ErrFinished,
+		},
+		{
+			desc:   "cancel non-existing job",
+			id:     validID,
+			status: http.StatusNotFound,
+			err:    svcerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: ts.Client(),
+				method: http.MethodDelete,
+				url:    fmt.Sprintf("%s/jobs/%s", ts.URL, tc.id),
+				token:  validToken,
+			}
+
+			authCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := jsvc.On("Cancel", mock.Anything, session, tc.id).Return(tc.job, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
 func TestListThings(t *testing.T) {
 	ts, svc, _, authn := newThingsServer()
 	defer ts.Close()
@@ -1385,6 +1579,100 @@ func TestUpdateClientSecret(t *testing.T) {
 	}
 }
 
+func TestRotateThingKey(t *testing.T) {
+	ts, svc, _, authn := newThingsServer()
+	defer ts.Close()
+
+	newKey := "new-key"
+	rotated := client
+	rotated.Credentials.Secret = newKey
+
+	cases := []struct {
+		desc     string
+		id       string
+		response mgclients.Client
+		domainID string
+		token    string
+		status   int
+		authnRes mgauthn.Session
+		authnErr error
+		svcErr   error
+		err      error
+	}{
+		{
+			desc:     "rotate thing key with valid token",
+			id:       client.ID,
+			response: rotated,
+			domainID: domainID,
+			token:    validToken,
+			authnRes: mgauthn.Session{DomainUserID: domainID + "_" + validID, UserID: validID, DomainID: domainID},
+			status:   http.StatusOK,
+			err:      nil,
+		},
+		{
+			desc:     "rotate thing key with invalid token",
+			id:       client.ID,
+			domainID: domainID,
+			token:    inValidToken,
+			status:   http.StatusUnauthorized,
+			authnErr: svcerr.ErrAuthentication,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:     "rotate thing key without permission",
+			id:       client.ID,
+			domainID: domainID,
+			token:    validToken,
+			authnRes: mgauthn.Session{DomainUserID: domainID + "_" + validID, UserID: validID, DomainID: domainID},
+			status:   http.StatusForbidden,
+			svcErr:   svcerr.ErrAuthorization,
+			err:      svcerr.ErrAuthorization,
+		},
+		{
+			desc:     "rotate key of disabled thing",
+			id:       client.ID,
+			domainID: domainID,
+			token:    validToken,
+			authnRes: mgauthn.Session{DomainUserID: domainID + "_" + validID, UserID: validID, DomainID: domainID},
+			status:   http.StatusUnprocessableEntity,
+			svcErr:   svcerr.ErrUpdateEntity,
+			err:      svcerr.ErrUpdateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: ts.Client(),
+				method: http.MethodPost,
+				url:    fmt.Sprintf("%s/%s/things/%s/key/rotate", ts.URL, tc.domainID, tc.id),
+				token:  tc.token,
+			}
+
+			authCall := authn.On("Authenticate", mock.Anything, tc.token).Return(tc.authnRes, tc.authnErr)
+			svcCall := svc.On("RotateKey", mock.Anything, tc.authnRes, tc.id).Return(tc.response, tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody struct {
+				respBody
+				Credentials mgclients.Credentials `json:"credentials"`
+			}
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			if err == nil {
+				assert.Equal(t, newKey, resBody.Credentials.Secret, fmt.Sprintf("%s: expected key %s got %s\n", tc.desc, newKey, resBody.Credentials.Secret))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+			authCall.Unset()
+		})
+	}
+}
+
 func TestEnableThing(t *testing.T) {
 	ts, svc, _, authn := newThingsServer()
 	defer ts.Close()
@@ -0,0 +1,53 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	mgauthn "github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/jobs"
+	"github.com/go-chi/chi/v5"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+func jobsHandler(jsvc jobs.Service, authn mgauthn.Authentication, r *chi.Mux, logger *slog.Logger) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, api.EncodeError)),
+	}
+	r.Group(func(r chi.Router) {
+		r.Use(api.AuthenticateMiddleware(authn, false))
+
+		r.Route("/jobs", func(r chi.Router) {
+			r.Get("/{jobID}", otelhttp.NewHandler(kithttp.NewServer(
+				viewJobEndpoint(jsvc),
+				decodeJobReq,
+				api.EncodeResponse,
+				opts...,
+			), "view_job").ServeHTTP)
+
+			r.Delete("/{jobID}", otelhttp.NewHandler(kithttp.NewServer(
+				cancelJobEndpoint(jsvc),
+				decodeJobReq,
+				api.EncodeResponse,
+				opts...,
+			), "cancel_job").ServeHTTP)
+		})
+	})
+
+	return r
+}
+
+func decodeJobReq(_ context.Context, r *http.Request) (interface{}, error) {
+	req := jobReq{
+		id: chi.URLParam(r, "jobID"),
+	}
+
+	return req, nil
+}
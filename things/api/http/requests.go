@@ -9,6 +9,8 @@ import (
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 )
 
+var pageLimits = api.NewPageLimits(api.DefLimit, api.MaxLimitSize)
+
 type createClientReq struct {
 	client mgclients.Client
 }
@@ -26,6 +28,7 @@ func (req createClientReq) validate() error {
 
 type createClientsReq struct {
 	Clients []mgclients.Client
+	async   bool
 }
 
 func (req createClientsReq) validate() error {
@@ -82,12 +85,22 @@ type listClientsReq struct {
 	listPerms  bool
 	metadata   mgclients.Metadata
 	id         string
+	ids        []string
+	unfiltered bool
 }
 
 func (req listClientsReq) validate() error {
-	if req.limit > api.MaxLimitSize || req.limit < 1 {
+	if req.limit > pageLimits.Max || req.limit < 1 {
+		return apiutil.ErrLimitSize
+	}
+	if uint64(len(req.ids)) > pageLimits.Max {
 		return apiutil.ErrLimitSize
 	}
+	for _, id := range req.ids {
+		if err := api.ValidateUUID(id); err != nil {
+			return err
+		}
+	}
 	if req.visibility != "" &&
 		req.visibility != api.AllVisibility &&
 		req.visibility != api.MyVisibility &&
@@ -163,6 +176,30 @@ func (req updateClientCredentialsReq) validate() error {
 	return nil
 }
 
+type rotateKeyReq struct {
+	id string
+}
+
+func (req rotateKeyReq) validate() error {
+	if req.id == "" {
+		return apiutil.ErrMissingID
+	}
+
+	return nil
+}
+
+type jobReq struct {
+	id string
+}
+
+func (req jobReq) validate() error {
+	if req.id == "" {
+		return apiutil.ErrMissingID
+	}
+
+	return nil
+}
+
 type changeClientStatusReq struct {
 	id string
 }
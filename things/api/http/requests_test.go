@@ -226,6 +226,22 @@ func TestListClientsReqValidate(t *testing.T) {
 			},
 			err: apiutil.ErrNameSize,
 		},
+		{
+			desc: "valid ids",
+			req: listClientsReq{
+				limit: 10,
+				ids:   []string{validID},
+			},
+			err: nil,
+		},
+		{
+			desc: "invalid id format",
+			req: listClientsReq{
+				limit: 10,
+				ids:   []string{validID, "invalid'"},
+			},
+			err: apiutil.ErrInvalidIDFormat,
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
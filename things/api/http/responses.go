@@ -9,6 +9,7 @@ import (
 
 	"github.com/absmach/magistrala"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/jobs"
 )
 
 var (
@@ -23,12 +24,14 @@ var (
 	_ magistrala.Response = (*connectChannelThingRes)(nil)
 	_ magistrala.Response = (*disconnectChannelThingRes)(nil)
 	_ magistrala.Response = (*changeClientStatusRes)(nil)
+	_ magistrala.Response = (*jobRes)(nil)
 )
 
 type pageRes struct {
-	Limit  uint64 `json:"limit,omitempty"`
-	Offset uint64 `json:"offset"`
-	Total  uint64 `json:"total"`
+	Limit           uint64  `json:"limit,omitempty"`
+	Offset          uint64  `json:"offset"`
+	Total           uint64  `json:"total"`
+	TotalUnfiltered *uint64 `json:"total_unfiltered,omitempty"`
 }
 
 type createClientRes struct {
@@ -308,3 +311,30 @@ func (res thingUnshareRes) Headers() map[string]string {
 func (res thingUnshareRes) Empty() bool {
 	return true
 }
+
+type jobRes struct {
+	jobs.Job
+	submitted bool
+}
+
+func (res jobRes) Code() int {
+	if res.submitted {
+		return http.StatusAccepted
+	}
+
+	return http.StatusOK
+}
+
+func (res jobRes) Headers() map[string]string {
+	if res.submitted {
+		return map[string]string{
+			"Location": fmt.Sprintf("/jobs/%s", res.ID),
+		}
+	}
+
+	return map[string]string{}
+}
+
+func (res jobRes) Empty() bool {
+	return false
+}
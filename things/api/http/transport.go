@@ -8,19 +8,25 @@ import (
 	"net/http"
 
 	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/internal/api"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
 	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/jobs"
+	"github.com/absmach/magistrala/pkg/quotas"
+	quotasapi "github.com/absmach/magistrala/pkg/quotas/api"
 	"github.com/absmach/magistrala/things"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// MakeHandler returns a HTTP handler for Things and Groups API endpoints.
-func MakeHandler(tsvc things.Service, grps groups.Service, authn mgauthn.Authentication, mux *chi.Mux, logger *slog.Logger, instanceID string) http.Handler {
-	clientsHandler(tsvc, mux, authn, logger)
-	groupsHandler(grps, authn, mux, logger)
+// MakeHandler returns a HTTP handler for Things, Groups, Jobs and Quotas API endpoints.
+func MakeHandler(tsvc things.Service, grps groups.Service, jsvc jobs.Service, qsvc quotas.Service, authn mgauthn.Authentication, mux *chi.Mux, logger *slog.Logger, instanceID string, pl api.PageLimits, healthOpts ...magistrala.HealthOption) http.Handler {
+	clientsHandler(tsvc, jsvc, mux, authn, logger, pl)
+	groupsHandler(grps, authn, mux, logger, pl)
+	jobsHandler(jsvc, authn, mux, logger)
+	quotasapi.MakeHandler(qsvc, authn, mux, logger)
 
-	mux.Get("/health", magistrala.Health("things", instanceID))
+	mux.Get("/health", magistrala.Health("things", instanceID, healthOpts...))
 	mux.Handle("/metrics", promhttp.Handler())
 
 	return mux
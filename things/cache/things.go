@@ -5,9 +5,11 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	"github.com/absmach/magistrala/things"
@@ -15,8 +17,9 @@ import (
 )
 
 const (
-	keyPrefix = "thing_key"
-	idPrefix  = "thing_id"
+	keyPrefix   = "thing_key"
+	idPrefix    = "thing_id"
+	thingPrefix = "thing"
 )
 
 var _ things.Cache = (*thingCache)(nil)
@@ -65,7 +68,51 @@ func (tc *thingCache) ID(ctx context.Context, thingKey string) (string, error) {
 	return thingID, nil
 }
 
+// cachedThing holds the thing fields that are used to authorize its messages.
+type cachedThing struct {
+	Domain   string             `json:"domain"`
+	Metadata mgclients.Metadata `json:"metadata,omitempty"`
+}
+
+func (tc *thingCache) SaveThing(ctx context.Context, thing mgclients.Client) error {
+	if thing.ID == "" {
+		return errors.Wrap(repoerr.ErrCreateEntity, errors.New("thing id is empty"))
+	}
+	data, err := json.Marshal(cachedThing{Domain: thing.Domain, Metadata: thing.Metadata})
+	if err != nil {
+		return errors.Wrap(repoerr.ErrCreateEntity, err)
+	}
+	tkey := fmt.Sprintf("%s:%s", thingPrefix, thing.ID)
+	if err := tc.client.Set(ctx, tkey, data, tc.keyDuration).Err(); err != nil {
+		return errors.Wrap(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (tc *thingCache) Thing(ctx context.Context, thingID string) (mgclients.Client, error) {
+	if thingID == "" {
+		return mgclients.Client{}, repoerr.ErrNotFound
+	}
+
+	tkey := fmt.Sprintf("%s:%s", thingPrefix, thingID)
+	data, err := tc.client.Get(ctx, tkey).Bytes()
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrNotFound, err)
+	}
+	var ct cachedThing
+	if err := json.Unmarshal(data, &ct); err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrNotFound, err)
+	}
+
+	return mgclients.Client{ID: thingID, Domain: ct.Domain, Metadata: ct.Metadata}, nil
+}
+
 func (tc *thingCache) Remove(ctx context.Context, thingID string) error {
+	if err := tc.client.Del(ctx, fmt.Sprintf("%s:%s", thingPrefix, thingID)).Err(); err != nil {
+		return errors.Wrap(repoerr.ErrRemoveEntity, err)
+	}
+
 	tid := fmt.Sprintf("%s:%s", idPrefix, thingID)
 	key, err := tc.client.Get(ctx, tid).Result()
 	// Redis returns Nil Reply when key does not exist.
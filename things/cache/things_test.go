@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	"github.com/absmach/magistrala/things/cache"
@@ -137,6 +138,55 @@ func TestID(t *testing.T) {
 	}
 }
 
+func TestThing(t *testing.T) {
+	redisClient.FlushAll(context.Background())
+	tscache := cache.NewCache(redisClient, 1*time.Minute)
+	ctx := context.Background()
+
+	thing := mgclients.Client{ID: testID, Domain: testID2, Metadata: mgclients.Metadata{"rate_limit": map[string]interface{}{"rate": 0.5}}}
+	err := tscache.SaveThing(ctx, thing)
+	assert.Nil(t, err, fmt.Sprintf("Unexpected error while trying to save: %s", err))
+	err = tscache.SaveThing(ctx, mgclients.Client{})
+	assert.True(t, errors.Contains(err, repoerr.ErrCreateEntity), fmt.Sprintf("save thing with empty id: expected %s got %s\n", repoerr.ErrCreateEntity, err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		thing mgclients.Client
+		err   error
+	}{
+		{
+			desc:  "Get thing from cache",
+			id:    testID,
+			thing: thing,
+			err:   nil,
+		},
+		{
+			desc: "Get thing from cache for non existing thing",
+			id:   testID2,
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "Get thing from cache for empty id",
+			id:   "",
+			err:  repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		th, err := tscache.Thing(ctx, tc.id)
+		if err == nil {
+			assert.Equal(t, tc.thing, th, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.thing, th))
+		}
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	err = tscache.Remove(ctx, testID)
+	assert.Nil(t, err, fmt.Sprintf("Unexpected error while trying to remove: %s", err))
+	_, err = tscache.Thing(ctx, testID)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("get removed thing: expected %s got %s\n", repoerr.ErrNotFound, err))
+}
+
 func TestRemove(t *testing.T) {
 	redisClient.FlushAll(context.Background())
 	tscache := cache.NewCache(redisClient, 1*time.Minute)
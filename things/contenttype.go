@@ -0,0 +1,95 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+// AllowedContentTypesKey is the channel metadata key holding the list of the
+// content types the channel accepts. A missing or empty list accepts any
+// content type.
+const AllowedContentTypesKey = "allowed_content_types"
+
+// DefaultContentTypeKey is the channel metadata key holding the content type
+// of the messages published without one, such as over MQTT 3.1.1.
+const DefaultContentTypeKey = "default_content_type"
+
+var (
+	// ErrContentTypeNotAllowed indicates that the channel doesn't accept
+	// messages of the published content type.
+	ErrContentTypeNotAllowed = errors.New("content type is not allowed on the channel")
+
+	errInvalidContentTypes = errors.New("allowed content types of the channel must be a list of strings")
+	errMissingContentType  = errors.New("content type is required on the channel")
+)
+
+// checkContentType checks that the channel accepts the content type. Media
+// type parameters, such as the charset, are ignored. Messages without the
+// content type are checked against the default content type of the channel,
+// and are rejected if the channel restricts the content types without
+// setting the default one.
+func (svc service) checkContentType(ctx context.Context, channelID, contentType string) error {
+	ch, err := svc.grepo.RetrieveByID(ctx, channelID)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	allowed, err := allowedContentTypes(ch.Metadata[AllowedContentTypesKey])
+	if err != nil {
+		return errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	if contentType == "" {
+		contentType, _ = ch.Metadata[DefaultContentTypeKey].(string)
+		if contentType == "" {
+			return errors.Wrap(svcerr.ErrAuthorization, errors.Wrap(ErrContentTypeNotAllowed, errMissingContentType))
+		}
+	}
+	ct := mediaType(contentType)
+	for _, a := range allowed {
+		if mediaType(a) == ct {
+			return nil
+		}
+	}
+
+	return errors.Wrap(svcerr.ErrAuthorization, errors.Wrap(ErrContentTypeNotAllowed, fmt.Errorf("%s is not one of %s", ct, strings.Join(allowed, ", "))))
+}
+
+func allowedContentTypes(val interface{}) ([]string, error) {
+	switch val := val.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return val, nil
+	case []interface{}:
+		cts := make([]string, 0, len(val))
+		for _, v := range val {
+			ct, ok := v.(string)
+			if !ok {
+				return nil, errInvalidContentTypes
+			}
+			cts = append(cts, ct)
+		}
+		return cts, nil
+	default:
+		return nil, errInvalidContentTypes
+	}
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	return mt
+}
@@ -15,6 +15,7 @@ const (
 	clientCreate       = clientPrefix + "create"
 	clientUpdate       = clientPrefix + "update"
 	clientChangeStatus = clientPrefix + "change_status"
+	clientRotateKey    = clientPrefix + "rotate_key"
 	clientRemove       = clientPrefix + "remove"
 	clientView         = clientPrefix + "view"
 	clientViewPerms    = clientPrefix + "view_perms"
@@ -28,6 +29,7 @@ var (
 	_ events.Event = (*createClientEvent)(nil)
 	_ events.Event = (*updateClientEvent)(nil)
 	_ events.Event = (*changeStatusClientEvent)(nil)
+	_ events.Event = (*rotateKeyClientEvent)(nil)
 	_ events.Event = (*viewClientEvent)(nil)
 	_ events.Event = (*viewClientPermsEvent)(nil)
 	_ events.Event = (*listClientEvent)(nil)
@@ -112,6 +114,23 @@ func (uce updateClientEvent) Encode() (map[string]interface{}, error) {
 	return val, nil
 }
 
+// rotateKeyClientEvent tells the consumers caching thing keys to drop the
+// old key. The new key is not part of the event.
+type rotateKeyClientEvent struct {
+	id        string
+	updatedAt time.Time
+	updatedBy string
+}
+
+func (rce rotateKeyClientEvent) Encode() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"operation":  clientRotateKey,
+		"id":         rce.id,
+		"updated_at": rce.updatedAt,
+		"updated_by": rce.updatedBy,
+	}, nil
+}
+
 type changeStatusClientEvent struct {
 	id        string
 	status    string
@@ -81,6 +81,24 @@ func (es *eventStore) UpdateClientSecret(ctx context.Context, session authn.Sess
 	return es.update(ctx, "secret", cli)
 }
 
+func (es *eventStore) RotateKey(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	cli, err := es.svc.RotateKey(ctx, session, id)
+	if err != nil {
+		return cli, err
+	}
+
+	event := rotateKeyClientEvent{
+		id:        cli.ID,
+		updatedAt: cli.UpdatedAt,
+		updatedBy: cli.UpdatedBy,
+	}
+	if err := es.Publish(ctx, event); err != nil {
+		return cli, err
+	}
+
+	return cli, nil
+}
+
 func (es *eventStore) update(ctx context.Context, operation string, thing mgclients.Client) (mgclients.Client, error) {
 	event := updateClientEvent{
 		thing, operation,
@@ -203,23 +221,23 @@ func (es *eventStore) Identify(ctx context.Context, key string) (string, error)
 	return thingID, nil
 }
 
-func (es *eventStore) Authorize(ctx context.Context, req things.AuthzReq) (string, error) {
-	thingID, err := es.svc.Authorize(ctx, req)
+func (es *eventStore) Authorize(ctx context.Context, req things.AuthzReq) (things.AuthzRes, error) {
+	res, err := es.svc.Authorize(ctx, req)
 	if err != nil {
-		return thingID, err
+		return res, err
 	}
 
 	event := authorizeClientEvent{
-		thingID:    thingID,
+		thingID:    res.ThingID,
 		channelID:  req.ChannelID,
 		permission: req.Permission,
 	}
 
 	if err := es.Publish(ctx, event); err != nil {
-		return thingID, err
+		return res, err
 	}
 
-	return thingID, nil
+	return res, nil
 }
 
 func (es *eventStore) Share(ctx context.Context, session authn.Session, id, relation string, userids ...string) error {
@@ -0,0 +1,54 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"context"
+	"fmt"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+// GatewayKey is the thing metadata key holding the ID of the gateway thing
+// which may publish the messages of the thing on its behalf.
+const GatewayKey = "gateway"
+
+// ErrNotRepresented indicates that the thing doesn't allow the gateway to
+// publish on its behalf.
+var ErrNotRepresented = errors.New("thing is not represented by the gateway")
+
+var errInvalidGateway = errors.New("invalid thing gateway")
+
+// parseGateway parses the gateway thing ID from the thing metadata value. It
+// returns empty ID if the thing has no gateway.
+func parseGateway(val interface{}) (string, error) {
+	if val == nil {
+		return "", nil
+	}
+	id, ok := val.(string)
+	if !ok || id == "" {
+		return "", errors.Wrap(errInvalidGateway, fmt.Errorf("gateway must be a thing ID"))
+	}
+
+	return id, nil
+}
+
+// representedThing returns the enabled thing on whose behalf the gateway
+// publishes, if the thing names the gateway in its metadata.
+func (svc service) representedThing(ctx context.Context, gatewayID, id string) (mgclients.Client, error) {
+	thing, err := svc.clients.RetrieveByID(ctx, id)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	if thing.Status != mgclients.EnabledStatus {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthorization, ErrNotRepresented)
+	}
+	if gateway, err := parseGateway(thing.Metadata[GatewayKey]); err != nil || gateway != gatewayID {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthorization, ErrNotRepresented)
+	}
+
+	return thing, nil
+}
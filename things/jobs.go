@@ -0,0 +1,122 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/jobs"
+	"github.com/gofrs/uuid/v5"
+)
+
+// CreateThingsJob is the kind of the jobs creating things in bulk.
+const CreateThingsJob = "things.create"
+
+// CreateThingsPayload is the payload of the jobs creating things in bulk.
+// The things are created on behalf of the user and in the domain of the job.
+type CreateThingsPayload struct {
+	Things []mgclients.Client `json:"things"`
+}
+
+// NewCreateThingsHandler returns the handler of the jobs creating things in
+// bulk. The things are created in batches of batchSize things, so the job
+// reports progress and can be canceled between the batches. The created
+// things are the job results.
+//
+// The things without an ID get the ID derived from the job ID and their
+// position, so the batch which was created, but whose progress was not
+// saved before the job was interrupted, is found instead of being created
+// again once the job resumes.
+func NewCreateThingsHandler(svc Service, batchSize int) jobs.Handler {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	return func(ctx context.Context, job jobs.Job, progress jobs.ProgressFunc) error {
+		var payload CreateThingsPayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		session := authn.Session{
+			UserID:       job.UserID,
+			DomainID:     job.DomainID,
+			DomainUserID: auth.EncodeDomainUserID(job.DomainID, job.UserID),
+		}
+		ns := uuid.FromStringOrNil(job.ID)
+		for i := range payload.Things {
+			if payload.Things[i].ID == "" {
+				payload.Things[i].ID = uuid.NewV5(ns, strconv.Itoa(i)).String()
+			}
+		}
+
+		for start := int(job.Processed); start < len(payload.Things); start += batchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := min(start+batchSize, len(payload.Things))
+			batch := payload.Things[start:end]
+
+			var created []mgclients.Client
+			if start == int(job.Processed) {
+				existing, err := createdBatch(ctx, svc, session, job.CreatedAt, batch)
+				if err != nil {
+					return err
+				}
+				created = existing
+			}
+			if created == nil {
+				var err error
+				if created, err = svc.CreateThings(ctx, session, batch...); err != nil {
+					return err
+				}
+			}
+
+			results := make([]interface{}, len(created))
+			for i, c := range created {
+				results[i] = c
+			}
+			if err := progress(uint64(end), results...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// createdBatch returns the things of the batch if the batch was created by
+// the job before it was interrupted, or nil if it wasn't. The things of a
+// batch are saved together, so the batch was created if its first thing was
+// created after the job was submitted.
+func createdBatch(ctx context.Context, svc Service, session authn.Session, submitted time.Time, batch []mgclients.Client) ([]mgclients.Client, error) {
+	first, err := svc.ViewClient(ctx, session, batch[0].ID)
+	switch {
+	case errors.Contains(err, repoerr.ErrNotFound), errors.Contains(err, svcerr.ErrAuthorization):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case first.CreatedAt.Before(submitted):
+		return nil, nil
+	}
+
+	created := []mgclients.Client{first}
+	for _, th := range batch[1:] {
+		c, err := svc.ViewClient(ctx, session, th.ID)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, c)
+	}
+
+	return created, nil
+}
@@ -117,6 +117,17 @@ func (am *authorizationMiddleware) UpdateClientSecret(ctx context.Context, sessi
 	return am.svc.UpdateClientSecret(ctx, session, id, key)
 }
 
+func (am *authorizationMiddleware) RotateKey(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
+	if session.DomainUserID == "" {
+		return clients.Client{}, svcerr.ErrDomainAuthorization
+	}
+	if err := am.authorize(ctx, session.DomainID, policies.UserType, policies.UsersKind, session.DomainUserID, policies.EditPermission, policies.ThingType, id); err != nil {
+		return clients.Client{}, err
+	}
+
+	return am.svc.RotateKey(ctx, session, id)
+}
+
 func (am *authorizationMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
 	if session.DomainUserID == "" {
 		return clients.Client{}, svcerr.ErrDomainAuthorization
@@ -159,7 +170,7 @@ func (am *authorizationMiddleware) Identify(ctx context.Context, key string) (st
 	return am.svc.Identify(ctx, key)
 }
 
-func (am *authorizationMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (string, error) {
+func (am *authorizationMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (things.AuthzRes, error) {
 	return am.svc.Authorize(ctx, req)
 }
 
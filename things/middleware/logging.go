@@ -155,6 +155,25 @@ func (lm *loggingMiddleware) UpdateClientSecret(ctx context.Context, session aut
 	return lm.svc.UpdateClientSecret(ctx, session, oldSecret, newSecret)
 }
 
+func (lm *loggingMiddleware) RotateKey(ctx context.Context, session authn.Session, id string) (c mgclients.Client, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("thing",
+				slog.String("id", id),
+				slog.String("name", c.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Rotate thing key failed", args...)
+			return
+		}
+		lm.logger.Info("Rotate thing key completed successfully", args...)
+	}(time.Now())
+	return lm.svc.RotateKey(ctx, session, id)
+}
+
 func (lm *loggingMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (c mgclients.Client, err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -230,7 +249,7 @@ func (lm *loggingMiddleware) Identify(ctx context.Context, key string) (id strin
 	return lm.svc.Identify(ctx, key)
 }
 
-func (lm *loggingMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (id string, err error) {
+func (lm *loggingMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (res things.AuthzRes, err error) {
 	defer func(begin time.Time) {
 		args := []any{
 			slog.String("duration", time.Since(begin).String()),
@@ -238,6 +257,7 @@ func (lm *loggingMiddleware) Authorize(ctx context.Context, req things.AuthzReq)
 			slog.String("thingID", req.ThingKey),
 			slog.String("channelID", req.ChannelID),
 			slog.String("permission", req.Permission),
+			slog.String("content_type", req.ContentType),
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
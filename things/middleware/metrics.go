@@ -86,6 +86,14 @@ func (ms *metricsMiddleware) UpdateClientSecret(ctx context.Context, session aut
 	return ms.svc.UpdateClientSecret(ctx, session, oldSecret, newSecret)
 }
 
+func (ms *metricsMiddleware) RotateKey(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "rotate_thing_key").Add(1)
+		ms.latency.With("method", "rotate_thing_key").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.RotateKey(ctx, session, id)
+}
+
 func (ms *metricsMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "enable_thing").Add(1)
@@ -118,7 +126,7 @@ func (ms *metricsMiddleware) Identify(ctx context.Context, key string) (string,
 	return ms.svc.Identify(ctx, key)
 }
 
-func (ms *metricsMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (id string, err error) {
+func (ms *metricsMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (res things.AuthzRes, err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "authorize").Add(1)
 		ms.latency.With("method", "authorize").Observe(time.Since(begin).Seconds())
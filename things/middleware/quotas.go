@@ -0,0 +1,98 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/quotas"
+	"github.com/absmach/magistrala/things"
+)
+
+var _ things.Service = (*quotasMiddleware)(nil)
+
+type quotasMiddleware struct {
+	svc    things.Service
+	quotas quotas.Service
+}
+
+// QuotasMiddleware enforces the domain things quota.
+func QuotasMiddleware(svc things.Service, qsvc quotas.Service) things.Service {
+	return &quotasMiddleware{
+		svc:    svc,
+		quotas: qsvc,
+	}
+}
+
+func (qm *quotasMiddleware) CreateThings(ctx context.Context, session authn.Session, clients ...mgclients.Client) ([]mgclients.Client, error) {
+	release, err := qm.quotas.Reserve(ctx, session.DomainID, quotas.ThingsKind, uint64(len(clients)))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return qm.svc.CreateThings(ctx, session, clients...)
+}
+
+func (qm *quotasMiddleware) ViewClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	return qm.svc.ViewClient(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) ViewClientPerms(ctx context.Context, session authn.Session, id string) ([]string, error) {
+	return qm.svc.ViewClientPerms(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) ListClients(ctx context.Context, session authn.Session, reqUserID string, pm mgclients.Page) (mgclients.ClientsPage, error) {
+	return qm.svc.ListClients(ctx, session, reqUserID, pm)
+}
+
+func (qm *quotasMiddleware) UpdateClient(ctx context.Context, session authn.Session, client mgclients.Client) (mgclients.Client, error) {
+	return qm.svc.UpdateClient(ctx, session, client)
+}
+
+func (qm *quotasMiddleware) UpdateClientTags(ctx context.Context, session authn.Session, client mgclients.Client) (mgclients.Client, error) {
+	return qm.svc.UpdateClientTags(ctx, session, client)
+}
+
+func (qm *quotasMiddleware) UpdateClientSecret(ctx context.Context, session authn.Session, oldSecret, newSecret string) (mgclients.Client, error) {
+	return qm.svc.UpdateClientSecret(ctx, session, oldSecret, newSecret)
+}
+
+func (qm *quotasMiddleware) RotateKey(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	return qm.svc.RotateKey(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	return qm.svc.EnableClient(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) DisableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	return qm.svc.DisableClient(ctx, session, id)
+}
+
+func (qm *quotasMiddleware) ListClientsByGroup(ctx context.Context, session authn.Session, groupID string, pm mgclients.Page) (mp mgclients.MembersPage, err error) {
+	return qm.svc.ListClientsByGroup(ctx, session, groupID, pm)
+}
+
+func (qm *quotasMiddleware) Identify(ctx context.Context, key string) (string, error) {
+	return qm.svc.Identify(ctx, key)
+}
+
+func (qm *quotasMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (res things.AuthzRes, err error) {
+	return qm.svc.Authorize(ctx, req)
+}
+
+func (qm *quotasMiddleware) Share(ctx context.Context, session authn.Session, id, relation string, userids ...string) error {
+	return qm.svc.Share(ctx, session, id, relation, userids...)
+}
+
+func (qm *quotasMiddleware) Unshare(ctx context.Context, session authn.Session, id, relation string, userids ...string) error {
+	return qm.svc.Unshare(ctx, session, id, relation, userids...)
+}
+
+func (qm *quotasMiddleware) DeleteClient(ctx context.Context, session authn.Session, id string) error {
+	return qm.svc.DeleteClient(ctx, session, id)
+}
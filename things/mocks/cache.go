@@ -7,6 +7,8 @@ package mocks
 import (
 	context "context"
 
+	clients "github.com/absmach/magistrala/pkg/clients"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -79,6 +81,52 @@ func (_m *Cache) Save(ctx context.Context, thingSecret string, thingID string) e
 	return r0
 }
 
+// SaveThing provides a mock function with given fields: ctx, thing
+func (_m *Cache) SaveThing(ctx context.Context, thing clients.Client) error {
+	ret := _m.Called(ctx, thing)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveThing")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, clients.Client) error); ok {
+		r0 = rf(ctx, thing)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Thing provides a mock function with given fields: ctx, thingID
+func (_m *Cache) Thing(ctx context.Context, thingID string) (clients.Client, error) {
+	ret := _m.Called(ctx, thingID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Thing")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (clients.Client, error)); ok {
+		return rf(ctx, thingID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) clients.Client); ok {
+		r0 = rf(ctx, thingID)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, thingID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewCache creates a new instance of Cache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCache(t interface {
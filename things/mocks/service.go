@@ -21,22 +21,22 @@ type Service struct {
 }
 
 // Authorize provides a mock function with given fields: ctx, req
-func (_m *Service) Authorize(ctx context.Context, req things.AuthzReq) (string, error) {
+func (_m *Service) Authorize(ctx context.Context, req things.AuthzReq) (things.AuthzRes, error) {
 	ret := _m.Called(ctx, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Authorize")
 	}
 
-	var r0 string
+	var r0 things.AuthzRes
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, things.AuthzReq) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, things.AuthzReq) (things.AuthzRes, error)); ok {
 		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, things.AuthzReq) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, things.AuthzReq) things.AuthzRes); ok {
 		r0 = rf(ctx, req)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(things.AuthzRes)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, things.AuthzReq) error); ok {
@@ -243,6 +243,34 @@ func (_m *Service) ListClientsByGroup(ctx context.Context, session authn.Session
 	return r0, r1
 }
 
+// RotateKey provides a mock function with given fields: ctx, session, id
+func (_m *Service) RotateKey(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
+	ret := _m.Called(ctx, session, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateKey")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string) (clients.Client, error)); ok {
+		return rf(ctx, session, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string) clients.Client); ok {
+		r0 = rf(ctx, session, id)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, string) error); ok {
+		r1 = rf(ctx, session, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Share provides a mock function with given fields: ctx, session, id, relation, userids
 func (_m *Service) Share(ctx context.Context, session authn.Session, id string, relation string, userids ...string) error {
 	_va := make([]interface{}, len(userids))
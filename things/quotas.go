@@ -0,0 +1,29 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"context"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/quotas"
+	"github.com/absmach/magistrala/things/postgres"
+)
+
+// CountClients returns the counter of the things of a domain, used to
+// enforce the domain things quota.
+func CountClients(repo postgres.Repository) quotas.Counter {
+	return func(ctx context.Context, domainID string) (uint64, error) {
+		page, err := repo.RetrieveAll(ctx, mgclients.Page{
+			Domain: domainID,
+			Status: mgclients.AllStatus,
+			Role:   mgclients.AllRole,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		return page.Total, nil
+	}
+}
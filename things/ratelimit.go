@@ -0,0 +1,47 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// RateLimitKey is the thing metadata key holding the publish rate limit of
+// the thing, which overrides the default rate limit of the adapters.
+const RateLimitKey = "rate_limit"
+
+var errInvalidRateLimit = errors.New("invalid thing rate limit")
+
+// RateLimit is the publish rate limit of the thing, enforced by the adapters.
+type RateLimit struct {
+	// Rate is the number of messages per second the thing may publish.
+	Rate float64 `json:"rate"`
+	// Burst is the number of messages the thing may publish at once. Zero
+	// burst equals the rate rounded up.
+	Burst uint32 `json:"burst,omitempty"`
+}
+
+// parseRateLimit parses the rate limit from the thing metadata value. It
+// returns zero rate limit if the thing has no rate limit.
+func parseRateLimit(val interface{}) (RateLimit, error) {
+	if val == nil {
+		return RateLimit{}, nil
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return RateLimit{}, errors.Wrap(errInvalidRateLimit, err)
+	}
+	var rl RateLimit
+	if err := json.Unmarshal(data, &rl); err != nil {
+		return RateLimit{}, errors.Wrap(errInvalidRateLimit, err)
+	}
+	if rl.Rate <= 0 {
+		return RateLimit{}, errors.Wrap(errInvalidRateLimit, fmt.Errorf("rate must be positive"))
+	}
+
+	return rl, nil
+}
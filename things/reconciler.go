@@ -0,0 +1,35 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"context"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/things/postgres"
+)
+
+// ExistingClients returns the lookup of the things referred to by policies,
+// used to reconcile orphaned policies.
+func ExistingClients(repo postgres.Repository) policies.ExistingFunc {
+	return func(ctx context.Context, ids []string) ([]string, error) {
+		page, err := repo.RetrieveAllByIDs(ctx, mgclients.Page{
+			IDs:    ids,
+			Limit:  uint64(len(ids)),
+			Status: mgclients.AllStatus,
+			Role:   mgclients.AllRole,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		existing := []string{}
+		for _, c := range page.Clients {
+			existing = append(existing, c.ID)
+		}
+
+		return existing, nil
+	}
+}
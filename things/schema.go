@@ -0,0 +1,156 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"encoding/json"
+	"fmt"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/senml"
+)
+
+// SchemaKey is the thing metadata key holding the SenML schema the messages
+// published by the thing must conform to. A thing without a schema may
+// publish any payload.
+const SchemaKey = "schema"
+
+const senmlCBOR = "application/senml+cbor"
+
+var (
+	// ErrSchemaViolation indicates that the published payload doesn't
+	// conform to the schema of the thing.
+	ErrSchemaViolation = errors.New("payload doesn't conform to the thing schema")
+
+	errInvalidSchema = errors.New("invalid thing schema")
+)
+
+// Schema lists the SenML records the thing publishes.
+type Schema struct {
+	Records []SchemaRecord `json:"records"`
+}
+
+// SchemaRecord describes a SenML record, identified by its resolved name.
+type SchemaRecord struct {
+	Name string `json:"name"`
+	// Unit is the unit the record must have, if set.
+	Unit string `json:"unit,omitempty"`
+	// Value is the SenML value field the record must carry, one of v, vs,
+	// vb, vd or s, if set.
+	Value string `json:"value,omitempty"`
+	// Required records must be present in every message.
+	Required bool `json:"required,omitempty"`
+}
+
+// checkSchema checks that the payload published by the thing conforms to its
+// schema.
+func checkSchema(thing mgclients.Client, contentType string, payload []byte) error {
+	schema, err := parseSchema(thing.Metadata[SchemaKey])
+	if err != nil {
+		return errors.Wrap(errors.ErrMalformedEntity, err)
+	}
+	if schema == nil {
+		return nil
+	}
+	if err := schema.Validate(payload, contentType); err != nil {
+		return errors.Wrap(errors.ErrMalformedEntity, err)
+	}
+
+	return nil
+}
+
+// parseSchema parses the schema from the thing metadata value. It returns nil
+// if the thing has no schema.
+func parseSchema(val interface{}) (*Schema, error) {
+	if val == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, errors.Wrap(errInvalidSchema, err)
+	}
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, errors.Wrap(errInvalidSchema, err)
+	}
+	if len(schema.Records) == 0 {
+		return nil, errors.Wrap(errInvalidSchema, fmt.Errorf("schema has no records"))
+	}
+	seen := make(map[string]bool)
+	for _, r := range schema.Records {
+		if r.Name == "" {
+			return nil, errors.Wrap(errInvalidSchema, fmt.Errorf("record without name"))
+		}
+		if seen[r.Name] {
+			return nil, errors.Wrap(errInvalidSchema, fmt.Errorf("duplicate record %q", r.Name))
+		}
+		seen[r.Name] = true
+		switch r.Value {
+		case "", "v", "vs", "vb", "vd", "s":
+		default:
+			return nil, errors.Wrap(errInvalidSchema, fmt.Errorf("unknown value field %q of record %q", r.Value, r.Name))
+		}
+	}
+
+	return &schema, nil
+}
+
+// Validate checks that the SenML payload conforms to the schema. The payload
+// is decoded as SenML CBOR if the content type says so, and as SenML JSON
+// otherwise.
+func (s Schema) Validate(payload []byte, contentType string) error {
+	format := senml.JSON
+	if mediaType(contentType) == senmlCBOR {
+		format = senml.CBOR
+	}
+	pack, err := senml.Decode(payload, format)
+	if err != nil {
+		return errors.Wrap(ErrSchemaViolation, err)
+	}
+	if pack, err = senml.Normalize(pack); err != nil {
+		return errors.Wrap(ErrSchemaViolation, err)
+	}
+
+	records := make(map[string]SchemaRecord, len(s.Records))
+	for _, r := range s.Records {
+		records[r.Name] = r
+	}
+	found := make(map[string]bool)
+	for _, r := range pack.Records {
+		sr, ok := records[r.Name]
+		if !ok {
+			return errors.Wrap(ErrSchemaViolation, fmt.Errorf("record %q is not in the schema", r.Name))
+		}
+		if sr.Unit != "" && r.Unit != sr.Unit {
+			return errors.Wrap(ErrSchemaViolation, fmt.Errorf("record %q must have unit %q, got %q", r.Name, sr.Unit, r.Unit))
+		}
+		if sr.Value != "" && valueField(r) != sr.Value {
+			return errors.Wrap(ErrSchemaViolation, fmt.Errorf("record %q must have value field %q, got %q", r.Name, sr.Value, valueField(r)))
+		}
+		found[r.Name] = true
+	}
+	for _, sr := range s.Records {
+		if sr.Required && !found[sr.Name] {
+			return errors.Wrap(ErrSchemaViolation, fmt.Errorf("required record %q is missing", sr.Name))
+		}
+	}
+
+	return nil
+}
+
+func valueField(r senml.Record) string {
+	switch {
+	case r.Value != nil:
+		return "v"
+	case r.StringValue != nil:
+		return "vs"
+	case r.BoolValue != nil:
+		return "vb"
+	case r.DataValue != nil:
+		return "vd"
+	default:
+		return "s"
+	}
+}
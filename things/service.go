@@ -18,6 +18,27 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// Config contains the things service settings.
+type Config struct {
+	// MaxMetadataSize is the maximum size of the JSON-encoded thing
+	// metadata in bytes.
+	MaxMetadataSize int
+
+	// DefaultChannels maps the domain IDs to the IDs of the channels the
+	// new things of the domain are connected to. The things of the domains
+	// without a default channel are not connected.
+	DefaultChannels map[string]string
+
+	// RevealSecretOnce reports whether the thing secrets are returned only
+	// when the thing is created and when its secret is changed. The other
+	// responses contain mgclients.MaskedSecret instead.
+	RevealSecretOnce bool
+}
+
+// ErrDefaultChannel indicates that the default channel of new things doesn't
+// exist in the domain of the new things.
+var ErrDefaultChannel = errors.New("default channel of new things doesn't exist")
+
 type service struct {
 	evaluator   policies.Evaluator
 	policysvc   policies.Service
@@ -25,10 +46,15 @@ type service struct {
 	clientCache Cache
 	idProvider  magistrala.IDProvider
 	grepo       mggroups.Repository
+	config      Config
 }
 
 // NewService returns a new Clients service implementation.
-func NewService(policyEvaluator policies.Evaluator, policyService policies.Service, c postgres.Repository, grepo mggroups.Repository, tcache Cache, idp magistrala.IDProvider) Service {
+func NewService(policyEvaluator policies.Evaluator, policyService policies.Service, c postgres.Repository, grepo mggroups.Repository, tcache Cache, idp magistrala.IDProvider, cfg Config) Service {
+	if cfg.MaxMetadataSize <= 0 {
+		cfg.MaxMetadataSize = mgclients.DefMaxMetadataSize
+	}
+
 	return service{
 		evaluator:   policyEvaluator,
 		policysvc:   policyService,
@@ -36,13 +62,21 @@ func NewService(policyEvaluator policies.Evaluator, policyService policies.Servi
 		grepo:       grepo,
 		clientCache: tcache,
 		idProvider:  idp,
+		config:      cfg,
 	}
 }
 
-func (svc service) Authorize(ctx context.Context, req AuthzReq) (string, error) {
+func (svc service) Authorize(ctx context.Context, req AuthzReq) (AuthzRes, error) {
 	thingID, err := svc.Identify(ctx, req.ThingKey)
 	if err != nil {
-		return "", err
+		return AuthzRes{}, err
+	}
+	var thing mgclients.Client
+	if req.ThingID != "" && req.ThingID != thingID {
+		if thing, err = svc.representedThing(ctx, thingID, req.ThingID); err != nil {
+			return AuthzRes{}, err
+		}
+		thingID = thing.ID
 	}
 
 	r := policies.Policy{
@@ -54,10 +88,53 @@ func (svc service) Authorize(ctx context.Context, req AuthzReq) (string, error)
 	}
 	err = svc.evaluator.CheckPolicy(ctx, r)
 	if err != nil {
-		return "", errors.Wrap(svcerr.ErrAuthorization, err)
+		return AuthzRes{}, errors.Wrap(svcerr.ErrAuthorization, err)
+	}
+	if req.Permission == policies.PublishPermission {
+		if err := svc.checkContentType(ctx, req.ChannelID, req.ContentType); err != nil {
+			return AuthzRes{}, err
+		}
+	}
+	if thing.ID == "" {
+		if thing, err = svc.authzThing(ctx, thingID); err != nil {
+			return AuthzRes{}, err
+		}
+	}
+	res := AuthzRes{ThingID: thingID, DomainID: thing.Domain}
+	if req.Permission != policies.PublishPermission && len(req.Payload) == 0 {
+		return res, nil
+	}
+	if len(req.Payload) > 0 {
+		if err := checkSchema(thing, req.ContentType, req.Payload); err != nil {
+			return AuthzRes{}, err
+		}
+	}
+	if res.RateLimit, err = parseRateLimit(thing.Metadata[RateLimitKey]); err != nil {
+		return AuthzRes{}, errors.Wrap(errors.ErrMalformedEntity, err)
+	}
+	if res.SigningSecret, err = parseSigningSecret(thing.Metadata[SigningSecretKey]); err != nil {
+		return AuthzRes{}, errors.Wrap(errors.ErrMalformedEntity, err)
+	}
+
+	return res, nil
+}
+
+// authzThing returns the domain and metadata of the thing, which are read
+// from the cache if present so that the repository is not queried on every
+// message. The cached entry is removed whenever the thing changes.
+func (svc service) authzThing(ctx context.Context, thingID string) (mgclients.Client, error) {
+	if thing, err := svc.clientCache.Thing(ctx, thingID); err == nil {
+		return thing, nil
+	}
+	thing, err := svc.clients.RetrieveByID(ctx, thingID)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	if err := svc.clientCache.SaveThing(ctx, thing); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthorization, err)
 	}
 
-	return thingID, nil
+	return thing, nil
 }
 
 func (svc service) CreateThings(ctx context.Context, session authn.Session, cls ...mgclients.Client) ([]mgclients.Client, error) {
@@ -80,11 +157,30 @@ func (svc service) CreateThings(ctx context.Context, session authn.Session, cls
 		if c.Status != mgclients.DisabledStatus && c.Status != mgclients.EnabledStatus {
 			return []mgclients.Client{}, svcerr.ErrInvalidStatus
 		}
+		if err := c.Metadata.ValidateSize(svc.config.MaxMetadataSize); err != nil {
+			return []mgclients.Client{}, err
+		}
+		if _, err := parseSchema(c.Metadata[SchemaKey]); err != nil {
+			return []mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		if _, err := parseRateLimit(c.Metadata[RateLimitKey]); err != nil {
+			return []mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		if _, err := parseGateway(c.Metadata[GatewayKey]); err != nil {
+			return []mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		if _, err := parseSigningSecret(c.Metadata[SigningSecretKey]); err != nil {
+			return []mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
 		c.Domain = session.DomainID
 		c.CreatedAt = time.Now()
 		clients = append(clients, c)
 	}
 
+	if err := svc.checkDefaultChannel(ctx, session.DomainID); err != nil {
+		return []mgclients.Client{}, err
+	}
+
 	err := svc.addThingPolicies(ctx, session.DomainUserID, session.DomainID, clients)
 	if err != nil {
 		return []mgclients.Client{}, err
@@ -110,6 +206,8 @@ func (svc service) ViewClient(ctx context.Context, session authn.Session, id str
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
 	}
+	svc.maskSecret(&client)
+
 	return client, nil
 }
 
@@ -149,6 +247,14 @@ func (svc service) ListClients(ctx context.Context, session authn.Session, reqUs
 		}
 	}
 
+	// The requested IDs limit the listed things to the ones among them.
+	if len(pm.IDs) > 0 {
+		if pm.Domain == "" {
+			ids = intersectIDs(ids, pm.IDs)
+		} else {
+			ids = pm.IDs
+		}
+	}
 	if len(ids) == 0 && pm.Domain == "" {
 		return mgclients.ClientsPage{}, nil
 	}
@@ -158,6 +264,10 @@ func (svc service) ListClients(ctx context.Context, session authn.Session, reqUs
 		return mgclients.ClientsPage{}, errors.Wrap(svcerr.ErrViewEntity, err)
 	}
 
+	for i := range tp.Clients {
+		svc.maskSecret(&tp.Clients[i])
+	}
+
 	if pm.ListPerms && len(tp.Clients) > 0 {
 		g, ctx := errgroup.WithContext(ctx)
 
@@ -176,6 +286,22 @@ func (svc service) ListClients(ctx context.Context, session authn.Session, reqUs
 	return tp, nil
 }
 
+// intersectIDs returns the IDs present in both lists, in the order of ids.
+func intersectIDs(ids, requested []string) []string {
+	req := make(map[string]bool, len(requested))
+	for _, id := range requested {
+		req[id] = true
+	}
+	var ret []string
+	for _, id := range ids {
+		if req[id] {
+			ret = append(ret, id)
+		}
+	}
+
+	return ret
+}
+
 // Experimental functions used for async calling of svc.listUserThingPermission. This might be helpful during listing of large number of entities.
 func (svc service) retrievePermissions(ctx context.Context, userID string, client *mgclients.Client) error {
 	permissions, err := svc.listUserThingPermission(ctx, userID, client.ID)
@@ -234,6 +360,22 @@ func (svc service) filterAllowedThingIDs(ctx context.Context, userID, permission
 }
 
 func (svc service) UpdateClient(ctx context.Context, session authn.Session, cli mgclients.Client) (mgclients.Client, error) {
+	if err := cli.Metadata.ValidateSize(svc.config.MaxMetadataSize); err != nil {
+		return mgclients.Client{}, err
+	}
+	if _, err := parseSchema(cli.Metadata[SchemaKey]); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	if _, err := parseRateLimit(cli.Metadata[RateLimitKey]); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	if _, err := parseGateway(cli.Metadata[GatewayKey]); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	if _, err := parseSigningSecret(cli.Metadata[SigningSecretKey]); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+
 	client := mgclients.Client{
 		ID:        cli.ID,
 		Name:      cli.Name,
@@ -245,6 +387,13 @@ func (svc service) UpdateClient(ctx context.Context, session authn.Session, cli
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
+	// The cached metadata carries the rate limit, schema and signing secret
+	// of the thing, so it's removed to apply the new metadata right away.
+	if err := svc.clientCache.Remove(ctx, client.ID); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+	svc.maskSecret(&client)
+
 	return client, nil
 }
 
@@ -259,6 +408,8 @@ func (svc service) UpdateClientTags(ctx context.Context, session authn.Session,
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
+	svc.maskSecret(&client)
+
 	return client, nil
 }
 
@@ -279,6 +430,34 @@ func (svc service) UpdateClientSecret(ctx context.Context, session authn.Session
 	return client, nil
 }
 
+func (svc service) RotateKey(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	key, err := svc.idProvider.ID()
+	if err != nil {
+		return mgclients.Client{}, err
+	}
+	client := mgclients.Client{
+		ID: id,
+		Credentials: mgclients.Credentials{
+			Secret: key,
+		},
+		UpdatedAt: time.Now(),
+		UpdatedBy: session.UserID,
+		Status:    mgclients.EnabledStatus,
+	}
+	client, err = svc.clients.UpdateSecret(ctx, client)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	// The old key stays valid while it's cached, so it's removed from the
+	// cache right away instead of waiting for it to expire.
+	if err := svc.clientCache.Remove(ctx, id); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+	client.Credentials.Secret = key
+
+	return client, nil
+}
+
 func (svc service) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	client := mgclients.Client{
 		ID:        id,
@@ -383,6 +562,8 @@ func (svc service) changeClientStatus(ctx context.Context, session authn.Session
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
+	svc.maskSecret(&client)
+
 	return client, nil
 }
 
@@ -404,6 +585,10 @@ func (svc service) ListClientsByGroup(ctx context.Context, session authn.Session
 		return mgclients.MembersPage{}, errors.Wrap(svcerr.ErrViewEntity, err)
 	}
 
+	for i := range cp.Clients {
+		svc.maskSecret(&cp.Clients[i])
+	}
+
 	if pm.ListPerms && len(cp.Clients) > 0 {
 		g, ctx := errgroup.WithContext(ctx)
 
@@ -443,6 +628,36 @@ func (svc service) Identify(ctx context.Context, key string) (string, error) {
 	return client.ID, nil
 }
 
+// maskSecret hides the secret of the client if the secrets are revealed only
+// on creation and on change.
+func (svc service) maskSecret(client *mgclients.Client) {
+	if svc.config.RevealSecretOnce && client.Credentials.Secret != "" {
+		client.Credentials.Secret = mgclients.MaskedSecret
+	}
+}
+
+// checkDefaultChannel checks that the default channel of the domain, if
+// configured, exists in the domain, so new things are never created without
+// the connection.
+func (svc service) checkDefaultChannel(ctx context.Context, domainID string) error {
+	chanID, ok := svc.config.DefaultChannels[domainID]
+	if !ok {
+		return nil
+	}
+	ch, err := svc.grepo.RetrieveByID(ctx, chanID)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, errors.Wrap(ErrDefaultChannel, err))
+	}
+	if ch.Domain != domainID {
+		return errors.Wrap(svcerr.ErrCreateEntity, ErrDefaultChannel)
+	}
+
+	return nil
+}
+
+// addThingPolicies adds the thing ownership policies. The connection to the
+// default channel is added in the same request, so the new things are never
+// created unconnected.
 func (svc service) addThingPolicies(ctx context.Context, userID, domainID string, things []mgclients.Client) error {
 	policyList := []policies.Policy{}
 	for _, thing := range things {
@@ -463,6 +678,9 @@ func (svc service) addThingPolicies(ctx context.Context, userID, domainID string
 			ObjectType:  policies.ThingType,
 			Object:      thing.ID,
 		})
+		if chanID, ok := svc.config.DefaultChannels[domainID]; ok {
+			policyList = append(policyList, defaultChannelPolicy(domainID, chanID, thing.ID))
+		}
 	}
 	if err := svc.policysvc.AddPolicies(ctx, policyList); err != nil {
 		return errors.Wrap(svcerr.ErrCreateEntity, err)
@@ -491,6 +709,9 @@ func (svc service) addThingPoliciesRollback(ctx context.Context, userID, domainI
 			ObjectType:  policies.ThingType,
 			Object:      thing.ID,
 		})
+		if chanID, ok := svc.config.DefaultChannels[domainID]; ok {
+			policyList = append(policyList, defaultChannelPolicy(domainID, chanID, thing.ID))
+		}
 	}
 	if err := svc.policysvc.DeletePolicies(ctx, policyList); err != nil {
 		return errors.Wrap(svcerr.ErrRemoveEntity, err)
@@ -498,3 +719,15 @@ func (svc service) addThingPoliciesRollback(ctx context.Context, userID, domainI
 
 	return nil
 }
+
+func defaultChannelPolicy(domainID, chanID, thingID string) policies.Policy {
+	return policies.Policy{
+		Domain:      domainID,
+		SubjectType: policies.GroupType,
+		SubjectKind: policies.ChannelsKind,
+		Subject:     chanID,
+		Relation:    policies.GroupRelation,
+		ObjectType:  policies.ThingType,
+		Object:      thingID,
+	}
+}
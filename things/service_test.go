@@ -5,16 +5,22 @@ package things_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/apiutil"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	mggroups "github.com/absmach/magistrala/pkg/groups"
 	gmocks "github.com/absmach/magistrala/pkg/groups/mocks"
+	"github.com/absmach/magistrala/pkg/jobs"
 	"github.com/absmach/magistrala/pkg/policies"
 	policysvc "github.com/absmach/magistrala/pkg/policies"
 	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
@@ -23,6 +29,7 @@ import (
 	"github.com/absmach/magistrala/things/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -43,6 +50,12 @@ var (
 	validID           = "d4ebb847-5d0e-4e46-bdd9-b6aceaaa3a22"
 	wrongID           = testsutil.GenerateUUID(&testing.T{})
 	errRemovePolicies = errors.New("failed to delete policies")
+	schemaMetadata    = mgclients.Metadata{things.SchemaKey: map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{"name": "room1:temp", "unit": "Cel", "value": "v", "required": true},
+			map[string]interface{}{"name": "room1:door", "value": "vb"},
+		},
+	}}
 )
 
 var (
@@ -50,6 +63,7 @@ var (
 	pEvaluator *policymocks.Evaluator
 	cache      *mocks.Cache
 	cRepo      *mocks.Repository
+	gRepo      *gmocks.Repository
 )
 
 func newService() things.Service {
@@ -58,9 +72,9 @@ func newService() things.Service {
 	cache = new(mocks.Cache)
 	idProvider := uuid.NewMock()
 	cRepo = new(mocks.Repository)
-	gRepo := new(gmocks.Repository)
+	gRepo = new(gmocks.Repository)
 
-	return things.NewService(pEvaluator, pService, cRepo, gRepo, cache, idProvider)
+	return things.NewService(pEvaluator, pService, cRepo, gRepo, cache, idProvider, things.Config{})
 }
 
 func TestCreateThings(t *testing.T) {
@@ -602,6 +616,57 @@ func TestListClients(t *testing.T) {
 	}
 }
 
+func TestListClientsByIDs(t *testing.T) {
+	visible := testsutil.GenerateUUID(t)
+	hidden := testsutil.GenerateUUID(t)
+	domainID := testsutil.GenerateUUID(t)
+
+	cases := []struct {
+		desc    string
+		session mgauthn.Session
+		ids     []string
+		listed  []string
+		domain  string
+	}{
+		{
+			desc:    "list requested clients visible to the user",
+			session: mgauthn.Session{UserID: validID, DomainID: domainID, DomainUserID: domainID + "_" + validID},
+			ids:     []string{visible, hidden},
+			listed:  []string{visible},
+		},
+		{
+			desc:    "list requested clients not visible to the user",
+			session: mgauthn.Session{UserID: validID, DomainID: domainID, DomainUserID: domainID + "_" + validID},
+			ids:     []string{hidden},
+		},
+		{
+			desc:    "list requested clients as super admin",
+			session: mgauthn.Session{UserID: validID, DomainID: domainID, SuperAdmin: true},
+			ids:     []string{visible, hidden},
+			listed:  []string{visible, hidden},
+			domain:  domainID,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			pService := new(policymocks.Service)
+			svc := things.NewService(new(policymocks.Evaluator), pService, cRepo, new(gmocks.Repository), new(mocks.Cache), uuid.NewMock(), things.Config{})
+			pService.On("ListAllObjects", context.Background(), mock.Anything).Return(policysvc.PolicyPage{Policies: []string{visible}}, nil)
+			cRepo.On("SearchClients", context.Background(), mgclients.Page{Limit: 10, IDs: tc.listed, Domain: tc.domain}).Return(mgclients.ClientsPage{}, nil)
+
+			_, err := svc.ListClients(context.Background(), tc.session, "", mgclients.Page{Limit: 10, IDs: tc.ids})
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			if len(tc.listed) == 0 {
+				cRepo.AssertNotCalled(t, "SearchClients", mock.Anything, mock.Anything)
+				return
+			}
+			cRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUpdateClient(t *testing.T) {
 	svc := newService()
 
@@ -616,6 +681,7 @@ func TestUpdateClient(t *testing.T) {
 		session        mgauthn.Session
 		updateResponse mgclients.Client
 		updateErr      error
+		removeErr      error
 		err            error
 	}{
 		{
@@ -640,17 +706,354 @@ func TestUpdateClient(t *testing.T) {
 			updateErr:      repoerr.ErrMalformedEntity,
 			err:            svcerr.ErrUpdateEntity,
 		},
+		{
+			desc:           "update client with failed to remove from cache",
+			client:         client2,
+			updateResponse: mgclients.Client{},
+			session:        mgauthn.Session{UserID: validID},
+			removeErr:      repoerr.ErrRemoveEntity,
+			err:            svcerr.ErrRemoveEntity,
+		},
 	}
 
 	for _, tc := range cases {
 		repoCall1 := cRepo.On("Update", context.Background(), mock.Anything).Return(tc.updateResponse, tc.updateErr)
+		cacheCall := cache.On("Remove", context.Background(), tc.updateResponse.ID).Return(tc.removeErr)
 		updatedClient, err := svc.UpdateClient(context.Background(), tc.session, tc.client)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 		assert.Equal(t, tc.updateResponse, updatedClient, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.updateResponse, updatedClient))
 		repoCall1.Unset()
+		cacheCall.Unset()
+	}
+}
+
+func TestMetadataSizeLimit(t *testing.T) {
+	const maxSize = 1024
+	cases := []struct {
+		desc     string
+		metadata mgclients.Metadata
+		err      error
+	}{
+		{
+			desc:     "metadata at size limit",
+			metadata: metadataOfSize(maxSize),
+			err:      nil,
+		},
+		{
+			desc:     "metadata over size limit",
+			metadata: metadataOfSize(maxSize + 1),
+			err:      apiutil.ErrMetadataSize,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			cache := new(mocks.Cache)
+			pService := new(policymocks.Service)
+			svc := things.NewService(new(policymocks.Evaluator), pService, cRepo, new(gmocks.Repository), cache, uuid.NewMock(), things.Config{MaxMetadataSize: maxSize})
+
+			thing := mgclients.Client{ID: ID, Metadata: tc.metadata, Status: mgclients.EnabledStatus}
+			cRepo.On("Save", context.Background(), mock.Anything).Return([]mgclients.Client{thing}, nil)
+			cRepo.On("Update", context.Background(), mock.Anything).Return(thing, nil)
+			cache.On("Remove", context.Background(), thing.ID).Return(nil)
+			pService.On("AddPolicies", mock.Anything, mock.Anything).Return(nil)
+
+			_, err := svc.CreateThings(context.Background(), mgauthn.Session{}, thing)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("create %s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.True(t, errors.Contains(err, apiutil.ErrValidation) == (tc.err != nil), fmt.Sprintf("create %s: expected validation error got %s\n", tc.desc, err))
+			_, err = svc.UpdateClient(context.Background(), mgauthn.Session{UserID: validID}, thing)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("update %s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err != nil {
+				cRepo.AssertNotCalled(t, "Save", context.Background(), mock.Anything)
+				cRepo.AssertNotCalled(t, "Update", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSchemaMetadata(t *testing.T) {
+	cases := []struct {
+		desc     string
+		metadata mgclients.Metadata
+		err      error
+	}{
+		{
+			desc:     "metadata with valid schema",
+			metadata: schemaMetadata,
+			err:      nil,
+		},
+		{
+			desc:     "metadata with schema without records",
+			metadata: mgclients.Metadata{things.SchemaKey: map[string]interface{}{"records": []interface{}{}}},
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "metadata with schema with unknown value field",
+			metadata: mgclients.Metadata{things.SchemaKey: map[string]interface{}{"records": []interface{}{map[string]interface{}{"name": "temp", "value": "vx"}}}},
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "metadata with malformed schema",
+			metadata: mgclients.Metadata{things.SchemaKey: "temp"},
+			err:      svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			cache := new(mocks.Cache)
+			pService := new(policymocks.Service)
+			svc := things.NewService(new(policymocks.Evaluator), pService, cRepo, new(gmocks.Repository), cache, uuid.NewMock(), things.Config{})
+
+			thing := mgclients.Client{ID: ID, Metadata: tc.metadata, Status: mgclients.EnabledStatus}
+			cRepo.On("Save", context.Background(), mock.Anything).Return([]mgclients.Client{thing}, nil)
+			cRepo.On("Update", context.Background(), mock.Anything).Return(thing, nil)
+			cache.On("Remove", context.Background(), thing.ID).Return(nil)
+			pService.On("AddPolicies", mock.Anything, mock.Anything).Return(nil)
+
+			_, err := svc.CreateThings(context.Background(), mgauthn.Session{}, thing)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("create %s: expected %s got %s\n", tc.desc, tc.err, err))
+			_, err = svc.UpdateClient(context.Background(), mgauthn.Session{UserID: validID}, thing)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("update %s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err != nil {
+				cRepo.AssertNotCalled(t, "Save", context.Background(), mock.Anything)
+				cRepo.AssertNotCalled(t, "Update", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRateLimitMetadata(t *testing.T) {
+	cases := []struct {
+		desc     string
+		metadata mgclients.Metadata
+		err      error
+	}{
+		{
+			desc:     "metadata with valid rate limit",
+			metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": 0.5, "burst": float64(5)}},
+			err:      nil,
+		},
+		{
+			desc:     "metadata with rate limit without burst",
+			metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": float64(10)}},
+			err:      nil,
+		},
+		{
+			desc:     "metadata with rate limit with zero rate",
+			metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": float64(0)}},
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "metadata with rate limit with negative burst",
+			metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": float64(1), "burst": float64(-1)}},
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "metadata with malformed rate limit",
+			metadata: mgclients.Metadata{things.RateLimitKey: "10/s"},
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "metadata with valid signing secret",
+			metadata: mgclients.Metadata{things.SigningSecretKey: "secret"},
+			err:      nil,
+		},
+		{
+			desc:     "metadata with empty signing secret",
+			metadata: mgclients.Metadata{things.SigningSecretKey: ""},
+			err:      svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			cache := new(mocks.Cache)
+			pService := new(policymocks.Service)
+			svc := things.NewService(new(policymocks.Evaluator), pService, cRepo, new(gmocks.Repository), cache, uuid.NewMock(), things.Config{})
+
+			thing := mgclients.Client{ID: ID, Metadata: tc.metadata, Status: mgclients.EnabledStatus}
+			cRepo.On("Save", context.Background(), mock.Anything).Return([]mgclients.Client{thing}, nil)
+			cRepo.On("Update", context.Background(), mock.Anything).Return(thing, nil)
+			cache.On("Remove", context.Background(), thing.ID).Return(nil)
+			pService.On("AddPolicies", mock.Anything, mock.Anything).Return(nil)
+
+			_, err := svc.CreateThings(context.Background(), mgauthn.Session{}, thing)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("create %s: expected %s got %s\n", tc.desc, tc.err, err))
+			_, err = svc.UpdateClient(context.Background(), mgauthn.Session{UserID: validID}, thing)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("update %s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err != nil {
+				cRepo.AssertNotCalled(t, "Save", context.Background(), mock.Anything)
+				cRepo.AssertNotCalled(t, "Update", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCreateThingsDefaultChannel(t *testing.T) {
+	channelID := testsutil.GenerateUUID(t)
+	domainID := testsutil.GenerateUUID(t)
+	session := mgauthn.Session{DomainID: domainID, DomainUserID: domainID + "_" + validID, UserID: validID}
+	ths := []mgclients.Client{
+		{ID: testsutil.GenerateUUID(t), Status: mgclients.EnabledStatus},
+		{ID: testsutil.GenerateUUID(t), Status: mgclients.EnabledStatus},
+	}
+
+	cases := []struct {
+		desc       string
+		domainID   string
+		channel    mggroups.Group
+		channelErr error
+		saveErr    error
+		err        error
+	}{
+		{
+			desc:    "create things connected to default channel",
+			channel: mggroups.Group{ID: channelID, Domain: domainID},
+		},
+		{
+			desc:     "create things in domain without default channel",
+			domainID: testsutil.GenerateUUID(t),
+		},
+		{
+			desc:       "create things with non-existing default channel",
+			channelErr: repoerr.ErrNotFound,
+			err:        things.ErrDefaultChannel,
+		},
+		{
+			desc:    "create things with default channel of other domain",
+			channel: mggroups.Group{ID: channelID, Domain: testsutil.GenerateUUID(t)},
+			err:     things.ErrDefaultChannel,
+		},
+		{
+			desc:    "create things with failed save",
+			channel: mggroups.Group{ID: channelID, Domain: domainID},
+			saveErr: repoerr.ErrCreateEntity,
+			err:     svcerr.ErrCreateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			gRepo := new(gmocks.Repository)
+			pService := new(policymocks.Service)
+			svc := things.NewService(new(policymocks.Evaluator), pService, cRepo, gRepo, new(mocks.Cache), uuid.NewMock(), things.Config{DefaultChannels: map[string]string{domainID: channelID}})
+
+			var added, deleted []policysvc.Policy
+			gRepo.On("RetrieveByID", context.Background(), channelID).Return(tc.channel, tc.channelErr)
+			pService.On("AddPolicies", context.Background(), mock.Anything).Run(func(args mock.Arguments) {
+				added = args.Get(1).([]policysvc.Policy)
+			}).Return(nil)
+			pService.On("DeletePolicies", context.Background(), mock.Anything).Run(func(args mock.Arguments) {
+				deleted = args.Get(1).([]policysvc.Policy)
+			}).Return(nil)
+			cRepo.On("Save", context.Background(), mock.Anything, mock.Anything).Return(ths, tc.saveErr)
+
+			session := session
+			if tc.domainID != "" {
+				session.DomainID = tc.domainID
+			}
+			_, err := svc.CreateThings(context.Background(), session, ths...)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.domainID != "" {
+				gRepo.AssertNotCalled(t, "RetrieveByID", context.Background(), channelID)
+				for _, p := range added {
+					assert.NotEqual(t, policysvc.GroupType, p.SubjectType, fmt.Sprintf("%s: expected no channel connection got %v", tc.desc, p))
+				}
+				return
+			}
+			if tc.channelErr != nil || tc.channel.Domain != domainID {
+				pService.AssertNotCalled(t, "AddPolicies", context.Background(), mock.Anything)
+				cRepo.AssertNotCalled(t, "Save", context.Background(), mock.Anything)
+				return
+			}
+			for _, th := range ths {
+				connection := policysvc.Policy{
+					Domain:      domainID,
+					SubjectType: policysvc.GroupType,
+					SubjectKind: policysvc.ChannelsKind,
+					Subject:     channelID,
+					Relation:    policysvc.GroupRelation,
+					ObjectType:  policysvc.ThingType,
+					Object:      th.ID,
+				}
+				assert.Contains(t, added, connection, fmt.Sprintf("%s: expected thing %s to be connected to default channel", tc.desc, th.ID))
+				if tc.saveErr != nil {
+					assert.Contains(t, deleted, connection, fmt.Sprintf("%s: expected connection of thing %s to be rolled back", tc.desc, th.ID))
+				}
+			}
+		})
 	}
 }
 
+func TestRevealSecretOnce(t *testing.T) {
+	session := mgauthn.Session{DomainID: validID, DomainUserID: validID + "_" + validID, UserID: validID, SuperAdmin: true}
+
+	cases := []struct {
+		desc   string
+		config things.Config
+		secret string
+	}{
+		{
+			desc:   "reveal secret on every view",
+			config: things.Config{},
+			secret: secret,
+		},
+		{
+			desc:   "reveal secret only once",
+			config: things.Config{RevealSecretOnce: true},
+			secret: mgclients.MaskedSecret,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			cache := new(mocks.Cache)
+			pService := new(policymocks.Service)
+			svc := things.NewService(new(policymocks.Evaluator), pService, cRepo, new(gmocks.Repository), cache, uuid.NewMock(), tc.config)
+
+			pService.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+			cRepo.On("Save", context.Background(), mock.Anything).Return([]mgclients.Client{client}, nil)
+			cRepo.On("RetrieveByID", context.Background(), client.ID).Return(client, nil)
+			cRepo.On("SearchClients", context.Background(), mock.Anything).Return(mgclients.ClientsPage{Clients: []mgclients.Client{client}}, nil)
+			cRepo.On("Update", context.Background(), mock.Anything).Return(client, nil)
+			cRepo.On("UpdateSecret", context.Background(), mock.Anything).Return(client, nil)
+			cache.On("Remove", mock.Anything, client.ID).Return(nil)
+
+			created, err := svc.CreateThings(context.Background(), session, client)
+			assert.Nil(t, err, fmt.Sprintf("create thing: unexpected error %s", err))
+			assert.Equal(t, secret, created[0].Credentials.Secret, "create thing: expected the secret to be revealed")
+
+			viewed, err := svc.ViewClient(context.Background(), session, client.ID)
+			assert.Nil(t, err, fmt.Sprintf("view thing: unexpected error %s", err))
+			assert.Equal(t, tc.secret, viewed.Credentials.Secret, fmt.Sprintf("view thing: expected secret %s got %s", tc.secret, viewed.Credentials.Secret))
+
+			page, err := svc.ListClients(context.Background(), session, "", mgclients.Page{})
+			assert.Nil(t, err, fmt.Sprintf("list things: unexpected error %s", err))
+			assert.Equal(t, tc.secret, page.Clients[0].Credentials.Secret, fmt.Sprintf("list things: expected secret %s got %s", tc.secret, page.Clients[0].Credentials.Secret))
+
+			updated, err := svc.UpdateClient(context.Background(), session, client)
+			assert.Nil(t, err, fmt.Sprintf("update thing: unexpected error %s", err))
+			assert.Equal(t, tc.secret, updated.Credentials.Secret, fmt.Sprintf("update thing: expected secret %s got %s", tc.secret, updated.Credentials.Secret))
+
+			rotated, err := svc.RotateKey(context.Background(), session, client.ID)
+			assert.Nil(t, err, fmt.Sprintf("rotate key: unexpected error %s", err))
+			assert.NotEqual(t, mgclients.MaskedSecret, rotated.Credentials.Secret, "rotate key: expected the new secret to be revealed")
+			assert.NotEmpty(t, rotated.Credentials.Secret, "rotate key: expected the new secret to be revealed")
+		})
+	}
+}
+
+// metadataOfSize returns metadata whose JSON encoding is size bytes long.
+func metadataOfSize(size int) mgclients.Metadata {
+	return mgclients.Metadata{"data": strings.Repeat("a", size-len(`{"data":""}`))}
+}
+
 func TestUpdateClientTags(t *testing.T) {
 	svc := newService()
 
@@ -736,6 +1139,92 @@ func TestUpdateClientSecret(t *testing.T) {
 	}
 }
 
+func TestRotateKey(t *testing.T) {
+	svc := newService()
+
+	oldKey := client.Credentials.Secret
+	stored := client
+	cached := map[string]string{oldKey: client.ID}
+
+	cache.On("ID", mock.Anything, mock.Anything).Return(func(_ context.Context, key string) (string, error) {
+		id, ok := cached[key]
+		if !ok {
+			return "", repoerr.ErrNotFound
+		}
+		return id, nil
+	})
+	cache.On("Save", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, key, id string) error {
+		cached[key] = id
+		return nil
+	})
+	cache.On("Remove", mock.Anything, client.ID).Return(func(_ context.Context, id string) error {
+		for key, cid := range cached {
+			if cid == id {
+				delete(cached, key)
+			}
+		}
+		return nil
+	})
+	cRepo.On("UpdateSecret", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		stored.Credentials.Secret = c.Credentials.Secret
+		return mgclients.Client{ID: stored.ID, Name: stored.Name, Status: stored.Status}, nil
+	})
+	cRepo.On("RetrieveBySecret", mock.Anything, mock.Anything).Return(func(_ context.Context, key string) (mgclients.Client, error) {
+		if key != stored.Credentials.Secret {
+			return mgclients.Client{}, repoerr.ErrNotFound
+		}
+		return stored, nil
+	})
+
+	id, err := svc.Identify(context.Background(), oldKey)
+	assert.Nil(t, err, fmt.Sprintf("identify with key before rotation: unexpected error %s", err))
+	assert.Equal(t, client.ID, id, fmt.Sprintf("identify with key before rotation: expected %s got %s", client.ID, id))
+
+	rotated, err := svc.RotateKey(context.Background(), mgauthn.Session{UserID: validID}, client.ID)
+	assert.Nil(t, err, fmt.Sprintf("rotate key: unexpected error %s", err))
+	newKey := rotated.Credentials.Secret
+	assert.NotEmpty(t, newKey, "rotate key: expected the new key to be returned")
+	assert.NotEqual(t, oldKey, newKey, "rotate key: expected the key to change")
+
+	id, err = svc.Identify(context.Background(), newKey)
+	assert.Nil(t, err, fmt.Sprintf("identify with new key: unexpected error %s", err))
+	assert.Equal(t, client.ID, id, fmt.Sprintf("identify with new key: expected %s got %s", client.ID, id))
+
+	_, err = svc.Identify(context.Background(), oldKey)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("identify with old key: expected %s got %s", svcerr.ErrAuthorization, err))
+}
+
+func TestRotateKeyFailures(t *testing.T) {
+	svc := newService()
+
+	cases := []struct {
+		desc      string
+		updateErr error
+		removeErr error
+		err       error
+	}{
+		{
+			desc:      "rotate key of disabled or non-existing thing",
+			updateErr: repoerr.ErrNotFound,
+			err:       svcerr.ErrUpdateEntity,
+		},
+		{
+			desc:      "rotate key with failed cache removal",
+			removeErr: repoerr.ErrRemoveEntity,
+			err:       svcerr.ErrRemoveEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		repoCall := cRepo.On("UpdateSecret", context.Background(), mock.Anything).Return(client, tc.updateErr)
+		cacheCall := cache.On("Remove", mock.Anything, client.ID).Return(tc.removeErr)
+		_, err := svc.RotateKey(context.Background(), mgauthn.Session{UserID: validID}, client.ID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		repoCall.Unset()
+		cacheCall.Unset()
+	}
+}
+
 func TestEnableClient(t *testing.T) {
 	svc := newService()
 
@@ -1308,7 +1797,16 @@ func TestAuthorize(t *testing.T) {
 		retrieveBySecretErr error
 		cacheSaveErr        error
 		checkPolicyErr      error
+		channel             mggroups.Group
+		retrieveChannelErr  error
+		cachedThing         mgclients.Client
+		thing               mgclients.Client
+		retrieveThingErr    error
+		saveThingErr        error
 		id                  string
+		domainID            string
+		rateLimit           things.RateLimit
+		signingSecret       string
 		err                 error
 	}{
 		{
@@ -1370,10 +1868,206 @@ func TestAuthorize(t *testing.T) {
 			checkPolicyErr:      svcerr.ErrAuthorization,
 			err:                 svcerr.ErrAuthorization,
 		},
+		{
+			desc:       "authorize client publishing allowed content type to restricted channel",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, ContentType: "application/senml+json"},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{"application/senml+json", "application/senml+cbor"}}},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client publishing allowed content type with parameters to restricted channel",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, ContentType: "application/senml+json; charset=utf-8"},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{"application/senml+json"}}},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client publishing not allowed content type to restricted channel",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, ContentType: "application/octet-stream"},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{"application/senml+json"}}},
+			err:        things.ErrContentTypeNotAllowed,
+		},
+		{
+			desc:       "authorize client publishing without content type to restricted channel",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{"application/senml+json"}}},
+			err:        things.ErrContentTypeNotAllowed,
+		},
+		{
+			desc:       "authorize client publishing without content type to restricted channel with allowed default",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{"application/senml+json"}, things.DefaultContentTypeKey: "application/senml+json"}},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client publishing without content type to restricted channel with not allowed default",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{"application/senml+json"}, things.DefaultContentTypeKey: "text/plain"}},
+			err:        things.ErrContentTypeNotAllowed,
+		},
+		{
+			desc:       "authorize client publishing any content type to unrestricted channel",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, ContentType: "application/octet-stream"},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: []interface{}{}}},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client publishing to channel with invalid allowed content types",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, ContentType: "application/senml+json"},
+			cacheIDRes: valid,
+			channel:    mggroups.Group{ID: valid, Metadata: mgclients.Metadata{things.AllowedContentTypesKey: "application/senml+json"}},
+			err:        svcerr.ErrAuthorization,
+		},
+		{
+			desc:               "authorize client publishing content type with failed to retrieve channel",
+			request:            things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, ContentType: "application/senml+json"},
+			cacheIDRes:         valid,
+			retrieveChannelErr: repoerr.ErrNotFound,
+			err:                svcerr.ErrViewEntity,
+		},
+		{
+			desc:       "authorize client publishing conforming payload with schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`[{"bn":"room1:","n":"temp","u":"Cel","v":21.5},{"n":"door","vb":true}]`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: schemaMetadata},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client publishing payload with unknown record with schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`[{"n":"room1:temp","u":"Cel","v":21.5},{"n":"room1:humidity","u":"%RH","v":40}]`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: schemaMetadata},
+			err:        things.ErrSchemaViolation,
+		},
+		{
+			desc:       "authorize client publishing payload with wrong unit with schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`[{"n":"room1:temp","u":"Far","v":70.7}]`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: schemaMetadata},
+			err:        things.ErrSchemaViolation,
+		},
+		{
+			desc:       "authorize client publishing payload with wrong value type with schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`[{"n":"room1:temp","u":"Cel","vs":"warm"}]`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: schemaMetadata},
+			err:        things.ErrSchemaViolation,
+		},
+		{
+			desc:       "authorize client publishing payload without required record with schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`[{"n":"room1:door","vb":false}]`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: schemaMetadata},
+			err:        things.ErrSchemaViolation,
+		},
+		{
+			desc:       "authorize client publishing non SenML payload with schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`{"temp":21.5}`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: schemaMetadata},
+			err:        things.ErrSchemaViolation,
+		},
+		{
+			desc:       "authorize client publishing any payload without schema",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`{"temp":21.5}`)},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client with domain",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.SubscribePermission},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Domain: validID},
+			id:         valid,
+			domainID:   validID,
+		},
+		{
+			desc:             "authorize client subscribing with failed to retrieve thing",
+			request:          things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.SubscribePermission},
+			cacheIDRes:       valid,
+			retrieveThingErr: repoerr.ErrNotFound,
+			err:              svcerr.ErrViewEntity,
+		},
+		{
+			desc:       "authorize client publishing with rate limit",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": 0.5, "burst": float64(5)}}},
+			id:         valid,
+			rateLimit:  things.RateLimit{Rate: 0.5, Burst: 5},
+		},
+		{
+			desc:          "authorize client publishing with signing secret",
+			request:       things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes:    valid,
+			thing:         mgclients.Client{ID: valid, Metadata: mgclients.Metadata{things.SigningSecretKey: "secret"}},
+			id:            valid,
+			signingSecret: "secret",
+		},
+		{
+			desc:       "authorize client subscribing with signing secret",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.SubscribePermission},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: mgclients.Metadata{things.SigningSecretKey: "secret"}},
+			id:         valid,
+		},
+		{
+			desc:       "authorize client subscribing with rate limit",
+			request:    things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.SubscribePermission},
+			cacheIDRes: valid,
+			thing:      mgclients.Client{ID: valid, Metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": 0.5}}},
+			id:         valid,
+		},
+		{
+			desc:             "authorize client publishing with failed to retrieve thing",
+			request:          things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes:       valid,
+			retrieveThingErr: repoerr.ErrNotFound,
+			err:              svcerr.ErrViewEntity,
+		},
+		{
+			desc:             "authorize client publishing payload with failed to retrieve thing",
+			request:          things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission, Payload: []byte(`[{"n":"room1:temp","u":"Cel","v":21.5}]`)},
+			cacheIDRes:       valid,
+			retrieveThingErr: repoerr.ErrNotFound,
+			err:              svcerr.ErrViewEntity,
+		},
+		{
+			desc:        "authorize client publishing with thing in cache",
+			request:     things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes:  valid,
+			cachedThing: mgclients.Client{ID: valid, Domain: validID, Metadata: mgclients.Metadata{things.RateLimitKey: map[string]interface{}{"rate": 0.5, "burst": float64(5)}}},
+			// The repository is not queried if the thing is cached.
+			retrieveThingErr: repoerr.ErrNotFound,
+			id:               valid,
+			domainID:         validID,
+			rateLimit:        things.RateLimit{Rate: 0.5, Burst: 5},
+		},
+		{
+			desc:         "authorize client publishing with failed to save thing to cache",
+			request:      things.AuthzReq{ThingKey: valid, ChannelID: valid, Permission: policies.PublishPermission},
+			cacheIDRes:   valid,
+			thing:        mgclients.Client{ID: valid, Domain: validID},
+			saveThingErr: errors.ErrMalformedEntity,
+			err:          svcerr.ErrAuthorization,
+		},
 	}
 
 	for _, tc := range cases {
+		cacheThingErr := error(nil)
+		if tc.cachedThing.ID == "" {
+			cacheThingErr = repoerr.ErrNotFound
+		}
 		cacheCall := cache.On("ID", context.Background(), tc.request.ThingKey).Return(tc.cacheIDRes, tc.cacheIDErr)
+		cacheCall2 := cache.On("Thing", context.Background(), valid).Return(tc.cachedThing, cacheThingErr)
+		cacheCall3 := cache.On("SaveThing", context.Background(), tc.thing).Return(tc.saveThingErr)
 		repoCall := cRepo.On("RetrieveBySecret", context.Background(), tc.request.ThingKey).Return(tc.retrieveBySecretRes, tc.retrieveBySecretErr)
 		cacheCall1 := cache.On("Save", context.Background(), tc.request.ThingKey, tc.retrieveBySecretRes.ID).Return(tc.cacheSaveErr)
 		policyCall := pEvaluator.On("CheckPolicy", context.Background(), policies.Policy{
@@ -1383,14 +2077,225 @@ func TestAuthorize(t *testing.T) {
 			Object:      valid,
 			Permission:  tc.request.Permission,
 		}).Return(tc.checkPolicyErr)
-		id, err := svc.Authorize(context.Background(), tc.request)
+		groupCall := gRepo.On("RetrieveByID", context.Background(), tc.request.ChannelID).Return(tc.channel, tc.retrieveChannelErr)
+		repoCall1 := cRepo.On("RetrieveByID", context.Background(), valid).Return(tc.thing, tc.retrieveThingErr)
+		res, err := svc.Authorize(context.Background(), tc.request)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 		if tc.err == nil {
-			assert.Equal(t, tc.id, id, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.id, id))
+			assert.Equal(t, tc.id, res.ThingID, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.id, res.ThingID))
+			assert.Equal(t, tc.rateLimit, res.RateLimit, fmt.Sprintf("%s: expected rate limit %v got %v\n", tc.desc, tc.rateLimit, res.RateLimit))
+			assert.Equal(t, tc.domainID, res.DomainID, fmt.Sprintf("%s: expected domain %s got %s\n", tc.desc, tc.domainID, res.DomainID))
+			assert.Equal(t, tc.signingSecret, res.SigningSecret, fmt.Sprintf("%s: expected signing secret %s got %s\n", tc.desc, tc.signingSecret, res.SigningSecret))
 		}
 		cacheCall.Unset()
 		cacheCall1.Unset()
+		cacheCall2.Unset()
+		cacheCall3.Unset()
 		repoCall.Unset()
 		policyCall.Unset()
+		groupCall.Unset()
+		repoCall1.Unset()
+	}
+}
+
+func TestAuthorizeGateway(t *testing.T) {
+	gatewayID := testsutil.GenerateUUID(t)
+	gatewayKey := testsutil.GenerateUUID(t)
+	represented := mgclients.Client{ID: testsutil.GenerateUUID(t), Domain: validID, Status: mgclients.EnabledStatus, Metadata: mgclients.Metadata{things.GatewayKey: gatewayID}}
+	other := mgclients.Client{ID: testsutil.GenerateUUID(t), Domain: validID, Status: mgclients.EnabledStatus, Metadata: mgclients.Metadata{things.GatewayKey: testsutil.GenerateUUID(t)}}
+	disabled := mgclients.Client{ID: testsutil.GenerateUUID(t), Domain: validID, Status: mgclients.DisabledStatus, Metadata: mgclients.Metadata{things.GatewayKey: gatewayID}}
+	unconnected := mgclients.Client{ID: testsutil.GenerateUUID(t), Domain: validID, Status: mgclients.EnabledStatus, Metadata: mgclients.Metadata{things.GatewayKey: gatewayID}}
+
+	cases := []struct {
+		desc    string
+		thingID string
+		res     things.AuthzRes
+		err     error
+	}{
+		{
+			desc:    "authorize gateway publishing on behalf of represented thing",
+			thingID: represented.ID,
+			res:     things.AuthzRes{ThingID: represented.ID, DomainID: validID},
+		},
+		{
+			desc:    "authorize gateway publishing on its own behalf",
+			thingID: gatewayID,
+			res:     things.AuthzRes{ThingID: gatewayID, DomainID: validID},
+		},
+		{
+			desc:    "authorize gateway publishing on behalf of thing represented by other gateway",
+			thingID: other.ID,
+			err:     things.ErrNotRepresented,
+		},
+		{
+			desc:    "authorize gateway publishing on behalf of disabled thing",
+			thingID: disabled.ID,
+			err:     things.ErrNotRepresented,
+		},
+		{
+			desc:    "authorize gateway publishing on behalf of thing not connected to channel",
+			thingID: unconnected.ID,
+			err:     svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := newService()
+			cache.On("ID", context.Background(), gatewayKey).Return(gatewayID, nil)
+			cache.On("Thing", context.Background(), mock.Anything).Return(mgclients.Client{}, repoerr.ErrNotFound)
+			cache.On("SaveThing", context.Background(), mock.Anything).Return(nil)
+			gRepo.On("RetrieveByID", context.Background(), validID).Return(mggroups.Group{ID: validID}, nil)
+			for _, th := range []mgclients.Client{represented, other, disabled, unconnected, {ID: gatewayID, Domain: validID, Status: mgclients.EnabledStatus}} {
+				cRepo.On("RetrieveByID", context.Background(), th.ID).Return(th, nil)
+			}
+			pEvaluator.On("CheckPolicy", context.Background(), mock.Anything).Return(func(_ context.Context, pr policies.Policy) error {
+				if pr.Object == unconnected.ID {
+					return svcerr.ErrAuthorization
+				}
+				return nil
+			})
+
+			res, err := svc.Authorize(context.Background(), things.AuthzReq{
+				ChannelID:  validID,
+				ThingID:    tc.thingID,
+				ThingKey:   gatewayKey,
+				Permission: policies.PublishPermission,
+			})
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.res, res))
+			if tc.err != nil {
+				assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, svcerr.ErrAuthorization, err))
+			}
+		})
+	}
+}
+
+func TestCreateThingsHandler(t *testing.T) {
+	svc := new(mocks.Service)
+	handler := things.NewCreateThingsHandler(svc, 2)
+
+	domainID := testsutil.GenerateUUID(t)
+	session := mgauthn.Session{UserID: validID, DomainID: domainID, DomainUserID: domainID + "_" + validID}
+	submitted := time.Now().UTC().Add(-time.Minute).Round(0)
+	var ths []mgclients.Client
+	for i := 0; i < 5; i++ {
+		ths = append(ths, mgclients.Client{ID: testsutil.GenerateUUID(t), Name: fmt.Sprintf("thing%d", i), CreatedAt: submitted.Add(time.Second)})
+	}
+	payload, err := json.Marshal(things.CreateThingsPayload{Things: ths})
+	assert.Nil(t, err, fmt.Sprintf("marshaling payload expected to succeed: %s", err))
+
+	cases := []struct {
+		desc      string
+		processed uint64
+		existing  []mgclients.Client
+		viewErr   error
+		batches   [][]mgclients.Client
+		createErr error
+		progress  []uint64
+		err       error
+	}{
+		{
+			desc:     "create things in batches",
+			viewErr:  repoerr.ErrNotFound,
+			batches:  [][]mgclients.Client{ths[0:2], ths[2:4], ths[4:5]},
+			progress: []uint64{2, 4, 5},
+		},
+		{
+			desc:      "resume creating things",
+			processed: 3,
+			viewErr:   svcerr.ErrAuthorization,
+			batches:   [][]mgclients.Client{ths[3:5]},
+			progress:  []uint64{5},
+		},
+		{
+			desc:      "resume creating things with created batch",
+			processed: 2,
+			existing:  ths[2:4],
+			batches:   [][]mgclients.Client{ths[4:5]},
+			progress:  []uint64{4, 5},
+		},
+		{
+			desc:      "resume creating things with batch created before submission",
+			processed: 3,
+			existing:  []mgclients.Client{{ID: ths[3].ID, CreatedAt: submitted.Add(-time.Second)}},
+			batches:   [][]mgclients.Client{ths[3:5]},
+			progress:  []uint64{5},
+		},
+		{
+			desc:    "create things with failed view",
+			viewErr: svcerr.ErrViewEntity,
+			err:     svcerr.ErrViewEntity,
+		},
+		{
+			desc:      "create things with service error",
+			viewErr:   repoerr.ErrNotFound,
+			batches:   [][]mgclients.Client{ths[0:2]},
+			createErr: svcerr.ErrCreateEntity,
+			err:       svcerr.ErrCreateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var calls []*mock.Call
+			for _, th := range tc.existing {
+				calls = append(calls, svc.On("ViewClient", mock.Anything, session, th.ID).Return(th, nil))
+			}
+			if tc.existing == nil {
+				calls = append(calls, svc.On("ViewClient", mock.Anything, session, ths[tc.processed].ID).Return(mgclients.Client{}, tc.viewErr))
+			}
+			for _, batch := range tc.batches {
+				args := []interface{}{mock.Anything, session}
+				for _, th := range batch {
+					args = append(args, th)
+				}
+				calls = append(calls, svc.On("CreateThings", args...).Return(batch, tc.createErr))
+			}
+
+			var progress []uint64
+			var created []mgclients.Client
+			job := jobs.Job{ID: testsutil.GenerateUUID(t), Kind: things.CreateThingsJob, UserID: validID, DomainID: domainID, Total: uint64(len(ths)), Processed: tc.processed, Payload: payload, CreatedAt: submitted}
+			err := handler(context.Background(), job, func(processed uint64, results ...interface{}) error {
+				progress = append(progress, processed)
+				for _, res := range results {
+					created = append(created, res.(mgclients.Client))
+				}
+				return nil
+			})
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			assert.Equal(t, tc.progress, progress, fmt.Sprintf("%s: expected progress %v got %v", tc.desc, tc.progress, progress))
+			if tc.err == nil {
+				assert.Equal(t, ths[tc.processed:], created, fmt.Sprintf("%s: unexpected created things", tc.desc))
+			}
+			for _, call := range calls {
+				call.Unset()
+			}
+		})
+	}
+}
+
+func TestCreateThingsHandlerIDs(t *testing.T) {
+	svc := new(mocks.Service)
+	handler := things.NewCreateThingsHandler(svc, 2)
+
+	payload, err := json.Marshal(things.CreateThingsPayload{Things: []mgclients.Client{{Name: "thing1"}, {Name: "thing2"}}})
+	assert.Nil(t, err, fmt.Sprintf("marshaling payload expected to succeed: %s", err))
+	job := jobs.Job{ID: testsutil.GenerateUUID(t), Kind: things.CreateThingsJob, UserID: validID, DomainID: validID, Total: 2, Payload: payload}
+
+	var ids [][]string
+	svc.On("ViewClient", mock.Anything, mock.Anything, mock.Anything).Return(mgclients.Client{}, repoerr.ErrNotFound)
+	svc.On("CreateThings", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ids = append(ids, []string{args.Get(2).(mgclients.Client).ID, args.Get(3).(mgclients.Client).ID})
+	}).Return([]mgclients.Client{}, nil)
+
+	// The resumed job creates the things with the same IDs.
+	for i := 0; i < 2; i++ {
+		err := handler(context.Background(), job, func(uint64, ...interface{}) error { return nil })
+		assert.Nil(t, err, fmt.Sprintf("creating things expected to succeed: %s", err))
 	}
+	require.Len(t, ids, 2, "expected the things to be created by each run")
+	assert.NotEmpty(t, ids[0][0], "expected the thing ID to be generated")
+	assert.NotEqual(t, ids[0][0], ids[0][1], "expected distinct thing IDs")
+	assert.Equal(t, ids[0], ids[1], "expected the resumed job to generate the same thing IDs")
 }
@@ -0,0 +1,31 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// SigningSecretKey is the thing metadata key holding the secret the thing
+// signs its messages with, which the adapters verify the messages published
+// to the channels requiring signatures with.
+const SigningSecretKey = "signing_secret"
+
+var errInvalidSigningSecret = errors.New("invalid thing signing secret")
+
+// parseSigningSecret parses the signing secret from the thing metadata
+// value. It returns empty secret if the thing has no signing secret.
+func parseSigningSecret(val interface{}) (string, error) {
+	if val == nil {
+		return "", nil
+	}
+	secret, ok := val.(string)
+	if !ok || secret == "" {
+		return "", errors.Wrap(errInvalidSigningSecret, fmt.Errorf("signing secret must be a non-empty string"))
+	}
+
+	return secret, nil
+}
@@ -11,10 +11,33 @@ import (
 )
 
 type AuthzReq struct {
-	ChannelID  string
+	ChannelID string
+	// ThingID is the thing on whose behalf the thing identified by the key
+	// publishes, if not empty. The thing must name the publishing thing as
+	// its gateway, see GatewayKey.
 	ThingID    string
 	ThingKey   string
 	Permission string
+	// ContentType is the content type of the published message, checked
+	// against the content types the channel allows if not empty.
+	ContentType string
+	// Payload is the published message, checked against the schema of the
+	// thing if not empty.
+	Payload []byte
+}
+
+// AuthzRes is the result of the successful thing authorization.
+type AuthzRes struct {
+	ThingID string
+	// DomainID is the domain of the thing and the channel, which scopes the
+	// broker topics of the channel.
+	DomainID string
+	// RateLimit is the publish rate limit of the thing, set if the thing is
+	// authorized to publish and has its own rate limit.
+	RateLimit RateLimit
+	// SigningSecret is the secret the thing signs its messages with, set if
+	// the thing is authorized to publish and has a signing secret.
+	SigningSecret string
 }
 
 // Service specifies an API that must be fullfiled by the domain service
@@ -49,6 +72,10 @@ type Service interface {
 	// UpdateClientSecret updates the client's secret
 	UpdateClientSecret(ctx context.Context, session authn.Session, id, key string) (clients.Client, error)
 
+	// RotateKey replaces the client's key with a newly generated one. The
+	// old key is invalidated immediately.
+	RotateKey(ctx context.Context, session authn.Session, id string) (clients.Client, error)
+
 	// EnableClient logically enableds the client identified with the provided ID
 	EnableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error)
 
@@ -65,7 +92,7 @@ type Service interface {
 	Identify(ctx context.Context, key string) (string, error)
 
 	// Authorize used for Things authorization.
-	Authorize(ctx context.Context, req AuthzReq) (string, error)
+	Authorize(ctx context.Context, req AuthzReq) (AuthzRes, error)
 
 	// DeleteClient deletes client with given ID.
 	DeleteClient(ctx context.Context, session authn.Session, id string) error
@@ -81,6 +108,13 @@ type Cache interface {
 	// ID returns thing ID for given thing secret.
 	ID(ctx context.Context, thingSecret string) (string, error)
 
+	// SaveThing stores the domain and metadata of the thing, which are
+	// used to authorize its messages.
+	SaveThing(ctx context.Context, thing clients.Client) error
+
+	// Thing returns the thing with the domain and metadata stored by SaveThing.
+	Thing(ctx context.Context, thingID string) (clients.Client, error)
+
 	// Removes thing from cache.
 	Remove(ctx context.Context, thingID string) error
 }
@@ -81,6 +81,14 @@ func (tm *tracingMiddleware) UpdateClientSecret(ctx context.Context, session aut
 	return tm.svc.UpdateClientSecret(ctx, session, oldSecret, newSecret)
 }
 
+// RotateKey traces the "RotateKey" operation of the wrapped policies.Service.
+func (tm *tracingMiddleware) RotateKey(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_rotate_client_key", trace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
+
+	return tm.svc.RotateKey(ctx, session, id)
+}
+
 // EnableClient traces the "EnableClient" operation of the wrapped policies.Service.
 func (tm *tracingMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_enable_client", trace.WithAttributes(attribute.String("id", id)))
@@ -114,7 +122,7 @@ func (tm *tracingMiddleware) Identify(ctx context.Context, key string) (string,
 }
 
 // Authorize traces the "Authorize" operation of the wrapped things.Service.
-func (tm *tracingMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (string, error) {
+func (tm *tracingMiddleware) Authorize(ctx context.Context, req things.AuthzReq) (things.AuthzRes, error) {
 	ctx, span := tm.tracer.Start(ctx, "connect", trace.WithAttributes(attribute.String("thingKey", req.ThingKey), attribute.String("channelID", req.ChannelID)))
 	defer span.End()
 
@@ -23,7 +23,7 @@ func Benchmark(cfg Config) error {
 	if err := checkConnection(cfg.MQTT.Broker.URL, 1); err != nil {
 		return err
 	}
-	logger, err := mglog.New(os.Stdout, "debug")
+	logger, err := mglog.New(os.Stdout, "debug", mglog.JSONFormat)
 	if err != nil {
 		return err
 	}
@@ -7,9 +7,12 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/absmach/magistrala"
 	mgauth "github.com/absmach/magistrala/auth"
@@ -18,19 +21,33 @@ import (
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/groups"
 	"github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/absmach/magistrala/pkg/passwords"
 	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/saml"
 	"github.com/absmach/magistrala/users"
 	"github.com/go-chi/chi/v5"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+const (
+	sinceKey  = "since"
+	cursorKey = "cursor"
+
+	// samlRequestCookie holds the ID of the SAML authentication request
+	// started by the browser.
+	samlRequestCookie = "saml_request_id"
+	samlRequestTTL    = 10 * time.Minute
+)
+
 var passRegex = regexp.MustCompile("^.{8,}$")
 
 // MakeHandler returns a HTTP handler for API endpoints.
-func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient magistrala.TokenServiceClient, selfRegister bool, r *chi.Mux, logger *slog.Logger, pr *regexp.Regexp, providers ...oauth2.Provider) http.Handler {
+func clientsHandler(svc users.Service, grps groups.Service, authn mgauthn.Authentication, tokenClient magistrala.TokenServiceClient, selfRegister bool, r *chi.Mux, logger *slog.Logger, pr *regexp.Regexp, pl api.PageLimits, pe passwords.Evaluator, sl, dl *api.KeyedLimiter, sp saml.ServiceProvider, providers ...oauth2.Provider) http.Handler {
 	passRegex = pr
+	pageLimits = api.NewPageLimits(pl.Default, pl.Max)
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, api.EncodeError)),
@@ -54,9 +71,93 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 			), "register_client").ServeHTTP)
 		}
 
+		r.Post("/password/strength", otelhttp.NewHandler(kithttp.NewServer(
+			rateLimit(sl)(passwordStrengthEndpoint(pe)),
+			decodePasswordStrength,
+			api.EncodeResponse,
+			append(opts, kithttp.ServerBefore(clientIPToContext))...,
+		), "password_strength").ServeHTTP)
+
+		r.Get("/identity/confirm", otelhttp.NewHandler(kithttp.NewServer(
+			confirmIdentityEndpoint(svc),
+			decodeConfirmIdentity,
+			api.EncodeResponse,
+			opts...,
+		), "confirm_identity").ServeHTTP)
+
+		r.Get("/deletion/confirm", otelhttp.NewHandler(kithttp.NewServer(
+			confirmDeletionEndpoint(svc),
+			decodeDeletionToken,
+			api.EncodeResponse,
+			opts...,
+		), "confirm_deletion").ServeHTTP)
+
+		r.Post("/deletion/cancel", otelhttp.NewHandler(kithttp.NewServer(
+			cancelDeletionEndpoint(svc),
+			decodeDeletionToken,
+			api.EncodeResponse,
+			opts...,
+		), "cancel_deletion").ServeHTTP)
+
+		r.Post("/phone/verify", otelhttp.NewHandler(kithttp.NewServer(
+			verifyPhoneEndpoint(svc),
+			decodeVerifyPhone,
+			api.EncodeResponse,
+			opts...,
+		), "verify_phone").ServeHTTP)
+
+		r.Post("/phone/code", otelhttp.NewHandler(kithttp.NewServer(
+			sendPhoneCodeEndpoint(svc),
+			decodeSendPhoneCode,
+			api.EncodeResponse,
+			opts...,
+		), "send_phone_code").ServeHTTP)
+
+		r.Post("/verify/resend", otelhttp.NewHandler(kithttp.NewServer(
+			resendVerificationEndpoint(svc),
+			decodeResendVerification,
+			api.EncodeResponse,
+			opts...,
+		), "resend_verification").ServeHTTP)
+
+		r.Post("/mfa/enroll", otelhttp.NewHandler(kithttp.NewServer(
+			enrollMFAEndpoint(svc),
+			decodeCredentials,
+			api.EncodeResponse,
+			opts...,
+		), "enroll_mfa").ServeHTTP)
+
+		r.Post("/mfa/confirm", otelhttp.NewHandler(kithttp.NewServer(
+			confirmMFAEndpoint(svc),
+			decodeCredentials,
+			api.EncodeResponse,
+			opts...,
+		), "confirm_mfa").ServeHTTP)
+
+		r.Post("/oauth/device/code", otelhttp.NewHandler(kithttp.NewServer(
+			rateLimit(dl)(deviceCodeEndpoint(svc)),
+			decodeDeviceCode,
+			api.EncodeResponse,
+			append(opts, kithttp.ServerBefore(clientIPToContext))...,
+		), "request_device_code").ServeHTTP)
+
+		r.Post("/tokens/device", otelhttp.NewHandler(kithttp.NewServer(
+			deviceTokenEndpoint(svc),
+			decodeDeviceToken,
+			api.EncodeResponse,
+			kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, encodeDeviceTokenError)),
+		), "device_token").ServeHTTP)
+
 		r.Group(func(r chi.Router) {
 			r.Use(api.AuthenticateMiddleware(authn, false))
 
+			r.Post("/oauth/device/verify", otelhttp.NewHandler(kithttp.NewServer(
+				rateLimit(dl)(authorizeDeviceEndpoint(svc)),
+				decodeAuthorizeDevice,
+				api.EncodeResponse,
+				append(opts, kithttp.ServerBefore(clientIPToContext))...,
+			), "authorize_device").ServeHTTP)
+
 			r.Get("/profile", otelhttp.NewHandler(kithttp.NewServer(
 				viewProfileEndpoint(svc),
 				decodeViewProfile,
@@ -64,6 +165,13 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 				opts...,
 			), "view_profile").ServeHTTP)
 
+			r.Post("/deletion", otelhttp.NewHandler(kithttp.NewServer(
+				requestDeletionEndpoint(svc),
+				decodeViewProfile,
+				api.EncodeResponse,
+				opts...,
+			), "request_deletion").ServeHTTP)
+
 			r.Get("/{id}", otelhttp.NewHandler(kithttp.NewServer(
 				viewClientEndpoint(svc),
 				decodeViewClient,
@@ -78,6 +186,13 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 				opts...,
 			), "list_clients").ServeHTTP)
 
+			r.Get("/changes", otelhttp.NewHandler(kithttp.NewServer(
+				listChangesEndpoint(svc),
+				decodeListChanges,
+				api.EncodeResponse,
+				opts...,
+			), "list_changes").ServeHTTP)
+
 			r.Get("/search", otelhttp.NewHandler(kithttp.NewServer(
 				searchClientsEndpoint(svc),
 				decodeSearchClients,
@@ -106,6 +221,13 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 				opts...,
 			), "update_client_tags").ServeHTTP)
 
+			r.Get("/{id}/groups", otelhttp.NewHandler(kithttp.NewServer(
+				listUserGroupsEndpoint(grps),
+				decodeListUserGroups,
+				api.EncodeResponse,
+				opts...,
+			), "list_user_groups").ServeHTTP)
+
 			r.Patch("/{id}/identity", otelhttp.NewHandler(kithttp.NewServer(
 				updateClientIdentityEndpoint(svc),
 				decodeUpdateClientIdentity,
@@ -127,6 +249,20 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 				opts...,
 			), "update_client_role").ServeHTTP)
 
+			r.Patch("/roles", otelhttp.NewHandler(kithttp.NewServer(
+				updateClientsRoleEndpoint(svc),
+				decodeUpdateClientsRole,
+				api.EncodeResponse,
+				opts...,
+			), "update_clients_role").ServeHTTP)
+
+			r.Post("/emails/{template}/preview", otelhttp.NewHandler(kithttp.NewServer(
+				previewEmailEndpoint(svc),
+				decodePreviewEmail,
+				api.EncodeResponse,
+				opts...,
+			), "preview_email").ServeHTTP)
+
 			r.Post("/{id}/enable", otelhttp.NewHandler(kithttp.NewServer(
 				enableClientEndpoint(svc),
 				decodeChangeClientStatus,
@@ -201,6 +337,27 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 			opts...,
 		), "list_users_by_thing_id").ServeHTTP)
 
+		r.Get("/{domainID}/groups/{groupID}/users/permissions", otelhttp.NewHandler(kithttp.NewServer(
+			listObjectPermissionsEndpoint(svc, policies.GroupsKind),
+			decodeListObjectPermissions("groupID"),
+			api.EncodeResponse,
+			opts...,
+		), "list_user_group_permissions").ServeHTTP)
+
+		r.Get("/{domainID}/channels/{channelID}/users/permissions", otelhttp.NewHandler(kithttp.NewServer(
+			listObjectPermissionsEndpoint(svc, policies.GroupsKind),
+			decodeListObjectPermissions("channelID"),
+			api.EncodeResponse,
+			opts...,
+		), "list_channel_permissions").ServeHTTP)
+
+		r.Get("/{domainID}/things/{thingID}/users/permissions", otelhttp.NewHandler(kithttp.NewServer(
+			listObjectPermissionsEndpoint(svc, policies.ThingsKind),
+			decodeListObjectPermissions("thingID"),
+			api.EncodeResponse,
+			opts...,
+		), "list_thing_permissions").ServeHTTP)
+
 		r.Get("/{domainID}/users", otelhttp.NewHandler(kithttp.NewServer(
 			listMembersByDomainEndpoint(svc),
 			decodeListMembersByDomain,
@@ -209,11 +366,11 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 		), "list_users_by_domain_id").ServeHTTP)
 	})
 
-	r.Post("/users/tokens/issue", otelhttp.NewHandler(kithttp.NewServer(
+	r.With(api.TokenBindingMiddleware).Post("/users/tokens/issue", otelhttp.NewHandler(kithttp.NewServer(
 		issueTokenEndpoint(svc),
 		decodeCredentials,
 		api.EncodeResponse,
-		opts...,
+		append(opts, kithttp.ServerBefore(clientIPToContext))...,
 	), "issue_token").ServeHTTP)
 
 	r.Post("/password/reset-request", otelhttp.NewHandler(kithttp.NewServer(
@@ -227,6 +384,14 @@ func clientsHandler(svc users.Service, authn mgauthn.Authentication, tokenClient
 		r.HandleFunc("/oauth/callback/"+provider.Name(), oauth2CallbackHandler(provider, svc, tokenClient))
 	}
 
+	if sp != nil {
+		r.Route("/saml", func(r chi.Router) {
+			r.Get("/metadata", samlMetadataHandler(sp))
+			r.Get("/login", samlLoginHandler(sp))
+			r.Post("/acs", samlACSHandler(sp, svc, tokenClient))
+		})
+	}
+
 	return r
 }
 
@@ -251,7 +416,7 @@ func decodeListClients(_ context.Context, r *http.Request) (interface{}, error)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
@@ -283,22 +448,32 @@ func decodeListClients(_ context.Context, r *http.Request) (interface{}, error)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
+	tu, err := apiutil.ReadBoolQuery(r, api.TotalUnfiltered, api.DefUnfiltered)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	p, err := apiutil.ReadStringQuery(r, api.OAuthProviderKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
 
 	st, err := mgclients.ToStatus(s)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
 	req := listClientsReq{
-		status:   st,
-		offset:   o,
-		limit:    l,
-		metadata: m,
-		name:     n,
-		identity: i,
-		tag:      t,
-		order:    order,
-		dir:      dir,
-		id:       id,
+		status:     st,
+		offset:     o,
+		limit:      l,
+		metadata:   m,
+		name:       n,
+		identity:   i,
+		tag:        t,
+		order:      order,
+		dir:        dir,
+		id:         id,
+		provider:   p,
+		unfiltered: tu,
 	}
 
 	return req, nil
@@ -309,7 +484,7 @@ func decodeSearchClients(_ context.Context, r *http.Request) (interface{}, error
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
 	if err != nil {
 		return nil, errors.Wrap(apiutil.ErrValidation, err)
 	}
@@ -349,6 +524,33 @@ func decodeSearchClients(_ context.Context, r *http.Request) (interface{}, error
 	return req, nil
 }
 
+func decodeListChanges(_ context.Context, r *http.Request) (interface{}, error) {
+	s, err := apiutil.ReadStringQuery(r, sinceKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	c, err := apiutil.ReadStringQuery(r, cursorKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	req := listChangesReq{
+		cursor: c,
+		limit:  l,
+	}
+	if s != "" {
+		if req.since, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(apiutil.ErrInvalidQueryParams, err))
+		}
+	}
+
+	return req, nil
+}
+
 func decodeUpdateClient(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -394,6 +596,80 @@ func decodeUpdateClientIdentity(_ context.Context, r *http.Request) (interface{}
 	return req, nil
 }
 
+func decodeConfirmIdentity(_ context.Context, r *http.Request) (interface{}, error) {
+	t, err := apiutil.ReadStringQuery(r, api.TokenKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	return confirmIdentityReq{token: t}, nil
+}
+
+func decodeDeletionToken(_ context.Context, r *http.Request) (interface{}, error) {
+	t, err := apiutil.ReadStringQuery(r, api.TokenKey, "")
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+
+	return deletionTokenReq{token: t}, nil
+}
+
+func decodeVerifyPhone(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, apiutil.ErrUnsupportedContentType
+	}
+
+	var req verifyPhoneReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+func decodeSendPhoneCode(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, apiutil.ErrUnsupportedContentType
+	}
+
+	var req sendPhoneCodeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+func decodeResendVerification(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, apiutil.ErrUnsupportedContentType
+	}
+
+	var req resendVerificationReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+// decodePreviewEmail decodes the optional template data. Without the body,
+// the template is rendered with sample data.
+func decodePreviewEmail(_ context.Context, r *http.Request) (interface{}, error) {
+	req := previewEmailReq{name: chi.URLParam(r, "template")}
+	if r.ContentLength == 0 {
+		return req, nil
+	}
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
 func decodeUpdateClientSecret(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -421,6 +697,21 @@ func decodePasswordResetRequest(_ context.Context, r *http.Request) (interface{}
 	return req, nil
 }
 
+func decodePasswordStrength(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, apiutil.ErrUnsupportedContentType
+	}
+
+	// Decoding error is dropped since it may quote the candidate password
+	// and errors are logged.
+	var req passwStrengthReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.ErrMalformedEntity)
+	}
+
+	return req, nil
+}
+
 func decodePasswordReset(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -450,6 +741,24 @@ func decodeUpdateClientRole(_ context.Context, r *http.Request) (interface{}, er
 	return req, err
 }
 
+func decodeUpdateClientsRole(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := updateClientsRoleReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+	role, err := mgclients.ToRole(req.Role)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	req.role = role
+
+	return req, nil
+}
+
 func decodeCredentials(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -463,6 +772,18 @@ func decodeCredentials(_ context.Context, r *http.Request) (interface{}, error)
 	return req, nil
 }
 
+// clientIPToContext stores the client IP address in the context. Behind
+// trusted proxies, the remote address is already set to the forwarded client
+// address by the real IP middleware.
+func clientIPToContext(ctx context.Context, r *http.Request) context.Context {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return users.WithClientIP(ctx, ip)
+}
+
 func decodeRefreshToken(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -472,6 +793,79 @@ func decodeRefreshToken(_ context.Context, r *http.Request) (interface{}, error)
 	return req, nil
 }
 
+func decodeDeviceCode(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := deviceCodeReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+func decodeAuthorizeDevice(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := authorizeDeviceReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+func decodeDeviceToken(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+
+	req := deviceTokenReq{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, errors.Wrap(err, errors.ErrMalformedEntity))
+	}
+
+	return req, nil
+}
+
+// deviceTokenErrors are the error codes of RFC 8628, which the polling
+// devices rely on to either keep polling, slow down, or give up.
+var deviceTokenErrors = []struct {
+	err  error
+	code string
+}{
+	{users.ErrAuthorizationPending, "authorization_pending"},
+	{users.ErrSlowDown, "slow_down"},
+	{users.ErrDeviceCodeExpired, "expired_token"},
+	{users.ErrDeviceAccessDenied, "access_denied"},
+	{users.ErrInvalidDeviceCode, "invalid_grant"},
+}
+
+// encodeDeviceTokenError encodes the device flow errors as the OAuth2 error
+// responses, and the other errors as the rest of the API does.
+func encodeDeviceTokenError(ctx context.Context, err error, w http.ResponseWriter) {
+	for _, dte := range deviceTokenErrors {
+		if errors.Contains(err, dte.err) {
+			w.Header().Set("Content-Type", api.ContentType)
+			w.WriteHeader(http.StatusBadRequest)
+			res := map[string]string{
+				"error":             dte.code,
+				"error_description": dte.err.Error(),
+			}
+			if err := json.NewEncoder(w).Encode(res); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+	}
+
+	api.EncodeError(ctx, err, w)
+}
+
 func decodeCreateClientReq(_ context.Context, r *http.Request) (interface{}, error) {
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
@@ -496,6 +890,24 @@ func decodeChangeClientStatus(_ context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeListUserGroups(_ context.Context, r *http.Request) (interface{}, error) {
+	o, err := apiutil.ReadNumQuery[uint64](r, api.OffsetKey, api.DefOffset)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
+	if err != nil {
+		return nil, errors.Wrap(apiutil.ErrValidation, err)
+	}
+	req := listUserGroupsReq{
+		id:     chi.URLParam(r, "id"),
+		offset: o,
+		limit:  l,
+	}
+
+	return req, nil
+}
+
 func decodeListMembersByGroup(_ context.Context, r *http.Request) (interface{}, error) {
 	page, err := queryPageParams(r, api.DefPermission)
 	if err != nil {
@@ -549,6 +961,22 @@ func decodeListMembersByDomain(_ context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+// decodeListObjectPermissions decodes the request listing permissions on the object identified by the idParam URL parameter.
+func decodeListObjectPermissions(idParam string) kithttp.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		page, err := queryPageParams(r, policies.ViewPermission)
+		if err != nil {
+			return nil, err
+		}
+		req := listMembersByObjectReq{
+			Page:     page,
+			objectID: chi.URLParam(r, idParam),
+		}
+
+		return req, nil
+	}
+}
+
 func queryPageParams(r *http.Request, defPermission string) (mgclients.Page, error) {
 	s, err := apiutil.ReadStringQuery(r, api.StatusKey, api.DefClientStatus)
 	if err != nil {
@@ -558,7 +986,7 @@ func queryPageParams(r *http.Request, defPermission string) (mgclients.Page, err
 	if err != nil {
 		return mgclients.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
-	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, api.DefLimit)
+	l, err := apiutil.ReadNumQuery[uint64](r, api.LimitKey, pageLimits.Default)
 	if err != nil {
 		return mgclients.Page{}, errors.Wrap(apiutil.ErrValidation, err)
 	}
@@ -629,40 +1057,11 @@ func oauth2CallbackHandler(oauth oauth2.Provider, svc users.Service, tokenClient
 				return
 			}
 
-			client, err = svc.OAuthCallback(r.Context(), client)
-			if err != nil {
-				http.Redirect(w, r, oauth.ErrorURL()+"?error="+err.Error(), http.StatusSeeOther)
-				return
-			}
-			if err := svc.OAuthAddClientPolicy(r.Context(), client); err != nil {
-				http.Redirect(w, r, oauth.ErrorURL()+"?error="+err.Error(), http.StatusSeeOther)
-				return
-			}
-
-			jwt, err := tokenClient.Issue(r.Context(), &magistrala.IssueReq{
-				UserId: client.ID,
-				Type:   uint32(mgauth.AccessKey),
-			})
-			if err != nil {
+			if err := federatedLogin(w, r, svc, tokenClient, client); err != nil {
 				http.Redirect(w, r, oauth.ErrorURL()+"?error="+err.Error(), http.StatusSeeOther)
 				return
 			}
 
-			http.SetCookie(w, &http.Cookie{
-				Name:     "access_token",
-				Value:    jwt.GetAccessToken(),
-				Path:     "/",
-				HttpOnly: true,
-				Secure:   true,
-			})
-			http.SetCookie(w, &http.Cookie{
-				Name:     "refresh_token",
-				Value:    jwt.GetRefreshToken(),
-				Path:     "/",
-				HttpOnly: true,
-				Secure:   true,
-			})
-
 			http.Redirect(w, r, oauth.RedirectURL(), http.StatusFound)
 			return
 		}
@@ -670,3 +1069,117 @@ func oauth2CallbackHandler(oauth oauth2.Provider, svc users.Service, tokenClient
 		http.Redirect(w, r, oauth.ErrorURL()+"?error=empty%20code", http.StatusSeeOther)
 	}
 }
+
+// samlMetadataHandler is a http.HandlerFunc that serves the SAML service provider metadata.
+func samlMetadataHandler(sp saml.ServiceProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		md, err := sp.Metadata()
+		if err != nil {
+			api.EncodeError(r.Context(), err, w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(md)
+	}
+}
+
+// samlLoginHandler is a http.HandlerFunc that redirects the user to the SAML identity provider.
+func samlLoginHandler(sp saml.ServiceProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		loginURL, requestID, err := sp.LoginURL(r.URL.Query().Get("RelayState"))
+		if err != nil {
+			http.Redirect(w, r, sp.ErrorURL()+"?error="+err.Error(), http.StatusSeeOther)
+			return
+		}
+
+		// The IdP posts the response cross-site, so the cookie must be sent
+		// with the cross-site requests.
+		http.SetCookie(w, &http.Cookie{
+			Name:     samlRequestCookie,
+			Value:    requestID,
+			Path:     "/",
+			MaxAge:   int(samlRequestTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+		http.Redirect(w, r, loginURL, http.StatusFound)
+	}
+}
+
+// samlACSHandler is a http.HandlerFunc that consumes the SAML assertions posted by the identity provider.
+func samlACSHandler(sp saml.ServiceProvider, svc users.Service, tokenClient magistrala.TokenServiceClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		samlResponse := r.PostFormValue("SAMLResponse")
+		if samlResponse == "" {
+			http.Redirect(w, r, sp.ErrorURL()+"?error=empty%20SAML%20response", http.StatusSeeOther)
+			return
+		}
+
+		var requestID string
+		if c, err := r.Cookie(samlRequestCookie); err == nil {
+			requestID = c.Value
+		}
+		// The request ID is used only once.
+		http.SetCookie(w, &http.Cookie{
+			Name:     samlRequestCookie,
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client, err := sp.ParseResponse(samlResponse, requestID)
+		if err != nil {
+			http.Redirect(w, r, sp.ErrorURL()+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+
+		if err := federatedLogin(w, r, svc, tokenClient, client); err != nil {
+			http.Redirect(w, r, sp.ErrorURL()+"?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+
+		http.Redirect(w, r, sp.RedirectURL(), http.StatusFound)
+	}
+}
+
+// federatedLogin provisions the user authenticated by the external identity
+// provider, if needed, and sets the issued tokens as cookies.
+func federatedLogin(w http.ResponseWriter, r *http.Request, svc users.Service, tokenClient magistrala.TokenServiceClient, client mgclients.Client) error {
+	client, err := svc.OAuthCallback(r.Context(), client)
+	if err != nil {
+		return err
+	}
+	if err := svc.OAuthAddClientPolicy(r.Context(), client); err != nil {
+		return err
+	}
+
+	jwt, err := tokenClient.Issue(r.Context(), &magistrala.IssueReq{
+		UserId: client.ID,
+		Type:   uint32(mgauth.AccessKey),
+		Claims: users.Claims(client.Metadata),
+	})
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     "access_token",
+		Value:    jwt.GetAccessToken(),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    jwt.GetRefreshToken(),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+	})
+
+	return nil
+}
@@ -4,6 +4,8 @@
 package api_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,10 +14,12 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/absmach/magistrala"
 	authmocks "github.com/absmach/magistrala/auth/mocks"
 	"github.com/absmach/magistrala/internal/api"
+	"github.com/absmach/magistrala/internal/email"
 	"github.com/absmach/magistrala/internal/testsutil"
 	mglog "github.com/absmach/magistrala/logger"
 	"github.com/absmach/magistrala/pkg/apiutil"
@@ -24,13 +28,21 @@ import (
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/groups"
 	gmocks "github.com/absmach/magistrala/pkg/groups/mocks"
 	oauth2mocks "github.com/absmach/magistrala/pkg/oauth2/mocks"
+	"github.com/absmach/magistrala/pkg/passwords"
+	qmocks "github.com/absmach/magistrala/pkg/quotas/mocks"
+	"github.com/absmach/magistrala/pkg/requestid"
+	"github.com/absmach/magistrala/users"
 	httpapi "github.com/absmach/magistrala/users/api"
+	"github.com/absmach/magistrala/users/middleware"
 	"github.com/absmach/magistrala/users/mocks"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -85,6 +97,10 @@ func (tr testRequest) make() (*http.Response, error) {
 }
 
 func newUsersServer() (*httptest.Server, *mocks.Service, *gmocks.Service, *authnmocks.Authentication) {
+	return newUsersServerWithConfig(api.PageLimits{}, passwords.NewEvaluator(passRegex, 0), nil, nil)
+}
+
+func newUsersServerWithConfig(pl api.PageLimits, pe passwords.Evaluator, sl, dl *api.KeyedLimiter) (*httptest.Server, *mocks.Service, *gmocks.Service, *authnmocks.Authentication) {
 	svc := new(mocks.Service)
 	gsvc := new(gmocks.Service)
 
@@ -94,7 +110,7 @@ func newUsersServer() (*httptest.Server, *mocks.Service, *gmocks.Service, *authn
 	provider.On("Name").Return("test")
 	authn := new(authnmocks.Authentication)
 	token := new(authmocks.TokenServiceClient)
-	httpapi.MakeHandler(svc, authn, token, true, gsvc, mux, logger, "", passRegex, provider)
+	httpapi.MakeHandler(svc, authn, token, true, gsvc, new(qmocks.Service), mux, logger, "", passRegex, pl, pe, sl, dl, nil, nil, provider)
 
 	return httptest.NewServer(mux), svc, gsvc, authn
 }
@@ -391,6 +407,78 @@ func TestViewProfile(t *testing.T) {
 	}
 }
 
+func TestRequestID(t *testing.T) {
+	svc := new(mocks.Service)
+	authn := new(authnmocks.Authentication)
+	provider := new(oauth2mocks.Provider)
+	provider.On("Name").Return("test")
+	buf := &bytes.Buffer{}
+	logger, err := mglog.New(buf, "info", mglog.JSONFormat)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating logger: %s", err))
+	mux := chi.NewRouter()
+	httpapi.MakeHandler(middleware.LoggingMiddleware(svc, logger), authn, new(authmocks.TokenServiceClient), true, new(gmocks.Service), new(qmocks.Service), mux, logger, "", passRegex, api.PageLimits{}, passwords.NewEvaluator(passRegex, 0), nil, nil, nil, nil, provider)
+	us := httptest.NewServer(mux)
+	defer us.Close()
+
+	cases := []struct {
+		desc      string
+		requestID string
+		generated bool
+	}{
+		{
+			desc:      "request with request ID",
+			requestID: validID,
+		},
+		{
+			desc:      "request without request ID",
+			generated: true,
+		},
+		{
+			desc:      "request with invalid request ID",
+			requestID: "invalid request id",
+			generated: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			buf.Reset()
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/users/profile", us.URL), nil)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			req.Header.Set("Authorization", apiutil.BearerPrefix+validToken)
+			if tc.requestID != "" {
+				req.Header.Set(requestid.Header, tc.requestID)
+			}
+
+			var svcID string
+			authnCall := authn.On("Authenticate", mock.Anything, validToken).Return(mgauthn.Session{UserID: validID, DomainID: domainID}, nil)
+			svcCall := svc.On("ViewProfile", mock.Anything, mock.Anything).Return(client, nil).Run(func(args mock.Arguments) {
+				svcID = requestid.FromContext(args.Get(0).(context.Context))
+			})
+			res, err := us.Client().Do(req)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusOK, res.StatusCode))
+
+			echoed := res.Header.Get(requestid.Header)
+			assert.NotEmpty(t, echoed, fmt.Sprintf("%s: expected request ID in response header", tc.desc))
+			switch tc.generated {
+			case true:
+				assert.NotEqual(t, tc.requestID, echoed, fmt.Sprintf("%s: expected request ID to be generated", tc.desc))
+			default:
+				assert.Equal(t, tc.requestID, echoed, fmt.Sprintf("%s: expected request ID %s got %s", tc.desc, tc.requestID, echoed))
+			}
+			assert.Equal(t, echoed, svcID, fmt.Sprintf("%s: expected service context request ID %s got %s", tc.desc, echoed, svcID))
+
+			var entry map[string]interface{}
+			err = json.Unmarshal(buf.Bytes(), &entry)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error parsing log entry: %s", tc.desc, err))
+			assert.Equal(t, echoed, entry[requestid.LogKey], fmt.Sprintf("%s: expected request ID %s in log entry", tc.desc, echoed))
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
 func TestListClients(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
@@ -676,6 +764,38 @@ func TestListClients(t *testing.T) {
 			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
 			err:      nil,
 		},
+		{
+			desc: "list users with metadata order",
+			listUsersResponse: mgclients.ClientsPage{
+				Page: mgclients.Page{
+					Total: 1,
+				},
+				Clients: []mgclients.Client{
+					client,
+				},
+			},
+			token:    validToken,
+			query:    "order=metadata.org.department&dir=desc",
+			status:   http.StatusOK,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			err:      nil,
+		},
+		{
+			desc:     "list users with invalid metadata order",
+			token:    validToken,
+			query:    "order=metadata.department'",
+			status:   http.StatusBadRequest,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			err:      apiutil.ErrValidation,
+		},
+		{
+			desc:     "list users with empty metadata order",
+			token:    validToken,
+			query:    "order=metadata.",
+			status:   http.StatusBadRequest,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			err:      apiutil.ErrValidation,
+		},
 		{
 			desc:     "list users with duplicate order",
 			token:    validToken,
@@ -730,6 +850,270 @@ func TestListClients(t *testing.T) {
 	}
 }
 
+func TestListClientsPageLimits(t *testing.T) {
+	us, svc, _, authn := newUsersServerWithConfig(api.PageLimits{Default: 5, Max: 20}, passwords.NewEvaluator(passRegex, 0), nil, nil)
+	defer us.Close()
+
+	cases := []struct {
+		desc   string
+		query  string
+		limit  uint64
+		status int
+		err    error
+	}{
+		{
+			desc:   "list users with omitted limit uses configured default",
+			query:  "",
+			limit:  5,
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:   "list users with limit equal to configured max",
+			query:  "limit=20",
+			limit:  20,
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:   "list users with limit above configured max",
+			query:  "limit=21",
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrLimitSize,
+		},
+		{
+			desc:   "list users with limit allowed by default max",
+			query:  fmt.Sprintf("limit=%d", api.MaxLimitSize),
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrLimitSize,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodGet,
+				url:         us.URL + "/users?" + tc.query,
+				contentType: contentType,
+				token:       validToken,
+			}
+
+			session := mgauthn.Session{UserID: validID, DomainID: domainID}
+			authnCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := svc.On("ListClients", mock.Anything, session, mock.Anything).Return(mgclients.ClientsPage{}, nil).Run(func(args mock.Arguments) {
+				pm := args.Get(2).(mgclients.Page)
+				assert.Equal(t, tc.limit, pm.Limit, fmt.Sprintf("%s: expected limit %d got %d", tc.desc, tc.limit, pm.Limit))
+			})
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var bodyRes respBody
+			err = json.NewDecoder(res.Body).Decode(&bodyRes)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if bodyRes.Err != "" || bodyRes.Message != "" {
+				err = errors.Wrap(errors.New(bodyRes.Err), errors.New(bodyRes.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
+func TestListClientsTotalUnfiltered(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	var empty, unfiltered uint64 = 0, 500
+	cases := []struct {
+		desc            string
+		query           string
+		unfiltered      bool
+		page            mgclients.Page
+		total           int
+		totalUnfiltered *uint64
+		status          int
+		err             error
+	}{
+		{
+			desc:   "list users without total unfiltered",
+			query:  "status=enabled",
+			page:   mgclients.Page{Total: 42},
+			total:  42,
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:            "list users with total unfiltered and no filters",
+			query:           "status=all&total_unfiltered=true",
+			unfiltered:      true,
+			page:            mgclients.Page{Total: 500, TotalUnfiltered: &unfiltered},
+			total:           500,
+			totalUnfiltered: &unfiltered,
+			status:          http.StatusOK,
+			err:             nil,
+		},
+		{
+			desc:            "list users with total unfiltered and filters",
+			query:           "name=client&total_unfiltered=true",
+			unfiltered:      true,
+			page:            mgclients.Page{Total: 42, TotalUnfiltered: &unfiltered},
+			total:           42,
+			totalUnfiltered: &unfiltered,
+			status:          http.StatusOK,
+			err:             nil,
+		},
+		{
+			desc:            "list users with zero total unfiltered",
+			query:           "total_unfiltered=true",
+			unfiltered:      true,
+			page:            mgclients.Page{Total: 0, TotalUnfiltered: &empty},
+			total:           0,
+			totalUnfiltered: &empty,
+			status:          http.StatusOK,
+			err:             nil,
+		},
+		{
+			desc:   "list users with invalid total unfiltered",
+			query:  "total_unfiltered=invalid",
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrValidation,
+		},
+		{
+			desc:   "list users with duplicate total unfiltered",
+			query:  "total_unfiltered=true&total_unfiltered=true",
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrInvalidQueryParams,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodGet,
+				url:         us.URL + "/users?" + tc.query,
+				contentType: contentType,
+				token:       validToken,
+			}
+
+			session := mgauthn.Session{UserID: validID, DomainID: domainID}
+			authnCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := svc.On("ListClients", mock.Anything, session, mock.Anything).Return(mgclients.ClientsPage{Page: tc.page}, nil).Run(func(args mock.Arguments) {
+				pm := args.Get(2).(mgclients.Page)
+				assert.Equal(t, tc.unfiltered, pm.CountUnfiltered, fmt.Sprintf("%s: expected count unfiltered %t got %t", tc.desc, tc.unfiltered, pm.CountUnfiltered))
+			})
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var bodyRes respBody
+			err = json.NewDecoder(res.Body).Decode(&bodyRes)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if bodyRes.Err != "" || bodyRes.Message != "" {
+				err = errors.Wrap(errors.New(bodyRes.Err), errors.New(bodyRes.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			assert.Equal(t, tc.total, bodyRes.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, bodyRes.Total))
+			assert.Equal(t, tc.totalUnfiltered, bodyRes.TotalUnfiltered, fmt.Sprintf("%s: expected total unfiltered %v got %v", tc.desc, tc.totalUnfiltered, bodyRes.TotalUnfiltered))
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
+func TestListClientsByOAuthProvider(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc     string
+		query    string
+		provider string
+		status   int
+		err      error
+	}{
+		{
+			desc:     "list users by oauth provider",
+			query:    "oauth_provider=google",
+			provider: "google",
+			status:   http.StatusOK,
+			err:      nil,
+		},
+		{
+			desc:   "list users without oauth provider",
+			query:  "status=enabled",
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:   "list users with duplicate oauth provider",
+			query:  "oauth_provider=google&oauth_provider=saml",
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrInvalidQueryParams,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodGet,
+				url:         us.URL + "/users?" + tc.query,
+				contentType: contentType,
+				token:       validToken,
+			}
+
+			session := mgauthn.Session{UserID: validID, DomainID: domainID}
+			authnCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+			svcCall := svc.On("ListClients", mock.Anything, session, mock.Anything).Return(mgclients.ClientsPage{}, nil).Run(func(args mock.Arguments) {
+				pm := args.Get(2).(mgclients.Page)
+				assert.Equal(t, tc.provider, pm.OAuthProvider, fmt.Sprintf("%s: expected oauth provider %s got %s", tc.desc, tc.provider, pm.OAuthProvider))
+			})
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var bodyRes respBody
+			err = json.NewDecoder(res.Body).Decode(&bodyRes)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if bodyRes.Err != "" || bodyRes.Message != "" {
+				err = errors.Wrap(errors.New(bodyRes.Err), errors.New(bodyRes.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
+func TestViewClientLinkedProviders(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	linked := client
+	linked.LinkedProviders = []string{"google", "saml"}
+
+	req := testRequest{
+		client: us.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/users/%s", us.URL, client.ID),
+		token:  validToken,
+	}
+
+	session := mgauthn.Session{UserID: validID, DomainID: domainID}
+	authnCall := authn.On("Authenticate", mock.Anything, validToken).Return(session, nil)
+	svcCall := svc.On("ViewClient", mock.Anything, session, client.ID).Return(linked, nil)
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("expected status code %d got %d", http.StatusOK, res.StatusCode))
+	var resClient mgclients.Client
+	err = json.NewDecoder(res.Body).Decode(&resClient)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error while decoding response body: %s", err))
+	assert.Equal(t, linked.LinkedProviders, resClient.LinkedProviders, fmt.Sprintf("expected linked providers %v got %v", linked.LinkedProviders, resClient.LinkedProviders))
+	svcCall.Unset()
+	authnCall.Unset()
+}
+
 func TestSearchUsers(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
@@ -856,52 +1240,177 @@ func TestSearchUsers(t *testing.T) {
 	}
 }
 
-func TestUpdateClient(t *testing.T) {
+func TestListChanges(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
 
-	newName := "newname"
-	newMetadata := mgclients.Metadata{"newkey": "newvalue"}
+	since := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	deletedID := testsutil.GenerateUUID(t)
+	page := users.ChangesPage{
+		Changes: []users.Change{
+			{ID: client.ID, Operation: users.ChangeUpdated, ChangedAt: since.Add(time.Minute), Client: &client},
+			{ID: deletedID, Operation: users.ChangeDeleted, ChangedAt: since.Add(2 * time.Minute)},
+		},
+		Cursor: "cursor",
+	}
 
 	cases := []struct {
-		desc           string
-		id             string
-		data           string
-		clientResponse mgclients.Client
-		token          string
-		authnRes       mgauthn.Session
-		authnErr       error
-		contentType    string
-		status         int
-		err            error
+		desc     string
+		token    string
+		query    string
+		since    time.Time
+		cursor   string
+		limit    uint64
+		response users.ChangesPage
+		status   int
+		authnErr error
+		err      error
 	}{
 		{
-			desc:        "update as admin user with valid token",
-			id:          client.ID,
-			data:        fmt.Sprintf(`{"name":"%s","metadata":%s}`, newName, toJSON(newMetadata)),
-			token:       validToken,
-			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
-			contentType: contentType,
-			clientResponse: mgclients.Client{
-				ID:       client.ID,
-				Name:     newName,
-				Metadata: newMetadata,
-			},
-			status: http.StatusOK,
-			err:    nil,
+			desc:     "list changes since time",
+			token:    validToken,
+			query:    "since=" + since.Format(time.RFC3339),
+			since:    since,
+			limit:    10,
+			response: page,
+			status:   http.StatusOK,
 		},
 		{
-			desc:        "update as normal user with valid token",
-			id:          client.ID,
-			data:        fmt.Sprintf(`{"name":"%s","metadata":%s}`, newName, toJSON(newMetadata)),
-			token:       validToken,
-			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
-			contentType: contentType,
-			clientResponse: mgclients.Client{
-				ID:       client.ID,
-				Name:     newName,
-				Metadata: newMetadata,
-			},
+			desc:     "list changes with cursor and limit",
+			token:    validToken,
+			query:    "cursor=cursor&limit=5",
+			cursor:   "cursor",
+			limit:    5,
+			response: page,
+			status:   http.StatusOK,
+		},
+		{
+			desc:     "list changes with invalid token",
+			token:    inValidToken,
+			status:   http.StatusUnauthorized,
+			authnErr: svcerr.ErrAuthentication,
+		},
+		{
+			desc:   "list changes with invalid since",
+			token:  validToken,
+			query:  "since=yesterday",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "list changes with both since and cursor",
+			token:  validToken,
+			query:  "since=" + since.Format(time.RFC3339) + "&cursor=cursor",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "list changes with invalid limit",
+			token:  validToken,
+			query:  "limit=0",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "list changes as normal user",
+			token:  validToken,
+			limit:  10,
+			status: http.StatusForbidden,
+			err:    svcerr.ErrAuthorization,
+		},
+		{
+			desc:   "list changes with invalid cursor",
+			token:  validToken,
+			query:  "cursor=invalid",
+			cursor: "invalid",
+			limit:  10,
+			status: http.StatusBadRequest,
+			err:    errors.Wrap(svcerr.ErrMalformedEntity, users.ErrInvalidCursor),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: us.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/users/changes?%s", us.URL, tc.query),
+				token:  tc.token,
+			}
+
+			authnCall := authn.On("Authenticate", mock.Anything, tc.token).Return(mgauthn.Session{UserID: validID, DomainID: domainID}, tc.authnErr)
+			svcCall := svc.On("ListChanges", mock.Anything, mock.Anything, tc.since, tc.cursor, tc.limit).Return(tc.response, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusOK {
+				var body struct {
+					Changes []struct {
+						Operation string            `json:"operation"`
+						ID        string            `json:"id"`
+						User      *mgclients.Client `json:"user"`
+					} `json:"changes"`
+					Cursor string `json:"cursor"`
+				}
+				err := json.NewDecoder(res.Body).Decode(&body)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error decoding response %s", tc.desc, err))
+				assert.Equal(t, page.Cursor, body.Cursor, fmt.Sprintf("%s: expected cursor %s got %s", tc.desc, page.Cursor, body.Cursor))
+				assert.Len(t, body.Changes, len(page.Changes), fmt.Sprintf("%s: expected %d changes got %d", tc.desc, len(page.Changes), len(body.Changes)))
+				if len(body.Changes) == len(page.Changes) {
+					assert.Equal(t, client.ID, body.Changes[0].User.ID, fmt.Sprintf("%s: expected the updated user", tc.desc))
+					assert.Equal(t, deletedID, body.Changes[1].ID, fmt.Sprintf("%s: expected the tombstone ID %s got %s", tc.desc, deletedID, body.Changes[1].ID))
+					assert.Nil(t, body.Changes[1].User, fmt.Sprintf("%s: expected the tombstone without user", tc.desc))
+				}
+			}
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
+func TestUpdateClient(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	newName := "newname"
+	newMetadata := mgclients.Metadata{"newkey": "newvalue"}
+
+	cases := []struct {
+		desc           string
+		id             string
+		data           string
+		clientResponse mgclients.Client
+		token          string
+		authnRes       mgauthn.Session
+		authnErr       error
+		contentType    string
+		status         int
+		err            error
+	}{
+		{
+			desc:        "update as admin user with valid token",
+			id:          client.ID,
+			data:        fmt.Sprintf(`{"name":"%s","metadata":%s}`, newName, toJSON(newMetadata)),
+			token:       validToken,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			contentType: contentType,
+			clientResponse: mgclients.Client{
+				ID:       client.ID,
+				Name:     newName,
+				Metadata: newMetadata,
+			},
+			status: http.StatusOK,
+			err:    nil,
+		},
+		{
+			desc:        "update as normal user with valid token",
+			id:          client.ID,
+			data:        fmt.Sprintf(`{"name":"%s","metadata":%s}`, newName, toJSON(newMetadata)),
+			token:       validToken,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			contentType: contentType,
+			clientResponse: mgclients.Client{
+				ID:       client.ID,
+				Name:     newName,
+				Metadata: newMetadata,
+			},
 			status: http.StatusOK,
 			err:    nil,
 		},
@@ -1392,6 +1901,165 @@ func TestPasswordResetRequest(t *testing.T) {
 	}
 }
 
+func TestPasswordStrength(t *testing.T) {
+	us, _, _, _ := newUsersServerWithConfig(api.PageLimits{}, passwords.NewEvaluator(passRegex, 3), nil, nil)
+	defer us.Close()
+
+	cases := []struct {
+		desc        string
+		data        string
+		contentType string
+		status      int
+		valid       bool
+		maxScore    int
+		minScore    int
+		failed      []string
+		err         error
+	}{
+		{
+			desc:        "password strength with weak password",
+			data:        `{"password": "password1"}`,
+			contentType: contentType,
+			status:      http.StatusOK,
+			valid:       false,
+			maxScore:    1,
+			failed:      []string{passwords.CommonRule, passwords.MinScoreRule},
+			err:         nil,
+		},
+		{
+			desc:        "password strength with password not matching policy",
+			data:        `{"password": "aaaaaaa"}`,
+			contentType: contentType,
+			status:      http.StatusOK,
+			valid:       false,
+			maxScore:    0,
+			failed:      []string{passwords.PolicyRule, passwords.RepeatedRule, passwords.VarietyRule, passwords.MinScoreRule},
+			err:         nil,
+		},
+		{
+			desc:        "password strength with strong password",
+			data:        `{"password": "correct-Horse7-battery-Staple"}`,
+			contentType: contentType,
+			status:      http.StatusOK,
+			valid:       true,
+			maxScore:    passwords.MaxScore,
+			minScore:    passwords.MaxScore,
+			err:         nil,
+		},
+		{
+			desc:        "password strength with empty password",
+			data:        `{"password": ""}`,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrMissingPass,
+		},
+		{
+			desc:        "password strength with malformed data",
+			data:        `{"password": password}`,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "password strength with invalid content type",
+			data:        `{"password": "password1"}`,
+			contentType: "application/xml",
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrUnsupportedContentType,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/password/strength", us.URL),
+				contentType: tc.contentType,
+				body:        strings.NewReader(tc.data),
+			}
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var bodyRes struct {
+				respBody
+				passwords.Strength
+			}
+			err = json.NewDecoder(res.Body).Decode(&bodyRes)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if bodyRes.Err != "" || bodyRes.Message != "" {
+				err = errors.Wrap(errors.New(bodyRes.Err), errors.New(bodyRes.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			assert.Equal(t, tc.valid, bodyRes.Valid, fmt.Sprintf("%s: expected valid %t got %t", tc.desc, tc.valid, bodyRes.Valid))
+			assert.LessOrEqual(t, bodyRes.Score, tc.maxScore, fmt.Sprintf("%s: expected score at most %d got %d", tc.desc, tc.maxScore, bodyRes.Score))
+			assert.GreaterOrEqual(t, bodyRes.Score, tc.minScore, fmt.Sprintf("%s: expected score at least %d got %d", tc.desc, tc.minScore, bodyRes.Score))
+			assert.ElementsMatch(t, tc.failed, bodyRes.Failed, fmt.Sprintf("%s: expected failed rules %v got %v", tc.desc, tc.failed, bodyRes.Failed))
+		})
+	}
+}
+
+func TestPasswordStrengthRateLimit(t *testing.T) {
+	mux := chi.NewRouter()
+	httpapi.MakeHandler(new(mocks.Service), new(authnmocks.Authentication), new(authmocks.TokenServiceClient), true, new(gmocks.Service), new(qmocks.Service), mux, mglog.NewMock(), "", passRegex, api.PageLimits{}, passwords.NewEvaluator(passRegex, 0), api.NewKeyedLimiter(rate.Every(time.Hour), 2, 0), nil, nil, nil)
+	// The test client is the trusted proxy forwarding the requests.
+	proxies, err := api.ParseTrustedProxies("127.0.0.1,::1")
+	require.Nil(t, err, fmt.Sprintf("unexpected error parsing proxies: %s", err))
+	us := httptest.NewServer(api.RealIPMiddleware(proxies)(mux))
+	defer us.Close()
+
+	cases := []struct {
+		ip     string
+		status int
+	}{
+		{ip: "192.0.2.1", status: http.StatusOK},
+		{ip: "192.0.2.1", status: http.StatusOK},
+		{ip: "192.0.2.1", status: http.StatusTooManyRequests},
+		{ip: "192.0.2.2", status: http.StatusOK},
+	}
+	for i, tc := range cases {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/users/password/strength", us.URL), strings.NewReader(`{"password": "password1"}`))
+		require.Nil(t, err, fmt.Sprintf("request %d: unexpected error %s", i, err))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Forwarded-For", tc.ip)
+		res, err := us.Client().Do(req)
+		assert.Nil(t, err, fmt.Sprintf("request %d: unexpected error %s", i, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("request %d from %s: expected status code %d got %d", i, tc.ip, tc.status, res.StatusCode))
+	}
+}
+
+func TestDeviceCodeRateLimit(t *testing.T) {
+	mux := chi.NewRouter()
+	svc := new(mocks.Service)
+	svc.On("RequestDeviceCode", mock.Anything, "").Return(users.DeviceAuthorization{DeviceCode: validToken, UserCode: "BCDF-GHJK"}, nil)
+	httpapi.MakeHandler(svc, new(authnmocks.Authentication), new(authmocks.TokenServiceClient), true, new(gmocks.Service), new(qmocks.Service), mux, mglog.NewMock(), "", passRegex, api.PageLimits{}, passwords.NewEvaluator(passRegex, 0), nil, api.NewKeyedLimiter(rate.Every(time.Hour), 2, 0), nil, nil)
+	// The test client is the trusted proxy forwarding the requests.
+	proxies, err := api.ParseTrustedProxies("127.0.0.1,::1")
+	require.Nil(t, err, fmt.Sprintf("unexpected error parsing proxies: %s", err))
+	us := httptest.NewServer(api.RealIPMiddleware(proxies)(mux))
+	defer us.Close()
+
+	cases := []struct {
+		ip     string
+		status int
+	}{
+		{ip: "192.0.2.1", status: http.StatusOK},
+		{ip: "192.0.2.1", status: http.StatusOK},
+		{ip: "192.0.2.1", status: http.StatusTooManyRequests},
+		{ip: "192.0.2.2", status: http.StatusOK},
+	}
+	for i, tc := range cases {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/users/oauth/device/code", us.URL), strings.NewReader(`{}`))
+		require.Nil(t, err, fmt.Sprintf("request %d: unexpected error %s", i, err))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Forwarded-For", tc.ip)
+		res, err := us.Client().Do(req)
+		assert.Nil(t, err, fmt.Sprintf("request %d: unexpected error %s", i, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("request %d from %s: expected status code %d got %d", i, tc.ip, tc.status, res.StatusCode))
+	}
+	svc.AssertNumberOfCalls(t, "RequestDeviceCode", 3)
+}
+
 func TestPasswordReset(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
@@ -1605,88 +2273,632 @@ func TestUpdateClientRole(t *testing.T) {
 	}
 }
 
-func TestUpdateClientSecret(t *testing.T) {
+func TestUpdateClientsRole(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
 
+	unauthorizedID := testsutil.GenerateUUID(t)
+	promoted := client
+	promoted.Role = mgclients.AdminRole
+
+	type resultRes struct {
+		ID    string           `json:"id"`
+		User  mgclients.Client `json:"user"`
+		Error string           `json:"error"`
+	}
+
 	cases := []struct {
 		desc        string
 		data        string
-		client      mgclients.Client
-		contentType string
 		token       string
-		status      int
+		contentType string
 		authnRes    mgauthn.Session
 		authnErr    error
+		svcRes      []users.RoleUpdate
+		svcErr      error
+		results     []resultRes
+		status      int
 		err         error
 	}{
 		{
-			desc: "update user secret with valid token",
-			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
-			client: mgclients.Client{
-				ID: client.ID,
-				Credentials: mgclients.Credentials{
-					Identity: "clientname",
-					Secret:   "strongersecret",
-				},
-			},
-			contentType: contentType,
+			desc:        "update clients role with authorized and unauthorized clients",
+			data:        fmt.Sprintf(`{"user_ids": ["%s", "%s"], "role": "admin"}`, client.ID, unauthorizedID),
 			token:       validToken,
-			status:      http.StatusOK,
-			err:         nil,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			svcRes: []users.RoleUpdate{
+				{ID: client.ID, Client: promoted},
+				{ID: unauthorizedID, Err: errors.Wrap(svcerr.ErrAuthorization, errors.ErrAuthorization)},
+			},
+			results: []resultRes{
+				{ID: client.ID, User: promoted},
+				{ID: unauthorizedID, Error: svcerr.ErrAuthorization.Error()},
+			},
+			status: http.StatusOK,
+			err:    nil,
 		},
 		{
-			desc: "update user secret with empty token",
-			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
-			client: mgclients.Client{
-				ID: client.ID,
-				Credentials: mgclients.Credentials{
-					Identity: "clientname",
-					Secret:   "strongersecret",
-				},
-			},
+			desc:        "update clients role with invalid token",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "admin"}`, client.ID),
+			token:       inValidToken,
 			contentType: contentType,
-			token:       "",
-			status:      http.StatusUnauthorized,
 			authnErr:    svcerr.ErrAuthentication,
-			err:         apiutil.ErrBearerToken,
+			status:      http.StatusUnauthorized,
+			err:         svcerr.ErrAuthentication,
 		},
 		{
-			desc: "update user secret with invalid token",
-			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
-			client: mgclients.Client{
-				ID: client.ID,
-				Credentials: mgclients.Credentials{
-					Identity: "clientname",
-					Secret:   "strongersecret",
-				},
-			},
+			desc:        "update clients role with empty token",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "admin"}`, client.ID),
+			token:       "",
 			contentType: contentType,
-			token:       inValid,
 			status:      http.StatusUnauthorized,
-			authnErr:    svcerr.ErrAuthentication,
-			err:         svcerr.ErrAuthentication,
+			err:         apiutil.ErrBearerToken,
 		},
-
 		{
-			desc: "update user secret with empty secret",
-			data: `{"old_secret": "", "new_secret": "strongersecret"}`,
-			client: mgclients.Client{
-				ID: client.ID,
-				Credentials: mgclients.Credentials{
-					Identity: "clientname",
-					Secret:   "",
-				},
-			},
+			desc:        "update clients role with invalid role",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "invalid"}`, client.ID),
+			token:       validToken,
 			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "update clients role with all role",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "all"}`, client.ID),
 			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
 			status:      http.StatusBadRequest,
-			err:         apiutil.ErrMissingPass,
+			err:         apiutil.ErrValidation,
 		},
 		{
-			desc: "update user secret with invalid contentype",
-			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
-			client: mgclients.Client{
+			desc:        "update clients role with empty list",
+			data:        `{"user_ids": [], "role": "admin"}`,
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "update clients role with empty ID",
+			data:        `{"user_ids": [""], "role": "admin"}`,
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "update clients role with too many IDs",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "admin"}`, strings.Repeat(client.ID+`", "`, 100)+client.ID),
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "update clients role with invalid content type",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "admin"}`, client.ID),
+			token:       validToken,
+			contentType: "application/xml",
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "update clients role with malformed data",
+			data:        `{"user_ids": "invalid", "role": "admin"}`,
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "update clients role with service error",
+			data:        fmt.Sprintf(`{"user_ids": ["%s"], "role": "admin"}`, client.ID),
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			svcErr:      svcerr.ErrAuthorization,
+			status:      http.StatusForbidden,
+			err:         svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPatch,
+				url:         fmt.Sprintf("%s/users/roles", us.URL),
+				contentType: tc.contentType,
+				token:       tc.token,
+				body:        strings.NewReader(tc.data),
+			}
+
+			authnCall := authn.On("Authenticate", mock.Anything, tc.token).Return(tc.authnRes, tc.authnErr)
+			svcCall := svc.On("UpdateClientsRole", mock.Anything, tc.authnRes, mock.Anything, mgclients.AdminRole).Return(tc.svcRes, tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody struct {
+				respBody
+				Results []resultRes `json:"results"`
+			}
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.err == nil {
+				assert.Len(t, resBody.Results, len(tc.results), fmt.Sprintf("%s: expected %d results got %d", tc.desc, len(tc.results), len(resBody.Results)))
+				for i, r := range resBody.Results {
+					assert.Equal(t, tc.results[i].ID, r.ID, fmt.Sprintf("%s: expected result ID %s got %s", tc.desc, tc.results[i].ID, r.ID))
+					assert.Equal(t, tc.results[i].User.ID, r.User.ID, fmt.Sprintf("%s: expected user %s got %s", tc.desc, tc.results[i].User.ID, r.User.ID))
+					assert.Equal(t, tc.results[i].User.Role, r.User.Role, fmt.Sprintf("%s: expected role %s got %s", tc.desc, tc.results[i].User.Role, r.User.Role))
+					assert.Equal(t, tc.results[i].Error, r.Error, fmt.Sprintf("%s: expected result error %s got %s", tc.desc, tc.results[i].Error, r.Error))
+				}
+			}
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
+func TestPreviewEmail(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	preview := users.EmailPreview{
+		Subject:     "Welcome",
+		ContentType: "text/plain",
+		Body:        "Dear John Doe,\n\nWelcome!",
+	}
+	templateErr := errors.Wrap(email.ErrParseTemplate, errors.New(`template: welcome.tmpl:1: unexpected "}" in operand`))
+
+	cases := []struct {
+		desc        string
+		template    string
+		data        string
+		token       string
+		contentType string
+		authnRes    mgauthn.Session
+		authnErr    error
+		emailData   users.EmailData
+		svcRes      users.EmailPreview
+		svcErr      error
+		status      int
+		err         error
+	}{
+		{
+			desc:     "preview e-mail with sample data",
+			template: users.WelcomeTemplate,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			svcRes:   preview,
+			status:   http.StatusOK,
+		},
+		{
+			desc:        "preview e-mail with supplied data",
+			template:    users.WelcomeTemplate,
+			data:        `{"user": "Jane", "footer": "Magistrala"}`,
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			emailData:   users.EmailData{User: "Jane", Footer: "Magistrala"},
+			svcRes:      preview,
+			status:      http.StatusOK,
+		},
+		{
+			desc:     "preview e-mail with template error",
+			template: users.WelcomeTemplate,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			svcErr:   templateErr,
+			status:   http.StatusUnprocessableEntity,
+			err:      email.ErrParseTemplate,
+		},
+		{
+			desc:     "preview unknown e-mail template",
+			template: "unknown",
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			svcErr:   svcerr.ErrNotFound,
+			status:   http.StatusNotFound,
+			err:      svcerr.ErrNotFound,
+		},
+		{
+			desc:     "preview e-mail as non admin",
+			template: users.WelcomeTemplate,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID},
+			svcErr:   svcerr.ErrAuthorization,
+			status:   http.StatusForbidden,
+			err:      svcerr.ErrAuthorization,
+		},
+		{
+			desc:     "preview e-mail with invalid token",
+			template: users.WelcomeTemplate,
+			token:    inValidToken,
+			authnErr: svcerr.ErrAuthentication,
+			status:   http.StatusUnauthorized,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:        "preview e-mail with invalid content type",
+			template:    users.WelcomeTemplate,
+			data:        `{"user": "Jane"}`,
+			token:       validToken,
+			contentType: "application/xml",
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "preview e-mail with malformed data",
+			template:    users.WelcomeTemplate,
+			data:        `{"user": 1}`,
+			token:       validToken,
+			contentType: contentType,
+			authnRes:    mgauthn.Session{UserID: validID, DomainID: domainID},
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/emails/%s/preview", us.URL, tc.template),
+				contentType: tc.contentType,
+				token:       tc.token,
+				body:        strings.NewReader(tc.data),
+			}
+
+			authnCall := authn.On("Authenticate", mock.Anything, tc.token).Return(tc.authnRes, tc.authnErr)
+			svcCall := svc.On("PreviewEmail", mock.Anything, tc.authnRes, tc.template, tc.emailData).Return(tc.svcRes, tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody struct {
+				respBody
+				users.EmailPreview
+			}
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			switch {
+			case tc.err == nil:
+				assert.Equal(t, tc.svcRes, resBody.EmailPreview, fmt.Sprintf("%s: expected preview %v got %v", tc.desc, tc.svcRes, resBody.EmailPreview))
+			case tc.svcErr == templateErr:
+				assert.Contains(t, resBody.Err, "unexpected", fmt.Sprintf("%s: expected template error to be reported", tc.desc))
+			}
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+func TestConfirmIdentity(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	confirmed := client
+	confirmed.Credentials.Identity = "updated@example.com"
+
+	cases := []struct {
+		desc     string
+		token    string
+		response mgclients.Client
+		status   int
+		err      error
+	}{
+		{
+			desc:     "confirm identity successfully",
+			token:    validToken,
+			response: confirmed,
+			status:   http.StatusOK,
+			err:      nil,
+		},
+		{
+			desc:   "confirm identity with empty token",
+			token:  "",
+			status: http.StatusUnauthorized,
+			err:    apiutil.ErrBearerToken,
+		},
+		{
+			desc:   "confirm identity with invalid token",
+			token:  inValidToken,
+			status: http.StatusUnauthorized,
+			err:    svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: us.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/users/identity/confirm?token=%s", us.URL, tc.token),
+			}
+
+			svcCall := svc.On("ConfirmIdentity", mock.Anything, tc.token).Return(tc.response, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody respBody
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc        string
+		data        string
+		identity    string
+		contentType string
+		svcErr      error
+		status      int
+		err         error
+	}{
+		{
+			desc:        "resend verification successfully",
+			data:        fmt.Sprintf(`{"identity": "%s"}`, client.Credentials.Identity),
+			identity:    client.Credentials.Identity,
+			contentType: contentType,
+			status:      http.StatusAccepted,
+			err:         nil,
+		},
+		{
+			desc:        "resend verification with empty identity",
+			data:        `{"identity": ""}`,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "resend verification with malformed data",
+			data:        `{"identity": `,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "resend verification with invalid content type",
+			data:        fmt.Sprintf(`{"identity": "%s"}`, client.Credentials.Identity),
+			identity:    client.Credentials.Identity,
+			contentType: "application/xml",
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrUnsupportedContentType,
+		},
+		{
+			desc:        "resend verification too often",
+			data:        fmt.Sprintf(`{"identity": "%s"}`, client.Credentials.Identity),
+			identity:    client.Credentials.Identity,
+			contentType: contentType,
+			svcErr:      apiutil.ErrTooManyRequests,
+			status:      http.StatusTooManyRequests,
+			err:         apiutil.ErrTooManyRequests,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/verify/resend", us.URL),
+				contentType: tc.contentType,
+				body:        strings.NewReader(tc.data),
+			}
+
+			svcCall := svc.On("ResendVerification", mock.Anything, tc.identity).Return(tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody respBody
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+		})
+	}
+}
+
+func TestDeletion(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	deleted := client
+	deleted.Status = mgclients.DeletedStatus
+
+	cases := []struct {
+		desc     string
+		method   string
+		endpoint string
+		svcCall  string
+		token    string
+		response mgclients.Client
+		status   int
+		err      error
+	}{
+		{
+			desc:     "confirm deletion successfully",
+			method:   http.MethodGet,
+			endpoint: "confirm",
+			svcCall:  "ConfirmDeletion",
+			token:    validToken,
+			response: deleted,
+			status:   http.StatusOK,
+			err:      nil,
+		},
+		{
+			desc:     "confirm deletion with empty token",
+			method:   http.MethodGet,
+			endpoint: "confirm",
+			svcCall:  "ConfirmDeletion",
+			token:    "",
+			status:   http.StatusUnauthorized,
+			err:      apiutil.ErrBearerToken,
+		},
+		{
+			desc:     "confirm confirmed deletion",
+			method:   http.MethodGet,
+			endpoint: "confirm",
+			svcCall:  "ConfirmDeletion",
+			token:    validToken,
+			status:   http.StatusConflict,
+			err:      svcerr.ErrConflict,
+		},
+		{
+			desc:     "cancel deletion successfully",
+			method:   http.MethodPost,
+			endpoint: "cancel",
+			svcCall:  "CancelDeletion",
+			token:    validToken,
+			response: client,
+			status:   http.StatusOK,
+			err:      nil,
+		},
+		{
+			desc:     "cancel deletion with expired token",
+			method:   http.MethodPost,
+			endpoint: "cancel",
+			svcCall:  "CancelDeletion",
+			token:    inValidToken,
+			status:   http.StatusUnauthorized,
+			err:      svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: us.Client(),
+				method: tc.method,
+				url:    fmt.Sprintf("%s/users/deletion/%s?token=%s", us.URL, tc.endpoint, tc.token),
+			}
+
+			svcCall := svc.On(tc.svcCall, mock.Anything, tc.token).Return(tc.response, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody respBody
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+		})
+	}
+}
+
+func TestUpdateClientSecret(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc        string
+		data        string
+		client      mgclients.Client
+		contentType string
+		token       string
+		status      int
+		authnRes    mgauthn.Session
+		authnErr    error
+		err         error
+	}{
+		{
+			desc: "update user secret with valid token",
+			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
+			client: mgclients.Client{
+				ID: client.ID,
+				Credentials: mgclients.Credentials{
+					Identity: "clientname",
+					Secret:   "strongersecret",
+				},
+			},
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusOK,
+			err:         nil,
+		},
+		{
+			desc: "update user secret with empty token",
+			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
+			client: mgclients.Client{
+				ID: client.ID,
+				Credentials: mgclients.Credentials{
+					Identity: "clientname",
+					Secret:   "strongersecret",
+				},
+			},
+			contentType: contentType,
+			token:       "",
+			status:      http.StatusUnauthorized,
+			authnErr:    svcerr.ErrAuthentication,
+			err:         apiutil.ErrBearerToken,
+		},
+		{
+			desc: "update user secret with invalid token",
+			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
+			client: mgclients.Client{
+				ID: client.ID,
+				Credentials: mgclients.Credentials{
+					Identity: "clientname",
+					Secret:   "strongersecret",
+				},
+			},
+			contentType: contentType,
+			token:       inValid,
+			status:      http.StatusUnauthorized,
+			authnErr:    svcerr.ErrAuthentication,
+			err:         svcerr.ErrAuthentication,
+		},
+
+		{
+			desc: "update user secret with empty secret",
+			data: `{"old_secret": "", "new_secret": "strongersecret"}`,
+			client: mgclients.Client{
+				ID: client.ID,
+				Credentials: mgclients.Credentials{
+					Identity: "clientname",
+					Secret:   "",
+				},
+			},
+			contentType: contentType,
+			token:       validToken,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrMissingPass,
+		},
+		{
+			desc: "update user secret with invalid contentype",
+			data: `{"old_secret": "strongersecret", "new_secret": "strongersecret"}`,
+			client: mgclients.Client{
 				ID: client.ID,
 				Credentials: mgclients.Credentials{
 					Identity: "clientname",
@@ -1818,7 +3030,7 @@ func TestIssueToken(t *testing.T) {
 				body:        strings.NewReader(tc.data),
 			}
 
-			svcCall := svc.On("IssueToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, tc.err)
+			svcCall := svc.On("IssueToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, tc.err)
 			res, err := req.make()
 			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
 			if tc.err != nil {
@@ -1836,6 +3048,108 @@ func TestIssueToken(t *testing.T) {
 	}
 }
 
+func TestEnrollMFA(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc   string
+		data   string
+		status int
+		err    error
+	}{
+		{
+			desc:   "enroll MFA with valid credentials",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": "%s"}`, "valid", secret),
+			status: http.StatusCreated,
+		},
+		{
+			desc:   "enroll MFA with empty secret",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": ""}`, "valid"),
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrValidation,
+		},
+		{
+			desc:   "enroll MFA with invalid credentials",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": "%s"}`, "invalid", secret),
+			status: http.StatusUnauthorized,
+			err:    svcerr.ErrLogin,
+		},
+		{
+			desc:   "enroll MFA when already enrolled",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": "%s"}`, "valid", secret),
+			status: http.StatusConflict,
+			err:    svcerr.ErrConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/mfa/enroll", us.URL),
+				contentType: contentType,
+				body:        strings.NewReader(tc.data),
+			}
+
+			svcCall := svc.On("EnrollMFA", mock.Anything, mock.Anything, mock.Anything).Return(users.MFASecret{Secret: "secret", URI: "otpauth://totp/Magistrala:valid?secret=secret"}, tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+		})
+	}
+}
+
+func TestConfirmMFA(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc   string
+		data   string
+		status int
+		err    error
+	}{
+		{
+			desc:   "confirm MFA with valid code",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": "%s", "mfa_code": "123456"}`, "valid", secret),
+			status: http.StatusNoContent,
+		},
+		{
+			desc:   "confirm MFA without code",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": "%s"}`, "valid", secret),
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrValidation,
+		},
+		{
+			desc:   "confirm MFA with invalid code",
+			data:   fmt.Sprintf(`{"identity": "%s", "secret": "%s", "mfa_code": "000000"}`, "valid", secret),
+			status: http.StatusUnauthorized,
+			err:    svcerr.ErrMFACode,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/mfa/confirm", us.URL),
+				contentType: contentType,
+				body:        strings.NewReader(tc.data),
+			}
+
+			svcCall := svc.On("ConfirmMFA", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.err)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+		})
+	}
+}
+
 func TestRefreshToken(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
@@ -1936,6 +3250,375 @@ func TestRefreshToken(t *testing.T) {
 	}
 }
 
+func TestDeviceCode(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	da := users.DeviceAuthorization{
+		DeviceCode:              validToken,
+		UserCode:                "BCDF-GHJK",
+		VerificationURL:         "http://localhost/device",
+		VerificationURLComplete: "http://localhost/device?user_code=BCDF-GHJK",
+		ExpiresIn:               10 * time.Minute,
+		Interval:                5 * time.Second,
+	}
+
+	cases := []struct {
+		desc        string
+		data        string
+		contentType string
+		audience    string
+		svcRes      users.DeviceAuthorization
+		svcErr      error
+		status      int
+		err         error
+	}{
+		{
+			desc:        "request device code successfully",
+			data:        `{"audience": "cli"}`,
+			contentType: contentType,
+			audience:    "cli",
+			svcRes:      da,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "request device code with device flow disabled",
+			data:        `{}`,
+			contentType: contentType,
+			svcErr:      svcerr.ErrAuthorization,
+			status:      http.StatusForbidden,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:        "request device code with malformed data",
+			data:        `{"audience": cli}`,
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+			err:         apiutil.ErrValidation,
+		},
+		{
+			desc:        "request device code with invalid content type",
+			data:        `{}`,
+			contentType: "application/xml",
+			status:      http.StatusUnsupportedMediaType,
+			err:         apiutil.ErrValidation,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/oauth/device/code", us.URL),
+				contentType: tc.contentType,
+				body:        strings.NewReader(tc.data),
+			}
+
+			svcCall := svc.On("RequestDeviceCode", mock.Anything, tc.audience).Return(tc.svcRes, tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var resBody struct {
+				respBody
+				DeviceCode              string `json:"device_code"`
+				UserCode                string `json:"user_code"`
+				VerificationURIComplete string `json:"verification_uri_complete"`
+				ExpiresIn               int64  `json:"expires_in"`
+				Interval                int64  `json:"interval"`
+			}
+			err = json.NewDecoder(res.Body).Decode(&resBody)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+			if resBody.Err != "" || resBody.Message != "" {
+				err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.err == nil {
+				assert.Equal(t, da.DeviceCode, resBody.DeviceCode, fmt.Sprintf("%s: expected device code %s got %s", tc.desc, da.DeviceCode, resBody.DeviceCode))
+				assert.Equal(t, da.UserCode, resBody.UserCode, fmt.Sprintf("%s: expected user code %s got %s", tc.desc, da.UserCode, resBody.UserCode))
+				assert.Equal(t, da.VerificationURLComplete, resBody.VerificationURIComplete, fmt.Sprintf("%s: expected complete verification URI", tc.desc))
+				assert.Equal(t, int64(600), resBody.ExpiresIn, fmt.Sprintf("%s: expected expiration in seconds got %d", tc.desc, resBody.ExpiresIn))
+				assert.Equal(t, int64(5), resBody.Interval, fmt.Sprintf("%s: expected interval in seconds got %d", tc.desc, resBody.Interval))
+			}
+			svcCall.Unset()
+		})
+	}
+}
+
+func TestAuthorizeDevice(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc     string
+		data     string
+		token    string
+		authnRes mgauthn.Session
+		authnErr error
+		userCode string
+		mfaCode  string
+		approve  bool
+		svcErr   error
+		status   int
+		err      error
+	}{
+		{
+			desc:     "approve device successfully",
+			data:     `{"user_code": "BCDF-GHJK", "approve": true}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			userCode: "BCDF-GHJK",
+			approve:  true,
+			status:   http.StatusNoContent,
+		},
+		{
+			desc:     "approve device with MFA code successfully",
+			data:     `{"user_code": "BCDF-GHJK", "mfa_code": "123456", "approve": true}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			userCode: "BCDF-GHJK",
+			mfaCode:  "123456",
+			approve:  true,
+			status:   http.StatusNoContent,
+		},
+		{
+			desc:     "approve device with invalid MFA code",
+			data:     `{"user_code": "BCDF-GHJK", "mfa_code": "000000", "approve": true}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			userCode: "BCDF-GHJK",
+			mfaCode:  "000000",
+			approve:  true,
+			svcErr:   svcerr.ErrAuthentication,
+			status:   http.StatusUnauthorized,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:     "deny device successfully",
+			data:     `{"user_code": "BCDF-GHJK"}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			userCode: "BCDF-GHJK",
+			status:   http.StatusNoContent,
+		},
+		{
+			desc:     "authorize device with invalid token",
+			data:     `{"user_code": "BCDF-GHJK", "approve": true}`,
+			token:    inValidToken,
+			authnErr: svcerr.ErrAuthentication,
+			status:   http.StatusUnauthorized,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:     "authorize device with empty user code",
+			data:     `{"approve": true}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			status:   http.StatusBadRequest,
+			err:      apiutil.ErrMissingCode,
+		},
+		{
+			desc:     "authorize device with expired user code",
+			data:     `{"user_code": "BCDF-GHJK", "approve": true}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			userCode: "BCDF-GHJK",
+			approve:  true,
+			svcErr:   svcerr.ErrMalformedEntity,
+			status:   http.StatusBadRequest,
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "authorize already authorized device",
+			data:     `{"user_code": "BCDF-GHJK", "approve": true}`,
+			token:    validToken,
+			authnRes: mgauthn.Session{UserID: validID},
+			userCode: "BCDF-GHJK",
+			approve:  true,
+			svcErr:   svcerr.ErrConflict,
+			status:   http.StatusConflict,
+			err:      svcerr.ErrConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client:      us.Client(),
+				method:      http.MethodPost,
+				url:         fmt.Sprintf("%s/users/oauth/device/verify", us.URL),
+				contentType: contentType,
+				token:       tc.token,
+				body:        strings.NewReader(tc.data),
+			}
+
+			authnCall := authn.On("Authenticate", mock.Anything, tc.token).Return(tc.authnRes, tc.authnErr)
+			svcCall := svc.On("AuthorizeDevice", mock.Anything, tc.authnRes, tc.userCode, tc.mfaCode, tc.approve).Return(tc.svcErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			if tc.err != nil {
+				var resBody respBody
+				err = json.NewDecoder(res.Body).Decode(&resBody)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error while decoding response body: %s", tc.desc, err))
+				if resBody.Err != "" || resBody.Message != "" {
+					err = errors.Wrap(errors.New(resBody.Err), errors.New(resBody.Message))
+				}
+				assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			}
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
+// deviceTokenRes is the union of the token response and the OAuth2 error
+// response of the device token polling.
+type deviceTokenRes struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Message          string `json:"message"`
+}
+
+func pollDeviceToken(t *testing.T, us *httptest.Server, deviceCode string) (int, deviceTokenRes) {
+	req := testRequest{
+		client:      us.Client(),
+		method:      http.MethodPost,
+		url:         fmt.Sprintf("%s/users/tokens/device", us.URL),
+		contentType: contentType,
+		body:        strings.NewReader(fmt.Sprintf(`{"device_code": "%s"}`, deviceCode)),
+	}
+	res, err := req.make()
+	require.Nil(t, err, fmt.Sprintf("poll device token: unexpected error %s", err))
+	defer res.Body.Close()
+
+	var body deviceTokenRes
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.Nil(t, err, fmt.Sprintf("poll device token: unexpected error while decoding response body: %s", err))
+
+	return res.StatusCode, body
+}
+
+func TestDeviceFlowHandshake(t *testing.T) {
+	us, svc, _, authn := newUsersServer()
+	defer us.Close()
+
+	da := users.DeviceAuthorization{DeviceCode: validToken, UserCode: "BCDF-GHJK", Interval: 5 * time.Second}
+	svc.On("RequestDeviceCode", mock.Anything, "").Return(da, nil)
+	authn.On("Authenticate", mock.Anything, validToken).Return(mgauthn.Session{UserID: validID}, nil)
+	svc.On("AuthorizeDevice", mock.Anything, mgauthn.Session{UserID: validID}, da.UserCode, "", true).Return(nil)
+
+	// The device polls until the user authorizes it, and polls once too fast.
+	svc.On("DeviceToken", mock.Anything, da.DeviceCode).Return(&magistrala.Token{}, users.ErrAuthorizationPending).Once()
+	svc.On("DeviceToken", mock.Anything, da.DeviceCode).Return(&magistrala.Token{}, users.ErrSlowDown).Once()
+	svc.On("DeviceToken", mock.Anything, da.DeviceCode).Return(&magistrala.Token{}, users.ErrAuthorizationPending).Once()
+	svc.On("DeviceToken", mock.Anything, da.DeviceCode).Return(&magistrala.Token{AccessToken: validToken, RefreshToken: &validToken}, nil).Once()
+	svc.On("DeviceToken", mock.Anything, da.DeviceCode).Return(&magistrala.Token{}, users.ErrInvalidDeviceCode).Once()
+
+	req := testRequest{
+		client:      us.Client(),
+		method:      http.MethodPost,
+		url:         fmt.Sprintf("%s/users/oauth/device/code", us.URL),
+		contentType: contentType,
+		body:        strings.NewReader(`{}`),
+	}
+	res, err := req.make()
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("request device code: expected status code %d got %d", http.StatusOK, res.StatusCode))
+
+	status, body := pollDeviceToken(t, us, da.DeviceCode)
+	assert.Equal(t, http.StatusBadRequest, status, fmt.Sprintf("poll before authorization: expected status code %d got %d", http.StatusBadRequest, status))
+	assert.Equal(t, "authorization_pending", body.Error, fmt.Sprintf("poll before authorization: expected authorization_pending got %s", body.Error))
+
+	status, body = pollDeviceToken(t, us, da.DeviceCode)
+	assert.Equal(t, http.StatusBadRequest, status, fmt.Sprintf("poll too fast: expected status code %d got %d", http.StatusBadRequest, status))
+	assert.Equal(t, "slow_down", body.Error, fmt.Sprintf("poll too fast: expected slow_down got %s", body.Error))
+
+	status, body = pollDeviceToken(t, us, da.DeviceCode)
+	assert.Equal(t, "authorization_pending", body.Error, fmt.Sprintf("poll after interval: expected authorization_pending got %s", body.Error))
+	assert.Equal(t, users.ErrAuthorizationPending.Error(), body.ErrorDescription, "poll after interval: expected error description")
+
+	req = testRequest{
+		client:      us.Client(),
+		method:      http.MethodPost,
+		url:         fmt.Sprintf("%s/users/oauth/device/verify", us.URL),
+		contentType: contentType,
+		token:       validToken,
+		body:        strings.NewReader(fmt.Sprintf(`{"user_code": "%s", "approve": true}`, da.UserCode)),
+	}
+	res, err = req.make()
+	require.Nil(t, err, fmt.Sprintf("authorize device: unexpected error %s", err))
+	assert.Equal(t, http.StatusNoContent, res.StatusCode, fmt.Sprintf("authorize device: expected status code %d got %d", http.StatusNoContent, res.StatusCode))
+
+	status, body = pollDeviceToken(t, us, da.DeviceCode)
+	assert.Equal(t, http.StatusCreated, status, fmt.Sprintf("poll after authorization: expected status code %d got %d", http.StatusCreated, status))
+	assert.Equal(t, validToken, body.AccessToken, "poll after authorization: expected access token")
+	assert.Empty(t, body.Error, fmt.Sprintf("poll after authorization: unexpected error %s", body.Error))
+
+	status, body = pollDeviceToken(t, us, da.DeviceCode)
+	assert.Equal(t, http.StatusBadRequest, status, fmt.Sprintf("poll with used device code: expected status code %d got %d", http.StatusBadRequest, status))
+	assert.Equal(t, "invalid_grant", body.Error, fmt.Sprintf("poll with used device code: expected invalid_grant got %s", body.Error))
+	svc.AssertExpectations(t)
+}
+
+func TestDeviceToken(t *testing.T) {
+	us, svc, _, _ := newUsersServer()
+	defer us.Close()
+
+	cases := []struct {
+		desc       string
+		deviceCode string
+		svcErr     error
+		status     int
+		errCode    string
+		err        error
+	}{
+		{
+			desc:       "poll with expired device code",
+			deviceCode: validToken,
+			svcErr:     users.ErrDeviceCodeExpired,
+			status:     http.StatusBadRequest,
+			errCode:    "expired_token",
+		},
+		{
+			desc:       "poll for denied device",
+			deviceCode: validToken,
+			svcErr:     users.ErrDeviceAccessDenied,
+			status:     http.StatusBadRequest,
+			errCode:    "access_denied",
+		},
+		{
+			desc:       "poll with device flow disabled",
+			deviceCode: validToken,
+			svcErr:     svcerr.ErrAuthorization,
+			status:     http.StatusForbidden,
+			err:        svcerr.ErrAuthorization,
+		},
+		{
+			desc:   "poll with empty device code",
+			status: http.StatusBadRequest,
+			err:    apiutil.ErrMissingCode,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svcCall := svc.On("DeviceToken", mock.Anything, tc.deviceCode).Return(&magistrala.Token{}, tc.svcErr)
+			status, body := pollDeviceToken(t, us, tc.deviceCode)
+			assert.Equal(t, tc.status, status, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, status))
+			if tc.errCode != "" {
+				assert.Equal(t, tc.errCode, body.Error, fmt.Sprintf("%s: expected error code %s got %s", tc.desc, tc.errCode, body.Error))
+			}
+			if tc.err != nil {
+				err := errors.Wrap(errors.New(body.Error), errors.New(body.Message))
+				assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			}
+			svcCall.Unset()
+		})
+	}
+}
+
 func TestEnableClient(t *testing.T) {
 	us, svc, _, authn := newUsersServer()
 	defer us.Close()
@@ -3939,14 +5622,150 @@ func TestUnassignGroups(t *testing.T) {
 	}
 }
 
+func TestListUserGroups(t *testing.T) {
+	us, _, gsvc, authn := newUsersServer()
+	defer us.Close()
+
+	userID := testsutil.GenerateUUID(t)
+	session := mgauthn.Session{UserID: userID, DomainID: domainID, DomainUserID: domainID + "_" + userID}
+	memberships := []groups.Membership{
+		{Group: groups.Group{ID: testsutil.GenerateUUID(t), Name: "group1", Domain: domainID}, Relation: "administrator"},
+		{Group: groups.Group{ID: testsutil.GenerateUUID(t), Name: "group2", Domain: domainID}, Relation: "member"},
+		{Group: groups.Group{ID: testsutil.GenerateUUID(t), Name: "group3", Domain: domainID}, Relation: "guest"},
+	}
+
+	cases := []struct {
+		desc     string
+		token    string
+		userID   string
+		query    string
+		authnRes mgauthn.Session
+		authnErr error
+		pageMeta groups.PageMeta
+		listRes  groups.MembershipsPage
+		listErr  error
+		status   int
+	}{
+		{
+			desc:     "list groups of the user in several groups",
+			token:    validToken,
+			userID:   userID,
+			authnRes: session,
+			pageMeta: groups.PageMeta{Offset: 0, Limit: 10},
+			listRes: groups.MembershipsPage{
+				PageMeta:    groups.PageMeta{Total: uint64(len(memberships)), Offset: 0, Limit: 10},
+				Memberships: memberships,
+			},
+			status: http.StatusOK,
+		},
+		{
+			desc:     "list groups of the user with offset and limit",
+			token:    validToken,
+			userID:   userID,
+			query:    "?offset=1&limit=1",
+			authnRes: session,
+			pageMeta: groups.PageMeta{Offset: 1, Limit: 1},
+			listRes: groups.MembershipsPage{
+				PageMeta:    groups.PageMeta{Total: uint64(len(memberships)), Offset: 1, Limit: 1},
+				Memberships: memberships[1:2],
+			},
+			status: http.StatusOK,
+		},
+		{
+			desc:     "list groups of another user as admin",
+			token:    validToken,
+			userID:   userID,
+			authnRes: mgauthn.Session{UserID: validID, DomainID: domainID, DomainUserID: domainID + "_" + validID},
+			pageMeta: groups.PageMeta{Offset: 0, Limit: 10},
+			listRes: groups.MembershipsPage{
+				PageMeta:    groups.PageMeta{Total: uint64(len(memberships)), Offset: 0, Limit: 10},
+				Memberships: memberships,
+			},
+			status: http.StatusOK,
+		},
+		{
+			desc:     "list groups of another user without authorization",
+			token:    validToken,
+			userID:   validID,
+			authnRes: session,
+			pageMeta: groups.PageMeta{Offset: 0, Limit: 10},
+			listErr:  svcerr.ErrAuthorization,
+			status:   http.StatusForbidden,
+		},
+		{
+			desc:     "list groups of the user with invalid token",
+			token:    inValidToken,
+			userID:   userID,
+			authnErr: svcerr.ErrAuthentication,
+			status:   http.StatusUnauthorized,
+		},
+		{
+			desc:   "list groups of the user with empty token",
+			token:  "",
+			userID: userID,
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:     "list groups of the user with invalid limit",
+			token:    validToken,
+			userID:   userID,
+			query:    "?limit=1000",
+			authnRes: session,
+			status:   http.StatusBadRequest,
+		},
+		{
+			desc:     "list groups of the user with malformed offset",
+			token:    validToken,
+			userID:   userID,
+			query:    "?offset=invalid",
+			authnRes: session,
+			status:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := testRequest{
+				client: us.Client(),
+				method: http.MethodGet,
+				url:    fmt.Sprintf("%s/users/%s/groups%s", us.URL, tc.userID, tc.query),
+				token:  tc.token,
+			}
+			authnCall := authn.On("Authenticate", mock.Anything, tc.token).Return(tc.authnRes, tc.authnErr)
+			svcCall := gsvc.On("ListUserGroups", mock.Anything, tc.authnRes, tc.userID, tc.pageMeta).Return(tc.listRes, tc.listErr)
+			res, err := req.make()
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+			if tc.status == http.StatusOK {
+				var body struct {
+					Total  uint64              `json:"total"`
+					Groups []groups.Membership `json:"groups"`
+				}
+				err = json.NewDecoder(res.Body).Decode(&body)
+				assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+				assert.Equal(t, tc.listRes.Total, body.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.listRes.Total, body.Total))
+				if assert.Len(t, body.Groups, len(tc.listRes.Memberships), tc.desc) {
+					for i, m := range tc.listRes.Memberships {
+						assert.Equal(t, m.ID, body.Groups[i].ID, fmt.Sprintf("%s: expected group %s got %s", tc.desc, m.ID, body.Groups[i].ID))
+						assert.Equal(t, m.Relation, body.Groups[i].Relation, fmt.Sprintf("%s: expected relation %s got %s", tc.desc, m.Relation, body.Groups[i].Relation))
+					}
+				}
+			}
+			svcCall.Unset()
+			authnCall.Unset()
+		})
+	}
+}
+
 type respBody struct {
-	Err     string           `json:"error"`
-	Message string           `json:"message"`
-	Total   int              `json:"total"`
-	ID      string           `json:"id"`
-	Tags    []string         `json:"tags"`
-	Role    mgclients.Role   `json:"role"`
-	Status  mgclients.Status `json:"status"`
+	Err             string           `json:"error"`
+	Message         string           `json:"message"`
+	Total           int              `json:"total"`
+	TotalUnfiltered *uint64          `json:"total_unfiltered"`
+	ID              string           `json:"id"`
+	Tags            []string         `json:"tags"`
+	Role            mgclients.Role   `json:"role"`
+	Status          mgclients.Status `json:"status"`
 }
 
 type groupReqBody struct {
@@ -12,6 +12,8 @@ import (
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/passwords"
 	"github.com/absmach/magistrala/users"
 	"github.com/go-kit/kit/endpoint"
 )
@@ -92,16 +94,18 @@ func listClientsEndpoint(svc users.Service) endpoint.Endpoint {
 		}
 
 		pm := mgclients.Page{
-			Status:   req.status,
-			Offset:   req.offset,
-			Limit:    req.limit,
-			Name:     req.name,
-			Tag:      req.tag,
-			Metadata: req.metadata,
-			Identity: req.identity,
-			Order:    req.order,
-			Dir:      req.dir,
-			Id:       req.id,
+			Status:          req.status,
+			Offset:          req.offset,
+			Limit:           req.limit,
+			Name:            req.name,
+			Tag:             req.tag,
+			Metadata:        req.metadata,
+			Identity:        req.identity,
+			Order:           req.order,
+			Dir:             req.dir,
+			Id:              req.id,
+			OAuthProvider:   req.provider,
+			CountUnfiltered: req.unfiltered,
 		}
 
 		page, err := svc.ListClients(ctx, session, pm)
@@ -111,9 +115,10 @@ func listClientsEndpoint(svc users.Service) endpoint.Endpoint {
 
 		res := clientsPageRes{
 			pageRes: pageRes{
-				Total:  page.Total,
-				Offset: page.Offset,
-				Limit:  page.Limit,
+				Total:           page.Total,
+				TotalUnfiltered: page.TotalUnfiltered,
+				Offset:          page.Offset,
+				Limit:           page.Limit,
 			},
 			Clients: []viewClientRes{},
 		}
@@ -125,6 +130,43 @@ func listClientsEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+func listChangesEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listChangesReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		page, err := svc.ListChanges(ctx, session, req.since, req.cursor, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := changesPageRes{
+			Changes: []changeRes{},
+			Cursor:  page.Cursor,
+		}
+		for _, c := range page.Changes {
+			cr := changeRes{
+				Operation: c.Operation,
+				ChangedAt: c.ChangedAt,
+				ID:        c.ID,
+			}
+			if c.Client != nil {
+				cr.User = &viewClientRes{Client: *c.Client}
+			}
+			res.Changes = append(res.Changes, cr)
+		}
+
+		return res, nil
+	}
+}
+
 func searchClientsEndpoint(svc users.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(searchClientsReq)
@@ -161,6 +203,37 @@ func searchClientsEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+func listUserGroupsEndpoint(svc groups.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listUserGroupsReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		page, err := svc.ListUserGroups(ctx, session, req.id, groups.PageMeta{
+			Offset: req.offset,
+			Limit:  req.limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return userGroupsPageRes{
+			pageRes: pageRes{
+				Total:  page.Total,
+				Offset: page.Offset,
+				Limit:  page.Limit,
+			},
+			Groups: page.Memberships,
+		}, nil
+	}
+}
+
 func listMembersByGroupEndpoint(svc users.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listMembersByObjectReq)
@@ -206,6 +279,30 @@ func listMembersByChannelEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+// listObjectPermissionsEndpoint lists users with access to the object of the given kind and their permissions.
+// Channels use the "groups" kind, since spiceDB schema uses the same 'group' type for channels and groups.
+func listObjectPermissionsEndpoint(svc users.Service, objectKind string) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listMembersByObjectReq)
+		req.objectKind = objectKind
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		page, err := svc.ListObjectPermissions(ctx, session, req.objectKind, req.objectID, req.Page)
+		if err != nil {
+			return nil, err
+		}
+
+		return buildClientsResponse(page), nil
+	}
+}
+
 func listMembersByThingEndpoint(svc users.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listMembersByObjectReq)
@@ -324,6 +421,147 @@ func updateClientIdentityEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+// Identity confirmation endpoint.
+// When identity confirmation is enabled, the link sent to the new address
+// points to this endpoint. The token authorizes the request, so it doesn't
+// require the user to be logged in.
+func confirmIdentityEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(confirmIdentityReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		client, err := svc.ConfirmIdentity(ctx, req.token)
+		if err != nil {
+			return nil, err
+		}
+
+		return updateClientRes{Client: client}, nil
+	}
+}
+
+// Phone verification endpoint.
+// The code sent to the phone authorizes the request, so it doesn't require
+// the user to be logged in.
+func verifyPhoneEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(verifyPhoneReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		client, err := svc.VerifyPhone(ctx, req.Identity, req.Code)
+		if err != nil {
+			return nil, err
+		}
+
+		return updateClientRes{Client: client}, nil
+	}
+}
+
+func sendPhoneCodeEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(sendPhoneCodeReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		if err := svc.SendPhoneCode(ctx, req.Identity); err != nil {
+			return nil, err
+		}
+
+		return sendPhoneCodeRes{Msg: CodeSent}, nil
+	}
+}
+
+func resendVerificationEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(resendVerificationReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		if err := svc.ResendVerification(ctx, req.Identity); err != nil {
+			return nil, err
+		}
+
+		return resendVerificationRes{Msg: VerificationSent}, nil
+	}
+}
+
+func requestDeletionEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		if err := svc.RequestDeletion(ctx, session); err != nil {
+			return nil, err
+		}
+
+		return requestDeletionRes{Msg: DeletionMailSent}, nil
+	}
+}
+
+// Account deletion confirmation endpoint.
+// The link in the deletion confirmation e-mail points to this endpoint. The
+// token authorizes the request, so it doesn't require the user to be logged
+// in, which is not possible once the deletion is confirmed anyway.
+func confirmDeletionEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deletionTokenReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		client, err := svc.ConfirmDeletion(ctx, req.token)
+		if err != nil {
+			return nil, err
+		}
+
+		return changeClientStatusClientRes{Client: client}, nil
+	}
+}
+
+func cancelDeletionEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deletionTokenReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		client, err := svc.CancelDeletion(ctx, req.token)
+		if err != nil {
+			return nil, err
+		}
+
+		return changeClientStatusClientRes{Client: client}, nil
+	}
+}
+
+func previewEmailEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(previewEmailReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		preview, err := svc.PreviewEmail(ctx, session, req.name, req.EmailData)
+		if err != nil {
+			return nil, err
+		}
+
+		return previewEmailRes{EmailPreview: preview}, nil
+	}
+}
+
 // Password reset request endpoint.
 // When successful password reset link is generated.
 // Link is generated using MG_TOKEN_RESET_ENDPOINT env.
@@ -349,6 +587,33 @@ func passwordResetRequestEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+// The password is only evaluated and must never be logged or stored.
+func passwordStrengthEndpoint(pe passwords.Evaluator) endpoint.Endpoint {
+	return func(_ context.Context, request interface{}) (interface{}, error) {
+		req := request.(passwStrengthReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		return passwStrengthRes{Strength: pe.Evaluate(req.Password)}, nil
+	}
+}
+
+// rateLimit rejects requests once the limiter of the client IP address is
+// exhausted, so a single client can't throttle the others. A nil limiter
+// doesn't limit requests.
+func rateLimit(limiter *api.KeyedLimiter) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if !limiter.Allow(users.ClientIP(ctx)) {
+				return nil, apiutil.ErrTooManyRequests
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
 // This is endpoint that actually sets new password in password reset flow.
 // When user clicks on a link in email finally ends on this endpoint as explained in
 // the comment above.
@@ -417,6 +682,45 @@ func updateClientRoleEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+func updateClientsRoleEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateClientsRoleReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		results, err := svc.UpdateClientsRole(ctx, session, req.UserIDs, req.role)
+		if err != nil {
+			return nil, err
+		}
+
+		res := updateClientsRoleRes{
+			Role:    req.role.String(),
+			Results: []roleUpdateRes{},
+		}
+		for _, r := range results {
+			item := roleUpdateRes{ID: r.ID}
+			switch e := r.Err.(type) {
+			case nil:
+				client := r.Client
+				item.User = &client
+			case errors.Error:
+				item.Error = e.Msg()
+			default:
+				item.Error = e.Error()
+			}
+			res.Results = append(res.Results, item)
+		}
+
+		return res, nil
+	}
+}
+
 func issueTokenEndpoint(svc users.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(loginClientReq)
@@ -424,7 +728,7 @@ func issueTokenEndpoint(svc users.Service) endpoint.Endpoint {
 			return nil, errors.Wrap(apiutil.ErrValidation, err)
 		}
 
-		token, err := svc.IssueToken(ctx, req.Identity, req.Secret)
+		token, err := svc.IssueToken(ctx, req.Identity, req.Secret, req.MFACode, req.Audience)
 		if err != nil {
 			return nil, err
 		}
@@ -437,6 +741,40 @@ func issueTokenEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+func enrollMFAEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(loginClientReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		secret, err := svc.EnrollMFA(ctx, req.Identity, req.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		return enrollMFARes{
+			Secret: secret.Secret,
+			URI:    secret.URI,
+		}, nil
+	}
+}
+
+func confirmMFAEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := confirmMFAReq{loginClientReq: request.(loginClientReq)}
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		if err := svc.ConfirmMFA(ctx, req.Identity, req.Secret, req.MFACode); err != nil {
+			return nil, err
+		}
+
+		return confirmMFARes{}, nil
+	}
+}
+
 func refreshTokenEndpoint(svc users.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(tokenReq)
@@ -462,6 +800,69 @@ func refreshTokenEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+func deviceCodeEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deviceCodeReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		da, err := svc.RequestDeviceCode(ctx, req.Audience)
+		if err != nil {
+			return nil, err
+		}
+
+		return deviceCodeRes{
+			DeviceCode:              da.DeviceCode,
+			UserCode:                da.UserCode,
+			VerificationURI:         da.VerificationURL,
+			VerificationURIComplete: da.VerificationURLComplete,
+			ExpiresIn:               int64(da.ExpiresIn.Seconds()),
+			Interval:                int64(da.Interval.Seconds()),
+		}, nil
+	}
+}
+
+func authorizeDeviceEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(authorizeDeviceReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		session, ok := ctx.Value(api.SessionKey).(authn.Session)
+		if !ok {
+			return nil, svcerr.ErrAuthorization
+		}
+
+		if err := svc.AuthorizeDevice(ctx, session, req.UserCode, req.MFACode, req.Approve); err != nil {
+			return nil, err
+		}
+
+		return authorizeDeviceRes{}, nil
+	}
+}
+
+func deviceTokenEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deviceTokenReq)
+		if err := req.validate(); err != nil {
+			return nil, errors.Wrap(apiutil.ErrValidation, err)
+		}
+
+		token, err := svc.DeviceToken(ctx, req.DeviceCode)
+		if err != nil {
+			return nil, err
+		}
+
+		return tokenRes{
+			AccessToken:  token.GetAccessToken(),
+			RefreshToken: token.GetRefreshToken(),
+			AccessType:   token.GetAccessType(),
+		}, nil
+	}
+}
+
 func enableClientEndpoint(svc users.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(changeClientStatusReq)
@@ -4,12 +4,15 @@
 package api
 
 import (
+	"time"
+
 	"github.com/absmach/magistrala/internal/api"
 	"github.com/absmach/magistrala/pkg/apiutil"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/users"
 )
 
-const maxLimitSize = 100
+var pageLimits = api.NewPageLimits(api.DefLimit, api.MaxLimitSize)
 
 type createClientReq struct {
 	client mgclients.Client
@@ -45,25 +48,30 @@ func (req viewClientReq) validate() error {
 }
 
 type listClientsReq struct {
-	status   mgclients.Status
-	offset   uint64
-	limit    uint64
-	name     string
-	tag      string
-	identity string
-	metadata mgclients.Metadata
-	order    string
-	dir      string
-	id       string
+	status     mgclients.Status
+	offset     uint64
+	limit      uint64
+	name       string
+	tag        string
+	identity   string
+	metadata   mgclients.Metadata
+	order      string
+	dir        string
+	id         string
+	provider   string
+	unfiltered bool
 }
 
 func (req listClientsReq) validate() error {
-	if req.limit > maxLimitSize || req.limit < 1 {
+	if req.limit > pageLimits.Max || req.limit < 1 {
 		return apiutil.ErrLimitSize
 	}
 	if req.dir != "" && (req.dir != api.AscDir && req.dir != api.DescDir) {
 		return apiutil.ErrInvalidDirection
 	}
+	if err := mgclients.ValidateOrder(req.order); err != nil {
+		return apiutil.ErrInvalidOrder
+	}
 
 	return nil
 }
@@ -85,6 +93,40 @@ func (req searchClientsReq) validate() error {
 	return nil
 }
 
+type listChangesReq struct {
+	since  time.Time
+	cursor string
+	limit  uint64
+}
+
+func (req listChangesReq) validate() error {
+	if req.limit > pageLimits.Max || req.limit < 1 {
+		return apiutil.ErrLimitSize
+	}
+	if !req.since.IsZero() && req.cursor != "" {
+		return apiutil.ErrInvalidQueryParams
+	}
+
+	return nil
+}
+
+type listUserGroupsReq struct {
+	id     string
+	offset uint64
+	limit  uint64
+}
+
+func (req listUserGroupsReq) validate() error {
+	if req.id == "" {
+		return apiutil.ErrMissingID
+	}
+	if req.limit > pageLimits.Max || req.limit < 1 {
+		return apiutil.ErrLimitSize
+	}
+
+	return nil
+}
+
 type listMembersByObjectReq struct {
 	mgclients.Page
 	objectKind string
@@ -143,6 +185,31 @@ func (req updateClientRoleReq) validate() error {
 	return nil
 }
 
+type updateClientsRoleReq struct {
+	role    mgclients.Role
+	UserIDs []string `json:"user_ids"`
+	Role    string   `json:"role"`
+}
+
+func (req updateClientsRoleReq) validate() error {
+	if len(req.UserIDs) == 0 {
+		return apiutil.ErrEmptyList
+	}
+	if len(req.UserIDs) > api.MaxLimitSize {
+		return apiutil.ErrLimitSize
+	}
+	for _, id := range req.UserIDs {
+		if id == "" {
+			return apiutil.ErrMissingID
+		}
+	}
+	if req.role != mgclients.AdminRole && req.role != mgclients.UserRole {
+		return apiutil.ErrInvalidRole
+	}
+
+	return nil
+}
+
 type updateClientIdentityReq struct {
 	id       string
 	Identity string `json:"identity,omitempty"`
@@ -156,6 +223,30 @@ func (req updateClientIdentityReq) validate() error {
 	return nil
 }
 
+type confirmIdentityReq struct {
+	token string
+}
+
+func (req confirmIdentityReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	return nil
+}
+
+type deletionTokenReq struct {
+	token string
+}
+
+func (req deletionTokenReq) validate() error {
+	if req.token == "" {
+		return apiutil.ErrBearerToken
+	}
+
+	return nil
+}
+
 type updateClientSecretReq struct {
 	OldSecret string `json:"old_secret,omitempty"`
 	NewSecret string `json:"new_secret,omitempty"`
@@ -187,6 +278,8 @@ func (req changeClientStatusReq) validate() error {
 type loginClientReq struct {
 	Identity string `json:"identity,omitempty"`
 	Secret   string `json:"secret,omitempty"`
+	MFACode  string `json:"mfa_code,omitempty"`
+	Audience string `json:"audience,omitempty"`
 }
 
 func (req loginClientReq) validate() error {
@@ -200,6 +293,21 @@ func (req loginClientReq) validate() error {
 	return nil
 }
 
+type confirmMFAReq struct {
+	loginClientReq
+}
+
+func (req confirmMFAReq) validate() error {
+	if err := req.loginClientReq.validate(); err != nil {
+		return err
+	}
+	if req.MFACode == "" {
+		return apiutil.ErrMissingMFACode
+	}
+
+	return nil
+}
+
 type tokenReq struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 }
@@ -212,6 +320,93 @@ func (req tokenReq) validate() error {
 	return nil
 }
 
+type deviceCodeReq struct {
+	Audience string `json:"audience,omitempty"`
+}
+
+func (req deviceCodeReq) validate() error {
+	return nil
+}
+
+type authorizeDeviceReq struct {
+	UserCode string `json:"user_code"`
+	MFACode  string `json:"mfa_code,omitempty"`
+	Approve  bool   `json:"approve"`
+}
+
+func (req authorizeDeviceReq) validate() error {
+	if req.UserCode == "" {
+		return apiutil.ErrMissingCode
+	}
+
+	return nil
+}
+
+type deviceTokenReq struct {
+	DeviceCode string `json:"device_code"`
+}
+
+func (req deviceTokenReq) validate() error {
+	if req.DeviceCode == "" {
+		return apiutil.ErrMissingCode
+	}
+
+	return nil
+}
+
+type verifyPhoneReq struct {
+	Identity string `json:"identity"`
+	Code     string `json:"code"`
+}
+
+func (req verifyPhoneReq) validate() error {
+	if req.Identity == "" {
+		return apiutil.ErrMissingIdentity
+	}
+	if req.Code == "" {
+		return apiutil.ErrMissingCode
+	}
+
+	return nil
+}
+
+type sendPhoneCodeReq struct {
+	Identity string `json:"identity"`
+}
+
+func (req sendPhoneCodeReq) validate() error {
+	if req.Identity == "" {
+		return apiutil.ErrMissingIdentity
+	}
+
+	return nil
+}
+
+type resendVerificationReq struct {
+	Identity string `json:"identity"`
+}
+
+func (req resendVerificationReq) validate() error {
+	if req.Identity == "" {
+		return apiutil.ErrMissingIdentity
+	}
+
+	return nil
+}
+
+type previewEmailReq struct {
+	name string
+	users.EmailData
+}
+
+func (req previewEmailReq) validate() error {
+	if req.name == "" {
+		return apiutil.ErrMissingName
+	}
+
+	return nil
+}
+
 type passwResetReq struct {
 	Email string `json:"email"`
 	Host  string `json:"host"`
@@ -228,6 +423,18 @@ func (req passwResetReq) validate() error {
 	return nil
 }
 
+type passwStrengthReq struct {
+	Password string `json:"password"`
+}
+
+func (req passwStrengthReq) validate() error {
+	if req.Password == "" {
+		return apiutil.ErrMissingPass
+	}
+
+	return nil
+}
+
 type resetTokenReq struct {
 	Token    string `json:"token"`
 	Password string `json:"password"`
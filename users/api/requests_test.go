@@ -165,6 +165,31 @@ func TestListClientsReqValidate(t *testing.T) {
 			},
 			err: apiutil.ErrInvalidDirection,
 		},
+		{
+			desc: "valid metadata order",
+			req: listClientsReq{
+				limit: 10,
+				order: "metadata.org.department",
+				dir:   "desc",
+			},
+			err: nil,
+		},
+		{
+			desc: "invalid metadata order",
+			req: listClientsReq{
+				limit: 10,
+				order: "metadata.org..department",
+			},
+			err: apiutil.ErrInvalidOrder,
+		},
+		{
+			desc: "unknown column order",
+			req: listClientsReq{
+				limit: 10,
+				order: "secret",
+			},
+			err: apiutil.ErrInvalidOrder,
+		},
 	}
 	for _, c := range cases {
 		err := c.req.validate()
@@ -321,6 +346,58 @@ func TestUpdateClientRoleReqValidate(t *testing.T) {
 	}
 }
 
+func TestUpdateClientsRoleReqValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		req  updateClientsRoleReq
+		err  error
+	}{
+		{
+			desc: "valid request",
+			req: updateClientsRoleReq{
+				UserIDs: []string{validID},
+				role:    mgclients.AdminRole,
+			},
+			err: nil,
+		},
+		{
+			desc: "empty list of IDs",
+			req: updateClientsRoleReq{
+				role: mgclients.AdminRole,
+			},
+			err: apiutil.ErrEmptyList,
+		},
+		{
+			desc: "too many IDs",
+			req: updateClientsRoleReq{
+				UserIDs: make([]string, api.MaxLimitSize+1),
+				role:    mgclients.AdminRole,
+			},
+			err: apiutil.ErrLimitSize,
+		},
+		{
+			desc: "empty id",
+			req: updateClientsRoleReq{
+				UserIDs: []string{validID, ""},
+				role:    mgclients.AdminRole,
+			},
+			err: apiutil.ErrMissingID,
+		},
+		{
+			desc: "all role",
+			req: updateClientsRoleReq{
+				UserIDs: []string{validID},
+				role:    mgclients.AllRole,
+			},
+			err: apiutil.ErrInvalidRole,
+		},
+	}
+	for _, c := range cases {
+		err := c.req.validate()
+		assert.Equal(t, c.err, err, "%s: expected %s got %s\n", c.desc, c.err, err)
+	}
+}
+
 func TestUpdateClientIdentityReqValidate(t *testing.T) {
 	cases := []struct {
 		desc string
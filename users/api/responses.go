@@ -6,14 +6,28 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/absmach/magistrala"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/passwords"
+	"github.com/absmach/magistrala/users"
 )
 
 // MailSent message response when link is sent.
 const MailSent = "Email with reset link is sent"
 
+// CodeSent message response when phone verification code is sent.
+const CodeSent = "SMS with verification code is sent"
+
+// VerificationSent message response when verification is resent. The same
+// message is returned for the accounts that aren't waiting for verification.
+const VerificationSent = "Verification is sent if the account is waiting for it"
+
+// DeletionMailSent message response when account deletion confirmation link is sent.
+const DeletionMailSent = "Email with account deletion confirmation link is sent"
+
 var (
 	_ magistrala.Response = (*tokenRes)(nil)
 	_ magistrala.Response = (*viewClientRes)(nil)
@@ -21,19 +35,27 @@ var (
 	_ magistrala.Response = (*changeClientStatusClientRes)(nil)
 	_ magistrala.Response = (*clientsPageRes)(nil)
 	_ magistrala.Response = (*viewMembersRes)(nil)
+	_ magistrala.Response = (*userGroupsPageRes)(nil)
 	_ magistrala.Response = (*passwResetReqRes)(nil)
+	_ magistrala.Response = (*sendPhoneCodeRes)(nil)
+	_ magistrala.Response = (*resendVerificationRes)(nil)
+	_ magistrala.Response = (*passwStrengthRes)(nil)
 	_ magistrala.Response = (*passwChangeRes)(nil)
 	_ magistrala.Response = (*assignUsersRes)(nil)
 	_ magistrala.Response = (*unassignUsersRes)(nil)
 	_ magistrala.Response = (*updateClientRes)(nil)
+	_ magistrala.Response = (*updateClientsRoleRes)(nil)
 	_ magistrala.Response = (*tokenRes)(nil)
 	_ magistrala.Response = (*deleteClientRes)(nil)
+	_ magistrala.Response = (*deviceCodeRes)(nil)
+	_ magistrala.Response = (*authorizeDeviceRes)(nil)
 )
 
 type pageRes struct {
-	Limit  uint64 `json:"limit,omitempty"`
-	Offset uint64 `json:"offset"`
-	Total  uint64 `json:"total"`
+	Limit           uint64  `json:"limit,omitempty"`
+	Offset          uint64  `json:"offset"`
+	Total           uint64  `json:"total"`
+	TotalUnfiltered *uint64 `json:"total_unfiltered,omitempty"`
 }
 
 type createClientRes struct {
@@ -81,6 +103,74 @@ func (res tokenRes) Empty() bool {
 	return res.AccessToken == "" || res.RefreshToken == ""
 }
 
+// deviceCodeRes is the device authorization response of RFC 8628, so the
+// periods are in seconds.
+type deviceCodeRes struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri,omitempty"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+func (res deviceCodeRes) Code() int {
+	return http.StatusOK
+}
+
+func (res deviceCodeRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res deviceCodeRes) Empty() bool {
+	return false
+}
+
+type enrollMFARes struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+func (res enrollMFARes) Code() int {
+	return http.StatusCreated
+}
+
+func (res enrollMFARes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res enrollMFARes) Empty() bool {
+	return false
+}
+
+type confirmMFARes struct{}
+
+func (res confirmMFARes) Code() int {
+	return http.StatusNoContent
+}
+
+func (res confirmMFARes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res confirmMFARes) Empty() bool {
+	return true
+}
+
+type authorizeDeviceRes struct{}
+
+func (res authorizeDeviceRes) Code() int {
+	return http.StatusNoContent
+}
+
+func (res authorizeDeviceRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res authorizeDeviceRes) Empty() bool {
+	return true
+}
+
 type updateClientRes struct {
 	mgclients.Client `json:",inline"`
 }
@@ -97,6 +187,29 @@ func (res updateClientRes) Empty() bool {
 	return false
 }
 
+type roleUpdateRes struct {
+	ID    string            `json:"id"`
+	User  *mgclients.Client `json:"user,omitempty"`
+	Error string            `json:"error,omitempty"`
+}
+
+type updateClientsRoleRes struct {
+	Role    string          `json:"role"`
+	Results []roleUpdateRes `json:"results"`
+}
+
+func (res updateClientsRoleRes) Code() int {
+	return http.StatusOK
+}
+
+func (res updateClientsRoleRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res updateClientsRoleRes) Empty() bool {
+	return false
+}
+
 type viewClientRes struct {
 	mgclients.Client `json:",inline"`
 }
@@ -130,6 +243,49 @@ func (res clientsPageRes) Empty() bool {
 	return false
 }
 
+// changeRes is a change of a user. The users removed from the database are
+// tombstones, carrying only their ID.
+type changeRes struct {
+	Operation string         `json:"operation"`
+	ChangedAt time.Time      `json:"changed_at"`
+	ID        string         `json:"id"`
+	User      *viewClientRes `json:"user,omitempty"`
+}
+
+type changesPageRes struct {
+	Changes []changeRes `json:"changes"`
+	Cursor  string      `json:"cursor"`
+}
+
+func (res changesPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res changesPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res changesPageRes) Empty() bool {
+	return false
+}
+
+type userGroupsPageRes struct {
+	pageRes
+	Groups []groups.Membership `json:"groups"`
+}
+
+func (res userGroupsPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res userGroupsPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res userGroupsPageRes) Empty() bool {
+	return false
+}
+
 type viewMembersRes struct {
 	mgclients.Client `json:",inline"`
 }
@@ -162,6 +318,22 @@ func (res changeClientStatusClientRes) Empty() bool {
 	return false
 }
 
+type passwStrengthRes struct {
+	passwords.Strength
+}
+
+func (res passwStrengthRes) Code() int {
+	return http.StatusOK
+}
+
+func (res passwStrengthRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res passwStrengthRes) Empty() bool {
+	return false
+}
+
 type passwResetReqRes struct {
 	Msg string `json:"msg"`
 }
@@ -178,6 +350,70 @@ func (res passwResetReqRes) Empty() bool {
 	return false
 }
 
+type sendPhoneCodeRes struct {
+	Msg string `json:"msg"`
+}
+
+func (res sendPhoneCodeRes) Code() int {
+	return http.StatusCreated
+}
+
+func (res sendPhoneCodeRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res sendPhoneCodeRes) Empty() bool {
+	return false
+}
+
+type resendVerificationRes struct {
+	Msg string `json:"msg"`
+}
+
+func (res resendVerificationRes) Code() int {
+	return http.StatusAccepted
+}
+
+func (res resendVerificationRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res resendVerificationRes) Empty() bool {
+	return false
+}
+
+type requestDeletionRes struct {
+	Msg string `json:"msg"`
+}
+
+func (res requestDeletionRes) Code() int {
+	return http.StatusAccepted
+}
+
+func (res requestDeletionRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res requestDeletionRes) Empty() bool {
+	return false
+}
+
+type previewEmailRes struct {
+	users.EmailPreview
+}
+
+func (res previewEmailRes) Code() int {
+	return http.StatusOK
+}
+
+func (res previewEmailRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res previewEmailRes) Empty() bool {
+	return false
+}
+
 type passwChangeRes struct{}
 
 func (res passwChangeRes) Code() int {
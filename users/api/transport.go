@@ -9,21 +9,30 @@ import (
 	"regexp"
 
 	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/internal/api"
 	mgauthn "github.com/absmach/magistrala/pkg/authn"
 	"github.com/absmach/magistrala/pkg/groups"
 	"github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/absmach/magistrala/pkg/passwords"
+	"github.com/absmach/magistrala/pkg/quotas"
+	quotasapi "github.com/absmach/magistrala/pkg/quotas/api"
+	"github.com/absmach/magistrala/pkg/saml"
 	"github.com/absmach/magistrala/users"
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// MakeHandler returns a HTTP handler for Users and Groups API endpoints.
-func MakeHandler(cls users.Service, authn mgauthn.Authentication, tokenClient magistrala.TokenServiceClient, selfRegister bool, grps groups.Service, mux *chi.Mux, logger *slog.Logger, instanceID string, pr *regexp.Regexp, providers ...oauth2.Provider) http.Handler {
-	clientsHandler(cls, authn, tokenClient, selfRegister, mux, logger, pr, providers...)
-	groupsHandler(grps, authn, mux, logger)
+// MakeHandler returns a HTTP handler for Users, Groups and Quotas API endpoints.
+func MakeHandler(cls users.Service, authn mgauthn.Authentication, tokenClient magistrala.TokenServiceClient, selfRegister bool, grps groups.Service, qsvc quotas.Service, mux *chi.Mux, logger *slog.Logger, instanceID string, pr *regexp.Regexp, pl api.PageLimits, pe passwords.Evaluator, sl, dl *api.KeyedLimiter, healthOpts []magistrala.HealthOption, sp saml.ServiceProvider, providers ...oauth2.Provider) http.Handler {
+	mux.Use(api.RequestIDMiddleware)
+	clientsHandler(cls, grps, authn, tokenClient, selfRegister, mux, logger, pr, pl, pe, sl, dl, sp, providers...)
+	groupsHandler(grps, authn, mux, logger, pl)
+	quotasapi.MakeHandler(qsvc, authn, mux, logger)
 
-	mux.Get("/health", magistrala.Health("users", instanceID))
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Get("/health", magistrala.Health("users", instanceID, healthOpts...))
+	// OpenMetrics exposes the exemplars linking the request durations to the traces.
+	mux.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
 
 	return mux
 }
@@ -0,0 +1,53 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+var errDeletedUser = errors.New("user is deleted")
+
+var _ authn.Authentication = (*authentication)(nil)
+
+type authentication struct {
+	authn authn.Authentication
+	repo  Repository
+}
+
+// NewAuthentication returns authentication that rejects tokens of users that
+// are neither enabled nor disabled, such as the deleted users, without
+// waiting for the auth service to revoke them. The tokens of disabled users
+// are checked by the auth service, which accepts them until the grace period
+// since the user was disabled elapses, so every service applies it.
+func NewAuthentication(authn authn.Authentication, repo Repository) authn.Authentication {
+	return &authentication{
+		authn: authn,
+		repo:  repo,
+	}
+}
+
+func (a *authentication) Authenticate(ctx context.Context, token string) (authn.Session, error) {
+	session, err := a.authn.Authenticate(ctx, token)
+	if err != nil {
+		return authn.Session{}, err
+	}
+
+	client, err := a.repo.RetrieveByID(ctx, session.UserID)
+	if err != nil {
+		return authn.Session{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+
+	switch client.Status {
+	case mgclients.EnabledStatus, mgclients.DisabledStatus:
+		return session, nil
+	default:
+		return authn.Session{}, errors.Wrap(svcerr.ErrAuthentication, errDeletedUser)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	authnmocks "github.com/absmach/magistrala/pkg/authn/mocks"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	session := authn.Session{UserID: client.ID, DomainID: validID, DomainUserID: validID + "_" + client.ID}
+
+	disabledClient := client
+	disabledClient.Status = mgclients.DisabledStatus
+	deletedClient := client
+	deletedClient.Status = mgclients.DeletedStatus
+
+	cases := []struct {
+		desc                 string
+		authnRes             authn.Session
+		authnErr             error
+		retrieveByIDResponse mgclients.Client
+		retrieveByIDErr      error
+		session              authn.Session
+		err                  error
+	}{
+		{
+			desc:                 "authenticate enabled user",
+			authnRes:             session,
+			retrieveByIDResponse: client,
+			session:              session,
+			err:                  nil,
+		},
+		{
+			desc:                 "authenticate disabled user accepted by auth service",
+			authnRes:             session,
+			retrieveByIDResponse: disabledClient,
+			session:              session,
+			err:                  nil,
+		},
+		{
+			desc:     "authenticate disabled user rejected by auth service",
+			authnErr: svcerr.ErrAuthentication,
+			err:      svcerr.ErrAuthentication,
+		},
+		{
+			desc:                 "authenticate deleted user",
+			authnRes:             session,
+			retrieveByIDResponse: deletedClient,
+			err:                  svcerr.ErrAuthentication,
+		},
+		{
+			desc:            "authenticate with failed to retrieve user",
+			authnRes:        session,
+			retrieveByIDErr: repoerr.ErrNotFound,
+			err:             svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			authnSvc := new(authnmocks.Authentication)
+			cRepo := new(mocks.Repository)
+			a := users.NewAuthentication(authnSvc, cRepo)
+
+			authnCall := authnSvc.On("Authenticate", context.Background(), validToken).Return(tc.authnRes, tc.authnErr)
+			repoCall := cRepo.On("RetrieveByID", context.Background(), tc.authnRes.UserID).Return(tc.retrieveByIDResponse, tc.retrieveByIDErr)
+			s, err := a.Authenticate(context.Background(), validToken)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.session, s, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.session, s))
+			authnCall.Unset()
+			repoCall.Unset()
+		})
+	}
+}
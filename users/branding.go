@@ -0,0 +1,95 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+var errInvalidEmailBranding = errors.New("invalid e-mail branding")
+
+// EmailBranding customizes the e-mails sent to the users of a domain.
+// Empty values fall back to the global e-mail configuration.
+type EmailBranding struct {
+	// SenderName is the name the e-mails are sent from.
+	SenderName string `json:"sender_name,omitempty"`
+
+	// ReplyTo is the address the replies to the e-mails are sent to.
+	ReplyTo string `json:"reply_to,omitempty"`
+
+	// LogoURL is the URL of the logo, available to the template as {{.Logo}}.
+	LogoURL string `json:"logo_url,omitempty"`
+
+	// Template is the path of the template overriding the global one.
+	Template string `json:"template,omitempty"`
+}
+
+// ParseEmailBranding parses the e-mail branding from a JSON object mapping
+// domain IDs to the branding of the domain.
+func ParseEmailBranding(s string) (map[string]EmailBranding, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var branding map[string]EmailBranding
+	if err := json.Unmarshal([]byte(s), &branding); err != nil {
+		return nil, errors.Wrap(errInvalidEmailBranding, err)
+	}
+	for domainID, b := range branding {
+		if domainID == "" {
+			return nil, errors.Wrap(errInvalidEmailBranding, fmt.Errorf("missing domain ID"))
+		}
+		if b.ReplyTo != "" {
+			if _, err := mail.ParseAddress(b.ReplyTo); err != nil {
+				return nil, errors.Wrap(errInvalidEmailBranding, fmt.Errorf("invalid reply-to address of domain %q: %w", domainID, err))
+			}
+		}
+		if b.LogoURL != "" {
+			u, err := url.ParseRequestURI(b.LogoURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				return nil, errors.Wrap(errInvalidEmailBranding, fmt.Errorf("invalid logo URL of domain %q", domainID))
+			}
+		}
+	}
+
+	return branding, nil
+}
+
+// emailBranding returns the e-mail branding of the domain of the user with
+// the given identity, or no branding if none of the user domains is branded.
+// Users may be members of several branded domains, in which case the domain
+// with the lowest ID is used, so the same branding is used every time.
+func (svc service) emailBranding(ctx context.Context, identity string) (EmailBranding, error) {
+	if len(svc.config.EmailBranding) == 0 {
+		return EmailBranding{}, nil
+	}
+	client, err := svc.clients.RetrieveByIdentity(ctx, identity)
+	if err != nil {
+		return EmailBranding{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	page, err := svc.policies.ListAllObjects(ctx, policies.Policy{
+		SubjectType: policies.UserType,
+		Subject:     client.ID,
+		Permission:  policies.MembershipPermission,
+		ObjectType:  policies.DomainType,
+	})
+	if err != nil {
+		return EmailBranding{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	var domainID string
+	for _, id := range page.Policies {
+		if _, ok := svc.config.EmailBranding[id]; ok && (domainID == "" || id < domainID) {
+			domainID = id
+		}
+	}
+
+	return svc.config.EmailBranding[domainID], nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	"github.com/absmach/magistrala/pkg/policies"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEmailBranding(t *testing.T) {
+	cases := []struct {
+		desc     string
+		branding string
+		expected map[string]users.EmailBranding
+		err      bool
+	}{
+		{
+			desc: "empty e-mail branding",
+		},
+		{
+			desc:     "valid e-mail branding",
+			branding: `{"domain1":{"sender_name":"Acme","reply_to":"support@acme.com","logo_url":"https://acme.com/logo.png","template":"acme.tmpl"}}`,
+			expected: map[string]users.EmailBranding{
+				"domain1": {SenderName: "Acme", ReplyTo: "support@acme.com", LogoURL: "https://acme.com/logo.png", Template: "acme.tmpl"},
+			},
+		},
+		{
+			desc:     "malformed e-mail branding",
+			branding: `["domain1"]`,
+			err:      true,
+		},
+		{
+			desc:     "e-mail branding without domain ID",
+			branding: `{"":{"sender_name":"Acme"}}`,
+			err:      true,
+		},
+		{
+			desc:     "e-mail branding with invalid reply-to address",
+			branding: `{"domain1":{"reply_to":"support"}}`,
+			err:      true,
+		},
+		{
+			desc:     "e-mail branding with invalid logo URL",
+			branding: `{"domain1":{"logo_url":"ftp://acme.com/logo.png"}}`,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			branding, err := users.ParseEmailBranding(tc.branding)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+			if !tc.err {
+				assert.Equal(t, tc.expected, branding)
+			}
+		})
+	}
+}
+
+func TestSendPasswordResetBranding(t *testing.T) {
+	acme := users.EmailBranding{SenderName: "Acme", ReplyTo: "support@acme.com", LogoURL: "https://acme.com/logo.png"}
+	globex := users.EmailBranding{SenderName: "Globex"}
+	branding := map[string]users.EmailBranding{
+		"domain1": acme,
+		"domain2": globex,
+	}
+
+	cases := []struct {
+		desc        string
+		branding    map[string]users.EmailBranding
+		domains     []string
+		retrieveErr error
+		listErr     error
+		expected    users.EmailBranding
+		err         error
+	}{
+		{
+			desc:     "send reset e-mail without configured branding",
+			domains:  []string{"domain1"},
+			expected: users.EmailBranding{},
+		},
+		{
+			desc:     "send reset e-mail to member of branded domain",
+			branding: branding,
+			domains:  []string{"domain3", "domain2"},
+			expected: globex,
+		},
+		{
+			desc:     "send reset e-mail to member of several branded domains",
+			branding: branding,
+			domains:  []string{"domain2", "domain1"},
+			expected: acme,
+		},
+		{
+			desc:     "send reset e-mail to member of unbranded domain",
+			branding: branding,
+			domains:  []string{"domain3"},
+			expected: users.EmailBranding{},
+		},
+		{
+			desc:        "send reset e-mail to non-existing client",
+			branding:    branding,
+			retrieveErr: repoerr.ErrNotFound,
+			err:         repoerr.ErrNotFound,
+		},
+		{
+			desc:     "send reset e-mail with failed to list domains",
+			branding: branding,
+			listErr:  repoerr.ErrViewEntity,
+			err:      repoerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			policySvc := new(policymocks.Service)
+			e := new(mocks.Emailer)
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, policySvc, e, phasher, idProvider, users.Config{EmailBranding: tc.branding})
+
+			cRepo.On("RetrieveByIdentity", context.Background(), client.Credentials.Identity).Return(client, tc.retrieveErr)
+			policySvc.On("ListAllObjects", context.Background(), policies.Policy{
+				SubjectType: policies.UserType,
+				Subject:     client.ID,
+				Permission:  policies.MembershipPermission,
+				ObjectType:  policies.DomainType,
+			}).Return(policies.PolicyPage{Policies: tc.domains}, tc.listErr)
+			e.On("SendPasswordReset", []string{client.Credentials.Identity}, "host", client.Name, validToken, tc.expected).Return(nil)
+
+			err := svc.SendPasswordReset(context.Background(), "host", client.Credentials.Identity, client.Name, validToken)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				e.AssertCalled(t, "SendPasswordReset", []string{client.Credentials.Identity}, "host", client.Name, validToken, tc.expected)
+			}
+			if tc.branding == nil {
+				cRepo.AssertNotCalled(t, "RetrieveByIdentity", context.Background(), client.Credentials.Identity)
+			}
+		})
+	}
+}
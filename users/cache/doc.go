@@ -0,0 +1,7 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cache contains the Redis implementation of the users service
+// password reset cache, which shares the reset requests between service
+// instances.
+package cache
@@ -0,0 +1,61 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/absmach/magistrala/users"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "users_resets"
+
+// claimScript counts the request and starts the window with the first one,
+// so the key expires once the window passes.
+var claimScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+var _ users.ResetCache = (*resetCache)(nil)
+
+type resetCache struct {
+	client *redis.Client
+}
+
+// NewResetCache returns Redis password reset cache. The requests of an
+// identity expire once its window passes, so the number of stored keys is
+// bounded by the request rate within the window.
+func NewResetCache(client *redis.Client) users.ResetCache {
+	return &resetCache{client: client}
+}
+
+func (rc *resetCache) Claim(ctx context.Context, identity string, window time.Duration) (int, error) {
+	count, err := claimScript.Run(ctx, rc.client, []string{resetKey(identity)}, window.Milliseconds()).Int()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (rc *resetCache) Release(ctx context.Context, identity string) error {
+	return rc.client.Del(ctx, resetKey(identity)).Err()
+}
+
+// resetKey hashes the identity so that stored keys have a fixed size and
+// don't expose the identities.
+func resetKey(identity string) string {
+	sum := sha256.Sum256([]byte(identity))
+
+	return fmt.Sprintf("%s:%s", keyPrefix, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,103 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	identity  = "user@example.com"
+	identity1 = "user1@example.com"
+)
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+	rc := cache.NewResetCache(redisClient)
+	// Second cache represents another service instance sharing the store.
+	rc1 := cache.NewResetCache(redisClient)
+
+	cases := []struct {
+		desc     string
+		cache    users.ResetCache
+		identity string
+		count    int
+	}{
+		{
+			desc:     "claim new identity",
+			cache:    rc,
+			identity: identity,
+			count:    1,
+		},
+		{
+			desc:     "claim claimed identity",
+			cache:    rc,
+			identity: identity,
+			count:    2,
+		},
+		{
+			desc:     "claim claimed identity on another instance",
+			cache:    rc1,
+			identity: identity,
+			count:    3,
+		},
+		{
+			desc:     "claim another identity",
+			cache:    rc1,
+			identity: identity1,
+			count:    1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			count, err := tc.cache.Claim(ctx, tc.identity, time.Minute)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.count, count))
+		})
+	}
+}
+
+func TestClaimExpired(t *testing.T) {
+	ctx := context.Background()
+	rc := cache.NewResetCache(redisClient)
+
+	count, err := rc.Claim(ctx, "expired", 100*time.Millisecond)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, 1, count, fmt.Sprintf("expected 1 got %d", count))
+
+	// Requests within the window don't extend it.
+	time.Sleep(60 * time.Millisecond)
+	count, err = rc.Claim(ctx, "expired", 100*time.Millisecond)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, 2, count, fmt.Sprintf("expected 2 got %d", count))
+
+	time.Sleep(60 * time.Millisecond)
+	count, err = rc.Claim(ctx, "expired", 100*time.Millisecond)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, 1, count, fmt.Sprintf("expected expired window to start again, got %d", count))
+}
+
+func TestRelease(t *testing.T) {
+	ctx := context.Background()
+	rc := cache.NewResetCache(redisClient)
+
+	count, err := rc.Claim(ctx, "released", time.Minute)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, 1, count, fmt.Sprintf("expected 1 got %d", count))
+
+	err = rc.Release(ctx, "released")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error on release %s", err))
+
+	count, err = rc.Claim(ctx, "released", time.Minute)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, 1, count, fmt.Sprintf("expected released identity to start again, got %d", count))
+}
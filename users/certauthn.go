@@ -0,0 +1,105 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+// CertField is the client certificate field mapped to the user identity.
+type CertField string
+
+const (
+	// CommonNameField maps the certificate subject common name.
+	CommonNameField CertField = "cn"
+	// EmailField maps the certificate email SANs.
+	EmailField CertField = "email"
+	// DNSField maps the certificate DNS name SANs.
+	DNSField CertField = "dns"
+	// URIField maps the certificate URI SANs.
+	URIField CertField = "uri"
+)
+
+var (
+	errInvalidCertField = errors.New("invalid client certificate field")
+	errUnmappedCert     = errors.New("client certificate is not mapped to a user")
+)
+
+// ParseCertField parses the client certificate field name.
+func ParseCertField(field string) (CertField, error) {
+	switch f := CertField(field); f {
+	case CommonNameField, EmailField, DNSField, URIField:
+		return f, nil
+	default:
+		return "", errors.Wrap(errInvalidCertField, fmt.Errorf("unknown field %q", field))
+	}
+}
+
+func (f CertField) values(cert *x509.Certificate) []string {
+	switch f {
+	case CommonNameField:
+		if cert.Subject.CommonName == "" {
+			return nil
+		}
+		return []string{cert.Subject.CommonName}
+	case EmailField:
+		return cert.EmailAddresses
+	case DNSField:
+		return cert.DNSNames
+	case URIField:
+		values := make([]string, 0, len(cert.URIs))
+		for _, uri := range cert.URIs {
+			values = append(values, uri.String())
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+var (
+	_ authn.Authentication     = (*certAuthentication)(nil)
+	_ authn.CertAuthentication = (*certAuthentication)(nil)
+)
+
+type certAuthentication struct {
+	authn.Authentication
+	repo  Repository
+	field CertField
+}
+
+// NewCertAuthentication returns authentication that, in addition to tokens,
+// authenticates verified client certificates. The value of the certificate
+// field is matched against identities of enabled users, so service accounts
+// can authenticate without bearer tokens.
+func NewCertAuthentication(authn authn.Authentication, repo Repository, field CertField) authn.Authentication {
+	return &certAuthentication{
+		Authentication: authn,
+		repo:           repo,
+		field:          field,
+	}
+}
+
+func (a *certAuthentication) AuthenticateCert(ctx context.Context, cert *x509.Certificate) (authn.Session, error) {
+	for _, value := range a.field.values(cert) {
+		client, err := a.repo.RetrieveByIdentity(ctx, value)
+		switch {
+		case errors.Contains(err, repoerr.ErrNotFound):
+			continue
+		case err != nil:
+			return authn.Session{}, errors.Wrap(svcerr.ErrAuthentication, err)
+		}
+
+		return authn.Session{DomainUserID: client.ID, UserID: client.ID}, nil
+	}
+
+	return authn.Session{}, errors.Wrap(svcerr.ErrAuthentication, errUnmappedCert)
+}
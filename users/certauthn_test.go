@@ -0,0 +1,132 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	authnmocks "github.com/absmach/magistrala/pkg/authn/mocks"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticateCert(t *testing.T) {
+	serviceURI, err := url.Parse("spiffe://magistrala/service")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error parsing URI: %s", err))
+
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: client.Credentials.Identity},
+		EmailAddresses: []string{"unknown@example.com", client.Credentials.Identity},
+		URIs:           []*url.URL{serviceURI},
+	}
+	session := authn.Session{DomainUserID: client.ID, UserID: client.ID}
+
+	cases := []struct {
+		desc        string
+		field       users.CertField
+		cert        *x509.Certificate
+		identities  map[string]error
+		retrieveRes mgclients.Client
+		session     authn.Session
+		err         error
+	}{
+		{
+			desc:        "authenticate cert mapped by common name",
+			field:       users.CommonNameField,
+			cert:        cert,
+			identities:  map[string]error{client.Credentials.Identity: nil},
+			retrieveRes: client,
+			session:     session,
+		},
+		{
+			desc:  "authenticate cert mapped by second email SAN",
+			field: users.EmailField,
+			cert:  cert,
+			identities: map[string]error{
+				"unknown@example.com":       repoerr.ErrNotFound,
+				client.Credentials.Identity: nil,
+			},
+			retrieveRes: client,
+			session:     session,
+		},
+		{
+			desc:       "authenticate cert with unmapped subject",
+			field:      users.URIField,
+			cert:       cert,
+			identities: map[string]error{serviceURI.String(): repoerr.ErrNotFound},
+			err:        svcerr.ErrAuthentication,
+		},
+		{
+			desc:  "authenticate cert without mapped field",
+			field: users.DNSField,
+			cert:  cert,
+			err:   svcerr.ErrAuthentication,
+		},
+		{
+			desc:       "authenticate cert with failed to retrieve user",
+			field:      users.CommonNameField,
+			cert:       cert,
+			identities: map[string]error{client.Credentials.Identity: repoerr.ErrViewEntity},
+			err:        svcerr.ErrAuthentication,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			a := users.NewCertAuthentication(new(authnmocks.Authentication), cRepo, tc.field)
+			certAuthn, ok := a.(authn.CertAuthentication)
+			assert.True(t, ok, "expected authentication to support client certificates")
+
+			for identity, err := range tc.identities {
+				res := mgclients.Client{}
+				if err == nil {
+					res = tc.retrieveRes
+				}
+				cRepo.On("RetrieveByIdentity", context.Background(), identity).Return(res, err)
+			}
+			s, err := certAuthn.AuthenticateCert(context.Background(), tc.cert)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.session, s, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.session, s))
+			if len(tc.identities) == 0 {
+				cRepo.AssertNotCalled(t, "RetrieveByIdentity", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestParseCertField(t *testing.T) {
+	cases := []struct {
+		desc  string
+		field string
+		res   users.CertField
+		err   bool
+	}{
+		{desc: "parse common name", field: "cn", res: users.CommonNameField},
+		{desc: "parse email", field: "email", res: users.EmailField},
+		{desc: "parse DNS name", field: "dns", res: users.DNSField},
+		{desc: "parse URI", field: "uri", res: users.URIField},
+		{desc: "parse unknown field", field: "serial", err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := users.ParseCertField(tc.field)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+			assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.res, res))
+		})
+	}
+}
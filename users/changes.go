@@ -0,0 +1,114 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+// The operations of the user changes.
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// ErrInvalidCursor indicates a malformed change feed cursor.
+var ErrInvalidCursor = errors.New("invalid changes cursor")
+
+// Change is the latest change of a user. The change of a user removed from
+// the database is a tombstone, without the user.
+type Change struct {
+	ID        string
+	Operation string
+	ChangedAt time.Time
+	Client    *mgclients.Client
+}
+
+// ChangesQuery selects the changes made after the given time, ordered by the
+// change time and the user ID. If AfterID is set, the changes made exactly at
+// the given time are selected too, if their user ID is greater than AfterID.
+type ChangesQuery struct {
+	Since   time.Time
+	AfterID string
+	Limit   uint64
+}
+
+// ChangesPage contains the changes and the cursor resuming the feed right
+// after them.
+type ChangesPage struct {
+	Changes []Change
+	Cursor  string
+}
+
+// ListChanges returns the changes of the users made after the given time, or
+// after the cursor if set, so the copies of the users, such as the search
+// indexes, can be kept in sync incrementally. Only the latest change of each
+// user is kept, so a user changed again reappears later in the feed.
+func (svc service) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (ChangesPage, error) {
+	if err := svc.checkSuperAdmin(ctx, session); err != nil {
+		return ChangesPage{}, err
+	}
+
+	cq := ChangesQuery{Since: since, Limit: limit}
+	if cursor != "" {
+		var err error
+		if cq, err = decodeChangesCursor(cursor); err != nil {
+			return ChangesPage{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		cq.Limit = limit
+	}
+
+	changes, err := svc.clients.RetrieveChanges(ctx, cq)
+	if err != nil {
+		return ChangesPage{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	if len(changes) > 0 {
+		last := changes[len(changes)-1]
+		cq.Since, cq.AfterID = last.ChangedAt, last.ID
+	}
+
+	return ChangesPage{Changes: changes, Cursor: encodeChangesCursor(cq)}, nil
+}
+
+// encodeChangesCursor encodes the position in the feed as the change time in
+// nanoseconds and the user ID. The start of the feed has no time.
+func encodeChangesCursor(cq ChangesQuery) string {
+	var nanos string
+	if !cq.Since.IsZero() {
+		nanos = strconv.FormatInt(cq.Since.UnixNano(), 10)
+	}
+	pos := nanos + ":" + cq.AfterID
+
+	return base64.RawURLEncoding.EncodeToString([]byte(pos))
+}
+
+func decodeChangesCursor(cursor string) (ChangesQuery, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ChangesQuery{}, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return ChangesQuery{}, ErrInvalidCursor
+	}
+	if nanos == "" {
+		return ChangesQuery{AfterID: id}, nil
+	}
+	ns, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return ChangesQuery{}, ErrInvalidCursor
+	}
+
+	return ChangesQuery{Since: time.Unix(0, ns).UTC(), AfterID: id}, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"fmt"
+	"reflect"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// ClaimsKey is the user metadata key holding the custom claims of the user,
+// such as {"region": "eu"}. The claims are added to the tokens issued to the
+// user and the conditions of the domain policies are evaluated against them,
+// so only super admins may set and change them.
+const ClaimsKey = "claims"
+
+var (
+	errInvalidClaims    = errors.New("invalid user claims")
+	errClaimsSuperAdmin = errors.New("only super admins may set the claims of the users")
+)
+
+// parseClaims returns the custom claims from the user metadata.
+func parseClaims(md mgclients.Metadata) (map[string]string, error) {
+	val, ok := md[ClaimsKey]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	obj, ok := asObject(val)
+	if !ok {
+		return nil, errors.Wrap(errInvalidClaims, fmt.Errorf("claims must be an object"))
+	}
+	claims := make(map[string]string, len(obj))
+	for name, v := range obj {
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.Wrap(errInvalidClaims, fmt.Errorf("claim %s must be a string", name))
+		}
+		claims[name] = s
+	}
+
+	return claims, nil
+}
+
+// claimsChanged reports whether the update changes the claims of the user.
+func claimsChanged(current, updated mgclients.Metadata) bool {
+	_, ok := updated[ClaimsKey]
+	if !ok {
+		return false
+	}
+	currClaims, _ := parseClaims(current)
+	updClaims, _ := parseClaims(updated)
+	if len(currClaims) == 0 && len(updClaims) == 0 {
+		return false
+	}
+
+	return !reflect.DeepEqual(currClaims, updClaims)
+}
+
+// Claims returns the custom claims added to the tokens of the user with the
+// metadata. Malformed claims are dropped, so the user fails the conditions
+// of the policies rather than failing to log in.
+func Claims(md mgclients.Metadata) map[string]string {
+	claims, err := parseClaims(md)
+	if err != nil {
+		return nil
+	}
+
+	return claims
+}
@@ -5,6 +5,7 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/pkg/authn"
@@ -29,9 +30,18 @@ type Service interface {
 	// ListClients retrieves clients list for a valid auth token.
 	ListClients(ctx context.Context, session authn.Session, pm clients.Page) (clients.ClientsPage, error)
 
+	// ListChanges retrieves up to the limit of the changes of the clients made
+	// after the given time, or after the cursor if set, oldest first. The
+	// changes of the removed clients are tombstones.
+	ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (ChangesPage, error)
+
 	// ListMembers retrieves everything that is assigned to a group/thing identified by objectID.
 	ListMembers(ctx context.Context, session authn.Session, objectKind, objectID string, pm clients.Page) (clients.MembersPage, error)
 
+	// ListObjectPermissions retrieves users with access to the group/channel/thing identified by objectID,
+	// along with their effective permissions on it, including those inherited through groups.
+	ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, pm clients.Page) (clients.MembersPage, error)
+
 	// SearchClients searches for users with provided filters for a valid auth token.
 	SearchUsers(ctx context.Context, pm clients.Page) (clients.ClientsPage, error)
 
@@ -44,6 +54,34 @@ type Service interface {
 	// UpdateClientIdentity updates the client's identity.
 	UpdateClientIdentity(ctx context.Context, session authn.Session, id, identity string) (clients.Client, error)
 
+	// ConfirmIdentity activates the pending identity change identified by the confirmation token.
+	ConfirmIdentity(ctx context.Context, token string) (clients.Client, error)
+
+	// VerifyPhone enables the user registered with the phone identity once
+	// the code sent to the phone is verified.
+	VerifyPhone(ctx context.Context, identity, code string) (clients.Client, error)
+
+	// SendPhoneCode sends a new verification code to the phone of the user
+	// waiting for verification.
+	SendPhoneCode(ctx context.Context, identity string) error
+
+	// ResendVerification sends a new verification to the e-mail or phone of
+	// the user waiting for verification. It doesn't fail for unknown and
+	// already verified identities, so it doesn't reveal the accounts.
+	ResendVerification(ctx context.Context, identity string) error
+
+	// RequestDeletion sends the account deletion confirmation token to the
+	// e-mail of the user.
+	RequestDeletion(ctx context.Context, session authn.Session) error
+
+	// ConfirmDeletion deletes the account of the user identified by the
+	// deletion token. The account is purged after the cooling-off period.
+	ConfirmDeletion(ctx context.Context, token string) (clients.Client, error)
+
+	// CancelDeletion cancels the deletion identified by the deletion token,
+	// restoring the account if the deletion has already been confirmed.
+	CancelDeletion(ctx context.Context, token string) (clients.Client, error)
+
 	// GenerateResetToken email where mail will be sent.
 	// host is used for generating reset link.
 	GenerateResetToken(ctx context.Context, email, host string) error
@@ -58,29 +96,72 @@ type Service interface {
 	// SendPasswordReset sends reset password link to email.
 	SendPasswordReset(ctx context.Context, host, email, user, token string) error
 
+	// PreviewEmail renders the named e-mail template with the data, without
+	// sending it, so super admins can check the e-mails before enabling them.
+	PreviewEmail(ctx context.Context, session authn.Session, name string, data EmailData) (EmailPreview, error)
+
 	// UpdateClientRole updates the client's Role.
 	UpdateClientRole(ctx context.Context, session authn.Session, client clients.Client) (clients.Client, error)
 
+	// UpdateClientsRole updates the role of the clients with the given IDs at once.
+	// The role is applied to all the clients in a single transaction, while the clients
+	// which can't be updated, e.g. because they don't exist, are reported in the results.
+	UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role clients.Role) ([]RoleUpdate, error)
+
 	// EnableClient logically enableds the client identified with the provided ID.
 	EnableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error)
 
 	// DisableClient logically disables the client identified with the provided ID.
 	DisableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error)
 
+	// DisableInactiveClient disables the client inactive for longer than the
+	// configured inactivity threshold. It is not exposed through the API and
+	// returns an error if the client is not inactive.
+	DisableInactiveClient(ctx context.Context, id string) (clients.Client, error)
+
 	// DeleteClient deletes client with given ID.
 	DeleteClient(ctx context.Context, session authn.Session, id string) error
 
 	// Identify returns the client id from the given token.
 	Identify(ctx context.Context, session authn.Session) (string, error)
 
-	// IssueToken issues a new access and refresh token.
-	IssueToken(ctx context.Context, identity, secret string) (*magistrala.Token, error)
+	// IssueToken issues a new access and refresh token. Users with
+	// multi-factor authentication enrolled must provide the TOTP code. If
+	// the audience is not empty, the tokens may only be used by that
+	// audience. If the context carries a client fingerprint, the tokens are
+	// bound to that client.
+	IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (*magistrala.Token, error)
+
+	// EnrollMFA starts the multi-factor authentication enrollment of the user
+	// with the given credentials and returns the new TOTP secret. The
+	// credentials are used instead of a token, so that the users who must
+	// enroll MFA can do so before they can log in.
+	EnrollMFA(ctx context.Context, identity, secret string) (MFASecret, error)
+
+	// ConfirmMFA completes the multi-factor authentication enrollment with
+	// the first TOTP code generated from the secret.
+	ConfirmMFA(ctx context.Context, identity, secret, code string) error
 
 	// RefreshToken refreshes expired access tokens.
 	// After an access token expires, the refresh token is used to get
 	// a new pair of access and refresh tokens.
 	RefreshToken(ctx context.Context, session authn.Session, refreshToken string) (*magistrala.Token, error)
 
+	// RequestDeviceCode starts the OAuth2 device authorization flow, returning
+	// the device code the device polls the token with, and the user code the
+	// user authorizes the device with. The token is issued for the audience.
+	RequestDeviceCode(ctx context.Context, audience string) (DeviceAuthorization, error)
+
+	// AuthorizeDevice approves or denies the device waiting for the user
+	// authorization with the user code. Approving the device requires the
+	// MFA code of the users with MFA enrolled.
+	AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) error
+
+	// DeviceToken issues the token of the user who approved the device. Until
+	// then, ErrAuthorizationPending or ErrSlowDown is returned, and once the
+	// device code expires, ErrDeviceCodeExpired.
+	DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error)
+
 	// OAuthCallback handles the callback from any supported OAuth provider.
 	// It processes the OAuth tokens and either signs in or signs up the user based on the provided state.
 	OAuthCallback(ctx context.Context, client clients.Client) (clients.Client, error)
@@ -88,3 +169,11 @@ type Service interface {
 	// OAuthAddClientPolicy adds a policy to the client for an OAuth request.
 	OAuthAddClientPolicy(ctx context.Context, client clients.Client) error
 }
+
+// RoleUpdate represents the result of the role update of a single client.
+// Err is set if the role of the client wasn't updated.
+type RoleUpdate struct {
+	ID     string
+	Client clients.Client
+	Err    error
+}
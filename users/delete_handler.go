@@ -16,15 +16,20 @@ import (
 
 	"github.com/absmach/magistrala"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/policies"
-	"github.com/absmach/magistrala/users/postgres"
 )
 
 const defLimit = uint64(100)
 
+var (
+	errDeleteUserDomains  = errors.New("failed to delete user from domains")
+	errDeleteUserPolicies = errors.New("failed to delete user policies")
+)
+
 type handler struct {
-	clients       postgres.Repository
+	clients       Repository
 	domains       magistrala.DomainsServiceClient
 	policies      policies.Service
 	checkInterval time.Duration
@@ -32,7 +37,7 @@ type handler struct {
 	logger        *slog.Logger
 }
 
-func NewDeleteHandler(ctx context.Context, clients postgres.Repository, policyService policies.Service, domainsClient magistrala.DomainsServiceClient, defCheckInterval, deleteAfter time.Duration, logger *slog.Logger) {
+func NewDeleteHandler(ctx context.Context, clients Repository, policyService policies.Service, domainsClient magistrala.DomainsServiceClient, defCheckInterval, deleteAfter time.Duration, logger *slog.Logger) {
 	handler := &handler{
 		clients:       clients,
 		domains:       domainsClient,
@@ -66,38 +71,20 @@ func (h *handler) handle(ctx context.Context) {
 			h.logger.Error("failed to retrieve users", slog.Any("error", err))
 			break
 		}
-		if dbUsers.Total == 0 {
+		if len(dbUsers.Clients) == 0 {
 			break
 		}
 
+		// The users which are not deleted stay in the list, so skip them
+		// when retrieving the next page.
 		for _, u := range dbUsers.Clients {
 			if time.Since(u.UpdatedAt) < h.deleteAfter {
+				pm.Offset++
 				continue
 			}
-
-			deletedRes, err := h.domains.DeleteUserFromDomains(ctx, &magistrala.DeleteUserReq{
-				Id: u.ID,
-			})
-			if err != nil {
-				h.logger.Error("failed to delete user from domains", slog.Any("error", err))
-				continue
-			}
-			if !deletedRes.Deleted {
-				h.logger.Error("failed to delete user from domains", slog.Any("error", svcerr.ErrAuthorization))
-				continue
-			}
-
-			req := policies.Policy{
-				Subject:     u.ID,
-				SubjectType: policies.UserType,
-			}
-			if err := h.policies.DeletePolicyFilter(ctx, req); err != nil {
-				h.logger.Error("failed to delete user policies", slog.Any("error", err))
-				continue
-			}
-
-			if err := h.clients.Delete(ctx, u.ID); err != nil {
-				h.logger.Error("failed to delete user", slog.Any("error", err))
+			if err := h.delete(ctx, u); err != nil {
+				h.logger.Error("failed to delete user", slog.String("id", u.ID), slog.Any("error", err))
+				pm.Offset++
 				continue
 			}
 
@@ -108,3 +95,25 @@ func (h *handler) handle(ctx context.Context) {
 		}
 	}
 }
+
+func (h *handler) delete(ctx context.Context, u mgclients.Client) error {
+	deletedRes, err := h.domains.DeleteUserFromDomains(ctx, &magistrala.DeleteUserReq{
+		Id: u.ID,
+	})
+	if err != nil {
+		return errors.Wrap(errDeleteUserDomains, err)
+	}
+	if !deletedRes.Deleted {
+		return errors.Wrap(errDeleteUserDomains, svcerr.ErrAuthorization)
+	}
+
+	req := policies.Policy{
+		Subject:     u.ID,
+		SubjectType: policies.UserType,
+	}
+	if err := h.policies.DeletePolicyFilter(ctx, req); err != nil {
+		return errors.Wrap(errDeleteUserPolicies, err)
+	}
+
+	return h.clients.Delete(ctx, u.ID)
+}
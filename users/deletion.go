@@ -0,0 +1,175 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+const (
+	// DefDeletionTokenTTL is the default validity period of the account deletion confirmation token.
+	DefDeletionTokenTTL = 24 * time.Hour
+
+	// DefDeletionCoolingOff is the default period during which a confirmed
+	// account deletion can be canceled.
+	DefDeletionCoolingOff = 30 * 24 * time.Hour
+)
+
+var (
+	errSelfDeletionDisabled = errors.New("account self-deletion is not enabled")
+	errDeletionIdentity     = errors.New("account self-deletion requires an e-mail identity")
+	errDeletionToken        = errors.New("invalid or expired account deletion token")
+	errDeletionConfirmed    = errors.New("account deletion is already confirmed")
+)
+
+// SelfDeletion defines how users delete their own accounts.
+type SelfDeletion struct {
+	// Enabled allows users to request the deletion of their own accounts.
+	Enabled bool
+
+	// TokenTTL is the validity period of the deletion confirmation token.
+	TokenTTL time.Duration
+
+	// CoolingOff is the period after the deletion is confirmed during which
+	// it can be canceled. It should match the period after which the delete
+	// handler purges deleted users.
+	CoolingOff time.Duration
+}
+
+func (svc service) RequestDeletion(ctx context.Context, session authn.Session) error {
+	if !svc.config.SelfDeletion.Enabled {
+		return errors.Wrap(svcerr.ErrAuthorization, errSelfDeletionDisabled)
+	}
+
+	cli, err := svc.clients.RetrieveByID(ctx, session.UserID)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	if IsPhone(cli.Credentials.Identity) {
+		return errors.Wrap(svcerr.ErrMalformedEntity, errDeletionIdentity)
+	}
+
+	token, err := generateIdentityToken()
+	if err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	now := time.Now()
+	pd := PendingDeletion{
+		ClientID:  cli.ID,
+		Token:     hashIdentityToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(svc.config.SelfDeletion.TokenTTL),
+	}
+	if err := svc.clients.SavePendingDeletion(ctx, pd); err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	if err := svc.email.SendDeletionConfirmation([]string{cli.Credentials.Identity}, cli.Name, token); err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (svc service) ConfirmDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	pd, err := svc.pendingDeletion(ctx, token)
+	if err != nil {
+		return mgclients.Client{}, err
+	}
+	if !pd.ConfirmedAt.IsZero() {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrConflict, errDeletionConfirmed)
+	}
+
+	prev, err := svc.clients.RetrieveByID(ctx, pd.ClientID)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	// Deleted users can't log in, and the auth service revokes the tokens
+	// issued to the user before the deletion once it receives the removal
+	// event. The delete handler purges the user once the cooling-off period
+	// since the update has passed.
+	now := time.Now()
+	cli := mgclients.Client{
+		ID:        pd.ClientID,
+		Status:    mgclients.DeletedStatus,
+		UpdatedAt: now,
+		UpdatedBy: pd.ClientID,
+	}
+	cli, err = svc.clients.ChangeStatus(ctx, cli)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	pd.ConfirmedAt = now
+	pd.PrevStatus = prev.Status
+	pd.ExpiresAt = now.Add(svc.config.SelfDeletion.CoolingOff)
+	if err := svc.clients.SavePendingDeletion(ctx, pd); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	return cli, nil
+}
+
+func (svc service) CancelDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	pd, err := svc.pendingDeletion(ctx, token)
+	if err != nil {
+		return mgclients.Client{}, err
+	}
+
+	var cli mgclients.Client
+	if pd.ConfirmedAt.IsZero() {
+		if cli, err = svc.clients.RetrieveByID(ctx, pd.ClientID); err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+		}
+	} else {
+		// Restore the status the user had before the deletion, so canceling
+		// the deletion doesn't enable a user disabled by the administrators.
+		cli = mgclients.Client{
+			ID:        pd.ClientID,
+			Status:    pd.PrevStatus,
+			UpdatedAt: time.Now(),
+			UpdatedBy: pd.ClientID,
+		}
+		if cli, err = svc.clients.ChangeStatus(ctx, cli); err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+		}
+	}
+	if err := svc.clients.RemovePendingDeletion(ctx, pd.ClientID); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+	cli.Credentials.Secret = ""
+
+	return cli, nil
+}
+
+// pendingDeletion retrieves the pending deletion identified by the token,
+// removing it if it has expired.
+func (svc service) pendingDeletion(ctx context.Context, token string) (PendingDeletion, error) {
+	if !svc.config.SelfDeletion.Enabled {
+		return PendingDeletion{}, errors.Wrap(svcerr.ErrAuthorization, errSelfDeletionDisabled)
+	}
+
+	pd, err := svc.clients.RetrievePendingDeletion(ctx, hashIdentityToken(token))
+	if err != nil {
+		return PendingDeletion{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(errDeletionToken, err))
+	}
+	if time.Now().After(pd.ExpiresAt) {
+		// Unconfirmed deletions are just dropped, while the confirmed ones
+		// are left to the delete handler, which removes them with the user.
+		if pd.ConfirmedAt.IsZero() {
+			if err := svc.clients.RemovePendingDeletion(ctx, pd.ClientID); err != nil {
+				return PendingDeletion{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+			}
+		}
+		return PendingDeletion{}, errors.Wrap(svcerr.ErrAuthentication, errDeletionToken)
+	}
+
+	return pd, nil
+}
@@ -0,0 +1,326 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/internal/testsutil"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newDeletionService(enabled bool) (users.Service, *authmocks.TokenServiceClient, *mocks.Repository, *mocks.Emailer) {
+	cRepo := new(mocks.Repository)
+	e := new(mocks.Emailer)
+	tokenClient := new(authmocks.TokenServiceClient)
+	cfg := users.Config{
+		SelfDeletion: users.SelfDeletion{
+			Enabled:    enabled,
+			TokenTTL:   time.Hour,
+			CoolingOff: 24 * time.Hour,
+		},
+	}
+
+	return users.NewService(tokenClient, cRepo, new(policymocks.Service), e, phasher, idProvider, cfg), tokenClient, cRepo, e
+}
+
+func TestRequestDeletion(t *testing.T) {
+	phoneClient := client
+	phoneClient.Credentials.Identity = phone
+
+	cases := []struct {
+		desc     string
+		enabled  bool
+		client   mgclients.Client
+		saveErr  error
+		emailErr error
+		err      error
+	}{
+		{
+			desc:    "request deletion",
+			enabled: true,
+			client:  client,
+		},
+		{
+			desc:   "request deletion with self-deletion disabled",
+			client: client,
+			err:    svcerr.ErrAuthorization,
+		},
+		{
+			desc:    "request deletion of client with phone identity",
+			enabled: true,
+			client:  phoneClient,
+			err:     svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:    "request deletion with failed save",
+			enabled: true,
+			client:  client,
+			saveErr: repoerr.ErrCreateEntity,
+			err:     svcerr.ErrCreateEntity,
+		},
+		{
+			desc:     "request deletion with failed e-mail",
+			enabled:  true,
+			client:   client,
+			emailErr: errors.New("smtp unavailable"),
+			err:      svcerr.ErrCreateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, _, cRepo, e := newDeletionService(tc.enabled)
+
+			var pending users.PendingDeletion
+			var token string
+			cRepo.On("RetrieveByID", context.Background(), tc.client.ID).Return(tc.client, nil)
+			cRepo.On("SavePendingDeletion", context.Background(), mock.Anything).Return(func(_ context.Context, pd users.PendingDeletion) error {
+				pending = pd
+				return tc.saveErr
+			})
+			e.On("SendDeletionConfirmation", []string{tc.client.Credentials.Identity}, tc.client.Name, mock.Anything).Return(func(_ []string, _, t string) error {
+				token = t
+				return tc.emailErr
+			})
+
+			err := svc.RequestDeletion(context.Background(), authn.Session{UserID: tc.client.ID})
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, tc.client.ID, pending.ClientID, fmt.Sprintf("%s: expected pending deletion of client %s got %s", tc.desc, tc.client.ID, pending.ClientID))
+				assert.NotEmpty(t, token, fmt.Sprintf("%s: expected deletion token to be sent", tc.desc))
+				assert.NotEqual(t, token, pending.Token, fmt.Sprintf("%s: expected only token hash to be stored", tc.desc))
+				assert.True(t, pending.ConfirmedAt.IsZero(), fmt.Sprintf("%s: expected unconfirmed deletion", tc.desc))
+				assert.WithinDuration(t, time.Now().Add(time.Hour), pending.ExpiresAt, time.Minute, fmt.Sprintf("%s: expected token to expire after TTL", tc.desc))
+			}
+		})
+	}
+}
+
+func TestConfirmAndCancelDeletion(t *testing.T) {
+	svc, tokenClient, cRepo, e := newDeletionService(true)
+
+	stored := client
+	var pending users.PendingDeletion
+	var token string
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(func(context.Context, string) (mgclients.Client, error) {
+		return stored, nil
+	})
+	cRepo.On("ChangeStatus", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		stored.Status = c.Status
+		stored.UpdatedAt = c.UpdatedAt
+		return stored, nil
+	})
+	cRepo.On("SavePendingDeletion", context.Background(), mock.Anything).Return(func(_ context.Context, pd users.PendingDeletion) error {
+		pending = pd
+		return nil
+	})
+	cRepo.On("RetrievePendingDeletion", context.Background(), mock.Anything).Return(func(_ context.Context, t string) (users.PendingDeletion, error) {
+		if pending.Token == "" || pending.Token != t {
+			return users.PendingDeletion{}, repoerr.ErrNotFound
+		}
+		return pending, nil
+	})
+	cRepo.On("RemovePendingDeletion", context.Background(), client.ID).Return(func(context.Context, string) error {
+		pending = users.PendingDeletion{}
+		return nil
+	})
+	e.On("SendDeletionConfirmation", []string{client.Credentials.Identity}, client.Name, mock.Anything).Return(func(_ []string, _, t string) error {
+		token = t
+		return nil
+	})
+	tokenClient.On("Refresh", context.Background(), mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, nil)
+	cRepo.On("RetrieveMFA", context.Background(), client.ID).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
+
+	session := authn.Session{UserID: client.ID}
+	err := svc.RequestDeletion(context.Background(), session)
+	assert.Nil(t, err, fmt.Sprintf("request deletion: unexpected error %s", err))
+	assert.Equal(t, mgclients.EnabledStatus, stored.Status, "request deletion: expected client to stay enabled until confirmed")
+
+	_, err = svc.ConfirmDeletion(context.Background(), "invalid")
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("confirm deletion with invalid token: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	cli, err := svc.ConfirmDeletion(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("confirm deletion: unexpected error %s", err))
+	assert.Equal(t, mgclients.DeletedStatus, cli.Status, fmt.Sprintf("confirm deletion: expected status %s got %s", mgclients.DeletedStatus, cli.Status))
+	assert.False(t, pending.ConfirmedAt.IsZero(), "confirm deletion: expected deletion to be confirmed")
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), pending.ExpiresAt, time.Minute, "confirm deletion: expected deletion to be cancelable during cooling-off period")
+
+	_, err = svc.RefreshToken(context.Background(), session, validToken)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("refresh token of deleted client: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	_, err = svc.ConfirmDeletion(context.Background(), token)
+	assert.True(t, errors.Contains(err, svcerr.ErrConflict), fmt.Sprintf("confirm deletion again: expected %s got %s", svcerr.ErrConflict, err))
+
+	cli, err = svc.CancelDeletion(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("cancel deletion within cooling-off period: unexpected error %s", err))
+	assert.Equal(t, mgclients.EnabledStatus, cli.Status, fmt.Sprintf("cancel deletion: expected status %s got %s", mgclients.EnabledStatus, cli.Status))
+	assert.Empty(t, pending.ClientID, "cancel deletion: expected pending deletion to be removed")
+
+	_, err = svc.RefreshToken(context.Background(), session, validToken)
+	assert.Nil(t, err, fmt.Sprintf("refresh token of restored client: unexpected error %s", err))
+
+	_, err = svc.CancelDeletion(context.Background(), token)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("cancel deletion with used token: expected %s got %s", svcerr.ErrAuthentication, err))
+}
+
+func TestCancelDeletionRestoresStatus(t *testing.T) {
+	svc, _, cRepo, e := newDeletionService(true)
+
+	stored := client
+	stored.Status = mgclients.DisabledStatus
+	var pending users.PendingDeletion
+	var token string
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(func(context.Context, string) (mgclients.Client, error) {
+		return stored, nil
+	})
+	cRepo.On("ChangeStatus", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		stored.Status = c.Status
+		return stored, nil
+	})
+	cRepo.On("SavePendingDeletion", context.Background(), mock.Anything).Return(func(_ context.Context, pd users.PendingDeletion) error {
+		pending = pd
+		return nil
+	})
+	cRepo.On("RetrievePendingDeletion", context.Background(), mock.Anything).Return(func(context.Context, string) (users.PendingDeletion, error) {
+		return pending, nil
+	})
+	cRepo.On("RemovePendingDeletion", context.Background(), client.ID).Return(nil)
+	e.On("SendDeletionConfirmation", []string{client.Credentials.Identity}, client.Name, mock.Anything).Return(func(_ []string, _, t string) error {
+		token = t
+		return nil
+	})
+
+	err := svc.RequestDeletion(context.Background(), authn.Session{UserID: client.ID})
+	assert.Nil(t, err, fmt.Sprintf("request deletion: unexpected error %s", err))
+
+	_, err = svc.ConfirmDeletion(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("confirm deletion: unexpected error %s", err))
+	assert.Equal(t, mgclients.DisabledStatus, pending.PrevStatus, fmt.Sprintf("confirm deletion: expected previous status %s got %s", mgclients.DisabledStatus, pending.PrevStatus))
+
+	cli, err := svc.CancelDeletion(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("cancel deletion: unexpected error %s", err))
+	assert.Equal(t, mgclients.DisabledStatus, cli.Status, fmt.Sprintf("cancel deletion: expected status %s got %s", mgclients.DisabledStatus, cli.Status))
+}
+
+func TestExpiredDeletion(t *testing.T) {
+	cases := []struct {
+		desc      string
+		confirmed bool
+		confirm   bool
+		removed   bool
+	}{
+		{
+			desc:    "confirm deletion with expired token",
+			confirm: true,
+			removed: true,
+		},
+		{
+			desc:    "cancel unconfirmed deletion with expired token",
+			removed: true,
+		},
+		{
+			desc:      "cancel deletion after cooling-off period",
+			confirmed: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, _, cRepo, _ := newDeletionService(true)
+
+			pending := users.PendingDeletion{
+				ClientID:  client.ID,
+				ExpiresAt: time.Now().Add(-time.Minute),
+			}
+			if tc.confirmed {
+				pending.ConfirmedAt = time.Now().Add(-25 * time.Hour)
+			}
+			cRepo.On("RetrievePendingDeletion", context.Background(), mock.Anything).Return(pending, nil)
+			cRepo.On("RemovePendingDeletion", context.Background(), client.ID).Return(nil)
+
+			cancelOrConfirm := svc.CancelDeletion
+			if tc.confirm {
+				cancelOrConfirm = svc.ConfirmDeletion
+			}
+			_, err := cancelOrConfirm(context.Background(), validToken)
+			assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("%s: expected %s got %s", tc.desc, svcerr.ErrAuthentication, err))
+			cRepo.AssertNotCalled(t, "ChangeStatus", context.Background(), mock.Anything)
+			if tc.removed {
+				cRepo.AssertCalled(t, "RemovePendingDeletion", context.Background(), client.ID)
+			} else {
+				cRepo.AssertNotCalled(t, "RemovePendingDeletion", context.Background(), client.ID)
+			}
+		})
+	}
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	policies := new(policymocks.Service)
+	domains := new(authmocks.DomainsServiceClient)
+
+	expired := mgclients.Client{ID: testsutil.GenerateUUID(t), Name: "expired", Status: mgclients.DeletedStatus, UpdatedAt: time.Now().Add(-2 * time.Hour)}
+	cooling := mgclients.Client{ID: testsutil.GenerateUUID(t), Name: "cooling", Status: mgclients.DeletedStatus, UpdatedAt: time.Now()}
+
+	var mu sync.Mutex
+	deleted := map[string]bool{}
+	cRepo.On("RetrieveAll", mock.Anything, mock.Anything).Return(func(_ context.Context, pm mgclients.Page) (mgclients.ClientsPage, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		var remaining []mgclients.Client
+		for _, c := range []mgclients.Client{expired, cooling} {
+			if !deleted[c.ID] {
+				remaining = append(remaining, c)
+			}
+		}
+		page := mgclients.ClientsPage{Page: mgclients.Page{Total: uint64(len(remaining))}}
+		if pm.Offset < uint64(len(remaining)) {
+			page.Clients = remaining[pm.Offset:]
+		}
+		return page, nil
+	})
+	cRepo.On("Delete", mock.Anything, mock.Anything).Return(func(_ context.Context, id string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deleted[id] = true
+		return nil
+	})
+	policies.On("DeletePolicyFilter", mock.Anything, mock.Anything).Return(nil)
+	domains.On("DeleteUserFromDomains", mock.Anything, mock.Anything).Return(&magistrala.DeleteUserRes{Deleted: true}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	users.NewDeleteHandler(ctx, cRepo, policies, domains, 10*time.Millisecond, time.Hour, mglog.NewMock())
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return deleted[expired.ID]
+	}, time.Second, 10*time.Millisecond, "expected user deleted before the cooling-off period to be purged")
+	// Let the handler run again, so the user within the cooling-off period
+	// is checked more than once.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.False(t, deleted[cooling.ID], "expected user within the cooling-off period not to be purged")
+}
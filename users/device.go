@@ -0,0 +1,275 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/absmach/magistrala"
+	mgauth "github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+const (
+	// DefDeviceCodeTTL is the default validity period of the device code.
+	DefDeviceCodeTTL = 10 * time.Minute
+
+	// DefDevicePollInterval is the default minimum time between two polls
+	// of the device token.
+	DefDevicePollInterval = 5 * time.Second
+
+	// deviceSlowDown is added to the polling interval of a device polling
+	// too fast, as in RFC 8628.
+	deviceSlowDown = 5 * time.Second
+
+	// The user code is typed by the user, so it's made of the consonants
+	// which can't be confused with each other or spell words.
+	userCodeChars  = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength = 8
+)
+
+var (
+	// ErrAuthorizationPending indicates that the user hasn't authorized the
+	// device yet, so the device should keep polling.
+	ErrAuthorizationPending = errors.New("device authorization is pending")
+
+	// ErrSlowDown indicates that the device polls faster than the polling
+	// interval, which is increased.
+	ErrSlowDown = errors.New("device polls too fast, slow down")
+
+	// ErrDeviceCodeExpired indicates that the device code expired before the
+	// user authorized the device.
+	ErrDeviceCodeExpired = errors.New("device code expired")
+
+	// ErrDeviceAccessDenied indicates that the user denied the device.
+	ErrDeviceAccessDenied = errors.New("device authorization denied")
+
+	// ErrInvalidDeviceCode indicates an unknown or already used device code.
+	ErrInvalidDeviceCode = errors.New("invalid device code")
+
+	errDeviceFlowDisabled = errors.New("device authorization flow is not enabled")
+	errDeviceAudience     = errors.New("audience is not allowed for the device tokens")
+	errUserCode           = errors.New("invalid or expired user code")
+	errDeviceAuthorized   = errors.New("device is already authorized")
+
+	maxUserCodeChar = big.NewInt(int64(len(userCodeChars)))
+)
+
+// DeviceFlow defines the OAuth2 device authorization flow, which logs in
+// the devices without a browser, such as the CLI on a headless machine.
+type DeviceFlow struct {
+	// Enabled allows the devices to request the device codes.
+	Enabled bool
+
+	// CodeTTL is the validity period of the device code.
+	CodeTTL time.Duration
+
+	// PollInterval is the minimum time between two polls of the device token.
+	PollInterval time.Duration
+
+	// VerificationURL is the page where the user enters the user code.
+	VerificationURL string
+
+	// Audiences are the audiences the devices may request the tokens for.
+	// The tokens without an audience are always allowed.
+	Audiences []string
+}
+
+// DeviceAuthorization is the device code of the device waiting for the user
+// to enter the user code at the verification URL.
+type DeviceAuthorization struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURL         string
+	VerificationURLComplete string
+	ExpiresIn               time.Duration
+	Interval                time.Duration
+}
+
+func (svc service) RequestDeviceCode(ctx context.Context, audience string) (DeviceAuthorization, error) {
+	cfg := svc.config.DeviceFlow
+	if !cfg.Enabled {
+		return DeviceAuthorization{}, errors.Wrap(svcerr.ErrAuthorization, errDeviceFlowDisabled)
+	}
+	if audience != "" && !slices.Contains(cfg.Audiences, audience) {
+		return DeviceAuthorization{}, errors.Wrap(svcerr.ErrMalformedEntity, errDeviceAudience)
+	}
+
+	// The devices which never poll again are purged along the new ones.
+	now := time.Now()
+	if err := svc.clients.RemoveExpiredPendingDevices(ctx, now); err != nil {
+		return DeviceAuthorization{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+
+	deviceCode, err := generateIdentityToken()
+	if err != nil {
+		return DeviceAuthorization{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return DeviceAuthorization{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	pd := PendingDevice{
+		DeviceCode: hashIdentityToken(deviceCode),
+		UserCode:   hashIdentityToken(normalizeUserCode(userCode)),
+		Audience:   audience,
+		Interval:   cfg.PollInterval,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(cfg.CodeTTL),
+	}
+	if err := svc.clients.SavePendingDevice(ctx, pd); err != nil {
+		return DeviceAuthorization{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+
+	da := DeviceAuthorization{
+		DeviceCode:      deviceCode,
+		UserCode:        userCode,
+		VerificationURL: cfg.VerificationURL,
+		ExpiresIn:       cfg.CodeTTL,
+		Interval:        cfg.PollInterval,
+	}
+	if cfg.VerificationURL != "" {
+		da.VerificationURLComplete = cfg.VerificationURL + "?user_code=" + url.QueryEscape(userCode)
+	}
+
+	return da, nil
+}
+
+func (svc service) AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) error {
+	if !svc.config.DeviceFlow.Enabled {
+		return errors.Wrap(svcerr.ErrAuthorization, errDeviceFlowDisabled)
+	}
+
+	pd, err := svc.clients.RetrievePendingDeviceByUserCode(ctx, hashIdentityToken(normalizeUserCode(userCode)))
+	if err != nil {
+		return errors.Wrap(svcerr.ErrMalformedEntity, errors.Wrap(errUserCode, err))
+	}
+	if time.Now().After(pd.ExpiresAt) {
+		return errors.Wrap(svcerr.ErrMalformedEntity, errUserCode)
+	}
+	if pd.ClientID != "" || pd.Denied {
+		return errors.Wrap(svcerr.ErrConflict, errDeviceAuthorized)
+	}
+
+	switch approve {
+	case true:
+		// The device gets a new token of the user, so the approval is
+		// verified like a login.
+		dbUser, err := svc.clients.RetrieveByID(ctx, session.UserID)
+		if err != nil {
+			return errors.Wrap(svcerr.ErrAuthentication, err)
+		}
+		if err := svc.verifyMFA(ctx, dbUser, mfaCode); err != nil {
+			return errors.Wrap(svcerr.ErrAuthentication, err)
+		}
+		pd.ClientID = session.UserID
+	default:
+		pd.Denied = true
+	}
+	if err := svc.clients.SavePendingDevice(ctx, pd); err != nil {
+		return errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	return nil
+}
+
+func (svc service) DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error) {
+	if !svc.config.DeviceFlow.Enabled {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthorization, errDeviceFlowDisabled)
+	}
+
+	pd, err := svc.clients.RetrievePendingDevice(ctx, hashIdentityToken(deviceCode))
+	if err != nil {
+		return &magistrala.Token{}, errors.Wrap(ErrInvalidDeviceCode, err)
+	}
+
+	now := time.Now()
+	switch {
+	case now.After(pd.ExpiresAt):
+		if err := svc.clients.RemovePendingDevice(ctx, pd.DeviceCode); err != nil {
+			return &magistrala.Token{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+		}
+		return &magistrala.Token{}, ErrDeviceCodeExpired
+	case pd.Denied:
+		if err := svc.clients.RemovePendingDevice(ctx, pd.DeviceCode); err != nil {
+			return &magistrala.Token{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+		}
+		return &magistrala.Token{}, ErrDeviceAccessDenied
+	case pd.ClientID == "":
+		tooFast := !pd.PolledAt.IsZero() && now.Sub(pd.PolledAt) < pd.Interval
+		if tooFast {
+			pd.Interval += deviceSlowDown
+		}
+		pd.PolledAt = now
+		if err := svc.clients.SavePendingDevice(ctx, pd); err != nil {
+			return &magistrala.Token{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+		}
+		if tooFast {
+			return &magistrala.Token{}, ErrSlowDown
+		}
+		return &magistrala.Token{}, ErrAuthorizationPending
+	}
+
+	// The device code is used once, so the token is issued only to the
+	// first poll after the authorization.
+	if err := svc.clients.RemovePendingDevice(ctx, pd.DeviceCode); err != nil {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+	dbUser, err := svc.clients.RetrieveByID(ctx, pd.ClientID)
+	if err != nil {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if dbUser.Status != mgclients.EnabledStatus {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, errLoginDisableUser)
+	}
+	// The code was verified by the login of the session that authorized
+	// the device, but the policy may require MFA since.
+	if _, err := svc.checkMFAEnrollment(ctx, dbUser); err != nil {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if err := svc.clients.UpdateLastLogin(ctx, dbUser.ID, now); err != nil {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	token, err := svc.token.Issue(ctx, &magistrala.IssueReq{UserId: dbUser.ID, Type: uint32(mgauth.AccessKey), Audience: pd.Audience, Claims: Claims(dbUser.Metadata)})
+	if err != nil {
+		return &magistrala.Token{}, errors.Wrap(errIssueToken, err)
+	}
+
+	return token, nil
+}
+
+// generateUserCode returns the random user code, formatted as two groups of
+// four characters for readability.
+func generateUserCode() (string, error) {
+	code := make([]byte, 0, userCodeLength+1)
+	for i := 0; i < userCodeLength; i++ {
+		if i == userCodeLength/2 {
+			code = append(code, '-')
+		}
+		n, err := rand.Int(rand.Reader, maxUserCodeChar)
+		if err != nil {
+			return "", err
+		}
+		code = append(code, userCodeChars[n.Int64()])
+	}
+
+	return string(code), nil
+}
+
+// normalizeUserCode returns the user code without the separators and in
+// upper case, so the code typed by the user matches however it's written.
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
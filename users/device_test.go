@@ -0,0 +1,257 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	mgauth "github.com/absmach/magistrala/auth"
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const verificationURL = "http://localhost:9095/device"
+
+func newDeviceService(enabled bool, codeTTL time.Duration) (users.Service, *authmocks.TokenServiceClient, *mocks.Repository, map[string]users.PendingDevice) {
+	cRepo := new(mocks.Repository)
+	tokenClient := new(authmocks.TokenServiceClient)
+	cfg := users.Config{
+		DeviceFlow: users.DeviceFlow{
+			Enabled:         enabled,
+			CodeTTL:         codeTTL,
+			PollInterval:    5 * time.Second,
+			VerificationURL: verificationURL,
+			Audiences:       []string{"cli"},
+		},
+	}
+
+	// The pending devices are kept in memory, by their device code.
+	pending := map[string]users.PendingDevice{}
+	cRepo.On("SavePendingDevice", context.Background(), mock.Anything).Return(func(_ context.Context, pd users.PendingDevice) error {
+		pending[pd.DeviceCode] = pd
+		return nil
+	})
+	cRepo.On("RetrievePendingDevice", context.Background(), mock.Anything).Return(func(_ context.Context, code string) (users.PendingDevice, error) {
+		pd, ok := pending[code]
+		if !ok {
+			return users.PendingDevice{}, repoerr.ErrNotFound
+		}
+		return pd, nil
+	})
+	cRepo.On("RetrievePendingDeviceByUserCode", context.Background(), mock.Anything).Return(func(_ context.Context, code string) (users.PendingDevice, error) {
+		for _, pd := range pending {
+			if pd.UserCode == code {
+				return pd, nil
+			}
+		}
+		return users.PendingDevice{}, repoerr.ErrNotFound
+	})
+	cRepo.On("RemovePendingDevice", context.Background(), mock.Anything).Return(func(_ context.Context, code string) error {
+		delete(pending, code)
+		return nil
+	})
+	cRepo.On("RemoveExpiredPendingDevices", context.Background(), mock.Anything).Return(func(_ context.Context, before time.Time) error {
+		for code, pd := range pending {
+			if pd.ExpiresAt.Before(before) {
+				delete(pending, code)
+			}
+		}
+		return nil
+	})
+	cRepo.On("RetrieveMFA", context.Background(), client.ID).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
+
+	return users.NewService(tokenClient, cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, cfg), tokenClient, cRepo, pending
+}
+
+func TestDeviceFlow(t *testing.T) {
+	svc, tokenClient, cRepo, pending := newDeviceService(true, time.Minute)
+
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(client, nil)
+	cRepo.On("UpdateLastLogin", context.Background(), client.ID, mock.Anything).Return(nil)
+	issueReq := &magistrala.IssueReq{UserId: client.ID, Type: uint32(mgauth.AccessKey), Audience: "cli"}
+	tokenClient.On("Issue", context.Background(), issueReq).Return(&magistrala.Token{AccessToken: validToken, RefreshToken: &validToken}, nil)
+
+	da, err := svc.RequestDeviceCode(context.Background(), "cli")
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+	assert.Regexp(t, "^[A-Z]{4}-[A-Z]{4}$", da.UserCode, "request device code: expected readable user code")
+	assert.Equal(t, verificationURL, da.VerificationURL, "request device code: expected verification URL")
+	assert.Equal(t, verificationURL+"?user_code="+da.UserCode, da.VerificationURLComplete, "request device code: expected complete verification URL")
+	assert.Equal(t, time.Minute, da.ExpiresIn, "request device code: expected code TTL")
+	assert.Equal(t, 5*time.Second, da.Interval, "request device code: expected polling interval")
+	require.Len(t, pending, 1, "request device code: expected pending device")
+	for code, pd := range pending {
+		assert.NotEqual(t, da.DeviceCode, code, "request device code: expected only device code hash to be stored")
+		assert.NotContains(t, []string{da.UserCode, strings.ReplaceAll(da.UserCode, "-", "")}, pd.UserCode, "request device code: expected only user code hash to be stored")
+	}
+
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, users.ErrAuthorizationPending), fmt.Sprintf("poll before authorization: expected %s got %s", users.ErrAuthorizationPending, err))
+
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, users.ErrSlowDown), fmt.Sprintf("poll too fast: expected %s got %s", users.ErrSlowDown, err))
+	for _, pd := range pending {
+		assert.Equal(t, 10*time.Second, pd.Interval, "poll too fast: expected polling interval to be increased")
+	}
+
+	// The device waits for the increased interval before polling again.
+	for code, pd := range pending {
+		pd.PolledAt = pd.PolledAt.Add(-pd.Interval)
+		pending[code] = pd
+	}
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, users.ErrAuthorizationPending), fmt.Sprintf("poll after interval: expected %s got %s", users.ErrAuthorizationPending, err))
+
+	session := authn.Session{UserID: client.ID}
+	err = svc.AuthorizeDevice(context.Background(), session, "BCDF-GHJK", "", true)
+	assert.True(t, errors.Contains(err, svcerr.ErrMalformedEntity), fmt.Sprintf("authorize device with unknown user code: expected %s got %s", svcerr.ErrMalformedEntity, err))
+
+	// The user code is accepted however the user types it.
+	typed := strings.ToLower(strings.ReplaceAll(da.UserCode, "-", " "))
+	err = svc.AuthorizeDevice(context.Background(), session, typed, "", true)
+	require.Nil(t, err, fmt.Sprintf("authorize device: unexpected error %s", err))
+
+	err = svc.AuthorizeDevice(context.Background(), session, da.UserCode, "", false)
+	assert.True(t, errors.Contains(err, svcerr.ErrConflict), fmt.Sprintf("authorize device again: expected %s got %s", svcerr.ErrConflict, err))
+
+	for code, pd := range pending {
+		pd.PolledAt = time.Time{}
+		pending[code] = pd
+	}
+	token, err := svc.DeviceToken(context.Background(), da.DeviceCode)
+	require.Nil(t, err, fmt.Sprintf("poll after authorization: unexpected error %s", err))
+	assert.Equal(t, validToken, token.GetAccessToken(), "poll after authorization: expected issued token")
+	assert.Empty(t, pending, "poll after authorization: expected pending device to be removed")
+	cRepo.AssertCalled(t, "UpdateLastLogin", context.Background(), client.ID, mock.Anything)
+
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, users.ErrInvalidDeviceCode), fmt.Sprintf("poll with used device code: expected %s got %s", users.ErrInvalidDeviceCode, err))
+	tokenClient.AssertNumberOfCalls(t, "Issue", 1)
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	svc, tokenClient, _, pending := newDeviceService(true, time.Minute)
+
+	da, err := svc.RequestDeviceCode(context.Background(), "")
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+
+	err = svc.AuthorizeDevice(context.Background(), authn.Session{UserID: client.ID}, da.UserCode, "", false)
+	require.Nil(t, err, fmt.Sprintf("deny device: unexpected error %s", err))
+
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, users.ErrDeviceAccessDenied), fmt.Sprintf("poll after denial: expected %s got %s", users.ErrDeviceAccessDenied, err))
+	assert.Empty(t, pending, "poll after denial: expected pending device to be removed")
+	tokenClient.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+}
+
+func TestDeviceFlowExpired(t *testing.T) {
+	svc, tokenClient, _, pending := newDeviceService(true, time.Nanosecond)
+
+	da, err := svc.RequestDeviceCode(context.Background(), "")
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+	time.Sleep(time.Millisecond)
+
+	err = svc.AuthorizeDevice(context.Background(), authn.Session{UserID: client.ID}, da.UserCode, "", true)
+	assert.True(t, errors.Contains(err, svcerr.ErrMalformedEntity), fmt.Sprintf("authorize device with expired user code: expected %s got %s", svcerr.ErrMalformedEntity, err))
+
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, users.ErrDeviceCodeExpired), fmt.Sprintf("poll with expired device code: expected %s got %s", users.ErrDeviceCodeExpired, err))
+	assert.Empty(t, pending, "poll with expired device code: expected pending device to be removed")
+	tokenClient.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+}
+
+func TestDeviceFlowDisabled(t *testing.T) {
+	svc, _, cRepo, _ := newDeviceService(false, time.Minute)
+
+	_, err := svc.RequestDeviceCode(context.Background(), "")
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("request device code: expected %s got %s", svcerr.ErrAuthorization, err))
+
+	err = svc.AuthorizeDevice(context.Background(), authn.Session{UserID: client.ID}, "BCDF-GHJK", "", true)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("authorize device: expected %s got %s", svcerr.ErrAuthorization, err))
+
+	_, err = svc.DeviceToken(context.Background(), validToken)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthorization), fmt.Sprintf("poll device token: expected %s got %s", svcerr.ErrAuthorization, err))
+	cRepo.AssertNotCalled(t, "SavePendingDevice", context.Background(), mock.Anything)
+}
+
+func TestDeviceTokenDisabledClient(t *testing.T) {
+	svc, tokenClient, cRepo, _ := newDeviceService(true, time.Minute)
+
+	disabled := client
+	disabled.Status = mgclients.DisabledStatus
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(disabled, nil)
+
+	da, err := svc.RequestDeviceCode(context.Background(), "")
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+	err = svc.AuthorizeDevice(context.Background(), authn.Session{UserID: client.ID}, da.UserCode, "", true)
+	require.Nil(t, err, fmt.Sprintf("authorize device: unexpected error %s", err))
+
+	_, err = svc.DeviceToken(context.Background(), da.DeviceCode)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("poll for disabled client: expected %s got %s", svcerr.ErrAuthentication, err))
+	tokenClient.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+}
+
+func TestRequestDeviceCodeAudience(t *testing.T) {
+	svc, _, cRepo, pending := newDeviceService(true, time.Minute)
+
+	_, err := svc.RequestDeviceCode(context.Background(), "unknown")
+	assert.True(t, errors.Contains(err, svcerr.ErrMalformedEntity), fmt.Sprintf("request device code for unknown audience: expected %s got %s", svcerr.ErrMalformedEntity, err))
+	assert.Empty(t, pending, "request device code for unknown audience: expected no pending device")
+	cRepo.AssertNotCalled(t, "SavePendingDevice", context.Background(), mock.Anything)
+}
+
+func TestRequestDeviceCodePurge(t *testing.T) {
+	svc, _, _, pending := newDeviceService(true, time.Minute)
+
+	expired := users.PendingDevice{DeviceCode: "expired", ExpiresAt: time.Now().Add(-time.Minute)}
+	pending[expired.DeviceCode] = expired
+
+	_, err := svc.RequestDeviceCode(context.Background(), "")
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+	assert.NotContains(t, pending, expired.DeviceCode, "request device code: expected expired pending device to be purged")
+	assert.Len(t, pending, 1, "request device code: expected new pending device")
+}
+
+func TestAuthorizeDeviceMFA(t *testing.T) {
+	svc, _, cRepo, pending := newDeviceService(true, time.Minute)
+
+	mfaUser := client
+	mfaUser.ID = testsutil.GenerateUUID(t)
+	cRepo.On("RetrieveByID", context.Background(), mfaUser.ID).Return(mfaUser, nil)
+	cRepo.On("RetrieveMFA", context.Background(), mfaUser.ID).Return(users.MFAEnrollment{ClientID: mfaUser.ID, Secret: mfaSecret, EnrolledAt: time.Now()}, nil)
+	cRepo.On("UseMFAStep", context.Background(), mfaUser.ID, mock.Anything).Return(nil)
+	session := authn.Session{UserID: mfaUser.ID}
+
+	da, err := svc.RequestDeviceCode(context.Background(), "")
+	require.Nil(t, err, fmt.Sprintf("request device code: unexpected error %s", err))
+
+	err = svc.AuthorizeDevice(context.Background(), session, da.UserCode, "", true)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("approve device without MFA code: expected %s got %s", svcerr.ErrAuthentication, err))
+	err = svc.AuthorizeDevice(context.Background(), session, da.UserCode, "000000", true)
+	assert.True(t, errors.Contains(err, svcerr.ErrMFACode), fmt.Sprintf("approve device with invalid MFA code: expected %s got %s", svcerr.ErrMFACode, err))
+	for _, pd := range pending {
+		assert.Empty(t, pd.ClientID, "approve device with invalid MFA code: expected device to stay pending")
+	}
+
+	err = svc.AuthorizeDevice(context.Background(), session, da.UserCode, totp(t, mfaSecret, time.Now()), true)
+	require.Nil(t, err, fmt.Sprintf("approve device with MFA code: unexpected error %s", err))
+	for _, pd := range pending {
+		assert.Equal(t, mfaUser.ID, pd.ClientID, "approve device with MFA code: expected device to be authorized")
+	}
+}
@@ -3,10 +3,65 @@
 
 package users
 
+import "time"
+
+// Names of the e-mail templates which can be previewed.
+const (
+	ResetTemplate                = "reset"
+	WelcomeTemplate              = "welcome"
+	IdentityConfirmationTemplate = "identity_confirmation"
+	IdentityChangedTemplate      = "identity_changed"
+	VerificationTemplate         = "verification"
+	DeletionConfirmationTemplate = "deletion_confirmation"
+	InactivityWarningTemplate    = "inactivity_warning"
+)
+
+// EmailData contains the values the e-mail template is rendered with.
+type EmailData struct {
+	User    string `json:"user,omitempty"`
+	Content string `json:"content,omitempty"`
+	Footer  string `json:"footer,omitempty"`
+}
+
+// EmailPreview contains the rendered e-mail.
+type EmailPreview struct {
+	Subject     string `json:"subject"`
+	ContentType string `json:"content_type"`
+	Body        string `json:"body"`
+}
+
 // Emailer wrapper around the email.
 //
 //go:generate mockery --name Emailer --output=./mocks --filename emailer.go --quiet --note "Copyright (c) Abstract Machines"
 type Emailer interface {
 	// SendPasswordReset sends an email to the user with a link to reset the password.
-	SendPasswordReset(To []string, host, user, token string) error
+	// The e-mail is customized by the branding, if any.
+	SendPasswordReset(To []string, host, user, token string, branding EmailBranding) error
+
+	// SendWelcome enqueues a welcome email for a newly registered user.
+	// Sending is asynchronous, so delivery failures are not reported.
+	SendWelcome(To []string, user string) error
+
+	// SendIdentityConfirmation sends an email to the new user address with
+	// a link to confirm the identity change.
+	SendIdentityConfirmation(To []string, user, token string) error
+
+	// SendVerification sends an email to the address of the newly
+	// registered user with a link to verify it.
+	SendVerification(To []string, user, token string) error
+
+	// SendIdentityChanged notifies the previous user address that the identity has been changed.
+	SendIdentityChanged(To []string, user, identity string) error
+
+	// SendDeletionConfirmation sends an email to the user with a link to
+	// confirm the account deletion.
+	SendDeletionConfirmation(To []string, user, token string) error
+
+	// SendInactivityWarning notifies the user that the account will be
+	// disabled at the given time unless the user logs in before.
+	SendInactivityWarning(To []string, user string, disableAt time.Time) error
+
+	// PreviewEmail renders the named e-mail template without sending it.
+	// Empty data values are replaced by sample values.
+	PreviewEmail(name string, data EmailData) (EmailPreview, error)
 }
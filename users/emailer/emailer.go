@@ -4,26 +4,288 @@
 package emailer
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/absmach/magistrala/internal/email"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/users"
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	resetSubject           = "Password Reset Request"
+	welcomeSubject         = "Welcome"
+	identityConfirmSubject = "E-mail Address Change Confirmation"
+	identityChangedSubject = "E-mail Address Changed"
+	verificationSubject    = "E-mail Address Verification"
+	deletionConfirmSubject = "Account Deletion Confirmation"
+	inactivitySubject      = "Account Inactivity"
+	identityConfirmHeader  = "We have received a request to change the e-mail address of your account to this address. To confirm the change, please click on the link below:"
+	identityChangedHeader  = "The e-mail address of your account has been changed to:"
+	verificationHeader     = "Thank you for registering. To verify the e-mail address of your account, please click on the link below:"
+	deletionConfirmHeader  = "We have received a request to delete your account. To confirm the deletion, please click on the link below:"
+	inactivityHeader       = "Your account has not been used for a long time. Unless you log in, it will be disabled on:"
+	queueSize              = 1000
+	maxRetries             = 5
+
+	sampleUser     = "John Doe"
+	sampleHost     = "https://example.com"
+	sampleToken    = "sample-token"
+	sampleIdentity = "john.doe@example.com"
+)
+
+var sampleDisableAt = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	errWelcomeDisabled  = errors.New("welcome e-mail template is not configured")
+	errQueueFull        = errors.New("welcome e-mail queue is full")
+	errIdentityDisabled = errors.New("identity confirmation e-mail template is not configured")
+	errDeletionDisabled = errors.New("deletion confirmation e-mail template is not configured")
+	errUnknownTemplate  = errors.New("unknown e-mail template")
 )
 
 var _ users.Emailer = (*emailer)(nil)
 
+type welcomeEmail struct {
+	to   []string
+	user string
+}
+
+// Templates contains the paths of the welcome, identity and deletion e-mail
+// templates. The e-mails are sent only if enabled, but their templates can be
+// previewed before they are enabled.
+type Templates struct {
+	Welcome         string
+	WelcomeEnabled  bool
+	Identity        string
+	IdentityEnabled bool
+	Deletion        string
+	DeletionEnabled bool
+}
+
 type emailer struct {
-	resetURL string
-	agent    *email.Agent
+	resetURL    string
+	confirmURL  string
+	deletionURL string
+	config      email.Config
+	templates   Templates
+	agent       *email.Agent
+	welcome     *email.Agent
+	identity    *email.Agent
+	deletion    *email.Agent
+	queue       chan welcomeEmail
+	logger      *slog.Logger
+	mu          sync.Mutex
+	branded     map[string]*email.Agent
 }
 
-// New creates new emailer utility.
-func New(url string, c *email.Config) (users.Emailer, error) {
+// New creates new emailer utility. If welcome e-mails are enabled, they are
+// sent in the background until the context is canceled. If identity e-mails
+// are enabled, the identity change confirmation link is generated using
+// confirmURL. Likewise, the account deletion confirmation link is generated
+// using deletionURL.
+func New(ctx context.Context, url string, c *email.Config, templates Templates, confirmURL, deletionURL string, logger *slog.Logger) (users.Emailer, error) {
 	e, err := email.New(c)
-	return &emailer{resetURL: url, agent: e}, err
+	em := &emailer{resetURL: url, confirmURL: confirmURL, deletionURL: deletionURL, config: *c, templates: templates, agent: e, logger: logger, branded: make(map[string]*email.Agent)}
+	if err != nil {
+		return em, err
+	}
+
+	if templates.DeletionEnabled {
+		dc := *c
+		dc.Template = templates.Deletion
+		d, err := email.New(&dc)
+		if err != nil {
+			return em, err
+		}
+		em.deletion = d
+	}
+
+	if templates.IdentityEnabled {
+		ic := *c
+		ic.Template = templates.Identity
+		i, err := email.New(&ic)
+		if err != nil {
+			return em, err
+		}
+		em.identity = i
+	}
+
+	if !templates.WelcomeEnabled {
+		return em, nil
+	}
+
+	wc := *c
+	wc.Template = templates.Welcome
+	w, err := email.New(&wc)
+	if err != nil {
+		return em, err
+	}
+	em.welcome = w
+	em.queue = make(chan welcomeEmail, queueSize)
+	go em.sendWelcomeEmails(ctx)
+
+	return em, nil
 }
 
-func (e *emailer) SendPasswordReset(to []string, host, user, token string) error {
+func (e *emailer) SendPasswordReset(to []string, host, user, token string, branding users.EmailBranding) error {
+	agent, err := e.resetAgent(branding.Template)
+	if err != nil {
+		return err
+	}
 	url := fmt.Sprintf("%s%s?token=%s", host, e.resetURL, token)
-	return e.agent.Send(to, "", "Password Reset Request", "", user, url, "")
+	b := email.Branding{FromName: branding.SenderName, ReplyTo: branding.ReplyTo, LogoURL: branding.LogoURL}
+	return agent.SendBranded(to, b, resetSubject, "", user, url, "")
+}
+
+// resetAgent returns the agent sending the password reset e-mails with the
+// given template, or the global agent if the template is empty. The agents
+// of the template overrides are created on first use.
+func (e *emailer) resetAgent(template string) (*email.Agent, error) {
+	if template == "" {
+		return e.agent, nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if a, ok := e.branded[template]; ok {
+		return a, nil
+	}
+	c := e.config
+	c.Template = template
+	a, err := email.New(&c)
+	if err != nil {
+		return nil, err
+	}
+	e.branded[template] = a
+
+	return a, nil
+}
+
+func (e *emailer) SendIdentityConfirmation(to []string, user, token string) error {
+	if e.identity == nil {
+		return errIdentityDisabled
+	}
+	url := fmt.Sprintf("%s?token=%s", e.confirmURL, token)
+	return e.identity.Send(to, "", identityConfirmSubject, identityConfirmHeader, user, url, "")
+}
+
+// SendVerification uses the identity template, since the verification link
+// is confirmed the same way as the identity change.
+func (e *emailer) SendVerification(to []string, user, token string) error {
+	if e.identity == nil {
+		return errIdentityDisabled
+	}
+	url := fmt.Sprintf("%s?token=%s", e.confirmURL, token)
+	return e.identity.Send(to, "", verificationSubject, verificationHeader, user, url, "")
+}
+
+func (e *emailer) SendIdentityChanged(to []string, user, identity string) error {
+	if e.identity == nil {
+		return errIdentityDisabled
+	}
+	return e.identity.Send(to, "", identityChangedSubject, identityChangedHeader, user, identity, "")
+}
+
+func (e *emailer) SendDeletionConfirmation(to []string, user, token string) error {
+	if e.deletion == nil {
+		return errDeletionDisabled
+	}
+	url := fmt.Sprintf("%s?token=%s", e.deletionURL, token)
+	return e.deletion.Send(to, "", deletionConfirmSubject, deletionConfirmHeader, user, url, "")
+}
+
+func (e *emailer) SendInactivityWarning(to []string, user string, disableAt time.Time) error {
+	return e.agent.Send(to, "", inactivitySubject, inactivityHeader, user, disableAt.UTC().Format(time.RFC1123), "")
+}
+
+func (e *emailer) SendWelcome(to []string, user string) error {
+	if e.queue == nil {
+		return errWelcomeDisabled
+	}
+	select {
+	case e.queue <- welcomeEmail{to: to, user: user}:
+		return nil
+	default:
+		e.logger.Warn(fmt.Sprintf("failed to enqueue welcome e-mail to %s: %s", strings.Join(to, ","), errQueueFull))
+		return errQueueFull
+	}
+}
+
+func (e *emailer) PreviewEmail(name string, data users.EmailData) (users.EmailPreview, error) {
+	c := e.config
+	var subject, header, content string
+	switch name {
+	case users.ResetTemplate:
+		subject = resetSubject
+		content = fmt.Sprintf("%s%s?token=%s", sampleHost, e.resetURL, sampleToken)
+	case users.WelcomeTemplate:
+		c.Template = e.templates.Welcome
+		subject = welcomeSubject
+	case users.IdentityConfirmationTemplate:
+		c.Template = e.templates.Identity
+		subject = identityConfirmSubject
+		header = identityConfirmHeader
+		content = fmt.Sprintf("%s?token=%s", e.confirmURL, sampleToken)
+	case users.VerificationTemplate:
+		c.Template = e.templates.Identity
+		subject = verificationSubject
+		header = verificationHeader
+		content = fmt.Sprintf("%s?token=%s", e.confirmURL, sampleToken)
+	case users.IdentityChangedTemplate:
+		c.Template = e.templates.Identity
+		subject = identityChangedSubject
+		header = identityChangedHeader
+		content = sampleIdentity
+	case users.DeletionConfirmationTemplate:
+		c.Template = e.templates.Deletion
+		subject = deletionConfirmSubject
+		header = deletionConfirmHeader
+		content = fmt.Sprintf("%s?token=%s", e.deletionURL, sampleToken)
+	case users.InactivityWarningTemplate:
+		subject = inactivitySubject
+		header = inactivityHeader
+		content = sampleDisableAt.Format(time.RFC1123)
+	default:
+		return users.EmailPreview{}, errors.Wrap(svcerr.ErrNotFound, errors.Wrap(errUnknownTemplate, fmt.Errorf("template %q", name)))
+	}
+	user := sampleUser
+	if data.User != "" {
+		user = data.User
+	}
+	if data.Content != "" {
+		content = data.Content
+	}
+
+	body, err := email.Render(&c, []string{sampleIdentity}, "", subject, header, user, content, data.Footer)
+	if err != nil {
+		return users.EmailPreview{}, err
+	}
+
+	return users.EmailPreview{Subject: subject, ContentType: email.ContentType, Body: body}, nil
+}
+
+func (e *emailer) sendWelcomeEmails(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.queue:
+			send := func() error {
+				return e.welcome.Send(msg.to, "", welcomeSubject, "", msg.user, "", "")
+			}
+			notify := func(err error, next time.Duration) {
+				e.logger.Warn(fmt.Sprintf("failed to send welcome e-mail to %s, retrying in %s: %s", strings.Join(msg.to, ","), next, err))
+			}
+			bo := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries), ctx)
+			if err := backoff.RetryNotify(send, bo, notify); err != nil {
+				e.logger.Error(fmt.Sprintf("failed to send welcome e-mail to %s: %s", strings.Join(msg.to, ","), err))
+			}
+		}
+	}
 }
@@ -0,0 +1,265 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package emailer_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/email"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/emailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	resetURL    = "/password/reset"
+	confirmURL  = "http://localhost/users/identity/confirm"
+	deletionURL = "http://localhost/users/deletion/confirm"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.Nil(t, err, fmt.Sprintf("writing template expected to succeed: %s", err))
+
+	return path
+}
+
+func TestPreviewEmail(t *testing.T) {
+	dir := t.TempDir()
+	identity := writeTemplate(t, dir, "identity.tmpl", "Dear {{.User}},\n{{.Header}}\n{{.Content}}\n{{.Footer}}")
+	badWelcome := writeTemplate(t, dir, "welcome.tmpl", "Dear {{.User}\n")
+	cfg := &email.Config{
+		Port:     "25",
+		Template: writeTemplate(t, dir, "email.tmpl", "Dear {{.User}}, reset your password at {{.Content}}"),
+	}
+	templates := emailer.Templates{
+		Welcome:  badWelcome,
+		Identity: identity,
+		Deletion: identity,
+	}
+
+	em, err := emailer.New(context.Background(), resetURL, cfg, templates, confirmURL, deletionURL, mglog.NewMock())
+	require.Nil(t, err, fmt.Sprintf("creating emailer expected to succeed: %s", err))
+
+	cases := []struct {
+		desc     string
+		template string
+		data     users.EmailData
+		preview  users.EmailPreview
+		err      error
+	}{
+		{
+			desc:     "preview reset e-mail with sample data",
+			template: users.ResetTemplate,
+			preview: users.EmailPreview{
+				Subject:     "Password Reset Request",
+				ContentType: "text/plain",
+				Body:        "Dear John Doe, reset your password at https://example.com/password/reset?token=sample-token",
+			},
+		},
+		{
+			desc:     "preview identity confirmation e-mail with supplied data",
+			template: users.IdentityConfirmationTemplate,
+			data:     users.EmailData{User: "Jane", Content: "https://confirm", Footer: "Magistrala"},
+			preview: users.EmailPreview{
+				Subject:     "E-mail Address Change Confirmation",
+				ContentType: "text/plain",
+				Body:        "Dear Jane,\nWe have received a request to change the e-mail address of your account to this address. To confirm the change, please click on the link below:\nhttps://confirm\nMagistrala",
+			},
+		},
+		{
+			desc:     "preview disabled identity changed e-mail",
+			template: users.IdentityChangedTemplate,
+			preview: users.EmailPreview{
+				Subject:     "E-mail Address Changed",
+				ContentType: "text/plain",
+				Body:        "Dear John Doe,\nThe e-mail address of your account has been changed to:\njohn.doe@example.com\n",
+			},
+		},
+		{
+			desc:     "preview disabled deletion confirmation e-mail",
+			template: users.DeletionConfirmationTemplate,
+			preview: users.EmailPreview{
+				Subject:     "Account Deletion Confirmation",
+				ContentType: "text/plain",
+				Body:        "Dear John Doe,\nWe have received a request to delete your account. To confirm the deletion, please click on the link below:\nhttp://localhost/users/deletion/confirm?token=sample-token\n",
+			},
+		},
+		{
+			desc:     "preview inactivity warning e-mail with supplied data",
+			template: users.InactivityWarningTemplate,
+			data:     users.EmailData{User: "Jane", Content: "tomorrow"},
+			preview: users.EmailPreview{
+				Subject:     "Account Inactivity",
+				ContentType: "text/plain",
+				Body:        "Dear Jane, reset your password at tomorrow",
+			},
+		},
+		{
+			desc:     "preview template with parse error",
+			template: users.WelcomeTemplate,
+			err:      email.ErrParseTemplate,
+		},
+		{
+			desc:     "preview unknown template",
+			template: "unknown",
+			err:      svcerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			preview, err := em.PreviewEmail(tc.template, tc.data)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			assert.Equal(t, tc.preview, preview, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.preview, preview))
+		})
+	}
+}
+
+func TestPreviewEmailExecError(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &email.Config{
+		Port:     "25",
+		Template: writeTemplate(t, dir, "email.tmpl", "Dear {{.User}}"),
+	}
+	templates := emailer.Templates{
+		Welcome: writeTemplate(t, dir, "welcome.tmpl", "Dear {{.Name}}"),
+	}
+
+	em, err := emailer.New(context.Background(), resetURL, cfg, templates, confirmURL, deletionURL, mglog.NewMock())
+	require.Nil(t, err, fmt.Sprintf("creating emailer expected to succeed: %s", err))
+
+	_, err = em.PreviewEmail(users.WelcomeTemplate, users.EmailData{})
+	assert.True(t, errors.Contains(err, email.ErrExecTemplate), fmt.Sprintf("expected %s got %s", email.ErrExecTemplate, err))
+	assert.Contains(t, err.Error(), "Name", "expected error to name the missing variable")
+}
+
+// serveSMTP accepts a single SMTP session on a local port and sends the
+// received message to the returned channel.
+func serveSMTP(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, fmt.Sprintf("listening expected to succeed: %s", err))
+	t.Cleanup(func() { l.Close() })
+
+	msgs := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { fmt.Fprintf(conn, "%s\r\n", s) }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				msgs <- data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.Nil(t, err, fmt.Sprintf("parsing address expected to succeed: %s", err))
+
+	return port, msgs
+}
+
+func TestSendPasswordReset(t *testing.T) {
+	dir := t.TempDir()
+	defaultTmpl := writeTemplate(t, dir, "email.tmpl", "Dear {{.User}}, reset your password at {{.Content}}")
+	brandedTmpl := writeTemplate(t, dir, "branded.tmpl", "<{{.Logo}}> Dear {{.User}}, reset your Acme password at {{.Content}}")
+
+	cases := []struct {
+		desc     string
+		branding users.EmailBranding
+		headers  []string
+		body     string
+	}{
+		{
+			desc:    "send reset e-mail without branding",
+			headers: []string{"From: \"Magistrala\" <noreply@example.com>"},
+			body:    "Dear John, reset your password at http://host/password/reset?token=token",
+		},
+		{
+			desc: "send reset e-mail with branding",
+			branding: users.EmailBranding{
+				SenderName: "Acme",
+				ReplyTo:    "support@acme.com",
+				LogoURL:    "https://acme.com/logo.png",
+				Template:   brandedTmpl,
+			},
+			headers: []string{"From: \"Acme\" <noreply@example.com>", "Reply-To: support@acme.com"},
+			body:    "<https://acme.com/logo.png> Dear John, reset your Acme password at http://host/password/reset?token=token",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			port, msgs := serveSMTP(t)
+			cfg := &email.Config{
+				Host:        "127.0.0.1",
+				Port:        port,
+				FromAddress: "noreply@example.com",
+				FromName:    "Magistrala",
+				Template:    defaultTmpl,
+			}
+			em, err := emailer.New(context.Background(), resetURL, cfg, emailer.Templates{}, confirmURL, deletionURL, mglog.NewMock())
+			require.Nil(t, err, fmt.Sprintf("creating emailer expected to succeed: %s", err))
+
+			err = em.SendPasswordReset([]string{"john@example.com"}, "http://host", "John", "token", tc.branding)
+			require.Nil(t, err, fmt.Sprintf("%s: sending e-mail expected to succeed: %s", tc.desc, err))
+
+			header, body, _ := strings.Cut(<-msgs, "\r\n\r\n")
+			for _, h := range tc.headers {
+				assert.Contains(t, header, h, fmt.Sprintf("%s: expected header %q", tc.desc, h))
+			}
+			if tc.branding.ReplyTo == "" {
+				assert.NotContains(t, header, "Reply-To", fmt.Sprintf("%s: expected no reply-to header", tc.desc))
+			}
+			decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+			require.Nil(t, err, fmt.Sprintf("%s: decoding body expected to succeed: %s", tc.desc, err))
+			assert.Equal(t, tc.body, strings.TrimSpace(string(decoded)), fmt.Sprintf("%s: expected body %q", tc.desc, tc.body))
+		})
+	}
+}
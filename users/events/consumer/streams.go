@@ -0,0 +1,245 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/events"
+	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+const (
+	domainPrefix   = "domain."
+	domainCreate   = domainPrefix + "create"
+	domainAssign   = domainPrefix + "assign"
+	domainUnassign = domainPrefix + "unassign"
+
+	// groupsLimit is the number of the groups whose names contain the
+	// template group name retrieved while looking the group up.
+	groupsLimit = 100
+)
+
+var (
+	errInvalidTemplate = errors.New("invalid domain groups template")
+	errCreateGroup     = errors.New("failed to create domain group")
+	errRollbackGroups  = errors.New("failed to remove created domain groups")
+)
+
+// roles are the domain relations the template groups members are seeded from.
+var roles = map[string]bool{
+	policies.AdministratorRelation: true,
+	policies.EditorRelation:        true,
+	policies.ContributorRelation:   true,
+	policies.MemberRelation:        true,
+	policies.GuestRelation:         true,
+}
+
+// Group is a group created in every new domain. The users assigned to the
+// domain with the role relation become the members of the group.
+type Group struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Role        string                 `json:"role,omitempty"`
+}
+
+// ParseTemplate parses the JSON array of the groups created in every new
+// domain. Group names must be unique.
+func ParseTemplate(s string) ([]Group, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var template []Group
+	if err := json.Unmarshal([]byte(s), &template); err != nil {
+		return nil, errors.Wrap(errInvalidTemplate, err)
+	}
+	names := make(map[string]bool, len(template))
+	for _, g := range template {
+		if g.Name == "" {
+			return nil, errors.Wrap(errInvalidTemplate, fmt.Errorf("missing group name"))
+		}
+		if names[g.Name] {
+			return nil, errors.Wrap(errInvalidTemplate, fmt.Errorf("duplicate group name %q", g.Name))
+		}
+		if g.Role != "" && !roles[g.Role] {
+			return nil, errors.Wrap(errInvalidTemplate, fmt.Errorf("invalid role %q of group %q", g.Role, g.Name))
+		}
+		names[g.Name] = true
+	}
+
+	return template, nil
+}
+
+type eventHandler struct {
+	svc      groups.Service
+	repo     groups.Repository
+	template []Group
+}
+
+// NewEventHandler returns new event store handler, which creates the
+// template groups in the new domains and assigns the domain users to them.
+func NewEventHandler(svc groups.Service, repo groups.Repository, template []Group) events.EventHandler {
+	return &eventHandler{
+		svc:      svc,
+		repo:     repo,
+		template: template,
+	}
+}
+
+func (es *eventHandler) Handle(ctx context.Context, event events.Event) error {
+	if len(es.template) == 0 {
+		return nil
+	}
+	msg, err := event.Encode()
+	if err != nil {
+		return err
+	}
+
+	switch msg["operation"] {
+	case domainCreate:
+		return es.createGroups(ctx, events.Read(msg, "id", ""), events.Read(msg, "created_by", ""))
+	case domainAssign:
+		return es.assign(ctx, events.Read(msg, "domain_id", ""), events.Read(msg, "relation", ""), events.ReadStringSlice(msg, "user_ids")...)
+	case domainUnassign:
+		return es.unassign(ctx, events.Read(msg, "domain_id", ""), events.Read(msg, "user_id", ""))
+	}
+
+	return nil
+}
+
+// createGroups creates the template groups on behalf of the domain creator,
+// who becomes the administrator of the groups. Either all the groups are
+// created, or the ones created so far are removed.
+func (es *eventHandler) createGroups(ctx context.Context, domainID, userID string) error {
+	if domainID == "" || userID == "" {
+		return svcerr.ErrMalformedEntity
+	}
+	session := newSession(domainID, userID)
+	existing, err := es.retrieveGroups(ctx, domainID)
+	if err != nil {
+		return errors.Wrap(errCreateGroup, err)
+	}
+
+	var created []string
+	for _, g := range es.template {
+		// The event may be delivered again after a partial failure.
+		if _, ok := existing[g.Name]; ok {
+			continue
+		}
+		group := groups.Group{
+			Name:        g.Name,
+			Description: g.Description,
+			Metadata:    g.Metadata,
+			Status:      mgclients.EnabledStatus,
+		}
+		saved, err := es.svc.CreateGroup(ctx, session, policies.NewGroupKind, group)
+		if err != nil {
+			err = errors.Wrap(errCreateGroup, err)
+			for i := len(created) - 1; i >= 0; i-- {
+				if errRollback := es.svc.DeleteGroup(ctx, session, created[i]); errRollback != nil {
+					err = errors.Wrap(err, errors.Wrap(errRollbackGroups, errRollback))
+				}
+			}
+			return err
+		}
+		created = append(created, saved.ID)
+		existing[g.Name] = saved.ID
+	}
+
+	// Auth doesn't publish the assignment of the domain creator, who is the
+	// domain administrator.
+	return es.assignGroups(ctx, domainID, existing, policies.AdministratorRelation, userID)
+}
+
+// assign adds the users to the template groups seeded from the relation the
+// users are assigned to the domain with.
+func (es *eventHandler) assign(ctx context.Context, domainID, relation string, userIDs ...string) error {
+	if domainID == "" || len(userIDs) == 0 {
+		return svcerr.ErrMalformedEntity
+	}
+	existing, err := es.retrieveGroups(ctx, domainID)
+	if err != nil {
+		return err
+	}
+
+	return es.assignGroups(ctx, domainID, existing, relation, userIDs...)
+}
+
+func (es *eventHandler) assignGroups(ctx context.Context, domainID string, ids map[string]string, relation string, userIDs ...string) error {
+	for _, g := range es.template {
+		id, ok := ids[g.Name]
+		if !ok || g.Role != relation {
+			continue
+		}
+		if err := es.svc.Assign(ctx, newSession(domainID, ""), id, policies.MemberRelation, policies.UsersKind, userIDs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unassign removes the user removed from the domain from the template groups.
+func (es *eventHandler) unassign(ctx context.Context, domainID, userID string) error {
+	if domainID == "" || userID == "" {
+		return svcerr.ErrMalformedEntity
+	}
+	existing, err := es.retrieveGroups(ctx, domainID)
+	if err != nil {
+		return err
+	}
+	for _, g := range es.template {
+		id, ok := existing[g.Name]
+		if !ok || g.Role == "" {
+			continue
+		}
+		if err := es.svc.Unassign(ctx, newSession(domainID, ""), id, policies.MemberRelation, policies.UsersKind, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retrieveGroups returns the IDs of the template groups of the domain by the
+// group names.
+func (es *eventHandler) retrieveGroups(ctx context.Context, domainID string) (map[string]string, error) {
+	ids := make(map[string]string, len(es.template))
+	for _, g := range es.template {
+		page, err := es.repo.RetrieveAll(ctx, groups.Page{PageMeta: groups.PageMeta{
+			Name:     g.Name,
+			DomainID: domainID,
+			Limit:    groupsLimit,
+		}})
+		if err != nil {
+			return nil, err
+		}
+		// The name filter matches the names containing it.
+		for _, gr := range page.Groups {
+			if gr.Name == g.Name {
+				ids[g.Name] = gr.ID
+				break
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+func newSession(domainID, userID string) authn.Session {
+	return authn.Session{
+		UserID:       userID,
+		DomainID:     domainID,
+		DomainUserID: auth.EncodeDomainUserID(domainID, userID),
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package consumer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/groups"
+	"github.com/absmach/magistrala/pkg/groups/mocks"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/users/events/consumer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	domainID = "domainID"
+	userID   = "userID"
+)
+
+var (
+	errCreateGroup    = errors.New("failed to create domain group")
+	errRollbackGroups = errors.New("failed to remove created domain groups")
+	template          = []consumer.Group{
+		{Name: "admins", Description: "Domain administrators", Role: policies.AdministratorRelation},
+		{Name: "operators", Role: policies.EditorRelation},
+		{Name: "viewers", Metadata: map[string]interface{}{"role": "viewer"}},
+	}
+)
+
+type testEvent map[string]interface{}
+
+func (te testEvent) Encode() (map[string]interface{}, error) {
+	return te, nil
+}
+
+func TestParseTemplate(t *testing.T) {
+	cases := []struct {
+		desc     string
+		template string
+		groups   []consumer.Group
+		err      bool
+	}{
+		{
+			desc:     "parse empty template",
+			template: "",
+			groups:   nil,
+		},
+		{
+			desc:     "parse valid template",
+			template: `[{"name":"admins","description":"Domain administrators","role":"administrator"},{"name":"operators","role":"editor"},{"name":"viewers","metadata":{"role":"viewer"}}]`,
+			groups:   template,
+		},
+		{
+			desc:     "parse malformed template",
+			template: `{"name":"admins"}`,
+			err:      true,
+		},
+		{
+			desc:     "parse template with missing group name",
+			template: `[{"description":"Domain administrators"}]`,
+			err:      true,
+		},
+		{
+			desc:     "parse template with duplicate group name",
+			template: `[{"name":"admins"},{"name":"admins"}]`,
+			err:      true,
+		},
+		{
+			desc:     "parse template with invalid role",
+			template: `[{"name":"admins","role":"owner"}]`,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			groups, err := consumer.ParseTemplate(tc.template)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s\n", tc.desc, tc.err, err))
+			if !tc.err {
+				assert.Equal(t, tc.groups, groups)
+			}
+		})
+	}
+}
+
+func TestHandleDomainCreate(t *testing.T) {
+	cases := []struct {
+		desc      string
+		event     testEvent
+		existing  []groups.Group
+		createErr error
+		deleteErr error
+		created   int
+		err       error
+	}{
+		{
+			desc:    "create domain groups successfully",
+			event:   testEvent{"operation": "domain.create", "id": domainID, "created_by": userID},
+			created: len(template),
+		},
+		{
+			desc:     "create domain groups with already created group",
+			event:    testEvent{"operation": "domain.create", "id": domainID, "created_by": userID},
+			existing: []groups.Group{{ID: "admins", Name: "admins"}},
+			created:  len(template) - 1,
+		},
+		{
+			desc:  "create domain groups with missing creator",
+			event: testEvent{"operation": "domain.create", "id": domainID},
+			err:   svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:      "create domain groups with failed group creation",
+			event:     testEvent{"operation": "domain.create", "id": domainID, "created_by": userID},
+			createErr: svcerr.ErrCreateEntity,
+			created:   1,
+			err:       errCreateGroup,
+		},
+		{
+			desc:      "create domain groups with failed rollback",
+			event:     testEvent{"operation": "domain.create", "id": domainID, "created_by": userID},
+			createErr: svcerr.ErrCreateEntity,
+			deleteErr: repoerr.ErrRemoveEntity,
+			created:   1,
+			err:       errRollbackGroups,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := new(mocks.Service)
+			repo := new(mocks.Repository)
+			handler := consumer.NewEventHandler(svc, repo, template)
+
+			created := 0
+			repo.On("RetrieveAll", mock.Anything, mock.Anything).Return(func(_ context.Context, pm groups.Page) groups.Page {
+				var page groups.Page
+				for _, g := range tc.existing {
+					if g.Name == pm.Name {
+						page.Groups = append(page.Groups, g)
+					}
+				}
+				return page
+			}, nil)
+			svcCall := svc.On("CreateGroup", mock.Anything, mock.MatchedBy(func(s authn.Session) bool {
+				return s.UserID == userID && s.DomainID == domainID && s.DomainUserID == domainID+"_"+userID
+			}), policies.NewGroupKind, mock.Anything).Return(func(_ context.Context, _ authn.Session, _ string, g groups.Group) groups.Group {
+				created++
+				g.ID = g.Name
+				return g
+			}, func(context.Context, authn.Session, string, groups.Group) error {
+				if tc.createErr != nil && created > 1 {
+					return tc.createErr
+				}
+				return nil
+			})
+			svcCall1 := svc.On("DeleteGroup", mock.Anything, mock.Anything, mock.Anything).Return(tc.deleteErr)
+			svcCall2 := svc.On("Assign", mock.Anything, mock.Anything, "admins", policies.MemberRelation, policies.UsersKind, []string{userID}).Return(nil)
+
+			err := handler.Handle(context.Background(), tc.event)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			switch {
+			case tc.err == nil:
+				svcCall.Parent.AssertNumberOfCalls(t, "CreateGroup", tc.created)
+				svcCall2.Parent.AssertCalled(t, "Assign", mock.Anything, mock.Anything, "admins", policies.MemberRelation, policies.UsersKind, []string{userID})
+			case tc.createErr != nil:
+				svcCall1.Parent.AssertCalled(t, "DeleteGroup", mock.Anything, mock.Anything, "admins")
+				svcCall2.Parent.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestHandleDomainAssign(t *testing.T) {
+	existing := []groups.Group{
+		{ID: "adminsID", Name: "admins"},
+		{ID: "operatorsID", Name: "operators"},
+		{ID: "viewersID", Name: "viewers"},
+	}
+
+	cases := []struct {
+		desc     string
+		event    testEvent
+		assigned string
+		err      error
+	}{
+		{
+			desc:     "assign editors to the template group",
+			event:    testEvent{"operation": "domain.assign", "domain_id": domainID, "relation": policies.EditorRelation, "user_ids": []interface{}{userID}},
+			assigned: "operatorsID",
+		},
+		{
+			desc:  "assign members without template group",
+			event: testEvent{"operation": "domain.assign", "domain_id": domainID, "relation": policies.MemberRelation, "user_ids": []interface{}{userID}},
+		},
+		{
+			desc:  "assign without users",
+			event: testEvent{"operation": "domain.assign", "domain_id": domainID, "relation": policies.EditorRelation},
+			err:   svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := new(mocks.Service)
+			repo := new(mocks.Repository)
+			handler := consumer.NewEventHandler(svc, repo, template)
+
+			repo.On("RetrieveAll", mock.Anything, mock.Anything).Return(groups.Page{Groups: existing}, nil)
+			svcCall := svc.On("Assign", mock.Anything, mock.Anything, mock.Anything, policies.MemberRelation, policies.UsersKind, []string{userID}).Return(nil)
+
+			err := handler.Handle(context.Background(), tc.event)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			switch tc.assigned {
+			case "":
+				svcCall.Parent.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			default:
+				svcCall.Parent.AssertNumberOfCalls(t, "Assign", 1)
+				svcCall.Parent.AssertCalled(t, "Assign", mock.Anything, mock.Anything, tc.assigned, policies.MemberRelation, policies.UsersKind, []string{userID})
+			}
+		})
+	}
+}
+
+func TestHandleDomainUnassign(t *testing.T) {
+	svc := new(mocks.Service)
+	repo := new(mocks.Repository)
+	handler := consumer.NewEventHandler(svc, repo, template)
+
+	repo.On("RetrieveAll", mock.Anything, mock.Anything).Return(groups.Page{Groups: []groups.Group{
+		{ID: "adminsID", Name: "admins"},
+		{ID: "operatorsID", Name: "operators"},
+		{ID: "viewersID", Name: "viewers"},
+	}}, nil)
+	svcCall := svc.On("Unassign", mock.Anything, mock.Anything, mock.Anything, policies.MemberRelation, policies.UsersKind, []string{userID}).Return(nil)
+
+	err := handler.Handle(context.Background(), testEvent{"operation": "domain.unassign", "domain_id": domainID, "user_id": userID})
+	assert.Nil(t, err, fmt.Sprintf("unassign user: unexpected error %s", err))
+	svcCall.Parent.AssertNumberOfCalls(t, "Unassign", 2)
+	svcCall.Parent.AssertNotCalled(t, "Unassign", mock.Anything, mock.Anything, "viewersID", mock.Anything, mock.Anything, mock.Anything)
+}
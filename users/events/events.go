@@ -15,15 +15,22 @@ const (
 	clientCreate       = clientPrefix + "create"
 	clientUpdate       = clientPrefix + "update"
 	clientRemove       = clientPrefix + "remove"
+	disableInactive    = clientPrefix + "disable_inactive"
 	clientView         = clientPrefix + "view"
 	profileView        = clientPrefix + "view_profile"
 	clientList         = clientPrefix + "list"
 	clientSearch       = clientPrefix + "search"
+	clientListChanges  = clientPrefix + "list_changes"
 	clientListByGroup  = clientPrefix + "list_by_group"
+	clientListObjPerms = clientPrefix + "list_object_permissions"
 	clientIdentify     = clientPrefix + "identify"
 	generateResetToken = clientPrefix + "generate_reset_token"
 	issueToken         = clientPrefix + "issue_token"
 	refreshToken       = clientPrefix + "refresh_token"
+	confirmMFA         = clientPrefix + "confirm_mfa"
+	deviceCode         = clientPrefix + "device_code"
+	deviceAuthorize    = clientPrefix + "device_authorize"
+	deviceToken        = clientPrefix + "device_token"
 	resetSecret        = clientPrefix + "reset_secret"
 	sendPasswordReset  = clientPrefix + "send_password_reset"
 	oauthCallback      = clientPrefix + "oauth_callback"
@@ -35,15 +42,22 @@ var (
 	_ events.Event = (*createClientEvent)(nil)
 	_ events.Event = (*updateClientEvent)(nil)
 	_ events.Event = (*removeClientEvent)(nil)
+	_ events.Event = (*disableInactiveClientEvent)(nil)
 	_ events.Event = (*viewClientEvent)(nil)
 	_ events.Event = (*viewProfileEvent)(nil)
 	_ events.Event = (*listClientEvent)(nil)
+	_ events.Event = (*listChangesEvent)(nil)
 	_ events.Event = (*listClientByGroupEvent)(nil)
+	_ events.Event = (*listObjectPermissionsEvent)(nil)
 	_ events.Event = (*searchClientEvent)(nil)
 	_ events.Event = (*identifyClientEvent)(nil)
 	_ events.Event = (*generateResetTokenEvent)(nil)
 	_ events.Event = (*issueTokenEvent)(nil)
 	_ events.Event = (*refreshTokenEvent)(nil)
+	_ events.Event = (*confirmMFAEvent)(nil)
+	_ events.Event = (*deviceCodeEvent)(nil)
+	_ events.Event = (*deviceAuthorizeEvent)(nil)
+	_ events.Event = (*deviceTokenEvent)(nil)
 	_ events.Event = (*resetSecretEvent)(nil)
 	_ events.Event = (*sendPasswordResetEvent)(nil)
 	_ events.Event = (*oauthCallbackEvent)(nil)
@@ -122,20 +136,47 @@ func (uce updateClientEvent) Encode() (map[string]interface{}, error) {
 }
 
 type removeClientEvent struct {
-	id        string
-	status    string
-	updatedAt time.Time
-	updatedBy string
+	id         string
+	status     string
+	updatedAt  time.Time
+	updatedBy  string
+	disabledAt *time.Time
 }
 
 func (rce removeClientEvent) Encode() (map[string]interface{}, error) {
-	return map[string]interface{}{
+	val := map[string]interface{}{
 		"operation":  clientRemove,
 		"id":         rce.id,
 		"status":     rce.status,
 		"updated_at": rce.updatedAt,
 		"updated_by": rce.updatedBy,
-	}, nil
+	}
+	if rce.disabledAt != nil {
+		val["disabled_at"] = *rce.disabledAt
+	}
+
+	return val, nil
+}
+
+type disableInactiveClientEvent struct {
+	id         string
+	status     string
+	updatedAt  time.Time
+	disabledAt *time.Time
+}
+
+func (dice disableInactiveClientEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation":  disableInactive,
+		"id":         dice.id,
+		"status":     dice.status,
+		"updated_at": dice.updatedAt,
+	}
+	if dice.disabledAt != nil {
+		val["disabled_at"] = *dice.disabledAt
+	}
+
+	return val, nil
 }
 
 type viewClientEvent struct {
@@ -263,6 +304,43 @@ func (lce listClientEvent) Encode() (map[string]interface{}, error) {
 	return val, nil
 }
 
+type listObjectPermissionsEvent struct {
+	listClientByGroupEvent
+}
+
+func (lope listObjectPermissionsEvent) Encode() (map[string]interface{}, error) {
+	val, err := lope.listClientByGroupEvent.Encode()
+	if err != nil {
+		return nil, err
+	}
+	val["operation"] = clientListObjPerms
+
+	return val, nil
+}
+
+type listChangesEvent struct {
+	since  time.Time
+	cursor string
+	limit  uint64
+	count  int
+}
+
+func (lce listChangesEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation": clientListChanges,
+		"limit":     lce.limit,
+		"count":     lce.count,
+	}
+	if !lce.since.IsZero() {
+		val["since"] = lce.since
+	}
+	if lce.cursor != "" {
+		val["cursor"] = lce.cursor
+	}
+
+	return val, nil
+}
+
 type listClientByGroupEvent struct {
 	mgclients.Page
 	objectKind string
@@ -369,6 +447,17 @@ func (ite issueTokenEvent) Encode() (map[string]interface{}, error) {
 	}, nil
 }
 
+type confirmMFAEvent struct {
+	identity string
+}
+
+func (cme confirmMFAEvent) Encode() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"operation": confirmMFA,
+		"identity":  cme.identity,
+	}, nil
+}
+
 type refreshTokenEvent struct{}
 
 func (rte refreshTokenEvent) Encode() (map[string]interface{}, error) {
@@ -377,6 +466,42 @@ func (rte refreshTokenEvent) Encode() (map[string]interface{}, error) {
 	}, nil
 }
 
+type deviceCodeEvent struct {
+	audience string
+}
+
+func (dce deviceCodeEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation": deviceCode,
+	}
+	if dce.audience != "" {
+		val["audience"] = dce.audience
+	}
+
+	return val, nil
+}
+
+type deviceAuthorizeEvent struct {
+	id      string
+	approve bool
+}
+
+func (dae deviceAuthorizeEvent) Encode() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"operation": deviceAuthorize,
+		"id":        dae.id,
+		"approve":   dae.approve,
+	}, nil
+}
+
+type deviceTokenEvent struct{}
+
+func (dte deviceTokenEvent) Encode() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"operation": deviceToken,
+	}, nil
+}
+
 type resetSecretEvent struct{}
 
 func (rse resetSecretEvent) Encode() (map[string]interface{}, error) {
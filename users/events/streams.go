@@ -5,6 +5,7 @@ package events
 
 import (
 	"context"
+	"time"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/pkg/authn"
@@ -72,6 +73,24 @@ func (es *eventStore) UpdateClientRole(ctx context.Context, session authn.Sessio
 	return es.update(ctx, "role", user)
 }
 
+func (es *eventStore) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role mgclients.Role) ([]users.RoleUpdate, error) {
+	res, err := es.svc.UpdateClientsRole(ctx, session, ids, role)
+	if err != nil {
+		return res, err
+	}
+
+	for _, r := range res {
+		if r.Err != nil {
+			continue
+		}
+		if _, err := es.update(ctx, "role", r.Client); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
 func (es *eventStore) UpdateClientTags(ctx context.Context, session authn.Session, user mgclients.Client) (mgclients.Client, error) {
 	user, err := es.svc.UpdateClientTags(ctx, session, user)
 	if err != nil {
@@ -99,6 +118,57 @@ func (es *eventStore) UpdateClientIdentity(ctx context.Context, session authn.Se
 	return es.update(ctx, "identity", user)
 }
 
+func (es *eventStore) ConfirmIdentity(ctx context.Context, token string) (mgclients.Client, error) {
+	user, err := es.svc.ConfirmIdentity(ctx, token)
+	if err != nil {
+		return user, err
+	}
+
+	return es.update(ctx, "identity", user)
+}
+
+// VerifyPhone publishes the status change, since the verified user is enabled.
+func (es *eventStore) VerifyPhone(ctx context.Context, identity, code string) (mgclients.Client, error) {
+	user, err := es.svc.VerifyPhone(ctx, identity, code)
+	if err != nil {
+		return user, err
+	}
+
+	return es.delete(ctx, user)
+}
+
+func (es *eventStore) SendPhoneCode(ctx context.Context, identity string) error {
+	return es.svc.SendPhoneCode(ctx, identity)
+}
+
+func (es *eventStore) ResendVerification(ctx context.Context, identity string) error {
+	return es.svc.ResendVerification(ctx, identity)
+}
+
+func (es *eventStore) RequestDeletion(ctx context.Context, session authn.Session) error {
+	return es.svc.RequestDeletion(ctx, session)
+}
+
+// ConfirmDeletion publishes the status change, since the user is deleted.
+func (es *eventStore) ConfirmDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	user, err := es.svc.ConfirmDeletion(ctx, token)
+	if err != nil {
+		return user, err
+	}
+
+	return es.delete(ctx, user)
+}
+
+// CancelDeletion publishes the status change, since the user is enabled again.
+func (es *eventStore) CancelDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	user, err := es.svc.CancelDeletion(ctx, token)
+	if err != nil {
+		return user, err
+	}
+
+	return es.delete(ctx, user)
+}
+
 func (es *eventStore) update(ctx context.Context, operation string, user mgclients.Client) (mgclients.Client, error) {
 	event := updateClientEvent{
 		user, operation,
@@ -161,6 +231,25 @@ func (es *eventStore) ListClients(ctx context.Context, session authn.Session, pm
 	return cp, nil
 }
 
+func (es *eventStore) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (users.ChangesPage, error) {
+	cp, err := es.svc.ListChanges(ctx, session, since, cursor, limit)
+	if err != nil {
+		return cp, err
+	}
+	event := listChangesEvent{
+		since:  since,
+		cursor: cursor,
+		limit:  limit,
+		count:  len(cp.Changes),
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return cp, err
+	}
+
+	return cp, nil
+}
+
 func (es *eventStore) SearchUsers(ctx context.Context, pm mgclients.Page) (mgclients.ClientsPage, error) {
 	cp, err := es.svc.SearchUsers(ctx, pm)
 	if err != nil {
@@ -193,6 +282,22 @@ func (es *eventStore) ListMembers(ctx context.Context, session authn.Session, ob
 	return mp, nil
 }
 
+func (es *eventStore) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mgclients.MembersPage, error) {
+	mp, err := es.svc.ListObjectPermissions(ctx, session, objectKind, objectID, pm)
+	if err != nil {
+		return mp, err
+	}
+	event := listObjectPermissionsEvent{
+		listClientByGroupEvent{pm, objectKind, objectID},
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return mp, err
+	}
+
+	return mp, nil
+}
+
 func (es *eventStore) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	user, err := es.svc.EnableClient(ctx, session, id)
 	if err != nil {
@@ -211,12 +316,33 @@ func (es *eventStore) DisableClient(ctx context.Context, session authn.Session,
 	return es.delete(ctx, user)
 }
 
+func (es *eventStore) DisableInactiveClient(ctx context.Context, id string) (mgclients.Client, error) {
+	user, err := es.svc.DisableInactiveClient(ctx, id)
+	if err != nil {
+		return user, err
+	}
+
+	event := disableInactiveClientEvent{
+		id:         user.ID,
+		status:     user.Status.String(),
+		updatedAt:  user.UpdatedAt,
+		disabledAt: user.DisabledAt,
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
 func (es *eventStore) delete(ctx context.Context, user mgclients.Client) (mgclients.Client, error) {
 	event := removeClientEvent{
-		id:        user.ID,
-		updatedAt: user.UpdatedAt,
-		updatedBy: user.UpdatedBy,
-		status:    user.Status.String(),
+		id:         user.ID,
+		updatedAt:  user.UpdatedAt,
+		updatedBy:  user.UpdatedBy,
+		status:     user.Status.String(),
+		disabledAt: user.DisabledAt,
 	}
 
 	if err := es.Publish(ctx, event); err != nil {
@@ -257,8 +383,8 @@ func (es *eventStore) GenerateResetToken(ctx context.Context, email, host string
 	return es.Publish(ctx, event)
 }
 
-func (es *eventStore) IssueToken(ctx context.Context, identity, secret string) (*magistrala.Token, error) {
-	token, err := es.svc.IssueToken(ctx, identity, secret)
+func (es *eventStore) IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (*magistrala.Token, error) {
+	token, err := es.svc.IssueToken(ctx, identity, secret, mfaCode, audience)
 	if err != nil {
 		return token, err
 	}
@@ -274,6 +400,18 @@ func (es *eventStore) IssueToken(ctx context.Context, identity, secret string) (
 	return token, nil
 }
 
+func (es *eventStore) EnrollMFA(ctx context.Context, identity, secret string) (users.MFASecret, error) {
+	return es.svc.EnrollMFA(ctx, identity, secret)
+}
+
+func (es *eventStore) ConfirmMFA(ctx context.Context, identity, secret, code string) error {
+	if err := es.svc.ConfirmMFA(ctx, identity, secret, code); err != nil {
+		return err
+	}
+
+	return es.Publish(ctx, confirmMFAEvent{identity: identity})
+}
+
 func (es *eventStore) RefreshToken(ctx context.Context, session authn.Session, refreshToken string) (*magistrala.Token, error) {
 	token, err := es.svc.RefreshToken(ctx, session, refreshToken)
 	if err != nil {
@@ -289,6 +427,51 @@ func (es *eventStore) RefreshToken(ctx context.Context, session authn.Session, r
 	return token, nil
 }
 
+func (es *eventStore) RequestDeviceCode(ctx context.Context, audience string) (users.DeviceAuthorization, error) {
+	da, err := es.svc.RequestDeviceCode(ctx, audience)
+	if err != nil {
+		return da, err
+	}
+
+	event := deviceCodeEvent{
+		audience: audience,
+	}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return da, err
+	}
+
+	return da, nil
+}
+
+func (es *eventStore) AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) error {
+	if err := es.svc.AuthorizeDevice(ctx, session, userCode, mfaCode, approve); err != nil {
+		return err
+	}
+
+	event := deviceAuthorizeEvent{
+		id:      session.UserID,
+		approve: approve,
+	}
+
+	return es.Publish(ctx, event)
+}
+
+func (es *eventStore) DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error) {
+	token, err := es.svc.DeviceToken(ctx, deviceCode)
+	if err != nil {
+		return token, err
+	}
+
+	event := deviceTokenEvent{}
+
+	if err := es.Publish(ctx, event); err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
 func (es *eventStore) ResetSecret(ctx context.Context, session authn.Session, secret string) error {
 	if err := es.svc.ResetSecret(ctx, session, secret); err != nil {
 		return err
@@ -299,6 +482,10 @@ func (es *eventStore) ResetSecret(ctx context.Context, session authn.Session, se
 	return es.Publish(ctx, event)
 }
 
+func (es *eventStore) PreviewEmail(ctx context.Context, session authn.Session, name string, data users.EmailData) (users.EmailPreview, error) {
+	return es.svc.PreviewEmail(ctx, session, name, data)
+}
+
 func (es *eventStore) SendPasswordReset(ctx context.Context, host, email, user, token string) error {
 	if err := es.svc.SendPasswordReset(ctx, host, email, user, token); err != nil {
 		return err
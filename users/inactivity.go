@@ -0,0 +1,232 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"golang.org/x/time/rate"
+)
+
+var (
+	errInactivityDisabled = errors.New("disabling inactive users is not enabled")
+	errDisableInactive    = errors.New("failed to disable inactive users")
+)
+
+// InactivityConfig defines how the users inactive for too long are disabled.
+// The user is active when it logs in, and when its account is created or
+// updated, so the user re-enabled by an administrator is not disabled again
+// right away. Administrators and the users tagged with the exempt tag, such
+// as service accounts, are never disabled.
+type InactivityConfig struct {
+	// Threshold is the inactivity period after which the user is disabled.
+	// Zero keeps the inactive users enabled.
+	Threshold time.Duration `env:"THRESHOLD"   envDefault:"0s"`
+
+	// WarnBefore is how long before being disabled the user is warned by
+	// e-mail. The user is disabled only once warned for at least that long.
+	// Zero disables the warnings.
+	WarnBefore time.Duration `env:"WARN_BEFORE" envDefault:"0s"`
+
+	// ExemptTag is the tag of the users which are never disabled.
+	ExemptTag string `env:"EXEMPT_TAG"  envDefault:"service-account"`
+
+	// Interval is the period of checking for inactive users.
+	Interval time.Duration `env:"INTERVAL"    envDefault:"24h"`
+
+	// BatchSize is the number of users retrieved at once.
+	BatchSize uint64 `env:"BATCH_SIZE"  envDefault:"100"`
+
+	// Rate is the maximum number of users warned or disabled per second.
+	Rate float64 `env:"RATE"        envDefault:"10"`
+}
+
+// InactivityQuery selects the enabled non-admin users inactive since before
+// the given time.
+type InactivityQuery struct {
+	Before    time.Time
+	ExemptTag string
+	// Unwarned selects only the users not warned about the inactivity
+	// since their last activity.
+	Unwarned bool
+	// WarnedBefore, if set, selects only the users warned about the
+	// inactivity since their last activity and before the given time.
+	WarnedBefore time.Time
+	Limit        uint64
+}
+
+// InactivityReport contains the result of a single inactivity check.
+type InactivityReport struct {
+	Warned   uint64
+	Disabled uint64
+}
+
+// InactivityHandler periodically warns and disables the inactive users.
+// Users are disabled through the service, so that the disabling is
+// published as an event, and the users already disabled or active again are
+// skipped, so the check is idempotent.
+type InactivityHandler struct {
+	svc     Service
+	clients Repository
+	email   Emailer
+	cfg     InactivityConfig
+	limiter *rate.Limiter
+	logger  *slog.Logger
+}
+
+// NewInactivityHandler returns the handler of the inactive users.
+func NewInactivityHandler(svc Service, clients Repository, emailer Emailer, cfg InactivityConfig, logger *slog.Logger) *InactivityHandler {
+	limit := rate.Inf
+	if cfg.Rate > 0 {
+		limit = rate.Limit(cfg.Rate)
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = defLimit
+	}
+
+	return &InactivityHandler{
+		svc:     svc,
+		clients: clients,
+		email:   emailer,
+		cfg:     cfg,
+		limiter: rate.NewLimiter(limit, 1),
+		logger:  logger,
+	}
+}
+
+// Run checks for the inactive users periodically until the context is
+// canceled. It returns immediately if disabling inactive users is not enabled.
+func (h *InactivityHandler) Run(ctx context.Context) {
+	if h.cfg.Threshold <= 0 || h.cfg.Interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := h.Handle(ctx)
+			if err != nil {
+				h.logger.Error("failed to disable inactive users", slog.Any("error", err))
+				continue
+			}
+			h.logger.Info("inactive users checked",
+				slog.Uint64("warned", report.Warned),
+				slog.Uint64("disabled", report.Disabled),
+			)
+		}
+	}
+}
+
+// Handle warns the users about to be disabled, then disables the users
+// inactive for longer than the threshold.
+func (h *InactivityHandler) Handle(ctx context.Context) (InactivityReport, error) {
+	report := InactivityReport{}
+	if h.cfg.Threshold <= 0 {
+		return report, errInactivityDisabled
+	}
+	now := time.Now()
+
+	if h.cfg.WarnBefore > 0 {
+		q := InactivityQuery{
+			Before:    now.Add(-h.cfg.Threshold + h.cfg.WarnBefore),
+			ExemptTag: h.cfg.ExemptTag,
+			Unwarned:  true,
+			Limit:     h.cfg.BatchSize,
+		}
+		disableAt := now.Add(h.cfg.WarnBefore)
+		warned, err := h.each(ctx, q, func(u mgclients.Client) error {
+			return h.warn(ctx, u, disableAt)
+		})
+		report.Warned = warned
+		if err != nil {
+			return report, err
+		}
+	}
+
+	q := h.cfg.query(now)
+	q.Limit = h.cfg.BatchSize
+	disabled, err := h.each(ctx, q, func(u mgclients.Client) error {
+		_, err := h.svc.DisableInactiveClient(ctx, u.ID)
+		return err
+	})
+	report.Disabled = disabled
+
+	return report, err
+}
+
+// each applies the function to the users matching the query, and returns the
+// number of users it succeeded for. The handled users no longer match the
+// query, so the users the function failed for are skipped when retrieving
+// the next batch.
+func (h *InactivityHandler) each(ctx context.Context, q InactivityQuery, fn func(mgclients.Client) error) (uint64, error) {
+	var handled, failed uint64
+	for {
+		batch, err := h.clients.RetrieveInactive(ctx, q)
+		if err != nil {
+			return handled, errors.Wrap(errDisableInactive, err)
+		}
+		if uint64(len(batch)) <= failed {
+			return handled, nil
+		}
+		for _, u := range batch[failed:] {
+			if err := h.limiter.Wait(ctx); err != nil {
+				return handled, errors.Wrap(errDisableInactive, err)
+			}
+			if err := fn(u); err != nil {
+				if !errors.Contains(err, repoerr.ErrNotFound) {
+					h.logger.Error("failed to handle inactive user", slog.String("id", u.ID), slog.Any("error", err))
+				}
+				failed++
+				continue
+			}
+			handled++
+		}
+		q.Limit = h.cfg.BatchSize + failed
+	}
+}
+
+// query returns the query selecting the users to disable at the given time.
+func (cfg InactivityConfig) query(now time.Time) InactivityQuery {
+	q := InactivityQuery{
+		Before:    now.Add(-cfg.Threshold),
+		ExemptTag: cfg.ExemptTag,
+	}
+	if cfg.WarnBefore > 0 {
+		q.WarnedBefore = now.Add(-cfg.WarnBefore)
+	}
+
+	return q
+}
+
+func (h *InactivityHandler) warn(ctx context.Context, u mgclients.Client, disableAt time.Time) error {
+	if err := h.email.SendInactivityWarning([]string{u.Credentials.Identity}, u.Name, disableAt); err != nil {
+		return err
+	}
+
+	return h.clients.SaveInactivityWarning(ctx, u.ID, time.Now())
+}
+
+func (svc service) DisableInactiveClient(ctx context.Context, id string) (mgclients.Client, error) {
+	cfg := svc.config.Inactivity
+	if cfg.Threshold <= 0 {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, errInactivityDisabled)
+	}
+	now := time.Now()
+	client, err := svc.clients.DisableInactive(ctx, id, cfg.query(now), now)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,254 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/internal/testsutil"
+	mglog "github.com/absmach/magistrala/logger"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	inactivityThreshold = 90 * 24 * time.Hour
+	exemptTag           = "service-account"
+)
+
+// inactiveUser is the seeded user with the activity the repository
+// tracks.
+type inactiveUser struct {
+	client     mgclients.Client
+	lastActive time.Time
+	warnedAt   time.Time
+}
+
+// seedInactivityRepo backs the repository mock by the seeded users, selecting
+// the inactive users the way the repository backends do.
+func seedInactivityRepo(cRepo *mocks.Repository, seeded []*inactiveUser) {
+	matches := func(u *inactiveUser, q users.InactivityQuery) bool {
+		warned := !u.warnedAt.Before(u.lastActive)
+		switch {
+		case u.client.Status != mgclients.EnabledStatus, u.client.Role == mgclients.AdminRole:
+			return false
+		case !u.lastActive.Before(q.Before):
+			return false
+		case q.ExemptTag != "" && slices.Contains(u.client.Tags, q.ExemptTag):
+			return false
+		case q.Unwarned && warned:
+			return false
+		case !q.WarnedBefore.IsZero() && (!warned || !u.warnedAt.Before(q.WarnedBefore)):
+			return false
+		}
+		return true
+	}
+	find := func(id string) *inactiveUser {
+		for _, u := range seeded {
+			if u.client.ID == id {
+				return u
+			}
+		}
+		return nil
+	}
+
+	cRepo.On("RetrieveInactive", mock.Anything, mock.Anything).Return(func(_ context.Context, q users.InactivityQuery) ([]mgclients.Client, error) {
+		var res []*inactiveUser
+		for _, u := range seeded {
+			if matches(u, q) {
+				res = append(res, u)
+			}
+		}
+		sort.Slice(res, func(i, j int) bool { return res[i].lastActive.Before(res[j].lastActive) })
+		var clients []mgclients.Client
+		for _, u := range res {
+			if q.Limit > 0 && uint64(len(clients)) == q.Limit {
+				break
+			}
+			clients = append(clients, u.client)
+		}
+		return clients, nil
+	})
+	cRepo.On("DisableInactive", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, id string, q users.InactivityQuery, at time.Time) (mgclients.Client, error) {
+		u := find(id)
+		if u == nil || !matches(u, q) {
+			return mgclients.Client{}, repoerr.ErrNotFound
+		}
+		u.client.Status = mgclients.DisabledStatus
+		u.client.UpdatedAt = at
+		return u.client, nil
+	})
+	cRepo.On("SaveInactivityWarning", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, id string, at time.Time) error {
+		if u := find(id); u != nil {
+			u.warnedAt = at
+		}
+		return nil
+	})
+}
+
+func newInactivityHandler(cfg users.InactivityConfig, cRepo *mocks.Repository, e *mocks.Emailer) *users.InactivityHandler {
+	svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), e, phasher, idProvider, users.Config{Inactivity: cfg})
+
+	return users.NewInactivityHandler(svc, cRepo, e, cfg, mglog.NewMock())
+}
+
+func newInactiveUser(t *testing.T, n int, inactive time.Duration) *inactiveUser {
+	return &inactiveUser{
+		client: mgclients.Client{
+			ID:          testsutil.GenerateUUID(t),
+			Name:        fmt.Sprintf("user-%d", n),
+			Credentials: mgclients.Credentials{Identity: fmt.Sprintf("user-%d@example.com", n)},
+			Status:      mgclients.EnabledStatus,
+			Role:        mgclients.UserRole,
+		},
+		lastActive: time.Now().Add(-inactive),
+	}
+}
+
+func TestHandleInactive(t *testing.T) {
+	stale := newInactiveUser(t, 1, inactivityThreshold+time.Hour)
+	staler := newInactiveUser(t, 2, 2*inactivityThreshold)
+	active := newInactiveUser(t, 3, time.Hour)
+	almost := newInactiveUser(t, 4, inactivityThreshold-time.Hour)
+	admin := newInactiveUser(t, 5, 2*inactivityThreshold)
+	admin.client.Role = mgclients.AdminRole
+	exempt := newInactiveUser(t, 6, 2*inactivityThreshold)
+	exempt.client.Tags = []string{exemptTag}
+	disabled := newInactiveUser(t, 7, 2*inactivityThreshold)
+	disabled.client.Status = mgclients.DisabledStatus
+	seeded := []*inactiveUser{stale, staler, active, almost, admin, exempt, disabled}
+
+	cRepo := new(mocks.Repository)
+	seedInactivityRepo(cRepo, seeded)
+	cfg := users.InactivityConfig{
+		Threshold: inactivityThreshold,
+		ExemptTag: exemptTag,
+		BatchSize: 1,
+	}
+	h := newInactivityHandler(cfg, cRepo, new(mocks.Emailer))
+
+	report, err := h.Handle(context.Background())
+	require.Nil(t, err, fmt.Sprintf("handle inactive users: unexpected error %s", err))
+	assert.Equal(t, users.InactivityReport{Disabled: 2}, report, fmt.Sprintf("handle inactive users: expected 2 disabled users got %v", report))
+
+	expected := map[string]mgclients.Status{
+		stale.client.ID:    mgclients.DisabledStatus,
+		staler.client.ID:   mgclients.DisabledStatus,
+		active.client.ID:   mgclients.EnabledStatus,
+		almost.client.ID:   mgclients.EnabledStatus,
+		admin.client.ID:    mgclients.EnabledStatus,
+		exempt.client.ID:   mgclients.EnabledStatus,
+		disabled.client.ID: mgclients.DisabledStatus,
+	}
+	for _, u := range seeded {
+		assert.Equal(t, expected[u.client.ID], u.client.Status, fmt.Sprintf("%s: expected status %s got %s", u.client.Name, expected[u.client.ID], u.client.Status))
+	}
+
+	// Handling the inactive users again changes nothing.
+	report, err = h.Handle(context.Background())
+	require.Nil(t, err, fmt.Sprintf("handle inactive users again: unexpected error %s", err))
+	assert.Equal(t, users.InactivityReport{}, report, fmt.Sprintf("handle inactive users again: expected no changes got %v", report))
+}
+
+func TestHandleInactiveWithWarnings(t *testing.T) {
+	const warnBefore = 7 * 24 * time.Hour
+
+	stale := newInactiveUser(t, 1, inactivityThreshold+time.Hour)
+	warned := newInactiveUser(t, 2, inactivityThreshold+time.Hour)
+	warned.warnedAt = time.Now().Add(-warnBefore - time.Hour)
+	almost := newInactiveUser(t, 3, inactivityThreshold-time.Hour)
+	active := newInactiveUser(t, 4, time.Hour)
+	seeded := []*inactiveUser{stale, warned, almost, active}
+
+	cRepo := new(mocks.Repository)
+	seedInactivityRepo(cRepo, seeded)
+	e := new(mocks.Emailer)
+	e.On("SendInactivityWarning", []string{stale.client.Credentials.Identity}, stale.client.Name, mock.Anything).Return(nil)
+	e.On("SendInactivityWarning", []string{almost.client.Credentials.Identity}, almost.client.Name, mock.Anything).Return(nil)
+	cfg := users.InactivityConfig{
+		Threshold:  inactivityThreshold,
+		WarnBefore: warnBefore,
+		ExemptTag:  exemptTag,
+		BatchSize:  10,
+	}
+	h := newInactivityHandler(cfg, cRepo, e)
+
+	// The stale user not warned yet is warned instead of being disabled.
+	report, err := h.Handle(context.Background())
+	require.Nil(t, err, fmt.Sprintf("handle inactive users: unexpected error %s", err))
+	assert.Equal(t, users.InactivityReport{Warned: 2, Disabled: 1}, report, fmt.Sprintf("handle inactive users: expected 2 warned and 1 disabled users got %v", report))
+	assert.Equal(t, mgclients.DisabledStatus, warned.client.Status, fmt.Sprintf("warned user: expected status %s got %s", mgclients.DisabledStatus, warned.client.Status))
+	for _, u := range []*inactiveUser{stale, almost, active} {
+		assert.Equal(t, mgclients.EnabledStatus, u.client.Status, fmt.Sprintf("%s: expected status %s got %s", u.client.Name, mgclients.EnabledStatus, u.client.Status))
+	}
+	e.AssertNumberOfCalls(t, "SendInactivityWarning", 2)
+
+	// The users are warned only once.
+	report, err = h.Handle(context.Background())
+	require.Nil(t, err, fmt.Sprintf("handle inactive users again: unexpected error %s", err))
+	assert.Equal(t, users.InactivityReport{}, report, fmt.Sprintf("handle inactive users again: expected no changes got %v", report))
+	e.AssertNumberOfCalls(t, "SendInactivityWarning", 2)
+}
+
+func TestDisableInactiveClient(t *testing.T) {
+	cases := []struct {
+		desc       string
+		threshold  time.Duration
+		disableErr error
+		err        error
+	}{
+		{
+			desc:      "disable inactive client",
+			threshold: inactivityThreshold,
+		},
+		{
+			desc:       "disable client which is not inactive",
+			threshold:  inactivityThreshold,
+			disableErr: repoerr.ErrNotFound,
+			err:        repoerr.ErrNotFound,
+		},
+		{
+			desc:      "disable inactive client with inactivity disabled",
+			threshold: 0,
+			err:       svcerr.ErrUpdateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			cfg := users.Config{Inactivity: users.InactivityConfig{Threshold: tc.threshold, ExemptTag: exemptTag}}
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, cfg)
+
+			disabled := client
+			disabled.Status = mgclients.DisabledStatus
+			cRepo.On("DisableInactive", context.Background(), client.ID, mock.MatchedBy(func(q users.InactivityQuery) bool {
+				return q.ExemptTag == exemptTag && time.Since(q.Before) >= tc.threshold && q.WarnedBefore.IsZero()
+			}), mock.Anything).Return(disabled, tc.disableErr)
+
+			c, err := svc.DisableInactiveClient(context.Background(), client.ID)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if err == nil {
+				assert.Equal(t, mgclients.DisabledStatus, c.Status, fmt.Sprintf("%s: expected status %s got %s\n", tc.desc, mgclients.DisabledStatus, c.Status))
+			}
+			if tc.threshold == 0 {
+				cRepo.AssertNotCalled(t, "DisableInactive", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// maxLoginBursts is the number of identity and IP address pairs the failed
+// logins are tracked for. The least recently failed pairs are evicted first.
+const maxLoginBursts = 10000
+
+// LoginAlert describes a burst of failed logins of the same identity from
+// the same IP address.
+type LoginAlert struct {
+	Identity string    `json:"identity"`
+	IP       string    `json:"ip"`
+	Count    int       `json:"count"`
+	Since    time.Time `json:"since"`
+}
+
+// Alerter notifies about bursts of failed logins.
+//
+//go:generate mockery --name Alerter --output=./mocks --filename alerter.go --quiet --note "Copyright (c) Abstract Machines"
+type Alerter interface {
+	// SendLoginAlert enqueues the failed logins alert. Sending is
+	// asynchronous, so delivery failures are not reported.
+	SendLoginAlert(alert LoginAlert) error
+}
+
+// LoginAlerts defines when failed logins are reported.
+type LoginAlerts struct {
+	// Alerter sends the alerts. Nil alerter disables the alerts.
+	Alerter Alerter
+
+	// Threshold is the number of failed logins within the window that
+	// triggers an alert.
+	Threshold int
+
+	// Window is the period the failed logins are counted in, starting from
+	// the first failure.
+	Window time.Duration
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of the context carrying the client IP address.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the client IP address carried by the context.
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+type loginKey struct {
+	identity string
+	ip       string
+}
+
+type loginBurst struct {
+	count   int
+	since   time.Time
+	alerted bool
+}
+
+// loginFailures counts failed logins per identity and IP address and sends
+// a single alert per burst. The bursts expire once their window is over.
+type loginFailures struct {
+	mu     sync.Mutex
+	config LoginAlerts
+	bursts *expirable.LRU[loginKey, *loginBurst]
+}
+
+func newLoginFailures(cfg LoginAlerts) *loginFailures {
+	if cfg.Alerter == nil || cfg.Threshold <= 0 || cfg.Window <= 0 {
+		return nil
+	}
+
+	// The expiry only bounds the memory, the failures outside of the window
+	// start a new burst anyway, so shorter windows don't make the cache
+	// clean up its entries too often.
+	ttl := max(cfg.Window, time.Second)
+
+	return &loginFailures{
+		config: cfg,
+		bursts: expirable.NewLRU[loginKey, *loginBurst](maxLoginBursts, nil, ttl),
+	}
+}
+
+func (lf *loginFailures) failed(ctx context.Context, identity string) {
+	if lf == nil {
+		return
+	}
+	key := loginKey{identity: identity, ip: ClientIP(ctx)}
+	now := time.Now()
+
+	lf.mu.Lock()
+	b, ok := lf.bursts.Get(key)
+	if !ok || now.Sub(b.since) > lf.config.Window {
+		b = &loginBurst{since: now}
+		lf.bursts.Add(key, b)
+	}
+	b.count++
+	alert := LoginAlert{Identity: key.identity, IP: key.ip, Count: b.count, Since: b.since}
+	fire := b.count >= lf.config.Threshold && !b.alerted
+	if fire {
+		b.alerted = true
+	}
+	lf.mu.Unlock()
+
+	if fire {
+		// The alert must not change the login result, so the alerter is
+		// responsible for reporting its failures.
+		_ = lf.config.Alerter.SendLoginAlert(alert)
+	}
+}
+
+func (lf *loginFailures) succeeded(ctx context.Context, identity string) {
+	if lf == nil {
+		return
+	}
+
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	lf.bursts.Remove(loginKey{identity: identity, ip: ClientIP(ctx)})
+}
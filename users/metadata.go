@@ -0,0 +1,114 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+var errInvalidDefaultMetadata = errors.New("invalid default metadata")
+
+// DefaultMetadata defines the metadata new users start with. It is merged
+// into the metadata of users when they register, so changing it does not
+// modify the metadata of existing users.
+type DefaultMetadata struct {
+	// All is the metadata every new user starts with.
+	All mgclients.Metadata
+
+	// Domains maps a domain ID to the metadata users registered in that
+	// domain start with, on top of All.
+	Domains map[string]mgclients.Metadata
+}
+
+// ParseDefaultMetadata parses the default metadata from a JSON object
+// applied to all users and a JSON object mapping domain IDs to the
+// metadata applied to users registered in that domain.
+func ParseDefaultMetadata(all, domains string) (DefaultMetadata, error) {
+	var dm DefaultMetadata
+	if all != "" {
+		if err := json.Unmarshal([]byte(all), &dm.All); err != nil {
+			return DefaultMetadata{}, errors.Wrap(errInvalidDefaultMetadata, err)
+		}
+	}
+	if domains != "" {
+		if err := json.Unmarshal([]byte(domains), &dm.Domains); err != nil {
+			return DefaultMetadata{}, errors.Wrap(errInvalidDefaultMetadata, err)
+		}
+		for domainID, md := range dm.Domains {
+			if domainID == "" || md == nil {
+				return DefaultMetadata{}, errors.Wrap(errInvalidDefaultMetadata, fmt.Errorf("malformed metadata of domain %q", domainID))
+			}
+		}
+	}
+
+	return dm, nil
+}
+
+// apply returns the metadata of the user registered in the domain with the
+// defaults merged in. Keys provided by the user take precedence over the
+// defaults, and nested objects are merged key by key.
+func (dm DefaultMetadata) apply(domainID string, md mgclients.Metadata) mgclients.Metadata {
+	defaults := mergeMetadata(dm.All, dm.Domains[domainID])
+	if len(defaults) == 0 {
+		return md
+	}
+
+	return mergeMetadata(defaults, md)
+}
+
+// mergeMetadata returns a deep copy of base with override merged into it.
+// Values in override replace those in base, except when both are objects,
+// in which case they are merged recursively.
+func mergeMetadata(base, override mgclients.Metadata) mgclients.Metadata {
+	if base == nil && override == nil {
+		return nil
+	}
+	ret := make(mgclients.Metadata, len(base)+len(override))
+	for k, v := range base {
+		ret[k] = copyValue(v)
+	}
+	for k, v := range override {
+		if bm, ok := asObject(ret[k]); ok {
+			if om, ok := asObject(v); ok {
+				ret[k] = map[string]interface{}(mergeMetadata(bm, om))
+				continue
+			}
+		}
+		ret[k] = copyValue(v)
+	}
+
+	return ret
+}
+
+func asObject(v interface{}) (mgclients.Metadata, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case mgclients.Metadata:
+		return m, true
+	default:
+		return nil, false
+	}
+}
+
+// copyValue copies objects and arrays, so the merged metadata never shares
+// them with the defaults.
+func copyValue(v interface{}) interface{} {
+	if m, ok := asObject(v); ok {
+		return map[string]interface{}(mergeMetadata(m, nil))
+	}
+	if s, ok := v.([]interface{}); ok {
+		ret := make([]interface{}, len(s))
+		for i, e := range s {
+			ret[i] = copyValue(e)
+		}
+		return ret
+	}
+
+	return v
+}
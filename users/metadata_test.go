@@ -0,0 +1,215 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseDefaultMetadata(t *testing.T) {
+	cases := []struct {
+		desc     string
+		all      string
+		domains  string
+		metadata users.DefaultMetadata
+		err      bool
+	}{
+		{
+			desc:     "empty default metadata",
+			metadata: users.DefaultMetadata{},
+		},
+		{
+			desc:    "all and domains default metadata",
+			all:     `{"onboarding":{"completed":false}}`,
+			domains: `{"domain1":{"onboarding":{"tour":true}}}`,
+			metadata: users.DefaultMetadata{
+				All: mgclients.Metadata{"onboarding": map[string]interface{}{"completed": false}},
+				Domains: map[string]mgclients.Metadata{
+					"domain1": {"onboarding": map[string]interface{}{"tour": true}},
+				},
+			},
+		},
+		{
+			desc: "malformed all default metadata",
+			all:  `["onboarding"]`,
+			err:  true,
+		},
+		{
+			desc:    "malformed domains default metadata",
+			domains: `{"domain1":"onboarding"}`,
+			err:     true,
+		},
+		{
+			desc:    "domain without default metadata",
+			domains: `{"domain1":null}`,
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			metadata, err := users.ParseDefaultMetadata(tc.all, tc.domains)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+			if !tc.err {
+				assert.Equal(t, tc.metadata, metadata)
+			}
+		})
+	}
+}
+
+func TestRegisterClientDefaultMetadata(t *testing.T) {
+	defaults := users.DefaultMetadata{
+		All: mgclients.Metadata{
+			"onboarding": map[string]interface{}{
+				"completed": false,
+				"steps":     map[string]interface{}{"profile": false, "device": false},
+			},
+			"plan": "free",
+		},
+		Domains: map[string]mgclients.Metadata{
+			validID: {
+				"onboarding": map[string]interface{}{"tour": true},
+				"plan":       "enterprise",
+			},
+		},
+	}
+
+	cases := []struct {
+		desc     string
+		defaults users.DefaultMetadata
+		session  authn.Session
+		metadata mgclients.Metadata
+		expected mgclients.Metadata
+	}{
+		{
+			desc:     "register client without default metadata",
+			metadata: mgclients.Metadata{"role": "client"},
+			expected: mgclients.Metadata{"role": "client"},
+		},
+		{
+			desc:     "register client with default metadata",
+			defaults: defaults,
+			expected: mgclients.Metadata{
+				"onboarding": map[string]interface{}{
+					"completed": false,
+					"steps":     map[string]interface{}{"profile": false, "device": false},
+				},
+				"plan": "free",
+			},
+		},
+		{
+			desc:     "register client with metadata overriding defaults",
+			defaults: defaults,
+			metadata: mgclients.Metadata{"plan": "pro", "role": "client"},
+			expected: mgclients.Metadata{
+				"onboarding": map[string]interface{}{
+					"completed": false,
+					"steps":     map[string]interface{}{"profile": false, "device": false},
+				},
+				"plan": "pro",
+				"role": "client",
+			},
+		},
+		{
+			desc:     "register client with nested metadata merged into defaults",
+			defaults: defaults,
+			metadata: mgclients.Metadata{
+				"onboarding": map[string]interface{}{
+					"steps": map[string]interface{}{"profile": true},
+				},
+			},
+			expected: mgclients.Metadata{
+				"onboarding": map[string]interface{}{
+					"completed": false,
+					"steps":     map[string]interface{}{"profile": true, "device": false},
+				},
+				"plan": "free",
+			},
+		},
+		{
+			desc:     "register client with nested metadata replacing default value",
+			defaults: defaults,
+			metadata: mgclients.Metadata{"onboarding": "skipped"},
+			expected: mgclients.Metadata{
+				"onboarding": "skipped",
+				"plan":       "free",
+			},
+		},
+		{
+			desc:     "register client in domain with default metadata",
+			defaults: defaults,
+			session:  authn.Session{DomainID: validID},
+			metadata: mgclients.Metadata{"role": "client"},
+			expected: mgclients.Metadata{
+				"onboarding": map[string]interface{}{
+					"completed": false,
+					"tour":      true,
+					"steps":     map[string]interface{}{"profile": false, "device": false},
+				},
+				"plan": "enterprise",
+				"role": "client",
+			},
+		},
+		{
+			desc:     "register client in domain without default metadata",
+			defaults: defaults,
+			session:  authn.Session{DomainID: wrongID},
+			expected: mgclients.Metadata{
+				"onboarding": map[string]interface{}{
+					"completed": false,
+					"steps":     map[string]interface{}{"profile": false, "device": false},
+				},
+				"plan": "free",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			policies := new(policymocks.Service)
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, policies, new(mocks.Emailer), phasher, idProvider, users.Config{DefaultMetadata: tc.defaults})
+
+			cli := client
+			cli.Metadata = tc.metadata
+			var saved mgclients.Client
+			policyCall := policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+			repoCall := cRepo.On("Save", context.Background(), mock.Anything).Run(func(args mock.Arguments) {
+				saved = args.Get(1).(mgclients.Client)
+			}).Return(client, nil)
+			_, err := svc.RegisterClient(context.Background(), tc.session, cli, true)
+			assert.True(t, errors.Contains(err, nil), fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.expected, saved.Metadata, fmt.Sprintf("%s: expected metadata %v got %v", tc.desc, tc.expected, saved.Metadata))
+			if onboarding, ok := saved.Metadata["onboarding"].(map[string]interface{}); ok {
+				onboarding["completed"] = true
+				if steps, ok := onboarding["steps"].(map[string]interface{}); ok {
+					steps["device"] = true
+				}
+			}
+			policyCall.Unset()
+			repoCall.Unset()
+		})
+	}
+
+	// Changing the metadata of registered users must not change the defaults.
+	assert.Equal(t, mgclients.Metadata{
+		"onboarding": map[string]interface{}{
+			"completed": false,
+			"steps":     map[string]interface{}{"profile": false, "device": false},
+		},
+		"plan": "free",
+	}, defaults.All)
+}
@@ -0,0 +1,302 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+const (
+	// DefMFAIssuer is the default issuer shown by the authenticator apps.
+	DefMFAIssuer = "Magistrala"
+
+	// The TOTP parameters are the defaults of RFC 6238, which are the only
+	// ones supported by all the common authenticator apps.
+	totpPeriod     = 30
+	totpDigits     = 6
+	totpSkew       = 1
+	mfaSecretBytes = 20
+)
+
+var (
+	errInvalidMFAPolicy = errors.New("invalid multi-factor authentication policy")
+	errMFAEnrolled      = errors.New("multi-factor authentication is already enrolled")
+	errMFANotPending    = errors.New("multi-factor authentication enrollment was not started")
+	errMFAFederated     = errors.New("users with multi-factor authentication must log in with the secret and the code")
+
+	totpModulo  = uint32(1_000_000)
+	mfaEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// MFAEnrollment is the TOTP secret of the user. The enrollment is pending
+// until the user confirms it with a valid code.
+type MFAEnrollment struct {
+	ClientID   string
+	Secret     string
+	EnrolledAt time.Time
+	LastStep   int64
+}
+
+// Enrolled reports whether the enrollment is confirmed.
+func (e MFAEnrollment) Enrolled() bool {
+	return !e.EnrolledAt.IsZero()
+}
+
+// MFASecret is the TOTP secret returned on enrollment, both as the base32
+// secret and as the otpauth URI rendered as the QR code.
+type MFASecret struct {
+	Secret string
+	URI    string
+}
+
+// MFAPolicy defines which users must have multi-factor authentication
+// enrolled to log in. Users not matched by the policy log in as usual.
+type MFAPolicy struct {
+	// Roles are the platform roles that require MFA.
+	Roles []mgclients.Role
+
+	// Domains maps a domain ID to the domain permissions, such as "admin"
+	// or "membership", that require MFA in that domain.
+	Domains map[string][]string
+
+	// Issuer is the issuer shown by the authenticator apps.
+	Issuer string
+}
+
+// ParseMFAPolicy parses the MFA policy from a comma separated list of
+// platform roles and a comma separated list of domainID:permission pairs.
+func ParseMFAPolicy(roles, domains string) (MFAPolicy, error) {
+	var p MFAPolicy
+	for _, r := range splitList(roles) {
+		role, err := mgclients.ToRole(r)
+		if err != nil || role == mgclients.AllRole {
+			return MFAPolicy{}, errors.Wrap(errInvalidMFAPolicy, fmt.Errorf("invalid role %q", r))
+		}
+		p.Roles = append(p.Roles, role)
+	}
+	for _, d := range splitList(domains) {
+		domainID, permission, ok := strings.Cut(d, ":")
+		if !ok || domainID == "" || permission == "" {
+			return MFAPolicy{}, errors.Wrap(errInvalidMFAPolicy, fmt.Errorf("malformed domain permission %q", d))
+		}
+		if p.Domains == nil {
+			p.Domains = make(map[string][]string)
+		}
+		p.Domains[domainID] = append(p.Domains[domainID], permission)
+	}
+
+	return p, nil
+}
+
+func splitList(s string) []string {
+	var ret []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+
+	return ret
+}
+
+func (svc service) EnrollMFA(ctx context.Context, identity, secret string) (MFASecret, error) {
+	user, err := svc.authenticate(ctx, identity, secret)
+	if err != nil {
+		return MFASecret{}, err
+	}
+	enrollment, err := svc.clients.RetrieveMFA(ctx, user.ID)
+	switch {
+	case err == nil && enrollment.Enrolled():
+		return MFASecret{}, errors.Wrap(svcerr.ErrConflict, errMFAEnrolled)
+	case err != nil && !errors.Contains(err, repoerr.ErrNotFound):
+		return MFASecret{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	key := make([]byte, mfaSecretBytes)
+	if _, err := rand.Read(key); err != nil {
+		return MFASecret{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	totpSecret := mfaEncoding.EncodeToString(key)
+	if err := svc.clients.SaveMFASecret(ctx, user.ID, totpSecret); err != nil {
+		if errors.Contains(err, repoerr.ErrConflict) {
+			return MFASecret{}, errors.Wrap(svcerr.ErrConflict, errMFAEnrolled)
+		}
+		return MFASecret{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+
+	return MFASecret{
+		Secret: totpSecret,
+		URI:    totpURI(svc.config.MFA.Issuer, user.Credentials.Identity, totpSecret),
+	}, nil
+}
+
+func (svc service) ConfirmMFA(ctx context.Context, identity, secret, code string) error {
+	user, err := svc.authenticate(ctx, identity, secret)
+	if err != nil {
+		return err
+	}
+	enrollment, err := svc.clients.RetrieveMFA(ctx, user.ID)
+	switch {
+	case errors.Contains(err, repoerr.ErrNotFound):
+		return errors.Wrap(svcerr.ErrMalformedEntity, errMFANotPending)
+	case err != nil:
+		return errors.Wrap(svcerr.ErrViewEntity, err)
+	case enrollment.Enrolled():
+		return errors.Wrap(svcerr.ErrConflict, errMFAEnrolled)
+	}
+	step, ok := validateTOTP(enrollment.Secret, code, time.Now())
+	if !ok {
+		return svcerr.ErrMFACode
+	}
+	if err := svc.clients.ConfirmMFA(ctx, user.ID, step, time.Now()); err != nil {
+		return errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	return nil
+}
+
+// checkMFAEnrollment returns the MFA enrollment of the user, or
+// ErrMFAEnrollmentRequired if the policy requires MFA for the user and the
+// user has not enrolled it. The returned enrollment is confirmed or empty.
+func (svc service) checkMFAEnrollment(ctx context.Context, user mgclients.Client) (MFAEnrollment, error) {
+	enrollment, err := svc.clients.RetrieveMFA(ctx, user.ID)
+	switch {
+	case err == nil && enrollment.Enrolled():
+		return enrollment, nil
+	case err != nil && !errors.Contains(err, repoerr.ErrNotFound):
+		return MFAEnrollment{}, err
+	}
+	required, err := svc.mfaRequired(ctx, user)
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+	if required {
+		return MFAEnrollment{}, svcerr.ErrMFAEnrollmentRequired
+	}
+
+	return MFAEnrollment{}, nil
+}
+
+// verifyMFA verifies the TOTP code of the users with MFA enrolled and
+// returns ErrMFAEnrollmentRequired if the policy requires MFA for the user
+// and the user has not enrolled it. Every code is accepted once.
+func (svc service) verifyMFA(ctx context.Context, user mgclients.Client, code string) error {
+	enrollment, err := svc.checkMFAEnrollment(ctx, user)
+	if err != nil || !enrollment.Enrolled() {
+		return err
+	}
+	step, ok := validateTOTP(enrollment.Secret, code, time.Now())
+	if !ok {
+		return svcerr.ErrMFACode
+	}
+	switch err := svc.clients.UseMFAStep(ctx, user.ID, step); {
+	case errors.Contains(err, repoerr.ErrConflict):
+		// The code was already used.
+		return svcerr.ErrMFACode
+	default:
+		return err
+	}
+}
+
+func (svc service) mfaRequired(ctx context.Context, user mgclients.Client) (bool, error) {
+	if slices.Contains(svc.config.MFA.Roles, user.Role) {
+		return true, nil
+	}
+	var permissions []string
+	for _, perms := range svc.config.MFA.Domains {
+		for _, p := range perms {
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	for _, p := range permissions {
+		page, err := svc.policies.ListAllObjects(ctx, policies.Policy{
+			SubjectType: policies.UserType,
+			Subject:     user.ID,
+			Permission:  p,
+			ObjectType:  policies.DomainType,
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, domainID := range page.Policies {
+			if slices.Contains(svc.config.MFA.Domains[domainID], p) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// validateTOTP returns the time step of the code if the code is valid for
+// the current time step or the adjacent ones, which tolerates the clock
+// drift of the authenticator and the time the user takes to type the code.
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := mfaEncoding.DecodeString(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode returns the TOTP code of the time step, as in RFC 6238.
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulo)
+}
+
+// totpURI returns the otpauth URI of the secret, which the authenticator
+// apps import from the QR code.
+func totpURI(issuer, identity, secret string) string {
+	if issuer == "" {
+		issuer = DefMFAIssuer
+	}
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + identity,
+		RawQuery: q.Encode(),
+	}
+
+	return u.String()
+}
@@ -0,0 +1,397 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policysvc "github.com/absmach/magistrala/pkg/policies"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMFAPolicy(t *testing.T) {
+	cases := []struct {
+		desc    string
+		roles   string
+		domains string
+		policy  users.MFAPolicy
+		err     bool
+	}{
+		{
+			desc:   "empty policy",
+			policy: users.MFAPolicy{},
+		},
+		{
+			desc:    "roles and domains",
+			roles:   "admin",
+			domains: "domain1:admin, domain1:membership,domain2:admin",
+			policy: users.MFAPolicy{
+				Roles: []mgclients.Role{mgclients.AdminRole},
+				Domains: map[string][]string{
+					"domain1": {"admin", "membership"},
+					"domain2": {"admin"},
+				},
+			},
+		},
+		{
+			desc:  "invalid role",
+			roles: "owner",
+			err:   true,
+		},
+		{
+			desc:  "all role",
+			roles: "all",
+			err:   true,
+		},
+		{
+			desc:    "domain without permission",
+			domains: "domain1",
+			err:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			policy, err := users.ParseMFAPolicy(tc.roles, tc.domains)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %v", tc.desc, err))
+			if !tc.err {
+				assert.Equal(t, tc.policy, policy)
+			}
+		})
+	}
+}
+
+// mfaSecret is the RFC 6238 test secret "12345678901234567890".
+const mfaSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// totp returns the TOTP code of the secret at the time.
+func totp(t *testing.T, secret string, at time.Time) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.Nil(t, err, fmt.Sprintf("decode secret unexpected error: %s", err))
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1_000_000)
+}
+
+func TestTOTP(t *testing.T) {
+	// The test vector of RFC 6238, truncated to six digits.
+	assert.Equal(t, "287082", totp(t, mfaSecret, time.Unix(59, 0)))
+}
+
+func TestIssueTokenMFA(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	policies := new(policymocks.Service)
+	auth := new(authmocks.TokenServiceClient)
+	cfg := users.Config{
+		MFA: users.MFAPolicy{
+			Roles:   []mgclients.Role{mgclients.AdminRole},
+			Domains: map[string][]string{validID: {policysvc.AdminPermission}},
+		},
+	}
+	svc := users.NewService(auth, cRepo, policies, new(mocks.Emailer), phasher, idProvider, cfg)
+
+	user := client
+	user.Credentials.Secret, _ = phasher.Hash(client.Credentials.Secret)
+	admin := user
+	admin.Role = mgclients.AdminRole
+	enrolled := users.MFAEnrollment{ClientID: user.ID, Secret: mfaSecret, EnrolledAt: time.Now()}
+	pending := users.MFAEnrollment{ClientID: user.ID, Secret: mfaSecret}
+	code := totp(t, mfaSecret, time.Now())
+
+	cases := []struct {
+		desc        string
+		client      mgclients.Client
+		code        string
+		domains     []string
+		listErr     error
+		enrollment  users.MFAEnrollment
+		retrieveErr error
+		useErr      error
+		useStep     bool
+		err         error
+	}{
+		{
+			desc:        "admin without enrolled MFA",
+			client:      admin,
+			retrieveErr: repoerr.ErrNotFound,
+			err:         svcerr.ErrMFAEnrollmentRequired,
+		},
+		{
+			desc:        "admin with pending MFA enrollment",
+			client:      admin,
+			enrollment:  pending,
+			retrieveErr: nil,
+			err:         svcerr.ErrMFAEnrollmentRequired,
+		},
+		{
+			desc:       "admin with enrolled MFA and valid code",
+			client:     admin,
+			code:       code,
+			enrollment: enrolled,
+			useStep:    true,
+		},
+		{
+			desc:       "admin with enrolled MFA without code",
+			client:     admin,
+			enrollment: enrolled,
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:       "admin with enrolled MFA and invalid code",
+			client:     admin,
+			code:       "000000",
+			enrollment: enrolled,
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:       "admin with enrolled MFA and used code",
+			client:     admin,
+			code:       code,
+			enrollment: enrolled,
+			useStep:    true,
+			useErr:     repoerr.ErrConflict,
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:        "regular user",
+			client:      user,
+			retrieveErr: repoerr.ErrNotFound,
+		},
+		{
+			desc:       "regular user with enrolled MFA and valid code",
+			client:     user,
+			code:       code,
+			enrollment: enrolled,
+			useStep:    true,
+		},
+		{
+			desc:       "regular user with enrolled MFA without code",
+			client:     user,
+			enrollment: enrolled,
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:        "regular user administering another domain",
+			client:      user,
+			domains:     []string{wrongID},
+			retrieveErr: repoerr.ErrNotFound,
+		},
+		{
+			desc:        "domain administrator without enrolled MFA",
+			client:      user,
+			domains:     []string{wrongID, validID},
+			retrieveErr: repoerr.ErrNotFound,
+			err:         svcerr.ErrMFAEnrollmentRequired,
+		},
+		{
+			desc:       "domain administrator with enrolled MFA",
+			client:     user,
+			code:       code,
+			domains:    []string{validID},
+			enrollment: enrolled,
+			useStep:    true,
+		},
+		{
+			desc:        "failed to list user domains",
+			client:      user,
+			retrieveErr: repoerr.ErrNotFound,
+			listErr:     svcerr.ErrAuthorization,
+			err:         svcerr.ErrAuthorization,
+		},
+		{
+			desc:        "failed to retrieve enrollment",
+			client:      admin,
+			retrieveErr: repoerr.ErrViewEntity,
+			err:         repoerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), tc.client.Credentials.Identity).Return(tc.client, nil)
+			repoCall1 := cRepo.On("RetrieveMFA", context.Background(), tc.client.ID).Return(tc.enrollment, tc.retrieveErr)
+			repoCall2 := cRepo.On("UseMFAStep", context.Background(), tc.client.ID, mock.Anything).Return(tc.useErr)
+			policyCall := policies.On("ListAllObjects", context.Background(), mock.Anything).Return(policysvc.PolicyPage{Policies: tc.domains}, tc.listErr)
+			cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			authCall := auth.On("Issue", context.Background(), mock.Anything).Return(&magistrala.Token{AccessToken: validToken, RefreshToken: &validToken}, nil)
+			_, err := svc.IssueToken(context.Background(), client.Credentials.Identity, client.Credentials.Secret, tc.code, "")
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.useStep {
+				cRepo.AssertCalled(t, "UseMFAStep", context.Background(), tc.client.ID, mock.Anything)
+			} else {
+				cRepo.AssertNotCalled(t, "UseMFAStep", context.Background(), tc.client.ID, mock.Anything)
+			}
+			if tc.err != nil {
+				auth.AssertNotCalled(t, "Issue", context.Background(), mock.Anything)
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			policyCall.Unset()
+			authCall.Unset()
+			cRepo.Calls = nil
+			auth.Calls = nil
+		})
+	}
+}
+
+func TestEnrollMFA(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, users.Config{})
+
+	user := client
+	user.Credentials.Secret, _ = phasher.Hash(client.Credentials.Secret)
+
+	cases := []struct {
+		desc        string
+		secret      string
+		enrollment  users.MFAEnrollment
+		retrieveErr error
+		saveErr     error
+		err         error
+	}{
+		{
+			desc:        "enroll MFA",
+			secret:      client.Credentials.Secret,
+			retrieveErr: repoerr.ErrNotFound,
+		},
+		{
+			desc:       "enroll MFA again while pending",
+			secret:     client.Credentials.Secret,
+			enrollment: users.MFAEnrollment{ClientID: user.ID, Secret: mfaSecret},
+		},
+		{
+			desc:       "enroll MFA when already enrolled",
+			secret:     client.Credentials.Secret,
+			enrollment: users.MFAEnrollment{ClientID: user.ID, Secret: mfaSecret, EnrolledAt: time.Now()},
+			err:        svcerr.ErrConflict,
+		},
+		{
+			desc:        "enroll MFA with concurrent confirmation",
+			secret:      client.Credentials.Secret,
+			retrieveErr: repoerr.ErrNotFound,
+			saveErr:     repoerr.ErrConflict,
+			err:         svcerr.ErrConflict,
+		},
+		{
+			desc:   "enroll MFA with invalid secret",
+			secret: "invalid",
+			err:    svcerr.ErrLogin,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), user.Credentials.Identity).Return(user, nil)
+			repoCall1 := cRepo.On("RetrieveMFA", context.Background(), user.ID).Return(tc.enrollment, tc.retrieveErr)
+			repoCall2 := cRepo.On("SaveMFASecret", context.Background(), user.ID, mock.Anything).Return(tc.saveErr)
+			secret, err := svc.EnrollMFA(context.Background(), user.Credentials.Identity, tc.secret)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.NotEmpty(t, secret.Secret)
+				assert.Contains(t, secret.URI, "otpauth://totp/")
+				assert.Contains(t, secret.URI, "secret="+secret.Secret)
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+		})
+	}
+}
+
+func TestConfirmMFA(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, users.Config{})
+
+	user := client
+	user.Credentials.Secret, _ = phasher.Hash(client.Credentials.Secret)
+	pending := users.MFAEnrollment{ClientID: user.ID, Secret: mfaSecret}
+
+	cases := []struct {
+		desc        string
+		code        string
+		enrollment  users.MFAEnrollment
+		retrieveErr error
+		confirmErr  error
+		err         error
+	}{
+		{
+			desc:       "confirm MFA",
+			code:       totp(t, mfaSecret, time.Now()),
+			enrollment: pending,
+		},
+		{
+			desc:       "confirm MFA with the previous code",
+			code:       totp(t, mfaSecret, time.Now().Add(-30*time.Second)),
+			enrollment: pending,
+		},
+		{
+			desc:       "confirm MFA with expired code",
+			code:       totp(t, mfaSecret, time.Now().Add(-2*time.Minute)),
+			enrollment: pending,
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:       "confirm MFA with invalid code",
+			code:       "12345",
+			enrollment: pending,
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:        "confirm MFA without enrollment",
+			code:        totp(t, mfaSecret, time.Now()),
+			retrieveErr: repoerr.ErrNotFound,
+			err:         svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:       "confirm MFA when already enrolled",
+			code:       totp(t, mfaSecret, time.Now()),
+			enrollment: users.MFAEnrollment{ClientID: user.ID, Secret: mfaSecret, EnrolledAt: time.Now()},
+			err:        svcerr.ErrConflict,
+		},
+		{
+			desc:       "confirm MFA with failed update",
+			code:       totp(t, mfaSecret, time.Now()),
+			enrollment: pending,
+			confirmErr: repoerr.ErrNotFound,
+			err:        svcerr.ErrUpdateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), user.Credentials.Identity).Return(user, nil)
+			repoCall1 := cRepo.On("RetrieveMFA", context.Background(), user.ID).Return(tc.enrollment, tc.retrieveErr)
+			repoCall2 := cRepo.On("ConfirmMFA", context.Background(), user.ID, mock.Anything, mock.Anything).Return(tc.confirmErr)
+			err := svc.ConfirmMFA(context.Background(), user.Credentials.Identity, client.Credentials.Secret, tc.code)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+		})
+	}
+}
@@ -5,6 +5,7 @@ package middleware
 
 import (
 	"context"
+	"time"
 
 	"github.com/absmach/magistrala"
 	mgauth "github.com/absmach/magistrala/auth"
@@ -12,6 +13,7 @@ import (
 	"github.com/absmach/magistrala/pkg/authz"
 	mgauthz "github.com/absmach/magistrala/pkg/authz"
 	"github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/policies"
 	"github.com/absmach/magistrala/users"
@@ -64,6 +66,14 @@ func (am *authorizationMiddleware) ListClients(ctx context.Context, session auth
 	return am.svc.ListClients(ctx, session, pm)
 }
 
+func (am *authorizationMiddleware) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (users.ChangesPage, error) {
+	if err := am.checkSuperAdmin(ctx, session.UserID); err == nil {
+		session.SuperAdmin = true
+	}
+
+	return am.svc.ListChanges(ctx, session, since, cursor, limit)
+}
+
 func (am *authorizationMiddleware) ListMembers(ctx context.Context, session authn.Session, objectKind, objectID string, pm clients.Page) (clients.MembersPage, error) {
 	if session.DomainUserID == "" {
 		return clients.MembersPage{}, svcerr.ErrDomainAuthorization
@@ -88,6 +98,26 @@ func (am *authorizationMiddleware) ListMembers(ctx context.Context, session auth
 	return am.svc.ListMembers(ctx, session, objectKind, objectID, pm)
 }
 
+func (am *authorizationMiddleware) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, pm clients.Page) (clients.MembersPage, error) {
+	if session.DomainUserID == "" {
+		return clients.MembersPage{}, svcerr.ErrDomainAuthorization
+	}
+	var objectType string
+	switch objectKind {
+	case policies.GroupsKind:
+		objectType = policies.GroupType
+	case policies.ThingsKind:
+		objectType = policies.ThingType
+	default:
+		return clients.MembersPage{}, svcerr.ErrAuthorization
+	}
+	if err := am.authorize(ctx, session.DomainID, policies.UserType, policies.UsersKind, session.UserID, policies.ViewPermission, objectType, objectID); err != nil {
+		return clients.MembersPage{}, err
+	}
+
+	return am.svc.ListObjectPermissions(ctx, session, objectKind, objectID, pm)
+}
+
 func (am *authorizationMiddleware) SearchUsers(ctx context.Context, pm clients.Page) (clients.ClientsPage, error) {
 	return am.svc.SearchUsers(ctx, pm)
 }
@@ -116,6 +146,34 @@ func (am *authorizationMiddleware) UpdateClientIdentity(ctx context.Context, ses
 	return am.svc.UpdateClientIdentity(ctx, session, id, identity)
 }
 
+func (am *authorizationMiddleware) ConfirmIdentity(ctx context.Context, token string) (clients.Client, error) {
+	return am.svc.ConfirmIdentity(ctx, token)
+}
+
+func (am *authorizationMiddleware) VerifyPhone(ctx context.Context, identity, code string) (clients.Client, error) {
+	return am.svc.VerifyPhone(ctx, identity, code)
+}
+
+func (am *authorizationMiddleware) SendPhoneCode(ctx context.Context, identity string) error {
+	return am.svc.SendPhoneCode(ctx, identity)
+}
+
+func (am *authorizationMiddleware) ResendVerification(ctx context.Context, identity string) error {
+	return am.svc.ResendVerification(ctx, identity)
+}
+
+func (am *authorizationMiddleware) RequestDeletion(ctx context.Context, session authn.Session) error {
+	return am.svc.RequestDeletion(ctx, session)
+}
+
+func (am *authorizationMiddleware) ConfirmDeletion(ctx context.Context, token string) (clients.Client, error) {
+	return am.svc.ConfirmDeletion(ctx, token)
+}
+
+func (am *authorizationMiddleware) CancelDeletion(ctx context.Context, token string) (clients.Client, error) {
+	return am.svc.CancelDeletion(ctx, token)
+}
+
 func (am *authorizationMiddleware) GenerateResetToken(ctx context.Context, email, host string) error {
 	return am.svc.GenerateResetToken(ctx, email, host)
 }
@@ -132,6 +190,14 @@ func (am *authorizationMiddleware) SendPasswordReset(ctx context.Context, host,
 	return am.svc.SendPasswordReset(ctx, host, email, user, token)
 }
 
+func (am *authorizationMiddleware) PreviewEmail(ctx context.Context, session authn.Session, name string, data users.EmailData) (users.EmailPreview, error) {
+	if err := am.checkSuperAdmin(ctx, session.UserID); err == nil {
+		session.SuperAdmin = true
+	}
+
+	return am.svc.PreviewEmail(ctx, session, name, data)
+}
+
 func (am *authorizationMiddleware) UpdateClientRole(ctx context.Context, session authn.Session, client clients.Client) (clients.Client, error) {
 	if err := am.checkSuperAdmin(ctx, session.UserID); err == nil {
 		session.SuperAdmin = true
@@ -143,6 +209,37 @@ func (am *authorizationMiddleware) UpdateClientRole(ctx context.Context, session
 	return am.svc.UpdateClientRole(ctx, session, client)
 }
 
+func (am *authorizationMiddleware) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role clients.Role) ([]users.RoleUpdate, error) {
+	if err := am.checkSuperAdmin(ctx, session.UserID); err == nil {
+		session.SuperAdmin = true
+	}
+
+	results := make([]users.RoleUpdate, len(ids))
+	var authorized []string
+	for i, id := range ids {
+		results[i].ID = id
+		if err := am.authorize(ctx, "", policies.UserType, policies.UsersKind, id, policies.MembershipPermission, policies.PlatformType, policies.MagistralaObject); err != nil {
+			results[i].Err = errors.Wrap(svcerr.ErrAuthorization, err)
+			continue
+		}
+		authorized = append(authorized, id)
+	}
+
+	updated, err := am.svc.UpdateClientsRole(ctx, session, authorized, role)
+	if err != nil {
+		return nil, err
+	}
+	j := 0
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = updated[j]
+			j++
+		}
+	}
+
+	return results, nil
+}
+
 func (am *authorizationMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
 	if err := am.checkSuperAdmin(ctx, session.UserID); err == nil {
 		session.SuperAdmin = true
@@ -159,6 +256,10 @@ func (am *authorizationMiddleware) DisableClient(ctx context.Context, session au
 	return am.svc.DisableClient(ctx, session, id)
 }
 
+func (am *authorizationMiddleware) DisableInactiveClient(ctx context.Context, id string) (clients.Client, error) {
+	return am.svc.DisableInactiveClient(ctx, id)
+}
+
 func (am *authorizationMiddleware) DeleteClient(ctx context.Context, session authn.Session, id string) error {
 	if err := am.checkSuperAdmin(ctx, session.UserID); err == nil {
 		session.SuperAdmin = true
@@ -171,14 +272,34 @@ func (am *authorizationMiddleware) Identify(ctx context.Context, session authn.S
 	return am.svc.Identify(ctx, session)
 }
 
-func (am *authorizationMiddleware) IssueToken(ctx context.Context, identity, secret string) (*magistrala.Token, error) {
-	return am.svc.IssueToken(ctx, identity, secret)
+func (am *authorizationMiddleware) IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (*magistrala.Token, error) {
+	return am.svc.IssueToken(ctx, identity, secret, mfaCode, audience)
+}
+
+func (am *authorizationMiddleware) EnrollMFA(ctx context.Context, identity, secret string) (users.MFASecret, error) {
+	return am.svc.EnrollMFA(ctx, identity, secret)
+}
+
+func (am *authorizationMiddleware) ConfirmMFA(ctx context.Context, identity, secret, code string) error {
+	return am.svc.ConfirmMFA(ctx, identity, secret, code)
 }
 
 func (am *authorizationMiddleware) RefreshToken(ctx context.Context, session authn.Session, refreshToken string) (*magistrala.Token, error) {
 	return am.svc.RefreshToken(ctx, session, refreshToken)
 }
 
+func (am *authorizationMiddleware) RequestDeviceCode(ctx context.Context, audience string) (users.DeviceAuthorization, error) {
+	return am.svc.RequestDeviceCode(ctx, audience)
+}
+
+func (am *authorizationMiddleware) AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) error {
+	return am.svc.AuthorizeDevice(ctx, session, userCode, mfaCode, approve)
+}
+
+func (am *authorizationMiddleware) DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error) {
+	return am.svc.DeviceToken(ctx, deviceCode)
+}
+
 func (am *authorizationMiddleware) OAuthCallback(ctx context.Context, client clients.Client) (clients.Client, error) {
 	return am.svc.OAuthCallback(ctx, client)
 }
@@ -0,0 +1,123 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/authn"
+	"github.com/absmach/magistrala/pkg/authz"
+	authzmocks "github.com/absmach/magistrala/pkg/authz/mocks"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/middleware"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateClientsRole(t *testing.T) {
+	adminID := testsutil.GenerateUUID(t)
+	authorized1 := testsutil.GenerateUUID(t)
+	authorized2 := testsutil.GenerateUUID(t)
+	unauthorized := testsutil.GenerateUUID(t)
+	session := authn.Session{UserID: adminID}
+	superSession := authn.Session{UserID: adminID, SuperAdmin: true}
+
+	cases := []struct {
+		desc         string
+		ids          []string
+		unauthorized []string
+		svcIDs       []string
+		svcRes       []users.RoleUpdate
+		svcErr       error
+		results      []users.RoleUpdate
+		err          error
+	}{
+		{
+			desc:   "update role of authorized clients",
+			ids:    []string{authorized1, authorized2},
+			svcIDs: []string{authorized1, authorized2},
+			svcRes: []users.RoleUpdate{
+				{ID: authorized1, Client: mgclients.Client{ID: authorized1, Role: mgclients.AdminRole}},
+				{ID: authorized2, Client: mgclients.Client{ID: authorized2, Role: mgclients.AdminRole}},
+			},
+			results: []users.RoleUpdate{
+				{ID: authorized1, Client: mgclients.Client{ID: authorized1, Role: mgclients.AdminRole}},
+				{ID: authorized2, Client: mgclients.Client{ID: authorized2, Role: mgclients.AdminRole}},
+			},
+		},
+		{
+			desc:         "update role of authorized and unauthorized clients",
+			ids:          []string{authorized1, unauthorized, authorized2},
+			unauthorized: []string{unauthorized},
+			svcIDs:       []string{authorized1, authorized2},
+			svcRes: []users.RoleUpdate{
+				{ID: authorized1, Client: mgclients.Client{ID: authorized1, Role: mgclients.AdminRole}},
+				{ID: authorized2, Err: svcerr.ErrNotFound},
+			},
+			results: []users.RoleUpdate{
+				{ID: authorized1, Client: mgclients.Client{ID: authorized1, Role: mgclients.AdminRole}},
+				{ID: unauthorized, Err: svcerr.ErrAuthorization},
+				{ID: authorized2, Err: svcerr.ErrNotFound},
+			},
+		},
+		{
+			desc:         "update role of unauthorized clients",
+			ids:          []string{unauthorized},
+			unauthorized: []string{unauthorized},
+			svcIDs:       []string(nil),
+			svcRes:       []users.RoleUpdate{},
+			results: []users.RoleUpdate{
+				{ID: unauthorized, Err: svcerr.ErrAuthorization},
+			},
+		},
+		{
+			desc:         "update role with failed service update",
+			ids:          []string{authorized1, unauthorized},
+			unauthorized: []string{unauthorized},
+			svcIDs:       []string{authorized1},
+			svcErr:       svcerr.ErrUpdateEntity,
+			err:          svcerr.ErrUpdateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc := new(mocks.Service)
+			authorizer := new(authzmocks.Authorization)
+			am := middleware.AuthorizationMiddleware(svc, authorizer, false)
+
+			authorizer.On("Authorize", context.Background(), mock.Anything).Return(func(_ context.Context, pr authz.PolicyReq) error {
+				if pr.Permission == policies.AdminPermission {
+					return nil
+				}
+				for _, id := range tc.unauthorized {
+					if pr.Subject == id {
+						return errors.ErrAuthorization
+					}
+				}
+				return nil
+			})
+			svcCall := svc.On("UpdateClientsRole", context.Background(), superSession, tc.svcIDs, mgclients.AdminRole).Return(tc.svcRes, tc.svcErr)
+
+			results, err := am.UpdateClientsRole(context.Background(), session, tc.ids, mgclients.AdminRole)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			assert.Len(t, results, len(tc.results), fmt.Sprintf("%s: expected %d results got %d", tc.desc, len(tc.results), len(results)))
+			for i, r := range results {
+				want := tc.results[i]
+				assert.Equal(t, want.ID, r.ID, fmt.Sprintf("%s: expected result ID %s got %s", tc.desc, want.ID, r.ID))
+				assert.Equal(t, want.Client, r.Client, fmt.Sprintf("%s: expected client %v got %v", tc.desc, want.Client, r.Client))
+				assert.True(t, errors.Contains(r.Err, want.Err), fmt.Sprintf("%s: expected result error %s got %s", tc.desc, want.Err, r.Err))
+			}
+			svcCall.Parent.AssertCalled(t, "UpdateClientsRole", context.Background(), superSession, tc.svcIDs, mgclients.AdminRole)
+		})
+	}
+}
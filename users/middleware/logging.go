@@ -39,17 +39,17 @@ func (lm *loggingMiddleware) RegisterClient(ctx context.Context, session authn.S
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Register user failed", args...)
+			lm.logger.WarnContext(ctx, "Register user failed", args...)
 			return
 		}
-		lm.logger.Info("Register user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Register user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.RegisterClient(ctx, session, client, selfRegister)
 }
 
 // IssueToken logs the issue_token request. It logs the client identity type and the time it took to complete the request.
 // If the request fails, it logs the error.
-func (lm *loggingMiddleware) IssueToken(ctx context.Context, identity, secret string) (t *magistrala.Token, err error) {
+func (lm *loggingMiddleware) IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (t *magistrala.Token, err error) {
 	defer func(begin time.Time) {
 		args := []any{
 			slog.String("duration", time.Since(begin).String()),
@@ -57,14 +57,54 @@ func (lm *loggingMiddleware) IssueToken(ctx context.Context, identity, secret st
 		if t.AccessType != "" {
 			args = append(args, slog.String("access_type", t.AccessType))
 		}
+		if audience != "" {
+			args = append(args, slog.String("audience", audience))
+		}
+		if authn.Binding(ctx) != "" {
+			args = append(args, slog.Bool("bound", true))
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Issue token failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Issue token completed successfully", args...)
+	}(time.Now())
+	return lm.svc.IssueToken(ctx, identity, secret, mfaCode, audience)
+}
+
+// EnrollMFA logs the enroll_mfa request. It logs the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) EnrollMFA(ctx context.Context, identity, secret string) (s users.MFASecret, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Enroll MFA failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Enroll MFA completed successfully", args...)
+	}(time.Now())
+	return lm.svc.EnrollMFA(ctx, identity, secret)
+}
+
+// ConfirmMFA logs the confirm_mfa request. It logs the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ConfirmMFA(ctx context.Context, identity, secret, code string) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Issue token failed", args...)
+			lm.logger.WarnContext(ctx, "Confirm MFA failed", args...)
 			return
 		}
-		lm.logger.Info("Issue token completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Confirm MFA completed successfully", args...)
 	}(time.Now())
-	return lm.svc.IssueToken(ctx, identity, secret)
+	return lm.svc.ConfirmMFA(ctx, identity, secret, code)
 }
 
 // RefreshToken logs the refresh_token request. It logs the refreshtoken, token type and the time it took to complete the request.
@@ -79,14 +119,68 @@ func (lm *loggingMiddleware) RefreshToken(ctx context.Context, session authn.Ses
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Refresh token failed", args...)
+			lm.logger.WarnContext(ctx, "Refresh token failed", args...)
 			return
 		}
-		lm.logger.Info("Refresh token completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Refresh token completed successfully", args...)
 	}(time.Now())
 	return lm.svc.RefreshToken(ctx, session, refreshToken)
 }
 
+// RequestDeviceCode logs the request_device_code request. It logs the audience and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) RequestDeviceCode(ctx context.Context, audience string) (da users.DeviceAuthorization, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("audience", audience),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Request device code failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Request device code completed successfully", args...)
+	}(time.Now())
+	return lm.svc.RequestDeviceCode(ctx, audience)
+}
+
+// AuthorizeDevice logs the authorize_device request. It logs the user id, the decision and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("user_id", session.UserID),
+			slog.Bool("approve", approve),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Authorize device failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Authorize device completed successfully", args...)
+	}(time.Now())
+	return lm.svc.AuthorizeDevice(ctx, session, userCode, mfaCode, approve)
+}
+
+// DeviceToken logs the device_token request. It logs the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) DeviceToken(ctx context.Context, deviceCode string) (t *magistrala.Token, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Device token failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Device token completed successfully", args...)
+	}(time.Now())
+	return lm.svc.DeviceToken(ctx, deviceCode)
+}
+
 // ViewClient logs the view_client request. It logs the client id and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) ViewClient(ctx context.Context, session authn.Session, id string) (c mgclients.Client, err error) {
@@ -100,10 +194,10 @@ func (lm *loggingMiddleware) ViewClient(ctx context.Context, session authn.Sessi
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("View user failed", args...)
+			lm.logger.WarnContext(ctx, "View user failed", args...)
 			return
 		}
-		lm.logger.Info("View user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "View user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.ViewClient(ctx, session, id)
 }
@@ -121,10 +215,10 @@ func (lm *loggingMiddleware) ViewProfile(ctx context.Context, session authn.Sess
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("View profile failed", args...)
+			lm.logger.WarnContext(ctx, "View profile failed", args...)
 			return
 		}
-		lm.logger.Info("View profile completed successfully", args...)
+		lm.logger.InfoContext(ctx, "View profile completed successfully", args...)
 	}(time.Now())
 	return lm.svc.ViewProfile(ctx, session)
 }
@@ -143,14 +237,35 @@ func (lm *loggingMiddleware) ListClients(ctx context.Context, session authn.Sess
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("List users failed", args...)
+			lm.logger.WarnContext(ctx, "List users failed", args...)
 			return
 		}
-		lm.logger.Info("List users completed successfully", args...)
+		lm.logger.InfoContext(ctx, "List users completed successfully", args...)
 	}(time.Now())
 	return lm.svc.ListClients(ctx, session, pm)
 }
 
+// ListChanges logs the list_changes request. It logs the feed position and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (cp users.ChangesPage, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Time("since", since),
+			slog.String("cursor", cursor),
+			slog.Uint64("limit", limit),
+			slog.Int("count", len(cp.Changes)),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "List user changes failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "List user changes completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ListChanges(ctx, session, since, cursor, limit)
+}
+
 // SearchUsers logs the search_users request. It logs the page metadata and the time it took to complete the request.
 func (lm *loggingMiddleware) SearchUsers(ctx context.Context, cp mgclients.Page) (mp mgclients.ClientsPage, err error) {
 	defer func(begin time.Time) {
@@ -164,10 +279,10 @@ func (lm *loggingMiddleware) SearchUsers(ctx context.Context, cp mgclients.Page)
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Search clients failed to complete successfully", args...)
+			lm.logger.WarnContext(ctx, "Search clients failed to complete successfully", args...)
 			return
 		}
-		lm.logger.Info("Search clients completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Search clients completed successfully", args...)
 	}(time.Now())
 	return lm.svc.SearchUsers(ctx, cp)
 }
@@ -186,10 +301,10 @@ func (lm *loggingMiddleware) UpdateClient(ctx context.Context, session authn.Ses
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Update user failed", args...)
+			lm.logger.WarnContext(ctx, "Update user failed", args...)
 			return
 		}
-		lm.logger.Info("Update user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Update user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.UpdateClient(ctx, session, client)
 }
@@ -208,10 +323,10 @@ func (lm *loggingMiddleware) UpdateClientTags(ctx context.Context, session authn
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Update user tags failed", args...)
+			lm.logger.WarnContext(ctx, "Update user tags failed", args...)
 			return
 		}
-		lm.logger.Info("Update user tags completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Update user tags completed successfully", args...)
 	}(time.Now())
 	return lm.svc.UpdateClientTags(ctx, session, client)
 }
@@ -229,14 +344,149 @@ func (lm *loggingMiddleware) UpdateClientIdentity(ctx context.Context, session a
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Update client identity failed", args...)
+			lm.logger.WarnContext(ctx, "Update client identity failed", args...)
 			return
 		}
-		lm.logger.Info("Update client identity completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Update client identity completed successfully", args...)
 	}(time.Now())
 	return lm.svc.UpdateClientIdentity(ctx, session, id, identity)
 }
 
+// ConfirmIdentity logs the confirm_identity request. It logs the client id and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ConfirmIdentity(ctx context.Context, token string) (c mgclients.Client, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("user",
+				slog.String("id", c.ID),
+				slog.String("name", c.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Confirm client identity failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Confirm client identity completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ConfirmIdentity(ctx, token)
+}
+
+// VerifyPhone logs the verify_phone request. It logs the client id and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) VerifyPhone(ctx context.Context, identity, code string) (c mgclients.Client, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("user",
+				slog.String("id", c.ID),
+				slog.String("name", c.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Verify client phone failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Verify client phone completed successfully", args...)
+	}(time.Now())
+	return lm.svc.VerifyPhone(ctx, identity, code)
+}
+
+// SendPhoneCode logs the send_phone_code request. It logs the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) SendPhoneCode(ctx context.Context, identity string) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Send phone verification code failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Send phone verification code completed successfully", args...)
+	}(time.Now())
+	return lm.svc.SendPhoneCode(ctx, identity)
+}
+
+// ResendVerification logs the resend_verification request. It logs the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ResendVerification(ctx context.Context, identity string) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Resend verification failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Resend verification completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ResendVerification(ctx, identity)
+}
+
+// RequestDeletion logs the request_deletion request. It logs the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) RequestDeletion(ctx context.Context, session authn.Session) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Request account deletion failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Request account deletion completed successfully", args...)
+	}(time.Now())
+	return lm.svc.RequestDeletion(ctx, session)
+}
+
+// ConfirmDeletion logs the confirm_deletion request. It logs the client id and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ConfirmDeletion(ctx context.Context, token string) (c mgclients.Client, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("user",
+				slog.String("id", c.ID),
+				slog.String("name", c.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Confirm account deletion failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Confirm account deletion completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ConfirmDeletion(ctx, token)
+}
+
+// CancelDeletion logs the cancel_deletion request. It logs the client id and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) CancelDeletion(ctx context.Context, token string) (c mgclients.Client, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("user",
+				slog.String("id", c.ID),
+				slog.String("name", c.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Cancel account deletion failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Cancel account deletion completed successfully", args...)
+	}(time.Now())
+	return lm.svc.CancelDeletion(ctx, token)
+}
+
 // UpdateClientSecret logs the update_client_secret request. It logs the client id and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) UpdateClientSecret(ctx context.Context, session authn.Session, oldSecret, newSecret string) (c mgclients.Client, err error) {
@@ -250,10 +500,10 @@ func (lm *loggingMiddleware) UpdateClientSecret(ctx context.Context, session aut
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Update user secret failed", args...)
+			lm.logger.WarnContext(ctx, "Update user secret failed", args...)
 			return
 		}
-		lm.logger.Info("Update user secret completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Update user secret completed successfully", args...)
 	}(time.Now())
 	return lm.svc.UpdateClientSecret(ctx, session, oldSecret, newSecret)
 }
@@ -268,10 +518,10 @@ func (lm *loggingMiddleware) GenerateResetToken(ctx context.Context, email, host
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Generate reset token failed", args...)
+			lm.logger.WarnContext(ctx, "Generate reset token failed", args...)
 			return
 		}
-		lm.logger.Info("Generate reset token completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Generate reset token completed successfully", args...)
 	}(time.Now())
 	return lm.svc.GenerateResetToken(ctx, email, host)
 }
@@ -285,10 +535,10 @@ func (lm *loggingMiddleware) ResetSecret(ctx context.Context, session authn.Sess
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Reset secret failed", args...)
+			lm.logger.WarnContext(ctx, "Reset secret failed", args...)
 			return
 		}
-		lm.logger.Info("Reset secret completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Reset secret completed successfully", args...)
 	}(time.Now())
 	return lm.svc.ResetSecret(ctx, session, secret)
 }
@@ -303,14 +553,32 @@ func (lm *loggingMiddleware) SendPasswordReset(ctx context.Context, host, email,
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Send password reset failed", args...)
+			lm.logger.WarnContext(ctx, "Send password reset failed", args...)
 			return
 		}
-		lm.logger.Info("Send password reset completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Send password reset completed successfully", args...)
 	}(time.Now())
 	return lm.svc.SendPasswordReset(ctx, host, email, user, token)
 }
 
+// PreviewEmail logs the preview_email request. It logs the template name and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) PreviewEmail(ctx context.Context, session authn.Session, name string, data users.EmailData) (p users.EmailPreview, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("template", name),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Preview e-mail failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Preview e-mail completed successfully", args...)
+	}(time.Now())
+	return lm.svc.PreviewEmail(ctx, session, name, data)
+}
+
 // UpdateClientRole logs the update_client_role request. It logs the client id and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) UpdateClientRole(ctx context.Context, session authn.Session, client mgclients.Client) (c mgclients.Client, err error) {
@@ -325,14 +593,41 @@ func (lm *loggingMiddleware) UpdateClientRole(ctx context.Context, session authn
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Update user role failed", args...)
+			lm.logger.WarnContext(ctx, "Update user role failed", args...)
 			return
 		}
-		lm.logger.Info("Update user role completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Update user role completed successfully", args...)
 	}(time.Now())
 	return lm.svc.UpdateClientRole(ctx, session, client)
 }
 
+// UpdateClientsRole logs the update_clients_role request. It logs the role, the number of
+// updated and failed clients and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role mgclients.Role) (res []users.RoleUpdate, err error) {
+	defer func(begin time.Time) {
+		var failed int
+		for _, r := range res {
+			if r.Err != nil {
+				failed++
+			}
+		}
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("role", role.String()),
+			slog.Int("updated", len(res)-failed),
+			slog.Int("failed", failed),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Update users role failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Update users role completed successfully", args...)
+	}(time.Now())
+	return lm.svc.UpdateClientsRole(ctx, session, ids, role)
+}
+
 // EnableClient logs the enable_client request. It logs the client id and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (c mgclients.Client, err error) {
@@ -346,10 +641,10 @@ func (lm *loggingMiddleware) EnableClient(ctx context.Context, session authn.Ses
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Enable user failed", args...)
+			lm.logger.WarnContext(ctx, "Enable user failed", args...)
 			return
 		}
-		lm.logger.Info("Enable user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Enable user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.EnableClient(ctx, session, id)
 }
@@ -367,14 +662,35 @@ func (lm *loggingMiddleware) DisableClient(ctx context.Context, session authn.Se
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Disable user failed", args...)
+			lm.logger.WarnContext(ctx, "Disable user failed", args...)
 			return
 		}
-		lm.logger.Info("Disable user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Disable user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.DisableClient(ctx, session, id)
 }
 
+// DisableInactiveClient logs the disable_inactive_client request. It logs the client id and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) DisableInactiveClient(ctx context.Context, id string) (c mgclients.Client, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("user",
+				slog.String("id", id),
+				slog.String("name", c.Name),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "Disable inactive user failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "Disable inactive user completed successfully", args...)
+	}(time.Now())
+	return lm.svc.DisableInactiveClient(ctx, id)
+}
+
 // ListMembers logs the list_members request. It logs the group id, and the time it took to complete the request.
 // If the request fails, it logs the error.
 func (lm *loggingMiddleware) ListMembers(ctx context.Context, session authn.Session, objectKind, objectID string, cp mgclients.Page) (mp mgclients.MembersPage, err error) {
@@ -393,14 +709,40 @@ func (lm *loggingMiddleware) ListMembers(ctx context.Context, session authn.Sess
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("List members failed", args...)
+			lm.logger.WarnContext(ctx, "List members failed", args...)
 			return
 		}
-		lm.logger.Info("List members completed successfully", args...)
+		lm.logger.InfoContext(ctx, "List members completed successfully", args...)
 	}(time.Now())
 	return lm.svc.ListMembers(ctx, session, objectKind, objectID, cp)
 }
 
+// ListObjectPermissions logs the list_object_permissions request. It logs the object, and the time it took to complete the request.
+// If the request fails, it logs the error.
+func (lm *loggingMiddleware) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, cp mgclients.Page) (mp mgclients.MembersPage, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("object",
+				slog.String("kind", objectKind),
+				slog.String("id", objectID),
+			),
+			slog.Group("page",
+				slog.Uint64("limit", cp.Limit),
+				slog.Uint64("offset", cp.Offset),
+				slog.Uint64("total", mp.Total),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.WarnContext(ctx, "List object permissions failed", args...)
+			return
+		}
+		lm.logger.InfoContext(ctx, "List object permissions completed successfully", args...)
+	}(time.Now())
+	return lm.svc.ListObjectPermissions(ctx, session, objectKind, objectID, cp)
+}
+
 // Identify logs the identify request. It logs the time it took to complete the request.
 func (lm *loggingMiddleware) Identify(ctx context.Context, session authn.Session) (id string, err error) {
 	defer func(begin time.Time) {
@@ -410,10 +752,10 @@ func (lm *loggingMiddleware) Identify(ctx context.Context, session authn.Session
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Identify user failed", args...)
+			lm.logger.WarnContext(ctx, "Identify user failed", args...)
 			return
 		}
-		lm.logger.Info("Identify user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Identify user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.Identify(ctx, session)
 }
@@ -426,10 +768,10 @@ func (lm *loggingMiddleware) OAuthCallback(ctx context.Context, client mgclients
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("OAuth callback failed", args...)
+			lm.logger.WarnContext(ctx, "OAuth callback failed", args...)
 			return
 		}
-		lm.logger.Info("OAuth callback completed successfully", args...)
+		lm.logger.InfoContext(ctx, "OAuth callback completed successfully", args...)
 	}(time.Now())
 	return lm.svc.OAuthCallback(ctx, client)
 }
@@ -443,10 +785,10 @@ func (lm *loggingMiddleware) DeleteClient(ctx context.Context, session authn.Ses
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Delete user failed to complete successfully", args...)
+			lm.logger.WarnContext(ctx, "Delete user failed to complete successfully", args...)
 			return
 		}
-		lm.logger.Info("Delete user completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Delete user completed successfully", args...)
 	}(time.Now())
 	return lm.svc.DeleteClient(ctx, session, id)
 }
@@ -460,10 +802,10 @@ func (lm *loggingMiddleware) OAuthAddClientPolicy(ctx context.Context, client mg
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
-			lm.logger.Warn("Add client policy failed", args...)
+			lm.logger.WarnContext(ctx, "Add client policy failed", args...)
 			return
 		}
-		lm.logger.Info("Add client policy completed successfully", args...)
+		lm.logger.InfoContext(ctx, "Add client policy completed successfully", args...)
 	}(time.Now())
 	return lm.svc.OAuthAddClientPolicy(ctx, client)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/pkg/authn"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/prometheus"
 	"github.com/absmach/magistrala/users"
 	"github.com/go-kit/kit/metrics"
 )
@@ -18,12 +19,14 @@ var _ users.Service = (*metricsMiddleware)(nil)
 
 type metricsMiddleware struct {
 	counter metrics.Counter
-	latency metrics.Histogram
+	latency *prometheus.Histogram
 	svc     users.Service
 }
 
 // MetricsMiddleware instruments policies service by tracking request count and latency.
-func MetricsMiddleware(svc users.Service, counter metrics.Counter, latency metrics.Histogram) users.Service {
+// The latency is tracked with the IDs of the sampled traces as exemplars, so the
+// middleware must be wrapped by the tracing middleware.
+func MetricsMiddleware(svc users.Service, counter metrics.Counter, latency *prometheus.Histogram) users.Service {
 	return &metricsMiddleware{
 		counter: counter,
 		latency: latency,
@@ -35,34 +38,79 @@ func MetricsMiddleware(svc users.Service, counter metrics.Counter, latency metri
 func (ms *metricsMiddleware) RegisterClient(ctx context.Context, session authn.Session, client mgclients.Client, selfRegister bool) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "register_client").Add(1)
-		ms.latency.With("method", "register_client").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "register_client").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.RegisterClient(ctx, session, client, selfRegister)
 }
 
 // IssueToken instruments IssueToken method with metrics.
-func (ms *metricsMiddleware) IssueToken(ctx context.Context, identity, secret string) (*magistrala.Token, error) {
+func (ms *metricsMiddleware) IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (*magistrala.Token, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "issue_token").Add(1)
-		ms.latency.With("method", "issue_token").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "issue_token").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
-	return ms.svc.IssueToken(ctx, identity, secret)
+	return ms.svc.IssueToken(ctx, identity, secret, mfaCode, audience)
+}
+
+// EnrollMFA instruments EnrollMFA method with metrics.
+func (ms *metricsMiddleware) EnrollMFA(ctx context.Context, identity, secret string) (users.MFASecret, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "enroll_mfa").Add(1)
+		ms.latency.With("method", "enroll_mfa").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.EnrollMFA(ctx, identity, secret)
+}
+
+// ConfirmMFA instruments ConfirmMFA method with metrics.
+func (ms *metricsMiddleware) ConfirmMFA(ctx context.Context, identity, secret, code string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "confirm_mfa").Add(1)
+		ms.latency.With("method", "confirm_mfa").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ConfirmMFA(ctx, identity, secret, code)
 }
 
 // RefreshToken instruments RefreshToken method with metrics.
 func (ms *metricsMiddleware) RefreshToken(ctx context.Context, session authn.Session, refreshToken string) (token *magistrala.Token, err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "refresh_token").Add(1)
-		ms.latency.With("method", "refresh_token").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "refresh_token").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.RefreshToken(ctx, session, refreshToken)
 }
 
+// RequestDeviceCode instruments RequestDeviceCode method with metrics.
+func (ms *metricsMiddleware) RequestDeviceCode(ctx context.Context, audience string) (users.DeviceAuthorization, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "request_device_code").Add(1)
+		ms.latency.With("method", "request_device_code").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.RequestDeviceCode(ctx, audience)
+}
+
+// AuthorizeDevice instruments AuthorizeDevice method with metrics.
+func (ms *metricsMiddleware) AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "authorize_device").Add(1)
+		ms.latency.With("method", "authorize_device").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.AuthorizeDevice(ctx, session, userCode, mfaCode, approve)
+}
+
+// DeviceToken instruments DeviceToken method with metrics.
+func (ms *metricsMiddleware) DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "device_token").Add(1)
+		ms.latency.With("method", "device_token").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.DeviceToken(ctx, deviceCode)
+}
+
 // ViewClient instruments ViewClient method with metrics.
 func (ms *metricsMiddleware) ViewClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "view_client").Add(1)
-		ms.latency.With("method", "view_client").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "view_client").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.ViewClient(ctx, session, id)
 }
@@ -71,7 +119,7 @@ func (ms *metricsMiddleware) ViewClient(ctx context.Context, session authn.Sessi
 func (ms *metricsMiddleware) ViewProfile(ctx context.Context, session authn.Session) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "view_profile").Add(1)
-		ms.latency.With("method", "view_profile").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "view_profile").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.ViewProfile(ctx, session)
 }
@@ -80,16 +128,25 @@ func (ms *metricsMiddleware) ViewProfile(ctx context.Context, session authn.Sess
 func (ms *metricsMiddleware) ListClients(ctx context.Context, session authn.Session, pm mgclients.Page) (mgclients.ClientsPage, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_clients").Add(1)
-		ms.latency.With("method", "list_clients").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "list_clients").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.ListClients(ctx, session, pm)
 }
 
+// ListChanges instruments ListChanges method with metrics.
+func (ms *metricsMiddleware) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (users.ChangesPage, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_changes").Add(1)
+		ms.latency.With("method", "list_changes").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ListChanges(ctx, session, since, cursor, limit)
+}
+
 // SearchUsers instruments SearchClients method with metrics.
 func (ms *metricsMiddleware) SearchUsers(ctx context.Context, pm mgclients.Page) (mp mgclients.ClientsPage, err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "search_users").Add(1)
-		ms.latency.With("method", "search_users").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "search_users").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.SearchUsers(ctx, pm)
 }
@@ -98,7 +155,7 @@ func (ms *metricsMiddleware) SearchUsers(ctx context.Context, pm mgclients.Page)
 func (ms *metricsMiddleware) UpdateClient(ctx context.Context, session authn.Session, client mgclients.Client) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_client_name_and_metadata").Add(1)
-		ms.latency.With("method", "update_client_name_and_metadata").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "update_client_name_and_metadata").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.UpdateClient(ctx, session, client)
 }
@@ -107,7 +164,7 @@ func (ms *metricsMiddleware) UpdateClient(ctx context.Context, session authn.Ses
 func (ms *metricsMiddleware) UpdateClientTags(ctx context.Context, session authn.Session, client mgclients.Client) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_client_tags").Add(1)
-		ms.latency.With("method", "update_client_tags").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "update_client_tags").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.UpdateClientTags(ctx, session, client)
 }
@@ -116,16 +173,79 @@ func (ms *metricsMiddleware) UpdateClientTags(ctx context.Context, session authn
 func (ms *metricsMiddleware) UpdateClientIdentity(ctx context.Context, session authn.Session, id, identity string) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_client_identity").Add(1)
-		ms.latency.With("method", "update_client_identity").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "update_client_identity").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.UpdateClientIdentity(ctx, session, id, identity)
 }
 
+// ConfirmIdentity instruments ConfirmIdentity method with metrics.
+func (ms *metricsMiddleware) ConfirmIdentity(ctx context.Context, token string) (mgclients.Client, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "confirm_identity").Add(1)
+		ms.latency.With("method", "confirm_identity").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ConfirmIdentity(ctx, token)
+}
+
+// VerifyPhone instruments VerifyPhone method with metrics.
+func (ms *metricsMiddleware) VerifyPhone(ctx context.Context, identity, code string) (mgclients.Client, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "verify_phone").Add(1)
+		ms.latency.With("method", "verify_phone").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.VerifyPhone(ctx, identity, code)
+}
+
+// SendPhoneCode instruments SendPhoneCode method with metrics.
+func (ms *metricsMiddleware) SendPhoneCode(ctx context.Context, identity string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "send_phone_code").Add(1)
+		ms.latency.With("method", "send_phone_code").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.SendPhoneCode(ctx, identity)
+}
+
+// ResendVerification instruments ResendVerification method with metrics.
+func (ms *metricsMiddleware) ResendVerification(ctx context.Context, identity string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "resend_verification").Add(1)
+		ms.latency.With("method", "resend_verification").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ResendVerification(ctx, identity)
+}
+
+// RequestDeletion instruments RequestDeletion method with metrics.
+func (ms *metricsMiddleware) RequestDeletion(ctx context.Context, session authn.Session) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "request_deletion").Add(1)
+		ms.latency.With("method", "request_deletion").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.RequestDeletion(ctx, session)
+}
+
+// ConfirmDeletion instruments ConfirmDeletion method with metrics.
+func (ms *metricsMiddleware) ConfirmDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "confirm_deletion").Add(1)
+		ms.latency.With("method", "confirm_deletion").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ConfirmDeletion(ctx, token)
+}
+
+// CancelDeletion instruments CancelDeletion method with metrics.
+func (ms *metricsMiddleware) CancelDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "cancel_deletion").Add(1)
+		ms.latency.With("method", "cancel_deletion").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.CancelDeletion(ctx, token)
+}
+
 // UpdateClientSecret instruments UpdateClientSecret method with metrics.
 func (ms *metricsMiddleware) UpdateClientSecret(ctx context.Context, session authn.Session, oldSecret, newSecret string) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_client_secret").Add(1)
-		ms.latency.With("method", "update_client_secret").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "update_client_secret").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.UpdateClientSecret(ctx, session, oldSecret, newSecret)
 }
@@ -134,7 +254,7 @@ func (ms *metricsMiddleware) UpdateClientSecret(ctx context.Context, session aut
 func (ms *metricsMiddleware) GenerateResetToken(ctx context.Context, email, host string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "generate_reset_token").Add(1)
-		ms.latency.With("method", "generate_reset_token").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "generate_reset_token").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.GenerateResetToken(ctx, email, host)
 }
@@ -143,7 +263,7 @@ func (ms *metricsMiddleware) GenerateResetToken(ctx context.Context, email, host
 func (ms *metricsMiddleware) ResetSecret(ctx context.Context, session authn.Session, secret string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "reset_secret").Add(1)
-		ms.latency.With("method", "reset_secret").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "reset_secret").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.ResetSecret(ctx, session, secret)
 }
@@ -152,25 +272,43 @@ func (ms *metricsMiddleware) ResetSecret(ctx context.Context, session authn.Sess
 func (ms *metricsMiddleware) SendPasswordReset(ctx context.Context, host, email, user, token string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "send_password_reset").Add(1)
-		ms.latency.With("method", "send_password_reset").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "send_password_reset").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.SendPasswordReset(ctx, host, email, user, token)
 }
 
+// PreviewEmail instruments PreviewEmail method with metrics.
+func (ms *metricsMiddleware) PreviewEmail(ctx context.Context, session authn.Session, name string, data users.EmailData) (users.EmailPreview, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "preview_email").Add(1)
+		ms.latency.With("method", "preview_email").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.PreviewEmail(ctx, session, name, data)
+}
+
 // UpdateClientRole instruments UpdateClientRole method with metrics.
 func (ms *metricsMiddleware) UpdateClientRole(ctx context.Context, session authn.Session, client mgclients.Client) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "update_client_role").Add(1)
-		ms.latency.With("method", "update_client_role").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "update_client_role").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.UpdateClientRole(ctx, session, client)
 }
 
+// UpdateClientsRole instruments UpdateClientsRole method with metrics.
+func (ms *metricsMiddleware) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role mgclients.Role) ([]users.RoleUpdate, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "update_clients_role").Add(1)
+		ms.latency.With("method", "update_clients_role").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.UpdateClientsRole(ctx, session, ids, role)
+}
+
 // EnableClient instruments EnableClient method with metrics.
 func (ms *metricsMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "enable_client").Add(1)
-		ms.latency.With("method", "enable_client").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "enable_client").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.EnableClient(ctx, session, id)
 }
@@ -179,25 +317,43 @@ func (ms *metricsMiddleware) EnableClient(ctx context.Context, session authn.Ses
 func (ms *metricsMiddleware) DisableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "disable_client").Add(1)
-		ms.latency.With("method", "disable_client").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "disable_client").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.DisableClient(ctx, session, id)
 }
 
+// DisableInactiveClient instruments DisableInactiveClient method with metrics.
+func (ms *metricsMiddleware) DisableInactiveClient(ctx context.Context, id string) (mgclients.Client, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "disable_inactive_client").Add(1)
+		ms.latency.With("method", "disable_inactive_client").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.DisableInactiveClient(ctx, id)
+}
+
 // ListMembers instruments ListMembers method with metrics.
 func (ms *metricsMiddleware) ListMembers(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mp mgclients.MembersPage, err error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_members").Add(1)
-		ms.latency.With("method", "list_members").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "list_members").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.ListMembers(ctx, session, objectKind, objectID, pm)
 }
 
+// ListObjectPermissions instruments ListObjectPermissions method with metrics.
+func (ms *metricsMiddleware) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mp mgclients.MembersPage, err error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_object_permissions").Add(1)
+		ms.latency.With("method", "list_object_permissions").Observe(ctx, time.Since(begin).Seconds())
+	}(time.Now())
+	return ms.svc.ListObjectPermissions(ctx, session, objectKind, objectID, pm)
+}
+
 // Identify instruments Identify method with metrics.
 func (ms *metricsMiddleware) Identify(ctx context.Context, session authn.Session) (string, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "identify").Add(1)
-		ms.latency.With("method", "identify").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "identify").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.Identify(ctx, session)
 }
@@ -206,7 +362,7 @@ func (ms *metricsMiddleware) Identify(ctx context.Context, session authn.Session
 func (ms *metricsMiddleware) OAuthCallback(ctx context.Context, client mgclients.Client) (mgclients.Client, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "oauth_callback").Add(1)
-		ms.latency.With("method", "oauth_callback").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "oauth_callback").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.OAuthCallback(ctx, client)
 }
@@ -215,7 +371,7 @@ func (ms *metricsMiddleware) OAuthCallback(ctx context.Context, client mgclients
 func (ms *metricsMiddleware) DeleteClient(ctx context.Context, session authn.Session, id string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "delete_client").Add(1)
-		ms.latency.With("method", "delete_client").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "delete_client").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.DeleteClient(ctx, session, id)
 }
@@ -224,7 +380,7 @@ func (ms *metricsMiddleware) DeleteClient(ctx context.Context, session authn.Ses
 func (ms *metricsMiddleware) OAuthAddClientPolicy(ctx context.Context, client mgclients.Client) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "add_client_policy").Add(1)
-		ms.latency.With("method", "add_client_policy").Observe(time.Since(begin).Seconds())
+		ms.latency.With("method", "add_client_policy").Observe(ctx, time.Since(begin).Seconds())
 	}(time.Now())
 	return ms.svc.OAuthAddClientPolicy(ctx, client)
 }
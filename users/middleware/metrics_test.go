@@ -0,0 +1,107 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/prometheus"
+	"github.com/absmach/magistrala/users/middleware"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/absmach/magistrala/users/tracing"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const openMetricsType = "application/openmetrics-text; version=1.0.0"
+
+func TestMetricsExemplars(t *testing.T) {
+	cases := []struct {
+		desc      string
+		namespace string
+		sampler   sdktrace.Sampler
+		exemplar  bool
+	}{
+		{
+			desc:      "view profile in sampled trace",
+			namespace: "sampled",
+			sampler:   sdktrace.AlwaysSample(),
+			exemplar:  true,
+		},
+		{
+			desc:      "view profile in unsampled trace",
+			namespace: "unsampled",
+			sampler:   sdktrace.NeverSample(),
+			exemplar:  false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(tc.sampler))
+			defer func() { _ = tp.Shutdown(context.Background()) }()
+
+			svc := new(mocks.Service)
+			counter, latency := prometheus.MakeTracedMetrics(tc.namespace, "api")
+			msvc := middleware.MetricsMiddleware(svc, counter, latency)
+			tsvc := tracing.New(msvc, tp.Tracer("users"))
+
+			// The trace of the request is started by the HTTP handler.
+			ctx, span := tp.Tracer("http").Start(context.Background(), "view_profile")
+			traceID := span.SpanContext().TraceID().String()
+			session := authn.Session{UserID: "user"}
+			svc.On("ViewProfile", mock.Anything, session).Return(mgclients.Client{}, nil)
+			_, err := tsvc.ViewProfile(ctx, session)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			span.End()
+
+			metrics := scrape(t)
+			series := fmt.Sprintf("%s_api_request_latency_microseconds_bucket{method=\"view_profile\"", tc.namespace)
+			exemplar := fmt.Sprintf("# {%s=\"%s\"}", prometheus.TraceIDLabel, traceID)
+			var observed, found bool
+			for _, line := range strings.Split(metrics, "\n") {
+				if !strings.HasPrefix(line, series) {
+					continue
+				}
+				observed = true
+				if strings.Contains(line, " # {") {
+					found = found || strings.Contains(line, exemplar)
+					assert.True(t, tc.exemplar, fmt.Sprintf("%s: unexpected exemplar in %s", tc.desc, line))
+				}
+			}
+			assert.True(t, observed, fmt.Sprintf("%s: expected the request latency to be observed", tc.desc))
+			assert.Equal(t, tc.exemplar, found, fmt.Sprintf("%s: expected exemplar with trace ID %s to be %t", tc.desc, traceID, tc.exemplar))
+		})
+	}
+}
+
+// scrape returns the metrics of the default registry the way Prometheus
+// scrapes them with the exemplars enabled.
+func scrape(t *testing.T) string {
+	ts := httptest.NewServer(promhttp.HandlerFor(stdprometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, http.NoBody)
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating request %s", err))
+	req.Header.Set("Accept", openMetricsType)
+	res, err := ts.Client().Do(req)
+	require.Nil(t, err, fmt.Sprintf("unexpected error scraping metrics %s", err))
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err, fmt.Sprintf("unexpected error reading metrics %s", err))
+
+	return string(body)
+}
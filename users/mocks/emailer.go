@@ -4,24 +4,165 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	time "time"
+
+	users "github.com/absmach/magistrala/users"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Emailer is an autogenerated mock type for the Emailer type
 type Emailer struct {
 	mock.Mock
 }
 
-// SendPasswordReset provides a mock function with given fields: To, host, user, token
-func (_m *Emailer) SendPasswordReset(To []string, host string, user string, token string) error {
-	ret := _m.Called(To, host, user, token)
+// PreviewEmail provides a mock function with given fields: name, data
+func (_m *Emailer) PreviewEmail(name string, data users.EmailData) (users.EmailPreview, error) {
+	ret := _m.Called(name, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewEmail")
+	}
+
+	var r0 users.EmailPreview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, users.EmailData) (users.EmailPreview, error)); ok {
+		return rf(name, data)
+	}
+	if rf, ok := ret.Get(0).(func(string, users.EmailData) users.EmailPreview); ok {
+		r0 = rf(name, data)
+	} else {
+		r0 = ret.Get(0).(users.EmailPreview)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, users.EmailData) error); ok {
+		r1 = rf(name, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendDeletionConfirmation provides a mock function with given fields: To, user, token
+func (_m *Emailer) SendDeletionConfirmation(To []string, user string, token string) error {
+	ret := _m.Called(To, user, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendDeletionConfirmation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string, string) error); ok {
+		r0 = rf(To, user, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendIdentityChanged provides a mock function with given fields: To, user, identity
+func (_m *Emailer) SendIdentityChanged(To []string, user string, identity string) error {
+	ret := _m.Called(To, user, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendIdentityChanged")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string, string) error); ok {
+		r0 = rf(To, user, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendIdentityConfirmation provides a mock function with given fields: To, user, token
+func (_m *Emailer) SendIdentityConfirmation(To []string, user string, token string) error {
+	ret := _m.Called(To, user, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendIdentityConfirmation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string, string) error); ok {
+		r0 = rf(To, user, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendInactivityWarning provides a mock function with given fields: To, user, disableAt
+func (_m *Emailer) SendInactivityWarning(To []string, user string, disableAt time.Time) error {
+	ret := _m.Called(To, user, disableAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendInactivityWarning")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string, time.Time) error); ok {
+		r0 = rf(To, user, disableAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendPasswordReset provides a mock function with given fields: To, host, user, token, branding
+func (_m *Emailer) SendPasswordReset(To []string, host string, user string, token string, branding users.EmailBranding) error {
+	ret := _m.Called(To, host, user, token, branding)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendPasswordReset")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]string, string, string, string) error); ok {
-		r0 = rf(To, host, user, token)
+	if rf, ok := ret.Get(0).(func([]string, string, string, string, users.EmailBranding) error); ok {
+		r0 = rf(To, host, user, token, branding)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendVerification provides a mock function with given fields: To, user, token
+func (_m *Emailer) SendVerification(To []string, user string, token string) error {
+	ret := _m.Called(To, user, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string, string) error); ok {
+		r0 = rf(To, user, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendWelcome provides a mock function with given fields: To, user
+func (_m *Emailer) SendWelcome(To []string, user string) error {
+	ret := _m.Called(To, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendWelcome")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string, string) error); ok {
+		r0 = rf(To, user)
 	} else {
 		r0 = ret.Error(0)
 	}
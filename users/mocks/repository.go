@@ -10,6 +10,10 @@ import (
 	clients "github.com/absmach/magistrala/pkg/clients"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	users "github.com/absmach/magistrala/users"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -63,6 +67,52 @@ func (_m *Repository) CheckSuperAdmin(ctx context.Context, adminID string) error
 	return r0
 }
 
+// ConfirmMFA provides a mock function with given fields: ctx, id, step, at
+func (_m *Repository) ConfirmMFA(ctx context.Context, id string, step int64, at time.Time) error {
+	ret := _m.Called(ctx, id, step, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmMFA")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, time.Time) error); ok {
+		r0 = rf(ctx, id, step, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DisableInactive provides a mock function with given fields: ctx, id, q, at
+func (_m *Repository) DisableInactive(ctx context.Context, id string, q users.InactivityQuery, at time.Time) (clients.Client, error) {
+	ret := _m.Called(ctx, id, q, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableInactive")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, users.InactivityQuery, time.Time) (clients.Client, error)); ok {
+		return rf(ctx, id, q, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, users.InactivityQuery, time.Time) clients.Client); ok {
+		r0 = rf(ctx, id, q, at)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, users.InactivityQuery, time.Time) error); ok {
+		r1 = rf(ctx, id, q, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, id
 func (_m *Repository) Delete(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -81,6 +131,106 @@ func (_m *Repository) Delete(ctx context.Context, id string) error {
 	return r0
 }
 
+// IncrementPendingAttempts provides a mock function with given fields: ctx, clientID, limit
+func (_m *Repository) IncrementPendingAttempts(ctx context.Context, clientID string, limit int) (int, error) {
+	ret := _m.Called(ctx, clientID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementPendingAttempts")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (int, error)); ok {
+		return rf(ctx, clientID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) int); ok {
+		r0 = rf(ctx, clientID, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, clientID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveExpiredPendingDevices provides a mock function with given fields: ctx, before
+func (_m *Repository) RemoveExpiredPendingDevices(ctx context.Context, before time.Time) error {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveExpiredPendingDevices")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemovePendingDeletion provides a mock function with given fields: ctx, clientID
+func (_m *Repository) RemovePendingDeletion(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemovePendingDeletion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemovePendingDevice provides a mock function with given fields: ctx, deviceCode
+func (_m *Repository) RemovePendingDevice(ctx context.Context, deviceCode string) error {
+	ret := _m.Called(ctx, deviceCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemovePendingDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, deviceCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemovePendingIdentity provides a mock function with given fields: ctx, clientID
+func (_m *Repository) RemovePendingIdentity(ctx context.Context, clientID string) error {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemovePendingIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RetrieveAll provides a mock function with given fields: ctx, pm
 func (_m *Repository) RetrieveAll(ctx context.Context, pm clients.Page) (clients.ClientsPage, error) {
 	ret := _m.Called(ctx, pm)
@@ -193,6 +343,264 @@ func (_m *Repository) RetrieveByIdentity(ctx context.Context, identity string) (
 	return r0, r1
 }
 
+// RetrieveChanges provides a mock function with given fields: ctx, q
+func (_m *Repository) RetrieveChanges(ctx context.Context, q users.ChangesQuery) ([]users.Change, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveChanges")
+	}
+
+	var r0 []users.Change
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.ChangesQuery) ([]users.Change, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, users.ChangesQuery) []users.Change); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]users.Change)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, users.ChangesQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrieveInactive provides a mock function with given fields: ctx, q
+func (_m *Repository) RetrieveInactive(ctx context.Context, q users.InactivityQuery) ([]clients.Client, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveInactive")
+	}
+
+	var r0 []clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.InactivityQuery) ([]clients.Client, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, users.InactivityQuery) []clients.Client); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]clients.Client)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, users.InactivityQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrieveLinkedProviders provides a mock function with given fields: ctx, clientID
+func (_m *Repository) RetrieveLinkedProviders(ctx context.Context, clientID string) ([]string, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveLinkedProviders")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrieveMFA provides a mock function with given fields: ctx, id
+func (_m *Repository) RetrieveMFA(ctx context.Context, id string) (users.MFAEnrollment, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveMFA")
+	}
+
+	var r0 users.MFAEnrollment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.MFAEnrollment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.MFAEnrollment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(users.MFAEnrollment)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrievePendingDeletion provides a mock function with given fields: ctx, token
+func (_m *Repository) RetrievePendingDeletion(ctx context.Context, token string) (users.PendingDeletion, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrievePendingDeletion")
+	}
+
+	var r0 users.PendingDeletion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.PendingDeletion, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.PendingDeletion); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(users.PendingDeletion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrievePendingDevice provides a mock function with given fields: ctx, deviceCode
+func (_m *Repository) RetrievePendingDevice(ctx context.Context, deviceCode string) (users.PendingDevice, error) {
+	ret := _m.Called(ctx, deviceCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrievePendingDevice")
+	}
+
+	var r0 users.PendingDevice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.PendingDevice, error)); ok {
+		return rf(ctx, deviceCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.PendingDevice); ok {
+		r0 = rf(ctx, deviceCode)
+	} else {
+		r0 = ret.Get(0).(users.PendingDevice)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, deviceCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrievePendingDeviceByUserCode provides a mock function with given fields: ctx, userCode
+func (_m *Repository) RetrievePendingDeviceByUserCode(ctx context.Context, userCode string) (users.PendingDevice, error) {
+	ret := _m.Called(ctx, userCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrievePendingDeviceByUserCode")
+	}
+
+	var r0 users.PendingDevice
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.PendingDevice, error)); ok {
+		return rf(ctx, userCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.PendingDevice); ok {
+		r0 = rf(ctx, userCode)
+	} else {
+		r0 = ret.Get(0).(users.PendingDevice)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrievePendingIdentity provides a mock function with given fields: ctx, token
+func (_m *Repository) RetrievePendingIdentity(ctx context.Context, token string) (users.PendingIdentity, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrievePendingIdentity")
+	}
+
+	var r0 users.PendingIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.PendingIdentity, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.PendingIdentity); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(users.PendingIdentity)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetrievePendingVerification provides a mock function with given fields: ctx, identity
+func (_m *Repository) RetrievePendingVerification(ctx context.Context, identity string) (users.PendingIdentity, error) {
+	ret := _m.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrievePendingVerification")
+	}
+
+	var r0 users.PendingIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.PendingIdentity, error)); ok {
+		return rf(ctx, identity)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.PendingIdentity); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Get(0).(users.PendingIdentity)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, client
 func (_m *Repository) Save(ctx context.Context, client clients.Client) (clients.Client, error) {
 	ret := _m.Called(ctx, client)
@@ -221,6 +629,114 @@ func (_m *Repository) Save(ctx context.Context, client clients.Client) (clients.
 	return r0, r1
 }
 
+// SaveInactivityWarning provides a mock function with given fields: ctx, id, at
+func (_m *Repository) SaveInactivityWarning(ctx context.Context, id string, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveInactivityWarning")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLinkedProvider provides a mock function with given fields: ctx, clientID, provider
+func (_m *Repository) SaveLinkedProvider(ctx context.Context, clientID string, provider string) error {
+	ret := _m.Called(ctx, clientID, provider)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveLinkedProvider")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, clientID, provider)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveMFASecret provides a mock function with given fields: ctx, id, secret
+func (_m *Repository) SaveMFASecret(ctx context.Context, id string, secret string) error {
+	ret := _m.Called(ctx, id, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveMFASecret")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, secret)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePendingDeletion provides a mock function with given fields: ctx, pd
+func (_m *Repository) SavePendingDeletion(ctx context.Context, pd users.PendingDeletion) error {
+	ret := _m.Called(ctx, pd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePendingDeletion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.PendingDeletion) error); ok {
+		r0 = rf(ctx, pd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePendingDevice provides a mock function with given fields: ctx, pd
+func (_m *Repository) SavePendingDevice(ctx context.Context, pd users.PendingDevice) error {
+	ret := _m.Called(ctx, pd)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePendingDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.PendingDevice) error); ok {
+		r0 = rf(ctx, pd)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SavePendingIdentity provides a mock function with given fields: ctx, pi
+func (_m *Repository) SavePendingIdentity(ctx context.Context, pi users.PendingIdentity) error {
+	ret := _m.Called(ctx, pi)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePendingIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.PendingIdentity) error); ok {
+		r0 = rf(ctx, pi)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SearchClients provides a mock function with given fields: ctx, pm
 func (_m *Repository) SearchClients(ctx context.Context, pm clients.Page) (clients.ClientsPage, error) {
 	ret := _m.Called(ctx, pm)
@@ -305,6 +821,24 @@ func (_m *Repository) UpdateIdentity(ctx context.Context, client clients.Client)
 	return r0, r1
 }
 
+// UpdateLastLogin provides a mock function with given fields: ctx, id, at
+func (_m *Repository) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLastLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateRole provides a mock function with given fields: ctx, client
 func (_m *Repository) UpdateRole(ctx context.Context, client clients.Client) (clients.Client, error) {
 	ret := _m.Called(ctx, client)
@@ -333,6 +867,36 @@ func (_m *Repository) UpdateRole(ctx context.Context, client clients.Client) (cl
 	return r0, r1
 }
 
+// UpdateRoles provides a mock function with given fields: ctx, cs
+func (_m *Repository) UpdateRoles(ctx context.Context, cs []clients.Client) ([]clients.Client, error) {
+	ret := _m.Called(ctx, cs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRoles")
+	}
+
+	var r0 []clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []clients.Client) ([]clients.Client, error)); ok {
+		return rf(ctx, cs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []clients.Client) []clients.Client); ok {
+		r0 = rf(ctx, cs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]clients.Client)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []clients.Client) error); ok {
+		r1 = rf(ctx, cs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateSecret provides a mock function with given fields: ctx, client
 func (_m *Repository) UpdateSecret(ctx context.Context, client clients.Client) (clients.Client, error) {
 	ret := _m.Called(ctx, client)
@@ -361,6 +925,34 @@ func (_m *Repository) UpdateSecret(ctx context.Context, client clients.Client) (
 	return r0, r1
 }
 
+// UpdateSecretIfUnchanged provides a mock function with given fields: ctx, client, current
+func (_m *Repository) UpdateSecretIfUnchanged(ctx context.Context, client clients.Client, current string) (clients.Client, error) {
+	ret := _m.Called(ctx, client, current)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSecretIfUnchanged")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, clients.Client, string) (clients.Client, error)); ok {
+		return rf(ctx, client, current)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, clients.Client, string) clients.Client); ok {
+		r0 = rf(ctx, client, current)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, clients.Client, string) error); ok {
+		r1 = rf(ctx, client, current)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateTags provides a mock function with given fields: ctx, client
 func (_m *Repository) UpdateTags(ctx context.Context, client clients.Client) (clients.Client, error) {
 	ret := _m.Called(ctx, client)
@@ -389,6 +981,24 @@ func (_m *Repository) UpdateTags(ctx context.Context, client clients.Client) (cl
 	return r0, r1
 }
 
+// UseMFAStep provides a mock function with given fields: ctx, id, step
+func (_m *Repository) UseMFAStep(ctx context.Context, id string, step int64) error {
+	ret := _m.Called(ctx, id, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseMFAStep")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, id, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
@@ -13,6 +13,10 @@ import (
 	magistrala "github.com/absmach/magistrala"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	users "github.com/absmach/magistrala/users"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -20,6 +24,126 @@ type Service struct {
 	mock.Mock
 }
 
+// AuthorizeDevice provides a mock function with given fields: ctx, session, userCode, mfaCode, approve
+func (_m *Service) AuthorizeDevice(ctx context.Context, session authn.Session, userCode string, mfaCode string, approve bool) error {
+	ret := _m.Called(ctx, session, userCode, mfaCode, approve)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizeDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, string, bool) error); ok {
+		r0 = rf(ctx, session, userCode, mfaCode, approve)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CancelDeletion provides a mock function with given fields: ctx, token
+func (_m *Service) CancelDeletion(ctx context.Context, token string) (clients.Client, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelDeletion")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (clients.Client, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) clients.Client); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmDeletion provides a mock function with given fields: ctx, token
+func (_m *Service) ConfirmDeletion(ctx context.Context, token string) (clients.Client, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmDeletion")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (clients.Client, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) clients.Client); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmIdentity provides a mock function with given fields: ctx, token
+func (_m *Service) ConfirmIdentity(ctx context.Context, token string) (clients.Client, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmIdentity")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (clients.Client, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) clients.Client); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmMFA provides a mock function with given fields: ctx, identity, secret, code
+func (_m *Service) ConfirmMFA(ctx context.Context, identity string, secret string, code string) error {
+	ret := _m.Called(ctx, identity, secret, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmMFA")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, identity, secret, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteClient provides a mock function with given fields: ctx, session, id
 func (_m *Service) DeleteClient(ctx context.Context, session authn.Session, id string) error {
 	ret := _m.Called(ctx, session, id)
@@ -38,6 +162,36 @@ func (_m *Service) DeleteClient(ctx context.Context, session authn.Session, id s
 	return r0
 }
 
+// DeviceToken provides a mock function with given fields: ctx, deviceCode
+func (_m *Service) DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error) {
+	ret := _m.Called(ctx, deviceCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeviceToken")
+	}
+
+	var r0 *magistrala.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*magistrala.Token, error)); ok {
+		return rf(ctx, deviceCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *magistrala.Token); ok {
+		r0 = rf(ctx, deviceCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*magistrala.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, deviceCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DisableClient provides a mock function with given fields: ctx, session, id
 func (_m *Service) DisableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
 	ret := _m.Called(ctx, session, id)
@@ -66,6 +220,34 @@ func (_m *Service) DisableClient(ctx context.Context, session authn.Session, id
 	return r0, r1
 }
 
+// DisableInactiveClient provides a mock function with given fields: ctx, id
+func (_m *Service) DisableInactiveClient(ctx context.Context, id string) (clients.Client, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableInactiveClient")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (clients.Client, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) clients.Client); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EnableClient provides a mock function with given fields: ctx, session, id
 func (_m *Service) EnableClient(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
 	ret := _m.Called(ctx, session, id)
@@ -94,6 +276,34 @@ func (_m *Service) EnableClient(ctx context.Context, session authn.Session, id s
 	return r0, r1
 }
 
+// EnrollMFA provides a mock function with given fields: ctx, identity, secret
+func (_m *Service) EnrollMFA(ctx context.Context, identity string, secret string) (users.MFASecret, error) {
+	ret := _m.Called(ctx, identity, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnrollMFA")
+	}
+
+	var r0 users.MFASecret
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (users.MFASecret, error)); ok {
+		return rf(ctx, identity, secret)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) users.MFASecret); ok {
+		r0 = rf(ctx, identity, secret)
+	} else {
+		r0 = ret.Get(0).(users.MFASecret)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, identity, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateResetToken provides a mock function with given fields: ctx, email, host
 func (_m *Service) GenerateResetToken(ctx context.Context, email string, host string) error {
 	ret := _m.Called(ctx, email, host)
@@ -140,9 +350,9 @@ func (_m *Service) Identify(ctx context.Context, session authn.Session) (string,
 	return r0, r1
 }
 
-// IssueToken provides a mock function with given fields: ctx, identity, secret
-func (_m *Service) IssueToken(ctx context.Context, identity string, secret string) (*magistrala.Token, error) {
-	ret := _m.Called(ctx, identity, secret)
+// IssueToken provides a mock function with given fields: ctx, identity, secret, mfaCode, audience
+func (_m *Service) IssueToken(ctx context.Context, identity string, secret string, mfaCode string, audience string) (*magistrala.Token, error) {
+	ret := _m.Called(ctx, identity, secret, mfaCode, audience)
 
 	if len(ret) == 0 {
 		panic("no return value specified for IssueToken")
@@ -150,19 +360,47 @@ func (_m *Service) IssueToken(ctx context.Context, identity string, secret strin
 
 	var r0 *magistrala.Token
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*magistrala.Token, error)); ok {
-		return rf(ctx, identity, secret)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (*magistrala.Token, error)); ok {
+		return rf(ctx, identity, secret, mfaCode, audience)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *magistrala.Token); ok {
-		r0 = rf(ctx, identity, secret)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) *magistrala.Token); ok {
+		r0 = rf(ctx, identity, secret, mfaCode, audience)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*magistrala.Token)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, identity, secret)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, identity, secret, mfaCode, audience)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListChanges provides a mock function with given fields: ctx, session, since, cursor, limit
+func (_m *Service) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (users.ChangesPage, error) {
+	ret := _m.Called(ctx, session, since, cursor, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListChanges")
+	}
+
+	var r0 users.ChangesPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, time.Time, string, uint64) (users.ChangesPage, error)); ok {
+		return rf(ctx, session, since, cursor, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, time.Time, string, uint64) users.ChangesPage); ok {
+		r0 = rf(ctx, session, since, cursor, limit)
+	} else {
+		r0 = ret.Get(0).(users.ChangesPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, time.Time, string, uint64) error); ok {
+		r1 = rf(ctx, session, since, cursor, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -226,6 +464,34 @@ func (_m *Service) ListMembers(ctx context.Context, session authn.Session, objec
 	return r0, r1
 }
 
+// ListObjectPermissions provides a mock function with given fields: ctx, session, objectKind, objectID, pm
+func (_m *Service) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind string, objectID string, pm clients.Page) (clients.MembersPage, error) {
+	ret := _m.Called(ctx, session, objectKind, objectID, pm)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListObjectPermissions")
+	}
+
+	var r0 clients.MembersPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, string, clients.Page) (clients.MembersPage, error)); ok {
+		return rf(ctx, session, objectKind, objectID, pm)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, string, clients.Page) clients.MembersPage); ok {
+		r0 = rf(ctx, session, objectKind, objectID, pm)
+	} else {
+		r0 = ret.Get(0).(clients.MembersPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, string, string, clients.Page) error); ok {
+		r1 = rf(ctx, session, objectKind, objectID, pm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // OAuthAddClientPolicy provides a mock function with given fields: ctx, client
 func (_m *Service) OAuthAddClientPolicy(ctx context.Context, client clients.Client) error {
 	ret := _m.Called(ctx, client)
@@ -272,6 +538,34 @@ func (_m *Service) OAuthCallback(ctx context.Context, client clients.Client) (cl
 	return r0, r1
 }
 
+// PreviewEmail provides a mock function with given fields: ctx, session, name, data
+func (_m *Service) PreviewEmail(ctx context.Context, session authn.Session, name string, data users.EmailData) (users.EmailPreview, error) {
+	ret := _m.Called(ctx, session, name, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewEmail")
+	}
+
+	var r0 users.EmailPreview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, users.EmailData) (users.EmailPreview, error)); ok {
+		return rf(ctx, session, name, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, string, users.EmailData) users.EmailPreview); ok {
+		r0 = rf(ctx, session, name, data)
+	} else {
+		r0 = ret.Get(0).(users.EmailPreview)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, string, users.EmailData) error); ok {
+		r1 = rf(ctx, session, name, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RefreshToken provides a mock function with given fields: ctx, session, refreshToken
 func (_m *Service) RefreshToken(ctx context.Context, session authn.Session, refreshToken string) (*magistrala.Token, error) {
 	ret := _m.Called(ctx, session, refreshToken)
@@ -330,6 +624,70 @@ func (_m *Service) RegisterClient(ctx context.Context, session authn.Session, cl
 	return r0, r1
 }
 
+// RequestDeletion provides a mock function with given fields: ctx, session
+func (_m *Service) RequestDeletion(ctx context.Context, session authn.Session) error {
+	ret := _m.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestDeletion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestDeviceCode provides a mock function with given fields: ctx, audience
+func (_m *Service) RequestDeviceCode(ctx context.Context, audience string) (users.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, audience)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestDeviceCode")
+	}
+
+	var r0 users.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (users.DeviceAuthorization, error)); ok {
+		return rf(ctx, audience)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) users.DeviceAuthorization); ok {
+		r0 = rf(ctx, audience)
+	} else {
+		r0 = ret.Get(0).(users.DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, audience)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResendVerification provides a mock function with given fields: ctx, identity
+func (_m *Service) ResendVerification(ctx context.Context, identity string) error {
+	ret := _m.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResendVerification")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResetSecret provides a mock function with given fields: ctx, session, secret
 func (_m *Service) ResetSecret(ctx context.Context, session authn.Session, secret string) error {
 	ret := _m.Called(ctx, session, secret)
@@ -394,6 +752,24 @@ func (_m *Service) SendPasswordReset(ctx context.Context, host string, email str
 	return r0
 }
 
+// SendPhoneCode provides a mock function with given fields: ctx, identity
+func (_m *Service) SendPhoneCode(ctx context.Context, identity string) error {
+	ret := _m.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendPhoneCode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateClient provides a mock function with given fields: ctx, session, client
 func (_m *Service) UpdateClient(ctx context.Context, session authn.Session, client clients.Client) (clients.Client, error) {
 	ret := _m.Called(ctx, session, client)
@@ -534,6 +910,64 @@ func (_m *Service) UpdateClientTags(ctx context.Context, session authn.Session,
 	return r0, r1
 }
 
+// UpdateClientsRole provides a mock function with given fields: ctx, session, ids, role
+func (_m *Service) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role clients.Role) ([]users.RoleUpdate, error) {
+	ret := _m.Called(ctx, session, ids, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateClientsRole")
+	}
+
+	var r0 []users.RoleUpdate
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, []string, clients.Role) ([]users.RoleUpdate, error)); ok {
+		return rf(ctx, session, ids, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, authn.Session, []string, clients.Role) []users.RoleUpdate); ok {
+		r0 = rf(ctx, session, ids, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]users.RoleUpdate)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, authn.Session, []string, clients.Role) error); ok {
+		r1 = rf(ctx, session, ids, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyPhone provides a mock function with given fields: ctx, identity, code
+func (_m *Service) VerifyPhone(ctx context.Context, identity string, code string) (clients.Client, error) {
+	ret := _m.Called(ctx, identity, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyPhone")
+	}
+
+	var r0 clients.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (clients.Client, error)); ok {
+		return rf(ctx, identity, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) clients.Client); ok {
+		r0 = rf(ctx, identity, code)
+	} else {
+		r0 = ret.Get(0).(clients.Client)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, identity, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ViewClient provides a mock function with given fields: ctx, session, id
 func (_m *Service) ViewClient(ctx context.Context, session authn.Session, id string) (clients.Client, error) {
 	ret := _m.Called(ctx, session, id)
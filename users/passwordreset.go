@@ -0,0 +1,144 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+// maxResetRequests is the number of identities whose reset requests are
+// tracked in memory, so requests for arbitrary identities can't grow the
+// tracked requests without bound.
+const maxResetRequests = 10000
+
+var (
+	errResetRequests = errors.New("password reset was requested too many times")
+
+	// ErrResetRequestsFull indicates that no more identities can be tracked
+	// until the tracked requests expire.
+	ErrResetRequestsFull = errors.New("too many identities requested password reset")
+)
+
+// PasswordReset defines how the repeated password reset requests of the same
+// identity are coalesced.
+type PasswordReset struct {
+	// Window is the period after a reset e-mail is sent in which the
+	// requests of the same identity reuse the sent token instead of sending
+	// another e-mail. It must be shorter than the validity of the recovery
+	// token. Zero disables coalescing.
+	Window time.Duration
+
+	// MaxRequests is the number of the requests of the same identity
+	// accepted within the window. Zero means no limit.
+	MaxRequests int
+
+	// Cache tracks the requests, so they are coalesced across the service
+	// instances sharing it. Nil cache tracks the requests in memory.
+	Cache ResetCache
+}
+
+// ResetCache tracks the password reset requests per identity.
+//
+//go:generate mockery --name ResetCache --output=./mocks --filename resets.go --quiet --note "Copyright (c) Abstract Machines"
+type ResetCache interface {
+	// Claim records the reset request of the identity and returns the number
+	// of the requests of the identity within the window, which starts with
+	// the first of them.
+	Claim(ctx context.Context, identity string, window time.Duration) (int, error)
+
+	// Release removes the requests of the identity, so the next request
+	// starts a new window.
+	Release(ctx context.Context, identity string) error
+}
+
+type resetRequest struct {
+	sentAt time.Time
+	count  int
+}
+
+var _ ResetCache = (*resetRequests)(nil)
+
+// resetRequests tracks the password reset requests of at most
+// maxResetRequests identities in memory.
+type resetRequests struct {
+	mu       sync.Mutex
+	requests map[string]*resetRequest
+}
+
+func newResetRequests() *resetRequests {
+	return &resetRequests{
+		requests: make(map[string]*resetRequest),
+	}
+}
+
+func (rr *resetRequests) Claim(_ context.Context, identity string, window time.Duration) (int, error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	now := time.Now()
+	if r, ok := rr.requests[identity]; ok && now.Sub(r.sentAt) < window {
+		r.count++
+		return r.count, nil
+	}
+	if len(rr.requests) >= maxResetRequests {
+		for id, r := range rr.requests {
+			if now.Sub(r.sentAt) >= window {
+				delete(rr.requests, id)
+			}
+		}
+	}
+	// Requests which can't be tracked are rejected, since sending them
+	// would bypass the coalescing and the limit.
+	if len(rr.requests) >= maxResetRequests {
+		return 0, ErrResetRequestsFull
+	}
+	rr.requests[identity] = &resetRequest{sentAt: now, count: 1}
+
+	return 1, nil
+}
+
+func (rr *resetRequests) Release(_ context.Context, identity string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	delete(rr.requests, identity)
+
+	return nil
+}
+
+// claimReset records the reset request of the identity and reports whether
+// the reset e-mail is to be sent, or an error if the identity exceeded the
+// requests of the window or the request can't be tracked.
+func (svc service) claimReset(ctx context.Context, identity string) (bool, error) {
+	cfg := svc.config.PasswordReset
+	if cfg.Window <= 0 {
+		return true, nil
+	}
+
+	count, err := cfg.Cache.Claim(ctx, identity, cfg.Window)
+	switch {
+	case err != nil:
+		return false, err
+	case cfg.MaxRequests > 0 && count > cfg.MaxRequests:
+		return false, errResetRequests
+	default:
+		return count == 1, nil
+	}
+}
+
+// releaseReset forgets the request of the identity whose reset e-mail
+// failed, so the next request sends it again.
+func (svc service) releaseReset(ctx context.Context, identity string) {
+	cfg := svc.config.PasswordReset
+	if cfg.Window <= 0 {
+		return
+	}
+	// If the release fails, the next requests are coalesced until the
+	// window expires, the same as if the e-mail was sent.
+	_ = cfg.Cache.Release(ctx, identity)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+const (
+	// DefPhoneCodeTTL is the default validity period of the phone verification code.
+	DefPhoneCodeTTL = 10 * time.Minute
+
+	// phoneCodeResendInterval is the minimum time between two verification
+	// codes sent to the same phone, since every SMS is charged.
+	phoneCodeResendInterval = time.Minute
+
+	// maxPhoneCodeAttempts is the number of wrong codes after which the
+	// verification code is invalidated. The attempts are counted per pending
+	// verification, not per code, so resending the code doesn't give more
+	// guesses.
+	maxPhoneCodeAttempts = 5
+
+	// phoneCodeLockout is the time since the last code was sent after which
+	// a new code can be sent to the phone that ran out of attempts.
+	phoneCodeLockout = time.Hour
+
+	phoneCodeDigits = 6
+)
+
+var (
+	// ErrInvalidPhone indicates that the phone number is not a valid E.164 number.
+	ErrInvalidPhone = errors.New("invalid E.164 phone number")
+
+	errPhoneUnsupported  = errors.New("phone identities are not supported")
+	errPhoneCode         = errors.New("invalid or expired phone verification code")
+	errPhoneCodeAttempts = errors.New("too many phone verification attempts, try again later")
+	errPhoneCodeResend   = errors.New("phone verification code was sent recently")
+
+	phoneRegExp      = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	phoneSeparators  = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+	maxPhoneCodeSize = big.NewInt(1_000_000)
+)
+
+// SMSSender sends SMS messages.
+//
+//go:generate mockery --name SMSSender --output=./mocks --filename sms.go --quiet --note "Copyright (c) Abstract Machines"
+type SMSSender interface {
+	// SendVerificationCode sends the phone verification code to the phone number.
+	SendVerificationCode(to, code string) error
+}
+
+// PhoneVerification defines how phone identities are verified.
+type PhoneVerification struct {
+	// Sender sends the verification codes. Nil sender disables phone
+	// identities.
+	Sender SMSSender
+
+	// CodeTTL is the validity period of the verification code.
+	CodeTTL time.Duration
+}
+
+// IsPhone reports whether the identity is a phone number. Phone numbers
+// start with the plus sign, which is not valid at the start of an e-mail.
+func IsPhone(identity string) bool {
+	return strings.HasPrefix(strings.TrimSpace(identity), "+")
+}
+
+// NormalizePhone returns the E.164 form of the phone number, without the
+// spaces, dashes, dots and parentheses used for readability. The normalized
+// form is stored as the identity, so it's the key phone identities are
+// compared by.
+func NormalizePhone(phone string) (string, error) {
+	normalized := phoneSeparators.Replace(strings.TrimSpace(phone))
+	if !phoneRegExp.MatchString(normalized) {
+		return "", errors.Wrap(ErrInvalidPhone, fmt.Errorf("phone number %q", phone))
+	}
+
+	return normalized, nil
+}
+
+func (svc service) VerifyPhone(ctx context.Context, identity, code string) (mgclients.Client, error) {
+	phone, err := NormalizePhone(identity)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+
+	pi, err := svc.clients.RetrievePendingVerification(ctx, phone)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(errPhoneCode, err))
+	}
+	if pi.Attempts >= maxPhoneCodeAttempts {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errPhoneCodeAttempts)
+	}
+	if subtle.ConstantTimeCompare([]byte(pi.Token), []byte(hashPhoneCode(phone, code))) != 1 {
+		// The attempts are stored with the pending verification, so they
+		// are shared by all the service instances.
+		if _, err := svc.clients.IncrementPendingAttempts(ctx, pi.ClientID, maxPhoneCodeAttempts); err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+		}
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errPhoneCode)
+	}
+	if time.Now().After(pi.ExpiresAt) {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errPhoneCode)
+	}
+
+	cli := mgclients.Client{
+		ID:        pi.ClientID,
+		Status:    mgclients.EnabledStatus,
+		UpdatedAt: time.Now(),
+		UpdatedBy: pi.ClientID,
+	}
+	cli, err = svc.clients.ChangeStatus(ctx, cli)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	if err := svc.clients.RemovePendingIdentity(ctx, pi.ClientID); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+
+	return cli, nil
+}
+
+func (svc service) SendPhoneCode(ctx context.Context, identity string) error {
+	if svc.config.PhoneVerification.Sender == nil {
+		return errors.Wrap(svcerr.ErrMalformedEntity, errPhoneUnsupported)
+	}
+	phone, err := NormalizePhone(identity)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+
+	pi, err := svc.clients.RetrievePendingVerification(ctx, phone)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	if time.Since(pi.CreatedAt) < phoneCodeResendInterval {
+		return errors.Wrap(svcerr.ErrMalformedEntity, errPhoneCodeResend)
+	}
+	attempts, err := phoneCodeAttempts(pi)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+
+	return svc.sendPhoneCode(ctx, pi.ClientID, phone, attempts)
+}
+
+// phoneCodeAttempts returns the wrong code attempts the new code of the
+// pending verification starts with. The attempts are carried over to the new
+// code, unless the phone ran out of attempts and the lockout has passed.
+func phoneCodeAttempts(pi PendingIdentity) (int, error) {
+	if pi.Attempts < maxPhoneCodeAttempts {
+		return pi.Attempts, nil
+	}
+	if time.Since(pi.CreatedAt) < phoneCodeLockout {
+		return 0, errPhoneCodeAttempts
+	}
+
+	return 0, nil
+}
+
+// sendPhoneCode stores the new verification code of the user phone and
+// sends it. The stored code replaces the previous one.
+func (svc service) sendPhoneCode(ctx context.Context, clientID, phone string, attempts int) error {
+	code, err := generatePhoneCode()
+	if err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	now := time.Now()
+	pi := PendingIdentity{
+		ClientID:  clientID,
+		Identity:  phone,
+		Token:     hashPhoneCode(phone, code),
+		CreatedAt: now,
+		ExpiresAt: now.Add(svc.config.PhoneVerification.CodeTTL),
+		Attempts:  attempts,
+	}
+	if err := svc.clients.SavePendingIdentity(ctx, pi); err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+
+	return svc.config.PhoneVerification.Sender.SendVerificationCode(phone, code)
+}
+
+func generatePhoneCode() (string, error) {
+	n, err := rand.Int(rand.Reader, maxPhoneCodeSize)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", phoneCodeDigits, n), nil
+}
+
+// hashPhoneCode hashes the code together with the phone, so the short codes
+// of different phones don't collide and only the hash is stored.
+func hashPhoneCode(phone, code string) string {
+	return hashIdentityToken(phone + ":" + code)
+}
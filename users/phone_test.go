@@ -0,0 +1,382 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const phone = "+38761123456"
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		desc  string
+		phone string
+		norm  string
+		err   error
+	}{
+		{desc: "normalize E.164 phone", phone: phone, norm: phone},
+		{desc: "normalize formatted phone", phone: " +387 (61) 123-456 ", norm: phone},
+		{desc: "normalize phone with dots", phone: "+1.202.555.0100", norm: "+12025550100"},
+		{desc: "normalize phone without plus sign", phone: "38761123456", err: users.ErrInvalidPhone},
+		{desc: "normalize phone with leading zero", phone: "+038761123456", err: users.ErrInvalidPhone},
+		{desc: "normalize too long phone", phone: "+1234567890123456", err: users.ErrInvalidPhone},
+		{desc: "normalize phone with letters", phone: "+38761ABC456", err: users.ErrInvalidPhone},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			norm, err := users.NormalizePhone(tc.phone)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.norm, norm, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.norm, norm))
+		})
+	}
+}
+
+func TestRegisterClientPhone(t *testing.T) {
+	cases := []struct {
+		desc     string
+		identity string
+		sender   bool
+		sendErr  error
+		err      error
+	}{
+		{
+			desc:     "register client with phone",
+			identity: "+387 61 123-456",
+			sender:   true,
+		},
+		{
+			desc:     "register client with phone without SMS sender",
+			identity: phone,
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "register client with invalid phone",
+			identity: "+0123",
+			sender:   true,
+			err:      users.ErrInvalidPhone,
+		},
+		{
+			desc:     "register client with phone and failed SMS",
+			identity: phone,
+			sender:   true,
+			sendErr:  errors.New("gateway unavailable"),
+			err:      svcerr.ErrCreateEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			policies := new(policymocks.Service)
+			sms := new(mocks.SMSSender)
+			cfg := users.Config{}
+			if tc.sender {
+				cfg.PhoneVerification.Sender = sms
+			}
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, policies, new(mocks.Emailer), phasher, idProvider, cfg)
+
+			var saved mgclients.Client
+			var pending users.PendingIdentity
+			policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+			policies.On("DeletePolicies", context.Background(), mock.Anything).Return(nil)
+			cRepo.On("Save", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+				saved = c
+				return c, nil
+			})
+			cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, pi users.PendingIdentity) error {
+				pending = pi
+				return nil
+			})
+			cRepo.On("Delete", context.Background(), mock.Anything).Return(nil)
+			sms.On("SendVerificationCode", phone, mock.Anything).Return(tc.sendErr)
+
+			cli := client
+			cli.Credentials.Identity = tc.identity
+			_, err := svc.RegisterClient(context.Background(), authn.Session{}, cli, true)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			switch {
+			case err == nil:
+				assert.Equal(t, phone, saved.Credentials.Identity, fmt.Sprintf("%s: expected normalized identity %s got %s", tc.desc, phone, saved.Credentials.Identity))
+				assert.Equal(t, mgclients.DisabledStatus, saved.Status, fmt.Sprintf("%s: expected client to be disabled until verified", tc.desc))
+				assert.Equal(t, saved.ID, pending.ClientID, fmt.Sprintf("%s: expected pending verification of the client", tc.desc))
+				code := sms.Calls[0].Arguments.String(1)
+				assert.Len(t, code, 6, fmt.Sprintf("%s: expected 6 digit code got %s", tc.desc, code))
+				assert.NotEqual(t, code, pending.Token, fmt.Sprintf("%s: expected only code hash to be stored", tc.desc))
+				cRepo.AssertNotCalled(t, "Delete", context.Background(), mock.Anything)
+			case tc.sendErr != nil:
+				cRepo.AssertCalled(t, "Delete", context.Background(), saved.ID)
+			default:
+				cRepo.AssertNotCalled(t, "Save", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPhoneVerification(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	policies := new(policymocks.Service)
+	sms := new(mocks.SMSSender)
+	tokenClient := new(authmocks.TokenServiceClient)
+	cfg := users.Config{PhoneVerification: users.PhoneVerification{Sender: sms}}
+	svc := users.NewService(tokenClient, cRepo, policies, new(mocks.Emailer), phasher, idProvider, cfg)
+
+	var stored mgclients.Client
+	var pending users.PendingIdentity
+	var code string
+	policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+	cRepo.On("Save", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		stored = c
+		return c, nil
+	})
+	cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, pi users.PendingIdentity) error {
+		pending = pi
+		return nil
+	})
+	cRepo.On("RetrievePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, hash string) (users.PendingIdentity, error) {
+		if pending.Token == "" || hash != pending.Token {
+			return users.PendingIdentity{}, repoerr.ErrNotFound
+		}
+		return pending, nil
+	})
+	cRepo.On("RetrievePendingVerification", context.Background(), mock.Anything).Return(func(_ context.Context, identity string) (users.PendingIdentity, error) {
+		if pending.Token == "" || identity != pending.Identity {
+			return users.PendingIdentity{}, repoerr.ErrNotFound
+		}
+		return pending, nil
+	})
+	cRepo.On("IncrementPendingAttempts", context.Background(), mock.Anything, mock.Anything).Return(func(_ context.Context, _ string, _ int) (int, error) {
+		pending.Attempts++
+		return pending.Attempts, nil
+	})
+	cRepo.On("RemovePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, _ string) error {
+		pending = users.PendingIdentity{}
+		return nil
+	})
+	cRepo.On("ChangeStatus", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		stored.Status = c.Status
+		return stored, nil
+	})
+	cRepo.On("RetrieveByIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, identity string) (mgclients.Client, error) {
+		if identity != stored.Credentials.Identity || stored.Status != mgclients.EnabledStatus {
+			return mgclients.Client{}, repoerr.ErrNotFound
+		}
+		return stored, nil
+	})
+	sms.On("SendVerificationCode", phone, mock.Anything).Return(func(_, c string) error {
+		code = c
+		return nil
+	})
+	cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cRepo.On("RetrieveMFA", mock.Anything, mock.Anything).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
+	tokenClient.On("Issue", mock.Anything, mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, nil)
+
+	cli := client
+	cli.Credentials.Identity = phone
+	_, err := svc.RegisterClient(context.Background(), authn.Session{}, cli, true)
+	assert.Nil(t, err, fmt.Sprintf("register client with phone: unexpected error %s", err))
+
+	_, err = svc.IssueToken(context.Background(), phone, secret, "", "")
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("login with unverified phone: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	err = svc.SendPhoneCode(context.Background(), phone)
+	assert.True(t, errors.Contains(err, svcerr.ErrMalformedEntity), fmt.Sprintf("resend code too early: expected %s got %s", svcerr.ErrMalformedEntity, err))
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	_, err = svc.VerifyPhone(context.Background(), phone, wrong)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify phone with wrong code: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	_, err = svc.VerifyPhone(context.Background(), "+12025550100", code)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify other phone with the code: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	verified, err := svc.VerifyPhone(context.Background(), "+387 61 123 456", code)
+	assert.Nil(t, err, fmt.Sprintf("verify phone: unexpected error %s", err))
+	assert.Equal(t, mgclients.EnabledStatus, verified.Status, fmt.Sprintf("verify phone: expected status %s got %s", mgclients.EnabledStatus, verified.Status))
+
+	_, err = svc.VerifyPhone(context.Background(), phone, code)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify phone with used code: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	token, err := svc.IssueToken(context.Background(), "+387-61-123-456", secret, "", "")
+	assert.Nil(t, err, fmt.Sprintf("login with verified phone: unexpected error %s", err))
+	assert.Equal(t, validToken, token.GetAccessToken(), fmt.Sprintf("login with verified phone: expected token %s got %s", validToken, token.GetAccessToken()))
+}
+
+// newPendingPhone returns the service with the phone waiting for verification
+// and the code sent to it. The pending verification is kept by the mocked
+// repository.
+func newPendingPhone(t *testing.T) (users.Service, *mocks.Repository, *users.PendingIdentity, string) {
+	cRepo := new(mocks.Repository)
+	sms := new(mocks.SMSSender)
+	cfg := users.Config{PhoneVerification: users.PhoneVerification{Sender: sms, CodeTTL: time.Minute}}
+	svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, cfg)
+
+	pending := &users.PendingIdentity{ClientID: client.ID, Identity: phone, CreatedAt: time.Now().Add(-2 * time.Minute)}
+	var code string
+	cRepo.On("RetrievePendingVerification", context.Background(), phone).Return(func(_ context.Context, _ string) (users.PendingIdentity, error) {
+		return *pending, nil
+	})
+	cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, pi users.PendingIdentity) error {
+		*pending = pi
+		return nil
+	})
+	cRepo.On("IncrementPendingAttempts", context.Background(), client.ID, mock.Anything).Return(func(_ context.Context, _ string, limit int) (int, error) {
+		pending.Attempts++
+		if pending.Attempts >= limit {
+			pending.ExpiresAt = pending.CreatedAt
+		}
+		return pending.Attempts, nil
+	})
+	cRepo.On("ChangeStatus", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		return c, nil
+	})
+	cRepo.On("RemovePendingIdentity", context.Background(), client.ID).Return(nil)
+	sms.On("SendVerificationCode", phone, mock.Anything).Return(func(_, c string) error {
+		code = c
+		return nil
+	})
+
+	err := svc.SendPhoneCode(context.Background(), phone)
+	assert.Nil(t, err, fmt.Sprintf("send phone code: unexpected error %s", err))
+
+	return svc, cRepo, pending, code
+}
+
+func TestVerifyPhoneAttempts(t *testing.T) {
+	svc, cRepo, pending, code := newPendingPhone(t)
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < 3; i++ {
+		_, err := svc.VerifyPhone(context.Background(), phone, wrong)
+		assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify phone with wrong code: expected %s got %s", svcerr.ErrAuthentication, err))
+	}
+
+	// Resending the code keeps the attempts of the previous codes.
+	pending.CreatedAt = time.Now().Add(-2 * time.Minute)
+	err := svc.SendPhoneCode(context.Background(), phone)
+	assert.Nil(t, err, fmt.Sprintf("resend phone code: unexpected error %s", err))
+	assert.Equal(t, 3, pending.Attempts, fmt.Sprintf("resend phone code: expected 3 attempts got %d", pending.Attempts))
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.VerifyPhone(context.Background(), phone, wrong)
+		assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify phone with wrong code: expected %s got %s", svcerr.ErrAuthentication, err))
+	}
+	cRepo.AssertNumberOfCalls(t, "IncrementPendingAttempts", 5)
+	assert.False(t, time.Now().Before(pending.ExpiresAt), "verify phone with wrong codes: expected code to be invalidated")
+
+	pending.CreatedAt = time.Now().Add(-2 * time.Minute)
+	err = svc.SendPhoneCode(context.Background(), phone)
+	assert.True(t, errors.Contains(err, svcerr.ErrMalformedEntity), fmt.Sprintf("resend phone code after too many attempts: expected %s got %s", svcerr.ErrMalformedEntity, err))
+
+	_, err = svc.VerifyPhone(context.Background(), phone, wrong)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify phone after too many attempts: expected %s got %s", svcerr.ErrAuthentication, err))
+	cRepo.AssertNumberOfCalls(t, "IncrementPendingAttempts", 5)
+	cRepo.AssertNotCalled(t, "ChangeStatus", context.Background(), mock.Anything)
+
+	// After the lockout, the new code starts without attempts.
+	pending.CreatedAt = time.Now().Add(-2 * time.Hour)
+	err = svc.SendPhoneCode(context.Background(), phone)
+	assert.Nil(t, err, fmt.Sprintf("resend phone code after lockout: unexpected error %s", err))
+	assert.Equal(t, 0, pending.Attempts, fmt.Sprintf("resend phone code after lockout: expected 0 attempts got %d", pending.Attempts))
+}
+
+func TestVerifyPhoneExpired(t *testing.T) {
+	svc, cRepo, pending, code := newPendingPhone(t)
+
+	pending.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err := svc.VerifyPhone(context.Background(), phone, code)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("verify phone with expired code: expected %s got %s", svcerr.ErrAuthentication, err))
+	cRepo.AssertNotCalled(t, "ChangeStatus", context.Background(), mock.Anything)
+}
+
+func TestSendPhoneCode(t *testing.T) {
+	cases := []struct {
+		desc     string
+		identity string
+		sender   bool
+		pending  users.PendingIdentity
+		retrErr  error
+		sent     bool
+		err      error
+	}{
+		{
+			desc:     "send phone code",
+			identity: phone,
+			sender:   true,
+			pending:  users.PendingIdentity{ClientID: client.ID, Identity: phone, CreatedAt: time.Now().Add(-2 * time.Minute)},
+			sent:     true,
+		},
+		{
+			desc:     "send phone code without SMS sender",
+			identity: phone,
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "send phone code to invalid phone",
+			identity: "invalid",
+			sender:   true,
+			err:      svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:     "send phone code to verified phone",
+			identity: phone,
+			sender:   true,
+			retrErr:  repoerr.ErrNotFound,
+			err:      svcerr.ErrViewEntity,
+		},
+		{
+			desc:     "send phone code too early",
+			identity: phone,
+			sender:   true,
+			pending:  users.PendingIdentity{ClientID: client.ID, Identity: phone, CreatedAt: time.Now()},
+			err:      svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			sms := new(mocks.SMSSender)
+			cfg := users.Config{}
+			if tc.sender {
+				cfg.PhoneVerification.Sender = sms
+			}
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, cfg)
+
+			cRepo.On("RetrievePendingVerification", context.Background(), phone).Return(tc.pending, tc.retrErr)
+			cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(nil)
+			sms.On("SendVerificationCode", phone, mock.Anything).Return(nil)
+			err := svc.SendPhoneCode(context.Background(), tc.identity)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			switch tc.sent {
+			case true:
+				sms.AssertCalled(t, "SendVerificationCode", phone, mock.Anything)
+			default:
+				sms.AssertNotCalled(t, "SendVerificationCode", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -5,41 +5,29 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"sort"
+	"time"
 
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	pgclients "github.com/absmach/magistrala/pkg/clients/postgres"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	"github.com/absmach/magistrala/pkg/postgres"
+	"github.com/absmach/magistrala/users"
+	"github.com/jmoiron/sqlx"
 )
 
-var _ mgclients.Repository = (*clientRepo)(nil)
+var _ users.Repository = (*clientRepo)(nil)
 
 type clientRepo struct {
 	pgclients.Repository
 }
 
-// Repository defines the required dependencies for Client repository.
-//
-//go:generate mockery --name Repository --output=../mocks --filename repository.go --quiet --note "Copyright (c) Abstract Machines"
-type Repository interface {
-	mgclients.Repository
-
-	// Save persists the client account. A non-nil error is returned to indicate
-	// operation failure.
-	Save(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
-
-	RetrieveByID(ctx context.Context, id string) (mgclients.Client, error)
-
-	UpdateRole(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
-
-	CheckSuperAdmin(ctx context.Context, adminID string) error
-}
-
 // NewRepository instantiates a PostgreSQL
-// implementation of Clients repository.
-func NewRepository(db postgres.Database) Repository {
+// implementation of users repository.
+func NewRepository(db postgres.Database) users.Repository {
 	return &clientRepo{
 		Repository: pgclients.Repository{DB: db},
 	}
@@ -74,6 +62,77 @@ func (repo clientRepo) Save(ctx context.Context, c mgclients.Client) (mgclients.
 	return client, nil
 }
 
+func (repo clientRepo) RetrieveMFA(ctx context.Context, id string) (users.MFAEnrollment, error) {
+	q := `SELECT client_id, secret, enrolled_at, last_step FROM mfa_enrollments WHERE client_id = $1`
+
+	var (
+		enrollment users.MFAEnrollment
+		enrolledAt sql.NullTime
+		lastStep   sql.NullInt64
+	)
+	err := repo.DB.QueryRowxContext(ctx, q, id).Scan(&enrollment.ClientID, &enrollment.Secret, &enrolledAt, &lastStep)
+	switch {
+	case err == sql.ErrNoRows:
+		return users.MFAEnrollment{}, repoerr.ErrNotFound
+	case err != nil:
+		return users.MFAEnrollment{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	if enrolledAt.Valid {
+		enrollment.EnrolledAt = enrolledAt.Time
+	}
+	enrollment.LastStep = lastStep.Int64
+
+	return enrollment, nil
+}
+
+func (repo clientRepo) SaveMFASecret(ctx context.Context, id, secret string) error {
+	q := `INSERT INTO mfa_enrollments (client_id, secret) VALUES ($1, $2)
+        ON CONFLICT (client_id) DO UPDATE SET secret = EXCLUDED.secret, last_step = NULL
+        WHERE mfa_enrollments.enrolled_at IS NULL`
+
+	res, err := repo.DB.ExecContext(ctx, q, id, secret)
+	if err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repoerr.ErrConflict
+	}
+
+	return nil
+}
+
+func (repo clientRepo) ConfirmMFA(ctx context.Context, id string, step int64, at time.Time) error {
+	q := `UPDATE mfa_enrollments SET enrolled_at = $3, last_step = $2
+        WHERE client_id = $1 AND enrolled_at IS NULL`
+
+	res, err := repo.DB.ExecContext(ctx, q, id, step, at)
+	if err != nil {
+		return postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repoerr.ErrNotFound
+	}
+
+	return nil
+}
+
+func (repo clientRepo) UseMFAStep(ctx context.Context, id string, step int64) error {
+	// The step is recorded only if it's later than the last used one, so
+	// the concurrent logins with the same code can't both succeed.
+	q := `UPDATE mfa_enrollments SET last_step = $2
+        WHERE client_id = $1 AND enrolled_at IS NOT NULL AND (last_step IS NULL OR last_step < $2)`
+
+	res, err := repo.DB.ExecContext(ctx, q, id, step)
+	if err != nil {
+		return postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return repoerr.ErrConflict
+	}
+
+	return nil
+}
+
 func (repo clientRepo) CheckSuperAdmin(ctx context.Context, adminID string) error {
 	q := "SELECT 1 FROM clients WHERE id = $1 AND role = $2"
 	rows, err := repo.DB.QueryContext(ctx, q, adminID, mgclients.AdminRole)
@@ -93,7 +152,7 @@ func (repo clientRepo) CheckSuperAdmin(ctx context.Context, adminID string) erro
 }
 
 func (repo clientRepo) RetrieveByID(ctx context.Context, id string) (mgclients.Client, error) {
-	q := `SELECT id, name, tags, identity, secret, metadata, created_at, updated_at, updated_by, status, role
+	q := `SELECT id, name, tags, identity, secret, metadata, created_at, updated_at, updated_by, status, role, disabled_at
         FROM clients WHERE id = :id`
 
 	dbc := pgclients.DBClient{
@@ -128,9 +187,22 @@ func (repo clientRepo) RetrieveAll(ctx context.Context, pm mgclients.Page) (mgcl
 	if err != nil {
 		return mgclients.ClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
 	}
+	if pm.OAuthProvider != "" {
+		lq := "c.id IN (SELECT client_id FROM linked_providers WHERE provider = :oauth_provider)"
+		switch query {
+		case "":
+			query = "WHERE " + lq
+		default:
+			query = fmt.Sprintf("%s AND %s", query, lq)
+		}
+	}
+	order, err := pgclients.OrderQuery(pm)
+	if err != nil {
+		return mgclients.ClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
+	}
 
 	q := fmt.Sprintf(`SELECT c.id, c.name, c.tags, c.identity, c.metadata,  c.status, c.role,
-					c.created_at, c.updated_at, COALESCE(c.updated_by, '') AS updated_by FROM clients c %s ORDER BY c.created_at LIMIT :limit OFFSET :offset;`, query)
+					c.created_at, c.updated_at, COALESCE(c.updated_by, '') AS updated_by FROM clients c %s %s LIMIT :limit OFFSET :offset;`, query, order)
 
 	dbPage, err := pgclients.ToDBClientsPage(pm)
 	if err != nil {
@@ -172,6 +244,14 @@ func (repo clientRepo) RetrieveAll(ctx context.Context, pm mgclients.Page) (mgcl
 		},
 	}
 
+	if pm.CountUnfiltered {
+		total, err := repo.CountUnfiltered(ctx, pm)
+		if err != nil {
+			return mgclients.ClientsPage{}, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		page.TotalUnfiltered = &total
+	}
+
 	return page, nil
 }
 
@@ -201,3 +281,696 @@ func (repo clientRepo) UpdateRole(ctx context.Context, client mgclients.Client)
 
 	return pgclients.ToClient(dbc)
 }
+
+func (repo clientRepo) UpdateRoles(ctx context.Context, cs []mgclients.Client) ([]mgclients.Client, error) {
+	query := `UPDATE clients SET role = :role, updated_at = :updated_at, updated_by = :updated_by
+        WHERE id = :id AND status = :status
+        RETURNING id, name, tags, identity, metadata, status, role, created_at, updated_at, updated_by`
+
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return []mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+
+	var clients []mgclients.Client
+	for _, c := range cs {
+		client, err := updateRole(ctx, tx, query, c)
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				return []mgclients.Client{}, errors.Wrap(errRollback, err)
+			}
+			return []mgclients.Client{}, err
+		}
+		clients = append(clients, client)
+	}
+	if err := tx.Commit(); err != nil {
+		return []mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+
+	return clients, nil
+}
+
+func updateRole(ctx context.Context, tx *sqlx.Tx, query string, client mgclients.Client) (mgclients.Client, error) {
+	dbc, err := pgclients.ToDBClient(client)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+
+	row, err := tx.NamedQuery(query, dbc)
+	if err != nil {
+		return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	defer row.Close()
+
+	if ok := row.Next(); !ok {
+		if err := row.Err(); err != nil {
+			return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+		}
+		return mgclients.Client{}, repoerr.ErrNotFound
+	}
+	dbc = pgclients.DBClient{}
+	if err := row.StructScan(&dbc); err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+
+	return pgclients.ToClient(dbc)
+}
+
+type dbPendingIdentity struct {
+	ClientID  string    `db:"client_id"`
+	Identity  string    `db:"identity"`
+	Token     string    `db:"token"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+	Attempts  int       `db:"attempts"`
+}
+
+func (repo clientRepo) UpdateSecretIfUnchanged(ctx context.Context, client mgclients.Client, current string) (mgclients.Client, error) {
+	q := `UPDATE clients SET secret = :secret, updated_at = :updated_at, updated_by = :updated_by
+        WHERE id = :id AND status = :status AND COALESCE(secret, '') = :current_secret
+        RETURNING id, name, tags, identity, metadata, status, created_at, updated_at, updated_by`
+
+	client.Status = mgclients.EnabledStatus
+	dbc, err := pgclients.ToDBClient(client)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+	params := struct {
+		pgclients.DBClient
+		CurrentSecret string `db:"current_secret"`
+	}{
+		DBClient:      dbc,
+		CurrentSecret: current,
+	}
+
+	row, err := repo.DB.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	defer row.Close()
+
+	if row.Next() {
+		dbc = pgclients.DBClient{}
+		if err := row.StructScan(&dbc); err != nil {
+			return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+		}
+		return pgclients.ToClient(dbc)
+	}
+	if err := row.Err(); err != nil {
+		return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+
+	// Nothing was updated, either because the user doesn't exist or is
+	// disabled, or because the secret was changed since it was read.
+	var exists bool
+	if err := repo.DB.QueryRowxContext(ctx, `SELECT EXISTS (SELECT 1 FROM clients WHERE id = $1 AND status = $2)`, client.ID, mgclients.EnabledStatus).Scan(&exists); err != nil {
+		return mgclients.Client{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	if !exists {
+		return mgclients.Client{}, repoerr.ErrNotFound
+	}
+
+	return mgclients.Client{}, repoerr.ErrConcurrentUpdate
+}
+
+func (repo clientRepo) SavePendingIdentity(ctx context.Context, pi users.PendingIdentity) error {
+	q := `INSERT INTO pending_identities (client_id, identity, token, created_at, expires_at, attempts)
+        VALUES (:client_id, :identity, :token, :created_at, :expires_at, :attempts)
+        ON CONFLICT (client_id) DO UPDATE SET identity = EXCLUDED.identity, token = EXCLUDED.token,
+        created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at, attempts = EXCLUDED.attempts`
+
+	if _, err := repo.DB.NamedExecContext(ctx, q, dbPendingIdentity(pi)); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) RetrievePendingIdentity(ctx context.Context, token string) (users.PendingIdentity, error) {
+	q := `SELECT client_id, identity, token, created_at, expires_at, attempts FROM pending_identities WHERE token = :token`
+
+	rows, err := repo.DB.NamedQueryContext(ctx, q, dbPendingIdentity{Token: token})
+	if err != nil {
+		return users.PendingIdentity{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var dbpi dbPendingIdentity
+	if rows.Next() {
+		if err := rows.StructScan(&dbpi); err != nil {
+			return users.PendingIdentity{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+		}
+
+		return users.PendingIdentity(dbpi), nil
+	}
+
+	return users.PendingIdentity{}, repoerr.ErrNotFound
+}
+
+func (repo clientRepo) RetrievePendingVerification(ctx context.Context, identity string) (users.PendingIdentity, error) {
+	q := `SELECT p.client_id, p.identity, p.token, p.created_at, p.expires_at, p.attempts FROM pending_identities p
+        JOIN clients c ON c.id = p.client_id WHERE p.identity = :identity AND c.identity = p.identity`
+
+	rows, err := repo.DB.NamedQueryContext(ctx, q, dbPendingIdentity{Identity: identity})
+	if err != nil {
+		return users.PendingIdentity{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var dbpi dbPendingIdentity
+	if rows.Next() {
+		if err := rows.StructScan(&dbpi); err != nil {
+			return users.PendingIdentity{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+		}
+
+		return users.PendingIdentity(dbpi), nil
+	}
+
+	return users.PendingIdentity{}, repoerr.ErrNotFound
+}
+
+func (repo clientRepo) IncrementPendingAttempts(ctx context.Context, clientID string, limit int) (int, error) {
+	// The expiration is moved to the creation, which is always in the past.
+	q := `UPDATE pending_identities SET attempts = attempts + 1,
+        expires_at = CASE WHEN attempts + 1 >= $2 THEN created_at ELSE expires_at END
+        WHERE client_id = $1 RETURNING attempts`
+
+	var attempts int
+	err := repo.DB.QueryRowxContext(ctx, q, clientID, limit).Scan(&attempts)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, repoerr.ErrNotFound
+	case err != nil:
+		return 0, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+
+	return attempts, nil
+}
+
+func (repo clientRepo) RemovePendingIdentity(ctx context.Context, clientID string) error {
+	q := `DELETE FROM pending_identities WHERE client_id = $1`
+
+	if _, err := repo.DB.ExecContext(ctx, q, clientID); err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) SaveLinkedProvider(ctx context.Context, clientID, provider string) error {
+	q := `INSERT INTO linked_providers (client_id, provider, linked_at) VALUES ($1, $2, $3)
+        ON CONFLICT (client_id, provider) DO NOTHING`
+
+	if _, err := repo.DB.ExecContext(ctx, q, clientID, provider, time.Now().UTC()); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) RetrieveLinkedProviders(ctx context.Context, clientID string) ([]string, error) {
+	q := `SELECT provider FROM linked_providers WHERE client_id = $1 ORDER BY provider`
+
+	rows, err := repo.DB.QueryContext(ctx, q, clientID)
+	if err != nil {
+		return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var providers []string
+	for rows.Next() {
+		var provider string
+		if err := rows.Scan(&provider); err != nil {
+			return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+		}
+		providers = append(providers, provider)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+
+	return providers, nil
+}
+
+type dbPendingDeletion struct {
+	ClientID    string       `db:"client_id"`
+	Token       string       `db:"token"`
+	CreatedAt   time.Time    `db:"created_at"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	ConfirmedAt sql.NullTime `db:"confirmed_at"`
+	PrevStatus  uint8        `db:"prev_status"`
+}
+
+func toDBPendingDeletion(pd users.PendingDeletion) dbPendingDeletion {
+	return dbPendingDeletion{
+		ClientID:    pd.ClientID,
+		Token:       pd.Token,
+		CreatedAt:   pd.CreatedAt,
+		ExpiresAt:   pd.ExpiresAt,
+		ConfirmedAt: sql.NullTime{Time: pd.ConfirmedAt, Valid: !pd.ConfirmedAt.IsZero()},
+		PrevStatus:  uint8(pd.PrevStatus),
+	}
+}
+
+func toPendingDeletion(dbpd dbPendingDeletion) users.PendingDeletion {
+	pd := users.PendingDeletion{
+		ClientID:  dbpd.ClientID,
+		Token:     dbpd.Token,
+		CreatedAt: dbpd.CreatedAt,
+		ExpiresAt:  dbpd.ExpiresAt,
+		PrevStatus: mgclients.Status(dbpd.PrevStatus),
+	}
+	if dbpd.ConfirmedAt.Valid {
+		pd.ConfirmedAt = dbpd.ConfirmedAt.Time
+	}
+
+	return pd
+}
+
+func (repo clientRepo) SavePendingDeletion(ctx context.Context, pd users.PendingDeletion) error {
+	q := `INSERT INTO pending_deletions (client_id, token, created_at, expires_at, confirmed_at, prev_status)
+        VALUES (:client_id, :token, :created_at, :expires_at, :confirmed_at, :prev_status)
+        ON CONFLICT (client_id) DO UPDATE SET token = EXCLUDED.token, created_at = EXCLUDED.created_at,
+        expires_at = EXCLUDED.expires_at, confirmed_at = EXCLUDED.confirmed_at, prev_status = EXCLUDED.prev_status`
+
+	if _, err := repo.DB.NamedExecContext(ctx, q, toDBPendingDeletion(pd)); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) RetrievePendingDeletion(ctx context.Context, token string) (users.PendingDeletion, error) {
+	q := `SELECT client_id, token, created_at, expires_at, confirmed_at, prev_status FROM pending_deletions WHERE token = :token`
+
+	rows, err := repo.DB.NamedQueryContext(ctx, q, dbPendingDeletion{Token: token})
+	if err != nil {
+		return users.PendingDeletion{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var dbpd dbPendingDeletion
+	if rows.Next() {
+		if err := rows.StructScan(&dbpd); err != nil {
+			return users.PendingDeletion{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+		}
+
+		return toPendingDeletion(dbpd), nil
+	}
+
+	return users.PendingDeletion{}, repoerr.ErrNotFound
+}
+
+func (repo clientRepo) RemovePendingDeletion(ctx context.Context, clientID string) error {
+	q := `DELETE FROM pending_deletions WHERE client_id = $1`
+
+	if _, err := repo.DB.ExecContext(ctx, q, clientID); err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+type dbPendingDevice struct {
+	DeviceCode string         `db:"device_code"`
+	UserCode   string         `db:"user_code"`
+	Audience   string         `db:"audience"`
+	ClientID   sql.NullString `db:"client_id"`
+	Denied     bool           `db:"denied"`
+	Interval   int64          `db:"poll_interval"`
+	CreatedAt  time.Time      `db:"created_at"`
+	ExpiresAt  time.Time      `db:"expires_at"`
+	PolledAt   sql.NullTime   `db:"polled_at"`
+}
+
+func toDBPendingDevice(pd users.PendingDevice) dbPendingDevice {
+	return dbPendingDevice{
+		DeviceCode: pd.DeviceCode,
+		UserCode:   pd.UserCode,
+		Audience:   pd.Audience,
+		ClientID:   sql.NullString{String: pd.ClientID, Valid: pd.ClientID != ""},
+		Denied:     pd.Denied,
+		Interval:   int64(pd.Interval),
+		CreatedAt:  pd.CreatedAt,
+		ExpiresAt:  pd.ExpiresAt,
+		PolledAt:   sql.NullTime{Time: pd.PolledAt, Valid: !pd.PolledAt.IsZero()},
+	}
+}
+
+func toPendingDevice(dbpd dbPendingDevice) users.PendingDevice {
+	pd := users.PendingDevice{
+		DeviceCode: dbpd.DeviceCode,
+		UserCode:   dbpd.UserCode,
+		Audience:   dbpd.Audience,
+		Denied:     dbpd.Denied,
+		Interval:   time.Duration(dbpd.Interval),
+		CreatedAt:  dbpd.CreatedAt,
+		ExpiresAt:  dbpd.ExpiresAt,
+	}
+	if dbpd.ClientID.Valid {
+		pd.ClientID = dbpd.ClientID.String
+	}
+	if dbpd.PolledAt.Valid {
+		pd.PolledAt = dbpd.PolledAt.Time
+	}
+
+	return pd
+}
+
+func (repo clientRepo) SavePendingDevice(ctx context.Context, pd users.PendingDevice) error {
+	q := `INSERT INTO pending_devices (device_code, user_code, audience, client_id, denied, poll_interval, created_at, expires_at, polled_at)
+        VALUES (:device_code, :user_code, :audience, :client_id, :denied, :poll_interval, :created_at, :expires_at, :polled_at)
+        ON CONFLICT (device_code) DO UPDATE SET client_id = EXCLUDED.client_id, denied = EXCLUDED.denied,
+        poll_interval = EXCLUDED.poll_interval, polled_at = EXCLUDED.polled_at`
+
+	if _, err := repo.DB.NamedExecContext(ctx, q, toDBPendingDevice(pd)); err != nil {
+		return postgres.HandleError(repoerr.ErrCreateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) RetrievePendingDevice(ctx context.Context, deviceCode string) (users.PendingDevice, error) {
+	return repo.retrievePendingDevice(ctx, "device_code = :device_code", dbPendingDevice{DeviceCode: deviceCode})
+}
+
+func (repo clientRepo) RetrievePendingDeviceByUserCode(ctx context.Context, userCode string) (users.PendingDevice, error) {
+	return repo.retrievePendingDevice(ctx, "user_code = :user_code", dbPendingDevice{UserCode: userCode})
+}
+
+func (repo clientRepo) retrievePendingDevice(ctx context.Context, condition string, dbpd dbPendingDevice) (users.PendingDevice, error) {
+	q := fmt.Sprintf(`SELECT device_code, user_code, COALESCE(audience, '') AS audience, client_id, denied, poll_interval,
+        created_at, expires_at, polled_at FROM pending_devices WHERE %s`, condition)
+
+	rows, err := repo.DB.NamedQueryContext(ctx, q, dbpd)
+	if err != nil {
+		return users.PendingDevice{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.StructScan(&dbpd); err != nil {
+			return users.PendingDevice{}, postgres.HandleError(repoerr.ErrViewEntity, err)
+		}
+
+		return toPendingDevice(dbpd), nil
+	}
+
+	return users.PendingDevice{}, repoerr.ErrNotFound
+}
+
+func (repo clientRepo) RemovePendingDevice(ctx context.Context, deviceCode string) error {
+	q := `DELETE FROM pending_devices WHERE device_code = $1`
+
+	if _, err := repo.DB.ExecContext(ctx, q, deviceCode); err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) RemoveExpiredPendingDevices(ctx context.Context, before time.Time) error {
+	q := `DELETE FROM pending_devices WHERE expires_at < $1`
+
+	if _, err := repo.DB.ExecContext(ctx, q, before.UTC()); err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+// inactiveCondition selects the enabled non-admin users inactive since before
+// the query time, except the users with the exempt tag. The inactivity
+// warning counts only if it was sent after the last activity of the user.
+const inactiveCondition = `status = :enabled AND COALESCE(role, 0) <> :admin
+        AND GREATEST(created_at, updated_at, last_login_at) < :before
+        AND (:exempt_tag = '' OR NOT (:exempt_tag = ANY(COALESCE(tags, '{}'))))
+        AND (NOT :unwarned OR inactivity_warned_at IS NULL OR inactivity_warned_at < GREATEST(created_at, updated_at, last_login_at))
+        AND (CAST(:warned_before AS TIMESTAMP) IS NULL OR
+            (inactivity_warned_at >= GREATEST(created_at, updated_at, last_login_at) AND inactivity_warned_at < :warned_before))`
+
+type dbInactivityQuery struct {
+	ID        string       `db:"id"`
+	Before    time.Time    `db:"before"`
+	ExemptTag string       `db:"exempt_tag"`
+	Unwarned  bool         `db:"unwarned"`
+	Warned    sql.NullTime `db:"warned_before"`
+	Limit     uint64       `db:"limit"`
+	At        time.Time    `db:"at"`
+	Enabled   int          `db:"enabled"`
+	Disabled  int          `db:"disabled"`
+	Admin     int          `db:"admin"`
+}
+
+func toDBInactivityQuery(q users.InactivityQuery) dbInactivityQuery {
+	return dbInactivityQuery{
+		Before:    q.Before.UTC(),
+		ExemptTag: q.ExemptTag,
+		Unwarned:  q.Unwarned,
+		Warned:    sql.NullTime{Time: q.WarnedBefore.UTC(), Valid: !q.WarnedBefore.IsZero()},
+		Limit:     q.Limit,
+		Enabled:   int(mgclients.EnabledStatus),
+		Disabled:  int(mgclients.DisabledStatus),
+		Admin:     int(mgclients.AdminRole),
+	}
+}
+
+func (repo clientRepo) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	q := `UPDATE clients SET last_login_at = $2 WHERE id = $1`
+
+	if _, err := repo.DB.ExecContext(ctx, q, id, at.UTC()); err != nil {
+		return postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) SaveInactivityWarning(ctx context.Context, id string, at time.Time) error {
+	q := `UPDATE clients SET inactivity_warned_at = $2 WHERE id = $1`
+
+	if _, err := repo.DB.ExecContext(ctx, q, id, at.UTC()); err != nil {
+		return postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+
+	return nil
+}
+
+func (repo clientRepo) RetrieveInactive(ctx context.Context, iq users.InactivityQuery) ([]mgclients.Client, error) {
+	q := fmt.Sprintf(`SELECT id, name, tags, identity, metadata, created_at, updated_at, updated_by, status, role
+        FROM clients WHERE %s
+        ORDER BY GREATEST(created_at, updated_at, last_login_at), id
+        LIMIT NULLIF(:limit, 0)`, inactiveCondition)
+
+	rows, err := repo.DB.NamedQueryContext(ctx, q, toDBInactivityQuery(iq))
+	if err != nil {
+		return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var clients []mgclients.Client
+	for rows.Next() {
+		dbc := pgclients.DBClient{}
+		if err := rows.StructScan(&dbc); err != nil {
+			return nil, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		c, err := pgclients.ToClient(dbc)
+		if err != nil {
+			return nil, errors.Wrap(repoerr.ErrFailedOpDB, err)
+		}
+		clients = append(clients, c)
+	}
+
+	return clients, nil
+}
+
+// ChangeStatus records the time the user was disabled. Enabling the user
+// clears it, while other changes, such as the deletion, keep it, so the
+// user restored to the disabled status keeps the time it was disabled.
+func (repo clientRepo) ChangeStatus(ctx context.Context, client mgclients.Client) (mgclients.Client, error) {
+	q := `UPDATE clients SET status = :status, updated_at = :updated_at, updated_by = :updated_by,
+            disabled_at = CASE WHEN :status = 0 THEN NULL ELSE COALESCE(:disabled_at, disabled_at) END
+        WHERE id = :id
+        RETURNING id, name, tags, identity, metadata, status, role, created_at, updated_at, updated_by, disabled_at`
+
+	dbc, err := pgclients.ToDBClient(client)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+	row, err := repo.DB.NamedQueryContext(ctx, q, dbc)
+	if err != nil {
+		return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	defer row.Close()
+
+	if ok := row.Next(); !ok {
+		if err := row.Err(); err != nil {
+			return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+		}
+		return mgclients.Client{}, repoerr.ErrNotFound
+	}
+	dbc = pgclients.DBClient{}
+	if err := row.StructScan(&dbc); err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+
+	return pgclients.ToClient(dbc)
+}
+
+func (repo clientRepo) DisableInactive(ctx context.Context, id string, iq users.InactivityQuery, at time.Time) (mgclients.Client, error) {
+	q := fmt.Sprintf(`UPDATE clients SET status = :disabled, updated_at = :at, disabled_at = :at
+        WHERE id = :id AND %s
+        RETURNING id, name, tags, identity, metadata, status, role, created_at, updated_at, updated_by, disabled_at`, inactiveCondition)
+
+	dbq := toDBInactivityQuery(iq)
+	dbq.ID = id
+	dbq.At = at.UTC()
+	row, err := repo.DB.NamedQueryContext(ctx, q, dbq)
+	if err != nil {
+		return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+	}
+	defer row.Close()
+
+	if ok := row.Next(); !ok {
+		if err := row.Err(); err != nil {
+			return mgclients.Client{}, postgres.HandleError(repoerr.ErrUpdateEntity, err)
+		}
+		return mgclients.Client{}, repoerr.ErrNotFound
+	}
+	dbc := pgclients.DBClient{}
+	if err := row.StructScan(&dbc); err != nil {
+		return mgclients.Client{}, errors.Wrap(repoerr.ErrUpdateEntity, err)
+	}
+
+	return pgclients.ToClient(dbc)
+}
+
+func (repo clientRepo) Delete(ctx context.Context, id string) error {
+	tx, err := repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(repoerr.ErrRemoveEntity, err)
+	}
+	if err := deleteClient(ctx, tx, id); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			return errors.Wrap(errRollback, err)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+// deleteClient deletes the user and records its tombstone, so the deletion
+// is not missed by the feed of the user changes.
+func deleteClient(ctx context.Context, tx *sqlx.Tx, id string) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM clients WHERE id = $1`, id)
+	if err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return repoerr.ErrNotFound
+	}
+
+	q := `INSERT INTO clients_tombstones (id, deleted_at) VALUES ($1, $2)
+        ON CONFLICT (id) DO UPDATE SET deleted_at = EXCLUDED.deleted_at`
+	if _, err := tx.ExecContext(ctx, q, id, time.Now().UTC()); err != nil {
+		return postgres.HandleError(repoerr.ErrRemoveEntity, err)
+	}
+
+	return nil
+}
+
+// changedAfter selects the rows changed after the query position, the change
+// time of the row being the first argument.
+const changedAfter = `(%[1]s > :since OR (:after_id <> '' AND %[1]s = :since AND id > :after_id))`
+
+type dbChangesQuery struct {
+	Since   time.Time `db:"since"`
+	AfterID string    `db:"after_id"`
+	Limit   uint64    `db:"limit"`
+}
+
+type dbTombstone struct {
+	ID        string    `db:"id"`
+	DeletedAt time.Time `db:"deleted_at"`
+}
+
+func (repo clientRepo) RetrieveChanges(ctx context.Context, cq users.ChangesQuery) ([]users.Change, error) {
+	dbq := dbChangesQuery{
+		Since:   cq.Since.UTC(),
+		AfterID: cq.AfterID,
+		Limit:   cq.Limit,
+	}
+
+	// The users and the tombstones are retrieved up to the limit each, and
+	// merged by the change time.
+	q := fmt.Sprintf(`SELECT id, name, tags, identity, metadata, status, role, created_at, updated_at, updated_by
+        FROM clients WHERE %s
+        ORDER BY COALESCE(updated_at, created_at), id
+        LIMIT NULLIF(:limit, 0)`, fmt.Sprintf(changedAfter, "COALESCE(updated_at, created_at)"))
+	rows, err := repo.DB.NamedQueryContext(ctx, q, dbq)
+	if err != nil {
+		return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer rows.Close()
+
+	var changes []users.Change
+	for rows.Next() {
+		dbc := pgclients.DBClient{}
+		if err := rows.StructScan(&dbc); err != nil {
+			return nil, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		c, err := pgclients.ToClient(dbc)
+		if err != nil {
+			return nil, errors.Wrap(repoerr.ErrFailedOpDB, err)
+		}
+		changes = append(changes, clientChange(c))
+	}
+
+	q = fmt.Sprintf(`SELECT id, deleted_at FROM clients_tombstones WHERE %s
+        ORDER BY deleted_at, id
+        LIMIT NULLIF(:limit, 0)`, fmt.Sprintf(changedAfter, "deleted_at"))
+	trows, err := repo.DB.NamedQueryContext(ctx, q, dbq)
+	if err != nil {
+		return nil, postgres.HandleError(repoerr.ErrViewEntity, err)
+	}
+	defer trows.Close()
+
+	for trows.Next() {
+		dbt := dbTombstone{}
+		if err := trows.StructScan(&dbt); err != nil {
+			return nil, errors.Wrap(repoerr.ErrViewEntity, err)
+		}
+		changes = append(changes, users.Change{
+			ID:        dbt.ID,
+			Operation: users.ChangeDeleted,
+			ChangedAt: dbt.DeletedAt,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ChangedAt.Equal(changes[j].ChangedAt) {
+			return changes[i].ID < changes[j].ID
+		}
+		return changes[i].ChangedAt.Before(changes[j].ChangedAt)
+	})
+	if cq.Limit > 0 && uint64(len(changes)) > cq.Limit {
+		changes = changes[:cq.Limit]
+	}
+
+	return changes, nil
+}
+
+func clientChange(c mgclients.Client) users.Change {
+	change := users.Change{ID: c.ID, Operation: users.ChangeUpdated, ChangedAt: c.UpdatedAt, Client: &c}
+	switch {
+	case c.Status == mgclients.DeletedStatus:
+		change.Operation = users.ChangeDeleted
+	case c.UpdatedAt.IsZero():
+		change.Operation, change.ChangedAt = users.ChangeCreated, c.CreatedAt
+	}
+
+	return change
+}
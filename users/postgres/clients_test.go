@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/0x6flab/namegenerator"
 	"github.com/absmach/magistrala/internal/testsutil"
@@ -243,6 +244,114 @@ func TestIsPlatformAdmin(t *testing.T) {
 	}
 }
 
+func TestMFAEnrollment(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM clients")
+		require.Nil(t, err, fmt.Sprintf("clean clients unexpected error: %s", err))
+	})
+	repo := cpostgres.NewRepository(database)
+
+	client := mgclients.Client{
+		ID:   testsutil.GenerateUUID(t),
+		Name: namesgen.Generate(),
+		Credentials: mgclients.Credentials{
+			Identity: fmt.Sprintf("%s@example.com", namesgen.Generate()),
+			Secret:   password,
+		},
+		Metadata: mgclients.Metadata{},
+		Status:   mgclients.EnabledStatus,
+	}
+	_, err := repo.Save(context.Background(), client)
+	require.Nil(t, err, fmt.Sprintf("save client unexpected error: %s", err))
+
+	_, err = repo.RetrieveMFA(context.Background(), client.ID)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("retrieve missing enrollment: expected %v got %v", repoerr.ErrNotFound, err))
+
+	err = repo.ConfirmMFA(context.Background(), client.ID, 1, time.Now())
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("confirm missing enrollment: expected %v got %v", repoerr.ErrNotFound, err))
+
+	err = repo.SaveMFASecret(context.Background(), client.ID, "first")
+	assert.Nil(t, err, fmt.Sprintf("save secret unexpected error: %s", err))
+	err = repo.SaveMFASecret(context.Background(), client.ID, "second")
+	assert.Nil(t, err, fmt.Sprintf("replace pending secret unexpected error: %s", err))
+
+	enrollment, err := repo.RetrieveMFA(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve pending enrollment unexpected error: %s", err))
+	assert.Equal(t, "second", enrollment.Secret)
+	assert.False(t, enrollment.Enrolled(), "expected pending enrollment")
+
+	err = repo.UseMFAStep(context.Background(), client.ID, 10)
+	assert.True(t, errors.Contains(err, repoerr.ErrConflict), fmt.Sprintf("use step of pending enrollment: expected %v got %v", repoerr.ErrConflict, err))
+
+	err = repo.ConfirmMFA(context.Background(), client.ID, 10, time.Now())
+	assert.Nil(t, err, fmt.Sprintf("confirm enrollment unexpected error: %s", err))
+
+	enrollment, err = repo.RetrieveMFA(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve enrollment unexpected error: %s", err))
+	assert.True(t, enrollment.Enrolled(), "expected confirmed enrollment")
+	assert.Equal(t, int64(10), enrollment.LastStep)
+
+	err = repo.SaveMFASecret(context.Background(), client.ID, "third")
+	assert.True(t, errors.Contains(err, repoerr.ErrConflict), fmt.Sprintf("replace confirmed secret: expected %v got %v", repoerr.ErrConflict, err))
+
+	err = repo.UseMFAStep(context.Background(), client.ID, 10)
+	assert.True(t, errors.Contains(err, repoerr.ErrConflict), fmt.Sprintf("use the confirmation step: expected %v got %v", repoerr.ErrConflict, err))
+	err = repo.UseMFAStep(context.Background(), client.ID, 11)
+	assert.Nil(t, err, fmt.Sprintf("use later step unexpected error: %s", err))
+	err = repo.UseMFAStep(context.Background(), client.ID, 11)
+	assert.True(t, errors.Contains(err, repoerr.ErrConflict), fmt.Sprintf("use the step again: expected %v got %v", repoerr.ErrConflict, err))
+}
+
+func TestUpdateSecretIfUnchanged(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := db.Exec("DELETE FROM clients")
+		require.Nil(t, err, fmt.Sprintf("clean clients unexpected error: %s", err))
+	})
+	repo := cpostgres.NewRepository(database)
+
+	client := mgclients.Client{
+		ID:   testsutil.GenerateUUID(t),
+		Name: namesgen.Generate(),
+		Credentials: mgclients.Credentials{
+			Identity: fmt.Sprintf("%s@example.com", namesgen.Generate()),
+			Secret:   password,
+		},
+		Metadata: mgclients.Metadata{},
+		Status:   mgclients.EnabledStatus,
+	}
+	_, err := repo.Save(context.Background(), client)
+	require.Nil(t, err, fmt.Sprintf("save client unexpected error: %s", err))
+
+	errs := make(chan error, 2)
+	for _, secret := range []string{"firstNewSecret", "secondNewSecret"} {
+		go func(secret string) {
+			c := client
+			c.Credentials.Secret = secret
+			c.UpdatedAt = time.Now()
+			_, err := repo.UpdateSecretIfUnchanged(context.Background(), c, password)
+			errs <- err
+		}(secret)
+	}
+	conflicts := 0
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		if err != nil {
+			assert.True(t, errors.Contains(err, repoerr.ErrConcurrentUpdate), fmt.Sprintf("expected %s got %s", repoerr.ErrConcurrentUpdate, err))
+			conflicts++
+		}
+	}
+	assert.Equal(t, 1, conflicts, "expected exactly one concurrent update to fail")
+
+	c := client
+	c.Credentials.Secret = "newSecret"
+	_, err = repo.UpdateSecretIfUnchanged(context.Background(), c, password)
+	assert.True(t, errors.Contains(err, repoerr.ErrConcurrentUpdate), fmt.Sprintf("stale update: expected %s got %s", repoerr.ErrConcurrentUpdate, err))
+
+	c.ID = testsutil.GenerateUUID(t)
+	_, err = repo.UpdateSecretIfUnchanged(context.Background(), c, password)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
 func TestRetrieveByID(t *testing.T) {
 	t.Cleanup(func() {
 		_, err := db.Exec("DELETE FROM clients")
@@ -0,0 +1,29 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala/users"
+	cpostgres "github.com/absmach/magistrala/users/postgres"
+	"github.com/absmach/magistrala/users/repotest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) users.Repository {
+		t.Cleanup(func() {
+			_, err := db.Exec("DELETE FROM clients")
+			require.Nil(t, err, fmt.Sprintf("clean clients unexpected error: %s", err))
+			_, err = db.Exec("DELETE FROM clients_tombstones")
+			require.Nil(t, err, fmt.Sprintf("clean clients tombstones unexpected error: %s", err))
+			_, err = db.Exec("DELETE FROM pending_devices")
+			require.Nil(t, err, fmt.Sprintf("clean pending devices unexpected error: %s", err))
+		})
+
+		return cpostgres.NewRepository(database)
+	})
+}
@@ -1,5 +1,5 @@
 // Copyright (c) Abstract Machines
 // SPDX-License-Identifier: Apache-2.0
 
-// Package postgres contains the database implementation of clients repository layer.
+// Package postgres contains the PostgreSQL implementation of the users repository.
 package postgres
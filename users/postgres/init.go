@@ -45,6 +45,177 @@ func Migration() *migrate.MemoryMigrationSource {
 				},
 				Down: []string{},
 			},
+			{
+				// To support confirmation of client identity changes
+				Id: "clients_03",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS pending_identities (
+						client_id   VARCHAR(36) PRIMARY KEY REFERENCES clients (id) ON DELETE CASCADE,
+						identity    VARCHAR(254) NOT NULL,
+						token       VARCHAR(64) NOT NULL UNIQUE,
+						created_at  TIMESTAMP,
+						expires_at  TIMESTAMP NOT NULL
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS pending_identities`,
+				},
+			},
+			{
+				// To support enforcing multi-factor authentication on login
+				Id: "clients_04",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS mfa_enrollments (
+						client_id   VARCHAR(36) PRIMARY KEY REFERENCES clients (id) ON DELETE CASCADE,
+						enrolled_at TIMESTAMP NOT NULL
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS mfa_enrollments`,
+				},
+			},
+			{
+				// To support listing users by the OAuth2 provider they signed in with
+				Id: "clients_05",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS linked_providers (
+						client_id   VARCHAR(36) NOT NULL REFERENCES clients (id) ON DELETE CASCADE,
+						provider    VARCHAR(64) NOT NULL,
+						linked_at   TIMESTAMP NOT NULL,
+						PRIMARY KEY (client_id, provider)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_linked_providers_provider ON linked_providers (provider)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS linked_providers`,
+				},
+			},
+			{
+				// To support account self-deletion with a cooling-off period
+				Id: "clients_06",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS pending_deletions (
+						client_id    VARCHAR(36) PRIMARY KEY REFERENCES clients (id) ON DELETE CASCADE,
+						token        VARCHAR(64) NOT NULL UNIQUE,
+						created_at   TIMESTAMP,
+						expires_at   TIMESTAMP NOT NULL,
+						confirmed_at TIMESTAMP
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS pending_deletions`,
+				},
+			},
+			{
+				// To support disabling the users inactive for too long
+				Id: "clients_07",
+				Up: []string{
+					`ALTER TABLE clients ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP`,
+					`ALTER TABLE clients ADD COLUMN IF NOT EXISTS inactivity_warned_at TIMESTAMP`,
+				},
+				Down: []string{
+					`ALTER TABLE clients DROP COLUMN IF EXISTS last_login_at`,
+					`ALTER TABLE clients DROP COLUMN IF EXISTS inactivity_warned_at`,
+				},
+			},
+			{
+				// To support the feed of the user changes
+				Id: "clients_08",
+				Up: []string{
+					`CREATE INDEX IF NOT EXISTS clients_changed_at_idx ON clients ((COALESCE(updated_at, created_at)), id)`,
+					`CREATE TABLE IF NOT EXISTS clients_tombstones (
+						id          VARCHAR(36) PRIMARY KEY,
+						deleted_at  TIMESTAMP NOT NULL
+					)`,
+					`CREATE INDEX IF NOT EXISTS clients_tombstones_deleted_at_idx ON clients_tombstones (deleted_at, id)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS clients_tombstones`,
+					`DROP INDEX IF EXISTS clients_changed_at_idx`,
+				},
+			},
+			{
+				// To support the OAuth2 device authorization flow
+				Id: "clients_09",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS pending_devices (
+						device_code   VARCHAR(64) PRIMARY KEY,
+						user_code     VARCHAR(64) NOT NULL UNIQUE,
+						audience      VARCHAR(254),
+						client_id     VARCHAR(36) REFERENCES clients (id) ON DELETE CASCADE,
+						denied        BOOLEAN NOT NULL DEFAULT FALSE,
+						poll_interval BIGINT NOT NULL,
+						created_at    TIMESTAMP,
+						expires_at    TIMESTAMP NOT NULL,
+						polled_at     TIMESTAMP
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS pending_devices`,
+				},
+			},
+			{
+				// To support enrolling TOTP multi-factor authentication. The
+				// enrollments without a secret can't be verified, so they
+				// are removed and the users enroll again.
+				Id: "clients_10",
+				Up: []string{
+					`ALTER TABLE mfa_enrollments ADD COLUMN IF NOT EXISTS secret VARCHAR(64)`,
+					`ALTER TABLE mfa_enrollments ADD COLUMN IF NOT EXISTS last_step BIGINT`,
+					`ALTER TABLE mfa_enrollments ALTER COLUMN enrolled_at DROP NOT NULL`,
+					`DELETE FROM mfa_enrollments WHERE secret IS NULL`,
+					`ALTER TABLE mfa_enrollments ALTER COLUMN secret SET NOT NULL`,
+				},
+				Down: []string{
+					`DELETE FROM mfa_enrollments WHERE enrolled_at IS NULL`,
+					`ALTER TABLE mfa_enrollments ALTER COLUMN enrolled_at SET NOT NULL`,
+					`ALTER TABLE mfa_enrollments DROP COLUMN IF EXISTS last_step`,
+					`ALTER TABLE mfa_enrollments DROP COLUMN IF EXISTS secret`,
+				},
+			},
+			{
+				Id: "clients_11",
+				Up: []string{
+					`ALTER TABLE pending_deletions ADD COLUMN IF NOT EXISTS prev_status SMALLINT NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					`ALTER TABLE pending_deletions DROP COLUMN IF EXISTS prev_status`,
+				},
+			},
+			{
+				// To purge the expired pending devices
+				Id: "clients_12",
+				Up: []string{
+					`CREATE INDEX IF NOT EXISTS pending_devices_expires_at_idx ON pending_devices (expires_at)`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS pending_devices_expires_at_idx`,
+				},
+			},
+			{
+				// To record when the users were disabled, since the update
+				// time moves on every later change. The disabled users are
+				// assumed disabled at their last update.
+				Id: "clients_13",
+				Up: []string{
+					`ALTER TABLE clients ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP`,
+					`UPDATE clients SET disabled_at = COALESCE(updated_at, created_at) WHERE status = 1 AND disabled_at IS NULL`,
+				},
+				Down: []string{
+					`ALTER TABLE clients DROP COLUMN IF EXISTS disabled_at`,
+				},
+			},
+			{
+				// To count the wrong verification codes of all the service
+				// instances, across the resent codes.
+				Id: "clients_14",
+				Up: []string{
+					`ALTER TABLE pending_identities ADD COLUMN IF NOT EXISTS attempts SMALLINT NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					`ALTER TABLE pending_identities DROP COLUMN IF EXISTS attempts`,
+				},
+			},
 		},
 	}
 }
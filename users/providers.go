@@ -0,0 +1,325 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// LocalProvider is the name of the identity provider verifying the
+	// secrets stored by the users service.
+	LocalProvider = "local"
+
+	// OAuth2Provider is the name of the identity provider verifying the
+	// credentials with an OAuth2 authorization server.
+	OAuth2Provider = "oauth2"
+)
+
+// ErrIdentityProviders indicates that none of the identity providers
+// accepted the credentials.
+var ErrIdentityProviders = errors.New("credentials rejected by all identity providers")
+
+var (
+	errInvalidProviders = errors.New("invalid identity providers chain")
+	errUserInfo         = errors.New("failed to retrieve user info")
+)
+
+// IdentityProvider verifies the user credentials against an authentication
+// backend, such as LDAP.
+//
+//go:generate mockery --name IdentityProvider --output=./mocks --filename identity_provider.go --quiet --note "Copyright (c) Abstract Machines"
+type IdentityProvider interface {
+	// Name returns the name the provider is configured with.
+	Name() string
+
+	// Authenticate verifies the secret of the identity and returns the user
+	// as known to the backend. The returned user needs only the identity
+	// and optionally the name.
+	Authenticate(ctx context.Context, identity, secret string) (mgclients.Client, error)
+}
+
+// IdentityProviders defines the ordered chain of the identity providers
+// tried when issuing a token.
+type IdentityProviders struct {
+	// Chain contains the providers in the order they are tried. The local
+	// provider is included with LocalIdentityProvider. If the chain is
+	// empty, only the local provider is used.
+	Chain []IdentityProvider
+
+	// MigrateAccounts creates the local account of a user authenticated by
+	// another provider, or updates its secret, so the user can still log in
+	// once the provider is removed from the chain. Otherwise, users without
+	// a local account are rejected.
+	MigrateAccounts bool
+}
+
+// ParseIdentityProviders parses the comma-separated names of the providers
+// into the chain, in the same order. The names are resolved among the local
+// provider and the given available providers.
+func ParseIdentityProviders(names string, available ...IdentityProvider) ([]IdentityProvider, error) {
+	providers := map[string]IdentityProvider{LocalProvider: LocalIdentityProvider()}
+	for _, p := range available {
+		providers[p.Name()] = p
+	}
+
+	var chain []IdentityProvider
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, ok := providers[name]
+		if !ok {
+			return nil, errors.Wrap(errInvalidProviders, fmt.Errorf("unknown provider %q", name))
+		}
+		if seen[name] {
+			return nil, errors.Wrap(errInvalidProviders, fmt.Errorf("duplicate provider %q", name))
+		}
+		seen[name] = true
+		chain = append(chain, p)
+	}
+
+	return chain, nil
+}
+
+type localProvider struct{}
+
+// LocalIdentityProvider returns the provider verifying the secrets stored by
+// the users service, placed in the chain to set its precedence.
+func LocalIdentityProvider() IdentityProvider {
+	return localProvider{}
+}
+
+func (localProvider) Name() string {
+	return LocalProvider
+}
+
+// Authenticate is never called, since the service verifies the stored
+// secrets itself.
+func (localProvider) Authenticate(context.Context, string, string) (mgclients.Client, error) {
+	return mgclients.Client{}, svcerr.ErrAuthentication
+}
+
+// OAuth2ProviderConfig defines the OAuth2 authorization server verifying the
+// credentials with the resource owner password credentials grant.
+type OAuth2ProviderConfig struct {
+	TokenURL     string        `env:"TOKEN_URL"     envDefault:""`
+	ClientID     string        `env:"CLIENT_ID"     envDefault:""`
+	ClientSecret string        `env:"CLIENT_SECRET" envDefault:""`
+	Scopes       []string      `env:"SCOPES"        envDefault:""`
+	UserInfoURL  string        `env:"USER_INFO_URL" envDefault:""`
+	Timeout      time.Duration `env:"TIMEOUT"       envDefault:"10s"`
+}
+
+// Enabled reports whether the OAuth2 provider is configured.
+func (cfg OAuth2ProviderConfig) Enabled() bool {
+	return cfg.TokenURL != ""
+}
+
+type oauth2Provider struct {
+	config      oauth2.Config
+	userInfoURL string
+	client      *http.Client
+}
+
+// NewOAuth2Provider returns the provider which verifies the credentials by
+// exchanging them for a token at the token URL of the authorization server,
+// such as the one the users are migrated to. If the user info URL is set,
+// the name of the user is read from the name claim of the user info.
+func NewOAuth2Provider(cfg OAuth2ProviderConfig) IdentityProvider {
+	return &oauth2Provider{
+		config: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: cfg.TokenURL},
+			Scopes:       cfg.Scopes,
+		},
+		userInfoURL: cfg.UserInfoURL,
+		client:      &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (p *oauth2Provider) Name() string {
+	return OAuth2Provider
+}
+
+func (p *oauth2Provider) Authenticate(ctx context.Context, identity, secret string) (mgclients.Client, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.config.PasswordCredentialsToken(ctx, identity, secret)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	user := mgclients.Client{
+		Credentials: mgclients.Credentials{Identity: identity},
+	}
+	if p.userInfoURL == "" {
+		return user, nil
+	}
+	if user.Name, err = p.userName(ctx, token); err != nil {
+		return mgclients.Client{}, errors.Wrap(errUserInfo, err)
+	}
+
+	return user, nil
+}
+
+// userName returns the name claim of the user info, which may be empty.
+func (p *oauth2Provider) userName(ctx context.Context, token *oauth2.Token) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := p.config.Client(ctx, token).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected user info status %d", res.StatusCode)
+	}
+	var info struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return "", err
+	}
+
+	return info.Name, nil
+}
+
+// authenticate returns the user authenticated by the first provider of the
+// chain which accepts the credentials. If the chain has a single provider,
+// its error is returned, otherwise the failure is reported as a single
+// ErrIdentityProviders error, so it doesn't reveal which providers know the
+// identity.
+func (svc service) authenticate(ctx context.Context, identity, secret string) (mgclients.Client, error) {
+	chain := svc.config.IdentityProviders.Chain
+	if len(chain) == 0 {
+		chain = []IdentityProvider{LocalIdentityProvider()}
+	}
+
+	var err error
+	for _, p := range chain {
+		var user mgclients.Client
+		if user, err = svc.authenticateWith(ctx, p, identity, secret); err == nil {
+			return user, nil
+		}
+	}
+	// The local provider counts the failed logins itself if it's the only one.
+	if _, local := chain[0].(localProvider); (!local || len(chain) > 1) && !errors.Contains(err, errLoginDisableUser) {
+		svc.logins.failed(ctx, identity)
+	}
+	if len(chain) == 1 {
+		return mgclients.Client{}, err
+	}
+
+	return mgclients.Client{}, errors.Wrap(svcerr.ErrLogin, ErrIdentityProviders)
+}
+
+func (svc service) authenticateWith(ctx context.Context, p IdentityProvider, identity, secret string) (mgclients.Client, error) {
+	if _, ok := p.(localProvider); ok {
+		return svc.authenticateLocal(ctx, identity, secret, len(svc.config.IdentityProviders.Chain) <= 1)
+	}
+
+	ext, err := p.Authenticate(ctx, identity, secret)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrLogin, err)
+	}
+	user, err := svc.clients.RetrieveByIdentity(ctx, identity)
+	switch {
+	case err == nil:
+		if svc.config.IdentityProviders.MigrateAccounts && svc.hasher.Compare(secret, user.Credentials.Secret) != nil {
+			if user, err = svc.migrateSecret(ctx, user, secret); err != nil {
+				return mgclients.Client{}, err
+			}
+		}
+	case errors.Contains(err, repoerr.ErrNotFound) && svc.config.IdentityProviders.MigrateAccounts:
+		if user, err = svc.migrateAccount(ctx, ext, identity, secret); err != nil {
+			return mgclients.Client{}, err
+		}
+	default:
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if user.Status != mgclients.EnabledStatus {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errLoginDisableUser)
+	}
+
+	return user, nil
+}
+
+// authenticateLocal verifies the secret stored by the service. The failed
+// logins are counted only if the local provider is the only one, otherwise
+// the chain counts them once.
+func (svc service) authenticateLocal(ctx context.Context, identity, secret string, countFailures bool) (mgclients.Client, error) {
+	user, err := svc.clients.RetrieveByIdentity(ctx, identity)
+	if err != nil {
+		if countFailures && errors.Contains(err, repoerr.ErrNotFound) {
+			svc.logins.failed(ctx, identity)
+		}
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	// Tokens are never issued to disabled users, regardless of the grace
+	// period their existing tokens may have.
+	if user.Status != mgclients.EnabledStatus {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errLoginDisableUser)
+	}
+	if err := svc.hasher.Compare(secret, user.Credentials.Secret); err != nil {
+		if countFailures {
+			svc.logins.failed(ctx, identity)
+		}
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrLogin, err)
+	}
+
+	return user, nil
+}
+
+// migrateAccount creates the local account of the user authenticated by an
+// external provider.
+func (svc service) migrateAccount(ctx context.Context, ext mgclients.Client, identity, secret string) (mgclients.Client, error) {
+	user := mgclients.Client{
+		Name: ext.Name,
+		Credentials: mgclients.Credentials{
+			Identity: identity,
+			Secret:   secret,
+		},
+		Metadata: ext.Metadata,
+		Role:     mgclients.UserRole,
+		Status:   mgclients.EnabledStatus,
+	}
+	if user.Name == "" {
+		user.Name = identity
+	}
+
+	return svc.RegisterClient(ctx, authn.Session{}, user, true)
+}
+
+// migrateSecret replaces the stored secret with the one accepted by an
+// external provider.
+func (svc service) migrateSecret(ctx context.Context, user mgclients.Client, secret string) (mgclients.Client, error) {
+	hash, err := svc.hasher.Hash(secret)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	user.Credentials.Secret = hash
+	user.UpdatedAt = time.Now()
+	user.UpdatedBy = user.ID
+	if user, err = svc.clients.UpdateSecret(ctx, user); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
+	return user, nil
+}
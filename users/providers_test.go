@@ -0,0 +1,328 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala"
+	mgauth "github.com/absmach/magistrala/auth"
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var errLDAP = errors.New("ldap: invalid credentials")
+
+func newProviderService(t *testing.T, providers users.IdentityProviders) (users.Service, *authmocks.TokenServiceClient, *mocks.Repository, *policymocks.Service) {
+	cRepo := mocks.NewRepository(t)
+	policies := new(policymocks.Service)
+	tokenClient := new(authmocks.TokenServiceClient)
+	cfg := users.Config{IdentityProviders: providers}
+
+	return users.NewService(tokenClient, cRepo, policies, new(mocks.Emailer), phasher, idProvider, cfg), tokenClient, cRepo, policies
+}
+
+func newProvider(t *testing.T, name string, err error) *mocks.IdentityProvider {
+	p := mocks.NewIdentityProvider(t)
+	p.On("Name").Return(name).Maybe()
+	if err != nil {
+		p.On("Authenticate", context.Background(), client.Credentials.Identity, secret).Return(mgclients.Client{}, err).Maybe()
+		return p
+	}
+	p.On("Authenticate", context.Background(), client.Credentials.Identity, secret).Return(mgclients.Client{
+		Name:        client.Name,
+		Credentials: mgclients.Credentials{Identity: client.Credentials.Identity},
+	}, nil).Maybe()
+
+	return p
+}
+
+func TestIssueTokenProviderChain(t *testing.T) {
+	stored := client
+	stored.Credentials.Secret, _ = phasher.Hash(secret)
+	outdated := client
+	outdated.Credentials.Secret, _ = phasher.Hash("oldsecret")
+	token := &magistrala.Token{AccessToken: validToken, RefreshToken: &validToken, AccessType: "3"}
+
+	cases := []struct {
+		desc     string
+		chain    func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider)
+		migrate  bool
+		local    mgclients.Client
+		localErr error
+		called   []bool
+		saved    bool
+		updated  bool
+		err      error
+	}{
+		{
+			desc: "first provider fails and second succeeds",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap, oauth := newProvider(t, "ldap", errLDAP), newProvider(t, "oauth", nil)
+				return []users.IdentityProvider{ldap, oauth}, []*mocks.IdentityProvider{ldap, oauth}
+			},
+			local:  stored,
+			called: []bool{true, true},
+		},
+		{
+			desc: "first provider succeeds",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap, oauth := newProvider(t, "ldap", nil), newProvider(t, "oauth", nil)
+				return []users.IdentityProvider{ldap, oauth}, []*mocks.IdentityProvider{ldap, oauth}
+			},
+			local:  stored,
+			called: []bool{true, false},
+		},
+		{
+			desc: "all providers fail",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap, oauth := newProvider(t, "ldap", errLDAP), newProvider(t, "oauth", svcerr.ErrAuthentication)
+				return []users.IdentityProvider{ldap, oauth}, []*mocks.IdentityProvider{ldap, oauth}
+			},
+			called: []bool{true, true},
+			err:    users.ErrIdentityProviders,
+		},
+		{
+			desc: "local provider rejects secret and external provider succeeds",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap := newProvider(t, "ldap", nil)
+				return []users.IdentityProvider{users.LocalIdentityProvider(), ldap}, []*mocks.IdentityProvider{ldap}
+			},
+			local:  outdated,
+			called: []bool{true},
+		},
+		{
+			desc: "local provider succeeds before external provider",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap := newProvider(t, "ldap", nil)
+				return []users.IdentityProvider{users.LocalIdentityProvider(), ldap}, []*mocks.IdentityProvider{ldap}
+			},
+			local:  stored,
+			called: []bool{false},
+		},
+		{
+			desc: "external provider succeeds without local account",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap, oauth := newProvider(t, "ldap", nil), newProvider(t, "oauth", errLDAP)
+				return []users.IdentityProvider{ldap, oauth}, []*mocks.IdentityProvider{ldap, oauth}
+			},
+			localErr: repoerr.ErrNotFound,
+			called:   []bool{true, true},
+			err:      users.ErrIdentityProviders,
+		},
+		{
+			desc: "external provider succeeds and creates local account",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap := newProvider(t, "ldap", nil)
+				return []users.IdentityProvider{ldap}, []*mocks.IdentityProvider{ldap}
+			},
+			migrate:  true,
+			localErr: repoerr.ErrNotFound,
+			called:   []bool{true},
+			saved:    true,
+		},
+		{
+			desc: "external provider succeeds and updates local secret",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap := newProvider(t, "ldap", nil)
+				return []users.IdentityProvider{ldap}, []*mocks.IdentityProvider{ldap}
+			},
+			migrate: true,
+			local:   outdated,
+			called:  []bool{true},
+			updated: true,
+		},
+		{
+			desc: "single external provider fails",
+			chain: func(t *testing.T) ([]users.IdentityProvider, []*mocks.IdentityProvider) {
+				ldap := newProvider(t, "ldap", errLDAP)
+				return []users.IdentityProvider{ldap}, []*mocks.IdentityProvider{ldap}
+			},
+			called: []bool{true},
+			err:    errLDAP,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			chain, providers := tc.chain(t)
+			svc, auth, cRepo, policies := newProviderService(t, users.IdentityProviders{Chain: chain, MigrateAccounts: tc.migrate})
+			cRepo.On("RetrieveByIdentity", context.Background(), client.Credentials.Identity).Return(tc.local, tc.localErr).Maybe()
+			userID := client.ID
+			if tc.saved {
+				policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+				cRepo.On("Save", context.Background(), mock.MatchedBy(func(c mgclients.Client) bool {
+					userID = c.ID
+					return c.Credentials.Identity == client.Credentials.Identity && c.Name == client.Name && phasher.Compare(secret, c.Credentials.Secret) == nil
+				})).Return(func(_ context.Context, c mgclients.Client) mgclients.Client { return c }, nil)
+			}
+			if tc.updated {
+				cRepo.On("UpdateSecret", context.Background(), mock.MatchedBy(func(c mgclients.Client) bool {
+					return c.ID == client.ID && phasher.Compare(secret, c.Credentials.Secret) == nil
+				})).Return(stored, nil)
+			}
+			cRepo.On("UpdateLastLogin", context.Background(), mock.Anything, mock.Anything).Return(nil).Maybe()
+			cRepo.On("RetrieveMFA", context.Background(), mock.Anything).Return(users.MFAEnrollment{}, repoerr.ErrNotFound).Maybe()
+			auth.On("Issue", context.Background(), mock.MatchedBy(func(req *magistrala.IssueReq) bool {
+				return req.GetUserId() == userID && req.GetType() == uint32(mgauth.AccessKey)
+			})).Return(token, nil)
+
+			tkn, err := svc.IssueToken(context.Background(), client.Credentials.Identity, secret, "", "")
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.Equal(t, token, tkn)
+			} else {
+				auth.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+			}
+			for i, p := range providers {
+				if tc.called[i] {
+					p.AssertCalled(t, "Authenticate", context.Background(), client.Credentials.Identity, secret)
+					continue
+				}
+				p.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestParseIdentityProviders(t *testing.T) {
+	ldap := mocks.NewIdentityProvider(t)
+	ldap.On("Name").Return("ldap")
+
+	cases := []struct {
+		desc  string
+		names string
+		chain []string
+		err   bool
+	}{
+		{
+			desc:  "parse empty chain",
+			names: "",
+		},
+		{
+			desc:  "parse local provider",
+			names: "local",
+			chain: []string{users.LocalProvider},
+		},
+		{
+			desc:  "parse providers in order",
+			names: "ldap, local",
+			chain: []string{"ldap", users.LocalProvider},
+		},
+		{
+			desc:  "parse unknown provider",
+			names: "local,oauth",
+			err:   true,
+		},
+		{
+			desc:  "parse duplicate provider",
+			names: "local,ldap,local",
+			err:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			chain, err := users.ParseIdentityProviders(tc.names, ldap)
+			assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			var names []string
+			for _, p := range chain {
+				names = append(names, p.Name())
+			}
+			assert.Equal(t, tc.chain, names)
+		})
+	}
+}
+
+func TestOAuth2ProviderAuthenticate(t *testing.T) {
+	const (
+		clientID     = "magistrala"
+		clientSecret = "clientsecret"
+		accessToken  = "accesstoken"
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, sec, _ := r.BasicAuth()
+		if r.FormValue("grant_type") != "password" || id != clientID || sec != clientSecret ||
+			r.FormValue("username") != client.Credentials.Identity || r.FormValue("password") != secret {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3600}`, accessToken)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+accessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"name":%q}`, client.Name)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	cases := []struct {
+		desc        string
+		userInfoURL string
+		secret      string
+		name        string
+		err         error
+	}{
+		{
+			desc:   "authenticate with valid credentials",
+			secret: secret,
+		},
+		{
+			desc:        "authenticate with valid credentials and user info",
+			userInfoURL: ts.URL + "/userinfo",
+			secret:      secret,
+			name:        client.Name,
+		},
+		{
+			desc:   "authenticate with invalid credentials",
+			secret: "wrongsecret",
+			err:    svcerr.ErrAuthentication,
+		},
+		{
+			desc:        "authenticate with failed user info",
+			userInfoURL: ts.URL + "/invalid",
+			secret:      secret,
+			err:         errors.New("failed to retrieve user info"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p := users.NewOAuth2Provider(users.OAuth2ProviderConfig{
+				TokenURL:     ts.URL + "/token",
+				ClientID:     clientID,
+				ClientSecret: clientSecret,
+				UserInfoURL:  tc.userInfoURL,
+				Timeout:      time.Second,
+			})
+			assert.Equal(t, users.OAuth2Provider, p.Name())
+			user, err := p.Authenticate(context.Background(), client.Credentials.Identity, tc.secret)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err == nil {
+				assert.Equal(t, client.Credentials.Identity, user.Credentials.Identity)
+				assert.Equal(t, tc.name, user.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+
+	mgauth "github.com/absmach/magistrala/auth"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/policies"
+)
+
+// ExistingClients returns the lookup of the users referred to by policies,
+// used to reconcile orphaned policies. Policy subjects may be either user IDs
+// or domain user IDs, which are looked up by the user ID part.
+func ExistingClients(repo Repository) policies.ExistingFunc {
+	return func(ctx context.Context, ids []string) ([]string, error) {
+		refs := make(map[string][]string)
+		userIDs := []string{}
+		for _, id := range ids {
+			userID := id
+			if _, uid := mgauth.DecodeDomainUserID(id); uid != "" {
+				userID = uid
+			}
+			if _, ok := refs[userID]; !ok {
+				userIDs = append(userIDs, userID)
+			}
+			refs[userID] = append(refs[userID], id)
+		}
+
+		page, err := repo.RetrieveAllByIDs(ctx, mgclients.Page{
+			IDs:    userIDs,
+			Limit:  uint64(len(userIDs)),
+			Status: mgclients.AllStatus,
+			Role:   mgclients.AllRole,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		existing := []string{}
+		for _, c := range page.Clients {
+			existing = append(existing, refs[c.ID]...)
+		}
+
+		return existing, nil
+	}
+}
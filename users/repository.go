@@ -0,0 +1,214 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"time"
+
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+)
+
+// Repository specifies the users persistence API. The service depends only on
+// this interface, so any storage backend implementing it can be plugged in.
+// Backends must pass the contract test suite in the users/repotest package.
+//
+// Methods return repository errors from the pkg/errors/repository package,
+// ErrNotFound if the user doesn't exist and ErrConflict if a unique field
+// such as the name or the identity is already taken.
+//
+//go:generate mockery --name Repository --output=./mocks --filename repository.go --quiet --note "Copyright (c) Abstract Machines"
+type Repository interface {
+	// Save persists the user account. A non-nil error is returned to indicate
+	// operation failure.
+	Save(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// RetrieveByID retrieves the user by its unique ID, regardless of its status.
+	RetrieveByID(ctx context.Context, id string) (mgclients.Client, error)
+
+	// RetrieveByIdentity retrieves the enabled user by its unique identity.
+	RetrieveByIdentity(ctx context.Context, identity string) (mgclients.Client, error)
+
+	// RetrieveAll retrieves the users matching the page filters. If the
+	// page OAuth provider is set, only the users linked to it are retrieved.
+	RetrieveAll(ctx context.Context, pm mgclients.Page) (mgclients.ClientsPage, error)
+
+	// SearchClients retrieves the users matching the page search criteria.
+	SearchClients(ctx context.Context, pm mgclients.Page) (mgclients.ClientsPage, error)
+
+	// RetrieveAllByIDs retrieves the users with the page IDs.
+	RetrieveAllByIDs(ctx context.Context, pm mgclients.Page) (mgclients.ClientsPage, error)
+
+	// Update updates the enabled user name and metadata.
+	Update(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// UpdateTags updates the enabled user tags.
+	UpdateTags(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// UpdateIdentity updates the enabled user identity.
+	UpdateIdentity(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// UpdateSecret updates the enabled user secret.
+	UpdateSecret(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// UpdateSecretIfUnchanged updates the enabled user secret only if the
+	// stored secret still equals the current one. It returns
+	// repoerr.ErrConcurrentUpdate if the secret was changed in the meantime.
+	UpdateSecretIfUnchanged(ctx context.Context, client mgclients.Client, current string) (mgclients.Client, error)
+
+	// UpdateRole updates the enabled user role.
+	UpdateRole(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// UpdateRoles updates the roles of the enabled users in a single
+	// transaction. If any of the users can't be updated, none is.
+	UpdateRoles(ctx context.Context, cs []mgclients.Client) ([]mgclients.Client, error)
+
+	// ChangeStatus changes the user status.
+	ChangeStatus(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// Delete deletes the user with the given ID, leaving its tombstone in the
+	// changes of the users.
+	Delete(ctx context.Context, id string) error
+
+	// RetrieveChanges retrieves up to the query limit of the latest changes
+	// of the users matching the query, ordered by the change time and the
+	// user ID. The change time of the user is its update time, or creation
+	// time if never updated, and the deletion time of the tombstone.
+	RetrieveChanges(ctx context.Context, q ChangesQuery) ([]Change, error)
+
+	// RetrieveMFA retrieves the multi-factor authentication enrollment of
+	// the user with the given ID.
+	RetrieveMFA(ctx context.Context, id string) (MFAEnrollment, error)
+
+	// SaveMFASecret persists the TOTP secret of the pending enrollment,
+	// replacing the previous pending one. It fails with ErrConflict if the
+	// user has already enrolled.
+	SaveMFASecret(ctx context.Context, id, secret string) error
+
+	// ConfirmMFA confirms the pending enrollment, recording the time step
+	// of the code it was confirmed with.
+	ConfirmMFA(ctx context.Context, id string, step int64, at time.Time) error
+
+	// UseMFAStep records the time step of the used TOTP code. It fails with
+	// ErrConflict if a code of the same or a later step was already used.
+	UseMFAStep(ctx context.Context, id string, step int64) error
+
+	// CheckSuperAdmin returns nil if the user with the given ID has the admin role.
+	CheckSuperAdmin(ctx context.Context, adminID string) error
+
+	// SavePendingIdentity persists the identity change waiting for confirmation.
+	// It replaces any previous pending identity change of the user.
+	SavePendingIdentity(ctx context.Context, pi PendingIdentity) error
+
+	// RetrievePendingIdentity retrieves the pending identity change by its token.
+	RetrievePendingIdentity(ctx context.Context, token string) (PendingIdentity, error)
+
+	// RetrievePendingVerification retrieves the pending verification of the
+	// user's current identity, the pending identity equal to the identity of
+	// the user it belongs to.
+	RetrievePendingVerification(ctx context.Context, identity string) (PendingIdentity, error)
+
+	// IncrementPendingAttempts counts the wrong verification attempt of the
+	// pending identity change of the user and returns the attempts so far.
+	// Once the attempts reach the limit, the pending identity change expires.
+	IncrementPendingAttempts(ctx context.Context, clientID string, limit int) (int, error)
+
+	// RemovePendingIdentity removes the pending identity change of the user.
+	RemovePendingIdentity(ctx context.Context, clientID string) error
+
+	// SaveLinkedProvider links the user to the OAuth2 provider the user has
+	// signed in with. Linking the user to the same provider again is a no-op.
+	SaveLinkedProvider(ctx context.Context, clientID, provider string) error
+
+	// RetrieveLinkedProviders retrieves the names of the OAuth2 providers
+	// linked to the user, sorted by name.
+	RetrieveLinkedProviders(ctx context.Context, clientID string) ([]string, error)
+
+	// SavePendingDeletion persists the account deletion requested by the user.
+	// It replaces any previous pending deletion of the user.
+	SavePendingDeletion(ctx context.Context, pd PendingDeletion) error
+
+	// RetrievePendingDeletion retrieves the pending deletion by its token.
+	RetrievePendingDeletion(ctx context.Context, token string) (PendingDeletion, error)
+
+	// RemovePendingDeletion removes the pending deletion of the user.
+	RemovePendingDeletion(ctx context.Context, clientID string) error
+
+	// SavePendingDevice persists the device waiting for the user
+	// authorization. It replaces the pending device with the same device code.
+	SavePendingDevice(ctx context.Context, pd PendingDevice) error
+
+	// RetrievePendingDevice retrieves the pending device by its device code.
+	RetrievePendingDevice(ctx context.Context, deviceCode string) (PendingDevice, error)
+
+	// RetrievePendingDeviceByUserCode retrieves the pending device by its user code.
+	RetrievePendingDeviceByUserCode(ctx context.Context, userCode string) (PendingDevice, error)
+
+	// RemovePendingDevice removes the pending device by its device code.
+	RemovePendingDevice(ctx context.Context, deviceCode string) error
+
+	// RemoveExpiredPendingDevices removes the pending devices expired before
+	// the given time.
+	RemoveExpiredPendingDevices(ctx context.Context, before time.Time) error
+
+	// UpdateLastLogin records the login of the user at the given time.
+	UpdateLastLogin(ctx context.Context, id string, at time.Time) error
+
+	// RetrieveInactive retrieves up to the query limit of the users matching
+	// the inactivity query, the least recently active first. The user
+	// activity is the latest of its creation, update and last login.
+	RetrieveInactive(ctx context.Context, q InactivityQuery) ([]mgclients.Client, error)
+
+	// DisableInactive disables the user if it still matches the inactivity
+	// query, setting its update time to the given time. It returns
+	// ErrNotFound if the user doesn't match the query.
+	DisableInactive(ctx context.Context, id string, q InactivityQuery, at time.Time) (mgclients.Client, error)
+
+	// SaveInactivityWarning records that the user was warned about the
+	// inactivity at the given time. The warning is cleared by any later
+	// activity of the user.
+	SaveInactivityWarning(ctx context.Context, id string, at time.Time) error
+}
+
+// PendingIdentity represents the user identity change waiting for
+// confirmation, or the verification of the identity of a new user.
+type PendingIdentity struct {
+	ClientID  string
+	Identity  string
+	Token     string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Attempts  int
+}
+
+// PendingDeletion represents the account deletion requested by the user.
+// Until the deletion is confirmed, ExpiresAt is the expiration of the
+// confirmation token. Once confirmed, ExpiresAt is the end of the cooling-off
+// period during which the same token cancels the deletion. PrevStatus is the
+// status of the user before the deletion was confirmed, which is restored if
+// the deletion is canceled.
+type PendingDeletion struct {
+	ClientID    string
+	Token       string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	ConfirmedAt time.Time
+	PrevStatus  mgclients.Status
+}
+
+// PendingDevice represents the device waiting for the user to authorize it
+// with the user code. The device and the user codes are stored hashed. The
+// user authorizing the device is set as ClientID, unless the device is
+// denied. PolledAt is the time of the last poll of the device token.
+type PendingDevice struct {
+	DeviceCode string
+	UserCode   string
+	Audience   string
+	ClientID   string
+	Denied     bool
+	Interval   time.Duration
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	PolledAt   time.Time
+}
@@ -0,0 +1,1236 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package repotest contains the contract test suite every users repository
+// backend must pass.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/absmach/magistrala/internal/testsutil"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	"github.com/absmach/magistrala/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewRepository returns the repository under test. The repository must be
+// backed by an empty store, which the backend cleans up once the test is done.
+type NewRepository func(t *testing.T) users.Repository
+
+// Run runs the contract test suite against the repository backend.
+func Run(t *testing.T, newRepo NewRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo users.Repository)
+	}{
+		{"Save", testSave},
+		{"RetrieveByID", testRetrieveByID},
+		{"RetrieveByIdentity", testRetrieveByIdentity},
+		{"RetrieveAll", testRetrieveAll},
+		{"RetrieveAllOrdered", testRetrieveAllOrdered},
+		{"SearchClients", testSearchClients},
+		{"RetrieveAllByIDs", testRetrieveAllByIDs},
+		{"Update", testUpdate},
+		{"UpdateTags", testUpdateTags},
+		{"UpdateIdentity", testUpdateIdentity},
+		{"UpdateSecret", testUpdateSecret},
+		{"UpdateRole", testUpdateRole},
+		{"UpdateRoles", testUpdateRoles},
+		{"ChangeStatus", testChangeStatus},
+		{"Delete", testDelete},
+		{"PendingIdentity", testPendingIdentity},
+		{"PendingVerification", testPendingVerification},
+		{"PendingAttempts", testPendingAttempts},
+		{"LinkedProviders", testLinkedProviders},
+		{"PendingDeletion", testPendingDeletion},
+		{"PendingDevice", testPendingDevice},
+		{"Inactive", testInactive},
+		{"Changes", testChanges},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.test(t, newRepo(t))
+		})
+	}
+}
+
+var created = time.Now().UTC().Truncate(time.Millisecond)
+
+func newClient(t *testing.T, n int) mgclients.Client {
+	return mgclients.Client{
+		ID:   testsutil.GenerateUUID(t),
+		Name: fmt.Sprintf("user-%d", n),
+		Tags: []string{"tag1", "tag2"},
+		Credentials: mgclients.Credentials{
+			Identity: fmt.Sprintf("user-%d@example.com", n),
+			Secret:   "$tr0ngPassw0rd",
+		},
+		Metadata:  mgclients.Metadata{"key": "value"},
+		CreatedAt: created.Add(time.Duration(n) * time.Second),
+		Status:    mgclients.EnabledStatus,
+		Role:      mgclients.UserRole,
+	}
+}
+
+func save(t *testing.T, repo users.Repository, clients ...mgclients.Client) {
+	for _, c := range clients {
+		_, err := repo.Save(context.Background(), c)
+		require.Nil(t, err, fmt.Sprintf("failed to save client %s: %s", c.ID, err))
+	}
+}
+
+func ids(clients []mgclients.Client) []string {
+	var res []string
+	for _, c := range clients {
+		res = append(res, c.ID)
+	}
+
+	return res
+}
+
+func testSave(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+
+	sameName := newClient(t, 2)
+	sameName.Name = client.Name
+
+	sameIdentity := newClient(t, 3)
+	sameIdentity.Credentials.Identity = client.Credentials.Identity
+
+	cases := []struct {
+		desc   string
+		client mgclients.Client
+		err    error
+	}{
+		{
+			desc:   "save new client",
+			client: client,
+			err:    nil,
+		},
+		{
+			desc:   "save client with existing id",
+			client: client,
+			err:    repoerr.ErrConflict,
+		},
+		{
+			desc:   "save client with existing name",
+			client: sameName,
+			err:    repoerr.ErrConflict,
+		},
+		{
+			desc:   "save client with existing identity",
+			client: sameIdentity,
+			err:    repoerr.ErrConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := repo.Save(context.Background(), tc.client)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.client.ID, c.ID, fmt.Sprintf("%s: expected id %s got %s\n", tc.desc, tc.client.ID, c.ID))
+			assert.Equal(t, tc.client.Name, c.Name, fmt.Sprintf("%s: expected name %s got %s\n", tc.desc, tc.client.Name, c.Name))
+			assert.Equal(t, tc.client.Credentials.Identity, c.Credentials.Identity, fmt.Sprintf("%s: expected identity %s got %s\n", tc.desc, tc.client.Credentials.Identity, c.Credentials.Identity))
+		}
+	}
+}
+
+func testRetrieveByID(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	disabled := newClient(t, 2)
+	disabled.Status = mgclients.DisabledStatus
+	save(t, repo, client, disabled)
+
+	cases := []struct {
+		desc     string
+		id       string
+		response mgclients.Client
+		err      error
+	}{
+		{
+			desc:     "retrieve existing client",
+			id:       client.ID,
+			response: client,
+			err:      nil,
+		},
+		{
+			desc:     "retrieve disabled client",
+			id:       disabled.ID,
+			response: disabled,
+			err:      nil,
+		},
+		{
+			desc: "retrieve non-existing client",
+			id:   testsutil.GenerateUUID(t),
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "retrieve client with empty id",
+			id:   "",
+			err:  repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := repo.RetrieveByID(context.Background(), tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.response.Name, c.Name, fmt.Sprintf("%s: expected name %s got %s\n", tc.desc, tc.response.Name, c.Name))
+			assert.Equal(t, tc.response.Tags, c.Tags, fmt.Sprintf("%s: expected tags %v got %v\n", tc.desc, tc.response.Tags, c.Tags))
+			assert.Equal(t, tc.response.Credentials, c.Credentials, fmt.Sprintf("%s: expected credentials %v got %v\n", tc.desc, tc.response.Credentials, c.Credentials))
+			assert.Equal(t, tc.response.Metadata, c.Metadata, fmt.Sprintf("%s: expected metadata %v got %v\n", tc.desc, tc.response.Metadata, c.Metadata))
+			assert.Equal(t, tc.response.Status, c.Status, fmt.Sprintf("%s: expected status %s got %s\n", tc.desc, tc.response.Status, c.Status))
+			assert.Equal(t, tc.response.Role, c.Role, fmt.Sprintf("%s: expected role %s got %s\n", tc.desc, tc.response.Role, c.Role))
+		}
+	}
+}
+
+func testRetrieveByIdentity(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	disabled := newClient(t, 2)
+	disabled.Status = mgclients.DisabledStatus
+	save(t, repo, client, disabled)
+
+	cases := []struct {
+		desc     string
+		identity string
+		err      error
+	}{
+		{
+			desc:     "retrieve client by identity",
+			identity: client.Credentials.Identity,
+			err:      nil,
+		},
+		{
+			desc:     "retrieve disabled client by identity",
+			identity: disabled.Credentials.Identity,
+			err:      repoerr.ErrNotFound,
+		},
+		{
+			desc:     "retrieve client by non-existing identity",
+			identity: "unknown@example.com",
+			err:      repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := repo.RetrieveByIdentity(context.Background(), tc.identity)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, client.ID, c.ID, fmt.Sprintf("%s: expected id %s got %s\n", tc.desc, client.ID, c.ID))
+			assert.Equal(t, client.Credentials.Secret, c.Credentials.Secret, fmt.Sprintf("%s: expected secret to be retrieved\n", tc.desc))
+		}
+	}
+}
+
+func testRetrieveAll(t *testing.T, repo users.Repository) {
+	var clients []mgclients.Client
+	for i := 0; i < 5; i++ {
+		c := newClient(t, i)
+		if i == 4 {
+			c.Status = mgclients.DisabledStatus
+			c.Role = mgclients.AdminRole
+		}
+		clients = append(clients, c)
+	}
+	save(t, repo, clients...)
+
+	cases := []struct {
+		desc     string
+		pm       mgclients.Page
+		response []mgclients.Client
+		total    uint64
+	}{
+		{
+			desc:     "retrieve all clients",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole},
+			response: clients,
+			total:    5,
+		},
+		{
+			desc:     "retrieve enabled clients",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.EnabledStatus, Role: mgclients.AllRole},
+			response: clients[:4],
+			total:    4,
+		},
+		{
+			desc:     "retrieve admin clients",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AdminRole},
+			response: clients[4:],
+			total:    1,
+		},
+		{
+			desc:     "retrieve clients page",
+			pm:       mgclients.Page{Offset: 1, Limit: 2, Status: mgclients.AllStatus, Role: mgclients.AllRole},
+			response: clients[1:3],
+			total:    5,
+		},
+		{
+			desc:     "retrieve clients page out of range",
+			pm:       mgclients.Page{Offset: 10, Limit: 2, Status: mgclients.AllStatus, Role: mgclients.AllRole},
+			response: nil,
+			total:    5,
+		},
+		{
+			desc:     "retrieve clients by metadata",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, Metadata: mgclients.Metadata{"key": "value"}},
+			response: clients,
+			total:    5,
+		},
+		{
+			desc:     "retrieve clients by non-existing metadata",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, Metadata: mgclients.Metadata{"key": "other"}},
+			response: nil,
+			total:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAll(context.Background(), tc.pm)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, tc.total, page.Total))
+		assert.Equal(t, tc.pm.Offset, page.Offset, fmt.Sprintf("%s: expected offset %d got %d\n", tc.desc, tc.pm.Offset, page.Offset))
+		assert.Equal(t, tc.pm.Limit, page.Limit, fmt.Sprintf("%s: expected limit %d got %d\n", tc.desc, tc.pm.Limit, page.Limit))
+		assert.Equal(t, ids(tc.response), ids(page.Clients), fmt.Sprintf("%s: expected clients %v got %v\n", tc.desc, ids(tc.response), ids(page.Clients)))
+	}
+}
+
+func testRetrieveAllOrdered(t *testing.T, repo users.Repository) {
+	departments := []string{"sales", "engineering", "support"}
+	var clients []mgclients.Client
+	for i, department := range departments {
+		c := newClient(t, i)
+		c.Metadata = mgclients.Metadata{"org": map[string]interface{}{"department": department}}
+		clients = append(clients, c)
+	}
+	// Client without the metadata field is always ordered last.
+	clients = append(clients, newClient(t, len(departments)))
+	save(t, repo, clients...)
+
+	cases := []struct {
+		desc     string
+		pm       mgclients.Page
+		response []mgclients.Client
+	}{
+		{
+			desc:     "retrieve clients ordered by nested metadata field ascending",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, Order: "metadata.org.department", Dir: "asc"},
+			response: []mgclients.Client{clients[1], clients[0], clients[2], clients[3]},
+		},
+		{
+			desc:     "retrieve clients ordered by nested metadata field descending",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, Order: "metadata.org.department", Dir: "desc"},
+			response: []mgclients.Client{clients[2], clients[0], clients[1], clients[3]},
+		},
+		{
+			desc:     "retrieve clients ordered by name descending",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, Order: "name", Dir: "desc"},
+			response: []mgclients.Client{clients[3], clients[2], clients[1], clients[0]},
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAll(context.Background(), tc.pm)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, ids(tc.response), ids(page.Clients), fmt.Sprintf("%s: expected clients %v got %v\n", tc.desc, ids(tc.response), ids(page.Clients)))
+	}
+
+	_, err := repo.RetrieveAll(context.Background(), mgclients.Page{Limit: 10, Role: mgclients.AllRole, Order: "metadata.org'department"})
+	assert.NotNil(t, err, "retrieve clients ordered by invalid metadata path: expected error")
+
+	_, err = repo.RetrieveAll(context.Background(), mgclients.Page{Limit: 10, Role: mgclients.AllRole, Order: "secret"})
+	assert.True(t, errors.Contains(err, mgclients.ErrInvalidOrder), fmt.Sprintf("retrieve clients ordered by unknown column: expected %s got %s", mgclients.ErrInvalidOrder, err))
+}
+
+func testSearchClients(t *testing.T, repo users.Repository) {
+	var clients []mgclients.Client
+	for i := 0; i < 3; i++ {
+		clients = append(clients, newClient(t, i))
+	}
+	other := newClient(t, 3)
+	other.Name = "other"
+	other.Credentials.Identity = "other@example.com"
+	clients = append(clients, other)
+	save(t, repo, clients...)
+
+	cases := []struct {
+		desc     string
+		pm       mgclients.Page
+		response []mgclients.Client
+		total    uint64
+	}{
+		{
+			desc:     "search clients by name",
+			pm:       mgclients.Page{Limit: 10, Name: "user", Order: "name", Dir: "asc", Role: mgclients.AllRole},
+			response: clients[:3],
+			total:    3,
+		},
+		{
+			desc:     "search clients by exact name",
+			pm:       mgclients.Page{Limit: 10, Name: "other", Role: mgclients.AllRole},
+			response: clients[3:],
+			total:    1,
+		},
+		{
+			desc:     "search clients by id",
+			pm:       mgclients.Page{Limit: 10, Id: other.ID, Role: mgclients.AllRole},
+			response: clients[3:],
+			total:    1,
+		},
+		{
+			desc:     "search clients by non-existing name",
+			pm:       mgclients.Page{Limit: 10, Name: "unknown", Role: mgclients.AllRole},
+			response: nil,
+			total:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.SearchClients(context.Background(), tc.pm)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, tc.total, page.Total))
+		assert.Equal(t, ids(tc.response), ids(page.Clients), fmt.Sprintf("%s: expected clients %v got %v\n", tc.desc, ids(tc.response), ids(page.Clients)))
+	}
+}
+
+func testRetrieveAllByIDs(t *testing.T, repo users.Repository) {
+	var clients []mgclients.Client
+	for i := 0; i < 4; i++ {
+		clients = append(clients, newClient(t, i))
+	}
+	save(t, repo, clients...)
+
+	cases := []struct {
+		desc     string
+		pm       mgclients.Page
+		response []mgclients.Client
+		total    uint64
+	}{
+		{
+			desc:     "retrieve clients by ids",
+			pm:       mgclients.Page{Limit: 10, IDs: ids(clients[1:3]), Status: mgclients.AllStatus, Role: mgclients.AllRole},
+			response: clients[1:3],
+			total:    2,
+		},
+		{
+			desc:     "retrieve clients by non-existing ids",
+			pm:       mgclients.Page{Limit: 10, IDs: []string{testsutil.GenerateUUID(t)}, Status: mgclients.AllStatus, Role: mgclients.AllRole},
+			response: nil,
+			total:    0,
+		},
+		{
+			desc:     "retrieve clients by empty ids",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole},
+			response: nil,
+			total:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAllByIDs(context.Background(), tc.pm)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, tc.total, page.Total))
+		assert.Equal(t, ids(tc.response), ids(page.Clients), fmt.Sprintf("%s: expected clients %v got %v\n", tc.desc, ids(tc.response), ids(page.Clients)))
+	}
+}
+
+func testUpdate(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	disabled := newClient(t, 2)
+	disabled.Status = mgclients.DisabledStatus
+	save(t, repo, client, disabled)
+
+	cases := []struct {
+		desc   string
+		client mgclients.Client
+		err    error
+	}{
+		{
+			desc:   "update client name and metadata",
+			client: mgclients.Client{ID: client.ID, Name: "updated", Metadata: mgclients.Metadata{"updated": "value"}},
+			err:    nil,
+		},
+		{
+			desc:   "update disabled client",
+			client: mgclients.Client{ID: disabled.ID, Name: "updated-disabled"},
+			err:    repoerr.ErrNotFound,
+		},
+		{
+			desc:   "update non-existing client",
+			client: mgclients.Client{ID: testsutil.GenerateUUID(t), Name: "updated-unknown"},
+			err:    repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		tc.client.UpdatedAt = time.Now().UTC()
+		tc.client.UpdatedBy = client.ID
+		c, err := repo.Update(context.Background(), tc.client)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.client.Name, c.Name, fmt.Sprintf("%s: expected name %s got %s\n", tc.desc, tc.client.Name, c.Name))
+			assert.Equal(t, tc.client.Metadata, c.Metadata, fmt.Sprintf("%s: expected metadata %v got %v\n", tc.desc, tc.client.Metadata, c.Metadata))
+			assert.Equal(t, tc.client.UpdatedBy, c.UpdatedBy, fmt.Sprintf("%s: expected updated by %s got %s\n", tc.desc, tc.client.UpdatedBy, c.UpdatedBy))
+		}
+	}
+}
+
+func testUpdateTags(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	tags := []string{"updated1", "updated2"}
+	c, err := repo.UpdateTags(context.Background(), mgclients.Client{ID: client.ID, Tags: tags, UpdatedAt: time.Now().UTC(), UpdatedBy: client.ID})
+	assert.Nil(t, err, fmt.Sprintf("update tags: unexpected error %s", err))
+	assert.Equal(t, tags, c.Tags, fmt.Sprintf("update tags: expected %v got %v", tags, c.Tags))
+
+	c, err = repo.RetrieveByID(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve updated client: unexpected error %s", err))
+	assert.Equal(t, tags, c.Tags, fmt.Sprintf("retrieve updated client: expected tags %v got %v", tags, c.Tags))
+
+	_, err = repo.UpdateTags(context.Background(), mgclients.Client{ID: testsutil.GenerateUUID(t), Tags: tags, UpdatedAt: time.Now().UTC()})
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("update tags of non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func testUpdateIdentity(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	other := newClient(t, 2)
+	save(t, repo, client, other)
+
+	cases := []struct {
+		desc     string
+		id       string
+		identity string
+		err      error
+	}{
+		{
+			desc:     "update client identity",
+			id:       client.ID,
+			identity: "updated@example.com",
+			err:      nil,
+		},
+		{
+			desc:     "update client identity to existing identity",
+			id:       client.ID,
+			identity: other.Credentials.Identity,
+			err:      repoerr.ErrConflict,
+		},
+		{
+			desc:     "update non-existing client identity",
+			id:       testsutil.GenerateUUID(t),
+			identity: "unknown@example.com",
+			err:      repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		cli := mgclients.Client{
+			ID:          tc.id,
+			Credentials: mgclients.Credentials{Identity: tc.identity},
+			UpdatedAt:   time.Now().UTC(),
+			UpdatedBy:   client.ID,
+		}
+		c, err := repo.UpdateIdentity(context.Background(), cli)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.identity, c.Credentials.Identity, fmt.Sprintf("%s: expected identity %s got %s\n", tc.desc, tc.identity, c.Credentials.Identity))
+			_, err = repo.RetrieveByIdentity(context.Background(), tc.identity)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error retrieving client by new identity %s\n", tc.desc, err))
+		}
+	}
+}
+
+func testUpdateSecret(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	secret := "$updat3dPassw0rd"
+	cli := mgclients.Client{
+		ID:          client.ID,
+		Credentials: mgclients.Credentials{Identity: client.Credentials.Identity, Secret: secret},
+		UpdatedAt:   time.Now().UTC(),
+		UpdatedBy:   client.ID,
+	}
+	_, err := repo.UpdateSecret(context.Background(), cli)
+	assert.Nil(t, err, fmt.Sprintf("update secret: unexpected error %s", err))
+
+	c, err := repo.RetrieveByID(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve updated client: unexpected error %s", err))
+	assert.Equal(t, secret, c.Credentials.Secret, "retrieve updated client: expected secret to be updated")
+
+	cli.ID = testsutil.GenerateUUID(t)
+	_, err = repo.UpdateSecret(context.Background(), cli)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("update secret of non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func testUpdateRole(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	err := repo.CheckSuperAdmin(context.Background(), client.ID)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("check super admin of user: expected %s got %s", repoerr.ErrNotFound, err))
+
+	c, err := repo.UpdateRole(context.Background(), mgclients.Client{ID: client.ID, Role: mgclients.AdminRole, UpdatedAt: time.Now().UTC(), UpdatedBy: client.ID})
+	assert.Nil(t, err, fmt.Sprintf("update role: unexpected error %s", err))
+	assert.Equal(t, mgclients.AdminRole, c.Role, fmt.Sprintf("update role: expected %s got %s", mgclients.AdminRole, c.Role))
+
+	err = repo.CheckSuperAdmin(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("check super admin of admin: unexpected error %s", err))
+
+	_, err = repo.UpdateRole(context.Background(), mgclients.Client{ID: testsutil.GenerateUUID(t), Role: mgclients.AdminRole, UpdatedAt: time.Now().UTC()})
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("update role of non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func testUpdateRoles(t *testing.T, repo users.Repository) {
+	client1, client2 := newClient(t, 1), newClient(t, 2)
+	save(t, repo, client1, client2)
+
+	updates := []mgclients.Client{
+		{ID: client1.ID, Role: mgclients.AdminRole, UpdatedAt: time.Now().UTC(), UpdatedBy: client1.ID},
+		{ID: client2.ID, Role: mgclients.AdminRole, UpdatedAt: time.Now().UTC(), UpdatedBy: client1.ID},
+	}
+	cs, err := repo.UpdateRoles(context.Background(), updates)
+	assert.Nil(t, err, fmt.Sprintf("update roles: unexpected error %s", err))
+	assert.ElementsMatch(t, []string{client1.ID, client2.ID}, ids(cs), fmt.Sprintf("update roles: expected %v got %v", []string{client1.ID, client2.ID}, ids(cs)))
+	for _, c := range cs {
+		assert.Equal(t, mgclients.AdminRole, c.Role, fmt.Sprintf("update roles: expected %s got %s", mgclients.AdminRole, c.Role))
+	}
+
+	updates = []mgclients.Client{
+		{ID: client1.ID, Role: mgclients.UserRole, UpdatedAt: time.Now().UTC(), UpdatedBy: client1.ID},
+		{ID: testsutil.GenerateUUID(t), Role: mgclients.UserRole, UpdatedAt: time.Now().UTC(), UpdatedBy: client1.ID},
+	}
+	_, err = repo.UpdateRoles(context.Background(), updates)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("update roles with non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+
+	err = repo.CheckSuperAdmin(context.Background(), client1.ID)
+	assert.Nil(t, err, fmt.Sprintf("check role after failed update: expected role to be unchanged, got %s", err))
+}
+
+func testChangeStatus(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	disabledAt := time.Now().UTC().Truncate(time.Millisecond)
+	c, err := repo.ChangeStatus(context.Background(), mgclients.Client{ID: client.ID, Status: mgclients.DisabledStatus, UpdatedAt: disabledAt, UpdatedBy: client.ID, DisabledAt: &disabledAt})
+	assert.Nil(t, err, fmt.Sprintf("disable client: unexpected error %s", err))
+	assert.Equal(t, mgclients.DisabledStatus, c.Status, fmt.Sprintf("disable client: expected %s got %s", mgclients.DisabledStatus, c.Status))
+
+	c, err = repo.RetrieveByID(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve disabled client: unexpected error %s", err))
+	assert.Equal(t, mgclients.DisabledStatus, c.Status, fmt.Sprintf("retrieve disabled client: expected %s got %s", mgclients.DisabledStatus, c.Status))
+	assertDisabledAt(t, "retrieve disabled client", &disabledAt, c.DisabledAt)
+
+	// Later changes don't move the time the client was disabled.
+	c, err = repo.ChangeStatus(context.Background(), mgclients.Client{ID: client.ID, Status: mgclients.DeletedStatus, UpdatedAt: time.Now().UTC(), UpdatedBy: client.ID})
+	assert.Nil(t, err, fmt.Sprintf("delete client: unexpected error %s", err))
+	c, err = repo.ChangeStatus(context.Background(), mgclients.Client{ID: client.ID, Status: mgclients.DisabledStatus, UpdatedAt: time.Now().UTC(), UpdatedBy: client.ID})
+	assert.Nil(t, err, fmt.Sprintf("restore disabled client: unexpected error %s", err))
+	assertDisabledAt(t, "restore disabled client", &disabledAt, c.DisabledAt)
+
+	c, err = repo.ChangeStatus(context.Background(), mgclients.Client{ID: client.ID, Status: mgclients.EnabledStatus, UpdatedAt: time.Now().UTC(), UpdatedBy: client.ID})
+	assert.Nil(t, err, fmt.Sprintf("enable client: unexpected error %s", err))
+	assert.Equal(t, mgclients.EnabledStatus, c.Status, fmt.Sprintf("enable client: expected %s got %s", mgclients.EnabledStatus, c.Status))
+	assertDisabledAt(t, "enable client", nil, c.DisabledAt)
+
+	_, err = repo.ChangeStatus(context.Background(), mgclients.Client{ID: testsutil.GenerateUUID(t), Status: mgclients.DisabledStatus, UpdatedAt: time.Now().UTC()})
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("change status of non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func assertDisabledAt(t *testing.T, desc string, expected, actual *time.Time) {
+	if expected == nil {
+		assert.Nil(t, actual, fmt.Sprintf("%s: expected no disable time got %v", desc, actual))
+		return
+	}
+	if assert.NotNil(t, actual, fmt.Sprintf("%s: expected disable time %s", desc, expected)) {
+		assert.True(t, expected.Equal(*actual), fmt.Sprintf("%s: expected disable time %s got %s", desc, expected, actual))
+	}
+}
+
+func testDelete(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	err := repo.Delete(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("delete client: unexpected error %s", err))
+
+	_, err = repo.RetrieveByID(context.Background(), client.ID)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("retrieve deleted client: expected %s got %s", repoerr.ErrNotFound, err))
+
+	err = repo.Delete(context.Background(), client.ID)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("delete non-existing client: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func testPendingIdentity(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	pi := users.PendingIdentity{
+		ClientID:  client.ID,
+		Identity:  "pending@example.com",
+		Token:     "token",
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	}
+	err := repo.SavePendingIdentity(context.Background(), pi)
+	assert.Nil(t, err, fmt.Sprintf("save pending identity: unexpected error %s", err))
+
+	// Saving the pending identity again replaces the previous one.
+	pi.Identity = "replaced@example.com"
+	pi.Token = "new-token"
+	err = repo.SavePendingIdentity(context.Background(), pi)
+	assert.Nil(t, err, fmt.Sprintf("replace pending identity: unexpected error %s", err))
+
+	cases := []struct {
+		desc     string
+		token    string
+		identity string
+		err      error
+	}{
+		{
+			desc:     "retrieve pending identity",
+			token:    pi.Token,
+			identity: pi.Identity,
+			err:      nil,
+		},
+		{
+			desc:  "retrieve replaced pending identity",
+			token: "token",
+			err:   repoerr.ErrNotFound,
+		},
+		{
+			desc:  "retrieve pending identity with empty token",
+			token: "",
+			err:   repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := repo.RetrievePendingIdentity(context.Background(), tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.identity, res.Identity, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.identity, res.Identity))
+		if err == nil {
+			assert.Equal(t, client.ID, res.ClientID, fmt.Sprintf("%s: expected client id %s got %s\n", tc.desc, client.ID, res.ClientID))
+			assert.WithinDuration(t, pi.ExpiresAt, res.ExpiresAt, time.Second, fmt.Sprintf("%s: expected expiration %s got %s\n", tc.desc, pi.ExpiresAt, res.ExpiresAt))
+		}
+	}
+
+	err = repo.RemovePendingIdentity(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove pending identity: unexpected error %s", err))
+	_, err = repo.RetrievePendingIdentity(context.Background(), pi.Token)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("retrieve removed pending identity: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func testPendingVerification(t *testing.T, repo users.Repository) {
+	unverified := newClient(t, 1)
+	unverified.Credentials.Identity = "+15551234567"
+	unverified.Status = mgclients.DisabledStatus
+	changing := newClient(t, 2)
+	save(t, repo, unverified, changing)
+
+	verification := users.PendingIdentity{
+		ClientID:  unverified.ID,
+		Identity:  unverified.Credentials.Identity,
+		Token:     "verification-token",
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	}
+	change := users.PendingIdentity{
+		ClientID:  changing.ID,
+		Identity:  "+15557654321",
+		Token:     "change-token",
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	}
+	for _, pi := range []users.PendingIdentity{verification, change} {
+		err := repo.SavePendingIdentity(context.Background(), pi)
+		require.Nil(t, err, fmt.Sprintf("save pending identity: unexpected error %s", err))
+	}
+
+	cases := []struct {
+		desc     string
+		identity string
+		clientID string
+		err      error
+	}{
+		{
+			desc:     "retrieve pending verification of the current identity",
+			identity: unverified.Credentials.Identity,
+			clientID: unverified.ID,
+			err:      nil,
+		},
+		{
+			desc:     "retrieve pending verification of the identity change",
+			identity: change.Identity,
+			err:      repoerr.ErrNotFound,
+		},
+		{
+			desc:     "retrieve pending verification of non-existing identity",
+			identity: "+15550000000",
+			err:      repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := repo.RetrievePendingVerification(context.Background(), tc.identity)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.clientID, res.ClientID, fmt.Sprintf("%s: expected client id %s got %s\n", tc.desc, tc.clientID, res.ClientID))
+	}
+}
+
+func testPendingAttempts(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	client.Credentials.Identity = "+15551234567"
+	client.Status = mgclients.DisabledStatus
+	save(t, repo, client)
+
+	pi := users.PendingIdentity{
+		ClientID:  client.ID,
+		Identity:  client.Credentials.Identity,
+		Token:     "attempts-token",
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+		Attempts:  1,
+	}
+	err := repo.SavePendingIdentity(context.Background(), pi)
+	require.Nil(t, err, fmt.Sprintf("save pending identity: unexpected error %s", err))
+
+	cases := []struct {
+		desc     string
+		clientID string
+		attempts int
+		expired  bool
+		err      error
+	}{
+		{
+			desc:     "increment pending attempts",
+			clientID: client.ID,
+			attempts: 2,
+		},
+		{
+			desc:     "increment pending attempts to the limit",
+			clientID: client.ID,
+			attempts: 3,
+			expired:  true,
+		},
+		{
+			desc:     "increment pending attempts of non-existing client",
+			clientID: testsutil.GenerateUUID(t),
+			err:      repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		attempts, err := repo.IncrementPendingAttempts(context.Background(), tc.clientID, 3)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.attempts, attempts, fmt.Sprintf("%s: expected %d attempts got %d\n", tc.desc, tc.attempts, attempts))
+		if err != nil {
+			continue
+		}
+		res, err := repo.RetrievePendingVerification(context.Background(), pi.Identity)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.attempts, res.Attempts, fmt.Sprintf("%s: expected %d stored attempts got %d\n", tc.desc, tc.attempts, res.Attempts))
+		assert.Equal(t, tc.expired, !time.Now().UTC().Before(res.ExpiresAt), fmt.Sprintf("%s: expected expired %t got expiration %s\n", tc.desc, tc.expired, res.ExpiresAt))
+	}
+
+	// The new code keeps the attempts the service carries over.
+	pi.Token = "resent-token"
+	pi.Attempts = 3
+	err = repo.SavePendingIdentity(context.Background(), pi)
+	require.Nil(t, err, fmt.Sprintf("resave pending identity: unexpected error %s", err))
+	res, err := repo.RetrievePendingIdentity(context.Background(), pi.Token)
+	require.Nil(t, err, fmt.Sprintf("retrieve resaved pending identity: unexpected error %s", err))
+	assert.Equal(t, pi.Attempts, res.Attempts, fmt.Sprintf("resave pending identity: expected %d attempts got %d", pi.Attempts, res.Attempts))
+}
+
+func testLinkedProviders(t *testing.T, repo users.Repository) {
+	google := newClient(t, 1)
+	both := newClient(t, 2)
+	unlinked := newClient(t, 3)
+	save(t, repo, google, both, unlinked)
+
+	links := []struct {
+		clientID string
+		provider string
+	}{
+		{google.ID, "google"},
+		{both.ID, "saml"},
+		{both.ID, "google"},
+		// Linking the same provider again is a no-op.
+		{both.ID, "google"},
+	}
+	for _, l := range links {
+		err := repo.SaveLinkedProvider(context.Background(), l.clientID, l.provider)
+		assert.Nil(t, err, fmt.Sprintf("link client %s to %s: unexpected error %s", l.clientID, l.provider, err))
+	}
+
+	providers, err := repo.RetrieveLinkedProviders(context.Background(), both.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve linked providers: unexpected error %s", err))
+	assert.Equal(t, []string{"google", "saml"}, providers, fmt.Sprintf("retrieve linked providers: expected %v got %v", []string{"google", "saml"}, providers))
+
+	providers, err = repo.RetrieveLinkedProviders(context.Background(), unlinked.ID)
+	assert.Nil(t, err, fmt.Sprintf("retrieve linked providers of unlinked client: unexpected error %s", err))
+	assert.Empty(t, providers, fmt.Sprintf("retrieve linked providers of unlinked client: expected none got %v", providers))
+
+	cases := []struct {
+		desc     string
+		pm       mgclients.Page
+		response []mgclients.Client
+	}{
+		{
+			desc:     "retrieve clients linked to provider",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, OAuthProvider: "google"},
+			response: []mgclients.Client{google, both},
+		},
+		{
+			desc:     "retrieve clients linked to provider by name",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, OAuthProvider: "saml", Name: "user"},
+			response: []mgclients.Client{both},
+		},
+		{
+			desc:     "retrieve clients linked to unknown provider",
+			pm:       mgclients.Page{Limit: 10, Status: mgclients.AllStatus, Role: mgclients.AllRole, OAuthProvider: "github"},
+			response: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAll(context.Background(), tc.pm)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s\n", tc.desc, err))
+		assert.Equal(t, uint64(len(tc.response)), page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, len(tc.response), page.Total))
+		assert.ElementsMatch(t, ids(tc.response), ids(page.Clients), fmt.Sprintf("%s: expected clients %v got %v\n", tc.desc, ids(tc.response), ids(page.Clients)))
+	}
+}
+
+func testPendingDeletion(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	pd := users.PendingDeletion{
+		ClientID:  client.ID,
+		Token:     "token",
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	}
+	err := repo.SavePendingDeletion(context.Background(), pd)
+	assert.Nil(t, err, fmt.Sprintf("save pending deletion: unexpected error %s", err))
+
+	res, err := repo.RetrievePendingDeletion(context.Background(), pd.Token)
+	assert.Nil(t, err, fmt.Sprintf("retrieve pending deletion: unexpected error %s", err))
+	assert.Equal(t, client.ID, res.ClientID, fmt.Sprintf("retrieve pending deletion: expected client id %s got %s", client.ID, res.ClientID))
+	assert.True(t, res.ConfirmedAt.IsZero(), fmt.Sprintf("retrieve pending deletion: expected unconfirmed deletion got %s", res.ConfirmedAt))
+
+	// Saving the pending deletion again replaces the previous one.
+	pd.ConfirmedAt = time.Now().UTC()
+	pd.ExpiresAt = pd.ConfirmedAt.Add(24 * time.Hour)
+	err = repo.SavePendingDeletion(context.Background(), pd)
+	assert.Nil(t, err, fmt.Sprintf("confirm pending deletion: unexpected error %s", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		err   error
+	}{
+		{
+			desc:  "retrieve confirmed pending deletion",
+			token: pd.Token,
+			err:   nil,
+		},
+		{
+			desc:  "retrieve pending deletion with invalid token",
+			token: "invalid",
+			err:   repoerr.ErrNotFound,
+		},
+		{
+			desc:  "retrieve pending deletion with empty token",
+			token: "",
+			err:   repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := repo.RetrievePendingDeletion(context.Background(), tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.WithinDuration(t, pd.ConfirmedAt, res.ConfirmedAt, time.Second, fmt.Sprintf("%s: expected confirmation %s got %s\n", tc.desc, pd.ConfirmedAt, res.ConfirmedAt))
+			assert.WithinDuration(t, pd.ExpiresAt, res.ExpiresAt, time.Second, fmt.Sprintf("%s: expected expiration %s got %s\n", tc.desc, pd.ExpiresAt, res.ExpiresAt))
+		}
+	}
+
+	err = repo.RemovePendingDeletion(context.Background(), client.ID)
+	assert.Nil(t, err, fmt.Sprintf("remove pending deletion: unexpected error %s", err))
+	_, err = repo.RetrievePendingDeletion(context.Background(), pd.Token)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("retrieve removed pending deletion: expected %s got %s", repoerr.ErrNotFound, err))
+}
+
+func testPendingDevice(t *testing.T, repo users.Repository) {
+	client := newClient(t, 1)
+	save(t, repo, client)
+
+	pd := users.PendingDevice{
+		DeviceCode: "device-code",
+		UserCode:   "user-code",
+		Audience:   "cli",
+		Interval:   5 * time.Second,
+		CreatedAt:  time.Now().UTC(),
+		ExpiresAt:  time.Now().UTC().Add(10 * time.Minute),
+	}
+	err := repo.SavePendingDevice(context.Background(), pd)
+	assert.Nil(t, err, fmt.Sprintf("save pending device: unexpected error %s", err))
+
+	res, err := repo.RetrievePendingDevice(context.Background(), pd.DeviceCode)
+	assert.Nil(t, err, fmt.Sprintf("retrieve pending device: unexpected error %s", err))
+	assert.Equal(t, pd.Audience, res.Audience, fmt.Sprintf("retrieve pending device: expected audience %s got %s", pd.Audience, res.Audience))
+	assert.Empty(t, res.ClientID, fmt.Sprintf("retrieve pending device: expected unauthorized device got %s", res.ClientID))
+	assert.True(t, res.PolledAt.IsZero(), fmt.Sprintf("retrieve pending device: expected device not polled got %s", res.PolledAt))
+
+	// Saving the pending device again records the poll and the authorization.
+	pd.ClientID = client.ID
+	pd.Interval += 5 * time.Second
+	pd.PolledAt = time.Now().UTC()
+	err = repo.SavePendingDevice(context.Background(), pd)
+	assert.Nil(t, err, fmt.Sprintf("authorize pending device: unexpected error %s", err))
+
+	cases := []struct {
+		desc     string
+		retrieve func(context.Context, string) (users.PendingDevice, error)
+		code     string
+		err      error
+	}{
+		{
+			desc:     "retrieve pending device by device code",
+			retrieve: repo.RetrievePendingDevice,
+			code:     pd.DeviceCode,
+			err:      nil,
+		},
+		{
+			desc:     "retrieve pending device by user code",
+			retrieve: repo.RetrievePendingDeviceByUserCode,
+			code:     pd.UserCode,
+			err:      nil,
+		},
+		{
+			desc:     "retrieve pending device with invalid device code",
+			retrieve: repo.RetrievePendingDevice,
+			code:     pd.UserCode,
+			err:      repoerr.ErrNotFound,
+		},
+		{
+			desc:     "retrieve pending device with invalid user code",
+			retrieve: repo.RetrievePendingDeviceByUserCode,
+			code:     pd.DeviceCode,
+			err:      repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := tc.retrieve(context.Background(), tc.code)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, client.ID, res.ClientID, fmt.Sprintf("%s: expected client id %s got %s\n", tc.desc, client.ID, res.ClientID))
+			assert.Equal(t, pd.Interval, res.Interval, fmt.Sprintf("%s: expected interval %s got %s\n", tc.desc, pd.Interval, res.Interval))
+			assert.WithinDuration(t, pd.PolledAt, res.PolledAt, time.Second, fmt.Sprintf("%s: expected poll %s got %s\n", tc.desc, pd.PolledAt, res.PolledAt))
+			assert.WithinDuration(t, pd.ExpiresAt, res.ExpiresAt, time.Second, fmt.Sprintf("%s: expected expiration %s got %s\n", tc.desc, pd.ExpiresAt, res.ExpiresAt))
+		}
+	}
+
+	err = repo.RemovePendingDevice(context.Background(), pd.DeviceCode)
+	assert.Nil(t, err, fmt.Sprintf("remove pending device: unexpected error %s", err))
+	_, err = repo.RetrievePendingDevice(context.Background(), pd.DeviceCode)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("retrieve removed pending device: expected %s got %s", repoerr.ErrNotFound, err))
+
+	// The expired pending devices are purged, the others are kept.
+	expired := users.PendingDevice{
+		DeviceCode: "expired-device-code",
+		UserCode:   "expired-user-code",
+		Interval:   5 * time.Second,
+		CreatedAt:  time.Now().UTC().Add(-20 * time.Minute),
+		ExpiresAt:  time.Now().UTC().Add(-10 * time.Minute),
+	}
+	for _, d := range []users.PendingDevice{pd, expired} {
+		err = repo.SavePendingDevice(context.Background(), d)
+		assert.Nil(t, err, fmt.Sprintf("save pending device: unexpected error %s", err))
+	}
+	err = repo.RemoveExpiredPendingDevices(context.Background(), time.Now())
+	assert.Nil(t, err, fmt.Sprintf("remove expired pending devices: unexpected error %s", err))
+	_, err = repo.RetrievePendingDevice(context.Background(), expired.DeviceCode)
+	assert.True(t, errors.Contains(err, repoerr.ErrNotFound), fmt.Sprintf("retrieve expired pending device: expected %s got %s", repoerr.ErrNotFound, err))
+	_, err = repo.RetrievePendingDevice(context.Background(), pd.DeviceCode)
+	assert.Nil(t, err, fmt.Sprintf("retrieve pending device: unexpected error %s", err))
+}
+
+func testInactive(t *testing.T, repo users.Repository) {
+	const exemptTag = "service-account"
+	old := time.Now().UTC().Add(-100 * 24 * time.Hour)
+
+	stale := newClient(t, 1)
+	stale.CreatedAt = old
+	loggedIn := newClient(t, 2)
+	loggedIn.CreatedAt = old
+	fresh := newClient(t, 3)
+	fresh.CreatedAt = time.Now().UTC()
+	admin := newClient(t, 4)
+	admin.CreatedAt = old
+	admin.Role = mgclients.AdminRole
+	exempt := newClient(t, 5)
+	exempt.CreatedAt = old
+	exempt.Tags = []string{exemptTag}
+	disabled := newClient(t, 6)
+	disabled.CreatedAt = old
+	disabled.Status = mgclients.DisabledStatus
+	save(t, repo, stale, loggedIn, fresh, admin, exempt, disabled)
+
+	err := repo.UpdateLastLogin(context.Background(), loggedIn.ID, time.Now().UTC())
+	require.Nil(t, err, fmt.Sprintf("update last login: unexpected error %s", err))
+
+	q := users.InactivityQuery{
+		Before:    time.Now().UTC().Add(-90 * 24 * time.Hour),
+		ExemptTag: exemptTag,
+		Limit:     10,
+	}
+	res, err := repo.RetrieveInactive(context.Background(), q)
+	assert.Nil(t, err, fmt.Sprintf("retrieve inactive: unexpected error %s", err))
+	assert.Equal(t, []string{stale.ID}, ids(res), fmt.Sprintf("retrieve inactive: expected %v got %v", []string{stale.ID}, ids(res)))
+
+	// Without the exempt tag, the tagged users are inactive too.
+	res, err = repo.RetrieveInactive(context.Background(), users.InactivityQuery{Before: q.Before, Limit: 10})
+	assert.Nil(t, err, fmt.Sprintf("retrieve inactive without exempt tag: unexpected error %s", err))
+	assert.ElementsMatch(t, []string{stale.ID, exempt.ID}, ids(res), fmt.Sprintf("retrieve inactive without exempt tag: expected %v got %v", []string{stale.ID, exempt.ID}, ids(res)))
+
+	// The warned users are not selected until they are active again.
+	q.Unwarned = true
+	err = repo.SaveInactivityWarning(context.Background(), stale.ID, time.Now().UTC())
+	assert.Nil(t, err, fmt.Sprintf("save inactivity warning: unexpected error %s", err))
+	res, err = repo.RetrieveInactive(context.Background(), q)
+	assert.Nil(t, err, fmt.Sprintf("retrieve unwarned inactive: unexpected error %s", err))
+	assert.Empty(t, res, fmt.Sprintf("retrieve unwarned inactive: expected no users got %v", ids(res)))
+	q.Unwarned = false
+
+	// Only the users warned long enough ago are selected.
+	q.WarnedBefore = time.Now().UTC().Add(-time.Hour)
+	res, err = repo.RetrieveInactive(context.Background(), q)
+	assert.Nil(t, err, fmt.Sprintf("retrieve recently warned inactive: unexpected error %s", err))
+	assert.Empty(t, res, fmt.Sprintf("retrieve recently warned inactive: expected no users got %v", ids(res)))
+	q.WarnedBefore = time.Now().UTC().Add(time.Minute)
+	res, err = repo.RetrieveInactive(context.Background(), q)
+	assert.Nil(t, err, fmt.Sprintf("retrieve warned inactive: unexpected error %s", err))
+	assert.Equal(t, []string{stale.ID}, ids(res), fmt.Sprintf("retrieve warned inactive: expected %v got %v", []string{stale.ID}, ids(res)))
+
+	cases := []struct {
+		desc   string
+		id     string
+		status mgclients.Status
+		err    error
+	}{
+		{
+			desc:   "disable inactive user",
+			id:     stale.ID,
+			status: mgclients.DisabledStatus,
+			err:    nil,
+		},
+		{
+			desc: "disable already disabled inactive user",
+			id:   stale.ID,
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "disable user who logged in recently",
+			id:   loggedIn.ID,
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "disable recently created user",
+			id:   fresh.ID,
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "disable inactive admin",
+			id:   admin.ID,
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "disable inactive exempt user",
+			id:   exempt.ID,
+			err:  repoerr.ErrNotFound,
+		},
+		{
+			desc: "disable non-existing user",
+			id:   testsutil.GenerateUUID(t),
+			err:  repoerr.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		at := time.Now().UTC().Truncate(time.Millisecond)
+		c, err := repo.DisableInactive(context.Background(), tc.id, q, at)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.status, c.Status, fmt.Sprintf("%s: expected status %s got %s\n", tc.desc, tc.status, c.Status))
+			assertDisabledAt(t, tc.desc, &at, c.DisabledAt)
+		}
+	}
+
+	res, err = repo.RetrieveInactive(context.Background(), q)
+	assert.Nil(t, err, fmt.Sprintf("retrieve inactive after disabling: unexpected error %s", err))
+	assert.Empty(t, res, fmt.Sprintf("retrieve inactive after disabling: expected no users got %v", ids(res)))
+}
+
+func testChanges(t *testing.T, repo users.Repository) {
+	old := time.Now().UTC().Add(-24 * time.Hour)
+	since := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+
+	unchanged := newClient(t, 1)
+	unchanged.CreatedAt = old
+	added := newClient(t, 2)
+	added.CreatedAt = since.Add(time.Second)
+	updated := newClient(t, 3)
+	updated.CreatedAt = old
+	deleted := newClient(t, 4)
+	deleted.CreatedAt = old
+	save(t, repo, unchanged, added, updated, deleted)
+
+	_, err := repo.Update(context.Background(), mgclients.Client{ID: updated.ID, Name: "updated", UpdatedAt: since.Add(2 * time.Second), UpdatedBy: updated.ID})
+	require.Nil(t, err, fmt.Sprintf("update client: unexpected error %s", err))
+	err = repo.Delete(context.Background(), deleted.ID)
+	require.Nil(t, err, fmt.Sprintf("delete client: unexpected error %s", err))
+
+	expected := []struct {
+		id        string
+		operation string
+	}{
+		{added.ID, users.ChangeCreated},
+		{updated.ID, users.ChangeUpdated},
+		{deleted.ID, users.ChangeDeleted},
+	}
+
+	changes, err := repo.RetrieveChanges(context.Background(), users.ChangesQuery{Since: since, Limit: 10})
+	assert.Nil(t, err, fmt.Sprintf("retrieve changes: unexpected error %s", err))
+	require.Len(t, changes, len(expected), fmt.Sprintf("retrieve changes: expected %d changes got %d", len(expected), len(changes)))
+	for i, c := range changes {
+		assert.Equal(t, expected[i].id, c.ID, fmt.Sprintf("retrieve changes: expected change %d of %s got %s", i, expected[i].id, c.ID))
+		assert.Equal(t, expected[i].operation, c.Operation, fmt.Sprintf("retrieve changes: expected operation %s got %s", expected[i].operation, c.Operation))
+	}
+	assert.Equal(t, "updated", changes[1].Client.Name, fmt.Sprintf("retrieve changes: expected updated name got %s", changes[1].Client.Name))
+	assert.Nil(t, changes[2].Client, "retrieve changes: expected the tombstone without user")
+
+	// The feed resumed after each change returns the following one.
+	cq := users.ChangesQuery{Since: since, Limit: 1}
+	for i := range expected {
+		changes, err := repo.RetrieveChanges(context.Background(), cq)
+		assert.Nil(t, err, fmt.Sprintf("retrieve change %d: unexpected error %s", i, err))
+		require.Len(t, changes, 1, fmt.Sprintf("retrieve change %d: expected one change got %d", i, len(changes)))
+		assert.Equal(t, expected[i].id, changes[0].ID, fmt.Sprintf("retrieve change %d: expected %s got %s", i, expected[i].id, changes[0].ID))
+		cq.Since, cq.AfterID = changes[0].ChangedAt, changes[0].ID
+	}
+	changes, err = repo.RetrieveChanges(context.Background(), cq)
+	assert.Nil(t, err, fmt.Sprintf("retrieve changes at the end of the feed: unexpected error %s", err))
+	assert.Empty(t, changes, fmt.Sprintf("retrieve changes at the end of the feed: expected no changes got %d", len(changes)))
+}
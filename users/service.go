@@ -5,17 +5,22 @@ package users
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/absmach/magistrala"
 	mgauth "github.com/absmach/magistrala/auth"
+	"github.com/absmach/magistrala/pkg/apiutil"
 	"github.com/absmach/magistrala/pkg/authn"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
 	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/policies"
-	"github.com/absmach/magistrala/users/postgres"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -24,19 +29,153 @@ var (
 	errFailedPermissionsList = errors.New("failed to list permissions")
 	errRecoveryToken         = errors.New("failed to generate password recovery token")
 	errLoginDisableUser      = errors.New("failed to login in disabled user")
+	errIdentityToken         = errors.New("invalid or expired identity confirmation token")
+
+	// ErrTooManyObjectUsers indicates that the object has more users than allowed to list permissions for.
+	ErrTooManyObjectUsers = errors.New("object has too many users to list their permissions")
+)
+
+const (
+	// DefMaxTags is the default maximum number of tags per client.
+	DefMaxTags = 100
+
+	// DefMaxTagLen is the default maximum length of a single tag.
+	DefMaxTagLen = 256
+
+	// DefMaxObjectUsers is the default maximum number of users permissions are listed for.
+	DefMaxObjectUsers = 1000
+
+	// DefIdentityTokenTTL is the default validity period of the identity confirmation token.
+	DefIdentityTokenTTL = 24 * time.Hour
+
+	identityTokenSize = 32
+
+	// oauthProviderKey is the metadata key of the provider the user signed in with.
+	oauthProviderKey = "oauth_provider"
 )
 
+// Config contains the users service settings.
+type Config struct {
+	// MaxTags is the maximum number of tags a client can have.
+	MaxTags int
+
+	// MaxTagLen is the maximum length of a single tag.
+	MaxTagLen int
+
+	// MaxMetadataSize is the maximum size of the JSON-encoded client
+	// metadata in bytes.
+	MaxMetadataSize int
+
+	// WelcomeEmail enables sending a welcome e-mail on registration.
+	WelcomeEmail bool
+
+	// MaxObjectUsers is the maximum number of users with access to an object
+	// whose permissions are resolved when listing object permissions.
+	MaxObjectUsers int
+
+	// ConfirmIdentity requires the new identity to be confirmed before it
+	// replaces the current one.
+	ConfirmIdentity bool
+
+	// IdentityTokenTTL is the validity period of the identity confirmation token.
+	IdentityTokenTTL time.Duration
+
+	// VerifyEmail requires the self-registered users to verify their e-mail
+	// before they can log in. The users are disabled until verified.
+	VerifyEmail bool
+
+	// MFA defines the users that must enroll multi-factor authentication
+	// before they can log in, and the issuer of the TOTP secrets.
+	MFA MFAPolicy
+
+	// SecretUpdateLock rejects a secret update if the secret was changed
+	// after it was read, instead of letting the last update silently win.
+	SecretUpdateLock bool
+
+	// DefaultMetadata is the metadata merged into the metadata of new users.
+	DefaultMetadata DefaultMetadata
+
+	// LoginAlerts defines when bursts of failed logins are reported.
+	LoginAlerts LoginAlerts
+
+	// PhoneVerification defines how phone identities are verified.
+	PhoneVerification PhoneVerification
+
+	// EmailBranding maps a domain ID to the branding of the password reset
+	// e-mails sent to the users of that domain.
+	EmailBranding map[string]EmailBranding
+
+	// SelfDeletion defines how users delete their own accounts.
+	SelfDeletion SelfDeletion
+
+	// IdentityProviders defines the authentication backends tried when
+	// issuing a token.
+	IdentityProviders IdentityProviders
+
+	// Inactivity defines when the inactive users are disabled.
+	Inactivity InactivityConfig
+
+	// DeviceFlow defines the OAuth2 device authorization flow.
+	DeviceFlow DeviceFlow
+
+	// PasswordReset defines how the repeated password reset requests are
+	// coalesced.
+	PasswordReset PasswordReset
+
+	// JITDisabled lists the federated login providers whose users must be
+	// registered before their first login, instead of being provisioned
+	// on it.
+	JITDisabled []string
+}
+
 type service struct {
 	token      magistrala.TokenServiceClient
-	clients    postgres.Repository
+	clients    Repository
 	idProvider magistrala.IDProvider
 	policies   policies.Service
 	hasher     Hasher
 	email      Emailer
+	config     Config
+	logins     *loginFailures
+	resends    *verificationResends
 }
 
 // NewService returns a new Users service implementation.
-func NewService(token magistrala.TokenServiceClient, crepo postgres.Repository, policyService policies.Service, emailer Emailer, hasher Hasher, idp magistrala.IDProvider) Service {
+func NewService(token magistrala.TokenServiceClient, crepo Repository, policyService policies.Service, emailer Emailer, hasher Hasher, idp magistrala.IDProvider, cfg Config) Service {
+	if cfg.MaxTags <= 0 {
+		cfg.MaxTags = DefMaxTags
+	}
+	if cfg.MaxTagLen <= 0 {
+		cfg.MaxTagLen = DefMaxTagLen
+	}
+	if cfg.MaxObjectUsers <= 0 {
+		cfg.MaxObjectUsers = DefMaxObjectUsers
+	}
+	if cfg.MaxMetadataSize <= 0 {
+		cfg.MaxMetadataSize = mgclients.DefMaxMetadataSize
+	}
+	if cfg.IdentityTokenTTL <= 0 {
+		cfg.IdentityTokenTTL = DefIdentityTokenTTL
+	}
+	if cfg.PhoneVerification.CodeTTL <= 0 {
+		cfg.PhoneVerification.CodeTTL = DefPhoneCodeTTL
+	}
+	if cfg.SelfDeletion.TokenTTL <= 0 {
+		cfg.SelfDeletion.TokenTTL = DefDeletionTokenTTL
+	}
+	if cfg.SelfDeletion.CoolingOff <= 0 {
+		cfg.SelfDeletion.CoolingOff = DefDeletionCoolingOff
+	}
+	if cfg.DeviceFlow.CodeTTL <= 0 {
+		cfg.DeviceFlow.CodeTTL = DefDeviceCodeTTL
+	}
+	if cfg.DeviceFlow.PollInterval <= 0 {
+		cfg.DeviceFlow.PollInterval = DefDevicePollInterval
+	}
+	if cfg.PasswordReset.Cache == nil {
+		cfg.PasswordReset.Cache = newResetRequests()
+	}
+
 	return service{
 		token:      token,
 		clients:    crepo,
@@ -44,6 +183,9 @@ func NewService(token magistrala.TokenServiceClient, crepo postgres.Repository,
 		hasher:     hasher,
 		email:      emailer,
 		idProvider: idp,
+		config:     cfg,
+		logins:     newLoginFailures(cfg.LoginAlerts),
+		resends:    newVerificationResends(),
 	}
 }
 
@@ -53,6 +195,41 @@ func (svc service) RegisterClient(ctx context.Context, session authn.Session, cl
 			return mgclients.Client{}, err
 		}
 	}
+	// The claims set by the default metadata are allowed, since the
+	// defaults are configured by the operator.
+	if _, ok := cli.Metadata[ClaimsKey]; ok && selfRegister {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthorization, errClaimsSuperAdmin)
+	}
+
+	tags, err := svc.validateTags(cli.Tags)
+	if err != nil {
+		return mgclients.Client{}, err
+	}
+	cli.Tags = tags
+	cli.Metadata = svc.config.DefaultMetadata.apply(session.DomainID, cli.Metadata)
+	if err := cli.Metadata.ValidateSize(svc.config.MaxMetadataSize); err != nil {
+		return mgclients.Client{}, err
+	}
+	if _, err := parseClaims(cli.Metadata); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+
+	// Phone users are enabled once they verify the code sent to the phone.
+	phone := IsPhone(cli.Credentials.Identity)
+	if phone {
+		if svc.config.PhoneVerification.Sender == nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, errPhoneUnsupported)
+		}
+		if cli.Credentials.Identity, err = NormalizePhone(cli.Credentials.Identity); err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		cli.Status = mgclients.DisabledStatus
+	}
+	// Self-registered e-mail users are enabled once they verify the e-mail.
+	verifyEmail := !phone && selfRegister && svc.config.VerifyEmail
+	if verifyEmail {
+		cli.Status = mgclients.DisabledStatus
+	}
 
 	clientID, err := svc.idProvider.ID()
 	if err != nil {
@@ -90,19 +267,52 @@ func (svc service) RegisterClient(ctx context.Context, session authn.Session, cl
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrCreateEntity, err)
 	}
+	if phone || verifyEmail {
+		// Without the code the user could never be enabled, so the
+		// registration fails as a whole.
+		if phone {
+			err = svc.sendPhoneCode(ctx, client.ID, client.Credentials.Identity, 0)
+		} else {
+			err = svc.sendEmailVerification(ctx, client.ID, client.Name, client.Credentials.Identity)
+		}
+		if err != nil {
+			if errDelete := svc.clients.Delete(ctx, client.ID); errDelete != nil {
+				err = errors.Wrap(errDelete, err)
+			}
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrCreateEntity, err)
+		}
+		return client, nil
+	}
+	if svc.config.WelcomeEmail {
+		// Welcome e-mail is sent asynchronously and its failure must not fail the registration.
+		_ = svc.email.SendWelcome([]string{client.Credentials.Identity}, client.Name)
+	}
+
 	return client, nil
 }
 
-func (svc service) IssueToken(ctx context.Context, identity, secret string) (*magistrala.Token, error) {
-	dbUser, err := svc.clients.RetrieveByIdentity(ctx, identity)
+func (svc service) IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (*magistrala.Token, error) {
+	if IsPhone(identity) {
+		if phone, err := NormalizePhone(identity); err == nil {
+			identity = phone
+		}
+	}
+	dbUser, err := svc.authenticate(ctx, identity, secret)
 	if err != nil {
+		return &magistrala.Token{}, err
+	}
+	if err := svc.verifyMFA(ctx, dbUser, mfaCode); err != nil {
+		if errors.Contains(err, svcerr.ErrMFACode) {
+			svc.logins.failed(ctx, identity)
+		}
 		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
-	if err := svc.hasher.Compare(secret, dbUser.Credentials.Secret); err != nil {
-		return &magistrala.Token{}, errors.Wrap(svcerr.ErrLogin, err)
+	svc.logins.succeeded(ctx, identity)
+	if err := svc.clients.UpdateLastLogin(ctx, dbUser.ID, time.Now()); err != nil {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
 
-	token, err := svc.token.Issue(ctx, &magistrala.IssueReq{UserId: dbUser.ID, Type: uint32(mgauth.AccessKey)})
+	token, err := svc.token.Issue(ctx, &magistrala.IssueReq{UserId: dbUser.ID, Type: uint32(mgauth.AccessKey), Audience: audience, Binding: authn.Binding(ctx), Claims: Claims(dbUser.Metadata)})
 	if err != nil {
 		return &magistrala.Token{}, errors.Wrap(errIssueToken, err)
 	}
@@ -115,9 +325,14 @@ func (svc service) RefreshToken(ctx context.Context, session authn.Session, refr
 	if err != nil {
 		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, err)
 	}
-	if dbUser.Status == mgclients.DisabledStatus {
+	if dbUser.Status != mgclients.EnabledStatus {
 		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, errLoginDisableUser)
 	}
+	// The code was verified when the refresh token was issued, but the
+	// policy may require MFA since.
+	if _, err := svc.checkMFAEnrollment(ctx, dbUser); err != nil {
+		return &magistrala.Token{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
 
 	return svc.token.Refresh(ctx, &magistrala.RefreshReq{RefreshToken: refreshToken})
 }
@@ -135,6 +350,10 @@ func (svc service) ViewClient(ctx context.Context, session authn.Session, id str
 	}
 
 	client.Credentials.Secret = ""
+	client.LinkedProviders, err = svc.clients.RetrieveLinkedProviders(ctx, id)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
 
 	return client, nil
 }
@@ -186,15 +405,41 @@ func (svc service) UpdateClient(ctx context.Context, session authn.Session, cli
 		}
 	}
 
+	tags, err := svc.validateTags(cli.Tags)
+	if err != nil {
+		return mgclients.Client{}, err
+	}
+	cli.Tags = tags
+	if err := cli.Metadata.ValidateSize(svc.config.MaxMetadataSize); err != nil {
+		return mgclients.Client{}, err
+	}
+	if _, err := parseClaims(cli.Metadata); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
+	}
+	// Users may keep but not change their own claims. The super admins were
+	// checked above when updating other users.
+	if _, ok := cli.Metadata[ClaimsKey]; ok && session.UserID == cli.ID {
+		current, err := svc.clients.RetrieveByID(ctx, cli.ID)
+		if err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+		}
+		if claimsChanged(current.Metadata, cli.Metadata) {
+			if err := svc.checkSuperAdmin(ctx, session); err != nil {
+				return mgclients.Client{}, errors.Wrap(err, errClaimsSuperAdmin)
+			}
+		}
+	}
+
 	client := mgclients.Client{
 		ID:        cli.ID,
 		Name:      cli.Name,
+		Tags:      cli.Tags,
 		Metadata:  cli.Metadata,
 		UpdatedAt: time.Now(),
 		UpdatedBy: session.UserID,
 	}
 
-	client, err := svc.clients.Update(ctx, client)
+	client, err = svc.clients.Update(ctx, client)
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
@@ -208,13 +453,18 @@ func (svc service) UpdateClientTags(ctx context.Context, session authn.Session,
 		}
 	}
 
+	tags, err := svc.validateTags(cli.Tags)
+	if err != nil {
+		return mgclients.Client{}, err
+	}
+
 	client := mgclients.Client{
 		ID:        cli.ID,
-		Tags:      cli.Tags,
+		Tags:      tags,
 		UpdatedAt: time.Now(),
 		UpdatedBy: session.UserID,
 	}
-	client, err := svc.clients.UpdateTags(ctx, client)
+	client, err = svc.clients.UpdateTags(ctx, client)
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
@@ -229,6 +479,10 @@ func (svc service) UpdateClientIdentity(ctx context.Context, session authn.Sessi
 		}
 	}
 
+	if svc.config.ConfirmIdentity {
+		return svc.requestIdentityChange(ctx, clientID, identity)
+	}
+
 	cli := mgclients.Client{
 		ID: clientID,
 		Credentials: mgclients.Credentials{
@@ -244,9 +498,109 @@ func (svc service) UpdateClientIdentity(ctx context.Context, session authn.Sessi
 	return cli, nil
 }
 
+// requestIdentityChange stores the new identity as pending and sends the
+// confirmation link to the new address. The current identity stays active
+// until the change is confirmed.
+func (svc service) requestIdentityChange(ctx context.Context, clientID, identity string) (mgclients.Client, error) {
+	_, err := svc.clients.RetrieveByIdentity(ctx, identity)
+	switch {
+	case err == nil:
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, svcerr.ErrConflict)
+	case !errors.Contains(err, repoerr.ErrNotFound):
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	cli, err := svc.clients.RetrieveByID(ctx, clientID)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	token, err := generateIdentityToken()
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	now := time.Now()
+	pi := PendingIdentity{
+		ClientID:  cli.ID,
+		Identity:  identity,
+		Token:     hashIdentityToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(svc.config.IdentityTokenTTL),
+	}
+	if err := svc.clients.SavePendingIdentity(ctx, pi); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	if err := svc.email.SendIdentityConfirmation([]string{identity}, cli.Name, token); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	cli.Credentials.Secret = ""
+
+	return cli, nil
+}
+
+func (svc service) ConfirmIdentity(ctx context.Context, token string) (mgclients.Client, error) {
+	pi, err := svc.clients.RetrievePendingIdentity(ctx, hashIdentityToken(token))
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(errIdentityToken, err))
+	}
+	if time.Now().After(pi.ExpiresAt) {
+		if err := svc.clients.RemovePendingIdentity(ctx, pi.ClientID); err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+		}
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errIdentityToken)
+	}
+
+	old, err := svc.clients.RetrieveByID(ctx, pi.ClientID)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	// The pending current identity is the verification of the registered
+	// e-mail, which enables the user instead of changing the identity.
+	if pi.Identity == old.Credentials.Identity && !IsPhone(pi.Identity) {
+		return svc.verifyEmail(ctx, pi)
+	}
+
+	cli := mgclients.Client{
+		ID: pi.ClientID,
+		Credentials: mgclients.Credentials{
+			Identity: pi.Identity,
+		},
+		UpdatedAt: time.Now(),
+		UpdatedBy: pi.ClientID,
+	}
+	cli, err = svc.clients.UpdateIdentity(ctx, cli)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	if err := svc.clients.RemovePendingIdentity(ctx, pi.ClientID); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+	// Identity is already changed, so failing to notify the previous address must not fail the confirmation.
+	_ = svc.email.SendIdentityChanged([]string{old.Credentials.Identity}, old.Name, pi.Identity)
+
+	return cli, nil
+}
+
 func (svc service) GenerateResetToken(ctx context.Context, email, host string) error {
-	client, err := svc.clients.RetrieveByIdentity(ctx, email)
+	// The requests are coalesced and limited before the lookup, so neither
+	// reveals which identities belong to accounts. Requests which can't be
+	// tracked are rejected as well.
+	send, err := svc.claimReset(ctx, email)
 	if err != nil {
+		return errors.Wrap(apiutil.ErrTooManyRequests, err)
+	}
+	if !send {
+		// The reset e-mail sent within the window carries a valid token.
+		return nil
+	}
+
+	client, err := svc.clients.RetrieveByIdentity(ctx, email)
+	switch {
+	case errors.Contains(err, repoerr.ErrNotFound):
+		// Unknown identities are a no-op.
+		return nil
+	case err != nil:
+		svc.releaseReset(ctx, email)
 		return errors.Wrap(svcerr.ErrViewEntity, err)
 	}
 	issueReq := &magistrala.IssueReq{
@@ -255,10 +609,15 @@ func (svc service) GenerateResetToken(ctx context.Context, email, host string) e
 	}
 	token, err := svc.token.Issue(ctx, issueReq)
 	if err != nil {
+		svc.releaseReset(ctx, email)
 		return errors.Wrap(errRecoveryToken, err)
 	}
+	if err := svc.SendPasswordReset(ctx, host, email, client.Name, token.AccessToken); err != nil {
+		svc.releaseReset(ctx, email)
+		return err
+	}
 
-	return svc.SendPasswordReset(ctx, host, email, client.Name, token.AccessToken)
+	return nil
 }
 
 func (svc service) ResetSecret(ctx context.Context, session authn.Session, secret string) error {
@@ -271,6 +630,7 @@ func (svc service) ResetSecret(ctx context.Context, session authn.Session, secre
 	if err != nil {
 		return errors.Wrap(svcerr.ErrMalformedEntity, err)
 	}
+	current := c.Credentials.Secret
 	c = mgclients.Client{
 		ID: c.ID,
 		Credentials: mgclients.Credentials{
@@ -280,7 +640,10 @@ func (svc service) ResetSecret(ctx context.Context, session authn.Session, secre
 		UpdatedAt: time.Now(),
 		UpdatedBy: session.UserID,
 	}
-	if _, err := svc.clients.UpdateSecret(ctx, c); err != nil {
+	if _, err := svc.updateSecret(ctx, c, current); err != nil {
+		if errors.Contains(err, repoerr.ErrConcurrentUpdate) {
+			return errors.Wrap(svcerr.ErrConcurrentUpdate, err)
+		}
 		return errors.Wrap(svcerr.ErrAuthorization, err)
 	}
 	return nil
@@ -291,28 +654,54 @@ func (svc service) UpdateClientSecret(ctx context.Context, session authn.Session
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrViewEntity, err)
 	}
-	if _, err := svc.IssueToken(ctx, dbClient.Credentials.Identity, oldSecret); err != nil {
+	if _, err := svc.authenticate(ctx, dbClient.Credentials.Identity, oldSecret); err != nil {
 		return mgclients.Client{}, err
 	}
 	newSecret, err = svc.hasher.Hash(newSecret)
 	if err != nil {
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrMalformedEntity, err)
 	}
+	current := dbClient.Credentials.Secret
 	dbClient.Credentials.Secret = newSecret
 	dbClient.UpdatedAt = time.Now()
 	dbClient.UpdatedBy = session.UserID
 
-	dbClient, err = svc.clients.UpdateSecret(ctx, dbClient)
+	dbClient, err = svc.updateSecret(ctx, dbClient, current)
 	if err != nil {
+		if errors.Contains(err, repoerr.ErrConcurrentUpdate) {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrConcurrentUpdate, err)
+		}
 		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
 	}
 
 	return dbClient, nil
 }
 
-func (svc service) SendPasswordReset(_ context.Context, host, email, user, token string) error {
+// updateSecret saves the client secret. With the secret update lock enabled,
+// the update fails if the stored secret is no longer the current one.
+func (svc service) updateSecret(ctx context.Context, client mgclients.Client, current string) (mgclients.Client, error) {
+	if svc.config.SecretUpdateLock {
+		return svc.clients.UpdateSecretIfUnchanged(ctx, client, current)
+	}
+
+	return svc.clients.UpdateSecret(ctx, client)
+}
+
+func (svc service) SendPasswordReset(ctx context.Context, host, email, user, token string) error {
+	branding, err := svc.emailBranding(ctx, email)
+	if err != nil {
+		return err
+	}
 	to := []string{email}
-	return svc.email.SendPasswordReset(to, host, user, token)
+	return svc.email.SendPasswordReset(to, host, user, token, branding)
+}
+
+func (svc service) PreviewEmail(ctx context.Context, session authn.Session, name string, data EmailData) (EmailPreview, error) {
+	if err := svc.checkSuperAdmin(ctx, session); err != nil {
+		return EmailPreview{}, err
+	}
+
+	return svc.email.PreviewEmail(name, data)
 }
 
 func (svc service) UpdateClientRole(ctx context.Context, session authn.Session, cli mgclients.Client) (mgclients.Client, error) {
@@ -341,6 +730,79 @@ func (svc service) UpdateClientRole(ctx context.Context, session authn.Session,
 	return client, nil
 }
 
+func (svc service) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role mgclients.Role) ([]RoleUpdate, error) {
+	if err := svc.checkSuperAdmin(ctx, session); err != nil {
+		return nil, err
+	}
+	if role != mgclients.AdminRole && role != mgclients.UserRole {
+		return nil, svcerr.ErrInvalidRole
+	}
+
+	results := make([]RoleUpdate, len(ids))
+	index := make(map[string]int, len(ids))
+	var updates []mgclients.Client
+	var changed []string
+	for i, id := range ids {
+		results[i].ID = id
+		if _, ok := index[id]; ok {
+			continue
+		}
+		index[id] = i
+
+		client, err := svc.clients.RetrieveByID(ctx, id)
+		if err != nil {
+			if !errors.Contains(err, repoerr.ErrNotFound) {
+				return nil, errors.Wrap(svcerr.ErrViewEntity, err)
+			}
+			results[i].Err = svcerr.ErrNotFound
+			continue
+		}
+		// Disabled clients can't be updated, same as with UpdateClientRole.
+		if client.Status != mgclients.EnabledStatus {
+			results[i].Err = svcerr.ErrNotFound
+			continue
+		}
+		if client.Role != role {
+			changed = append(changed, id)
+		}
+		updates = append(updates, mgclients.Client{
+			ID:        id,
+			Role:      role,
+			UpdatedAt: time.Now(),
+			UpdatedBy: session.UserID,
+		})
+	}
+
+	if len(updates) > 0 {
+		if err := svc.updateClientsPolicy(ctx, changed, role); err != nil {
+			return nil, err
+		}
+
+		clients, err := svc.clients.UpdateRoles(ctx, updates)
+		if err != nil {
+			// Revert the policies of the clients whose role was changed.
+			prev := mgclients.UserRole
+			if role == mgclients.UserRole {
+				prev = mgclients.AdminRole
+			}
+			if errRollback := svc.updateClientsPolicy(ctx, changed, prev); errRollback != nil {
+				return nil, errors.Wrap(errRollback, err)
+			}
+			return nil, errors.Wrap(svcerr.ErrUpdateEntity, err)
+		}
+		for _, c := range clients {
+			results[index[c.ID]].Client = c
+		}
+	}
+
+	// Duplicate IDs share the result of the first occurrence.
+	for i := range results {
+		results[i] = results[index[results[i].ID]]
+	}
+
+	return results, nil
+}
+
 func (svc service) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	client := mgclients.Client{
 		ID:        id,
@@ -356,10 +818,15 @@ func (svc service) EnableClient(ctx context.Context, session authn.Session, id s
 }
 
 func (svc service) DisableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
+	// The disable time is stored apart from the update time, which later
+	// changes move, since the tokens of the user are accepted only during
+	// the grace period since the user was disabled.
+	now := time.Now()
 	client := mgclients.Client{
-		ID:        id,
-		UpdatedAt: time.Now(),
-		Status:    mgclients.DisabledStatus,
+		ID:         id,
+		UpdatedAt:  now,
+		Status:     mgclients.DisabledStatus,
+		DisabledAt: &now,
 	}
 	client, err := svc.changeClientStatus(ctx, session, client)
 	if err != nil {
@@ -406,6 +873,27 @@ func (svc service) DeleteClient(ctx context.Context, session authn.Session, id s
 }
 
 func (svc service) ListMembers(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mgclients.MembersPage, error) {
+	return svc.listMembers(ctx, session, objectKind, objectID, pm, 0)
+}
+
+func (svc service) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mgclients.MembersPage, error) {
+	// Every permission on the object implies view, so users having view are all users with access.
+	pm.Permission = policies.ViewPermission
+	pm.ListPerms = true
+	mp, err := svc.listMembers(ctx, session, objectKind, objectID, pm, svc.config.MaxObjectUsers)
+	if err != nil {
+		return mgclients.MembersPage{}, err
+	}
+	for i := range mp.Members {
+		sort.Strings(mp.Members[i].Permissions)
+	}
+
+	return mp, nil
+}
+
+// listMembers lists users having the page permission on the object. If maxSubjects
+// is positive, objects with more users than maxSubjects are rejected.
+func (svc service) listMembers(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page, maxSubjects int) (mgclients.MembersPage, error) {
 	var objectType string
 	switch objectKind {
 	case policies.ThingsKind:
@@ -432,6 +920,9 @@ func (svc service) ListMembers(ctx context.Context, session authn.Session, objec
 			Page: mgclients.Page{Total: 0, Offset: pm.Offset, Limit: pm.Limit},
 		}, nil
 	}
+	if maxSubjects > 0 && len(duids.Policies) > maxSubjects {
+		return mgclients.MembersPage{}, errors.Wrap(svcerr.ErrViewEntity, ErrTooManyObjectUsers)
+	}
 
 	var userIDs []string
 
@@ -501,6 +992,31 @@ func (svc service) listObjectUserPermission(ctx context.Context, userID, objectT
 	return permissions, nil
 }
 
+// validateTags removes duplicate tags and checks the remaining ones
+// against the configured number and length limits.
+func (svc service) validateTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	seen := make(map[string]struct{}, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		if len(tag) > svc.config.MaxTagLen {
+			return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrTagSize)
+		}
+		seen[tag] = struct{}{}
+		unique = append(unique, tag)
+	}
+	if len(unique) > svc.config.MaxTags {
+		return nil, errors.Wrap(apiutil.ErrValidation, apiutil.ErrMaxTags)
+	}
+
+	return unique, nil
+}
+
 func (svc *service) checkSuperAdmin(ctx context.Context, session authn.Session) error {
 	if !session.SuperAdmin {
 		if err := svc.clients.CheckSuperAdmin(ctx, session.UserID); err != nil {
@@ -516,6 +1032,10 @@ func (svc service) OAuthCallback(ctx context.Context, client mgclients.Client) (
 	if err != nil {
 		switch errors.Contains(err, repoerr.ErrNotFound) {
 		case true:
+			provider, _ := client.Metadata[oauthProviderKey].(string)
+			if slices.Contains(svc.config.JITDisabled, provider) {
+				return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+			}
 			rclient, err = svc.RegisterClient(ctx, authn.Session{}, client, true)
 			if err != nil {
 				return mgclients.Client{}, err
@@ -525,6 +1045,27 @@ func (svc service) OAuthCallback(ctx context.Context, client mgclients.Client) (
 		}
 	}
 
+	// The identity providers can't ask for the TOTP code, so the users with
+	// MFA enrolled log in with the secret and the code instead.
+	enrollment, err := svc.checkMFAEnrollment(ctx, rclient)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, err)
+	}
+	if enrollment.Enrolled() {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrAuthentication, errors.Wrap(svcerr.ErrMFACode, errMFAFederated))
+	}
+
+	// The provider is set by the OAuth2 and SAML providers, so the users
+	// can be listed by the provider they signed in with.
+	if provider, ok := client.Metadata[oauthProviderKey].(string); ok && provider != "" {
+		if err := svc.clients.SaveLinkedProvider(ctx, rclient.ID, provider); err != nil {
+			return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+		}
+	}
+	if err := svc.clients.UpdateLastLogin(ctx, rclient.ID, time.Now()); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+
 	return mgclients.Client{
 		ID:   rclient.ID,
 		Role: rclient.Role,
@@ -627,3 +1168,46 @@ func (svc service) updateClientPolicy(ctx context.Context, userID string, role m
 		return nil
 	}
 }
+
+func (svc service) updateClientsPolicy(ctx context.Context, userIDs []string, role mgclients.Role) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	var prs []policies.Policy
+	for _, id := range userIDs {
+		prs = append(prs, policies.Policy{
+			SubjectType: policies.UserType,
+			Subject:     id,
+			Relation:    policies.AdministratorRelation,
+			ObjectType:  policies.PlatformType,
+			Object:      policies.MagistralaObject,
+		})
+	}
+
+	if role == mgclients.AdminRole {
+		if err := svc.policies.AddPolicies(ctx, prs); err != nil {
+			return errors.Wrap(svcerr.ErrAddPolicies, err)
+		}
+		return nil
+	}
+	if err := svc.policies.DeletePolicies(ctx, prs); err != nil {
+		return errors.Wrap(svcerr.ErrDeletePolicies, err)
+	}
+
+	return nil
+}
+
+func generateIdentityToken() (string, error) {
+	b := make([]byte, identityTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashIdentityToken hashes the confirmation token so that only its hash is stored.
+func hashIdentityToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -7,12 +7,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/absmach/magistrala"
 	mgauth "github.com/absmach/magistrala/auth"
 	authmocks "github.com/absmach/magistrala/auth/mocks"
 	"github.com/absmach/magistrala/internal/testsutil"
+	"github.com/absmach/magistrala/pkg/apiutil"
 	"github.com/absmach/magistrala/pkg/authn"
 	mgclients "github.com/absmach/magistrala/pkg/clients"
 	"github.com/absmach/magistrala/pkg/errors"
@@ -26,6 +29,7 @@ import (
 	"github.com/absmach/magistrala/users/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -57,7 +61,7 @@ func newService() (users.Service, *authmocks.TokenServiceClient, *mocks.Reposito
 	policies := new(policymocks.Service)
 	e := new(mocks.Emailer)
 	tokenClient := new(authmocks.TokenServiceClient)
-	return users.NewService(tokenClient, cRepo, policies, e, phasher, idProvider), tokenClient, cRepo, policies, e
+	return users.NewService(tokenClient, cRepo, policies, e, phasher, idProvider, users.Config{}), tokenClient, cRepo, policies, e
 }
 
 func newServiceMinimal() (users.Service, *mocks.Repository) {
@@ -65,7 +69,16 @@ func newServiceMinimal() (users.Service, *mocks.Repository) {
 	policies := new(policymocks.Service)
 	e := new(mocks.Emailer)
 	tokenClient := new(authmocks.TokenServiceClient)
-	return users.NewService(tokenClient, cRepo, policies, e, phasher, idProvider), cRepo
+	return users.NewService(tokenClient, cRepo, policies, e, phasher, idProvider, users.Config{}), cRepo
+}
+
+func generateTags(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	return tags
 }
 
 func TestRegisterClient(t *testing.T) {
@@ -213,6 +226,69 @@ func TestRegisterClient(t *testing.T) {
 			saveErr:                   repoerr.ErrConflict,
 			err:                       svcerr.ErrConflict,
 		},
+		{
+			desc: "register a new client with maximum number of tags",
+			client: mgclients.Client{
+				Name: "clientWithMaxTags",
+				Tags: generateTags(users.DefMaxTags),
+				Credentials: mgclients.Credentials{
+					Identity: "clientwithmaxtags@example.com",
+					Secret:   secret,
+				},
+			},
+			err: nil,
+		},
+		{
+			desc: "register a new client with too many tags",
+			client: mgclients.Client{
+				Name: "clientWithTooManyTags",
+				Tags: generateTags(users.DefMaxTags + 1),
+				Credentials: mgclients.Credentials{
+					Identity: "clientwithtoomanytags@example.com",
+					Secret:   secret,
+				},
+			},
+			err: apiutil.ErrMaxTags,
+		},
+		{
+			desc: "register a new client with maximum tag length",
+			client: mgclients.Client{
+				Name: "clientWithMaxTagLength",
+				Tags: []string{strings.Repeat("a", users.DefMaxTagLen)},
+				Credentials: mgclients.Credentials{
+					Identity: "clientwithmaxtaglength@example.com",
+					Secret:   secret,
+				},
+			},
+			err: nil,
+		},
+		{
+			desc: "register a new client with too long tag",
+			client: mgclients.Client{
+				Name: "clientWithTooLongTag",
+				Tags: []string{strings.Repeat("a", users.DefMaxTagLen+1)},
+				Credentials: mgclients.Credentials{
+					Identity: "clientwithtoolongtag@example.com",
+					Secret:   secret,
+				},
+			},
+			err: apiutil.ErrTagSize,
+		},
+		{
+			desc: "register a new client with claims",
+			client: mgclients.Client{
+				Name: "clientWithClaims",
+				Credentials: mgclients.Credentials{
+					Identity: "newclientwithclaims@example.com",
+					Secret:   secret,
+				},
+				Metadata: mgclients.Metadata{
+					users.ClaimsKey: map[string]interface{}{"region": "eu"},
+				},
+				Status: mgclients.EnabledStatus,
+			},
+			err: svcerr.ErrAuthorization,
+		},
 	}
 
 	for _, tc := range cases {
@@ -261,6 +337,38 @@ func TestRegisterClient(t *testing.T) {
 			checkSuperAdminErr: svcerr.ErrAuthorization,
 			err:                svcerr.ErrAuthorization,
 		},
+		{
+			desc: "register new client with claims as admin",
+			client: mgclients.Client{
+				Name: "clientWithClaims",
+				Credentials: mgclients.Credentials{
+					Identity: "newclientwithclaims@example.com",
+					Secret:   secret,
+				},
+				Metadata: mgclients.Metadata{
+					users.ClaimsKey: map[string]interface{}{"region": "eu"},
+				},
+				Status: mgclients.EnabledStatus,
+			},
+			session: authn.Session{UserID: validID, SuperAdmin: true},
+			err:     nil,
+		},
+		{
+			desc: "register new client with malformed claims as admin",
+			client: mgclients.Client{
+				Name: "clientWithClaims",
+				Credentials: mgclients.Credentials{
+					Identity: "newclientwithclaims@example.com",
+					Secret:   secret,
+				},
+				Metadata: mgclients.Metadata{
+					users.ClaimsKey: map[string]interface{}{"region": 1},
+				},
+				Status: mgclients.EnabledStatus,
+			},
+			session: authn.Session{UserID: validID, SuperAdmin: true},
+			err:     svcerr.ErrMalformedEntity,
+		},
 	}
 	for _, tc := range cases2 {
 		repoCall := cRepo.On("CheckSuperAdmin", context.Background(), mock.Anything).Return(tc.checkSuperAdminErr)
@@ -286,9 +394,75 @@ func TestRegisterClient(t *testing.T) {
 	}
 }
 
+func TestRegisterClientWelcomeEmail(t *testing.T) {
+	cases := []struct {
+		desc         string
+		welcomeEmail bool
+		sendErr      error
+		saveErr      error
+		sent         bool
+		err          error
+	}{
+		{
+			desc:         "register new client with welcome email enabled",
+			welcomeEmail: true,
+			sent:         true,
+			err:          nil,
+		},
+		{
+			desc:         "register new client with welcome email enabled and failed enqueue",
+			welcomeEmail: true,
+			sendErr:      errors.New("queue is full"),
+			sent:         true,
+			err:          nil,
+		},
+		{
+			desc:         "register new client with welcome email disabled",
+			welcomeEmail: false,
+			sent:         false,
+			err:          nil,
+		},
+		{
+			desc:         "register new client with welcome email enabled and failed save",
+			welcomeEmail: true,
+			saveErr:      repoerr.ErrConflict,
+			sent:         false,
+			err:          repoerr.ErrConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		cRepo := new(mocks.Repository)
+		policies := new(policymocks.Service)
+		e := new(mocks.Emailer)
+		tokenClient := new(authmocks.TokenServiceClient)
+		svc := users.NewService(tokenClient, cRepo, policies, e, phasher, idProvider, users.Config{WelcomeEmail: tc.welcomeEmail})
+
+		policyCall := policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+		policyCall1 := policies.On("DeletePolicies", context.Background(), mock.Anything).Return(nil)
+		repoCall := cRepo.On("Save", context.Background(), mock.Anything).Return(client, tc.saveErr)
+		emailCall := e.On("SendWelcome", []string{client.Credentials.Identity}, client.Name).Return(tc.sendErr)
+		_, err := svc.RegisterClient(context.Background(), authn.Session{}, client, true)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		switch tc.sent {
+		case true:
+			e.AssertCalled(t, "SendWelcome", []string{client.Credentials.Identity}, client.Name)
+		default:
+			e.AssertNotCalled(t, "SendWelcome", mock.Anything, mock.Anything)
+		}
+		repoCall.Unset()
+		policyCall.Unset()
+		policyCall1.Unset()
+		emailCall.Unset()
+	}
+}
+
 func TestViewClient(t *testing.T) {
 	svc, cRepo := newServiceMinimal()
 
+	linkedClient := client
+	linkedClient.LinkedProviders = []string{"google", "saml"}
+
 	cases := []struct {
 		desc                 string
 		token                string
@@ -298,8 +472,10 @@ func TestViewClient(t *testing.T) {
 		response             mgclients.Client
 		identifyErr          error
 		authorizeErr         error
+		linkedProviders      []string
 		retrieveByIDErr      error
 		checkSuperAdminErr   error
+		retrieveLinkedErr    error
 		err                  error
 	}{
 		{
@@ -331,6 +507,26 @@ func TestViewClient(t *testing.T) {
 			clientID:             client.ID,
 			err:                  nil,
 		},
+		{
+			desc:                 "view client with linked providers",
+			retrieveByIDResponse: client,
+			response:             linkedClient,
+			linkedProviders:      []string{"google", "saml"},
+			token:                validToken,
+			reqClientID:          client.ID,
+			clientID:             client.ID,
+			err:                  nil,
+		},
+		{
+			desc:                 "view client with failed to retrieve linked providers",
+			retrieveByIDResponse: client,
+			response:             mgclients.Client{},
+			token:                validToken,
+			reqClientID:          client.ID,
+			clientID:             client.ID,
+			retrieveLinkedErr:    repoerr.ErrViewEntity,
+			err:                  svcerr.ErrViewEntity,
+		},
 		{
 			desc:                 "view client as admin user with failed check on super admin",
 			token:                validToken,
@@ -346,6 +542,7 @@ func TestViewClient(t *testing.T) {
 	for _, tc := range cases {
 		repoCall := cRepo.On("CheckSuperAdmin", context.Background(), mock.Anything).Return(tc.checkSuperAdminErr)
 		repoCall1 := cRepo.On("RetrieveByID", context.Background(), tc.clientID).Return(tc.retrieveByIDResponse, tc.retrieveByIDErr)
+		repoCall2 := cRepo.On("RetrieveLinkedProviders", context.Background(), tc.clientID).Return(tc.linkedProviders, tc.retrieveLinkedErr)
 		rClient, err := svc.ViewClient(context.Background(), authn.Session{UserID: tc.reqClientID}, tc.clientID)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 		tc.response.Credentials.Secret = ""
@@ -354,6 +551,7 @@ func TestViewClient(t *testing.T) {
 			ok := repoCall1.Parent.AssertCalled(t, "RetrieveByID", context.Background(), tc.clientID)
 			assert.True(t, ok, fmt.Sprintf("RetrieveByID was not called on %s", tc.desc))
 		}
+		repoCall2.Unset()
 		repoCall1.Unset()
 		repoCall.Unset()
 	}
@@ -412,6 +610,35 @@ func TestListClients(t *testing.T) {
 			superAdminErr: svcerr.ErrAuthorization,
 			err:           svcerr.ErrAuthorization,
 		},
+		{
+			desc: "list clients by oauth provider as admin successfully",
+			page: mgclients.Page{
+				OAuthProvider: "google",
+			},
+			retrieveAllResponse: mgclients.ClientsPage{
+				Page: mgclients.Page{
+					Total: 1,
+				},
+				Clients: []mgclients.Client{client},
+			},
+			response: mgclients.ClientsPage{
+				Page: mgclients.Page{
+					Total: 1,
+				},
+				Clients: []mgclients.Client{client},
+			},
+			token: validToken,
+			err:   nil,
+		},
+		{
+			desc: "list clients by oauth provider as normal user",
+			page: mgclients.Page{
+				OAuthProvider: "google",
+			},
+			token:         validToken,
+			superAdminErr: svcerr.ErrAuthorization,
+			err:           svcerr.ErrAuthorization,
+		},
 		{
 			desc: "list clients as normal user with failed to retrieve clients",
 			page: mgclients.Page{
@@ -431,7 +658,9 @@ func TestListClients(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 		assert.Equal(t, tc.response, page, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.response, page))
 		if tc.err == nil {
-			ok := repoCall1.Parent.AssertCalled(t, "RetrieveAll", context.Background(), mock.Anything)
+			ok := repoCall1.Parent.AssertCalled(t, "RetrieveAll", context.Background(), mock.MatchedBy(func(pm mgclients.Page) bool {
+				return pm.OAuthProvider == tc.page.OAuthProvider
+			}))
 			assert.True(t, ok, fmt.Sprintf("RetrieveAll was not called on %s", tc.desc))
 		}
 		repoCall.Unset()
@@ -497,6 +726,95 @@ func TestSearchUsers(t *testing.T) {
 	}
 }
 
+func TestListChanges(t *testing.T) {
+	svc, cRepo := newServiceMinimal()
+
+	adminID := testsutil.GenerateUUID(t)
+	since := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+	updated := client
+	updated.UpdatedAt = since.Add(time.Minute)
+	changes := []users.Change{
+		{ID: updated.ID, Operation: users.ChangeUpdated, ChangedAt: updated.UpdatedAt, Client: &updated},
+		{ID: testsutil.GenerateUUID(t), Operation: users.ChangeDeleted, ChangedAt: since.Add(2 * time.Minute)},
+	}
+
+	cases := []struct {
+		desc               string
+		session            authn.Session
+		since              time.Time
+		cursor             string
+		query              users.ChangesQuery
+		response           []users.Change
+		responseErr        error
+		checkSuperAdminErr error
+		err                error
+	}{
+		{
+			desc:     "list changes since time as admin",
+			session:  authn.Session{UserID: adminID, SuperAdmin: true},
+			since:    since,
+			query:    users.ChangesQuery{Since: since, Limit: 10},
+			response: changes,
+		},
+		{
+			desc:     "list changes from the start as admin",
+			session:  authn.Session{UserID: adminID, SuperAdmin: true},
+			query:    users.ChangesQuery{Limit: 10},
+			response: changes,
+		},
+		{
+			desc:     "list changes without new changes",
+			session:  authn.Session{UserID: adminID, SuperAdmin: true},
+			since:    since,
+			query:    users.ChangesQuery{Since: since, Limit: 10},
+			response: []users.Change{},
+		},
+		{
+			desc:               "list changes as normal user",
+			session:            authn.Session{UserID: client.ID},
+			since:              since,
+			checkSuperAdminErr: repoerr.ErrNotFound,
+			err:                svcerr.ErrAuthorization,
+		},
+		{
+			desc:    "list changes with invalid cursor",
+			session: authn.Session{UserID: adminID, SuperAdmin: true},
+			cursor:  "invalid",
+			err:     svcerr.ErrMalformedEntity,
+		},
+		{
+			desc:        "list changes with repo error",
+			session:     authn.Session{UserID: adminID, SuperAdmin: true},
+			since:       since,
+			query:       users.ChangesQuery{Since: since, Limit: 10},
+			responseErr: repoerr.ErrViewEntity,
+			err:         svcerr.ErrViewEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		repoCall := cRepo.On("CheckSuperAdmin", context.Background(), tc.session.UserID).Return(tc.checkSuperAdminErr)
+		repoCall1 := cRepo.On("RetrieveChanges", context.Background(), tc.query).Return(tc.response, tc.responseErr)
+		page, err := svc.ListChanges(context.Background(), tc.session, tc.since, tc.cursor, 10)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.response, page.Changes, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.response, page.Changes))
+			// The cursor resumes the feed right after the listed changes.
+			next := tc.query
+			if len(tc.response) > 0 {
+				last := tc.response[len(tc.response)-1]
+				next.Since, next.AfterID = last.ChangedAt, last.ID
+			}
+			cRepo.On("RetrieveChanges", context.Background(), next).Return([]users.Change{}, nil).Once()
+			_, err = svc.ListChanges(context.Background(), tc.session, time.Time{}, page.Cursor, 10)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error resuming the feed %s\n", tc.desc, err))
+			cRepo.AssertCalled(t, "RetrieveChanges", context.Background(), next)
+		}
+		repoCall.Unset()
+		repoCall1.Unset()
+	}
+}
+
 func TestUpdateClient(t *testing.T) {
 	svc, cRepo := newServiceMinimal()
 
@@ -504,6 +822,10 @@ func TestUpdateClient(t *testing.T) {
 	client2 := client
 	client1.Name = "Updated client"
 	client2.Metadata = mgclients.Metadata{"role": "test"}
+	client3 := client
+	client3.Metadata = mgclients.Metadata{users.ClaimsKey: map[string]interface{}{"region": "eu"}}
+	client4 := client
+	client4.Metadata = mgclients.Metadata{users.ClaimsKey: map[string]interface{}{"region": "us"}}
 	adminID := testsutil.GenerateUUID(t)
 
 	cases := []struct {
@@ -511,6 +833,7 @@ func TestUpdateClient(t *testing.T) {
 		client             mgclients.Client
 		session            authn.Session
 		updateResponse     mgclients.Client
+		retrieveResponse   mgclients.Client
 		token              string
 		updateErr          error
 		checkSuperAdminErr error
@@ -574,11 +897,46 @@ func TestUpdateClient(t *testing.T) {
 			updateErr:      errors.ErrMalformedEntity,
 			err:            svcerr.ErrUpdateEntity,
 		},
+		{
+			desc:             "update metadata keeping claims as normal user",
+			client:           client3,
+			session:          authn.Session{UserID: client3.ID},
+			retrieveResponse: client3,
+			updateResponse:   client3,
+			token:            validToken,
+			err:              nil,
+		},
+		{
+			desc:               "update claims as normal user",
+			client:             client4,
+			session:            authn.Session{UserID: client4.ID},
+			retrieveResponse:   client3,
+			token:              validToken,
+			checkSuperAdminErr: svcerr.ErrAuthorization,
+			err:                svcerr.ErrAuthorization,
+		},
+		{
+			desc:             "update own claims as admin successfully",
+			client:           client4,
+			session:          authn.Session{UserID: client4.ID, SuperAdmin: true},
+			retrieveResponse: client3,
+			updateResponse:   client4,
+			token:            validToken,
+			err:              nil,
+		},
+		{
+			desc:    "update client with malformed claims",
+			client:  mgclients.Client{ID: client.ID, Metadata: mgclients.Metadata{users.ClaimsKey: "eu"}},
+			session: authn.Session{UserID: adminID, SuperAdmin: true},
+			token:   validToken,
+			err:     svcerr.ErrMalformedEntity,
+		},
 	}
 
 	for _, tc := range cases {
 		repoCall := cRepo.On("CheckSuperAdmin", context.Background(), mock.Anything).Return(tc.checkSuperAdminErr)
 		repoCall1 := cRepo.On("Update", context.Background(), mock.Anything).Return(tc.updateResponse, tc.err)
+		repoCall2 := cRepo.On("RetrieveByID", context.Background(), tc.client.ID).Return(tc.retrieveResponse, nil)
 		updatedClient, err := svc.UpdateClient(context.Background(), tc.session, tc.client)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 		assert.Equal(t, tc.updateResponse, updatedClient, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.updateResponse, updatedClient))
@@ -588,6 +946,7 @@ func TestUpdateClient(t *testing.T) {
 		}
 		repoCall.Unset()
 		repoCall1.Unset()
+		repoCall2.Unset()
 	}
 }
 
@@ -659,6 +1018,115 @@ func TestUpdateClientTags(t *testing.T) {
 	}
 }
 
+func TestUpdateClientTagsLimits(t *testing.T) {
+
+	cases := []struct {
+		desc         string
+		tags         []string
+		expectedTags []string
+		err          error
+	}{
+		{
+			desc:         "update client tags with maximum number of tags",
+			tags:         generateTags(users.DefMaxTags),
+			expectedTags: generateTags(users.DefMaxTags),
+			err:          nil,
+		},
+		{
+			desc: "update client tags with too many tags",
+			tags: generateTags(users.DefMaxTags + 1),
+			err:  apiutil.ErrMaxTags,
+		},
+		{
+			desc: "update client tags with too long tag",
+			tags: []string{strings.Repeat("a", users.DefMaxTagLen+1)},
+			err:  apiutil.ErrTagSize,
+		},
+		{
+			desc:         "update client tags with duplicate tags",
+			tags:         []string{"tag1", "tag2", "tag1", "tag2", "tag3"},
+			expectedTags: []string{"tag1", "tag2", "tag3"},
+			err:          nil,
+		},
+		{
+			desc:         "update client tags with duplicates over the limit",
+			tags:         append(generateTags(users.DefMaxTags), generateTags(users.DefMaxTags)...),
+			expectedTags: generateTags(users.DefMaxTags),
+			err:          nil,
+		},
+	}
+
+	for _, tc := range cases {
+		svc, _, cRepo, _, _ := newService()
+		cli := mgclients.Client{ID: client.ID, Tags: tc.tags}
+		repoCall := cRepo.On("UpdateTags", context.Background(), mock.MatchedBy(func(c mgclients.Client) bool {
+			return assert.ObjectsAreEqual(tc.expectedTags, c.Tags)
+		})).Return(mgclients.Client{ID: client.ID, Tags: tc.expectedTags}, nil)
+		updatedClient, err := svc.UpdateClientTags(context.Background(), authn.Session{UserID: client.ID}, cli)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.err == nil {
+			assert.Equal(t, tc.expectedTags, updatedClient.Tags, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.expectedTags, updatedClient.Tags))
+			ok := repoCall.Parent.AssertCalled(t, "UpdateTags", context.Background(), mock.Anything)
+			assert.True(t, ok, fmt.Sprintf("UpdateTags was not called on %s", tc.desc))
+		}
+
+		repoCall = cRepo.On("Update", context.Background(), mock.MatchedBy(func(c mgclients.Client) bool {
+			return assert.ObjectsAreEqual(tc.expectedTags, c.Tags)
+		})).Return(mgclients.Client{ID: client.ID, Tags: tc.expectedTags}, nil)
+		updatedClient, err = svc.UpdateClient(context.Background(), authn.Session{UserID: client.ID}, cli)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("update %s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.err == nil {
+			assert.Equal(t, tc.expectedTags, updatedClient.Tags, fmt.Sprintf("update %s: expected %v got %v\n", tc.desc, tc.expectedTags, updatedClient.Tags))
+			ok := repoCall.Parent.AssertCalled(t, "Update", context.Background(), mock.Anything)
+			assert.True(t, ok, fmt.Sprintf("Update was not called on %s", tc.desc))
+		}
+	}
+}
+
+func TestMetadataSizeLimit(t *testing.T) {
+	cases := []struct {
+		desc     string
+		metadata mgclients.Metadata
+		err      error
+	}{
+		{
+			desc:     "metadata at size limit",
+			metadata: metadataOfSize(mgclients.DefMaxMetadataSize),
+			err:      nil,
+		},
+		{
+			desc:     "metadata over size limit",
+			metadata: metadataOfSize(mgclients.DefMaxMetadataSize + 1),
+			err:      apiutil.ErrMetadataSize,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, _, cRepo, policies, _ := newService()
+			cli := mgclients.Client{ID: client.ID, Credentials: client.Credentials, Metadata: tc.metadata, Status: mgclients.EnabledStatus}
+			policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+			cRepo.On("Save", context.Background(), mock.Anything).Return(cli, nil)
+			cRepo.On("Update", context.Background(), mock.Anything).Return(cli, nil)
+
+			_, err := svc.RegisterClient(context.Background(), authn.Session{}, cli, true)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("register %s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.True(t, errors.Contains(err, apiutil.ErrValidation) == (tc.err != nil), fmt.Sprintf("register %s: expected validation error got %s\n", tc.desc, err))
+			_, err = svc.UpdateClient(context.Background(), authn.Session{UserID: client.ID}, cli)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("update %s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err != nil {
+				cRepo.AssertNotCalled(t, "Save", context.Background(), mock.Anything)
+				cRepo.AssertNotCalled(t, "Update", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
+// metadataOfSize returns metadata whose JSON encoding is size bytes long.
+func metadataOfSize(size int) mgclients.Metadata {
+	return mgclients.Metadata{"data": strings.Repeat("a", size-len(`{"data":""}`))}
+}
+
 func TestUpdateClientRole(t *testing.T) {
 	svc, _, cRepo, policies, _ := newService()
 
@@ -756,6 +1224,182 @@ func TestUpdateClientRole(t *testing.T) {
 	}
 }
 
+func TestUpdateClientsRole(t *testing.T) {
+	userClient := client
+	userClient.ID = testsutil.GenerateUUID(t)
+	userClient.Role = mgclients.UserRole
+	adminClient := client
+	adminClient.ID = testsutil.GenerateUUID(t)
+	adminClient.Role = mgclients.AdminRole
+	disabledClient := client
+	disabledClient.ID = testsutil.GenerateUUID(t)
+	disabledClient.Status = mgclients.DisabledStatus
+	stored := map[string]mgclients.Client{
+		userClient.ID:     userClient,
+		adminClient.ID:    adminClient,
+		disabledClient.ID: disabledClient,
+	}
+	promoted := userClient
+	promoted.Role = mgclients.AdminRole
+	demoted := adminClient
+	demoted.Role = mgclients.UserRole
+
+	cases := []struct {
+		desc               string
+		session            authn.Session
+		ids                []string
+		role               mgclients.Role
+		checkSuperAdminErr error
+		retrieveByIDErr    error
+		updateRolesRes     []mgclients.Client
+		updateRolesErr     error
+		addPoliciesErr     error
+		deletePoliciesErr  error
+		addedPolicies      int
+		deletedPolicies    int
+		results            []users.RoleUpdate
+		err                error
+	}{
+		{
+			desc:           "promote clients with partial results",
+			session:        authn.Session{UserID: validID, SuperAdmin: true},
+			ids:            []string{userClient.ID, adminClient.ID, wrongID, disabledClient.ID},
+			role:           mgclients.AdminRole,
+			updateRolesRes: []mgclients.Client{promoted, adminClient},
+			addedPolicies:  1,
+			results: []users.RoleUpdate{
+				{ID: userClient.ID, Client: promoted},
+				{ID: adminClient.ID, Client: adminClient},
+				{ID: wrongID, Err: svcerr.ErrNotFound},
+				{ID: disabledClient.ID, Err: svcerr.ErrNotFound},
+			},
+			err: nil,
+		},
+		{
+			desc:            "demote clients successfully",
+			session:         authn.Session{UserID: validID, SuperAdmin: true},
+			ids:             []string{adminClient.ID, userClient.ID},
+			role:            mgclients.UserRole,
+			updateRolesRes:  []mgclients.Client{demoted, userClient},
+			deletedPolicies: 1,
+			results: []users.RoleUpdate{
+				{ID: adminClient.ID, Client: demoted},
+				{ID: userClient.ID, Client: userClient},
+			},
+			err: nil,
+		},
+		{
+			desc:           "promote duplicate clients",
+			session:        authn.Session{UserID: validID, SuperAdmin: true},
+			ids:            []string{userClient.ID, userClient.ID},
+			role:           mgclients.AdminRole,
+			updateRolesRes: []mgclients.Client{promoted},
+			addedPolicies:  1,
+			results: []users.RoleUpdate{
+				{ID: userClient.ID, Client: promoted},
+				{ID: userClient.ID, Client: promoted},
+			},
+			err: nil,
+		},
+		{
+			desc:    "promote only non-existing clients",
+			session: authn.Session{UserID: validID, SuperAdmin: true},
+			ids:     []string{wrongID},
+			role:    mgclients.AdminRole,
+			results: []users.RoleUpdate{
+				{ID: wrongID, Err: svcerr.ErrNotFound},
+			},
+			err: nil,
+		},
+		{
+			desc:               "update clients role with failed check on super admin",
+			session:            authn.Session{UserID: validID},
+			ids:                []string{userClient.ID},
+			role:               mgclients.AdminRole,
+			checkSuperAdminErr: repoerr.ErrNotFound,
+			err:                svcerr.ErrAuthorization,
+		},
+		{
+			desc:    "update clients role with invalid role",
+			session: authn.Session{UserID: validID, SuperAdmin: true},
+			ids:     []string{userClient.ID},
+			role:    mgclients.AllRole,
+			err:     svcerr.ErrInvalidRole,
+		},
+		{
+			desc:            "update clients role with failed to retrieve client",
+			session:         authn.Session{UserID: validID, SuperAdmin: true},
+			ids:             []string{userClient.ID},
+			role:            mgclients.AdminRole,
+			retrieveByIDErr: repoerr.ErrViewEntity,
+			err:             svcerr.ErrViewEntity,
+		},
+		{
+			desc:           "update clients role with failed to add policies",
+			session:        authn.Session{UserID: validID, SuperAdmin: true},
+			ids:            []string{userClient.ID},
+			role:           mgclients.AdminRole,
+			addPoliciesErr: svcerr.ErrMalformedEntity,
+			addedPolicies:  1,
+			err:            svcerr.ErrAddPolicies,
+		},
+		{
+			desc:            "update clients role with failed repo update and roll back",
+			session:         authn.Session{UserID: validID, SuperAdmin: true},
+			ids:             []string{userClient.ID, adminClient.ID},
+			role:            mgclients.AdminRole,
+			updateRolesErr:  repoerr.ErrNotFound,
+			addedPolicies:   1,
+			deletedPolicies: 1,
+			err:             svcerr.ErrUpdateEntity,
+		},
+		{
+			desc:              "update clients role with failed repo update and failed roll back",
+			session:           authn.Session{UserID: validID, SuperAdmin: true},
+			ids:               []string{userClient.ID},
+			role:              mgclients.AdminRole,
+			updateRolesErr:    repoerr.ErrNotFound,
+			deletePoliciesErr: svcerr.ErrMalformedEntity,
+			addedPolicies:     1,
+			deletedPolicies:   1,
+			err:               svcerr.ErrDeletePolicies,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, _, cRepo, policies, _ := newService()
+			cRepo.On("CheckSuperAdmin", context.Background(), validID).Return(tc.checkSuperAdminErr)
+			cRepo.On("RetrieveByID", context.Background(), mock.Anything).Return(func(_ context.Context, id string) (mgclients.Client, error) {
+				if tc.retrieveByIDErr != nil {
+					return mgclients.Client{}, tc.retrieveByIDErr
+				}
+				c, ok := stored[id]
+				if !ok {
+					return mgclients.Client{}, repoerr.ErrNotFound
+				}
+				return c, nil
+			})
+			cRepo.On("UpdateRoles", context.Background(), mock.Anything).Return(tc.updateRolesRes, tc.updateRolesErr)
+			addCall := policies.On("AddPolicies", context.Background(), mock.Anything).Return(tc.addPoliciesErr)
+			deleteCall := policies.On("DeletePolicies", context.Background(), mock.Anything).Return(tc.deletePoliciesErr)
+
+			results, err := svc.UpdateClientsRole(context.Background(), tc.session, tc.ids, tc.role)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.results, results, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.results, results))
+			if tc.addedPolicies > 0 {
+				prs := addCall.Parent.Calls[0].Arguments.Get(1).([]policysvc.Policy)
+				assert.Len(t, prs, tc.addedPolicies, fmt.Sprintf("%s: expected %d added policies got %d\n", tc.desc, tc.addedPolicies, len(prs)))
+			} else {
+				addCall.Parent.AssertNotCalled(t, "AddPolicies", context.Background(), mock.Anything)
+			}
+			if tc.deletedPolicies == 0 {
+				deleteCall.Parent.AssertNotCalled(t, "DeletePolicies", context.Background(), mock.Anything)
+			}
+		})
+	}
+}
+
 func TestUpdateClientSecret(t *testing.T) {
 	svc, authClient, cRepo, _, _ := newService()
 
@@ -847,6 +1491,7 @@ func TestUpdateClientSecret(t *testing.T) {
 		repoCall := cRepo.On("RetrieveByID", context.Background(), client.ID).Return(tc.retrieveByIDResponse, tc.retrieveByIDErr)
 		repoCall1 := cRepo.On("RetrieveByIdentity", context.Background(), client.Credentials.Identity).Return(tc.retrieveByIdentityResponse, tc.retrieveByIdentityErr)
 		repoCall2 := cRepo.On("UpdateSecret", context.Background(), mock.Anything).Return(tc.updateSecretResponse, tc.updateSecretErr)
+		cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 		authCall := authClient.On("Issue", context.Background(), mock.Anything).Return(tc.issueResponse, tc.issueErr)
 		updatedClient, err := svc.UpdateClientSecret(context.Background(), tc.session, tc.oldSecret, tc.newSecret)
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
@@ -866,6 +1511,123 @@ func TestUpdateClientSecret(t *testing.T) {
 	}
 }
 
+func TestConcurrentSecretUpdates(t *testing.T) {
+	cases := []struct {
+		desc      string
+		lock      bool
+		login     bool
+		update    func(svc users.Service, session authn.Session, secret string) error
+		conflicts int
+	}{
+		{
+			desc:  "concurrently update secret with lock",
+			lock:  true,
+			login: true,
+			update: func(svc users.Service, session authn.Session, secret string) error {
+				_, err := svc.UpdateClientSecret(context.Background(), session, client.Credentials.Secret, secret)
+				return err
+			},
+			conflicts: 1,
+		},
+		{
+			desc: "concurrently reset secret with lock",
+			lock: true,
+			update: func(svc users.Service, session authn.Session, secret string) error {
+				return svc.ResetSecret(context.Background(), session, secret)
+			},
+			conflicts: 1,
+		},
+		{
+			desc:  "concurrently update secret without lock",
+			lock:  false,
+			login: true,
+			update: func(svc users.Service, session authn.Session, secret string) error {
+				_, err := svc.UpdateClientSecret(context.Background(), session, client.Credentials.Secret, secret)
+				return err
+			},
+			conflicts: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			authClient := new(authmocks.TokenServiceClient)
+			svc := users.NewService(authClient, cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, users.Config{SecretUpdateLock: tc.lock})
+
+			hash, err := phasher.Hash(client.Credentials.Secret)
+			require.Nil(t, err, fmt.Sprintf("unexpected error hashing secret: %s", err))
+
+			// The stored secret is shared by both requests, which both
+			// read it before either of them writes it back.
+			var mu sync.Mutex
+			storedSecret := hash
+			stored := func() mgclients.Client {
+				mu.Lock()
+				defer mu.Unlock()
+				c := client
+				c.Credentials.Secret = storedSecret
+				return c
+			}
+			var reads, logins sync.WaitGroup
+			reads.Add(2)
+			if tc.login {
+				logins.Add(2)
+			}
+			cRepo.On("RetrieveByID", context.Background(), client.ID).Return(func(context.Context, string) (mgclients.Client, error) {
+				c := stored()
+				reads.Done()
+				reads.Wait()
+				return c, nil
+			})
+			cRepo.On("RetrieveByIdentity", context.Background(), client.Credentials.Identity).Return(func(context.Context, string) (mgclients.Client, error) {
+				c := stored()
+				logins.Done()
+				logins.Wait()
+				return c, nil
+			})
+			cRepo.On("UpdateSecret", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				storedSecret = c.Credentials.Secret
+				return c, nil
+			})
+			cRepo.On("UpdateSecretIfUnchanged", context.Background(), mock.Anything, mock.Anything).Return(func(_ context.Context, c mgclients.Client, current string) (mgclients.Client, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if current != storedSecret {
+					return mgclients.Client{}, repoerr.ErrConcurrentUpdate
+				}
+				storedSecret = c.Credentials.Secret
+				return c, nil
+			})
+			cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			authClient.On("Issue", context.Background(), mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, nil)
+
+			session := authn.Session{UserID: client.ID}
+			errs := make(chan error, 2)
+			for _, secret := range []string{"firstNewSecret", "secondNewSecret"} {
+				go func(secret string) {
+					errs <- tc.update(svc, session, secret)
+				}(secret)
+			}
+
+			conflicts := 0
+			for i := 0; i < 2; i++ {
+				err := <-errs
+				switch {
+				case err == nil:
+				case errors.Contains(err, svcerr.ErrConcurrentUpdate):
+					conflicts++
+				default:
+					t.Errorf("%s: unexpected error %s", tc.desc, err)
+				}
+			}
+			assert.Equal(t, tc.conflicts, conflicts, fmt.Sprintf("%s: expected %d conflicts got %d", tc.desc, tc.conflicts, conflicts))
+		})
+	}
+}
+
 func TestUpdateClientIdentity(t *testing.T) {
 	svc, cRepo := newServiceMinimal()
 
@@ -947,6 +1709,107 @@ func TestUpdateClientIdentity(t *testing.T) {
 	}
 }
 
+func TestUpdateClientIdentityConfirmation(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	e := new(mocks.Emailer)
+	tokenClient := new(authmocks.TokenServiceClient)
+	svc := users.NewService(tokenClient, cRepo, new(policymocks.Service), e, phasher, idProvider, users.Config{ConfirmIdentity: true})
+
+	oldIdentity := client.Credentials.Identity
+	newIdentity := "updated@example.com"
+	stored := client
+	stored.Credentials.Secret, _ = phasher.Hash(secret)
+	var pending users.PendingIdentity
+	var token string
+
+	cRepo.On("RetrieveByIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, identity string) (mgclients.Client, error) {
+		if identity != stored.Credentials.Identity {
+			return mgclients.Client{}, repoerr.ErrNotFound
+		}
+		return stored, nil
+	})
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(func(_ context.Context, _ string) (mgclients.Client, error) {
+		return stored, nil
+	})
+	cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, pi users.PendingIdentity) error {
+		pending = pi
+		return nil
+	})
+	cRepo.On("RetrievePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, hash string) (users.PendingIdentity, error) {
+		if pending.Token == "" || hash != pending.Token {
+			return users.PendingIdentity{}, repoerr.ErrNotFound
+		}
+		return pending, nil
+	})
+	cRepo.On("RemovePendingIdentity", context.Background(), client.ID).Return(func(_ context.Context, _ string) error {
+		pending = users.PendingIdentity{}
+		return nil
+	})
+	cRepo.On("UpdateIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+		stored.Credentials.Identity = c.Credentials.Identity
+		return stored, nil
+	})
+	e.On("SendIdentityConfirmation", []string{newIdentity}, client.Name, mock.Anything).Return(func(_ []string, _, t string) error {
+		token = t
+		return nil
+	})
+	e.On("SendIdentityChanged", []string{oldIdentity}, client.Name, newIdentity).Return(nil)
+	cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cRepo.On("RetrieveMFA", mock.Anything, mock.Anything).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
+	tokenClient.On("Issue", mock.Anything, mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, nil)
+
+	session := authn.Session{UserID: client.ID}
+	cli, err := svc.UpdateClientIdentity(context.Background(), session, client.ID, newIdentity)
+	assert.Nil(t, err, fmt.Sprintf("update client identity: unexpected error %s", err))
+	assert.Equal(t, oldIdentity, cli.Credentials.Identity, "update client identity: expected identity to remain unchanged until confirmed")
+	assert.NotEmpty(t, token, "update client identity: expected confirmation e-mail to be sent to the new address")
+	assert.NotEqual(t, token, pending.Token, "update client identity: expected only token hash to be stored")
+	cRepo.AssertNotCalled(t, "UpdateIdentity", context.Background(), mock.Anything)
+
+	_, err = svc.UpdateClientIdentity(context.Background(), session, client.ID, oldIdentity)
+	assert.True(t, errors.Contains(err, svcerr.ErrConflict), fmt.Sprintf("update client identity to existing identity: expected %s got %s", svcerr.ErrConflict, err))
+
+	_, err = svc.IssueToken(context.Background(), newIdentity, secret, "", "")
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("login with unconfirmed identity: expected %s got %s", svcerr.ErrAuthentication, err))
+	_, err = svc.IssueToken(context.Background(), oldIdentity, secret, "", "")
+	assert.Nil(t, err, fmt.Sprintf("login with current identity: unexpected error %s", err))
+
+	_, err = svc.ConfirmIdentity(context.Background(), "invalid")
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("confirm identity with invalid token: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	cli, err = svc.ConfirmIdentity(context.Background(), token)
+	assert.Nil(t, err, fmt.Sprintf("confirm identity: unexpected error %s", err))
+	assert.Equal(t, newIdentity, cli.Credentials.Identity, fmt.Sprintf("confirm identity: expected %s got %s", newIdentity, cli.Credentials.Identity))
+	e.AssertCalled(t, "SendIdentityChanged", []string{oldIdentity}, client.Name, newIdentity)
+
+	_, err = svc.IssueToken(context.Background(), newIdentity, secret, "", "")
+	assert.Nil(t, err, fmt.Sprintf("login with confirmed identity: unexpected error %s", err))
+	_, err = svc.IssueToken(context.Background(), oldIdentity, secret, "", "")
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("login with previous identity: expected %s got %s", svcerr.ErrAuthentication, err))
+
+	_, err = svc.ConfirmIdentity(context.Background(), token)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("confirm identity with used token: expected %s got %s", svcerr.ErrAuthentication, err))
+}
+
+func TestConfirmIdentityExpired(t *testing.T) {
+	svc, cRepo := newServiceMinimal()
+
+	pending := users.PendingIdentity{
+		ClientID:  client.ID,
+		Identity:  "updated@example.com",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	repoCall := cRepo.On("RetrievePendingIdentity", context.Background(), mock.Anything).Return(pending, nil)
+	repoCall1 := cRepo.On("RemovePendingIdentity", context.Background(), client.ID).Return(nil)
+	_, err := svc.ConfirmIdentity(context.Background(), validToken)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("confirm identity with expired token: expected %s got %s", svcerr.ErrAuthentication, err))
+	ok := repoCall1.Parent.AssertCalled(t, "RemovePendingIdentity", context.Background(), client.ID)
+	assert.True(t, ok, "RemovePendingIdentity was not called on expired token")
+	cRepo.AssertNotCalled(t, "UpdateIdentity", context.Background(), mock.Anything)
+	repoCall.Unset()
+	repoCall1.Unset()
+}
+
 func TestEnableClient(t *testing.T) {
 	svc, cRepo := newServiceMinimal()
 
@@ -1477,6 +2340,125 @@ func TestListMembers(t *testing.T) {
 	}
 }
 
+func TestListObjectPermissions(t *testing.T) {
+	domainID := testsutil.GenerateUUID(t)
+	session := authn.Session{DomainID: domainID, UserID: validID, DomainUserID: mgauth.EncodeDomainUserID(domainID, validID)}
+	// User granted directly on the channel.
+	directUser := mgclients.Client{ID: testsutil.GenerateUUID(t), Name: "direct"}
+	// User with access through the parent group membership.
+	groupUser := mgclients.Client{ID: testsutil.GenerateUUID(t), Name: "group"}
+	permissions := map[string]policysvc.Permissions{
+		mgauth.EncodeDomainUserID(domainID, directUser.ID): {"view", "edit", "admin"},
+		mgauth.EncodeDomainUserID(domainID, groupUser.ID):  {"view"},
+	}
+	subjects := policysvc.PolicyPage{Policies: []string{
+		mgauth.EncodeDomainUserID(domainID, directUser.ID),
+		mgauth.EncodeDomainUserID(domainID, groupUser.ID),
+	}}
+
+	cases := []struct {
+		desc                    string
+		objectKind              string
+		objectType              string
+		maxObjectUsers          int
+		listAllSubjectsResponse policysvc.PolicyPage
+		listAllSubjectsErr      error
+		retrieveAllResponse     mgclients.ClientsPage
+		listPermissionErr       error
+		response                mgclients.MembersPage
+		err                     error
+	}{
+		{
+			desc:                    "list permissions of directly and group granted users on channel",
+			objectKind:              policysvc.GroupsKind,
+			objectType:              policysvc.GroupType,
+			listAllSubjectsResponse: subjects,
+			retrieveAllResponse: mgclients.ClientsPage{
+				Page:    mgclients.Page{Total: 2, Limit: 10},
+				Clients: []mgclients.Client{directUser, groupUser},
+			},
+			response: mgclients.MembersPage{
+				Page: mgclients.Page{Total: 2, Limit: 10},
+				Members: []mgclients.Client{
+					{ID: directUser.ID, Name: directUser.Name, Permissions: []string{"admin", "edit", "view"}},
+					{ID: groupUser.ID, Name: groupUser.Name, Permissions: []string{"view"}},
+				},
+			},
+		},
+		{
+			desc:                    "list permissions on thing",
+			objectKind:              policysvc.ThingsKind,
+			objectType:              policysvc.ThingType,
+			listAllSubjectsResponse: policysvc.PolicyPage{Policies: subjects.Policies[1:]},
+			retrieveAllResponse: mgclients.ClientsPage{
+				Page:    mgclients.Page{Total: 1, Limit: 10},
+				Clients: []mgclients.Client{groupUser},
+			},
+			response: mgclients.MembersPage{
+				Page:    mgclients.Page{Total: 1, Limit: 10},
+				Members: []mgclients.Client{{ID: groupUser.ID, Name: groupUser.Name, Permissions: []string{"view"}}},
+			},
+		},
+		{
+			desc:       "list permissions on object without users",
+			objectKind: policysvc.GroupsKind,
+			objectType: policysvc.GroupType,
+			response:   mgclients.MembersPage{Page: mgclients.Page{Limit: 10}},
+		},
+		{
+			desc:                    "list permissions on object with too many users",
+			objectKind:              policysvc.GroupsKind,
+			objectType:              policysvc.GroupType,
+			maxObjectUsers:          1,
+			listAllSubjectsResponse: subjects,
+			err:                     users.ErrTooManyObjectUsers,
+		},
+		{
+			desc:               "list permissions with failed to list subjects",
+			objectKind:         policysvc.GroupsKind,
+			objectType:         policysvc.GroupType,
+			listAllSubjectsErr: svcerr.ErrNotFound,
+			err:                svcerr.ErrNotFound,
+		},
+		{
+			desc:                    "list permissions with failed to list permissions",
+			objectKind:              policysvc.GroupsKind,
+			objectType:              policysvc.GroupType,
+			listAllSubjectsResponse: subjects,
+			retrieveAllResponse: mgclients.ClientsPage{
+				Page:    mgclients.Page{Total: 2, Limit: 10},
+				Clients: []mgclients.Client{directUser, groupUser},
+			},
+			listPermissionErr: svcerr.ErrAuthorization,
+			err:               svcerr.ErrAuthorization,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			policies := new(policymocks.Service)
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, policies, new(mocks.Emailer), phasher, idProvider, users.Config{MaxObjectUsers: tc.maxObjectUsers})
+
+			policies.On("ListAllSubjects", context.Background(), policysvc.Policy{
+				SubjectType: policysvc.UserType,
+				Permission:  policysvc.ViewPermission,
+				Object:      validID,
+				ObjectType:  tc.objectType,
+			}).Return(tc.listAllSubjectsResponse, tc.listAllSubjectsErr)
+			cRepo.On("RetrieveAll", context.Background(), mock.Anything).Return(tc.retrieveAllResponse, nil)
+			policies.On("ListPermissions", mock.Anything, mock.Anything, []string{}).Return(func(_ context.Context, pr policysvc.Policy, _ []string) (policysvc.Permissions, error) {
+				assert.Equal(t, tc.objectType, pr.ObjectType, fmt.Sprintf("%s: expected object type %s got %s", tc.desc, tc.objectType, pr.ObjectType))
+				return permissions[pr.Subject], tc.listPermissionErr
+			})
+
+			page, err := svc.ListObjectPermissions(context.Background(), session, tc.objectKind, validID, mgclients.Page{Limit: 10})
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			assert.Equal(t, tc.response, page, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.response, page))
+		})
+	}
+}
+
 func TestIssueToken(t *testing.T) {
 	svc, auth, cRepo, _, _ := newService()
 
@@ -1486,13 +2468,18 @@ func TestIssueToken(t *testing.T) {
 	rClient.Credentials.Secret, _ = phasher.Hash(client.Credentials.Secret)
 	rClient2.Credentials.Secret = "wrongsecret"
 	rClient3.Credentials.Secret, _ = phasher.Hash("wrongsecret")
+	disabledClient := rClient
+	disabledClient.Status = mgclients.DisabledStatus
+	disabledClient.UpdatedAt = time.Now()
 
 	cases := []struct {
 		desc                       string
 		client                     mgclients.Client
+		audience                   string
 		retrieveByIdentityResponse mgclients.Client
 		issueResponse              *magistrala.Token
 		retrieveByIdentityErr      error
+		updateLastLoginErr         error
 		issueErr                   error
 		err                        error
 	}{
@@ -1503,6 +2490,14 @@ func TestIssueToken(t *testing.T) {
 			issueResponse:              &magistrala.Token{AccessToken: validToken, RefreshToken: &validToken, AccessType: "3"},
 			err:                        nil,
 		},
+		{
+			desc:                       "issue token with audience",
+			client:                     client,
+			audience:                   "users",
+			retrieveByIdentityResponse: rClient,
+			issueResponse:              &magistrala.Token{AccessToken: validToken, RefreshToken: &validToken, AccessType: "3"},
+			err:                        nil,
+		},
 		{
 			desc:                       "issue token for non-empty domain id",
 			client:                     client,
@@ -1517,6 +2512,12 @@ func TestIssueToken(t *testing.T) {
 			retrieveByIdentityErr:      repoerr.ErrNotFound,
 			err:                        repoerr.ErrNotFound,
 		},
+		{
+			desc:                       "issue token for a recently disabled client",
+			client:                     client,
+			retrieveByIdentityResponse: disabledClient,
+			err:                        svcerr.ErrAuthentication,
+		},
 		{
 			desc:                       "issue token for a client with wrong secret",
 			client:                     client,
@@ -1531,6 +2532,13 @@ func TestIssueToken(t *testing.T) {
 			issueErr:                   svcerr.ErrAuthentication,
 			err:                        svcerr.ErrAuthentication,
 		},
+		{
+			desc:                       "issue token with failed to record login",
+			client:                     client,
+			retrieveByIdentityResponse: rClient,
+			updateLastLoginErr:         repoerr.ErrUpdateEntity,
+			err:                        svcerr.ErrUpdateEntity,
+		},
 		{
 			desc:                       "issue token with grpc error",
 			client:                     client,
@@ -1544,19 +2552,122 @@ func TestIssueToken(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), tc.client.Credentials.Identity).Return(tc.retrieveByIdentityResponse, tc.retrieveByIdentityErr)
-			authCall := auth.On("Issue", context.Background(), &magistrala.IssueReq{UserId: tc.client.ID, Type: uint32(mgauth.AccessKey)}).Return(tc.issueResponse, tc.issueErr)
-			token, err := svc.IssueToken(context.Background(), tc.client.Credentials.Identity, tc.client.Credentials.Secret)
+			repoCall1 := cRepo.On("UpdateLastLogin", context.Background(), tc.client.ID, mock.Anything).Return(tc.updateLastLoginErr)
+			repoCall2 := cRepo.On("RetrieveMFA", context.Background(), tc.client.ID).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
+			authCall := auth.On("Issue", context.Background(), &magistrala.IssueReq{UserId: tc.client.ID, Type: uint32(mgauth.AccessKey), Audience: tc.audience}).Return(tc.issueResponse, tc.issueErr)
+			token, err := svc.IssueToken(context.Background(), tc.client.Credentials.Identity, tc.client.Credentials.Secret, "", tc.audience)
 			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 			if err == nil {
 				assert.NotEmpty(t, token.GetAccessToken(), fmt.Sprintf("%s: expected %s not to be empty\n", tc.desc, token.GetAccessToken()))
 				assert.NotEmpty(t, token.GetRefreshToken(), fmt.Sprintf("%s: expected %s not to be empty\n", tc.desc, token.GetRefreshToken()))
 				ok := repoCall.Parent.AssertCalled(t, "RetrieveByIdentity", context.Background(), tc.client.Credentials.Identity)
 				assert.True(t, ok, fmt.Sprintf("RetrieveByIdentity was not called on %s", tc.desc))
-				ok = authCall.Parent.AssertCalled(t, "Issue", context.Background(), &magistrala.IssueReq{UserId: tc.client.ID, Type: uint32(mgauth.AccessKey)})
+				ok = authCall.Parent.AssertCalled(t, "Issue", context.Background(), &magistrala.IssueReq{UserId: tc.client.ID, Type: uint32(mgauth.AccessKey), Audience: tc.audience})
 				assert.True(t, ok, fmt.Sprintf("Issue was not called on %s", tc.desc))
 			}
 			authCall.Unset()
 			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+		})
+	}
+}
+
+func TestIssueTokenLoginAlerts(t *testing.T) {
+	rClient := client
+	rClient.Credentials.Secret, _ = phasher.Hash(client.Credentials.Secret)
+	ip := "192.0.2.1"
+	otherIP := "192.0.2.2"
+	threshold := 3
+
+	type attempt struct {
+		ip      string
+		secret  string
+		unknown bool
+	}
+	failed := attempt{ip: ip, secret: "wrongsecret"}
+
+	cases := []struct {
+		desc     string
+		window   time.Duration
+		attempts []attempt
+		alerts   int
+		count    int
+	}{
+		{
+			desc:     "failures below threshold",
+			window:   time.Minute,
+			attempts: []attempt{failed, failed},
+		},
+		{
+			desc:     "failures crossing threshold",
+			window:   time.Minute,
+			attempts: []attempt{failed, failed, failed},
+			alerts:   1,
+			count:    threshold,
+		},
+		{
+			desc:     "failures after threshold within window",
+			window:   time.Minute,
+			attempts: []attempt{failed, failed, failed, failed, failed, failed},
+			alerts:   1,
+			count:    threshold,
+		},
+		{
+			desc:     "failures of unknown identity crossing threshold",
+			window:   time.Minute,
+			attempts: []attempt{{ip: ip, unknown: true}, {ip: ip, unknown: true}, {ip: ip, unknown: true}},
+			alerts:   1,
+			count:    threshold,
+		},
+		{
+			desc:     "failures from different IP addresses",
+			window:   time.Minute,
+			attempts: []attempt{failed, failed, {ip: otherIP, secret: "wrongsecret"}},
+		},
+		{
+			desc:     "failures interrupted by successful login",
+			window:   time.Minute,
+			attempts: []attempt{failed, failed, {ip: ip, secret: client.Credentials.Secret}, failed, failed},
+		},
+		{
+			desc:     "failures after window expired",
+			window:   time.Nanosecond,
+			attempts: []attempt{failed, failed, failed},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			tokenClient := new(authmocks.TokenServiceClient)
+			alerter := new(mocks.Alerter)
+			cfg := users.Config{
+				LoginAlerts: users.LoginAlerts{Alerter: alerter, Threshold: threshold, Window: tc.window},
+			}
+			svc := users.NewService(tokenClient, cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, cfg)
+
+			cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			cRepo.On("RetrieveMFA", mock.Anything, mock.Anything).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
+			tokenClient.On("Issue", mock.Anything, mock.Anything).Return(&magistrala.Token{AccessToken: validToken}, nil)
+			alerter.On("SendLoginAlert", mock.Anything).Return(nil)
+			for _, a := range tc.attempts {
+				ctx := users.WithClientIP(context.Background(), a.ip)
+				repoCall := cRepo.On("RetrieveByIdentity", ctx, client.Credentials.Identity).Return(rClient, nil)
+				if a.unknown {
+					repoCall.Unset()
+					repoCall = cRepo.On("RetrieveByIdentity", ctx, client.Credentials.Identity).Return(mgclients.Client{}, repoerr.ErrNotFound)
+				}
+				_, _ = svc.IssueToken(ctx, client.Credentials.Identity, a.secret, "", "")
+				repoCall.Unset()
+			}
+			alerter.AssertNumberOfCalls(t, "SendLoginAlert", tc.alerts)
+			if tc.alerts > 0 {
+				alert := alerter.Calls[0].Arguments.Get(0).(users.LoginAlert)
+				assert.Equal(t, client.Credentials.Identity, alert.Identity, fmt.Sprintf("%s: expected identity %s got %s", tc.desc, client.Credentials.Identity, alert.Identity))
+				assert.Equal(t, ip, alert.IP, fmt.Sprintf("%s: expected IP %s got %s", tc.desc, ip, alert.IP))
+				assert.Equal(t, tc.count, alert.Count, fmt.Sprintf("%s: expected count %d got %d", tc.desc, tc.count, alert.Count))
+			}
 		})
 	}
 }
@@ -1574,6 +2685,7 @@ func TestRefreshToken(t *testing.T) {
 		refresErr   error
 		repoResp    mgclients.Client
 		repoErr     error
+		mfaErr      error
 		err         error
 	}{
 		{
@@ -1581,14 +2693,23 @@ func TestRefreshToken(t *testing.T) {
 			session:     authn.Session{DomainUserID: validID, UserID: validID, DomainID: validID},
 			refreshResp: &magistrala.Token{AccessToken: validToken, RefreshToken: &validToken, AccessType: "3"},
 			repoResp:    rClient,
+			mfaErr:      repoerr.ErrNotFound,
 			err:         nil,
 		},
+		{
+			desc:     "refresh token with failed to check MFA enrollment",
+			session:  authn.Session{DomainUserID: validID, UserID: validID, DomainID: validID},
+			repoResp: rClient,
+			mfaErr:   repoerr.ErrViewEntity,
+			err:      svcerr.ErrAuthentication,
+		},
 		{
 			desc:        "refresh token with access token for an existing client",
 			session:     authn.Session{DomainUserID: validID, UserID: validID, DomainID: validID},
 			refreshResp: &magistrala.Token{},
 			refresErr:   svcerr.ErrAuthentication,
 			repoResp:    rClient,
+			mfaErr:      repoerr.ErrNotFound,
 			err:         svcerr.ErrAuthentication,
 		},
 		{
@@ -1603,12 +2724,19 @@ func TestRefreshToken(t *testing.T) {
 			repoResp: mgclients.Client{Status: mgclients.DisabledStatus},
 			err:      svcerr.ErrAuthentication,
 		},
+		{
+			desc:     "refresh token with refresh token for a deleted client",
+			session:  authn.Session{DomainUserID: validID, UserID: validID, DomainID: validID},
+			repoResp: mgclients.Client{Status: mgclients.DeletedStatus},
+			err:      svcerr.ErrAuthentication,
+		},
 		{
 			desc:        "refresh token with empty domain id",
 			session:     authn.Session{DomainUserID: validID, UserID: validID, DomainID: validID},
 			refreshResp: &magistrala.Token{},
 			refresErr:   svcerr.ErrAuthentication,
 			repoResp:    rClient,
+			mfaErr:      repoerr.ErrNotFound,
 			err:         svcerr.ErrAuthentication,
 		},
 	}
@@ -1617,6 +2745,7 @@ func TestRefreshToken(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			authCall := authsvc.On("Refresh", context.Background(), &magistrala.RefreshReq{RefreshToken: validToken}).Return(tc.refreshResp, tc.refresErr)
 			repoCall := crepo.On("RetrieveByID", context.Background(), tc.session.UserID).Return(tc.repoResp, tc.repoErr)
+			repoCall1 := crepo.On("RetrieveMFA", context.Background(), tc.repoResp.ID).Return(users.MFAEnrollment{}, tc.mfaErr)
 			token, err := svc.RefreshToken(context.Background(), tc.session, validToken)
 			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 			if err == nil {
@@ -1629,6 +2758,7 @@ func TestRefreshToken(t *testing.T) {
 			}
 			authCall.Unset()
 			repoCall.Unset()
+			repoCall1.Unset()
 		})
 	}
 }
@@ -1665,7 +2795,7 @@ func TestGenerateResetToken(t *testing.T) {
 				},
 			},
 			retrieveByIdentityErr: repoerr.ErrNotFound,
-			err:                   repoerr.ErrNotFound,
+			err:                   nil,
 		},
 		{
 			desc:                       "generate reset token with failed to issue token",
@@ -1681,8 +2811,9 @@ func TestGenerateResetToken(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.desc, func(t *testing.T) {
 			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), tc.email).Return(tc.retrieveByIdentityResponse, tc.retrieveByIdentityErr)
+			cRepo.On("UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			authCall := auth.On("Issue", context.Background(), mock.Anything).Return(tc.issueResponse, tc.issueErr)
-			svcCall := e.On("SendPasswordReset", []string{tc.email}, tc.host, client.Name, validToken).Return(tc.err)
+			svcCall := e.On("SendPasswordReset", []string{tc.email}, tc.host, client.Name, validToken, users.EmailBranding{}).Return(tc.err)
 			err := svc.GenerateResetToken(context.Background(), tc.email, tc.host)
 			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 			repoCall.Parent.AssertCalled(t, "RetrieveByIdentity", context.Background(), tc.email)
@@ -1693,6 +2824,179 @@ func TestGenerateResetToken(t *testing.T) {
 	}
 }
 
+func TestGenerateResetTokenCoalescing(t *testing.T) {
+	host := "examplehost"
+	token := &magistrala.Token{AccessToken: validToken}
+
+	newResetService := func() (users.Service, *authmocks.TokenServiceClient, *mocks.Repository, *mocks.Emailer) {
+		cRepo := new(mocks.Repository)
+		e := new(mocks.Emailer)
+		tokenClient := new(authmocks.TokenServiceClient)
+		cfg := users.Config{PasswordReset: users.PasswordReset{Window: time.Minute, MaxRequests: 5}}
+		svc := users.NewService(tokenClient, cRepo, new(policymocks.Service), e, phasher, idProvider, cfg)
+
+		return svc, tokenClient, cRepo, e
+	}
+
+	t.Run("concurrent requests within the window", func(t *testing.T) {
+		svc, tokenClient, cRepo, e := newResetService()
+		email := client.Credentials.Identity
+		cRepo.On("RetrieveByIdentity", context.Background(), email).Return(client, nil)
+		tokenClient.On("Issue", context.Background(), mock.Anything).Return(token, nil)
+		e.On("SendPasswordReset", []string{email}, host, client.Name, validToken, users.EmailBranding{}).Return(nil)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- svc.GenerateResetToken(context.Background(), email, host)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.Nil(t, err, fmt.Sprintf("expected generic response got %s", err))
+		}
+		tokenClient.AssertNumberOfCalls(t, "Issue", 1)
+		e.AssertNumberOfCalls(t, "SendPasswordReset", 1)
+
+		err := svc.GenerateResetToken(context.Background(), email, host)
+		assert.True(t, errors.Contains(err, apiutil.ErrTooManyRequests), fmt.Sprintf("expected %s got %s", apiutil.ErrTooManyRequests, err))
+		e.AssertNumberOfCalls(t, "SendPasswordReset", 1)
+	})
+
+	t.Run("requests of different identities", func(t *testing.T) {
+		svc, tokenClient, cRepo, e := newResetService()
+		emails := []string{"first@example.com", "second@example.com"}
+		for _, email := range emails {
+			cRepo.On("RetrieveByIdentity", context.Background(), email).Return(client, nil)
+			e.On("SendPasswordReset", []string{email}, host, client.Name, validToken, users.EmailBranding{}).Return(nil)
+		}
+		tokenClient.On("Issue", context.Background(), mock.Anything).Return(token, nil)
+
+		for _, email := range emails {
+			err := svc.GenerateResetToken(context.Background(), email, host)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", email, err))
+		}
+		tokenClient.AssertNumberOfCalls(t, "Issue", 2)
+		e.AssertNumberOfCalls(t, "SendPasswordReset", 2)
+	})
+
+	t.Run("request after failed e-mail", func(t *testing.T) {
+		svc, tokenClient, cRepo, e := newResetService()
+		email := client.Credentials.Identity
+		cRepo.On("RetrieveByIdentity", context.Background(), email).Return(client, nil)
+		tokenClient.On("Issue", context.Background(), mock.Anything).Return(token, nil)
+		e.On("SendPasswordReset", []string{email}, host, client.Name, validToken, users.EmailBranding{}).Return(svcerr.ErrCreateEntity).Once()
+		e.On("SendPasswordReset", []string{email}, host, client.Name, validToken, users.EmailBranding{}).Return(nil).Once()
+
+		err := svc.GenerateResetToken(context.Background(), email, host)
+		assert.True(t, errors.Contains(err, svcerr.ErrCreateEntity), fmt.Sprintf("expected %s got %s", svcerr.ErrCreateEntity, err))
+		err = svc.GenerateResetToken(context.Background(), email, host)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+		e.AssertNumberOfCalls(t, "SendPasswordReset", 2)
+	})
+
+	t.Run("requests of unknown identity", func(t *testing.T) {
+		svc, tokenClient, cRepo, e := newResetService()
+		email := "unknown@example.com"
+		cRepo.On("RetrieveByIdentity", context.Background(), email).Return(mgclients.Client{}, repoerr.ErrNotFound)
+
+		// The unknown identity is answered and limited like the known ones.
+		for i := 0; i < 5; i++ {
+			err := svc.GenerateResetToken(context.Background(), email, host)
+			assert.Nil(t, err, fmt.Sprintf("expected generic response got %s", err))
+		}
+		err := svc.GenerateResetToken(context.Background(), email, host)
+		assert.True(t, errors.Contains(err, apiutil.ErrTooManyRequests), fmt.Sprintf("expected %s got %s", apiutil.ErrTooManyRequests, err))
+		cRepo.AssertNumberOfCalls(t, "RetrieveByIdentity", 1)
+		tokenClient.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+		e.AssertNotCalled(t, "SendPasswordReset", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requests of too many identities", func(t *testing.T) {
+		svc, tokenClient, cRepo, e := newResetService()
+		cRepo.On("RetrieveByIdentity", context.Background(), mock.Anything).Return(mgclients.Client{}, repoerr.ErrNotFound)
+
+		for i := 0; i < 10000; i++ {
+			err := svc.GenerateResetToken(context.Background(), fmt.Sprintf("user%d@example.com", i), host)
+			assert.Nil(t, err, fmt.Sprintf("expected generic response got %s", err))
+		}
+		// The request which can't be tracked is rejected instead of sent.
+		err := svc.GenerateResetToken(context.Background(), client.Credentials.Identity, host)
+		assert.True(t, errors.Contains(err, apiutil.ErrTooManyRequests), fmt.Sprintf("expected %s got %s", apiutil.ErrTooManyRequests, err))
+		cRepo.AssertNumberOfCalls(t, "RetrieveByIdentity", 10000)
+		tokenClient.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+		e.AssertNotCalled(t, "SendPasswordReset", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGenerateResetTokenCache(t *testing.T) {
+	host := "examplehost"
+	token := &magistrala.Token{AccessToken: validToken}
+	email := client.Credentials.Identity
+
+	cases := []struct {
+		desc     string
+		count    int
+		claimErr error
+		sent     bool
+		err      error
+	}{
+		{
+			desc:  "first request of the window",
+			count: 1,
+			sent:  true,
+		},
+		{
+			desc:  "request sent by another instance",
+			count: 2,
+		},
+		{
+			desc:  "request past the limit",
+			count: 6,
+			err:   apiutil.ErrTooManyRequests,
+		},
+		{
+			desc:     "request with full cache",
+			claimErr: users.ErrResetRequestsFull,
+			err:      apiutil.ErrTooManyRequests,
+		},
+		{
+			desc:     "request with failed cache",
+			claimErr: repoerr.ErrViewEntity,
+			err:      apiutil.ErrTooManyRequests,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			e := new(mocks.Emailer)
+			tokenClient := new(authmocks.TokenServiceClient)
+			cache := new(mocks.ResetCache)
+			cfg := users.Config{PasswordReset: users.PasswordReset{Window: time.Minute, MaxRequests: 5, Cache: cache}}
+			svc := users.NewService(tokenClient, cRepo, new(policymocks.Service), e, phasher, idProvider, cfg)
+
+			cache.On("Claim", context.Background(), email, time.Minute).Return(tc.count, tc.claimErr)
+			cRepo.On("RetrieveByIdentity", context.Background(), email).Return(client, nil)
+			tokenClient.On("Issue", context.Background(), mock.Anything).Return(token, nil)
+			e.On("SendPasswordReset", []string{email}, host, client.Name, validToken, users.EmailBranding{}).Return(nil)
+
+			err := svc.GenerateResetToken(context.Background(), email, host)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			if tc.sent {
+				e.AssertNumberOfCalls(t, "SendPasswordReset", 1)
+				return
+			}
+			cRepo.AssertNotCalled(t, "RetrieveByIdentity", mock.Anything, mock.Anything)
+			e.AssertNotCalled(t, "SendPasswordReset", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
 func TestResetSecret(t *testing.T) {
 	svc, cRepo := newServiceMinimal()
 
@@ -1840,6 +3144,8 @@ func TestOAuthCallback(t *testing.T) {
 		saveErr                    error
 		addPoliciesErr             error
 		deletePoliciesErr          error
+		saveLinkedProviderErr      error
+		updateLastLoginErr         error
 		err                        error
 	}{
 		{
@@ -1890,6 +3196,49 @@ func TestOAuthCallback(t *testing.T) {
 			retrieveByIdentityErr: repoerr.ErrNotFound,
 			err:                   svcerr.ErrAuthorization,
 		},
+		{
+			desc: "oauth signin callback with linked provider successfully",
+			client: mgclients.Client{
+				Credentials: mgclients.Credentials{
+					Identity: "test@example.com",
+				},
+				Metadata: mgclients.Metadata{"oauth_provider": "google"},
+			},
+			retrieveByIdentityResponse: mgclients.Client{
+				ID:   testsutil.GenerateUUID(t),
+				Role: mgclients.UserRole,
+			},
+			err: nil,
+		},
+		{
+			desc: "oauth signin callback with failed to link provider",
+			client: mgclients.Client{
+				Credentials: mgclients.Credentials{
+					Identity: "test@example.com",
+				},
+				Metadata: mgclients.Metadata{"oauth_provider": "google"},
+			},
+			retrieveByIdentityResponse: mgclients.Client{
+				ID:   testsutil.GenerateUUID(t),
+				Role: mgclients.UserRole,
+			},
+			saveLinkedProviderErr: repoerr.ErrCreateEntity,
+			err:                   svcerr.ErrUpdateEntity,
+		},
+		{
+			desc: "oauth signin callback with failed to record login",
+			client: mgclients.Client{
+				Credentials: mgclients.Credentials{
+					Identity: "test@example.com",
+				},
+			},
+			retrieveByIdentityResponse: mgclients.Client{
+				ID:   testsutil.GenerateUUID(t),
+				Role: mgclients.UserRole,
+			},
+			updateLastLoginErr: repoerr.ErrUpdateEntity,
+			err:                svcerr.ErrUpdateEntity,
+		},
 		{
 			desc: "oauth signin callback with user not in the platform",
 			client: mgclients.Client{
@@ -1908,13 +3257,92 @@ func TestOAuthCallback(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), tc.client.Credentials.Identity).Return(tc.retrieveByIdentityResponse, tc.retrieveByIdentityErr)
 			repoCall1 := cRepo.On("Save", context.Background(), mock.Anything).Return(tc.saveResponse, tc.saveErr)
+			repoCall2 := cRepo.On("SaveLinkedProvider", context.Background(), tc.retrieveByIdentityResponse.ID, "google").Return(tc.saveLinkedProviderErr)
+			repoCall3 := cRepo.On("UpdateLastLogin", context.Background(), mock.Anything, mock.Anything).Return(tc.updateLastLoginErr)
+			repoCall4 := cRepo.On("RetrieveMFA", context.Background(), mock.Anything).Return(users.MFAEnrollment{}, repoerr.ErrNotFound)
 			policyCall := policies.On("AddPolicies", context.Background(), mock.Anything).Return(tc.addPoliciesErr)
 			_, err := svc.OAuthCallback(context.Background(), tc.client)
 			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 			repoCall.Parent.AssertCalled(t, "RetrieveByIdentity", context.Background(), tc.client.Credentials.Identity)
+			if tc.client.Metadata != nil {
+				repoCall2.Parent.AssertCalled(t, "SaveLinkedProvider", context.Background(), tc.retrieveByIdentityResponse.ID, "google")
+			}
 			repoCall.Unset()
 			repoCall1.Unset()
+			repoCall2.Unset()
+			repoCall3.Unset()
+			repoCall4.Unset()
 			policyCall.Unset()
 		})
 	}
 }
+
+func TestOAuthCallbackMFA(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	cfg := users.Config{MFA: users.MFAPolicy{Roles: []mgclients.Role{mgclients.AdminRole}}}
+	svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, cfg)
+
+	user := mgclients.Client{ID: testsutil.GenerateUUID(t), Role: mgclients.UserRole}
+	admin := mgclients.Client{ID: testsutil.GenerateUUID(t), Role: mgclients.AdminRole}
+
+	cases := []struct {
+		desc        string
+		client      mgclients.Client
+		enrollment  users.MFAEnrollment
+		retrieveErr error
+		err         error
+	}{
+		{
+			desc:        "user without MFA",
+			client:      user,
+			retrieveErr: repoerr.ErrNotFound,
+		},
+		{
+			desc:       "user with enrolled MFA",
+			client:     user,
+			enrollment: users.MFAEnrollment{ClientID: user.ID, Secret: "secret", EnrolledAt: time.Now()},
+			err:        svcerr.ErrMFACode,
+		},
+		{
+			desc:        "admin required to enroll MFA",
+			client:      admin,
+			retrieveErr: repoerr.ErrNotFound,
+			err:         svcerr.ErrMFAEnrollmentRequired,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			oauthClient := mgclients.Client{Credentials: mgclients.Credentials{Identity: "user@example.com"}}
+			repoCall := cRepo.On("RetrieveByIdentity", context.Background(), oauthClient.Credentials.Identity).Return(tc.client, nil)
+			repoCall1 := cRepo.On("RetrieveMFA", context.Background(), tc.client.ID).Return(tc.enrollment, tc.retrieveErr)
+			repoCall2 := cRepo.On("UpdateLastLogin", context.Background(), tc.client.ID, mock.Anything).Return(nil)
+			_, err := svc.OAuthCallback(context.Background(), oauthClient)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			if tc.err != nil {
+				cRepo.AssertNotCalled(t, "UpdateLastLogin", context.Background(), tc.client.ID, mock.Anything)
+			}
+			repoCall.Unset()
+			repoCall1.Unset()
+			repoCall2.Unset()
+			cRepo.Calls = nil
+		})
+	}
+}
+
+func TestOAuthCallbackJITDisabled(t *testing.T) {
+	cRepo := new(mocks.Repository)
+	svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, new(policymocks.Service), new(mocks.Emailer), phasher, idProvider, users.Config{JITDisabled: []string{"saml"}})
+
+	client := mgclients.Client{
+		Credentials: mgclients.Credentials{
+			Identity: "test@example.com",
+		},
+		Metadata: mgclients.Metadata{"oauth_provider": "saml"},
+	}
+	repoCall := cRepo.On("RetrieveByIdentity", context.Background(), client.Credentials.Identity).Return(mgclients.Client{}, repoerr.ErrNotFound)
+	_, err := svc.OAuthCallback(context.Background(), client)
+	assert.True(t, errors.Contains(err, svcerr.ErrAuthentication), fmt.Sprintf("expected %s got %s\n", svcerr.ErrAuthentication, err))
+	cRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	repoCall.Unset()
+}
@@ -5,6 +5,7 @@ package tracing
 
 import (
 	"context"
+	"time"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/magistrala/pkg/authn"
@@ -35,11 +36,27 @@ func (tm *tracingMiddleware) RegisterClient(ctx context.Context, session authn.S
 }
 
 // IssueToken traces the "IssueToken" operation of the wrapped clients.Service.
-func (tm *tracingMiddleware) IssueToken(ctx context.Context, identity, secret string) (*magistrala.Token, error) {
+func (tm *tracingMiddleware) IssueToken(ctx context.Context, identity, secret, mfaCode, audience string) (*magistrala.Token, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_issue_token", trace.WithAttributes(attribute.String("identity", identity)))
 	defer span.End()
 
-	return tm.svc.IssueToken(ctx, identity, secret)
+	return tm.svc.IssueToken(ctx, identity, secret, mfaCode, audience)
+}
+
+// EnrollMFA traces the "EnrollMFA" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) EnrollMFA(ctx context.Context, identity, secret string) (users.MFASecret, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_enroll_mfa", trace.WithAttributes(attribute.String("identity", identity)))
+	defer span.End()
+
+	return tm.svc.EnrollMFA(ctx, identity, secret)
+}
+
+// ConfirmMFA traces the "ConfirmMFA" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) ConfirmMFA(ctx context.Context, identity, secret, code string) error {
+	ctx, span := tm.tracer.Start(ctx, "svc_confirm_mfa", trace.WithAttributes(attribute.String("identity", identity)))
+	defer span.End()
+
+	return tm.svc.ConfirmMFA(ctx, identity, secret, code)
 }
 
 // RefreshToken traces the "RefreshToken" operation of the wrapped clients.Service.
@@ -50,6 +67,30 @@ func (tm *tracingMiddleware) RefreshToken(ctx context.Context, session authn.Ses
 	return tm.svc.RefreshToken(ctx, session, refreshToken)
 }
 
+// RequestDeviceCode traces the "RequestDeviceCode" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) RequestDeviceCode(ctx context.Context, audience string) (users.DeviceAuthorization, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_request_device_code", trace.WithAttributes(attribute.String("audience", audience)))
+	defer span.End()
+
+	return tm.svc.RequestDeviceCode(ctx, audience)
+}
+
+// AuthorizeDevice traces the "AuthorizeDevice" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) AuthorizeDevice(ctx context.Context, session authn.Session, userCode, mfaCode string, approve bool) error {
+	ctx, span := tm.tracer.Start(ctx, "svc_authorize_device", trace.WithAttributes(attribute.Bool("approve", approve)))
+	defer span.End()
+
+	return tm.svc.AuthorizeDevice(ctx, session, userCode, mfaCode, approve)
+}
+
+// DeviceToken traces the "DeviceToken" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) DeviceToken(ctx context.Context, deviceCode string) (*magistrala.Token, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_device_token")
+	defer span.End()
+
+	return tm.svc.DeviceToken(ctx, deviceCode)
+}
+
 // ViewClient traces the "ViewClient" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) ViewClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_view_client", trace.WithAttributes(attribute.String("id", id)))
@@ -72,6 +113,18 @@ func (tm *tracingMiddleware) ListClients(ctx context.Context, session authn.Sess
 	return tm.svc.ListClients(ctx, session, pm)
 }
 
+// ListChanges traces the "ListChanges" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) ListChanges(ctx context.Context, session authn.Session, since time.Time, cursor string, limit uint64) (users.ChangesPage, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_list_changes", trace.WithAttributes(
+		attribute.String("since", since.String()),
+		attribute.String("cursor", cursor),
+		attribute.Int64("limit", int64(limit)),
+	))
+	defer span.End()
+
+	return tm.svc.ListChanges(ctx, session, since, cursor, limit)
+}
+
 // SearchUsers traces the "SearchUsers" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) SearchUsers(ctx context.Context, pm mgclients.Page) (mgclients.ClientsPage, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_search_clients", trace.WithAttributes(
@@ -118,6 +171,62 @@ func (tm *tracingMiddleware) UpdateClientIdentity(ctx context.Context, session a
 	return tm.svc.UpdateClientIdentity(ctx, session, id, identity)
 }
 
+// ConfirmIdentity traces the "ConfirmIdentity" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) ConfirmIdentity(ctx context.Context, token string) (mgclients.Client, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_confirm_identity")
+	defer span.End()
+
+	return tm.svc.ConfirmIdentity(ctx, token)
+}
+
+// VerifyPhone traces the "VerifyPhone" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) VerifyPhone(ctx context.Context, identity, code string) (mgclients.Client, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_verify_phone")
+	defer span.End()
+
+	return tm.svc.VerifyPhone(ctx, identity, code)
+}
+
+// SendPhoneCode traces the "SendPhoneCode" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) SendPhoneCode(ctx context.Context, identity string) error {
+	ctx, span := tm.tracer.Start(ctx, "svc_send_phone_code")
+	defer span.End()
+
+	return tm.svc.SendPhoneCode(ctx, identity)
+}
+
+// ResendVerification traces the "ResendVerification" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) ResendVerification(ctx context.Context, identity string) error {
+	ctx, span := tm.tracer.Start(ctx, "svc_resend_verification")
+	defer span.End()
+
+	return tm.svc.ResendVerification(ctx, identity)
+}
+
+// RequestDeletion traces the "RequestDeletion" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) RequestDeletion(ctx context.Context, session authn.Session) error {
+	ctx, span := tm.tracer.Start(ctx, "svc_request_deletion")
+	defer span.End()
+
+	return tm.svc.RequestDeletion(ctx, session)
+}
+
+// ConfirmDeletion traces the "ConfirmDeletion" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) ConfirmDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_confirm_deletion")
+	defer span.End()
+
+	return tm.svc.ConfirmDeletion(ctx, token)
+}
+
+// CancelDeletion traces the "CancelDeletion" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) CancelDeletion(ctx context.Context, token string) (mgclients.Client, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_cancel_deletion")
+	defer span.End()
+
+	return tm.svc.CancelDeletion(ctx, token)
+}
+
 // UpdateClientSecret traces the "UpdateClientSecret" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) UpdateClientSecret(ctx context.Context, session authn.Session, oldSecret, newSecret string) (mgclients.Client, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_update_client_secret")
@@ -156,6 +265,16 @@ func (tm *tracingMiddleware) SendPasswordReset(ctx context.Context, host, email,
 	return tm.svc.SendPasswordReset(ctx, host, email, user, token)
 }
 
+// PreviewEmail traces the "PreviewEmail" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) PreviewEmail(ctx context.Context, session authn.Session, name string, data users.EmailData) (users.EmailPreview, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_preview_email", trace.WithAttributes(
+		attribute.String("template", name),
+	))
+	defer span.End()
+
+	return tm.svc.PreviewEmail(ctx, session, name, data)
+}
+
 // ViewProfile traces the "ViewProfile" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) ViewProfile(ctx context.Context, session authn.Session) (mgclients.Client, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_view_profile")
@@ -175,6 +294,17 @@ func (tm *tracingMiddleware) UpdateClientRole(ctx context.Context, session authn
 	return tm.svc.UpdateClientRole(ctx, session, cli)
 }
 
+// UpdateClientsRole traces the "UpdateClientsRole" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) UpdateClientsRole(ctx context.Context, session authn.Session, ids []string, role mgclients.Role) ([]users.RoleUpdate, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_update_clients_role", trace.WithAttributes(
+		attribute.StringSlice("ids", ids),
+		attribute.String("role", role.String()),
+	))
+	defer span.End()
+
+	return tm.svc.UpdateClientsRole(ctx, session, ids, role)
+}
+
 // EnableClient traces the "EnableClient" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) EnableClient(ctx context.Context, session authn.Session, id string) (mgclients.Client, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_enable_client", trace.WithAttributes(attribute.String("id", id)))
@@ -191,6 +321,14 @@ func (tm *tracingMiddleware) DisableClient(ctx context.Context, session authn.Se
 	return tm.svc.DisableClient(ctx, session, id)
 }
 
+// DisableInactiveClient traces the "DisableInactiveClient" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) DisableInactiveClient(ctx context.Context, id string) (mgclients.Client, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_disable_inactive_client", trace.WithAttributes(attribute.String("id", id)))
+	defer span.End()
+
+	return tm.svc.DisableInactiveClient(ctx, id)
+}
+
 // ListMembers traces the "ListMembers" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) ListMembers(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mgclients.MembersPage, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_list_members", trace.WithAttributes(attribute.String("object_kind", objectKind)), trace.WithAttributes(attribute.String("object_id", objectID)))
@@ -199,6 +337,14 @@ func (tm *tracingMiddleware) ListMembers(ctx context.Context, session authn.Sess
 	return tm.svc.ListMembers(ctx, session, objectKind, objectID, pm)
 }
 
+// ListObjectPermissions traces the "ListObjectPermissions" operation of the wrapped clients.Service.
+func (tm *tracingMiddleware) ListObjectPermissions(ctx context.Context, session authn.Session, objectKind, objectID string, pm mgclients.Page) (mgclients.MembersPage, error) {
+	ctx, span := tm.tracer.Start(ctx, "svc_list_object_permissions", trace.WithAttributes(attribute.String("object_kind", objectKind)), trace.WithAttributes(attribute.String("object_id", objectID)))
+	defer span.End()
+
+	return tm.svc.ListObjectPermissions(ctx, session, objectKind, objectID, pm)
+}
+
 // Identify traces the "Identify" operation of the wrapped clients.Service.
 func (tm *tracingMiddleware) Identify(ctx context.Context, session authn.Session) (string, error) {
 	ctx, span := tm.tracer.Start(ctx, "svc_identify", trace.WithAttributes(attribute.String("user_id", session.UserID)))
@@ -0,0 +1,149 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/apiutil"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+)
+
+const (
+	// verificationResendInterval is the minimum time between two
+	// verifications sent to the same identity.
+	verificationResendInterval = time.Minute
+
+	// maxVerificationResends is the number of identities whose last resend
+	// is tracked, so requests for arbitrary identities can't grow the
+	// tracked resends without bound.
+	maxVerificationResends = 10000
+)
+
+var errVerificationResend = errors.New("verification was requested recently")
+
+func (svc service) ResendVerification(ctx context.Context, identity string) error {
+	identity = strings.TrimSpace(identity)
+	phone := IsPhone(identity)
+	if phone {
+		normalized, err := NormalizePhone(identity)
+		if err != nil {
+			return errors.Wrap(svcerr.ErrMalformedEntity, err)
+		}
+		identity = normalized
+	}
+	// The resends are limited before the lookup, so the limit doesn't
+	// reveal which identities belong to accounts.
+	if !svc.resends.allow(identity) {
+		return errors.Wrap(apiutil.ErrTooManyRequests, errVerificationResend)
+	}
+
+	pi, err := svc.clients.RetrievePendingVerification(ctx, identity)
+	switch {
+	case errors.Contains(err, repoerr.ErrNotFound):
+		// Unknown and already verified identities are a no-op.
+		return nil
+	case err != nil:
+		return errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+
+	// The delivery failures are not reported, since only the accounts
+	// waiting for verification could fail.
+	if phone {
+		if attempts, err := phoneCodeAttempts(pi); err == nil && svc.config.PhoneVerification.Sender != nil {
+			_ = svc.sendPhoneCode(ctx, pi.ClientID, identity, attempts)
+		}
+		return nil
+	}
+	cli, err := svc.clients.RetrieveByID(ctx, pi.ClientID)
+	if err != nil {
+		return errors.Wrap(svcerr.ErrViewEntity, err)
+	}
+	_ = svc.sendEmailVerification(ctx, cli.ID, cli.Name, identity)
+
+	return nil
+}
+
+// sendEmailVerification stores the new verification token of the user
+// e-mail and sends the verification link. The stored token replaces the
+// previous one.
+func (svc service) sendEmailVerification(ctx context.Context, clientID, name, identity string) error {
+	token, err := generateIdentityToken()
+	if err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+	now := time.Now()
+	pi := PendingIdentity{
+		ClientID:  clientID,
+		Identity:  identity,
+		Token:     hashIdentityToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(svc.config.IdentityTokenTTL),
+	}
+	if err := svc.clients.SavePendingIdentity(ctx, pi); err != nil {
+		return errors.Wrap(svcerr.ErrCreateEntity, err)
+	}
+
+	return svc.email.SendVerification([]string{identity}, name, token)
+}
+
+// verifyEmail enables the user whose registered e-mail is verified.
+func (svc service) verifyEmail(ctx context.Context, pi PendingIdentity) (mgclients.Client, error) {
+	cli := mgclients.Client{
+		ID:        pi.ClientID,
+		Status:    mgclients.EnabledStatus,
+		UpdatedAt: time.Now(),
+		UpdatedBy: pi.ClientID,
+	}
+	cli, err := svc.clients.ChangeStatus(ctx, cli)
+	if err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrUpdateEntity, err)
+	}
+	if err := svc.clients.RemovePendingIdentity(ctx, pi.ClientID); err != nil {
+		return mgclients.Client{}, errors.Wrap(svcerr.ErrRemoveEntity, err)
+	}
+
+	return cli, nil
+}
+
+// verificationResends tracks the last verification resend per identity.
+// Resends are tracked per service instance.
+type verificationResends struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func newVerificationResends() *verificationResends {
+	return &verificationResends{last: make(map[string]time.Time)}
+}
+
+// allow reports whether the verification may be resent to the identity and
+// records the resend if so.
+func (vr *verificationResends) allow(identity string) bool {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := vr.last[identity]; ok && now.Sub(last) < verificationResendInterval {
+		return false
+	}
+	if len(vr.last) >= maxVerificationResends {
+		for id, last := range vr.last {
+			if now.Sub(last) >= verificationResendInterval {
+				delete(vr.last, id)
+			}
+		}
+	}
+	if len(vr.last) < maxVerificationResends {
+		vr.last[identity] = now
+	}
+
+	return true
+}
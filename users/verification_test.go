@@ -0,0 +1,184 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	authmocks "github.com/absmach/magistrala/auth/mocks"
+	"github.com/absmach/magistrala/pkg/apiutil"
+	"github.com/absmach/magistrala/pkg/authn"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/absmach/magistrala/pkg/errors"
+	repoerr "github.com/absmach/magistrala/pkg/errors/repository"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	policymocks "github.com/absmach/magistrala/pkg/policies/mocks"
+	"github.com/absmach/magistrala/users"
+	"github.com/absmach/magistrala/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegisterClientVerifyEmail(t *testing.T) {
+	cases := []struct {
+		desc         string
+		selfRegister bool
+		sendErr      error
+		status       mgclients.Status
+		err          error
+	}{
+		{
+			desc:         "self register client with e-mail verification",
+			selfRegister: true,
+			status:       mgclients.DisabledStatus,
+		},
+		{
+			desc:         "self register client with failed verification e-mail",
+			selfRegister: true,
+			sendErr:      errors.New("smtp unavailable"),
+			err:          svcerr.ErrCreateEntity,
+		},
+		{
+			desc:   "register client by admin without e-mail verification",
+			status: mgclients.EnabledStatus,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cRepo := new(mocks.Repository)
+			policies := new(policymocks.Service)
+			e := new(mocks.Emailer)
+			cfg := users.Config{VerifyEmail: true}
+			svc := users.NewService(new(authmocks.TokenServiceClient), cRepo, policies, e, phasher, idProvider, cfg)
+
+			var saved mgclients.Client
+			var pending users.PendingIdentity
+			policies.On("AddPolicies", context.Background(), mock.Anything).Return(nil)
+			policies.On("DeletePolicies", context.Background(), mock.Anything).Return(nil)
+			cRepo.On("CheckSuperAdmin", context.Background(), mock.Anything).Return(nil)
+			cRepo.On("Save", context.Background(), mock.Anything).Return(func(_ context.Context, c mgclients.Client) (mgclients.Client, error) {
+				saved = c
+				return c, nil
+			})
+			cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(func(_ context.Context, pi users.PendingIdentity) error {
+				pending = pi
+				return nil
+			})
+			cRepo.On("Delete", context.Background(), mock.Anything).Return(nil)
+			e.On("SendVerification", []string{client.Credentials.Identity}, client.Name, mock.Anything).Return(tc.sendErr)
+
+			_, err := svc.RegisterClient(context.Background(), authn.Session{UserID: validID}, client, tc.selfRegister)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			switch {
+			case tc.sendErr != nil:
+				cRepo.AssertCalled(t, "Delete", context.Background(), saved.ID)
+			case tc.selfRegister:
+				assert.Equal(t, tc.status, saved.Status, fmt.Sprintf("%s: expected status %s got %s", tc.desc, tc.status, saved.Status))
+				assert.Equal(t, saved.ID, pending.ClientID, fmt.Sprintf("%s: expected pending verification of the client", tc.desc))
+				assert.Equal(t, client.Credentials.Identity, pending.Identity, fmt.Sprintf("%s: expected pending verification of the identity", tc.desc))
+				token := e.Calls[0].Arguments.String(2)
+				assert.NotEqual(t, token, pending.Token, fmt.Sprintf("%s: expected only token hash to be stored", tc.desc))
+			default:
+				assert.Equal(t, tc.status, saved.Status, fmt.Sprintf("%s: expected status %s got %s", tc.desc, tc.status, saved.Status))
+				e.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestConfirmIdentityVerification(t *testing.T) {
+	svc, _, cRepo, _, e := newService()
+
+	disabled := client
+	disabled.Status = mgclients.DisabledStatus
+	pending := users.PendingIdentity{ClientID: client.ID, Identity: client.Credentials.Identity, ExpiresAt: time.Now().Add(time.Hour)}
+	enabled := client
+	cRepo.On("RetrievePendingIdentity", context.Background(), mock.Anything).Return(pending, nil)
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(disabled, nil)
+	cRepo.On("ChangeStatus", context.Background(), mock.Anything).Return(enabled, nil)
+	cRepo.On("RemovePendingIdentity", context.Background(), client.ID).Return(nil)
+
+	cli, err := svc.ConfirmIdentity(context.Background(), "token")
+	assert.Nil(t, err, fmt.Sprintf("confirm verification: expected nil got %s", err))
+	assert.Equal(t, mgclients.EnabledStatus, cli.Status, fmt.Sprintf("confirm verification: expected enabled client got %s", cli.Status))
+	cRepo.AssertCalled(t, "ChangeStatus", context.Background(), mock.MatchedBy(func(c mgclients.Client) bool {
+		return c.ID == client.ID && c.Status == mgclients.EnabledStatus
+	}))
+	cRepo.AssertNotCalled(t, "UpdateIdentity", context.Background(), mock.Anything)
+	e.AssertNotCalled(t, "SendIdentityChanged", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResendVerification(t *testing.T) {
+	cases := []struct {
+		desc     string
+		identity string
+		pending  users.PendingIdentity
+		retrErr  error
+		sent     bool
+		err      error
+	}{
+		{
+			desc:     "resend verification to unverified e-mail",
+			identity: client.Credentials.Identity,
+			pending:  users.PendingIdentity{ClientID: client.ID, Identity: client.Credentials.Identity},
+			sent:     true,
+		},
+		{
+			desc:     "resend verification to verified e-mail",
+			identity: client.Credentials.Identity,
+			retrErr:  repoerr.ErrNotFound,
+		},
+		{
+			desc:     "resend verification with failed retrieval",
+			identity: client.Credentials.Identity,
+			retrErr:  repoerr.ErrMalformedEntity,
+			err:      svcerr.ErrViewEntity,
+		},
+		{
+			desc:     "resend verification to invalid phone",
+			identity: "+0123",
+			err:      svcerr.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			svc, _, cRepo, _, e := newService()
+
+			cRepo.On("RetrievePendingVerification", context.Background(), client.Credentials.Identity).Return(tc.pending, tc.retrErr)
+			cRepo.On("RetrieveByID", context.Background(), client.ID).Return(client, nil)
+			cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(nil)
+			e.On("SendVerification", []string{client.Credentials.Identity}, client.Name, mock.Anything).Return(nil)
+
+			err := svc.ResendVerification(context.Background(), tc.identity)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+			switch tc.sent {
+			case true:
+				e.AssertCalled(t, "SendVerification", []string{client.Credentials.Identity}, client.Name, mock.Anything)
+			default:
+				e.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestResendVerificationLimit(t *testing.T) {
+	svc, _, cRepo, _, e := newService()
+
+	pending := users.PendingIdentity{ClientID: client.ID, Identity: client.Credentials.Identity}
+	cRepo.On("RetrievePendingVerification", context.Background(), client.Credentials.Identity).Return(pending, nil)
+	cRepo.On("RetrieveByID", context.Background(), client.ID).Return(client, nil)
+	cRepo.On("SavePendingIdentity", context.Background(), mock.Anything).Return(nil)
+	e.On("SendVerification", []string{client.Credentials.Identity}, client.Name, mock.Anything).Return(nil)
+
+	err := svc.ResendVerification(context.Background(), client.Credentials.Identity)
+	assert.Nil(t, err, fmt.Sprintf("resend verification: expected nil got %s", err))
+	err = svc.ResendVerification(context.Background(), client.Credentials.Identity)
+	assert.True(t, errors.Contains(err, apiutil.ErrTooManyRequests), fmt.Sprintf("resend verification again: expected %s got %s", apiutil.ErrTooManyRequests, err))
+	e.AssertNumberOfCalls(t, "SendVerification", 1)
+}
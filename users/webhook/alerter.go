@@ -0,0 +1,116 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/users"
+	"github.com/cenkalti/backoff/v4"
+)
+
+const (
+	queueSize  = 100
+	maxRetries = 5
+)
+
+var (
+	errQueueFull      = errors.New("login alert queue is full")
+	errUnexpectedCode = errors.New("unexpected status code from webhook")
+)
+
+var _ users.Alerter = (*alerter)(nil)
+
+// message is compatible with Slack incoming webhooks, which show the text
+// and ignore the other fields.
+type message struct {
+	Text string `json:"text"`
+	users.LoginAlert
+}
+
+type alerter struct {
+	url    string
+	client *http.Client
+	queue  chan users.LoginAlert
+	logger *slog.Logger
+}
+
+// NewAlerter returns alerter which posts failed logins alerts as JSON to the
+// webhook URL. Alerts are sent in the background until the context is
+// canceled.
+func NewAlerter(ctx context.Context, url string, timeout time.Duration, logger *slog.Logger) users.Alerter {
+	a := &alerter{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan users.LoginAlert, queueSize),
+		logger: logger,
+	}
+	go a.sendAlerts(ctx)
+
+	return a
+}
+
+func (a *alerter) SendLoginAlert(alert users.LoginAlert) error {
+	select {
+	case a.queue <- alert:
+		return nil
+	default:
+		a.logger.Warn(fmt.Sprintf("failed to enqueue login alert for %s: %s", alert.Identity, errQueueFull))
+		return errQueueFull
+	}
+}
+
+func (a *alerter) sendAlerts(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alert := <-a.queue:
+			send := func() error {
+				return a.send(ctx, alert)
+			}
+			notify := func(err error, next time.Duration) {
+				a.logger.Warn(fmt.Sprintf("failed to send login alert for %s, retrying in %s: %s", alert.Identity, next, err))
+			}
+			bo := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries), ctx)
+			if err := backoff.RetryNotify(send, bo, notify); err != nil {
+				a.logger.Error(fmt.Sprintf("failed to send login alert for %s: %s", alert.Identity, err))
+			}
+		}
+	}
+}
+
+func (a *alerter) send(ctx context.Context, alert users.LoginAlert) error {
+	data, err := json.Marshal(message{
+		Text:       fmt.Sprintf("%d failed logins for %s from %s since %s", alert.Count, alert.Identity, alert.IP, alert.Since.Format(time.RFC3339)),
+		LoginAlert: alert,
+	})
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(data))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Wrap(errUnexpectedCode, fmt.Errorf("status %d", resp.StatusCode))
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package webhook contains the users alerter which posts failed logins
+// alerts to a webhook, such as a Slack incoming webhook, and the SMS sender
+// which posts phone verification codes to an SMS gateway webhook.
+package webhook
@@ -0,0 +1,67 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/users"
+)
+
+var errSendSMS = errors.New("failed to send SMS")
+
+var _ users.SMSSender = (*smsSender)(nil)
+
+type sms struct {
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+type smsSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewSMSSender returns SMS sender which posts the messages as JSON with the
+// recipient phone in the "to" field and the message in the "text" field to
+// the SMS gateway webhook URL. Messages are sent synchronously, so the
+// caller knows whether the verification code was delivered to the gateway.
+func NewSMSSender(url string, timeout time.Duration) users.SMSSender {
+	return &smsSender{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *smsSender) SendVerificationCode(to, code string) error {
+	data, err := json.Marshal(sms{
+		To:   to,
+		Text: fmt.Sprintf("Your Magistrala verification code is %s", code),
+	})
+	if err != nil {
+		return errors.Wrap(errSendSMS, err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(errSendSMS, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(errSendSMS, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Wrap(errSendSMS, errors.Wrap(errUnexpectedCode, fmt.Errorf("status %d", resp.StatusCode)))
+	}
+
+	return nil
+}
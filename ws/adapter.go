@@ -16,6 +16,10 @@ import (
 
 const chansPrefix = "channels"
 
+// SubscriptionsPath is the path of the connections subscribing to the channels
+// with the subscription requests sent over them.
+const SubscriptionsPath = "/subscriptions"
+
 var (
 	// errFailedMessagePublish indicates that message publishing failed.
 	errFailedMessagePublish = errors.New("failed to publish message")
@@ -28,6 +32,10 @@ var (
 
 	// ErrEmptyTopic indicate absence of thingKey in the request.
 	ErrEmptyTopic = errors.New("empty topic")
+
+	// ErrNotSubscribed indicates that the client isn't subscribed to the
+	// specified channel.
+	ErrNotSubscribed = errors.New("not subscribed to the channel")
 )
 
 // Service specifies web socket service API.
@@ -35,7 +43,17 @@ type Service interface {
 	// Subscribe subscribes message from the broker using the thingKey for authorization,
 	// and the channelID for subscription. Subtopic is optional.
 	// If the subscription is successful, nil is returned otherwise error is returned.
+	// ErrConnLimit or ErrThingConnLimit is returned if the connection limits are reached,
+	// and ErrSubscriptionLimit if the client holds the maximum number of subscriptions.
 	Subscribe(ctx context.Context, thingKey, chanID, subtopic string, client *Client) error
+
+	// Unsubscribe removes the subscription of the client to the channel and the
+	// subtopic, freeing its subscription slot.
+	Unsubscribe(ctx context.Context, chanID, subtopic string, client *Client) error
+
+	// Disconnect removes all the subscriptions of the closed client, so the
+	// broker stops delivering the messages to it.
+	Disconnect(ctx context.Context, client *Client) error
 }
 
 var _ Service = (*adapterService)(nil)
@@ -43,13 +61,20 @@ var _ Service = (*adapterService)(nil)
 type adapterService struct {
 	things magistrala.ThingsServiceClient
 	pubsub messaging.PubSub
+	topics messaging.TopicScheme
+	config Config
+	limits *limiter
 }
 
-// New instantiates the WS adapter implementation.
-func New(thingsClient magistrala.ThingsServiceClient, pubsub messaging.PubSub) Service {
+// New instantiates the WS adapter implementation. The clients subscribe to
+// the topics of the topic scheme.
+func New(thingsClient magistrala.ThingsServiceClient, pubsub messaging.PubSub, topics messaging.TopicScheme, cfg Config) Service {
 	return &adapterService{
 		things: thingsClient,
 		pubsub: pubsub,
+		topics: topics,
+		config: cfg,
+		limits: newLimiter(cfg),
 	}
 }
 
@@ -58,16 +83,28 @@ func (svc *adapterService) Subscribe(ctx context.Context, thingKey, chanID, subt
 		return svcerr.ErrAuthentication
 	}
 
-	thingID, err := svc.authorize(ctx, thingKey, chanID, policies.SubscribePermission)
+	thingID, domainID, err := svc.authorize(ctx, thingKey, chanID, policies.SubscribePermission)
 	if err != nil {
 		return svcerr.ErrAuthorization
 	}
 
 	c.id = thingID
+	c.domainID = domainID
+
+	// A client holds a single connection slot, released once it's closed.
+	if c.release == nil {
+		release, err := svc.limits.acquire(thingID)
+		if err != nil {
+			return err
+		}
+		c.release = release
+	}
+	c.buffer(svc.config.SendBuffer, svc.config.SlowConsumer)
 
-	subject := fmt.Sprintf("%s.%s", chansPrefix, chanID)
-	if subtopic != "" {
-		subject = fmt.Sprintf("%s.%s", subject, subtopic)
+	subject := channelSubject(svc.topics.Topic(domainID, chanID), subtopic)
+	added, err := c.addSubscription(subject, svc.config.MaxSubscriptions)
+	if err != nil {
+		return err
 	}
 
 	subCfg := messaging.SubscriberConfig{
@@ -76,15 +113,62 @@ func (svc *adapterService) Subscribe(ctx context.Context, thingKey, chanID, subt
 		Handler: c,
 	}
 	if err := svc.pubsub.Subscribe(ctx, subCfg); err != nil {
+		if added {
+			c.removeSubscription(subject)
+		}
 		return ErrFailedSubscription
 	}
 
 	return nil
 }
 
+func (svc *adapterService) Unsubscribe(ctx context.Context, chanID, subtopic string, c *Client) error {
+	if chanID == "" {
+		return ErrEmptyTopic
+	}
+
+	subject := channelSubject(svc.topics.Topic(c.domainID, chanID), subtopic)
+	if !c.subscribed(subject) {
+		return ErrNotSubscribed
+	}
+	if err := svc.pubsub.Unsubscribe(ctx, c.id, subject); err != nil {
+		return errors.Wrap(errFailedUnsubscribe, err)
+	}
+	c.removeSubscription(subject)
+
+	return nil
+}
+
+func (svc *adapterService) Disconnect(ctx context.Context, c *Client) error {
+	var errs error
+	for _, subject := range c.clearSubscriptions() {
+		if err := svc.pubsub.Unsubscribe(ctx, c.id, subject); err != nil {
+			if errs == nil {
+				errs = err
+				continue
+			}
+			errs = errors.Wrap(errs, err)
+		}
+	}
+	if errs != nil {
+		return errors.Wrap(errFailedUnsubscribe, errs)
+	}
+
+	return nil
+}
+
+func channelSubject(topic, subtopic string) string {
+	subject := fmt.Sprintf("%s.%s", chansPrefix, topic)
+	if subtopic != "" {
+		subject = fmt.Sprintf("%s.%s", subject, subtopic)
+	}
+
+	return subject
+}
+
 // authorize checks if the thingKey is authorized to access the channel
-// and returns the thingID if it is.
-func (svc *adapterService) authorize(ctx context.Context, thingKey, chanID, action string) (string, error) {
+// and returns the thingID and the domainID if it is.
+func (svc *adapterService) authorize(ctx context.Context, thingKey, chanID, action string) (string, string, error) {
 	ar := &magistrala.ThingsAuthzReq{
 		Permission: action,
 		ThingKey:   thingKey,
@@ -92,11 +176,11 @@ func (svc *adapterService) authorize(ctx context.Context, thingKey, chanID, acti
 	}
 	res, err := svc.things.Authorize(ctx, ar)
 	if err != nil {
-		return "", errors.Wrap(svcerr.ErrAuthorization, err)
+		return "", "", errors.Wrap(svcerr.ErrAuthorization, err)
 	}
 	if !res.GetAuthorized() {
-		return "", errors.Wrap(svcerr.ErrAuthorization, err)
+		return "", "", errors.Wrap(svcerr.ErrAuthorization, err)
 	}
 
-	return res.GetId(), nil
+	return res.GetId(), res.GetDomainId(), nil
 }
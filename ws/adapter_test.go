@@ -17,6 +17,7 @@ import (
 	"github.com/absmach/magistrala/ws"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -37,15 +38,15 @@ var msg = messaging.Message{
 	Payload:   []byte(`[{"n":"current","t":-5,"v":1.2}]`),
 }
 
-func newService() (ws.Service, *mocks.PubSub, *thmocks.ThingsServiceClient) {
+func newService(cfg ws.Config) (ws.Service, *mocks.PubSub, *thmocks.ThingsServiceClient) {
 	pubsub := new(mocks.PubSub)
 	things := new(thmocks.ThingsServiceClient)
 
-	return ws.New(things, pubsub), pubsub, things
+	return ws.New(things, pubsub, messaging.FlatTopics, cfg), pubsub, things
 }
 
 func TestSubscribe(t *testing.T) {
-	svc, pubsub, things := newService()
+	svc, pubsub, things := newService(ws.Config{})
 
 	c := ws.NewClient(nil)
 
@@ -123,3 +124,194 @@ func TestSubscribe(t *testing.T) {
 		repocall1.Unset()
 	}
 }
+
+func TestSubscribeDomainTopics(t *testing.T) {
+	pubsub := new(mocks.PubSub)
+	things := new(thmocks.ThingsServiceClient)
+	svc := ws.New(things, pubsub, messaging.DomainTopics, ws.Config{})
+
+	cases := []struct {
+		desc     string
+		domainID string
+		subtopic string
+		topic    string
+	}{
+		{
+			desc:     "subscribe to channel of domain",
+			domainID: "domainA",
+			subtopic: subTopic,
+			topic:    "channels.domainA." + chanID + "." + subTopic,
+		},
+		{
+			desc:     "subscribe to channel of another domain",
+			domainID: "domainB",
+			subtopic: subTopic,
+			topic:    "channels.domainB." + chanID + "." + subTopic,
+		},
+		{
+			desc:     "subscribe to channel of thing without domain",
+			domainID: "",
+			subtopic: "",
+			topic:    "channels." + chanID,
+		},
+	}
+
+	for _, tc := range cases {
+		c := ws.NewClient(nil)
+		subConfig := messaging.SubscriberConfig{
+			ID:      id,
+			Topic:   tc.topic,
+			Handler: c,
+		}
+		repocall := pubsub.On("Subscribe", mock.Anything, subConfig).Return(nil)
+		repocall1 := things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id, DomainId: tc.domainID}, nil)
+		err := svc.Subscribe(context.Background(), thingKey, chanID, tc.subtopic, c)
+		assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error %s", tc.desc, err))
+		repocall.Parent.AssertCalled(t, "Subscribe", mock.Anything, subConfig)
+		repocall.Unset()
+		repocall1.Unset()
+	}
+}
+
+func TestSubscribeConnLimits(t *testing.T) {
+	svc, pubsub, things := newService(ws.Config{MaxConns: 3, MaxConnsPerThing: 2})
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: thingKey, ChannelID: chanID, Permission: "subscribe"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing1"}, nil)
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: invalidKey, ChannelID: chanID, Permission: "subscribe"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing2"}, nil)
+
+	var clients []*ws.Client
+	cases := []struct {
+		desc     string
+		thingKey string
+		err      error
+	}{
+		{
+			desc:     "subscribe first connection of thing",
+			thingKey: thingKey,
+		},
+		{
+			desc:     "subscribe second connection of thing",
+			thingKey: thingKey,
+		},
+		{
+			desc:     "subscribe connection of thing beyond its limit",
+			thingKey: thingKey,
+			err:      ws.ErrThingConnLimit,
+		},
+		{
+			desc:     "subscribe connection of another thing",
+			thingKey: invalidKey,
+		},
+		{
+			desc:     "subscribe connection beyond total limit",
+			thingKey: invalidKey,
+			err:      ws.ErrConnLimit,
+		},
+	}
+
+	for _, tc := range cases {
+		c := ws.NewClient(nil)
+		err := svc.Subscribe(context.Background(), tc.thingKey, chanID, "", c)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			clients = append(clients, c)
+		}
+	}
+
+	// Closing a connection releases its slot.
+	err := clients[0].Cancel()
+	assert.Nil(t, err, fmt.Sprintf("closing client: got unexpected error %s", err))
+	err = svc.Subscribe(context.Background(), thingKey, chanID, "", ws.NewClient(nil))
+	assert.Nil(t, err, fmt.Sprintf("subscribe after closing a connection: got unexpected error %s", err))
+}
+
+func TestSubscribeSubscriptionLimit(t *testing.T) {
+	svc, pubsub, things := newService(ws.Config{MaxSubscriptions: 2})
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	pubsub.On("Unsubscribe", mock.Anything, "thing1", mock.Anything).Return(nil)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing1"}, nil)
+
+	c := ws.NewClient(nil)
+	defer c.Cancel()
+
+	cases := []struct {
+		desc        string
+		unsubscribe bool
+		chanID      string
+		err         error
+	}{
+		{
+			desc:   "subscribe to first channel",
+			chanID: "1",
+		},
+		{
+			desc:   "subscribe to second channel",
+			chanID: "2",
+		},
+		{
+			desc:   "subscribe again to first channel",
+			chanID: "1",
+		},
+		{
+			desc:   "subscribe to channel beyond the limit",
+			chanID: "3",
+			err:    ws.ErrSubscriptionLimit,
+		},
+		{
+			desc:        "unsubscribe from first channel",
+			unsubscribe: true,
+			chanID:      "1",
+		},
+		{
+			desc:   "subscribe to channel after unsubscribing",
+			chanID: "3",
+		},
+		{
+			desc:   "subscribe to first channel again beyond the limit",
+			chanID: "1",
+			err:    ws.ErrSubscriptionLimit,
+		},
+		{
+			desc:        "unsubscribe from channel without subscription",
+			unsubscribe: true,
+			chanID:      "1",
+			err:         ws.ErrNotSubscribed,
+		},
+	}
+
+	for _, tc := range cases {
+		var err error
+		switch tc.unsubscribe {
+		case true:
+			err = svc.Unsubscribe(context.Background(), tc.chanID, "", c)
+		default:
+			err = svc.Subscribe(context.Background(), thingKey, tc.chanID, "", c)
+		}
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+	pubsub.AssertNumberOfCalls(t, "Unsubscribe", 1)
+}
+
+func TestDisconnect(t *testing.T) {
+	svc, pubsub, things := newService(ws.Config{})
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	pubsub.On("Unsubscribe", mock.Anything, "thing1", mock.Anything).Return(nil)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "thing1"}, nil)
+
+	c := ws.NewClient(nil)
+	for _, chanID := range []string{"1", "2"} {
+		err := svc.Subscribe(context.Background(), thingKey, chanID, "", c)
+		require.Nil(t, err, fmt.Sprintf("subscribe to channel %s: got unexpected error %s", chanID, err))
+	}
+	err := c.Cancel()
+	assert.Nil(t, err, fmt.Sprintf("closing client: got unexpected error %s", err))
+
+	err = svc.Disconnect(context.Background(), c)
+	assert.Nil(t, err, fmt.Sprintf("disconnect: got unexpected error %s", err))
+	pubsub.AssertNumberOfCalls(t, "Unsubscribe", 2)
+
+	// The subscriptions are removed once.
+	err = svc.Disconnect(context.Background(), c)
+	assert.Nil(t, err, fmt.Sprintf("disconnect again: got unexpected error %s", err))
+	pubsub.AssertNumberOfCalls(t, "Unsubscribe", 2)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"sync"
+
+	"github.com/absmach/magistrala/ws"
+	"github.com/gorilla/websocket"
+)
+
+// shutdownReason is the reason of the close frame sent to the clients when
+// the adapter shuts down, so they reconnect rather than report an error.
+const shutdownReason = "server is shutting down"
+
+// clients tracks the open connections, so they are closed cleanly on
+// shutdown.
+type clients struct {
+	mu     sync.Mutex
+	closed bool
+	conns  map[*ws.Client]struct{}
+}
+
+func newClients() *clients {
+	return &clients{conns: make(map[*ws.Client]struct{})}
+}
+
+// add tracks the client. The client is closed if the adapter is already
+// shutting down.
+func (cs *clients) add(c *ws.Client) bool {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.closed {
+		_ = c.Close(websocket.CloseGoingAway, shutdownReason)
+		return false
+	}
+	cs.conns[c] = struct{}{}
+
+	return true
+}
+
+func (cs *clients) remove(c *ws.Client) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	delete(cs.conns, c)
+}
+
+// close sends the going away close frame to the clients and closes their
+// connections.
+func (cs *clients) close() {
+	cs.mu.Lock()
+	cs.closed = true
+	conns := cs.conns
+	cs.conns = make(map[*ws.Client]struct{})
+	cs.mu.Unlock()
+
+	for c := range conns {
+		_ = c.Close(websocket.CloseGoingAway, shutdownReason)
+	}
+}
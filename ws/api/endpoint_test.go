@@ -11,9 +11,11 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/absmach/magistrala"
 	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/messaging/mocks"
 	thmocks "github.com/absmach/magistrala/things/mocks"
 	"github.com/absmach/magistrala/ws"
@@ -37,12 +39,16 @@ const (
 var msg = []byte(`[{"n":"current","t":-1,"v":1.6}]`)
 
 func newService(things magistrala.ThingsServiceClient) (ws.Service, *mocks.PubSub) {
+	return newServiceWithConfig(things, ws.Config{})
+}
+
+func newServiceWithConfig(things magistrala.ThingsServiceClient, cfg ws.Config) (ws.Service, *mocks.PubSub) {
 	pubsub := new(mocks.PubSub)
-	return ws.New(things, pubsub), pubsub
+	return ws.New(things, pubsub, messaging.FlatTopics, cfg), pubsub
 }
 
 func newHTTPServer(svc ws.Service) *httptest.Server {
-	mux := api.MakeHandler(context.Background(), svc, mglog.NewMock(), instanceID)
+	mux := api.MakeHandler(context.Background(), svc, messaging.SubtopicRules{}, mglog.NewMock(), instanceID)
 	return httptest.NewServer(mux)
 }
 
@@ -94,14 +100,17 @@ func TestHandshake(t *testing.T) {
 	svc, pubsub := newService(things)
 	target := newHTTPServer(svc)
 	defer target.Close()
-	handler := ws.NewHandler(pubsub, mglog.NewMock(), things)
+	handler := ws.NewHandler(pubsub, nil, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, messaging.SigningRules{})
 	ts, err := newProxyHTPPServer(handler, target)
 	require.Nil(t, err)
 	defer ts.Close()
-	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: thingKey, ChannelID: id, Permission: "publish"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "1"}, nil)
+	things.On("Authorize", mock.Anything, mock.MatchedBy(func(req *magistrala.ThingsAuthzReq) bool {
+		return req.GetThingKey() == thingKey && req.GetChannelID() == id && req.GetPermission() == "publish"
+	})).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "1"}, nil)
 	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: thingKey, ChannelID: id, Permission: "subscribe"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "2"}, nil)
 	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.AuthZRes{Authorized: false, Id: "3"}, nil)
 	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	pubsub.On("Unsubscribe", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	cases := []struct {
@@ -211,3 +220,248 @@ func TestHandshake(t *testing.T) {
 		})
 	}
 }
+
+func TestHandshakeConnLimits(t *testing.T) {
+	const otherKey = "5d4c2b5e-2a7e-4b3f-9d1c-1f0e8a6b7c3d"
+
+	things := new(thmocks.ThingsServiceClient)
+	svc, pubsub := newServiceWithConfig(things, ws.Config{MaxConns: 3, MaxConnsPerThing: 2})
+	target := newHTTPServer(svc)
+	defer target.Close()
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: thingKey, ChannelID: id, Permission: "subscribe"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "1"}, nil)
+	things.On("Authorize", mock.Anything, &magistrala.ThingsAuthzReq{ThingKey: otherKey, ChannelID: id, Permission: "subscribe"}).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: "2"}, nil)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	pubsub.On("Unsubscribe", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	var conns []*websocket.Conn
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	cases := []struct {
+		desc     string
+		thingKey string
+		code     int
+		reason   string
+	}{
+		{
+			desc:     "connect up to the thing limit",
+			thingKey: thingKey,
+		},
+		{
+			desc:     "connect at the thing limit",
+			thingKey: thingKey,
+		},
+		{
+			desc:     "connect beyond the thing limit",
+			thingKey: thingKey,
+			code:     websocket.ClosePolicyViolation,
+			reason:   ws.ErrThingConnLimit.Error(),
+		},
+		{
+			desc:     "connect at the total limit",
+			thingKey: otherKey,
+		},
+		{
+			desc:     "connect beyond the total limit",
+			thingKey: otherKey,
+			code:     websocket.CloseTryAgainLater,
+			reason:   ws.ErrConnLimit.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			conn, res, err := handshake(target.URL, id, "", tc.thingKey, true)
+			require.Nil(t, err, fmt.Sprintf("%s: got unexpected error %s", tc.desc, err))
+			assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode, fmt.Sprintf("%s: expected status code '%d' got '%d'", tc.desc, http.StatusSwitchingProtocols, res.StatusCode))
+			if tc.code == 0 {
+				conns = append(conns, conn)
+				return
+			}
+			defer conn.Close()
+			_, _, err = conn.ReadMessage()
+			closeErr, ok := err.(*websocket.CloseError)
+			require.True(t, ok, fmt.Sprintf("%s: expected close error got %s", tc.desc, err))
+			assert.Equal(t, tc.code, closeErr.Code, fmt.Sprintf("%s: expected close code %d got %d", tc.desc, tc.code, closeErr.Code))
+			assert.Equal(t, tc.reason, closeErr.Text, fmt.Sprintf("%s: expected close reason %s got %s", tc.desc, tc.reason, closeErr.Text))
+		})
+	}
+
+	// Once a connection is closed by the peer, its slot is released.
+	conns[0].Close()
+	assert.Eventually(t, func() bool {
+		conn, _, err := handshake(target.URL, id, "", otherKey, true)
+		if err != nil {
+			return false
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, _, err = conn.ReadMessage()
+		_, closed := err.(*websocket.CloseError)
+		return !closed
+	}, time.Second, 200*time.Millisecond, "expected connection to succeed after releasing a slot")
+}
+
+func TestSubscriptions(t *testing.T) {
+	const otherChanID = "0b6c1f3e-8d4a-4c2b-9e7f-5a1d3c2b4e6f"
+
+	things := new(thmocks.ThingsServiceClient)
+	svc, pubsub := newServiceWithConfig(things, ws.Config{MaxSubscriptions: 1})
+	target := newHTTPServer(svc)
+	defer target.Close()
+	handler := ws.NewHandler(pubsub, nil, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, messaging.SigningRules{})
+	ts, err := newProxyHTPPServer(handler, target)
+	require.Nil(t, err)
+	defer ts.Close()
+	things.On("Authorize", mock.Anything, mock.MatchedBy(func(req *magistrala.ThingsAuthzReq) bool {
+		return req.GetThingKey() == thingKey && req.GetPermission() == "subscribe"
+	})).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id}, nil)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: false}, nil)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	pubsub.On("Unsubscribe", mock.Anything, id, mock.Anything).Return(nil)
+
+	u, _ := url.Parse(ts.URL)
+	u.Scheme = protocol
+	header := http.Header{}
+	header.Add("Authorization", thingKey)
+	conn, res, err := websocket.DefaultDialer.Dial(u.String()+ws.SubscriptionsPath, header)
+	require.Nil(t, err, fmt.Sprintf("unexpected error connecting %s", err))
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode, fmt.Sprintf("expected status code '%d' got '%d'", http.StatusSwitchingProtocols, res.StatusCode))
+
+	cases := []struct {
+		desc    string
+		request string
+		err     string
+	}{
+		{
+			desc:    "subscribe up to the limit",
+			request: fmt.Sprintf(`{"action":"subscribe","channel_id":"%s","subtopic":"temperature"}`, chanID),
+		},
+		{
+			desc:    "subscribe beyond the limit",
+			request: fmt.Sprintf(`{"action":"subscribe","channel_id":"%s"}`, otherChanID),
+			err:     ws.ErrSubscriptionLimit.Error(),
+		},
+		{
+			desc:    "unsubscribe from channel without subscription",
+			request: fmt.Sprintf(`{"action":"unsubscribe","channel_id":"%s"}`, otherChanID),
+			err:     ws.ErrNotSubscribed.Error(),
+		},
+		{
+			desc:    "unsubscribe freeing a slot",
+			request: fmt.Sprintf(`{"action":"unsubscribe","channel_id":"%s","subtopic":"temperature"}`, chanID),
+		},
+		{
+			desc:    "subscribe after freeing a slot",
+			request: fmt.Sprintf(`{"action":"subscribe","channel_id":"%s"}`, otherChanID),
+		},
+		{
+			desc:    "send request with invalid action",
+			request: fmt.Sprintf(`{"action":"publish","channel_id":"%s"}`, chanID),
+			err:     "invalid subscription action",
+		},
+		{
+			desc:    "send malformed request",
+			request: "subscribe",
+			err:     "malformed subscription request",
+		},
+	}
+
+	for _, tc := range cases {
+		err := conn.WriteMessage(websocket.TextMessage, []byte(tc.request))
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error sending request %s", tc.desc, err))
+		var reply struct {
+			Error string `json:"error"`
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		err = conn.ReadJSON(&reply)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error reading reply %s", tc.desc, err))
+		assert.Equal(t, tc.err, reply.Error, fmt.Sprintf("%s: expected error %q got %q", tc.desc, tc.err, reply.Error))
+	}
+
+	// The subscription requests are not published.
+	pubsub.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	pubsub.AssertCalled(t, "Unsubscribe", mock.Anything, id, "channels."+chanID+".temperature")
+}
+
+func TestCloseUnsubscribes(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	svc, pubsub := newService(things)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id}, nil)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	disconnected := make(chan string, 1)
+	pubsub.On("Unsubscribe", mock.Anything, id, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		disconnected <- args.String(2)
+	})
+
+	u, _ := url.Parse(ts.URL)
+	u.Scheme = protocol
+	header := http.Header{}
+	header.Add("Authorization", thingKey)
+	conn, _, err := websocket.DefaultDialer.Dial(u.String()+ws.SubscriptionsPath, header)
+	require.Nil(t, err, fmt.Sprintf("unexpected error connecting %s", err))
+
+	err = conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"action":"subscribe","channel_id":"%s"}`, chanID)))
+	require.Nil(t, err, fmt.Sprintf("unexpected error sending request %s", err))
+	var reply struct {
+		Error string `json:"error"`
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	err = conn.ReadJSON(&reply)
+	require.Nil(t, err, fmt.Sprintf("unexpected error reading reply %s", err))
+	require.Empty(t, reply.Error, fmt.Sprintf("unexpected subscription error %s", reply.Error))
+
+	conn.Close()
+	select {
+	case subject := <-disconnected:
+		assert.Equal(t, "channels."+chanID, subject, fmt.Sprintf("expected unsubscribe from channels.%s got %s", chanID, subject))
+	case <-time.After(5 * time.Second):
+		t.Error("expected closed connection to unsubscribe")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	things := new(thmocks.ThingsServiceClient)
+	svc, pubsub := newService(things)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id}, nil)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+	pubsub.On("Unsubscribe", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	target := httptest.NewServer(api.MakeHandler(ctx, svc, messaging.SubtopicRules{}, mglog.NewMock(), instanceID))
+	defer target.Close()
+
+	subscriber, _, err := handshake(target.URL, chanID, "", thingKey, true)
+	require.Nil(t, err, fmt.Sprintf("unexpected error connecting %s", err))
+	defer subscriber.Close()
+
+	u, _ := url.Parse(target.URL)
+	u.Scheme = protocol
+	header := http.Header{}
+	header.Add("Authorization", thingKey)
+	subscriptions, _, err := websocket.DefaultDialer.Dial(u.String()+ws.SubscriptionsPath, header)
+	require.Nil(t, err, fmt.Sprintf("unexpected error connecting %s", err))
+	defer subscriptions.Close()
+
+	cancel()
+
+	for desc, conn := range map[string]*websocket.Conn{"subscriber": subscriber, "subscriptions": subscriptions} {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), fmt.Sprintf("%s on shutdown: expected going away close frame got %s", desc, err))
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String()+ws.SubscriptionsPath, header)
+	require.Nil(t, err, fmt.Sprintf("unexpected error connecting %s", err))
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), fmt.Sprintf("connection after shutdown: expected going away close frame got %s", err))
+}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,13 +13,15 @@ import (
 	"strings"
 
 	"github.com/absmach/magistrala/pkg/errors"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/ws"
 	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
 )
 
 var channelPartRegExp = regexp.MustCompile(`^/channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
 
-func handshake(ctx context.Context, svc ws.Service) http.HandlerFunc {
+func handshake(ctx context.Context, svc ws.Service, conns *clients) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		req, err := decodeRequest(r)
 		if err != nil {
@@ -34,29 +37,141 @@ func handshake(ctx context.Context, svc ws.Service) http.HandlerFunc {
 		client := ws.NewClient(conn)
 
 		if err := svc.Subscribe(ctx, req.thingKey, req.chanID, req.subtopic, client); err != nil {
-			req.conn.Close()
+			code, reason := closeReason(err)
+			client.Close(code, reason)
+			return
+		}
+
+		if !conns.add(client) {
+			disconnect(ctx, svc, client)
 			return
 		}
 
 		logger.Debug(fmt.Sprintf("Successfully upgraded communication to WS on channel %s", req.chanID))
+		go func() {
+			client.Listen()
+			conns.remove(client)
+			disconnect(ctx, svc, client)
+		}()
 	}
 }
 
-func decodeRequest(r *http.Request) (connReq, error) {
-	authKey := r.Header.Get("Authorization")
-	if authKey == "" {
-		authKeys := r.URL.Query()["authorization"]
-		if len(authKeys) == 0 {
+// subscriptions serves the connection subscribing to the channels with the
+// subscription requests sent over it. Each request is answered in-band, so a
+// rejected request doesn't close the connection.
+func subscriptions(ctx context.Context, svc ws.Service, conns *clients) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		thingKey, err := authKey(r)
+		if err != nil {
+			encodeError(w, err)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Failed to upgrade connection to websocket: %s", err.Error()))
+			return
+		}
+		client := ws.NewClient(conn)
+		if !conns.add(client) {
+			return
+		}
+
+		go func() {
+			client.Serve(func(payload []byte) {
+				res := handleSubscription(ctx, svc, thingKey, client, payload)
+				data, err := json.Marshal(res)
+				if err != nil {
+					return
+				}
+				if err := client.Reply(data); err != nil {
+					logger.Warn(fmt.Sprintf("Failed to reply to subscription request: %s", err.Error()))
+				}
+			})
+			conns.remove(client)
+			disconnect(ctx, svc, client)
+		}()
+	}
+}
+
+// disconnect removes the broker subscriptions of the closed client.
+func disconnect(ctx context.Context, svc ws.Service, client *ws.Client) {
+	if err := svc.Disconnect(ctx, client); err != nil {
+		logger.Warn(fmt.Sprintf("Failed to unsubscribe closed connection: %s", err.Error()))
+	}
+}
+
+func handleSubscription(ctx context.Context, svc ws.Service, thingKey string, client *ws.Client, payload []byte) subscriptionRes {
+	var req subscriptionReq
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return subscriptionRes{Error: errMalformedRequest.Error()}
+	}
+	res := subscriptionRes{
+		Action:    req.Action,
+		ChannelID: req.ChannelID,
+		Subtopic:  req.Subtopic,
+	}
+	if err := req.validate(); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	subtopic, err := parseSubTopic(req.Subtopic)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	switch req.Action {
+	case subscribeAction:
+		err = svc.Subscribe(ctx, thingKey, req.ChannelID, subtopic, client)
+	case unsubscribeAction:
+		err = svc.Unsubscribe(ctx, req.ChannelID, subtopic, client)
+	}
+	if err != nil {
+		res.Error = subscriptionError(err).Error()
+	}
+
+	return res
+}
+
+// subscriptionError returns the error sent to the peer, hiding the internal
+// failures.
+func subscriptionError(err error) error {
+	for _, e := range []error{ws.ErrSubscriptionLimit, ws.ErrConnLimit, ws.ErrThingConnLimit, ws.ErrNotSubscribed} {
+		if errors.Contains(err, e) {
+			return e
+		}
+	}
+	if errors.Contains(err, svcerr.ErrAuthentication) || errors.Contains(err, svcerr.ErrAuthorization) {
+		return errUnauthorizedAccess
+	}
+
+	return ws.ErrFailedSubscription
+}
+
+func authKey(r *http.Request) (string, error) {
+	key := r.Header.Get("Authorization")
+	if key == "" {
+		keys := r.URL.Query()["authorization"]
+		if len(keys) == 0 {
 			logger.Debug("Missing authorization key.")
-			return connReq{}, errUnauthorizedAccess
+			return "", errUnauthorizedAccess
 		}
-		authKey = authKeys[0]
+		key = keys[0]
+	}
+
+	return key, nil
+}
+
+func decodeRequest(r *http.Request) (connReq, error) {
+	key, err := authKey(r)
+	if err != nil {
+		return connReq{}, err
 	}
 
 	chanID := chi.URLParam(r, "chanID")
 
 	req := connReq{
-		thingKey: authKey,
+		thingKey: key,
 		chanID:   chanID,
 	}
 
@@ -103,10 +218,27 @@ func parseSubTopic(subtopic string) (string, error) {
 	}
 
 	subtopic = strings.Join(filteredElems, ".")
+	if subtopic, err = subtopics.Apply(subtopic); err != nil {
+		logger.Warn(fmt.Sprintf("Error applying subtopic rules: %s", err))
+		return "", errMalformedSubtopic
+	}
 
 	return subtopic, nil
 }
 
+// closeReason returns the code and the reason of the close frame rejecting
+// the connection.
+func closeReason(err error) (int, string) {
+	switch {
+	case errors.Contains(err, ws.ErrConnLimit):
+		return websocket.CloseTryAgainLater, ws.ErrConnLimit.Error()
+	case errors.Contains(err, ws.ErrThingConnLimit):
+		return websocket.ClosePolicyViolation, ws.ErrThingConnLimit.Error()
+	default:
+		return websocket.ClosePolicyViolation, ""
+	}
+}
+
 func encodeError(w http.ResponseWriter, err error) {
 	var statusCode int
 
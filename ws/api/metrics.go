@@ -39,3 +39,23 @@ func (mm *metricsMiddleware) Subscribe(ctx context.Context, thingKey, chanID, su
 
 	return mm.svc.Subscribe(ctx, thingKey, chanID, subtopic, c)
 }
+
+// Unsubscribe instruments Unsubscribe method with metrics.
+func (mm *metricsMiddleware) Unsubscribe(ctx context.Context, chanID, subtopic string, c *ws.Client) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "unsubscribe").Add(1)
+		mm.latency.With("method", "unsubscribe").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Unsubscribe(ctx, chanID, subtopic, c)
+}
+
+// Disconnect instruments Disconnect method with metrics.
+func (mm *metricsMiddleware) Disconnect(ctx context.Context, c *ws.Client) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "disconnect").Add(1)
+		mm.latency.With("method", "disconnect").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Disconnect(ctx, c)
+}
@@ -3,7 +3,15 @@
 
 package api
 
-import "github.com/gorilla/websocket"
+import (
+	"github.com/absmach/magistrala/ws"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	subscribeAction   = "subscribe"
+	unsubscribeAction = "unsubscribe"
+)
 
 type connReq struct {
 	thingKey string
@@ -11,3 +19,31 @@ type connReq struct {
 	subtopic string
 	conn     *websocket.Conn
 }
+
+// subscriptionReq subscribes or unsubscribes the connection of the
+// subscriptions path.
+type subscriptionReq struct {
+	Action    string `json:"action"`
+	ChannelID string `json:"channel_id"`
+	Subtopic  string `json:"subtopic,omitempty"`
+}
+
+func (req subscriptionReq) validate() error {
+	if req.Action != subscribeAction && req.Action != unsubscribeAction {
+		return errInvalidAction
+	}
+	if req.ChannelID == "" {
+		return ws.ErrEmptyTopic
+	}
+
+	return nil
+}
+
+// subscriptionRes is the in-band reply to the subscription request. The
+// error is set if the request is rejected.
+type subscriptionRes struct {
+	Action    string `json:"action,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	Subtopic  string `json:"subtopic,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
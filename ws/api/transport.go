@@ -10,6 +10,7 @@ import (
 	"net/http"
 
 	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/ws"
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
@@ -24,6 +25,8 @@ const (
 var (
 	errUnauthorizedAccess = errors.New("missing or invalid credentials provided")
 	errMalformedSubtopic  = errors.New("malformed subtopic")
+	errMalformedRequest   = errors.New("malformed subscription request")
+	errInvalidAction      = errors.New("invalid subscription action")
 )
 
 var (
@@ -32,16 +35,27 @@ var (
 		WriteBufferSize: readwriteBufferSize,
 		CheckOrigin:     func(r *http.Request) bool { return true },
 	}
-	logger *slog.Logger
+	logger    *slog.Logger
+	subtopics messaging.SubtopicRules
 )
 
-// MakeHandler returns http handler with handshake endpoint.
-func MakeHandler(ctx context.Context, svc ws.Service, l *slog.Logger, instanceID string) http.Handler {
+// MakeHandler returns http handler with handshake endpoint. The connections
+// are closed with the going away close frame once the context is done. The
+// subscribed subtopics are normalized with the rules of the published ones.
+func MakeHandler(ctx context.Context, svc ws.Service, rules messaging.SubtopicRules, l *slog.Logger, instanceID string) http.Handler {
 	logger = l
+	subtopics = rules
+
+	conns := newClients()
+	go func() {
+		<-ctx.Done()
+		conns.close()
+	}()
 
 	mux := chi.NewRouter()
-	mux.Get("/channels/{chanID}/messages", handshake(ctx, svc))
-	mux.Get("/channels/{chanID}/messages/*", handshake(ctx, svc))
+	mux.Get("/channels/{chanID}/messages", handshake(ctx, svc, conns))
+	mux.Get("/channels/{chanID}/messages/*", handshake(ctx, svc, conns))
+	mux.Get(ws.SubscriptionsPath, subscriptions(ctx, svc, conns))
 
 	mux.Get("/health", magistrala.Health(service, instanceID))
 	mux.Handle("/metrics", promhttp.Handler())
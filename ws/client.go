@@ -4,14 +4,38 @@
 package ws
 
 import (
+	"sync"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/errors"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/gorilla/websocket"
 )
 
+const closeTimeout = time.Second
+
+var errClientClosed = errors.New("client connection closed")
+
 // Client handles messaging and websocket connection.
 type Client struct {
 	conn *websocket.Conn
 	id   string
+	// domainID is the domain of the thing, which scopes its subscriptions.
+	domainID string
+
+	// send buffers the messages written to the connection, if set.
+	send    chan []byte
+	policy  string
+	done    chan struct{}
+	once    sync.Once
+	release func()
+
+	// wmu serializes the unbuffered writes.
+	wmu sync.Mutex
+
+	// mu guards the subscribed subjects.
+	mu   sync.Mutex
+	subs map[string]struct{}
 }
 
 // NewClient returns a new websocket client.
@@ -19,15 +43,79 @@ func NewClient(c *websocket.Conn) *Client {
 	return &Client{
 		conn: c,
 		id:   "",
+		done: make(chan struct{}),
+		subs: make(map[string]struct{}),
 	}
 }
 
 // Cancel handles the websocket connection after unsubscribing.
 func (c *Client) Cancel() error {
-	if c.conn == nil {
+	return c.Close(websocket.CloseNormalClosure, "")
+}
+
+// Close sends the close frame with the code and the reason to the peer, then
+// closes the connection and releases its slot.
+func (c *Client) Close(code int, reason string) error {
+	var err error
+	c.once.Do(func() {
+		close(c.done)
+		if c.release != nil {
+			c.release()
+		}
+		if c.conn == nil {
+			return
+		}
+		msg := websocket.FormatCloseMessage(code, reason)
+		_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(closeTimeout))
+		err = c.conn.Close()
+	})
+
+	return err
+}
+
+// Listen reads the connection until the peer closes it, then closes the
+// client. The messages sent by the peer are discarded.
+func (c *Client) Listen() {
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			_ = c.Cancel()
+			return
+		}
+	}
+}
+
+// Serve reads the connection until the peer closes it, passing the messages
+// sent by the peer to the handle function, then closes the client.
+func (c *Client) Serve(handle func(payload []byte)) {
+	for {
+		_, payload, err := c.conn.ReadMessage()
+		if err != nil {
+			_ = c.Cancel()
+			return
+		}
+		handle(payload)
+	}
+}
+
+// Reply sends the payload to the peer. Unlike the messages of the broker,
+// the reply waits for room in the send buffer.
+func (c *Client) Reply(payload []byte) error {
+	select {
+	case <-c.done:
+		return errClientClosed
+	default:
+	}
+
+	if c.send == nil {
+		return c.writeMessage(payload)
+	}
+
+	select {
+	case c.send <- payload:
 		return nil
+	case <-c.done:
+		return errClientClosed
 	}
-	return c.conn.Close()
 }
 
 // Handle handles the sending and receiving of messages via the broker.
@@ -37,5 +125,108 @@ func (c *Client) Handle(msg *messaging.Message) error {
 		return nil
 	}
 
-	return c.conn.WriteMessage(websocket.TextMessage, msg.GetPayload())
+	select {
+	case <-c.done:
+		return errClientClosed
+	default:
+	}
+
+	if c.send == nil {
+		return c.writeMessage(msg.GetPayload())
+	}
+
+	select {
+	case c.send <- msg.GetPayload():
+		return nil
+	default:
+	}
+	if c.policy == SlowConsumerDisconnect {
+		_ = c.Close(websocket.ClosePolicyViolation, ErrSlowConsumer.Error())
+	}
+
+	return ErrSlowConsumer
+}
+
+// buffer makes the client write the messages from a bounded buffer, so a
+// slow peer doesn't block the broker.
+func (c *Client) buffer(size int, policy string) {
+	if size <= 0 || c.send != nil {
+		return
+	}
+	c.send = make(chan []byte, size)
+	c.policy = policy
+	go c.write()
+}
+
+func (c *Client) write() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case payload := <-c.send:
+			if c.conn == nil {
+				continue
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				_ = c.Cancel()
+				return
+			}
+		}
+	}
+}
+
+// writeMessage writes the payload to the connection, since the messages of
+// several subscriptions may be written at the same time.
+func (c *Client) writeMessage(payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	return c.conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+// addSubscription takes a subscription slot for the subject, unless the
+// client is already subscribed to it. It reports whether the slot is taken.
+func (c *Client) addSubscription(subject string, limit int) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.subs[subject]; ok {
+		return false, nil
+	}
+	if limit > 0 && len(c.subs) >= limit {
+		return false, ErrSubscriptionLimit
+	}
+	c.subs[subject] = struct{}{}
+
+	return true, nil
+}
+
+func (c *Client) removeSubscription(subject string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.subs, subject)
+}
+
+// clearSubscriptions frees all the subscription slots, and returns their
+// subjects.
+func (c *Client) clearSubscriptions() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subjects := make([]string, 0, len(c.subs))
+	for subject := range c.subs {
+		subjects = append(subjects, subject)
+	}
+	c.subs = make(map[string]struct{})
+
+	return subjects
+}
+
+func (c *Client) subscribed(subject string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.subs[subject]
+	return ok
 }
@@ -4,6 +4,8 @@
 package ws_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,9 +14,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/absmach/magistrala"
+	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/ws"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 const expectedCount = uint64(1)
@@ -100,3 +106,72 @@ func TestHandle(t *testing.T) {
 	c := atomic.LoadUint64(&count)
 	assert.Equal(t, expectedCount, c, fmt.Sprintf("expected message count %d, got %d", expectedCount, c))
 }
+
+func TestHandleSlowConsumer(t *testing.T) {
+	const sendBuffer = 4
+	// Large payloads fill the socket buffers quickly, so the writes to the
+	// peer which never reads block.
+	payload := bytes.Repeat([]byte("a"), 4*1024*1024)
+
+	cases := []struct {
+		desc   string
+		policy string
+	}{
+		{
+			desc:   "handle messages for slow consumer with drop policy",
+			policy: ws.SlowConsumerDrop,
+		},
+		{
+			desc:   "handle messages for slow consumer with disconnect policy",
+			policy: ws.SlowConsumerDisconnect,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			stop := make(chan struct{})
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				<-stop
+			}))
+			defer s.Close()
+			defer close(stop)
+
+			wsConn, _, err := websocket.DefaultDialer.Dial(strings.Replace(s.URL, "http", "ws", 1), nil)
+			require.Nil(t, err, fmt.Sprintf("%s: got unexpected error %s", tc.desc, err))
+			defer wsConn.Close()
+
+			svc, pubsub, things := newService(ws.Config{SendBuffer: sendBuffer, SlowConsumer: tc.policy})
+			pubsub.On("Subscribe", mock.Anything, mock.Anything).Return(nil)
+			things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id}, nil)
+			c := ws.NewClient(wsConn)
+			err = svc.Subscribe(context.Background(), thingKey, chanID, "", c)
+			require.Nil(t, err, fmt.Sprintf("%s: got unexpected error %s", tc.desc, err))
+
+			m := messaging.Message{Channel: chanID, Publisher: "publisher", Payload: payload}
+			var sent int
+			for ; sent < 100; sent++ {
+				if err = c.Handle(&m); err != nil {
+					break
+				}
+			}
+			assert.ErrorIs(t, err, ws.ErrSlowConsumer, fmt.Sprintf("%s: expected %s got %s", tc.desc, ws.ErrSlowConsumer, err))
+			// Besides the buffered messages, only the few being written can be
+			// in flight.
+			assert.LessOrEqual(t, sent, 2*sendBuffer, fmt.Sprintf("%s: expected bounded buffering, got %d messages", tc.desc, sent))
+
+			err = c.Handle(&m)
+			switch tc.policy {
+			case ws.SlowConsumerDrop:
+				assert.ErrorIs(t, err, ws.ErrSlowConsumer, fmt.Sprintf("%s: expected %s got %s", tc.desc, ws.ErrSlowConsumer, err))
+			case ws.SlowConsumerDisconnect:
+				assert.NotNil(t, err, fmt.Sprintf("%s: expected error from closed client", tc.desc))
+				assert.NotErrorIs(t, err, ws.ErrSlowConsumer, fmt.Sprintf("%s: expected closed client got %s", tc.desc, err))
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import "github.com/absmach/magistrala/pkg/events"
+
+const (
+	protocol      = "websocket"
+	connPrefix    = "conn."
+	connOpen      = connPrefix + "open"
+	connChannel   = connPrefix + "channel"
+	connClose     = connPrefix + "close"
+	connReset     = connPrefix + "reset"
+	connHeartbeat = connPrefix + "heartbeat"
+)
+
+var _ events.Event = (*connEvent)(nil)
+
+// connEvent feeds the registry of the live connections. The connections are
+// identified by the adapter instance and the client ID.
+type connEvent struct {
+	operation string
+	instance  string
+	clientID  string
+	thingID   string
+	domainID  string
+	channelID string
+}
+
+func (ce connEvent) Encode() (map[string]interface{}, error) {
+	val := map[string]interface{}{
+		"operation": ce.operation,
+		"instance":  ce.instance,
+		"protocol":  protocol,
+	}
+	if ce.clientID != "" {
+		val["client_id"] = ce.clientID
+	}
+	if ce.thingID != "" {
+		val["thing_id"] = ce.thingID
+	}
+	if ce.domainID != "" {
+		val["domain_id"] = ce.domainID
+	}
+	if ce.channelID != "" {
+		val["channel_id"] = ce.channelID
+	}
+
+	return val, nil
+}
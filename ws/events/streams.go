@@ -0,0 +1,120 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/absmach/magistrala/pkg/events"
+	"github.com/absmach/magistrala/pkg/events/store"
+)
+
+const streamID = "magistrala.ws"
+
+//go:generate mockery --name EventStore --output=../mocks --filename events.go --quiet --note "Copyright (c) Abstract Machines"
+type EventStore interface {
+	// OpenConn issues event once the client connects.
+	OpenConn(ctx context.Context, clientID string) error
+
+	// AttachConn issues event once the thing of the client is authorized on
+	// the channel.
+	AttachConn(ctx context.Context, clientID, thingID, domainID, channelID string) error
+
+	// CloseConn issues event once the client disconnects.
+	CloseConn(ctx context.Context, clientID string) error
+
+	// ResetConns issues event closing all the connections of the instance.
+	ResetConns(ctx context.Context) error
+
+	// Heartbeat issues event reporting that the instance is alive.
+	Heartbeat(ctx context.Context) error
+}
+
+type eventStore struct {
+	events.Publisher
+	instance string
+}
+
+// NewEventStore returns the event store publishing the connection events of
+// the WebSocket adapter instance.
+func NewEventStore(ctx context.Context, url, instance string) (EventStore, error) {
+	publisher, err := store.NewPublisher(ctx, url, streamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventStore{
+		instance:  instance,
+		Publisher: publisher,
+	}, nil
+}
+
+func (es *eventStore) OpenConn(ctx context.Context, clientID string) error {
+	ev := connEvent{
+		operation: connOpen,
+		instance:  es.instance,
+		clientID:  clientID,
+	}
+
+	return es.Publish(ctx, ev)
+}
+
+func (es *eventStore) AttachConn(ctx context.Context, clientID, thingID, domainID, channelID string) error {
+	ev := connEvent{
+		operation: connChannel,
+		instance:  es.instance,
+		clientID:  clientID,
+		thingID:   thingID,
+		domainID:  domainID,
+		channelID: channelID,
+	}
+
+	return es.Publish(ctx, ev)
+}
+
+func (es *eventStore) CloseConn(ctx context.Context, clientID string) error {
+	ev := connEvent{
+		operation: connClose,
+		instance:  es.instance,
+		clientID:  clientID,
+	}
+
+	return es.Publish(ctx, ev)
+}
+
+func (es *eventStore) ResetConns(ctx context.Context) error {
+	return es.Publish(ctx, connEvent{operation: connReset, instance: es.instance})
+}
+
+func (es *eventStore) Heartbeat(ctx context.Context) error {
+	return es.Publish(ctx, connEvent{operation: connHeartbeat, instance: es.instance})
+}
+
+// RunHeartbeat closes the connections left by the previous run of the
+// instance, and then reports that the instance is alive every interval until
+// the context is canceled, so the registry expires the connections of the
+// instances which stopped without closing them.
+func RunHeartbeat(ctx context.Context, es EventStore, interval time.Duration, logger *slog.Logger) {
+	if err := es.ResetConns(ctx); err != nil {
+		logger.Error("failed to publish connections reset event", slog.Any("error", err))
+	}
+	if err := es.Heartbeat(ctx); err != nil {
+		logger.Error("failed to publish heartbeat event", slog.Any("error", err))
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := es.Heartbeat(ctx); err != nil {
+				logger.Error("failed to publish heartbeat event", slog.Any("error", err))
+			}
+		}
+	}
+}
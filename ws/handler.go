@@ -9,7 +9,10 @@ import (
 	"log/slog"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/absmach/magistrala"
@@ -17,6 +20,8 @@ import (
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/messaging"
 	"github.com/absmach/magistrala/pkg/policies"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	"github.com/absmach/magistrala/ws/events"
 	"github.com/absmach/mproxy/pkg/session"
 )
 
@@ -31,6 +36,9 @@ const (
 	LogInfoConnected    = "connected with client_id %s"
 	LogInfoDisconnected = "disconnected client_id %s and username %s"
 	LogInfoPublished    = "published with client_id %s to the topic %s"
+	LogWarnFlagged      = "flagged unverified message of thing %s on the channel %s"
+	LogWarnRateReject   = "rejected message of thing %s over the publish rate: %s"
+	LogWarnRateShed     = "shed message of thing %s over the publish rate"
 )
 
 // Error wrappers for MQTT errors.
@@ -44,29 +52,68 @@ var (
 	errFailedPublish            = errors.New("failed to publish")
 	errFailedParseSubtopic      = errors.New("failed to parse subtopic")
 	errFailedPublishToMsgBroker = errors.New("failed to publish to magistrala message broker")
+	errFailedPublishConnEvent   = errors.New("failed to publish connection event")
 )
 
 var channelRegExp = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/messages(\/[^?]*)?(\?.*)?$`)
 
 // Event implements events.Event interface.
 type handler struct {
-	pubsub messaging.PubSub
-	things magistrala.ThingsServiceClient
-	logger *slog.Logger
+	pubsub    messaging.PubSub
+	things    magistrala.ThingsServiceClient
+	subtopics messaging.SubtopicRules
+	topics    messaging.TopicScheme
+	limiter   ratelimit.Limiter
+	signing   messaging.SigningRules
+	es        events.EventStore
+	logger    *slog.Logger
+
+	// clients counts the connections, whose number identifies the client
+	// of the connection, since the WebSocket clients have no client ID.
+	clients atomic.Uint64
+	// channels maps sessions to the channels their things are authorized
+	// on, whose connections are reported to the connection registry.
+	channels sync.Map
 }
 
-// NewHandler creates new Handler entity.
-func NewHandler(pubsub messaging.PubSub, logger *slog.Logger, thingsClient magistrala.ThingsServiceClient) session.Handler {
+// NewHandler creates new Handler entity. The payloads published to the
+// channels requiring signatures are verified with the signing secret of the
+// thing, the signature being set in the signature query parameter of the
+// topic. If the rate limiter is not nil, publishes of things over their rate
+// are rejected or shed. The messages are published to the topics of the topic
+// scheme. If the event store is not nil, the connections are reported to the
+// connection registry.
+func NewHandler(pubsub messaging.PubSub, es events.EventStore, logger *slog.Logger, thingsClient magistrala.ThingsServiceClient, subtopics messaging.SubtopicRules, topics messaging.TopicScheme, limiter ratelimit.Limiter, signing messaging.SigningRules) session.Handler {
 	return &handler{
-		logger: logger,
-		pubsub: pubsub,
-		things: thingsClient,
+		es:        es,
+		logger:    logger,
+		pubsub:    pubsub,
+		things:    thingsClient,
+		subtopics: subtopics,
+		topics:    topics,
+		limiter:   limiter,
+		signing:   signing,
 	}
 }
 
 // AuthConnect is called on device connection,
 // prior forwarding to the ws server.
 func (h *handler) AuthConnect(ctx context.Context) error {
+	if h.es == nil {
+		return nil
+	}
+	s, ok := session.FromContext(ctx)
+	if !ok {
+		return errClientNotInitialized
+	}
+	if s.ID == "" {
+		s.ID = strconv.FormatUint(h.clients.Add(1), 10)
+	}
+	h.channels.Store(s, &sync.Map{})
+	if err := h.es.OpenConn(ctx, s.ID); err != nil {
+		h.logger.Error(errors.Wrap(errFailedPublishConnEvent, err).Error())
+	}
+
 	return nil
 }
 
@@ -76,6 +123,10 @@ func (h *handler) AuthPublish(ctx context.Context, topic *string, payload *[]byt
 	if topic == nil {
 		return errMissingTopicPub
 	}
+	// The messages sent to the subscriptions path are subscription requests.
+	if *topic == SubscriptionsPath {
+		return nil
+	}
 	s, ok := session.FromContext(ctx)
 	if !ok {
 		return errClientNotInitialized
@@ -89,7 +140,7 @@ func (h *handler) AuthPublish(ctx context.Context, topic *string, payload *[]byt
 		token = string(s.Password)
 	}
 
-	return h.authAccess(ctx, token, *topic, policies.PublishPermission)
+	return h.authAccess(ctx, s, token, *topic, policies.PublishPermission)
 }
 
 // AuthSubscribe is called on device publish,
@@ -112,7 +163,11 @@ func (h *handler) AuthSubscribe(ctx context.Context, topics *[]string) error {
 	}
 
 	for _, v := range *topics {
-		if err := h.authAccess(ctx, token, v, policies.SubscribePermission); err != nil {
+		// Each subscription request is authorized by the adapter.
+		if v == SubscriptionsPath {
+			continue
+		}
+		if err := h.authAccess(ctx, s, token, v, policies.SubscribePermission); err != nil {
 			return err
 		}
 	}
@@ -131,6 +186,9 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	if !ok {
 		return errors.Wrap(errFailedPublish, errClientNotInitialized)
 	}
+	if *topic == SubscriptionsPath {
+		return nil
+	}
 	h.logger.Info(fmt.Sprintf(LogInfoPublished, s.ID, *topic))
 
 	if len(*payload) == 0 {
@@ -151,6 +209,9 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	if err != nil {
 		return errors.Wrap(errFailedParseSubtopic, err)
 	}
+	if subtopic, err = h.subtopics.Apply(subtopic); err != nil {
+		return errors.Wrap(errFailedParseSubtopic, err)
+	}
 
 	var token string
 	switch {
@@ -161,9 +222,11 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	}
 
 	ar := &magistrala.ThingsAuthzReq{
-		Permission: policies.PublishPermission,
-		ThingKey:   token,
-		ChannelID:  chanID,
+		Permission:  policies.PublishPermission,
+		ThingKey:    token,
+		ChannelID:   chanID,
+		ContentType: messaging.ContentTypeFrom(ctx, *topic),
+		Payload:     *payload,
 	}
 	res, err := h.things.Authorize(ctx, ar)
 	if err != nil {
@@ -172,6 +235,15 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 	if !res.GetAuthorized() {
 		return svcerr.ErrAuthorization
 	}
+	allowed, err := messaging.CheckRate(ctx, h.limiter, res)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf(LogWarnRateReject, res.GetId(), err))
+		return errors.Wrap(errFailedPublish, err)
+	}
+	if !allowed {
+		h.logger.Warn(fmt.Sprintf(LogWarnRateShed, res.GetId()))
+		return nil
+	}
 
 	msg := messaging.Message{
 		Protocol:  protocol,
@@ -181,8 +253,14 @@ func (h *handler) Publish(ctx context.Context, topic *string, payload *[]byte) e
 		Payload:   *payload,
 		Created:   time.Now().UnixNano(),
 	}
+	if err := h.signing.Check(&msg, res.GetSigningSecret(), messaging.SignatureFrom(ctx, *topic)); err != nil {
+		return errors.Wrap(errFailedPublish, err)
+	}
+	if msg.GetUnverified() {
+		h.logger.Warn(fmt.Sprintf(LogWarnFlagged, msg.GetPublisher(), msg.GetChannel()))
+	}
 
-	if err := h.pubsub.Publish(ctx, msg.GetChannel(), &msg); err != nil {
+	if err := h.pubsub.Publish(ctx, h.topics.Topic(res.GetDomainId(), msg.GetChannel()), &msg); err != nil {
 		return errors.Wrap(errFailedPublishToMsgBroker, err)
 	}
 
@@ -199,7 +277,9 @@ func (h *handler) Subscribe(ctx context.Context, topics *[]string) error {
 	return nil
 }
 
-// Unsubscribe - after client unsubscribed.
+// Unsubscribe - after client unsubscribed. The WebSocket proxy unsubscribes
+// the client from the topic of the connection once the connection is lost,
+// which closes the connection.
 func (h *handler) Unsubscribe(ctx context.Context, topics *[]string) error {
 	s, ok := session.FromContext(ctx)
 	if !ok {
@@ -207,15 +287,50 @@ func (h *handler) Unsubscribe(ctx context.Context, topics *[]string) error {
 	}
 
 	h.logger.Info(fmt.Sprintf(LogInfoUnsubscribed, s.ID, strings.Join(*topics, ",")))
+	h.closeConn(ctx, s)
+
 	return nil
 }
 
 // Disconnect - connection with broker or client lost.
 func (h *handler) Disconnect(ctx context.Context) error {
+	s, ok := session.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	h.closeConn(ctx, s)
+
 	return nil
 }
 
-func (h *handler) authAccess(ctx context.Context, password, topic, action string) error {
+// attachConn reports the connection of the thing of the session to the
+// channel the first time the thing is authorized on the channel in the
+// session.
+func (h *handler) attachConn(ctx context.Context, s *session.Session, chanID string, res *magistrala.ThingsAuthzRes) {
+	chans, ok := h.channels.Load(s)
+	if !ok {
+		return
+	}
+	if _, ok := chans.(*sync.Map).LoadOrStore(chanID, struct{}{}); ok {
+		return
+	}
+	if err := h.es.AttachConn(ctx, s.ID, res.GetId(), res.GetDomainId(), chanID); err != nil {
+		h.logger.Error(errors.Wrap(errFailedPublishConnEvent, err).Error())
+	}
+}
+
+// closeConn reports the connection of the session closed once, the context
+// of the lost connection being canceled already.
+func (h *handler) closeConn(ctx context.Context, s *session.Session) {
+	if _, ok := h.channels.LoadAndDelete(s); !ok {
+		return
+	}
+	if err := h.es.CloseConn(context.WithoutCancel(ctx), s.ID); err != nil {
+		h.logger.Error(errors.Wrap(errFailedPublishConnEvent, err).Error())
+	}
+}
+
+func (h *handler) authAccess(ctx context.Context, s *session.Session, password, topic, action string) error {
 	// Topics are in the format:
 	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
 	if !channelRegExp.MatchString(topic) {
@@ -230,9 +345,10 @@ func (h *handler) authAccess(ctx context.Context, password, topic, action string
 	chanID := channelParts[1]
 
 	ar := &magistrala.ThingsAuthzReq{
-		Permission: action,
-		ThingKey:   password,
-		ChannelID:  chanID,
+		Permission:  action,
+		ThingKey:    password,
+		ChannelID:   chanID,
+		ContentType: messaging.ContentTypeFrom(ctx, topic),
 	}
 	res, err := h.things.Authorize(ctx, ar)
 	if err != nil {
@@ -241,6 +357,7 @@ func (h *handler) authAccess(ctx context.Context, password, topic, action string
 	if !res.GetAuthorized() {
 		return errors.Wrap(svcerr.ErrAuthorization, err)
 	}
+	h.attachConn(ctx, s, chanID, res)
 
 	return nil
 }
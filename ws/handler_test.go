@@ -0,0 +1,206 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package ws_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/absmach/magistrala"
+	mglog "github.com/absmach/magistrala/logger"
+	"github.com/absmach/magistrala/pkg/errors"
+	"github.com/absmach/magistrala/pkg/messaging"
+	"github.com/absmach/magistrala/pkg/messaging/mocks"
+	"github.com/absmach/magistrala/pkg/ratelimit"
+	rlmocks "github.com/absmach/magistrala/pkg/ratelimit/mocks"
+	thmocks "github.com/absmach/magistrala/things/mocks"
+	"github.com/absmach/magistrala/ws"
+	wsmocks "github.com/absmach/magistrala/ws/mocks"
+	"github.com/absmach/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPublishSignature(t *testing.T) {
+	const (
+		signingSecret = "signing_secret"
+		rejectChanID  = "1"
+		flagChanID    = "2"
+	)
+	payload := []byte(`[{"n":"current","t":-5,"v":1.2}]`)
+	signature := messaging.Sign(signingSecret, payload)
+
+	things := new(thmocks.ThingsServiceClient)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id, SigningSecret: signingSecret}, nil)
+	pubsub := new(mocks.PubSub)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	signing, err := messaging.NewSigningRules(messaging.SigningConfig{Channels: fmt.Sprintf("%s:reject,%s:flag", rejectChanID, flagChanID)})
+	assert.Nil(t, err, fmt.Sprintf("failed to create signing rules: %s", err))
+	handler := ws.NewHandler(pubsub, nil, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, signing)
+	ctx := session.NewContext(context.Background(), &session.Session{ID: id, Password: []byte(thingKey)})
+
+	cases := []struct {
+		desc       string
+		topic      string
+		err        error
+		published  bool
+		unverified bool
+	}{
+		{
+			desc:      "publish validly signed message",
+			topic:     fmt.Sprintf("/channels/%s/messages?signature=%s", rejectChanID, signature),
+			published: true,
+		},
+		{
+			desc:  "publish message signed with thing key",
+			topic: fmt.Sprintf("/channels/%s/messages?signature=%s", rejectChanID, messaging.Sign(thingKey, payload)),
+			err:   messaging.ErrInvalidSignature,
+		},
+		{
+			desc:  "publish unsigned message",
+			topic: fmt.Sprintf("/channels/%s/messages", rejectChanID),
+			err:   messaging.ErrMissingSignature,
+		},
+		{
+			desc:       "publish unsigned message to channel flagging unverified messages",
+			topic:      fmt.Sprintf("/channels/%s/messages", flagChanID),
+			published:  true,
+			unverified: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := handler.Publish(ctx, &tc.topic, &payload)
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+			published := len(pubsub.Calls) > 0
+			assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+			if published {
+				m := pubsub.Calls[0].Arguments.Get(2).(*messaging.Message)
+				assert.Equal(t, tc.unverified, m.GetUnverified(), fmt.Sprintf("%s: expected unverified %t got %t", tc.desc, tc.unverified, m.GetUnverified()))
+			}
+			pubsub.Calls = nil
+		})
+	}
+}
+
+func TestPublishRateLimit(t *testing.T) {
+	const (
+		thingKey = "thing_key"
+		id       = "1"
+		chanID   = "2"
+	)
+	payload := []byte(`[{"n":"current","t":-5,"v":1.2}]`)
+	topic := fmt.Sprintf("/channels/%s/messages", chanID)
+
+	for _, mode := range []string{ratelimit.Reject, ratelimit.Shed} {
+		t.Run(mode, func(t *testing.T) {
+			things := new(thmocks.ThingsServiceClient)
+			things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id, Rate: 1, Burst: 2}, nil)
+
+			// The bucket is not refilled during the test, so the thing may
+			// publish the number of messages of its burst.
+			var taken uint32
+			limiter := new(rlmocks.Limiter)
+			limiter.On("Mode").Return(mode)
+			limiter.On("Allow", mock.Anything, id, ratelimit.Limit{Rate: 1, Burst: 2}).Return(func(_ context.Context, _ string, limit ratelimit.Limit) error {
+				if taken == limit.Burst {
+					return ratelimit.ErrRateLimited
+				}
+				taken++
+				return nil
+			})
+			pubsub := new(mocks.PubSub)
+			pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			handler := ws.NewHandler(pubsub, nil, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, limiter, messaging.SigningRules{})
+			ctx := session.NewContext(context.Background(), &session.Session{ID: id, Password: []byte(thingKey)})
+
+			var overErr error
+			if mode == ratelimit.Reject {
+				overErr = ratelimit.ErrRateLimited
+			}
+			cases := []struct {
+				desc      string
+				err       error
+				published bool
+			}{
+				{
+					desc:      "publish first message under rate",
+					published: true,
+				},
+				{
+					desc:      "publish second message under rate",
+					published: true,
+				},
+				{
+					desc: "publish message over rate",
+					err:  overErr,
+				},
+			}
+
+			for _, tc := range cases {
+				err := handler.Publish(ctx, &topic, &payload)
+				assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+				published := len(pubsub.Calls) > 0
+				assert.Equal(t, tc.published, published, fmt.Sprintf("%s: expected published %t got %t", tc.desc, tc.published, published))
+				pubsub.Calls = nil
+			}
+		})
+	}
+}
+
+func TestConnEvents(t *testing.T) {
+	const (
+		domainID    = "domain"
+		otherChanID = "2"
+	)
+	things := new(thmocks.ThingsServiceClient)
+	things.On("Authorize", mock.Anything, mock.Anything).Return(&magistrala.ThingsAuthzRes{Authorized: true, Id: id, DomainId: domainID}, nil)
+	eventStore := new(wsmocks.EventStore)
+	eventStore.On("OpenConn", mock.Anything, mock.Anything).Return(nil)
+	eventStore.On("AttachConn", mock.Anything, mock.Anything, id, domainID, mock.Anything).Return(nil)
+	eventStore.On("CloseConn", mock.Anything, mock.Anything).Return(nil)
+	handler := ws.NewHandler(new(mocks.PubSub), eventStore, mglog.NewMock(), things, messaging.SubtopicRules{}, messaging.FlatTopics, nil, messaging.SigningRules{})
+
+	s := session.Session{Password: []byte(thingKey)}
+	ctx, cancel := context.WithCancel(session.NewContext(context.Background(), &s))
+	err := handler.AuthConnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("authorize connect: got unexpected error %s", err))
+	assert.NotEmpty(t, s.ID, "expected the client ID to be set")
+	eventStore.AssertCalled(t, "OpenConn", mock.Anything, s.ID)
+
+	topic := fmt.Sprintf("/channels/%s/messages", id)
+	err = handler.AuthSubscribe(ctx, &[]string{topic})
+	assert.Nil(t, err, fmt.Sprintf("authorize subscribe: got unexpected error %s", err))
+	for i := 0; i < 3; i++ {
+		err = handler.AuthPublish(ctx, &topic, &[]byte{})
+		assert.Nil(t, err, fmt.Sprintf("authorize publish: got unexpected error %s", err))
+	}
+	other := fmt.Sprintf("/channels/%s/messages", otherChanID)
+	err = handler.AuthPublish(ctx, &other, &[]byte{})
+	assert.Nil(t, err, fmt.Sprintf("authorize publish: got unexpected error %s", err))
+
+	// The connection to each channel is reported once per connection.
+	eventStore.AssertNumberOfCalls(t, "AttachConn", 2)
+	eventStore.AssertCalled(t, "AttachConn", mock.Anything, s.ID, id, domainID, id)
+	eventStore.AssertCalled(t, "AttachConn", mock.Anything, s.ID, id, domainID, otherChanID)
+
+	// The proxy unsubscribes the lost connection with the canceled context.
+	cancel()
+	err = handler.Unsubscribe(ctx, &[]string{topic})
+	assert.Nil(t, err, fmt.Sprintf("unsubscribe: got unexpected error %s", err))
+	err = handler.Disconnect(ctx)
+	assert.Nil(t, err, fmt.Sprintf("disconnect: got unexpected error %s", err))
+	eventStore.AssertNumberOfCalls(t, "CloseConn", 1)
+	eventStore.AssertCalled(t, "CloseConn", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), s.ID)
+
+	// Each connection is identified by its own client ID.
+	s1 := session.Session{Password: []byte(thingKey)}
+	err = handler.AuthConnect(session.NewContext(context.Background(), &s1))
+	assert.Nil(t, err, fmt.Sprintf("authorize connect: got unexpected error %s", err))
+	assert.NotEqual(t, s.ID, s1.ID, "expected distinct client IDs")
+}
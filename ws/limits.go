@@ -0,0 +1,123 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package ws
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/absmach/magistrala/pkg/errors"
+)
+
+const (
+	// SlowConsumerDrop drops the messages which don't fit in the send buffer
+	// of a slow client.
+	SlowConsumerDrop = "drop"
+
+	// SlowConsumerDisconnect closes the connection of a client whose send
+	// buffer is full.
+	SlowConsumerDisconnect = "disconnect"
+)
+
+var (
+	// ErrConnLimit indicates that the adapter serves the maximum number of
+	// connections.
+	ErrConnLimit = errors.New("connection limit exceeded")
+
+	// ErrThingConnLimit indicates that the thing holds the maximum number of
+	// connections.
+	ErrThingConnLimit = errors.New("thing connection limit exceeded")
+
+	// ErrSubscriptionLimit indicates that the connection holds the maximum
+	// number of subscriptions.
+	ErrSubscriptionLimit = errors.New("subscription limit exceeded")
+
+	// ErrSlowConsumer indicates that the message is not sent, since the
+	// send buffer of the client is full.
+	ErrSlowConsumer = errors.New("send buffer of slow consumer is full")
+)
+
+// Config defines the connection limits and the send buffering of the adapter.
+type Config struct {
+	// MaxConns is the maximum number of concurrent connections. Zero means
+	// no limit.
+	MaxConns int `env:"MAX_CONNS" envDefault:"0"`
+
+	// MaxConnsPerThing is the maximum number of concurrent connections of a
+	// single thing. Zero means no limit.
+	MaxConnsPerThing int `env:"MAX_CONNS_PER_THING" envDefault:"0"`
+
+	// MaxSubscriptions is the maximum number of active subscriptions of a
+	// single connection. Zero means no limit.
+	MaxSubscriptions int `env:"MAX_SUBSCRIPTIONS" envDefault:"100"`
+
+	// SendBuffer is the number of messages buffered per connection. Zero
+	// means the messages are written to the connection synchronously.
+	SendBuffer int `env:"SEND_BUFFER" envDefault:"256"`
+
+	// SlowConsumer is the policy applied once the send buffer is full,
+	// either drop or disconnect.
+	SlowConsumer string `env:"SLOW_CONSUMER" envDefault:"drop"`
+}
+
+// Validate returns an error if the config is invalid.
+func (cfg Config) Validate() error {
+	if cfg.MaxConns < 0 || cfg.MaxConnsPerThing < 0 || cfg.MaxSubscriptions < 0 || cfg.SendBuffer < 0 {
+		return fmt.Errorf("connection limits and send buffer must not be negative")
+	}
+	switch cfg.SlowConsumer {
+	case "", SlowConsumerDrop, SlowConsumerDisconnect:
+		return nil
+	default:
+		return fmt.Errorf("invalid slow consumer policy %q", cfg.SlowConsumer)
+	}
+}
+
+// limiter counts the open connections, in total and per thing.
+type limiter struct {
+	mu       sync.Mutex
+	maxConns int
+	maxThing int
+	conns    int
+	things   map[string]int
+}
+
+func newLimiter(cfg Config) *limiter {
+	return &limiter{
+		maxConns: cfg.MaxConns,
+		maxThing: cfg.MaxConnsPerThing,
+		things:   make(map[string]int),
+	}
+}
+
+// acquire takes a connection slot of the thing, and returns the function
+// releasing it.
+func (l *limiter) acquire(thingID string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxConns > 0 && l.conns >= l.maxConns {
+		return nil, ErrConnLimit
+	}
+	if l.maxThing > 0 && l.things[thingID] >= l.maxThing {
+		return nil, ErrThingConnLimit
+	}
+	l.conns++
+	l.things[thingID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(thingID) })
+	}, nil
+}
+
+func (l *limiter) release(thingID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.conns--
+	if l.things[thingID]--; l.things[thingID] <= 0 {
+		delete(l.things, thingID)
+	}
+}
@@ -16,6 +16,7 @@ const (
 	publishOP     = "publish_op"
 	subscribeOP   = "subscribe_op"
 	unsubscribeOP = "unsubscribe_op"
+	disconnectOP  = "disconnect_op"
 )
 
 type tracingMiddleware struct {
@@ -38,3 +39,19 @@ func (tm *tracingMiddleware) Subscribe(ctx context.Context, thingKey, chanID, su
 
 	return tm.svc.Subscribe(ctx, thingKey, chanID, subtopic, client)
 }
+
+// Unsubscribe traces the "Unsubscribe" operation of the wrapped ws.Service.
+func (tm *tracingMiddleware) Unsubscribe(ctx context.Context, chanID, subtopic string, client *ws.Client) error {
+	ctx, span := tm.tracer.Start(ctx, unsubscribeOP)
+	defer span.End()
+
+	return tm.svc.Unsubscribe(ctx, chanID, subtopic, client)
+}
+
+// Disconnect traces the "Disconnect" operation of the wrapped ws.Service.
+func (tm *tracingMiddleware) Disconnect(ctx context.Context, client *ws.Client) error {
+	ctx, span := tm.tracer.Start(ctx, disconnectOP)
+	defer span.End()
+
+	return tm.svc.Disconnect(ctx, client)
+}